@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bootstrapKueueVersion string
+	bootstrapQueue        string
+)
+
+var BootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Installs JobSet and Kueue on a cluster and creates queues sized to its node pools.",
+	Long: `Prepares a fresh GKE cluster for 'gcluster job submit'. JobSet and Kueue are
+installed, or repaired when they are broken or older than the requested
+version. A Kueue ResourceFlavor is created for each accelerator of the node
+pools, and a ClusterQueue whose quota matches the node pools' capacity is
+created with a LocalQueue in the default namespace. Existing queues are left
+unchanged.`,
+	RunE:         runClusterBootstrap,
+	SilenceUsage: true,
+}
+
+func init() {
+	BootstrapCmd.Flags().StringVarP(&clusterName, "cluster", "c", "", "Name of the GKE cluster. Required.")
+	BootstrapCmd.Flags().StringVarP(&location, "location", "l", "", "Location (region or zone) of the GKE cluster. Required.")
+	BootstrapCmd.Flags().StringVar(&bootstrapKueueVersion, "kueue-version", "", "Kueue release to install (e.g., v0.15.2). Defaults to the version gcluster is tested with.")
+	BootstrapCmd.Flags().StringVar(&bootstrapQueue, "queue", "multislice-queue", "Name of the LocalQueue to create in the default namespace.")
+	_ = BootstrapCmd.MarkFlagRequired("cluster")
+	_ = BootstrapCmd.MarkFlagRequired("location")
+}
+
+func runClusterBootstrap(cmd *cobra.Command, args []string) error {
+	logging.Info("Bootstrapping cluster %s...", clusterName)
+	result, err := orc.BootstrapCluster(cmd.Context(), orchestrator.BootstrapOptions{
+		ProjectID:       projectID,
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		KueueVersion:    bootstrapKueueVersion,
+		LocalQueue:      bootstrapQueue,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to bootstrap cluster %s: %w", clusterName, err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Cluster %s is ready for gcluster workloads.\n", clusterName)
+	fmt.Fprintf(out, "  Kueue version:    %s\n", result.KueueVersion)
+	fmt.Fprintf(out, "  ResourceFlavors:  %s\n", strings.Join(result.ResourceFlavors, ", "))
+	queues := "existing"
+	if result.CreatedQueues {
+		queues = "created"
+	}
+	fmt.Fprintf(out, "  ClusterQueue:     %s (%s)\n", result.ClusterQueue, queues)
+	fmt.Fprintf(out, "  LocalQueue:       default/%s (%s)\n", result.LocalQueue, queues)
+	fmt.Fprintf(out, "Submit workloads with 'gcluster job submit --cluster %s --location %s --queue %s ...'.\n", clusterName, location, result.LocalQueue)
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator/gke"
+)
+
+func TestBootstrapCmd_RequiresCluster(t *testing.T) {
+	resetClusterCmdFlags()
+
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func() *gke.GKEOrchestrator {
+		g := gke.NewGKEOrchestrator()
+		g.SetExecutor(&mockClusterExecutor{})
+		return g
+	}
+
+	_, err := executeCommand(ClusterCmd, "bootstrap", "--project", "test-project")
+	if err == nil || !strings.Contains(err.Error(), `required flag(s) "cluster", "location" not set`) {
+		t.Errorf("expected the missing cluster flags error, got %v", err)
+	}
+}
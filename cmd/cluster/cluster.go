@@ -19,6 +19,7 @@ import (
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/orchestrator/gke"
 	"hpc-toolkit/pkg/shell"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -44,12 +45,15 @@ var ClusterCmd = &cobra.Command{
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		orc = gkeOrchestratorFactory()
 
+		if projectID == "" {
+			projectID = os.Getenv("GCLUSTER_PROJECT")
+		}
 		if projectID == "" {
 			result := shell.ExecuteCommand("gcloud", "config", "get-value", "project")
 			ambientProject := strings.TrimSpace(result.Stdout)
 
 			if result.ExitCode != 0 || ambientProject == "" {
-				return fmt.Errorf("no Google Cloud project specified. Please provide one via the '--project' flag or set a default project using 'gcloud config set project <PROJECT_ID>'")
+				return fmt.Errorf("no Google Cloud project specified. Please provide one via the '--project' flag or the GCLUSTER_PROJECT environment variable, or set a default project using 'gcloud config set project <PROJECT_ID>'")
 			}
 
 			projectID = ambientProject
@@ -66,4 +70,5 @@ func init() {
 	ClusterCmd.AddCommand(InfoCmd)
 	ClusterCmd.AddCommand(DescribeCmd)
 	ClusterCmd.AddCommand(VolumeCmd)
+	ClusterCmd.AddCommand(BootstrapCmd)
 }
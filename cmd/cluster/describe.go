@@ -45,7 +45,7 @@ func runClusterDescribe(cmd *cobra.Command, args []string) error {
 		ClusterLocation: location,
 	}
 
-	description, err := orc.DescribeEnvironment(clusterName, opts)
+	description, err := orc.DescribeEnvironment(cmd.Context(), clusterName, opts)
 	if err != nil {
 		return fmt.Errorf("failed to describe cluster: %w", err)
 	}
@@ -45,7 +45,7 @@ func runClusterInfo(cmd *cobra.Command, args []string) error {
 		ClusterLocation: location,
 	}
 
-	info, err := orc.GetClusterInfo(clusterName, opts)
+	info, err := orc.GetClusterInfo(cmd.Context(), clusterName, opts)
 	if err != nil {
 		return fmt.Errorf("failed to get cluster info: %w", err)
 	}
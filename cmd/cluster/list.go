@@ -38,7 +38,7 @@ func runListClusters(cmd *cobra.Command, args []string) error {
 		ProjectID: projectID,
 	}
 
-	clusters, err := orc.ListEnvironments(opts)
+	clusters, err := orc.ListEnvironments(cmd.Context(), opts)
 	if err != nil {
 		return fmt.Errorf("failed to list clusters: %w", err)
 	}
@@ -45,7 +45,7 @@ func runListVolumes(cmd *cobra.Command, args []string) error {
 		ProjectID:       projectID,
 	}
 
-	volumes, err := orc.ListVolumes(opts)
+	volumes, err := orc.ListVolumes(cmd.Context(), opts)
 	if err != nil {
 		return fmt.Errorf("failed to list volumes: %w", err)
 	}
@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator/gke"
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	clusterName string
+	location    string
+	projectID   string
+)
+
+var gkeOrchestratorFactory = func() *gke.GKEOrchestrator {
+	return gke.NewGKEOrchestrator()
+}
+
+var orc *gke.GKEOrchestrator
+
+// ComponentsCmd represents the base command for the cluster components gcluster depends on
+var ComponentsCmd = &cobra.Command{
+	Use:   "components",
+	Short: "[EXPERIMENTAL] Manage the cluster components gcluster depends on.",
+	Long:  `Inspect and upgrade the controllers, device plugins and CSI drivers that gcluster workloads rely on in a cluster. This feature is under active development.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		orc = gkeOrchestratorFactory()
+
+		if projectID == "" {
+			projectID = os.Getenv("GCLUSTER_PROJECT")
+		}
+		if projectID == "" {
+			result := shell.ExecuteCommand("gcloud", "config", "get-value", "project")
+			ambientProject := strings.TrimSpace(result.Stdout)
+
+			if result.ExitCode != 0 || ambientProject == "" {
+				return fmt.Errorf("no Google Cloud project specified. Please provide one via the '--project' flag or the GCLUSTER_PROJECT environment variable, or set a default project using 'gcloud config set project <PROJECT_ID>'")
+			}
+
+			projectID = ambientProject
+			logging.Info("Using ambient project ID: %s", projectID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ComponentsCmd.PersistentFlags().StringVarP(&projectID, "project", "p", "", "Google Cloud Project ID.")
+
+	ComponentsCmd.AddCommand(UpgradeCmd)
+}
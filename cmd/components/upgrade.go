@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var upgradeDryRun bool
+
+var UpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrades JobSet and Kueue on a cluster to the versions gcluster is tested with.",
+	Long: `Inventories the JobSet and Kueue controllers, the GPU and TPU device plugins
+and the Cloud Storage FUSE and Filestore CSI drivers of a cluster, and compares
+them with the versions this gcluster release is tested with.
+
+JobSet and then Kueue are upgraded in place when they are older than tested.
+Each controller is only upgraded when it is healthy, and the upgrade waits for
+it to serve again at the new version before the next one starts; the first
+failure stops the upgrade. Queued and running workloads are kept. Device
+plugins and CSI drivers are managed by GKE and only reported. Use --dry-run to
+see the plan without changing the cluster.`,
+	RunE:         runComponentsUpgrade,
+	SilenceUsage: true,
+}
+
+func init() {
+	UpgradeCmd.Flags().StringVarP(&clusterName, "cluster", "c", "", "Name of the GKE cluster. Required.")
+	UpgradeCmd.Flags().StringVarP(&location, "location", "l", "", "Location (region or zone) of the GKE cluster. Required.")
+	UpgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "Only report the components and the planned upgrades.")
+	_ = UpgradeCmd.MarkFlagRequired("cluster")
+	_ = UpgradeCmd.MarkFlagRequired("location")
+}
+
+func runComponentsUpgrade(cmd *cobra.Command, args []string) error {
+	result, err := orc.UpgradeComponents(cmd.Context(), orchestrator.ComponentsUpgradeOptions{
+		ProjectID:       projectID,
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		DryRun:          upgradeDryRun,
+	})
+	if len(result.Components) > 0 {
+		printComponents(cmd, result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upgrade the components of cluster %s: %w", clusterName, err)
+	}
+	return nil
+}
+
+func printComponents(cmd *cobra.Command, result orchestrator.ComponentsUpgradeResult) {
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tVERSION\tTESTED\tHEALTHY\tACTION\tNOTE")
+	for _, c := range result.Components {
+		version, tested := c.Version, c.Tested
+		if version == "" {
+			version = "-"
+		}
+		if tested == "" {
+			tested = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n", c.Name, version, tested, c.Healthy, c.Action, c.Note)
+	}
+	w.Flush()
+
+	switch {
+	case upgradeDryRun:
+		fmt.Fprintln(out, "Dry run: nothing was changed.")
+	case len(result.Upgraded) > 0:
+		fmt.Fprintf(out, "Upgraded: %s.\n", strings.Join(result.Upgraded, ", "))
+	default:
+		fmt.Fprintln(out, "No component needed an upgrade.")
+	}
+}
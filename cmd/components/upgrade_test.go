@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"bytes"
+	"hpc-toolkit/pkg/orchestrator/gke"
+	"hpc-toolkit/pkg/shell"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func executeCommand(root *cobra.Command, args ...string) (string, error) {
+	buf := new(bytes.Buffer)
+	root.SetOut(buf)
+	root.SetErr(buf)
+	root.SetArgs(args)
+
+	err := root.Execute()
+
+	return buf.String(), err
+}
+
+type mockComponentsExecutor struct{}
+
+func (m *mockComponentsExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	return shell.CommandResult{ExitCode: 1, Stderr: "cluster not found"}
+}
+
+func (m *mockComponentsExecutor) ExecuteCommandStream(name string, args ...string) error {
+	return nil
+}
+
+func TestUpgradeCmd(t *testing.T) {
+	defer func() { clusterName, location, upgradeDryRun = "", "", false }()
+
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func() *gke.GKEOrchestrator {
+		g := gke.NewGKEOrchestrator()
+		g.SetExecutor(&mockComponentsExecutor{})
+		return g
+	}
+
+	_, err := executeCommand(ComponentsCmd, "upgrade", "--project", "dev", "--location", "us-central1")
+	if err == nil || !strings.Contains(err.Error(), `required flag(s) "cluster" not set`) {
+		t.Errorf("expected a missing cluster error, got %v", err)
+	}
+
+	_, err = executeCommand(ComponentsCmd, "upgrade", "--project", "dev", "--cluster", "c1", "--location", "us-central1", "--dry-run")
+	if err == nil || !strings.Contains(err.Error(), "failed to upgrade the components of cluster c1") {
+		t.Errorf("expected an upgrade error, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package experiment
+
+import (
+	"errors"
+	"fmt"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cancelYes   bool
+	cancelForce bool
+)
+
+var CancelCmd = &cobra.Command{
+	Use:   "cancel [experiment]",
+	Short: "Cancel the unfinished runs of an experiment.",
+	Long: `Cancel every run of the experiment that has not succeeded or failed yet, like
+'gcluster job cancel' does for one workload. Finished runs are kept so that
+they can still be compared. You are asked to confirm unless --yes is given.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runCancel,
+	SilenceUsage: true,
+}
+
+func init() {
+	CancelCmd.Flags().BoolVarP(&cancelYes, "yes", "y", false, "Cancel the runs without asking for confirmation.")
+	CancelCmd.Flags().BoolVar(&cancelForce, "force", false, "Remove the pods of the runs immediately without waiting for graceful termination.")
+}
+
+func runCancel(cmd *cobra.Command, args []string) error {
+	experiment := args[0]
+	runs, err := experimentRuns(cmd.Context(), experiment)
+	if err != nil {
+		return err
+	}
+	var active []orchestrator.JobStatus
+	for _, r := range runs {
+		if !finished(r) {
+			active = append(active, r)
+		}
+	}
+	if len(active) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Experiment %s has no unfinished runs.\n", experiment)
+		return nil
+	}
+
+	for _, r := range active {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %s (%s)\n", r.Name, r.Status)
+	}
+	if !cancelYes && !shell.PromptYesNo(fmt.Sprintf("Cancel these %d run(s) of experiment %s?", len(active), experiment)) {
+		return fmt.Errorf("experiment cancel aborted")
+	}
+
+	var errs []error
+	for _, r := range active {
+		if err := orc.CancelJob(cmd.Context(), r.Name, orchestrator.CancelOptions{
+			ClusterName:     clusterName,
+			ClusterLocation: location,
+			ProjectID:       projectID,
+			Force:           cancelForce,
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel %s: %w", r.Name, err))
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Canceled %d of %d run(s) of experiment %s.\n", len(active)-len(errs), len(active), experiment)
+	return errors.Join(errs...)
+}
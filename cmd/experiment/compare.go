@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package experiment
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var CompareCmd = &cobra.Command{
+	Use:   "compare [experiment] [run...]",
+	Short: "Compare the runs of an experiment.",
+	Long: `Rank the runs of an experiment: succeeded runs first, fastest first, with how
+much longer each took than the best one, then the other runs by submission.
+Name runs after the experiment to compare only those.`,
+	Args:         cobra.MinimumNArgs(1),
+	RunE:         runCompare,
+	SilenceUsage: true,
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	runs, err := experimentRuns(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	if names := args[1:]; len(names) > 0 {
+		for _, name := range names {
+			if !slices.ContainsFunc(runs, func(r orchestrator.JobStatus) bool { return r.Name == name }) {
+				return fmt.Errorf("run %q is not part of experiment %q", name, args[0])
+			}
+		}
+		runs = slices.DeleteFunc(runs, func(r orchestrator.JobStatus) bool { return !slices.Contains(names, r.Name) })
+	}
+
+	at := now()
+	rankRuns(runs, at)
+	s := summarize(args[0], runs, at)
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RANK\tRUN\tSTATUS\tSUBMITTED\tDURATION\tVS_BEST")
+	for i, r := range runs {
+		d, ok := runDuration(r, at)
+		rank := "-"
+		if r.Status == "Succeeded" && ok {
+			rank = fmt.Sprint(i + 1)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", rank, r.Name, r.Status, r.CreationTime, formatDuration(d, ok), versusBest(r, d, ok, s))
+	}
+	return w.Flush()
+}
+
+// rankRuns sorts the succeeded runs by duration ahead of the other runs,
+// which keep the order of their submission.
+func rankRuns(runs []orchestrator.JobStatus, at time.Time) {
+	slices.SortStableFunc(runs, func(a, b orchestrator.JobStatus) int {
+		da, aok := runDuration(a, at)
+		db, bok := runDuration(b, at)
+		aRanked, bRanked := a.Status == "Succeeded" && aok, b.Status == "Succeeded" && bok
+		switch {
+		case aRanked && bRanked:
+			return cmp.Compare(da, db)
+		case aRanked:
+			return -1
+		case bRanked:
+			return 1
+		}
+		return strings.Compare(a.CreationTime, b.CreationTime)
+	})
+}
+
+// versusBest describes how much longer a succeeded run took than the best
+// run of s, e.g. "+12m0s (+20%)".
+func versusBest(r orchestrator.JobStatus, d time.Duration, ok bool, s summary) string {
+	switch {
+	case r.Status != "Succeeded" || !ok || s.Best == "":
+		return "-"
+	case r.Name == s.Best:
+		return "best"
+	case s.BestDuration == 0:
+		return fmt.Sprintf("+%s", (d - s.BestDuration).Round(time.Second))
+	}
+	diff := d - s.BestDuration
+	return fmt.Sprintf("+%s (+%.0f%%)", diff.Round(time.Second), 100*float64(diff)/float64(s.BestDuration))
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package experiment groups the workloads submitted with the same
+// 'gcluster job submit --experiment' into experiments that can be listed,
+// summarized, compared and canceled together.
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"hpc-toolkit/cmd/job"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/orchestrator/gke"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	clusterName string
+	location    string
+	projectID   string
+)
+
+var gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	return gke.NewGKEOrchestrator()
+}
+
+var orc orchestrator.JobOrchestrator
+
+// now is the time run durations of running workloads are measured up to.
+var now = time.Now
+
+// ExperimentCmd represents the base command for operations on experiments
+var ExperimentCmd = &cobra.Command{
+	Use:   "experiment",
+	Short: "[EXPERIMENTAL] Manage the runs of an experiment.",
+	Long: `Group the workloads submitted with 'gcluster job submit --experiment <name>'
+into experiments: list them, summarize and compare their runs, and cancel the
+runs of an experiment together. This feature is under active development.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		ctx := job.EffectiveContext()
+		if clusterName == "" {
+			clusterName = ctx.ClusterName
+		}
+		if location == "" {
+			location = ctx.Location
+		}
+		if projectID == "" {
+			projectID = ctx.ProjectID
+		}
+		for _, f := range []struct{ value, flag, description string }{
+			{clusterName, "cluster", "cluster name"},
+			{location, "location", "cluster location"},
+			{projectID, "project", "project ID"},
+		} {
+			if f.value == "" {
+				return fmt.Errorf("%s is required; please specify it using the --%s flag or set a default value using 'gcluster job config set %s <value>'", f.description, f.flag, f.flag)
+			}
+		}
+		orc = gkeOrchestratorFactory()
+		return nil
+	},
+}
+
+func init() {
+	ExperimentCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "", "Name of the GKE cluster.")
+	ExperimentCmd.PersistentFlags().StringVarP(&location, "location", "l", "", "Location (region or zone) of the GKE cluster.")
+	ExperimentCmd.PersistentFlags().StringVarP(&projectID, "project", "p", "", "Google Cloud Project ID.")
+
+	ExperimentCmd.AddCommand(ListCmd)
+	ExperimentCmd.AddCommand(StatusCmd)
+	ExperimentCmd.AddCommand(CompareCmd)
+	ExperimentCmd.AddCommand(CancelCmd)
+}
+
+// listRuns returns the workloads of the cluster submitted under experiment,
+// or all of them when experiment is empty.
+func listRuns(ctx context.Context, experiment string) ([]orchestrator.JobStatus, error) {
+	return orc.ListJobs(ctx, orchestrator.ListOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+		Experiment:      experiment,
+	})
+}
+
+// experimentRuns returns the runs of experiment, oldest first, and fails
+// when it has none.
+func experimentRuns(ctx context.Context, experiment string) ([]orchestrator.JobStatus, error) {
+	runs, err := listRuns(ctx, experiment)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no runs of experiment %q found in cluster %s; runs are tagged with 'gcluster job submit --experiment %s'", experiment, clusterName, experiment)
+	}
+	slices.SortStableFunc(runs, func(a, b orchestrator.JobStatus) int {
+		return strings.Compare(a.CreationTime, b.CreationTime)
+	})
+	return runs, nil
+}
+
+// summary rolls up the runs of an experiment.
+type summary struct {
+	Name      string
+	Runs      int
+	Running   int
+	Pending   int // Queued or suspended by Kueue.
+	Succeeded int
+	Failed    int
+	// Best is the succeeded run that took the least time, if any.
+	Best         string
+	BestDuration time.Duration
+	// LastSubmitted is the creation time of the newest run.
+	LastSubmitted string
+}
+
+func summarize(name string, runs []orchestrator.JobStatus, at time.Time) summary {
+	s := summary{Name: name, Runs: len(runs)}
+	for _, r := range runs {
+		switch r.Status {
+		case "Running":
+			s.Running++
+		case "Pending", "Suspended":
+			s.Pending++
+		case "Succeeded":
+			s.Succeeded++
+			if d, ok := runDuration(r, at); ok && (s.Best == "" || d < s.BestDuration) {
+				s.Best, s.BestDuration = r.Name, d
+			}
+		case "Failed":
+			s.Failed++
+		}
+		if r.CreationTime > s.LastSubmitted {
+			s.LastSubmitted = r.CreationTime
+		}
+	}
+	return s
+}
+
+// runDuration returns how long run has taken: until its completion when it
+// finished, or until at when it is still going.
+func runDuration(run orchestrator.JobStatus, at time.Time) (time.Duration, bool) {
+	start, err := time.Parse(time.RFC3339, run.CreationTime)
+	if err != nil {
+		return 0, false
+	}
+	end := at
+	if run.CompletionTime != "" {
+		if end, err = time.Parse(time.RFC3339, run.CompletionTime); err != nil {
+			return 0, false
+		}
+	} else if run.Status == "Succeeded" || run.Status == "Failed" {
+		return 0, false
+	}
+	return max(end.Sub(start), 0), true
+}
+
+// formatDuration prints d to the second, or "-" when it is unknown.
+func formatDuration(d time.Duration, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+// finished reports whether run has completed or failed.
+func finished(run orchestrator.JobStatus) bool {
+	return run.Status == "Succeeded" || run.Status == "Failed"
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package experiment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	"github.com/spf13/cobra"
+)
+
+func executeCommand(root *cobra.Command, args ...string) (string, error) {
+	buf := new(bytes.Buffer)
+	root.SetOut(buf)
+	root.SetErr(buf)
+	root.SetArgs(args)
+
+	err := root.Execute()
+
+	return buf.String(), err
+}
+
+type mockOrchestrator struct {
+	orchestrator.JobOrchestrator
+	jobs      []orchestrator.JobStatus
+	canceled  []string
+	cancelErr error
+}
+
+func (m *mockOrchestrator) ListJobs(_ context.Context, opts orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
+	var jobs []orchestrator.JobStatus
+	for _, j := range m.jobs {
+		if opts.Experiment == "" || j.Experiment == opts.Experiment {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *mockOrchestrator) CancelJob(_ context.Context, name string, opts orchestrator.CancelOptions) error {
+	m.canceled = append(m.canceled, name)
+	return m.cancelErr
+}
+
+var testRuns = []orchestrator.JobStatus{
+	{Name: "lr-r1", Namespace: "default", Experiment: "lr", Status: "Succeeded", CreationTime: "2026-10-16T08:00:00Z", CompletionTime: "2026-10-16T09:00:00Z"},
+	{Name: "lr-r2", Namespace: "default", Experiment: "lr", Status: "Failed", CreationTime: "2026-10-16T08:10:00Z", CompletionTime: "2026-10-16T08:20:00Z"},
+	{Name: "lr-r3", Namespace: "default", Experiment: "lr", Status: "Succeeded", CreationTime: "2026-10-16T08:20:00Z", CompletionTime: "2026-10-16T09:05:00Z"},
+	{Name: "lr-r4", Namespace: "default", Experiment: "lr", Status: "Running", CreationTime: "2026-10-16T09:30:00Z"},
+	{Name: "bs-r1", Namespace: "research", Experiment: "bs", Status: "Suspended", CreationTime: "2026-10-16T09:45:00Z"},
+	{Name: "adhoc", Namespace: "default", Status: "Running", CreationTime: "2026-10-16T09:50:00Z"},
+}
+
+func setupMock(t *testing.T) *mockOrchestrator {
+	t.Helper()
+	m := &mockOrchestrator{jobs: testRuns}
+	oldFactory, oldNow := gkeOrchestratorFactory, now
+	t.Cleanup(func() {
+		gkeOrchestratorFactory, now = oldFactory, oldNow
+		clusterName, location, projectID = "", "", ""
+		cancelYes, cancelForce = false, false
+	})
+	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator { return m }
+	now = func() time.Time { return time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC) }
+	t.Setenv("HOME", t.TempDir())
+	return m
+}
+
+var clusterFlags = []string{"--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project"}
+
+func TestSummarize(t *testing.T) {
+	at := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	got := summarize("lr", testRuns[:4], at)
+	want := summary{Name: "lr", Runs: 4, Running: 1, Succeeded: 2, Failed: 1, Best: "lr-r3", BestDuration: 45 * time.Minute, LastSubmitted: "2026-10-16T09:30:00Z"}
+	if got != want {
+		t.Errorf("summarize() = %+v, want %+v", got, want)
+	}
+	if d, ok := runDuration(testRuns[3], at); !ok || d != 30*time.Minute {
+		t.Errorf("expected a running run to be measured up to now, got %s, %v", d, ok)
+	}
+}
+
+func TestStatusCmd(t *testing.T) {
+	setupMock(t)
+	output, err := executeCommand(ExperimentCmd, append([]string{"status", "lr"}, clusterFlags...)...)
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	for _, want := range []string{
+		"Runs:       4 (1 running / 0 pending / 2 succeeded / 1 failed)",
+		"Best:       lr-r3 in 45m0s",
+		"lr-r4   default     Running",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	_, err = executeCommand(ExperimentCmd, append([]string{"status", "missing"}, clusterFlags...)...)
+	if err == nil || !strings.Contains(err.Error(), `no runs of experiment "missing"`) {
+		t.Errorf("expected an error for an experiment without runs, got %v", err)
+	}
+}
+
+func TestListCmd(t *testing.T) {
+	setupMock(t)
+	output, err := executeCommand(ExperimentCmd, append([]string{"list"}, clusterFlags...)...)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	want := `EXPERIMENT   RUNS   RUNNING   PENDING   SUCCEEDED   FAILED   BEST_DURATION   LAST_SUBMITTED
+bs           1      0         1         0           0        -               2026-10-16T09:45:00Z
+lr           4      1         0         2           1        45m0s           2026-10-16T09:30:00Z
+2 experiment(s).
+`
+	if output != want {
+		t.Errorf("list =\n%s\nwant:\n%s", output, want)
+	}
+}
+
+func TestCompareCmd(t *testing.T) {
+	setupMock(t)
+	output, err := executeCommand(ExperimentCmd, append([]string{"compare", "lr"}, clusterFlags...)...)
+	if err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	want := `RANK   RUN     STATUS      SUBMITTED              DURATION   VS_BEST
+1      lr-r3   Succeeded   2026-10-16T08:20:00Z   45m0s      best
+2      lr-r1   Succeeded   2026-10-16T08:00:00Z   1h0m0s     +15m0s (+33%)
+-      lr-r2   Failed      2026-10-16T08:10:00Z   10m0s      -
+-      lr-r4   Running     2026-10-16T09:30:00Z   30m0s      -
+`
+	if output != want {
+		t.Errorf("compare =\n%s\nwant:\n%s", output, want)
+	}
+
+	_, err = executeCommand(ExperimentCmd, append([]string{"compare", "lr", "bs-r1"}, clusterFlags...)...)
+	if err == nil || !strings.Contains(err.Error(), `run "bs-r1" is not part of experiment "lr"`) {
+		t.Errorf("expected an error for a run of another experiment, got %v", err)
+	}
+}
+
+func TestCancelCmd(t *testing.T) {
+	m := setupMock(t)
+	oldPrompt := shell.PromptYesNo
+	defer func() { shell.PromptYesNo = oldPrompt }()
+	shell.PromptYesNo = func(string) bool { return false }
+
+	if _, err := executeCommand(ExperimentCmd, append([]string{"cancel", "lr"}, clusterFlags...)...); err == nil || len(m.canceled) != 0 {
+		t.Fatalf("expected a declined confirmation to cancel nothing, got %v and %v", err, m.canceled)
+	}
+
+	output, err := executeCommand(ExperimentCmd, append([]string{"cancel", "lr", "--yes"}, clusterFlags...)...)
+	if err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if len(m.canceled) != 1 || m.canceled[0] != "lr-r4" {
+		t.Errorf("expected only the unfinished run to be canceled, got %v", m.canceled)
+	}
+	if !strings.Contains(output, "Canceled 1 of 1 run(s) of experiment lr.") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	m.canceled, m.cancelErr = nil, errors.New("forbidden")
+	_, err = executeCommand(ExperimentCmd, append([]string{"cancel", "bs", "--yes"}, clusterFlags...)...)
+	if err == nil || !strings.Contains(err.Error(), "failed to cancel bs-r1: forbidden") {
+		t.Errorf("expected the cancel failure to be reported, got %v", err)
+	}
+}
+
+func TestExperimentCmd_RequiresCluster(t *testing.T) {
+	setupMock(t)
+	t.Setenv("GCLUSTER_CLUSTER_NAME", "")
+	_, err := executeCommand(ExperimentCmd, "list", "--location", "us-central1-a", "--project", "test-project")
+	if err == nil || !strings.Contains(err.Error(), "cluster name is required") {
+		t.Errorf("expected a missing cluster error, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package experiment
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the experiments of the cluster.",
+	Long: `List the experiments that workloads of the cluster were submitted under,
+with the number of their runs by state and their fastest successful run.
+Workloads submitted without --experiment are not listed.`,
+	Args:         cobra.NoArgs,
+	RunE:         runList,
+	SilenceUsage: true,
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	runs, err := listRuns(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	byExperiment := map[string][]orchestrator.JobStatus{}
+	for _, r := range runs {
+		if r.Experiment != "" {
+			byExperiment[r.Experiment] = append(byExperiment[r.Experiment], r)
+		}
+	}
+
+	at := now()
+	var summaries []summary
+	for name, runs := range byExperiment {
+		summaries = append(summaries, summarize(name, runs, at))
+	}
+	// The experiments worked on last come first.
+	slices.SortFunc(summaries, func(a, b summary) int {
+		if c := strings.Compare(b.LastSubmitted, a.LastSubmitted); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "EXPERIMENT\tRUNS\tRUNNING\tPENDING\tSUCCEEDED\tFAILED\tBEST_DURATION\tLAST_SUBMITTED")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n", s.Name, s.Runs, s.Running, s.Pending, s.Succeeded, s.Failed, formatDuration(s.BestDuration, s.Best != ""), s.LastSubmitted)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%d experiment(s).\n", len(summaries))
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package experiment
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var StatusCmd = &cobra.Command{
+	Use:   "status [experiment]",
+	Short: "Summarize the runs of an experiment.",
+	Long: `Show how many runs of the experiment are running, pending, succeeded and
+failed, its fastest successful run, and the state and duration of each run.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runStatus,
+	SilenceUsage: true,
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	runs, err := experimentRuns(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	at := now()
+	printSummary(cmd.OutOrStdout(), summarize(args[0], runs, at))
+	fmt.Fprintln(cmd.OutOrStdout())
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RUN\tNAMESPACE\tSTATUS\tSUBMITTED\tDURATION")
+	for _, r := range runs {
+		d, ok := runDuration(r, at)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Name, r.Namespace, r.Status, r.CreationTime, formatDuration(d, ok))
+	}
+	return w.Flush()
+}
+
+func printSummary(out io.Writer, s summary) {
+	fmt.Fprintf(out, "Experiment: %s\n", s.Name)
+	fmt.Fprintf(out, "Runs:       %d (%d running / %d pending / %d succeeded / %d failed)\n", s.Runs, s.Running, s.Pending, s.Succeeded, s.Failed)
+	if s.Best != "" {
+		fmt.Fprintf(out, "Best:       %s in %s\n", s.Best, s.BestDuration.Round(time.Second))
+	} else {
+		fmt.Fprintln(out, "Best:       <none>, no run has succeeded yet")
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator/gke"
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var projectIDs []string
+
+var gkeOrchestratorFactory = func() *gke.GKEOrchestrator {
+	return gke.NewGKEOrchestrator()
+}
+
+var orc *gke.GKEOrchestrator
+
+// FleetCmd represents the base command for operations across clusters
+var FleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "[EXPERIMENTAL] Operate on all the clusters of one or more projects.",
+	Long:  `Aggregate and clean up gcluster workloads across every cluster of one or more projects. This feature is under active development.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		orc = gkeOrchestratorFactory()
+
+		if p := os.Getenv("GCLUSTER_PROJECT"); len(projectIDs) == 0 && p != "" {
+			projectIDs = []string{p}
+		}
+		if len(projectIDs) == 0 {
+			result := shell.ExecuteCommand("gcloud", "config", "get-value", "project")
+			ambientProject := strings.TrimSpace(result.Stdout)
+
+			if result.ExitCode != 0 || ambientProject == "" {
+				return fmt.Errorf("no Google Cloud project specified. Please provide one via the '--project' flag or the GCLUSTER_PROJECT environment variable, or set a default project using 'gcloud config set project <PROJECT_ID>'")
+			}
+
+			projectIDs = []string{ambientProject}
+			logging.Info("Using ambient project ID: %s", ambientProject)
+		}
+		return nil
+	},
+}
+
+func init() {
+	FleetCmd.PersistentFlags().StringSliceVarP(&projectIDs, "project", "p", nil, "Google Cloud Project IDs of the fleet. Can be specified multiple times or as a comma-separated list.")
+
+	FleetCmd.AddCommand(StatusCmd)
+	FleetCmd.AddCommand(GCCmd)
+}
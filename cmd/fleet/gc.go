@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcOlderThan string
+	gcOutputs   bool
+	gcDryRun    bool
+)
+
+var GCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Deletes finished gcluster workloads and expired outputs in every cluster of the fleet.",
+	Long: `Sweeps every running GKE cluster of the projects selected with --project for
+gcluster workloads that completed or failed longer than --older-than ago, and
+deletes them. Running and suspended workloads are never removed.
+
+With --outputs, it also applies the retention rules that jobs declared with
+--retention or the retention field of a job spec: the outputs and checkpoints
+past them are deleted from Cloud Storage and from the Filestore and PVC
+volumes they were written to.
+
+Use --dry-run to only report what would be deleted.`,
+	RunE:         runFleetGC,
+	SilenceUsage: true,
+}
+
+func init() {
+	GCCmd.Flags().StringVar(&gcOlderThan, "older-than", "", "Retention of finished workloads, e.g. 14d, 36h.")
+	GCCmd.Flags().BoolVar(&gcOutputs, "outputs", false, "Delete the outputs and checkpoints past the retention rules declared by submitted jobs.")
+	GCCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Report what would be deleted without deleting it.")
+}
+
+func runFleetGC(cmd *cobra.Command, args []string) error {
+	if gcOlderThan == "" && !gcOutputs {
+		return fmt.Errorf("specify --older-than, --outputs or both")
+	}
+	var retention time.Duration
+	if gcOlderThan != "" {
+		var err error
+		if retention, err = parseRetention(gcOlderThan); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	if retention > 0 {
+		if err := collectWorkloads(cmd, retention); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if gcOutputs {
+		if err := collectOutputs(cmd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func collectWorkloads(cmd *cobra.Command, retention time.Duration) error {
+	logging.Info("Collecting workloads finished more than %s ago in project(s) %s...", gcOlderThan, strings.Join(projectIDs, ", "))
+	expired, sweepErr := orc.CollectGarbage(cmd.Context(), orchestrator.GCOptions{
+		ProjectIDs: projectIDs,
+		OlderThan:  retention,
+		DryRun:     gcDryRun,
+	})
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tCLUSTER\tLOCATION\tNAMESPACE\tNAME\tSTATUS\tFINISHED\tACTION")
+	failed := 0
+	for _, e := range expired {
+		action := "would delete"
+		switch {
+		case e.Error != "":
+			action = "error: " + e.Error
+			failed++
+		case e.Deleted:
+			action = "deleted"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.ProjectID, e.ClusterName, e.ClusterLocation, e.Namespace, e.Name, e.Status, e.FinishedAt.Format(time.RFC3339), action)
+	}
+	w.Flush()
+
+	if gcDryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d workload(s) would be deleted. Run without --dry-run to delete them.\n", len(expired))
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "Deleted %d of %d expired workload(s).\n", len(expired)-failed, len(expired))
+	}
+
+	if sweepErr != nil {
+		return sweepErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d workload(s)", failed)
+	}
+	return nil
+}
+
+func collectOutputs(cmd *cobra.Command) error {
+	logging.Info("Applying the retention rules of outputs and checkpoints in project(s) %s...", strings.Join(projectIDs, ", "))
+	expired, sweepErr := orc.CollectExpiredOutputs(cmd.Context(), orchestrator.GCOptions{
+		ProjectIDs: projectIDs,
+		DryRun:     gcDryRun,
+	})
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tCLUSTER\tNAMESPACE\tENTRY\tMODIFIED\tACTION")
+	failed := 0
+	for _, e := range expired {
+		action := "would delete"
+		switch {
+		case e.Error != "":
+			action = "error: " + e.Error
+			failed++
+		case e.Deleted:
+			action = "deleted"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.ProjectID, e.ClusterName, e.Namespace, e.Entry, e.ModifiedAt.Format(time.RFC3339), action)
+	}
+	w.Flush()
+
+	if gcDryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d output(s) would be deleted. Run without --dry-run to delete them.\n", len(expired))
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "Deleted %d of %d expired output(s).\n", len(expired)-failed, len(expired))
+	}
+
+	if sweepErr != nil {
+		return sweepErr
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d output(s)", failed)
+	}
+	return nil
+}
+
+// parseRetention parses a retention period: a number of days such as 14d, or
+// a Go duration such as 36h.
+func parseRetention(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --older-than %q: the number of days must be a positive integer", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --older-than %q: must be a positive number of days (e.g. 14d) or a duration (e.g. 36h)", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"hpc-toolkit/pkg/orchestrator/gke"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"14d": 14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"90m": 90 * time.Minute,
+	} {
+		got, err := parseRetention(value)
+		if err != nil || got != want {
+			t.Errorf("parseRetention(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "d", "0d", "-1d", "1.5d", "two weeks", "-1h"} {
+		if _, err := parseRetention(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestGCCmd_DryRun(t *testing.T) {
+	defer func() { projectIDs, gcOlderThan, gcOutputs, gcDryRun = nil, "", false, false }()
+
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func() *gke.GKEOrchestrator {
+		g := gke.NewGKEOrchestrator()
+		g.SetExecutor(&mockFleetExecutor{})
+		return g
+	}
+
+	output, err := executeCommand(FleetCmd, "gc", "--older-than", "14d", "--dry-run", "--project", "dev,prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v, output: %s", err, output)
+	}
+	if !strings.Contains(output, "PROJECT") || !strings.Contains(output, "0 workload(s) would be deleted") {
+		t.Errorf("expected a dry-run report, got %s", output)
+	}
+
+	_, err = executeCommand(FleetCmd, "gc", "--older-than", "soon", "--project", "dev")
+	if err == nil || !strings.Contains(err.Error(), `invalid --older-than "soon"`) {
+		t.Errorf("expected an invalid retention error, got %v", err)
+	}
+
+	gcOlderThan = ""
+	_, err = executeCommand(FleetCmd, "gc", "--project", "dev")
+	if err == nil || !strings.Contains(err.Error(), "specify --older-than, --outputs or both") {
+		t.Errorf("expected an error without --older-than or --outputs, got %v", err)
+	}
+
+	output, err = executeCommand(FleetCmd, "gc", "--outputs", "--dry-run", "--project", "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v, output: %s", err, output)
+	}
+	if !strings.Contains(output, "ENTRY") || !strings.Contains(output, "0 output(s) would be deleted") || strings.Contains(output, "workload(s)") {
+		t.Errorf("expected only a dry-run report of outputs, got %s", output)
+	}
+}
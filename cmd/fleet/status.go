@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusFilter string
+	nameFilter   string
+	parallelism  int
+)
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Lists the gcluster workloads of every cluster of the fleet.",
+	Long: `Queries every running GKE cluster of the --project projects concurrently,
+up to --parallelism clusters at a time, and prints one table of their gcluster
+workloads with the cluster each one runs on. Clusters that cannot be queried
+are reported after the table and do not hide the workloads of the others.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if statusFilter != "" {
+			allowed := map[string]bool{
+				"Pending":   true,
+				"Running":   true,
+				"Succeeded": true,
+				"Failed":    true,
+				"Suspended": true,
+			}
+			if !allowed[statusFilter] {
+				return fmt.Errorf("invalid value for --status: %s. Allowed values are: Pending, Running, Succeeded, Failed, Suspended", statusFilter)
+			}
+		}
+		if parallelism < 1 {
+			return fmt.Errorf("--parallelism must be at least 1, got %d", parallelism)
+		}
+		return nil
+	},
+	RunE:         runFleetStatus,
+	SilenceUsage: true,
+}
+
+func init() {
+	StatusCmd.Flags().StringVar(&statusFilter, "status", "", "Filter workloads by status (e.g. Running, Failed, Succeeded).")
+	StatusCmd.Flags().StringVar(&nameFilter, "name-contains", "", "Filter workloads by name containing the specified string.")
+	StatusCmd.Flags().IntVar(&parallelism, "parallelism", 8, "Maximum number of clusters queried at a time.")
+}
+
+func runFleetStatus(cmd *cobra.Command, args []string) error {
+	workloads, err := orc.FleetStatus(cmd.Context(), orchestrator.FleetStatusOptions{
+		ProjectIDs:   projectIDs,
+		Parallelism:  parallelism,
+		Status:       statusFilter,
+		NameContains: nameFilter,
+	})
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tCLUSTER\tLOCATION\tNAMESPACE\tNAME\tSTATUS\tCREATION_TIME\tCOMPLETION_TIME")
+	for _, wl := range workloads {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", wl.ProjectID, wl.ClusterName, wl.ClusterLocation, wl.Namespace, wl.Name, wl.Status, wl.CreationTime, wl.CompletionTime)
+	}
+	w.Flush()
+	fmt.Fprintf(cmd.OutOrStdout(), "%d workload(s).\n", len(workloads))
+
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fleet
+
+import (
+	"bytes"
+	"hpc-toolkit/pkg/orchestrator/gke"
+	"hpc-toolkit/pkg/shell"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func executeCommand(root *cobra.Command, args ...string) (string, error) {
+	buf := new(bytes.Buffer)
+	root.SetOut(buf)
+	root.SetErr(buf)
+	root.SetArgs(args)
+
+	err := root.Execute()
+
+	return buf.String(), err
+}
+
+type mockFleetExecutor struct{}
+
+func (m *mockFleetExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	if name == "gcloud" && len(args) > 2 && args[0] == "container" && args[1] == "clusters" && args[2] == "list" {
+		return shell.CommandResult{Stdout: `[]`}
+	}
+	return shell.CommandResult{ExitCode: 1, Stderr: "unexpected command"}
+}
+
+func (m *mockFleetExecutor) ExecuteCommandStream(name string, args ...string) error {
+	return nil
+}
+
+func TestStatusCmd(t *testing.T) {
+	defer func() { projectIDs, statusFilter, parallelism = nil, "", 8 }()
+
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func() *gke.GKEOrchestrator {
+		g := gke.NewGKEOrchestrator()
+		g.SetExecutor(&mockFleetExecutor{})
+		return g
+	}
+
+	output, err := executeCommand(FleetCmd, "status", "--project", "dev,prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v, output: %s", err, output)
+	}
+	if !strings.Contains(output, "PROJECT") || !strings.Contains(output, "CLUSTER") || !strings.Contains(output, "0 workload(s).") {
+		t.Errorf("expected an empty fleet table, got %s", output)
+	}
+
+	_, err = executeCommand(FleetCmd, "status", "--project", "dev", "--status", "Done")
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --status: Done") {
+		t.Errorf("expected an invalid status error, got %v", err)
+	}
+
+	_, err = executeCommand(FleetCmd, "status", "--project", "dev", "--status", "", "--parallelism", "0")
+	if err == nil || !strings.Contains(err.Error(), "--parallelism must be at least 1") {
+		t.Errorf("expected an invalid parallelism error, got %v", err)
+	}
+}
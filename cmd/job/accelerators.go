@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"path/filepath"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/localstate"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// acceleratorsFileName is the file in the state directory defining
+// accelerators that are not in the built-in catalog yet.
+const acceleratorsFileName = "accelerators.yaml"
+
+// loadUserAccelerators registers the accelerators of the user's
+// accelerators file, if any.
+func loadUserAccelerators() error {
+	path, err := localstate.Path(acceleratorsFileName)
+	if err != nil {
+		return err
+	}
+	defs, err := config.LoadAcceleratorDefinitions(path)
+	if err != nil {
+		return err
+	}
+	if len(defs) > 0 {
+		logging.Info("Loaded %d accelerator definitions from %s", len(defs), path)
+		config.RegisterAccelerators(defs)
+	}
+	return nil
+}
+
+// loadAcceleratorPresets registers the accelerator presets of the managed
+// profile and then those of --presets-file, so that the latter win.
+func loadAcceleratorPresets(mp *orchestrator.ManagedProfile, presetsFile string) error {
+	var paths []string
+	if mp != nil && mp.PresetsFile != "" {
+		path := mp.PresetsFile
+		if !filepath.IsAbs(path) {
+			profilePath, err := managedProfileFile.Path()
+			if err != nil {
+				return err
+			}
+			path = filepath.Join(filepath.Dir(profilePath), path)
+		}
+		paths = append(paths, path)
+	}
+	if presetsFile != "" {
+		paths = append(paths, presetsFile)
+	}
+	for _, path := range paths {
+		presets, err := config.LoadAcceleratorPresets(path)
+		if err != nil {
+			return err
+		}
+		logging.Info("Loaded %d accelerator presets from %s", len(presets.Presets), path)
+		config.RegisterAcceleratorPresets(presets)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/localstate"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var AttachCmd = &cobra.Command{
+	Use:   "attach [job-name]",
+	Short: "Stream the logs of a job until it finishes, resuming an interrupted session.",
+	Long: `The 'attach' command streams the logs of all containers in a job's pods until
+the job finishes, and fails if the job does not complete successfully.
+
+The progress of the session is recorded locally, so that if the process is
+interrupted (e.g. the laptop sleeps or the SSH connection drops), running
+'attach' again resumes from the last line printed instead of from the start.
+Lines logged in the few seconds before the interruption may be printed again.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runAttachCmd,
+	SilenceUsage: true,
+}
+
+var (
+	attachSince   time.Duration
+	attachRestart bool
+)
+
+// attachSessionFile records how far the sessions of 'gcluster job attach' got,
+// so that an interrupted one can be resumed.
+var attachSessionFile = localstate.File{Name: "attach_sessions.json", Version: 1}
+
+// attachSessionTTL is how long an unfinished session is kept.
+const attachSessionTTL = 7 * 24 * time.Hour
+
+type attachSessions struct {
+	Sessions map[string]attachSessionState `json:"sessions"`
+}
+
+type attachSessionState struct {
+	orchestrator.AttachCheckpoint
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func init() {
+	AttachCmd.Flags().DurationVar(&attachSince, "since", 0, "Only print logs newer than a relative duration (e.g. 10m, 2h) when no session is resumed")
+	AttachCmd.Flags().BoolVar(&attachRestart, "restart", false, "Start a new session instead of resuming the recorded one")
+}
+
+func runAttachCmd(cmd *cobra.Command, args []string) error {
+	jobName := args[0]
+	if attachSince < 0 {
+		return fmt.Errorf("--since must be a positive duration, got %s", attachSince)
+	}
+
+	key := attachSessionKey(jobName)
+	opts := orchestrator.AttachOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+		OnCheckpoint: func(cp orchestrator.AttachCheckpoint) {
+			if err := saveAttachSession(key, &cp); err != nil {
+				logging.Warn("Failed to record the session of job '%s': %v", jobName, err)
+			}
+		},
+	}
+	if attachSince > 0 {
+		opts.Since = attachSince.String()
+	}
+	if !attachRestart {
+		cp, err := loadAttachSession(key)
+		if err != nil {
+			logging.Warn("Failed to load the session of job '%s': %v. Starting a new session.", jobName, err)
+		}
+		opts.Resume = cp
+	}
+
+	status, err := orc.AttachJob(cmd.Context(), jobName, opts)
+	if err != nil {
+		return err
+	}
+	if err := saveAttachSession(key, nil); err != nil {
+		logging.Warn("Failed to remove the session of job '%s': %v", jobName, err)
+	}
+	if status != "Completed" {
+		return fmt.Errorf("job '%s' finished with status %s", jobName, status)
+	}
+	logging.Info("Job '%s' completed successfully.", jobName)
+	return nil
+}
+
+// attachSessionKey identifies the sessions of a job across clusters.
+func attachSessionKey(jobName string) string {
+	return strings.Join([]string{projectID, location, clusterName, jobName}, "/")
+}
+
+func loadAttachSession(key string) (*orchestrator.AttachCheckpoint, error) {
+	var s attachSessions
+	if err := attachSessionFile.Load(&s); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	state, ok := s.Sessions[key]
+	if !ok {
+		return nil, nil
+	}
+	return &state.AttachCheckpoint, nil
+}
+
+// saveAttachSession records the checkpoint of a session, or removes it when
+// cp is nil. Sessions not updated for attachSessionTTL are dropped.
+func saveAttachSession(key string, cp *orchestrator.AttachCheckpoint) error {
+	var s attachSessions
+	return attachSessionFile.Update(&s, func() error {
+		if s.Sessions == nil {
+			s.Sessions = map[string]attachSessionState{}
+		}
+		for k, state := range s.Sessions {
+			if time.Since(state.UpdatedAt) > attachSessionTTL {
+				delete(s.Sessions, k)
+			}
+		}
+		if cp == nil {
+			delete(s.Sessions, key)
+			return nil
+		}
+		s.Sessions[key] = attachSessionState{AttachCheckpoint: *cp, UpdatedAt: time.Now()}
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestAttachCmd_Resume(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	mock := &mockJobOrchestrator{}
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+	attach := func(extra ...string) error {
+		attachSince, attachRestart = 0, false
+		args := append([]string{"attach", "train", "--cluster", "c", "--location", "us-central1", "--project", "p"}, extra...)
+		_, err := executeCommand(JobCmd, args...)
+		return err
+	}
+
+	// The first session is interrupted after a checkpoint.
+	cp := orchestrator.AttachCheckpoint{
+		LogCursors:      map[string]time.Time{"pod/train-0/main": time.Date(2026, 1, 1, 0, 0, 1, 0, time.UTC)},
+		ResourceVersion: "10",
+		Status:          "Running",
+	}
+	mock.attachRun = func(opts orchestrator.AttachOptions) (string, error) {
+		opts.OnCheckpoint(cp)
+		return "", errors.New("interrupted")
+	}
+	if err := attach("--since", "10m"); err == nil {
+		t.Fatal("expected the interruption to be returned")
+	}
+	if got := mock.attachOpts[0]; got.Resume != nil || got.Since != "10m0s" {
+		t.Errorf("first session = {Resume: %v, Since: %q}, want a new session since 10m0s", got.Resume, got.Since)
+	}
+
+	// The second one resumes from the checkpoint, and the job fails.
+	mock.attachRun = func(opts orchestrator.AttachOptions) (string, error) { return "Failed", nil }
+	if err := attach(); err == nil || !strings.Contains(err.Error(), "finished with status Failed") {
+		t.Errorf("expected the failed job to be reported, got %v", err)
+	}
+	if got := mock.attachOpts[1].Resume; got == nil || !reflect.DeepEqual(*got, cp) {
+		t.Errorf("resumed from %+v, want %+v", got, cp)
+	}
+
+	// A finished session is removed.
+	mock.attachRun = nil
+	if err := attach(); err != nil {
+		t.Fatalf("attach failed: %v", err)
+	}
+	if got := mock.attachOpts[2].Resume; got != nil {
+		t.Errorf("expected a new session after the job finished, got %+v", got)
+	}
+
+	// --restart ignores a recorded session.
+	mock.attachRun = func(opts orchestrator.AttachOptions) (string, error) {
+		opts.OnCheckpoint(cp)
+		return "", errors.New("interrupted")
+	}
+	_ = attach()
+	mock.attachRun = nil
+	if err := attach("--restart"); err != nil {
+		t.Fatalf("attach failed: %v", err)
+	}
+	if got := mock.attachOpts[4].Resume; got != nil {
+		t.Errorf("expected --restart to start a new session, got %+v", got)
+	}
+}
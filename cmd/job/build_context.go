@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inferBuildContext derives a build context from the script referenced in
+// command. If the script lives in the git repository containing the current
+// directory, the repository root is used; otherwise the script's own directory
+// is. Because the context becomes the root of the image, the script reference in
+// the returned command is rewritten relative to the chosen context.
+func inferBuildContext(command string) (string, string, error) {
+	script := findScriptInCommand(command)
+	if script == "" {
+		return "", "", fmt.Errorf("could not find a local script in --command %q to infer the build context from", command)
+	}
+
+	absScript, err := filepath.Abs(script)
+	if err == nil {
+		absScript, err = filepath.EvalSymlinks(absScript)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve script path %q: %w", script, err)
+	}
+
+	context := filepath.Dir(absScript)
+	if root := gitTopLevel(); root != "" && isWithin(root, absScript) {
+		context = root
+	}
+
+	rel, err := filepath.Rel(context, absScript)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve script %q relative to %q: %w", script, context, err)
+	}
+	return context, replaceCommandToken(command, script, filepath.ToSlash(rel)), nil
+}
+
+// findScriptInCommand returns the first non-flag token of command that names
+// an existing regular file relative to the current directory.
+func findScriptInCommand(command string) string {
+	for _, tok := range strings.Fields(command) {
+		if strings.HasPrefix(tok, "-") || filepath.IsAbs(tok) {
+			continue
+		}
+		if fi, err := os.Stat(tok); err == nil && fi.Mode().IsRegular() {
+			return tok
+		}
+	}
+	return ""
+}
+
+func gitTopLevel() string {
+	res := shell.ExecuteCommand("git", "rev-parse", "--show-toplevel")
+	if res.ExitCode != 0 {
+		return ""
+	}
+	root := strings.TrimSpace(res.Stdout)
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	return root
+}
+
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// replaceCommandToken replaces the first whitespace-delimited occurrence of
+// old in command, leaving the rest of the command untouched.
+func replaceCommandToken(command, old, new string) string {
+	isSpace := func(b byte) bool { return b == ' ' || b == '\t' || b == '\n' }
+	for start := 0; start < len(command); {
+		i := strings.Index(command[start:], old)
+		if i < 0 {
+			break
+		}
+		i += start
+		end := i + len(old)
+		if (i == 0 || isSpace(command[i-1])) && (end == len(command) || isSpace(command[end])) {
+			return command[:i] + new + command[end:]
+		}
+		start = i + 1
+	}
+	return command
+}
+
+// resolveBuildContext fills in --build-context from --command when it was
+// omitted for a --base-image build, after confirmation from the user.
+func resolveBuildContext() error {
+	if baseImage == "" || buildContext != "" || commandToRun == "" {
+		return nil
+	}
+	context, command, err := inferBuildContext(commandToRun)
+	if err != nil {
+		return fmt.Errorf("a --build-context must be provided when --base-image is used for a Crane build (%w)", err)
+	}
+
+	prompt := fmt.Sprintf("No --build-context given. Use %q as the build context", context)
+	if command != commandToRun {
+		prompt += fmt.Sprintf(" and run %q", command)
+	}
+	if !shell.PromptYesNo(prompt + "?") {
+		return fmt.Errorf("a --build-context must be provided when --base-image is used for a Crane build")
+	}
+	buildContext, commandToRun = context, command
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/shell"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupScriptDir(t *testing.T, gitRoot bool) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "train"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "train", "train.py"), []byte("print('hi')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	oldExec := shell.ExecuteCommand
+	t.Cleanup(func() { shell.ExecuteCommand = oldExec })
+	shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+		if gitRoot {
+			return shell.CommandResult{ExitCode: 0, Stdout: dir + "\n"}
+		}
+		return shell.CommandResult{ExitCode: 128, Stderr: "fatal: not a git repository"}
+	}
+	return dir
+}
+
+func TestInferBuildContext_ScriptDir(t *testing.T) {
+	dir := setupScriptDir(t, false)
+
+	ctx, cmd, err := inferBuildContext("python  train/train.py --msg 'a  b'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx != filepath.Join(dir, "train") {
+		t.Errorf("context = %q, want %q", ctx, filepath.Join(dir, "train"))
+	}
+	if cmd != "python  train.py --msg 'a  b'" {
+		t.Errorf("command = %q", cmd)
+	}
+}
+
+func TestInferBuildContext_GitRoot(t *testing.T) {
+	dir := setupScriptDir(t, true)
+
+	ctx, cmd, err := inferBuildContext("torchrun --nproc_per_node=8 train/train.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx != dir {
+		t.Errorf("context = %q, want repo root %q", ctx, dir)
+	}
+	if cmd != "torchrun --nproc_per_node=8 train/train.py" {
+		t.Errorf("command should be unchanged, got %q", cmd)
+	}
+}
+
+func TestInferBuildContext_NoScript(t *testing.T) {
+	setupScriptDir(t, false)
+
+	if _, _, err := inferBuildContext("python -m mypkg.train"); err == nil {
+		t.Fatal("expected error when no script is referenced")
+	}
+}
+
+func TestResolveBuildContext_Prompt(t *testing.T) {
+	dir := setupScriptDir(t, false)
+	resetSubmitCmdFlags()
+	t.Cleanup(resetSubmitCmdFlags)
+
+	oldPrompt := shell.PromptYesNo
+	t.Cleanup(func() { shell.PromptYesNo = oldPrompt })
+
+	shell.PromptYesNo = func(prompt string) bool { return false }
+	baseImage, commandToRun = "python:3.11", "python train/train.py"
+	if err := resolveBuildContext(); err == nil || !strings.Contains(err.Error(), "--build-context must be provided") {
+		t.Fatalf("expected error when inference is declined, got %v", err)
+	}
+	if buildContext != "" {
+		t.Errorf("build context should stay empty when declined, got %q", buildContext)
+	}
+
+	var asked string
+	shell.PromptYesNo = func(prompt string) bool { asked = prompt; return true }
+	if err := resolveBuildContext(); err != nil {
+		t.Fatal(err)
+	}
+	if buildContext != filepath.Join(dir, "train") || commandToRun != "python train.py" {
+		t.Errorf("got context %q and command %q", buildContext, commandToRun)
+	}
+	if !strings.Contains(asked, `run "python train.py"`) {
+		t.Errorf("prompt should mention the rewritten command, got %q", asked)
+	}
+}
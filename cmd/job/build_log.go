@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"hpc-toolkit/pkg/imagebuilder"
+	"hpc-toolkit/pkg/logging"
+)
+
+// setupBuildLog routes the log output of a submit according to -v and
+// --build-log-file, and returns a func restoring the defaults. The console
+// gets the details of the image build only at the requested verbosity, while
+// the build log gets everything, including the regular messages.
+func setupBuildLog() (func(), error) {
+	console := logging.InfoOutput()
+	var file *os.File
+	if buildLogFile != "" {
+		if err := os.MkdirAll(filepath.Dir(buildLogFile), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create the directory of build log %s: %w", buildLogFile, err)
+		}
+		f, err := os.Create(buildLogFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create build log %s: %w", buildLogFile, err)
+		}
+		file = f
+		logging.SetInfoOutput(io.MultiWriter(console, file))
+	}
+
+	// output returns the writer of the messages shown on the console from
+	// verbosity level on.
+	output := func(level int) io.Writer {
+		var writers []io.Writer
+		if verbosity >= level {
+			writers = append(writers, console)
+		}
+		if file != nil {
+			writers = append(writers, file)
+		}
+		switch len(writers) {
+		case 0:
+			return io.Discard
+		case 1:
+			return writers[0]
+		}
+		return io.MultiWriter(writers...)
+	}
+	logging.SetDebugOutput(output(1))
+	imagebuilder.SetRegistryLogOutput(output(1), output(2))
+
+	return func() {
+		logging.SetInfoOutput(console)
+		logging.SetDebugOutput(io.Discard)
+		imagebuilder.SetRegistryLogOutput(io.Discard, io.Discard)
+		if file != nil {
+			file.Close()
+		}
+	}, nil
+}
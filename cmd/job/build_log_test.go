@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/logging"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+)
+
+func TestSetupBuildLog(t *testing.T) {
+	defer func() { verbosity, buildLogFile = 0, "" }()
+
+	var console bytes.Buffer
+	logging.SetInfoOutput(&console)
+	defer logging.SetInfoOutput(os.Stdout)
+
+	emit := func() {
+		logging.Info("info message")
+		logging.Debug("debug message")
+		logs.Progress.Print("registry progress")
+		logs.Debug.Print("registry request")
+	}
+
+	for _, tc := range []struct {
+		verbosity int
+		want      []string
+		notWant   []string
+	}{
+		{0, []string{"info message"}, []string{"debug message", "registry progress", "registry request"}},
+		{1, []string{"info message", "debug message", "registry progress"}, []string{"registry request"}},
+		{2, []string{"info message", "debug message", "registry progress", "registry request"}, nil},
+	} {
+		console.Reset()
+		verbosity = tc.verbosity
+		buildLogFile = filepath.Join(t.TempDir(), "logs", "build.log")
+		restore, err := setupBuildLog()
+		if err != nil {
+			t.Fatal(err)
+		}
+		emit()
+		restore()
+
+		for _, want := range tc.want {
+			if !strings.Contains(console.String(), want) {
+				t.Errorf("-v=%d: console is missing %q:\n%s", tc.verbosity, want, console.String())
+			}
+		}
+		for _, notWant := range tc.notWant {
+			if strings.Contains(console.String(), notWant) {
+				t.Errorf("-v=%d: console unexpectedly has %q:\n%s", tc.verbosity, notWant, console.String())
+			}
+		}
+		data, err := os.ReadFile(buildLogFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"info message", "debug message", "registry progress", "registry request"} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("-v=%d: build log is missing %q:\n%s", tc.verbosity, want, data)
+			}
+		}
+	}
+
+	console.Reset()
+	emit()
+	if got := console.String(); strings.Contains(got, "debug message") || strings.Contains(got, "registry") {
+		t.Errorf("expected the defaults to be restored, got:\n%s", got)
+	}
+}
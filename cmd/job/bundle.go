@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var BundleCmd = &cobra.Command{
+	Use:   "bundle [job-name]",
+	Short: "Collect a job's diagnostics into an archive for bug reports.",
+	Long: `The 'bundle' command writes a gzipped tarball with the job's JobSet manifest,
+its pods and their status, events, the last lines of every container's logs, the
+conditions of the nodes it runs on, and the gcluster version and flags used.
+
+Env values whose names look like secrets (tokens, passwords, keys) are redacted.
+Review the archive before sharing it.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runBundleCmd,
+	SilenceUsage: true,
+}
+
+var (
+	bundleOutputPath string
+	bundleLogLines   int
+)
+
+func init() {
+	BundleCmd.Flags().StringVarP(&bundleOutputPath, "out", "o", "", "Path of the archive to write. Defaults to gcluster-bundle-<job>-<timestamp>.tgz in the current directory.")
+	BundleCmd.Flags().IntVar(&bundleLogLines, "log-lines", 500, "Log lines kept per container.")
+}
+
+func runBundleCmd(cmd *cobra.Command, args []string) error {
+	if bundleLogLines <= 0 {
+		return fmt.Errorf("--log-lines must be positive, got %d", bundleLogLines)
+	}
+
+	opts := orchestrator.BundleOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+		OutputPath:      bundleOutputPath,
+		LogTailLines:    bundleLogLines,
+		ClientInfo:      bundleClientInfo(cmd),
+	}
+
+	path, err := orc.CreateBundle(cmd.Context(), args[0], opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Support bundle written to %s\n", path)
+	return nil
+}
+
+// bundleClientInfo describes the gcluster binary and the flags it was run with.
+// Secret-looking flag values are dropped and the home directory is shortened
+// to ~ so the bundle does not leak local user names.
+func bundleClientInfo(cmd *cobra.Command) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "gcluster version: %s\n", config.GetToolkitVersion())
+	fmt.Fprintf(&sb, "platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&sb, "cluster: %s\nlocation: %s\nproject: %s\n", clusterName, location, projectID)
+	fmt.Fprintf(&sb, "command: %s\n", cmd.CommandPath())
+
+	home, _ := os.UserHomeDir()
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		if orchestrator.IsSensitiveName(f.Name) {
+			value = orchestrator.RedactedValue
+		} else if home != "" && home != "/" {
+			value = strings.ReplaceAll(value, home, "~")
+		}
+		fmt.Fprintf(&sb, "  --%s=%s\n", f.Name, value)
+	})
+	return sb.String()
+}
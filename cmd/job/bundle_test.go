@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestBundleCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+	mock := &mockJobOrchestrator{}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+	t.Cleanup(func() { bundleOutputPath, bundleLogLines = "", 500 })
+
+	output, err := executeCommand(JobCmd, "bundle", "train", "--out", "train.tgz", "--log-lines", "50", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err != nil {
+		t.Fatalf("bundle command failed: %v", err)
+	}
+	if mock.bundleName != "train" || mock.bundleOpts.OutputPath != "train.tgz" || mock.bundleOpts.LogTailLines != 50 {
+		t.Errorf("unexpected call: %q %+v", mock.bundleName, mock.bundleOpts)
+	}
+	for _, want := range []string{"gcluster version:", "cluster: test-cluster", "--log-lines=50"} {
+		if !strings.Contains(mock.bundleOpts.ClientInfo, want) {
+			t.Errorf("expected client info to contain %q, got:\n%s", want, mock.bundleOpts.ClientInfo)
+		}
+	}
+	if !strings.Contains(output, "Support bundle written to train.tgz") {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
+
+func TestBundleClientInfo_RedactsSecrets(t *testing.T) {
+	cmd := &cobra.Command{Use: "bundle"}
+	cmd.Flags().String("hf-token", "", "")
+	cmd.Flags().String("out", "", "")
+	if err := cmd.Flags().Parse([]string{"--hf-token", "hf_abc123", "--out", "b.tgz"}); err != nil {
+		t.Fatal(err)
+	}
+
+	info := bundleClientInfo(cmd)
+	if strings.Contains(info, "hf_abc123") || !strings.Contains(info, "--hf-token=<redacted>") {
+		t.Errorf("expected --hf-token to be redacted, got:\n%s", info)
+	}
+	if !strings.Contains(info, "--out=b.tgz") {
+		t.Errorf("expected --out to be kept, got:\n%s", info)
+	}
+}
@@ -15,27 +15,63 @@
 package job
 
 import (
+	"fmt"
 	"hpc-toolkit/pkg/orchestrator"
 
 	"github.com/spf13/cobra"
 )
 
 var CancelJobCmd = &cobra.Command{
-	Use:          "cancel [job-name]",
-	Short:        "Cancel a job in the cluster.",
+	Use:   "cancel [job-name]",
+	Short: "Cancel a job in the cluster.",
+	Long: `The 'cancel' command deletes the job's JobSet, which stops and removes its pods.
+Use --grace-period to shorten (or extend) the time pods get to shut down, or
+--force to remove them immediately. --delete-volumes also removes the volume
+claims gcluster created for the job's Filestore mounts if no other job uses them.`,
 	Args:         cobra.ExactArgs(1),
 	RunE:         runCancelJob,
 	SilenceUsage: true,
 }
 
+var (
+	cancelGracePeriod   string
+	cancelForce         bool
+	cancelDeleteVolumes bool
+)
+
+func init() {
+	CancelJobCmd.Flags().StringVar(&cancelGracePeriod, "grace-period", "", "Time given to the job's pods to terminate gracefully (e.g. 10s, 2m). Defaults to the period from the pod spec.")
+	CancelJobCmd.Flags().BoolVar(&cancelForce, "force", false, "Remove the job's pods immediately without waiting for graceful termination.")
+	CancelJobCmd.Flags().BoolVar(&cancelDeleteVolumes, "delete-volumes", false, "Also delete the volume claims gcluster created for the job's mounts when no other job uses them.")
+}
+
 func runCancelJob(cmd *cobra.Command, args []string) error {
 	jobName := args[0]
 
+	var gracePeriod *int64
+	if cancelGracePeriod != "" {
+		seconds, err := parseDurationToSeconds(cancelGracePeriod, "--grace-period")
+		if err != nil {
+			return err
+		}
+		if seconds < 0 {
+			return fmt.Errorf("--grace-period must not be negative, got %s", cancelGracePeriod)
+		}
+		gp := int64(seconds)
+		gracePeriod = &gp
+	}
+	if cancelForce && gracePeriod != nil && *gracePeriod > 0 {
+		return fmt.Errorf("--force deletes pods immediately and cannot be combined with a positive --grace-period")
+	}
+
 	opts := orchestrator.CancelOptions{
-		ClusterName:     clusterName,
-		ClusterLocation: location,
-		ProjectID:       projectID,
+		ClusterName:        clusterName,
+		ClusterLocation:    location,
+		ProjectID:          projectID,
+		Force:              cancelForce,
+		DeleteVolumes:      cancelDeleteVolumes,
+		GracePeriodSeconds: gracePeriod,
 	}
 
-	return orc.CancelJob(jobName, opts)
+	return orc.CancelJob(cmd.Context(), jobName, opts)
 }
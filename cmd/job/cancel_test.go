@@ -15,12 +15,16 @@
 package job
 
 import (
+	"context"
 	"fmt"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/orchestrator/gke"
 	"hpc-toolkit/pkg/shell"
+	"io"
 	"strings"
 	"testing"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestCancelCmd_Success(t *testing.T) {
@@ -30,7 +34,7 @@ func TestCancelCmd_Success(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		g := gke.NewGKEOrchestrator()
 		g.SetExecutor(&mockCancelExecutor{})
 		g.SetKubeClient(&mockKubeClient{namespace: "default"})
@@ -62,21 +66,33 @@ func (m *mockCancelExecutor) ExecuteCommandStream(name string, args ...string) e
 type mockKubeClient struct {
 	namespace string
 	err       error
+	// logs are the logs of every container; logOptions records the
+	// options of the PodLogs calls.
+	logs       string
+	logOptions []corev1.PodLogOptions
 }
 
-func (m *mockKubeClient) GetJobNamespace(workloadName string) (string, error) {
+func (m *mockKubeClient) GetJobNamespace(_ context.Context, workloadName string) (string, error) {
 	return m.namespace, m.err
 }
 
-func (m *mockKubeClient) ListWorkloads(namespace string, workloadName string) ([]string, error) {
+func (m *mockKubeClient) ListWorkloads(_ context.Context, namespace string, workloadName string) ([]string, error) {
 	return nil, nil
 }
 
-func (m *mockKubeClient) DeleteJobSet(namespace string, name string) error {
+func (m *mockKubeClient) DeleteJobSet(_ context.Context, namespace string, name string) error {
+	return m.err
+}
+
+func (m *mockKubeClient) DeletePods(_ context.Context, namespace string, labelSelector string, gracePeriodSeconds int64) error {
+	return m.err
+}
+
+func (m *mockKubeClient) DeleteServices(_ context.Context, namespace string, labelSelector string) error {
 	return m.err
 }
 
-func (m *mockKubeClient) ListJobSets(labelSelector string) ([]orchestrator.JobStatus, error) {
+func (m *mockKubeClient) ListJobSets(_ context.Context, labelSelector string) ([]orchestrator.JobStatus, error) {
 	return []orchestrator.JobStatus{}, m.err
 }
 
@@ -87,6 +103,31 @@ func (m *mockKubeClient) GetCurrentNamespace() (string, error) {
 	return "default", m.err
 }
 
+func (m *mockKubeClient) ApplyManifests(_ context.Context, manifests []byte) error {
+	return m.err
+}
+
+func (m *mockKubeClient) CRDInstalled(_ context.Context, name string) (bool, error) {
+	return true, m.err
+}
+
+func (m *mockKubeClient) PodLogs(_ context.Context, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	m.logOptions = append(m.logOptions, *opts)
+	return io.NopCloser(strings.NewReader(m.logs)), m.err
+}
+
+func (m *mockKubeClient) PortForward(_ context.Context, namespace, pod string, ports []string) error {
+	return m.err
+}
+
+func (m *mockKubeClient) RawGet(_ context.Context, path string) ([]byte, error) {
+	return nil, m.err
+}
+
+func (m *mockKubeClient) CanI(_ context.Context, verb, resource string) (bool, error) {
+	return true, m.err
+}
+
 func TestCancelCmd_MissingArgs(t *testing.T) {
 	resetSubmitCmdFlags()
 
@@ -106,7 +147,7 @@ func TestCancelCmd_JobNotFound(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		g := gke.NewGKEOrchestrator()
 		g.SetExecutor(&mockCancelExecutor{})
 		g.SetKubeClient(&mockKubeClient{err: fmt.Errorf("job not found in any namespace")})
@@ -125,3 +166,13 @@ func TestCancelCmd_JobNotFound(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestCancelCmd_ForceWithGracePeriod_Fails(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Cleanup(func() { cancelGracePeriod, cancelForce = "", false })
+
+	_, err := executeCommand(JobCmd, "cancel", "test-job", "--force", "--grace-period", "30s", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined with a positive --grace-period") {
+		t.Fatalf("expected --force/--grace-period conflict error, got %v", err)
+	}
+}
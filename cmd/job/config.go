@@ -17,7 +17,9 @@ package job
 import (
 	"fmt"
 	"hpc-toolkit/pkg/logging"
+	"slices"
 	"strings"
+	"text/template"
 
 	"github.com/spf13/cobra"
 )
@@ -38,14 +40,25 @@ var configSetCmd = &cobra.Command{
 	Short: "Set a configuration property.",
 	Long: `Set a persistent configuration property.
 Supported keys:
-  project   - Google Cloud Project ID
-  cluster   - GKE Cluster Name
-  location  - GKE Cluster Location (region or zone)`,
+  project     - Google Cloud Project ID
+  cluster     - GKE Cluster Name
+  location    - GKE Cluster Location (region or zone)
+  team        - Default 'team' cost allocation label for submitted workloads
+  experiment  - Default 'experiment' cost allocation label for submitted workloads
+  user        - 'user' cost allocation label (defaults to the local user name)
+  name-template - Template of the names of workloads submitted without --name, e.g. '{{.User}}-{{.Experiment}}-r{{.Seq}}'
+  format      - Output format of 'submit', 'list' and plugins when not given: text, json or yaml`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key := strings.ToLower(args[0])
 		value := args[1]
 
+		if slices.Contains(costLabelKeys, key) {
+			if err := validateCostLabel(key, value); err != nil {
+				return err
+			}
+		}
+
 		ctx := loadContext()
 
 		switch key {
@@ -55,8 +68,24 @@ Supported keys:
 			ctx.ClusterName = value
 		case "location":
 			ctx.Location = value
+		case "team":
+			ctx.Team = value
+		case "experiment":
+			ctx.Experiment = value
+		case "user":
+			ctx.User = value
+		case "name-template":
+			if _, err := template.New("name").Parse(value); err != nil {
+				return fmt.Errorf("invalid name template %q: %w", value, err)
+			}
+			ctx.NameTemplate = value
+		case "format":
+			if !slices.Contains(outputFormats, value) {
+				return fmt.Errorf("invalid format %q. Allowed values are: %s", value, strings.Join(outputFormats, ", "))
+			}
+			ctx.Format = value
 		default:
-			return fmt.Errorf("invalid configuration key: %s. Supported keys: project, cluster, location", key)
+			return fmt.Errorf("invalid configuration key: %s. Supported keys: project, cluster, location, team, experiment, user, name-template, format", key)
 		}
 
 		if err := saveContext(ctx); err != nil {
@@ -75,9 +104,14 @@ var configListCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := loadContext()
 		fmt.Fprintln(cmd.OutOrStdout(), "Current Configuration:")
-		fmt.Fprintf(cmd.OutOrStdout(), "  project:  %s\n", ctx.ProjectID)
-		fmt.Fprintf(cmd.OutOrStdout(), "  cluster:  %s\n", ctx.ClusterName)
-		fmt.Fprintf(cmd.OutOrStdout(), "  location: %s\n", ctx.Location)
+		fmt.Fprintf(cmd.OutOrStdout(), "  project:    %s\n", ctx.ProjectID)
+		fmt.Fprintf(cmd.OutOrStdout(), "  cluster:    %s\n", ctx.ClusterName)
+		fmt.Fprintf(cmd.OutOrStdout(), "  location:   %s\n", ctx.Location)
+		fmt.Fprintf(cmd.OutOrStdout(), "  team:       %s\n", ctx.Team)
+		fmt.Fprintf(cmd.OutOrStdout(), "  experiment: %s\n", ctx.Experiment)
+		fmt.Fprintf(cmd.OutOrStdout(), "  user:       %s\n", ctx.User)
+		fmt.Fprintf(cmd.OutOrStdout(), "  name-template: %s\n", ctx.NameTemplate)
+		fmt.Fprintf(cmd.OutOrStdout(), "  format:     %s\n", ctx.Format)
 		return nil
 	},
 }
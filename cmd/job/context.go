@@ -15,62 +15,41 @@
 package job
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"hpc-toolkit/pkg/localstate"
 	"hpc-toolkit/pkg/logging"
 	"os"
-	"path/filepath"
 )
 
+// contextFile is the versioned on-disk location of the CLI context.
+var contextFile = localstate.File{Name: contextFileName, Version: 1}
+
 func contextFilePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("could not get user home directory: %w", err)
-	}
-	stateDir := filepath.Join(homeDir, stateDirName)
-	if err := os.MkdirAll(stateDir, 0755); err != nil {
-		return "", fmt.Errorf("could not create state directory %s: %w", stateDir, err)
-	}
-	return filepath.Join(stateDir, contextFileName), nil
+	return contextFile.Path()
 }
 
 func loadContext() Context {
-	filePath, err := contextFilePath()
-	if err != nil {
-		logging.Error("Failed to get context file path: %v", err)
-		return Context{}
-	}
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			logging.Error("Failed to read context from %s: %v", filePath, err)
-		}
-		return Context{}
-	}
-
 	var ctx Context
-	if err := json.Unmarshal(data, &ctx); err != nil {
-		logging.Error("Failed to unmarshal context from %s: %v", filePath, err)
+	if err := contextFile.Load(&ctx); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logging.Error("Failed to load context: %v", err)
+		}
 		return Context{}
 	}
 	return ctx
 }
 
 func saveContext(ctx Context) error {
-	filePath, err := contextFilePath()
-	if err != nil {
-		return fmt.Errorf("failed to get context file path for saving: %w", err)
-	}
-
-	data, err := json.MarshalIndent(ctx, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal context: %w", err)
-	}
-
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write context to %s: %w", filePath, err)
+	if err := contextFile.Save(ctx); err != nil {
+		return fmt.Errorf("failed to save context: %w", err)
 	}
+	filePath, _ := contextFilePath()
 	logging.Info("CLI context saved to %s", filePath)
 	return nil
 }
+
+// LoadContext returns the persisted CLI context (default project, cluster and location).
+func LoadContext() Context {
+	return loadContext()
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// costLabelKeys are the pod label keys set for GKE cost allocation. The
+// BigQuery billing export reports them as k8s-label/<key>.
+var costLabelKeys = []string{"team", "experiment", "user"}
+
+var (
+	costTeam       string
+	costExperiment string
+
+	invalidLabelValueChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+)
+
+func validateCostLabel(key, value string) error {
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("invalid %s label value %q: %s", key, value, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// costAllocationLabels returns the cost allocation pod labels for a workload.
+// Each label comes from its flag, then from 'gcluster job config', and 'user'
+// finally falls back to the local user name. Empty labels are omitted.
+func costAllocationLabels(team, experiment string) (map[string]string, error) {
+	ctx := loadContext()
+	if team == "" {
+		team = ctx.Team
+	}
+	if experiment == "" {
+		experiment = ctx.Experiment
+	}
+	user := ctx.User
+	if user == "" {
+		user = localUserLabel()
+	}
+
+	labels := map[string]string{}
+	for key, value := range map[string]string{"team": team, "experiment": experiment, "user": user} {
+		if value == "" {
+			continue
+		}
+		if err := validateCostLabel(key, value); err != nil {
+			return nil, err
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// localUserLabel converts the local user name into a valid label value.
+func localUserLabel() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME")
+	}
+	user = invalidLabelValueChars.ReplaceAllString(strings.ToLower(user), "-")
+	if len(user) > validation.LabelValueMaxLength {
+		user = user[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(user, "-_.")
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCostAllocationLabels(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USER", "Jane.Doe@corp")
+
+	got, err := costAllocationLabels("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"user": "jane.doe-corp"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("without config got %v, want %v", got, want)
+	}
+
+	if err := saveContext(Context{Team: "ml-infra", Experiment: "baseline", User: "jdoe"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = costAllocationLabels("", "sweep-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"team": "ml-infra", "experiment": "sweep-42", "user": "jdoe"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with config got %v, want %v", got, want)
+	}
+
+	if _, err := costAllocationLabels("ML Infra", ""); err == nil || !strings.Contains(err.Error(), "invalid team label value") {
+		t.Errorf("expected invalid team label error, got %v", err)
+	}
+}
+
+func TestConfigSetCmd_CostLabels(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := executeCommand(JobCmd, "config", "set", "team", "ml-infra"); err != nil {
+		t.Fatalf("config set team failed: %v", err)
+	}
+	if ctx := loadContext(); ctx.Team != "ml-infra" {
+		t.Errorf("expected team 'ml-infra', got %q", ctx.Team)
+	}
+	if _, err := executeCommand(JobCmd, "config", "set", "experiment", "not a label!"); err == nil {
+		t.Error("expected invalid experiment label to be rejected")
+	}
+}
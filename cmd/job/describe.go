@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var DescribeCmd = &cobra.Command{
+	Use:   "describe [job-name]",
+	Short: "Explain why a job is pending or failing.",
+	Long: `The 'describe' command shows the state of a job together with the conditions
+of its JobSet and Kueue Workload and the events of its pods, with repeated
+events merged. It ends with a diagnosis of why the job is pending or failing,
+such as missing Kueue quota, pods that cannot be scheduled, image pull errors,
+OOMKilled or crash looping containers, GPU XID errors on the nodes of its pods,
+and evictions.`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runDescribeCmd,
+	SilenceUsage: true,
+}
+
+func runDescribeCmd(cmd *cobra.Command, args []string) error {
+	opts := orchestrator.DescribeOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+	}
+
+	desc, err := orc.DescribeJob(cmd.Context(), args[0], opts)
+	if err != nil {
+		return err
+	}
+	return printWorkloadDescription(cmd.OutOrStdout(), desc)
+}
+
+func printWorkloadDescription(out io.Writer, d orchestrator.WorkloadDescription) error {
+	if err := printJobStatus(out, d.Status); err != nil {
+		return err
+	}
+	if err := printConditions(out, "JobSet Conditions", d.Conditions); err != nil {
+		return err
+	}
+	if err := printConditions(out, "Kueue Conditions", d.KueueConditions); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nEvents:")
+	if len(d.Events) == 0 {
+		fmt.Fprintln(out, "  No events found.")
+	} else {
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "TYPE\tREASON\tCOUNT\tLAST_SEEN\tOBJECT\tMESSAGE")
+		for _, e := range d.Events {
+			object := e.Objects[0]
+			if len(e.Objects) > 1 {
+				object = fmt.Sprintf("%s (+%d more)", object, len(e.Objects)-1)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", e.Type, e.Reason, e.Count, e.LastSeen, object, e.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out, "\nDiagnosis:")
+	if len(d.Diagnosis) == 0 {
+		fmt.Fprintln(out, "  No problems found.")
+	}
+	for _, line := range d.Diagnosis {
+		fmt.Fprintf(out, "  - %s\n", line)
+	}
+	return nil
+}
+
+func printConditions(out io.Writer, title string, conditions []orchestrator.Condition) error {
+	fmt.Fprintf(out, "\n%s:\n", title)
+	if len(conditions) == 0 {
+		fmt.Fprintln(out, "  None.")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tSTATUS\tREASON\tLAST_TRANSITION\tMESSAGE")
+	for _, c := range conditions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, c.LastTransitionTime, c.Message)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+)
+
+func TestDescribeCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+	mock := &mockJobOrchestrator{description: orchestrator.WorkloadDescription{
+		Status: orchestrator.JobStatusDetail{Name: "train", Namespace: "default", Status: "Suspended", KueueQueue: "lq"},
+		KueueConditions: []orchestrator.Condition{
+			{Type: "QuotaReserved", Status: "False", Reason: "Pending", Message: "insufficient unused quota for nvidia.com/gpu", LastTransitionTime: "2026-01-01T00:00:00Z"},
+		},
+		Events: []orchestrator.Event{
+			{Type: "Warning", Reason: "FailedScheduling", Message: "0/2 nodes are available", Objects: []string{"Pod/train-a", "Pod/train-b"}, Count: 7, LastSeen: "2026-01-01T00:05:00Z"},
+		},
+		Diagnosis: []string{"Kueue has not admitted the workload: insufficient unused quota for nvidia.com/gpu."},
+	}}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+
+	output, err := executeCommand(JobCmd, "describe", "train", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err != nil {
+		t.Fatalf("describe command failed: %v", err)
+	}
+	if mock.describeName != "train" {
+		t.Errorf("expected train to be described, got %q", mock.describeName)
+	}
+	for _, want := range []string{
+		"Status:     Suspended",
+		"JobSet Conditions:\n  None.",
+		"QuotaReserved   False    Pending",
+		"Pod/train-a (+1 more)",
+		"FailedScheduling   7",
+		"Diagnosis:\n  - Kueue has not admitted the workload",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestPrintWorkloadDescription_Healthy(t *testing.T) {
+	var out strings.Builder
+	if err := printWorkloadDescription(&out, orchestrator.WorkloadDescription{
+		Status: orchestrator.JobStatusDetail{Name: "train", Namespace: "default", Status: "Running"},
+	}); err != nil {
+		t.Fatalf("printWorkloadDescription() error = %v", err)
+	}
+	for _, want := range []string{"Events:\n  No events found.", "Diagnosis:\n  No problems found."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
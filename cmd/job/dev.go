@@ -0,0 +1,180 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	devMode          string
+	devIdleTimeout   time.Duration
+	devLocalPort     int
+	devSSHPublicKey  string
+	invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+)
+
+var DevCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Starts an interactive Jupyter or SSH development session on the cluster.",
+	Long: `The 'dev' command deploys a long-lived pod with the given image on the requested
+compute type, starts a Jupyter server (--mode jupyter) or an SSH daemon (--mode ssh)
+in it, and forwards its port to localhost. Use the SSH mode with VS Code Remote-SSH.
+
+The session shuts itself down after --idle-timeout without activity, so an
+abandoned session does not keep accelerators allocated.`,
+	RunE: runDevCmd,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if devMode != orchestrator.DevModeJupyter && devMode != orchestrator.DevModeSSH {
+			return fmt.Errorf("invalid value %q for --mode. Allowed values: %s, %s", devMode, orchestrator.DevModeJupyter, orchestrator.DevModeSSH)
+		}
+		if devIdleTimeout < time.Minute {
+			return fmt.Errorf("--idle-timeout must be at least 1m, got %s", devIdleTimeout)
+		}
+		if workloadName == "" {
+			workloadName = defaultDevName()
+		}
+		if len(workloadName) > 28 {
+			return fmt.Errorf("workload name cannot exceed 28 characters due to Kubernetes/GCE resource name limits. The provided name %q has %d characters", workloadName, len(workloadName))
+		}
+		if err := validateImageFlags(); err != nil {
+			return err
+		}
+		if err := validateEnvFlags(envVars); err != nil {
+			return err
+		}
+		return ensurePrerequisites(cmd, &projectID, "", location)
+	},
+	SilenceUsage: true,
+}
+
+func init() {
+	DevCmd.Flags().StringVarP(&imageName, "image", "i", "", "Name of the pre-built container image to run.")
+	DevCmd.Flags().StringVarP(&baseImage, "base-image", "B", "", "Name of the base image for Crane to build upon. Requires --build-context.")
+	DevCmd.Flags().StringVar(&imageRepo, "image-repo", "", "Artifact Registry repository to push the built image to. Defaults to GCLUSTER_IMAGE_REPO in the cluster's project and region.")
+	DevCmd.Flags().StringVarP(&buildContext, "build-context", "b", "", "Path to the build context directory for Crane. Required with --base-image.")
+	DevCmd.Flags().StringVar(&requirements, "requirements", "", "Path to a pip requirements.txt or conda environment.yml to bake into a cached dependency layer. Requires --base-image.")
+	DevCmd.Flags().StringVarP(&platform, "platform", "f", "linux/amd64", "Target platform for the image build. Used with --base-image.")
+	DevCmd.Flags().StringVar(&computeType, "compute-type", "", "Type of compute to request (e.g., 'nvidia-l4', 'n2-standard-32'). Alias: --accelerator.")
+	DevCmd.Flags().StringVarP(&workloadName, "name", "n", "", "Name of the development session. Defaults to '<user>-dev'.")
+	DevCmd.Flags().StringVarP(&kueueQueueName, "queue", "q", "", "Name of the Kueue LocalQueue to submit the session to. If empty, it will be auto-discovered.")
+	DevCmd.Flags().StringSliceVar(&volumeStr, "mount", nil, "Volumes to mount (format: <src>:<dest>[:<mode>]).")
+	DevCmd.Flags().StringArrayVar(&envVars, "env", []string{}, "Custom environment variables in KEY=VALUE format. Can be specified multiple times.")
+	DevCmd.Flags().StringVar(&costTeam, "team", "", "Team label for GKE cost allocation. Defaults to 'gcluster job config set team'.")
+	DevCmd.Flags().StringVar(&costExperiment, "experiment", "", "Experiment label for GKE cost allocation. Defaults to 'gcluster job config set experiment'.")
+	DevCmd.Flags().StringVar(&devMode, "mode", orchestrator.DevModeJupyter, "Development server to run: 'jupyter' or 'ssh'.")
+	DevCmd.Flags().DurationVar(&devIdleTimeout, "idle-timeout", time.Hour, "Shut the session down after this long without activity (e.g. 30m, 2h).")
+	DevCmd.Flags().IntVar(&devLocalPort, "local-port", 0, "Local port to forward to the session. Defaults to 8888 for jupyter and 2222 for ssh.")
+	DevCmd.Flags().StringVar(&devSSHPublicKey, "ssh-public-key", "", "Public key authorized for --mode ssh. Defaults to ~/.ssh/id_ed25519.pub or ~/.ssh/id_rsa.pub.")
+	DevCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "accelerator" {
+			name = "compute-type"
+		}
+		return pflag.NormalizedName(name)
+	})
+
+	_ = DevCmd.MarkFlagRequired("compute-type")
+}
+
+func runDevCmd(cmd *cobra.Command, args []string) error {
+	var sshKey string
+	if devMode == orchestrator.DevModeSSH {
+		var err error
+		if sshKey, err = readSSHPublicKey(devSSHPublicKey); err != nil {
+			return err
+		}
+	}
+
+	costLabels, err := costAllocationLabels(costTeam, costExperiment)
+	if err != nil {
+		return err
+	}
+
+	def := orchestrator.DevSessionDefinition{
+		Job: orchestrator.JobDefinition{
+			ImageName:                     imageName,
+			BaseImage:                     baseImage,
+			ImageRepo:                     imageRepo,
+			BuildContext:                  buildContext,
+			Requirements:                  requirements,
+			Platform:                      platform,
+			ComputeType:                   computeType,
+			ClusterProjectID:              projectID,
+			ClusterName:                   clusterName,
+			ClusterLocation:               location,
+			WorkloadName:                  workloadName,
+			KueueQueueName:                kueueQueueName,
+			NumSlices:                     1,
+			NodesPerSlice:                 1,
+			TtlSecondsAfterFinished:       3600,
+			TerminationGracePeriodSeconds: 30,
+			UseParallelContainers:         true,
+			RawMounts:                     volumeStr,
+			Env:                           parseEnvFlags(envVars),
+			CostLabels:                    costLabels,
+		},
+		Mode:               devMode,
+		IdleTimeoutSeconds: int(devIdleTimeout.Seconds()),
+		LocalPort:          devLocalPort,
+		SSHPublicKey:       sshKey,
+	}
+
+	return orc.StartDevSession(cmd.Context(), def)
+}
+
+// defaultDevName derives a valid workload name from the local user name.
+func defaultDevName() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME")
+	}
+	user = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(user), "-"), "-")
+	if len(user) > 24 {
+		user = strings.TrimRight(user[:24], "-")
+	}
+	if user == "" {
+		return "gcluster-dev"
+	}
+	return user + "-dev"
+}
+
+// readSSHPublicKey returns the contents of path, or of the first default key
+// found in ~/.ssh when path is empty.
+func readSSHPublicKey(path string) (string, error) {
+	candidates := []string{path}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %w", err)
+		}
+		candidates = []string{filepath.Join(home, ".ssh", "id_ed25519.pub"), filepath.Join(home, ".ssh", "id_rsa.pub")}
+	}
+	for _, c := range candidates {
+		data, err := os.ReadFile(c)
+		if err == nil && strings.TrimSpace(string(data)) != "" {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	return "", fmt.Errorf("no SSH public key found (tried %s); pass one with --ssh-public-key", strings.Join(candidates, ", "))
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockDevOrchestrator struct {
+	orchestrator.JobOrchestrator
+	def *orchestrator.DevSessionDefinition
+}
+
+func (m *mockDevOrchestrator) StartDevSession(_ context.Context, def orchestrator.DevSessionDefinition) error {
+	m.def = &def
+	return nil
+}
+
+func resetDevCmdFlags() {
+	resetSubmitCmdFlags()
+	devMode = orchestrator.DevModeJupyter
+	devIdleTimeout = time.Hour
+	devLocalPort = 0
+	devSSHPublicKey = ""
+}
+
+func setupDevTest(t *testing.T) *mockDevOrchestrator {
+	resetDevCmdFlags()
+	t.Cleanup(resetDevCmdFlags)
+
+	oldStore := store
+	t.Cleanup(func() { store = oldStore })
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	mock := &mockDevOrchestrator{}
+	oldFactory := gkeOrchestratorFactory
+	t.Cleanup(func() { gkeOrchestratorFactory = oldFactory })
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+	return mock
+}
+
+func TestDevCmd_SSH(t *testing.T) {
+	mock := setupDevTest(t)
+	t.Setenv("USER", "Jane.Doe")
+	keyFile := filepath.Join(t.TempDir(), "key.pub")
+	if err := os.WriteFile(keyFile, []byte("ssh-ed25519 AAAA test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := executeCommand(JobCmd, "dev",
+		"--image", "us-docker.pkg.dev/p/r/img:latest",
+		"--accelerator", "nvidia-l4",
+		"--mode", "ssh",
+		"--ssh-public-key", keyFile,
+		"--idle-timeout", "30m",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+	)
+	if err != nil {
+		t.Fatalf("dev command failed: %v", err)
+	}
+	if mock.def == nil {
+		t.Fatal("StartDevSession was not called")
+	}
+	if mock.def.Job.WorkloadName != "jane-doe-dev" {
+		t.Errorf("WorkloadName = %q, want jane-doe-dev", mock.def.Job.WorkloadName)
+	}
+	if mock.def.Job.ComputeType != "nvidia-l4" {
+		t.Errorf("ComputeType = %q, want nvidia-l4", mock.def.Job.ComputeType)
+	}
+	if mock.def.IdleTimeoutSeconds != 1800 || mock.def.Mode != orchestrator.DevModeSSH {
+		t.Errorf("unexpected session definition: %+v", mock.def)
+	}
+	if mock.def.SSHPublicKey != "ssh-ed25519 AAAA test" {
+		t.Errorf("SSHPublicKey = %q", mock.def.SSHPublicKey)
+	}
+}
+
+func TestDevCmd_InvalidMode(t *testing.T) {
+	setupDevTest(t)
+
+	_, err := executeCommand(JobCmd, "dev",
+		"--image", "busybox",
+		"--compute-type", "n2-standard-4",
+		"--mode", "rdp",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+	)
+	if err == nil || !strings.Contains(err.Error(), "invalid value \"rdp\" for --mode") {
+		t.Fatalf("expected invalid mode error, got %v", err)
+	}
+}
+
+func TestReadSSHPublicKey_Defaults(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if _, err := readSSHPublicKey(""); err == nil {
+		t.Fatal("expected error when no default key exists")
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "id_rsa.pub"), []byte("ssh-rsa BBBB\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	key, err := readSSHPublicKey("")
+	if err != nil || key != "ssh-rsa BBBB" {
+		t.Fatalf("readSSHPublicKey() = %q, %v", key, err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// envFlags binds flags to the environment variables that set them when they
+// are not given on the command line or in a job spec, so that CI systems and
+// containers can configure gcluster without long command lines. The values
+// saved with 'gcluster job config set' only apply when neither is set.
+var envFlags = []struct {
+	flag  string
+	env   string
+	value *string
+}{
+	{"project", "GCLUSTER_PROJECT", &projectID},
+	{"cluster", "GCLUSTER_CLUSTER_NAME", &clusterName},
+	{"location", "GCLUSTER_CLUSTER_LOCATION", &location},
+	{"platform", "GCLUSTER_PLATFORM", &platform},
+	{"registry-auth", "GCLUSTER_REGISTRY_AUTH", &registryAuth},
+	{"registry-credentials", "GCLUSTER_REGISTRY_CREDENTIALS", &registryCreds},
+}
+
+// applyEnvFlags sets the flags of cmd that were not given from their
+// environment variables. A flag counts as given when it holds a value other
+// than its default, or, for flags with a default such as --platform, when it
+// was set explicitly.
+func applyEnvFlags(cmd *cobra.Command) {
+	for _, b := range envFlags {
+		f := cmd.Flags().Lookup(b.flag)
+		if f == nil || *b.value != f.DefValue || (f.DefValue != "" && f.Changed) {
+			continue
+		}
+		if v := os.Getenv(b.env); v != "" {
+			*b.value = v
+		}
+	}
+}
+
+// outputFormats are the values of the format setting.
+var outputFormats = []string{"text", "json", "yaml"}
+
+// EffectiveContext returns the saved CLI context with the project, cluster,
+// location and format of the environment variables in place of the saved
+// ones.
+func EffectiveContext() Context {
+	ctx := loadContext()
+	for _, v := range []struct {
+		env   string
+		value *string
+	}{
+		{"GCLUSTER_PROJECT", &ctx.ProjectID},
+		{"GCLUSTER_CLUSTER_NAME", &ctx.ClusterName},
+		{"GCLUSTER_CLUSTER_LOCATION", &ctx.Location},
+		{"GCLUSTER_FORMAT", &ctx.Format},
+	} {
+		if env := os.Getenv(v.env); env != "" {
+			*v.value = env
+		}
+	}
+	return ctx
+}
+
+// applyFormat sets the output format of submit and list, when it was not
+// given, to the format of EffectiveContext. The table of list is its text
+// format.
+func applyFormat(cmd *cobra.Command) {
+	format := EffectiveContext().Format
+	if !slices.Contains(outputFormats, format) {
+		return
+	}
+	switch cmd {
+	case SubmitCmd:
+		if !cmd.Flags().Changed("output-format") && !submitJSON {
+			submitOutput = format
+		}
+	case ListWorkloadsCmd:
+		if !cmd.Flags().Changed("output") {
+			listOutput = format
+			if format == "text" {
+				listOutput = "table"
+			}
+		}
+	}
+}
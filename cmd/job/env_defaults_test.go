@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestEnvFlags_Precedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	stateDir := filepath.Join(home, stateDirName)
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(Context{ProjectID: "profile-project", ClusterName: "profile-cluster", Location: "profile-location"})
+	if err := os.WriteFile(filepath.Join(stateDir, contextFileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GCLUSTER_PROJECT", "env-project")
+	t.Setenv("GCLUSTER_CLUSTER_NAME", "env-cluster")
+	t.Setenv("GCLUSTER_CLUSTER_LOCATION", "")
+
+	mock := &mockStatusOrchestrator{}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	var gotCfg orchestrator.Config
+	gkeOrchestratorFactory = func(cfg orchestrator.Config) orchestrator.JobOrchestrator {
+		gotCfg = cfg
+		return mock
+	}
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, "status", "train", "--cluster", "flag-cluster"); err != nil {
+		t.Fatalf("status command failed: %v", err)
+	}
+	want := orchestrator.StatusOptions{ProjectID: "env-project", ClusterName: "flag-cluster", ClusterLocation: "profile-location"}
+	if mock.gotOpts != want {
+		t.Errorf("status options = %+v, want %+v (flag > env > profile)", mock.gotOpts, want)
+	}
+	wantCfg := orchestrator.Config{ProjectID: "env-project", ClusterName: "flag-cluster", ClusterLocation: "profile-location"}
+	if gotCfg != wantCfg {
+		t.Errorf("factory config = %+v, want %+v", gotCfg, wantCfg)
+	}
+}
+
+func TestEnvFlags_Platform(t *testing.T) {
+	f := SubmitCmd.Flags().Lookup("platform")
+	defer func() { f.Changed = false }()
+	t.Setenv("GCLUSTER_PLATFORM", "linux/arm64")
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+	f.Changed = false
+	applyEnvFlags(SubmitCmd)
+	if platform != "linux/arm64" {
+		t.Errorf("platform = %q, want the GCLUSTER_PLATFORM value", platform)
+	}
+
+	platform, f.Changed = "linux/amd64", true
+	applyEnvFlags(SubmitCmd)
+	if platform != "linux/amd64" {
+		t.Errorf("platform = %q, want the explicit --platform to win over GCLUSTER_PLATFORM", platform)
+	}
+}
+
+func TestEffectiveContext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GCLUSTER_PROJECT", "")
+	t.Setenv("GCLUSTER_CLUSTER_NAME", "env-cluster")
+	t.Setenv("GCLUSTER_CLUSTER_LOCATION", "us-east5")
+	want := Context{ClusterName: "env-cluster", Location: "us-east5"}
+	if got := EffectiveContext(); got != want {
+		t.Errorf("EffectiveContext() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyFormat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	out := ListWorkloadsCmd.Flags().Lookup("output")
+	outFormat := SubmitCmd.Flags().Lookup("output-format")
+	defer func() { out.Changed, outFormat.Changed = false, false }()
+	out.Changed, outFormat.Changed = false, false
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+	t.Setenv("GCLUSTER_FORMAT", "text")
+	applyFormat(ListWorkloadsCmd)
+	if listOutput != "table" {
+		t.Errorf("listOutput = %q, want the table for the text format", listOutput)
+	}
+
+	t.Setenv("GCLUSTER_FORMAT", "json")
+	applyFormat(ListWorkloadsCmd)
+	applyFormat(SubmitCmd)
+	if listOutput != "json" || submitOutput != "json" {
+		t.Errorf("listOutput, submitOutput = %q, %q, want the GCLUSTER_FORMAT json", listOutput, submitOutput)
+	}
+
+	submitOutput, outFormat.Changed = "yaml", true
+	applyFormat(SubmitCmd)
+	if submitOutput != "yaml" {
+		t.Errorf("submitOutput = %q, want the explicit --output-format to win over GCLUSTER_FORMAT", submitOutput)
+	}
+}
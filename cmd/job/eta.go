@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var EtaCmd = &cobra.Command{
+	Use:   "eta [job-name]",
+	Short: "Estimate when Kueue will admit a job.",
+	Long: `The 'eta' command gives a rough estimate of when a job will be admitted by
+Kueue. It compares the job's gang request with the quota and usage of its
+ClusterQueue, the pending workloads queued ahead of it and the autoscaling
+limits of the cluster's node pools. The wait is based on the median runtime of
+workloads that recently finished in the same ClusterQueue.
+
+Pass a submitted job's name, or --spec with a manifest written by
+'gcluster job submit --dry-run-out' to decide between waiting and resizing the
+request before submitting it.`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runEtaCmd,
+	SilenceUsage: true,
+}
+
+var etaSpecPath string
+
+func init() {
+	EtaCmd.Flags().StringVar(&etaSpecPath, "spec", "", "Path of a JobSet or Job manifest to estimate instead of a submitted job.")
+}
+
+func runEtaCmd(cmd *cobra.Command, args []string) error {
+	var name string
+	if len(args) == 1 {
+		name = args[0]
+	}
+	if (name == "") == (etaSpecPath == "") {
+		return fmt.Errorf("specify either a job name or --spec")
+	}
+
+	opts := orchestrator.EtaOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+		SpecPath:        etaSpecPath,
+	}
+
+	est, err := orc.EstimateAdmission(cmd.Context(), name, opts)
+	if err != nil {
+		return err
+	}
+	return printAdmissionEstimate(cmd.OutOrStdout(), est)
+}
+
+func printAdmissionEstimate(out io.Writer, est orchestrator.AdmissionEstimate) error {
+	fmt.Fprintf(out, "Workload:       %s\n", est.Workload)
+	fmt.Fprintf(out, "State:          %s\n", est.State)
+	fmt.Fprintf(out, "Queue:          %s (ClusterQueue: %s)\n", est.LocalQueue, est.ClusterQueue)
+	fmt.Fprintf(out, "Queued ahead:   %d\n", est.WorkloadsAhead)
+
+	if len(est.Resources) > 0 {
+		fmt.Fprintln(out)
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "RESOURCE\tREQUESTED\tQUOTA\tIN USE\tAHEAD\tNODE POOL MAX")
+		for _, r := range est.Resources {
+			limit := r.NodeLimit
+			if limit == "" {
+				limit = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, r.Requested, r.Quota, r.InUse, r.Ahead, limit)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\nEstimate: %s\n", est.Summary)
+	for _, note := range est.Notes {
+		fmt.Fprintf(out, "  - %s\n", note)
+	}
+	fmt.Fprintln(out, "\nEstimates assume admitted workloads run for the queue's median runtime and ignore preemption.")
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEtaCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+	mock := &mockJobOrchestrator{etaEstimate: orchestrator.AdmissionEstimate{
+		Workload:       "train",
+		State:          "Pending",
+		LocalQueue:     "lq",
+		ClusterQueue:   "cq",
+		WorkloadsAhead: 2,
+		Resources:      []orchestrator.ResourceHeadroom{{Name: "google.com/tpu", Requested: "32", Quota: "64", InUse: "48", Ahead: "16"}},
+		Wait:           90 * time.Minute,
+		WaitKnown:      true,
+		Summary:        "Estimated admission in ~1h30m.",
+		Notes:          []string{"Borrowing may help."},
+	}}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+	t.Cleanup(func() { etaSpecPath = "" })
+
+	output, err := executeCommand(JobCmd, "eta", "train", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err != nil {
+		t.Fatalf("eta command failed: %v", err)
+	}
+	if mock.etaName != "train" || mock.etaOpts.ClusterName != "test-cluster" || mock.etaOpts.SpecPath != "" {
+		t.Errorf("unexpected call: %q %+v", mock.etaName, mock.etaOpts)
+	}
+	for _, want := range []string{"Queued ahead:   2", "RESOURCE", "google.com/tpu", "Estimated admission in ~1h30m.", "  - Borrowing may help."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestEtaCmd_RequiresNameOrSpec(t *testing.T) {
+	resetSubmitCmdFlags()
+	mock := &mockJobOrchestrator{}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+	t.Cleanup(func() { etaSpecPath = "" })
+
+	for _, args := range [][]string{
+		{"eta"},
+		{"eta", "train", "--spec", "train.yaml"},
+	} {
+		args = append(args, "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+		if _, err := executeCommand(JobCmd, args...); err == nil || !strings.Contains(err.Error(), "either a job name or --spec") {
+			t.Errorf("%v: expected a name-or-spec error, got %v", args, err)
+		}
+		etaSpecPath = ""
+	}
+
+	if _, err := executeCommand(JobCmd, "eta", "--spec", "train.yaml", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project"); err != nil {
+		t.Fatalf("eta --spec failed: %v", err)
+	}
+	if mock.etaName != "" || mock.etaOpts.SpecPath != "train.yaml" {
+		t.Errorf("unexpected call: %q %+v", mock.etaName, mock.etaOpts)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+// parseInputs parses --input values of the form
+// "NAME=gs://<bucket>/<object>[,generation=<n>][,md5=<hash>]". A URI ending
+// in '/' is a prefix, which cannot be pinned.
+func parseInputs(values []string) ([]orchestrator.DataInput, error) {
+	var res []orchestrator.DataInput
+	seen := map[string]bool{}
+	for _, value := range values {
+		name, rest, ok := strings.Cut(value, "=")
+		if !ok || !validEnvKeyRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid --input %q. Must be in NAME=gs://<bucket>/<object>[,generation=<n>][,md5=<hash>] format, where NAME is an environment variable name", value)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("--input %s is given more than once", name)
+		}
+		seen[name] = true
+
+		parts := strings.Split(rest, ",")
+		in := orchestrator.DataInput{Name: name, URI: parts[0]}
+		if bucket, _, _ := strings.Cut(strings.TrimPrefix(in.URI, "gs://"), "/"); !strings.HasPrefix(in.URI, "gs://") || bucket == "" {
+			return nil, fmt.Errorf("invalid --input %q: %q is not a gs:// URI", value, in.URI)
+		}
+		for _, opt := range parts[1:] {
+			key, val, _ := strings.Cut(opt, "=")
+			switch {
+			case key == "generation" && val != "":
+				in.Generation = val
+			case key == "md5" && val != "":
+				in.MD5 = val
+			default:
+				return nil, fmt.Errorf("invalid --input %q: unknown option %q; use generation=<n> or md5=<hash>", value, opt)
+			}
+		}
+		if strings.HasSuffix(in.URI, "/") && (in.Generation != "" || in.MD5 != "") {
+			return nil, fmt.Errorf("invalid --input %q: the prefix %s cannot be pinned to a generation or MD5 hash", value, in.URI)
+		}
+		res = append(res, in)
+	}
+	return res, nil
+}
+
+// addInputEnv passes the URI of each input to the workload in its
+// environment variable.
+func addInputEnv(job *orchestrator.JobDefinition) error {
+	if len(job.Inputs) == 0 {
+		return nil
+	}
+	if job.Env == nil {
+		job.Env = map[string]string{}
+	}
+	for _, in := range job.Inputs {
+		if v, ok := job.Env[in.Name]; ok && v != in.URI {
+			return fmt.Errorf("--input %s conflicts with --env %s=%s", in.Name, in.Name, v)
+		}
+		job.Env[in.Name] = in.URI
+	}
+	return nil
+}
+
+// verifyInputs checks that every input exists and matches its pinned
+// generation and MD5 hash, and records the ones found on the inputs. All the
+// inputs are checked, so that one submission reports every broken one.
+func verifyInputs(inputs []orchestrator.DataInput) error {
+	var failures []string
+	for i := range inputs {
+		in := &inputs[i]
+		if err := verifyInput(in); err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s): %v", in.Name, in.URI, err))
+			continue
+		}
+		logging.Info("Verified input %s: %s", in.Name, in.URI)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d input(s) failed verification:\n  %s", len(failures), strings.Join(failures, "\n  "))
+	}
+	return nil
+}
+
+func verifyInput(in *orchestrator.DataInput) error {
+	if strings.HasSuffix(in.URI, "/") {
+		res := shell.ExecuteCommand("gcloud", "storage", "ls", in.URI)
+		if res.ExitCode != 0 {
+			return fmt.Errorf("the prefix cannot be listed: %s", strings.TrimSpace(res.Stderr))
+		}
+		if strings.TrimSpace(res.Stdout) == "" {
+			return fmt.Errorf("no objects found under the prefix")
+		}
+		return nil
+	}
+
+	res := shell.ExecuteCommand("gcloud", "storage", "objects", "describe", in.URI, "--format=json")
+	if res.ExitCode != 0 {
+		return fmt.Errorf("the object does not exist or cannot be read: %s", strings.TrimSpace(res.Stderr))
+	}
+	var obj struct {
+		Generation json.RawMessage `json:"generation"` // A string or a number.
+		MD5        string          `json:"md5_hash"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &obj); err != nil {
+		return fmt.Errorf("failed to parse the object metadata: %w", err)
+	}
+	generation := strings.Trim(string(obj.Generation), `"`)
+	if in.Generation != "" && in.Generation != generation {
+		return fmt.Errorf("generation is %s, want %s; the object was overwritten", generation, in.Generation)
+	}
+	if in.MD5 != "" && in.MD5 != obj.MD5 {
+		return fmt.Errorf("MD5 hash is %s, want %s", obj.MD5, in.MD5)
+	}
+	in.Generation, in.MD5 = generation, obj.MD5
+	return nil
+}
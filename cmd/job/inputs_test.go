@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestParseInputs(t *testing.T) {
+	got, err := parseInputs([]string{
+		"TRAIN_DATA=gs://data/train.tfrecord,generation=1712345678901234,md5=abc==",
+		"EVAL_DATA=gs://data/eval/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []orchestrator.DataInput{
+		{Name: "TRAIN_DATA", URI: "gs://data/train.tfrecord", Generation: "1712345678901234", MD5: "abc=="},
+		{Name: "EVAL_DATA", URI: "gs://data/eval/"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseInputs() = %+v, want %+v", got, want)
+	}
+
+	for value, wantErr := range map[string]string{
+		"gs://data/train":                      "NAME=gs://",
+		"1DATA=gs://data/train":                "NAME=gs://",
+		"DATA=/data/train":                     "is not a gs:// URI",
+		"DATA=gs:///train":                     "is not a gs:// URI",
+		"DATA=gs://data/train,size=1":          `unknown option "size=1"`,
+		"DATA=gs://data/eval/,generation=1234": "cannot be pinned",
+	} {
+		if _, err := parseInputs([]string{value}); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseInputs(%q) error = %v, want %q", value, err, wantErr)
+		}
+	}
+	if _, err := parseInputs([]string{"DATA=gs://a/b", "DATA=gs://a/c"}); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected a duplicate input error, got %v", err)
+	}
+}
+
+func TestAddInputEnv(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		Env:    map[string]string{"LR": "0.1"},
+		Inputs: []orchestrator.DataInput{{Name: "DATA", URI: "gs://a/b"}},
+	}
+	if err := addInputEnv(&job); err != nil {
+		t.Fatal(err)
+	}
+	if job.Env["DATA"] != "gs://a/b" || job.Env["LR"] != "0.1" {
+		t.Errorf("unexpected env %v", job.Env)
+	}
+
+	job.Env["DATA"] = "gs://a/other"
+	if err := addInputEnv(&job); err == nil || !strings.Contains(err.Error(), "conflicts with --env") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestVerifyInputs(t *testing.T) {
+	origExec := shell.ExecuteCommand
+	defer func() { shell.ExecuteCommand = origExec }()
+	shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+		switch strings.Join(args, " ") {
+		case "storage objects describe gs://data/train --format=json":
+			return shell.CommandResult{Stdout: `{"generation": "1712345678901234", "md5_hash": "abc=="}`}
+		case "storage objects describe gs://data/numeric --format=json":
+			return shell.CommandResult{Stdout: `{"generation": 1712345678901234}`}
+		case "storage ls gs://data/eval/":
+			return shell.CommandResult{Stdout: "gs://data/eval/part-0\n"}
+		case "storage ls gs://data/empty/":
+			return shell.CommandResult{}
+		}
+		return shell.CommandResult{ExitCode: 1, Stderr: "ERROR: (gcloud.storage) NotFoundError"}
+	}
+
+	inputs := []orchestrator.DataInput{
+		{Name: "TRAIN", URI: "gs://data/train"},
+		{Name: "NUMERIC", URI: "gs://data/numeric", Generation: "1712345678901234"},
+		{Name: "EVAL", URI: "gs://data/eval/"},
+	}
+	if err := verifyInputs(inputs); err != nil {
+		t.Fatalf("verifyInputs() error = %v", err)
+	}
+	if inputs[0].Generation != "1712345678901234" || inputs[0].MD5 != "abc==" {
+		t.Errorf("expected the found generation and MD5 hash to be recorded, got %+v", inputs[0])
+	}
+
+	err := verifyInputs([]orchestrator.DataInput{
+		{Name: "TRAIN", URI: "gs://data/train", Generation: "1"},
+		{Name: "CHECKED", URI: "gs://data/train", MD5: "xyz=="},
+		{Name: "MISSING", URI: "gs://data/missing"},
+		{Name: "EMPTY", URI: "gs://data/empty/"},
+	})
+	if err == nil {
+		t.Fatal("expected verification to fail")
+	}
+	for _, want := range []string{
+		"4 input(s) failed verification",
+		"TRAIN (gs://data/train): generation is 1712345678901234, want 1",
+		"CHECKED (gs://data/train): MD5 hash is abc==, want xyz==",
+		"MISSING (gs://data/missing): the object does not exist or cannot be read: ERROR: (gcloud.storage) NotFoundError",
+		"EMPTY (gs://data/empty/): no objects found under the prefix",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error:\n%v", want, err)
+		}
+	}
+}
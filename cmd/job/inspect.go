@@ -51,5 +51,5 @@ func runInspectCmd(cmd *cobra.Command, args []string) error {
 		Show:            inspectShow,
 	}
 
-	return orc.InspectCluster(opts)
+	return orc.InspectCluster(cmd.Context(), opts)
 }
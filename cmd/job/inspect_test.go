@@ -15,6 +15,7 @@
 package job
 
 import (
+	"context"
 	"hpc-toolkit/pkg/orchestrator"
 	"testing"
 )
@@ -23,30 +24,83 @@ type mockJobOrchestrator struct {
 	inspectOpts   orchestrator.InspectOptions
 	inspectErr    error
 	inspectCalled bool
+	bundleName    string
+	bundleOpts    orchestrator.BundleOptions
+	etaName       string
+	etaOpts       orchestrator.EtaOptions
+	etaEstimate   orchestrator.AdmissionEstimate
+	planOpts      orchestrator.PlanOptions
+	plan          orchestrator.PlacementPlan
+	attachOpts    []orchestrator.AttachOptions
+	attachRun     func(opts orchestrator.AttachOptions) (string, error)
+	jobs          []orchestrator.JobStatus
+	listOpts      orchestrator.ListOptions
+	describeName  string
+	description   orchestrator.WorkloadDescription
 }
 
-func (m *mockJobOrchestrator) SubmitJob(job orchestrator.JobDefinition) error { return nil }
-func (m *mockJobOrchestrator) ListJobs(opts orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
-	return nil, nil
+func (m *mockJobOrchestrator) SubmitJob(_ context.Context, job orchestrator.JobDefinition) (orchestrator.SubmitResult, error) {
+	return orchestrator.SubmitResult{}, nil
 }
-func (m *mockJobOrchestrator) CancelJob(name string, opts orchestrator.CancelOptions) error {
+func (m *mockJobOrchestrator) ListJobs(_ context.Context, opts orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
+	m.listOpts = opts
+	return m.jobs, nil
+}
+func (m *mockJobOrchestrator) CancelJob(_ context.Context, name string, opts orchestrator.CancelOptions) error {
 	return nil
 }
-func (m *mockJobOrchestrator) GetJobLogs(name string, opts orchestrator.LogsOptions) (string, error) {
+func (m *mockJobOrchestrator) GetJobLogs(_ context.Context, name string, opts orchestrator.LogsOptions) (string, error) {
 	return "", nil
 }
-func (m *mockJobOrchestrator) InspectCluster(opts orchestrator.InspectOptions) error {
+func (m *mockJobOrchestrator) AttachJob(_ context.Context, name string, opts orchestrator.AttachOptions) (string, error) {
+	m.attachOpts = append(m.attachOpts, opts)
+	if m.attachRun != nil {
+		return m.attachRun(opts)
+	}
+	return "Completed", nil
+}
+func (m *mockJobOrchestrator) InspectCluster(_ context.Context, opts orchestrator.InspectOptions) error {
 	m.inspectCalled = true
 	m.inspectOpts = opts
 	return m.inspectErr
 }
+func (m *mockJobOrchestrator) GetJobStatus(_ context.Context, name string, opts orchestrator.StatusOptions) (orchestrator.JobStatusDetail, error) {
+	return orchestrator.JobStatusDetail{}, nil
+}
+func (m *mockJobOrchestrator) WaitJob(_ context.Context, name string, opts orchestrator.WaitOptions) (orchestrator.WaitResult, error) {
+	return orchestrator.WaitResult{Reached: true, Condition: opts.Until}, nil
+}
+func (m *mockJobOrchestrator) CreateBundle(_ context.Context, name string, opts orchestrator.BundleOptions) (string, error) {
+	m.bundleName, m.bundleOpts = name, opts
+	return opts.OutputPath, nil
+}
+func (m *mockJobOrchestrator) ReportJob(_ context.Context, name string, opts orchestrator.ReportOptions) (orchestrator.RunReport, error) {
+	return orchestrator.RunReport{}, nil
+}
+func (m *mockJobOrchestrator) DescribeJob(_ context.Context, name string, opts orchestrator.DescribeOptions) (orchestrator.WorkloadDescription, error) {
+	m.describeName = name
+	return m.description, nil
+}
+func (m *mockJobOrchestrator) EstimateAdmission(_ context.Context, name string, opts orchestrator.EtaOptions) (orchestrator.AdmissionEstimate, error) {
+	m.etaName, m.etaOpts = name, opts
+	return m.etaEstimate, nil
+}
+func (m *mockJobOrchestrator) PlanPlacement(_ context.Context, opts orchestrator.PlanOptions) (orchestrator.PlacementPlan, error) {
+	m.planOpts = opts
+	return m.plan, nil
+}
+func (m *mockJobOrchestrator) StartDevSession(_ context.Context, def orchestrator.DevSessionDefinition) error {
+	return nil
+}
+
+func (m *mockJobOrchestrator) Impersonate(principal string) {}
 
 func TestInspectCmd_Success(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
 	mockOrc := &mockJobOrchestrator{}
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return mockOrc
 	}
 
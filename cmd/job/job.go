@@ -15,9 +15,15 @@
 package job
 
 import (
+	"errors"
 	"fmt"
+	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/orchestrator/batch"
 	"hpc-toolkit/pkg/orchestrator/gke"
+	"hpc-toolkit/pkg/orchestrator/vertex"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -26,12 +32,31 @@ var (
 	clusterName string
 	location    string
 	projectID   string
+	impersonate string
 )
 
-var gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+// The factories of the backends, which tests replace. They receive the
+// project, cluster and location the command runs against.
+var gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 	return gke.NewGKEOrchestrator()
 }
 
+var vertexOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+	return vertex.NewVertexOrchestrator()
+}
+
+var batchOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+	return batch.NewBatchOrchestrator()
+}
+
+// Backends 'gcluster job submit --backend' runs workloads on. The vertex and
+// batch backends need no cluster; the other job commands only support GKE.
+const (
+	backendGKE    = orchestrator.BackendGKE
+	backendVertex = orchestrator.BackendVertex
+	backendBatch  = orchestrator.BackendBatch
+)
+
 var orc orchestrator.JobOrchestrator
 
 // JobCmd represents the base command for job-related operations
@@ -40,8 +65,32 @@ var JobCmd = &cobra.Command{
 	Short: "[EXPERIMENTAL/ALPHA] Manage jobs on the cluster. Alpha version and not yet supported for production use.",
 	Long:  `[EXPERIMENTAL/ALPHA] Manage jobs on the cluster. This is the alpha version of the feature and is under active development. The feature is not yet supported for production use.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		orc = gkeOrchestratorFactory()
+		backend := selectedBackend(cmd)
+		if !slices.Contains(orchestrator.Backends, backend) {
+			return fmt.Errorf("invalid value for --backend: %s. Allowed values are: %s", backend, strings.Join(orchestrator.Backends, ", "))
+		}
+		if impersonate != "" && backend != backendGKE {
+			return fmt.Errorf("--as can only be used with the %s backend", backendGKE)
+		}
+
+		if err := loadUserAccelerators(); err != nil {
+			return err
+		}
+
+		if err := applyJobSpec(cmd); err != nil {
+			return err
+		}
+
+		// submit's --cluster-project names the cluster's project like --project.
+		if f := cmd.Flags().Lookup("cluster-project"); f != nil && f.Changed {
+			if projectID != "" && projectID != f.Value.String() {
+				return fmt.Errorf("--project %s and --cluster-project %s name different projects; specify only one of them", projectID, f.Value.String())
+			}
+			projectID = f.Value.String()
+		}
 
+		applyEnvFlags(cmd)
+		applyFormat(cmd)
 		ctx := loadContext()
 		if clusterName == "" {
 			clusterName = ctx.ClusterName
@@ -53,17 +102,16 @@ var JobCmd = &cobra.Command{
 			projectID = ctx.ProjectID
 		}
 
-		if clusterName == "" {
-			return fmt.Errorf("cluster name is required; please specify it using the --cluster flag or set a default value using 'gcluster job config set cluster <value>'")
-		}
-		if location == "" {
-			return fmt.Errorf("location is required; please specify it using the --location flag or set a default value using 'gcluster job config set location <value>'")
+		var err error
+		orc, err = orchestrator.New(backend, orchestrator.Config{ProjectID: projectID, ClusterName: clusterName, ClusterLocation: location})
+		var missing *orchestrator.MissingConfigError
+		if errors.As(err, &missing) {
+			return fmt.Errorf("%s is required; please specify it using the --%s flag or set a default value using 'gcluster job config set %s <value>'", missing.Description, missing.Field, missing.Field)
 		}
-		if projectID == "" {
-			return fmt.Errorf("project ID is required; please specify it using the --project flag or set a default value using 'gcluster job config set project <value>'")
+		if err != nil {
+			return err
 		}
-
-		return nil
+		return applyImpersonation()
 	},
 }
 
@@ -71,11 +119,59 @@ func init() {
 	JobCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "", "Name of the GKE cluster.")
 	JobCmd.PersistentFlags().StringVarP(&location, "location", "l", "", "Location (region or zone) of the GKE cluster.")
 	JobCmd.PersistentFlags().StringVarP(&projectID, "project", "p", "", "Google Cloud Project ID.")
+	JobCmd.PersistentFlags().StringVar(&impersonate, "as", "", "Act as this user or service account (e.g., user@example.com) in read-only mode, to check what they are allowed to do. Changes are validated with a server-side dry run and not made.")
 
 	JobCmd.AddCommand(SubmitCmd)
 	JobCmd.AddCommand(CancelJobCmd)
 	JobCmd.AddCommand(ListWorkloadsCmd)
 	JobCmd.AddCommand(LogsCmd)
+	JobCmd.AddCommand(AttachCmd)
+	JobCmd.AddCommand(WaitCmd)
 	JobCmd.AddCommand(ConfigCmd)
 	JobCmd.AddCommand(InspectCmd)
+	JobCmd.AddCommand(DevCmd)
+	JobCmd.AddCommand(StatusCmd)
+	JobCmd.AddCommand(DescribeCmd)
+	JobCmd.AddCommand(BundleCmd)
+	JobCmd.AddCommand(EtaCmd)
+	JobCmd.AddCommand(PlanCmd)
+	JobCmd.AddCommand(VerifyManifestCmd)
+
+	registerBackend(backendGKE, func(cfg orchestrator.Config) orchestrator.JobOrchestrator { return gkeOrchestratorFactory(cfg) })
+	registerBackend(backendVertex, func(cfg orchestrator.Config) orchestrator.JobOrchestrator { return vertexOrchestratorFactory(cfg) })
+	registerBackend(backendBatch, func(cfg orchestrator.Config) orchestrator.JobOrchestrator { return batchOrchestratorFactory(cfg) })
+}
+
+// registerBackend makes orchestrator.New create the orchestrators of backend
+// with factory, which receives the Config passed to orchestrator.New.
+func registerBackend(backend string, factory func(orchestrator.Config) orchestrator.JobOrchestrator) {
+	orchestrator.Register(backend, func(cfg orchestrator.Config) (orchestrator.JobOrchestrator, error) {
+		return factory(cfg), nil
+	})
+}
+
+// selectedBackend returns the --backend of cmd, or GKE for the commands
+// without the flag.
+func selectedBackend(cmd *cobra.Command) string {
+	if f := cmd.Flags().Lookup("backend"); f != nil {
+		return f.Value.String()
+	}
+	return backendGKE
+}
+
+// applyImpersonation switches the orchestrator to read-only impersonation of
+// the --as principal.
+func applyImpersonation() error {
+	if impersonate == "" {
+		return nil
+	}
+	if !strings.Contains(impersonate, "@") {
+		return fmt.Errorf("invalid --as %q: must be the email of a user or service account", impersonate)
+	}
+	orc.Impersonate(impersonate)
+	logging.Warn("Acting as %s in read-only mode: changes are checked by the cluster with a server-side dry run and are not made.", impersonate)
+	if !gke.IsServiceAccount(impersonate) {
+		logging.Warn("Google Cloud can only impersonate service accounts: gcloud commands run with your own credentials, and only the Kubernetes requests are made as %s.", impersonate)
+	}
+	return nil
 }
@@ -0,0 +1,82 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"slices"
+
+	"hpc-toolkit/pkg/jobspec"
+	"hpc-toolkit/pkg/logging"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var jobSpecFile string
+
+// specOverrides lists the flags that, given on the command line, override a
+// flag set by the job spec besides the flag itself, because they cannot be
+// combined with it.
+var specOverrides = map[string][]string{
+	"command":        {"command-json", "command-script"},
+	"command-json":   {"command", "command-script"},
+	"command-script": {"command", "command-json"},
+	"image":          {"base-image", "dockerfile"},
+	"base-image":     {"image", "dockerfile"},
+	"dockerfile":     {"image", "base-image"},
+	"build-context":  {"image"},
+	"requirements":   {"image", "capture-env"},
+	"capture-env":    {"image", "requirements"},
+	"queue":          {"no-queue"},
+	"project":        {"cluster-project"},
+	"compute-type":   {"gpu-memory"},
+	"gpu-memory":     {"compute-type"},
+}
+
+// applyJobSpec sets the flags of cmd from the --file job spec, leaving the ones
+// given on the command line. It runs before the cluster flags are defaulted,
+// since the spec may set them.
+func applyJobSpec(cmd *cobra.Command) error {
+	f := cmd.Flags().Lookup("file")
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	path := f.Value.String()
+	spec, err := jobspec.Load(path)
+	if err != nil {
+		return err
+	}
+	flags, err := spec.Flags()
+	if err != nil {
+		return err
+	}
+	for _, sf := range flags {
+		if overriddenOnCommandLine(cmd.Flags(), sf.Name) {
+			logging.Info("Using --%s from the command line instead of the job spec.", sf.Name)
+			continue
+		}
+		for _, v := range sf.Values {
+			if err := cmd.Flags().Set(sf.Name, v); err != nil {
+				return fmt.Errorf("invalid %s in job spec %s: %w", sf.Name, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func overriddenOnCommandLine(flags *pflag.FlagSet, name string) bool {
+	return slices.ContainsFunc(append([]string{name}, specOverrides[name]...), flags.Changed)
+}
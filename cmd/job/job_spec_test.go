@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/pflag"
+)
+
+func TestSubmitCmd_JobSpec(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	specPath := filepath.Join(t.TempDir(), "job.yaml")
+	spec := `
+name: spec-job
+cluster: spec-cluster
+location: us-central1-a
+project: spec-project
+image: busybox
+command: echo from spec
+compute_type: n2-standard-4
+num_nodes: 2
+env:
+  FOO: bar
+`
+	if err := os.WriteFile(specPath, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clearChanged := func() {
+		for _, fs := range []*pflag.FlagSet{SubmitCmd.Flags(), JobCmd.PersistentFlags()} {
+			fs.VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		}
+	}
+	defer clearChanged()
+
+	clearChanged()
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, "submit", "--file", specPath, "--dry-run"); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.WorkloadName != "spec-job" || got.ClusterName != "spec-cluster" || got.ClusterProjectID != "spec-project" ||
+		got.ImageName != "busybox" || got.CommandToRun != "echo from spec" || got.NodesPerSlice != 2 ||
+		!reflect.DeepEqual(got.Env, map[string]string{"FOO": "bar"}) {
+		t.Errorf("expected the job spec to be submitted, got %+v", got)
+	}
+
+	clearChanged()
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, "submit", "--file", specPath, "--dry-run",
+		"--name", "cli-job", "--command-json", `["echo","from","cli"]`); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.WorkloadName != "cli-job" || got.CommandToRun != "" || !reflect.DeepEqual(got.CommandArgs, []string{"echo", "from", "cli"}) {
+		t.Errorf("expected the command line to override the job spec, got name %q, command %q, args %v", got.WorkloadName, got.CommandToRun, got.CommandArgs)
+	}
+
+	clearChanged()
+	resetSubmitCmdFlags()
+	badPath := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(badPath, []byte("nmae: typo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := executeCommand(JobCmd, "submit", "--file", badPath, "--dry-run")
+	if err == nil || !strings.Contains(err.Error(), "field nmae not found") {
+		t.Errorf("expected an unknown field error, got %v", err)
+	}
+}
@@ -15,23 +15,36 @@
 package job
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"text/tabwriter"
+	"time"
 
 	"hpc-toolkit/pkg/orchestrator"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
 var (
-	filterStatus string
-	filterName   string
+	filterStatus     string
+	filterName       string
+	filterExperiment string
+	listOutput       string
 )
 
 var ListWorkloadsCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List workloads (jobs) in the cluster.",
+	Long: `List the workloads submitted by gcluster in all namespaces of the cluster,
+with their Kueue queue and admission state, age and completion status.`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		switch listOutput {
+		case "table", "json", "yaml":
+		default:
+			return fmt.Errorf("invalid value for --output: %s. Allowed values are: table, json, yaml", listOutput)
+		}
 		if filterStatus != "" {
 			allowed := map[string]bool{
 				"Pending":   true,
@@ -53,6 +66,20 @@ var ListWorkloadsCmd = &cobra.Command{
 func init() {
 	ListWorkloadsCmd.Flags().StringVar(&filterStatus, "status", "", "Filter jobs by status (e.g. Running, Failed, Succeeded).")
 	ListWorkloadsCmd.Flags().StringVar(&filterName, "name-contains", "", "Filter jobs by name containing the specified string.")
+	ListWorkloadsCmd.Flags().StringVar(&filterExperiment, "experiment", "", "Filter jobs by the --experiment they were submitted under.")
+	ListWorkloadsCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format: table, json or yaml.")
+}
+
+// listedWorkload is a workload as printed by list -o json and -o yaml.
+type listedWorkload struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	Status         string `json:"status"`
+	Queue          string `json:"queue,omitempty"`
+	Admission      string `json:"admission,omitempty"`
+	Experiment     string `json:"experiment,omitempty"`
+	CreationTime   string `json:"creationTime"`
+	CompletionTime string `json:"completionTime,omitempty"`
 }
 
 func runListWorkloads(cmd *cobra.Command, args []string) error {
@@ -63,19 +90,78 @@ func runListWorkloads(cmd *cobra.Command, args []string) error {
 		ProjectID:       projectID,
 		Status:          filterStatus,
 		NameContains:    filterName,
+		Experiment:      filterExperiment,
 	}
 
-	jobs, err := orc.ListJobs(opts)
+	jobs, err := orc.ListJobs(cmd.Context(), opts)
 	if err != nil {
 		return err
 	}
 
-	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "NAME	STATUS	CREATION_TIME	COMPLETION_TIME")
+	return printWorkloads(cmd.OutOrStdout(), jobs, listOutput, time.Now())
+}
+
+func printWorkloads(out io.Writer, jobs []orchestrator.JobStatus, format string, now time.Time) error {
+	if format == "table" {
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tNAMESPACE\tQUEUE\tADMISSION\tSTATUS\tAGE\tCOMPLETION_TIME")
+		for _, job := range jobs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", job.Name, job.Namespace, orNone(job.Queue), orNone(job.KueueState), job.Status, workloadAge(job.CreationTime, now), orNone(job.CompletionTime))
+		}
+		return w.Flush()
+	}
+
+	listed := make([]listedWorkload, 0, len(jobs))
 	for _, job := range jobs {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", job.Name, job.Status, job.CreationTime, job.CompletionTime)
+		listed = append(listed, listedWorkload{
+			Name:           job.Name,
+			Namespace:      job.Namespace,
+			Status:         job.Status,
+			Queue:          job.Queue,
+			Admission:      job.KueueState,
+			Experiment:     job.Experiment,
+			CreationTime:   job.CreationTime,
+			CompletionTime: job.CompletionTime,
+		})
+	}
+	data, err := json.MarshalIndent(listed, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format == "yaml" {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
 	}
-	w.Flush()
+	return s
+}
 
-	return nil
+// workloadAge formats the time since an RFC 3339 creation time the way
+// kubectl does, in its largest whole unit: 45s, 12m, 5h or 3d.
+func workloadAge(creationTime string, now time.Time) string {
+	created, err := time.Parse(time.RFC3339, creationTime)
+	if err != nil {
+		return "<unknown>"
+	}
+	age := now.Sub(created)
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", max(int(age.Seconds()), 0))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
 }
@@ -15,10 +15,15 @@
 package job
 
 import (
+	"bytes"
+	"encoding/json"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/orchestrator/gke"
 	"strings"
 	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
 )
 
 func TestListWorkloadsCmd_Success(t *testing.T) {
@@ -28,7 +33,7 @@ func TestListWorkloadsCmd_Success(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		g := gke.NewGKEOrchestrator()
 		g.SetExecutor(&mockCancelExecutor{}) // Use the mock from cancel_test.go if available
 		g.SetKubeClient(&mockKubeClient{namespace: "default"})
@@ -60,3 +65,72 @@ func TestListWorkloadsCmd_InvalidStatus(t *testing.T) {
 		t.Errorf("expected error to contain %q, got %v", expectedErr, err)
 	}
 }
+
+var listTestJobs = []orchestrator.JobStatus{
+	{Name: "train", Namespace: "default", Status: "Running", CreationTime: "2026-10-16T09:30:00Z", Queue: "team-a", KueueState: "Admitted"},
+	{Name: "eval", Namespace: "research", Status: "Succeeded", CreationTime: "2026-10-13T08:00:00Z", CompletionTime: "2026-10-13T09:00:00Z", Queue: "team-b", KueueState: "Finished", Experiment: "lr-sweep"},
+	{Name: "sweep", Namespace: "default", Status: "Suspended", CreationTime: "2026-10-16T09:59:15Z"},
+}
+
+func TestPrintWorkloads_Table(t *testing.T) {
+	var out bytes.Buffer
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	if err := printWorkloads(&out, listTestJobs, "table", now); err != nil {
+		t.Fatalf("printWorkloads() error = %v", err)
+	}
+	want := `NAME    NAMESPACE   QUEUE    ADMISSION   STATUS      AGE   COMPLETION_TIME
+train   default     team-a   Admitted    Running     30m   <none>
+eval    research    team-b   Finished    Succeeded   3d    2026-10-13T09:00:00Z
+sweep   default     <none>   <none>      Suspended   45s   <none>
+`
+	if out.String() != want {
+		t.Errorf("printWorkloads() =\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestListWorkloadsCmd_Output(t *testing.T) {
+	resetSubmitCmdFlags()
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &mockJobOrchestrator{jobs: listTestJobs}
+	}
+	t.Cleanup(resetSubmitCmdFlags)
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			output, err := executeCommand(JobCmd, "list", "-o", format, "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+			if err != nil {
+				t.Fatalf("list -o %s failed: %v", format, err)
+			}
+			data := []byte(output)
+			if format == "yaml" {
+				if data, err = yaml.YAMLToJSON(data); err != nil {
+					t.Fatalf("list -o yaml printed invalid YAML: %v\n%s", err, output)
+				}
+			}
+			var got []listedWorkload
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("failed to parse output: %v\n%s", err, output)
+			}
+			want := listedWorkload{Name: "eval", Namespace: "research", Status: "Succeeded", Queue: "team-b", Admission: "Finished", Experiment: "lr-sweep", CreationTime: "2026-10-13T08:00:00Z", CompletionTime: "2026-10-13T09:00:00Z"}
+			if len(got) != 3 || got[1] != want {
+				t.Errorf("got %+v, want 3 workloads with %+v second", got, want)
+			}
+		})
+	}
+
+	mock := &mockJobOrchestrator{jobs: listTestJobs}
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+	if _, err := executeCommand(JobCmd, "list", "--experiment", "lr-sweep", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project"); err != nil {
+		t.Fatalf("list --experiment failed: %v", err)
+	}
+	if mock.listOpts.Experiment != "lr-sweep" {
+		t.Errorf("expected the experiment filter to be passed on, got %+v", mock.listOpts)
+	}
+
+	_, err := executeCommand(JobCmd, "list", "-o", "wide", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --output") {
+		t.Errorf("expected an invalid --output error, got %v", err)
+	}
+}
@@ -15,14 +15,19 @@
 package job
 
 import (
+	"fmt"
 	"hpc-toolkit/pkg/orchestrator"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var LogsCmd = &cobra.Command{
-	Use:          "logs [job-name]",
-	Short:        "Fetch logs for a job in the cluster.",
+	Use:   "logs [job-name]",
+	Short: "Fetch logs for a job in the cluster.",
+	Long: `The 'logs' command prints the logs of all containers in a job's pods, each
+line prefixed with its pod and container name. Use --follow to keep streaming
+and --since to skip older output.`,
 	Args:         cobra.ExactArgs(1),
 	RunE:         runLogsCmd,
 	SilenceUsage: true,
@@ -30,14 +35,19 @@ var LogsCmd = &cobra.Command{
 
 var follow bool
 var mainOnly bool
+var since time.Duration
 
 func init() {
 	LogsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Stream logs continuously")
+	LogsCmd.Flags().DurationVar(&since, "since", 0, "Only return logs newer than a relative duration (e.g. 10m, 2h)")
 	LogsCmd.Flags().BoolVar(&mainOnly, "main-only", false, "Fetch logs only for the main replicated job (main-job or pathways-head)")
 }
 
 func runLogsCmd(cmd *cobra.Command, args []string) error {
 	jobName := args[0]
+	if since < 0 {
+		return fmt.Errorf("--since must be a positive duration, got %s", since)
+	}
 
 	var mainOnlyPtr *bool
 	if cmd.Flags().Changed("main-only") {
@@ -51,8 +61,11 @@ func runLogsCmd(cmd *cobra.Command, args []string) error {
 		Follow:          follow,
 		MainOnly:        mainOnlyPtr,
 	}
+	if since > 0 {
+		opts.Since = since.String()
+	}
 
-	output, err := orc.GetJobLogs(jobName, opts)
+	output, err := orc.GetJobLogs(cmd.Context(), jobName, opts)
 	if err != nil {
 		return err
 	}
@@ -17,22 +17,32 @@ package job
 import (
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/orchestrator/gke"
-	"hpc-toolkit/pkg/shell"
 	"strings"
 	"testing"
-)
-
-type mockLogsExecutor struct{}
 
-func (m *mockLogsExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
-	if name == "kubectl" && len(args) > 0 && args[0] == "logs" {
-		return shell.CommandResult{ExitCode: 0, Stdout: "mock logs output"}
-	}
-	return shell.CommandResult{ExitCode: 0}
-}
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
 
-func (m *mockLogsExecutor) ExecuteCommandStream(name string, args ...string) error {
-	return nil
+// newLogsOrchestrator returns an orchestrator whose cluster runs the pod
+// test-job-0 of the workload test-job, with kube serving its logs.
+func newLogsOrchestrator(kube *mockKubeClient) *gke.GKEOrchestrator {
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      "test-job-0",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"jobset.sigs.k8s.io/jobset-name": "test-job"},
+		},
+		"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "main"}}},
+	}}
+	g := gke.NewGKEOrchestrator()
+	g.SetExecutor(&mockCancelExecutor{})
+	g.SetDynamicClient(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), pod))
+	g.SetKubeClient(kube)
+	return g
 }
 
 func TestLogsCmd_Success(t *testing.T) {
@@ -42,11 +52,8 @@ func TestLogsCmd_Success(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
-		g := gke.NewGKEOrchestrator()
-		g.SetExecutor(&mockLogsExecutor{})
-		g.SetKubeClient(&mockKubeClient{namespace: "default"})
-		return g
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return newLogsOrchestrator(&mockKubeClient{namespace: "default", logs: "mock logs output\n"})
 	}
 
 	output, err := executeCommand(JobCmd, "logs", "test-job", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
@@ -64,3 +71,27 @@ func TestLogsCmd_Success(t *testing.T) {
 		t.Errorf("expected output to contain 'mock logs output', got %q", output)
 	}
 }
+
+func TestLogsCmd_Since(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Cleanup(func() { since = 0 })
+
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+
+	kube := &mockKubeClient{namespace: "default"}
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return newLogsOrchestrator(kube)
+	}
+
+	if _, err := executeCommand(JobCmd, "logs", "test-job", "--since", "-5m", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project"); err == nil || !strings.Contains(err.Error(), "--since must be a positive duration") {
+		t.Fatalf("expected negative --since to be rejected, got %v", err)
+	}
+
+	if _, err := executeCommand(JobCmd, "logs", "test-job", "--since", "10m", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kube.logOptions) != 1 || kube.logOptions[0].SinceSeconds == nil || *kube.logOptions[0].SinceSeconds != 600 {
+		t.Errorf("expected the logs of the last 10 minutes, got %+v", kube.logOptions)
+	}
+}
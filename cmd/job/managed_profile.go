@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"hpc-toolkit/pkg/localstate"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// managedProfileFile is installed by cluster admins to bound the workloads a
+// team submits. gcluster only reads it.
+var managedProfileFile = localstate.File{Name: "managed_profile.json", Version: 1}
+
+// loadManagedProfile returns the installed managed profile, or nil if there is
+// none. A profile that cannot be read fails the submission rather than being
+// ignored.
+func loadManagedProfile() (*orchestrator.ManagedProfile, error) {
+	var mp orchestrator.ManagedProfile
+	if err := managedProfileFile.Load(&mp); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load the managed profile: %w", err)
+	}
+	path, _ := managedProfileFile.Path()
+	if mp.Name == "" {
+		return nil, fmt.Errorf("the managed profile %s has no name", path)
+	}
+	if mp.MaxGPUs < 0 {
+		return nil, fmt.Errorf("the managed profile %s has a negative max_gpus", path)
+	}
+	logging.Info("Submitting under managed profile %q from %s", mp.Name, path)
+	return &mp, nil
+}
+
+// applyManagedProfileDefaults fills in the settings the managed profile
+// chooses when their flags are not set.
+func applyManagedProfileDefaults(mp *orchestrator.ManagedProfile, job *orchestrator.JobDefinition) {
+	if mp == nil {
+		return
+	}
+	if job.KueueQueueName == "" && !job.NoQueue && len(mp.AllowedQueues) > 0 {
+		job.KueueQueueName = mp.AllowedQueues[0]
+	}
+}
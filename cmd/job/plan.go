@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var PlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Preview which nodes a job's pods would be scheduled on.",
+	Long: `The 'plan' command simulates the scheduling of a job before it is submitted.
+It reads the allocatable resources of the cluster's nodes and the requests of
+the pods already running on them, then places the job's pods first-fit on the
+nodes whose labels match the pods' node selector and affinity and whose taints
+the pods tolerate.
+
+It reports the nodes and node pools that would host the pods, and for pods
+that fit nowhere, the reasons the nodes reject them, such as an untolerated
+taint or insufficient GPUs. Pass --spec with a manifest written by
+'gcluster job submit --dry-run-out'. Kueue quota is not checked; use
+'gcluster job eta' for that.`,
+	Args:         cobra.NoArgs,
+	RunE:         runPlanCmd,
+	SilenceUsage: true,
+}
+
+var planSpecPath string
+
+func init() {
+	PlanCmd.Flags().StringVar(&planSpecPath, "spec", "", "Path of a JobSet or Job manifest to plan.")
+	_ = PlanCmd.MarkFlagRequired("spec")
+}
+
+func runPlanCmd(cmd *cobra.Command, args []string) error {
+	plan, err := orc.PlanPlacement(cmd.Context(), orchestrator.PlanOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+		SpecPath:        planSpecPath,
+	})
+	if err != nil {
+		return err
+	}
+	return printPlacementPlan(cmd.OutOrStdout(), plan)
+}
+
+func printPlacementPlan(out io.Writer, plan orchestrator.PlacementPlan) error {
+	fmt.Fprintf(out, "Workload: %s (%s)\n", plan.Workload, plan.Kind)
+
+	total, unplaced := 0, 0
+	for _, ps := range plan.PodSets {
+		total += ps.Pods
+		unplaced += ps.Unplaced
+		requests := ps.PodRequests
+		if requests == "" {
+			requests = "nothing"
+		}
+		fmt.Fprintf(out, "\nPod set %s: %d pod(s), each requesting %s\n", ps.Name, ps.Pods, requests)
+		if len(ps.Placements) > 0 {
+			w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "NODE POOL\tNODE\tPODS")
+			for _, p := range ps.Placements {
+				pool := p.NodePool
+				if pool == "" {
+					pool = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\n", pool, p.Node, p.Pods)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if ps.Unplaced > 0 {
+			fmt.Fprintf(out, "%d pod(s) fit on no node:\n", ps.Unplaced)
+			for _, r := range ps.Reasons {
+				fmt.Fprintf(out, "  - %s\n", r)
+			}
+		}
+	}
+
+	if unplaced == 0 {
+		fmt.Fprintf(out, "\nAll %d pod(s) fit on the current nodes.\n", total)
+	} else {
+		fmt.Fprintf(out, "\n%d of %d pod(s) fit on no current node.\n", unplaced, total)
+	}
+	for _, note := range plan.Notes {
+		fmt.Fprintf(out, "  - %s\n", note)
+	}
+	return nil
+}
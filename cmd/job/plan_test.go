@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+)
+
+func TestPlanCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+	mock := &mockJobOrchestrator{plan: orchestrator.PlacementPlan{
+		Workload: "train",
+		Kind:     "JobSet",
+		PodSets: []orchestrator.PodSetPlan{{
+			Name:        "main-job",
+			Pods:        4,
+			PodRequests: "cpu=8, nvidia.com/gpu=1",
+			Placements:  []orchestrator.NodePlacement{{Node: "gke-gpu-1", NodePool: "gpu-pool", Pods: 2}},
+			Unplaced:    2,
+			Reasons:     []string{"3 Insufficient nvidia.com/gpu"},
+		}},
+		Notes: []string{"No node pool has autoscaling enabled."},
+	}}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+	t.Cleanup(func() { planSpecPath = "" })
+
+	output, err := executeCommand(JobCmd, "plan", "--spec", "train.yaml", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err != nil {
+		t.Fatalf("plan command failed: %v", err)
+	}
+	if mock.planOpts.SpecPath != "train.yaml" || mock.planOpts.ClusterName != "test-cluster" || mock.planOpts.ProjectID != "test-project" {
+		t.Errorf("unexpected call: %+v", mock.planOpts)
+	}
+	for _, want := range []string{
+		"Workload: train (JobSet)",
+		"Pod set main-job: 4 pod(s), each requesting cpu=8, nvidia.com/gpu=1",
+		"NODE POOL",
+		"gpu-pool    gke-gpu-1   2",
+		"2 pod(s) fit on no node:\n  - 3 Insufficient nvidia.com/gpu",
+		"2 of 4 pod(s) fit on no current node.",
+		"  - No node pool has autoscaling enabled.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -17,7 +17,9 @@ package job
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hpc-toolkit/pkg/localstate"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/shell"
 	"os"
@@ -36,44 +38,23 @@ type PrereqStore interface {
 
 type FilePrereqStore struct{}
 
-func (f *FilePrereqStore) Load() PrereqState {
-	filePath, err := stateFilePath()
-	if err != nil {
-		logging.Error("Failed to get state file path for loading: %v", err)
-		return PrereqState{}
-	}
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			logging.Error("Failed to read prerequisite state from %s: %v", filePath, err)
-		}
-		return PrereqState{}
-	}
+// prereqStateFile is the versioned on-disk location of the prerequisite state.
+var prereqStateFile = localstate.File{Name: stateFileName, Version: 1}
 
+func (f *FilePrereqStore) Load() PrereqState {
 	var state PrereqState
-	if err := json.Unmarshal(data, &state); err != nil {
-		logging.Error("Failed to unmarshal prerequisite state from %s: %v. Starting with fresh state.", filePath, err)
+	if err := prereqStateFile.Load(&state); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logging.Error("Failed to load prerequisite state: %v. Starting with fresh state.", err)
+		}
 		return PrereqState{}
 	}
 	return state
 }
 
 func (f *FilePrereqStore) Save(state PrereqState) {
-	filePath, err := stateFilePath()
-	if err != nil {
-		logging.Error("Failed to get state file path for saving: %v", err)
-		return
-	}
-
-	data, err := json.MarshalIndent(state, "", "  ")
-	if err != nil {
-		logging.Error("Failed to marshal prerequisite state: %v", err)
-		return
-	}
-
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		logging.Error("Failed to write prerequisite state to %s: %v", filePath, err)
+	if err := prereqStateFile.Save(state); err != nil {
+		logging.Error("Failed to save prerequisite state: %v", err)
 	}
 }
 
@@ -81,24 +62,16 @@ var store PrereqStore = &FilePrereqStore{}
 
 // stateFilePath returns the full path to the prerequisite state file.
 func stateFilePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("could not get user home directory: %w", err)
-	}
-	stateDir := filepath.Join(homeDir, stateDirName)
-	if err := os.MkdirAll(stateDir, 0755); err != nil {
-		return "", fmt.Errorf("could not create state directory %s: %w", stateDir, err)
-	}
-	return filepath.Join(stateDir, stateFileName), nil
+	return prereqStateFile.Path()
 }
 
 // isStateStale checks if the loaded state is older than the defined freshness threshold
-// or if the project ID has changed.
-func isStateStale(state PrereqState, currentProjectID string) bool {
+// or if the project IDs have changed.
+func isStateStale(state PrereqState, currentProjectID, currentBuildProjectID string) bool {
 	if time.Since(state.LastCheckedTimestamp) > stateFreshness {
 		return true
 	}
-	if state.LastCheckedProjectID != currentProjectID {
+	if state.LastCheckedProjectID != currentProjectID || state.LastCheckedBuildProjectID != currentBuildProjectID {
 		return true
 	}
 	return false
@@ -225,14 +198,16 @@ func isDockerCredsConfigured(region string) bool {
 }
 
 // EnsurePrerequisites checks all necessary gcloud and kubectl prerequisites.
-func ensurePrerequisites(cmd *cobra.Command, projectID *string, location string) error {
-	if dryRunManifest != "" {
+// Images are pushed to buildProjectID, or to the cluster's projectID when it is
+// empty.
+func ensurePrerequisites(cmd *cobra.Command, projectID *string, buildProjectID, location string) error {
+	if dryRunManifest != "" || dryRun {
 		return nil
 	}
 
 	state := store.Load()
 
-	if !isStateStale(state, *projectID) {
+	if !isStateStale(state, *projectID, buildProjectID) {
 		logging.Info("Skipping checks; prerequisites are fresh (project: %s, checked: %v ago).", state.LastCheckedProjectID, time.Since(state.LastCheckedTimestamp).Round(time.Second))
 		return nil
 	}
@@ -279,12 +254,16 @@ func ensurePrerequisites(cmd *cobra.Command, projectID *string, location string)
 	}
 
 	// Check Artifact Registry API
-	if *projectID != "" {
-		apiResult := shell.ExecuteCommand("gcloud", "services", "list", "--filter=NAME:artifactregistry.googleapis.com", "--format=value(STATE)", "--project", *projectID)
+	registryProjectID := buildProjectID
+	if registryProjectID == "" {
+		registryProjectID = *projectID
+	}
+	if registryProjectID != "" {
+		apiResult := shell.ExecuteCommand("gcloud", "services", "list", "--filter=NAME:artifactregistry.googleapis.com", "--format=value(STATE)", "--project", registryProjectID)
 		if strings.TrimSpace(apiResult.Stdout) != "ENABLED" {
 			missing = append(missing, missingPrereq{
 				name:     "Artifact Registry API",
-				commands: []string{fmt.Sprintf("gcloud services enable artifactregistry.googleapis.com --project %s --quiet", *projectID)},
+				commands: []string{fmt.Sprintf("gcloud services enable artifactregistry.googleapis.com --project %s --quiet", registryProjectID)},
 			})
 		} else {
 			state.ArtifactRegistryAPIEnabled = true
@@ -298,6 +277,7 @@ func ensurePrerequisites(cmd *cobra.Command, projectID *string, location string)
 
 	state.LastCheckedTimestamp = time.Now()
 	state.LastCheckedProjectID = *projectID
+	state.LastCheckedBuildProjectID = buildProjectID
 	store.Save(state)
 
 	logging.Info("Prerequisites checked successfully.")
@@ -33,10 +33,11 @@ func TestIsStateStale(t *testing.T) {
 	staleTime := now.Add(-48 * time.Hour)
 
 	tests := []struct {
-		name             string
-		state            PrereqState
-		currentProjectID string
-		wantStale        bool
+		name                  string
+		state                 PrereqState
+		currentProjectID      string
+		currentBuildProjectID string
+		wantStale             bool
 	}{
 		{
 			name: "Fresh state, same project",
@@ -65,11 +66,21 @@ func TestIsStateStale(t *testing.T) {
 			currentProjectID: "new-project",
 			wantStale:        true,
 		},
+		{
+			name: "Fresh state, different build project",
+			state: PrereqState{
+				LastCheckedTimestamp: freshTime,
+				LastCheckedProjectID: "test-project",
+			},
+			currentProjectID:      "test-project",
+			currentBuildProjectID: "build-project",
+			wantStale:             true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isStateStale(tt.state, tt.currentProjectID)
+			got := isStateStale(tt.state, tt.currentProjectID, tt.currentBuildProjectID)
 			if got != tt.wantStale {
 				t.Errorf("isStateStale() = %v, want %v", got, tt.wantStale)
 			}
@@ -433,7 +444,7 @@ func TestEnsurePrerequisites_DockerCreds(t *testing.T) {
 	var buf bytes.Buffer
 	cmd.SetOut(&buf)
 
-	err := ensurePrerequisites(cmd, &projectID, location)
+	err := ensurePrerequisites(cmd, &projectID, "", location)
 	if err == nil {
 		t.Error("expected error because prerequisites are missing, got nil")
 	}
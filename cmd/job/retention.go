@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// parseRetentionRules parses --retention values of the form
+// <path>[,keep-last=<n>][,expire-after=<age>], where path is a gs:// prefix or
+// an absolute path on a volume mount.
+func parseRetentionRules(values []string) ([]orchestrator.RetentionRule, error) {
+	var res []orchestrator.RetentionRule
+	seen := map[string]bool{}
+	for _, value := range values {
+		parts := strings.Split(value, ",")
+		rule := orchestrator.RetentionRule{Path: parts[0]}
+		if bucket, _, _ := strings.Cut(strings.TrimPrefix(rule.Path, "gs://"), "/"); strings.HasPrefix(rule.Path, "gs://") {
+			if bucket == "" {
+				return nil, fmt.Errorf("invalid --retention %q: %q has no bucket", value, rule.Path)
+			}
+			rule.Path = strings.TrimRight(rule.Path, "/")
+		} else if path.IsAbs(rule.Path) {
+			rule.Path = path.Clean(rule.Path)
+		} else {
+			return nil, fmt.Errorf("invalid --retention %q: the path must be a gs:// prefix or an absolute path on a --mount", value)
+		}
+		for _, opt := range parts[1:] {
+			key, val, _ := strings.Cut(opt, "=")
+			switch key {
+			case "keep-last":
+				n, err := strconv.Atoi(val)
+				if err != nil || n <= 0 {
+					return nil, fmt.Errorf("invalid --retention %q: keep-last must be a positive integer", value)
+				}
+				rule.KeepLast = n
+			case "expire-after":
+				d, err := parseAge(val)
+				if err != nil {
+					return nil, fmt.Errorf("invalid --retention %q: expire-after %w", value, err)
+				}
+				rule.ExpireAfter = d
+			default:
+				return nil, fmt.Errorf("invalid --retention %q: unknown option %q; use keep-last=<n> or expire-after=<age>", value, opt)
+			}
+		}
+		if rule.KeepLast == 0 && rule.ExpireAfter == 0 {
+			return nil, fmt.Errorf("invalid --retention %q: set keep-last, expire-after or both", value)
+		}
+		if seen[rule.Path] {
+			return nil, fmt.Errorf("--retention %s is given more than once", rule.Path)
+		}
+		seen[rule.Path] = true
+		res = append(res, rule)
+	}
+	return res, nil
+}
+
+// parseAge parses a number of days such as 30d, or a Go duration such as 36h.
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("must be a positive number of days (e.g. 30d) or a duration (e.g. 36h), got %q", value)
+}
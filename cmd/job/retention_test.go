@@ -0,0 +1,57 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestParseRetentionRules(t *testing.T) {
+	got, err := parseRetentionRules([]string{
+		"gs://runs/checkpoints/,keep-last=3,expire-after=30d",
+		"/mnt/nfs/outputs/,expire-after=36h",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []orchestrator.RetentionRule{
+		{Path: "gs://runs/checkpoints", KeepLast: 3, ExpireAfter: 30 * 24 * time.Hour},
+		{Path: "/mnt/nfs/outputs", ExpireAfter: 36 * time.Hour},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRetentionRules() = %+v, want %+v", got, want)
+	}
+
+	for value, wantErr := range map[string]string{
+		"gs://runs/ckpt":                   "set keep-last, expire-after or both",
+		"gs:///ckpt,keep-last=1":           "has no bucket",
+		"outputs,keep-last=1":              "must be a gs:// prefix or an absolute path",
+		"gs://runs/ckpt,keep-last=0":       "keep-last must be a positive integer",
+		"gs://runs/ckpt,expire-after=1.5d": "must be a positive number of days",
+		"gs://runs/ckpt,keep=3":            `unknown option "keep=3"`,
+	} {
+		if _, err := parseRetentionRules([]string{value}); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseRetentionRules(%q) error = %v, want %q", value, err, wantErr)
+		}
+	}
+	if _, err := parseRetentionRules([]string{"gs://a/b,keep-last=1", "gs://a/b/,keep-last=2"}); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected a duplicate path error, got %v", err)
+	}
+}
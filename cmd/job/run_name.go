@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"hpc-toolkit/pkg/localstate"
+	"hpc-toolkit/pkg/logging"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxWorkloadNameLength bounds workload names, which prefix the names of
+// Kubernetes and GCE resources created for the workload.
+const maxWorkloadNameLength = 28
+
+var nameTemplate string
+
+// runSequenceFile records the last run number handed out to each experiment
+// by {{.Seq}} in name templates.
+var runSequenceFile = localstate.File{Name: "run_sequences.json", Version: 1}
+
+type runSequences struct {
+	Experiments map[string]int `json:"experiments"`
+}
+
+// runNameData is the data a name template is rendered with.
+type runNameData struct {
+	User       string
+	Team       string
+	Experiment string
+	// Seq is the run number of the experiment, incremented on every submission
+	// whose name template uses it.
+	Seq int
+}
+
+// configuredNameTemplate returns --name-template, or the name-template
+// configuration.
+func configuredNameTemplate() string {
+	if nameTemplate != "" {
+		return nameTemplate
+	}
+	return loadContext().NameTemplate
+}
+
+// workloadNameFromTemplate renders the name of a workload submitted without
+// --name. When the template uses {{.Seq}}, the next run number of the
+// experiment is reserved, unless reserve is false, e.g. for a dry run.
+func workloadNameFromTemplate(text string, reserve bool) (string, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid name template %q: %w", text, err)
+	}
+
+	labels, err := costAllocationLabels(costTeam, costExperiment)
+	if err != nil {
+		return "", err
+	}
+	data := runNameData{User: labels["user"], Team: labels["team"], Experiment: labels["experiment"]}
+	if strings.Contains(text, ".Seq") {
+		if data.Seq, err = nextRunSeq(data.Experiment, reserve); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render name template %q: %w", text, err)
+	}
+	name := buf.String()
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return "", fmt.Errorf("name template %q rendered the invalid workload name %q: %s", text, name, strings.Join(errs, "; "))
+	}
+	if len(name) > maxWorkloadNameLength {
+		return "", fmt.Errorf("name template %q rendered the workload name %q, which exceeds %d characters", text, name, maxWorkloadNameLength)
+	}
+	logging.Info("Using workload name %s, rendered from the name template %q", name, text)
+	return name, nil
+}
+
+// nextRunSeq returns the next run number of experiment, and records it as
+// used when reserve is set.
+func nextRunSeq(experiment string, reserve bool) (int, error) {
+	var seqs runSequences
+	if !reserve {
+		if err := runSequenceFile.Load(&seqs); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, err
+		}
+		return seqs.Experiments[experiment] + 1, nil
+	}
+
+	var seq int
+	err := runSequenceFile.Update(&seqs, func() error {
+		if seqs.Experiments == nil {
+			seqs.Experiments = map[string]int{}
+		}
+		seqs.Experiments[experiment]++
+		seq = seqs.Experiments[experiment]
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve a run number: %w", err)
+	}
+	return seq, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWorkloadNameFromTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USER", "Alice")
+	defer func() { costTeam, costExperiment = "", "" }()
+
+	const tmpl = "{{.User}}-{{.Experiment}}-r{{.Seq}}"
+	costExperiment = "llama"
+	for _, tc := range []struct {
+		reserve bool
+		want    string
+	}{
+		{true, "alice-llama-r1"},
+		{true, "alice-llama-r2"},
+		{false, "alice-llama-r3"}, // A dry run does not use up the number.
+		{true, "alice-llama-r3"},
+	} {
+		got, err := workloadNameFromTemplate(tmpl, tc.reserve)
+		if err != nil || got != tc.want {
+			t.Errorf("workloadNameFromTemplate(reserve=%v) = %q, %v; want %q", tc.reserve, got, err, tc.want)
+		}
+	}
+
+	// Every experiment counts its own runs.
+	costExperiment = "gemma"
+	if got, err := workloadNameFromTemplate(tmpl, true); err != nil || got != "alice-gemma-r1" {
+		t.Errorf("got %q, %v; want alice-gemma-r1", got, err)
+	}
+	if got, err := workloadNameFromTemplate(`{{.Experiment}}-{{printf "%03d" .Seq}}`, true); err != nil || got != "gemma-002" {
+		t.Errorf("got %q, %v; want gemma-002", got, err)
+	}
+
+	for tmpl, wantErr := range map[string]string{
+		"{{.User":                  "invalid name template",
+		"{{.Project}}":             "failed to render",
+		"{{.Experiment}}_{{.Seq}}": "invalid workload name",
+		"{{.User}}-{{.Experiment}}-a-much-longer-suffix": "exceeds 28 characters",
+	} {
+		if _, err := workloadNameFromTemplate(tmpl, false); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("workloadNameFromTemplate(%q) error = %v, want %q", tmpl, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+var StatusCmd = &cobra.Command{
+	Use:          "status [job-name]",
+	Short:        "Show the detailed state of a job: replicated jobs, pods, retries and Kueue admission.",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runStatusCmd,
+	SilenceUsage: true,
+}
+
+func runStatusCmd(cmd *cobra.Command, args []string) error {
+	opts := orchestrator.StatusOptions{
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		ProjectID:       projectID,
+	}
+
+	status, err := orc.GetJobStatus(cmd.Context(), args[0], opts)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if err := printJobStatus(out, status); err != nil {
+		return err
+	}
+	if len(status.ProbableCauses) > 0 {
+		fmt.Fprintln(out, "\nProbable Causes:")
+		for _, line := range status.ProbableCauses {
+			fmt.Fprintf(out, "  - %s\n", line)
+		}
+	}
+	return nil
+}
+
+func printJobStatus(out io.Writer, s orchestrator.JobStatusDetail) error {
+	if s.StageIn != nil && len(s.ReplicatedJobs) == 0 {
+		// The workload is only submitted once its stage-in completes.
+		fmt.Fprintf(out, "Name:       %s\n", s.Name)
+		fmt.Fprintf(out, "Namespace:  %s\n", s.Namespace)
+		fmt.Fprintf(out, "Status:     %s\n", s.Status)
+		printStageIn(out, s.StageIn)
+		return nil
+	}
+
+	kueueState := s.KueueState
+	if kueueState == "" {
+		kueueState = "Unknown"
+	}
+	if s.ClusterQueue != "" {
+		kueueState = fmt.Sprintf("%s (ClusterQueue: %s)", kueueState, s.ClusterQueue)
+	}
+
+	fmt.Fprintf(out, "Name:       %s\n", s.Name)
+	fmt.Fprintf(out, "Namespace:  %s\n", s.Namespace)
+	fmt.Fprintf(out, "Status:     %s\n", s.Status)
+	fmt.Fprintf(out, "Restarts:   %d/%d (%d retries remaining)\n", s.Restarts, s.MaxRestarts, s.RetriesRemaining)
+	fmt.Fprintf(out, "Queue:      %s\n", s.KueueQueue)
+	fmt.Fprintf(out, "Admission:  %s\n", kueueState)
+	if s.StageIn != nil {
+		printStageIn(out, s.StageIn)
+	}
+
+	fmt.Fprintln(out, "\nReplicated Jobs:")
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREPLICAS\tACTIVE\tREADY\tSUCCEEDED\tFAILED\tSUSPENDED")
+	for _, rj := range s.ReplicatedJobs {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", rj.Name, rj.Replicas, rj.Active, rj.Ready, rj.Succeeded, rj.Failed, rj.Suspended)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nPods:")
+	if len(s.Pods) == 0 {
+		fmt.Fprintln(out, "  No pods found.")
+		return nil
+	}
+	w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREPLICATED_JOB\tPHASE\tRESTARTS\tNODE\tLAST_TERMINATION")
+	for _, p := range s.Pods {
+		phase := p.Phase
+		if p.State != "" {
+			phase = fmt.Sprintf("%s (%s)", p.Phase, p.State)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", p.Name, p.ReplicatedJob, phase, p.Restarts, p.Node, terminationText(p.Terminations))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(s.GPUErrors) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "\nGPU Errors:")
+	w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tGPU\tXID\tPOD\tSOURCE\tDESCRIPTION")
+	for _, e := range s.GPUErrors {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", e.Node, e.GPU, e.XID, e.Pod, e.Source, e.Description)
+	}
+	return w.Flush()
+}
+
+// terminationText describes why the containers of a pod last stopped, e.g.
+// "OOMKilled at 16Gi (exit 137)".
+func terminationText(terminations []orchestrator.ContainerTermination) string {
+	var parts []string
+	for _, t := range terminations {
+		text := t.Reason
+		if t.MemoryHighWater != "" {
+			text += " at " + t.MemoryHighWater
+		}
+		text = fmt.Sprintf("%s (exit %d)", text, t.ExitCode)
+		if len(terminations) > 1 {
+			text = t.Container + ": " + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printStageIn(out io.Writer, s *orchestrator.StageInStatus) {
+	fmt.Fprintf(out, "Stage-in:   %s\n", s.State)
+	for _, t := range s.Transfers {
+		fmt.Fprintf(out, "  %s\n", t)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+)
+
+type mockStatusOrchestrator struct {
+	orchestrator.JobOrchestrator
+	gotName string
+	gotOpts orchestrator.StatusOptions
+}
+
+func (m *mockStatusOrchestrator) GetJobStatus(_ context.Context, name string, opts orchestrator.StatusOptions) (orchestrator.JobStatusDetail, error) {
+	m.gotName, m.gotOpts = name, opts
+	return orchestrator.JobStatusDetail{
+		Name:             name,
+		Namespace:        "default",
+		Status:           "Running",
+		Restarts:         1,
+		MaxRestarts:      3,
+		RetriesRemaining: 2,
+		KueueQueue:       "multislice-queue",
+		KueueState:       "Admitted",
+		ClusterQueue:     "default-queue",
+		ReplicatedJobs:   []orchestrator.ReplicatedJobStatus{{Name: "main-job", Replicas: 2, Active: 2, Ready: 2}},
+		Pods: []orchestrator.PodStatus{
+			{Name: "train-main-job-0-0-a", ReplicatedJob: "main-job", Phase: "Running", Node: "node-a"},
+			{Name: "train-main-job-0-1-b", ReplicatedJob: "main-job", Phase: "Running", Node: "node-b", State: orchestrator.PodStateLoading, Restarts: 1,
+				Terminations: []orchestrator.ContainerTermination{{Container: "workload", Reason: "OOMKilled", ExitCode: 137, MemoryHighWater: "16Gi"}}},
+		},
+		GPUErrors: []orchestrator.GPUError{
+			{Node: "node-a", GPU: "0", Pod: "train-main-job-0-0-a", XID: 79, Source: orchestrator.GPUErrorSourceDmesg, Description: "GPU has fallen off the bus"},
+		},
+		ProbableCauses: []string{"GPU XID 79 (GPU has fallen off the bus) on node node-a."},
+	}, nil
+}
+
+func TestStatusCmd(t *testing.T) {
+	resetSubmitCmdFlags()
+	mock := &mockStatusOrchestrator{}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+
+	output, err := executeCommand(JobCmd, "status", "train", "--cluster", "test-cluster", "--location", "us-central1-a", "--project", "test-project")
+	if err != nil {
+		t.Fatalf("status command failed: %v", err)
+	}
+	if mock.gotName != "train" || mock.gotOpts.ClusterName != "test-cluster" {
+		t.Errorf("unexpected call: %q %+v", mock.gotName, mock.gotOpts)
+	}
+	for _, want := range []string{
+		"Restarts:   1/3 (2 retries remaining)",
+		"Admission:  Admitted (ClusterQueue: default-queue)",
+		"main-job",
+		"train-main-job-0-0-a",
+		"node-a",
+		"Running (Loading)",
+		"OOMKilled at 16Gi (exit 137)",
+		"GPU Errors:",
+		"GPU has fallen off the bus",
+		"Probable Causes:\n  - GPU XID 79 (GPU has fallen off the bus) on node node-a.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestPrintJobStatus_StageIn(t *testing.T) {
+	var out strings.Builder
+	err := printJobStatus(&out, orchestrator.JobStatusDetail{
+		Name:      "train",
+		Namespace: "default",
+		Status:    "StagingIn",
+		StageIn: &orchestrator.StageInStatus{State: "Running", Transfers: []orchestrator.TransferProgress{
+			{Source: "gs://weights", TotalBytes: 2048, Done: true},
+			{Source: "gs://datasets/imagenet", CopiedBytes: 3 << 40, TotalBytes: 4 << 40},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("printJobStatus() error = %v", err)
+	}
+	for _, want := range []string{
+		"Status:     StagingIn",
+		"Stage-in:   Running",
+		"gs://weights: done (2.0 KiB)",
+		"gs://datasets/imagenet: 3.0 TiB / 4.0 TiB (75%)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Replicated Jobs") {
+		t.Errorf("expected no replicated jobs before the workload is submitted, got:\n%s", out.String())
+	}
+}
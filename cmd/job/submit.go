@@ -15,35 +15,83 @@
 package job
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"hpc-toolkit/pkg/config"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"slices"
+	"strconv"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
+	"hpc-toolkit/pkg/imagebuilder"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/manifestsign"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
 
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var (
 	imageName      string
 	baseImage      string
+	clusterProject string
+	buildProject   string
+	imageRepo      string
+	buildBackend   string
+	builderZone    string
 	buildContext   string
+	workDir        string
+	imageLabels    []string
+	imageTag       string
+	imageTagStrat  string
+	dockerfile     string
+	requirements   string
+	captureEnv     string
 	commandToRun   string
+	commandJSON    string
+	snippet        string
+	snippetLang    string
+	commandScript  string
 	computeType    string
+	gpuMemory      string
 	dryRunManifest string
+	dryRun         bool
+
+	registryAuth           string
+	registryCreds          string
+	registryNonInteractive bool
+
+	baseImageMaxAgeDays int
+	baseImagePolicy     string
 
 	workloadName     string
+	workloadKind     string
 	kueueQueueName   string
+	noQueue          bool
+	submitJSON       bool
+	submitOutput     string
 	numNodes         int
 	numSlices        int
+	cpuLimit         string
+	memoryLimit      string
+	cpuRequest       string
+	memoryRequest    string
+	gpusPerPod       int
 	restarts         int
 	ttlAfterFinished string
 	gracePeriodStr   string
@@ -53,10 +101,24 @@ var (
 	placementPolicy string
 	nodeConstraint  map[string]string
 
+	nodeSelectors     []string
+	nodeAffinityExprs []string
+	tolerations       []string
+	spot              bool
+	dnsHostnames      bool
+	framework         string
+	presetsFile       string
+
 	cpuAffinityStr     string
 	restartOnExitCodes []int
+	restartPolicy      string
+	podBackoffLimit    int
 	imagePullSecrets   string
+	mintPullSecret     bool
+	pullSecretAccount  string
+	refreshPullSecret  bool
 	serviceAccountName string
+	signKey            string
 	topology           string
 	gkeScheduler       string
 	platform           string
@@ -66,14 +128,34 @@ var (
 	priorityClassName  string
 	isPathwaysJob      bool
 	verbose            bool
+	verbosity          int
+	buildLogFile       string
 
-	volumeStr []string
-	pathways  orchestrator.PathwaysJobDefinition
+	volumeStr         []string
+	gcsBucketStr      []string
+	secretMountStr    []string
+	configMapMountStr []string
+	nfsServer         string
+	nfsPath           string
+	nfsMountPath      string
+	startupProbeStr   string
+	startupTimeoutStr string
+	stepTimeoutStrs   [3]string // --credentials-timeout, --build-timeout, --apply-timeout
+	stageInStr        []string
+	inputStr          []string
+	retentionStr      []string
+	archiveLogs       string
+	sidecarStr        []string
+	pathways          orchestrator.PathwaysJobDefinition
 
 	gkeNapProvisioning string
 	gkeNapReservation  string
 
+	backend string
+
 	envVars           []string
+	secretEnvStr      []string
+	secretEnvMode     string
 	pathwaysProxyEnv  []string
 	pathwaysServerEnv []string
 	pathwaysWorkerEnv []string
@@ -88,14 +170,36 @@ on a GKE cluster, integrated with Kueue. Image can be pre-built (--image)
 or built on-the-fly using Crane (--base-image with --build-context).
 
 It accepts parameters for the container image, command to execute, accelerator type,
-and JobSet/Kueue specific configurations like workload name, queue, nodes, and restarts.`,
+and JobSet/Kueue specific configurations like workload name, queue, nodes, and restarts.
+
+With --backend vertex or --backend batch, the workload runs as a Vertex AI
+custom training job or a Cloud Batch job instead, without a GKE cluster.`,
 	RunE: runSubmitCmd,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if len(workloadName) > 28 {
+		// Checked here rather than with MarkFlagRequired, so that a --file job
+		// spec can set them.
+		if !cmd.Flags().Changed("name") && configuredNameTemplate() == "" {
+			return fmt.Errorf("required flag(s) %s not set", strconv.Quote("name"))
+		}
+		if len(workloadName) > maxWorkloadNameLength {
 			return fmt.Errorf("workload name cannot exceed 28 characters due to Kubernetes/GCE resource name limits. The provided name %q has %d characters", workloadName, len(workloadName))
 		}
 
-		if !pathways.Headless && commandToRun == "" {
+		if commandToRun != "" && commandJSON != "" {
+			return fmt.Errorf("--command and --command-json cannot be used together")
+		}
+		// Kubernetes stores the command as a UTF-8 string; other bytes
+		// would be replaced rather than passed to the container.
+		if !utf8.ValidString(commandToRun) || !utf8.ValidString(commandJSON) {
+			return fmt.Errorf("--command and --command-json must be valid UTF-8")
+		}
+		if err := validateSnippetFlags(); err != nil {
+			return err
+		}
+		if err := validateCommandScriptFlags(); err != nil {
+			return err
+		}
+		if !pathways.Headless && commandToRun == "" && commandJSON == "" && snippet == "" && commandScript == "" {
 			return fmt.Errorf("required flag \"command\" not set")
 		}
 
@@ -107,22 +211,78 @@ and JobSet/Kueue specific configurations like workload name, queue, nodes, and r
 			return err
 		}
 
-		if err := ensurePrerequisites(cmd, &projectID, location); err != nil {
+		if err := validateWorkloadKindFlags(); err != nil {
+			return err
+		}
+
+		if err := validateRestartFlags(); err != nil {
+			return err
+		}
+
+		if len(sidecarStr) > 0 && isPathwaysJob {
+			return fmt.Errorf("--sidecar cannot be used with --pathways")
+		}
+		if archiveLogs != "" && isPathwaysJob {
+			return fmt.Errorf("--archive-logs cannot be used with --pathways")
+		}
+		if len(stageInStr) > 0 && isPathwaysJob {
+			return fmt.Errorf("--stage-in cannot be used with --pathways")
+		}
+		if gpuMemory != "" && isPathwaysJob {
+			return fmt.Errorf("--gpu-memory cannot be used with --pathways")
+		}
+		if dnsHostnames && (isPathwaysJob || (workloadKind != orchestrator.WorkloadKindJobSet && workloadKind != orchestrator.WorkloadKindMPI)) {
+			return fmt.Errorf("--dns-hostnames requires --workload-kind jobset; Pathways and MPI workloads always have DNS hostnames")
+		}
+		if framework != "" {
+			if !slices.Contains(orchestrator.Frameworks, framework) {
+				return fmt.Errorf("invalid --framework %q: must be one of %s", framework, strings.Join(orchestrator.Frameworks, ", "))
+			}
+			if isPathwaysJob || workloadKind != orchestrator.WorkloadKindJobSet {
+				return fmt.Errorf("--framework requires --workload-kind jobset and cannot be used with --pathways")
+			}
+		}
+		if startupProbeStr != "" && (isPathwaysJob || workloadKind == orchestrator.WorkloadKindRayJob || workloadKind == orchestrator.WorkloadKindMPI) {
+			return fmt.Errorf("--startup-probe cannot be used with --pathways or --workload-kind rayjob or mpi")
+		}
+
+		if err := ensurePrerequisites(cmd, &projectID, registryProject(), location); err != nil {
 			return err
 		}
 
 		if err := validateGKENAPFlags(); err != nil {
 			return err
 		}
+		if spot {
+			if gkeNapProvisioning != "" && gkeNapProvisioning != "spot" {
+				return fmt.Errorf("--spot cannot be used with --gke-nap-provisioning=%s", gkeNapProvisioning)
+			}
+			if !cmd.Flags().Changed("restarts") {
+				restarts = spotDefaultRestarts
+			}
+		}
 
 		for _, envs := range [][]string{envVars, pathwaysProxyEnv, pathwaysServerEnv, pathwaysWorkerEnv} {
 			if err := validateEnvFlags(envs); err != nil {
 				return err
 			}
 		}
+		if err := validateSecretEnvFlags(); err != nil {
+			return err
+		}
 
 		priorityClassName = strings.ToLower(priorityClassName)
 
+		// Rendered last, so that a submission rejected by the checks above
+		// does not use up a run number.
+		if !cmd.Flags().Changed("name") {
+			name, err := workloadNameFromTemplate(configuredNameTemplate(), !dryRun && dryRunManifest == "")
+			if err != nil {
+				return err
+			}
+			workloadName = name
+		}
+
 		return nil
 	},
 	SilenceUsage: true,
@@ -131,36 +291,104 @@ and JobSet/Kueue specific configurations like workload name, queue, nodes, and r
 func init() {
 	SubmitCmd.Flags().StringVarP(&imageName, "image", "i", "", "Name of the pre-built container image to run. Must include the full path including registry (e.g., us-docker.pkg.dev/my-project/my-repo/my-image:tag).")
 	SubmitCmd.Flags().StringVarP(&baseImage, "base-image", "B", "", "Name of the base image for Crane to build upon (e.g., python:3.9-slim). Requires --build-context.")
-	SubmitCmd.Flags().StringVarP(&buildContext, "build-context", "b", "", "Path to the build context directory for Crane (e.g., .). Required with --base-image.")
-	SubmitCmd.Flags().StringVarP(&commandToRun, "command", "e", "", "Command to execute in the container (e.g., 'python train.py'). Required.")
-	SubmitCmd.Flags().StringVar(&computeType, "compute-type", "", "Type of compute to request (e.g., 'n2-standard-32', 'nvidia-l4', 'v6e-8').")
+	SubmitCmd.Flags().StringVar(&imageRepo, "image-repo", "", "Artifact Registry repository to push images built with --base-image to (e.g., us-central1-docker.pkg.dev/my-project/my-repo). Created if it does not exist. Defaults to GCLUSTER_IMAGE_REPO in the build project and the cluster's region.")
+	SubmitCmd.Flags().StringVar(&clusterProject, "cluster-project", "", "Project of the GKE cluster the workload runs on. Same as --project.")
+	SubmitCmd.Flags().StringVar(&buildProject, "build-project", "", "Project to build and push images in when it differs from the cluster's project. Defaults to the cluster's project.")
+	SubmitCmd.Flags().StringVar(&buildBackend, "build-backend", "", fmt.Sprintf("Where to build the image (one of %s). A --base-image is built by 'crane' (default) on the local machine or by 'remote' on a short-lived Compute Engine VM near the registry, which only receives the files of the --build-context that changed, for slow uplinks. A --dockerfile is built by 'cloudbuild' (default) with Cloud Build or by 'docker' with the local Docker daemon.", strings.Join(orchestrator.BuildBackends, ", ")))
+	SubmitCmd.Flags().StringVar(&registryAuth, "registry-auth", imagebuilder.RegistryAuthDefault, fmt.Sprintf("How gcluster authenticates to Artifact Registry to push and inspect images. One of: %s. basic reads %s and %s, access-token reads %s, json-key reads the service account key of --registry-credentials, and workload-identity exchanges the external credential configuration of --registry-credentials or GOOGLE_APPLICATION_CREDENTIALS with Workload Identity Federation.", strings.Join(imagebuilder.RegistryAuthModes, ", "), imagebuilder.RegistryUsernameEnv, imagebuilder.RegistryPasswordEnv, imagebuilder.RegistryTokenEnv))
+	SubmitCmd.Flags().StringVar(&registryCreds, "registry-credentials", "", "Credentials file of --registry-auth json-key or workload-identity.")
+	SubmitCmd.Flags().BoolVar(&registryNonInteractive, "registry-non-interactive", false, "Never run gcloud or Docker credential helpers for registry access, which may prompt. The default --registry-auth then uses Application Default Credentials, and other registries are accessed anonymously. Suitable for CI.")
+	SubmitCmd.Flags().StringVar(&builderZone, "builder-zone", "", "Zone of the builder VM of --build-backend remote. Defaults to a zone in the region of the --image-repo.")
+	SubmitCmd.Flags().IntVar(&baseImageMaxAgeDays, "base-image-max-age", 0, "Maximum age in days of the --base-image, read from its creation time. Older base images are reported according to --base-image-policy. 0 disables the check.")
+	SubmitCmd.Flags().StringVar(&baseImagePolicy, "base-image-policy", orchestrator.BaseImagePolicyWarn, fmt.Sprintf("What to do when the --base-image is older than --base-image-max-age or its tag moved to a new digest since the last build (one of %s).", strings.Join(orchestrator.BaseImagePolicies, ", ")))
+	SubmitCmd.Flags().StringVarP(&buildContext, "build-context", "b", "", "Path to the build context directory for Crane (e.g., .). If omitted with --base-image, it is inferred from the script referenced in --command.")
+	SubmitCmd.Flags().StringVar(&workDir, "workdir", "", "Absolute directory of the image that the --build-context of a --base-image is copied to and the workload runs in (e.g., /app), so relative paths in --command resolve against it. Defaults to the image root and the working directory of the --base-image.")
+	SubmitCmd.Flags().StringArrayVar(&imageLabels, "image-label", nil, "Label to set on the image built from a --base-image in KEY=VALUE format, besides the labels that record its build time, git commit and gcluster version. Can be specified multiple times.")
+	SubmitCmd.Flags().StringVar(&imageTag, "image-tag", "", "Tag of the image built from a --base-image or --dockerfile (e.g., v1.2), which sets --image-tag-strategy user. A --base-image build also keeps the tag of the build cache. Tags starting with 'ctx-' are reserved for the cache.")
+	SubmitCmd.Flags().StringVar(&imageTagStrat, "image-tag-strategy", "", fmt.Sprintf("How the built image is tagged (one of %s). content-hash (default) tags it with a hash of its build inputs, so unchanged inputs reuse the image, git-sha with the commit checked out in the --build-context, and user with --image-tag. The workload runs the image pinned to its digest.", strings.Join(imagebuilder.TagStrategies, ", ")))
+	SubmitCmd.Flags().StringVar(&dockerfile, "dockerfile", "", "Path to a Dockerfile to build with Cloud Build, or the local Docker daemon with --build-backend docker, instead of using --image or --base-image. The --build-context, which must contain it, defaults to the Dockerfile's directory.")
+	SubmitCmd.Flags().StringVar(&requirements, "requirements", "", "Path to a pip requirements.txt or conda environment.yml. Dependencies are installed into a cached image layer (built with Cloud Build) under the --build-context layer. Requires --base-image.")
+	SubmitCmd.Flags().StringVar(&captureEnv, "capture-env", "", "Local Python environment to reproduce in the image, as 'conda:<name>' or 'venv:<path>'. It is exported with 'conda env export' or 'pip freeze' and installed like --requirements. Requires --base-image.")
+	SubmitCmd.Flags().StringVarP(&commandToRun, "command", "e", "", "Command to execute in the container (e.g., 'python train.py'). Multi-line values run line by line and stop at the first failure. Required unless --command-json, --snippet or --command-script is set.")
+	SubmitCmd.Flags().StringVar(&costTeam, "team", "", "Team label for GKE cost allocation. Defaults to 'gcluster job config set team'.")
+	SubmitCmd.Flags().StringVar(&costExperiment, "experiment", "", "Experiment label for GKE cost allocation. Defaults to 'gcluster job config set experiment'.")
+	SubmitCmd.Flags().StringVar(&commandJSON, "command-json", "", `Exec-form command as a JSON array, run without a shell (e.g., '["python","train.py","--epochs","10"]').`)
+	SubmitCmd.Flags().StringVar(&snippet, "snippet", "", "Short inline script to run instead of a --command (e.g., 'print(42)'). It is mounted into the container from a ConfigMap and the --image, or the --base-image as is, runs it without an image build.")
+	SubmitCmd.Flags().StringVar(&commandScript, "command-script", "", "Path to a local script to run instead of a --command, for launch logic that does not fit on one line. It is mounted into the container from a ConfigMap and run by the interpreter of its #! line, or bash.")
+	SubmitCmd.Flags().StringVar(&snippetLang, "snippet-lang", orchestrator.SnippetLanguagePython, fmt.Sprintf("Language of the --snippet (one of %s).", strings.Join(orchestrator.SnippetLanguages, ", ")))
+	SubmitCmd.Flags().StringVar(&computeType, "compute-type", "", "Type of compute to request (e.g., 'n2-standard-32', 'nvidia-l4', 'v6e-8'). A comma-separated list is tried in order, and the first type the cluster has capacity for is used. If empty, it is auto-discovered from the cluster's node pools.")
+	SubmitCmd.Flags().StringVar(&gpuMemory, "gpu-memory", "", "Minimum memory of each GPU (e.g., '80GB'), instead of a --compute-type. The cheapest GPU machine type of the cluster's node pools with enough GPU memory is used.")
 	SubmitCmd.Flags().StringVarP(&dryRunManifest, "dry-run-out", "o", "", "Path to output the generated Kubernetes manifest instead of applying it.")
-	SubmitCmd.Flags().StringVarP(&platform, "platform", "f", "linux/amd64", "Target platform for the image build (e.g., 'linux/amd64', 'linux/arm64'). Used with --base-image.")
+	SubmitCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Resolve the workload without fetching cluster credentials, pushing images or applying manifests, and print the resolved project, the image that would be built and the manifest.")
+	SubmitCmd.Flags().BoolVar(&submitJSON, "json", false, "Same as --output-format json.")
+	SubmitCmd.Flags().StringVar(&submitOutput, "output-format", "text", "Format of the submission summary: text, json or yaml. With json and yaml, the summary is the only output on stdout and the progress messages go to stderr. With --dry-run-out, the summary names the written manifest.")
+	SubmitCmd.Flags().StringVarP(&platform, "platform", "f", "linux/amd64", "Target platform for the image build (e.g., 'linux/amd64', 'linux/arm64'). Used with --base-image. A comma-separated list (e.g., 'linux/amd64,linux/arm64') builds a multi-platform image that runs on node pools of either architecture.")
 
 	SubmitCmd.Flags().StringSliceVar(&volumeStr, "mount", nil, "Volumes to mount (format: <src>:<dest>[:<mode>], mode can be 'ro' or 'rw', default 'ro').")
+	SubmitCmd.Flags().StringArrayVar(&gcsBucketStr, "gcs-bucket", nil, "Mount a Cloud Storage bucket with the GCS FUSE CSI driver (format: <bucket>:<dest>[:<mode>], mode can be 'ro' or 'rw', default 'ro'). Same as --mount gs://<bucket>:<dest>[:<mode>]. Can be specified multiple times.")
+	SubmitCmd.Flags().StringArrayVar(&secretMountStr, "mount-secret", nil, "Mount an existing Kubernetes Secret read-only, one file per key (format: <name>:<dest>). Can be specified multiple times.")
+	SubmitCmd.Flags().StringArrayVar(&configMapMountStr, "mount-configmap", nil, "Mount an existing Kubernetes ConfigMap read-only, one file per key (format: <name>:<dest>). Can be specified multiple times.")
+	SubmitCmd.Flags().StringVar(&nfsServer, "nfs-server", "", "Host name or IP address of an NFS server, such as a Filestore instance, whose export all pods of the workload mount read-write. Requires --nfs-path.")
+	SubmitCmd.Flags().StringVar(&nfsPath, "nfs-path", "", "Exported path on the --nfs-server to mount, for example /share1.")
+	SubmitCmd.Flags().StringVar(&nfsMountPath, "nfs-mount-path", "/mnt/nfs", "Path in the containers at which the --nfs-server export is mounted.")
+	SubmitCmd.Flags().StringArrayVar(&inputStr, "input", nil, "Dataset the workload reads, as NAME=gs://<bucket>/<object>[,generation=<n>][,md5=<hash>] or NAME=gs://<bucket>/<prefix>/. It is checked to exist, and match its pinned generation and MD5 hash, before the workload is submitted, passed to the workload in the environment variable NAME, and recorded on the workload. Can be specified multiple times.")
+	SubmitCmd.Flags().StringArrayVar(&stageInStr, "stage-in", nil, "Copy a Cloud Storage dataset onto a filestore:// or PVC --mount before the workload is submitted (format: gs://<bucket>[/<prefix>]:<dest>). The copy runs in a Job on the cluster and submit waits for it. Can be specified multiple times.")
+	SubmitCmd.Flags().StringArrayVar(&retentionStr, "retention", nil, "Retention of the outputs or checkpoints the workload writes under a gs:// prefix or a directory on a filestore:// or PVC --mount, as <path>[,keep-last=<n>][,expire-after=<age>], e.g. gs://my-bucket/checkpoints,keep-last=3,expire-after=30d. Each file or directory directly under the path is one entry; entries that are not among the keep-last newest and are older than expire-after are deleted by 'gcluster fleet gc --outputs'. Can be specified multiple times.")
+	SubmitCmd.Flags().StringVar(&archiveLogs, "archive-logs", "", "Archive container logs and termination messages before --gke-ttl-after-finished deletes the pods: gs://<bucket>[/<prefix>] uploads them from a sidecar, logging://[<location>/]<log-bucket> routes them to a Cloud Logging log bucket with a log sink.")
+	SubmitCmd.Flags().StringArrayVar(&sidecarStr, "sidecar", nil, "Auxiliary container to run in every pod alongside the workload, e.g. TensorBoard or a metrics exporter, as image=<image>[,name=<name>][,command=<command>]. command is run with /bin/sh -c and, as the last option, may contain commas. Sidecars get the workload's environment and volume mounts, are restarted when they exit, and are stopped when the workload finishes. Can be specified multiple times.")
 	SubmitCmd.Flags().StringArrayVar(&envVars, "env", []string{}, "Custom environment variables to pass to the workload container in KEY=VALUE format. Can be specified multiple times.")
+	SubmitCmd.Flags().StringArrayVar(&secretEnvStr, "secret-env", nil, "Set an environment variable of the workload container from a Secret Manager secret, as NAME=sm://projects/<project>/secrets/<secret>[/versions/<version>], or NAME=sm://<secret> for a secret in the cluster project. The version defaults to latest. The value is never passed on the command line. Can be specified multiple times.")
+	SubmitCmd.Flags().StringVar(&secretEnvMode, "secret-env-mode", orchestrator.SecretEnvModeSecret, fmt.Sprintf("How --secret-env secrets reach the workload (one of %s). 'secret' reads them at submission with your credentials into a Kubernetes Secret of the workload. 'csi' mounts them with the Secret Manager add-on of the cluster, which reads them as the Kubernetes service account of the pods, so gcluster never sees the values.", strings.Join(orchestrator.SecretEnvModes, ", ")))
 
-	SubmitCmd.Flags().StringVarP(&workloadName, "name", "n", "", "Name of the workload to create. Required.")
+	SubmitCmd.Flags().StringVarP(&workloadName, "name", "n", "", "Name of the workload to create. Required unless a name template is set.")
+	SubmitCmd.Flags().StringVar(&nameTemplate, "name-template", "", "Go template the workload name is rendered from when --name is not set, e.g. '{{.User}}-{{.Experiment}}-r{{.Seq}}'. {{.Seq}} is the run number of the experiment, incremented on every submission. Defaults to 'gcluster job config set name-template'.")
+	SubmitCmd.Flags().StringVar(&workloadKind, "workload-kind", orchestrator.WorkloadKindJobSet, fmt.Sprintf("Kind of workload manifest to generate (one of %s). job submits a single-node batch Job that does not need the JobSet CRD; mpi submits a JobSet of an mpirun launcher and --num-nodes SSH workers per slice; deployment and rayjob can only be written with --dry-run-out or --dry-run.", strings.Join(orchestrator.WorkloadKinds, ", ")))
 	SubmitCmd.Flags().StringVarP(&kueueQueueName, "queue", "q", "", "Name of the Kueue LocalQueue to submit the workload to. If empty, it will be auto-discovered.")
+	SubmitCmd.Flags().BoolVar(&noQueue, "no-queue", false, "Submit without a Kueue queue, for clusters without Kueue. The workload is not admitted as a gang; its pods are scheduled as soon as nodes are available, by --priority.")
 	SubmitCmd.Flags().IntVar(&numNodes, "num-nodes", 1, "The number of nodes to use per group/slice. Defaults to 1 for CPU/GPU, or auto-calculated for TPUs.")
 	SubmitCmd.Flags().IntVar(&numSlices, "num-slices", 1, "The number of independent groups/slices to use.")
+	SubmitCmd.Flags().StringVar(&cpuLimit, "cpu", "", "CPU limit of each workload container as a Kubernetes quantity (e.g., '8', '500m'). Overrides the limit derived from --compute-type.")
+	SubmitCmd.Flags().StringVar(&memoryLimit, "memory", "", "Memory limit of each workload container as a Kubernetes quantity (e.g., '64Gi'). Overrides the limit derived from --compute-type.")
+	SubmitCmd.Flags().StringVar(&cpuRequest, "cpu-request", "", "CPU each workload container requests, when less than its limit for a burstable pod (e.g., '2'). Defaults to the limit.")
+	SubmitCmd.Flags().StringVar(&memoryRequest, "memory-request", "", "Memory each workload container requests, when less than its limit for a burstable pod (e.g., '16Gi'). Defaults to the limit.")
+	SubmitCmd.Flags().IntVar(&gpusPerPod, "gpus-per-pod", 0, "Number of GPUs of each workload container, at most the GPUs of a --compute-type node. Defaults to all of them.")
 	SubmitCmd.Flags().IntVar(&restarts, "restarts", 1, "Maximum number of restarts for the JobSet before failing.")
 	SubmitCmd.Flags().StringVar(&ttlAfterFinished, "gke-ttl-after-finished", "1h", "Time to retain the JobSet after it finishes (e.g. 5m, 1h).")
 	SubmitCmd.Flags().StringVar(&gracePeriodStr, "grace-period", "30s", "Time to wait before forcefully terminating a pod (e.g. 30s, 2m). Gives the workload time to save checkpoints or clean up distributed state during cancellation or preemption events (like Spot VM evictions).")
 	SubmitCmd.Flags().BoolVar(&gkeDisableParallelContainers, "gke-disable-parallel-containers", false, "Disable parallel containers for TPU7x on GKE.")
 
-	SubmitCmd.Flags().StringVar(&placementPolicy, "placement-policy", "", "Name of the GKE placement policy to use.")
+	SubmitCmd.Flags().StringVar(&placementPolicy, "placement-policy", "", "Name of the GKE placement group to schedule on, or 'compact' to place the pods on physically adjacent hosts: the node pools of the machine type with compact placement, or ones Node Auto-Provisioning creates.")
 	SubmitCmd.Flags().StringToStringVar(&nodeConstraint, "node-constraint", nil, "Key=value pairs for node labels to target specific nodes. Maps to nodeSelector in GKE, and to SLURM's --constraint.")
+	SubmitCmd.Flags().StringArrayVar(&nodeSelectors, "node-selector", nil, "Node label to require in key=value format (e.g., team-pool=research). Rendered into the pod nodeSelector. Can be specified multiple times.")
+	SubmitCmd.Flags().BoolVar(&spot, "spot", false, fmt.Sprintf("Schedule on Spot VM node pools: selects and tolerates the cloud.google.com/gke-spot=true nodes, and raises the default of --restarts to %d since Spot VMs can be preempted at any time.", spotDefaultRestarts))
+	SubmitCmd.Flags().StringArrayVar(&tolerations, "toleration", nil, "Node taint to tolerate in key[=value][:effect] format, where effect is NoSchedule, PreferNoSchedule or NoExecute (e.g., dedicated=ml:NoSchedule). Without a value any value is tolerated, and without an effect all effects are. Can be specified multiple times.")
+	SubmitCmd.Flags().StringArrayVar(&nodeAffinityExprs, "node-affinity-expr", nil, "Required node affinity expression in '<key> <operator> [values]' format, where operator is In, NotIn, Exists, DoesNotExist, Gt or Lt and values are comma-separated (e.g., 'capacity-tier In spot,standard'). Can be specified multiple times.")
 	SubmitCmd.Flags().StringVar(&cpuAffinityStr, "cpu-affinity", "", "CPU affinity rules (e.g., 'numa').")
 	SubmitCmd.Flags().IntSliceVar(&restartOnExitCodes, "restart-on-exit-codes", nil, "List of exit codes that should not trigger a job failure.")
+	SubmitCmd.Flags().StringVar(&restartPolicy, "restart-policy", orchestrator.RestartPolicyNever, "Container restart policy of the pods: Never restarts failed pods as a whole, OnFailure restarts failed containers in place. Container restarts count against --pod-backoff-limit.")
+	SubmitCmd.Flags().IntVar(&podBackoffLimit, "pod-backoff-limit", 0, "Pod failures, or container restarts with --restart-policy OnFailure, each Job of the JobSet tolerates before it fails and the JobSet is restarted as a whole (see --restarts).")
+	SubmitCmd.Flags().BoolVar(&dnsHostnames, "dns-hostnames", false, "Give the pods stable DNS hostnames, <name>-main-job-<slice>-<index>.<name>, through a headless Service of the workload, so that they can address each other for distributed training.")
+	SubmitCmd.Flags().StringVar(&framework, "framework", "", fmt.Sprintf("Inject the environment variables a distributed training framework uses to find its peers and rank (%s). Implies --dns-hostnames.", strings.Join(orchestrator.Frameworks, ", ")))
+	SubmitCmd.Flags().StringVar(&presetsFile, "presets-file", "", "An accelerator presets YAML file extending the built-in presets, e.g. with the accelerators and default CPU and memory limits of new machine types.")
 	SubmitCmd.Flags().StringVar(&imagePullSecrets, "image-pull-secret", "", "Comma-separated list of secrets for pulling images.")
+	SubmitCmd.Flags().BoolVar(&mintPullSecret, "mint-pull-secret", false, "Pull the image with a Kubernetes Secret holding an access token of --pull-secret-sa, for registries in other projects that the node service account cannot read. The token is valid for about an hour.")
+	SubmitCmd.Flags().StringVar(&pullSecretAccount, "pull-secret-sa", "", "Google service account with read access to the registry (roles/artifactregistry.reader) that the --mint-pull-secret token is minted as. Required by --mint-pull-secret.")
+	SubmitCmd.Flags().BoolVar(&refreshPullSecret, "refresh-pull-secret", false, "Run a CronJob that refreshes the --mint-pull-secret token as --pull-secret-sa, through Workload Identity, until the workload is deleted. For runs that start pods after the first hour.")
 	SubmitCmd.Flags().StringVar(&serviceAccountName, "service-account", "", "Service account name for the pods.")
-	SubmitCmd.Flags().StringVar(&topology, "topology", "", "TPU slice topology (e.g., 2x2x1).")
+	SubmitCmd.Flags().StringVar(&signKey, "sign-key", "", "Cloud KMS key version to sign the rendered manifest with (projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>/cryptoKeyVersions/<version>). Each object gets a gcluster.google.com/signature annotation.")
+	SubmitCmd.Flags().StringVar(&topology, "topology", "", "TPU slice topology (e.g., 2x2x1). Alias: --tpu-topology.")
 	SubmitCmd.Flags().StringVar(&gkeScheduler, "gke-scheduler", "", "Kubernetes Scheduler name (e.g., gke.io/topology-aware-auto).")
 	SubmitCmd.Flags().BoolVar(&awaitJobCompletion, "await-job-completion", false, "If true, gcluster will wait for the submitted job to complete.")
 	SubmitCmd.Flags().StringVar(&timeoutStr, "timeout", "-1s", "Time to wait for job in seconds or string format (e.g. 1h, 10m). Default is max timeout (-1s).")
+	SubmitCmd.Flags().StringVar(&startupProbeStr, "startup-probe", "", "Check that the workload has started, such as loaded its model: 'http:<port>[/<path>]', 'tcp:<port>' or 'exec:<command>'. Pods are not ready, and their containers are not restarted, until it succeeds.")
+	SubmitCmd.Flags().StringVar(&startupTimeoutStr, "startup-timeout", "", "How long the --startup-probe may take to succeed before the containers are restarted (e.g., '20m'). Defaults to 10m.")
+	SubmitCmd.Flags().StringVar(&stepTimeoutStrs[0], "credentials-timeout", "", "How long fetching the cluster credentials may take (e.g., '2m'). Unlimited by default.")
+	SubmitCmd.Flags().StringVar(&stepTimeoutStrs[1], "build-timeout", "", "How long building and pushing the container image may take (e.g., '30m'). Unlimited by default.")
+	SubmitCmd.Flags().StringVar(&stepTimeoutStrs[2], "apply-timeout", "", "How long applying the workload manifests may take (e.g., '5m'). If it times out after an image was built, resubmit with --image to reuse the image. Unlimited by default.")
 	SubmitCmd.Flags().StringVar(&priorityClassName, "priority", "", "A priority class name (e.g., low, medium, high, or any custom PriorityClass defined in the cluster). If empty, the cluster's default priority class will be used.")
 	SubmitCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging for the workload (TPUs and GPUs).")
+	SubmitCmd.Flags().CountVarP(&verbosity, "verbosity", "v", "Print the details of the image build: -v for the build steps and registry progress, -vv also for every registry request.")
+	SubmitCmd.Flags().StringVar(&buildLogFile, "build-log-file", "", "Write the full log of the submit, including the image build details, to this file regardless of -v. Its path is included in the summary.")
 	SubmitCmd.Flags().StringVar(&gkeNapProvisioning, "gke-nap-provisioning", "", "Compute provisioning model for GKE NAP. Allowed values: on-demand, spot, reservation.")
 	SubmitCmd.Flags().StringVar(&gkeNapReservation, "gke-nap-reservation", "", "Name of the Google Cloud Reservation for GKE NAP (required if --gke-nap-provisioning=reservation).")
 
@@ -182,9 +410,17 @@ func init() {
 	SubmitCmd.Flags().StringVar(&pathways.HeadNodePool, "pathways-head-np", "", "The node pool to use for the Pathways head job. If empty, it will be auto-detected (looking for 'cpu-np' or 'pathways-np').")
 	SubmitCmd.Flags().BoolVar(&pathways.MTCEnabled, "pathways-mtc-enabled", false, "Enable Multi-Tier Checkpointing (MTC) for Pathways.")
 	SubmitCmd.Flags().StringVar(&pathways.RamdiskDirectory, "pathways-ramdisk-directory", "", "The ramdisk directory path for local checkpoints in MTC.")
-
-	_ = SubmitCmd.MarkFlagRequired("name")
-	_ = SubmitCmd.MarkFlagRequired("compute-type")
+	SubmitCmd.Flags().StringVar(&backend, "backend", backendGKE, "Where to run the workload: gke, on the --cluster; vertex, as a Vertex AI custom training job; or batch, as a Cloud Batch job. The vertex and batch backends run in the region of --location and need no cluster.")
+	SubmitCmd.Flags().StringVar(&jobSpecFile, "file", "", "YAML job spec to submit. Flags given on the command line override its fields.")
+	SubmitCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		switch name {
+		case "tpu-topology":
+			name = "topology"
+		case "base-docker-image":
+			name = "base-image"
+		}
+		return pflag.NormalizedName(name)
+	})
 }
 
 func runSubmitCmd(cmd *cobra.Command, args []string) error {
@@ -213,12 +449,122 @@ func runSubmitCmd(cmd *cobra.Command, args []string) error {
 		awaitJobCompletion = true
 	}
 
-	if config.IsTPU(computeType) && cmd.Flags().Changed("num-nodes") {
+	if err := validateSubmitOutput(); err != nil {
+		return err
+	}
+	if noQueue && kueueQueueName != "" {
+		return fmt.Errorf("--no-queue cannot be used with --queue")
+	}
+	if mintPullSecret && pullSecretAccount == "" {
+		return fmt.Errorf("--mint-pull-secret requires --pull-secret-sa, a service account with read access to the registry; your own credentials are not stored in the cluster")
+	}
+	if (pullSecretAccount != "" || refreshPullSecret) && !mintPullSecret {
+		return fmt.Errorf("--pull-secret-sa and --refresh-pull-secret require --mint-pull-secret")
+	}
+
+	jobComputeType, computeTypeFallbacks, err := parseComputeTypes(computeType)
+	if err != nil {
+		return err
+	}
+	jobMinGPUMemory, err := parseGPUMemory(gpuMemory, jobComputeType)
+	if err != nil {
+		return err
+	}
+	if config.IsTPU(jobComputeType) && cmd.Flags().Changed("num-nodes") {
 		return fmt.Errorf("--num-nodes cannot be used with TPU jobs (it is calculated automatically from topology)")
 	}
 
 	jobTopology := strings.ToLower(strings.TrimSpace(topology))
 
+	jobNodeConstraint, err := parseNodeSelectors(nodeConstraint, nodeSelectors)
+	if err != nil {
+		return err
+	}
+	jobTolerations, err := parseTolerations(tolerations)
+	if err != nil {
+		return err
+	}
+	jobNodeAffinityExprs, err := parseNodeAffinityExprs(nodeAffinityExprs)
+	if err != nil {
+		return err
+	}
+	jobCommandArgs, err := parseCommandJSON(commandJSON)
+	if err != nil {
+		return err
+	}
+	jobCostLabels, err := costAllocationLabels(costTeam, costExperiment)
+	if err != nil {
+		return err
+	}
+	jobLogArchive, err := parseLogArchive(archiveLogs)
+	if err != nil {
+		return err
+	}
+	jobStartupProbe, err := parseStartupProbe(startupProbeStr, startupTimeoutStr)
+	if err != nil {
+		return err
+	}
+	jobStepTimeouts, err := parseStepTimeouts(stepTimeoutStrs)
+	if err != nil {
+		return err
+	}
+	if signKey != "" {
+		if _, err := manifestsign.ParseKeyVersion(signKey); err != nil {
+			return fmt.Errorf("invalid --sign-key: %w", err)
+		}
+	}
+	jobCPU, err := parseResourceQuantity(cpuLimit, "--cpu")
+	if err != nil {
+		return err
+	}
+	jobMemory, err := parseResourceQuantity(memoryLimit, "--memory")
+	if err != nil {
+		return err
+	}
+	jobCPURequest, err := parseResourceRequest(cpuRequest, "--cpu-request", jobCPU, "--cpu")
+	if err != nil {
+		return err
+	}
+	jobMemoryRequest, err := parseResourceRequest(memoryRequest, "--memory-request", jobMemory, "--memory")
+	if err != nil {
+		return err
+	}
+	if gpusPerPod < 0 {
+		return fmt.Errorf("invalid --gpus-per-pod %d: must not be negative", gpusPerPod)
+	}
+	jobInputs, err := parseInputs(inputStr)
+	if err != nil {
+		return err
+	}
+	jobSidecars, err := parseSidecars(sidecarStr)
+	if err != nil {
+		return err
+	}
+	jobStageIn, err := parseStageIn(stageInStr)
+	if err != nil {
+		return err
+	}
+	jobRetention, err := parseRetentionRules(retentionStr)
+	if err != nil {
+		return err
+	}
+	gcsBucketMounts, err := parseGCSBuckets(gcsBucketStr)
+	if err != nil {
+		return err
+	}
+	sharedVolumes, err := parseNFSVolume(nfsServer, nfsPath, nfsMountPath)
+	if err != nil {
+		return err
+	}
+
+	managedProfile, err := loadManagedProfile()
+	if err != nil {
+		return err
+	}
+	if err := loadAcceleratorPresets(managedProfile, presetsFile); err != nil {
+		return err
+	}
+
 	pathways.ProxyEnv = parseEnvFlags(pathwaysProxyEnv)
 	pathways.ServerEnv = parseEnvFlags(pathwaysServerEnv)
 	pathways.WorkerEnv = parseEnvFlags(pathwaysWorkerEnv)
@@ -226,43 +572,194 @@ func runSubmitCmd(cmd *cobra.Command, args []string) error {
 	jobDef := orchestrator.JobDefinition{
 		ImageName:                     imageName,
 		BaseImage:                     baseImage,
+		BaseImageMaxAgeDays:           baseImageMaxAgeDays,
+		BaseImagePolicy:               baseImagePolicy,
+		ImageRepo:                     imageRepo,
 		BuildContext:                  buildContext,
+		WorkDir:                       workDir,
+		ImageLabels:                   parseEnvFlags(imageLabels),
+		ImageTagStrategy:              imageTagStrat,
+		ImageTag:                      imageTag,
+		Dockerfile:                    dockerfile,
+		Requirements:                  requirements,
 		Platform:                      platform,
 		CommandToRun:                  commandToRun,
-		ComputeType:                   computeType,
+		CommandArgs:                   jobCommandArgs,
+		Snippet:                       snippet,
+		SnippetLanguage:               snippetLang,
+		CommandScript:                 commandScriptContent,
+		CommandScriptName:             filepath.Base(commandScript),
+		ComputeType:                   jobComputeType,
+		MinGPUMemoryGB:                jobMinGPUMemory,
+		ComputeTypeFallbacks:          computeTypeFallbacks,
 		DryRunManifest:                dryRunManifest,
-		ProjectID:                     projectID,
+		DryRun:                        dryRun,
+		ClusterProjectID:              projectID,
+		BuildProjectID:                buildProject,
+		BuildBackend:                  buildBackend,
+		BuilderZone:                   builderZone,
 		ClusterName:                   clusterName,
 		ClusterLocation:               location,
 		WorkloadName:                  workloadName,
+		WorkloadKind:                  workloadKind,
 		KueueQueueName:                kueueQueueName,
+		NoQueue:                       noQueue,
 		NumSlices:                     numSlices,
 		NodesPerSlice:                 numNodes,
+		CPU:                           jobCPU,
+		Memory:                        jobMemory,
+		GPUsPerPod:                    gpusPerPod,
+		CPURequest:                    jobCPURequest,
+		MemoryRequest:                 jobMemoryRequest,
 		MaxRestarts:                   restarts,
+		RestartPolicy:                 restartPolicy,
+		PodBackoffLimit:               podBackoffLimit,
 		TtlSecondsAfterFinished:       ttlSeconds,
 		TerminationGracePeriodSeconds: gracePeriodSeconds,
 		PlacementPolicy:               placementPolicy,
-		NodeConstraint:                nodeConstraint,
+		NodeConstraint:                jobNodeConstraint,
 		Affinity:                      affinity,
+		NodeAffinityExprs:             jobNodeAffinityExprs,
+		Tolerations:                   jobTolerations,
+		Spot:                          spot,
+		DNSHostnames:                  dnsHostnames,
+		Framework:                     framework,
 		RestartOnExitCodes:            restartOnExitCodes,
 		ImagePullSecrets:              imagePullSecrets,
+		MintPullSecret:                mintPullSecret,
+		PullSecretAccount:             pullSecretAccount,
+		RefreshPullSecret:             refreshPullSecret,
 		ServiceAccountName:            serviceAccountName,
 		Topology:                      jobTopology,
 		GKEScheduler:                  gkeScheduler,
 		AwaitJobCompletion:            awaitJobCompletion,
 		UseParallelContainers:         !gkeDisableParallelContainers,
 		Timeout:                       timeoutStr,
+		StepTimeouts:                  jobStepTimeouts,
 		PriorityClassName:             priorityClassName,
 		GKENAPProvisioning:            gkeNapProvisioning,
 		GKENAPReservation:             gkeNapReservation,
 		IsPathwaysJob:                 isPathwaysJob,
 		Pathways:                      pathways,
-		RawMounts:                     volumeStr,
+		RawMounts:                     slices.Concat(volumeStr, gcsBucketMounts),
+		SecretMounts:                  secretMountStr,
+		SecretEnv:                     parseEnvFlags(secretEnvStr),
+		SecretEnvMode:                 secretEnvMode,
+		ConfigMapMounts:               configMapMountStr,
+		SharedVolumes:                 sharedVolumes,
+		StageIn:                       jobStageIn,
+		Inputs:                        jobInputs,
+		Retention:                     jobRetention,
+		LogArchive:                    jobLogArchive,
+		Sidecars:                      jobSidecars,
+		StartupProbe:                  jobStartupProbe,
+		SigningKey:                    signKey,
 		Env:                           parseEnvFlags(envVars),
+		CostLabels:                    jobCostLabels,
+		ManagedProfile:                managedProfile,
 		Verbose:                       verbose,
 	}
+	applyManagedProfileDefaults(managedProfile, &jobDef)
+	if err := orchestrator.ValidateJobDefinition(backend, jobDef); err != nil {
+		return err
+	}
+	if err := addInputEnv(&jobDef); err != nil {
+		return err
+	}
+	if len(jobDef.Inputs) > 0 && jobDef.IsDryRun() {
+		logging.Info("[Dry Run] Skipping the verification of %d input(s).", len(jobDef.Inputs))
+	} else if err := verifyInputs(jobDef.Inputs); err != nil {
+		return err
+	}
 
-	return orc.SubmitJob(jobDef)
+	cleanup, err := captureLocalEnv(&jobDef)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if submitOutput != "text" {
+		// Keep stdout for the summary.
+		logging.SetInfoOutput(os.Stderr)
+		defer logging.SetInfoOutput(os.Stdout)
+	}
+	restoreLogs, err := setupBuildLog()
+	if err != nil {
+		return err
+	}
+	defer restoreLogs()
+	ctx, stop := interruptContext(cmd)
+	defer stop()
+	result, err := orc.SubmitJob(ctx, jobDef)
+	if err != nil && buildLogFile != "" {
+		logging.Debug("Submit failed: %v", err)
+		return fmt.Errorf("%w\nSee the build log at %s", err, buildLogFile)
+	}
+	if err != nil {
+		return err
+	}
+	if jobDef.IsDryRun() {
+		if submitOutput == "text" {
+			return nil
+		}
+		summary := newSubmitSummary(cmd, result)
+		summary.NextCommands = nil
+		return printSubmitSummary(cmd.OutOrStdout(), summary, submitOutput)
+	}
+	if impersonate != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is allowed to submit workload %s to cluster %s. Nothing was created.\n", impersonate, result.WorkloadName, clusterName)
+		return nil
+	}
+	summary := newSubmitSummary(cmd, result)
+	if backend != backendGKE {
+		// The follow-up commands only manage GKE workloads.
+		summary.NextCommands = nil
+	}
+	return printSubmitSummary(cmd.OutOrStdout(), summary, submitOutput)
+}
+
+// validateSubmitOutput checks --output-format and folds --json into it. A
+// structured summary cannot share stdout with the manifest of --dry-run.
+func validateSubmitOutput() error {
+	flag := "--output-format " + submitOutput
+	if submitJSON {
+		if submitOutput != "text" && submitOutput != "json" {
+			return fmt.Errorf("--json cannot be used with --output-format %s", submitOutput)
+		}
+		submitOutput, flag = "json", "--json"
+	}
+	switch submitOutput {
+	case "text", "json", "yaml":
+	default:
+		return fmt.Errorf("invalid value for --output-format: %s. Allowed values are: text, json, yaml", submitOutput)
+	}
+	if submitOutput != "text" && dryRun && dryRunManifest == "" {
+		return fmt.Errorf("%s cannot be used with --dry-run, which prints the manifest on stdout; write it to a file with --dry-run-out instead", flag)
+	}
+	return nil
+}
+
+// interruptContext returns a context of cmd that is canceled by the first
+// Ctrl-C or SIGTERM, so the submission stops and cleans up. A second signal
+// terminates gcluster right away.
+func interruptContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			logging.Info("Interrupted; stopping the submission and cleaning up. Press Ctrl-C again to exit immediately.")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
 }
 
 func parseEnvFlags(envs []string) map[string]string {
@@ -279,6 +776,419 @@ func parseEnvFlags(envs []string) map[string]string {
 	return res
 }
 
+// parseNodeSelectors merges --node-selector key=value pairs into the
+// --node-constraint map, rejecting conflicting values for the same label.
+func parseNodeSelectors(base map[string]string, selectors []string) (map[string]string, error) {
+	if len(selectors) == 0 {
+		return base, nil
+	}
+	res := make(map[string]string, len(base)+len(selectors))
+	for k, v := range base {
+		res[k] = v
+	}
+	for _, sel := range selectors {
+		k, v, ok := strings.Cut(sel, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --node-selector %q. Must be in key=value format", sel)
+		}
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --node-selector label key %q: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --node-selector label value %q: %s", v, strings.Join(errs, "; "))
+		}
+		if existing, found := res[k]; found && existing != v {
+			return nil, fmt.Errorf("conflicting node selector values for label %q: %q and %q", k, existing, v)
+		}
+		res[k] = v
+	}
+	return res, nil
+}
+
+var nodeAffinityOperators = []string{"In", "NotIn", "Exists", "DoesNotExist", "Gt", "Lt"}
+
+// parseCommandJSON parses --command-json, a JSON array of strings whose first
+// element is the executable.
+func parseCommandJSON(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var args []string
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return nil, fmt.Errorf("invalid --command-json %q: must be a JSON array of strings (e.g. '[\"python\",\"train.py\"]'): %w", raw, err)
+	}
+	if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+		return nil, fmt.Errorf("invalid --command-json %q: the first element must name the executable", raw)
+	}
+	return args, nil
+}
+
+// parseLogArchive parses --archive-logs, which is either gs://<bucket>[/<prefix>]
+// or logging://[<location>/]<log-bucket>. The log bucket location defaults to global.
+func parseLogArchive(dest string) (*orchestrator.LogArchive, error) {
+	if dest == "" {
+		return nil, nil
+	}
+	if rest, ok := strings.CutPrefix(dest, "gs://"); ok {
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("invalid --archive-logs %q: missing bucket name", dest)
+		}
+		return &orchestrator.LogArchive{Kind: orchestrator.LogArchiveGCS, Bucket: bucket, Prefix: strings.Trim(prefix, "/")}, nil
+	}
+	if rest, ok := strings.CutPrefix(dest, "logging://"); ok {
+		archive := &orchestrator.LogArchive{Kind: orchestrator.LogArchiveLogging, Location: "global", Bucket: rest}
+		if location, bucket, found := strings.Cut(rest, "/"); found {
+			archive.Location, archive.Bucket = location, bucket
+		}
+		if archive.Location == "" || archive.Bucket == "" || strings.Contains(archive.Bucket, "/") {
+			return nil, fmt.Errorf("invalid --archive-logs %q: must be logging://[<location>/]<log-bucket>", dest)
+		}
+		return archive, nil
+	}
+	return nil, fmt.Errorf("invalid --archive-logs %q: must be gs://<bucket>[/<prefix>] or logging://[<location>/]<log-bucket>", dest)
+}
+
+// parseStageIn parses --stage-in values of the form gs://<bucket>[/<prefix>]:<dest>.
+func parseStageIn(values []string) ([]orchestrator.StageIn, error) {
+	var res []orchestrator.StageIn
+	for _, v := range values {
+		i := strings.LastIndex(v, ":")
+		src, dest := v[:max(i, 0)], v[i+1:]
+		bucket, _, _ := strings.Cut(strings.TrimPrefix(src, "gs://"), "/")
+		if i < 0 || !strings.HasPrefix(src, "gs://") || bucket == "" || !path.IsAbs(dest) {
+			return nil, fmt.Errorf("invalid --stage-in %q: must be gs://<bucket>[/<prefix>]:<absolute destination path>", v)
+		}
+		res = append(res, orchestrator.StageIn{Source: src, Dest: path.Clean(dest)})
+	}
+	return res, nil
+}
+
+// parseGCSBuckets turns --gcs-bucket values into the equivalent gs:// --mount
+// values.
+func parseGCSBuckets(values []string) ([]string, error) {
+	var mounts []string
+	for _, v := range values {
+		trimmed := strings.TrimPrefix(v, "gs://")
+		bucket, rest, ok := strings.Cut(trimmed, ":")
+		dest, mode, _ := strings.Cut(rest, ":")
+		if !ok || bucket == "" || strings.Contains(bucket, "/") || !path.IsAbs(dest) || (mode != "" && mode != "ro" && mode != "rw") {
+			return nil, fmt.Errorf("invalid --gcs-bucket %q: must be <bucket>:<absolute mount path>[:ro|rw]", v)
+		}
+		mounts = append(mounts, "gs://"+trimmed)
+	}
+	return mounts, nil
+}
+
+// captureLocalEnv exports the --capture-env environment into a temporary
+// requirements file that job installs, and returns the function that removes
+// it once the job is submitted.
+func captureLocalEnv(job *orchestrator.JobDefinition) (func(), error) {
+	if captureEnv == "" {
+		return func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "gcluster-env-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a directory for the captured environment: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	env, err := imagebuilder.CaptureEnvironment(captureEnv, dir)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	job.Requirements = env.Path
+	job.CapturedEnv = env.String()
+	job.CapturedEnvDigest = env.Digest
+	return cleanup, nil
+}
+
+// parseComputeTypes splits a comma-separated --compute-type preference list
+// into the preferred type and its fallbacks, in order. The types must all be
+// TPUs or all not, since a TPU job is laid out by its --topology.
+func parseComputeTypes(value string) (string, []string, error) {
+	if !strings.Contains(value, ",") {
+		return value, nil, nil
+	}
+	var types []string
+	seen := map[string]bool{}
+	for _, t := range strings.Split(value, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			return "", nil, fmt.Errorf("invalid --compute-type %q: empty compute type in the list", value)
+		}
+		if seen[t] {
+			return "", nil, fmt.Errorf("invalid --compute-type %q: %s is listed more than once", value, t)
+		}
+		if len(types) > 0 && config.IsTPU(t) != config.IsTPU(types[0]) {
+			return "", nil, fmt.Errorf("invalid --compute-type %q: TPU and non-TPU compute types cannot be mixed", value)
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types[0], types[1:], nil
+}
+
+// parseResourceQuantity validates a --cpu or --memory Kubernetes quantity
+// and returns it in canonical form.
+func parseResourceQuantity(value, flag string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q: %w", flag, value, err)
+	}
+	if q.Sign() <= 0 {
+		return "", fmt.Errorf("invalid %s %q: must be positive", flag, value)
+	}
+	return q.String(), nil
+}
+
+// parseGPUMemory parses --gpu-memory into gigabytes. It selects the compute
+// type, so it cannot be combined with --compute-type.
+func parseGPUMemory(value, computeType string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if computeType != "" {
+		return 0, fmt.Errorf("--gpu-memory cannot be used with --compute-type")
+	}
+	gb, err := config.ParseGPUMemory(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --gpu-memory: %w", err)
+	}
+	return gb, nil
+}
+
+// parseResourceRequest validates a --cpu-request or --memory-request quantity,
+// which cannot be more than the limit set with limitFlag.
+func parseResourceRequest(value, flag, limit, limitFlag string) (string, error) {
+	request, err := parseResourceQuantity(value, flag)
+	if err != nil || request == "" || limit == "" {
+		return request, err
+	}
+	if q := resource.MustParse(request); q.Cmp(resource.MustParse(limit)) > 0 {
+		return "", fmt.Errorf("%s %s is more than %s %s", flag, request, limitFlag, limit)
+	}
+	return request, nil
+}
+
+// parseStepTimeouts parses --credentials-timeout, --build-timeout and
+// --apply-timeout. An empty value leaves the step unlimited.
+func parseStepTimeouts(values [3]string) (orchestrator.StepTimeouts, error) {
+	flags := [3]string{"--credentials-timeout", "--build-timeout", "--apply-timeout"}
+	var d [3]time.Duration
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		var err error
+		if d[i], err = time.ParseDuration(v); err != nil || d[i] <= 0 {
+			return orchestrator.StepTimeouts{}, fmt.Errorf("invalid %s %q: must be a positive duration such as 10m", flags[i], v)
+		}
+	}
+	return orchestrator.StepTimeouts{Credentials: d[0], Build: d[1], Apply: d[2]}, nil
+}
+
+// defaultStartupTimeout is how long a --startup-probe may fail when
+// --startup-timeout is not set.
+const defaultStartupTimeout = 10 * time.Minute
+
+// parseStartupProbe parses --startup-probe and --startup-timeout.
+func parseStartupProbe(probe, timeout string) (*orchestrator.StartupProbe, error) {
+	if probe == "" {
+		if timeout != "" {
+			return nil, fmt.Errorf("--startup-timeout requires --startup-probe")
+		}
+		return nil, nil
+	}
+	res := &orchestrator.StartupProbe{Timeout: defaultStartupTimeout}
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid --startup-timeout %q: must be a positive duration such as 20m", timeout)
+		}
+		res.Timeout = d
+	}
+
+	kind, target, _ := strings.Cut(probe, ":")
+	invalid := fmt.Errorf("invalid --startup-probe %q: must be 'http:<port>[/<path>]', 'tcp:<port>' or 'exec:<command>'", probe)
+	switch kind {
+	case orchestrator.StartupProbeExec:
+		if strings.TrimSpace(target) == "" {
+			return nil, invalid
+		}
+		res.Command = target
+	case orchestrator.StartupProbeHTTP, orchestrator.StartupProbeTCP:
+		portStr, path, hasPath := strings.Cut(target, "/")
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < 1 || port > 65535 || (hasPath && kind == orchestrator.StartupProbeTCP) {
+			return nil, invalid
+		}
+		res.Port = port
+		if kind == orchestrator.StartupProbeHTTP {
+			res.Path = "/" + path
+		}
+	default:
+		return nil, invalid
+	}
+	res.Kind = kind
+	return res, nil
+}
+
+// parseNFSVolume returns the shared volume described by the --nfs-* flags, if
+// any.
+func parseNFSVolume(server, exportPath, mountPath string) ([]orchestrator.SharedVolume, error) {
+	if server == "" && exportPath == "" {
+		return nil, nil
+	}
+	if server == "" || exportPath == "" {
+		return nil, fmt.Errorf("--nfs-server and --nfs-path must be specified together")
+	}
+	if !path.IsAbs(exportPath) {
+		return nil, fmt.Errorf("invalid --nfs-path %q: must be an absolute path", exportPath)
+	}
+	if !path.IsAbs(mountPath) {
+		return nil, fmt.Errorf("invalid --nfs-mount-path %q: must be an absolute path", mountPath)
+	}
+	return []orchestrator.SharedVolume{{Server: server, Path: exportPath, MountPath: mountPath}}, nil
+}
+
+// spotDefaultRestarts is the default of --restarts for --spot workloads, whose
+// nodes may be preempted several times during a run.
+const spotDefaultRestarts = 5
+
+var taintEffects = []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+
+// parseTolerations parses --toleration values of the form
+// "<key>[=<value>][:<effect>]".
+func parseTolerations(values []string) ([]orchestrator.Toleration, error) {
+	var res []orchestrator.Toleration
+	for _, value := range values {
+		rest, effect, hasEffect := strings.Cut(value, ":")
+		key, val, _ := strings.Cut(rest, "=")
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid taint key %q in --toleration %q: %s", key, value, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(val); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid taint value %q in --toleration %q: %s", val, value, strings.Join(errs, "; "))
+		}
+		if hasEffect {
+			idx := slices.IndexFunc(taintEffects, func(e string) bool { return strings.EqualFold(e, effect) })
+			if idx < 0 {
+				return nil, fmt.Errorf("invalid effect %q in --toleration %q. Allowed values: %s", effect, value, strings.Join(taintEffects, ", "))
+			}
+			effect = taintEffects[idx]
+		}
+		res = append(res, orchestrator.Toleration{Key: key, Value: val, Effect: effect})
+	}
+	return res, nil
+}
+
+// reservedContainerNames are the names of the containers gcluster adds to the
+// pods besides the workload containers.
+var reservedContainerNames = []string{"log-archiver", "hostfile", "launcher", "worker"}
+
+// parseSidecars parses --sidecar values of the form
+// "image=<image>[,name=<name>][,command=<command>]". The command takes the
+// rest of the value, so that it may contain commas. Sidecars are named
+// sidecar-1, sidecar-2, ... unless a name is given.
+func parseSidecars(values []string) ([]orchestrator.ContainerSpec, error) {
+	var res []orchestrator.ContainerSpec
+	seen := map[string]bool{}
+	for i, value := range values {
+		sc := orchestrator.ContainerSpec{Name: fmt.Sprintf("sidecar-%d", i+1)}
+		rest := value
+		for rest != "" {
+			var opt string
+			if strings.HasPrefix(rest, "command=") {
+				opt, rest = rest, ""
+			} else {
+				opt, rest, _ = strings.Cut(rest, ",")
+			}
+			key, val, _ := strings.Cut(opt, "=")
+			switch {
+			case key == "image" && val != "":
+				sc.Image = val
+			case key == "name" && val != "":
+				sc.Name = val
+			case key == "command" && val != "":
+				sc.Command = val
+			default:
+				return nil, fmt.Errorf("invalid --sidecar %q: unknown option %q; use image=<image>, name=<name> or command=<command>", value, opt)
+			}
+		}
+		if sc.Image == "" {
+			return nil, fmt.Errorf("invalid --sidecar %q: image=<image> is required", value)
+		}
+		if errs := validation.IsDNS1123Label(sc.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --sidecar %q: name %q is not a valid container name: %s", value, sc.Name, strings.Join(errs, "; "))
+		}
+		if strings.HasPrefix(sc.Name, "workload-container") || slices.Contains(reservedContainerNames, sc.Name) {
+			return nil, fmt.Errorf("invalid --sidecar %q: name %q is reserved for the containers gcluster adds", value, sc.Name)
+		}
+		if seen[sc.Name] {
+			return nil, fmt.Errorf("--sidecar %s is given more than once", sc.Name)
+		}
+		seen[sc.Name] = true
+		res = append(res, sc)
+	}
+	return res, nil
+}
+
+// parseNodeAffinityExprs parses --node-affinity-expr values of the form
+// "<key> <operator> [v1,v2,...]".
+func parseNodeAffinityExprs(exprs []string) ([]orchestrator.NodeAffinityExpression, error) {
+	var res []orchestrator.NodeAffinityExpression
+	for _, expr := range exprs {
+		fields := strings.Fields(expr)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("invalid --node-affinity-expr %q. Must be in '<key> <operator> [values]' format", expr)
+		}
+		key := fields[0]
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q in --node-affinity-expr: %s", key, strings.Join(errs, "; "))
+		}
+		idx := slices.IndexFunc(nodeAffinityOperators, func(op string) bool { return strings.EqualFold(op, fields[1]) })
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid operator %q in --node-affinity-expr %q. Allowed values: %s", fields[1], expr, strings.Join(nodeAffinityOperators, ", "))
+		}
+		op := nodeAffinityOperators[idx]
+
+		var values []string
+		if len(fields) == 3 {
+			for _, v := range strings.Split(fields[2], ",") {
+				v = strings.TrimSpace(v)
+				if v == "" {
+					return nil, fmt.Errorf("invalid --node-affinity-expr %q: empty value", expr)
+				}
+				values = append(values, v)
+			}
+		}
+
+		switch op {
+		case "Exists", "DoesNotExist":
+			if len(values) > 0 {
+				return nil, fmt.Errorf("invalid --node-affinity-expr %q: operator %s does not take values", expr, op)
+			}
+		case "Gt", "Lt":
+			if len(values) != 1 {
+				return nil, fmt.Errorf("invalid --node-affinity-expr %q: operator %s requires exactly one integer value", expr, op)
+			}
+			if _, err := strconv.Atoi(values[0]); err != nil {
+				return nil, fmt.Errorf("invalid --node-affinity-expr %q: operator %s requires an integer value", expr, op)
+			}
+		default:
+			if len(values) == 0 {
+				return nil, fmt.Errorf("invalid --node-affinity-expr %q: operator %s requires at least one value", expr, op)
+			}
+		}
+		res = append(res, orchestrator.NodeAffinityExpression{Key: key, Operator: op, Values: values})
+	}
+	return res, nil
+}
+
 func validateEnvFlags(envs []string) error {
 	for _, env := range envs {
 		parts := strings.SplitN(env, "=", 2)
@@ -324,19 +1234,282 @@ func validatePathwaysFlags() error {
 	return nil
 }
 
+// validateWorkloadKindFlags checks --workload-kind. list, status, logs and
+// cancel track JobSets, so besides JobSets and MPI JobSets only single-node
+// batch Jobs, which run on clusters without the JobSet CRD, are submitted; the
+// other kinds are generated for review or for applying with other tooling.
+func validateWorkloadKindFlags() error {
+	if !slices.Contains(orchestrator.WorkloadKinds, workloadKind) {
+		return fmt.Errorf("invalid --workload-kind %q: must be one of %s", workloadKind, strings.Join(orchestrator.WorkloadKinds, ", "))
+	}
+	if workloadKind == orchestrator.WorkloadKindJobSet {
+		return nil
+	}
+	if isPathwaysJob {
+		return fmt.Errorf("--workload-kind %s cannot be used with --pathways", workloadKind)
+	}
+	if workloadKind == orchestrator.WorkloadKindMPI {
+		return nil
+	}
+	if numSlices != 1 && workloadKind != orchestrator.WorkloadKindRayJob {
+		return fmt.Errorf("--workload-kind %s does not support --num-slices; use jobset or rayjob for multi-slice workloads", workloadKind)
+	}
+	if dryRunManifest != "" || dryRun {
+		return nil
+	}
+	if workloadKind != orchestrator.WorkloadKindJob {
+		return fmt.Errorf("--workload-kind %s requires --dry-run-out or --dry-run; only jobset, mpi and job workloads can be submitted to the cluster", workloadKind)
+	}
+	if numNodes != 1 {
+		return fmt.Errorf("--workload-kind job runs on a single node; use --workload-kind jobset for --num-nodes %d", numNodes)
+	}
+	if awaitJobCompletion || timeoutStr != "-1s" {
+		return fmt.Errorf("--await-job-completion and --timeout are not supported with --workload-kind job")
+	}
+	return nil
+}
+
+// validateRestartFlags checks --restart-policy and --pod-backoff-limit against
+// what the Jobs of the workload accept.
+func validateRestartFlags() error {
+	idx := slices.IndexFunc(orchestrator.RestartPolicies, func(p string) bool { return strings.EqualFold(p, restartPolicy) })
+	if idx < 0 {
+		return fmt.Errorf("invalid --restart-policy %q: must be one of %s", restartPolicy, strings.Join(orchestrator.RestartPolicies, ", "))
+	}
+	restartPolicy = orchestrator.RestartPolicies[idx]
+	if podBackoffLimit < 0 {
+		return fmt.Errorf("--pod-backoff-limit must not be negative, got %d", podBackoffLimit)
+	}
+	if restartPolicy == orchestrator.RestartPolicyNever && podBackoffLimit == 0 {
+		return nil
+	}
+
+	if isPathwaysJob {
+		return fmt.Errorf("--restart-policy and --pod-backoff-limit cannot be used with --pathways")
+	}
+	switch workloadKind {
+	case orchestrator.WorkloadKindDeployment, orchestrator.WorkloadKindRayJob:
+		return fmt.Errorf("--restart-policy and --pod-backoff-limit cannot be used with --workload-kind %s", workloadKind)
+	case orchestrator.WorkloadKindJob:
+		if podBackoffLimit != 0 {
+			return fmt.Errorf("--pod-backoff-limit cannot be used with --workload-kind job, whose pod retries are set by --restarts")
+		}
+	}
+	if restartPolicy == orchestrator.RestartPolicyOnFailure {
+		if len(restartOnExitCodes) > 0 {
+			return fmt.Errorf("--restart-on-exit-codes requires --restart-policy Never: a pod failure policy cannot be used with restartPolicy OnFailure")
+		}
+		if podBackoffLimit == 0 && workloadKind != orchestrator.WorkloadKindJob {
+			return fmt.Errorf("--restart-policy OnFailure requires a positive --pod-backoff-limit: each container restart counts against it, so with 0 the first restart fails the job")
+		}
+	}
+	return nil
+}
+
 func validateImageFlags() error {
 	if pathways.Headless {
 		return nil
 	}
+	if err := resolveBuildContext(); err != nil {
+		return err
+	}
 	if err := validateImageSources(); err != nil {
 		return err
 	}
+	if err := validateImageTagFlags(); err != nil {
+		return err
+	}
+	if err := validateBaseImagePolicyFlags(); err != nil {
+		return err
+	}
+	if err := validateBuildBackendFlags(); err != nil {
+		return err
+	}
+	if err := configureRegistryAuth(); err != nil {
+		return err
+	}
 	return validateBuildContext()
 }
 
+// validateSecretEnvFlags checks the --secret-env references, whose variables
+// must not also be set with --env, and the --secret-env-mode.
+func validateSecretEnvFlags() error {
+	secretEnvMode = strings.ToLower(secretEnvMode)
+	if !slices.Contains(orchestrator.SecretEnvModes, secretEnvMode) {
+		return fmt.Errorf("invalid value %q for --secret-env-mode. Allowed values: %s", secretEnvMode, strings.Join(orchestrator.SecretEnvModes, ", "))
+	}
+	if err := validateEnvFlags(secretEnvStr); err != nil {
+		return err
+	}
+	env := parseEnvFlags(envVars)
+	for _, s := range secretEnvStr {
+		name, ref, _ := strings.Cut(s, "=")
+		if _, err := orchestrator.ParseSecretRef(ref); err != nil {
+			return fmt.Errorf("invalid --secret-env %s: %w", name, err)
+		}
+		if _, ok := env[name]; ok {
+			return fmt.Errorf("environment variable %s is set by both --env and --secret-env", name)
+		}
+	}
+	return nil
+}
+
+// validateBuildBackendFlags checks that the --build-backend can build the
+// image source, and defaults it to the backend of that source: cloudbuild for
+// a --dockerfile and crane otherwise.
+func validateBuildBackendFlags() error {
+	buildBackend = strings.ToLower(buildBackend)
+	switch buildBackend {
+	case "":
+		buildBackend = orchestrator.BuildBackendCrane
+		if dockerfile != "" {
+			buildBackend = orchestrator.BuildBackendCloudBuild
+		}
+	case orchestrator.BuildBackendCrane, orchestrator.BuildBackendRemote:
+		if baseImage == "" {
+			return fmt.Errorf("--build-backend %s can only be used with --base-image", buildBackend)
+		}
+	case orchestrator.BuildBackendDocker, orchestrator.BuildBackendCloudBuild:
+		if dockerfile == "" {
+			return fmt.Errorf("--build-backend %s can only be used with --dockerfile", buildBackend)
+		}
+	default:
+		return fmt.Errorf("invalid value %q for --build-backend. Allowed values: %s", buildBackend, strings.Join(orchestrator.BuildBackends, ", "))
+	}
+	if builderZone != "" && buildBackend != orchestrator.BuildBackendRemote {
+		return fmt.Errorf("--builder-zone can only be used with --build-backend remote")
+	}
+	if strings.Contains(platform, ",") {
+		switch buildBackend {
+		case orchestrator.BuildBackendDocker, orchestrator.BuildBackendCloudBuild:
+			return fmt.Errorf("--dockerfile builds a single-platform image with --build-backend %s; pass one --platform, or build a multi-platform image from a --base-image with --build-backend crane", buildBackend)
+		case orchestrator.BuildBackendRemote:
+			return fmt.Errorf("a list of --platform values builds a multi-platform image, which only --build-backend crane supports")
+		}
+	}
+	return nil
+}
+
+// validateImageTagFlags checks how the built image is tagged: with the
+// --image-tag, which implies the user strategy, or by --image-tag-strategy.
+func validateImageTagFlags() error {
+	if imageTag == "" && imageTagStrat == "" {
+		return nil
+	}
+	if baseImage == "" && dockerfile == "" {
+		return fmt.Errorf("--image-tag and --image-tag-strategy can only be used when an image is built from --base-image or --dockerfile")
+	}
+	imageTagStrat = strings.ToLower(imageTagStrat)
+	if imageTagStrat == "" {
+		imageTagStrat = string(imagebuilder.TagUserSupplied)
+	}
+	switch {
+	case !slices.Contains(imagebuilder.TagStrategies, imageTagStrat):
+		return fmt.Errorf("invalid value %q for --image-tag-strategy. Allowed values: %s", imageTagStrat, strings.Join(imagebuilder.TagStrategies, ", "))
+	case imageTagStrat == string(imagebuilder.TagUserSupplied) && imageTag == "":
+		return fmt.Errorf("--image-tag-strategy %s needs an --image-tag", imagebuilder.TagUserSupplied)
+	case imageTagStrat != string(imagebuilder.TagUserSupplied) && imageTag != "":
+		return fmt.Errorf("--image-tag can only be used with --image-tag-strategy %s", imagebuilder.TagUserSupplied)
+	}
+	if imageTag != "" {
+		if err := imagebuilder.ValidateImageTag(imageTag); err != nil {
+			return fmt.Errorf("invalid --image-tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// configureRegistryAuth makes image builds and lookups authenticate to
+// registries as selected by --registry-auth.
+func configureRegistryAuth() error {
+	registryAuth = strings.ToLower(registryAuth)
+	if registryCreds != "" && registryAuth != imagebuilder.RegistryAuthJSONKey && registryAuth != imagebuilder.RegistryAuthWorkloadIdentity {
+		return fmt.Errorf("--registry-credentials can only be used with --registry-auth %s or %s", imagebuilder.RegistryAuthJSONKey, imagebuilder.RegistryAuthWorkloadIdentity)
+	}
+	p, err := imagebuilder.NewAuthProvider(imagebuilder.RegistryAuthOptions{
+		Mode:            registryAuth,
+		CredentialsFile: registryCreds,
+		NonInteractive:  registryNonInteractive,
+	})
+	if err != nil {
+		return err
+	}
+	imagebuilder.SetRegistryAuth(p)
+	return nil
+}
+
+// validateSnippetFlags checks a --snippet, which replaces the command and
+// runs on an image as is. A --base-image is then used as the image instead of
+// being built upon.
+func validateSnippetFlags() error {
+	if snippet == "" {
+		return nil
+	}
+	snippetLang = strings.ToLower(snippetLang)
+	if !slices.Contains(orchestrator.SnippetLanguages, snippetLang) {
+		return fmt.Errorf("invalid value %q for --snippet-lang. Allowed values: %s", snippetLang, strings.Join(orchestrator.SnippetLanguages, ", "))
+	}
+	if commandToRun != "" || commandJSON != "" {
+		return fmt.Errorf("--snippet cannot be used with --command or --command-json")
+	}
+	if isPathwaysJob {
+		return fmt.Errorf("--snippet cannot be used with --pathways")
+	}
+	if buildContext != "" || requirements != "" || captureEnv != "" || dockerfile != "" {
+		return fmt.Errorf("--snippet runs without an image build and cannot be used with --build-context, --requirements, --capture-env or --dockerfile")
+	}
+	if baseImage != "" {
+		if imageName != "" {
+			return fmt.Errorf("--image and --base-image cannot be used together with --snippet")
+		}
+		imageName, baseImage = baseImage, ""
+	}
+	return nil
+}
+
+// maxCommandScriptBytes is the size limit of a ConfigMap, which holds a
+// --command-script.
+const maxCommandScriptBytes = 1 << 20
+
+// commandScriptContent is the content of the --command-script, read when the
+// flags are validated.
+var commandScriptContent string
+
+// validateCommandScriptFlags checks a --command-script, which replaces the
+// command, and reads it.
+func validateCommandScriptFlags() error {
+	commandScriptContent = ""
+	if commandScript == "" {
+		return nil
+	}
+	if commandToRun != "" || commandJSON != "" || snippet != "" {
+		return fmt.Errorf("--command-script cannot be used with --command, --command-json or --snippet")
+	}
+	if isPathwaysJob {
+		return fmt.Errorf("--command-script cannot be used with --pathways")
+	}
+	data, err := os.ReadFile(commandScript)
+	if err != nil {
+		return fmt.Errorf("failed to read --command-script: %w", err)
+	}
+	switch {
+	case len(bytes.TrimSpace(data)) == 0:
+		return fmt.Errorf("--command-script %s is empty", commandScript)
+	case len(data) > maxCommandScriptBytes:
+		return fmt.Errorf("--command-script %s is %d bytes, more than the 1 MiB a ConfigMap holds", commandScript, len(data))
+	case !utf8.Valid(data):
+		return fmt.Errorf("--command-script %s must be valid UTF-8", commandScript)
+	}
+	commandScriptContent = string(data)
+	return nil
+}
+
 func validateImageSources() error {
+	if dockerfile != "" {
+		return validateDockerfileFlags()
+	}
 	if (imageName == "" && baseImage == "") || (buildContext != "" && baseImage == "") {
-		return fmt.Errorf("either --image or --base-image must be provided")
+		return fmt.Errorf("one of --image, --base-image or --dockerfile must be provided")
 	}
 	if imageName != "" && buildContext != "" {
 		return fmt.Errorf("--build-context cannot be provided when --image is used as no build is performed")
@@ -344,15 +1517,109 @@ func validateImageSources() error {
 	if baseImage != "" && buildContext == "" {
 		return fmt.Errorf("a --build-context must be provided when --base-image is used for a Crane build")
 	}
+	if workDir != "" {
+		if baseImage == "" {
+			return fmt.Errorf("--workdir can only be used with --base-image")
+		}
+		if !path.IsAbs(workDir) {
+			return fmt.Errorf("--workdir %q must be an absolute path", workDir)
+		}
+		workDir = path.Clean(workDir)
+	}
+	if len(imageLabels) > 0 {
+		if baseImage == "" {
+			return fmt.Errorf("--image-label can only be used with --base-image")
+		}
+		for _, l := range imageLabels {
+			if k, _, ok := strings.Cut(l, "="); !ok || k == "" {
+				return fmt.Errorf("invalid --image-label %q, expected KEY=VALUE", l)
+			}
+		}
+	}
+	if requirements != "" {
+		if baseImage == "" {
+			return fmt.Errorf("--requirements can only be used with --base-image")
+		}
+		if fi, err := os.Stat(requirements); err != nil || fi.IsDir() {
+			return fmt.Errorf("requirements file %q not found", requirements)
+		}
+	}
+	if captureEnv != "" {
+		if baseImage == "" {
+			return fmt.Errorf("--capture-env can only be used with --base-image")
+		}
+		if requirements != "" {
+			return fmt.Errorf("--capture-env cannot be used with --requirements")
+		}
+		if _, err := imagebuilder.ParseCaptureEnv(captureEnv); err != nil {
+			return fmt.Errorf("invalid --capture-env: %w", err)
+		}
+	}
+	if (requirements != "" || captureEnv != "") && strings.Contains(platform, ",") {
+		return fmt.Errorf("--requirements and --capture-env can only be used with a single --platform")
+	}
 	return nil
 }
 
+// validateDockerfileFlags checks a --dockerfile build, which replaces --image
+// and --base-image, and defaults its --build-context to the directory of the
+// Dockerfile.
+func validateDockerfileFlags() error {
+	if imageName != "" || baseImage != "" {
+		return fmt.Errorf("--dockerfile cannot be used with --image or --base-image")
+	}
+	if workDir != "" {
+		return fmt.Errorf("--workdir can only be used with --base-image, set the WORKDIR in the --dockerfile instead")
+	}
+	if len(imageLabels) > 0 {
+		return fmt.Errorf("--image-label can only be used with --base-image, set a LABEL in the --dockerfile instead")
+	}
+	if requirements != "" || captureEnv != "" {
+		return fmt.Errorf("--requirements and --capture-env can only be used with --base-image, install dependencies in the --dockerfile instead")
+	}
+	if fi, err := os.Stat(dockerfile); err != nil || fi.IsDir() {
+		return fmt.Errorf("Dockerfile %q not found", dockerfile)
+	}
+	if buildContext == "" {
+		buildContext = filepath.Dir(dockerfile)
+	}
+	_, err := imagebuilder.DockerfileInContext(buildContext, dockerfile)
+	return err
+}
+
+func validateBaseImagePolicyFlags() error {
+	baseImagePolicy = strings.ToLower(baseImagePolicy)
+	if !slices.Contains(orchestrator.BaseImagePolicies, baseImagePolicy) {
+		return fmt.Errorf("invalid value %q for --base-image-policy. Allowed values: %s", baseImagePolicy, strings.Join(orchestrator.BaseImagePolicies, ", "))
+	}
+	if baseImageMaxAgeDays < 0 {
+		return fmt.Errorf("--base-image-max-age must not be negative, got %d", baseImageMaxAgeDays)
+	}
+	if baseImage == "" && (baseImageMaxAgeDays > 0 || baseImagePolicy != orchestrator.BaseImagePolicyWarn) {
+		return fmt.Errorf("--base-image-max-age and --base-image-policy can only be used with --base-image")
+	}
+	return nil
+}
+
+// registryProject returns the --build-project when it differs from the
+// cluster's project, and "" otherwise.
+func registryProject() string {
+	if buildProject == projectID {
+		return ""
+	}
+	return buildProject
+}
+
 func validateBuildContext() error {
 	if buildContext == "" {
 		return nil
 	}
-	if os.Getenv("GCLUSTER_IMAGE_REPO") == "" {
-		return fmt.Errorf("GCLUSTER_IMAGE_REPO environment variable is required when using --build-context. Please set it in your environment with the repository name only (e.g., export GCLUSTER_IMAGE_REPO=gcluster-repo)")
+	if imageRepo != "" {
+		if _, err := imagebuilder.ParseImageRepo(imageRepo); err != nil {
+			return err
+		}
+	} else if os.Getenv("GCLUSTER_IMAGE_REPO") == "" {
+		return fmt.Errorf("GCLUSTER_IMAGE_REPO environment variable is required when using --build-context. Please set it in your environment with the repository name only (e.g., export GCLUSTER_IMAGE_REPO=gcluster-repo), or pass --image-repo")
 	}
 	if os.Getenv("USER") == "" && os.Getenv("USERNAME") == "" {
 		return fmt.Errorf("failed to determine user identity from environment (tried USER and USERNAME). This is required to ensure unique image tagging when using --build-context")
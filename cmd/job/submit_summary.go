@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// submitSummary is printed once a workload is submitted.
+type submitSummary struct {
+	orchestrator.SubmitResult
+	BuildLog     string        `json:"build_log,omitempty"`
+	NextCommands *nextCommands `json:"next_commands,omitempty"` // Nil for dry runs and backends other than GKE.
+}
+
+// nextCommands are copy-pasteable commands to follow up on a workload.
+type nextCommands struct {
+	Status string `json:"status"`
+	Logs   string `json:"logs"`
+	Cancel string `json:"cancel"`
+}
+
+// newSubmitSummary returns the summary of result. The follow-up commands repeat
+// the cluster flags given on the command line, so they target the same cluster.
+func newSubmitSummary(cmd *cobra.Command, result orchestrator.SubmitResult) submitSummary {
+	var target []string
+	if flag := cmd.Flag("cluster-project"); flag != nil && flag.Changed {
+		target = append(target, "--project", flag.Value.String())
+	} else if flag := cmd.Flag("project"); flag != nil && flag.Changed {
+		target = append(target, "--project", flag.Value.String())
+	}
+	for _, f := range []string{"cluster", "location"} {
+		if flag := cmd.Flag(f); flag != nil && flag.Changed {
+			target = append(target, "--"+f, flag.Value.String())
+		}
+	}
+	command := func(args ...string) string {
+		return strings.Join(append(append([]string{"gcluster", "job"}, args...), target...), " ")
+	}
+	var buildLog string
+	if flag := cmd.Flag("build-log-file"); flag != nil && flag.Value.String() != "" {
+		buildLog = flag.Value.String()
+		if abs, err := filepath.Abs(buildLog); err == nil {
+			buildLog = abs
+		}
+	}
+	return submitSummary{
+		SubmitResult: result,
+		BuildLog:     buildLog,
+		NextCommands: &nextCommands{
+			Status: command("status", result.WorkloadName),
+			Logs:   command("logs", result.WorkloadName, "-f"),
+			Cancel: command("cancel", result.WorkloadName),
+		},
+	}
+}
+
+// printSubmitSummary prints s in format: text for people, or json or yaml
+// for scripts.
+func printSubmitSummary(out io.Writer, s submitSummary, format string) error {
+	if format != "text" {
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		if format == "yaml" {
+			if data, err = yaml.JSONToYAML(data); err != nil {
+				return err
+			}
+		} else {
+			data = append(data, '\n')
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	image := s.Image
+	if s.ImageDigest != "" && !strings.Contains(image, "@") {
+		image += "@" + s.ImageDigest
+	}
+	fmt.Fprintln(out, "\nWorkload submitted:")
+	fmt.Fprintf(out, "  Name:       %s\n", s.WorkloadName)
+	if s.Namespace != "" {
+		fmt.Fprintf(out, "  Namespace:  %s\n", s.Namespace)
+	}
+	if s.ClusterName != "" {
+		fmt.Fprintf(out, "  Cluster:    %s (%s, project %s)\n", s.ClusterName, s.Location, s.ProjectID)
+	} else {
+		fmt.Fprintf(out, "  Location:   %s (project %s)\n", s.Location, s.ProjectID)
+	}
+	if s.ComputeType != "" {
+		fmt.Fprintf(out, "  Compute:    %s\n", s.ComputeType)
+	}
+	if image != "" {
+		fmt.Fprintf(out, "  Image:      %s\n", image)
+	}
+	fmt.Fprintf(out, "  Run ID:     %s\n", s.RunID)
+	if s.BuildLog != "" {
+		fmt.Fprintf(out, "  Build log:  %s\n", s.BuildLog)
+	}
+	if s.NextCommands == nil {
+		if s.WorkloadURL != "" {
+			fmt.Fprintf(out, "\nFollow the workload at:\n  %s\n", s.WorkloadURL)
+		}
+		return nil
+	}
+	fmt.Fprintln(out, "\nNext steps:")
+	fmt.Fprintf(out, "  %s\n", s.NextCommands.Status)
+	fmt.Fprintf(out, "  %s\n", s.NextCommands.Logs)
+	fmt.Fprintf(out, "  %s\n", s.NextCommands.Cancel)
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+type resultOrchestrator struct {
+	mockOrchestrator
+}
+
+func (m *resultOrchestrator) SubmitJob(_ context.Context, job orchestrator.JobDefinition) (orchestrator.SubmitResult, error) {
+	return orchestrator.SubmitResult{
+		WorkloadName: job.WorkloadName,
+		Namespace:    "team-a",
+		ClusterName:  job.ClusterName,
+		Location:     job.ClusterLocation,
+		ProjectID:    job.ClusterProjectID,
+		RunID:        "0123456789ab",
+		ComputeType:  job.ComputeType,
+		Image:        job.ImageName,
+		ImageDigest:  "sha256:feed",
+		Manifest:     job.DryRunManifest,
+	}, nil
+}
+
+func TestSubmitCmd_Summary(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{
+		State: PrereqState{
+			LastCheckedTimestamp: time.Now(),
+			LastCheckedProjectID: "test-project",
+			GCloudSDKInstalled:   true,
+			GCloudAuthenticated:  true,
+			ADCConfigured:        true,
+			KubectlInstalled:     true,
+		},
+	}
+
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &resultOrchestrator{}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "summary-test",
+		"--image", "us-docker.pkg.dev/p/r/img:v1",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+	}
+	target := " --project test-project --cluster test-cluster --location us-central1-a"
+
+	resetSubmitCmdFlags()
+	output, err := executeCommand(JobCmd, args...)
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	for _, want := range []string{
+		"Name:       summary-test",
+		"Namespace:  team-a",
+		"Cluster:    test-cluster (us-central1-a, project test-project)",
+		"Compute:    n2-standard-4",
+		"Image:      us-docker.pkg.dev/p/r/img:v1@sha256:feed",
+		"gcluster job status summary-test" + target,
+		"gcluster job logs summary-test -f" + target,
+		"gcluster job cancel summary-test" + target,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("summary missing %q:\n%s", want, output)
+		}
+	}
+
+	resetSubmitCmdFlags()
+	output, err = executeCommand(JobCmd, append(args, "--json")...)
+	if err != nil {
+		t.Fatalf("submit --json failed: %v", err)
+	}
+	var got submitSummary
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("expected only the JSON summary on stdout: %v\n%s", err, output)
+	}
+	if got.ImageDigest != "sha256:feed" || got.Namespace != "team-a" || got.NextCommands.Logs != "gcluster job logs summary-test -f"+target {
+		t.Errorf("unexpected JSON summary: %+v", got)
+	}
+
+	resetSubmitCmdFlags()
+	buildLog := filepath.Join(t.TempDir(), "build.log")
+	output, err = executeCommand(JobCmd, append(args, "--build-log-file", buildLog)...)
+	if err != nil {
+		t.Fatalf("submit --build-log-file failed: %v", err)
+	}
+	if !strings.Contains(output, "Build log:  "+buildLog) {
+		t.Errorf("summary missing the build log:\n%s", output)
+	}
+	if _, err := os.Stat(buildLog); err != nil {
+		t.Errorf("expected the build log to be written: %v", err)
+	}
+
+	resetSubmitCmdFlags()
+	output, err = executeCommand(JobCmd, append(args, "--output-format", "yaml")...)
+	if err != nil {
+		t.Fatalf("submit --output-format yaml failed: %v", err)
+	}
+	if !strings.HasPrefix(output, "cluster: test-cluster") || !strings.Contains(output, "image_digest: sha256:feed") {
+		t.Errorf("expected only the YAML summary on stdout:\n%s", output)
+	}
+
+	resetSubmitCmdFlags()
+	manifest := filepath.Join(t.TempDir(), "workload.yaml")
+	output, err = executeCommand(JobCmd, append(args, "--json", "--dry-run-out", manifest)...)
+	if err != nil {
+		t.Fatalf("submit --json --dry-run-out failed: %v", err)
+	}
+	got = submitSummary{}
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("expected only the JSON summary on stdout: %v\n%s", err, output)
+	}
+	if got.Manifest != manifest || got.NextCommands != nil {
+		t.Errorf("expected the manifest path and no next commands for a dry run, got %+v", got)
+	}
+
+	resetSubmitCmdFlags()
+	_, err = executeCommand(JobCmd, append(args, "--output-format", "xml")...)
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --output-format: xml") {
+		t.Errorf("expected an invalid output format error, got %v", err)
+	}
+
+	resetSubmitCmdFlags()
+	_, err = executeCommand(JobCmd, append(args, "--json", "--dry-run")...)
+	if err == nil || !strings.Contains(err.Error(), "--json cannot be used with --dry-run") {
+		t.Errorf("expected --json and --dry-run to conflict, got %v", err)
+	}
+}
@@ -16,15 +16,21 @@ package job
 
 import (
 	"bytes"
+	"context"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/imagebuilder"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func executeCommand(root *cobra.Command, args ...string) (string, error) {
@@ -63,7 +69,7 @@ func TestSubmitCmd_PathwaysDryRun(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -138,7 +144,7 @@ func TestSubmitCmd_RegularDryRun(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -256,24 +262,60 @@ func resetSubmitCmdFlags() {
 	imageName = ""
 	baseImage = ""
 	buildContext = ""
+	dockerfile = ""
+	requirements = ""
+	captureEnv = ""
+	signKey = ""
 	commandToRun = ""
+	commandJSON = ""
+	impersonate = ""
+	verbosity = 0
+	buildLogFile = ""
+	snippet = ""
+	snippetLang = orchestrator.SnippetLanguagePython
+	commandScript = ""
+	costTeam = ""
+	costExperiment = ""
+	nameTemplate = ""
 	computeType = ""
+	gpuMemory = ""
 	dryRunManifest = ""
+	dryRun = false
 	clusterName = ""
 	location = ""
 	projectID = ""
 	workloadName = ""
+	workloadKind = orchestrator.WorkloadKindJobSet
 	kueueQueueName = ""
 	numNodes = 1
 	numSlices = 1
+	cpuLimit = ""
+	memoryLimit = ""
+	cpuRequest = ""
+	memoryRequest = ""
+	gpusPerPod = 0
 	restarts = 1
 	ttlAfterFinished = "1h"
 	gracePeriodStr = "30s"
 	placementPolicy = ""
 	nodeConstraint = nil
+	nodeSelectors = nil
+	nodeAffinityExprs = nil
+	tolerations = nil
+	spot = false
+	dnsHostnames = false
+	framework = ""
+	presetsFile = ""
+	inputStr = nil
+	retentionStr = nil
+	restartPolicy = orchestrator.RestartPolicyNever
+	podBackoffLimit = 0
 	cpuAffinityStr = ""
 	restartOnExitCodes = nil
 	imagePullSecrets = ""
+	mintPullSecret = false
+	pullSecretAccount = ""
+	refreshPullSecret = false
 	serviceAccountName = ""
 	topology = ""
 	gkeScheduler = ""
@@ -284,17 +326,53 @@ func resetSubmitCmdFlags() {
 	pathways = orchestrator.PathwaysJobDefinition{MaxSliceRestarts: 1}
 	gkeNapProvisioning = ""
 	gkeNapReservation = ""
+	backend = backendGKE
 	envVars = nil
+	secretEnvStr = nil
+	secretEnvMode = orchestrator.SecretEnvModeSecret
 	pathwaysProxyEnv = nil
 	pathwaysServerEnv = nil
 	pathwaysWorkerEnv = nil
+	archiveLogs = ""
+	stageInStr = nil
+	noQueue = false
+	submitJSON = false
+	submitOutput = "text"
+	secretMountStr = nil
+	gcsBucketStr = nil
+	nfsServer = ""
+	nfsPath = ""
+	nfsMountPath = "/mnt/nfs"
+	clusterProject = ""
+	buildProject = ""
+	jobSpecFile = ""
+	startupProbeStr = ""
+	startupTimeoutStr = ""
+	stepTimeoutStrs = [3]string{}
+	buildBackend = ""
+	builderZone = ""
+	workDir = ""
+	imageLabels = nil
+	imageTag = ""
+	imageTagStrat = ""
+	registryAuth = imagebuilder.RegistryAuthDefault
+	registryCreds = ""
+	registryNonInteractive = false
+	filterStatus = ""
+	filterName = ""
+	filterExperiment = ""
+	listOutput = "table"
+	configMapMountStr = nil
+	imageRepo = ""
+	baseImageMaxAgeDays = 0
+	baseImagePolicy = orchestrator.BaseImagePolicyWarn
 }
 
 type mockOrchestrator struct {
 	orchestrator.JobOrchestrator
 }
 
-func (m *mockOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
+func (m *mockOrchestrator) SubmitJob(_ context.Context, job orchestrator.JobDefinition) (orchestrator.SubmitResult, error) {
 	if job.DryRunManifest != "" {
 		var content string
 		if job.IsPathwaysJob {
@@ -302,9 +380,9 @@ func (m *mockOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
 		} else {
 			content = "name: " + job.WorkloadName + "\nimage: busybox"
 		}
-		return os.WriteFile(job.DryRunManifest, []byte(content), 0644)
+		return orchestrator.SubmitResult{}, os.WriteFile(job.DryRunManifest, []byte(content), 0644)
 	}
-	return nil
+	return orchestrator.SubmitResult{WorkloadName: job.WorkloadName}, nil
 }
 
 type MockPrereqStore struct {
@@ -360,7 +438,7 @@ func TestSubmitCmd_MissingRepoEnvVar(t *testing.T) {
 
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -385,6 +463,539 @@ func TestSubmitCmd_MissingRepoEnvVar(t *testing.T) {
 	}
 }
 
+func TestSubmitCmd_ImageRepo(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Setenv("GCLUSTER_IMAGE_REPO", "")
+	t.Setenv("USER", "tester")
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := func(repo string) []string {
+		return []string{
+			"submit",
+			"--name", "repo-test",
+			"--base-image", "python:3.9-slim",
+			"--build-context", t.TempDir(),
+			"--command", "echo hello",
+			"--compute-type", "n2-standard-4",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+			"--image-repo", repo,
+		}
+	}
+
+	if _, err := executeCommand(JobCmd, args("gcr.io/test-project")...); err == nil || !strings.Contains(err.Error(), "invalid image repository") {
+		t.Fatalf("expected an invalid image repository error, got %v", err)
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args("us-docker.pkg.dev/shared/images")...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.ImageRepo != "us-docker.pkg.dev/shared/images" {
+		t.Errorf("expected the image repo to be passed to the orchestrator, got %q", got.ImageRepo)
+	}
+}
+
+func TestSubmitCmd_BaseImagePolicy(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Setenv("GCLUSTER_IMAGE_REPO", "gcluster")
+	t.Setenv("USER", "tester")
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := func(extra ...string) []string {
+		return append([]string{
+			"submit",
+			"--name", "policy-test",
+			"--command", "echo hello",
+			"--compute-type", "n2-standard-4",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+		}, extra...)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"invalid policy", args("--base-image", "python:3.11", "--build-context", t.TempDir(), "--base-image-policy", "ignore"), "invalid value \"ignore\" for --base-image-policy"},
+		{"negative age", args("--base-image", "python:3.11", "--build-context", t.TempDir(), "--base-image-max-age", "-1"), "must not be negative"},
+		{"without base image", args("--image", "us-docker.pkg.dev/p/r/img:v1", "--base-image-max-age", "30"), "can only be used with --base-image"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resetSubmitCmdFlags()
+			if _, err := executeCommand(JobCmd, tc.args...); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args("--base-image", "python:3.11", "--build-context", t.TempDir(), "--base-image-max-age", "30", "--base-image-policy", "Block")...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.BaseImageMaxAgeDays != 30 || got.BaseImagePolicy != orchestrator.BaseImagePolicyBlock {
+		t.Errorf("expected the base image policy to be passed to the orchestrator, got %d days, %q", got.BaseImageMaxAgeDays, got.BaseImagePolicy)
+	}
+}
+
+func TestSubmitCmd_BuildBackend(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Cleanup(resetSubmitCmdFlags)
+	t.Setenv("GCLUSTER_IMAGE_REPO", "gcluster")
+	t.Setenv("USER", "tester")
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	dockerfilePath := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(dockerfilePath, []byte("FROM python:3.11\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := func(extra ...string) []string {
+		return append([]string{
+			"submit",
+			"--name", "backend-test",
+			"--command", "echo hello",
+			"--compute-type", "n2-standard-4",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+		}, extra...)
+	}
+
+	for name, tc := range map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"unknown backend":          {args("--base-image", "python:3.11", "--build-context", t.TempDir(), "--build-backend", "kaniko"), `invalid value "kaniko" for --build-backend`},
+		"without base image":       {args("--image", "us-docker.pkg.dev/p/r/img:v1", "--build-backend", "remote"), "--build-backend remote can only be used with --base-image"},
+		"zone without remote":      {args("--base-image", "python:3.11", "--build-context", t.TempDir(), "--builder-zone", "us-central1-b"), "--builder-zone can only be used with --build-backend remote"},
+		"docker base image":        {args("--base-image", "python:3.11", "--build-context", t.TempDir(), "--build-backend", "docker"), "--build-backend docker can only be used with --dockerfile"},
+		"crane dockerfile":         {args("--dockerfile", dockerfilePath, "--build-backend", "crane"), "--build-backend crane can only be used with --base-image"},
+		"dockerfile platform list": {args("--dockerfile", dockerfilePath, "--platform", "linux/amd64,linux/arm64"), "--dockerfile builds a single-platform image with --build-backend cloudbuild"},
+		"docker platform list":     {args("--dockerfile", dockerfilePath, "--build-backend", "docker", "--platform", "linux/amd64,linux/arm64"), "--dockerfile builds a single-platform image with --build-backend docker"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resetSubmitCmdFlags()
+			if _, err := executeCommand(JobCmd, tc.args...); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args("--base-image", "python:3.11", "--build-context", t.TempDir(), "--build-backend", "Remote", "--builder-zone", "us-central1-b")...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.BuildBackend != orchestrator.BuildBackendRemote || got.BuilderZone != "us-central1-b" {
+		t.Errorf("expected the remote backend in us-central1-b, got %q in %q", got.BuildBackend, got.BuilderZone)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{args("--base-image", "python:3.11", "--build-context", t.TempDir()), orchestrator.BuildBackendCrane},
+		{args("--dockerfile", dockerfilePath), orchestrator.BuildBackendCloudBuild},
+		{args("--dockerfile", dockerfilePath, "--build-backend", "docker"), orchestrator.BuildBackendDocker},
+	} {
+		resetSubmitCmdFlags()
+		if _, err := executeCommand(JobCmd, tc.args...); err != nil {
+			t.Fatalf("submit failed: %v", err)
+		}
+		if got.BuildBackend != tc.want {
+			t.Errorf("%v: BuildBackend = %q, want %q", tc.args, got.BuildBackend, tc.want)
+		}
+	}
+}
+
+func TestSubmitCmd_CaptureEnv(t *testing.T) {
+	t.Setenv("GCLUSTER_IMAGE_REPO", "gcluster")
+	t.Setenv("USER", "tester")
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	oldExec := shell.ExecuteCommand
+	defer func() { shell.ExecuteCommand = oldExec }()
+	shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+		if name != "conda" {
+			t.Fatalf("unexpected command: %s %v", name, args)
+		}
+		return shell.CommandResult{Stdout: "name: ml\ndependencies:\n  - numpy\n"}
+	}
+
+	var got orchestrator.JobDefinition
+	var spec []byte
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got, onSubmit: func(job orchestrator.JobDefinition) {
+			spec, _ = os.ReadFile(job.Requirements)
+		}}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "capture-test",
+		"--command", "python train.py",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--base-image", "continuumio/miniconda3",
+		"--build-context", t.TempDir(),
+		"--capture-env", "conda:ml",
+	}
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.CapturedEnv != "conda:ml" || !strings.HasPrefix(got.CapturedEnvDigest, "sha256:") {
+		t.Errorf("expected the captured environment to be recorded, got %q (%q)", got.CapturedEnv, got.CapturedEnvDigest)
+	}
+	if filepath.Base(got.Requirements) != "environment.yml" || string(spec) != "dependencies:\n  - numpy\n" {
+		t.Errorf("expected the exported environment to be installed, got %s: %q", got.Requirements, spec)
+	}
+	if _, err := os.Stat(got.Requirements); !os.IsNotExist(err) {
+		t.Errorf("expected the exported environment to be removed after the submission, got %v", err)
+	}
+
+	resetSubmitCmdFlags()
+	req := filepath.Join(t.TempDir(), "requirements.txt")
+	if err := os.WriteFile(req, []byte("numpy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeCommand(JobCmd, append(args, "--requirements", req)...); err == nil || !strings.Contains(err.Error(), "cannot be used with --requirements") {
+		t.Errorf("expected --capture-env and --requirements to conflict, got %v", err)
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, append(args[:len(args)-1], "pipenv:ml")...); err == nil || !strings.Contains(err.Error(), "invalid --capture-env") {
+		t.Errorf("expected an invalid --capture-env error, got %v", err)
+	}
+}
+
+func TestSubmitCmd_DryRun(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Setenv("USER", "tester")
+
+	// A stale prerequisite state would run the gcloud and kubectl checks.
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	_, err := executeCommand(JobCmd,
+		"submit",
+		"--name", "dry-run-test",
+		"--image", "us-docker.pkg.dev/p/r/img:v1",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--workload-kind", "rayjob",
+		"--dry-run",
+	)
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if !got.DryRun || got.DryRunManifest != "" {
+		t.Errorf("expected a dry run printing the manifest, got DryRun=%v DryRunManifest=%q", got.DryRun, got.DryRunManifest)
+	}
+	if !got.IsDryRun() {
+		t.Error("expected IsDryRun to report the dry run")
+	}
+}
+
+func TestSubmitCmd_NoQueue(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "no-queue-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--dry-run",
+		"--no-queue",
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if !got.NoQueue || got.KueueQueueName != "" {
+		t.Errorf("expected a submission without a queue, got NoQueue=%v KueueQueueName=%q", got.NoQueue, got.KueueQueueName)
+	}
+
+	resetSubmitCmdFlags()
+	_, err := executeCommand(JobCmd, append(args, "--queue", "my-queue")...)
+	if err == nil || !strings.Contains(err.Error(), "--no-queue cannot be used with --queue") {
+		t.Errorf("expected --no-queue and --queue to conflict, got %v", err)
+	}
+}
+
+func TestSubmitCmd_MintPullSecret(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "pull-test",
+		"--image", "us-docker.pkg.dev/other/repo/app:v1",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--dry-run",
+		"--mint-pull-secret",
+	}
+
+	resetSubmitCmdFlags()
+	_, err := executeCommand(JobCmd, args...)
+	if err == nil || !strings.Contains(err.Error(), "--mint-pull-secret requires --pull-secret-sa") {
+		t.Errorf("expected --mint-pull-secret without a service account to be refused, got %v", err)
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, append(args, "--pull-secret-sa", "reader@other.iam.gserviceaccount.com", "--refresh-pull-secret")...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if !got.MintPullSecret || got.PullSecretAccount != "reader@other.iam.gserviceaccount.com" || !got.RefreshPullSecret {
+		t.Errorf("unexpected pull secret settings: MintPullSecret=%v PullSecretAccount=%q RefreshPullSecret=%v", got.MintPullSecret, got.PullSecretAccount, got.RefreshPullSecret)
+	}
+
+	resetSubmitCmdFlags()
+	_, err = executeCommand(JobCmd, append(args[:len(args)-1], "--refresh-pull-secret")...)
+	if err == nil || !strings.Contains(err.Error(), "require --mint-pull-secret") {
+		t.Errorf("expected --refresh-pull-secret without --mint-pull-secret to be refused, got %v", err)
+	}
+}
+
+func TestSubmitCmd_SeparateProjects(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+	defer func() { SubmitCmd.Flags().Lookup("cluster-project").Changed = false }()
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "projects-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--cluster-project", "cluster-project",
+		"--build-project", "build-project",
+		"--dry-run",
+	}
+
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.ClusterProjectID != "cluster-project" || got.BuildProjectID != "build-project" {
+		t.Errorf("expected cluster project %q and build project %q, got %q and %q", "cluster-project", "build-project", got.ClusterProjectID, got.BuildProjectID)
+	}
+
+	resetSubmitCmdFlags()
+	_, err := executeCommand(JobCmd, append(args, "--project", "other-project")...)
+	if err == nil || !strings.Contains(err.Error(), "name different projects") {
+		t.Errorf("expected --project and --cluster-project to conflict, got %v", err)
+	}
+}
+
+func TestSubmitCmd_ManagedProfile(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Setenv("USER", "tester")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "profile-test",
+		"--image", "us-docker.pkg.dev/p/r/img:v1",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".gcluster"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	profilePath := filepath.Join(home, ".gcluster", "managed_profile.json")
+	if err := os.WriteFile(profilePath, []byte(`{"name": "ml-research", "allowed_queues": ["ml-queue", "ml-low-queue"], "max_gpus": 16}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.ManagedProfile == nil || got.ManagedProfile.Name != "ml-research" || got.ManagedProfile.MaxGPUs != 16 {
+		t.Errorf("expected the managed profile to be passed to the orchestrator, got %+v", got.ManagedProfile)
+	}
+	if got.KueueQueueName != "ml-queue" {
+		t.Errorf("expected the queue to default to the first allowed queue, got %q", got.KueueQueueName)
+	}
+
+	if err := os.WriteFile(profilePath, []byte(`{"allowed_queues": ["ml-queue"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args...); err == nil || !strings.Contains(err.Error(), "has no name") {
+		t.Errorf("expected a profile without a name to be rejected, got %v", err)
+	}
+}
+
+func TestSubmitCmd_PresetsFile(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Setenv("USER", "tester")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(config.ResetAcceleratorPresets)
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &orchestrator.JobDefinition{}}
+	}
+
+	stateDir := filepath.Join(home, ".gcluster")
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(stateDir, "managed_profile.json"), `{"name": "ml-research", "presets_file": "site_presets.yaml"}`)
+	write(filepath.Join(stateDir, "site_presets.yaml"), "version: 1\npresets:\n  - machine_type: acme-highgpu-4g\n    accelerators: 4\n    cpu: \"90\"\n")
+	userPresets := filepath.Join(home, "presets.yaml")
+	write(userPresets, "version: 1\npresets:\n  - machine_type: acme-highgpu-4g\n    accelerators: 4\n    cpu: \"80\"\n")
+
+	submit := func(extra ...string) error {
+		resetSubmitCmdFlags()
+		args := append([]string{"submit",
+			"--name", "presets-test",
+			"--image", "us-docker.pkg.dev/p/r/img:v1",
+			"--command", "echo hello",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+		}, extra...)
+		_, err := executeCommand(JobCmd, args...)
+		return err
+	}
+
+	if err := submit(); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if preset, ok := config.AcceleratorPresetFor("acme-highgpu-4g"); !ok || preset.CPU != "90" {
+		t.Errorf("expected the presets of the managed profile, got %+v, %v", preset, ok)
+	}
+
+	if err := submit("--presets-file", userPresets); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if preset, _ := config.AcceleratorPresetFor("acme-highgpu-4g"); preset.CPU != "80" {
+		t.Errorf("expected --presets-file to take precedence, got %+v", preset)
+	}
+
+	write(userPresets, "version: 2\n")
+	if err := submit("--presets-file", userPresets); err == nil || !strings.Contains(err.Error(), "unsupported version 2") {
+		t.Errorf("expected an unsupported presets file to be rejected, got %v", err)
+	}
+}
+
 func TestSubmitCmd_MissingUserEnvVar(t *testing.T) {
 	resetSubmitCmdFlags()
 
@@ -405,7 +1016,7 @@ func TestSubmitCmd_MissingUserEnvVar(t *testing.T) {
 
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -510,6 +1121,115 @@ func TestSubmitCmd_ReservationModelWithoutName_Fails(t *testing.T) {
 	}
 }
 
+func TestSubmitCmd_RequirementsWithoutBaseImage_Fails(t *testing.T) {
+	resetSubmitCmdFlags()
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now()}}
+
+	output, err := executeCommand(JobCmd,
+		"submit",
+		"--name", "requirements-test",
+		"--image", "busybox",
+		"--requirements", "requirements.txt",
+		"--command", "echo hello",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--compute-type", "n2-standard-4",
+	)
+
+	if err == nil {
+		t.Fatalf("expected error when passing --requirements without --base-image, but got nil")
+	}
+
+	expectedErr := "--requirements can only be used with --base-image"
+	if !strings.Contains(output, expectedErr) && !strings.Contains(err.Error(), expectedErr) {
+		t.Errorf("expected error message to contain %q, got output: %q, err: %v", expectedErr, output, err)
+	}
+}
+
+func TestSubmitCmd_InvalidImageOptions_Fails(t *testing.T) {
+	t.Setenv(imagebuilder.RegistryTokenEnv, "")
+	reqs := filepath.Join(t.TempDir(), "requirements.txt")
+	if err := os.WriteFile(reqs, []byte("numpy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"without base image", []string{"--image", "busybox", "--workdir", "/app"}, "--workdir can only be used with --base-image"},
+		{"relative path", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--workdir", "app"}, `--workdir "app" must be an absolute path`},
+		{"image label without base image", []string{"--image", "busybox", "--image-label", "team=ml"}, "--image-label can only be used with --base-image"},
+		{"image label without value", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--image-label", "team"}, `invalid --image-label "team", expected KEY=VALUE`},
+		{"image tag without build", []string{"--image", "busybox", "--image-tag", "v1"}, "can only be used when an image is built"},
+		{"image tag with git strategy", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--image-tag", "v1", "--image-tag-strategy", "git-sha"}, "--image-tag can only be used with --image-tag-strategy user"},
+		{"user strategy without tag", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--image-tag-strategy", "user"}, "--image-tag-strategy user needs an --image-tag"},
+		{"unknown tag strategy", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--image-tag-strategy", "latest"}, `invalid value "latest" for --image-tag-strategy`},
+		{"reserved image tag", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--image-tag", "ctx-1"}, "reserved for the build cache"},
+		{"platform list with remote backend", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--platform", "linux/amd64,linux/arm64", "--build-backend", "remote"}, "only --build-backend crane supports"},
+		{"registry credentials without key mode", []string{"--image", "busybox", "--registry-credentials", "key.json"}, "--registry-credentials can only be used with --registry-auth json-key or workload-identity"},
+		{"access token without token", []string{"--image", "busybox", "--registry-auth", "access-token"}, "reads the access token from GCLUSTER_REGISTRY_TOKEN"},
+		{"platform list with requirements", []string{"--base-image", "python:3.11-slim", "--build-context", ".", "--platform", "linux/amd64,linux/arm64", "--requirements", reqs}, "can only be used with a single --platform"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resetSubmitCmdFlags()
+
+			oldStore := store
+			defer func() { store = oldStore }()
+			store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now()}}
+
+			args := append([]string{
+				"submit",
+				"--name", "image-options-test",
+				"--command", "echo hello",
+				"--cluster", "test-cluster",
+				"--location", "us-central1-a",
+				"--project", "test-project",
+				"--compute-type", "n2-standard-4",
+			}, tc.args...)
+			output, err := executeCommand(JobCmd, args...)
+			if err == nil {
+				t.Fatalf("expected error %q, got nil", tc.wantErr)
+			}
+			if !strings.Contains(output, tc.wantErr) && !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error message to contain %q, got output: %q, err: %v", tc.wantErr, output, err)
+			}
+		})
+	}
+}
+
+func TestSubmitCmd_MissingRequirementsFile_Fails(t *testing.T) {
+	resetSubmitCmdFlags()
+	t.Setenv("GCLUSTER_IMAGE_REPO", "gcluster")
+	t.Setenv("USER", "tester")
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now()}}
+
+	_, err := executeCommand(JobCmd,
+		"submit",
+		"--name", "requirements-test",
+		"--base-image", "python:3.11-slim",
+		"--build-context", t.TempDir(),
+		"--requirements", filepath.Join(t.TempDir(), "missing.txt"),
+		"--command", "echo hello",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--compute-type", "n2-standard-4",
+	)
+
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected missing requirements file error, got %v", err)
+	}
+}
+
 func TestSubmitCmd_NonReservationModelWithName_Fails(t *testing.T) {
 	resetSubmitCmdFlags()
 
@@ -566,7 +1286,7 @@ func TestSubmitCmd_DryRunMissingDir_Approved(t *testing.T) {
 
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -616,7 +1336,7 @@ func TestSubmitCmd_DryRunMissingDir_Rejected(t *testing.T) {
 
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -732,7 +1452,7 @@ func TestSubmitCmd_ValidEnvVars(t *testing.T) {
 
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -811,7 +1531,7 @@ func TestSubmitCmd_PathwaysEnv_Success(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -855,7 +1575,7 @@ func TestSubmitCmd_PathwaysEnv_InvalidFormat_Fails(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -967,7 +1687,7 @@ func TestSubmitCmd_PathwaysMTCFlags(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -1021,7 +1741,7 @@ func TestSubmitCmd_PathwaysHeadless(t *testing.T) {
 	oldFactory := gkeOrchestratorFactory
 	defer func() { gkeOrchestratorFactory = oldFactory }()
 
-	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
 		return &mockOrchestrator{}
 	}
 
@@ -1047,3 +1767,1261 @@ func TestSubmitCmd_PathwaysHeadless(t *testing.T) {
 		t.Errorf("expected pathways.Headless to be true")
 	}
 }
+
+func TestParseNodeSelectors(t *testing.T) {
+	got, err := parseNodeSelectors(map[string]string{"a": "1"}, []string{"team-pool=research", "example.com/tier=gold"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"a": "1", "team-pool": "research", "example.com/tier": "gold"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNodeSelectors() = %v, want %v", got, want)
+	}
+
+	for _, tc := range []struct {
+		selectors []string
+		wantErr   string
+	}{
+		{[]string{"no-equals"}, "key=value format"},
+		{[]string{"bad key=x"}, "invalid --node-selector label key"},
+		{[]string{"a=2"}, "conflicting node selector values"},
+	} {
+		if _, err := parseNodeSelectors(map[string]string{"a": "1"}, tc.selectors); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("parseNodeSelectors(%v) error = %v, want %q", tc.selectors, err, tc.wantErr)
+		}
+	}
+}
+
+func TestParseTolerations(t *testing.T) {
+	got, err := parseTolerations([]string{"dedicated=ml:noschedule", "nvidia.com/gpu", "example.com/maintenance:NoExecute"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []orchestrator.Toleration{
+		{Key: "dedicated", Value: "ml", Effect: "NoSchedule"},
+		{Key: "nvidia.com/gpu"},
+		{Key: "example.com/maintenance", Effect: "NoExecute"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTolerations() = %+v, want %+v", got, want)
+	}
+
+	for _, value := range []string{"", "bad key=x", "dedicated=not ok", "dedicated=ml:Never"} {
+		if _, err := parseTolerations([]string{value}); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestParseSidecars(t *testing.T) {
+	got, err := parseSidecars([]string{
+		"image=tensorflow/tensorflow:2.16.1,name=tensorboard,command=tensorboard --logdir /data/logs --bind_all",
+		"image=prom/node-exporter,command=node_exporter --collector.disable-defaults --collector.cpu,meminfo",
+		"image=busybox",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []orchestrator.ContainerSpec{
+		{Name: "tensorboard", Image: "tensorflow/tensorflow:2.16.1", Command: "tensorboard --logdir /data/logs --bind_all"},
+		{Name: "sidecar-2", Image: "prom/node-exporter", Command: "node_exporter --collector.disable-defaults --collector.cpu,meminfo"},
+		{Name: "sidecar-3", Image: "busybox"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSidecars() = %+v, want %+v", got, want)
+	}
+
+	for _, value := range []string{"", "name=tb", "image=busybox,port=80", "image=busybox,name=Not_Valid", "image=busybox,name=log-archiver", "image=busybox,name=workload-container-1"} {
+		if _, err := parseSidecars([]string{value}); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+	if _, err := parseSidecars([]string{"image=a,name=tb", "image=b,name=tb"}); err == nil {
+		t.Error("expected error for a duplicate sidecar name")
+	}
+}
+
+func TestParseNodeAffinityExprs(t *testing.T) {
+	got, err := parseNodeAffinityExprs([]string{"team-pool in a,b", "gpu-count Gt 4", "spot DoesNotExist"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []orchestrator.NodeAffinityExpression{
+		{Key: "team-pool", Operator: "In", Values: []string{"a", "b"}},
+		{Key: "gpu-count", Operator: "Gt", Values: []string{"4"}},
+		{Key: "spot", Operator: "DoesNotExist"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNodeAffinityExprs() = %+v, want %+v", got, want)
+	}
+
+	for _, expr := range []string{"team-pool", "team-pool Matches a", "team-pool In", "spot Exists yes", "gpu-count Lt many", "team-pool In a,,b"} {
+		if _, err := parseNodeAffinityExprs([]string{expr}); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestParseStageIn(t *testing.T) {
+	got, err := parseStageIn([]string{"gs://datasets/imagenet:/data/imagenet/", "gs://weights:/ckpt"})
+	if err != nil {
+		t.Fatalf("parseStageIn() error = %v", err)
+	}
+	want := []orchestrator.StageIn{
+		{Source: "gs://datasets/imagenet", Dest: "/data/imagenet"},
+		{Source: "gs://weights", Dest: "/ckpt"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStageIn() = %+v, want %+v", got, want)
+	}
+
+	for _, v := range []string{"gs://datasets", "/data/in:/data/out", "gs://:/data", "gs://datasets:data"} {
+		if _, err := parseStageIn([]string{v}); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+func TestParseGCSBuckets(t *testing.T) {
+	got, err := parseGCSBuckets([]string{"datasets:/mnt/gcs", "gs://checkpoints:/ckpt:rw"})
+	if err != nil {
+		t.Fatalf("parseGCSBuckets() error = %v", err)
+	}
+	want := []string{"gs://datasets:/mnt/gcs", "gs://checkpoints:/ckpt:rw"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGCSBuckets() = %v, want %v", got, want)
+	}
+
+	for _, v := range []string{"datasets", ":/mnt/gcs", "datasets/prefix:/mnt/gcs", "datasets:mnt/gcs", "datasets:/mnt/gcs:rx"} {
+		if _, err := parseGCSBuckets([]string{v}); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+func TestParseNFSVolume(t *testing.T) {
+	got, err := parseNFSVolume("10.0.0.2", "/share1", "/mnt/nfs")
+	if err != nil {
+		t.Fatalf("parseNFSVolume() error = %v", err)
+	}
+	want := []orchestrator.SharedVolume{{Server: "10.0.0.2", Path: "/share1", MountPath: "/mnt/nfs"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNFSVolume() = %v, want %v", got, want)
+	}
+
+	if got, err := parseNFSVolume("", "", "/mnt/nfs"); err != nil || got != nil {
+		t.Errorf("parseNFSVolume() without flags = %v, %v; want nil, nil", got, err)
+	}
+	for _, args := range [][3]string{
+		{"10.0.0.2", "", "/mnt/nfs"},
+		{"", "/share1", "/mnt/nfs"},
+		{"10.0.0.2", "share1", "/mnt/nfs"},
+		{"10.0.0.2", "/share1", "mnt/nfs"},
+	} {
+		if _, err := parseNFSVolume(args[0], args[1], args[2]); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestParseResourceQuantity(t *testing.T) {
+	for value, want := range map[string]string{
+		"":      "",
+		"8":     "8",
+		"500m":  "500m",
+		"0.5":   "500m",
+		"64Gi":  "64Gi",
+		"1024M": "1024M",
+	} {
+		got, err := parseResourceQuantity(value, "--cpu")
+		if err != nil || got != want {
+			t.Errorf("parseResourceQuantity(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"eight", "64GB", "0", "-1"} {
+		if _, err := parseResourceQuantity(value, "--memory"); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestSubmitCmd_ResourceOverrides(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "resources-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "nvidia-l4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--cpu", "0.5",
+		"--memory", "16Gi",
+		"--gpus-per-pod", "1",
+		"--memory-request", "8Gi",
+		"--dry-run",
+	}
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.CPU != "500m" || got.Memory != "16Gi" || got.GPUsPerPod != 1 {
+		t.Errorf("expected CPU 500m, memory 16Gi and 1 GPU, got %q, %q and %d", got.CPU, got.Memory, got.GPUsPerPod)
+	}
+	if got.MemoryRequest != "8Gi" || got.CPURequest != "" {
+		t.Errorf("expected a memory request of 8Gi only, got %q and %q", got.MemoryRequest, got.CPURequest)
+	}
+
+	resetSubmitCmdFlags()
+	_, err := executeCommand(JobCmd, append(args, "--cpu-request", "1")...)
+	if err == nil || !strings.Contains(err.Error(), "--cpu-request 1 is more than --cpu 500m") {
+		t.Errorf("expected a request above the limit to fail, got %v", err)
+	}
+
+	resetSubmitCmdFlags()
+	_, err = executeCommand(JobCmd, append(args, "--memory", "lots")...)
+	if err == nil || !strings.Contains(err.Error(), `invalid --memory "lots"`) {
+		t.Errorf("expected an invalid --memory error, got %v", err)
+	}
+}
+
+func TestSubmitCmd_ComputeTypeFallbacks(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "fallback-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "nvidia-h100-80gb,nvidia-a100-80gb,nvidia-l4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--dry-run",
+	}
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.ComputeType != "nvidia-h100-80gb" || !reflect.DeepEqual(got.ComputeTypeFallbacks, []string{"nvidia-a100-80gb", "nvidia-l4"}) {
+		t.Errorf("expected nvidia-h100-80gb with two fallbacks, got %q and %v", got.ComputeType, got.ComputeTypeFallbacks)
+	}
+
+	// Without --compute-type, the orchestrator discovers it from the cluster.
+	var withoutComputeType []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--compute-type" {
+			i++
+			continue
+		}
+		withoutComputeType = append(withoutComputeType, args[i])
+	}
+	SubmitCmd.Flags().Lookup("compute-type").Changed = false
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, withoutComputeType...); err != nil {
+		t.Fatalf("submit without --compute-type failed: %v", err)
+	}
+	if got.ComputeType != "" || got.ComputeTypeFallbacks != nil {
+		t.Errorf("expected no compute type, got %q and %v", got.ComputeType, got.ComputeTypeFallbacks)
+	}
+}
+
+func TestSubmitCmd_InvalidUTF8Command(t *testing.T) {
+	resetSubmitCmdFlags()
+	_, err := executeCommand(JobCmd, "submit",
+		"--name", "utf8-test",
+		"--image", "busybox",
+		"--command", "echo \xff",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--dry-run",
+	)
+	if err == nil || !strings.Contains(err.Error(), "must be valid UTF-8") {
+		t.Errorf("expected a UTF-8 error, got %v", err)
+	}
+}
+
+func TestSubmitCmd_GPUMemory(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+	defer func() {
+		SubmitCmd.Flags().Lookup("compute-type").Changed = false
+		SubmitCmd.Flags().Lookup("gpu-memory").Changed = false
+	}()
+
+	args := []string{
+		"submit",
+		"--name", "gpu-memory-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--gpu-memory", "80GB",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--dry-run",
+	}
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.MinGPUMemoryGB != 80 || got.ComputeType != "" {
+		t.Errorf("expected 80 GB of GPU memory and no compute type, got %d and %q", got.MinGPUMemoryGB, got.ComputeType)
+	}
+
+	for wantErr, extra := range map[string][]string{
+		"--gpu-memory cannot be used with --compute-type":  {"--compute-type", "nvidia-l4"},
+		`invalid --gpu-memory: "80MB" is not a size in GB`: {"--gpu-memory", "80MB"},
+	} {
+		resetSubmitCmdFlags()
+		_, err := executeCommand(JobCmd, append(slices.Clone(args), extra...)...)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("expected error %q, got %v", wantErr, err)
+		}
+	}
+}
+
+func TestSubmitCmd_TPUTopologyAlias(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	// TPU jobs reject --num-nodes, which earlier tests leave marked as changed.
+	clearChanged := func() { SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false }) }
+	defer clearChanged()
+	clearChanged()
+
+	resetSubmitCmdFlags()
+	_, err := executeCommand(JobCmd, "submit",
+		"--name", "tpu-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "tpu-v4-podslice",
+		"--tpu-topology", "4x4x4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--dry-run",
+	)
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.Topology != "4x4x4" {
+		t.Errorf("expected --tpu-topology to set the topology, got %q", got.Topology)
+	}
+}
+
+func TestSubmitCmd_SignKey(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	key := "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+	args := []string{
+		"submit",
+		"--name", "signed-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--dry-run",
+	}
+	resetSubmitCmdFlags()
+	if _, err := executeCommand(JobCmd, append(args, "--sign-key", key)...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.SigningKey != key {
+		t.Errorf("expected signing key %q, got %q", key, got.SigningKey)
+	}
+
+	resetSubmitCmdFlags()
+	_, err := executeCommand(JobCmd, append(args, "--sign-key", "projects/p/keyRings/r")...)
+	if err == nil || !strings.Contains(err.Error(), "invalid --sign-key") {
+		t.Errorf("expected an invalid --sign-key error, got %v", err)
+	}
+}
+
+func TestSubmitCmd_NameTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USER", "bob")
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name-template", "{{.User}}-{{.Experiment}}-r{{.Seq}}",
+		"--experiment", "sweep",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+	}
+	for _, want := range []string{"bob-sweep-r1", "bob-sweep-r2"} {
+		resetSubmitCmdFlags()
+		SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		if _, err := executeCommand(JobCmd, args...); err != nil {
+			t.Fatalf("submit failed: %v", err)
+		}
+		if got.WorkloadName != want {
+			t.Errorf("expected workload name %q, got %q", want, got.WorkloadName)
+		}
+	}
+
+	// --name takes precedence over the template.
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	if _, err := executeCommand(JobCmd, append(args, "--name", "explicit")...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.WorkloadName != "explicit" {
+		t.Errorf("expected workload name explicit, got %q", got.WorkloadName)
+	}
+
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	_, err := executeCommand(JobCmd, append([]string{"submit"}, args[3:]...)...)
+	if err == nil || !strings.Contains(err.Error(), `required flag(s) "name" not set`) {
+		t.Errorf("expected a missing name error, got %v", err)
+	}
+}
+
+func TestSubmitCmd_Snippet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "snippet-job",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--snippet", "print(42)",
+	}
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	if _, err := executeCommand(JobCmd, append(args, "--base-docker-image", "python:3.11")...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.ImageName != "python:3.11" || got.BaseImage != "" || got.BuildContext != "" {
+		t.Errorf("expected the base image to run as is, got image %q, base image %q, build context %q", got.ImageName, got.BaseImage, got.BuildContext)
+	}
+	if got.Snippet != "print(42)" || got.SnippetLanguage != orchestrator.SnippetLanguagePython {
+		t.Errorf("unexpected snippet %q in %q", got.Snippet, got.SnippetLanguage)
+	}
+
+	for wantErr, extra := range map[string][]string{
+		"cannot be used with --command": {"--image", "busybox", "--command", "echo hi"},
+		"without an image build":        {"--base-image", "python:3.11", "--build-context", "."},
+		"Allowed values: python, bash":  {"--image", "busybox", "--snippet-lang", "ruby"},
+	} {
+		resetSubmitCmdFlags()
+		SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		_, err := executeCommand(JobCmd, append(args, extra...)...)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("with %v: expected an error containing %q, got %v", extra, wantErr, err)
+		}
+	}
+}
+
+func TestSubmitCmd_Dockerfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GCLUSTER_IMAGE_REPO", "gcluster")
+	t.Setenv("USER", "tester")
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	dir := t.TempDir()
+	dockerfilePath := filepath.Join(dir, "docker", "Dockerfile")
+	if err := os.MkdirAll(filepath.Dir(dockerfilePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dockerfilePath, []byte("FROM python:3.11\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{
+		"submit",
+		"--name", "dockerfile-job",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--command", "python train.py",
+		"--dockerfile", dockerfilePath,
+	}
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	if _, err := executeCommand(JobCmd, args...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.Dockerfile != dockerfilePath || got.BuildContext != filepath.Dir(dockerfilePath) {
+		t.Errorf("expected the build context to default to the directory of the Dockerfile, got Dockerfile %q, build context %q", got.Dockerfile, got.BuildContext)
+	}
+
+	for wantErr, extra := range map[string][]string{
+		"cannot be used with --image or --base-image": {"--image", "busybox"},
+		"must be inside the build context":            {"--build-context", filepath.Join(dir, "docker", "src")},
+		"not found":                                   {"--dockerfile", filepath.Join(dir, "missing")},
+	} {
+		resetSubmitCmdFlags()
+		SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		_, err := executeCommand(JobCmd, append(args, extra...)...)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("with %v: expected an error containing %q, got %v", extra, wantErr, err)
+		}
+	}
+}
+
+func TestSubmitCmd_SecretEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "secret-job",
+		"--image", "busybox",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--command", "python train.py",
+		"--secret-env", "WANDB_API_KEY=sm://projects/p/secrets/wandb/versions/latest",
+	}
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	if _, err := executeCommand(JobCmd, append(args, "--secret-env-mode", "CSI")...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.SecretEnv["WANDB_API_KEY"] != "sm://projects/p/secrets/wandb/versions/latest" || got.SecretEnvMode != orchestrator.SecretEnvModeCSI {
+		t.Errorf("unexpected secret env %v, mode %q", got.SecretEnv, got.SecretEnvMode)
+	}
+
+	for wantErr, extra := range map[string][]string{
+		"set by both --env and --secret-env": {"--env", "WANDB_API_KEY=plain"},
+		"must start with sm://":              {"--secret-env", "HF_TOKEN=projects/p/secrets/hf"},
+		"invalid environment variable name":  {"--secret-env", "1TOKEN=sm://hf"},
+		"invalid value \"vault\"":            {"--secret-env-mode", "vault"},
+	} {
+		resetSubmitCmdFlags()
+		SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		_, err := executeCommand(JobCmd, append(args, extra...)...)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("with %v: expected an error containing %q, got %v", extra, wantErr, err)
+		}
+	}
+}
+
+func TestSubmitCmd_CommandScript(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/bash\nset -euo pipefail\ntorchrun --nproc-per-node=8 train.py \"$@\"\n"
+	scriptPath := filepath.Join(dir, "launch.sh")
+	emptyPath := filepath.Join(dir, "empty.sh")
+	for path, content := range map[string]string{scriptPath: script, emptyPath: "\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args := []string{
+		"submit",
+		"--name", "script-job",
+		"--image", "busybox",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+	}
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	if _, err := executeCommand(JobCmd, append(slices.Clone(args), "--command-script", scriptPath)...); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.CommandScript != script || got.CommandScriptName != "launch.sh" || got.CommandToRun != "" {
+		t.Errorf("unexpected command script %q named %q, command %q", got.CommandScript, got.CommandScriptName, got.CommandToRun)
+	}
+
+	for wantErr, extra := range map[string][]string{
+		"cannot be used with --command":   {"--command-script", scriptPath, "--command", "echo hi"},
+		"failed to read --command-script": {"--command-script", filepath.Join(dir, "missing.sh")},
+		"is empty":                        {"--command-script", emptyPath},
+	} {
+		resetSubmitCmdFlags()
+		SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		_, err := executeCommand(JobCmd, append(slices.Clone(args), extra...)...)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("with %v: expected an error containing %q, got %v", extra, wantErr, err)
+		}
+	}
+}
+
+func TestSubmitCmd_Impersonate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+	defer func() { impersonate = "" }()
+
+	var got orchestrator.JobDefinition
+	rec := &recordingOrchestrator{job: &got}
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return rec }
+
+	args := []string{
+		"submit",
+		"--name", "audit-job",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--compute-type", "n2-standard-4",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+	}
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	output, err := executeCommand(JobCmd, append(args, "--as", "alice@example.com")...)
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if rec.impersonated != "alice@example.com" {
+		t.Errorf("expected the orchestrator to impersonate alice@example.com, got %q", rec.impersonated)
+	}
+	if want := "alice@example.com is allowed to submit workload audit-job to cluster test-cluster. Nothing was created."; !strings.Contains(output, want) {
+		t.Errorf("expected %q in the output, got %s", want, output)
+	}
+
+	resetSubmitCmdFlags()
+	SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	if _, err := executeCommand(JobCmd, append(args, "--as", "alice")...); err == nil || !strings.Contains(err.Error(), `invalid --as "alice"`) {
+		t.Errorf("expected an invalid principal error, got %v", err)
+	}
+}
+
+func TestParseComputeTypes(t *testing.T) {
+	for value, want := range map[string][]string{
+		"nvidia-l4":                             {"nvidia-l4"},
+		"nvidia-h100-80gb,nvidia-a100-80gb":     {"nvidia-h100-80gb", "nvidia-a100-80gb"},
+		"v6e-8, v5p-8":                          {"v6e-8", "v5p-8"},
+		"h100-80gb-8,a100-80gb-8,n2-standard-8": {"h100-80gb-8", "a100-80gb-8", "n2-standard-8"},
+	} {
+		computeType, fallbacks, err := parseComputeTypes(value)
+		if err != nil {
+			t.Errorf("parseComputeTypes(%q) error = %v", value, err)
+			continue
+		}
+		if got := append([]string{computeType}, fallbacks...); !reflect.DeepEqual(got, want) {
+			t.Errorf("parseComputeTypes(%q) = %v, want %v", value, got, want)
+		}
+	}
+	for value, wantErr := range map[string]string{
+		"nvidia-l4,":          "empty compute type",
+		"nvidia-l4,nvidia-l4": "nvidia-l4 is listed more than once",
+		"v6e-8,nvidia-l4":     "TPU and non-TPU compute types cannot be mixed",
+	} {
+		if _, _, err := parseComputeTypes(value); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseComputeTypes(%q) error = %v, want %q", value, err, wantErr)
+		}
+	}
+}
+
+func TestParseStartupProbe(t *testing.T) {
+	for _, tc := range []struct {
+		probe, timeout string
+		want           *orchestrator.StartupProbe
+	}{
+		{"", "", nil},
+		{"http:8080/healthz", "20m", &orchestrator.StartupProbe{Kind: "http", Port: 8080, Path: "/healthz", Timeout: 20 * time.Minute}},
+		{"http:8080", "", &orchestrator.StartupProbe{Kind: "http", Port: 8080, Path: "/", Timeout: defaultStartupTimeout}},
+		{"tcp:9000", "90s", &orchestrator.StartupProbe{Kind: "tcp", Port: 9000, Timeout: 90 * time.Second}},
+		{"exec:test -f /tmp/ready", "", &orchestrator.StartupProbe{Kind: "exec", Command: "test -f /tmp/ready", Timeout: defaultStartupTimeout}},
+	} {
+		got, err := parseStartupProbe(tc.probe, tc.timeout)
+		if err != nil {
+			t.Errorf("parseStartupProbe(%q, %q) error = %v", tc.probe, tc.timeout, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseStartupProbe(%q, %q) = %+v, want %+v", tc.probe, tc.timeout, got, tc.want)
+		}
+	}
+
+	for _, args := range [][2]string{
+		{"", "20m"},
+		{"http:8080", "soon"},
+		{"http:8080", "-1m"},
+		{"http:port", ""},
+		{"tcp:70000", ""},
+		{"tcp:8080/path", ""},
+		{"exec:", ""},
+		{"grpc:8080", ""},
+	} {
+		if _, err := parseStartupProbe(args[0], args[1]); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestParseStepTimeouts(t *testing.T) {
+	got, err := parseStepTimeouts([3]string{"2m", "", "90s"})
+	if err != nil {
+		t.Fatalf("parseStepTimeouts() error = %v", err)
+	}
+	if want := (orchestrator.StepTimeouts{Credentials: 2 * time.Minute, Apply: 90 * time.Second}); got != want {
+		t.Errorf("parseStepTimeouts() = %+v, want %+v", got, want)
+	}
+
+	for values, wantErr := range map[[3]string]string{
+		{"soon", "", ""}: "invalid --credentials-timeout",
+		{"", "0s", ""}:   "invalid --build-timeout",
+		{"", "", "-5m"}:  "invalid --apply-timeout",
+	} {
+		if _, err := parseStepTimeouts(values); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseStepTimeouts(%q) error = %v, want %q", values, err, wantErr)
+		}
+	}
+}
+
+func TestParseLogArchive(t *testing.T) {
+	for dest, want := range map[string]*orchestrator.LogArchive{
+		"":                              nil,
+		"gs://my-logs":                  {Kind: orchestrator.LogArchiveGCS, Bucket: "my-logs"},
+		"gs://my-logs/runs/":            {Kind: orchestrator.LogArchiveGCS, Bucket: "my-logs", Prefix: "runs"},
+		"logging://archive":             {Kind: orchestrator.LogArchiveLogging, Bucket: "archive", Location: "global"},
+		"logging://us-central1/archive": {Kind: orchestrator.LogArchiveLogging, Bucket: "archive", Location: "us-central1"},
+	} {
+		got, err := parseLogArchive(dest)
+		if err != nil {
+			t.Errorf("parseLogArchive(%q) error = %v", dest, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parseLogArchive(%q) = %+v, want %+v", dest, got, want)
+		}
+	}
+
+	for _, dest := range []string{"gs://", "my-logs", "s3://my-logs", "logging://", "logging://us/a/b", "logging:///archive"} {
+		if _, err := parseLogArchive(dest); err == nil {
+			t.Errorf("expected error for %q", dest)
+		}
+	}
+}
+
+func TestParseCommandJSON(t *testing.T) {
+	got, err := parseCommandJSON(`["python","train.py","--msg","a b"]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"python", "train.py", "--msg", "a b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCommandJSON() = %q, want %q", got, want)
+	}
+
+	for _, raw := range []string{`python train.py`, `[]`, `["", "x"]`, `["python", 1]`, `{"cmd": "python"}`} {
+		if _, err := parseCommandJSON(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestSubmitCmd_CommandAndCommandJSON_Fails(t *testing.T) {
+	resetSubmitCmdFlags()
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now()}}
+
+	_, err := executeCommand(JobCmd,
+		"submit",
+		"--name", "command-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--command-json", `["echo","hello"]`,
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--compute-type", "n2-standard-4",
+	)
+
+	if err == nil || !strings.Contains(err.Error(), "--command and --command-json cannot be used together") {
+		t.Fatalf("expected mutually exclusive command error, got %v", err)
+	}
+}
+
+func TestSubmitCmd_WorkloadKindValidation(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now()}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "unknown kind", args: []string{"--workload-kind", "statefulset", "--dry-run-out", "out.yaml"}, wantErr: "invalid --workload-kind"},
+		{name: "rayjob without dry run", args: []string{"--workload-kind", "rayjob"}, wantErr: "requires --dry-run-out"},
+		{name: "multi-node job", args: []string{"--workload-kind", "job", "--num-nodes", "2"}, wantErr: "runs on a single node"},
+		{name: "job awaiting completion", args: []string{"--workload-kind", "job", "--await-job-completion"}, wantErr: "are not supported with --workload-kind job"},
+		{name: "deployment with slices", args: []string{"--workload-kind", "deployment", "--dry-run-out", "out.yaml", "--num-slices", "2"}, wantErr: "does not support --num-slices"},
+		{name: "rayjob with pathways", args: []string{"--workload-kind", "rayjob", "--dry-run-out", "out.yaml", "--pathways", "--pathways-gcs-location", "gs://bucket"}, wantErr: "cannot be used with --pathways"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resetSubmitCmdFlags()
+			args := append([]string{"submit",
+				"--name", "kind-test",
+				"--image", "busybox",
+				"--command", "echo hello",
+				"--cluster", "test-cluster",
+				"--location", "us-central1-a",
+				"--project", "test-project",
+			}, tc.args...)
+			_, err := executeCommand(JobCmd, args...)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSubmitCmd_RestartPolicy(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	submit := func(extra ...string) error {
+		resetSubmitCmdFlags()
+		args := append([]string{"submit",
+			"--name", "restart-test",
+			"--image", "busybox",
+			"--command", "echo hello",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+		}, extra...)
+		_, err := executeCommand(JobCmd, args...)
+		return err
+	}
+
+	if err := submit("--restart-policy", "onfailure", "--pod-backoff-limit", "4"); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.RestartPolicy != orchestrator.RestartPolicyOnFailure || got.PodBackoffLimit != 4 {
+		t.Errorf("job = {RestartPolicy: %q, PodBackoffLimit: %d}, want {OnFailure, 4}", got.RestartPolicy, got.PodBackoffLimit)
+	}
+
+	for _, tc := range []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--restart-policy", "Always"}, "invalid --restart-policy"},
+		{[]string{"--pod-backoff-limit", "-1"}, "must not be negative"},
+		{[]string{"--restart-policy", "OnFailure"}, "requires a positive --pod-backoff-limit"},
+		{[]string{"--restart-policy", "OnFailure", "--pod-backoff-limit", "2", "--restart-on-exit-codes", "42"}, "--restart-on-exit-codes requires --restart-policy Never"},
+		{[]string{"--pod-backoff-limit", "2", "--workload-kind", "job"}, "cannot be used with --workload-kind job"},
+		{[]string{"--pod-backoff-limit", "2", "--workload-kind", "deployment", "--dry-run-out", "out.yaml"}, "cannot be used with --workload-kind deployment"},
+	} {
+		if err := submit(tc.args...); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("submit %v: expected error containing %q, got %v", tc.args, tc.wantErr, err)
+		}
+	}
+}
+
+func TestSubmitCmd_Spot(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	submit := func(extra ...string) error {
+		resetSubmitCmdFlags()
+		SubmitCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+		args := append([]string{"submit",
+			"--name", "spot-test",
+			"--image", "busybox",
+			"--command", "echo hello",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+			"--spot",
+		}, extra...)
+		_, err := executeCommand(JobCmd, args...)
+		return err
+	}
+
+	if err := submit(); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if !got.Spot || got.MaxRestarts != spotDefaultRestarts {
+		t.Errorf("job = {Spot: %v, MaxRestarts: %d}, want {true, %d}", got.Spot, got.MaxRestarts, spotDefaultRestarts)
+	}
+
+	if err := submit("--restarts", "2"); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.MaxRestarts != 2 {
+		t.Errorf("expected an explicit --restarts to be kept, got %d", got.MaxRestarts)
+	}
+
+	if err := submit("--gke-nap-provisioning", "on-demand"); err == nil || !strings.Contains(err.Error(), "--spot cannot be used with --gke-nap-provisioning=on-demand") {
+		t.Errorf("expected --spot and on-demand provisioning to conflict, got %v", err)
+	}
+}
+
+func TestSubmitCmd_DNSHostnames(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	submit := func(extra ...string) error {
+		resetSubmitCmdFlags()
+		args := append([]string{"submit",
+			"--name", "dns-test",
+			"--image", "busybox",
+			"--command", "echo hello",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+			"--dns-hostnames",
+		}, extra...)
+		_, err := executeCommand(JobCmd, args...)
+		return err
+	}
+
+	if err := submit(); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if !got.DNSHostnames {
+		t.Error("expected DNSHostnames to be set")
+	}
+	if err := submit("--workload-kind", "job"); err == nil || !strings.Contains(err.Error(), "--dns-hostnames requires --workload-kind jobset") {
+		t.Errorf("expected --dns-hostnames to be rejected for a job, got %v", err)
+	}
+}
+
+func TestSubmitCmd_Framework(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{State: PrereqState{LastCheckedTimestamp: time.Now(), LastCheckedProjectID: "test-project"}}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	submit := func(extra ...string) error {
+		resetSubmitCmdFlags()
+		args := append([]string{"submit",
+			"--name", "ddp-test",
+			"--image", "busybox",
+			"--command", "echo hello",
+			"--cluster", "test-cluster",
+			"--location", "us-central1-a",
+			"--project", "test-project",
+		}, extra...)
+		_, err := executeCommand(JobCmd, args...)
+		return err
+	}
+
+	if err := submit("--framework", "pytorch"); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if got.Framework != orchestrator.FrameworkPyTorch {
+		t.Errorf("expected framework pytorch, got %q", got.Framework)
+	}
+	if err := submit("--framework", "tensorflow"); err == nil || !strings.Contains(err.Error(), "invalid --framework") {
+		t.Errorf("expected an unknown framework to be rejected, got %v", err)
+	}
+	if err := submit("--framework", "jax", "--workload-kind", "job"); err == nil || !strings.Contains(err.Error(), "--framework requires --workload-kind jobset") {
+		t.Errorf("expected --framework to be rejected for a job, got %v", err)
+	}
+}
+
+type recordingOrchestrator struct {
+	mockOrchestrator
+	job          *orchestrator.JobDefinition
+	onSubmit     func(orchestrator.JobDefinition)
+	impersonated string
+}
+
+func (m *recordingOrchestrator) Impersonate(principal string) {
+	m.impersonated = principal
+}
+
+func (m *recordingOrchestrator) SubmitJob(_ context.Context, job orchestrator.JobDefinition) (orchestrator.SubmitResult, error) {
+	*m.job = job
+	if m.onSubmit != nil {
+		m.onSubmit(job)
+	}
+	return orchestrator.SubmitResult{WorkloadName: job.WorkloadName}, nil
+}
+
+func TestSubmitCmd_WorkloadKindDryRun(t *testing.T) {
+	resetSubmitCmdFlags()
+
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{
+		State: PrereqState{
+			LastCheckedTimestamp:         time.Now(),
+			LastCheckedProjectID:         "test-project",
+			GCloudSDKInstalled:           true,
+			GCloudAuthenticated:          true,
+			ADCConfigured:                true,
+			KubectlInstalled:             true,
+			GKEGCloudAuthPluginInstalled: true,
+			DockerCredsConfigured:        true,
+		},
+	}
+
+	var got orchestrator.JobDefinition
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		return &recordingOrchestrator{job: &got}
+	}
+
+	outPath := filepath.Join(t.TempDir(), "rayjob.yaml")
+	output, err := executeCommand(JobCmd,
+		"submit",
+		"--name", "ray-test",
+		"--image", "busybox",
+		"--command", "python train.py",
+		"--cluster", "test-cluster",
+		"--location", "us-central1-a",
+		"--project", "test-project",
+		"--compute-type", "n2-standard-4",
+		"--workload-kind", "rayjob",
+		"--num-slices", "2",
+		"--dry-run-out", outPath,
+	)
+	if err != nil {
+		if strings.Contains(output, "gcloud not found") {
+			t.Skip("gcloud is not installed")
+		}
+		t.Fatalf("submit failed: %v, output: %s", err, output)
+	}
+	if got.WorkloadKind != orchestrator.WorkloadKindRayJob || got.DryRunManifest != outPath {
+		t.Errorf("job = {WorkloadKind: %q, DryRunManifest: %q}, want rayjob written to %s", got.WorkloadKind, got.DryRunManifest, outPath)
+	}
+}
+
+type hostedSubmitOrchestrator struct {
+	orchestrator.JobOrchestrator
+	job orchestrator.JobDefinition
+}
+
+func (m *hostedSubmitOrchestrator) SubmitJob(_ context.Context, job orchestrator.JobDefinition) (orchestrator.SubmitResult, error) {
+	m.job = job
+	return orchestrator.SubmitResult{
+		WorkloadName: job.WorkloadName,
+		Location:     "us-central1",
+		ProjectID:    job.ClusterProjectID,
+		RunID:        "0123456789ab",
+		WorkloadURL:  "https://console.cloud.google.com/vertex-ai/locations/us-central1/training/456/cpu?project=test-project",
+	}, nil
+}
+
+func TestSubmitCmd_VertexBackend(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{
+		State: PrereqState{
+			LastCheckedTimestamp: time.Now(),
+			LastCheckedProjectID: "test-project",
+			GCloudSDKInstalled:   true,
+			GCloudAuthenticated:  true,
+			ADCConfigured:        true,
+		},
+	}
+
+	oldGKE, oldVertex := gkeOrchestratorFactory, vertexOrchestratorFactory
+	defer func() { gkeOrchestratorFactory, vertexOrchestratorFactory = oldGKE, oldVertex }()
+	gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator {
+		t.Error("expected the vertex backend not to use the GKE orchestrator")
+		return &mockOrchestrator{}
+	}
+	mock := &hostedSubmitOrchestrator{}
+	vertexOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	output, err := executeCommand(JobCmd,
+		"submit",
+		"--backend", "vertex",
+		"--name", "vertex-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--location", "us-central1",
+		"--project", "test-project",
+		"--compute-type", "l4-1",
+	)
+	if err != nil {
+		t.Fatalf("command failed with error: %v, output: %s", err, output)
+	}
+	if mock.job.WorkloadName != "vertex-test" || mock.job.ComputeType != "l4-1" {
+		t.Errorf("unexpected job submitted: %+v", mock.job)
+	}
+	if !strings.Contains(output, "Location:   us-central1 (project test-project)") || !strings.Contains(output, "/vertex-ai/locations/us-central1/training/456/") {
+		t.Errorf("expected the Vertex AI location and link in the summary, got:\n%s", output)
+	}
+	if strings.Contains(output, "gcluster job status") {
+		t.Errorf("expected no GKE follow-up commands, got:\n%s", output)
+	}
+}
+
+func TestSubmitCmd_BatchBackend(t *testing.T) {
+	oldStore := store
+	defer func() { store = oldStore }()
+	store = &MockPrereqStore{}
+
+	oldBatch := batchOrchestratorFactory
+	defer func() { batchOrchestratorFactory = oldBatch }()
+	mock := &hostedSubmitOrchestrator{}
+	batchOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	output, err := executeCommand(JobCmd,
+		"submit",
+		"--backend", "batch",
+		"--name", "batch-test",
+		"--image", "busybox",
+		"--command", "echo hello",
+		"--location", "us-central1",
+		"--project", "test-project",
+		"--compute-type", "n2-standard-8",
+		"--dry-run-out", filepath.Join(t.TempDir(), "job.json"),
+	)
+	if err != nil {
+		t.Fatalf("command failed with error: %v, output: %s", err, output)
+	}
+	if mock.job.WorkloadName != "batch-test" || !mock.job.IsDryRun() {
+		t.Errorf("expected the dry run to reach the batch orchestrator, got %+v", mock.job)
+	}
+}
+
+func TestSubmitCmd_InvalidBackend(t *testing.T) {
+	resetSubmitCmdFlags()
+	defer resetSubmitCmdFlags()
+
+	_, err := executeCommand(JobCmd, "submit", "--backend", "k8s", "--name", "x", "--image", "busybox", "--command", "true")
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --backend: k8s. Allowed values are: gke, vertex, batch") {
+		t.Errorf("expected an invalid --backend error, got %v", err)
+	}
+}
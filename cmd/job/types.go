@@ -14,11 +14,14 @@
 
 package job
 
-import "time"
+import (
+	"hpc-toolkit/pkg/localstate"
+	"time"
+)
 
 const (
 	contextFileName = "context.json"
-	stateDirName    = ".gcluster"
+	stateDirName    = localstate.DirName
 	stateFileName   = "job_prereq_state.json"
 	stateFreshness  = 24 * time.Hour // State is considered fresh for 24 hours
 )
@@ -33,6 +36,18 @@ type Context struct {
 	ProjectID   string `json:"project_id"`
 	ClusterName string `json:"cluster_name"`
 	Location    string `json:"location"`
+
+	// Cost allocation label defaults applied to submitted workloads.
+	Team       string `json:"team,omitempty"`
+	Experiment string `json:"experiment,omitempty"`
+	User       string `json:"user,omitempty"`
+
+	// NameTemplate renders the names of workloads submitted without --name.
+	NameTemplate string `json:"name_template,omitempty"`
+
+	// Format is the preferred output format of commands and plugins: text,
+	// json or yaml.
+	Format string `json:"format,omitempty"`
 }
 
 // PrereqState holds the current state of prerequisite checks.
@@ -46,5 +61,6 @@ type PrereqState struct {
 	DockerCredsConfigured        bool      `json:"docker_creds_configured"`
 	ArtifactRegistryAPIEnabled   bool      `json:"artifact_registry_api_enabled"`
 	LastCheckedProjectID         string    `json:"last_checked_project_id"`
+	LastCheckedBuildProjectID    string    `json:"last_checked_build_project_id,omitempty"`
 	LastCheckedTimestamp         time.Time `json:"last_checked_timestamp"`
 }
@@ -0,0 +1,56 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"os"
+
+	"hpc-toolkit/pkg/manifestsign"
+
+	"github.com/spf13/cobra"
+)
+
+var VerifyManifestCmd = &cobra.Command{
+	Use:   "verify-manifest [file]",
+	Short: "Verify the signatures of a manifest signed with --sign-key.",
+	Long: `The 'verify-manifest' command checks that every object of a manifest written
+by 'gcluster job submit --sign-key ... --dry-run-out' carries a valid
+gcluster.google.com/signature annotation, made with the Cloud KMS key version
+named by its gcluster.google.com/signing-key annotation. It fails if any
+object is unsigned or was modified after signing.`,
+	Args: cobra.ExactArgs(1),
+	// Verification needs no cluster.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE:         runVerifyManifestCmd,
+	SilenceUsage: true,
+}
+
+func runVerifyManifestCmd(cmd *cobra.Command, args []string) error {
+	manifest, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	objects, err := manifestsign.Verify(manifest)
+	if err != nil {
+		return fmt.Errorf("verification of %s failed: %w", args[0], err)
+	}
+	for _, obj := range objects {
+		fmt.Fprintf(cmd.OutOrStdout(), "Verified %s\n", obj)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyManifestCmd_Unsigned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(path, []byte("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: train\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := executeCommand(JobCmd, "verify-manifest", path)
+	if err == nil || !strings.Contains(err.Error(), "Job/train is not signed") {
+		t.Errorf("expected an unsigned manifest to fail verification, got %v", err)
+	}
+}
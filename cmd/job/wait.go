@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of 'gcluster job wait' besides 0, for a reached condition, and
+// 1, for errors such as a workload that does not exist.
+const (
+	waitExitTimeout   = 2
+	waitExitFailed    = 3
+	waitExitSucceeded = 4
+)
+
+var WaitCmd = &cobra.Command{
+	Use:   "wait [job-name]",
+	Short: "Wait until a job is admitted, running, succeeded or failed.",
+	Long: fmt.Sprintf(`The 'wait' command polls a job until it reaches the condition given with
+--until, so that scripts and CI steps can gate on it:
+
+  admitted   Kueue admitted the job, or it runs without a queue.
+  running    A pod of the job is running.
+  succeeded  The job completed successfully.
+  failed     The job failed.
+
+A job that has succeeded has also been admitted and running. The exit code
+tells why the wait stopped:
+
+  0  The condition was reached.
+  1  The job could not be checked, e.g. it does not exist.
+  %d  --timeout passed first.
+  %d  The job failed before the condition was reached.
+  %d  The job succeeded while --until failed was awaited.`, waitExitTimeout, waitExitFailed, waitExitSucceeded),
+	Args:         cobra.ExactArgs(1),
+	RunE:         runWaitCmd,
+	SilenceUsage: true,
+}
+
+var (
+	waitUntil   string
+	waitTimeout time.Duration
+)
+
+func init() {
+	WaitCmd.Flags().StringVar(&waitUntil, "until", orchestrator.WaitUntilSucceeded, fmt.Sprintf("Condition to wait for (one of %s).", strings.Join(orchestrator.WaitConditions, ", ")))
+	WaitCmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "How long to wait (e.g. 30m) before exiting with code 2. 0 waits without a limit.")
+}
+
+func runWaitCmd(cmd *cobra.Command, args []string) error {
+	jobName := args[0]
+	if !slices.Contains(orchestrator.WaitConditions, waitUntil) {
+		return fmt.Errorf("invalid value for --until: %s. Allowed values are: %s", waitUntil, strings.Join(orchestrator.WaitConditions, ", "))
+	}
+	if waitTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative, got %s", waitTimeout)
+	}
+
+	result, err := orc.WaitJob(cmd.Context(), jobName, orchestrator.WaitOptions{
+		ProjectID:       projectID,
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		Until:           waitUntil,
+		Timeout:         waitTimeout,
+	})
+	switch {
+	case err != nil:
+		return err
+	case result.Reached:
+		fmt.Fprintf(cmd.OutOrStdout(), "Job '%s' is %s.\n", jobName, result.Condition)
+		return nil
+	case result.Condition == orchestrator.WaitUntilFailed:
+		return &exitError{code: waitExitFailed, err: fmt.Errorf("job '%s' failed before it was %s", jobName, waitUntil)}
+	case result.Condition == orchestrator.WaitUntilSucceeded:
+		return &exitError{code: waitExitSucceeded, err: fmt.Errorf("job '%s' succeeded instead of failing", jobName)}
+	default:
+		return &exitError{code: waitExitTimeout, err: fmt.Errorf("timed out after %s waiting for job '%s' to be %s; its status is %s", waitTimeout, jobName, waitUntil, result.Status)}
+	}
+}
+
+// exitError makes gcluster exit with code instead of 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+func (e *exitError) ExitCode() int { return e.code }
@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+type mockWaitOrchestrator struct {
+	orchestrator.JobOrchestrator
+	gotOpts orchestrator.WaitOptions
+	result  orchestrator.WaitResult
+}
+
+func (m *mockWaitOrchestrator) WaitJob(_ context.Context, name string, opts orchestrator.WaitOptions) (orchestrator.WaitResult, error) {
+	m.gotOpts = opts
+	return m.result, nil
+}
+
+func TestWaitCmd(t *testing.T) {
+	defer func() { waitUntil, waitTimeout = orchestrator.WaitUntilSucceeded, 0 }()
+	oldFactory := gkeOrchestratorFactory
+	defer func() { gkeOrchestratorFactory = oldFactory }()
+
+	tests := []struct {
+		name     string
+		until    string
+		result   orchestrator.WaitResult
+		wantCode int
+		wantErr  string
+	}{
+		{name: "reached", until: "running", result: orchestrator.WaitResult{Reached: true, Condition: "succeeded"}},
+		{name: "timeout", until: "admitted", result: orchestrator.WaitResult{Status: "Suspended"}, wantCode: waitExitTimeout, wantErr: "timed out after 30m0s waiting for job 'train' to be admitted; its status is Suspended"},
+		{name: "failed", until: "succeeded", result: orchestrator.WaitResult{Condition: "failed"}, wantCode: waitExitFailed, wantErr: "job 'train' failed before it was succeeded"},
+		{name: "succeeded", until: "failed", result: orchestrator.WaitResult{Condition: "succeeded"}, wantCode: waitExitSucceeded, wantErr: "succeeded instead of failing"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockWaitOrchestrator{result: tc.result}
+			gkeOrchestratorFactory = func(orchestrator.Config) orchestrator.JobOrchestrator { return mock }
+
+			_, err := executeCommand(JobCmd, "wait", "train", "--until", tc.until, "--timeout", "30m", "--cluster", "c", "--location", "us-central1", "--project", "p")
+			if mock.gotOpts.Until != tc.until || mock.gotOpts.Timeout != 30*time.Minute || mock.gotOpts.ClusterName != "c" {
+				t.Errorf("unexpected wait options: %+v", mock.gotOpts)
+			}
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var exitErr *exitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != tc.wantCode || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected exit code %d and %q, got %v", tc.wantCode, tc.wantErr, err)
+			}
+		})
+	}
+
+	_, err := executeCommand(JobCmd, "wait", "train", "--until", "done", "--cluster", "c", "--location", "us-central1", "--project", "p")
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --until: done") {
+		t.Errorf("expected an invalid condition error, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"hpc-toolkit/cmd/job"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/localstate"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix is the executable name prefix that marks a gcluster plugin.
+const pluginPrefix = "gcluster-"
+
+// plugin is an executable discovered on PATH that extends gcluster with a subcommand.
+type plugin struct {
+	Name string // Subcommand name, e.g. "billing" for gcluster-billing.
+	Path string
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+	rootCmd.AddCommand(pluginCmd)
+}
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Inspect gcluster plugins.",
+	Long: `Any executable on PATH named gcluster-<name> is exposed as 'gcluster <name>'.
+Plugins receive the active gcluster context through environment variables:
+
+  GCLUSTER_PROJECT, GCLUSTER_CLUSTER_NAME, GCLUSTER_CLUSTER_LOCATION
+      defaults of 'gcluster job', from the environment or 'gcluster job config set'
+  GCLUSTER_FORMAT     - output format: the --format argument of the plugin, else
+                        the default of 'gcluster job', else "text"
+  GCLUSTER_NO_COLOR   - "true" if colorized output is disabled
+  GCLUSTER_VERSION    - version of the invoking gcluster binary
+  GCLUSTER_BINARY     - path of the invoking gcluster binary
+  GCLUSTER_STATE_DIR  - gcluster's local state directory`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List plugins discovered on PATH.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plugins := discoverPlugins(os.Getenv("PATH"))
+		if len(plugins) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No gcluster plugins found on PATH.")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "COMMAND\tPATH")
+		for _, p := range plugins {
+			fmt.Fprintf(w, "%s\t%s\n", p.Name, p.Path)
+		}
+		return w.Flush()
+	},
+}
+
+// discoverPlugins scans the directories of pathEnv for gcluster plugins. When
+// the same plugin name appears in several directories the first one wins, as
+// it would for normal PATH lookup.
+func discoverPlugins(pathEnv string) []plugin {
+	seen := map[string]bool{}
+	var plugins []plugin
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !strings.HasPrefix(name, pluginPrefix) || e.IsDir() {
+				continue
+			}
+			sub := strings.TrimPrefix(name, pluginPrefix)
+			if sub == "" || seen[sub] {
+				continue
+			}
+			full := filepath.Join(dir, name)
+			if !isExecutable(full) {
+				continue
+			}
+			seen[sub] = true
+			plugins = append(plugins, plugin{Name: sub, Path: full})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return fi.Mode().IsRegular() && fi.Mode().Perm()&0111 != 0
+}
+
+// registerPlugins adds a subcommand for every discovered plugin that does not
+// shadow a built-in command.
+func registerPlugins(root *cobra.Command, plugins []plugin) {
+	builtin := map[string]bool{"help": true, "completion": true}
+	for _, c := range root.Commands() {
+		builtin[c.Name()] = true
+		for _, a := range c.Aliases {
+			builtin[a] = true
+		}
+	}
+
+	for _, p := range plugins {
+		if builtin[p.Name] {
+			continue
+		}
+		root.AddCommand(newPluginCommand(p))
+	}
+}
+
+func newPluginCommand(p plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              fmt.Sprintf("Plugin provided by %s", p.Path),
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		Annotations:        map[string]string{"plugin": p.Path},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := exec.Command(p.Path, args...)
+			c.Stdin = os.Stdin
+			c.Stdout = cmd.OutOrStdout()
+			c.Stderr = cmd.ErrOrStderr()
+			c.Env = append(os.Environ(), pluginEnv(args)...)
+			return c.Run()
+		},
+	}
+}
+
+// pluginEnv returns the context passed to a plugin run with args as
+// environment variables.
+func pluginEnv(args []string) []string {
+	ctx := job.EffectiveContext()
+	env := []string{
+		"GCLUSTER_PROJECT=" + ctx.ProjectID,
+		"GCLUSTER_CLUSTER_NAME=" + ctx.ClusterName,
+		"GCLUSTER_CLUSTER_LOCATION=" + ctx.Location,
+		"GCLUSTER_FORMAT=" + pluginFormat(args, ctx.Format),
+		"GCLUSTER_NO_COLOR=" + strconv.FormatBool(noColorFlag),
+		"GCLUSTER_VERSION=" + config.GetToolkitVersion(),
+	}
+	if exe, err := os.Executable(); err == nil {
+		env = append(env, "GCLUSTER_BINARY="+exe)
+	}
+	if dir, err := localstate.Dir(); err == nil {
+		env = append(env, "GCLUSTER_STATE_DIR="+dir)
+	}
+	return env
+}
+
+// pluginFormat returns the output format requested by a --format argument of
+// a plugin, which gcluster passes on without parsing, or else the format of
+// the context.
+func pluginFormat(args []string, format string) string {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		if v, ok := strings.CutPrefix(a, "--format="); ok {
+			format = v
+		} else if a == "--format" && i+1 < len(args) {
+			format = args[i+1]
+		}
+	}
+	if format == "" {
+		return "text"
+	}
+	return format
+}
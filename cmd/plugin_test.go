@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func writeExecutable(t *testing.T, dir, name, content string, mode os.FileMode) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	first := writeExecutable(t, dir1, "gcluster-billing", "#!/bin/sh\n", 0755)
+	writeExecutable(t, dir2, "gcluster-billing", "#!/bin/sh\n", 0755)
+	writeExecutable(t, dir2, "gcluster-audit", "#!/bin/sh\n", 0755)
+	writeExecutable(t, dir2, "gcluster-notexec", "#!/bin/sh\n", 0644)
+	writeExecutable(t, dir2, "other-tool", "#!/bin/sh\n", 0755)
+
+	plugins := discoverPlugins(strings.Join([]string{dir1, "", "/does/not/exist", dir2}, string(os.PathListSeparator)))
+
+	if len(plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %+v", plugins)
+	}
+	if plugins[0].Name != "audit" || plugins[1].Name != "billing" {
+		t.Errorf("unexpected plugin names: %+v", plugins)
+	}
+	if plugins[1].Path != first {
+		t.Errorf("expected first PATH entry to win, got %s", plugins[1].Path)
+	}
+}
+
+func TestRegisterPlugins_SkipsBuiltins(t *testing.T) {
+	root := &cobra.Command{Use: "gcluster"}
+	root.AddCommand(&cobra.Command{Use: "deploy", Aliases: []string{"apply"}, Run: func(*cobra.Command, []string) {}})
+
+	registerPlugins(root, []plugin{
+		{Name: "deploy", Path: "/bin/gcluster-deploy"},
+		{Name: "apply", Path: "/bin/gcluster-apply"},
+		{Name: "help", Path: "/bin/gcluster-help"},
+		{Name: "billing", Path: "/bin/gcluster-billing"},
+	})
+
+	var names []string
+	for _, c := range root.Commands() {
+		names = append(names, c.Name())
+	}
+	if strings.Join(names, ",") != "billing,deploy" {
+		t.Errorf("unexpected commands after registration: %v", names)
+	}
+}
+
+func TestPluginCommand_PassesArgsAndContext(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GCLUSTER_CLUSTER_NAME", "env-cluster")
+	dir := t.TempDir()
+	p := writeExecutable(t, dir, "gcluster-echo", "#!/bin/sh\necho \"args=$*\"\necho \"cluster=$GCLUSTER_CLUSTER_NAME\"\necho \"version=$GCLUSTER_VERSION\"\necho \"state=$GCLUSTER_STATE_DIR\"\n", 0755)
+
+	c := newPluginCommand(plugin{Name: "echo", Path: p})
+	var out bytes.Buffer
+	c.SetOut(&out)
+	if err := c.RunE(c, []string{"--flag", "value"}); err != nil {
+		t.Fatalf("plugin failed: %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"args=--flag value", "cluster=env-cluster", "version=v", "state=" + filepath.Join(home, ".gcluster")} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestPluginFormat(t *testing.T) {
+	tests := []struct {
+		args    []string
+		context string
+		want    string
+	}{
+		{nil, "", "text"},
+		{nil, "yaml", "yaml"},
+		{[]string{"report", "--format", "json"}, "yaml", "json"},
+		{[]string{"--format=json", "report"}, "", "json"},
+		{[]string{"report", "--", "--format", "json"}, "", "text"},
+	}
+	for _, tc := range tests {
+		if got := pluginFormat(tc.args, tc.context); got != tc.want {
+			t.Errorf("pluginFormat(%q, %q) = %q, want %q", tc.args, tc.context, got, tc.want)
+		}
+	}
+}
+
+func TestPluginCommand_PropagatesFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	p := writeExecutable(t, dir, "gcluster-fail", "#!/bin/sh\nexit 3\n", 0755)
+
+	c := newPluginCommand(plugin{Name: "fail", Path: p})
+	c.SetOut(&bytes.Buffer{})
+	if err := c.RunE(c, nil); err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+}
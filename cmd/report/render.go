@@ -0,0 +1,319 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// reportView is a RunReport with the values the templates print worked out.
+type reportView struct {
+	orchestrator.RunReport
+	GeneratedAt  string
+	CreatedAt    string
+	StartedAt    string
+	FinishedAt   string
+	Duration     string
+	Labels       []keyValue
+	Annotations  []keyValue
+	Containers   []containerView
+	Accelerators []acceleratorView
+}
+
+type keyValue struct{ Key, Value string }
+
+type containerView struct {
+	orchestrator.ReportContainer
+	CommandLine string
+	Env         []keyValue
+}
+
+// acceleratorView is the chip-hours of an accelerator over the run.
+type acceleratorView struct {
+	Name  string
+	Count int64
+	Hours string
+}
+
+func newReportView(r orchestrator.RunReport, at time.Time) reportView {
+	v := reportView{
+		RunReport:   r,
+		GeneratedAt: formatTime(at),
+		CreatedAt:   formatTime(r.Created),
+		StartedAt:   formatTime(r.Started),
+		FinishedAt:  formatTime(r.Finished),
+		Duration:    "-",
+		Labels:      sortedKeyValues(r.Labels),
+		Annotations: sortedKeyValues(r.Annotations),
+	}
+	d := r.Duration(at)
+	if !r.Started.IsZero() {
+		v.Duration = d.Round(time.Second).String()
+	}
+	for _, c := range r.Containers {
+		v.Containers = append(v.Containers, containerView{ReportContainer: c, CommandLine: strings.Join(c.Command, " "), Env: sortedKeyValues(c.Env)})
+	}
+	for _, res := range r.Resources {
+		if res.Accelerator {
+			v.Accelerators = append(v.Accelerators, acceleratorView{Name: res.Name, Count: res.Count, Hours: fmt.Sprintf("%.2f", float64(res.Count)*d.Hours())})
+		}
+	}
+	return v
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func sortedKeyValues(m map[string]string) []keyValue {
+	var kvs []keyValue
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		kvs = append(kvs, keyValue{k, m[k]})
+	}
+	return kvs
+}
+
+// render returns the report in format. HTML reports are a single page with
+// inline styles, so they can be attached or shared as one file.
+func render(format string, v reportView) (string, error) {
+	var sb strings.Builder
+	var err error
+	if format == formatMarkdown {
+		err = markdownTemplate.Execute(&sb, v)
+	} else {
+		err = htmlTemplate.Execute(&sb, v)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to render the report: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// markdownCell escapes the pipes and newlines of a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("report.html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gcluster report: {{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 70em; color: #202124; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; border-bottom: 1px solid #dadce0; padding-bottom: 0.2em; margin-top: 1.5em; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+th, td { text-align: left; padding: 0.25em 1em 0.25em 0; vertical-align: top; }
+th { color: #5f6368; font-weight: normal; }
+pre { background: #f1f3f4; padding: 0.75em; overflow-x: auto; font-size: 0.85em; }
+.status { font-weight: bold; }
+.footer { color: #5f6368; font-size: 0.85em; margin-top: 2em; }
+</style>
+</head>
+<body>
+<h1>Run report: {{.Name}}</h1>
+<p class="status">Status: {{.Status}}</p>
+
+<h2>Run</h2>
+<table>
+<tr><th>Workload</th><td>{{.Name}}</td></tr>
+<tr><th>Run ID</th><td>{{or .RunID "-"}}</td></tr>
+<tr><th>Namespace</th><td>{{.Namespace}}</td></tr>
+<tr><th>Cluster</th><td>{{.ClusterName}} ({{.Location}}, project {{.ProjectID}})</td></tr>
+<tr><th>Submitted</th><td>{{.CreatedAt}}</td></tr>
+<tr><th>Started</th><td>{{.StartedAt}}</td></tr>
+<tr><th>Finished</th><td>{{.FinishedAt}}</td></tr>
+<tr><th>Duration</th><td>{{.Duration}}</td></tr>
+{{- range .Labels}}
+<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- if .Annotations}}
+<table>
+{{- range .Annotations}}
+<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+<h2>Configuration</h2>
+{{- range .Containers}}
+<h3>{{.ReplicatedJob}} ({{.Pods}} pods)</h3>
+<table>
+<tr><th>Image</th><td>{{.Image}}</td></tr>
+</table>
+<pre>{{.CommandLine}}</pre>
+{{- if .Env}}
+<table>
+{{- range .Env}}
+<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+
+<h2>Resources</h2>
+<table>
+<tr><th>Resource</th><th>Requested by all pods</th></tr>
+{{- range .Resources}}
+<tr><td>{{.Name}}</td><td>{{.Total}}</td></tr>
+{{- end}}
+</table>
+{{- if .Accelerators}}
+<table>
+<tr><th>Accelerator</th><th>Chips</th><th>Chip-hours</th></tr>
+{{- range .Accelerators}}
+<tr><td>{{.Name}}</td><td>{{.Count}}</td><td>{{.Hours}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Diagnosis}}
+
+<h2>Diagnosis</h2>
+<ul>
+{{- range .Diagnosis}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+
+<h2>Logs</h2>
+{{- if .ErrorLines}}
+<h3>Errors</h3>
+<pre>{{range .ErrorLines}}{{.}}
+{{end}}</pre>
+{{- end}}
+{{- if .LastLines}}
+<h3>Last lines</h3>
+<pre>{{range .LastLines}}{{.}}
+{{end}}</pre>
+{{- else}}
+<p>No logs were found.</p>
+{{- end}}
+
+<h2>Links</h2>
+<ul>
+<li><a href="{{.WorkloadURL}}">Workload in the Cloud Console</a></li>
+<li><a href="{{.LogsURL}}">Logs in Cloud Logging</a></li>
+</ul>
+<p class="footer">Generated by gcluster at {{.GeneratedAt}}.</p>
+</body>
+</html>
+`))
+
+var markdownTemplate = template.Must(template.New("report.md").Funcs(template.FuncMap{"cell": markdownCell}).Parse(`# Run report: {{.Name}}
+
+**Status:** {{.Status}}
+
+## Run
+
+| | |
+|---|---|
+| Workload | {{.Name}} |
+| Run ID | {{or .RunID "-"}} |
+| Namespace | {{.Namespace}} |
+| Cluster | {{.ClusterName}} ({{.Location}}, project {{.ProjectID}}) |
+| Submitted | {{.CreatedAt}} |
+| Started | {{.StartedAt}} |
+| Finished | {{.FinishedAt}} |
+| Duration | {{.Duration}} |
+{{- range .Labels}}
+| {{cell .Key}} | {{cell .Value}} |
+{{- end}}
+{{- range .Annotations}}
+| {{cell .Key}} | {{cell .Value}} |
+{{- end}}
+
+## Configuration
+{{range .Containers}}
+### {{.ReplicatedJob}} ({{.Pods}} pods)
+
+Image: ` + "`{{.Image}}`" + `
+
+` + "```" + `
+{{.CommandLine}}
+` + "```" + `
+{{- if .Env}}
+
+| Env | Value |
+|---|---|
+{{- range .Env}}
+| {{cell .Key}} | {{cell .Value}} |
+{{- end}}
+{{- end}}
+{{end}}
+## Resources
+
+| Resource | Requested by all pods |
+|---|---|
+{{- range .Resources}}
+| {{.Name}} | {{cell .Total}} |
+{{- end}}
+{{- if .Accelerators}}
+
+| Accelerator | Chips | Chip-hours |
+|---|---|---|
+{{- range .Accelerators}}
+| {{.Name}} | {{.Count}} | {{.Hours}} |
+{{- end}}
+{{- end}}
+{{- if .Diagnosis}}
+
+## Diagnosis
+{{range .Diagnosis}}
+- {{.}}
+{{- end}}
+{{- end}}
+
+## Logs
+{{- if .ErrorLines}}
+
+### Errors
+
+` + "```" + `
+{{range .ErrorLines}}{{.}}
+{{end}}` + "```" + `
+{{- end}}
+{{- if .LastLines}}
+
+### Last lines
+
+` + "```" + `
+{{range .LastLines}}{{.}}
+{{end}}` + "```" + `
+{{- else}}
+
+No logs were found.
+{{- end}}
+
+## Links
+
+- [Workload in the Cloud Console]({{.WorkloadURL}})
+- [Logs in Cloud Logging]({{.LogsURL}})
+
+_Generated by gcluster at {{.GeneratedAt}}._
+`))
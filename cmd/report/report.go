@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report writes a shareable single-file report of a workload run.
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"hpc-toolkit/cmd/job"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/orchestrator/gke"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatHTML     = "html"
+	formatMarkdown = "markdown"
+)
+
+var formats = []string{formatHTML, formatMarkdown}
+
+var (
+	clusterName string
+	location    string
+	projectID   string
+	outPath     string
+	format      string
+	logLines    int
+)
+
+var gkeOrchestratorFactory = func() orchestrator.JobOrchestrator {
+	return gke.NewGKEOrchestrator()
+}
+
+var orc orchestrator.JobOrchestrator
+
+// now is the time the report is generated at and the durations of running
+// workloads are measured up to.
+var now = time.Now
+
+// ReportCmd writes the run report of a workload.
+var ReportCmd = &cobra.Command{
+	Use:   "report <workload>",
+	Short: "[EXPERIMENTAL] Write a shareable report of a workload run.",
+	Long: `Write a single-file HTML or Markdown report of a workload run: its metadata,
+configuration, duration, requested resources and accelerator hours, why it is
+pending or failing, excerpts of its logs and links to the Cloud Console.
+
+Env values whose names look like secrets are redacted, but logs are included as
+they are; review the report before sharing it.`,
+	Example: `  gcluster report train-42 --out report.html
+  gcluster report train-42 --out report.md`,
+	Args: cobra.ExactArgs(1),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		ctx := job.EffectiveContext()
+		if clusterName == "" {
+			clusterName = ctx.ClusterName
+		}
+		if location == "" {
+			location = ctx.Location
+		}
+		if projectID == "" {
+			projectID = ctx.ProjectID
+		}
+		for _, f := range []struct{ value, flag, description string }{
+			{clusterName, "cluster", "cluster name"},
+			{location, "location", "cluster location"},
+			{projectID, "project", "project ID"},
+		} {
+			if f.value == "" {
+				return fmt.Errorf("%s is required; please specify it using the --%s flag or set a default value using 'gcluster job config set %s <value>'", f.description, f.flag, f.flag)
+			}
+		}
+		orc = gkeOrchestratorFactory()
+		return nil
+	},
+	RunE:         runReport,
+	SilenceUsage: true,
+}
+
+func init() {
+	ReportCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "", "Name of the GKE cluster.")
+	ReportCmd.PersistentFlags().StringVarP(&location, "location", "l", "", "Location (region or zone) of the GKE cluster.")
+	ReportCmd.PersistentFlags().StringVarP(&projectID, "project", "p", "", "Google Cloud Project ID.")
+	ReportCmd.Flags().StringVarP(&outPath, "out", "o", "", "Path of the report. Defaults to gcluster-report-<workload>.html, or .md with --format markdown.")
+	ReportCmd.Flags().StringVar(&format, "format", "", fmt.Sprintf("Format of the report: %s. Defaults to markdown for .md paths and html otherwise.", strings.Join(formats, ", ")))
+	ReportCmd.Flags().IntVar(&logLines, "log-lines", 200, "Log lines per container that the log excerpts are taken from.")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	f, err := reportFormat(format, outPath)
+	if err != nil {
+		return err
+	}
+	path := outPath
+	if path == "" {
+		path = "gcluster-report-" + name + ".html"
+		if f == formatMarkdown {
+			path = "gcluster-report-" + name + ".md"
+		}
+	}
+
+	r, err := orc.ReportJob(cmd.Context(), name, orchestrator.ReportOptions{
+		ProjectID:       projectID,
+		ClusterName:     clusterName,
+		ClusterLocation: location,
+		LogTailLines:    logLines,
+	})
+	if err != nil {
+		return err
+	}
+
+	content, err := render(f, newReportView(r, now()))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	logging.Info("Report of %s written to %s. Review it for sensitive data before sharing it.", name, path)
+	return nil
+}
+
+// reportFormat returns the format set with --format, or the one implied by
+// the extension of the output path.
+func reportFormat(format, path string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".markdown":
+			return formatMarkdown, nil
+		default:
+			return formatHTML, nil
+		}
+	}
+	if !slices.Contains(formats, format) {
+		return "", fmt.Errorf("invalid --format %q, must be one of: %s", format, strings.Join(formats, ", "))
+	}
+	return format, nil
+}
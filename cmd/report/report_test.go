@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/spf13/cobra"
+)
+
+func executeCommand(root *cobra.Command, args ...string) (string, error) {
+	buf := new(bytes.Buffer)
+	root.SetOut(buf)
+	root.SetErr(buf)
+	root.SetArgs(args)
+
+	err := root.Execute()
+
+	return buf.String(), err
+}
+
+type mockOrchestrator struct {
+	orchestrator.JobOrchestrator
+	name   string
+	opts   orchestrator.ReportOptions
+	report orchestrator.RunReport
+}
+
+func (m *mockOrchestrator) ReportJob(_ context.Context, name string, opts orchestrator.ReportOptions) (orchestrator.RunReport, error) {
+	m.name, m.opts = name, opts
+	return m.report, nil
+}
+
+var testReport = orchestrator.RunReport{
+	Name:        "train",
+	Namespace:   "default",
+	ClusterName: "c",
+	Location:    "us-central1",
+	ProjectID:   "p",
+	RunID:       "run123",
+	Status:      "Failed",
+	Labels:      map[string]string{"experiment": "lr"},
+	Created:     time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+	Started:     time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC),
+	Finished:    time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC),
+	Containers: []orchestrator.ReportContainer{{
+		ReplicatedJob: "main-job", Pods: 2, Image: "img:v1", Command: []string{"bash", "-c", "python train.py"},
+		Env: map[string]string{"HF_TOKEN": orchestrator.RedactedValue},
+	}},
+	Resources: []orchestrator.ReportResource{
+		{Name: "cpu", Total: "8"},
+		{Name: "nvidia.com/gpu", Total: "16", Accelerator: true, Count: 16},
+	},
+	Diagnosis:   []string{"The JobSet failed: FailedJobs."},
+	ErrorLines:  []string{"[pod/train-0/workload] RuntimeError: <CUDA> error"},
+	LastLines:   []string{"[pod/train-0/workload] step 10"},
+	WorkloadURL: "https://console.cloud.google.com/kubernetes/job/train",
+	LogsURL:     "https://console.cloud.google.com/logs/query",
+}
+
+func setupMock(t *testing.T) *mockOrchestrator {
+	t.Helper()
+	m := &mockOrchestrator{report: testReport}
+	oldFactory, oldNow := gkeOrchestratorFactory, now
+	t.Cleanup(func() {
+		gkeOrchestratorFactory, now = oldFactory, oldNow
+		clusterName, location, projectID = "", "", ""
+		outPath, format, logLines = "", "", 200
+	})
+	gkeOrchestratorFactory = func() orchestrator.JobOrchestrator { return m }
+	now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+	return m
+}
+
+func TestReportCmd_HTML(t *testing.T) {
+	m := setupMock(t)
+	out := filepath.Join(t.TempDir(), "report.html")
+
+	if _, err := executeCommand(ReportCmd, "train", "-c", "c", "-l", "us-central1", "-p", "p", "--out", out, "--log-lines", "50"); err != nil {
+		t.Fatal(err)
+	}
+	if m.name != "train" || m.opts != (orchestrator.ReportOptions{ProjectID: "p", ClusterName: "c", ClusterLocation: "us-central1", LogTailLines: 50}) {
+		t.Errorf("ReportJob(%q, %+v) called with unexpected arguments", m.name, m.opts)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>gcluster report: train</title>",
+		"<tr><th>Duration</th><td>2h0m0s</td></tr>",
+		"<tr><th>experiment</th><td>lr</td></tr>",
+		"<tr><th>HF_TOKEN</th><td>&lt;redacted&gt;</td></tr>",
+		"<tr><td>nvidia.com/gpu</td><td>16</td><td>32.00</td></tr>",
+		"RuntimeError: &lt;CUDA&gt; error",
+		"<li>The JobSet failed: FailedJobs.</li>",
+		`<a href="https://console.cloud.google.com/logs/query">`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("report does not contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestReportCmd_MarkdownFromExtension(t *testing.T) {
+	setupMock(t)
+	out := filepath.Join(t.TempDir(), "report.md")
+
+	if _, err := executeCommand(ReportCmd, "train", "-c", "c", "-l", "us-central1", "-p", "p", "--out", out); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Run report: train",
+		"| Duration | 2h0m0s |",
+		"| nvidia.com/gpu | 16 | 32.00 |",
+		"python train.py",
+		"- [Logs in Cloud Logging](https://console.cloud.google.com/logs/query)",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("report does not contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestReportCmd_InvalidFormat(t *testing.T) {
+	setupMock(t)
+	_, err := executeCommand(ReportCmd, "train", "-c", "c", "-l", "us-central1", "-p", "p", "--format", "pdf")
+	if err == nil || !strings.Contains(err.Error(), `invalid --format "pdf"`) {
+		t.Errorf("expected an invalid format error, got %v", err)
+	}
+}
+
+func TestReportFormat(t *testing.T) {
+	for _, tc := range []struct{ format, path, want string }{
+		{"", "", formatHTML},
+		{"", "out/r.MD", formatMarkdown},
+		{"", "r.html", formatHTML},
+		{formatMarkdown, "r.html", formatMarkdown},
+	} {
+		if got, err := reportFormat(tc.format, tc.path); err != nil || got != tc.want {
+			t.Errorf("reportFormat(%q, %q) = %q, %v, want %q", tc.format, tc.path, got, err, tc.want)
+		}
+	}
+}
@@ -34,7 +34,11 @@ import (
 	"github.com/spf13/cobra"
 
 	"hpc-toolkit/cmd/cluster"
+	"hpc-toolkit/cmd/components"
+	"hpc-toolkit/cmd/experiment"
+	"hpc-toolkit/cmd/fleet"
 	"hpc-toolkit/cmd/job"
+	"hpc-toolkit/cmd/report"
 )
 
 // Git references when use Makefile
@@ -78,10 +82,15 @@ func init() {
 		initColor()
 		initDependencies(cmd)
 		initTelemetry(cmd, args)
+		checkForUpdates(cmd)
 	}
 
 	rootCmd.AddCommand(cluster.ClusterCmd)
 	rootCmd.AddCommand(job.JobCmd)
+	rootCmd.AddCommand(fleet.FleetCmd)
+	rootCmd.AddCommand(experiment.ExperimentCmd)
+	rootCmd.AddCommand(report.ReportCmd)
+	rootCmd.AddCommand(components.ComponentsCmd)
 }
 
 // Execute the root command
@@ -120,23 +129,15 @@ Commit info: {{index .Annotations "commitInfo"}}
 		rootCmd.SetVersionTemplate(tmpl)
 	}
 
+	registerPlugins(rootCmd, discoverPlugins(os.Getenv("PATH")))
+
 	for _, child := range rootCmd.Commands() {
 		wrapTelemetry(child)
 	}
 
 	err := rootCmd.Execute()
 
-	// Capture Error Code
-	exitCode := 0
-	if err != nil {
-		exitCode = 1 // Default generic error code
-
-		// Attempt to unwrap the specific exit code from the error
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			exitCode = exitErr.ExitCode()
-		}
-	}
+	exitCode := ExitCode(err)
 
 	// Ensure telemetry is executed exactly once on normal exits to prevent recurrency
 	if telemetryFlushed.CompareAndSwap(false, true) {
@@ -148,6 +149,20 @@ Commit info: {{index .Annotations "commitInfo"}}
 	return err
 }
 
+// ExitCode returns the exit status gcluster ends with after err: 0 without an
+// error, the code an error carries, such as the exit status of a plugin or the
+// outcome of 'gcluster job wait', and 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var coded interface{ ExitCode() int }
+	if errors.As(err, &coded) && coded.ExitCode() > 0 {
+		return coded.ExitCode()
+	}
+	return 1
+}
+
 func initTelemetry(cmd *cobra.Command, args []string) {
 	if err := config.InitUserConfig(); err == nil {
 		telemetryCollector = telemetry.NewCollector(cmd, args, InstallationMode)
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -438,3 +439,27 @@ func TestWrapTelemetryDynamic(t *testing.T) {
 		t.Errorf("Expected userConfigExists to be true")
 	}
 }
+
+type codedError struct{ code int }
+
+func (e codedError) Error() string { return "coded" }
+func (e codedError) ExitCode() int { return e.code }
+
+func TestExitCode(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 7").Run()
+	for _, tc := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"no error", nil, 0},
+		{"generic", errors.New("generic"), 1},
+		{"process exit status", exitErr, 7},
+		{"wrapped code", fmt.Errorf("wait: %w", codedError{3}), 3},
+		{"no code", codedError{0}, 1},
+	} {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Errorf("%s: ExitCode() = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
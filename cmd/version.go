@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+	"hpc-toolkit/pkg/telemetry"
+	"hpc-toolkit/pkg/versioncheck"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagCheckLatest bool
+
+	latestReleaseFn       = versioncheck.LatestRelease
+	cachedLatestVersionFn = versioncheck.CachedLatestVersion
+	selfUpdateFn          = versioncheck.SelfUpdate
+)
+
+func init() {
+	versionCmd.Flags().BoolVar(&flagCheckLatest, "check", false, "Compare this binary against the latest published release and list known-incompatible cluster components.")
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(addAutoApproveFlag(selfUpdateCmd))
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the gcluster version.",
+	Long: `Print the gcluster version. With --check, also query the latest published
+release and print the JobSet and Kueue versions this release is known not to work with.
+
+Set GCLUSTER_UPDATE_CHECK=true to run the release check automatically (at most once a day).`,
+	Args: cobra.NoArgs,
+	RunE: runVersionCmd,
+}
+
+var selfUpdateCmd = &cobra.Command{
+	Use:          "self-update",
+	Short:        "Replace a standalone gcluster binary with the latest release.",
+	Args:         cobra.NoArgs,
+	RunE:         runSelfUpdateCmd,
+	SilenceUsage: true,
+}
+
+func runVersionCmd(cmd *cobra.Command, args []string) error {
+	current := config.GetToolkitVersion()
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "gcluster version %s\n", current)
+	if GitCommitInfo != "" {
+		fmt.Fprintf(out, "Commit info: %s\n", GitCommitInfo)
+	}
+
+	if !flagCheckLatest {
+		return nil
+	}
+
+	rel, err := latestReleaseFn()
+	if err != nil {
+		return err
+	}
+	if versioncheck.IsNewer(rel.TagName, current) {
+		fmt.Fprintf(out, "A newer version of gcluster is available: %s (release notes: %s)\n", rel.TagName, rel.HTMLURL)
+		fmt.Fprintln(out, upgradeHint())
+	} else {
+		fmt.Fprintln(out, "gcluster is up to date.")
+	}
+
+	fmt.Fprintln(out, "\nKnown-incompatible cluster components:")
+	for _, inc := range versioncheck.CompatibilityMatrix {
+		fmt.Fprintf(out, "  %s < %s: %s\n", inc.Component, inc.Below, inc.Reason)
+	}
+	return nil
+}
+
+func runSelfUpdateCmd(cmd *cobra.Command, args []string) error {
+	if InstallationMode != telemetry.BINARY {
+		return fmt.Errorf("self-update is only supported for standalone binary installs. %s", upgradeHint())
+	}
+
+	current := config.GetToolkitVersion()
+	rel, err := latestReleaseFn()
+	if err != nil {
+		return err
+	}
+	if !versioncheck.IsNewer(rel.TagName, current) {
+		logging.Info("gcluster %s is already the latest version.", current)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running gcluster binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	if getApplyBehavior() != shell.AutomaticApply {
+		if !shell.PromptYesNo(fmt.Sprintf("Replace %s (%s) with %s?", exe, current, rel.TagName)) {
+			return fmt.Errorf("self-update cancelled")
+		}
+	}
+
+	logging.Info("Downloading gcluster %s...", rel.TagName)
+	if err := selfUpdateFn(rel, exe); err != nil {
+		return fmt.Errorf("self-update failed: %w", err)
+	}
+	logging.Info("gcluster updated to %s.", rel.TagName)
+	return nil
+}
+
+func upgradeHint() string {
+	if InstallationMode == telemetry.BINARY {
+		return "Run 'gcluster self-update' to upgrade."
+	}
+	return "Update your Cluster Toolkit checkout (e.g. 'git pull') and rebuild with 'make'."
+}
+
+// checkForUpdates runs the opt-in startup release check. Failures are silent
+// so that a flaky network never blocks regular commands.
+func checkForUpdates(cmd *cobra.Command) {
+	if !versioncheck.StartupCheckEnabled() || cmd == versionCmd || cmd == selfUpdateCmd {
+		return
+	}
+	latest, err := cachedLatestVersionFn()
+	if err != nil {
+		return
+	}
+	current := config.GetToolkitVersion()
+	if versioncheck.IsNewer(latest, current) {
+		logging.Warn("gcluster %s is available (you have %s). %s", latest, current, upgradeHint())
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/telemetry"
+	"hpc-toolkit/pkg/versioncheck"
+)
+
+func TestVersionCmd_Check(t *testing.T) {
+	origFn := latestReleaseFn
+	origMode := InstallationMode
+	defer func() {
+		latestReleaseFn = origFn
+		InstallationMode = origMode
+		flagCheckLatest = false
+	}()
+
+	tests := []struct {
+		name     string
+		tag      string
+		mode     string
+		expected []string
+	}{
+		{
+			name:     "newer release for binary install",
+			tag:      "v99.0.0",
+			mode:     telemetry.BINARY,
+			expected: []string{"A newer version of gcluster is available: v99.0.0", "gcluster self-update", "kueue < v0.15.0"},
+		},
+		{
+			name:     "newer release for source install",
+			tag:      "v99.0.0",
+			mode:     telemetry.SOURCE,
+			expected: []string{"rebuild with 'make'"},
+		},
+		{
+			name:     "up to date",
+			tag:      "v0.0.1",
+			mode:     telemetry.BINARY,
+			expected: []string{"gcluster is up to date."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latestReleaseFn = func() (versioncheck.Release, error) {
+				return versioncheck.Release{TagName: tt.tag}, nil
+			}
+			InstallationMode = tt.mode
+			flagCheckLatest = true
+
+			var out bytes.Buffer
+			versionCmd.SetOut(&out)
+			if err := versionCmd.RunE(versionCmd, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, e := range tt.expected {
+				if !strings.Contains(out.String(), e) {
+					t.Errorf("expected output to contain %q, got:\n%s", e, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestSelfUpdateCmd_RequiresBinaryInstall(t *testing.T) {
+	origMode := InstallationMode
+	defer func() { InstallationMode = origMode }()
+	InstallationMode = telemetry.SOURCE
+
+	err := selfUpdateCmd.RunE(selfUpdateCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "only supported for standalone binary installs") {
+		t.Fatalf("expected binary-install error, got %v", err)
+	}
+}
+
+func TestSelfUpdateCmd_AlreadyLatest(t *testing.T) {
+	origFn, origUpdate, origMode := latestReleaseFn, selfUpdateFn, InstallationMode
+	defer func() {
+		latestReleaseFn, selfUpdateFn, InstallationMode = origFn, origUpdate, origMode
+	}()
+	InstallationMode = telemetry.BINARY
+	latestReleaseFn = func() (versioncheck.Release, error) {
+		return versioncheck.Release{TagName: "v0.0.1"}, nil
+	}
+	selfUpdateFn = func(versioncheck.Release, string) error {
+		t.Fatal("self-update should not run when already on the latest version")
+		return nil
+	}
+
+	if err := selfUpdateCmd.RunE(selfUpdateCmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
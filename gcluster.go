@@ -52,6 +52,6 @@ func main() {
 	cmd.GitIsOfficial = gitIsOfficial
 	cmd.InstallationMode = installationMode
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
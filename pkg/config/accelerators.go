@@ -0,0 +1,115 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AcceleratorDefinition extends the built-in accelerator catalog of
+// machine_mappings.json with an accelerator it does not know yet.
+type AcceleratorDefinition struct {
+	// Label is the cloud.google.com/gke-accelerator (or gke-tpu-accelerator)
+	// value of the nodes, e.g. nvidia-h200-141gb.
+	Label string `yaml:"label"`
+	// MachineFamilies are the machine type families carrying the accelerator,
+	// e.g. a3-ultragpu.
+	MachineFamilies []string `yaml:"machine_families"`
+	// Shorthands map --compute-type shorthands to full machine types.
+	Shorthands map[string]string `yaml:"shorthands"`
+	// GPUMemoryGB and HourlyPrice add a GPU to the catalog --gpu-memory
+	// selects from: the memory of one GPU in GB and its approximate price
+	// per hour.
+	GPUMemoryGB int     `yaml:"gpu_memory_gb"`
+	HourlyPrice float64 `yaml:"hourly_price"`
+}
+
+// acceleratorsFile is the schema of a user-supplied accelerators file.
+type acceleratorsFile struct {
+	Accelerators []AcceleratorDefinition `yaml:"accelerators"`
+}
+
+// LoadAcceleratorDefinitions reads the accelerator definitions of a YAML file.
+// A missing file defines no accelerators.
+func LoadAcceleratorDefinitions(path string) ([]AcceleratorDefinition, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accelerators file %s: %w", path, err)
+	}
+
+	var f acceleratorsFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to parse accelerators file %s: %w", path, err)
+	}
+	for i, def := range f.Accelerators {
+		if err := def.validate(); err != nil {
+			return nil, fmt.Errorf("accelerators file %s: accelerator %d: %w", path, i+1, err)
+		}
+	}
+	return f.Accelerators, nil
+}
+
+func (d AcceleratorDefinition) validate() error {
+	if d.Label == "" {
+		return fmt.Errorf("label is required")
+	}
+	if len(d.MachineFamilies) == 0 && len(d.Shorthands) == 0 {
+		return fmt.Errorf("%s: at least one of machine_families and shorthands is required", d.Label)
+	}
+	for shorthand, machineType := range d.Shorthands {
+		if machineType == "" {
+			return fmt.Errorf("%s: shorthand %q has no machine type", d.Label, shorthand)
+		}
+	}
+	if d.GPUMemoryGB < 0 || d.HourlyPrice < 0 {
+		return fmt.Errorf("%s: gpu_memory_gb and hourly_price cannot be negative", d.Label)
+	}
+	if (d.GPUMemoryGB > 0) != (d.HourlyPrice > 0) {
+		return fmt.Errorf("%s: gpu_memory_gb and hourly_price must be set together", d.Label)
+	}
+	return nil
+}
+
+// RegisterAccelerators adds accelerator definitions to the catalog used to
+// resolve --compute-type shorthands and node selector labels. Definitions
+// replace built-in entries for the same machine family or shorthand.
+func RegisterAccelerators(defs []AcceleratorDefinition) {
+	mappings := GetMachineMappings()
+	for _, def := range defs {
+		for _, family := range def.MachineFamilies {
+			mappings.MachineFamilyToLabelMap[strings.ToLower(family)] = def.Label
+		}
+		for shorthand, machineType := range def.Shorthands {
+			AcceleratorShorthandMap[strings.ToLower(shorthand)] = machineType
+		}
+		if strings.HasPrefix(def.Label, "nvidia-") {
+			ValidGPUAccelerators[def.Label] = true
+		}
+		if def.GPUMemoryGB > 0 {
+			GPUCatalog[def.Label] = GPUSpec{MemoryGB: def.GPUMemoryGB, HourlyPrice: def.HourlyPrice}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 "Google LLC"
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAcceleratorDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "accelerators.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	defs, err := LoadAcceleratorDefinitions(filepath.Join(dir, "missing.yaml"))
+	if err != nil || defs != nil {
+		t.Errorf("expected no definitions for a missing file, got %v, %v", defs, err)
+	}
+
+	defs, err = LoadAcceleratorDefinitions(write(`
+accelerators:
+  - label: nvidia-b300
+    machine_families: [a4u-highgpu]
+    shorthands:
+      b300-8: a4u-highgpu-8g
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 1 || defs[0].Label != "nvidia-b300" || defs[0].Shorthands["b300-8"] != "a4u-highgpu-8g" {
+		t.Errorf("unexpected definitions %+v", defs)
+	}
+
+	for content, wantErr := range map[string]string{
+		"accelerators:\n  - machine_families: [a4u-highgpu]\n":                                                 "label is required",
+		"accelerators:\n  - label: nvidia-b300\n":                                                              "at least one of machine_families and shorthands",
+		"accelerators:\n  - label: nvidia-b300\n    shorthands: {b300-8: \"\"}\n":                              `shorthand "b300-8" has no machine type`,
+		"accelerators:\n  - label: nvidia-b300\n    gpus: 8\n":                                                 "field gpus not found",
+		"accelerators:\n  - label: nvidia-b300\n    machine_families: [a4u-highgpu]\n    gpu_memory_gb: 288\n": "gpu_memory_gb and hourly_price must be set together",
+	} {
+		if _, err := LoadAcceleratorDefinitions(write(content)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("expected error %q for %q, got %v", wantErr, content, err)
+		}
+	}
+}
+
+func TestRegisterAccelerators(t *testing.T) {
+	mappings := GetMachineMappings()
+	origLabels := maps.Clone(mappings.MachineFamilyToLabelMap)
+	origShorthands := maps.Clone(AcceleratorShorthandMap)
+	origGPUs := maps.Clone(ValidGPUAccelerators)
+	origCatalog := maps.Clone(GPUCatalog)
+	t.Cleanup(func() {
+		mappings.MachineFamilyToLabelMap = origLabels
+		AcceleratorShorthandMap = origShorthands
+		ValidGPUAccelerators = origGPUs
+		GPUCatalog = origCatalog
+	})
+
+	RegisterAccelerators([]AcceleratorDefinition{{
+		Label:           "nvidia-b300",
+		MachineFamilies: []string{"A4U-highgpu"},
+		Shorthands:      map[string]string{"B300-8": "a4u-highgpu-8g"},
+		GPUMemoryGB:     288,
+		HourlyPrice:     20,
+	}})
+	if got := mappings.MachineFamilyToLabelMap["a4u-highgpu"]; got != "nvidia-b300" {
+		t.Errorf("machine family label = %q, want nvidia-b300", got)
+	}
+	if got := ResolveMachineType("b300-8"); got != "a4u-highgpu-8g" {
+		t.Errorf("ResolveMachineType(b300-8) = %q, want a4u-highgpu-8g", got)
+	}
+	if !ValidGPUAccelerators["nvidia-b300"] {
+		t.Error("expected nvidia-b300 to be a valid GPU accelerator")
+	}
+	if got := GPUCatalog["nvidia-b300"]; got.MemoryGB != 288 || got.HourlyPrice != 20 {
+		t.Errorf("GPUCatalog[nvidia-b300] = %+v, want 288 GB at 20/hour", got)
+	}
+}
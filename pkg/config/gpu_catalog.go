@@ -0,0 +1,89 @@
+// Copyright 2026 "Google LLC"
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GPUSpec describes the capabilities of a GPU accelerator.
+type GPUSpec struct {
+	// MemoryGB is the memory of one GPU in gigabytes.
+	MemoryGB int
+	// HourlyPrice is the approximate on-demand price of one GPU per hour in
+	// USD, including its share of the host VM. It is only used to rank GPUs
+	// against each other.
+	HourlyPrice float64
+}
+
+// GPUCatalog maps GKE accelerator labels to the capabilities of their GPUs.
+var GPUCatalog = map[string]GPUSpec{
+	"nvidia-tesla-p4":       {MemoryGB: 8, HourlyPrice: 0.60},
+	"nvidia-tesla-t4":       {MemoryGB: 16, HourlyPrice: 0.35},
+	"nvidia-tesla-p100":     {MemoryGB: 16, HourlyPrice: 1.46},
+	"nvidia-tesla-v100":     {MemoryGB: 16, HourlyPrice: 2.48},
+	"nvidia-l4":             {MemoryGB: 24, HourlyPrice: 0.85},
+	"nvidia-tesla-a100":     {MemoryGB: 40, HourlyPrice: 3.67},
+	"nvidia-a100-80gb":      {MemoryGB: 80, HourlyPrice: 5.07},
+	"nvidia-h100-80gb":      {MemoryGB: 80, HourlyPrice: 11.06},
+	"nvidia-h100-mega-80gb": {MemoryGB: 80, HourlyPrice: 11.70},
+	"nvidia-rtx-pro-6000":   {MemoryGB: 96, HourlyPrice: 5.50},
+	"nvidia-h200-141gb":     {MemoryGB: 141, HourlyPrice: 12.00},
+	"nvidia-b200":           {MemoryGB: 180, HourlyPrice: 15.00},
+	"nvidia-gb200":          {MemoryGB: 186, HourlyPrice: 18.00},
+}
+
+var gpuMemoryRegex = regexp.MustCompile(`^(\d+)\s*(gb|g|gib|gi)?$`)
+
+// ParseGPUMemory parses a GPU memory size such as "80GB", "80G" or "80" into
+// gigabytes. Sizes in GiB ("75GiB", "75Gi") are converted and rounded up.
+func ParseGPUMemory(value string) (int, error) {
+	m := gpuMemoryRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if m == nil {
+		return 0, fmt.Errorf("%q is not a size in GB, such as 80GB", value)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q must be positive", value)
+	}
+	if m[2] == "gib" || m[2] == "gi" {
+		n = int(math.Ceil(float64(n) * (1 << 30) / 1e9))
+	}
+	return n, nil
+}
+
+// GPUsWithMemory returns the labels of the catalog GPUs with at least
+// minMemoryGB of memory, cheapest first.
+func GPUsWithMemory(minMemoryGB int) []string {
+	var labels []string
+	for label, spec := range GPUCatalog {
+		if spec.MemoryGB >= minMemoryGB {
+			labels = append(labels, label)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		pi, pj := GPUCatalog[labels[i]].HourlyPrice, GPUCatalog[labels[j]].HourlyPrice
+		if pi != pj {
+			return pi < pj
+		}
+		return labels[i] < labels[j]
+	})
+	return labels
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 "Google LLC"
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseGPUMemory(t *testing.T) {
+	for value, want := range map[string]int{
+		"80GB":  80,
+		"80gb":  80,
+		"80G":   80,
+		"80":    80,
+		" 40 ":  40,
+		"75GiB": 81,
+		"75Gi":  81,
+	} {
+		got, err := ParseGPUMemory(value)
+		if err != nil || got != want {
+			t.Errorf("ParseGPUMemory(%q) = %d, %v, want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "0GB", "80MB", "1.5GB", "-8GB", "GB"} {
+		if _, err := ParseGPUMemory(value); err == nil {
+			t.Errorf("ParseGPUMemory(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestGPUsWithMemory(t *testing.T) {
+	got := GPUsWithMemory(80)
+	if len(got) == 0 || got[0] != "nvidia-a100-80gb" {
+		t.Errorf("GPUsWithMemory(80) = %v, want nvidia-a100-80gb first", got)
+	}
+	for _, label := range got {
+		if GPUCatalog[label].MemoryGB < 80 {
+			t.Errorf("GPUsWithMemory(80) includes %s with %d GB", label, GPUCatalog[label].MemoryGB)
+		}
+	}
+	if !slices.Contains(got, "nvidia-h200-141gb") || slices.Contains(got, "nvidia-l4") {
+		t.Errorf("GPUsWithMemory(80) = %v", got)
+	}
+	if got := GPUsWithMemory(1000); len(got) != 0 {
+		t.Errorf("GPUsWithMemory(1000) = %v, want none", got)
+	}
+}
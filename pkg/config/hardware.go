@@ -96,6 +96,8 @@ var AcceleratorShorthandMap = map[string]string{
 var ValidGPUAccelerators = map[string]bool{
 	"nvidia-l4":             true,
 	"nvidia-tesla-a100":     true,
+	"nvidia-a100-80gb":      true,
+	"nvidia-rtx-pro-6000":   true,
 	"nvidia-gb200":          true,
 	"nvidia-b200":           true,
 	"nvidia-h200-141gb":     true,
@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// AcceleratorPresetsVersion is the schema version of accelerator presets files.
+const AcceleratorPresetsVersion = 1
+
+// Container limits an accelerator kind is set on.
+const (
+	AcceleratorLimitGPU = "gpu"
+	AcceleratorLimitTPU = "tpu"
+)
+
+//go:embed accelerator_presets.yaml
+var defaultAcceleratorPresetsYAML []byte
+
+// AcceleratorKind classifies the nodes whose accelerator label contains Match.
+type AcceleratorKind struct {
+	Match string `yaml:"match"`
+	// Limit is the container limit the accelerators are set on: gpu or tpu.
+	Limit string `yaml:"limit"`
+}
+
+// AcceleratorPreset sets the container limits of the workloads of a machine
+// type.
+type AcceleratorPreset struct {
+	MachineType string `yaml:"machine_type"`
+	// Accelerators per node; 0 looks them up with the Compute Engine API.
+	Accelerators int `yaml:"accelerators"`
+	// CPU and Memory are the default limits, overridden by --cpu and --memory.
+	CPU    string `yaml:"cpu"`
+	Memory string `yaml:"memory"`
+}
+
+// AcceleratorPresets is the schema of an accelerator presets file.
+type AcceleratorPresets struct {
+	Version int                 `yaml:"version"`
+	Kinds   []AcceleratorKind   `yaml:"kinds"`
+	Presets []AcceleratorPreset `yaml:"presets"`
+}
+
+// activeAcceleratorPresets are the built-in presets, extended by the
+// registered ones.
+var activeAcceleratorPresets = mustParseDefaultAcceleratorPresets()
+
+func mustParseDefaultAcceleratorPresets() AcceleratorPresets {
+	p, err := parseAcceleratorPresets(defaultAcceleratorPresetsYAML)
+	if err != nil {
+		panic(fmt.Sprintf("failed to parse accelerator_presets.yaml: %v", err))
+	}
+	return *p
+}
+
+// LoadAcceleratorPresets reads an accelerator presets file.
+func LoadAcceleratorPresets(path string) (*AcceleratorPresets, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read presets file %s: %w", path, err)
+	}
+	p, err := parseAcceleratorPresets(data)
+	if err != nil {
+		return nil, fmt.Errorf("presets file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func parseAcceleratorPresets(data []byte) (*AcceleratorPresets, error) {
+	var p AcceleratorPresets
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	if p.Version != AcceleratorPresetsVersion {
+		return nil, fmt.Errorf("unsupported version %d, expected version: %d", p.Version, AcceleratorPresetsVersion)
+	}
+	for i, k := range p.Kinds {
+		if k.Match == "" {
+			return nil, fmt.Errorf("kind %d: match is required", i+1)
+		}
+		if k.Limit != AcceleratorLimitGPU && k.Limit != AcceleratorLimitTPU {
+			return nil, fmt.Errorf("kind %q: limit must be %s or %s, got %q", k.Match, AcceleratorLimitGPU, AcceleratorLimitTPU, k.Limit)
+		}
+	}
+	for i, preset := range p.Presets {
+		if err := preset.validate(); err != nil {
+			return nil, fmt.Errorf("preset %d: %w", i+1, err)
+		}
+	}
+	return &p, nil
+}
+
+func (p AcceleratorPreset) validate() error {
+	if p.MachineType == "" {
+		return fmt.Errorf("machine_type is required")
+	}
+	if p.Accelerators < 0 {
+		return fmt.Errorf("%s: accelerators must not be negative", p.MachineType)
+	}
+	for name, v := range map[string]string{"cpu": p.CPU, "memory": p.Memory} {
+		if v == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(v); err != nil {
+			return fmt.Errorf("%s: invalid %s %q: %w", p.MachineType, name, v, err)
+		}
+	}
+	return nil
+}
+
+// RegisterAcceleratorPresets extends the active presets. Its kinds are
+// matched before the ones already registered, and its presets replace the
+// ones for the same machine type.
+func RegisterAcceleratorPresets(p *AcceleratorPresets) {
+	activeAcceleratorPresets.Kinds = append(slices.Clone(p.Kinds), activeAcceleratorPresets.Kinds...)
+	for _, preset := range p.Presets {
+		i := slices.IndexFunc(activeAcceleratorPresets.Presets, func(existing AcceleratorPreset) bool {
+			return strings.EqualFold(existing.MachineType, preset.MachineType)
+		})
+		if i >= 0 {
+			activeAcceleratorPresets.Presets[i] = preset
+			continue
+		}
+		activeAcceleratorPresets.Presets = append(activeAcceleratorPresets.Presets, preset)
+	}
+}
+
+// AcceleratorLimitFor returns the container limit the accelerators of nodes
+// with the given accelerator label are set on, or "" if no kind matches.
+func AcceleratorLimitFor(label string) string {
+	label = strings.ToLower(label)
+	for _, k := range activeAcceleratorPresets.Kinds {
+		if strings.Contains(label, strings.ToLower(k.Match)) {
+			return k.Limit
+		}
+	}
+	return ""
+}
+
+// AcceleratorPresetFor returns the preset of a machine type.
+func AcceleratorPresetFor(machineType string) (AcceleratorPreset, bool) {
+	for _, p := range activeAcceleratorPresets.Presets {
+		if strings.EqualFold(p.MachineType, machineType) {
+			return p, true
+		}
+	}
+	return AcceleratorPreset{}, false
+}
+
+// ResetAcceleratorPresets drops the registered presets. Used for testing.
+func ResetAcceleratorPresets() {
+	activeAcceleratorPresets = mustParseDefaultAcceleratorPresets()
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAcceleratorPresets(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "presets.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if _, err := LoadAcceleratorPresets(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected a missing presets file to fail")
+	}
+
+	p, err := LoadAcceleratorPresets(write(`
+version: 1
+kinds:
+  - match: acme
+    limit: gpu
+presets:
+  - machine_type: acme-highgpu-4g
+    accelerators: 4
+    cpu: "90"
+    memory: 700Gi
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Kinds) != 1 || len(p.Presets) != 1 || p.Presets[0].Memory != "700Gi" {
+		t.Errorf("unexpected presets %+v", p)
+	}
+
+	for content, wantErr := range map[string]string{
+		"presets: []\n":                        "unsupported version 0",
+		"version: 2\n":                         "unsupported version 2",
+		"version: 1\nkinds:\n  - limit: gpu\n": "match is required",
+		"version: 1\nkinds:\n  - match: acme\n    limit: fpga\n":            "limit must be gpu or tpu",
+		"version: 1\npresets:\n  - cpu: \"4\"\n":                            "machine_type is required",
+		"version: 1\npresets:\n  - machine_type: a\n    accelerators: -1\n": "accelerators must not be negative",
+		"version: 1\npresets:\n  - machine_type: a\n    memory: lots\n":     `invalid memory "lots"`,
+		"version: 1\npresets:\n  - machine_type: a\n    gpus: 8\n":          "field gpus not found",
+	} {
+		if _, err := LoadAcceleratorPresets(write(content)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("expected error %q for %q, got %v", wantErr, content, err)
+		}
+	}
+}
+
+func TestRegisterAcceleratorPresets(t *testing.T) {
+	t.Cleanup(ResetAcceleratorPresets)
+
+	if got := AcceleratorLimitFor("nvidia-l4"); got != AcceleratorLimitGPU {
+		t.Errorf("built-in limit of nvidia-l4 = %q, want gpu", got)
+	}
+	if got := AcceleratorLimitFor("tpu-v6e-slice"); got != AcceleratorLimitTPU {
+		t.Errorf("built-in limit of tpu-v6e-slice = %q, want tpu", got)
+	}
+	if got := AcceleratorLimitFor("acme-x1"); got != "" {
+		t.Errorf("expected no limit for an unknown accelerator, got %q", got)
+	}
+
+	RegisterAcceleratorPresets(&AcceleratorPresets{
+		Kinds:   []AcceleratorKind{{Match: "ACME", Limit: AcceleratorLimitGPU}},
+		Presets: []AcceleratorPreset{{MachineType: "acme-highgpu-4g", Accelerators: 4}},
+	})
+	RegisterAcceleratorPresets(&AcceleratorPresets{
+		Presets: []AcceleratorPreset{{MachineType: "ACME-highgpu-4g", Accelerators: 4, CPU: "90"}},
+	})
+	if got := AcceleratorLimitFor("acme-x1"); got != AcceleratorLimitGPU {
+		t.Errorf("registered limit of acme-x1 = %q, want gpu", got)
+	}
+	preset, ok := AcceleratorPresetFor("acme-highgpu-4g")
+	if !ok || preset.CPU != "90" {
+		t.Errorf("expected the later preset to replace the earlier one, got %+v, %v", preset, ok)
+	}
+	if len(activeAcceleratorPresets.Presets) != 1 {
+		t.Errorf("expected one preset, got %+v", activeAcceleratorPresets.Presets)
+	}
+
+	ResetAcceleratorPresets()
+	if _, ok := AcceleratorPresetFor("acme-highgpu-4g"); ok {
+		t.Error("expected the registered presets to be dropped")
+	}
+}
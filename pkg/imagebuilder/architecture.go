@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ImageArchitectures returns the CPU architectures (e.g. "amd64", "arm64") a
+// pushed image can run on. Multi-platform images report every linux platform
+// in their index; single-platform images report the architecture from their
+// config.
+func ImageArchitectures(image string) ([]string, error) {
+	rawManifest, err := registry.Manifest(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest for %s: %w", image, err)
+	}
+
+	var index struct {
+		Manifests []struct {
+			Platform *struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(rawManifest, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest for %s: %w", image, err)
+	}
+
+	if len(index.Manifests) > 0 {
+		seen := map[string]bool{}
+		var archs []string
+		for _, m := range index.Manifests {
+			// Attestation manifests carry an "unknown" platform and are not runnable.
+			if m.Platform == nil || m.Platform.OS != "linux" || seen[m.Platform.Architecture] {
+				continue
+			}
+			seen[m.Platform.Architecture] = true
+			archs = append(archs, m.Platform.Architecture)
+		}
+		sort.Strings(archs)
+		return archs, nil
+	}
+
+	rawConfig, err := registry.Config(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config for %s: %w", image, err)
+	}
+	var cfg struct {
+		Architecture string `json:"architecture"`
+	}
+	if err := json.Unmarshal(rawConfig, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config for %s: %w", image, err)
+	}
+	if cfg.Architecture == "" {
+		return nil, nil
+	}
+	return []string{cfg.Architecture}, nil
+}
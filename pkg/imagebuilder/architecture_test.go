@@ -0,0 +1,56 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mockImageMetadata(t *testing.T, ref, manifest, config string) {
+	t.Helper()
+	if err := fakeRegistry(t).AddManifest(ref, []byte(manifest), []byte(config)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImageArchitectures_Index(t *testing.T) {
+	mockImageMetadata(t, "python:3.11", `{"manifests": [
+		{"platform": {"os": "linux", "architecture": "arm64"}},
+		{"platform": {"os": "linux", "architecture": "amd64"}},
+		{"platform": {"os": "unknown", "architecture": "unknown"}},
+		{"platform": {"os": "linux", "architecture": "amd64"}}
+	]}`, "")
+
+	got, err := ImageArchitectures("python:3.11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"amd64", "arm64"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImageArchitectures() = %v, want %v", got, want)
+	}
+}
+
+func TestImageArchitectures_SinglePlatform(t *testing.T) {
+	mockImageMetadata(t, "example.com/app:arm", `{"config": {"digest": "sha256:abc"}, "layers": []}`, `{"architecture": "arm64", "os": "linux"}`)
+
+	got, err := ImageArchitectures("example.com/app:arm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"arm64"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImageArchitectures() = %v, want %v", got, want)
+	}
+}
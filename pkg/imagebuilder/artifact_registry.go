@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+)
+
+// imageRepoPattern matches an Artifact Registry Docker repository path such as
+// us-central1-docker.pkg.dev/my-project/my-repo or us-docker.pkg.dev/my-project/my-repo.
+var imageRepoPattern = regexp.MustCompile(`^([a-z0-9-]+)-docker\.pkg\.dev/([a-z][a-z0-9:.-]*[a-z0-9])/([a-z0-9][a-z0-9_.-]*)$`)
+
+// ImageRepo is the Artifact Registry Docker repository built images are pushed to.
+type ImageRepo struct {
+	Location   string // Region or multi-region of the repository, e.g. us-central1 or us.
+	Project    string
+	Repository string
+}
+
+// String returns the repository path images are named under.
+func (r ImageRepo) String() string {
+	return fmt.Sprintf("%s-docker.pkg.dev/%s/%s", r.Location, r.Project, r.Repository)
+}
+
+// ParseImageRepo parses a LOCATION-docker.pkg.dev/PROJECT/REPOSITORY path.
+func ParseImageRepo(path string) (ImageRepo, error) {
+	m := imageRepoPattern.FindStringSubmatch(strings.TrimSuffix(path, "/"))
+	if m == nil {
+		return ImageRepo{}, fmt.Errorf("invalid image repository %q: must be an Artifact Registry path of the form LOCATION-docker.pkg.dev/PROJECT/REPOSITORY", path)
+	}
+	return ImageRepo{Location: m[1], Project: m[2], Repository: m[3]}, nil
+}
+
+// ResolveImageRepo returns the repository set with --image-repo or, when it is
+// empty, the GCLUSTER_IMAGE_REPO repository in the cluster's project and region.
+// GCLUSTER_IMAGE_REPO may also hold a full repository path.
+func ResolveImageRepo(imageRepo, project, location string) (ImageRepo, error) {
+	if imageRepo != "" {
+		return ParseImageRepo(imageRepo)
+	}
+	repoName := os.Getenv("GCLUSTER_IMAGE_REPO")
+	if repoName == "" {
+		return ImageRepo{}, fmt.Errorf("GCLUSTER_IMAGE_REPO environment variable is required but not set. Please set it in your environment (e.g., export GCLUSTER_IMAGE_REPO=<repo>) or pass --image-repo")
+	}
+	if strings.Contains(repoName, "/") {
+		return ParseImageRepo(repoName)
+	}
+	return ImageRepo{Location: shell.ExtractRegion(location), Project: project, Repository: repoName}, nil
+}
+
+// EnsureRepository creates the Docker repository in Artifact Registry if it
+// does not exist yet.
+func EnsureRepository(repo ImageRepo) error {
+	res := shell.ExecuteCommand("gcloud", "artifacts", "repositories", "describe", repo.Repository,
+		"--location", repo.Location, "--project", repo.Project, "--format=value(format)")
+	if res.ExitCode == 0 {
+		if format := strings.TrimSpace(res.Stdout); format != "" && format != "DOCKER" {
+			return fmt.Errorf("artifact registry repository %s has format %s; images need a DOCKER repository", repo, format)
+		}
+		return nil
+	}
+
+	logging.Info("Creating Artifact Registry repository %s...", repo)
+	res = shell.ExecuteCommand("gcloud", "artifacts", "repositories", "create", repo.Repository,
+		"--repository-format=docker", "--location", repo.Location, "--project", repo.Project,
+		"--description", "Images built by gcluster job submit", "--quiet")
+	if res.ExitCode != 0 {
+		return fmt.Errorf("failed to create artifact registry repository %s: %s", repo, res.Stderr)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestParseImageRepo(t *testing.T) {
+	for path, want := range map[string]ImageRepo{
+		"us-central1-docker.pkg.dev/my-project/my-repo":  {Location: "us-central1", Project: "my-project", Repository: "my-repo"},
+		"us-docker.pkg.dev/my-project/my-repo/":          {Location: "us", Project: "my-project", Repository: "my-repo"},
+		"europe-west4-docker.pkg.dev/example.com:p/imgs": {Location: "europe-west4", Project: "example.com:p", Repository: "imgs"},
+	} {
+		got, err := ParseImageRepo(path)
+		if err != nil {
+			t.Errorf("ParseImageRepo(%q) error = %v", path, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseImageRepo(%q) = %+v, want %+v", path, got, want)
+		}
+	}
+
+	for _, path := range []string{"gcr.io/my-project", "my-repo", "us-central1-docker.pkg.dev/my-project", "us-central1-docker.pkg.dev/my-project/my-repo/image"} {
+		if _, err := ParseImageRepo(path); err == nil {
+			t.Errorf("expected error for %q", path)
+		}
+	}
+}
+
+func TestImageRepoString(t *testing.T) {
+	repo := ImageRepo{Location: "us-central1", Project: "p", Repository: "r"}
+	if got, want := repo.String(), "us-central1-docker.pkg.dev/p/r"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveImageRepo(t *testing.T) {
+	t.Setenv("GCLUSTER_IMAGE_REPO", "env-repo")
+	got, err := ResolveImageRepo("us-docker.pkg.dev/other/flag-repo", "proj", "us-central1-a")
+	if err != nil || got != (ImageRepo{Location: "us", Project: "other", Repository: "flag-repo"}) {
+		t.Errorf("expected --image-repo to win, got %+v, %v", got, err)
+	}
+
+	got, err = ResolveImageRepo("", "proj", "us-central1-a")
+	if err != nil || got != (ImageRepo{Location: "us-central1", Project: "proj", Repository: "env-repo"}) {
+		t.Errorf("expected GCLUSTER_IMAGE_REPO in the cluster's region, got %+v, %v", got, err)
+	}
+
+	t.Setenv("GCLUSTER_IMAGE_REPO", "asia-docker.pkg.dev/shared/images")
+	got, err = ResolveImageRepo("", "proj", "us-central1-a")
+	if err != nil || got != (ImageRepo{Location: "asia", Project: "shared", Repository: "images"}) {
+		t.Errorf("expected a full GCLUSTER_IMAGE_REPO path, got %+v, %v", got, err)
+	}
+
+	t.Setenv("GCLUSTER_IMAGE_REPO", "")
+	if _, err := ResolveImageRepo("", "proj", "us-central1-a"); err == nil || !strings.Contains(err.Error(), "GCLUSTER_IMAGE_REPO") {
+		t.Errorf("expected a missing repository error, got %v", err)
+	}
+}
+
+func TestEnsureRepository(t *testing.T) {
+	repo := ImageRepo{Location: "us-central1", Project: "proj", Repository: "imgs"}
+	origExec := shell.ExecuteCommand
+	defer func() { shell.ExecuteCommand = origExec }()
+
+	tests := []struct {
+		name     string
+		describe shell.CommandResult
+		create   shell.CommandResult
+		wantErr  string
+		created  bool
+	}{
+		{name: "existing docker repository", describe: shell.CommandResult{ExitCode: 0, Stdout: "DOCKER\n"}},
+		{name: "existing repository of another format", describe: shell.CommandResult{ExitCode: 0, Stdout: "PYTHON\n"}, wantErr: "format PYTHON"},
+		{name: "missing repository is created", describe: shell.CommandResult{ExitCode: 1, Stderr: "NOT_FOUND"}, create: shell.CommandResult{ExitCode: 0}, created: true},
+		{name: "create fails", describe: shell.CommandResult{ExitCode: 1}, create: shell.CommandResult{ExitCode: 1, Stderr: "PERMISSION_DENIED"}, wantErr: "PERMISSION_DENIED", created: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			created := false
+			shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+				switch strings.Join(args[:3], " ") {
+				case "artifacts repositories describe":
+					return tc.describe
+				case "artifacts repositories create":
+					created = true
+					if !strings.Contains(strings.Join(args, " "), "imgs --repository-format=docker --location us-central1 --project proj") {
+						t.Errorf("unexpected create command: %v", args)
+					}
+					return tc.create
+				}
+				t.Fatalf("unexpected command: %s %v", name, args)
+				return shell.CommandResult{}
+			}
+
+			err := EnsureRepository(repo)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("EnsureRepository() error = %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if created != tc.created {
+				t.Errorf("created = %v, want %v", created, tc.created)
+			}
+		})
+	}
+}
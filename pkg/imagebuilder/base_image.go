@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"hpc-toolkit/pkg/localstate"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// baseImageHistoryFile records the digest each base image reference resolved
+// to in the previous build, to detect tags that moved since then.
+var baseImageHistoryFile = localstate.File{Name: "base_images.json", Version: 1}
+
+type baseImageHistory struct {
+	Images map[string]baseImageRecord `json:"images"`
+}
+
+type baseImageRecord struct {
+	Digest   string    `json:"digest"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// BaseImageCheck is the result of checking a base image for freshness.
+type BaseImageCheck struct {
+	Digest  string
+	Created time.Time // Zero if the image does not record its creation time.
+	// Violations explain why the image should be rebuilt on a patched base.
+	Violations []string
+}
+
+// CheckBaseImage resolves baseImage for platformStr and reports it if its tag
+// moved since the previous build, or if maxAge is positive and it was created
+// longer than maxAge ago. For a list of platforms, baseImage resolves to the
+// digest of its index, and the age is that of the variant of the first one.
+func CheckBaseImage(baseImage, platformStr string, maxAge time.Duration) (*BaseImageCheck, error) {
+	platforms, err := parsePlatforms(platformStr)
+	if err != nil {
+		return nil, err
+	}
+	platform := platforms[0]
+	ref, err := name.ParseReference(baseImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base image reference %q: %w", baseImage, err)
+	}
+
+	digestOpts := []crane.Option{crane.WithPlatform(&platform)}
+	if len(platforms) > 1 {
+		digestOpts = nil
+	}
+	digest, err := registry.Digest(ref.String(), digestOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base image digest for %q: %w", baseImage, err)
+	}
+	check := &BaseImageCheck{Digest: digest}
+
+	if _, pinned := ref.(name.Digest); !pinned {
+		var history baseImageHistory
+		if err := baseImageHistoryFile.Load(&history); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if prev, ok := history.Images[baseImageKey(baseImage, platformStr)]; ok && prev.Digest != digest {
+			check.Violations = append(check.Violations, fmt.Sprintf(
+				"base image %s moved from %s to %s since the last build (last used %s); pin %s@%s to keep building on it",
+				baseImage, prev.Digest, digest, prev.LastUsed.Format(time.DateOnly), ref.Context(), digest))
+		}
+	}
+
+	if maxAge <= 0 {
+		return check, nil
+	}
+	raw, err := registry.Config(ref.Context().Digest(digest).String(), crane.WithPlatform(&platform))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config of base image %q: %w", baseImage, err)
+	}
+	config, err := v1.ParseConfigFile(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the config of base image %q: %w", baseImage, err)
+	}
+	// Reproducible builds set the creation time to the Unix epoch, which says
+	// nothing about the age of the image.
+	if created := config.Created.Time; created.Unix() > 0 {
+		check.Created = created
+		if age := clock.Now().Sub(created); age > maxAge {
+			check.Violations = append(check.Violations, fmt.Sprintf(
+				"base image %s was created %d days ago, more than the allowed %d days; rebuild on a patched base image",
+				baseImage, int(age.Hours()/24), int(maxAge.Hours()/24)))
+		}
+	}
+	return check, nil
+}
+
+// RecordBaseImage records the digest baseImage resolved to, so that the next
+// build can tell whether its tag moved.
+func RecordBaseImage(baseImage, platformStr string, check *BaseImageCheck) error {
+	var history baseImageHistory
+	return baseImageHistoryFile.Update(&history, func() error {
+		if history.Images == nil {
+			history.Images = map[string]baseImageRecord{}
+		}
+		history.Images[baseImageKey(baseImage, platformStr)] = baseImageRecord{
+			Digest:   check.Digest,
+			Created:  check.Created,
+			LastUsed: clock.Now().UTC(),
+		}
+		return nil
+	})
+}
+
+// PinnedBaseImage returns baseImage pinned to digest, so that the images built
+// on top of it, and their cache keys, follow the digest rather than the tag.
+func PinnedBaseImage(baseImage, digest string) (string, error) {
+	ref, err := name.ParseReference(baseImage)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base image reference %q: %w", baseImage, err)
+	}
+	return ref.Context().Digest(digest).String(), nil
+}
+
+func baseImageKey(baseImage, platformStr string) string {
+	return baseImage + " " + platformStr
+}
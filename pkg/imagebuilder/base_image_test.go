@@ -0,0 +1,115 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/testutil"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+var baseImageNow = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeBaseImageRegistry installs an empty registry and a clock stopped at
+// baseImageNow.
+func fakeBaseImageRegistry(t *testing.T) *testutil.FakeRegistry {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(SetClock(testutil.NewFakeClock(baseImageNow)))
+	return fakeRegistry(t)
+}
+
+// addBaseImage pushes an image created at created as ref and returns its digest.
+func addBaseImage(t *testing.T, reg *testutil.FakeRegistry, ref string, created time.Time) string {
+	t.Helper()
+	img, err := mutate.CreatedAt(empty.Image, v1.Time{Time: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.AddImage(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.String()
+}
+
+func TestCheckBaseImage_Age(t *testing.T) {
+	reg := fakeBaseImageRegistry(t)
+	digest := addBaseImage(t, reg, "python:3.11", baseImageNow.Add(-40*24*time.Hour))
+
+	check, err := CheckBaseImage("python:3.11", "linux/amd64", 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("CheckBaseImage() error = %v", err)
+	}
+	if check.Digest != digest || len(check.Violations) != 1 || !strings.Contains(check.Violations[0], "created 40 days ago, more than the allowed 30 days") {
+		t.Errorf("expected an age violation, got %+v", check)
+	}
+	if want := "Config index.docker.io/library/python@" + digest; !strings.Contains(strings.Join(reg.Calls(), "\n"), want) {
+		t.Errorf("expected the config of the resolved digest to be read, got %v", reg.Calls())
+	}
+
+	if check, err := CheckBaseImage("python:3.11", "linux/amd64", 60*24*time.Hour); err != nil || len(check.Violations) != 0 {
+		t.Errorf("expected no violation within the allowed age, got %+v, %v", check, err)
+	}
+}
+
+func TestCheckBaseImage_TagMoved(t *testing.T) {
+	reg := fakeBaseImageRegistry(t)
+	first := addBaseImage(t, reg, "python:3.11", baseImageNow)
+
+	check, err := CheckBaseImage("python:3.11", "linux/amd64", 0)
+	if err != nil || len(check.Violations) != 0 {
+		t.Fatalf("expected no violation on the first build, got %+v, %v", check, err)
+	}
+	if err := RecordBaseImage("python:3.11", "linux/amd64", check); err != nil {
+		t.Fatalf("RecordBaseImage() error = %v", err)
+	}
+
+	digest := addBaseImage(t, reg, "python:3.11", baseImageNow.Add(time.Hour))
+	check, err = CheckBaseImage("python:3.11", "linux/amd64", 0)
+	if err != nil {
+		t.Fatalf("CheckBaseImage() error = %v", err)
+	}
+	if len(check.Violations) != 1 || !strings.Contains(check.Violations[0], "moved from "+first) || !strings.Contains(check.Violations[0], "index.docker.io/library/python@"+digest) || !strings.Contains(check.Violations[0], "last used 2026-06-01") {
+		t.Errorf("expected a tag drift violation, got %+v", check)
+	}
+
+	if check, err := CheckBaseImage("python:3.11", "linux/arm64", 0); err != nil || len(check.Violations) != 0 {
+		t.Errorf("expected the digest to be recorded per platform, got %+v, %v", check, err)
+	}
+	if check, err := CheckBaseImage("python@"+digest, "linux/amd64", 0); err != nil || len(check.Violations) != 0 {
+		t.Errorf("expected no drift for a pinned base image, got %+v, %v", check, err)
+	}
+}
+
+func TestPinnedBaseImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("c", 64)
+	got, err := PinnedBaseImage("us-docker.pkg.dev/p/r/base:v1", digest)
+	if err != nil {
+		t.Fatalf("PinnedBaseImage() error = %v", err)
+	}
+	if want := "us-docker.pkg.dev/p/r/base@" + digest; got != want {
+		t.Errorf("PinnedBaseImage() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/stream"
+)
+
+var (
+	// hashWorkers is the number of files of the build context hashed in parallel.
+	hashWorkers = min(runtime.NumCPU(), 16)
+	// progressInterval is how often the progress of hashing and uploading the
+	// build context is logged.
+	progressInterval = 10 * time.Second
+)
+
+// buildContextEntry is a file, directory or symlink of the filtered build context.
+type buildContextEntry struct {
+	path   string
+	header *tar.Header
+	// digest is the SHA-256 of the content of a regular file.
+	digest string
+}
+
+// buildContext is the filtered content of a script directory, which is added
+// to the base image as a single layer.
+type buildContext struct {
+	entries []*buildContextEntry
+	files   int
+	bytes   int64
+	// destDir is the directory of the image, without the leading slash, the
+	// entries are placed under; empty places them at the image root.
+	destDir string
+}
+
+// placeUnder makes the layer of the build context place its entries under
+// the absolute directory destPath of the image instead of at its root.
+func (bc *buildContext) placeUnder(destPath string) {
+	bc.destDir = strings.Trim(path.Clean("/"+destPath), "/")
+}
+
+// workDir returns the directory of the image the build context is placed
+// under, or "" when it is placed at the image root.
+func (bc *buildContext) workDir() string {
+	if bc.destDir == "" {
+		return ""
+	}
+	return "/" + bc.destDir
+}
+
+// layerName returns the name of e in the layer of the build context.
+func (bc *buildContext) layerName(e *buildContextEntry) string {
+	if bc.destDir == "" {
+		return e.header.Name
+	}
+	return bc.destDir + "/" + e.header.Name
+}
+
+// scanBuildContext walks sourceDir, skipping the paths matched by
+// ignoreMatcher, and hashes the files it contains with a pool of workers.
+func scanBuildContext(sourceDir string, ignoreMatcher *IgnoreMatcher) (*buildContext, error) {
+	bc := &buildContext{}
+	err := filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, walkDirErr error) error {
+		entry, err := newBuildContextEntry(sourceDir, ignoreMatcher, path, d, walkDirErr)
+		if err != nil || entry == nil {
+			return err
+		}
+		bc.entries = append(bc.entries, entry)
+		if entry.header.Typeflag == tar.TypeReg {
+			bc.files++
+			bc.bytes += entry.header.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Info("Build context %s: %d files, %s", sourceDir, bc.files, formatSize(bc.bytes))
+	if err := bc.hashFiles(); err != nil {
+		return nil, err
+	}
+	return bc, nil
+}
+
+func newBuildContextEntry(sourceDir string, ignoreMatcher *IgnoreMatcher, path string, d fs.DirEntry, errFromWalk error) (*buildContextEntry, error) {
+	if errFromWalk != nil {
+		return nil, errFromWalk
+	}
+
+	relPath, err := filepath.Rel(sourceDir, path)
+	if err != nil || relPath == "." {
+		return nil, err
+	}
+
+	ignored, err := ignoreMatcher.Ignored(relPath, d.IsDir())
+	if err != nil {
+		return nil, err
+	}
+	if ignored {
+		if d.IsDir() && ignoreMatcher.SkipDir(relPath) {
+			return nil, filepath.SkipDir
+		}
+		return nil, nil
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for %q: %w", path, err)
+	}
+
+	var linkTarget string
+	if info.Mode()&os.ModeSymlink != 0 {
+		linkTarget, err = os.Readlink(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read link for %q: %w", path, err)
+		}
+	}
+
+	header, err := tar.FileInfoHeader(info, linkTarget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tar header for %q: %w", path, err)
+	}
+	header.Name = filepath.ToSlash(relPath)
+	return &buildContextEntry{path: path, header: header}, nil
+}
+
+// hashFiles computes the digest of every regular file in parallel.
+func (bc *buildContext) hashFiles() error {
+	jobs := make(chan *buildContextEntry)
+	errs := make(chan error, hashWorkers)
+	p := newProgress("Hashing build context", bc.files, bc.bytes)
+	var wg sync.WaitGroup
+
+	for w := 0; w < hashWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				digest, err := hashFile(entry.path)
+				if err != nil {
+					errs <- err
+					// Drain the remaining jobs so that the feeder is not blocked.
+					for range jobs {
+					}
+					return
+				}
+				entry.digest = digest
+				p.add(entry.header.Size)
+			}
+		}()
+	}
+
+	for _, entry := range bc.entries {
+		if entry.header.Typeflag == tar.TypeReg {
+			jobs <- entry
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %q: %w", path, err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash file %q: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cacheKey returns the content address of an image built from the build
+// context on top of the base image with baseDigest. Only entry names, types,
+// modes, link targets and file contents are hashed, so touching a file without
+// changing it does not invalidate the cache. The settings of cfg are hashed
+// too.
+func (bc *buildContext) cacheKey(baseDigest, platformStr string, cfg ImageConfig) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", baseDigest, platformStr)
+	if bc.destDir != "" {
+		fmt.Fprintf(h, "workdir /%s\n", bc.destDir)
+	}
+	cfg.writeKey(h)
+	for _, e := range bc.entries {
+		fmt.Fprintf(h, "%s\x00%c\x00%o\x00%s\x00%d\x00%s\n", bc.layerName(e), e.header.Typeflag, e.header.Mode, e.header.Linkname, e.header.Size, e.digest)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// writeTar writes the build context to w as an uncompressed tarball. When it
+// is placed under a directory, that directory and its parents come first.
+func (bc *buildContext) writeTar(w io.Writer) error {
+	tarWriter := tar.NewWriter(w)
+	p := newProgress("Uploading build context", bc.files, bc.bytes)
+	if bc.destDir != "" {
+		var dir string
+		for _, part := range strings.Split(bc.destDir, "/") {
+			dir = path.Join(dir, part)
+			hdr := &tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0755, ModTime: time.Unix(0, 0)}
+			if err := tarWriter.WriteHeader(hdr); err != nil {
+				return fmt.Errorf("failed to write tar header for %q: %w", dir, err)
+			}
+		}
+	}
+	for _, e := range bc.entries {
+		header := e.header
+		if bc.destDir != "" {
+			copied := *e.header
+			copied.Name = bc.layerName(e)
+			header = &copied
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write tar header for %q: %w", e.path, err)
+		}
+		if e.header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := writeFileContent(tarWriter, e.path, e.header.Size); err != nil {
+			return err
+		}
+		p.add(e.header.Size)
+	}
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close tar writer: %w", err)
+	}
+	return nil
+}
+
+// layer returns a layer that streams the build context, compressing it as it
+// is uploaded, so that no copy of the build context is written to disk. The
+// layer can only be consumed once; the returned function must be called once
+// the image was pushed to release the goroutine writing the tarball.
+func (bc *buildContext) layer() (v1.Layer, func()) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(bc.writeTar(pw))
+	}()
+	return stream.NewLayer(pr), func() { pr.Close() }
+}
+
+func writeFileContent(tarWriter *tar.Writer, path string, size int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file %q: %w", path, err)
+	}
+	defer file.Close()
+
+	// The header was written with the size seen while walking the directory,
+	// so a file that shrank since then cannot be added.
+	if _, err := io.CopyN(tarWriter, file, size); err != nil {
+		return fmt.Errorf("failed to write file content for %q (was it modified during the build?): %w", path, err)
+	}
+	return nil
+}
+
+// progress logs the number of files and bytes processed by a step of the
+// build, at most once per progressInterval and when the step completes.
+type progress struct {
+	action     string
+	totalFiles int
+	totalBytes int64
+
+	mu      sync.Mutex
+	files   int
+	bytes   int64
+	lastLog time.Time
+}
+
+func newProgress(action string, totalFiles int, totalBytes int64) *progress {
+	return &progress{action: action, totalFiles: totalFiles, totalBytes: totalBytes, lastLog: clock.Now()}
+}
+
+func (p *progress) add(bytes int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files++
+	p.bytes += bytes
+	if p.files == p.totalFiles || clock.Now().Sub(p.lastLog) >= progressInterval {
+		p.lastLog = clock.Now()
+		logging.Debug("%s: %d/%d files, %s/%s", p.action, p.files, p.totalFiles, formatSize(p.bytes), formatSize(p.totalBytes))
+	}
+}
+
+// formatSize renders n bytes in MiB.
+func formatSize(n int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+)
+
+// Sources of a captured local environment.
+const (
+	CondaEnvSource = "conda"
+	VenvEnvSource  = "venv"
+)
+
+// CapturedEnv is a local Python environment exported to a requirements file,
+// so that it can be installed into a dependency image.
+type CapturedEnv struct {
+	Source string // One of CondaEnvSource and VenvEnvSource.
+	Name   string // Name of the conda environment, or path of the virtualenv.
+	Path   string // Exported requirements file: environment.yml or requirements.txt.
+	Digest string // sha256 of the exported requirements file.
+}
+
+// String returns the environment in the conda:<name> or venv:<path> form it
+// was requested in.
+func (e CapturedEnv) String() string {
+	return e.Source + ":" + e.Name
+}
+
+// ParseCaptureEnv parses a conda:<name> or venv:<path> environment reference.
+func ParseCaptureEnv(ref string) (CapturedEnv, error) {
+	source, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" || (source != CondaEnvSource && source != VenvEnvSource) {
+		return CapturedEnv{}, fmt.Errorf("invalid environment %q: must be 'conda:<name>' or 'venv:<path>'", ref)
+	}
+	return CapturedEnv{Source: source, Name: name}, nil
+}
+
+// CaptureEnvironment exports the packages of the local environment ref into a
+// requirements file in dir: `conda env export` for a conda environment, or
+// `pip freeze` for a virtualenv.
+func CaptureEnvironment(ref, dir string) (CapturedEnv, error) {
+	env, err := ParseCaptureEnv(ref)
+	if err != nil {
+		return CapturedEnv{}, err
+	}
+
+	var name, spec string
+	switch env.Source {
+	case CondaEnvSource:
+		res := shell.ExecuteCommand("conda", "env", "export", "--name", env.Name, "--no-builds")
+		if res.ExitCode != 0 {
+			return CapturedEnv{}, fmt.Errorf("failed to export conda environment %q: %s", env.Name, res.Stderr)
+		}
+		name, spec = "environment.yml", condaEnvSpec(res.Stdout)
+	case VenvEnvSource:
+		python := filepath.Join(env.Name, "bin", "python")
+		if _, err := os.Stat(python); err != nil {
+			return CapturedEnv{}, fmt.Errorf("%s is not a virtualenv: %w", env.Name, err)
+		}
+		res := shell.ExecuteCommand(python, "-m", "pip", "freeze", "--exclude-editable")
+		if res.ExitCode != 0 {
+			return CapturedEnv{}, fmt.Errorf("failed to freeze virtualenv %s: %s", env.Name, res.Stderr)
+		}
+		name, spec = "requirements.txt", pipEnvSpec(res.Stdout)
+	}
+
+	sum := sha256.Sum256([]byte(spec))
+	env.Path, env.Digest = filepath.Join(dir, name), "sha256:"+hex.EncodeToString(sum[:])
+	if err := os.WriteFile(env.Path, []byte(spec), 0644); err != nil {
+		return CapturedEnv{}, fmt.Errorf("failed to write the captured environment: %w", err)
+	}
+	logging.Info("Captured environment %s into %s", env, env.Path)
+	return env, nil
+}
+
+// condaEnvSpec drops the name and prefix of an exported conda environment,
+// which refer to the local machine; the packages are installed into the
+// image's base environment.
+func condaEnvSpec(export string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(export, "\n") {
+		if strings.HasPrefix(line, "name:") || strings.HasPrefix(line, "prefix:") {
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// pipEnvSpec drops the packages of a pip freeze that were installed from
+// local paths, which cannot be installed in the image.
+func pipEnvSpec(freeze string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(freeze, "\n") {
+		if strings.Contains(line, " @ file://") {
+			logging.Warn("Skipping %s, which was installed from a local path; add it to the build context instead.", strings.TrimSpace(line))
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestParseCaptureEnv(t *testing.T) {
+	for ref, want := range map[string]CapturedEnv{
+		"conda:ml":           {Source: CondaEnvSource, Name: "ml"},
+		"venv:/home/a/.venv": {Source: VenvEnvSource, Name: "/home/a/.venv"},
+	} {
+		got, err := ParseCaptureEnv(ref)
+		if err != nil || got != want {
+			t.Errorf("ParseCaptureEnv(%q) = %+v, %v; want %+v", ref, got, err, want)
+		}
+		if got.String() != ref {
+			t.Errorf("String() = %q, want %q", got.String(), ref)
+		}
+	}
+	for _, ref := range []string{"", "ml", "conda:", "poetry:ml"} {
+		if _, err := ParseCaptureEnv(ref); err == nil {
+			t.Errorf("expected error for %q", ref)
+		}
+	}
+}
+
+func TestCaptureEnvironment(t *testing.T) {
+	origExec := shell.ExecuteCommand
+	defer func() { shell.ExecuteCommand = origExec }()
+
+	t.Run("conda", func(t *testing.T) {
+		shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+			if name != "conda" || strings.Join(args, " ") != "env export --name ml --no-builds" {
+				t.Fatalf("unexpected command: %s %v", name, args)
+			}
+			return shell.CommandResult{Stdout: "name: ml\nchannels:\n  - conda-forge\ndependencies:\n  - numpy=2.0.0\nprefix: /home/a/miniconda3/envs/ml\n"}
+		}
+		env, err := CaptureEnvironment("conda:ml", t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec, _ := os.ReadFile(env.Path)
+		if want := "channels:\n  - conda-forge\ndependencies:\n  - numpy=2.0.0\n"; string(spec) != want {
+			t.Errorf("captured spec = %q, want %q", spec, want)
+		}
+		if filepath.Base(env.Path) != "environment.yml" || DetectRequirementsKind(env.Path) != CondaRequirements {
+			t.Errorf("expected a conda environment file, got %s", env.Path)
+		}
+		if !strings.HasPrefix(env.Digest, "sha256:") {
+			t.Errorf("unexpected digest %q", env.Digest)
+		}
+	})
+
+	t.Run("venv", func(t *testing.T) {
+		venv := t.TempDir()
+		python := filepath.Join(venv, "bin", "python")
+		if err := os.MkdirAll(filepath.Dir(python), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(python, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+		shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+			if name != python || strings.Join(args, " ") != "-m pip freeze --exclude-editable" {
+				t.Fatalf("unexpected command: %s %v", name, args)
+			}
+			return shell.CommandResult{Stdout: "numpy==2.0.0\nmylib @ file:///home/a/mylib\ntorch==2.4.0\n"}
+		}
+		env, err := CaptureEnvironment("venv:"+venv, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec, _ := os.ReadFile(env.Path)
+		if want := "numpy==2.0.0\ntorch==2.4.0\n"; string(spec) != want {
+			t.Errorf("captured spec = %q, want %q", spec, want)
+		}
+		if DetectRequirementsKind(env.Path) != PipRequirements {
+			t.Errorf("expected a pip requirements file, got %s", env.Path)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+			return shell.CommandResult{ExitCode: 1, Stderr: "EnvironmentLocationNotFound"}
+		}
+		if _, err := CaptureEnvironment("conda:missing", t.TempDir()); err == nil || !strings.Contains(err.Error(), "EnvironmentLocationNotFound") {
+			t.Errorf("expected the conda error, got %v", err)
+		}
+		if _, err := CaptureEnvironment("venv:"+t.TempDir(), t.TempDir()); err == nil || !strings.Contains(err.Error(), "is not a virtualenv") {
+			t.Errorf("expected a virtualenv error, got %v", err)
+		}
+	})
+}
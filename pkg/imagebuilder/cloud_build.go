@@ -0,0 +1,288 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+
+	cloudbuild "google.golang.org/api/cloudbuild/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	storage "google.golang.org/api/storage/v1"
+)
+
+var (
+	// cloudAPIOptions are the client options of the Cloud Build and Cloud
+	// Storage APIs. Tests point them at a fake server.
+	cloudAPIOptions []option.ClientOption
+	// cloudBuildPollInterval is how often the status and the new log lines of
+	// a running build are fetched.
+	cloudBuildPollInterval = 3 * time.Second
+)
+
+// CloudBuildResult is a successful Cloud Build build.
+type CloudBuildResult struct {
+	BuildID string
+	// ImageDigests maps the images the build pushed to their digests.
+	ImageDigests map[string]string
+	LogURL       string
+}
+
+// dockerBuildSteps returns the Cloud Build steps that build dockerfile, a path
+// relative to the uploaded source, for platform into the local image. They
+// build with buildx so that non-amd64 platforms can be produced on the amd64
+// Cloud Build workers through QEMU emulation. The image is loaded into the
+// worker's Docker daemon for Cloud Build to push it and report its digest.
+func dockerBuildSteps(image, dockerfile, platformStr string) []*cloudbuild.BuildStep {
+	var steps []*cloudbuild.BuildStep
+	if platformStr != string(LinuxAMD64) {
+		steps = append(steps, &cloudbuild.BuildStep{Name: "gcr.io/cloud-builders/docker", Args: []string{"run", "--privileged", "tonistiigi/binfmt", "--install", "all"}})
+	}
+	return append(steps,
+		&cloudbuild.BuildStep{Name: "gcr.io/cloud-builders/docker", Args: []string{"buildx", "create", "--name", "gcluster", "--use"}},
+		&cloudbuild.BuildStep{Name: "gcr.io/cloud-builders/docker", Args: []string{"buildx", "build", "--platform", platformStr, "-f", filepath.ToSlash(dockerfile), "-t", image, "--load", "."}},
+	)
+}
+
+// SubmitCloudBuild uploads sourceDir, without the paths its .dockerignore
+// excludes, and runs steps on it with Cloud Build in project, in the region
+// of location. The logs of the build are streamed to the info log until it
+// finishes; Cloud Build then pushes images. The build is canceled once ctx is
+// done.
+func SubmitCloudBuild(ctx context.Context, project, location, sourceDir string, steps []*cloudbuild.BuildStep, images []string) (CloudBuildResult, error) {
+	if err := ctx.Err(); err != nil {
+		return CloudBuildResult{}, fmt.Errorf("cloud build canceled: %w", err)
+	}
+	cb, err := cloudbuild.NewService(ctx, cloudAPIOptions...)
+	if err != nil {
+		return CloudBuildResult{}, fmt.Errorf("failed to initialize the Cloud Build client: %w", err)
+	}
+	gcs, err := storage.NewService(ctx, cloudAPIOptions...)
+	if err != nil {
+		return CloudBuildResult{}, fmt.Errorf("failed to initialize the Cloud Storage client: %w", err)
+	}
+
+	source, err := uploadBuildSource(ctx, gcs, project, sourceDir)
+	if err != nil {
+		return CloudBuildResult{}, err
+	}
+	parent := fmt.Sprintf("projects/%s/locations/%s", project, shell.ExtractRegion(location))
+	op, err := cb.Projects.Locations.Builds.Create(parent, &cloudbuild.Build{
+		Source: &cloudbuild.Source{StorageSource: source},
+		Steps:  steps,
+		Images: images,
+	}).Context(ctx).Do()
+	if err != nil {
+		return CloudBuildResult{}, fmt.Errorf("failed to start cloud build: %w", err)
+	}
+	var meta cloudbuild.BuildOperationMetadata
+	if err := json.Unmarshal(op.Metadata, &meta); err != nil || meta.Build == nil || meta.Build.Id == "" {
+		return CloudBuildResult{}, fmt.Errorf("cloud build operation %s did not return a build ID", op.Name)
+	}
+	logging.Info("Started Cloud Build %s. Logs are available at %s", meta.Build.Id, meta.Build.LogUrl)
+
+	build, err := waitForBuild(ctx, cb, gcs, parent+"/builds/"+meta.Build.Id)
+	if err != nil {
+		return CloudBuildResult{}, err
+	}
+	res := CloudBuildResult{BuildID: build.Id, LogURL: build.LogUrl, ImageDigests: map[string]string{}}
+	if build.Results != nil {
+		for _, img := range build.Results.Images {
+			res.ImageDigests[img.Name] = img.Digest
+		}
+	}
+	for _, img := range images {
+		if res.ImageDigests[img] == "" {
+			return res, fmt.Errorf("cloud build %s succeeded but did not report the digest of %s", build.Id, img)
+		}
+	}
+	return res, nil
+}
+
+// waitForBuild polls the build called name until it finishes, streaming its
+// log, and returns it once it succeeded.
+func waitForBuild(ctx context.Context, cb *cloudbuild.Service, gcs *storage.Service, name string) (*cloudbuild.Build, error) {
+	logs := &buildLogStreamer{gcs: gcs, out: logging.InfoOutput()}
+	for {
+		build, err := cb.Projects.Locations.Builds.Get(name).Context(context.WithoutCancel(ctx)).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the status of cloud build %s: %w", name, err)
+		}
+		logs.stream(ctx, build)
+
+		switch build.Status {
+		case "SUCCESS":
+			return build, nil
+		case "FAILURE", "INTERNAL_ERROR", "TIMEOUT", "CANCELLED", "EXPIRED":
+			return nil, fmt.Errorf("cloud build %s failed with status %s: %s\nSee the logs at %s", build.Id, build.Status, build.StatusDetail, build.LogUrl)
+		}
+
+		if ctx.Err() != nil {
+			logging.Info("Canceling cloud build %s...", build.Id)
+			if _, err := cb.Projects.Locations.Builds.Cancel(name, &cloudbuild.CancelBuildRequest{}).Context(context.WithoutCancel(ctx)).Do(); err != nil {
+				logging.Warn("Failed to cancel cloud build %s: %v", build.Id, err)
+			}
+			return nil, fmt.Errorf("cloud build canceled: %w", ctx.Err())
+		}
+		clock.Sleep(cloudBuildPollInterval)
+	}
+}
+
+// buildLogStreamer copies the log of a build to out as it grows. Cloud Build
+// writes it to log-<build ID>.txt in the logs bucket of the build.
+type buildLogStreamer struct {
+	gcs      *storage.Service
+	out      io.Writer
+	offset   int64
+	disabled bool
+}
+
+// stream copies the lines written to the log of build since the last call.
+// When the log cannot be read, e.g. because it is kept in Cloud Logging only
+// or the caller lacks access to the bucket, streaming stops with a warning
+// and the build is only followed by its status.
+func (s *buildLogStreamer) stream(ctx context.Context, build *cloudbuild.Build) {
+	if s.disabled || build.LogsBucket == "" {
+		return
+	}
+	bucket := strings.TrimPrefix(build.LogsBucket, "gs://")
+	object := fmt.Sprintf("log-%s.txt", build.Id)
+	call := s.gcs.Objects.Get(bucket, object).Context(context.WithoutCancel(ctx))
+	call.Header().Set("Range", fmt.Sprintf("bytes=%d-", s.offset))
+	resp, err := call.Download()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusRequestedRangeNotSatisfiable) {
+			// The log is not written yet, or has no new lines.
+			return
+		}
+		logging.Warn("Cannot stream the log of cloud build %s (%v); follow it at %s", build.Id, err, build.LogUrl)
+		s.disabled = true
+		return
+	}
+	defer resp.Body.Close()
+	n, _ := io.Copy(s.out, resp.Body)
+	s.offset += n
+}
+
+// cloudBuildSourceBucket is the bucket build sources are uploaded to, the
+// one 'gcloud builds submit' uses.
+func cloudBuildSourceBucket(project string) string {
+	return project + "_cloudbuild"
+}
+
+// uploadBuildSource uploads sourceDir as a gzipped tarball to the Cloud
+// Build source bucket of project, creating the bucket if needed.
+func uploadBuildSource(ctx context.Context, gcs *storage.Service, project, sourceDir string) (*cloudbuild.StorageSource, error) {
+	ignoreMatcher, err := ReadDockerignorePatterns(sourceDir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore patterns: %w", err)
+	}
+	bc, err := scanBuildContext(sourceDir, ignoreMatcher)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := cloudBuildSourceBucket(project)
+	if _, err := gcs.Buckets.Get(bucket).Context(ctx).Do(); err != nil {
+		var apiErr *googleapi.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound {
+			return nil, fmt.Errorf("failed to look up cloud build source bucket gs://%s: %w", bucket, err)
+		}
+		logging.Info("Creating cloud build source bucket gs://%s...", bucket)
+		if _, err := gcs.Buckets.Insert(project, &storage.Bucket{Name: bucket}).Context(ctx).Do(); err != nil {
+			return nil, fmt.Errorf("failed to create cloud build source bucket gs://%s: %w", bucket, err)
+		}
+	}
+
+	suffix, err := shell.RandomString(8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the name of the build source: %w", err)
+	}
+	object := fmt.Sprintf("source/%d-%s.tgz", clock.Now().Unix(), suffix)
+
+	pr, pw := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(pw)
+		err := bc.writeTar(gz)
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+	defer pr.Close()
+	uploaded, err := gcs.Objects.Insert(bucket, &storage.Object{Name: object, ContentType: "application/gzip"}).Media(pr).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload build source to gs://%s/%s: %w", bucket, object, err)
+	}
+	return &cloudbuild.StorageSource{Bucket: bucket, Object: object, Generation: uploaded.Generation}, nil
+}
+
+// DockerfileInContext returns the path of dockerfile relative to
+// buildContext, which Cloud Build uploads, and fails when it is outside of it.
+func DockerfileInContext(buildContext, dockerfile string) (string, error) {
+	absContext, err := filepath.Abs(buildContext)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve build context %q: %w", buildContext, err)
+	}
+	absDockerfile, err := filepath.Abs(dockerfile)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve Dockerfile %q: %w", dockerfile, err)
+	}
+	rel, err := filepath.Rel(absContext, absDockerfile)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Dockerfile %s must be inside the build context %s", dockerfile, buildContext)
+	}
+	return rel, nil
+}
+
+// BuildImageWithCloudBuild builds dockerfile on buildContext with Cloud Build
+// in project, in the region of location, and returns the image it pushed to
+// repo, tagged as selected by tag and pinned to its digest. It waits for the
+// build to finish.
+func BuildImageWithCloudBuild(ctx context.Context, project, location string, repo ImageRepo, buildContext, dockerfile, platformStr string, tag ImageTag) (string, error) {
+	if _, err := parsePlatform(platformStr); err != nil {
+		return "", err
+	}
+	rel, err := DockerfileInContext(buildContext, dockerfile)
+	if err != nil {
+		return "", err
+	}
+	image, err := dockerfileImageName(repo, tag, buildContext, rel, platformStr)
+	if err != nil {
+		return "", err
+	}
+
+	logging.Info("Building %s from %s with Cloud Build...", image, dockerfile)
+	res, err := SubmitCloudBuild(ctx, project, location, buildContext, dockerBuildSteps(image, rel, platformStr), []string{image})
+	if err != nil {
+		return "", err
+	}
+	digest := res.ImageDigests[image]
+	logging.Info("Image %s (%s) built and uploaded successfully by Cloud Build %s.", image, digest, res.BuildID)
+	return image + "@" + digest, nil
+}
@@ -0,0 +1,305 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/testutil"
+
+	cloudbuild "google.golang.org/api/cloudbuild/v1"
+	"google.golang.org/api/option"
+)
+
+// fakeCloudBuild serves the Cloud Build and Cloud Storage requests of a
+// build. Each status poll returns the next of statuses, and reveals the next
+// line of log.
+type fakeCloudBuild struct {
+	t        *testing.T
+	statuses []string
+	log      []string
+	// logErr makes reading the log fail with this status code.
+	logErr int
+
+	mu       sync.Mutex
+	build    cloudbuild.Build
+	source   map[string]string
+	polls    int
+	canceled bool
+	buckets  []string
+}
+
+func newFakeCloudBuild(t *testing.T, statuses ...string) *fakeCloudBuild {
+	t.Helper()
+	f := &fakeCloudBuild{t: t, statuses: statuses}
+	ts := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(ts.Close)
+	oldOptions, oldInterval := cloudAPIOptions, cloudBuildPollInterval
+	t.Cleanup(func() { cloudAPIOptions, cloudBuildPollInterval = oldOptions, oldInterval })
+	cloudAPIOptions = []option.ClientOption{option.WithEndpoint(ts.URL + "/"), option.WithHTTPClient(ts.Client())}
+	t.Cleanup(SetClock(testutil.NewFakeClock(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))))
+	return f
+}
+
+func (f *fakeCloudBuild) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	const buildPath = "/v1/projects/proj/locations/us-central1/builds"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/b/proj_cloudbuild":
+		if len(f.buckets) == 0 {
+			http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"name":"proj_cloudbuild"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/b":
+		f.buckets = append(f.buckets, r.URL.Query().Get("project"))
+		fmt.Fprint(w, `{"name":"proj_cloudbuild"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/proj_cloudbuild/o":
+		f.source = readUploadedSource(f.t, r)
+		fmt.Fprint(w, `{"name":"source/src.tgz","bucket":"proj_cloudbuild","generation":"7"}`)
+	case r.Method == http.MethodPost && r.URL.Path == buildPath:
+		if err := json.NewDecoder(r.Body).Decode(&f.build); err != nil {
+			f.t.Errorf("failed to decode build: %v", err)
+		}
+		fmt.Fprint(w, `{"name":"operations/op1","metadata":{"@type":"type.googleapis.com/google.devtools.cloudbuild.v1.BuildOperationMetadata","build":{"id":"b1","logUrl":"https://console.cloud.google.com/b1"}}}`)
+	case r.Method == http.MethodGet && r.URL.Path == buildPath+"/b1":
+		status := f.statuses[min(f.polls, len(f.statuses)-1)]
+		f.polls++
+		build := map[string]any{"id": "b1", "status": status, "statusDetail": "step 2 exited with 1", "logUrl": "https://console.cloud.google.com/b1", "logsBucket": "gs://logs"}
+		if status == "SUCCESS" {
+			var images []map[string]string
+			for _, img := range f.build.Images {
+				images = append(images, map[string]string{"name": img, "digest": "sha256:abc"})
+			}
+			build["results"] = map[string]any{"images": images}
+		}
+		json.NewEncoder(w).Encode(build)
+	case r.Method == http.MethodPost && r.URL.Path == buildPath+"/b1:cancel":
+		f.canceled = true
+		fmt.Fprint(w, `{"id":"b1","status":"CANCELLED"}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/b/logs/o/log-b1.txt":
+		if f.logErr != 0 {
+			http.Error(w, `{"error":{"code":403,"message":"denied"}}`, f.logErr)
+			return
+		}
+		content := strings.Join(f.log[:min(f.polls, len(f.log))], "")
+		var offset int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset)
+		if offset >= len(content) {
+			http.Error(w, "", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		fmt.Fprint(w, content[offset:])
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+// readUploadedSource returns the files of the gzipped tarball of a multipart
+// upload.
+func readUploadedSource(t *testing.T, r *http.Request) map[string]string {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		t.Errorf("failed to parse upload content type: %v", err)
+		return nil
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	if _, err := mr.NextPart(); err != nil { // The object metadata.
+		t.Errorf("failed to read upload metadata: %v", err)
+		return nil
+	}
+	media, err := mr.NextPart()
+	if err != nil {
+		t.Errorf("failed to read upload media: %v", err)
+		return nil
+	}
+	gz, err := gzip.NewReader(media)
+	if err != nil {
+		t.Errorf("uploaded source is not gzipped: %v", err)
+		return nil
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Errorf("uploaded source is not a tarball: %v", err)
+			return nil
+		}
+		data, _ := io.ReadAll(tr)
+		files[h.Name] = string(data)
+	}
+}
+
+func TestDockerBuildSteps(t *testing.T) {
+	amd := dockerBuildSteps("us-docker.pkg.dev/p/r/img:tag", "docker/Dockerfile.gpu", "linux/amd64")
+	want := "buildx build --platform linux/amd64 -f docker/Dockerfile.gpu -t us-docker.pkg.dev/p/r/img:tag --load ."
+	if len(amd) != 2 || strings.Join(amd[1].Args, " ") != want {
+		t.Errorf("unexpected steps %+v", amd)
+	}
+	if arm := dockerBuildSteps("img", "Dockerfile", "linux/arm64"); len(arm) != 3 || arm[0].Args[1] != "--privileged" {
+		t.Errorf("expected emulation to be installed for linux/arm64, got %+v", arm)
+	}
+}
+
+func TestDockerfileInContext(t *testing.T) {
+	dir := t.TempDir()
+	rel, err := DockerfileInContext(dir, filepath.Join(dir, "docker", "Dockerfile"))
+	if err != nil {
+		t.Fatalf("DockerfileInContext() error = %v", err)
+	}
+	if want := filepath.Join("docker", "Dockerfile"); rel != want {
+		t.Errorf("DockerfileInContext() = %q, want %q", rel, want)
+	}
+	if _, err := DockerfileInContext(filepath.Join(dir, "src"), filepath.Join(dir, "Dockerfile")); err == nil || !strings.Contains(err.Error(), "must be inside the build context") {
+		t.Errorf("expected a Dockerfile outside the build context to be rejected, got %v", err)
+	}
+}
+
+func writeBuildContext(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func captureInfoLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	old := logging.InfoOutput()
+	logging.SetInfoOutput(&buf)
+	t.Cleanup(func() { logging.SetInfoOutput(old) })
+	return &buf
+}
+
+func TestSubmitCloudBuild(t *testing.T) {
+	f := newFakeCloudBuild(t, "QUEUED", "WORKING", "SUCCESS")
+	f.log = []string{"Step #0: starting\n", "Step #1: building\n", "DONE\n"}
+	dir := writeBuildContext(t, map[string]string{"Dockerfile": "FROM python:3.11\n", "app.py": "print(1)\n", "secret.env": "x", ".dockerignore": "*.env\n"})
+	logs := captureInfoLog(t)
+
+	steps := dockerBuildSteps("img:tag", "Dockerfile", "linux/amd64")
+	res, err := SubmitCloudBuild(context.Background(), "proj", "us-central1-a", dir, steps, []string{"img:tag"})
+	if err != nil {
+		t.Fatalf("SubmitCloudBuild() error = %v", err)
+	}
+	if res.BuildID != "b1" || res.ImageDigests["img:tag"] != "sha256:abc" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if len(f.buckets) != 1 || f.buckets[0] != "proj" {
+		t.Errorf("expected the source bucket to be created in proj, got %v", f.buckets)
+	}
+	if f.source["app.py"] != "print(1)\n" || f.source["Dockerfile"] == "" {
+		t.Errorf("expected the build context to be uploaded, got %v", f.source)
+	}
+	if _, ok := f.source["secret.env"]; ok {
+		t.Errorf("expected .dockerignore to exclude secret.env from the upload")
+	}
+	if src := f.build.Source.StorageSource; src.Bucket != "proj_cloudbuild" || src.Generation != 7 {
+		t.Errorf("unexpected build source %+v", src)
+	}
+	if len(f.build.Steps) != 2 || len(f.build.Images) != 1 {
+		t.Errorf("unexpected build %+v", f.build)
+	}
+	if !strings.Contains(logs.String(), "Step #0: starting\nStep #1: building\nDONE\n") {
+		t.Errorf("expected the build log to be streamed once, got:\n%s", logs.String())
+	}
+}
+
+func TestSubmitCloudBuild_Failure(t *testing.T) {
+	f := newFakeCloudBuild(t, "WORKING", "FAILURE")
+	f.logErr = http.StatusForbidden
+	captureInfoLog(t)
+	dir := writeBuildContext(t, map[string]string{"Dockerfile": "FROM scratch\n"})
+
+	_, err := SubmitCloudBuild(context.Background(), "proj", "us-central1", dir, nil, []string{"img:tag"})
+	if err == nil || !strings.Contains(err.Error(), "cloud build b1 failed with status FAILURE: step 2 exited with 1") {
+		t.Errorf("expected the build failure to be reported, got %v", err)
+	}
+	if f.polls != 2 {
+		t.Errorf("expected an unreadable log not to stop following the build, got %d polls", f.polls)
+	}
+}
+
+func TestSubmitCloudBuild_Canceled(t *testing.T) {
+	f := newFakeCloudBuild(t, "WORKING")
+	captureInfoLog(t)
+	dir := writeBuildContext(t, map[string]string{"Dockerfile": "FROM scratch\n"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	SetClock(cancelingClock{FakeClock: testutil.NewFakeClock(time.Now()), cancel: cancel})
+
+	_, err := SubmitCloudBuild(ctx, "proj", "us-central1", dir, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "cloud build canceled") || !f.canceled {
+		t.Errorf("expected the build to be canceled, got %v (canceled: %v)", err, f.canceled)
+	}
+}
+
+// cancelingClock cancels the build on its first wait between polls.
+type cancelingClock struct {
+	*testutil.FakeClock
+	cancel context.CancelFunc
+}
+
+func (c cancelingClock) Sleep(d time.Duration) {
+	c.cancel()
+	c.FakeClock.Sleep(d)
+}
+
+func TestBuildImageWithCloudBuild(t *testing.T) {
+	t.Setenv("USER", "tester")
+	f := newFakeCloudBuild(t, "SUCCESS")
+	captureInfoLog(t)
+	repo := ImageRepo{Location: "us-central1", Project: "proj", Repository: "my-repo"}
+	dir := writeBuildContext(t, map[string]string{"Dockerfile": "FROM python:3.11\n"})
+
+	image, err := BuildImageWithCloudBuild(context.Background(), "proj", "us-central1-a", repo, dir, filepath.Join(dir, "Dockerfile"), "linux/amd64", ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildImageWithCloudBuild() error = %v", err)
+	}
+	pushed, digest, _ := strings.Cut(image, "@")
+	if !strings.HasPrefix(pushed, "us-central1-docker.pkg.dev/proj/my-repo/tester-runner:ctx-") || digest != "sha256:abc" {
+		t.Errorf("expected a content-addressed image pinned to the digest Cloud Build reported, got %q", image)
+	}
+	if len(f.build.Images) != 1 || f.build.Images[0] != pushed {
+		t.Errorf("expected Cloud Build to push %s, got %v", pushed, f.build.Images)
+	}
+}
@@ -15,35 +15,22 @@
 package imagebuilder
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"context"
 	"fmt"
-	"io"
-	"io/fs"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 
 	"hpc-toolkit/pkg/shell"
 
 	"hpc-toolkit/pkg/logging"
 
-	"github.com/google/go-containerregistry/pkg/compression"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
-	"github.com/moby/patternmatcher"
-	"github.com/moby/patternmatcher/ignorefile"
-)
-
-var (
-	cranePull       = crane.Pull
-	cranePush       = crane.Push
-	appendLayers    = mutate.AppendLayers
-	layerFromOpener = tarball.LayerFromOpener
+	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
 // DockerPlatform represents the target platform for a Docker image.
@@ -56,261 +43,261 @@ const (
 
 // BuildContainerImageFromBaseImage builds and pushes a container image.
 // It appends a new layer created from the scriptDir, filtered by ignorePatterns,
-// to a base Docker image.
+// to a base Docker image. The layer is streamed to the registry rather than
+// staged in a temporary tarball. The image is tagged with a digest of the build context
+// and the base image, so when an image with that tag already exists in the
+// registry it is reused instead of being pushed again. Registry requests stop
+// when ctx is done; an interrupted push leaves no image behind, since the
+// manifest is only uploaded after every layer. The build context is placed at
+// the image root, or under destPath, which then becomes the working directory
+// of the image, when it is set. The settings of cfg and labels recording the
+// provenance of the image are set in its config. A comma-separated list of
+// platforms, e.g. "linux/amd64,linux/arm64", builds an image for each of them
+// on the matching variant of the base image and pushes an index of them. The
+// image is also tagged as selected by tag, and the returned reference pins it
+// to its digest.
 func BuildContainerImageFromBaseImage(
-	project string,
-	location string,
+	ctx context.Context,
+	repo ImageRepo,
 	baseImage string,
 	scriptDir string,
+	destPath string,
 	platformStr string,
-	ignoreMatcher *patternmatcher.PatternMatcher,
+	ignoreMatcher *IgnoreMatcher,
+	cfg ImageConfig,
+	tag ImageTag,
 ) (string, error) {
-	platform, err := parsePlatform(platformStr)
+	platforms, err := parsePlatforms(platformStr)
 	if err != nil {
 		return "", err
 	}
 
-	imageName, err := GenerateImageName(project, location)
+	baseRef, err := name.ParseReference(baseImage)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to parse base image reference %q: %w", baseImage, err)
 	}
 
-	logging.Info("Starting image build process for %s", imageName)
-	logging.Info("Base Image: %s", baseImage)
-	logging.Info("Script Directory: %s", scriptDir)
-	logging.Info("Target Platform: %s/%s", platform.OS, platform.Architecture)
+	logging.Debug("Base Image: %s", baseImage)
+	logging.Debug("Script Directory: %s", scriptDir)
+	logging.Debug("Target Platform: %s", platformStr)
 
-	// Create a tarball in a temporary file from the scriptDir, applying ignore patterns.
-	tempTarballPath, err := createFilteredTar(scriptDir, ignoreMatcher)
+	buildCtx, err := scanBuildContext(scriptDir, ignoreMatcher)
 	if err != nil {
-		return "", fmt.Errorf("failed to create filtered tarball: %w", err)
+		return "", fmt.Errorf("failed to read build context: %w", err)
+	}
+	if destPath != "" {
+		buildCtx.placeUnder(destPath)
 	}
-	// Ensure the temporary file is cleaned up after use.
-	defer func() {
-		if tempTarballPath != "" {
-			os.Remove(tempTarballPath)
-		}
-	}()
 
-	// Create a v1.Layer from the tarball.
-	tarLayer, err := layerFromOpener(func() (io.ReadCloser, error) {
-		file, openErr := os.Open(tempTarballPath)
-		if openErr != nil {
-			return nil, fmt.Errorf("failed to open temporary tarball %q: %w", tempTarballPath, openErr)
+	baseDigests := make([]string, len(platforms))
+	for i := range platforms {
+		if baseDigests[i], err = registry.Digest(baseRef.String(), crane.WithContext(ctx), crane.WithPlatform(&platforms[i])); err != nil {
+			return "", fmt.Errorf("failed to resolve base image digest for %q: %w", baseImage, err)
 		}
-		return file, nil
-	}, tarball.WithCompression(compression.GZip))
-	if err != nil {
-		return "", fmt.Errorf("failed to create layer from tarball: %w", err)
 	}
-
-	baseRef, err := name.ParseReference(baseImage)
+	key := buildCtx.cacheKey(strings.Join(baseDigests, ","), platformStr, cfg)
+	imageName, err := CachedImageName(repo, key)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse base image reference %q: %w", baseImage, err)
+		return "", err
 	}
-
-	baseImg, err := cranePull(baseRef.String(), crane.WithPlatform(&platform))
+	tagStr, err := tag.resolve(scriptDir, key)
 	if err != nil {
-		return "", fmt.Errorf("failed to pull base image %q: %w", baseImage, err)
+		return "", err
 	}
 
-	newImg, err := appendLayers(baseImg, tarLayer)
-	if err != nil {
-		return "", fmt.Errorf("failed to append layer: %w", err)
+	if _, err := registry.Head(imageName, crane.WithContext(ctx)); err == nil {
+		logging.Info("Build context unchanged, reusing cached image %s", imageName)
+		return tagAndPin(ctx, imageName, tagStr)
 	}
 
-	// Push the new image.
+	logging.Debug("Starting image build process for %s", imageName)
+
+	// The build context is tarred, compressed and uploaded in a single pass
+	// while the image is pushed.
+	contextLayer, release := buildCtx.layer()
+	defer release()
+
 	imageRef, err := name.ParseReference(imageName)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse new image reference %q: %w", imageName, err)
 	}
+	cfg = cfg.withProvenance(scriptDir)
+
+	if len(platforms) > 1 {
+		// The layer is uploaded once; the image of each platform then
+		// references the blob, which the push finds in the registry.
+		logging.Info("Uploading build context to %s", imageRef.Context())
+		if err := registry.PushLayer(contextLayer, imageRef.Context().String(), crane.WithContext(ctx)); err != nil {
+			return "", fmt.Errorf("failed to upload build context layer: %w", err)
+		}
+		idx := v1.ImageIndex(empty.Index)
+		for i, platform := range platforms {
+			img, err := platformImage(ctx, baseRef.Context().Digest(baseDigests[i]), platform, cfg, buildCtx.workDir(), contextLayer)
+			if err != nil {
+				return "", err
+			}
+			if i == 0 {
+				idx, err = indexFor(img)
+				if err != nil {
+					return "", err
+				}
+			}
+			idx = mutate.AppendManifests(idx, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &platform}})
+		}
+
+		logging.Info("Uploading Container Image to %s for %s", imageName, platformStr)
+		if err := registry.PushIndex(idx, imageRef.String(), crane.WithContext(ctx)); err != nil {
+			return "", fmt.Errorf("failed to push image %q: %w", imageName, err)
+		}
+		logging.Info("Image %s built and uploaded successfully.", imageName)
+		return tagAndPin(ctx, imageName, tagStr)
+	}
+
+	platform := platforms[0]
+	newImg, err := platformImage(ctx, baseRef.Context().Digest(baseDigests[0]), platform, cfg, buildCtx.workDir(), contextLayer)
+	if err != nil {
+		return "", err
+	}
 
 	logging.Info("Uploading Container Image to %s", imageName)
 
-	err = cranePush(newImg, imageRef.String(), crane.WithPlatform(&platform))
+	err = registry.Push(newImg, imageRef.String(), crane.WithContext(ctx), crane.WithPlatform(&platform))
 	if err != nil {
 		return "", fmt.Errorf("failed to push image %q: %w", imageName, err)
 	}
 
 	logging.Info("Image %s built and uploaded successfully.", imageName)
-	return imageName, nil
+	return tagAndPin(ctx, imageName, tagStr)
 }
 
-func GenerateImageName(project, location string) (string, error) {
-	userName := os.Getenv("USER")
-	if userName == "" {
-		// Check USERNAME for Windows compatibility
-		userName = os.Getenv("USERNAME")
+// platformImage pulls the base image for platform and returns it with the
+// settings of cfg and the working directory workDir, plus contextLayer.
+func platformImage(ctx context.Context, base name.Digest, platform v1.Platform, cfg ImageConfig, workDir string, contextLayer v1.Layer) (v1.Image, error) {
+	logging.Debug("Pulling base image %s for %s", base, platform)
+	baseImg, err := registry.Pull(base.String(), crane.WithContext(ctx), crane.WithPlatform(&platform))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull base image %q: %w", base, err)
 	}
-	if userName == "" {
-		return "", fmt.Errorf("failed to determine user identity from environment (tried USER and USERNAME)")
+	// A base image without variants is returned whatever the platform, so a
+	// mismatch would only surface as an exec format error on the nodes.
+	if baseCfg, err := baseImg.ConfigFile(); err == nil && baseCfg.Architecture != "" && baseCfg.Architecture != platform.Architecture {
+		return nil, fmt.Errorf("base image %s has no %s variant, it is built for %s/%s", base, platform, baseCfg.OS, baseCfg.Architecture)
 	}
-
-	repoName := os.Getenv("GCLUSTER_IMAGE_REPO")
-	if repoName == "" {
-		return "", fmt.Errorf("GCLUSTER_IMAGE_REPO environment variable is required but not set. Please set it in your environment (e.g., export GCLUSTER_IMAGE_REPO=<repo>)")
+	// The config is set on the base image, since the config of an image with
+	// a streamed layer cannot be read before the push.
+	if baseImg, err = cfg.apply(baseImg, workDir); err != nil {
+		return nil, err
 	}
-
-	region := shell.ExtractRegion(location)
-
-	tagRandomPrefix, err := shell.RandomString(4)
+	newImg, err := mutate.AppendLayers(baseImg, contextLayer)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate random prefix for image tag: %w", err)
+		return nil, fmt.Errorf("failed to append layer: %w", err)
 	}
-	tagDatetime := time.Now().Format("2006-01-02-15-04-05") // YYYY-MM-DD-HH-MM-SS
-	return fmt.Sprintf("%s-docker.pkg.dev/%s/%s/%s-runner:%s-%s", region, project, repoName, strings.ToLower(userName), tagRandomPrefix, tagDatetime), nil
+	return newImg, nil
 }
 
-// parsePlatform converts a platform string (e.g., "linux/amd64") into a v1.Platform struct.
-func parsePlatform(platformStr string) (v1.Platform, error) {
-	parts := strings.Split(platformStr, "/")
-	if len(parts) != 2 {
-		return v1.Platform{}, fmt.Errorf("invalid platform format: %q, expected \"os/arch\"", platformStr)
+// indexFor returns an empty index of the same format as img: a Docker
+// manifest list for Docker images, an OCI image index otherwise.
+func indexFor(img v1.Image) (v1.ImageIndex, error) {
+	mt, err := img.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image media type: %w", err)
 	}
-	return v1.Platform{
-		OS:           parts[0],
-		Architecture: parts[1],
-	}, nil
+	if mt == types.DockerManifestSchema2 {
+		return mutate.IndexMediaType(empty.Index, types.DockerManifestList), nil
+	}
+	return mutate.IndexMediaType(empty.Index, types.OCIImageIndex), nil
 }
 
-func ReadDockerignorePatterns(dir string, defaultPatterns []string) (*patternmatcher.PatternMatcher, error) {
-	dockerignorePath := filepath.Join(dir, ".dockerignore")
-
-	patterns := make([]string, len(defaultPatterns))
-	copy(patterns, defaultPatterns)
-
-	if _, err := os.Stat(dockerignorePath); err == nil {
-		file, err := os.Open(dockerignorePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open .dockerignore file %q: %w", dockerignorePath, err)
-		}
-		defer file.Close()
-
-		filePatterns, err := ignorefile.ReadAll(file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read .dockerignore file %q: %w", dockerignorePath, err)
-		}
-		patterns = append(patterns, filePatterns...)
-		logging.Info("Found %d patterns in .dockerignore at %q", len(filePatterns), dockerignorePath)
-	} else if !os.IsNotExist(err) {
-		return nil, fmt.Errorf("failed to stat .dockerignore file %q: %w", dockerignorePath, err)
+// GenerateImageName returns a unique name for an image built by the current user
+// in repo.
+func GenerateImageName(repo ImageRepo) (string, error) {
+	userName, err := currentUser()
+	if err != nil {
+		return "", err
 	}
 
-	matcher, err := patternmatcher.New(patterns)
+	tagRandomPrefix, err := shell.RandomString(4)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pattern matcher: %w", err)
+		return "", fmt.Errorf("failed to generate random prefix for image tag: %w", err)
 	}
-	return matcher, nil
+	tagDatetime := clock.Now().Format("2006-01-02-15-04-05") // YYYY-MM-DD-HH-MM-SS
+	return fmt.Sprintf("%s/%s-runner:%s-%s", repo, strings.ToLower(userName), tagRandomPrefix, tagDatetime), nil
 }
 
-func isPathIgnored(relPath string, d fs.DirEntry, matcher *patternmatcher.PatternMatcher) (bool, error) {
-	relPathSlash := filepath.ToSlash(relPath)
-	if d.IsDir() && !strings.HasSuffix(relPathSlash, "/") {
-		relPathSlash += "/"
-	}
-
-	ignored, err := matcher.MatchesOrParentMatches(relPathSlash)
-	if err != nil {
-		return false, fmt.Errorf("failed to check ignore patterns: %w", err)
-	}
-	return ignored, nil
+// CachedImageName returns the name of the image built by the current user in
+// repo for the given build cache key.
+func CachedImageName(repo ImageRepo, hash string) (string, error) {
+	return runnerImageName(repo, cacheTagPrefix+hash)
 }
 
-func writeFileContent(tarWriter *tar.Writer, path string) error {
-	file, err := os.Open(path)
+// runnerImageName returns the name of the image built by the current user in
+// repo with tag.
+func runnerImageName(repo ImageRepo, tag string) (string, error) {
+	userName, err := currentUser()
 	if err != nil {
-		return fmt.Errorf("failed to open file %q: %w", path, err)
-	}
-	defer file.Close()
-
-	if _, err := io.Copy(tarWriter, file); err != nil {
-		return fmt.Errorf("failed to write file content for %q: %w", path, err)
+		return "", err
 	}
-	return nil
+	return fmt.Sprintf("%s/%s-runner:%s", repo, strings.ToLower(userName), tag), nil
 }
 
-func processTarEntry(tarWriter *tar.Writer, sourceDir string, ignoreMatcher *patternmatcher.PatternMatcher, path string, d fs.DirEntry, errFromWalk error) error {
-	if errFromWalk != nil {
-		return errFromWalk
+func currentUser() (string, error) {
+	userName := os.Getenv("USER")
+	if userName == "" {
+		// Check USERNAME for Windows compatibility
+		userName = os.Getenv("USERNAME")
 	}
-
-	relPath, err := filepath.Rel(sourceDir, path)
-	if err != nil || relPath == "." {
-		return err
+	if userName == "" {
+		return "", fmt.Errorf("failed to determine user identity from environment (tried USER and USERNAME)")
 	}
+	return userName, nil
+}
 
-	ignored, err := isPathIgnored(relPath, d, ignoreMatcher)
-	if err != nil {
-		return err
-	}
-	if ignored {
-		if d.IsDir() {
-			return filepath.SkipDir
+// parsePlatforms converts a comma-separated list of platforms (e.g.,
+// "linux/amd64,linux/arm64") into v1.Platform structs.
+func parsePlatforms(platformStr string) ([]v1.Platform, error) {
+	var platforms []v1.Platform
+	for _, p := range strings.Split(platformStr, ",") {
+		platform, err := parsePlatform(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
 		}
-		return nil
-	}
-
-	info, err := d.Info()
-	if err != nil {
-		return fmt.Errorf("failed to get info for %q: %w", path, err)
-	}
-
-	var linkTarget string
-	if info.Mode()&os.ModeSymlink != 0 {
-		var errLink error
-		linkTarget, errLink = os.Readlink(path)
-		if errLink != nil {
-			return fmt.Errorf("failed to read link for %q: %w", path, errLink)
+		if slices.ContainsFunc(platforms, platform.Equals) {
+			return nil, fmt.Errorf("platform %s is listed more than once in %q", p, platformStr)
 		}
+		platforms = append(platforms, platform)
 	}
+	return platforms, nil
+}
 
-	header, err := tar.FileInfoHeader(info, linkTarget)
-	if err != nil {
-		return fmt.Errorf("failed to create tar header for %q: %w", path, err)
-	}
-	header.Name = relPath
-
-	if err := tarWriter.WriteHeader(header); err != nil {
-		return fmt.Errorf("failed to write tar header for %q: %w", path, err)
+// parsePlatform converts a platform string (e.g., "linux/amd64") into a v1.Platform struct.
+// Builders that make single-platform images use it, so a list of platforms is
+// refused.
+func parsePlatform(platformStr string) (v1.Platform, error) {
+	if strings.Contains(platformStr, ",") {
+		return v1.Platform{}, fmt.Errorf("platform %q lists several platforms, but this build makes a single-platform image; pass one platform", platformStr)
 	}
-
-	if info.Mode().IsRegular() {
-		return writeFileContent(tarWriter, path)
+	parts := strings.Split(platformStr, "/")
+	if len(parts) != 2 {
+		return v1.Platform{}, fmt.Errorf("invalid platform format: %q, expected \"os/arch\"", platformStr)
 	}
-
-	return nil
+	return v1.Platform{
+		OS:           parts[0],
+		Architecture: parts[1],
+	}, nil
 }
 
-func createFilteredTar(sourceDir string, ignoreMatcher *patternmatcher.PatternMatcher) (tarPath string, err error) {
-	tmpFile, tmpErr := os.CreateTemp("", "gcluster-build-context-*.tar.gz")
-	if tmpErr != nil {
-		return "", fmt.Errorf("failed to create temporary file for tarball: %w", tmpErr)
+// ImageDigest returns the digest image currently resolves to in its registry.
+func ImageDigest(image string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference %q: %w", image, err)
 	}
-	defer tmpFile.Close()
-
-	gzipWriter := gzip.NewWriter(tmpFile)
-	tarWriter := tar.NewWriter(gzipWriter)
-
-	logging.Info("Creating filtered tar from %s to temporary file %s", sourceDir, tmpFile.Name())
-
-	defer func() {
-		// Ensure tar and gzip writers are closed to flush any buffered data
-		if closeErr := tarWriter.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close tar writer: %w", closeErr)
-		}
-		if closeErr := gzipWriter.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close gzip writer: %w", closeErr)
-		}
-	}()
-
-	err = filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, walkDirErr error) error {
-		return processTarEntry(tarWriter, sourceDir, ignoreMatcher, path, d, walkDirErr)
-	})
-
+	if d, ok := ref.(name.Digest); ok {
+		return d.DigestStr(), nil
+	}
+	digest, err := registry.Digest(ref.String())
 	if err != nil {
-		os.Remove(tmpFile.Name())
-		return "", err
+		return "", fmt.Errorf("failed to resolve the digest of %q: %w", image, err)
 	}
-
-	return tmpFile.Name(), nil
+	return digest, nil
 }
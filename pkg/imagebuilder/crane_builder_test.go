@@ -16,113 +16,27 @@ package imagebuilder
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
+	"context"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/testutil"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
-	"github.com/moby/patternmatcher"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 )
 
-// Wrapper to simulate logic in processTarEntry
-func testShouldIgnore(t *testing.T, matcher *patternmatcher.PatternMatcher, relPath string, isDir bool) bool {
-	relPathSlash := filepath.ToSlash(relPath)
-	if isDir && !strings.HasSuffix(relPathSlash, "/") {
-		relPathSlash += "/"
-	}
-	// MatchesOrParentMatches is what we use in processTarEntry
-	ignored, err := matcher.MatchesOrParentMatches(relPathSlash)
-	if err != nil {
-		t.Errorf("MatchesOrParentMatches error: %v", err)
-	}
-	return ignored
-}
-
-func TestPatternMatcherIntegration(t *testing.T) {
-	tests := []struct {
-		name           string
-		ignorePatterns []string
-		path           string
-		isDir          bool
-		wantIgnored    bool
-	}{
-		{
-			name:           "Simple match",
-			ignorePatterns: []string{"*.log"},
-			path:           "foo.log",
-			isDir:          false,
-			wantIgnored:    true,
-		},
-		{
-			name:           "Simple mismatch",
-			ignorePatterns: []string{"*.log"},
-			path:           "foo.txt",
-			isDir:          false,
-			wantIgnored:    false,
-		},
-		{
-			name:           "Directory match",
-			ignorePatterns: []string{"temp"},
-			path:           "temp",
-			isDir:          true,
-			wantIgnored:    true,
-		},
-		{
-			name:           "Negation",
-			ignorePatterns: []string{"*.log", "!important.log"},
-			path:           "important.log",
-			isDir:          false,
-			wantIgnored:    false,
-		},
-		{
-			name:           "Double star",
-			ignorePatterns: []string{"**/*.tmp"},
-			path:           "a/b/c/foo.tmp",
-			isDir:          false,
-			wantIgnored:    true,
-		},
-		{
-			name:           "Directory pattern with slash matching directory",
-			ignorePatterns: []string{"foo/"},
-			path:           "foo",
-			isDir:          true,
-			wantIgnored:    true,
-		},
-		{
-			name:           "Directory pattern with slash matching file (KNOWN LIMITATION)",
-			ignorePatterns: []string{"foo/"},
-			path:           "foo", // file named foo
-			isDir:          false,
-			wantIgnored:    true, // LIMITATION: moby/patternmatcher matches this even if it shouldn't per strict Docker spec
-		},
-		{
-			name:           "Nested file in ignored directory",
-			ignorePatterns: []string{"foo/"},
-			path:           "foo/bar",
-			isDir:          false,
-			wantIgnored:    true, // MatchesOrParentMatches should catch this
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			matcher, err := patternmatcher.New(tt.ignorePatterns)
-			if err != nil {
-				t.Fatalf("failed to create matcher: %v", err)
-			}
-
-			got := testShouldIgnore(t, matcher, tt.path, tt.isDir)
-			if got != tt.wantIgnored {
-				t.Errorf("testShouldIgnore(%q, isDir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.wantIgnored)
-			}
-		})
-	}
-}
-
 func TestParsePlatform(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -173,57 +87,7 @@ func TestParsePlatform(t *testing.T) {
 	}
 }
 
-func TestReadDockerignorePatterns(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "dockerignore-test")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	dockerignorePath := filepath.Join(tempDir, ".dockerignore")
-	content := "*.log\n!important.log\n"
-	if err := os.WriteFile(dockerignorePath, []byte(content), 0644); err != nil {
-		t.Fatal(err)
-	}
-
-	matcher, err := ReadDockerignorePatterns(tempDir, []string{"default.tmp"})
-	if err != nil {
-		t.Fatalf("ReadDockerignorePatterns() error = %v", err)
-	}
-
-	if matcher == nil {
-		t.Fatal("ReadDockerignorePatterns() returned nil matcher")
-	}
-
-	// Test if it matches *.log
-	ignored, err := matcher.MatchesOrParentMatches("foo.log")
-	if err != nil {
-		t.Errorf("got error matching: %v", err)
-	}
-	if !ignored {
-		t.Error("expected foo.log to be ignored per .dockerignore")
-	}
-
-	// Test negation
-	ignored, err = matcher.MatchesOrParentMatches("important.log")
-	if err != nil {
-		t.Errorf("got error matching: %v", err)
-	}
-	if ignored {
-		t.Error("expected important.log to NOT be ignored per .dockerignore")
-	}
-
-	// Test default pattern
-	ignored, err = matcher.MatchesOrParentMatches("default.tmp")
-	if err != nil {
-		t.Errorf("got error matching: %v", err)
-	}
-	if !ignored {
-		t.Error("expected default.tmp to be ignored per default patterns")
-	}
-}
-
-func TestCreateFilteredTar(t *testing.T) {
+func TestScanBuildContext(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "tar-test-source")
 	if err != nil {
 		t.Fatal(err)
@@ -232,18 +96,17 @@ func TestCreateFilteredTar(t *testing.T) {
 
 	createTestFiles(t, tempDir)
 
-	matcher, err := patternmatcher.New([]string{"*.log"})
+	matcher, err := NewIgnoreMatcher([]string{"*.log"})
 	if err != nil {
 		t.Fatalf("failed to create matcher: %v", err)
 	}
 
-	tarPath, err := createFilteredTar(tempDir, matcher)
+	bc, err := scanBuildContext(tempDir, matcher)
 	if err != nil {
-		t.Fatalf("createFilteredTar() error = %v", err)
+		t.Fatalf("scanBuildContext() error = %v", err)
 	}
-	defer os.Remove(tarPath)
 
-	foundFiles := getFilesFromTar(t, tarPath)
+	foundFiles := getFilesFromTar(t, tarBuildContext(t, bc))
 
 	if !foundFiles["foo.txt"] {
 		t.Error("foo.txt not found in tarball")
@@ -271,20 +134,16 @@ func createTestFiles(t *testing.T, tempDir string) {
 	}
 }
 
-func getFilesFromTar(t *testing.T, tarPath string) map[string]bool {
-	f, err := os.Open(tarPath)
-	if err != nil {
-		t.Fatalf("failed to open generated tarball: %v", err)
+func tarBuildContext(t *testing.T, bc *buildContext) io.Reader {
+	var buf bytes.Buffer
+	if err := bc.writeTar(&buf); err != nil {
+		t.Fatalf("writeTar() error = %v", err)
 	}
-	defer f.Close()
-
-	gr, err := gzip.NewReader(f)
-	if err != nil {
-		t.Fatalf("failed to create gzip reader: %v", err)
-	}
-	defer gr.Close()
+	return &buf
+}
 
-	tr := tar.NewReader(gr)
+func getFilesFromTar(t *testing.T, r io.Reader) map[string]bool {
+	tr := tar.NewReader(r)
 	foundFiles := make(map[string]bool)
 
 	for {
@@ -300,71 +159,378 @@ func getFilesFromTar(t *testing.T, tarPath string) map[string]bool {
 	return foundFiles
 }
 
+// fakeRegistry installs an empty in-memory registry for the test.
+func fakeRegistry(t *testing.T) *testutil.FakeRegistry {
+	t.Helper()
+	reg := testutil.NewFakeRegistry()
+	t.Cleanup(SetRegistry(reg))
+	return reg
+}
+
 func TestBuildContainerImageFromBaseImage_Success(t *testing.T) {
-	// Preserve originals
-	origPull := cranePull
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", empty.Image); err != nil {
+		t.Fatal(err)
+	}
 
-	origRepo := os.Getenv("GCLUSTER_IMAGE_REPO")
-	os.Setenv("GCLUSTER_IMAGE_REPO", "gcluster")
-	defer os.Setenv("GCLUSTER_IMAGE_REPO", origRepo)
+	tempDir := t.TempDir()
+	createTestFiles(t, tempDir)
 
-	origUser := os.Getenv("USER")
-	os.Setenv("USER", "testuser")
-	defer os.Setenv("USER", origUser)
+	matcher, _ := NewIgnoreMatcher([]string{})
+	got, err := BuildContainerImageFromBaseImage(context.Background(), ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}, "ubuntu", tempDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
+	}
 
-	origPush := cranePush
-	origAppend := appendLayers
-	origLayerOpener := layerFromOpener
-	defer func() {
-		cranePull = origPull
-		cranePush = origPush
-		appendLayers = origAppend
-		layerFromOpener = origLayerOpener
-	}()
+	if !strings.HasPrefix(got, "us-central1-docker.pkg.dev/test-project/gcluster/testuser-runner:ctx-") {
+		t.Errorf("expected a content-addressed image in us-central1-docker.pkg.dev/test-project/gcluster/, got %s", got)
+	}
+	layers := reg.PushedLayers(got)
+	if len(layers) != 1 {
+		t.Fatalf("expected the build context to be pushed as one layer, got %d", len(layers))
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(layers[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed := getFilesFromTar(t, gr)
+	if !pushed["foo.txt"] || !pushed["sub/baz.txt"] {
+		t.Errorf("expected the build context to be streamed into the pushed layer, got %v", pushed)
+	}
+}
 
-	// Mock implementations
-	cranePull = func(ref string, opts ...crane.Option) (v1.Image, error) {
-		return nil, nil // Return nil image for now, as we don't use it deeply in the mock
+func TestBuildContainerImageFromBaseImage_Workdir(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", empty.Image); err != nil {
+		t.Fatal(err)
 	}
-	cranePush = func(img v1.Image, ref string, opts ...crane.Option) error {
-		return nil
+	tempDir := t.TempDir()
+	createTestFiles(t, tempDir)
+	matcher, _ := NewIgnoreMatcher([]string{})
+	repo := ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}
+
+	atRoot, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatal(err)
 	}
-	appendLayers = func(base v1.Image, layers ...v1.Layer) (v1.Image, error) {
-		return nil, nil
+	got, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "/srv/app/", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
+	}
+	if got == atRoot {
+		t.Errorf("expected the workdir to change the cached image, got %s for both", got)
 	}
 
-	tempDir, err := os.MkdirTemp("", "build-test")
+	layers := reg.PushedLayers(got)
+	if len(layers) != 1 {
+		t.Fatalf("expected the build context to be pushed as one layer, got %d", len(layers))
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(layers[0]))
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer os.RemoveAll(tempDir)
+	pushed := getFilesFromTar(t, gr)
+	for _, want := range []string{"srv/", "srv/app/", "srv/app/foo.txt", "srv/app/sub/baz.txt"} {
+		if !pushed[want] {
+			t.Errorf("expected %s in the pushed layer, got %v", want, pushed)
+		}
+	}
+	if pushed["foo.txt"] {
+		t.Errorf("expected no files at the image root, got %v", pushed)
+	}
+
+	raw, err := reg.Config(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `"WorkingDir":"/srv/app"`) {
+		t.Errorf("expected the working directory /srv/app in the image config, got %s", raw)
+	}
+}
+
+func TestBuildContainerImageFromBaseImage_ImageConfig(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	t.Cleanup(SetClock(testutil.NewFakeClock(time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC))))
+	base, err := mutate.Config(empty.Image, v1.Config{
+		Env:        []string{"PATH=/usr/bin", "MODE=serve"},
+		Labels:     map[string]string{"maintainer": "base"},
+		Entrypoint: []string{"/bin/sh"},
+		Cmd:        []string{"-c", "serve"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.AddImage("ubuntu", base); err != nil {
+		t.Fatal(err)
+	}
+	tempDir := t.TempDir()
+	createTestFiles(t, tempDir)
+	matcher, _ := NewIgnoreMatcher([]string{})
+	repo := ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}
+
+	plain, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := ImageConfig{
+		Env:        map[string]string{"MODE": "train", "SEED": "1"},
+		Labels:     map[string]string{"team": "ml"},
+		User:       "1000:1000",
+		Entrypoint: []string{"python"},
+	}
+	got, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "", "linux/amd64", matcher, cfg, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
+	}
+	if got == plain {
+		t.Errorf("expected the image config to change the cached image, got %s for both", got)
+	}
+
+	img, err := reg.Pull(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cf.Config
+	if want := []string{"PATH=/usr/bin", "MODE=train", "SEED=1"}; !slices.Equal(c.Env, want) {
+		t.Errorf("Env = %v, want %v", c.Env, want)
+	}
+	if c.User != "1000:1000" {
+		t.Errorf("User = %q, want 1000:1000", c.User)
+	}
+	if !slices.Equal(c.Entrypoint, []string{"python"}) || !slices.Equal(c.Cmd, []string{"-c", "serve"}) {
+		t.Errorf("expected the entrypoint to be replaced and the cmd of the base image kept, got %v %v", c.Entrypoint, c.Cmd)
+	}
+	for k, want := range map[string]string{"maintainer": "base", "team": "ml", LabelCreated: "2026-06-01T12:00:00Z", LabelToolkitVersion: config.GetToolkitVersion()} {
+		if c.Labels[k] != want {
+			t.Errorf("label %s = %q, want %q", k, c.Labels[k], want)
+		}
+	}
+	if _, ok := c.Labels[LabelRevision]; ok {
+		t.Errorf("expected no revision label for a build context outside git, got %q", c.Labels[LabelRevision])
+	}
+}
+
+func TestParsePlatforms(t *testing.T) {
+	got, err := parsePlatforms("linux/amd64, linux/arm64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Architecture != "amd64" || got[1].Architecture != "arm64" {
+		t.Errorf("parsePlatforms() = %v, want linux/amd64 and linux/arm64", got)
+	}
+	if _, err := parsePlatforms("linux/amd64,linux/amd64"); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected an error for a duplicate platform, got %v", err)
+	}
+	if _, err := parsePlatform("linux/amd64,linux/arm64"); err == nil || !strings.Contains(err.Error(), "single-platform image") {
+		t.Errorf("expected a single-platform build to refuse a list of platforms, got %v", err)
+	}
+}
+
+// platformBase returns an empty image for linux/arch.
+func platformBase(t *testing.T, arch string) v1.Image {
+	t.Helper()
+	img, err := mutate.ConfigFile(empty.Image, &v1.ConfigFile{OS: "linux", Architecture: arch})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestBuildContainerImageFromBaseImage_MultiPlatform(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	var base v1.ImageIndex = empty.Index
+	for _, arch := range []string{"amd64", "arm64", "s390x"} {
+		base = mutate.AppendManifests(base, mutate.IndexAddendum{
+			Add:        platformBase(t, arch),
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+	}
+	if err := reg.AddIndex("python:3.11", base); err != nil {
+		t.Fatal(err)
+	}
+	tempDir := t.TempDir()
+	createTestFiles(t, tempDir)
+	matcher, _ := NewIgnoreMatcher([]string{})
+	repo := ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}
+
+	single, err := BuildContainerImageFromBaseImage(context.Background(), repo, "python:3.11", tempDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := BuildContainerImageFromBaseImage(context.Background(), repo, "python:3.11", tempDir, "", "linux/amd64,linux/arm64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
+	}
+	if got == single {
+		t.Errorf("expected the platforms to change the cached image, got %s for both", got)
+	}
+
+	raw, err := reg.Manifest(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := v1.ParseIndexManifest(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("expected an index to be pushed, got %s: %v", raw, err)
+	}
+	var archs []string
+	for _, m := range index.Manifests {
+		archs = append(archs, m.Platform.Architecture)
+	}
+	if !slices.Equal(archs, []string{"amd64", "arm64"}) {
+		t.Errorf("index platforms = %v, want amd64 and arm64", archs)
+	}
+
+	var contextLayers []v1.Hash
+	for _, arch := range archs {
+		img, err := reg.Pull(got, crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: arch}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf, err := img.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cf.Architecture != arch {
+			t.Errorf("expected the %s image to be built on the %s base, got %s", arch, arch, cf.Architecture)
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := layers[len(layers)-1].Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contextLayers = append(contextLayers, d)
+	}
+	if len(contextLayers) != 2 || contextLayers[0] != contextLayers[1] {
+		t.Errorf("expected both images to share the build context layer, got %v", contextLayers)
+	}
+
+	var layerPushes int
+	for _, c := range reg.Calls() {
+		if strings.HasPrefix(c, "PushLayer ") {
+			layerPushes++
+		}
+	}
+	if layerPushes != 1 {
+		t.Errorf("expected the build context to be uploaded once, got %d uploads in %v", layerPushes, reg.Calls())
+	}
+}
+
+func TestBuildContainerImageFromBaseImage_MissingPlatform(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", platformBase(t, "amd64")); err != nil {
+		t.Fatal(err)
+	}
+	tempDir := t.TempDir()
+	createTestFiles(t, tempDir)
+	matcher, _ := NewIgnoreMatcher([]string{})
+	repo := ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}
+
+	_, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "", "linux/amd64,linux/arm64", matcher, ImageConfig{}, ImageTag{})
+	if err == nil || !strings.Contains(err.Error(), "has no linux/arm64 variant") {
+		t.Errorf("expected an error for a base image without an arm64 variant, got %v", err)
+	}
+}
 
-	matcher, _ := patternmatcher.New([]string{})
-	got, err := BuildContainerImageFromBaseImage("test-project", "us-central1", "ubuntu", tempDir, "linux/amd64", matcher)
+func TestBuildContainerImageFromBaseImage_CacheHit(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", empty.Image); err != nil {
+		t.Fatal(err)
+	}
+
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "train.py"), []byte("print('hi')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	matcher, _ := NewIgnoreMatcher([]string{})
+	repo := ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}
+	first, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
+	}
+
+	before := len(reg.Calls())
+	got, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
 	if err != nil {
 		t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
 	}
+	if got != first {
+		t.Errorf("expected the cached image %s to be returned, got %s", first, got)
+	}
+	for _, call := range reg.Calls()[before:] {
+		if strings.HasPrefix(call, "Pull ") || strings.HasPrefix(call, "Push ") {
+			t.Errorf("expected no pull or push on a cache hit, got %s", call)
+		}
+	}
+}
+
+func TestBuildContextCacheKey(t *testing.T) {
+	tempDir := t.TempDir()
+	script := filepath.Join(tempDir, "train.py")
+	if err := os.WriteFile(script, []byte("print('hi')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	matcher, _ := NewIgnoreMatcher([]string{})
+
+	key := func(baseDigest, platform string) string {
+		t.Helper()
+		bc, err := scanBuildContext(tempDir, matcher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bc.cacheKey(baseDigest, platform, ImageConfig{})
+	}
 
-	if !strings.Contains(got, "us-central1-docker.pkg.dev/test-project/gcluster/") {
-		t.Errorf("expected imageName to contain us-central1-docker.pkg.dev/test-project/gcluster/, got %s", got)
+	orig := key("sha256:base", "linux/amd64")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(script, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := key("sha256:base", "linux/amd64"); got != orig {
+		t.Errorf("expected touching a file to keep the key %s, got %s", orig, got)
+	}
+	if got := key("sha256:other", "linux/amd64"); got == orig {
+		t.Error("expected a different base image digest to change the key")
+	}
+	if got := key("sha256:base", "linux/arm64"); got == orig {
+		t.Error("expected a different platform to change the key")
+	}
+	if err := os.WriteFile(script, []byte("print('bye')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := key("sha256:base", "linux/amd64"); got == orig {
+		t.Error("expected a content change to change the key")
 	}
 }
 
 func TestBuildContainerImageFromBaseImage_PlatformError(t *testing.T) {
-	_, err := BuildContainerImageFromBaseImage("test-project", "us-central1", "ubuntu", "", "invalid-platform", nil)
+	_, err := BuildContainerImageFromBaseImage(context.Background(), ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}, "ubuntu", "", "", "invalid-platform", nil, ImageConfig{}, ImageTag{})
 	if err == nil {
 		t.Error("expected error for invalid platform, got nil")
 	}
 }
 
 func TestBuildContainerImageFromBaseImage_ParseReferenceError(t *testing.T) {
-	_, err := BuildContainerImageFromBaseImage("test-project", "us-central1", "!!invalid!!", "", "linux/amd64", nil)
+	_, err := BuildContainerImageFromBaseImage(context.Background(), ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}, "!!invalid!!", "", "", "linux/amd64", nil, ImageConfig{}, ImageTag{})
 	if err == nil {
 		t.Error("expected error for invalid base image, got nil")
 	}
 }
 
-func TestCreateFilteredTar_Symlink(t *testing.T) {
+func TestScanBuildContext_Symlink(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "tar-symlink-test")
 	if err != nil {
 		t.Fatal(err)
@@ -381,30 +547,17 @@ func TestCreateFilteredTar_Symlink(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	matcher, err := patternmatcher.New([]string{})
+	matcher, err := NewIgnoreMatcher([]string{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	tarPath, err := createFilteredTar(tempDir, matcher)
+	bc, err := scanBuildContext(tempDir, matcher)
 	if err != nil {
-		t.Fatalf("createFilteredTar() error = %v", err)
+		t.Fatalf("scanBuildContext() error = %v", err)
 	}
-	defer os.Remove(tarPath)
 
-	f, err := os.Open(tarPath)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer f.Close()
-
-	gr, err := gzip.NewReader(f)
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer gr.Close()
-
-	tr := tar.NewReader(gr)
+	tr := tar.NewReader(tarBuildContext(t, bc))
 	if !findSymlinkInTar(t, tr, "link.txt", "target.txt") {
 		t.Error("link.txt not found or invalid in tarball")
 	}
@@ -433,32 +586,13 @@ func findSymlinkInTar(t *testing.T, tr *tar.Reader, linkName, expectedTarget str
 }
 
 func TestWriteFileContent_OpenError(t *testing.T) {
-	err := writeFileContent(nil, "non-existent-file")
+	err := writeFileContent(nil, "non-existent-file", 0)
 	if err == nil {
 		t.Error("expected error opening non-existent file, got nil")
 	}
 }
 
-func TestReadDockerignorePatterns_OpenError(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "dockerignore-open-error")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	dockerignorePath := filepath.Join(tempDir, ".dockerignore")
-	// Create a directory instead of a file to simulate a read error
-	if err := os.Mkdir(dockerignorePath, 0755); err != nil {
-		t.Fatal(err)
-	}
-
-	_, err = ReadDockerignorePatterns(tempDir, nil)
-	if err == nil {
-		t.Error("expected error reading unreadable .dockerignore, got nil")
-	}
-}
-
-func TestCreateFilteredTar_IgnoreDir(t *testing.T) {
+func TestScanBuildContext_IgnoreDir(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "tar-test-ignore-dir")
 	if err != nil {
 		t.Fatal(err)
@@ -479,18 +613,17 @@ func TestCreateFilteredTar_IgnoreDir(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	matcher, err := patternmatcher.New([]string{"ignored_dir/"})
+	matcher, err := NewIgnoreMatcher([]string{"ignored_dir/"})
 	if err != nil {
 		t.Fatalf("failed to create matcher: %v", err)
 	}
 
-	tarPath, err := createFilteredTar(tempDir, matcher)
+	bc, err := scanBuildContext(tempDir, matcher)
 	if err != nil {
-		t.Fatalf("createFilteredTar() error = %v", err)
+		t.Fatalf("scanBuildContext() error = %v", err)
 	}
-	defer os.Remove(tarPath)
 
-	foundFiles := getFilesFromTar(t, tarPath)
+	foundFiles := getFilesFromTar(t, tarBuildContext(t, bc))
 
 	if !foundFiles["keep.txt"] {
 		t.Error("keep.txt not found in tarball")
@@ -499,3 +632,79 @@ func TestCreateFilteredTar_IgnoreDir(t *testing.T) {
 		t.Error("ignored_dir/file.txt should have been ignored but was found in tarball")
 	}
 }
+
+func TestBuildContextWriteTar_FileShrank(t *testing.T) {
+	tempDir := t.TempDir()
+	script := filepath.Join(tempDir, "train.py")
+	if err := os.WriteFile(script, []byte("print('hello')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	matcher, _ := NewIgnoreMatcher([]string{})
+	bc, err := scanBuildContext(tempDir, matcher)
+	if err != nil {
+		t.Fatalf("scanBuildContext() error = %v", err)
+	}
+	if err := os.WriteFile(script, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := bc.writeTar(io.Discard); err == nil || !strings.Contains(err.Error(), "modified during the build") {
+		t.Errorf("expected an error for a file modified after the scan, got %v", err)
+	}
+}
+
+func TestScanBuildContext_NegationInIgnoredDir(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, f := range []string{"data/keep.txt", "data/drop.txt", "logs/run.txt", "train.py"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(tempDir, f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".dockerignore"), []byte("data\nlogs\n!data/keep.txt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	matcher, err := ReadDockerignorePatterns(tempDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc, err := scanBuildContext(tempDir, matcher)
+	if err != nil {
+		t.Fatalf("scanBuildContext() error = %v", err)
+	}
+
+	foundFiles := getFilesFromTar(t, tarBuildContext(t, bc))
+	for f, want := range map[string]bool{"data/keep.txt": true, "data/drop.txt": false, "logs/run.txt": false, "train.py": true} {
+		if foundFiles[f] != want {
+			t.Errorf("expected %s in tarball = %v, got %v", f, want, foundFiles[f])
+		}
+	}
+}
+
+func TestImageDigest(t *testing.T) {
+	reg := fakeRegistry(t)
+	img, err := random.Image(16, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.AddImage("us-docker.pkg.dev/p/r/img:v1", img); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := img.Digest()
+
+	got, err := ImageDigest("us-docker.pkg.dev/p/r/img:v1")
+	if err != nil || got != want.String() {
+		t.Errorf("ImageDigest() = %q, %v; want %s", got, err, want)
+	}
+
+	pinned := "us-docker.pkg.dev/p/r/img@sha256:" + strings.Repeat("a", 64)
+	got, err = ImageDigest(pinned)
+	if err != nil || got != "sha256:"+strings.Repeat("a", 64) {
+		t.Errorf("ImageDigest(%q) = %q, %v", pinned, got, err)
+	}
+	if calls := reg.Calls(); len(calls) != 1 {
+		t.Errorf("expected a pinned image not to be resolved, got %v", calls)
+	}
+}
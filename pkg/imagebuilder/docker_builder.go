@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+)
+
+// BuildImageWithDocker builds dockerfile on buildContext with the local Docker
+// daemon and pushes the image to repo with the credentials of the Docker CLI.
+// Platforms other than the one of the daemon need buildx with QEMU emulation.
+// The image is tagged as selected by tag, and the returned reference pins it
+// to its digest. No further step is started once ctx is done.
+func BuildImageWithDocker(ctx context.Context, repo ImageRepo, buildContext, dockerfile, platformStr string, tag ImageTag) (string, error) {
+	if _, err := parsePlatform(platformStr); err != nil {
+		return "", err
+	}
+	rel, err := DockerfileInContext(buildContext, dockerfile)
+	if err != nil {
+		return "", err
+	}
+	if _, err := lookPath("docker"); err != nil {
+		return "", fmt.Errorf("the docker build backend needs the docker CLI, which was not found on the PATH: %w", err)
+	}
+	image, err := dockerfileImageName(repo, tag, buildContext, rel, platformStr)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("docker build canceled: %w", err)
+	}
+	logging.Info("Building %s from %s with the local Docker daemon...", image, dockerfile)
+	if res := shell.ExecuteCommand("docker", "build", "--platform", platformStr, "-f", dockerfile, "-t", image, buildContext); res.ExitCode != 0 {
+		return "", fmt.Errorf("docker build failed: %s\n%s", res.Stderr, res.Stdout)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("docker push of %s canceled: %w", image, err)
+	}
+
+	logging.Info("Pushing %s...", image)
+	if res := shell.ExecuteCommand("docker", "push", image); res.ExitCode != 0 {
+		msg := strings.TrimSpace(res.Stderr)
+		if strings.Contains(msg, "denied") || strings.Contains(msg, "unauthorized") {
+			msg += fmt.Sprintf("\nConfigure Docker to authenticate to Artifact Registry with 'gcloud auth configure-docker %s-docker.pkg.dev'.", repo.Location)
+		}
+		return "", fmt.Errorf("docker push of %s failed: %s", image, msg)
+	}
+
+	digest, err := registry.Digest(image, crane.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the digest of pushed image %s: %w", image, err)
+	}
+	logging.Info("Image %s (%s) built and uploaded successfully with Docker.", image, digest)
+	return image + "@" + digest, nil
+}
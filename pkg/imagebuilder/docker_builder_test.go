@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/shell"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+)
+
+func stubDocker(t *testing.T, run func(args ...string) shell.CommandResult) {
+	t.Helper()
+	t.Setenv("USER", "testuser")
+	origExec, origLookPath := shell.ExecuteCommand, lookPath
+	t.Cleanup(func() { shell.ExecuteCommand, lookPath = origExec, origLookPath })
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+		if name != "docker" {
+			t.Fatalf("unexpected command: %s %s", name, strings.Join(args, " "))
+		}
+		return run(args...)
+	}
+}
+
+func TestBuildImageWithDocker(t *testing.T) {
+	reg := fakeRegistry(t)
+	var build []string
+	stubDocker(t, func(args ...string) shell.CommandResult {
+		switch args[0] {
+		case "build":
+			build = args
+		case "push":
+			if err := reg.AddImage(args[1], empty.Image); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return shell.CommandResult{}
+	})
+
+	dir := t.TempDir()
+	dockerfile := filepath.Join(dir, "docker", "Dockerfile")
+	if err := os.MkdirAll(filepath.Dir(dockerfile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dockerfile, []byte("FROM ubuntu\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := BuildImageWithDocker(context.Background(), ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}, dir, dockerfile, "linux/arm64", ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildImageWithDocker() error = %v", err)
+	}
+	image, digest, _ := strings.Cut(got, "@")
+	if !strings.HasPrefix(image, "us-central1-docker.pkg.dev/p/gcluster/testuser-runner:ctx-") || !strings.HasPrefix(digest, "sha256:") {
+		t.Errorf("expected a content-addressed image pinned to its digest, got %s", got)
+	}
+	want := []string{"build", "--platform", "linux/arm64", "-f", dockerfile, "-t", image, dir}
+	if strings.Join(build, " ") != strings.Join(want, " ") {
+		t.Errorf("docker %s, want docker %s", strings.Join(build, " "), strings.Join(want, " "))
+	}
+}
+
+func TestBuildImageWithDocker_Errors(t *testing.T) {
+	dir := t.TempDir()
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM ubuntu\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}
+
+	t.Run("push denied", func(t *testing.T) {
+		fakeRegistry(t)
+		stubDocker(t, func(args ...string) shell.CommandResult {
+			if args[0] == "push" {
+				return shell.CommandResult{ExitCode: 1, Stderr: "denied: Permission \"artifactregistry.repositories.uploadArtifacts\" denied"}
+			}
+			return shell.CommandResult{}
+		})
+		_, err := BuildImageWithDocker(context.Background(), repo, dir, dockerfile, "linux/amd64", ImageTag{})
+		if err == nil || !strings.Contains(err.Error(), "gcloud auth configure-docker us-central1-docker.pkg.dev") {
+			t.Errorf("expected a hint to configure Docker credentials, got %v", err)
+		}
+	})
+
+	t.Run("no docker", func(t *testing.T) {
+		stubDocker(t, func(args ...string) shell.CommandResult { return shell.CommandResult{} })
+		lookPath = func(string) (string, error) { return "", errors.New("executable file not found in $PATH") }
+		_, err := BuildImageWithDocker(context.Background(), repo, dir, dockerfile, "linux/amd64", ImageTag{})
+		if err == nil || !strings.Contains(err.Error(), "needs the docker CLI") {
+			t.Errorf("expected a missing docker error, got %v", err)
+		}
+	})
+
+	t.Run("dockerfile outside context", func(t *testing.T) {
+		_, err := BuildImageWithDocker(context.Background(), repo, t.TempDir(), dockerfile, "linux/amd64", ImageTag{})
+		if err == nil || !strings.Contains(err.Error(), "must be inside the build context") {
+			t.Errorf("expected a build context error, got %v", err)
+		}
+	})
+}
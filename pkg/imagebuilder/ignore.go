@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// DefaultIgnorePatterns are excluded from every build context, before the
+// patterns of its .dockerignore file.
+var DefaultIgnorePatterns = []string{
+	".git", ".terraform", ".ghpc", ".ansible", "vendor", "bin", "pkg", "node_modules", "*.log", "tmp/", ".DS_Store", "__pycache__",
+}
+
+// IgnoreMatcher decides which paths of a build context are excluded, with the
+// semantics of .dockerignore files: patterns are relative to the root of the
+// context, support `**`, and are evaluated in order, so that a later
+// `!pattern` re-includes paths excluded by an earlier one, including files
+// inside an excluded directory.
+//
+// Paths must be matched in the order of a directory walk, parents first.
+// IgnoreMatcher is not safe to use concurrently.
+type IgnoreMatcher struct {
+	patterns *patternmatcher.PatternMatcher
+	// parents holds the match results of the directories matched so far, so
+	// that their contents are matched against the same patterns.
+	parents map[string]patternmatcher.MatchInfo
+}
+
+// NewIgnoreMatcher returns an IgnoreMatcher for patterns in .dockerignore syntax.
+func NewIgnoreMatcher(patterns []string) (*IgnoreMatcher, error) {
+	pm, err := patternmatcher.New(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pattern matcher: %w", err)
+	}
+	return &IgnoreMatcher{patterns: pm, parents: map[string]patternmatcher.MatchInfo{}}, nil
+}
+
+// ReadDockerignorePatterns returns an IgnoreMatcher for defaultPatterns
+// followed by the patterns of the .dockerignore file in dir, if any.
+func ReadDockerignorePatterns(dir string, defaultPatterns []string) (*IgnoreMatcher, error) {
+	dockerignorePath := filepath.Join(dir, ".dockerignore")
+
+	patterns := make([]string, len(defaultPatterns))
+	copy(patterns, defaultPatterns)
+
+	if _, err := os.Stat(dockerignorePath); err == nil {
+		file, err := os.Open(dockerignorePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open .dockerignore file %q: %w", dockerignorePath, err)
+		}
+		defer file.Close()
+
+		filePatterns, err := ignorefile.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read .dockerignore file %q: %w", dockerignorePath, err)
+		}
+		patterns = append(patterns, filePatterns...)
+		logging.Debug("Found %d patterns in .dockerignore at %q", len(filePatterns), dockerignorePath)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat .dockerignore file %q: %w", dockerignorePath, err)
+	}
+
+	return NewIgnoreMatcher(patterns)
+}
+
+// Ignored reports whether relPath, relative to the root of the build context,
+// is excluded.
+func (m *IgnoreMatcher) Ignored(relPath string, isDir bool) (bool, error) {
+	relPath = path.Clean(filepath.ToSlash(relPath))
+	ignored, info, err := m.patterns.MatchesUsingParentResults(relPath, m.parents[path.Dir(relPath)])
+	if err != nil {
+		return false, fmt.Errorf("failed to check ignore patterns for %q: %w", relPath, err)
+	}
+	if isDir {
+		m.parents[relPath] = info
+	}
+	return ignored, nil
+}
+
+// SkipDir reports whether the contents of the excluded directory relPath can
+// be skipped, which is the case unless an exception pattern may re-include
+// some of them.
+func (m *IgnoreMatcher) SkipDir(relPath string) bool {
+	dir := path.Clean(filepath.ToSlash(relPath)) + "/"
+	for _, p := range m.patterns.Patterns() {
+		if !p.Exclusion() {
+			continue
+		}
+		// Only the part of the pattern before its first wildcard tells which
+		// directories it can match in.
+		prefix := filepath.ToSlash(p.String())
+		if i := strings.IndexAny(prefix, `*?[\`); i >= 0 {
+			prefix = prefix[:i]
+		}
+		if strings.HasPrefix(dir, prefix) || strings.HasPrefix(prefix, dir) {
+			return false
+		}
+	}
+	return true
+}
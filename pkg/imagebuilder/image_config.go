@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/config"
+
+	"github.com/go-git/go-git/v5"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// Labels that record the provenance of an image built from a base image.
+const (
+	LabelCreated        = "org.opencontainers.image.created"
+	LabelRevision       = "org.opencontainers.image.revision"
+	LabelToolkitVersion = "gcluster.google.com/version"
+)
+
+// ImageConfig holds the settings that a build from a base image sets in the
+// config of the image it pushes, on top of those of the base image. The zero
+// value keeps the config of the base image.
+type ImageConfig struct {
+	// Env sets environment variables, replacing those of the base image with
+	// the same name.
+	Env map[string]string
+	// Labels are added to the labels of the base image.
+	Labels map[string]string
+	// User is the user, and optionally the group, the image runs as, e.g. "1000:1000".
+	User string
+	// Entrypoint replaces the entrypoint of the base image.
+	Entrypoint []string
+	// Cmd replaces the default arguments of the base image.
+	Cmd []string
+}
+
+// writeKey writes the settings of c to the cache key h in a stable order.
+func (c ImageConfig) writeKey(h io.Writer) {
+	for _, k := range slices.Sorted(maps.Keys(c.Env)) {
+		fmt.Fprintf(h, "env %s=%s\n", k, c.Env[k])
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Labels)) {
+		fmt.Fprintf(h, "label %s=%s\n", k, c.Labels[k])
+	}
+	if c.User != "" {
+		fmt.Fprintf(h, "user %s\n", c.User)
+	}
+	if len(c.Entrypoint) > 0 {
+		fmt.Fprintf(h, "entrypoint %q\n", c.Entrypoint)
+	}
+	if len(c.Cmd) > 0 {
+		fmt.Fprintf(h, "cmd %q\n", c.Cmd)
+	}
+}
+
+// withProvenance returns c with labels that record when, from which git
+// commit of contextDir and by which gcluster version the image was built.
+// Labels set in c take precedence. They are not part of the cache key, so an
+// image reused from the cache keeps the labels of the build that pushed it.
+func (c ImageConfig) withProvenance(contextDir string) ImageConfig {
+	labels := map[string]string{
+		LabelCreated:        clock.Now().UTC().Format(time.RFC3339),
+		LabelToolkitVersion: config.GetToolkitVersion(),
+	}
+	if rev := gitRevision(contextDir); rev != "" {
+		labels[LabelRevision] = rev
+	}
+	maps.Copy(labels, c.Labels)
+	c.Labels = labels
+	return c
+}
+
+// gitRevision returns the commit checked out in the git repository that holds
+// dir, or "" when dir is not in one.
+func gitRevision(dir string) string {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return ""
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	return head.Hash().String()
+}
+
+// apply returns img with the settings of c and, when it is set, the working
+// directory workDir.
+func (c ImageConfig) apply(img v1.Image, workDir string) (v1.Image, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config: %w", err)
+	}
+	conf := *cfg.Config.DeepCopy()
+	if workDir != "" {
+		conf.WorkingDir = workDir
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Env)) {
+		conf.Env = setEnv(conf.Env, k, c.Env[k])
+	}
+	if len(c.Labels) > 0 {
+		if conf.Labels == nil {
+			conf.Labels = map[string]string{}
+		}
+		maps.Copy(conf.Labels, c.Labels)
+	}
+	if c.User != "" {
+		conf.User = c.User
+	}
+	if len(c.Entrypoint) > 0 {
+		conf.Entrypoint = c.Entrypoint
+	}
+	if len(c.Cmd) > 0 {
+		conf.Cmd = c.Cmd
+	}
+	img, err = mutate.Config(img, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set image config: %w", err)
+	}
+	return img, nil
+}
+
+// setEnv sets name to value in the KEY=VALUE list env, in place of an
+// existing definition of name.
+func setEnv(env []string, name, value string) []string {
+	for i, kv := range env {
+		if k, _, _ := strings.Cut(kv, "="); k == name {
+			env = slices.Clone(env)
+			env[i] = name + "=" + value
+			return env
+		}
+	}
+	return append(slices.Clone(env), name+"="+value)
+}
+
+// mutateArgs returns the gcrane mutate flags that apply c and, when it is set,
+// the working directory workDir. List values are CSV-encoded like gcrane
+// parses them, so they may contain commas.
+func (c ImageConfig) mutateArgs(workDir string) []string {
+	var args []string
+	if workDir != "" {
+		args = append(args, "--workdir", workDir)
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Env)) {
+		args = append(args, "--env", csvList([]string{k + "=" + c.Env[k]}))
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Labels)) {
+		args = append(args, "--label", csvList([]string{k + "=" + c.Labels[k]}))
+	}
+	if c.User != "" {
+		args = append(args, "--user", c.User)
+	}
+	if len(c.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", csvList(c.Entrypoint))
+	}
+	if len(c.Cmd) > 0 {
+		args = append(args, "--cmd", csvList(c.Cmd))
+	}
+	return args
+}
+
+func csvList(values []string) string {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(values)
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// TagStrategy selects the tag of the images the builders push.
+type TagStrategy string
+
+const (
+	// TagContentHash tags an image with a hash of its build inputs, so
+	// builds of the same inputs get the same tag. It is the default.
+	TagContentHash TagStrategy = "content-hash"
+	// TagGitSHA tags an image with the commit checked out in the git
+	// repository of its build context.
+	TagGitSHA TagStrategy = "git-sha"
+	// TagUserSupplied tags an image with ImageTag.Value.
+	TagUserSupplied TagStrategy = "user"
+)
+
+// TagStrategies lists the valid values of ImageTag.Strategy.
+var TagStrategies = []string{string(TagContentHash), string(TagGitSHA), string(TagUserSupplied)}
+
+// cacheTagPrefix starts the tags of the images of the build cache, which are
+// named after the content address of their build inputs.
+const cacheTagPrefix = "ctx-"
+
+// ImageTag selects the tag of a built image. The zero value tags by content
+// hash.
+type ImageTag struct {
+	Strategy TagStrategy // Empty means TagContentHash.
+	// Value is the tag of TagUserSupplied.
+	Value string
+}
+
+var validTag = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// ValidateImageTag checks that tag is a valid image tag that does not take
+// the place of an image of the build cache.
+func ValidateImageTag(tag string) error {
+	if !validTag.MatchString(tag) {
+		return fmt.Errorf("invalid image tag %q: it must be at most 128 letters, digits, '_', '.' or '-' and not start with '.' or '-'", tag)
+	}
+	if strings.HasPrefix(tag, cacheTagPrefix) {
+		return fmt.Errorf("invalid image tag %q: tags starting with %q are reserved for the build cache", tag, cacheTagPrefix)
+	}
+	return nil
+}
+
+// Validate checks the strategy of t and that only TagUserSupplied sets a
+// valid Value.
+func (t ImageTag) Validate() error {
+	switch t.Strategy {
+	case "", TagContentHash, TagGitSHA:
+		if t.Value != "" {
+			return fmt.Errorf("an image tag can only be set with the %s tag strategy", TagUserSupplied)
+		}
+		return nil
+	case TagUserSupplied:
+		if t.Value == "" {
+			return fmt.Errorf("the %s tag strategy needs an image tag", TagUserSupplied)
+		}
+		return ValidateImageTag(t.Value)
+	}
+	return fmt.Errorf("unknown image tag strategy %q, valid strategies are %s", t.Strategy, strings.Join(TagStrategies, ", "))
+}
+
+func (t ImageTag) contentHash() bool {
+	return t.Strategy == "" || t.Strategy == TagContentHash
+}
+
+// resolve returns the tag t gives an image built from contextDir, whose build
+// inputs hash to hash.
+func (t ImageTag) resolve(contextDir, hash string) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	switch t.Strategy {
+	case TagGitSHA:
+		rev := gitRevision(contextDir)
+		if rev == "" {
+			return "", fmt.Errorf("the %s tag strategy needs the build context %s to be in a git repository with a commit", TagGitSHA, contextDir)
+		}
+		dirty, err := gitContextDirty(contextDir)
+		if err != nil {
+			return "", err
+		}
+		if !dirty {
+			return "git-" + rev[:12], nil
+		}
+		// The commit does not identify the content of a dirty build context,
+		// so its tag would move between builds of different content.
+		if hash == "" {
+			return "git-" + rev[:12] + "-dirty", nil
+		}
+		return "git-" + rev[:12] + "-dirty-" + hash[:8], nil
+	case TagUserSupplied:
+		return t.Value, nil
+	}
+	return cacheTagPrefix + hash, nil
+}
+
+// PredictImageName returns the name of the image a build from contextDir
+// with tag pushes to repo, for dry runs. A content hash needs the build
+// inputs, which dry runs do not resolve, so a unique name stands for it, and
+// the tag of a dirty git build context lacks it.
+func PredictImageName(repo ImageRepo, tag ImageTag, contextDir string) (string, error) {
+	if tag.contentHash() {
+		if err := tag.Validate(); err != nil {
+			return "", err
+		}
+		return GenerateImageName(repo)
+	}
+	t, err := tag.resolve(contextDir, "")
+	if err != nil {
+		return "", err
+	}
+	return runnerImageName(repo, t)
+}
+
+// tagAndPin adds tag to the pushed image image, unless it already has it, and
+// returns the reference that pins it to its digest, e.g.
+// "<repo>/alice-runner:git-0123456789ab@sha256:...". The tag keeps the
+// reference readable; the digest is what is pulled.
+func tagAndPin(ctx context.Context, image, tag string) (string, error) {
+	ref, err := name.NewTag(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference %q: %w", image, err)
+	}
+	digest, err := registry.Digest(image, crane.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the digest of image %s: %w", image, err)
+	}
+	if ref.TagStr() != tag {
+		if err := registry.Tag(image, tag, crane.WithContext(ctx)); err != nil {
+			return "", fmt.Errorf("failed to tag image %s as %s: %w", image, tag, err)
+		}
+		ref = ref.Context().Tag(tag)
+		logging.Info("Tagged image %s as %s", image, ref)
+	}
+	return ref.String() + "@" + digest, nil
+}
+
+// dockerfileImageName returns the name of the image built from the Dockerfile
+// rel on buildContext with tag. The content hash covers the files Docker
+// sends, all but those of the .dockerignore, as well as the Dockerfile and the
+// platform. A Dockerfile can fetch more than its context, e.g. a base image
+// tag that moved, so the image is built even when the name exists.
+func dockerfileImageName(repo ImageRepo, tag ImageTag, buildContext, rel, platformStr string) (string, error) {
+	var hash string
+	if tag.Strategy != TagUserSupplied {
+		ignoreMatcher, err := ReadDockerignorePatterns(buildContext, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to read .dockerignore patterns: %w", err)
+		}
+		buildCtx, err := scanBuildContext(buildContext, ignoreMatcher)
+		if err != nil {
+			return "", fmt.Errorf("failed to read build context: %w", err)
+		}
+		dockerfileDigest, err := hashFile(filepath.Join(buildContext, rel))
+		if err != nil {
+			return "", err
+		}
+		hash = buildCtx.cacheKey(fmt.Sprintf("dockerfile %s %s", filepath.ToSlash(rel), dockerfileDigest), platformStr, ImageConfig{})
+	}
+	t, err := tag.resolve(buildContext, hash)
+	if err != nil {
+		return "", err
+	}
+	return runnerImageName(repo, t)
+}
+
+// gitContextDirty reports whether the git repository that holds dir has
+// uncommitted changes under dir, untracked files included. Files ignored by
+// git do not count.
+func gitContextDirty(dir string) (bool, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return false, fmt.Errorf("failed to open the git repository of %s: %w", dir, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("failed to open the git worktree of %s: %w", dir, err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return false, fmt.Errorf("failed to read the git status of %s: %w", dir, err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(wt.Filesystem.Root(), absDir)
+	if err != nil {
+		return false, err
+	}
+	prefix := filepath.ToSlash(rel) + "/"
+	for file, st := range status {
+		if st.Staging == git.Unmodified && st.Worktree == git.Unmodified {
+			continue
+		}
+		if rel == "." || strings.HasPrefix(file, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
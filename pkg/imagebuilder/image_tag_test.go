@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+)
+
+func TestImageTagValidate(t *testing.T) {
+	tests := []struct {
+		tag     ImageTag
+		wantErr string
+	}{
+		{ImageTag{}, ""},
+		{ImageTag{Strategy: TagContentHash}, ""},
+		{ImageTag{Strategy: TagGitSHA}, ""},
+		{ImageTag{Strategy: TagUserSupplied, Value: "v1.2_rc-1"}, ""},
+		{ImageTag{Strategy: TagUserSupplied}, "needs an image tag"},
+		{ImageTag{Strategy: TagUserSupplied, Value: "-v1"}, "invalid image tag"},
+		{ImageTag{Strategy: TagUserSupplied, Value: "v1:latest"}, "invalid image tag"},
+		{ImageTag{Strategy: TagUserSupplied, Value: strings.Repeat("a", 129)}, "invalid image tag"},
+		{ImageTag{Strategy: TagUserSupplied, Value: "ctx-0123"}, "reserved for the build cache"},
+		{ImageTag{Strategy: TagGitSHA, Value: "v1"}, "only be set with the user tag strategy"},
+		{ImageTag{Strategy: "latest"}, "unknown image tag strategy"},
+	}
+	for _, tc := range tests {
+		err := tc.tag.Validate()
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("%+v: Validate() error = %v", tc.tag, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%+v: Validate() error = %v, want it to contain %q", tc.tag, err, tc.wantErr)
+		}
+	}
+}
+
+// commitAll commits the files of dir to a new git repository and returns the
+// commit.
+func commitAll(t *testing.T, dir string) string {
+	t.Helper()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.AddGlob("."); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := wt.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(0, 0)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func TestBuildContainerImageFromBaseImage_TagStrategies(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", empty.Image); err != nil {
+		t.Fatal(err)
+	}
+	tempDir := t.TempDir()
+	createTestFiles(t, tempDir)
+	commit := commitAll(t, tempDir)
+	matcher, _ := NewIgnoreMatcher([]string{".git"})
+	repo := ImageRepo{Location: "us-central1", Project: "test-project", Repository: "gcluster"}
+	build := func(tag ImageTag) string {
+		t.Helper()
+		got, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", tempDir, "", "linux/amd64", matcher, ImageConfig{}, tag)
+		if err != nil {
+			t.Fatalf("BuildContainerImageFromBaseImage(%+v) error = %v", tag, err)
+		}
+		return got
+	}
+
+	cached := build(ImageTag{})
+	cachedName, digest, _ := strings.Cut(cached, "@")
+	if !strings.Contains(cachedName, ":ctx-") || !strings.HasPrefix(digest, "sha256:") {
+		t.Fatalf("expected the content-addressed image pinned to its digest, got %s", cached)
+	}
+
+	wantName := "us-central1-docker.pkg.dev/test-project/gcluster/testuser-runner:git-" + commit[:12]
+	if got := build(ImageTag{Strategy: TagGitSHA}); got != wantName+"@"+digest {
+		t.Errorf("git-sha build = %s, want %s@%s", got, wantName, digest)
+	}
+	if got := build(ImageTag{Strategy: TagUserSupplied, Value: "v1"}); !strings.HasSuffix(got, "-runner:v1@"+digest) {
+		t.Errorf("expected the cached image tagged v1, got %s", got)
+	}
+	if d, err := reg.Digest("us-central1-docker.pkg.dev/test-project/gcluster/testuser-runner:v1"); err != nil || d != digest {
+		t.Errorf("expected v1 to be tagged in the registry, got %s, %v", d, err)
+	}
+	pushes := 0
+	for _, c := range reg.Calls() {
+		if strings.HasPrefix(c, "Push ") {
+			pushes++
+		}
+	}
+	if pushes != 1 {
+		t.Errorf("expected unchanged build inputs to be pushed once and then tagged, got %d pushes in %v", pushes, reg.Calls())
+	}
+}
+
+func TestBuildContainerImageFromBaseImage_GitSHADirty(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", empty.Image); err != nil {
+		t.Fatal(err)
+	}
+	repoDir := t.TempDir()
+	contextDir := filepath.Join(repoDir, "app")
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	createTestFiles(t, contextDir)
+	commit := commitAll(t, repoDir)
+	matcher, _ := NewIgnoreMatcher(nil)
+	build := func() string {
+		t.Helper()
+		got, err := BuildContainerImageFromBaseImage(context.Background(), ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}, "ubuntu", contextDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{Strategy: TagGitSHA})
+		if err != nil {
+			t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
+		}
+		name, _, _ := strings.Cut(got, "@")
+		return name
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("outside the build context\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	clean := build()
+	if !strings.HasSuffix(clean, ":git-"+commit[:12]) {
+		t.Errorf("expected changes outside the build context to keep the commit tag, got %s", clean)
+	}
+
+	if err := os.WriteFile(filepath.Join(contextDir, "foo.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dirty := build()
+	if !strings.Contains(dirty, ":git-"+commit[:12]+"-dirty-") {
+		t.Errorf("expected the tag of a dirty build context to be marked dirty, got %s", dirty)
+	}
+	if err := os.WriteFile(filepath.Join(contextDir, "foo.txt"), []byte("changed again"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if again := build(); again == dirty || !strings.Contains(again, "-dirty-") {
+		t.Errorf("expected different uncommitted content to get a different tag than %s, got %s", dirty, again)
+	}
+}
+
+func TestBuildContainerImageFromBaseImage_GitSHAOutsideGit(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", empty.Image); err != nil {
+		t.Fatal(err)
+	}
+	tempDir := t.TempDir()
+	createTestFiles(t, tempDir)
+	matcher, _ := NewIgnoreMatcher(nil)
+	_, err := BuildContainerImageFromBaseImage(context.Background(), ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}, "ubuntu", tempDir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{Strategy: TagGitSHA})
+	if err == nil || !strings.Contains(err.Error(), "git repository") {
+		t.Fatalf("expected an error for a build context outside git, got %v", err)
+	}
+	for _, c := range reg.Calls() {
+		if strings.HasPrefix(c, "Push") {
+			t.Errorf("expected nothing to be pushed, got %s", c)
+		}
+	}
+}
+
+func TestDockerfileImageName(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	repo := ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}
+	dir := writeBuildContext(t, map[string]string{"Dockerfile": "FROM ubuntu\n", "app.py": "print(1)\n", "notes.md": "draft\n", ".dockerignore": "*.md\n"})
+	name := func() string {
+		t.Helper()
+		got, err := dockerfileImageName(repo, ImageTag{}, dir, "Dockerfile", "linux/amd64")
+		if err != nil {
+			t.Fatalf("dockerfileImageName() error = %v", err)
+		}
+		return got
+	}
+
+	first := name()
+	if !strings.HasPrefix(first, "us-central1-docker.pkg.dev/p/gcluster/testuser-runner:ctx-") {
+		t.Fatalf("expected a content-addressed name, got %s", first)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("final\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := name(); got != first {
+		t.Errorf("expected a change to an ignored file to keep the name %s, got %s", first, got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.py"), []byte("print(2)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := name(); got == first {
+		t.Errorf("expected a change to the build context to change the name %s", got)
+	}
+}
+
+func TestPredictImageName(t *testing.T) {
+	t.Setenv("USER", "testuser")
+	repo := ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}
+	got, err := PredictImageName(repo, ImageTag{Strategy: TagUserSupplied, Value: "v1"}, t.TempDir())
+	if err != nil || got != "us-central1-docker.pkg.dev/p/gcluster/testuser-runner:v1" {
+		t.Errorf("PredictImageName() = %s, %v, want the user-supplied tag", got, err)
+	}
+	if _, err := PredictImageName(repo, ImageTag{Strategy: TagUserSupplied, Value: "ctx-1"}, t.TempDir()); err == nil {
+		t.Error("expected a reserved tag to be rejected")
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+)
+
+// SetRegistryLogOutput sets where the registry client used to pull and push
+// images logs. progress receives its warnings and notable events, such as
+// layers pushed or already present; debug receives every HTTP request and
+// response. io.Discard turns either off, which is the default.
+func SetRegistryLogOutput(progress, debug io.Writer) {
+	logs.Warn.SetOutput(progress)
+	logs.Progress.SetOutput(progress)
+	logs.Debug.SetOutput(debug)
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"time"
+
+	"hpc-toolkit/pkg/retry"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// Registry is the container registry client images are resolved, pulled and
+// pushed with. The default talks to real registries through crane; tests and
+// embedding tools replace it with SetRegistry.
+type Registry interface {
+	Digest(ref string, opts ...crane.Option) (string, error)
+	Head(ref string, opts ...crane.Option) (*v1.Descriptor, error)
+	Pull(ref string, opts ...crane.Option) (v1.Image, error)
+	Push(img v1.Image, ref string, opts ...crane.Option) error
+	// PushLayer uploads the blob of a layer to the repository repo.
+	PushLayer(l v1.Layer, repo string, opts ...crane.Option) error
+	// PushIndex pushes a multi-platform image index and its images.
+	PushIndex(idx v1.ImageIndex, ref string, opts ...crane.Option) error
+	// Tag adds tag to the image ref, in the repository of ref.
+	Tag(ref, tag string, opts ...crane.Option) error
+	Manifest(ref string, opts ...crane.Option) ([]byte, error)
+	Config(ref string, opts ...crane.Option) ([]byte, error)
+}
+
+// Clock tells the time and waits between polls.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type craneRegistry struct{}
+
+func (craneRegistry) Digest(ref string, opts ...crane.Option) (string, error) {
+	return crane.Digest(ref, withRetry(withAuth(opts))...)
+}
+
+func (craneRegistry) Head(ref string, opts ...crane.Option) (*v1.Descriptor, error) {
+	return crane.Head(ref, withRetry(withAuth(opts))...)
+}
+
+func (craneRegistry) Pull(ref string, opts ...crane.Option) (v1.Image, error) {
+	return crane.Pull(ref, withRetry(withAuth(opts))...)
+}
+
+func (craneRegistry) Push(img v1.Image, ref string, opts ...crane.Option) error {
+	return crane.Push(img, ref, withRetry(withAuth(opts))...)
+}
+
+func (craneRegistry) PushLayer(l v1.Layer, repo string, opts ...crane.Option) error {
+	o := crane.GetOptions(withRetry(withAuth(opts))...)
+	r, err := name.NewRepository(repo, o.Name...)
+	if err != nil {
+		return err
+	}
+	return remote.WriteLayer(r, l, o.Remote...)
+}
+
+func (craneRegistry) PushIndex(idx v1.ImageIndex, ref string, opts ...crane.Option) error {
+	o := crane.GetOptions(withRetry(withAuth(opts))...)
+	r, err := name.ParseReference(ref, o.Name...)
+	if err != nil {
+		return err
+	}
+	return remote.WriteIndex(r, idx, o.Remote...)
+}
+
+func (craneRegistry) Tag(ref, tag string, opts ...crane.Option) error {
+	return crane.Tag(ref, tag, withRetry(withAuth(opts))...)
+}
+
+func (craneRegistry) Manifest(ref string, opts ...crane.Option) ([]byte, error) {
+	return crane.Manifest(ref, withRetry(withAuth(opts))...)
+}
+
+func (craneRegistry) Config(ref string, opts ...crane.Option) ([]byte, error) {
+	return crane.Config(ref, withRetry(withAuth(opts))...)
+}
+
+// registryRetry is the retry policy of registry requests.
+var registryRetry = retry.Default
+
+// withRetry makes crane retry requests that fail with a transient error or
+// status, including the rate limits of Artifact Registry, with the backoff of
+// registryRetry. Options set by the caller take precedence.
+func withRetry(opts []crane.Option) []crane.Option {
+	p := registryRetry
+	backoff := remote.Backoff{Duration: p.Initial, Factor: p.Multiplier, Jitter: p.Jitter, Steps: p.Attempts, Cap: p.Max}
+	return append([]crane.Option{func(o *crane.Options) {
+		o.Remote = append(o.Remote, remote.WithRetryBackoff(backoff), remote.WithRetryStatusCodes(retry.TransientStatusCodes...))
+	}}, opts...)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+var (
+	registry Registry = craneRegistry{}
+	clock    Clock    = realClock{}
+)
+
+// SetRegistry makes the package use r for every registry request and returns
+// a function that restores the previous client.
+func SetRegistry(r Registry) (restore func()) {
+	prev := registry
+	registry = r
+	return func() { registry = prev }
+}
+
+// SetClock makes the package read the time from and wait on c, and returns a
+// function that restores the previous clock.
+func SetClock(c Clock) (restore func()) {
+	prev := clock
+	clock = c
+	return func() { clock = prev }
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Registry authentication modes of RegistryAuthOptions.
+const (
+	// RegistryAuthDefault uses the Docker keychain: the credentials saved by
+	// docker login and credential helpers such as docker-credential-gcloud.
+	RegistryAuthDefault = "default"
+	// RegistryAuthBasic uses a user name and password.
+	RegistryAuthBasic = "basic"
+	// RegistryAuthJSONKey uses a service account key file.
+	RegistryAuthJSONKey = "json-key"
+	// RegistryAuthAccessToken uses an OAuth access token minted elsewhere.
+	RegistryAuthAccessToken = "access-token"
+	// RegistryAuthWorkloadIdentity exchanges the token of an external
+	// identity provider for a Google access token with Workload Identity
+	// Federation.
+	RegistryAuthWorkloadIdentity = "workload-identity"
+)
+
+// RegistryAuthModes lists the valid values of RegistryAuthOptions.Mode.
+var RegistryAuthModes = []string{RegistryAuthDefault, RegistryAuthBasic, RegistryAuthJSONKey, RegistryAuthAccessToken, RegistryAuthWorkloadIdentity}
+
+// Environment variables that hold registry secrets, so that they do not show
+// up in shell history or process lists.
+const (
+	RegistryUsernameEnv = "GCLUSTER_REGISTRY_USERNAME"
+	RegistryPasswordEnv = "GCLUSTER_REGISTRY_PASSWORD"
+	RegistryTokenEnv    = "GCLUSTER_REGISTRY_TOKEN"
+)
+
+const (
+	// googleRegistryUser is the user name Google registries accept OAuth
+	// access tokens with.
+	googleRegistryUser = "oauth2accesstoken"
+	// jsonKeyUser is the user name Google registries accept service
+	// account keys with.
+	jsonKeyUser   = "_json_key"
+	registryScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// RegistryAuthOptions selects how registry requests are authenticated.
+type RegistryAuthOptions struct {
+	Mode string // One of RegistryAuthModes; empty means RegistryAuthDefault.
+	// CredentialsFile is the service account key of RegistryAuthJSONKey, or
+	// the credential configuration of RegistryAuthWorkloadIdentity, which
+	// defaults to GOOGLE_APPLICATION_CREDENTIALS.
+	CredentialsFile string
+	// NonInteractive never runs gcloud or Docker credential helpers, which may
+	// prompt or open a browser. RegistryAuthDefault then uses Application
+	// Default Credentials, and registries other than those of Google are
+	// accessed anonymously.
+	NonInteractive bool
+}
+
+// AuthProvider supplies the credentials of registry requests. It is an
+// authn.Keychain, so it plugs into crane.
+type AuthProvider interface {
+	Resolve(resource authn.Resource) (authn.Authenticator, error)
+}
+
+// NewAuthProvider returns the provider of opts. The credentials of the
+// explicit modes are only sent to Artifact Registry and Container Registry,
+// which gcluster pushes images to; other registries, such as the one of a
+// public base image, use the Docker keychain. It returns nil for the default
+// mode, which keeps the Docker keychain of crane.
+func NewAuthProvider(opts RegistryAuthOptions) (AuthProvider, error) {
+	var fallback authn.Keychain = authn.DefaultKeychain
+	if opts.NonInteractive {
+		fallback = anonymousKeychain{}
+	}
+
+	var auth authn.Authenticator
+	switch opts.Mode {
+	case "", RegistryAuthDefault:
+		if !opts.NonInteractive {
+			return nil, nil
+		}
+		auth = &adcAuthenticator{}
+	case RegistryAuthBasic:
+		user, password := os.Getenv(RegistryUsernameEnv), os.Getenv(RegistryPasswordEnv)
+		if user == "" || password == "" {
+			return nil, fmt.Errorf("--registry-auth %s reads the user name and password from %s and %s, which must be set", opts.Mode, RegistryUsernameEnv, RegistryPasswordEnv)
+		}
+		auth = &authn.Basic{Username: user, Password: password}
+	case RegistryAuthJSONKey:
+		key, err := readCredentials(opts.CredentialsFile, "service_account")
+		if err != nil {
+			return nil, err
+		}
+		auth = &authn.Basic{Username: jsonKeyUser, Password: string(key)}
+	case RegistryAuthAccessToken:
+		token := os.Getenv(RegistryTokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("--registry-auth %s reads the access token from %s, which must be set", opts.Mode, RegistryTokenEnv)
+		}
+		auth = &authn.Basic{Username: googleRegistryUser, Password: token}
+	case RegistryAuthWorkloadIdentity:
+		file := opts.CredentialsFile
+		if file == "" {
+			file = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+		}
+		config, err := readCredentials(file, "external_account")
+		if err != nil {
+			return nil, err
+		}
+		creds, err := google.CredentialsFromJSON(context.Background(), config, registryScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Workload Identity Federation credentials from %s: %w", file, err)
+		}
+		auth = tokenAuthenticator{creds.TokenSource}
+	default:
+		return nil, fmt.Errorf("invalid registry authentication mode %q. Allowed values: %s", opts.Mode, strings.Join(RegistryAuthModes, ", "))
+	}
+	return googleRegistryAuth{auth: auth, fallback: fallback}, nil
+}
+
+// readCredentials reads the credentials file of a mode and checks that it is
+// of the given type, so that a file meant for something else is not sent to
+// a registry or token endpoint.
+func readCredentials(file, wantType string) ([]byte, error) {
+	if file == "" {
+		return nil, fmt.Errorf("a credentials file of type %s is required, set one with --registry-credentials", wantType)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry credentials: %w", err)
+	}
+	var f struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse registry credentials %s: %w", file, err)
+	}
+	if f.Type != wantType {
+		return nil, fmt.Errorf("registry credentials %s are of type %q, want %q", file, f.Type, wantType)
+	}
+	return data, nil
+}
+
+// googleRegistryAuth authenticates to Google registries with auth and to
+// other registries with fallback.
+type googleRegistryAuth struct {
+	auth     authn.Authenticator
+	fallback authn.Keychain
+}
+
+func (a googleRegistryAuth) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	if isGoogleRegistry(resource.RegistryStr()) {
+		return a.auth, nil
+	}
+	return a.fallback.Resolve(resource)
+}
+
+func isGoogleRegistry(host string) bool {
+	return host == "gcr.io" || strings.HasSuffix(host, ".gcr.io") || strings.HasSuffix(host, "docker.pkg.dev")
+}
+
+type anonymousKeychain struct{}
+
+func (anonymousKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return authn.Anonymous, nil
+}
+
+// tokenAuthenticator authenticates with access tokens of a token source,
+// which refreshes them as they expire.
+type tokenAuthenticator struct {
+	ts oauth2.TokenSource
+}
+
+func (a tokenAuthenticator) Authorization() (*authn.AuthConfig, error) {
+	tok, err := a.ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a registry access token: %w", err)
+	}
+	return &authn.AuthConfig{Username: googleRegistryUser, Password: tok.AccessToken}, nil
+}
+
+// adcAuthenticator authenticates with Application Default Credentials, which
+// are only looked up for the first request.
+type adcAuthenticator struct {
+	once sync.Once
+	ts   oauth2.TokenSource
+	err  error
+}
+
+func (a *adcAuthenticator) Authorization() (*authn.AuthConfig, error) {
+	a.once.Do(func() {
+		creds, err := google.FindDefaultCredentials(context.Background(), registryScope)
+		if err != nil {
+			a.err = fmt.Errorf("no Application Default Credentials for registry access in non-interactive mode, pass --registry-auth: %w", err)
+			return
+		}
+		a.ts = creds.TokenSource
+	})
+	if a.err != nil {
+		return nil, a.err
+	}
+	return tokenAuthenticator{a.ts}.Authorization()
+}
+
+var registryAuth AuthProvider
+
+// SetRegistryAuth makes the package authenticate registry requests with p,
+// or with the Docker keychain when p is nil, and returns a function that
+// restores the previous provider.
+func SetRegistryAuth(p AuthProvider) (restore func()) {
+	prev := registryAuth
+	registryAuth = p
+	return func() { registryAuth = prev }
+}
+
+// withAuth makes crane authenticate with the provider of SetRegistryAuth.
+// Options set by the caller take precedence.
+func withAuth(opts []crane.Option) []crane.Option {
+	if registryAuth == nil {
+		return opts
+	}
+	return append([]crane.Option{crane.WithAuthFromKeychain(registryAuth)}, opts...)
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// resolve returns the credentials p sends to registry.
+func resolve(t *testing.T, p AuthProvider, registry string) *authn.AuthConfig {
+	t.Helper()
+	reg, err := name.NewRegistry(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := p.Resolve(reg)
+	if err != nil {
+		t.Fatalf("Resolve(%s) error = %v", registry, err)
+	}
+	cfg, err := auth.Authorization()
+	if err != nil {
+		t.Fatalf("Authorization(%s) error = %v", registry, err)
+	}
+	return cfg
+}
+
+func writeCredentials(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestNewAuthProvider(t *testing.T) {
+	t.Setenv(RegistryUsernameEnv, "ci-bot")
+	t.Setenv(RegistryPasswordEnv, "s3cret")
+	t.Setenv(RegistryTokenEnv, "ya29.token")
+	key := writeCredentials(t, map[string]string{"type": "service_account", "client_email": "ci@p.iam.gserviceaccount.com"})
+	keyData, _ := os.ReadFile(key)
+
+	tests := []struct {
+		name         string
+		opts         RegistryAuthOptions
+		wantUser     string
+		wantPassword string
+	}{
+		{"basic", RegistryAuthOptions{Mode: RegistryAuthBasic}, "ci-bot", "s3cret"},
+		{"access token", RegistryAuthOptions{Mode: RegistryAuthAccessToken}, "oauth2accesstoken", "ya29.token"},
+		{"json key", RegistryAuthOptions{Mode: RegistryAuthJSONKey, CredentialsFile: key}, "_json_key", string(keyData)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.NonInteractive = true
+			p, err := NewAuthProvider(tc.opts)
+			if err != nil {
+				t.Fatalf("NewAuthProvider() error = %v", err)
+			}
+			for _, registry := range []string{"us-central1-docker.pkg.dev", "gcr.io", "eu.gcr.io"} {
+				if got := resolve(t, p, registry); got.Username != tc.wantUser || got.Password != tc.wantPassword {
+					t.Errorf("credentials for %s = %s/%s, want %s/%s", registry, got.Username, got.Password, tc.wantUser, tc.wantPassword)
+				}
+			}
+			if got := resolve(t, p, "index.docker.io"); *got != (authn.AuthConfig{}) {
+				t.Errorf("expected no credentials for Docker Hub, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestNewAuthProvider_Errors(t *testing.T) {
+	t.Setenv(RegistryUsernameEnv, "")
+	t.Setenv(RegistryTokenEnv, "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	key := writeCredentials(t, map[string]string{"type": "authorized_user"})
+
+	tests := []struct {
+		name    string
+		opts    RegistryAuthOptions
+		wantErr string
+	}{
+		{"unknown mode", RegistryAuthOptions{Mode: "kerberos"}, `invalid registry authentication mode "kerberos"`},
+		{"basic without user", RegistryAuthOptions{Mode: RegistryAuthBasic}, RegistryUsernameEnv},
+		{"access token without token", RegistryAuthOptions{Mode: RegistryAuthAccessToken}, RegistryTokenEnv},
+		{"json key without file", RegistryAuthOptions{Mode: RegistryAuthJSONKey}, "set one with --registry-credentials"},
+		{"json key of another type", RegistryAuthOptions{Mode: RegistryAuthJSONKey, CredentialsFile: key}, `of type "authorized_user", want "service_account"`},
+		{"workload identity with a key", RegistryAuthOptions{Mode: RegistryAuthWorkloadIdentity, CredentialsFile: key}, `want "external_account"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewAuthProvider(tc.opts); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("NewAuthProvider() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewAuthProvider_Default(t *testing.T) {
+	p, err := NewAuthProvider(RegistryAuthOptions{})
+	if err != nil || p != nil {
+		t.Errorf("NewAuthProvider() = %v, %v; want the Docker keychain of crane", p, err)
+	}
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+	p, err = NewAuthProvider(RegistryAuthOptions{NonInteractive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, _ := name.NewRegistry("us-central1-docker.pkg.dev")
+	auth, err := p.Resolve(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authorization(); err == nil || !strings.Contains(err.Error(), "no Application Default Credentials") {
+		t.Errorf("expected non-interactive mode to fail without Application Default Credentials, got %v", err)
+	}
+}
+
+func TestNewAuthProvider_WorkloadIdentity(t *testing.T) {
+	var subjectToken string
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		subjectToken = r.Form.Get("subject_token")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      "federated-token",
+			"issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+			"token_type":        "Bearer",
+			"expires_in":        3600,
+		})
+	}))
+	defer sts.Close()
+
+	oidcToken := filepath.Join(t.TempDir(), "oidc-token")
+	if err := os.WriteFile(oidcToken, []byte("github-oidc-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := writeCredentials(t, map[string]any{
+		"type":               "external_account",
+		"audience":           "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/ci/providers/github",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":          sts.URL,
+		"credential_source":  map[string]string{"file": oidcToken},
+	})
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", config)
+
+	p, err := NewAuthProvider(RegistryAuthOptions{Mode: RegistryAuthWorkloadIdentity})
+	if err != nil {
+		t.Fatalf("NewAuthProvider() error = %v", err)
+	}
+	got := resolve(t, p, "europe-docker.pkg.dev")
+	if got.Username != "oauth2accesstoken" || got.Password != "federated-token" {
+		t.Errorf("credentials = %s/%s, want the exchanged access token", got.Username, got.Password)
+	}
+	if subjectToken != "github-oidc-token" {
+		t.Errorf("expected the external token to be exchanged, got subject token %q", subjectToken)
+	}
+}
+
+func TestSetRegistryAuth(t *testing.T) {
+	if o := crane.GetOptions(withAuth(nil)...); o.Keychain != authn.DefaultKeychain {
+		t.Errorf("expected the Docker keychain of crane without a provider, got %v", o.Keychain)
+	}
+	p := googleRegistryAuth{auth: authn.Anonymous, fallback: anonymousKeychain{}}
+	restore := SetRegistryAuth(p)
+	defer restore()
+	if o := crane.GetOptions(withAuth(nil)...); o.Keychain != p {
+		t.Errorf("expected registry requests to use the provider, got %v", o.Keychain)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	ggcrregistry "github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestCraneRegistry_RetriesTransientStatus(t *testing.T) {
+	orig := registryRetry
+	t.Cleanup(func() { registryRetry = orig })
+	registryRetry.Initial = time.Millisecond
+
+	backend := ggcrregistry.New(ggcrregistry.Logger(log.New(io.Discard, "", 0)))
+	var enabled atomic.Bool
+	var limited atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Rate limit the first two manifest requests.
+		if enabled.Load() && strings.Contains(r.URL.Path, "/manifests/") && limited.Add(1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	img, err := random.Image(16, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := strings.TrimPrefix(srv.URL, "http://") + "/app:v1"
+	if err := crane.Push(img, ref, crane.Insecure); err != nil {
+		t.Fatal(err)
+	}
+	enabled.Store(true)
+
+	want, _ := img.Digest()
+	got, err := craneRegistry{}.Digest(ref, crane.Insecure)
+	if err != nil || got != want.String() {
+		t.Fatalf("Digest() = %q, %v; want %s after the rate limit clears", got, err, want)
+	}
+	if n := limited.Load(); n != 3 {
+		t.Errorf("expected 2 rate-limited requests and 1 retry that succeeds, got %d requests", n)
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/shell"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const (
+	remoteBuilderMachineType = "e2-standard-4"
+	// remoteBuilderLifetime is how long a builder VM lives before it deletes
+	// itself. Builds within that time reuse it and its copy of the build
+	// context, so only the files that changed are uploaded.
+	remoteBuilderLifetime  = "2h"
+	remoteBuilderReadyFile = "/var/lib/gcluster-builder/ready"
+	remoteBuilderGcrane    = "v0.20.7"
+)
+
+var (
+	lookPath                  = exec.LookPath
+	remoteBuilderPollInterval = 10 * time.Second
+	remoteBuilderStartTimeout = 5 * time.Minute
+)
+
+// multiRegionBuilderRegions are the regions builder VMs run in for the
+// Artifact Registry multi-regions.
+var multiRegionBuilderRegions = map[string]string{
+	"us":     "us-central1",
+	"europe": "europe-west4",
+	"asia":   "asia-east1",
+}
+
+// remoteBuilderStartupScript installs the tools a builder VM needs: rsync to
+// receive the build context and gcrane, which pushes with the credentials of
+// the VM's service account.
+var remoteBuilderStartupScript = `#!/bin/bash
+set -euo pipefail
+apt-get update
+apt-get install -y rsync
+curl -fsSL https://github.com/google/go-containerregistry/releases/download/` + remoteBuilderGcrane + `/go-containerregistry_Linux_x86_64.tar.gz | tar -xz -C /usr/local/bin gcrane
+mkdir -p ` + path.Dir(remoteBuilderReadyFile) + `
+touch ` + remoteBuilderReadyFile + `
+`
+
+// RemoteBuilder is a short-lived Compute Engine VM that builds images next to
+// their registry. Only the build context is uploaded to it, and the image is
+// pushed from it with datacenter bandwidth.
+type RemoteBuilder struct {
+	Project string
+	// Zone of the VM. It defaults to a zone in the region of the registry.
+	Zone string
+	// Instance is the name of the VM. It defaults to gcluster-builder-<user>.
+	Instance string
+}
+
+// BuildContainerImageOnRemoteVM builds and pushes the same image as
+// BuildContainerImageFromBaseImage, but composes and pushes it on a builder
+// VM. The VM is created when it does not exist and deletes itself after
+// remoteBuilderLifetime; the build context is synced to it with rsync, so
+// later builds only upload the files that changed. No further step is started
+// once ctx is done.
+func BuildContainerImageOnRemoteVM(
+	ctx context.Context,
+	builder RemoteBuilder,
+	repo ImageRepo,
+	baseImage string,
+	scriptDir string,
+	destPath string,
+	platformStr string,
+	ignoreMatcher *IgnoreMatcher,
+	cfg ImageConfig,
+	tag ImageTag,
+) (string, error) {
+	platform, err := parsePlatform(platformStr)
+	if err != nil {
+		return "", err
+	}
+	baseRef, err := name.ParseReference(baseImage)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base image reference %q: %w", baseImage, err)
+	}
+
+	buildCtx, err := scanBuildContext(scriptDir, ignoreMatcher)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build context: %w", err)
+	}
+	if destPath != "" {
+		buildCtx.placeUnder(destPath)
+	}
+	baseDigest, err := registry.Digest(baseRef.String(), crane.WithContext(ctx), crane.WithPlatform(&platform))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve base image digest for %q: %w", baseImage, err)
+	}
+	key := buildCtx.cacheKey(baseDigest, platformStr, cfg)
+	imageName, err := CachedImageName(repo, key)
+	if err != nil {
+		return "", err
+	}
+	tagStr, err := tag.resolve(scriptDir, key)
+	if err != nil {
+		return "", err
+	}
+	if _, err := registry.Head(imageName, crane.WithContext(ctx)); err == nil {
+		logging.Info("Build context unchanged, reusing cached image %s", imageName)
+		return tagAndPin(ctx, imageName, tagStr)
+	}
+
+	if _, err := lookPath("rsync"); err != nil {
+		return "", fmt.Errorf("the remote build backend uploads the build context with rsync, which was not found on the PATH: %w", err)
+	}
+	if builder, err = builder.withDefaults(repo); err != nil {
+		return "", err
+	}
+	if err := builder.ensureRunning(); err != nil {
+		return "", err
+	}
+	if err := builder.waitUntilReady(ctx); err != nil {
+		return "", err
+	}
+
+	remoteDir := remoteContextDir(scriptDir)
+	logging.Info("Syncing build context to builder VM %s...", builder.Instance)
+	if err := builder.syncContext(buildCtx, scriptDir, remoteDir); err != nil {
+		return "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("image build on builder VM %s canceled: %w", builder.Instance, err)
+	}
+
+	logging.Info("Building and uploading container image %s on builder VM %s...", imageName, builder.Instance)
+	mutateArgs := cfg.withProvenance(scriptDir).mutateArgs(buildCtx.workDir())
+	script := remoteBuildScript(remoteDir, buildCtx.destDir, baseRef.Context().Digest(baseDigest).String(), platformStr, imageName, mutateArgs)
+	if res := builder.ssh(script); res.ExitCode != 0 {
+		return "", fmt.Errorf("image build on builder VM %s failed: %s\n%s", builder.Instance, res.Stderr, res.Stdout)
+	}
+
+	logging.Info("Image %s built and uploaded successfully.", imageName)
+	return tagAndPin(ctx, imageName, tagStr)
+}
+
+func (b RemoteBuilder) withDefaults(repo ImageRepo) (RemoteBuilder, error) {
+	if b.Project == "" {
+		b.Project = repo.Project
+	}
+	if b.Instance == "" {
+		user, err := currentUser()
+		if err != nil {
+			return b, err
+		}
+		b.Instance = "gcluster-builder-" + instanceNameSuffix(user)
+	}
+	if b.Zone != "" {
+		return b, nil
+	}
+	region := repo.Location
+	if r, ok := multiRegionBuilderRegions[region]; ok {
+		region = r
+	}
+	res := shell.ExecuteCommand("gcloud", "compute", "zones", "list",
+		"--project", b.Project,
+		"--filter", fmt.Sprintf("region:%s AND status:UP", region),
+		"--format", "value(name)",
+		"--sort-by", "name",
+		"--limit", "1")
+	b.Zone = strings.TrimSpace(res.Stdout)
+	if res.ExitCode != 0 || b.Zone == "" {
+		return b, fmt.Errorf("failed to find a zone in %s for the builder VM, set one with --builder-zone: %s", region, res.Stderr)
+	}
+	return b, nil
+}
+
+var invalidInstanceChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// instanceNameSuffix turns a user name into characters allowed in the name of
+// a VM.
+func instanceNameSuffix(user string) string {
+	s := strings.Trim(invalidInstanceChars.ReplaceAllString(strings.ToLower(user), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	if s == "" {
+		s = "user"
+	}
+	return s
+}
+
+// ensureRunning creates the builder VM, or starts it when it is stopped.
+func (b RemoteBuilder) ensureRunning() error {
+	res := shell.ExecuteCommand("gcloud", "compute", "instances", "describe", b.Instance,
+		"--zone", b.Zone, "--project", b.Project, "--format", "value(status)")
+	if res.ExitCode == 0 {
+		switch status := strings.TrimSpace(res.Stdout); status {
+		case "TERMINATED", "STOPPED":
+			logging.Info("Starting builder VM %s...", b.Instance)
+			if res := shell.ExecuteCommand("gcloud", "compute", "instances", "start", b.Instance,
+				"--zone", b.Zone, "--project", b.Project, "--quiet"); res.ExitCode != 0 {
+				return fmt.Errorf("failed to start builder VM %s: %s", b.Instance, res.Stderr)
+			}
+		default:
+			logging.Info("Reusing builder VM %s (%s).", b.Instance, strings.ToLower(status))
+		}
+		return nil
+	}
+	if !strings.Contains(res.Stderr, "was not found") {
+		return fmt.Errorf("failed to look up builder VM %s: %s", b.Instance, res.Stderr)
+	}
+
+	dir, err := os.MkdirTemp("", "gcluster-builder-")
+	if err != nil {
+		return fmt.Errorf("failed to create builder VM directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	startupScript := filepath.Join(dir, "startup.sh")
+	if err := os.WriteFile(startupScript, []byte(remoteBuilderStartupScript), 0644); err != nil {
+		return fmt.Errorf("failed to write builder VM startup script: %w", err)
+	}
+
+	logging.Info("Creating builder VM %s in %s; it deletes itself after %s.", b.Instance, b.Zone, remoteBuilderLifetime)
+	res = shell.ExecuteCommand("gcloud", "compute", "instances", "create", b.Instance,
+		"--zone", b.Zone,
+		"--project", b.Project,
+		"--machine-type", remoteBuilderMachineType,
+		"--image-family", "debian-12",
+		"--image-project", "debian-cloud",
+		"--boot-disk-size", "100GB",
+		"--scopes", "cloud-platform",
+		"--labels", "gcluster-builder=true",
+		"--max-run-duration", remoteBuilderLifetime,
+		"--instance-termination-action", "DELETE",
+		"--metadata-from-file", "startup-script="+startupScript,
+		"--quiet")
+	if res.ExitCode != 0 {
+		return fmt.Errorf("failed to create builder VM %s: %s", b.Instance, res.Stderr)
+	}
+	return nil
+}
+
+// waitUntilReady waits until the startup script of the builder VM installed
+// its tools. SSH fails while the VM boots, so failures are retried too, until
+// ctx is done.
+func (b RemoteBuilder) waitUntilReady(ctx context.Context) error {
+	deadline := clock.Now().Add(remoteBuilderStartTimeout)
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("stopped waiting for builder VM %s: %w", b.Instance, err)
+		}
+		res := b.ssh("test -f " + remoteBuilderReadyFile)
+		if res.ExitCode == 0 {
+			return nil
+		}
+		if clock.Now().After(deadline) {
+			return fmt.Errorf("builder VM %s was not ready after %s: %s", b.Instance, remoteBuilderStartTimeout, res.Stderr)
+		}
+		logging.Debug("Waiting for builder VM %s to be ready...", b.Instance)
+		clock.Sleep(remoteBuilderPollInterval)
+	}
+}
+
+func (b RemoteBuilder) ssh(command string) shell.CommandResult {
+	return shell.ExecuteCommand("gcloud", "compute", "ssh", b.Instance,
+		"--zone", b.Zone, "--project", b.Project, "--quiet", "--command", command)
+}
+
+// syncContext uploads the files of buildCtx to remoteDir on the builder VM.
+// Files that are unchanged since the last build are skipped, and files that
+// are no longer in the build context are deleted.
+func (b RemoteBuilder) syncContext(buildCtx *buildContext, scriptDir, remoteDir string) error {
+	rules, err := rsyncFilterRules(buildCtx)
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "gcluster-rsync-")
+	if err != nil {
+		return fmt.Errorf("failed to create rsync directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filterFile := filepath.Join(dir, "filter")
+	if err := os.WriteFile(filterFile, []byte(rules), 0644); err != nil {
+		return fmt.Errorf("failed to write rsync filter: %w", err)
+	}
+	// rsync runs "<shell> <host> <command>..."; gcloud takes the command as
+	// a flag instead.
+	rsh := filepath.Join(dir, "rsh")
+	wrapper := fmt.Sprintf("#!/bin/sh\nhost=\"$1\"\nshift\nexec gcloud compute ssh \"$host\" --zone %s --project %s --quiet --command \"$*\"\n", shellQuote(b.Zone), shellQuote(b.Project))
+	if err := os.WriteFile(rsh, []byte(wrapper), 0755); err != nil {
+		return fmt.Errorf("failed to write rsync remote shell: %w", err)
+	}
+
+	res := shell.ExecuteCommand("rsync", "-rlptz", "--delete", "--delete-excluded",
+		"--filter", "merge "+filterFile,
+		"-e", rsh,
+		strings.TrimSuffix(scriptDir, string(filepath.Separator))+string(filepath.Separator),
+		b.Instance+":"+remoteDir+"/")
+	if res.ExitCode != 0 {
+		return fmt.Errorf("failed to sync the build context to builder VM %s: %s", b.Instance, res.Stderr)
+	}
+	return nil
+}
+
+// rsyncFilterRules returns rsync filter rules that transfer exactly the
+// entries of buildCtx. The directories of included files are included even
+// when they are ignored themselves, so that rsync descends into them.
+func rsyncFilterRules(buildCtx *buildContext) (string, error) {
+	included := map[string]bool{}
+	for _, e := range buildCtx.entries {
+		if strings.ContainsAny(e.header.Name, "\n\r") {
+			return "", fmt.Errorf("cannot sync %q to the builder VM: its name contains a line break", e.header.Name)
+		}
+		rule := "/" + e.header.Name
+		if e.header.Typeflag == tar.TypeDir {
+			rule += "/"
+		}
+		included[rule] = true
+		for dir := path.Dir(e.header.Name); dir != "."; dir = path.Dir(dir) {
+			included["/"+dir+"/"] = true
+		}
+	}
+	rules := make([]string, 0, len(included))
+	for rule := range included {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	var b strings.Builder
+	for _, rule := range rules {
+		b.WriteString("+ " + escapeRsyncPattern(rule) + "\n")
+	}
+	b.WriteString("- *\n")
+	return b.String(), nil
+}
+
+// escapeRsyncPattern escapes the wildcards of a file name. rsync only treats
+// backslashes as escapes in patterns that contain a wildcard.
+func escapeRsyncPattern(p string) string {
+	if !strings.ContainsAny(p, "*?[") {
+		return p
+	}
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(p)
+}
+
+// remoteContextDir is the directory, relative to the home directory on the
+// builder VM, that the build context in scriptDir is synced to. Each build
+// context gets its own directory, so that switching between projects does
+// not upload them again.
+func remoteContextDir(scriptDir string) string {
+	abs, err := filepath.Abs(scriptDir)
+	if err != nil {
+		abs = scriptDir
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return "gcluster-build-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// remoteBuildScript packs the synced build context into a layer with the same
+// paths as a local build, owned by root, and appends it to the base image.
+// With a destDir, the layer places the build context under it. With
+// mutateArgs, the image is only tagged imageName once gcrane mutate has set
+// its config, so that an interrupted build is not mistaken for a cached image.
+func remoteBuildScript(remoteDir, destDir, baseImage, platformStr, imageName string, mutateArgs []string) string {
+	layer := remoteDir + ".tar"
+	tarFlags := "--null --no-recursion -T - --owner=0 --group=0 --numeric-owner"
+	appendTag := imageName
+	if destDir != "" {
+		tarFlags += " --transform " + shellQuote("s,^,"+destDir+"/,")
+	}
+	if len(mutateArgs) > 0 {
+		appendTag = imageName + "-layer"
+	}
+	lines := []string{
+		"set -euo pipefail",
+		"cd " + shellQuote(remoteDir),
+		"find . -mindepth 1 -printf '%P\\0' | LC_ALL=C sort -z | tar " + tarFlags + " -cf ../" + shellQuote(layer),
+		fmt.Sprintf("gcrane append --platform %s -b %s -f ../%s -t %s", shellQuote(platformStr), shellQuote(baseImage), shellQuote(layer), shellQuote(appendTag)),
+	}
+	if len(mutateArgs) > 0 {
+		quoted := make([]string, len(mutateArgs))
+		for i, a := range mutateArgs {
+			quoted[i] = shellQuote(a)
+		}
+		lines = append(lines, fmt.Sprintf("gcrane mutate %s %s -t %s", shellQuote(appendTag), strings.Join(quoted, " "), shellQuote(imageName)))
+	}
+	return strings.Join(append(lines, "rm -f ../"+shellQuote(layer)), "\n")
+}
+
+// shellQuote quotes s as a single word for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/shell"
+	"hpc-toolkit/pkg/testutil"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+)
+
+// stubRemoteBuild installs an in-memory registry holding the base image, a
+// fake clock and fake local tools for a remote build, and returns the commands
+// run through run. The image of a build script that succeeds is added to the
+// registry, like gcrane pushes it.
+func stubRemoteBuild(t *testing.T, run func(name string, args ...string) shell.CommandResult) *[]string {
+	t.Helper()
+	t.Setenv("USER", "Test.User")
+	origExec, origLookPath := shell.ExecuteCommand, lookPath
+	t.Cleanup(func() { shell.ExecuteCommand, lookPath = origExec, origLookPath })
+
+	reg := fakeRegistry(t)
+	if err := reg.AddImage("ubuntu", empty.Image); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(SetClock(testutil.NewFakeClock(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))))
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+	var commands []string
+	shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+		commands = append(commands, name+" "+strings.Join(args[:min(len(args), 3)], " "))
+		res := run(name, args...)
+		if pushed := scriptImage.FindAllStringSubmatch(args[len(args)-1], -1); res.ExitCode == 0 && len(pushed) > 0 {
+			if err := reg.AddImage(pushed[len(pushed)-1][1], empty.Image); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return res
+	}
+	return &commands
+}
+
+// scriptImage matches the images a build script tags.
+var scriptImage = regexp.MustCompile(`gcrane .* -t '([^']+)'`)
+
+func TestBuildContainerImageOnRemoteVM(t *testing.T) {
+	var filter, script string
+	sshAttempts := 0
+	commands := stubRemoteBuild(t, func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "gcloud compute zones list"):
+			if !strings.Contains(cmd, "region:us-central1 AND status:UP") {
+				t.Errorf("expected a zone of the registry's region, got %s", cmd)
+			}
+			return shell.CommandResult{Stdout: "us-central1-a\n"}
+		case strings.HasPrefix(cmd, "gcloud compute instances describe"):
+			return shell.CommandResult{ExitCode: 1, Stderr: "The resource 'projects/p/zones/us-central1-a/instances/gcluster-builder-test-user' was not found"}
+		case strings.HasPrefix(cmd, "gcloud compute instances create"):
+			for _, want := range []string{"--max-run-duration 2h", "--instance-termination-action DELETE", "--scopes cloud-platform"} {
+				if !strings.Contains(cmd, want) {
+					t.Errorf("expected %q in %s", want, cmd)
+				}
+			}
+			return shell.CommandResult{}
+		case strings.HasPrefix(cmd, "gcloud compute ssh gcluster-builder-test-user"):
+			command := args[len(args)-1]
+			if strings.HasPrefix(command, "test -f") {
+				if sshAttempts++; sshAttempts == 1 {
+					return shell.CommandResult{ExitCode: 255, Stderr: "Connection refused"}
+				}
+				return shell.CommandResult{}
+			}
+			script = command
+			return shell.CommandResult{}
+		case name == "rsync":
+			for i, a := range args {
+				if a == "--filter" {
+					data, _ := os.ReadFile(strings.TrimPrefix(args[i+1], "merge "))
+					filter = string(data)
+				}
+			}
+			if dest := args[len(args)-1]; !strings.HasPrefix(dest, "gcluster-builder-test-user:gcluster-build-") {
+				t.Errorf("unexpected rsync destination %s", dest)
+			}
+			return shell.CommandResult{}
+		}
+		t.Fatalf("unexpected command: %s", cmd)
+		return shell.CommandResult{}
+	})
+
+	dir := t.TempDir()
+	createTestFiles(t, dir)
+	matcher, _ := NewIgnoreMatcher([]string{"*.log"})
+	got, err := BuildContainerImageOnRemoteVM(context.Background(), RemoteBuilder{}, ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}, "ubuntu", dir, "", "linux/arm64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageOnRemoteVM() error = %v", err)
+	}
+	if !strings.HasPrefix(got, "us-central1-docker.pkg.dev/p/gcluster/test.user-runner:ctx-") {
+		t.Errorf("expected the content-addressed image of a local build, got %s", got)
+	}
+	image, digest, _ := strings.Cut(got, "@")
+	if !strings.HasPrefix(digest, "sha256:") {
+		t.Errorf("expected %s to be pinned to its digest", got)
+	}
+
+	want := []string{
+		"gcloud compute zones list",
+		"gcloud compute instances describe",
+		"gcloud compute instances create",
+		"gcloud compute ssh gcluster-builder-test-user",
+		"gcloud compute ssh gcluster-builder-test-user",
+		"rsync -rlptz --delete --delete-excluded",
+		"gcloud compute ssh gcluster-builder-test-user",
+	}
+	if strings.Join(*commands, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands =\n%s\nwant\n%s", strings.Join(*commands, "\n"), strings.Join(want, "\n"))
+	}
+	if want := "+ /foo.txt\n+ /sub/\n+ /sub/baz.txt\n- *\n"; filter != want {
+		t.Errorf("rsync filter =\n%s\nwant\n%s", filter, want)
+	}
+	base, _ := empty.Image.Digest()
+	if want := "gcrane append --platform 'linux/arm64' -b 'index.docker.io/library/ubuntu@" + base.String() + "'"; !strings.Contains(script, want) {
+		t.Errorf("expected %q in build script:\n%s", want, script)
+	}
+	if !strings.Contains(script, "-t '"+image+"'") {
+		t.Errorf("expected the build script to push %s:\n%s", image, script)
+	}
+	if !strings.Contains(script, "'--label' '"+LabelCreated+"=2026-06-01T00:00:") {
+		t.Errorf("expected the build time label in the build script:\n%s", script)
+	}
+}
+
+func TestBuildContainerImageOnRemoteVM_ReusesRunningVM(t *testing.T) {
+	commands := stubRemoteBuild(t, func(name string, args ...string) shell.CommandResult {
+		if name == "gcloud" && args[1] == "instances" && args[2] == "describe" {
+			return shell.CommandResult{Stdout: "RUNNING\n"}
+		}
+		return shell.CommandResult{}
+	})
+
+	dir := t.TempDir()
+	createTestFiles(t, dir)
+	matcher, _ := NewIgnoreMatcher(nil)
+	if _, err := BuildContainerImageOnRemoteVM(context.Background(), RemoteBuilder{Zone: "europe-west4-b", Instance: "shared-builder"}, ImageRepo{Location: "europe", Project: "p", Repository: "gcluster"}, "ubuntu", dir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{}); err != nil {
+		t.Fatalf("BuildContainerImageOnRemoteVM() error = %v", err)
+	}
+	for _, cmd := range *commands {
+		if strings.Contains(cmd, "create") || strings.Contains(cmd, "zones list") {
+			t.Errorf("expected the running VM in the given zone to be reused, ran %s", cmd)
+		}
+	}
+}
+
+func TestBuildContainerImageOnRemoteVM_CacheHit(t *testing.T) {
+	commands := stubRemoteBuild(t, func(name string, args ...string) shell.CommandResult {
+		t.Fatalf("unexpected command: %s %v", name, args)
+		return shell.CommandResult{}
+	})
+	dir := t.TempDir()
+	createTestFiles(t, dir)
+	matcher, _ := NewIgnoreMatcher(nil)
+	repo := ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}
+	// Images built locally and remotely share the cache.
+	cached, err := BuildContainerImageFromBaseImage(context.Background(), repo, "ubuntu", dir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageFromBaseImage() error = %v", err)
+	}
+	got, err := BuildContainerImageOnRemoteVM(context.Background(), RemoteBuilder{}, repo, "ubuntu", dir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if err != nil {
+		t.Fatalf("BuildContainerImageOnRemoteVM() error = %v", err)
+	}
+	if got != cached {
+		t.Errorf("expected the cached image %s, got %s", cached, got)
+	}
+	if len(*commands) != 0 {
+		t.Errorf("expected no builder VM for a cached image, ran %v", *commands)
+	}
+}
+
+func TestRemoteBuildScript_Workdir(t *testing.T) {
+	script := remoteBuildScript("gcluster-build-1", "app", "ubuntu@sha256:abc", "linux/amd64", "r/img:ctx-1", ImageConfig{}.mutateArgs("/app"))
+	for _, want := range []string{
+		"--transform 's,^,app/,'",
+		"gcrane append --platform 'linux/amd64' -b 'ubuntu@sha256:abc' -f ../'gcluster-build-1.tar' -t 'r/img:ctx-1-layer'",
+		"gcrane mutate 'r/img:ctx-1-layer' '--workdir' '/app' -t 'r/img:ctx-1'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected %q in build script:\n%s", want, script)
+		}
+	}
+	if script := remoteBuildScript("gcluster-build-1", "", "ubuntu@sha256:abc", "linux/amd64", "r/img:ctx-1", nil); strings.Contains(script, "mutate") || strings.Contains(script, "--transform") {
+		t.Errorf("expected the build context at the image root without a workdir:\n%s", script)
+	}
+}
+
+func TestRemoteBuildScript_ImageConfig(t *testing.T) {
+	cfg := ImageConfig{
+		Env:        map[string]string{"MODE": "train"},
+		Labels:     map[string]string{"team": "ml,infra"},
+		User:       "1000",
+		Entrypoint: []string{"python", "-c", "print(1, 2)"},
+	}
+	script := remoteBuildScript("gcluster-build-1", "", "ubuntu@sha256:abc", "linux/amd64", "r/img:ctx-1", cfg.mutateArgs(""))
+	want := `gcrane mutate 'r/img:ctx-1-layer' '--env' 'MODE=train' '--label' '"team=ml,infra"' '--user' '1000' '--entrypoint' 'python,-c,"print(1, 2)"' -t 'r/img:ctx-1'`
+	if !strings.Contains(script, want) {
+		t.Errorf("expected %q in build script:\n%s", want, script)
+	}
+	if strings.Contains(script, "--transform") {
+		t.Errorf("expected the build context at the image root:\n%s", script)
+	}
+}
+
+func TestRsyncFilterRules(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"logs/keep.log", "logs/drop.log", "data[1]/*.csv"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	matcher, err := NewIgnoreMatcher([]string{"logs", "!logs/keep.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc, err := scanBuildContext(dir, matcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := rsyncFilterRules(bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "+ /data\\[1]/\n+ /data\\[1]/\\*.csv\n+ /logs/\n+ /logs/keep.log\n- *\n"
+	if got != want {
+		t.Errorf("rsyncFilterRules() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuildContainerImageOnRemoteVM_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	commands := stubRemoteBuild(t, func(name string, args ...string) shell.CommandResult {
+		if name == "gcloud" && args[1] == "instances" && args[2] == "describe" {
+			return shell.CommandResult{Stdout: "RUNNING\n"}
+		}
+		if name == "rsync" {
+			cancel()
+		}
+		return shell.CommandResult{}
+	})
+
+	dir := t.TempDir()
+	createTestFiles(t, dir)
+	matcher, _ := NewIgnoreMatcher(nil)
+	_, err := BuildContainerImageOnRemoteVM(ctx, RemoteBuilder{Zone: "us-central1-a"}, ImageRepo{Location: "us-central1", Project: "p", Repository: "gcluster"}, "ubuntu", dir, "", "linux/amd64", matcher, ImageConfig{}, ImageTag{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("BuildContainerImageOnRemoteVM() error = %v, want a cancellation", err)
+	}
+	if last := (*commands)[len(*commands)-1]; !strings.HasPrefix(last, "rsync") {
+		t.Errorf("expected no build after the cancellation, last command was %s", last)
+	}
+}
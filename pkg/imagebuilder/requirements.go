@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+)
+
+// dependencyImageRepo is the Artifact Registry image that holds the cached
+// dependency layers, one tag per requirements hash.
+const dependencyImageRepo = "gcluster-deps"
+
+// RequirementsKind identifies the package manager used to install a requirements file.
+type RequirementsKind string
+
+const (
+	PipRequirements   RequirementsKind = "pip"
+	CondaRequirements RequirementsKind = "conda"
+)
+
+// DetectRequirementsKind infers the package manager from the file name:
+// environment.yml/.yaml files are treated as conda environments, anything else
+// as a pip requirements file.
+func DetectRequirementsKind(path string) RequirementsKind {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return CondaRequirements
+	default:
+		return PipRequirements
+	}
+}
+
+// RequirementsHash returns the cache key of the dependency layer built from
+// requirementsPath on top of baseImage for the given platform. baseImage is
+// hashed as given, so it should be pinned to a digest for the key to change
+// when its tag moves.
+func RequirementsHash(baseImage, platformStr, requirementsPath string) (string, error) {
+	content, err := os.ReadFile(requirementsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read requirements file %q: %w", requirementsPath, err)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", baseImage, platformStr, DetectRequirementsKind(requirementsPath))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// DependencyImageName returns the Artifact Registry reference of the cached
+// dependency image for the given requirements hash.
+func DependencyImageName(repo ImageRepo, hash string) string {
+	return fmt.Sprintf("%s/%s:%s", repo, dependencyImageRepo, hash)
+}
+
+// EnsureDependencyImage returns an image that contains baseImage plus the
+// dependencies listed in requirementsPath. The image is tagged with the hash of
+// its inputs, so it is only built with Cloud Build the first time a given
+// requirements file is used on a given base image; later submissions reuse the
+// pushed image. baseImage is resolved to its digest for the platform, which
+// the image is built on, so a base image tag that moves yields a new image.
+// Cloud Build runs in project, in the region of location. The build is not
+// started once ctx is done.
+func EnsureDependencyImage(ctx context.Context, project, location string, repo ImageRepo, baseImage, requirementsPath, platformStr string) (string, error) {
+	platform, err := parsePlatform(platformStr)
+	if err != nil {
+		return "", err
+	}
+	baseDigest, err := registry.Digest(baseImage, crane.WithContext(ctx), crane.WithPlatform(&platform))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve base image digest for %q: %w", baseImage, err)
+	}
+	if baseImage, err = PinnedBaseImage(baseImage, baseDigest); err != nil {
+		return "", err
+	}
+	hash, err := RequirementsHash(baseImage, platformStr, requirementsPath)
+	if err != nil {
+		return "", err
+	}
+	depImage := DependencyImageName(repo, hash)
+
+	if _, err := registry.Digest(depImage, crane.WithContext(ctx)); err == nil {
+		logging.Info("Reusing cached dependency image %s", depImage)
+		return depImage, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("dependency image build canceled: %w", err)
+	}
+
+	logging.Info("Building dependency image %s from %s with Cloud Build...", depImage, requirementsPath)
+	buildDir, err := os.MkdirTemp("", "gcluster-deps-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create dependency build directory: %w", err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	if err := writeDependencyBuildFiles(buildDir, baseImage, requirementsPath); err != nil {
+		return "", err
+	}
+
+	res, err := SubmitCloudBuild(ctx, project, location, buildDir, dockerBuildSteps(depImage, "Dockerfile", platformStr), []string{depImage})
+	if err != nil {
+		return "", err
+	}
+
+	logging.Info("Dependency image %s (%s) built and uploaded successfully by Cloud Build %s.", depImage, res.ImageDigests[depImage], res.BuildID)
+	return depImage, nil
+}
+
+// writeDependencyBuildFiles writes the Dockerfile and requirements file that
+// produce the dependency image into dir.
+func writeDependencyBuildFiles(dir, baseImage, requirementsPath string) error {
+	content, err := os.ReadFile(requirementsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read requirements file %q: %w", requirementsPath, err)
+	}
+	reqName := filepath.Base(requirementsPath)
+	if err := os.WriteFile(filepath.Join(dir, reqName), content, 0644); err != nil {
+		return fmt.Errorf("failed to stage requirements file: %w", err)
+	}
+
+	dockerfile := dependencyDockerfile(baseImage, reqName, DetectRequirementsKind(requirementsPath))
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		return fmt.Errorf("failed to write Dockerfile: %w", err)
+	}
+	return nil
+}
+
+// DependencySpecDir is the directory of a dependency image that keeps the
+// requirements file its dependencies were installed from.
+const DependencySpecDir = "/opt/gcluster"
+
+func dependencyDockerfile(baseImage, reqName string, kind RequirementsKind) string {
+	target := DependencySpecDir + "/" + reqName
+	install := fmt.Sprintf("pip install --no-cache-dir -r %s", target)
+	if kind == CondaRequirements {
+		install = fmt.Sprintf("conda env update --name base --file %s && conda clean --all --yes", target)
+	}
+	return fmt.Sprintf("FROM %s\nCOPY %s %s\nRUN %s\n", baseImage, reqName, target, install)
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuilder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/shell"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+func writeRequirements(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDetectRequirementsKind(t *testing.T) {
+	for path, want := range map[string]RequirementsKind{
+		"requirements.txt":       PipRequirements,
+		"deps/requirements.in":   PipRequirements,
+		"environment.yml":        CondaRequirements,
+		"conda/environment.YAML": CondaRequirements,
+	} {
+		if got := DetectRequirementsKind(path); got != want {
+			t.Errorf("DetectRequirementsKind(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRequirementsHash(t *testing.T) {
+	req := writeRequirements(t, "requirements.txt", "numpy==2.0.0\n")
+
+	h1, err := RequirementsHash("python:3.11", "linux/amd64", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h1) != 16 {
+		t.Errorf("expected 16 character hash, got %q", h1)
+	}
+	if h2, _ := RequirementsHash("python:3.11", "linux/amd64", req); h2 != h1 {
+		t.Errorf("hash is not stable: %q != %q", h1, h2)
+	}
+	if h3, _ := RequirementsHash("python:3.12", "linux/amd64", req); h3 == h1 {
+		t.Error("expected base image to change the hash")
+	}
+	if h4, _ := RequirementsHash("python:3.11", "linux/arm64", req); h4 == h1 {
+		t.Error("expected platform to change the hash")
+	}
+	if err := os.WriteFile(req, []byte("numpy==2.1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if h5, _ := RequirementsHash("python:3.11", "linux/amd64", req); h5 == h1 {
+		t.Error("expected requirements content to change the hash")
+	}
+}
+
+func TestEnsureDependencyImage(t *testing.T) {
+	repo := ImageRepo{Location: "us-central1", Project: "proj", Repository: "my-repo"}
+	req := writeRequirements(t, "requirements.txt", "torch\n")
+
+	origExec := shell.ExecuteCommand
+	defer func() { shell.ExecuteCommand = origExec }()
+
+	t.Run("cache hit skips build", func(t *testing.T) {
+		reg := fakeRegistry(t)
+		if err := reg.AddImage("python:3.11", empty.Image); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := RequirementsHash(pinnedEmptyImage(t, "python"), "linux/amd64", req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.AddImage(DependencyImageName(repo, hash), empty.Image); err != nil {
+			t.Fatal(err)
+		}
+		shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+			t.Fatalf("unexpected command: %s %v", name, args)
+			return shell.CommandResult{}
+		}
+
+		img, err := EnsureDependencyImage(context.Background(), "proj", "us-central1-a", repo, "python:3.11", req, "linux/amd64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(img, "us-central1-docker.pkg.dev/proj/my-repo/gcluster-deps:") {
+			t.Errorf("unexpected dependency image %q", img)
+		}
+	})
+
+	t.Run("cache miss builds with cloud build", func(t *testing.T) {
+		if err := fakeRegistry(t).AddImage("python:3.11", empty.Image); err != nil {
+			t.Fatal(err)
+		}
+		f := newFakeCloudBuild(t, "SUCCESS")
+		captureInfoLog(t)
+
+		img, err := EnsureDependencyImage(context.Background(), "proj", "us-central1", repo, "python:3.11", req, "linux/arm64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dockerfile := f.source["Dockerfile"]
+		for _, want := range []string{"FROM " + pinnedEmptyImage(t, "python"), "COPY requirements.txt /opt/gcluster/requirements.txt", "pip install --no-cache-dir -r /opt/gcluster/requirements.txt"} {
+			if !strings.Contains(dockerfile, want) {
+				t.Errorf("Dockerfile missing %q:\n%s", want, dockerfile)
+			}
+		}
+		if f.source["requirements.txt"] != "torch\n" {
+			t.Errorf("expected the requirements file to be uploaded, got %v", f.source)
+		}
+		var args []string
+		for _, step := range f.build.Steps {
+			args = append(args, strings.Join(step.Args, " "))
+		}
+		for _, want := range []string{"tonistiigi/binfmt", "--platform linux/arm64", "-t " + img} {
+			if !strings.Contains(strings.Join(args, "\n"), want) {
+				t.Errorf("build steps missing %q:\n%s", want, strings.Join(args, "\n"))
+			}
+		}
+		if len(f.build.Images) != 1 || f.build.Images[0] != img {
+			t.Errorf("expected Cloud Build to push %s, got %v", img, f.build.Images)
+		}
+	})
+
+	t.Run("base tag moved", func(t *testing.T) {
+		reg := fakeRegistry(t)
+		if err := reg.AddImage("python:3.11", empty.Image); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := RequirementsHash(pinnedEmptyImage(t, "python"), "linux/amd64", req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.AddImage(DependencyImageName(repo, hash), empty.Image); err != nil {
+			t.Fatal(err)
+		}
+		moved, err := mutate.Config(empty.Image, v1.Config{Env: []string{"PYTHON_VERSION=3.11.9"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reg.AddImage("python:3.11", moved); err != nil {
+			t.Fatal(err)
+		}
+		f := newFakeCloudBuild(t, "SUCCESS")
+		captureInfoLog(t)
+
+		img, err := EnsureDependencyImage(context.Background(), "proj", "us-central1", repo, "python:3.11", req, "linux/amd64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img == DependencyImageName(repo, hash) || len(f.build.Images) != 1 {
+			t.Errorf("expected the dependency image to be rebuilt on the new base image, got %s and builds of %v", img, f.build.Images)
+		}
+	})
+
+	t.Run("cloud build failure", func(t *testing.T) {
+		if err := fakeRegistry(t).AddImage("python:3.11", empty.Image); err != nil {
+			t.Fatal(err)
+		}
+		newFakeCloudBuild(t, "FAILURE")
+		captureInfoLog(t)
+		if _, err := EnsureDependencyImage(context.Background(), "proj", "us-central1", repo, "python:3.11", req, "linux/amd64"); err == nil || !strings.Contains(err.Error(), "failed with status FAILURE") {
+			t.Fatalf("expected cloud build error, got %v", err)
+		}
+	})
+}
+
+func TestDependencyDockerfile_Conda(t *testing.T) {
+	df := dependencyDockerfile("continuumio/miniconda3", "environment.yml", CondaRequirements)
+	if !strings.Contains(df, "conda env update --name base --file /opt/gcluster/environment.yml") {
+		t.Errorf("unexpected conda Dockerfile:\n%s", df)
+	}
+}
+
+// pinnedEmptyImage returns the Docker Hub image repository pinned to the
+// digest of the empty image.
+func pinnedEmptyImage(t *testing.T, repository string) string {
+	t.Helper()
+	d, err := empty.Image.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned, err := PinnedBaseImage(repository, d.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pinned
+}
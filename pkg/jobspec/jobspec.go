@@ -0,0 +1,394 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobspec loads declarative job spec files for gcluster job submit.
+// Each field of a spec stands for a submit flag, so a spec is turned into a
+// JobDefinition the same way the flags are, and flags given on the command
+// line override it.
+package jobspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the spec schema this package reads.
+const CurrentVersion = 1
+
+// Spec is a job spec file. Unset fields leave their flags at the defaults.
+type Spec struct {
+	// Version of the spec schema. Defaults to CurrentVersion.
+	Version int `yaml:"version"`
+
+	Name     string `yaml:"name"`
+	Cluster  string `yaml:"cluster"`
+	Location string `yaml:"location"`
+	Project  string `yaml:"project"`
+
+	// NameTemplate renders the name when Name is not set.
+	NameTemplate string `yaml:"name_template"`
+
+	Image        string `yaml:"image"`
+	BaseImage    string `yaml:"base_image"`
+	ImageRepo    string `yaml:"image_repo"`
+	BuildContext string `yaml:"build_context"`
+	WorkDir      string `yaml:"workdir"`
+	Dockerfile   string `yaml:"dockerfile"`
+	Requirements string `yaml:"requirements"`
+	CaptureEnv   string `yaml:"capture_env"`
+	Platform     string `yaml:"platform"`
+	BuildBackend string `yaml:"build_backend"`
+	BuilderZone  string `yaml:"builder_zone"`
+	// ImageLabels are set on the image built from BaseImage.
+	ImageLabels      map[string]string `yaml:"image_labels"`
+	ImageTag         string            `yaml:"image_tag"`
+	ImageTagStrategy string            `yaml:"image_tag_strategy"`
+
+	// Command is run by a shell, CommandArgs is run as is, and CommandScript
+	// is the path of a script to run. Only one of them may be set.
+	Command       string   `yaml:"command"`
+	CommandArgs   []string `yaml:"command_args"`
+	CommandScript string   `yaml:"command_script"`
+
+	ComputeType     string            `yaml:"compute_type"`
+	GPUMemory       string            `yaml:"gpu_memory"`
+	NumNodes        int               `yaml:"num_nodes"`
+	NumSlices       int               `yaml:"num_slices"`
+	CPU             string            `yaml:"cpu"`
+	Memory          string            `yaml:"memory"`
+	CPURequest      string            `yaml:"cpu_request"`
+	MemoryRequest   string            `yaml:"memory_request"`
+	GPUsPerPod      int               `yaml:"gpus_per_pod"`
+	Topology        string            `yaml:"topology"`
+	PlacementPolicy string            `yaml:"placement_policy"`
+	NodeSelectors   []string          `yaml:"node_selectors"`
+	Queue           string            `yaml:"queue"`
+	Priority        string            `yaml:"priority"`
+	Restarts        *int              `yaml:"restarts"`
+	Timeout         string            `yaml:"timeout"`
+	StartupProbe    string            `yaml:"startup_probe"`
+	StartupTimeout  string            `yaml:"startup_timeout"`
+	GracePeriod     string            `yaml:"grace_period"`
+	StepTimeouts    StepTimeouts      `yaml:"step_timeouts"`
+	ServiceAccount  string            `yaml:"service_account"`
+	SignKey         string            `yaml:"sign_key"`
+	Team            string            `yaml:"team"`
+	Experiment      string            `yaml:"experiment"`
+	Env             map[string]string `yaml:"env"`
+	SecretEnv       map[string]string `yaml:"secret_env"`
+	SecretEnvMode   string            `yaml:"secret_env_mode"`
+
+	Mounts          []string `yaml:"mounts"`
+	GCSBuckets      []string `yaml:"gcs_buckets"`
+	SecretMounts    []string `yaml:"secret_mounts"`
+	ConfigMapMounts []string `yaml:"configmap_mounts"`
+	StageIn         []string `yaml:"stage_in"`
+	Inputs          []Input  `yaml:"inputs"`
+	// Retention limits what is kept of the outputs and checkpoints the job
+	// writes.
+	Retention []Retention `yaml:"retention"`
+}
+
+// StepTimeouts limit how long the steps of the submission may take, such as
+// "10m".
+type StepTimeouts struct {
+	Credentials string `yaml:"credentials"`
+	Build       string `yaml:"build"`
+	Apply       string `yaml:"apply"`
+}
+
+// Input declares a dataset the job reads, optionally pinned to an object
+// generation or MD5 hash. It is passed to the job in the Name environment
+// variable.
+type Input struct {
+	Name       string `yaml:"name"`
+	URI        string `yaml:"uri"`
+	Generation string `yaml:"generation"`
+	MD5        string `yaml:"md5"`
+}
+
+func (in Input) flag() string {
+	v := in.Name + "=" + in.URI
+	if in.Generation != "" {
+		v += ",generation=" + in.Generation
+	}
+	if in.MD5 != "" {
+		v += ",md5=" + in.MD5
+	}
+	return v
+}
+
+// Retention keeps the KeepLast newest files or directories under Path, and
+// deletes the others once they are older than ExpireAfter, such as "30d".
+type Retention struct {
+	Path        string `yaml:"path"`
+	KeepLast    int    `yaml:"keep_last"`
+	ExpireAfter string `yaml:"expire_after"`
+}
+
+func (r Retention) flag() string {
+	v := r.Path
+	if r.KeepLast != 0 {
+		v += ",keep-last=" + strconv.Itoa(r.KeepLast)
+	}
+	if r.ExpireAfter != "" {
+		v += ",expire-after=" + r.ExpireAfter
+	}
+	return v
+}
+
+// Flag is the value, or values for repeated flags, that a spec gives a flag.
+type Flag struct {
+	Name   string
+	Values []string
+}
+
+// Load reads and validates the spec at path. Unknown fields are rejected, and
+// relative build_context, dockerfile, requirements and capture_env virtualenv paths are
+// resolved against the directory of the spec. When base_image is set without a build_context, the
+// directory of the spec is the build context.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job spec: %w", err)
+	}
+	s, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job spec %s: %w", path, err)
+	}
+	s.applyDefaults(filepath.Dir(path))
+	return s, nil
+}
+
+func parse(data []byte) (*Spec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("the file is empty")
+		}
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Spec) validate() error {
+	if s.Version != 0 && s.Version != CurrentVersion {
+		return fmt.Errorf("unsupported version %d; this gcluster reads version %d", s.Version, CurrentVersion)
+	}
+	if s.Image != "" && s.BaseImage != "" {
+		return fmt.Errorf("image and base_image cannot be used together")
+	}
+	if s.Dockerfile != "" && (s.Image != "" || s.BaseImage != "") {
+		return fmt.Errorf("dockerfile cannot be used with image or base_image")
+	}
+	if s.Requirements != "" && s.CaptureEnv != "" {
+		return fmt.Errorf("requirements and capture_env cannot be used together")
+	}
+	if s.Command != "" && len(s.CommandArgs) > 0 {
+		return fmt.Errorf("command and command_args cannot be used together")
+	}
+	if s.CommandScript != "" && (s.Command != "" || len(s.CommandArgs) > 0) {
+		return fmt.Errorf("command_script cannot be used with command or command_args")
+	}
+	if s.NumNodes < 0 || s.NumSlices < 0 {
+		return fmt.Errorf("num_nodes and num_slices cannot be negative")
+	}
+	if s.GPUsPerPod < 0 {
+		return fmt.Errorf("gpus_per_pod cannot be negative")
+	}
+	if s.Restarts != nil && *s.Restarts < 0 {
+		return fmt.Errorf("restarts cannot be negative")
+	}
+	for k := range s.Env {
+		if k == "" || strings.Contains(k, "=") {
+			return fmt.Errorf("invalid env variable name %q", k)
+		}
+	}
+	for k := range s.SecretEnv {
+		if k == "" || strings.Contains(k, "=") {
+			return fmt.Errorf("invalid secret_env variable name %q", k)
+		}
+	}
+	for k := range s.ImageLabels {
+		if k == "" || strings.Contains(k, "=") {
+			return fmt.Errorf("invalid image_labels key %q", k)
+		}
+	}
+	for i, in := range s.Inputs {
+		if in.Name == "" || in.URI == "" {
+			return fmt.Errorf("input %d: name and uri are required", i+1)
+		}
+		if strings.ContainsAny(in.Generation+in.MD5, ",") {
+			return fmt.Errorf("input %s: generation and md5 cannot contain ','", in.Name)
+		}
+	}
+	for i, r := range s.Retention {
+		if r.Path == "" || (r.KeepLast == 0 && r.ExpireAfter == "") {
+			return fmt.Errorf("retention %d: path and keep_last or expire_after are required", i+1)
+		}
+		if strings.Contains(r.Path+r.ExpireAfter, ",") {
+			return fmt.Errorf("retention %s: path and expire_after cannot contain ','", r.Path)
+		}
+	}
+	return nil
+}
+
+func (s *Spec) applyDefaults(dir string) {
+	if s.Version == 0 {
+		s.Version = CurrentVersion
+	}
+	if s.BaseImage != "" && s.BuildContext == "" {
+		s.BuildContext = dir
+	}
+	for _, p := range []*string{&s.BuildContext, &s.Dockerfile, &s.Requirements, &s.CommandScript} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	if venv, ok := strings.CutPrefix(s.CaptureEnv, "venv:"); ok && venv != "" && !filepath.IsAbs(venv) {
+		s.CaptureEnv = "venv:" + filepath.Join(dir, venv)
+	}
+}
+
+// Flags returns the submit flags the spec sets, in a stable order.
+func (s *Spec) Flags() ([]Flag, error) {
+	var flags []Flag
+	str := func(name, value string) {
+		if value != "" {
+			flags = append(flags, Flag{Name: name, Values: []string{value}})
+		}
+	}
+	list := func(name string, values []string) {
+		if len(values) > 0 {
+			flags = append(flags, Flag{Name: name, Values: values})
+		}
+	}
+	num := func(name string, value int) {
+		if value != 0 {
+			str(name, strconv.Itoa(value))
+		}
+	}
+
+	str("name", s.Name)
+	str("name-template", s.NameTemplate)
+	str("cluster", s.Cluster)
+	str("location", s.Location)
+	str("project", s.Project)
+	str("image", s.Image)
+	str("base-image", s.BaseImage)
+	str("image-repo", s.ImageRepo)
+	str("build-context", s.BuildContext)
+	str("workdir", s.WorkDir)
+	str("dockerfile", s.Dockerfile)
+	str("requirements", s.Requirements)
+	str("capture-env", s.CaptureEnv)
+	str("platform", s.Platform)
+	str("build-backend", s.BuildBackend)
+	str("builder-zone", s.BuilderZone)
+	str("image-tag", s.ImageTag)
+	str("image-tag-strategy", s.ImageTagStrategy)
+	str("command", s.Command)
+	if len(s.CommandArgs) > 0 {
+		args, err := json.Marshal(s.CommandArgs)
+		if err != nil {
+			return nil, err
+		}
+		str("command-json", string(args))
+	}
+	str("command-script", s.CommandScript)
+	str("compute-type", s.ComputeType)
+	str("gpu-memory", s.GPUMemory)
+	num("num-nodes", s.NumNodes)
+	num("num-slices", s.NumSlices)
+	str("cpu", s.CPU)
+	str("memory", s.Memory)
+	str("cpu-request", s.CPURequest)
+	str("memory-request", s.MemoryRequest)
+	num("gpus-per-pod", s.GPUsPerPod)
+	str("topology", s.Topology)
+	str("placement-policy", s.PlacementPolicy)
+	list("node-selector", s.NodeSelectors)
+	str("queue", s.Queue)
+	str("priority", s.Priority)
+	if s.Restarts != nil {
+		str("restarts", strconv.Itoa(*s.Restarts))
+	}
+	str("timeout", s.Timeout)
+	str("startup-probe", s.StartupProbe)
+	str("startup-timeout", s.StartupTimeout)
+	str("grace-period", s.GracePeriod)
+	str("credentials-timeout", s.StepTimeouts.Credentials)
+	str("build-timeout", s.StepTimeouts.Build)
+	str("apply-timeout", s.StepTimeouts.Apply)
+	str("service-account", s.ServiceAccount)
+	str("sign-key", s.SignKey)
+	str("team", s.Team)
+	str("experiment", s.Experiment)
+
+	var imageLabels []string
+	for k, v := range s.ImageLabels {
+		imageLabels = append(imageLabels, k+"="+v)
+	}
+	slices.Sort(imageLabels)
+	list("image-label", imageLabels)
+
+	var env []string
+	for k, v := range s.Env {
+		env = append(env, k+"="+v)
+	}
+	slices.Sort(env)
+	list("env", env)
+
+	var secretEnv []string
+	for k, v := range s.SecretEnv {
+		secretEnv = append(secretEnv, k+"="+v)
+	}
+	slices.Sort(secretEnv)
+	list("secret-env", secretEnv)
+	str("secret-env-mode", s.SecretEnvMode)
+
+	list("mount", s.Mounts)
+	list("gcs-bucket", s.GCSBuckets)
+	list("mount-secret", s.SecretMounts)
+	list("mount-configmap", s.ConfigMapMounts)
+	list("stage-in", s.StageIn)
+
+	var inputs []string
+	for _, in := range s.Inputs {
+		inputs = append(inputs, in.flag())
+	}
+	list("input", inputs)
+
+	var retention []string
+	for _, r := range s.Retention {
+		retention = append(retention, r.flag())
+	}
+	list("retention", retention)
+	return flags, nil
+}
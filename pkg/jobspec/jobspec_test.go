@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeSpec(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "job.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeSpec(t, `
+name: train
+cluster: my-cluster
+base_image: python:3.11-slim
+workdir: /app
+image_labels:
+  team: ml
+  stage: dev
+image_tag_strategy: git-sha
+requirements: requirements.txt
+command_args: ["python", "train.py", "--epochs", "10"]
+compute_type: v6e-8
+num_slices: 2
+cpu: 8
+memory: 64Gi
+memory_request: 32Gi
+restarts: 0
+step_timeouts:
+  build: 30m
+env:
+  LR: "0.1"
+  BATCH: "64"
+secret_env:
+  WANDB_API_KEY: sm://projects/p/secrets/wandb
+mounts:
+  - gs://data:/data
+inputs:
+  - name: TRAIN_DATA
+    uri: gs://data/train.tfrecord
+    generation: "1712345678901234"
+  - name: EVAL_DATA
+    uri: gs://data/eval/
+retention:
+  - path: gs://data/checkpoints
+    keep_last: 3
+    expire_after: 30d
+`)
+	spec, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	dir := filepath.Dir(path)
+	if spec.Version != CurrentVersion {
+		t.Errorf("expected version to default to %d, got %d", CurrentVersion, spec.Version)
+	}
+	if spec.BuildContext != dir {
+		t.Errorf("expected build_context to default to %s, got %s", dir, spec.BuildContext)
+	}
+	if want := filepath.Join(dir, "requirements.txt"); spec.Requirements != want {
+		t.Errorf("expected requirements %s, got %s", want, spec.Requirements)
+	}
+
+	flags, err := spec.Flags()
+	if err != nil {
+		t.Fatalf("Flags() error = %v", err)
+	}
+	want := []Flag{
+		{Name: "name", Values: []string{"train"}},
+		{Name: "cluster", Values: []string{"my-cluster"}},
+		{Name: "base-image", Values: []string{"python:3.11-slim"}},
+		{Name: "build-context", Values: []string{dir}},
+		{Name: "workdir", Values: []string{"/app"}},
+		{Name: "requirements", Values: []string{filepath.Join(dir, "requirements.txt")}},
+		{Name: "image-tag-strategy", Values: []string{"git-sha"}},
+		{Name: "command-json", Values: []string{`["python","train.py","--epochs","10"]`}},
+		{Name: "compute-type", Values: []string{"v6e-8"}},
+		{Name: "num-slices", Values: []string{"2"}},
+		{Name: "cpu", Values: []string{"8"}},
+		{Name: "memory", Values: []string{"64Gi"}},
+		{Name: "memory-request", Values: []string{"32Gi"}},
+		{Name: "restarts", Values: []string{"0"}},
+		{Name: "build-timeout", Values: []string{"30m"}},
+		{Name: "image-label", Values: []string{"stage=dev", "team=ml"}},
+		{Name: "env", Values: []string{"BATCH=64", "LR=0.1"}},
+		{Name: "secret-env", Values: []string{"WANDB_API_KEY=sm://projects/p/secrets/wandb"}},
+		{Name: "mount", Values: []string{"gs://data:/data"}},
+		{Name: "input", Values: []string{"TRAIN_DATA=gs://data/train.tfrecord,generation=1712345678901234", "EVAL_DATA=gs://data/eval/"}},
+		{Name: "retention", Values: []string{"gs://data/checkpoints,keep-last=3,expire-after=30d"}},
+	}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("Flags() = %v, want %v", flags, want)
+	}
+}
+
+func TestLoad_CaptureEnv(t *testing.T) {
+	path := writeSpec(t, "base_image: python:3.11-slim\ncapture_env: venv:.venv\n")
+	spec, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := "venv:" + filepath.Join(filepath.Dir(path), ".venv"); spec.CaptureEnv != want {
+		t.Errorf("expected capture_env %s, got %s", want, spec.CaptureEnv)
+	}
+
+	spec, err = Load(writeSpec(t, "base_image: python:3.11-slim\ncapture_env: conda:ml\n"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if spec.CaptureEnv != "conda:ml" {
+		t.Errorf("expected the conda environment to be kept, got %s", spec.CaptureEnv)
+	}
+}
+
+func TestLoad_CommandScript(t *testing.T) {
+	path := writeSpec(t, "image: busybox\ncommand_script: scripts/run.sh\n")
+	spec, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := filepath.Join(filepath.Dir(path), "scripts", "run.sh")
+	if spec.CommandScript != want {
+		t.Errorf("expected command_script %s, got %s", want, spec.CommandScript)
+	}
+	flags, err := spec.Flags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(flags, func(f Flag) bool { return f.Name == "command-script" && slices.Equal(f.Values, []string{want}) }) {
+		t.Errorf("expected a --command-script flag, got %+v", flags)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for content, wantErr := range map[string]string{
+		"":                                       "the file is empty",
+		"name: train\ncomand: echo":              "field comand not found",
+		"num_nodes: two":                         "cannot unmarshal",
+		"version: 2":                             "unsupported version 2",
+		"image: busybox\nbase_image: python":     "image and base_image cannot be used together",
+		"image: busybox\ndockerfile: Dockerfile": "dockerfile cannot be used with image or base_image",
+		"command: echo\ncommand_args: [echo]":    "command and command_args cannot be used together",
+		"num_slices: -1":                         "cannot be negative",
+		"restarts: -1":                           "restarts cannot be negative",
+		"requirements: r.txt\ncapture_env: conda:ml": "requirements and capture_env cannot be used together",
+		"env:\n  \"A=B\": c":                         `invalid env variable name "A=B"`,
+		"secret_env:\n  \"A=B\": sm://c":             `invalid secret_env variable name "A=B"`,
+		"inputs:\n  - name: DATA":                    "input 1: name and uri are required",
+		"retention:\n  - path: gs://b/ckpt":          "retention 1: path and keep_last or expire_after are required",
+		"command: echo\ncommand_script: run.sh":      "command_script cannot be used with command or command_args",
+	} {
+		_, err := Load(writeSpec(t, content))
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Load(%q) error = %v, want %q", content, err, wantErr)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localstate provides concurrency-safe access to the files gcluster
+// keeps under the user's local state directory (~/.gcluster).
+package localstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DirName is the name of the state directory inside the user's home directory.
+	DirName = ".gcluster"
+
+	// schemaVersionKey is the top-level JSON key recording the schema version of a state file.
+	schemaVersionKey = "schema_version"
+
+	lockSuffix = ".lock"
+)
+
+var (
+	// LockTimeout bounds how long a caller waits for another gcluster process to release a state file.
+	LockTimeout       = 30 * time.Second
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// Migration upgrades the raw JSON document of a state file from one schema
+// version to the next. It mutates doc in place.
+type Migration func(doc map[string]interface{}) error
+
+// File describes a single JSON state file stored in the state directory.
+type File struct {
+	// Name is the file name relative to the state directory.
+	Name string
+	// Version is the current schema version written by this binary.
+	Version int
+	// Migrations maps a schema version to the migration that upgrades it to Version+1.
+	// Files written before versioning was introduced are treated as version 0.
+	Migrations map[int]Migration
+}
+
+// Dir returns the state directory, creating it if necessary.
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	stateDir := filepath.Join(homeDir, DirName)
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return "", fmt.Errorf("could not create state directory %s: %w", stateDir, err)
+	}
+	return stateDir, nil
+}
+
+// Path returns the full path to the named file in the state directory.
+func Path(name string) (string, error) {
+	stateDir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, name), nil
+}
+
+// Lock takes an exclusive advisory lock guarding path. The lock is held on a
+// sibling "<path>.lock" file so that the guarded file itself can be replaced
+// atomically. The returned function releases the lock.
+func Lock(path string) (func(), error) {
+	return lock(path, unix.LOCK_EX)
+}
+
+// RLock takes a shared advisory lock guarding path.
+func RLock(path string) (func(), error) {
+	return lock(path, unix.LOCK_SH)
+}
+
+func lock(path string, how int) (func(), error) {
+	lockPath := path + lockSuffix
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", lockPath, err)
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		err = unix.Flock(int(f.Fd()), how|unix.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, unix.EWOULDBLOCK) && !errors.Is(err, unix.EINTR) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for another gcluster process to release %s", LockTimeout, lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	return func() {
+		_ = unix.Flock(int(f.Fd()), unix.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// WriteFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so concurrent readers never observe a partial write.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file for %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file for %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file for %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// Path returns the full path of the state file.
+func (f File) Path() (string, error) {
+	return Path(f.Name)
+}
+
+// Load reads the state file into v under a shared lock, applying any pending
+// schema migrations. It returns an error wrapping os.ErrNotExist if the file
+// has not been written yet.
+func (f File) Load(v interface{}) error {
+	path, err := f.Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := RLock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return f.read(path, v)
+}
+
+// Save writes v to the state file under an exclusive lock.
+func (f File) Save(v interface{}) error {
+	path, err := f.Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return f.write(path, v)
+}
+
+// Update performs a read-modify-write cycle on the state file while holding an
+// exclusive lock. v is populated from disk (left untouched if the file does not
+// exist yet), then mutate is called, and the result is written back.
+func (f File) Update(v interface{}, mutate func() error) error {
+	path, err := f.Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := f.read(path, v); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := mutate(); err != nil {
+		return err
+	}
+	return f.write(path, v)
+}
+
+func (f File) read(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	if err := f.migrate(doc); err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal migrated %s: %w", path, err)
+	}
+	if err := json.Unmarshal(migrated, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return nil
+}
+
+func (f File) write(path string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", f.Name, err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("state for %s must be a JSON object: %w", f.Name, err)
+	}
+	doc[schemaVersionKey] = f.Version
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", f.Name, err)
+	}
+	return WriteFileAtomic(path, data, 0644)
+}
+
+// migrate upgrades doc in place to f.Version.
+func (f File) migrate(doc map[string]interface{}) error {
+	version := 0
+	if raw, ok := doc[schemaVersionKey].(float64); ok {
+		version = int(raw)
+	}
+	if version > f.Version {
+		return fmt.Errorf("state file was written by a newer gcluster (schema version %d, this binary supports up to %d); please upgrade gcluster", version, f.Version)
+	}
+	for ; version < f.Version; version++ {
+		if m, ok := f.Migrations[version]; ok {
+			if err := m(doc); err != nil {
+				return fmt.Errorf("schema migration from version %d failed: %w", version, err)
+			}
+		}
+	}
+	doc[schemaVersionKey] = f.Version
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type counter struct {
+	Count int    `json:"count"`
+	Label string `json:"label,omitempty"`
+}
+
+func TestDir_CreatesStateDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir() error = %v", err)
+	}
+	if dir != filepath.Join(home, DirName) {
+		t.Errorf("Dir() = %s, want %s", dir, filepath.Join(home, DirName))
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		t.Errorf("expected state directory %s to exist", dir)
+	}
+}
+
+func TestFile_LoadNotExist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	f := File{Name: "missing.json", Version: 1}
+	var c counter
+	err := f.Load(&c)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestFile_SaveWritesSchemaVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	f := File{Name: "counter.json", Version: 3}
+	if err := f.Save(counter{Count: 7}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	path, _ := f.Path()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc[schemaVersionKey] != float64(3) {
+		t.Errorf("schema_version = %v, want 3", doc[schemaVersionKey])
+	}
+
+	var c counter
+	if err := f.Load(&c); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Count != 7 {
+		t.Errorf("Count = %d, want 7", c.Count)
+	}
+}
+
+func TestFile_MigratesLegacyFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	f := File{
+		Name:    "legacy.json",
+		Version: 2,
+		Migrations: map[int]Migration{
+			0: func(doc map[string]interface{}) error {
+				doc["count"] = doc["old_count"]
+				delete(doc, "old_count")
+				return nil
+			},
+			1: func(doc map[string]interface{}) error {
+				doc["label"] = "migrated"
+				return nil
+			},
+		},
+	}
+	path, _ := f.Path()
+	if err := os.WriteFile(path, []byte(`{"old_count": 5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var c counter
+	if err := f.Load(&c); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Count != 5 || c.Label != "migrated" {
+		t.Errorf("Load() = %+v, want {Count:5 Label:migrated}", c)
+	}
+}
+
+func TestFile_RejectsNewerSchema(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	f := File{Name: "newer.json", Version: 1}
+	path, _ := f.Path()
+	if err := os.WriteFile(path, []byte(`{"schema_version": 9}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var c counter
+	err := f.Load(&c)
+	if err == nil || !strings.Contains(err.Error(), "newer gcluster") {
+		t.Fatalf("expected newer schema error, got %v", err)
+	}
+}
+
+func TestFile_ConcurrentUpdates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	f := File{Name: "concurrent.json", Version: 1}
+	const workers = 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var c counter
+			if err := f.Update(&c, func() error {
+				c.Count++
+				return nil
+			}); err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var c counter
+	if err := f.Load(&c); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.Count != workers {
+		t.Errorf("Count = %d, want %d", c.Count, workers)
+	}
+}
+
+func TestLock_TimesOut(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "busy.json")
+
+	origTimeout := LockTimeout
+	LockTimeout = 100 * time.Millisecond
+	defer func() { LockTimeout = origTimeout }()
+
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	defer unlock()
+
+	if _, err := Lock(path); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second" {
+		t.Errorf("content = %q, want %q", data, "second")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected temporary files to be cleaned up, found %d entries", len(entries))
+	}
+}
@@ -17,6 +17,7 @@ package logging
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"time"
@@ -31,6 +32,7 @@ const (
 
 var (
 	infolog      *log.Logger
+	debuglog     *log.Logger
 	errorlog     *log.Logger
 	fatallog     *log.Logger
 	FatalHook    func(exitCode int, err error) // FatalHook allows registering a callback to run before the program exits on a fatal error.
@@ -41,6 +43,7 @@ var (
 
 func init() {
 	infolog = log.New(os.Stdout, "", 0)
+	debuglog = log.New(io.Discard, "", 0)
 	errorlog = log.New(os.Stderr, "", 0)
 	fatallog = log.New(os.Stderr, "", 0)
 }
@@ -57,6 +60,29 @@ func Info(f string, a ...any) {
 	infolog.Printf("%s: %s", formatTs(), msg)
 }
 
+// SetInfoOutput sets where Info prints, e.g. to stderr while stdout carries
+// machine-readable output.
+func SetInfoOutput(w io.Writer) {
+	infolog.SetOutput(w)
+}
+
+// Debug prints detail that is only useful when something goes wrong. It is
+// discarded unless an output is set with SetDebugOutput.
+func Debug(f string, a ...any) {
+	msg := fmt.Sprintf(f, a...)
+	debuglog.Printf("%s: %s", formatTs(), msg)
+}
+
+// SetDebugOutput sets where Debug prints; io.Discard turns it off.
+func SetDebugOutput(w io.Writer) {
+	debuglog.SetOutput(w)
+}
+
+// InfoOutput returns where Info prints.
+func InfoOutput() io.Writer {
+	return infolog.Writer()
+}
+
 // Warn prints message to stderr but does not end the program
 func Warn(f string, a ...any) {
 	msg := fmt.Sprintf(f, a...)
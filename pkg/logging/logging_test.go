@@ -15,6 +15,9 @@
 package logging
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -79,3 +82,19 @@ func TestFatal(t *testing.T) {
 		t.Errorf("expected hook error message 'fatal error 404', got %v", hookErr)
 	}
 }
+
+func TestDebug(t *testing.T) {
+	defer SetDebugOutput(io.Discard)
+
+	var buf bytes.Buffer
+	Debug("dropped %d", 1)
+	SetDebugOutput(&buf)
+	Debug("kept %d", 2)
+
+	if strings.Contains(buf.String(), "dropped") {
+		t.Errorf("Debug printed before an output was set: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "kept 2") {
+		t.Errorf("expected the debug message, got %q", buf.String())
+	}
+}
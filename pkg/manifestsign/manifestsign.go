@@ -0,0 +1,329 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package manifestsign signs the objects of rendered workload manifests with
+// a Cloud KMS asymmetric key, and verifies them. Each object carries a
+// detached signature over its canonical JSON form in an annotation, so that
+// it can be verified on its own, e.g. by an admission policy.
+package manifestsign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"hpc-toolkit/pkg/shell"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SignatureAnnotation holds the base64 signature of the object.
+	SignatureAnnotation = "gcluster.google.com/signature"
+	// SigningKeyAnnotation names the KMS key version the object is signed with.
+	SigningKeyAnnotation = "gcluster.google.com/signing-key"
+)
+
+var keyVersionRegex = regexp.MustCompile(`^projects/([^/]+)/locations/([^/]+)/keyRings/([^/]+)/cryptoKeys/([^/]+)/cryptoKeyVersions/([^/]+)$`)
+
+// KeyVersion is a Cloud KMS asymmetric signing key version.
+type KeyVersion struct {
+	Project, Location, KeyRing, Key, Version string
+}
+
+// ParseKeyVersion parses the resource name of a KMS key version:
+// projects/P/locations/L/keyRings/R/cryptoKeys/K/cryptoKeyVersions/V.
+func ParseKeyVersion(name string) (KeyVersion, error) {
+	m := keyVersionRegex.FindStringSubmatch(name)
+	if m == nil {
+		return KeyVersion{}, fmt.Errorf("invalid KMS key version %q: must be projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>/cryptoKeyVersions/<version>", name)
+	}
+	return KeyVersion{Project: m[1], Location: m[2], KeyRing: m[3], Key: m[4], Version: m[5]}, nil
+}
+
+func (k KeyVersion) String() string {
+	return fmt.Sprintf("projects/%s/locations/%s/keyRings/%s/cryptoKeys/%s/cryptoKeyVersions/%s", k.Project, k.Location, k.KeyRing, k.Key, k.Version)
+}
+
+func (k KeyVersion) gcloudArgs() []string {
+	return []string{"--project", k.Project, "--location", k.Location, "--keyring", k.KeyRing, "--key", k.Key}
+}
+
+// Sign signs every object of manifest with keyVersion and returns the
+// manifest with the signature annotations added.
+func Sign(manifest []byte, keyVersion string) ([]byte, error) {
+	key, err := ParseKeyVersion(keyVersion)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := decode(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "gcluster-sign-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, doc := range docs {
+		setAnnotation(doc, SigningKeyAnnotation, key.String())
+		canonical, err := canonicalObject(doc)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := kmsSign(key, canonical, dir, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign %s: %w", describe(doc), err)
+		}
+		setAnnotation(doc, SignatureAnnotation, sig)
+	}
+	return encode(docs)
+}
+
+// Verify checks the signature of every object of manifest against the
+// public key of the KMS key version it names, and returns the objects.
+func Verify(manifest []byte) ([]string, error) {
+	docs, err := decode(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := map[string]crypto.PublicKey{}
+	var objects []string
+	for _, doc := range docs {
+		name := describe(doc)
+		annotations := annotationsOf(doc)
+		sig, keyVersion := annotations[SignatureAnnotation], annotations[SigningKeyAnnotation]
+		if sig == "" || keyVersion == "" {
+			return nil, fmt.Errorf("%s is not signed", name)
+		}
+		pub, ok := publicKeys[keyVersion]
+		if !ok {
+			key, err := ParseKeyVersion(keyVersion)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if pub, err = kmsPublicKey(key); err != nil {
+				return nil, err
+			}
+			publicKeys[keyVersion] = pub
+		}
+
+		canonical, err := canonicalObject(doc)
+		if err != nil {
+			return nil, err
+		}
+		rawSig, err := base64.StdEncoding.DecodeString(sig)
+		if err != nil {
+			return nil, fmt.Errorf("%s has a malformed signature: %w", name, err)
+		}
+		if err := verifySignature(pub, canonical, rawSig); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		objects = append(objects, name)
+	}
+	return objects, nil
+}
+
+func kmsSign(key KeyVersion, data []byte, dir string, i int) (string, error) {
+	input := filepath.Join(dir, fmt.Sprintf("object-%d.json", i))
+	output := input + ".sig"
+	if err := os.WriteFile(input, data, 0600); err != nil {
+		return "", err
+	}
+	args := append([]string{"kms", "asymmetric-sign"}, key.gcloudArgs()...)
+	args = append(args, "--version", key.Version, "--digest-algorithm", "sha256", "--input-file", input, "--signature-file", output)
+	if res := shell.ExecuteCommand("gcloud", args...); res.ExitCode != 0 {
+		return "", fmt.Errorf("gcloud kms asymmetric-sign failed: %s", res.Stderr)
+	}
+	sig, err := os.ReadFile(output)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func kmsPublicKey(key KeyVersion) (crypto.PublicKey, error) {
+	f, err := os.CreateTemp("", "gcluster-public-key-*.pem")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary file: %w", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	args := append([]string{"kms", "keys", "versions", "get-public-key", key.Version}, key.gcloudArgs()...)
+	args = append(args, "--output-file", f.Name())
+	if res := shell.ExecuteCommand("gcloud", args...); res.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to get the public key of %s: %s", key, res.Stderr)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("the public key of %s is not PEM encoded", key)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the public key of %s: %w", key, err)
+	}
+	return pub, nil
+}
+
+// verifySignature verifies a signature made with a SHA-256 KMS algorithm:
+// EC_SIGN_P256_SHA256, or an RSA_SIGN_PKCS1 or RSA_SIGN_PSS one.
+func verifySignature(pub crypto.PublicKey, data, sig []byte) error {
+	digest := sha256.Sum256(data)
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(pub, digest[:], sig) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil || rsa.VerifyPSS(pub, crypto.SHA256, digest[:], sig, nil) == nil {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return errors.New("signature does not match the object; it was modified after signing")
+}
+
+// canonicalObject returns the JSON form of an object without its signature,
+// with the keys of every map sorted.
+func canonicalObject(doc *yaml.Node) ([]byte, error) {
+	var obj map[string]any
+	if err := doc.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", describe(doc), err)
+	}
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			delete(annotations, SignatureAnnotation)
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+	return json.Marshal(obj)
+}
+
+func decode(manifest []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(manifest))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		docs = append(docs, doc.Content[0])
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("the manifest has no objects")
+	}
+	return docs, nil
+}
+
+func encode(docs []*yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to encode manifest: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// child returns the value of key in a mapping node, or nil.
+func child(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func addChild(node *yaml.Node, key string, value *yaml.Node) *yaml.Node {
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+func setAnnotation(doc *yaml.Node, key, value string) {
+	metadata := child(doc, "metadata")
+	if metadata == nil {
+		metadata = addChild(doc, "metadata", &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+	annotations := child(metadata, "annotations")
+	if annotations == nil {
+		annotations = addChild(metadata, "annotations", &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	} else if annotations.Kind != yaml.MappingNode {
+		// An empty "annotations:" is a null scalar.
+		*annotations = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	if v := child(annotations, key); v != nil {
+		v.Value = value
+		return
+	}
+	addChild(annotations, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+func annotationsOf(doc *yaml.Node) map[string]string {
+	annotations := map[string]string{}
+	if node := child(child(doc, "metadata"), "annotations"); node != nil {
+		_ = node.Decode(&annotations)
+	}
+	return annotations
+}
+
+// describe names an object as Kind/name for messages.
+func describe(doc *yaml.Node) string {
+	kind, name := "object", "<unnamed>"
+	if v := child(doc, "kind"); v != nil {
+		kind = v.Value
+	}
+	if v := child(child(doc, "metadata"), "name"); v != nil {
+		name = v.Value
+	}
+	return kind + "/" + name
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestsign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/shell"
+)
+
+const testKey = "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+
+const testManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: stage-in
+data:
+  a: b
+---
+apiVersion: jobset.x-k8s.io/v1alpha2
+kind: JobSet
+metadata:
+  name: train
+  annotations:
+    gcluster.google.com/run-id: "0123456789ab"
+spec:
+  replicatedJobs:
+    - name: workers
+      replicas: 2
+`
+
+// fakeKMS signs with a local ECDSA key in place of gcloud kms.
+func fakeKMS(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	flag := func(args []string, name string) string {
+		for i, a := range args {
+			if a == name && i+1 < len(args) {
+				return args[i+1]
+			}
+		}
+		return ""
+	}
+	orig := shell.ExecuteCommand
+	t.Cleanup(func() { shell.ExecuteCommand = orig })
+	shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+		cmd := strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "kms asymmetric-sign"):
+			data, err := os.ReadFile(flag(args, "--input-file"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			digest := sha256.Sum256(data)
+			sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(flag(args, "--signature-file"), sig, 0600); err != nil {
+				t.Fatal(err)
+			}
+		case strings.HasPrefix(cmd, "kms keys versions get-public-key 1"):
+			if err := os.WriteFile(flag(args, "--output-file"), pubPEM, 0600); err != nil {
+				t.Fatal(err)
+			}
+		default:
+			t.Fatalf("unexpected command: %s %s", name, cmd)
+		}
+		return shell.CommandResult{}
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	fakeKMS(t)
+
+	signed, err := Sign([]byte(testManifest), testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(signed), SignatureAnnotation+":"); n != 2 {
+		t.Errorf("expected both objects to be signed, got %d signatures:\n%s", n, signed)
+	}
+	if !strings.Contains(string(signed), SigningKeyAnnotation+": "+testKey) {
+		t.Errorf("expected the signing key annotation:\n%s", signed)
+	}
+
+	objects, err := Verify(signed)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if strings.Join(objects, ",") != "ConfigMap/stage-in,JobSet/train" {
+		t.Errorf("unexpected verified objects %v", objects)
+	}
+
+	tampered := strings.Replace(string(signed), "replicas: 2", "replicas: 20", 1)
+	if _, err := Verify([]byte(tampered)); err == nil || !strings.Contains(err.Error(), "JobSet/train: signature does not match") {
+		t.Errorf("expected the modified JobSet to fail verification, got %v", err)
+	}
+
+	if _, err := Verify([]byte(testManifest)); err == nil || !strings.Contains(err.Error(), "ConfigMap/stage-in is not signed") {
+		t.Errorf("expected an unsigned manifest to fail verification, got %v", err)
+	}
+}
+
+func TestParseKeyVersion(t *testing.T) {
+	key, err := ParseKeyVersion(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != (KeyVersion{Project: "p", Location: "global", KeyRing: "r", Key: "k", Version: "1"}) || key.String() != testKey {
+		t.Errorf("unexpected key version %+v", key)
+	}
+	for _, name := range []string{"", "projects/p/locations/global/keyRings/r/cryptoKeys/k"} {
+		if _, err := ParseKeyVersion(name); err == nil {
+			t.Errorf("expected an error for %q", name)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Backends workloads can run on.
+const (
+	BackendGKE    = "gke"    // A JobSet on a GKE cluster.
+	BackendVertex = "vertex" // A Vertex AI custom training job, without a cluster.
+	BackendBatch  = "batch"  // A Cloud Batch job, without a cluster.
+)
+
+// Backends lists the valid backends.
+var Backends = []string{BackendGKE, BackendVertex, BackendBatch}
+
+// Config locates where the orchestrator created by New runs workloads.
+// ClusterLocation is a region or zone; the backends without a cluster run in
+// its region.
+type Config struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+}
+
+// Factory creates the orchestrator of a backend.
+type Factory func(cfg Config) (JobOrchestrator, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register makes New create the orchestrators of backend with f. It is
+// called by the programs that link the backend in; registering a backend
+// again replaces its factory.
+func Register(backend string, f Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[backend] = f
+}
+
+// MissingConfigError reports a Config field that a backend requires but
+// that is not set. Field is "cluster", "location" or "project", and
+// Description names it in messages, e.g. "cluster name".
+type MissingConfigError struct {
+	Backend     string
+	Field       string
+	Description string
+}
+
+func (e *MissingConfigError) Error() string {
+	return fmt.Sprintf("%s is required with the %s backend", e.Description, e.Backend)
+}
+
+// requiresCluster tells whether a backend runs workloads on a cluster, so
+// that Config.ClusterName and JobDefinition.ClusterName must be set.
+func requiresCluster(backend string) bool {
+	return backend == BackendGKE
+}
+
+// New returns the orchestrator of backend for cfg. It fails when the backend
+// is unknown or not registered, or when cfg lacks what the backend requires,
+// with a *MissingConfigError.
+func New(backend string, cfg Config) (JobOrchestrator, error) {
+	if !slices.Contains(Backends, backend) {
+		return nil, fmt.Errorf("unknown backend %q; valid backends are %s", backend, strings.Join(Backends, ", "))
+	}
+	required := []struct{ field, description, value string }{
+		{"cluster", "cluster name", cfg.ClusterName},
+		{"location", "location", cfg.ClusterLocation},
+		{"project", "project ID", cfg.ProjectID},
+	}
+	for _, r := range required {
+		if r.value == "" && (r.field != "cluster" || requiresCluster(backend)) {
+			return nil, &MissingConfigError{Backend: backend, Field: r.field, Description: r.description}
+		}
+	}
+
+	factoriesMu.RLock()
+	f, ok := factories[backend]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("the %s backend is not available in this build", backend)
+	}
+	return f(cfg)
+}
+
+// requiredJobField is a JobDefinition setting that a backend cannot run a
+// workload without.
+type requiredJobField struct {
+	flags string // The flags that set the field.
+	set   func(JobDefinition) bool
+}
+
+var (
+	requireName     = requiredJobField{"--name", func(j JobDefinition) bool { return j.WorkloadName != "" }}
+	requireProject  = requiredJobField{"--project", func(j JobDefinition) bool { return j.ClusterProjectID != "" }}
+	requireLocation = requiredJobField{"--location", func(j JobDefinition) bool { return j.ClusterLocation != "" }}
+	requireCluster  = requiredJobField{"--cluster", func(j JobDefinition) bool { return j.ClusterName != "" }}
+	requireImage    = requiredJobField{"--image, --base-image or --dockerfile", func(j JobDefinition) bool {
+		return j.ImageName != "" || j.BaseImage != "" || j.Dockerfile != ""
+	}}
+	// A GKE workload can also run a snippet or script in a stock image, and a
+	// headless Pathways workload runs no user container at all.
+	requireGKEProgram = requiredJobField{"--image, --base-image, --dockerfile, --snippet or --command-script", func(j JobDefinition) bool {
+		return j.ImageName != "" || j.BaseImage != "" || j.Dockerfile != "" || j.Snippet != "" || j.CommandScript != "" || (j.IsPathwaysJob && j.Pathways.Headless)
+	}}
+)
+
+// requiredJobFields lists the fields each backend requires.
+var requiredJobFields = map[string][]requiredJobField{
+	BackendGKE:    {requireName, requireCluster, requireLocation, requireProject, requireGKEProgram},
+	BackendVertex: {requireName, requireLocation, requireProject, requireImage},
+	BackendBatch:  {requireName, requireLocation, requireProject, requireImage},
+}
+
+// ValidateJobDefinition checks that job sets every field backend requires,
+// and names the flags of all the missing ones.
+func ValidateJobDefinition(backend string, job JobDefinition) error {
+	fields, ok := requiredJobFields[backend]
+	if !ok {
+		return fmt.Errorf("unknown backend %q; valid backends are %s", backend, strings.Join(Backends, ", "))
+	}
+	var missing []string
+	for _, f := range fields {
+		if !f.set(job) {
+			missing = append(missing, f.flags)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the %s backend requires %s", backend, strings.Join(missing, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeOrchestrator struct {
+	JobOrchestrator
+	cfg Config
+}
+
+func TestNew(t *testing.T) {
+	Register(BackendVertex, func(cfg Config) (JobOrchestrator, error) { return &fakeOrchestrator{cfg: cfg}, nil })
+	defer func() {
+		factoriesMu.Lock()
+		delete(factories, BackendVertex)
+		factoriesMu.Unlock()
+	}()
+
+	cfg := Config{ProjectID: "p", ClusterLocation: "us-central1"}
+	orc, err := New(BackendVertex, cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := orc.(*fakeOrchestrator).cfg; got != cfg {
+		t.Errorf("factory got config %+v, want %+v", got, cfg)
+	}
+
+	if _, err := New("k8s", cfg); err == nil || !strings.Contains(err.Error(), "valid backends are gke, vertex, batch") {
+		t.Errorf("expected an unknown backend error, got %v", err)
+	}
+	if _, err := New(BackendBatch, cfg); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("expected an unregistered backend error, got %v", err)
+	}
+}
+
+func TestNew_MissingConfig(t *testing.T) {
+	tests := []struct {
+		backend string
+		cfg     Config
+		field   string
+	}{
+		{BackendGKE, Config{ProjectID: "p", ClusterLocation: "us-central1"}, "cluster"},
+		{BackendGKE, Config{ProjectID: "p", ClusterName: "c"}, "location"},
+		{BackendBatch, Config{ClusterLocation: "us-central1"}, "project"},
+		{BackendVertex, Config{}, "location"},
+	}
+	for _, tc := range tests {
+		_, err := New(tc.backend, tc.cfg)
+		var missing *MissingConfigError
+		if !errors.As(err, &missing) || missing.Field != tc.field || missing.Backend != tc.backend {
+			t.Errorf("New(%s, %+v) error = %v, want a missing %s", tc.backend, tc.cfg, err, tc.field)
+		}
+	}
+}
+
+func TestValidateJobDefinition(t *testing.T) {
+	located := JobDefinition{WorkloadName: "train", ClusterProjectID: "p", ClusterLocation: "us-central1"}
+	tests := []struct {
+		name    string
+		backend string
+		job     func(JobDefinition) JobDefinition
+		wantErr string
+	}{
+		{"gke image", BackendGKE, func(j JobDefinition) JobDefinition { j.ClusterName, j.ImageName = "c", "img"; return j }, ""},
+		{"gke snippet", BackendGKE, func(j JobDefinition) JobDefinition { j.ClusterName, j.Snippet = "c", "print(1)"; return j }, ""},
+		{"gke without cluster or image", BackendGKE, func(j JobDefinition) JobDefinition { return j },
+			"the gke backend requires --cluster; --image, --base-image, --dockerfile, --snippet or --command-script"},
+		{"vertex base image", BackendVertex, func(j JobDefinition) JobDefinition { j.BaseImage = "python:3.11"; return j }, ""},
+		{"batch without name or image", BackendBatch, func(j JobDefinition) JobDefinition { j.WorkloadName = ""; return j },
+			"the batch backend requires --name; --image, --base-image or --dockerfile"},
+		{"unknown backend", "k8s", func(j JobDefinition) JobDefinition { return j }, "unknown backend"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateJobDefinition(tc.backend, tc.job(located))
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateJobDefinition() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ValidateJobDefinition() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
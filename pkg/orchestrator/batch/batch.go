@@ -0,0 +1,234 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package batch runs gcluster workloads as Cloud Batch jobs, for users who do
+// not want to manage a GKE cluster.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/orchestrator/hosted"
+	"hpc-toolkit/pkg/shell"
+)
+
+// stdoutManifestPath as a DryRunManifest prints the job config.
+const stdoutManifestPath = "-"
+
+// dryRunOut receives the job config printed by a plain --dry-run.
+var dryRunOut io.Writer = os.Stdout
+
+// errUnsupported is returned by the operations of the JobOrchestrator
+// interface that only the GKE backend implements.
+var errUnsupported = errors.New("not supported by the batch backend; manage Cloud Batch jobs with 'gcloud batch jobs' or in the Cloud Console")
+
+// Executor runs the gcloud commands of the orchestrator.
+type Executor interface {
+	ExecuteCommand(name string, args ...string) shell.CommandResult
+}
+
+type defaultExecutor struct{}
+
+func (defaultExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	return shell.ExecuteCommand(name, args...)
+}
+
+// BatchOrchestrator submits workloads as Cloud Batch jobs in the region of the
+// job's location.
+type BatchOrchestrator struct {
+	executor Executor
+}
+
+var _ orchestrator.JobOrchestrator = (*BatchOrchestrator)(nil)
+
+func NewBatchOrchestrator() *BatchOrchestrator {
+	return &BatchOrchestrator{executor: defaultExecutor{}}
+}
+
+func (b *BatchOrchestrator) SetExecutor(e Executor) {
+	b.executor = e
+}
+
+// SubmitJob builds the image of job, unless it names a pre-built one, and
+// submits a Cloud Batch job running it. A dry run prints or writes the job
+// config instead.
+func (b *BatchOrchestrator) SubmitJob(ctx context.Context, job orchestrator.JobDefinition) (orchestrator.SubmitResult, error) {
+	logging.Info("Starting gcluster job submit workflow for Cloud Batch...")
+	if err := hosted.ValidateJob(job, "batch", "Cloud Batch job"); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := orchestrator.AssignRunMetadata(&job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if job.BuildProjectID == "" {
+		job.BuildProjectID = job.ClusterProjectID
+	}
+	if err := hosted.EnforceManagedProfile(job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	region := shell.ExtractRegion(job.ClusterLocation)
+
+	image, err := hosted.BuildImage(ctx, job)
+	if err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	cfg, err := buildJobConfig(job, image)
+	if err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return orchestrator.SubmitResult{}, fmt.Errorf("failed to render the Cloud Batch job config: %w", err)
+	}
+	data = append(data, '\n')
+
+	result := orchestrator.SubmitResult{
+		WorkloadName: job.WorkloadName,
+		Location:     region,
+		ProjectID:    job.ClusterProjectID,
+		RunID:        job.RunID,
+		ComputeType:  job.ComputeType,
+		Image:        image,
+		Manifest:     job.DryRunManifest,
+	}
+	if job.IsDryRun() {
+		return result, writeDryRunConfig(job.DryRunManifest, data)
+	}
+	if err := ctx.Err(); err != nil {
+		return orchestrator.SubmitResult{}, fmt.Errorf("job submission canceled: %w", err)
+	}
+
+	uid, err := b.submitBatchJob(job, region, data)
+	if err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	result.WorkloadURL, result.LogsURL = consoleLinks(job.ClusterProjectID, region, job.WorkloadName, uid)
+	logging.Info("Follow your Cloud Batch job here: %s", result.WorkloadURL)
+	logging.Info("View its logs here: %s", result.LogsURL)
+	logging.Info("gcluster job submit workflow completed.")
+	return result, nil
+}
+
+// submitBatchJob submits the job of config and returns its UID.
+func (b *BatchOrchestrator) submitBatchJob(job orchestrator.JobDefinition, region string, config []byte) (string, error) {
+	dir, err := os.MkdirTemp("", "gcluster-batch-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a directory for the Cloud Batch job config: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "job.json")
+	if err := os.WriteFile(configPath, config, 0644); err != nil {
+		return "", fmt.Errorf("failed to write the Cloud Batch job config: %w", err)
+	}
+
+	logging.Info("Submitting Cloud Batch job '%s' in %s...", job.WorkloadName, region)
+	res := b.executor.ExecuteCommand("gcloud", "batch", "jobs", "submit", job.WorkloadName,
+		"--project", job.ClusterProjectID,
+		"--location", region,
+		"--config", configPath,
+		"--format=value(uid)")
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("failed to submit Cloud Batch job: %s\n%s", res.Stderr, res.Stdout)
+	}
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// consoleLinks returns the Cloud Console links of a Cloud Batch job and of the
+// logs of its tasks.
+func consoleLinks(project, region, name, uid string) (jobLink, logsLink string) {
+	jobLink = fmt.Sprintf("https://console.cloud.google.com/batch/jobsDetail/regions/%s/jobs/%s/details?project=%s", region, name, project)
+	query := fmt.Sprintf(`logName="projects/%s/logs/batch_task_logs"
+labels.job_uid="%s"`, project, uid)
+	logsLink = fmt.Sprintf("https://console.cloud.google.com/logs/query;query=%s;storageScope=project;duration=P1D?project=%s",
+		url.QueryEscape(query), project)
+	return jobLink, logsLink
+}
+
+func writeDryRunConfig(path string, data []byte) error {
+	if path == "" || path == stdoutManifestPath {
+		_, err := dryRunOut.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write the Cloud Batch job config to %s: %w", path, err)
+	}
+	logging.Info("[Dry Run] Cloud Batch job config written to %s.", path)
+	return nil
+}
+
+func (b *BatchOrchestrator) ListJobs(context.Context, orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
+	return nil, fmt.Errorf("listing workloads is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) CancelJob(context.Context, string, orchestrator.CancelOptions) error {
+	return fmt.Errorf("canceling workloads is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) GetJobLogs(context.Context, string, orchestrator.LogsOptions) (string, error) {
+	return "", fmt.Errorf("reading logs is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) AttachJob(context.Context, string, orchestrator.AttachOptions) (string, error) {
+	return "", fmt.Errorf("attaching to workloads is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) GetJobStatus(context.Context, string, orchestrator.StatusOptions) (orchestrator.JobStatusDetail, error) {
+	return orchestrator.JobStatusDetail{}, fmt.Errorf("reading the status of workloads is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) WaitJob(context.Context, string, orchestrator.WaitOptions) (orchestrator.WaitResult, error) {
+	return orchestrator.WaitResult{}, fmt.Errorf("waiting for workloads is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) InspectCluster(context.Context, orchestrator.InspectOptions) error {
+	return fmt.Errorf("inspecting clusters is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) CreateBundle(context.Context, string, orchestrator.BundleOptions) (string, error) {
+	return "", fmt.Errorf("support bundles are %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) ReportJob(context.Context, string, orchestrator.ReportOptions) (orchestrator.RunReport, error) {
+	return orchestrator.RunReport{}, fmt.Errorf("run reports are %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) DescribeJob(context.Context, string, orchestrator.DescribeOptions) (orchestrator.WorkloadDescription, error) {
+	return orchestrator.WorkloadDescription{}, fmt.Errorf("describing workloads is %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) EstimateAdmission(context.Context, string, orchestrator.EtaOptions) (orchestrator.AdmissionEstimate, error) {
+	return orchestrator.AdmissionEstimate{}, fmt.Errorf("admission estimates are %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) PlanPlacement(context.Context, orchestrator.PlanOptions) (orchestrator.PlacementPlan, error) {
+	return orchestrator.PlacementPlan{}, fmt.Errorf("placement plans are %w", errUnsupported)
+}
+
+func (b *BatchOrchestrator) StartDevSession(context.Context, orchestrator.DevSessionDefinition) error {
+	return fmt.Errorf("dev sessions are %w", errUnsupported)
+}
+
+// Impersonate is a no-op: the batch backend makes no read-only checks, so
+// callers reject --as with it.
+func (b *BatchOrchestrator) Impersonate(string) {}
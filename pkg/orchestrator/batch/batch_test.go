@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+type mockExecutor struct {
+	calls  [][]string
+	config string
+	result shell.CommandResult
+}
+
+func (m *mockExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	m.calls = append(m.calls, append([]string{name}, args...))
+	for i, arg := range args {
+		if arg == "--config" && i+1 < len(args) {
+			data, _ := os.ReadFile(args[i+1])
+			m.config = string(data)
+		}
+	}
+	return m.result
+}
+
+func testJob() orchestrator.JobDefinition {
+	return orchestrator.JobDefinition{
+		ImageName:        "us-docker.pkg.dev/p/r/train:v1",
+		ComputeType:      "l4-1",
+		CommandToRun:     "python train.py",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-central1-a",
+		WorkloadName:     "train",
+		NumSlices:        1,
+		NodesPerSlice:    1,
+	}
+}
+
+func TestSubmitJob(t *testing.T) {
+	exec := &mockExecutor{result: shell.CommandResult{Stdout: "train-1a2b3c4d-5e6f\n"}}
+	b := NewBatchOrchestrator()
+	b.SetExecutor(exec)
+
+	result, err := b.SubmitJob(context.Background(), testJob())
+	if err != nil {
+		t.Fatalf("SubmitJob() failed: %v", err)
+	}
+
+	if len(exec.calls) != 1 {
+		t.Fatalf("expected one gcloud call, got %v", exec.calls)
+	}
+	cmd := strings.Join(exec.calls[0], " ")
+	for _, want := range []string{"gcloud batch jobs submit train", "--project my-project", "--location us-central1 "} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("expected %q in %q", want, cmd)
+		}
+	}
+	for _, want := range []string{`"machineType": "g2-standard-12"`, `"type": "nvidia-l4"`, `"imageUri": "us-docker.pkg.dev/p/r/train:v1"`} {
+		if !strings.Contains(exec.config, want) {
+			t.Errorf("expected %q in the job config:\n%s", want, exec.config)
+		}
+	}
+	if result.Location != "us-central1" || result.RunID == "" || result.ClusterName != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.Contains(result.WorkloadURL, "/batch/jobsDetail/regions/us-central1/jobs/train/") || !strings.Contains(result.LogsURL, "train-1a2b3c4d-5e6f") {
+		t.Errorf("unexpected links %q and %q", result.WorkloadURL, result.LogsURL)
+	}
+}
+
+func TestSubmitJobDryRun(t *testing.T) {
+	var out bytes.Buffer
+	prev := dryRunOut
+	dryRunOut = &out
+	defer func() { dryRunOut = prev }()
+
+	exec := &mockExecutor{}
+	b := NewBatchOrchestrator()
+	b.SetExecutor(exec)
+	job := testJob()
+	job.DryRun = true
+
+	if _, err := b.SubmitJob(context.Background(), job); err != nil {
+		t.Fatalf("SubmitJob() failed: %v", err)
+	}
+	if len(exec.calls) != 0 {
+		t.Errorf("expected no gcloud calls in a dry run, got %v", exec.calls)
+	}
+	if !strings.Contains(out.String(), `"taskGroups"`) {
+		t.Errorf("expected the job config to be printed, got:\n%s", out.String())
+	}
+}
+
+func TestSubmitJobRejectsKubernetesOptions(t *testing.T) {
+	exec := &mockExecutor{}
+	b := NewBatchOrchestrator()
+	b.SetExecutor(exec)
+	job := testJob()
+	job.Sidecars = []orchestrator.ContainerSpec{{Name: "tb", Image: "tensorboard"}}
+
+	if _, err := b.SubmitJob(context.Background(), job); err == nil || !strings.Contains(err.Error(), "--sidecar") {
+		t.Errorf("expected a --sidecar error, got %v", err)
+	}
+	if len(exec.calls) != 0 {
+		t.Errorf("expected nothing to be submitted, got %v", exec.calls)
+	}
+}
+
+func TestSubmitJobEnforcesManagedProfile(t *testing.T) {
+	exec := &mockExecutor{}
+	b := NewBatchOrchestrator()
+	b.SetExecutor(exec)
+	job := testJob()
+	job.NodesPerSlice = 4
+	job.ManagedProfile = &orchestrator.ManagedProfile{Name: "ml-research", MaxGPUs: 2, AllowedRegistries: []string{"us-docker.pkg.dev/ml-research"}}
+
+	_, err := b.SubmitJob(context.Background(), job)
+	if err == nil || !strings.Contains(err.Error(), `managed profile "ml-research"`) {
+		t.Fatalf("expected the managed profile to be enforced, got %v", err)
+	}
+	for _, want := range []string{"requests 4 GPUs, more than the allowed 2", "image repository us-docker.pkg.dev/p/r/train is not allowed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if len(exec.calls) != 0 {
+		t.Errorf("expected nothing to be submitted, got %v", exec.calls)
+	}
+}
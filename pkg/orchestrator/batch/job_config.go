@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/orchestrator/hosted"
+)
+
+// maxRetryCount is the most retries Cloud Batch allows a task.
+const maxRetryCount = 10
+
+// jobConfig is a Cloud Batch job, in the form 'gcloud batch jobs submit
+// --config' reads it.
+type jobConfig struct {
+	TaskGroups       []taskGroup       `json:"taskGroups"`
+	AllocationPolicy allocationPolicy  `json:"allocationPolicy"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	LogsPolicy       logsPolicy        `json:"logsPolicy"`
+}
+
+type taskGroup struct {
+	TaskCount        int      `json:"taskCount"`
+	Parallelism      int      `json:"parallelism"`
+	TaskCountPerNode int      `json:"taskCountPerNode"`
+	RequireHostsFile bool     `json:"requireHostsFile,omitempty"`
+	PermissiveSSH    bool     `json:"permissiveSsh,omitempty"`
+	TaskSpec         taskSpec `json:"taskSpec"`
+}
+
+type taskSpec struct {
+	Runnables     []runnable  `json:"runnables"`
+	Environment   environment `json:"environment"`
+	MaxRetryCount int         `json:"maxRetryCount,omitempty"`
+}
+
+type runnable struct {
+	Container container `json:"container"`
+}
+
+type container struct {
+	ImageURI   string   `json:"imageUri"`
+	Entrypoint string   `json:"entrypoint,omitempty"`
+	Commands   []string `json:"commands,omitempty"`
+}
+
+type environment struct {
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+type allocationPolicy struct {
+	Instances      []instancePolicyOrTemplate `json:"instances"`
+	ServiceAccount *serviceAccount            `json:"serviceAccount,omitempty"`
+}
+
+type instancePolicyOrTemplate struct {
+	Policy            instancePolicy `json:"policy"`
+	InstallGPUDrivers bool           `json:"installGpuDrivers,omitempty"`
+}
+
+type instancePolicy struct {
+	MachineType       string        `json:"machineType"`
+	ProvisioningModel string        `json:"provisioningModel,omitempty"`
+	Accelerators      []accelerator `json:"accelerators,omitempty"`
+}
+
+type accelerator struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+type serviceAccount struct {
+	Email string `json:"email"`
+}
+
+type logsPolicy struct {
+	Destination string `json:"destination"`
+}
+
+// resolveInstancePolicy maps the compute type of job, an accelerator
+// shorthand such as l4-1 or a machine type, to the VMs Cloud Batch creates.
+func resolveInstancePolicy(job orchestrator.JobDefinition) (instancePolicyOrTemplate, error) {
+	if job.ComputeType == "" {
+		return instancePolicyOrTemplate{}, fmt.Errorf("--compute-type is required with the batch backend, e.g. l4-1 or n2-standard-32")
+	}
+	machineType := config.ResolveMachineType(job.ComputeType)
+	if config.IsTPU(machineType) {
+		return instancePolicyOrTemplate{}, fmt.Errorf("TPU machine type %s is not supported by the batch backend; use the gke or vertex backend", machineType)
+	}
+	policy := instancePolicyOrTemplate{Policy: instancePolicy{MachineType: machineType}}
+	if job.Spot {
+		policy.Policy.ProvisioningModel = "SPOT"
+	}
+	accelType, count, err := hosted.GPUs(machineType, job.GPUsPerPod)
+	if err != nil {
+		return instancePolicyOrTemplate{}, err
+	}
+	if accelType != "" {
+		policy.Policy.Accelerators = []accelerator{{Type: accelType, Count: count}}
+		policy.InstallGPUDrivers = true
+	}
+	return policy, nil
+}
+
+// buildJobConfig translates job into a Cloud Batch job of one task group. It
+// runs a task on each of the NumSlices*NodesPerSlice VMs at once; tasks of a
+// multi-VM job find each other through the hosts file Cloud Batch writes.
+func buildJobConfig(job orchestrator.JobDefinition, image string) (jobConfig, error) {
+	instance, err := resolveInstancePolicy(job)
+	if err != nil {
+		return jobConfig{}, err
+	}
+	tasks := max(job.NumSlices, 1) * max(job.NodesPerSlice, 1)
+
+	c := container{ImageURI: image}
+	command, args := hosted.ContainerCommand(job.CommandToRun, job.CommandArgs)
+	if len(command) > 0 {
+		c.Entrypoint = command[0]
+		c.Commands = slices.Concat(command[1:], args)
+	}
+
+	cfg := jobConfig{
+		TaskGroups: []taskGroup{{
+			TaskCount:        tasks,
+			Parallelism:      tasks,
+			TaskCountPerNode: 1,
+			RequireHostsFile: tasks > 1,
+			PermissiveSSH:    tasks > 1,
+			TaskSpec: taskSpec{
+				Runnables:     []runnable{{Container: c}},
+				Environment:   environment{Variables: orchestrator.RunMetadataEnv(job)},
+				MaxRetryCount: min(job.MaxRestarts, maxRetryCount),
+			},
+		}},
+		AllocationPolicy: allocationPolicy{Instances: []instancePolicyOrTemplate{instance}},
+		Labels:           job.CostLabels,
+		LogsPolicy:       logsPolicy{Destination: "CLOUD_LOGGING"},
+	}
+	if strings.Contains(job.ServiceAccountName, "@") {
+		cfg.AllocationPolicy.ServiceAccount = &serviceAccount{Email: job.ServiceAccountName}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestResolveInstancePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     orchestrator.JobDefinition
+		want    instancePolicyOrTemplate
+		wantErr string
+	}{
+		{
+			name: "GPU shorthand",
+			job:  orchestrator.JobDefinition{ComputeType: "l4-2"},
+			want: instancePolicyOrTemplate{
+				Policy:            instancePolicy{MachineType: "g2-standard-24", Accelerators: []accelerator{{Type: "nvidia-l4", Count: 2}}},
+				InstallGPUDrivers: true,
+			},
+		},
+		{
+			name: "Spot CPU machine type",
+			job:  orchestrator.JobDefinition{ComputeType: "c2-standard-60", Spot: true},
+			want: instancePolicyOrTemplate{Policy: instancePolicy{MachineType: "c2-standard-60", ProvisioningModel: "SPOT"}},
+		},
+		{
+			name:    "TPU",
+			job:     orchestrator.JobDefinition{ComputeType: "v6e-4"},
+			wantErr: "not supported by the batch backend",
+		},
+		{
+			name:    "no compute type",
+			job:     orchestrator.JobDefinition{},
+			wantErr: "--compute-type is required",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveInstancePolicy(tc.job)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("resolveInstancePolicy() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveInstancePolicy() failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("resolveInstancePolicy() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildJobConfig(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		WorkloadName:       "train",
+		ComputeType:        "a3-highgpu-8g",
+		NumSlices:          2,
+		NodesPerSlice:      2,
+		MaxRestarts:        20,
+		CommandToRun:       "torchrun train.py",
+		ServiceAccountName: "trainer@my-project.iam.gserviceaccount.com",
+		CostLabels:         map[string]string{"team": "ml"},
+	}
+	cfg, err := buildJobConfig(job, "us-docker.pkg.dev/p/r/train:v1")
+	if err != nil {
+		t.Fatalf("buildJobConfig() failed: %v", err)
+	}
+
+	group := cfg.TaskGroups[0]
+	if group.TaskCount != 4 || group.Parallelism != 4 || group.TaskCountPerNode != 1 || !group.RequireHostsFile {
+		t.Errorf("expected 4 tasks on 4 VMs sharing a hosts file, got %+v", group)
+	}
+	c := group.TaskSpec.Runnables[0].Container
+	if c.ImageURI != "us-docker.pkg.dev/p/r/train:v1" || c.Entrypoint != "/bin/bash" || !reflect.DeepEqual(c.Commands, []string{"-c", "torchrun train.py"}) {
+		t.Errorf("unexpected container %+v", c)
+	}
+	if group.TaskSpec.MaxRetryCount != maxRetryCount {
+		t.Errorf("expected the retries to be capped at %d, got %d", maxRetryCount, group.TaskSpec.MaxRetryCount)
+	}
+	if got := group.TaskSpec.Environment.Variables["GCLUSTER_WORKLOAD_NAME"]; got != "train" {
+		t.Errorf("expected the run metadata in the environment, got %v", group.TaskSpec.Environment.Variables)
+	}
+	if accel := cfg.AllocationPolicy.Instances[0].Policy.Accelerators; len(accel) != 1 || accel[0] != (accelerator{Type: "nvidia-h100-80gb", Count: 8}) {
+		t.Errorf("unexpected accelerators %+v", accel)
+	}
+	if sa := cfg.AllocationPolicy.ServiceAccount; sa == nil || sa.Email != job.ServiceAccountName {
+		t.Errorf("unexpected service account %+v", sa)
+	}
+	if cfg.Labels["team"] != "ml" || cfg.LogsPolicy.Destination != "CLOUD_LOGGING" {
+		t.Errorf("unexpected labels %v or logs policy %+v", cfg.Labels, cfg.LogsPolicy)
+	}
+}
+
+func TestBuildJobConfigSingleTask(t *testing.T) {
+	job := orchestrator.JobDefinition{ComputeType: "n2-standard-8", CommandArgs: []string{"python", "main.py"}, ServiceAccountName: "default"}
+	cfg, err := buildJobConfig(job, "img")
+	if err != nil {
+		t.Fatalf("buildJobConfig() failed: %v", err)
+	}
+	group := cfg.TaskGroups[0]
+	if group.TaskCount != 1 || group.RequireHostsFile {
+		t.Errorf("expected a single task without a hosts file, got %+v", group)
+	}
+	if c := group.TaskSpec.Runnables[0].Container; c.Entrypoint != "python" || !reflect.DeepEqual(c.Commands, []string{"main.py"}) {
+		t.Errorf("expected the exec-form command to be kept, got %+v", c)
+	}
+	if cfg.AllocationPolicy.ServiceAccount != nil {
+		t.Errorf("expected no service account for a Kubernetes service account name, got %+v", cfg.AllocationPolicy.ServiceAccount)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/imagebuilder"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"slices"
+	"strings"
+)
+
+var imageArchitectures = imagebuilder.ImageArchitectures
+
+// armMachinePrefixes lists the Arm-based machine families, used when the
+// Compute API does not report an architecture for a machine type.
+var armMachinePrefixes = []string{"t2a-", "c4a-", "n4a-", "a4x-"}
+
+// machineArchitecture maps a machine type to its container architecture name.
+func machineArchitecture(machineType, reported string) string {
+	switch reported {
+	case "ARM64":
+		return "arm64"
+	case "X86_64":
+		return "amd64"
+	}
+	for _, prefix := range armMachinePrefixes {
+		if strings.HasPrefix(machineType, prefix) {
+			return "arm64"
+		}
+	}
+	return "amd64"
+}
+
+// validateImageArchitecture fails when the workload image cannot run on the
+// architecture of the node pools matching the requested machine type. Without
+// this check the mismatch only shows up as "exec format error" in pod logs.
+func (g *GKEOrchestrator) validateImageArchitecture(job orchestrator.JobDefinition) error {
+	if job.MachineType == "" || job.Pathways.Headless {
+		return nil
+	}
+
+	var imageArchs []string
+	var image string
+	switch {
+	case job.BaseImage != "":
+		image = job.BaseImage
+		for _, p := range strings.Split(job.Platform, ",") {
+			if _, arch, ok := strings.Cut(strings.TrimSpace(p), "/"); ok {
+				imageArchs = append(imageArchs, arch)
+			}
+		}
+	case job.ImageName != "":
+		image = job.ImageName
+		archs, err := imageArchitectures(job.ImageName)
+		if err != nil {
+			logging.Warn("Could not determine the architecture of image %s, skipping architecture check: %v", job.ImageName, err)
+			return nil
+		}
+		imageArchs = archs
+	}
+	if len(imageArchs) == 0 {
+		return nil
+	}
+
+	var reported string
+	if cap, err := g.FetchMachineCapabilities(job.MachineType, job.ClusterLocation); err == nil {
+		reported = cap.Architecture
+	}
+	nodeArch := machineArchitecture(job.MachineType, reported)
+	if slices.Contains(imageArchs, nodeArch) {
+		return nil
+	}
+
+	var pools []string
+	for _, np := range g.clusterDesc.NodePools {
+		if np.Config.MachineType == job.MachineType {
+			pools = append(pools, np.Name)
+		}
+	}
+	target := fmt.Sprintf("machine type %s", job.MachineType)
+	if len(pools) > 0 {
+		target = fmt.Sprintf("node pool(s) %s (%s)", strings.Join(pools, ", "), job.MachineType)
+	}
+
+	fix := fmt.Sprintf("rebuild the image for linux/%s", nodeArch)
+	if job.BaseImage != "" {
+		fix = fmt.Sprintf("pass --platform linux/%s", nodeArch)
+	}
+	return fmt.Errorf("image %s is built for %s but %s run %s nodes, so the container would fail with 'exec format error'. Either %s, or use --compute-type to target a %s node pool",
+		image, strings.Join(imageArchs, ", "), target, nodeArch, fix, strings.Join(imageArchs, "/"))
+}
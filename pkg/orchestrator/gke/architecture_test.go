@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestMachineArchitecture(t *testing.T) {
+	tests := []struct {
+		machineType, reported, want string
+	}{
+		{"n2-standard-8", "X86_64", "amd64"},
+		{"c4a-standard-8", "ARM64", "arm64"},
+		{"t2a-standard-4", "", "arm64"},
+		{"a4x-highgpu-4g", "", "arm64"},
+		{"g2-standard-8", "", "amd64"},
+	}
+	for _, tc := range tests {
+		if got := machineArchitecture(tc.machineType, tc.reported); got != tc.want {
+			t.Errorf("machineArchitecture(%q, %q) = %q, want %q", tc.machineType, tc.reported, got, tc.want)
+		}
+	}
+}
+
+func TestValidateImageArchitecture(t *testing.T) {
+	oldArchs := imageArchitectures
+	t.Cleanup(func() { imageArchitectures = oldArchs })
+
+	newOrc := func(arch string) *GKEOrchestrator {
+		g := NewGKEOrchestrator()
+		g.projectID = "test-project"
+		g.machineTypeClient = &MockMachineTypeClient{MT: &compute.MachineType{Architecture: arch}}
+		g.clusterDesc = gkeCluster{NodePools: []gkeJobNodePool{
+			{Name: "arm-pool", Config: gkeNodePoolConfig{MachineType: "c4a-standard-8"}},
+			{Name: "x86-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-8"}},
+		}}
+		return g
+	}
+
+	tests := []struct {
+		name       string
+		arch       string
+		job        orchestrator.JobDefinition
+		imageArchs []string
+		imageErr   error
+		wantErr    []string
+	}{
+		{
+			name: "base image platform matches",
+			arch: "X86_64",
+			job:  orchestrator.JobDefinition{MachineType: "n2-standard-8", BaseImage: "python:3.11", Platform: "linux/amd64"},
+		},
+		{
+			name:    "base image platform mismatch",
+			arch:    "ARM64",
+			job:     orchestrator.JobDefinition{MachineType: "c4a-standard-8", BaseImage: "python:3.11", Platform: "linux/amd64"},
+			wantErr: []string{"built for amd64", "arm-pool", "run arm64 nodes", "--platform linux/arm64"},
+		},
+		{
+			name: "multi-platform base image build",
+			arch: "ARM64",
+			job:  orchestrator.JobDefinition{MachineType: "c4a-standard-8", BaseImage: "python:3.11", Platform: "linux/amd64,linux/arm64"},
+		},
+		{
+			name:       "multi-arch image",
+			arch:       "ARM64",
+			job:        orchestrator.JobDefinition{MachineType: "c4a-standard-8", ImageName: "python:3.11"},
+			imageArchs: []string{"amd64", "arm64"},
+		},
+		{
+			name:       "prebuilt arm image on x86 pool",
+			arch:       "X86_64",
+			job:        orchestrator.JobDefinition{MachineType: "n2-standard-8", ImageName: "example.com/app:arm"},
+			imageArchs: []string{"arm64"},
+			wantErr:    []string{"built for arm64", "x86-pool", "rebuild the image for linux/amd64"},
+		},
+		{
+			name:     "unknown image architecture is skipped",
+			arch:     "X86_64",
+			job:      orchestrator.JobDefinition{MachineType: "n2-standard-8", ImageName: "example.com/app:arm"},
+			imageErr: fmt.Errorf("unauthorized"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			imageArchitectures = func(string) ([]string, error) { return tc.imageArchs, tc.imageErr }
+			tc.job.ClusterLocation = "us-central1-a"
+
+			err := newOrc(tc.arch).validateImageArchitecture(tc.job)
+			if len(tc.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected architecture mismatch error")
+			}
+			for _, want := range tc.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+var (
+	// attachPollInterval is how long a session waits before streaming the
+	// logs again when they end while the workload is still running, e.g.
+	// while its pods are pending or being restarted.
+	attachPollInterval = 15 * time.Second
+	// attachCheckpointInterval bounds how often the progress of a session is
+	// reported while logs are streamed.
+	attachCheckpointInterval = 5 * time.Second
+	// attachOutput receives the log lines of a session.
+	attachOutput io.Writer = os.Stdout
+)
+
+// jobSetState is what a session records of a JobSet between log streams.
+type jobSetState struct {
+	ResourceVersion string
+	Status          string
+	Terminal        bool
+}
+
+// AttachJob streams the logs of a workload, prefixed with their pod and
+// container, until the workload finishes, and returns its final status. A
+// session resumed from opts.Resume prints only the lines logged after its
+// checkpoint, so an interrupted session can be picked up where it stopped.
+func (g *GKEOrchestrator) AttachJob(ctx context.Context, name string, opts orchestrator.AttachOptions) (string, error) {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return "", err
+	}
+	ns, err := g.getJobNamespace(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	state, err := g.getJobSetState(ctx, ns, name)
+	if err != nil {
+		return "", err
+	}
+
+	s := &attachSession{cursors: map[string]time.Time{}, state: state, onCheckpoint: opts.OnCheckpoint}
+	if r := opts.Resume; r != nil {
+		maps.Copy(s.cursors, r.LogCursors)
+		if r.ResourceVersion != state.ResourceVersion && r.Status != state.Status {
+			logging.Info("Job '%s' changed from %s to %s while detached.", name, r.Status, state.Status)
+		}
+		if since, ok := s.since(); ok {
+			logging.Info("Resuming the session of job '%s' from %s.", name, since.Format(time.RFC3339))
+		}
+	}
+
+	for {
+		logging.Info("Streaming logs for job '%s' (%s)...", name, state.Status)
+		selector := fmt.Sprintf("gcluster.google.com/workload=%s", name)
+		if err := g.streamPodLogs(ctx, ns, selector, s.logOptions(opts.Since), s.printLine); err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			logging.Warn("The log stream of job '%s' ended: %v", name, err)
+		}
+		if state, err = g.getJobSetState(ctx, ns, name); err != nil {
+			return "", err
+		}
+		s.checkpoint(state)
+		if state.Terminal {
+			return state.Status, nil
+		}
+		g.sleep(attachPollInterval)
+	}
+}
+
+func (g *GKEOrchestrator) getJobSetState(ctx context.Context, ns, name string) (jobSetState, error) {
+	var js struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+		Spec struct {
+			Suspend bool `json:"suspend"`
+		} `json:"spec"`
+		Status struct {
+			TerminalState string `json:"terminalState"`
+		} `json:"status"`
+	}
+	if err := g.getResource(ctx, &js, jobSetGVR, ns, name); err != nil {
+		return jobSetState{}, err
+	}
+	state := jobSetState{ResourceVersion: js.Metadata.ResourceVersion, Status: "Running"}
+	switch {
+	case js.Status.TerminalState != "":
+		state.Status, state.Terminal = js.Status.TerminalState, true
+	case js.Spec.Suspend:
+		state.Status = "Suspended"
+	}
+	return state, nil
+}
+
+// attachSession tracks the log lines a session has printed.
+type attachSession struct {
+	cursors        map[string]time.Time
+	state          jobSetState
+	lastCheckpoint time.Time
+	onCheckpoint   func(orchestrator.AttachCheckpoint)
+}
+
+// since returns the time of the stream that is furthest behind, from which
+// the logs are fetched again.
+func (s *attachSession) since() (time.Time, bool) {
+	var since time.Time
+	for _, t := range s.cursors {
+		if since.IsZero() || t.Before(since) {
+			since = t
+		}
+	}
+	return since, !since.IsZero()
+}
+
+// logOptions follows the logs of every container with their timestamps, from
+// the checkpoint of the session or else from sinceDuration ago.
+func (s *attachSession) logOptions(sinceDuration string) podLogsOptions {
+	opts := podLogsOptions{follow: true, timestamps: true, prefix: true}
+	if since, ok := s.since(); ok {
+		opts.sinceTime = since
+	} else {
+		opts.since = sinceDuration
+	}
+	return opts
+}
+
+// printLine prints a "[pod/<pod>/<container>] <timestamp> <message>" log line
+// without its timestamp, unless its stream already printed it.
+func (s *attachSession) printLine(line string) {
+	stream, rest, ok := strings.Cut(strings.TrimPrefix(line, "["), "] ")
+	if !ok || !strings.HasPrefix(line, "[") {
+		fmt.Fprintln(attachOutput, line)
+		return
+	}
+	stamp, msg, _ := strings.Cut(rest, " ")
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		fmt.Fprintln(attachOutput, line)
+		return
+	}
+	if !t.After(s.cursors[stream]) {
+		return
+	}
+	s.cursors[stream] = t
+	fmt.Fprintf(attachOutput, "[%s] %s\n", stream, msg)
+	if time.Since(s.lastCheckpoint) >= attachCheckpointInterval {
+		s.checkpoint(s.state)
+	}
+}
+
+func (s *attachSession) checkpoint(state jobSetState) {
+	s.state = state
+	s.lastCheckpoint = time.Now()
+	if s.onCheckpoint == nil {
+		return
+	}
+	s.onCheckpoint(orchestrator.AttachCheckpoint{
+		LogCursors:      maps.Clone(s.cursors),
+		ResourceVersion: state.ResourceVersion,
+		Status:          state.Status,
+	})
+}
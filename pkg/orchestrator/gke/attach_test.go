@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAttachJob(t *testing.T) {
+	origOutput, origPoll := attachOutput, attachPollInterval
+	defer func() { attachOutput, attachPollInterval = origOutput, origPoll }()
+	var out bytes.Buffer
+	attachOutput = &out
+	attachPollInterval = 0
+
+	// The first streams end while the pods are restarted, the second when
+	// the JobSet has completed.
+	jobSets := []string{
+		`{"metadata": {"resourceVersion": "10"}, "status": {}}`,
+		`{"metadata": {"resourceVersion": "11"}, "status": {}}`,
+		`{"metadata": {"resourceVersion": "12"}, "status": {"terminalState": "Completed"}}`,
+	}
+	streams := []map[string]string{
+		{
+			"train-0/main": "2026-01-01T00:00:01.000000000Z already printed\n2026-01-01T00:00:02.000000000Z step 1\n",
+			"train-1/main": "2026-01-01T00:00:01.500000000Z worker up\n",
+		},
+		{
+			"train-0/main": "2026-01-01T00:00:02.000000000Z step 1\n2026-01-01T00:00:03.000000000Z done\n",
+			"train-1/main": "",
+		},
+	}
+	kube := &MockKubeClient{Namespace: "default", Logs: streams[0]}
+	dyn := newFakeDynamicClient(`
+apiVersion: v1
+kind: Pod
+metadata: {name: train-0, namespace: default, labels: {gcluster.google.com/workload: train}}
+spec: {containers: [{name: main}]}
+---
+apiVersion: v1
+kind: Pod
+metadata: {name: train-1, namespace: default, labels: {gcluster.google.com/workload: train}}
+spec: {containers: [{name: main}]}
+`)
+	gets := 0
+	dyn.PrependReactor("get", "jobsets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON([]byte(`{"apiVersion": "jobset.x-k8s.io/v1alpha2", "kind": "JobSet", ` + jobSets[gets][1:])); err != nil {
+			return true, nil, err
+		}
+		gets++
+		if gets == 2 {
+			kube.Logs = streams[1]
+		}
+		return true, obj, nil
+	})
+	orc := NewGKEOrchestrator()
+	orc.SetExecutor(&mockExecutor{})
+	orc.SetKubeClient(kube)
+	orc.dynClient = dyn
+
+	var checkpoints []orchestrator.AttachCheckpoint
+	status, err := orc.AttachJob(context.Background(), "train", orchestrator.AttachOptions{
+		Resume: &orchestrator.AttachCheckpoint{
+			LogCursors:      map[string]time.Time{"pod/train-0/main": time.Date(2026, 1, 1, 0, 0, 1, 0, time.UTC)},
+			ResourceVersion: "9",
+			Status:          "Suspended",
+		},
+		OnCheckpoint: func(cp orchestrator.AttachCheckpoint) { checkpoints = append(checkpoints, cp) },
+	})
+	if err != nil {
+		t.Fatalf("AttachJob() error = %v", err)
+	}
+	if status != "Completed" {
+		t.Errorf("status = %q, want Completed", status)
+	}
+	// The streams of the two pods are read concurrently.
+	printed := strings.Split(strings.TrimSpace(out.String()), "\n")
+	slices.Sort(printed)
+	want := []string{"[pod/train-0/main] done", "[pod/train-0/main] step 1", "[pod/train-1/main] worker up"}
+	if !slices.Equal(printed, want) {
+		t.Errorf("printed logs = %q, want %q", printed, want)
+	}
+	last := kube.LogOptions[len(kube.LogOptions)-1]
+	if !last.Follow || !last.Timestamps || last.SinceTime == nil || !last.SinceTime.Time.Equal(time.Date(2026, 1, 1, 0, 0, 1, 500000000, time.UTC)) {
+		t.Errorf("expected the second streams to resume from the stream furthest behind, got %+v", last)
+	}
+
+	lastCheckpoint := checkpoints[len(checkpoints)-1]
+	if lastCheckpoint.ResourceVersion != "12" || lastCheckpoint.Status != "Completed" {
+		t.Errorf("last checkpoint = %+v, want resource version 12 and status Completed", lastCheckpoint)
+	}
+	if got := lastCheckpoint.LogCursors["pod/train-0/main"]; !got.Equal(time.Date(2026, 1, 1, 0, 0, 3, 0, time.UTC)) {
+		t.Errorf("cursor of pod/train-0/main = %s", got)
+	}
+}
+
+func TestAttachSessionLogOptions(t *testing.T) {
+	s := &attachSession{cursors: map[string]time.Time{}}
+	opts := s.logOptions("10m")
+	if !opts.follow || !opts.timestamps || !opts.prefix || opts.since != "10m" || !opts.sinceTime.IsZero() {
+		t.Errorf("unexpected options for a new session: %+v", opts)
+	}
+}
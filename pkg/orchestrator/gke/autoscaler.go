@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// fingerprintLabelKeys are the ResourceLabels keys rendered as
+// gcluster.google.com/<key> pod labels, so that cluster-autoscaler events and
+// unschedulable pod dashboards can be filtered per run.
+var fingerprintLabelKeys = []string{"accelerator", "topology", "provisioning"}
+
+// resourceFingerprintLabels returns the requested accelerator, topology and
+// provisioning model of a job as label values. Unknown values are omitted.
+func resourceFingerprintLabels(job orchestrator.JobDefinition, topology string) map[string]string {
+	accelerator := job.ComputeType
+	if accelerator == "" {
+		accelerator = job.MachineType
+	}
+	provisioning := job.GKENAPProvisioning
+	if provisioning == "" && job.Spot {
+		provisioning = "spot"
+	}
+	if provisioning == "" {
+		provisioning = "default"
+	}
+
+	labels := map[string]string{}
+	for key, value := range map[string]string{"accelerator": accelerator, "topology": topology, "provisioning": provisioning} {
+		if v := sanitizeLabelValue(value); v != "" {
+			labels[key] = v
+		}
+	}
+	return labels
+}
+
+// sanitizeLabelValue lowercases value and replaces the characters a label
+// value may not contain with '-'.
+func sanitizeLabelValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(value))
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(value, "-_.")
+}
+
+// logAutoscalerEvents writes the requested resources of a workload's pods and
+// the cluster-autoscaler events about them, which explain why a scale-up was
+// or was not triggered.
+func (g *GKEOrchestrator) logAutoscalerEvents(ctx context.Context, w io.Writer, workloadName, ns string) {
+	_, _ = fmt.Fprintf(w, "Description: Autoscaler: Scale-up events for %s\n", workloadName)
+	defer func() { _, _ = fmt.Fprintf(w, "\n%s\n\n", spacer) }()
+
+	var pods podList
+	if err := g.listResources(ctx, &pods, podGVR, ns, metav1.ListOptions{LabelSelector: "gcluster.google.com/workload=" + workloadName}); err != nil {
+		_, _ = fmt.Fprintf(w, "Error listing pods: %v\n", err)
+		return
+	}
+	podNames := map[string]bool{}
+	for _, p := range pods.Items {
+		podNames[p.Metadata.Name] = true
+	}
+
+	_, _ = fmt.Fprint(w, "Output:\n")
+	if len(pods.Items) > 0 {
+		var requested []string
+		for _, key := range fingerprintLabelKeys {
+			if v := pods.Items[0].Metadata.Labels["gcluster.google.com/"+key]; v != "" {
+				requested = append(requested, key+"="+v)
+			}
+		}
+		if len(requested) > 0 {
+			_, _ = fmt.Fprintf(w, "Requested: %s\n", strings.Join(requested, ", "))
+		}
+	}
+
+	var events eventList
+	if err := g.listResources(ctx, &events, eventGVR, ns, metav1.ListOptions{FieldSelector: "source=cluster-autoscaler"}); err != nil {
+		_, _ = fmt.Fprintf(w, "Error listing autoscaler events: %v\n", err)
+		return
+	}
+
+	var lines []string
+	for _, e := range events.Items {
+		if e.InvolvedObject.Kind != "Pod" || !podNames[e.InvolvedObject.Name] {
+			continue
+		}
+		ts := e.LastTimestamp
+		if ts == "" {
+			ts = e.EventTime
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%s", ts, e.InvolvedObject.Name, e.Reason, e.Message))
+	}
+	if len(lines) == 0 {
+		_, _ = fmt.Fprintf(w, "No cluster-autoscaler events for the pods of %s.\n", workloadName)
+		return
+	}
+	sort.Strings(lines)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIME\tPOD\tREASON\tMESSAGE")
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(tw, line)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResourceFingerprintLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      orchestrator.JobDefinition
+		topology string
+		want     map[string]string
+	}{
+		{
+			name:     "tpu on spot",
+			job:      orchestrator.JobDefinition{ComputeType: "v5p-32", GKENAPProvisioning: "spot"},
+			topology: "2x4x4",
+			want:     map[string]string{"accelerator": "v5p-32", "topology": "2x4x4", "provisioning": "spot"},
+		},
+		{
+			name: "machine type fallback",
+			job:  orchestrator.JobDefinition{MachineType: "A3-HighGPU-8G"},
+			want: map[string]string{"accelerator": "a3-highgpu-8g", "provisioning": "default"},
+		},
+		{
+			name: "invalid characters",
+			job:  orchestrator.JobDefinition{ComputeType: "nvidia/h100:8", GKENAPProvisioning: "reservation"},
+			want: map[string]string{"accelerator": "nvidia-h100-8", "provisioning": "reservation"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resourceFingerprintLabels(tc.job, tc.topology); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("resourceFingerprintLabels() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	if got := sanitizeLabelValue("-" + strings.Repeat("a", 70)); len(got) != 62 || strings.HasPrefix(got, "-") {
+		t.Errorf("expected a trimmed value of at most 63 characters, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// BootstrapCluster prepares a cluster for gcluster workloads: it installs or
+// repairs JobSet and Kueue, creates a ResourceFlavor for each accelerator of
+// the node pools, and creates a ClusterQueue sized to the node pools with a
+// LocalQueue for it. Queues that already exist are left as they are.
+func (g *GKEOrchestrator) BootstrapCluster(ctx context.Context, opts orchestrator.BootstrapOptions) (orchestrator.BootstrapResult, error) {
+	if opts.LocalQueue == "" {
+		opts.LocalQueue = defaultLocalQueue
+	}
+	job := &orchestrator.JobDefinition{
+		ClusterProjectID: opts.ProjectID,
+		ClusterName:      opts.ClusterName,
+		ClusterLocation:  opts.ClusterLocation,
+	}
+	if err := g.populateClusterMetadata(job); err != nil {
+		return orchestrator.BootstrapResult{}, err
+	}
+	logging.Info("Configuring kubectl for GKE cluster '%s'...", job.ClusterName)
+	if err := g.configureKubectl(ctx, job.ClusterName, job.ClusterLocation, job.ClusterProjectID); err != nil {
+		return orchestrator.BootstrapResult{}, err
+	}
+	if err := g.checkClusterConnectivity(ctx); err != nil {
+		return orchestrator.BootstrapResult{}, err
+	}
+
+	if err := g.checkAndInstallJobSetCRD(ctx); err != nil {
+		return orchestrator.BootstrapResult{}, fmt.Errorf("failed to install JobSet: %w", err)
+	}
+	if err := g.CheckAndInstallKueue(ctx, opts.KueueVersion, job.ClusterName, job.ClusterLocation); err != nil {
+		return orchestrator.BootstrapResult{}, fmt.Errorf("failed to install Kueue: %w", err)
+	}
+	if err := g.ensurePriorityClassesInstalled(ctx); err != nil {
+		return orchestrator.BootstrapResult{}, err
+	}
+	if err := g.EnsureResourceFlavors(ctx); err != nil {
+		return orchestrator.BootstrapResult{}, err
+	}
+
+	result := orchestrator.BootstrapResult{LocalQueue: opts.LocalQueue}
+	result.KueueVersion, _ = g.GetKueueVersion(ctx)
+	for name := range g.capacity.Flavors {
+		result.ResourceFlavors = append(result.ResourceFlavors, name)
+	}
+	sort.Strings(result.ResourceFlavors)
+
+	exists, err := g.checkLocalQueueExists(ctx, opts.LocalQueue)
+	if err != nil {
+		return result, err
+	}
+	if !exists {
+		if err := g.createDefaultQueues(ctx, opts.LocalQueue); err != nil {
+			return result, err
+		}
+		result.ClusterQueue, result.CreatedQueues = defaultClusterQueue, true
+		return result, nil
+	}
+	logging.Info("LocalQueue '%s' already exists. Leaving the queues unchanged.", opts.LocalQueue)
+	if result.ClusterQueue, err = g.getClusterQueueName(ctx, opts.LocalQueue); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestBootstrapCluster(t *testing.T) {
+	setupMockMachineConfig(t)
+	exec := &mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "gcloud container clusters describe train"):
+			return shell.CommandResult{Stdout: `{"name": "train", "locations": ["us-central1-a"], "nodePools": [{"name": "l4", "config": {"machineType": "g2-standard-8", "accelerators": [{"acceleratorCount": "1", "acceleratorType": "nvidia-l4"}]}, "initialNodeCount": 2}]}`}
+		case strings.HasPrefix(cmd, "gcloud compute machine-types describe g2-standard-8"):
+			return shell.CommandResult{Stdout: `{"guestCpus": 8, "memoryMb": 32768, "accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-l4"}]}`}
+		}
+		return shell.CommandResult{}
+	}}
+	cluster := []string{
+		fakeObject("Namespace", "", "default", `{}`),
+		fakeObject("Endpoints", "jobset-system", "jobset-webhook-service", `{"subsets": [{"addresses": [{"ip": "10.0.0.1"}]}]}`),
+	}
+	opts := orchestrator.BootstrapOptions{ProjectID: "p", ClusterName: "train", ClusterLocation: "us-central1"}
+
+	t.Run("fresh cluster", func(t *testing.T) {
+		orc := newTestGKEOrchestrator(exec)
+		orc.dynClient = fakeKueueCluster("v0.15.2", cluster...)
+		result, err := orc.BootstrapCluster(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("BootstrapCluster() error = %v", err)
+		}
+		want := orchestrator.BootstrapResult{
+			KueueVersion:    "v0.15.2",
+			ClusterQueue:    "default-queue",
+			LocalQueue:      "multislice-queue",
+			ResourceFlavors: []string{"flavor-nvidia-l4"},
+			CreatedQueues:   true,
+		}
+		if !reflect.DeepEqual(result, want) {
+			t.Errorf("BootstrapCluster() = %+v, want %+v", result, want)
+		}
+		applied := strings.Join(orc.kubeClient.(*MockKubeClient).Applied, "---\n")
+		for _, want := range []string{"kind: ResourceFlavor", "name: flavor-nvidia-l4", "kind: ClusterQueue", "kind: LocalQueue"} {
+			if !strings.Contains(applied, want) {
+				t.Errorf("expected the applied manifests to contain %q, got:\n%s", want, applied)
+			}
+		}
+	})
+
+	t.Run("existing queues", func(t *testing.T) {
+		orc := newTestGKEOrchestrator(exec)
+		orc.dynClient = fakeKueueCluster("v0.15.2", append(cluster,
+			fakeObject("LocalQueue", "default", "multislice-queue", `{"spec": {"clusterQueue": "team-queue"}}`))...)
+		result, err := orc.BootstrapCluster(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("BootstrapCluster() error = %v", err)
+		}
+		if result.CreatedQueues || result.ClusterQueue != "team-queue" {
+			t.Errorf("expected the existing queues to be kept, got %+v", result)
+		}
+		applied := strings.Join(orc.kubeClient.(*MockKubeClient).Applied, "---\n")
+		if strings.Contains(applied, "kind: ClusterQueue") {
+			t.Errorf("expected the existing ClusterQueue to be left unchanged, got:\n%s", applied)
+		}
+	})
+}
@@ -0,0 +1,280 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// defaultBundleLogLines is the number of log lines kept per container when
+// BundleOptions.LogTailLines is not set.
+const defaultBundleLogLines = 500
+
+type bundleFile struct {
+	name    string
+	content string
+}
+
+type eventList struct {
+	Items []struct {
+		InvolvedObject struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"involvedObject"`
+		Type          string `json:"type"`
+		Reason        string `json:"reason"`
+		Message       string `json:"message"`
+		Count         int    `json:"count"`
+		LastTimestamp string `json:"lastTimestamp"`
+		EventTime     string `json:"eventTime"`
+	} `json:"items"`
+}
+
+type nodeConditions struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Status struct {
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// CreateBundle collects the manifest, pods, events, truncated logs and node
+// conditions of a workload into a gzipped tarball that can be attached to
+// bug reports. Sections that cannot be collected record the error instead of
+// failing the bundle.
+func (g *GKEOrchestrator) CreateBundle(ctx context.Context, name string, opts orchestrator.BundleOptions) (string, error) {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return "", err
+	}
+	ns, err := g.getJobNamespace(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	outPath := opts.OutputPath
+	if outPath == "" {
+		outPath = fmt.Sprintf("gcluster-bundle-%s-%s.tgz", name, g.now().UTC().Format("20060102-150405"))
+	}
+
+	logging.Info("Collecting support bundle for workload %s in namespace %s...", name, ns)
+	if err := writeBundle(outPath, name, g.collectBundle(ctx, name, ns, opts)); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
+
+func (g *GKEOrchestrator) collectBundle(ctx context.Context, name, ns string, opts orchestrator.BundleOptions) []bundleFile {
+	selector := "jobset.sigs.k8s.io/jobset-name=" + name
+	tail := opts.LogTailLines
+	if tail <= 0 {
+		tail = defaultBundleLogLines
+	}
+
+	return []bundleFile{
+		{name: "gcluster.txt", content: opts.ClientInfo},
+		{name: "manifest.yaml", content: g.bundleManifest(ctx, name, ns)},
+		{name: "pods.yaml", content: g.bundlePods(ctx, ns, selector)},
+		{name: "events.txt", content: g.bundleEvents(ctx, name, ns)},
+		{name: "logs.txt", content: g.bundleLogs(ctx, ns, selector, tail)},
+		{name: "nodes.txt", content: g.bundleNodeConditions(ctx, name, ns)},
+	}
+}
+
+// bundleManifest returns the JobSet as YAML; see redactedYAML.
+func (g *GKEOrchestrator) bundleManifest(ctx context.Context, name, ns string) string {
+	out, err := g.objectYAML(ctx, jobSetGVR, ns, name)
+	if err != nil {
+		return fmt.Sprintf("error getting jobset %s: %v", name, err)
+	}
+	return out
+}
+
+// bundlePods returns the pods matching selector, with their status, as YAML;
+// see redactedYAML.
+func (g *GKEOrchestrator) bundlePods(ctx context.Context, ns, selector string) string {
+	out, err := g.listYAML(ctx, podGVR, ns, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Sprintf("error listing pods: %v", err)
+	}
+	return out
+}
+
+// redactedYAML returns an object as YAML with managed fields dropped and
+// secret-looking env values redacted.
+func redactedYAML(obj map[string]interface{}) string {
+	if md, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(md, "managedFields")
+	}
+	redactEnv(obj)
+	out, err := k8syaml.Marshal(obj)
+	if err != nil {
+		return fmt.Sprintf("error encoding object: %v", err)
+	}
+	return string(out)
+}
+
+// redactEnv replaces the value of every container env var whose name looks
+// like a secret.
+func redactEnv(v interface{}) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if env, ok := node["env"].([]interface{}); ok {
+			for _, e := range env {
+				if ev, ok := e.(map[string]interface{}); ok {
+					if n, _ := ev["name"].(string); orchestrator.IsSensitiveName(n) && ev["value"] != nil {
+						ev["value"] = orchestrator.RedactedValue
+					}
+				}
+			}
+		}
+		for _, child := range node {
+			redactEnv(child)
+		}
+	case []interface{}:
+		for _, child := range node {
+			redactEnv(child)
+		}
+	}
+}
+
+// bundleEvents lists the events of the JobSet and of the Jobs and pods it
+// owns, which are all named after the workload.
+func (g *GKEOrchestrator) bundleEvents(ctx context.Context, name, ns string) string {
+	var list eventList
+	if err := g.listResources(ctx, &list, eventGVR, ns, metav1.ListOptions{}); err != nil {
+		return fmt.Sprintf("error getting events in namespace %s: %v", ns, err)
+	}
+
+	var lines []string
+	for _, e := range list.Items {
+		obj := e.InvolvedObject.Name
+		if obj != name && !strings.HasPrefix(obj, name+"-") {
+			continue
+		}
+		ts := e.LastTimestamp
+		if ts == "" {
+			ts = e.EventTime
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%s/%s (x%d): %s", ts, e.Type, e.Reason, e.InvolvedObject.Kind, obj, max(e.Count, 1), e.Message))
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("no events found for %s\n", name)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// bundleLogs returns the last lines of the logs of every container of the
+// pods matching selector, or the error that stopped them from being read.
+func (g *GKEOrchestrator) bundleLogs(ctx context.Context, ns, selector string, tail int) string {
+	var sb strings.Builder
+	if err := g.writePodLogs(ctx, &sb, ns, selector, podLogsOptions{tail: int64(tail), prefix: true}); err != nil {
+		fmt.Fprintf(&sb, "error getting logs: %v\n", err)
+	}
+	return sb.String()
+}
+
+// bundleNodeConditions reports the conditions of the nodes the workload's pods
+// are scheduled on.
+func (g *GKEOrchestrator) bundleNodeConditions(ctx context.Context, name, ns string) string {
+	pods, err := g.listWorkloadPods(ctx, ns, name)
+	if err != nil {
+		return fmt.Sprintf("error listing pods: %v", err)
+	}
+	seen := map[string]bool{}
+	var nodes []string
+	for _, p := range pods {
+		if p.Node != "" && !seen[p.Node] {
+			seen[p.Node] = true
+			nodes = append(nodes, p.Node)
+		}
+	}
+	if len(nodes) == 0 {
+		return "no pods of this workload are scheduled on a node\n"
+	}
+	sort.Strings(nodes)
+
+	var sb strings.Builder
+	for _, name := range nodes {
+		var n nodeConditions
+		if err := g.getResource(ctx, &n, nodeGVR, "", name); err != nil {
+			fmt.Fprintf(&sb, "%s\n  error: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s\n", n.Metadata.Name)
+		for _, c := range n.Status.Conditions {
+			fmt.Fprintf(&sb, "  %s=%s", c.Type, c.Status)
+			if c.Reason != "" {
+				fmt.Fprintf(&sb, " (%s)", c.Reason)
+			}
+			if c.Message != "" {
+				fmt.Fprintf(&sb, ": %s", c.Message)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func writeBundle(path, name string, files []bundleFile) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle %s: %w", path, err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range files {
+		hdr := &tar.Header{
+			Name:    name + "/" + file.name,
+			Mode:    0644,
+			Size:    int64(len(file.content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write %s to bundle: %w", file.name, err)
+		}
+		if _, err := tw.Write([]byte(file.content)); err != nil {
+			return fmt.Errorf("failed to write %s to bundle: %w", file.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize bundle %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finalize bundle %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"hpc-toolkit/pkg/orchestrator"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateBundle(t *testing.T) {
+	kube := &MockKubeClient{Namespace: "team-a", Logs: map[string]string{"train-main-job-0-0-a/workload-container": "step 10\n"}}
+	g := &GKEOrchestrator{executor: &mockExecutor{}, kubeClient: kube}
+	g.dynClient = newFakeDynamicClient(`
+apiVersion: jobset.x-k8s.io/v1alpha2
+kind: JobSet
+metadata:
+  name: train
+  namespace: team-a
+  managedFields: [{manager: gcluster}]
+spec:
+  template:
+    containers:
+    - env: [{name: HF_TOKEN, value: hf_abc}, {name: EPOCHS, value: "3"}]
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: train-main-job-0-0-a
+  namespace: team-a
+  labels: {jobset.sigs.k8s.io/jobset-name: train}
+spec:
+  nodeName: node-a
+  containers:
+  - name: workload-container
+    env: [{name: HF_TOKEN, value: hf_abc}, {name: EPOCHS, value: "3"}]
+---
+apiVersion: v1
+kind: Node
+metadata: {name: node-a}
+status:
+  conditions: [{type: Ready, status: "False", reason: KubeletNotReady, message: PLEG is not healthy}]
+`, fakeList("Event", "team-a", nil, `{"items":[
+		{"involvedObject":{"kind":"Pod","name":"train-main-job-0-0-a"},"type":"Warning","reason":"BackOff","message":"restarting","count":4,"lastTimestamp":"2026-01-02T00:00:00Z"},
+		{"involvedObject":{"kind":"Pod","name":"other-0"},"type":"Normal","reason":"Pulled","message":"pulled","lastTimestamp":"2026-01-01T00:00:00Z"}]}`))
+
+	out := filepath.Join(t.TempDir(), "bundle.tgz")
+	path, err := g.CreateBundle(context.Background(), "train", orchestrator.BundleOptions{OutputPath: out, LogTailLines: 20, ClientInfo: "gcluster version: v1.2.3\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != out {
+		t.Errorf("CreateBundle() path = %q, want %q", path, out)
+	}
+	if len(kube.LogOptions) != 1 || kube.LogOptions[0].TailLines == nil || *kube.LogOptions[0].TailLines != 20 {
+		t.Errorf("expected the last 20 lines of the logs, got %+v", kube.LogOptions)
+	}
+
+	files := readBundle(t, out)
+	checks := map[string][]string{
+		"train/gcluster.txt":  {"gcluster version: v1.2.3"},
+		"train/manifest.yaml": {"value: <redacted>", `value: "3"`},
+		"train/pods.yaml":     {"nodeName: node-a", "value: <redacted>", `value: "3"`},
+		"train/events.txt":    {"Warning\tBackOff\tPod/train-main-job-0-0-a (x4): restarting"},
+		"train/logs.txt":      {"[pod/train-main-job-0-0-a/workload-container] step 10"},
+		"train/nodes.txt":     {"node-a", "Ready=False (KubeletNotReady): PLEG is not healthy"},
+	}
+	for name, wants := range checks {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("bundle is missing %s", name)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %q:\n%s", name, want, content)
+			}
+		}
+	}
+	for name, content := range files {
+		if strings.Contains(content, "hf_abc") || strings.Contains(content, "managedFields") || strings.Contains(content, "other-0") {
+			t.Errorf("%s leaks unrelated or secret data:\n%s", name, content)
+		}
+	}
+}
+
+func readBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(b)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// commandScriptMountPath is the directory the ConfigMap of a command script
+// is mounted at.
+const commandScriptMountPath = "/gcluster/command-script"
+
+// addCommandScript renders the ConfigMap holding the command script of job
+// into the additional manifests, makes the container run it, and returns the
+// mount of the ConfigMap. It returns nil if the job has no command script.
+func addCommandScript(opts *ManifestOptions, job orchestrator.JobDefinition) (*MountInfo, error) {
+	if job.CommandScript == "" {
+		return nil, nil
+	}
+	file := path.Base(job.CommandScriptName)
+	if file == "." || file == "/" {
+		file = "run.sh"
+	}
+
+	name := opts.WorkloadName + "-command-script"
+	manifest, err := k8syaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]string{"gcluster.google.com/workload": opts.WorkloadName},
+		},
+		"data": map[string]string{file: job.CommandScript},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the command script ConfigMap: %w", err)
+	}
+	opts.AdditionalManifests = append(opts.AdditionalManifests, string(manifest))
+	opts.CommandToRun = ""
+	opts.CommandArgs = append(scriptInterpreter(job.CommandScript), path.Join(commandScriptMountPath, file))
+	return &MountInfo{
+		Name:      "command-script",
+		Source:    name,
+		MountPath: commandScriptMountPath,
+		Type:      mountTypeConfigMap,
+		ReadOnly:  true,
+	}, nil
+}
+
+// scriptInterpreter returns the interpreter and its arguments from the
+// shebang line of a script, or bash for a script without one. ConfigMap
+// files are not executable, so the script is passed to its interpreter.
+func scriptInterpreter(script string) []string {
+	first, _, _ := strings.Cut(script, "\n")
+	if shebang, ok := strings.CutPrefix(first, "#!"); ok {
+		if fields := strings.Fields(shebang); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"bash"}
+}
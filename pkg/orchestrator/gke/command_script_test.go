@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	corev1 "k8s.io/api/core/v1"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+func TestAddCommandScript(t *testing.T) {
+	script := "#!/usr/bin/env python3 -u\nimport sys\nprint(\"launch: \" + ' '.join(sys.argv))\n"
+	opts := ManifestOptions{WorkloadName: "train", CommandToRun: "ignored"}
+	mount, err := addCommandScript(&opts, orchestrator.JobDefinition{CommandScript: script, CommandScriptName: "launch.py"})
+	if err != nil {
+		t.Fatalf("addCommandScript() error = %v", err)
+	}
+	if want := []string{"/usr/bin/env", "python3", "-u", "/gcluster/command-script/launch.py"}; !reflect.DeepEqual(opts.CommandArgs, want) || opts.CommandToRun != "" {
+		t.Errorf("expected command %v, got %v (%q)", want, opts.CommandArgs, opts.CommandToRun)
+	}
+	if len(opts.AdditionalManifests) != 1 {
+		t.Fatalf("expected the ConfigMap manifest, got %v", opts.AdditionalManifests)
+	}
+	var cm corev1.ConfigMap
+	if err := k8syaml.UnmarshalStrict([]byte(opts.AdditionalManifests[0]), &cm); err != nil {
+		t.Fatalf("failed to decode the ConfigMap: %v", err)
+	}
+	if cm.Name != "train-command-script" || cm.Labels["gcluster.google.com/workload"] != "train" || cm.Data["launch.py"] != script {
+		t.Errorf("unexpected ConfigMap %+v", cm)
+	}
+	want := MountInfo{Name: "command-script", Source: "train-command-script", MountPath: "/gcluster/command-script", Type: mountTypeConfigMap, ReadOnly: true}
+	if mount == nil || *mount != want {
+		t.Errorf("addCommandScript() mount = %+v, want %+v", mount, want)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	if _, err := addCommandScript(&opts, orchestrator.JobDefinition{CommandScript: "cd /app\npython train.py\n", CommandScriptName: "run.sh"}); err != nil {
+		t.Fatalf("addCommandScript() error = %v", err)
+	}
+	if want := []string{"bash", "/gcluster/command-script/run.sh"}; !reflect.DeepEqual(opts.CommandArgs, want) {
+		t.Errorf("expected a script without a shebang to run with bash, got %v", opts.CommandArgs)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	if mount, err := addCommandScript(&opts, orchestrator.JobDefinition{}); mount != nil || err != nil || len(opts.AdditionalManifests) != 0 {
+		t.Errorf("expected no changes without a command script, got %+v, %v", mount, err)
+	}
+}
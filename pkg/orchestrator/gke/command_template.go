@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"regexp"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+var commandTemplateVarRegex = regexp.MustCompile(`\{\{\s*\.(\w+)\s*\}\}`)
+
+// expandCommandTemplate replaces the template variables of the command, such
+// as {{.PodIndex}}, with references to the container environment, which
+// Kubernetes expands when the container starts for shell and exec-form
+// commands alike. Unknown variables are left as they are, so that commands
+// passing templates to other tools keep working.
+func expandCommandTemplate(opts *ManifestOptions, job orchestrator.JobDefinition) error {
+	vars := map[string]string{
+		"JobIndex":      "$(" + frameworkJobIndexEnv + ")",
+		"PodIndex":      "$(JOB_COMPLETION_INDEX)",
+		"NumSlices":     "$(" + orchestrator.EnvNumSlices + ")",
+		"NodesPerSlice": "$(" + orchestrator.EnvVMsPerSlice + ")",
+		"WorkloadName":  "$(" + orchestrator.EnvWorkloadName + ")",
+	}
+	kind := job.WorkloadKind
+	if kind == "" {
+		kind = orchestrator.WorkloadKindJobSet
+	}
+	if kind == orchestrator.WorkloadKindJob {
+		// A single Job has no replicated jobs to index.
+		vars["JobIndex"] = "0"
+	}
+
+	var used []string
+	expand := func(s string) string {
+		return commandTemplateVarRegex.ReplaceAllStringFunc(s, func(m string) string {
+			name := commandTemplateVarRegex.FindStringSubmatch(m)[1]
+			v, ok := vars[name]
+			if !ok {
+				return m
+			}
+			used = append(used, name)
+			return v
+		})
+	}
+	command := expand(opts.CommandToRun)
+	args := make([]string, len(opts.CommandArgs))
+	for i, a := range opts.CommandArgs {
+		args[i] = expand(a)
+	}
+	if len(used) == 0 {
+		return nil
+	}
+	if job.IsPathwaysJob || (kind != orchestrator.WorkloadKindJobSet && kind != orchestrator.WorkloadKindJob) {
+		return fmt.Errorf("command template variable {{.%s}} requires --workload-kind jobset or job and cannot be used with --pathways", used[0])
+	}
+
+	opts.CommandToRun = command
+	if len(opts.CommandArgs) > 0 {
+		opts.CommandArgs = args
+	}
+	if kind == orchestrator.WorkloadKindJobSet {
+		if opts.FieldEnv == nil {
+			opts.FieldEnv = map[string]string{}
+		}
+		opts.FieldEnv[frameworkJobIndexEnv] = jobIndexFieldPath
+	}
+	return nil
+}
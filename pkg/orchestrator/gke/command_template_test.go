@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestExpandCommandTemplate(t *testing.T) {
+	opts := ManifestOptions{CommandToRun: "python shard.py --shard {{.PodIndex}}/{{ .NodesPerSlice }} --slice {{.JobIndex}} --fmt '{{.ID}}'"}
+	if err := expandCommandTemplate(&opts, orchestrator.JobDefinition{WorkloadKind: orchestrator.WorkloadKindJobSet}); err != nil {
+		t.Fatalf("expandCommandTemplate() error = %v", err)
+	}
+	want := "python shard.py --shard $(JOB_COMPLETION_INDEX)/$(GCLUSTER_VMS_PER_SLICE) --slice $(JOB_INDEX) --fmt '{{.ID}}'"
+	if opts.CommandToRun != want {
+		t.Errorf("command = %q, want %q", opts.CommandToRun, want)
+	}
+	if opts.FieldEnv["JOB_INDEX"] != jobIndexFieldPath {
+		t.Errorf("expected JOB_INDEX from the downward API, got %v", opts.FieldEnv)
+	}
+
+	opts = ManifestOptions{CommandArgs: []string{"python", "shard.py", "--slices={{.NumSlices}}", "--slice={{.JobIndex}}"}}
+	if err := expandCommandTemplate(&opts, orchestrator.JobDefinition{WorkloadKind: orchestrator.WorkloadKindJob}); err != nil {
+		t.Fatalf("expandCommandTemplate() error = %v", err)
+	}
+	if want := []string{"python", "shard.py", "--slices=$(GCLUSTER_NUM_SLICES)", "--slice=0"}; !reflect.DeepEqual(opts.CommandArgs, want) {
+		t.Errorf("args = %q, want %q", opts.CommandArgs, want)
+	}
+	if opts.FieldEnv != nil {
+		t.Errorf("expected no downward API variables for a Job, got %v", opts.FieldEnv)
+	}
+
+	opts = ManifestOptions{CommandToRun: "echo {{.ID}}"}
+	if err := expandCommandTemplate(&opts, orchestrator.JobDefinition{WorkloadKind: orchestrator.WorkloadKindDeployment}); err != nil || opts.CommandToRun != "echo {{.ID}}" {
+		t.Errorf("expected a command without variables to be left alone, got %q, %v", opts.CommandToRun, err)
+	}
+
+	opts = ManifestOptions{CommandToRun: "echo {{.PodIndex}}"}
+	if err := expandCommandTemplate(&opts, orchestrator.JobDefinition{WorkloadKind: orchestrator.WorkloadKindDeployment}); err == nil || !strings.Contains(err.Error(), "{{.PodIndex}} requires --workload-kind jobset or job") {
+		t.Errorf("expected variables to be rejected for a deployment, got %v", err)
+	}
+	opts = ManifestOptions{CommandToRun: "echo {{.PodIndex}}"}
+	if err := expandCommandTemplate(&opts, orchestrator.JobDefinition{IsPathwaysJob: true}); err == nil {
+		t.Error("expected variables to be rejected for Pathways")
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/retry"
+	"hpc-toolkit/pkg/versioncheck"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Cluster components gcluster depends on.
+const (
+	componentGCSFuseCSI      = "gcsfuse-csi-driver"
+	componentFilestoreCSI    = "filestore-csi-driver"
+	componentGPUDevicePlugin = "nvidia-gpu-device-plugin"
+	componentTPUDevicePlugin = "tpu-device-plugin"
+	componentJobSet          = "jobset"
+	componentKueue           = "kueue"
+)
+
+// componentHealthRetry polls a controller for up to a minute for its health
+// check before it is upgraded.
+var componentHealthRetry = retry.Policy{Attempts: 12, Initial: 5 * time.Second, Multiplier: 1}
+
+// UpgradeComponents inventories the components gcluster depends on in a
+// cluster, compares them with the versions gcluster is tested with and
+// upgrades the JobSet and Kueue controllers that are behind, in place and in
+// order. A controller is only upgraded when it is healthy, and the upgrade
+// waits for it to serve again at the new version before the next one starts.
+// GKE add-ons and device plugins are reported but left to GKE.
+func (g *GKEOrchestrator) UpgradeComponents(ctx context.Context, opts orchestrator.ComponentsUpgradeOptions) (orchestrator.ComponentsUpgradeResult, error) {
+	job := &orchestrator.JobDefinition{
+		ClusterProjectID: opts.ProjectID,
+		ClusterName:      opts.ClusterName,
+		ClusterLocation:  opts.ClusterLocation,
+	}
+	if err := g.populateClusterMetadata(job); err != nil {
+		return orchestrator.ComponentsUpgradeResult{}, err
+	}
+	logging.Info("Configuring kubectl for GKE cluster '%s'...", job.ClusterName)
+	if err := g.configureKubectl(ctx, job.ClusterName, job.ClusterLocation, job.ClusterProjectID); err != nil {
+		return orchestrator.ComponentsUpgradeResult{}, err
+	}
+	if err := g.checkClusterConnectivity(ctx); err != nil {
+		return orchestrator.ComponentsUpgradeResult{}, err
+	}
+
+	result := orchestrator.ComponentsUpgradeResult{Components: g.inventoryComponents(ctx, *job)}
+	if opts.DryRun {
+		return result, nil
+	}
+	for i, c := range result.Components {
+		if c.Action != orchestrator.ComponentActionUpgrade {
+			continue
+		}
+		upgraded, err := g.upgradeComponent(ctx, c)
+		if err != nil {
+			return result, fmt.Errorf("failed to upgrade %s from %s to %s; components after it were not upgraded: %w", c.Name, c.Version, c.Tested, err)
+		}
+		result.Components[i] = upgraded
+		result.Upgraded = append(result.Upgraded, c.Name)
+	}
+	return result, nil
+}
+
+// inventoryComponents returns the components of the cluster of job in the
+// order they are upgraded: the add-ons and device plugins GKE manages, then
+// JobSet and finally Kueue, which integrates with JobSet.
+func (g *GKEOrchestrator) inventoryComponents(ctx context.Context, job orchestrator.JobDefinition) []orchestrator.ComponentStatus {
+	components := g.addonComponents(job)
+	components = append(components, g.devicePluginComponents(ctx)...)
+	return append(components, g.jobSetComponent(ctx), g.kueueComponent(ctx))
+}
+
+// addonComponents reports the CSI drivers of the cluster, which are GKE
+// add-ons. Autopilot clusters always run them.
+func (g *GKEOrchestrator) addonComponents(job orchestrator.JobDefinition) []orchestrator.ComponentStatus {
+	addons := []struct {
+		name, addon string
+		enabled     bool
+	}{
+		{componentGCSFuseCSI, "GcsFuseCsiDriver", g.clusterDesc.AddonsConfig.GcsFuseCsiDriverConfig.Enabled},
+		{componentFilestoreCSI, "GcpFilestoreCsiDriver", g.clusterDesc.AddonsConfig.GcpFilestoreCsiDriverConfig.Enabled},
+	}
+	var components []orchestrator.ComponentStatus
+	for _, a := range addons {
+		c := orchestrator.ComponentStatus{Name: a.name, Version: "enabled", Healthy: true, Action: orchestrator.ComponentActionNone, Note: "managed by GKE"}
+		if !a.enabled && !g.clusterDesc.Autopilot.Enabled {
+			c.Version, c.Healthy, c.Action = "disabled", false, orchestrator.ComponentActionManual
+			c.Note = fmt.Sprintf("mounts that need it fail; enable it with 'gcloud container clusters update %s --location %s --project %s --update-addons %s=ENABLED'",
+				job.ClusterName, job.ClusterLocation, job.ClusterProjectID, a.addon)
+		}
+		components = append(components, c)
+	}
+	return components
+}
+
+type daemonSetList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Image string `json:"image"`
+					} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+		Status struct {
+			DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+			NumberReady            int `json:"numberReady"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// devicePluginComponents reports the GPU and TPU device plugins GKE runs on
+// accelerator nodes. A plugin without any DaemonSet, as on clusters without
+// that accelerator, is left out.
+func (g *GKEOrchestrator) devicePluginComponents(ctx context.Context) []orchestrator.ComponentStatus {
+	var list daemonSetList
+	if err := g.listResources(ctx, &list, daemonSetGVR, "kube-system", metav1.ListOptions{}); err != nil {
+		logging.Warn("Failed to list the device plugins of the cluster: %v", err)
+		return nil
+	}
+
+	var components []orchestrator.ComponentStatus
+	for _, name := range []string{componentGPUDevicePlugin, componentTPUDevicePlugin} {
+		var found, ready, desired int
+		c := orchestrator.ComponentStatus{Name: name, Action: orchestrator.ComponentActionNone}
+		for _, ds := range list.Items {
+			if !strings.HasPrefix(ds.Metadata.Name, name) {
+				continue
+			}
+			found++
+			ready += ds.Status.NumberReady
+			desired += ds.Status.DesiredNumberScheduled
+			if containers := ds.Spec.Template.Spec.Containers; c.Version == "" && len(containers) > 0 {
+				c.Version = imageTag(containers[0].Image)
+			}
+		}
+		if found == 0 {
+			continue
+		}
+		c.Healthy = ready == desired
+		c.Note = "managed by GKE; upgraded with the node pools"
+		if !c.Healthy {
+			c.Note = fmt.Sprintf("%d of %d pods ready; %s", ready, desired, c.Note)
+		}
+		components = append(components, c)
+	}
+	return components
+}
+
+// imageTag returns the tag of image, or "unknown" when it has none.
+func imageTag(image string) string {
+	tag, err := parseImageTag(image)
+	if err != nil {
+		return "unknown"
+	}
+	return tag
+}
+
+// parseImageTag returns the tag of the image reference image, such as
+// "v0.6.3" for "registry.k8s.io/kueue/kueue:v0.6.3" or for the same image
+// pinned by digest.
+func parseImageTag(image string) (string, error) {
+	if _, err := name.ParseReference(image); err != nil {
+		return "", err
+	}
+	// A digest reference keeps the tag only in its name.
+	base, _, _ := strings.Cut(image, "@")
+	tag, err := name.NewTag(base)
+	if err != nil || !strings.HasSuffix(base, ":"+tag.TagStr()) {
+		return "", fmt.Errorf("image %s has no tag", image)
+	}
+	return tag.TagStr(), nil
+}
+
+func (g *GKEOrchestrator) jobSetComponent(ctx context.Context) orchestrator.ComponentStatus {
+	version, _ := g.GetJobSetVersion(ctx)
+	return g.controllerComponent(ctx, componentJobSet, version, defaultJobSetVersion, "jobset-system", "jobset-controller-manager")
+}
+
+func (g *GKEOrchestrator) kueueComponent(ctx context.Context) orchestrator.ComponentStatus {
+	version, _ := g.GetKueueVersion(ctx)
+	c := g.controllerComponent(ctx, componentKueue, version, defaultKueueVersion, "kueue-system", "kueue-controller-manager")
+	if c.Action != orchestrator.ComponentActionUpgrade {
+		return c
+	}
+	if len(versioncheck.CheckComponent(componentKueue, version)) > 0 {
+		c.Action = orchestrator.ComponentActionManual
+		c.Note = "older than the Kueue API gcluster uses, which an in-place upgrade cannot migrate; reinstall it with 'gcluster cluster bootstrap', which deletes queued workloads"
+	} else if g.checkDynamicSlicingViaGKE() {
+		c.Action = orchestrator.ComponentActionManual
+		c.Note = "the cluster is set up for dynamic slicing with a custom Kueue configuration, which an upgrade would overwrite; upgrade Kueue manually"
+	}
+	return c
+}
+
+// controllerComponent reports a controller gcluster installs, and plans an
+// in-place upgrade when it is healthy and older than tested.
+func (g *GKEOrchestrator) controllerComponent(ctx context.Context, name, version, tested, namespace, deployment string) orchestrator.ComponentStatus {
+	c := orchestrator.ComponentStatus{Name: name, Version: version, Tested: tested, Action: orchestrator.ComponentActionNone}
+	if version == "" {
+		c.Action = orchestrator.ComponentActionManual
+		c.Note = "not installed; install it with 'gcluster cluster bootstrap'"
+		return c
+	}
+	c.Healthy = g.deploymentHealthy(ctx, namespace, deployment)
+	switch cmp := versioncheck.Compare(version, tested); {
+	case cmp > 0:
+		c.Note = "newer than the tested version"
+	case cmp < 0 && c.Healthy:
+		c.Action = orchestrator.ComponentActionUpgrade
+	case cmp < 0:
+		c.Action = orchestrator.ComponentActionManual
+		c.Note = "unhealthy, so it is not upgraded; repair it with 'gcluster cluster bootstrap' first"
+	}
+	if !c.Healthy && c.Note == "" {
+		c.Note = "unhealthy; repair it with 'gcluster cluster bootstrap'"
+	}
+	return c
+}
+
+// deploymentHealthy reports whether every replica of a deployment is
+// rolled out and available.
+func (g *GKEOrchestrator) deploymentHealthy(ctx context.Context, namespace, deployment string) bool {
+	return g.waitForRollout(ctx, namespace, deployment, componentHealthRetry) == nil
+}
+
+// upgradeComponent applies the tested release of c, waits for its webhook to
+// serve and checks that the controller runs the new version.
+func (g *GKEOrchestrator) upgradeComponent(ctx context.Context, c orchestrator.ComponentStatus) (orchestrator.ComponentStatus, error) {
+	logging.Info("Upgrading %s from %s to %s...", c.Name, c.Version, c.Tested)
+	var upgraded orchestrator.ComponentStatus
+	switch c.Name {
+	case componentJobSet:
+		if err := g.installJobSetCRD(ctx, jobSetManifestsURL(c.Tested)); err != nil {
+			return c, err
+		}
+		upgraded = g.jobSetComponent(ctx)
+	case componentKueue:
+		if err := g.applyKueueRelease(ctx, c.Tested); err != nil {
+			return c, err
+		}
+		upgraded = g.kueueComponent(ctx)
+	default:
+		return c, fmt.Errorf("gcluster does not upgrade %s", c.Name)
+	}
+	if upgraded.Version != c.Tested {
+		return upgraded, fmt.Errorf("post-upgrade check failed: the controller runs version %q instead of %s", upgraded.Version, c.Tested)
+	}
+	if !upgraded.Healthy {
+		return upgraded, fmt.Errorf("post-upgrade check failed: the controller is not healthy")
+	}
+	logging.Info("%s upgraded to %s.", c.Name, c.Tested)
+	return upgraded, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+	"hpc-toolkit/pkg/testutil"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestUpgradeComponents(t *testing.T) {
+	var stuck bool
+	exec := &mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "gcloud container clusters describe train"):
+			return shell.CommandResult{Stdout: `{"name": "train", "locations": ["us-central1-a"], "addonsConfig": {"gcsFuseCsiDriverConfig": {"enabled": true}}}`}
+		}
+		return shell.CommandResult{}
+	}}
+	opts := orchestrator.ComponentsUpgradeOptions{ProjectID: "p", ClusterName: "train", ClusterLocation: "us-central1"}
+	jobSetURL := jobSetManifestsURL(defaultJobSetVersion)
+
+	newOrchestrator := func(t *testing.T) (*GKEOrchestrator, *testutil.FakeTransport) {
+		t.Setenv("HOME", t.TempDir())
+		stuck = false
+		orc := newTestGKEOrchestrator(exec)
+		client := fakeKueueCluster(defaultKueueVersion,
+			fakeObject("Namespace", "", "default", `{}`),
+			fakeList("DaemonSet", "kube-system", nil, `{"items": [
+				{"metadata": {"name": "nvidia-gpu-device-plugin-small-cos"}, "spec": {"template": {"spec": {"containers": [{"name": "plugin", "image": "gke.gcr.io/nvidia-gpu-device-plugin:v1.2.3"}]}}}, "status": {"desiredNumberScheduled": 2, "numberReady": 2}},
+				{"metadata": {"name": "kube-proxy"}, "spec": {"template": {"spec": {"containers": [{"name": "kube-proxy", "image": "gke.gcr.io/kube-proxy:v1.31"}]}}}, "status": {"desiredNumberScheduled": 2, "numberReady": 2}}]}`),
+			fakeList("EndpointSlice", "jobset-system", map[string]string{"kubernetes.io/service-name": "jobset-webhook-service"},
+				`{"items": [{"endpoints": [{"addresses": ["10.0.0.2"], "conditions": {"ready": true}}]}]}`),
+		)
+		// The JobSet controller runs the tested version once its release is
+		// applied, unless it is stuck.
+		client.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.(k8stesting.GetAction).GetName() != "jobset-controller-manager" {
+				return false, nil, nil
+			}
+			version := "v0.8.0"
+			if len(orc.kubeClient.(*MockKubeClient).Applied) > 0 && !stuck {
+				version = defaultJobSetVersion
+			}
+			obj := &unstructured.Unstructured{}
+			err := obj.UnmarshalJSON([]byte(fakeDeployment("jobset-system", "jobset-controller-manager", "registry.k8s.io/jobset/jobset:"+version)))
+			return true, obj, err
+		})
+		orc.dynClient = client
+		release := testutil.NewFakeTransport(map[string]testutil.HTTPResponse{
+			jobSetURL: {Body: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: jobset-system\n"},
+		})
+		orc.SetHTTPClient(release.Client())
+		orc.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+		return orc, release
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		orc, release := newOrchestrator(t)
+		result, err := orc.UpgradeComponents(context.Background(), orchestrator.ComponentsUpgradeOptions{ProjectID: "p", ClusterName: "train", ClusterLocation: "us-central1", DryRun: true})
+		if err != nil {
+			t.Fatalf("UpgradeComponents() error = %v", err)
+		}
+		var names, actions []string
+		for _, c := range result.Components {
+			names = append(names, c.Name)
+			actions = append(actions, c.Action)
+		}
+		if want := []string{componentGCSFuseCSI, componentFilestoreCSI, componentGPUDevicePlugin, componentJobSet, componentKueue}; !reflect.DeepEqual(names, want) {
+			t.Errorf("components = %v, want %v", names, want)
+		}
+		if want := []string{"none", "manual", "none", "upgrade", "none"}; !reflect.DeepEqual(actions, want) {
+			t.Errorf("actions = %v, want %v", actions, want)
+		}
+		if c := result.Components[1]; !strings.Contains(c.Note, "--update-addons GcpFilestoreCsiDriver=ENABLED") {
+			t.Errorf("expected the disabled Filestore driver to explain how to enable it, got %+v", c)
+		}
+		if c := result.Components[2]; c.Version != "v1.2.3" || !c.Healthy {
+			t.Errorf("expected the healthy GPU device plugin v1.2.3, got %+v", c)
+		}
+		if len(result.Upgraded) != 0 || len(release.Requests()) != 0 {
+			t.Errorf("expected a dry run to change nothing, upgraded %v, requests %v", result.Upgraded, release.Requests())
+		}
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		orc, release := newOrchestrator(t)
+		result, err := orc.UpgradeComponents(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("UpgradeComponents() error = %v", err)
+		}
+		if !reflect.DeepEqual(result.Upgraded, []string{componentJobSet}) {
+			t.Errorf("Upgraded = %v, want [jobset]", result.Upgraded)
+		}
+		if c := result.Components[3]; c.Version != defaultJobSetVersion || c.Action != orchestrator.ComponentActionNone {
+			t.Errorf("expected JobSet to report its new version, got %+v", c)
+		}
+		if got := release.Requests(); !reflect.DeepEqual(got, []string{"GET " + jobSetURL}) {
+			t.Errorf("requests = %v, want the JobSet release only", got)
+		}
+		if applied := orc.kubeClient.(*MockKubeClient).Applied; len(applied) != 1 || !strings.Contains(applied[0], "jobset-system") {
+			t.Errorf("expected the JobSet release to be applied in place, got %q", applied)
+		}
+	})
+
+	t.Run("post-upgrade check fails", func(t *testing.T) {
+		orc, _ := newOrchestrator(t)
+		stuck = true
+		_, err := orc.UpgradeComponents(context.Background(), opts)
+		if err == nil || !strings.Contains(err.Error(), `runs version "v0.8.0" instead of `+defaultJobSetVersion) || !strings.Contains(err.Error(), "components after it were not upgraded") {
+			t.Errorf("expected the post-upgrade version check to fail, got %v", err)
+		}
+	})
+}
+
+func TestControllerComponent_Unhealthy(t *testing.T) {
+	orc := newTestGKEOrchestrator(&mockExecutor{})
+	orc.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	orc.dynClient = newFakeDynamicClient(fakeObject("Deployment", "jobset-system", "jobset-controller-manager", `{
+		"metadata": {"generation": 2},
+		"spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "manager", "image": "registry.k8s.io/jobset/jobset:v0.8.0"}]}}},
+		"status": {"observedGeneration": 2, "replicas": 1, "updatedReplicas": 1, "availableReplicas": 0}
+	}`))
+	c := orc.controllerComponent(context.Background(), componentJobSet, "v0.8.0", defaultJobSetVersion, "jobset-system", "jobset-controller-manager")
+	if c.Healthy || c.Action != orchestrator.ComponentActionManual || !strings.Contains(c.Note, "not upgraded") {
+		t.Errorf("expected an unhealthy controller not to be upgraded, got %+v", c)
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	for image, want := range map[string]string{
+		"registry.k8s.io/kueue/kueue:v0.15.2":                       "v0.15.2",
+		"localhost:5000/jobset":                                     "unknown",
+		"gke.gcr.io/plugin:v1.2@sha256:" + strings.Repeat("ab", 32): "v1.2",
+		"gke.gcr.io/plugin@sha256:" + strings.Repeat("ab", 32):      "unknown",
+		"not a reference":                                           "unknown",
+	} {
+		if got := imageTag(image); got != want {
+			t.Errorf("imageTag(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// discoverComputeType sets the compute type of a job submitted without one
+// to the machine type of the cluster's node pools. Accelerator node pools are
+// preferred over CPU ones; when there are several machine types to choose
+// from, the first node pool's is used and the others are listed in a warning.
+func (g *GKEOrchestrator) discoverComputeType(job *orchestrator.JobDefinition) error {
+	if job.ComputeType != "" {
+		return nil
+	}
+
+	var accelerated, cpu []string
+	for _, np := range g.clusterDesc.NodePools {
+		machineType := np.Config.MachineType
+		if machineType == "" || g.isSystemPool(np) {
+			continue
+		}
+		if len(np.Config.Accelerators) > 0 || config.IsTPU(machineType) {
+			if !slices.Contains(accelerated, machineType) {
+				accelerated = append(accelerated, machineType)
+			}
+		} else if !slices.Contains(cpu, machineType) {
+			cpu = append(cpu, machineType)
+		}
+	}
+
+	candidates := accelerated
+	if len(candidates) == 0 {
+		candidates = cpu
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("--compute-type was not set and cluster %s has no node pools to discover it from; set --compute-type", job.ClusterName)
+	}
+	if len(candidates) > 1 {
+		logging.Warn("Cluster %s has node pools of several machine types (%s); using %s. Set --compute-type to use another.", job.ClusterName, strings.Join(candidates, ", "), candidates[0])
+	}
+	logging.Info("Auto-discovered compute type %s from the node pools of cluster %s", candidates[0], job.ClusterName)
+	job.ComputeType = candidates[0]
+	return nil
+}
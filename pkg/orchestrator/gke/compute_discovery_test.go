@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestDiscoverComputeType(t *testing.T) {
+	systemPool := gkeJobNodePool{Name: "default-pool", Config: gkeNodePoolConfig{
+		MachineType: "e2-standard-4",
+		Taints:      []gkeTaint{{Key: "components.gke.io/gke-managed-components", Value: "true"}},
+	}}
+	tests := []struct {
+		name      string
+		job       orchestrator.JobDefinition
+		nodePools []gkeJobNodePool
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "set compute type is kept",
+			job:       orchestrator.JobDefinition{ComputeType: "n2-standard-8"},
+			nodePools: []gkeJobNodePool{{Config: gkeNodePoolConfig{MachineType: "g2-standard-12"}}},
+			want:      "n2-standard-8",
+		},
+		{
+			name: "accelerator pools are preferred",
+			nodePools: []gkeJobNodePool{
+				systemPool,
+				{Config: gkeNodePoolConfig{MachineType: "n2-standard-8"}},
+				{Config: gkeNodePoolConfig{MachineType: "g2-standard-12", Accelerators: []gkeAccelerator{{AcceleratorType: "nvidia-l4", AcceleratorCount: "1"}}}},
+				{Config: gkeNodePoolConfig{MachineType: "ct6e-standard-4t"}},
+			},
+			want: "g2-standard-12",
+		},
+		{
+			name:      "cpu pool",
+			nodePools: []gkeJobNodePool{systemPool, {Config: gkeNodePoolConfig{MachineType: "n2-standard-8"}}},
+			want:      "n2-standard-8",
+		},
+		{
+			name:      "no pools",
+			job:       orchestrator.JobDefinition{ClusterName: "c"},
+			nodePools: []gkeJobNodePool{systemPool},
+			wantErr:   "cluster c has no node pools to discover it from",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newTestGKEOrchestrator(NewMockExecutor(nil))
+			g.clusterDesc.NodePools = tc.nodePools
+			job := tc.job
+			err := g.discoverComputeType(&job)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.ComputeType != tc.want {
+				t.Errorf("ComputeType = %q, want %q", job.ComputeType, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// selectComputeType picks the first of the job's compute type and its
+// fallbacks that the cluster can run the job on, and makes it the job's
+// compute type. The resource limits, node selectors and labels of the
+// workload are then derived from the selected type.
+func (g *GKEOrchestrator) selectComputeType(job *orchestrator.JobDefinition) error {
+	if len(job.ComputeTypeFallbacks) == 0 {
+		return nil
+	}
+	candidates := append([]string{job.ComputeType}, job.ComputeTypeFallbacks...)
+
+	var reasons []string
+	for _, computeType := range candidates {
+		reason := g.computeTypeUnavailable(computeType, *job)
+		if reason == "" {
+			logging.Info("Selected compute type %s, the first available of %s.", computeType, strings.Join(candidates, ", "))
+			job.ComputeType = computeType
+			return nil
+		}
+		logging.Info("Compute type %s is not available: %s", computeType, reason)
+		reasons = append(reasons, fmt.Sprintf("%s: %s", computeType, reason))
+	}
+	return fmt.Errorf("none of the compute types is available in cluster %s:\n  %s", job.ClusterName, strings.Join(reasons, "\n  "))
+}
+
+// computeTypeUnavailable returns why the cluster cannot run job on
+// computeType, or "" if it can: its node pools of that machine type scale to
+// the nodes the job needs, or Node Auto-Provisioning can create them.
+func (g *GKEOrchestrator) computeTypeUnavailable(computeType string, job orchestrator.JobDefinition) string {
+	machineType, err := g.resolveJobMachineType(computeType)
+	if err != nil {
+		return err.Error()
+	}
+
+	// TPU jobs need whole slices, whose size is only known once the topology
+	// is resolved; any node pool of the machine type is taken as capacity.
+	needed := 1
+	if !config.IsTPU(machineType) {
+		needed = max(job.NodesPerSlice, 1) * max(job.NumSlices, 1)
+	}
+	nodes := g.machineTypeNodes(machineType)
+	if nodes >= needed {
+		return ""
+	}
+
+	nap, err := g.isNAPEnabledForMachineType(machineType, job.ClusterLocation)
+	if err != nil {
+		logging.Warn("Failed to check whether Node Auto-Provisioning can create %s nodes: %v", machineType, err)
+	}
+	if nap {
+		return ""
+	}
+	if nodes == 0 {
+		return fmt.Sprintf("the cluster has no node pool of machine type %s", machineType)
+	}
+	return fmt.Sprintf("the node pools of machine type %s scale to %d nodes, and the job needs %d", machineType, nodes, needed)
+}
+
+// machineTypeNodes returns the maximum number of nodes of machineType the
+// cluster's node pools scale to.
+func (g *GKEOrchestrator) machineTypeNodes(machineType string) int {
+	nodes := 0
+	for _, np := range g.clusterDesc.NodePools {
+		if g.isSystemPool(np) || !strings.EqualFold(np.Config.MachineType, machineType) {
+			continue
+		}
+		nodes += g.getNodeCount(np)
+	}
+	return nodes
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestSelectComputeType(t *testing.T) {
+	newOrc := func() *GKEOrchestrator {
+		g := newTestGKEOrchestrator(NewMockExecutor(nil))
+		g.clusterDesc.NodePools = []gkeJobNodePool{
+			{Name: "a100", Config: gkeNodePoolConfig{MachineType: "a2-ultragpu-1g"}, Autoscaling: gkeAutoscaling{Enabled: true, TotalMaxNodeCount: 1}},
+			{Name: "l4", Config: gkeNodePoolConfig{MachineType: "g2-standard-12"}, InitialNodeCount: 4},
+		}
+		return g
+	}
+
+	tests := []struct {
+		name    string
+		job     orchestrator.JobDefinition
+		want    string
+		wantErr []string
+	}{
+		{
+			name: "no fallbacks keeps the compute type",
+			job:  orchestrator.JobDefinition{ComputeType: "h100-80gb-1"},
+			want: "h100-80gb-1",
+		},
+		{
+			name: "first type with capacity",
+			job:  orchestrator.JobDefinition{ComputeType: "h100-80gb-1", ComputeTypeFallbacks: []string{"a100-80gb-1", "l4-1"}},
+			want: "a100-80gb-1",
+		},
+		{
+			name: "skips node pools too small for the job",
+			job:  orchestrator.JobDefinition{ComputeType: "h100-80gb-1", ComputeTypeFallbacks: []string{"a100-80gb-1", "l4-1"}, NodesPerSlice: 2},
+			want: "l4-1",
+		},
+		{
+			name: "none available",
+			job:  orchestrator.JobDefinition{ClusterName: "c", ComputeType: "h100-80gb-1", ComputeTypeFallbacks: []string{"a100-80gb-1"}, NodesPerSlice: 2},
+			wantErr: []string{
+				"none of the compute types is available in cluster c",
+				"h100-80gb-1: the cluster has no node pool of machine type a3-highgpu-1g",
+				"a100-80gb-1: the node pools of machine type a2-ultragpu-1g scale to 1 nodes, and the job needs 2",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			job := tc.job
+			err := newOrc().selectComputeType(&job)
+			if len(tc.wantErr) > 0 {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				for _, want := range tc.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.ComputeType != tc.want {
+				t.Errorf("ComputeType = %q, want %q", job.ComputeType, tc.want)
+			}
+		})
+	}
+}
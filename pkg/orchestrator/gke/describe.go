@@ -0,0 +1,308 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxDiagnosisPods is the number of pod names listed in one diagnosis line.
+const maxDiagnosisPods = 3
+
+type jobSetConditions struct {
+	Metadata struct {
+		UID string `json:"uid"`
+	} `json:"metadata"`
+	Status struct {
+		Conditions []kueueWorkloadCondition `json:"conditions"`
+	} `json:"status"`
+}
+
+// describePodList holds the parts of the workload pods that explain why they
+// are not running.
+type describePodList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Reason            string `json:"reason"`
+			Message           string `json:"message"`
+			ContainerStatuses []struct {
+				Name  string `json:"name"`
+				State struct {
+					Waiting *struct {
+						Reason  string `json:"reason"`
+						Message string `json:"message"`
+					} `json:"waiting"`
+					Terminated *containerTermination `json:"terminated"`
+				} `json:"state"`
+				LastState struct {
+					Terminated *containerTermination `json:"terminated"`
+				} `json:"lastState"`
+			} `json:"containerStatuses"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type containerTermination struct {
+	Reason   string `json:"reason"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// podProblems groups the pods of a workload by what is wrong with them.
+type podProblems struct {
+	oomKilled    []string
+	imagePull    []string
+	pullMessage  string
+	crashLooping []string
+	evicted      []string
+	evictMessage string
+}
+
+// DescribeJob explains why a workload is pending or failing from its JobSet
+// and Kueue Workload conditions, the state of its pods and their events.
+func (g *GKEOrchestrator) DescribeJob(ctx context.Context, name string, opts orchestrator.DescribeOptions) (orchestrator.WorkloadDescription, error) {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return orchestrator.WorkloadDescription{}, err
+	}
+	ns, err := g.getJobNamespace(ctx, name)
+	if err != nil {
+		return orchestrator.WorkloadDescription{}, err
+	}
+	return g.collectDescription(ctx, name, ns)
+}
+
+func (g *GKEOrchestrator) collectDescription(ctx context.Context, name, ns string) (orchestrator.WorkloadDescription, error) {
+	status, err := g.collectJobStatus(ctx, name, ns)
+	if err != nil {
+		return orchestrator.WorkloadDescription{}, err
+	}
+	status.GPUErrors = g.workloadGPUErrors(ctx, ns, status.Pods)
+	desc := orchestrator.WorkloadDescription{Status: status}
+
+	var js jobSetConditions
+	if err := g.getResource(ctx, &js, jobSetGVR, ns, name); err != nil {
+		return desc, err
+	}
+	desc.Conditions = toConditions(js.Status.Conditions)
+
+	objects := map[string]bool{}
+	if wl := g.jobKueueWorkload(ctx, ns, js.Metadata.UID); wl != nil {
+		desc.KueueConditions = toConditions(wl.Status.Conditions)
+		objects[wl.Metadata.Name] = true
+	}
+
+	problems, err := g.workloadPodProblems(ctx, name, ns)
+	if err != nil {
+		return desc, err
+	}
+	desc.Events, err = g.workloadEvents(ctx, name, ns, objects)
+	if err != nil {
+		return desc, err
+	}
+	desc.Diagnosis = diagnose(desc, problems)
+	return desc, nil
+}
+
+func toConditions(in []kueueWorkloadCondition) []orchestrator.Condition {
+	var out []orchestrator.Condition
+	for _, c := range in {
+		out = append(out, orchestrator.Condition{
+			Type: c.Type, Status: c.Status, Reason: c.Reason, Message: c.Message, LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].LastTransitionTime < out[j].LastTransitionTime })
+	return out
+}
+
+func (g *GKEOrchestrator) workloadPodProblems(ctx context.Context, name, ns string) (podProblems, error) {
+	var p podProblems
+	var list describePodList
+	if err := g.listResources(ctx, &list, podGVR, ns, metav1.ListOptions{LabelSelector: "jobset.sigs.k8s.io/jobset-name=" + name}); err != nil {
+		return p, fmt.Errorf("failed to list pods for %s: %w", name, err)
+	}
+
+	for _, pod := range list.Items {
+		podName := pod.Metadata.Name
+		if pod.Status.Reason == "Evicted" {
+			p.evicted = append(p.evicted, podName)
+			p.evictMessage = pod.Status.Message
+		}
+		var oom, pull, crash bool
+		for _, cs := range pod.Status.ContainerStatuses {
+			for _, t := range []*containerTermination{cs.State.Terminated, cs.LastState.Terminated} {
+				oom = oom || (t != nil && t.Reason == "OOMKilled")
+			}
+			if w := cs.State.Waiting; w != nil {
+				switch w.Reason {
+				case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+					pull = true
+					if w.Message != "" {
+						p.pullMessage = w.Message
+					}
+				case "CrashLoopBackOff":
+					crash = true
+				}
+			}
+		}
+		if oom {
+			p.oomKilled = append(p.oomKilled, podName)
+		}
+		if pull {
+			p.imagePull = append(p.imagePull, podName)
+		}
+		if crash {
+			p.crashLooping = append(p.crashLooping, podName)
+		}
+	}
+	return p, nil
+}
+
+// workloadEvents lists the events of the JobSet, the Jobs and pods it owns and
+// of the extra objects, aggregated by type, reason and message. Warnings come
+// first, then the most recent events.
+func (g *GKEOrchestrator) workloadEvents(ctx context.Context, name, ns string, objects map[string]bool) ([]orchestrator.Event, error) {
+	var list eventList
+	if err := g.listResources(ctx, &list, eventGVR, ns, metav1.ListOptions{}); err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]*orchestrator.Event{}
+	var events []*orchestrator.Event
+	for _, e := range list.Items {
+		obj := e.InvolvedObject.Name
+		if obj != name && !strings.HasPrefix(obj, name+"-") && !objects[obj] {
+			continue
+		}
+		key := e.Type + "\x00" + e.Reason + "\x00" + e.Message
+		agg, ok := byKey[key]
+		if !ok {
+			agg = &orchestrator.Event{Type: e.Type, Reason: e.Reason, Message: e.Message}
+			byKey[key] = agg
+			events = append(events, agg)
+		}
+		agg.Objects = append(agg.Objects, e.InvolvedObject.Kind+"/"+obj)
+		agg.Count += max(e.Count, 1)
+		ts := e.LastTimestamp
+		if ts == "" {
+			ts = e.EventTime
+		}
+		agg.LastSeen = max(agg.LastSeen, ts)
+	}
+
+	out := make([]orchestrator.Event, 0, len(events))
+	for _, e := range events {
+		sort.Strings(e.Objects)
+		out = append(out, *e)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if wi, wj := out[i].Type == "Warning", out[j].Type == "Warning"; wi != wj {
+			return wi
+		}
+		return out[i].LastSeen > out[j].LastSeen
+	})
+	return out, nil
+}
+
+// diagnose explains, most severe first, why a workload is pending or failing.
+func diagnose(desc orchestrator.WorkloadDescription, p podProblems) []string {
+	var d []string
+	name := desc.Status.Name
+
+	if c := findCondition(desc.Conditions, "Failed"); c != nil && c.Status == "True" {
+		d = append(d, fmt.Sprintf("The JobSet failed: %s.", conditionText(*c)))
+	}
+	d = append(d, gpuErrorCauses(desc.Status.GPUErrors)...)
+	if len(p.oomKilled) > 0 {
+		d = append(d, oomDiagnosis(p.oomKilled, oomMemoryLimit(desc.Status.Pods)))
+	}
+	if len(p.imagePull) > 0 {
+		line := fmt.Sprintf("The container image cannot be pulled for %s.", podNames(p.imagePull))
+		if p.pullMessage != "" {
+			line += " " + strings.TrimSuffix(p.pullMessage, ".") + "."
+		}
+		d = append(d, line+" Check that the image exists and that the node service account can read its registry.")
+	}
+	if len(p.crashLooping) > 0 {
+		d = append(d, fmt.Sprintf("Containers are crash looping in %s. Run 'gcluster job logs %s' to see why the container exits.", podNames(p.crashLooping), name))
+	}
+	if c := findCondition(desc.KueueConditions, "Evicted"); c != nil && c.Status == "True" {
+		d = append(d, fmt.Sprintf("Kueue evicted the workload: %s.", conditionText(*c)))
+	}
+	if len(p.evicted) > 0 {
+		line := fmt.Sprintf("Nodes evicted %s.", podNames(p.evicted))
+		if p.evictMessage != "" {
+			line += " " + strings.TrimSuffix(p.evictMessage, ".") + "."
+		}
+		d = append(d, line)
+	}
+	for _, e := range desc.Events {
+		if e.Reason == "FailedScheduling" {
+			d = append(d, fmt.Sprintf("Pods cannot be scheduled: %s.", strings.TrimSuffix(e.Message, ".")))
+		}
+	}
+	if len(desc.KueueConditions) > 0 {
+		if c := findCondition(desc.KueueConditions, "Admitted"); c == nil || c.Status != "True" {
+			line := "Kueue has not admitted the workload"
+			if c := findCondition(desc.KueueConditions, "QuotaReserved"); c != nil && c.Status != "True" && c.Message != "" {
+				line += ": " + strings.TrimSuffix(c.Message, ".")
+			}
+			d = append(d, fmt.Sprintf("%s. Run 'gcluster job eta %s' to see the quota it is waiting for.", line, name))
+		}
+	}
+	return d
+}
+
+func findCondition(conditions []orchestrator.Condition, condType string) *orchestrator.Condition {
+	for i := len(conditions) - 1; i >= 0; i-- {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func conditionText(c orchestrator.Condition) string {
+	switch {
+	case c.Reason != "" && c.Message != "":
+		return c.Reason + ": " + strings.TrimSuffix(c.Message, ".")
+	case c.Message != "":
+		return strings.TrimSuffix(c.Message, ".")
+	}
+	return c.Reason
+}
+
+// podNames names the first pods of a list, e.g. "2 pods (a, b)".
+func podNames(pods []string) string {
+	shown := pods
+	if len(shown) > maxDiagnosisPods {
+		shown = shown[:maxDiagnosisPods]
+	}
+	list := strings.Join(shown, ", ")
+	if len(pods) > len(shown) {
+		list += ", ..."
+	}
+	if len(pods) == 1 {
+		return fmt.Sprintf("1 pod (%s)", list)
+	}
+	return fmt.Sprintf("%d pods (%s)", len(pods), list)
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const describeJobSetJSON = `{
+  "metadata": {"uid": "uid-123", "labels": {"kueue.x-k8s.io/queue-name": "lq"}},
+  "spec": {"failurePolicy": {"maxRestarts": 1}, "replicatedJobs": [{"name": "main", "replicas": 1}]},
+  "status": {"conditions": [
+    {"type": "Failed", "status": "True", "reason": "FailedJobs", "message": "jobset failed due to one or more job failures", "lastTransitionTime": "2026-01-01T00:20:00Z"},
+    {"type": "Suspended", "status": "False", "reason": "ResumeJobs", "lastTransitionTime": "2026-01-01T00:01:00Z"}
+  ]}
+}`
+
+const describePodsJSON = `{"items": [
+  {"metadata": {"name": "train-main-0-0-a"},
+   "status": {"phase": "Running", "containerStatuses": [{"name": "workload",
+     "state": {"waiting": {"reason": "CrashLoopBackOff"}},
+     "lastState": {"terminated": {"reason": "OOMKilled", "exitCode": 137}}}]}},
+  {"metadata": {"name": "train-main-0-1-b"},
+   "status": {"phase": "Pending", "containerStatuses": [{"name": "workload",
+     "state": {"waiting": {"reason": "ImagePullBackOff", "message": "Back-off pulling image \"us-docker.pkg.dev/p/r/img:missing\""}}}]}},
+  {"metadata": {"name": "train-main-0-2-c"},
+   "status": {"phase": "Failed", "reason": "Evicted", "message": "The node was low on resource: ephemeral-storage."}}
+]}`
+
+const describeWorkloadsJSON = `{"items": [{
+  "metadata": {"name": "jobset-train-3f2a1"},
+  "status": {"conditions": [
+    {"type": "QuotaReserved", "status": "False", "reason": "Pending", "message": "couldn't assign flavors to pod set main: insufficient unused quota for nvidia.com/gpu in flavor default, 8 more needed", "lastTransitionTime": "2026-01-01T00:00:00Z"}
+  ]}
+}]}`
+
+const describeEventsJSON = `{"items": [
+  {"involvedObject": {"kind": "Pod", "name": "train-main-0-0-a"}, "type": "Warning", "reason": "FailedScheduling", "message": "0/4 nodes are available: 4 Insufficient nvidia.com/gpu.", "count": 3, "lastTimestamp": "2026-01-01T00:02:00Z"},
+  {"involvedObject": {"kind": "Pod", "name": "train-main-0-1-b"}, "type": "Warning", "reason": "FailedScheduling", "message": "0/4 nodes are available: 4 Insufficient nvidia.com/gpu.", "count": 2, "lastTimestamp": "2026-01-01T00:03:00Z"},
+  {"involvedObject": {"kind": "Workload", "name": "jobset-train-3f2a1"}, "type": "Normal", "reason": "Pending", "message": "insufficient unused quota", "eventTime": "2026-01-01T00:00:30Z"},
+  {"involvedObject": {"kind": "Pod", "name": "other-job-0"}, "type": "Warning", "reason": "BackOff", "message": "unrelated"}
+]}`
+
+func TestCollectDescription(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{})
+	g.dynClient = newFakeDynamicClient(
+		fakeObject("JobSet", "team-a", "train", describeJobSetJSON),
+		fakeList("Pod", "team-a", map[string]string{"jobset.sigs.k8s.io/jobset-name": "train"}, describePodsJSON),
+		fakeList("Workload", "team-a", map[string]string{"kueue.x-k8s.io/job-uid": "uid-123"}, describeWorkloadsJSON),
+		fakeList("Event", "team-a", nil, describeEventsJSON),
+	)
+
+	got, err := g.collectDescription(context.Background(), "train", "team-a")
+	if err != nil {
+		t.Fatalf("collectDescription() error = %v", err)
+	}
+
+	if got.Status.Status != "Failed" || len(got.Status.Pods) != 3 {
+		t.Errorf("unexpected status %+v", got.Status)
+	}
+	if len(got.Conditions) != 2 || got.Conditions[0].Type != "Suspended" || got.Conditions[1].Reason != "FailedJobs" {
+		t.Errorf("expected the JobSet conditions oldest first, got %+v", got.Conditions)
+	}
+	if len(got.KueueConditions) != 1 || got.KueueConditions[0].Reason != "Pending" {
+		t.Errorf("unexpected Kueue conditions %+v", got.KueueConditions)
+	}
+
+	wantEvents := []orchestrator.Event{
+		{Type: "Warning", Reason: "FailedScheduling", Message: "0/4 nodes are available: 4 Insufficient nvidia.com/gpu.", Objects: []string{"Pod/train-main-0-0-a", "Pod/train-main-0-1-b"}, Count: 5, LastSeen: "2026-01-01T00:03:00Z"},
+		{Type: "Normal", Reason: "Pending", Message: "insufficient unused quota", Objects: []string{"Workload/jobset-train-3f2a1"}, Count: 1, LastSeen: "2026-01-01T00:00:30Z"},
+	}
+	if !reflect.DeepEqual(got.Events, wantEvents) {
+		t.Errorf("Events =\n%+v\nwant\n%+v", got.Events, wantEvents)
+	}
+
+	wantDiagnosis := []string{
+		"The JobSet failed: FailedJobs: jobset failed due to one or more job failures.",
+		"Containers ran out of memory and were OOMKilled in 1 pod (train-main-0-0-a). Lower the memory use of the job or request more memory per pod.",
+		"The container image cannot be pulled for 1 pod (train-main-0-1-b). Back-off pulling image \"us-docker.pkg.dev/p/r/img:missing\". Check that the image exists and that the node service account can read its registry.",
+		"Containers are crash looping in 1 pod (train-main-0-0-a). Run 'gcluster job logs train' to see why the container exits.",
+		"Nodes evicted 1 pod (train-main-0-2-c). The node was low on resource: ephemeral-storage.",
+		"Pods cannot be scheduled: 0/4 nodes are available: 4 Insufficient nvidia.com/gpu.",
+		"Kueue has not admitted the workload: couldn't assign flavors to pod set main: insufficient unused quota for nvidia.com/gpu in flavor default, 8 more needed. Run 'gcluster job eta train' to see the quota it is waiting for.",
+	}
+	if !reflect.DeepEqual(got.Diagnosis, wantDiagnosis) {
+		t.Errorf("Diagnosis =\n%s\nwant\n%s", strings.Join(got.Diagnosis, "\n"), strings.Join(wantDiagnosis, "\n"))
+	}
+}
+
+func TestDiagnose_Healthy(t *testing.T) {
+	desc := orchestrator.WorkloadDescription{
+		Status: orchestrator.JobStatusDetail{Name: "train", Status: "Running"},
+		KueueConditions: []orchestrator.Condition{
+			{Type: "QuotaReserved", Status: "True"},
+			{Type: "Admitted", Status: "True"},
+		},
+		Events: []orchestrator.Event{{Type: "Normal", Reason: "Started"}},
+	}
+	if got := diagnose(desc, podProblems{}); len(got) != 0 {
+		t.Errorf("expected no diagnosis for a running workload, got %v", got)
+	}
+}
+
+func TestPodNames(t *testing.T) {
+	if got, want := podNames([]string{"a", "b", "c", "d"}), "4 pods (a, b, c, ...)"; got != want {
+		t.Errorf("podNames() = %q, want %q", got, want)
+	}
+}
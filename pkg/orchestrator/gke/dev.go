@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	devJupyterPort = 8888
+	devSSHPort     = 2222
+
+	devTokenEnv  = "GCLUSTER_DEV_TOKEN"
+	devSSHKeyEnv = "GCLUSTER_DEV_SSH_KEY"
+)
+
+var (
+	devPodPollInterval = 5 * time.Second
+	devPodStartTimeout = 30 * time.Minute
+)
+
+// StartDevSession submits a single-pod JobSet running a Jupyter server or SSH
+// daemon, waits for the pod to start and forwards its port to localhost. The
+// server exits on its own after the configured idle timeout, which completes
+// the JobSet so that its TTL cleans it up.
+func (g *GKEOrchestrator) StartDevSession(ctx context.Context, def orchestrator.DevSessionDefinition) error {
+	job := def.Job
+	remotePort, command, err := devServerCommand(def.Mode, def.IdleTimeoutSeconds)
+	if err != nil {
+		return err
+	}
+
+	env := make(map[string]string, len(job.Env)+1)
+	for k, v := range job.Env {
+		env[k] = v
+	}
+	var token string
+	switch def.Mode {
+	case orchestrator.DevModeJupyter:
+		if token, err = shell.RandomString(32); err != nil {
+			return err
+		}
+		env[devTokenEnv] = token
+	case orchestrator.DevModeSSH:
+		env[devSSHKeyEnv] = strings.TrimSpace(def.SSHPublicKey)
+	}
+
+	job.Env = env
+	job.CommandToRun = command
+	job.MaxRestarts = 0
+	job.AwaitJobCompletion = false
+	if _, err := g.SubmitJob(ctx, job); err != nil {
+		return err
+	}
+
+	localPort := def.LocalPort
+	if localPort == 0 {
+		localPort = remotePort
+	}
+	return g.connectDevSession(ctx, job.WorkloadName, def.Mode, token, localPort, remotePort, def.IdleTimeoutSeconds)
+}
+
+func (g *GKEOrchestrator) connectDevSession(ctx context.Context, name, mode, token string, localPort, remotePort, idleTimeoutSeconds int) error {
+	ns, err := g.getJobNamespace(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	logging.Info("Waiting for the development pod to start (this may take a while if nodes need to be provisioned)...")
+	pod, err := g.waitForRunningPod(ctx, ns, name)
+	if err != nil {
+		return err
+	}
+
+	portSpec := fmt.Sprintf("%d:%d", localPort, remotePort)
+	switch mode {
+	case orchestrator.DevModeJupyter:
+		logging.Info("Jupyter will be available at http://localhost:%d/lab?token=%s once the server has started.", localPort, token)
+	case orchestrator.DevModeSSH:
+		logging.Info("Connect with: ssh -p %d -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null root@localhost", localPort)
+		logging.Info("For VS Code, add this host to Remote-SSH: ssh -p %d root@localhost", localPort)
+	}
+	logging.Info("The session shuts down after %s without activity. Press Ctrl+C to stop port-forwarding.", time.Duration(idleTimeoutSeconds)*time.Second)
+
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return err
+	}
+	err = kube.PortForward(ctx, ns, pod, []string{portSpec})
+	logging.Info("Port-forwarding stopped. Reconnect with 'kubectl port-forward -n %s pod/%s %s', or end the session now with 'gcluster job cancel %s'.", ns, pod, portSpec, name)
+	return err
+}
+
+func (g *GKEOrchestrator) waitForRunningPod(ctx context.Context, ns, name string) (string, error) {
+	opts := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("jobset.sigs.k8s.io/jobset-name=%s", name),
+		FieldSelector: "status.phase=Running",
+	}
+	deadline := g.now().Add(devPodStartTimeout)
+	for {
+		var pods corev1.PodList
+		if err := g.listResources(ctx, &pods, podGVR, ns, opts); err == nil && len(pods.Items) > 0 {
+			return pods.Items[0].Name, nil
+		}
+		if g.now().After(deadline) {
+			return "", fmt.Errorf("timed out after %s waiting for a running pod of '%s'; check 'gcluster job list' and Kueue admission", devPodStartTimeout, name)
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		g.sleep(devPodPollInterval)
+	}
+}
+
+// devServerCommand returns the container port and the shell command that runs
+// the development server and enforces the idle timeout inside the pod.
+func devServerCommand(mode string, idleTimeoutSeconds int) (int, string, error) {
+	if idleTimeoutSeconds <= 0 {
+		return 0, "", fmt.Errorf("idle timeout must be positive, got %ds", idleTimeoutSeconds)
+	}
+	switch mode {
+	case orchestrator.DevModeJupyter:
+		return devJupyterPort, fmt.Sprintf(`command -v jupyter >/dev/null 2>&1 || pip install --quiet jupyterlab
+exec jupyter lab --ip=0.0.0.0 --port=%[1]d --no-browser --allow-root \
+  --ServerApp.token="$%[2]s" \
+  --ServerApp.shutdown_no_activity_timeout=%[3]d \
+  --MappingKernelManager.cull_idle_timeout=%[3]d --MappingKernelManager.cull_interval=60 \
+  --TerminalManager.cull_inactive_timeout=%[3]d`, devJupyterPort, devTokenEnv, idleTimeoutSeconds), nil
+	case orchestrator.DevModeSSH:
+		// Established connections to the SSH port (hex in /proc/net/tcp, state 01)
+		// reset the idle counter; port-forwarded sessions show up as local connections.
+		return devSSHPort, fmt.Sprintf(`set -e
+if ! command -v sshd >/dev/null 2>&1; then
+  apt-get update -qq && DEBIAN_FRONTEND=noninteractive apt-get install -y -qq openssh-server >/dev/null
+fi
+mkdir -p /run/sshd /root/.ssh && chmod 700 /root/.ssh
+printf '%%s\n' "$%[1]s" > /root/.ssh/authorized_keys && chmod 600 /root/.ssh/authorized_keys
+ssh-keygen -A >/dev/null
+"$(command -v sshd)" -p %[2]d -o PasswordAuthentication=no -o PermitRootLogin=prohibit-password
+idle=0
+while [ "$idle" -lt %[3]d ]; do
+  sleep 60
+  if cat /proc/net/tcp /proc/net/tcp6 2>/dev/null | awk '$2 ~ /:%04[2]X$/ && $4 == "01" {found=1} END {exit !found}'; then
+    idle=0
+  else
+    idle=$((idle+60))
+  fi
+done
+echo "No SSH sessions for %[3]d seconds, shutting down."`, devSSHKeyEnv, devSSHPort, idleTimeoutSeconds), nil
+	default:
+		return 0, "", fmt.Errorf("unsupported dev mode %q, must be %q or %q", mode, orchestrator.DevModeJupyter, orchestrator.DevModeSSH)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/testutil"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDevServerCommand(t *testing.T) {
+	port, cmd, err := devServerCommand(orchestrator.DevModeJupyter, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port != devJupyterPort {
+		t.Errorf("jupyter port = %d, want %d", port, devJupyterPort)
+	}
+	for _, want := range []string{"jupyter lab", `--ServerApp.token="$GCLUSTER_DEV_TOKEN"`, "--ServerApp.shutdown_no_activity_timeout=3600"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("jupyter command missing %q:\n%s", want, cmd)
+		}
+	}
+
+	port, cmd, err = devServerCommand(orchestrator.DevModeSSH, 1800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port != devSSHPort {
+		t.Errorf("ssh port = %d, want %d", port, devSSHPort)
+	}
+	for _, want := range []string{"-p 2222", `printf '%s\n' "$GCLUSTER_DEV_SSH_KEY"`, "/:08AE$/", `-lt 1800`} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("ssh command missing %q:\n%s", want, cmd)
+		}
+	}
+
+	if _, _, err := devServerCommand("rdp", 60); err == nil {
+		t.Error("expected error for unsupported mode")
+	}
+	if _, _, err := devServerCommand(orchestrator.DevModeSSH, 0); err == nil {
+		t.Error("expected error for non-positive idle timeout")
+	}
+}
+
+func TestConnectDevSession_WaitsForRunningPod(t *testing.T) {
+	pod := fakeObject("Pod", "team-a", "dev-abc-0-0-xyz", `{"metadata": {"labels": {"jobset.sigs.k8s.io/jobset-name": "dev-abc"}}, "status": {"phase": "Running"}}`)
+	client := newFakeDynamicClient(pod)
+	polls := 0
+	client.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		polls++
+		if polls < 3 {
+			return true, &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList"}}, nil
+		}
+		return false, nil, nil
+	})
+	kube := &MockKubeClient{Namespace: "team-a"}
+	g := NewGKEOrchestrator()
+	g.SetExecutor(NewMockExecutor(nil))
+	g.SetKubeClient(kube)
+	g.dynClient = client
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	g.SetClock(clock)
+
+	if err := g.connectDevSession(context.Background(), "dev-abc", orchestrator.DevModeSSH, "", 2022, devSSHPort, 3600); err != nil {
+		t.Fatalf("connectDevSession() error = %v", err)
+	}
+	if polls != 3 {
+		t.Errorf("expected 3 pod polls, got %d", polls)
+	}
+	if sleeps := clock.Sleeps(); len(sleeps) != 2 || sleeps[0] != devPodPollInterval {
+		t.Errorf("expected two waits of %s between polls, got %v", devPodPollInterval, sleeps)
+	}
+	want := "team-a/dev-abc-0-0-xyz 2022:2222"
+	if len(kube.Forwarded) != 1 || kube.Forwarded[0] != want {
+		t.Errorf("port-forward = %v, want %q", kube.Forwarded, want)
+	}
+}
+
+func TestWaitForRunningPod_Timeout(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := testutil.NewFakeClock(start)
+	g := NewGKEOrchestrator()
+	g.SetClock(clock)
+	g.dynClient = newFakeDynamicClient()
+	if _, err := g.waitForRunningPod(context.Background(), "default", "dev-abc"); err == nil || !strings.Contains(err.Error(), "timed out after 30m0s") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if waited := clock.Now().Sub(start); waited <= devPodStartTimeout || waited > devPodStartTimeout+devPodPollInterval {
+		t.Errorf("expected to give up right after %s, waited %s", devPodStartTimeout, waited)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// addHeadlessService gives the pods of a JobSet stable DNS hostnames: the
+// JobSet sets the hostname of each pod to
+// <workload>-<replicated job>-<job index>-<pod index> under the subdomain of
+// the workload, and the headless Service of the same name publishes the
+// records, including for pods that are not ready yet so that workers can
+// find each other during startup. A --framework and MPI workloads imply the
+// hostnames.
+func addHeadlessService(opts *ManifestOptions, job orchestrator.JobDefinition) error {
+	if !job.DNSHostnames && job.Framework == "" && job.WorkloadKind != orchestrator.WorkloadKindMPI {
+		return nil
+	}
+	name := opts.WorkloadName
+	if errs := validation.IsDNS1035Label(name); len(errs) > 0 {
+		return fmt.Errorf("DNS hostnames require a workload name that is a valid Service name: %s", strings.Join(errs, "; "))
+	}
+
+	manifest, err := k8syaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]string{"gcluster.google.com/workload": name},
+		},
+		"spec": map[string]interface{}{
+			"clusterIP":                "None",
+			"publishNotReadyAddresses": true,
+			"selector":                 map[string]string{"jobset.sigs.k8s.io/jobset-name": name},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render the headless Service: %w", err)
+	}
+	opts.AdditionalManifests = append(opts.AdditionalManifests, string(manifest))
+	opts.Subdomain = name
+	return nil
+}
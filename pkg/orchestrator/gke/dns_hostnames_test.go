@@ -0,0 +1,50 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestAddHeadlessService(t *testing.T) {
+	opts := ManifestOptions{WorkloadName: "train"}
+	if err := addHeadlessService(&opts, orchestrator.JobDefinition{DNSHostnames: true}); err != nil {
+		t.Fatalf("addHeadlessService() error = %v", err)
+	}
+	if opts.Subdomain != "train" {
+		t.Errorf("expected subdomain train, got %q", opts.Subdomain)
+	}
+	if len(opts.AdditionalManifests) != 1 {
+		t.Fatalf("expected the Service manifest, got %v", opts.AdditionalManifests)
+	}
+	for _, want := range []string{"kind: Service", "name: train", "gcluster.google.com/workload: train", "clusterIP: None", "publishNotReadyAddresses: true", "jobset.sigs.k8s.io/jobset-name: train"} {
+		if !strings.Contains(opts.AdditionalManifests[0], want) {
+			t.Errorf("expected Service to contain %q, got:\n%s", want, opts.AdditionalManifests[0])
+		}
+	}
+
+	opts = ManifestOptions{WorkloadName: "1-train"}
+	if err := addHeadlessService(&opts, orchestrator.JobDefinition{DNSHostnames: true}); err == nil || !strings.Contains(err.Error(), "valid Service name") {
+		t.Errorf("expected an invalid Service name error, got %v", err)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	if err := addHeadlessService(&opts, orchestrator.JobDefinition{}); err != nil || opts.Subdomain != "" || len(opts.AdditionalManifests) != 0 {
+		t.Errorf("expected no changes without --dns-hostnames, got %+v, %v", opts, err)
+	}
+}
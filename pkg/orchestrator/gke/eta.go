@@ -0,0 +1,606 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Workload states reported by 'gcluster job eta'.
+const (
+	etaStateAdmitted     = "Admitted"
+	etaStatePending      = "Pending"
+	etaStateNotSubmitted = "NotSubmitted"
+	etaStateFinished     = "Finished"
+)
+
+// resourceList holds resource amounts in milli-units, keyed by resource name.
+type resourceList map[string]int64
+
+func (r resourceList) add(o resourceList) {
+	for name, v := range o {
+		r[name] += v
+	}
+}
+
+// fits reports whether every resource of req is available in r.
+func (r resourceList) fits(req resourceList) bool {
+	for name, v := range req {
+		if v > r[name] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatQuantity(name string, milli int64) string {
+	format := resource.DecimalSI
+	if name == corev1.ResourceMemory.String() || strings.Contains(name, "storage") {
+		format = resource.BinarySI
+	}
+	if milli%1000 == 0 {
+		return resource.NewQuantity(milli/1000, format).String()
+	}
+	return resource.NewMilliQuantity(milli, format).String()
+}
+
+// formatWait renders a wait rounded to the minute, e.g. "1h5m" or "2h".
+func formatWait(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	s := strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// etaPodSet is a group of identical pods, as in the podSets of a Kueue Workload.
+type etaPodSet struct {
+	Name     string                 `json:"name"`
+	Count    int                    `json:"count"`
+	Template corev1.PodTemplateSpec `json:"template"`
+}
+
+// requests sums what Kueue charges against quota for the pod sets: container
+// requests, falling back to limits for resources without a request.
+func podSetRequests(podSets []etaPodSet) resourceList {
+	total := resourceList{}
+	for _, ps := range podSets {
+		for _, c := range ps.Template.Spec.Containers {
+			perPod := resourceList{}
+			for name, q := range c.Resources.Limits {
+				perPod[name.String()] = q.MilliValue()
+			}
+			for name, q := range c.Resources.Requests {
+				perPod[name.String()] = q.MilliValue()
+			}
+			for name, v := range perPod {
+				total[name] += v * int64(ps.Count)
+			}
+		}
+	}
+	return total
+}
+
+// etaWorkload is the subset of a Kueue Workload read by EstimateAdmission.
+type etaWorkload struct {
+	Metadata struct {
+		Name              string                  `json:"name"`
+		Namespace         string                  `json:"namespace"`
+		CreationTimestamp string                  `json:"creationTimestamp"`
+		OwnerReferences   []kueueWorkloadOwnerRef `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		QueueName string      `json:"queueName"`
+		Priority  *int32      `json:"priority"`
+		PodSets   []etaPodSet `json:"podSets"`
+	} `json:"spec"`
+	Status struct {
+		Admission *struct {
+			ClusterQueue string `json:"clusterQueue"`
+		} `json:"admission"`
+		Conditions []kueueWorkloadCondition `json:"conditions"`
+	} `json:"status"`
+}
+
+// conditionTime returns the transition time of a condition that is true.
+func (w etaWorkload) conditionTime(condType string) (time.Time, bool) {
+	for _, c := range w.Status.Conditions {
+		if c.Type == condType && c.Status == "True" {
+			t, err := time.Parse(time.RFC3339, c.LastTransitionTime)
+			return t, err == nil
+		}
+	}
+	return time.Time{}, false
+}
+
+func (w etaWorkload) state() string {
+	if _, ok := w.conditionTime("Finished"); ok {
+		return etaStateFinished
+	}
+	if _, ok := w.conditionTime("QuotaReserved"); ok {
+		return etaStateAdmitted
+	}
+	if _, ok := w.conditionTime("Admitted"); ok {
+		return etaStateAdmitted
+	}
+	return etaStatePending
+}
+
+// admittedAt is when the workload's quota was reserved.
+func (w etaWorkload) admittedAt() (time.Time, bool) {
+	if t, ok := w.conditionTime("QuotaReserved"); ok {
+		return t, true
+	}
+	return w.conditionTime("Admitted")
+}
+
+func (w etaWorkload) priority() int32 {
+	if w.Spec.Priority == nil {
+		return 0
+	}
+	return *w.Spec.Priority
+}
+
+type etaWorkloadList struct {
+	Items []etaWorkload `json:"items"`
+}
+
+type etaLocalQueueList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			ClusterQueue string `json:"clusterQueue"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// etaClusterQueue is the subset of a Kueue ClusterQueue read by EstimateAdmission.
+type etaClusterQueue struct {
+	Spec struct {
+		Cohort         string `json:"cohort"`
+		ResourceGroups []struct {
+			Flavors []struct {
+				Resources []struct {
+					Name         string            `json:"name"`
+					NominalQuota resource.Quantity `json:"nominalQuota"`
+				} `json:"resources"`
+			} `json:"flavors"`
+		} `json:"resourceGroups"`
+	} `json:"spec"`
+	Status struct {
+		FlavorsUsage []struct {
+			Resources []struct {
+				Name  string            `json:"name"`
+				Total resource.Quantity `json:"total"`
+			} `json:"resources"`
+		} `json:"flavorsUsage"`
+	} `json:"status"`
+}
+
+func (cq etaClusterQueue) quota() resourceList {
+	quota := resourceList{}
+	for _, rg := range cq.Spec.ResourceGroups {
+		for _, f := range rg.Flavors {
+			for _, r := range f.Resources {
+				quota[r.Name] += r.NominalQuota.MilliValue()
+			}
+		}
+	}
+	return quota
+}
+
+func (cq etaClusterQueue) usage() resourceList {
+	usage := resourceList{}
+	for _, f := range cq.Status.FlavorsUsage {
+		for _, r := range f.Resources {
+			usage[r.Name] += r.Total.MilliValue()
+		}
+	}
+	return usage
+}
+
+// etaManifest is the subset of a JobSet or Job manifest needed to estimate it
+// before it is submitted.
+type etaManifest struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string            `json:"name"`
+		Namespace string            `json:"namespace"`
+		Labels    map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Parallelism    *int                   `json:"parallelism"`
+		Template       corev1.PodTemplateSpec `json:"template"`
+		ReplicatedJobs []struct {
+			Name     string `json:"name"`
+			Replicas int    `json:"replicas"`
+			Template struct {
+				Spec struct {
+					Parallelism *int                   `json:"parallelism"`
+					Template    corev1.PodTemplateSpec `json:"template"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"replicatedJobs"`
+	} `json:"spec"`
+}
+
+func (m etaManifest) podSets() []etaPodSet {
+	parallelism := func(p *int) int {
+		if p == nil {
+			return 1
+		}
+		return *p
+	}
+	if m.Kind == "Job" {
+		return []etaPodSet{{Name: m.Metadata.Name, Count: parallelism(m.Spec.Parallelism), Template: m.Spec.Template}}
+	}
+	var sets []etaPodSet
+	for _, rj := range m.Spec.ReplicatedJobs {
+		sets = append(sets, etaPodSet{Name: rj.Name, Count: max(rj.Replicas, 1) * parallelism(rj.Template.Spec.Parallelism), Template: rj.Template.Spec.Template})
+	}
+	return sets
+}
+
+// etaTarget is the workload being estimated, submitted or not.
+type etaTarget struct {
+	name       string
+	namespace  string
+	localQueue string
+	state      string
+	priority   int32
+	created    string // Empty for a manifest that is not submitted yet.
+	requests   resourceList
+	uid        string // Kueue Workload name, to skip the target itself.
+}
+
+// EstimateAdmission estimates when Kueue will admit a workload from the usage
+// and quota of its ClusterQueue, the pending workloads queued ahead of it, the
+// runtime of recently finished workloads and the node pools' autoscaling limits.
+func (g *GKEOrchestrator) EstimateAdmission(ctx context.Context, name string, opts orchestrator.EtaOptions) (orchestrator.AdmissionEstimate, error) {
+	job := orchestrator.JobDefinition{ClusterProjectID: opts.ProjectID, ClusterName: opts.ClusterName, ClusterLocation: opts.ClusterLocation}
+	if err := g.populateClusterMetadata(&job); err != nil {
+		return orchestrator.AdmissionEstimate{}, err
+	}
+	if err := g.configureKubectl(ctx, job.ClusterName, job.ClusterLocation, job.ClusterProjectID); err != nil {
+		return orchestrator.AdmissionEstimate{}, err
+	}
+
+	var workloads etaWorkloadList
+	if err := g.listResources(ctx, &workloads, workloadGVR, "", metav1.ListOptions{}); err != nil {
+		return orchestrator.AdmissionEstimate{}, err
+	}
+
+	var target etaTarget
+	var err error
+	if opts.SpecPath != "" {
+		target, err = g.etaTargetFromSpec(ctx, opts.SpecPath)
+	} else {
+		target, err = g.etaTargetFromWorkload(ctx, name, workloads.Items)
+	}
+	if err != nil {
+		return orchestrator.AdmissionEstimate{}, err
+	}
+
+	var localQueues etaLocalQueueList
+	if err := g.listResources(ctx, &localQueues, localQueueGVR, "", metav1.ListOptions{}); err != nil {
+		return orchestrator.AdmissionEstimate{}, err
+	}
+	lqToCQ := map[string]string{}
+	for _, lq := range localQueues.Items {
+		lqToCQ[lq.Metadata.Namespace+"/"+lq.Metadata.Name] = lq.Spec.ClusterQueue
+	}
+	cqName := lqToCQ[target.namespace+"/"+target.localQueue]
+	if cqName == "" {
+		return orchestrator.AdmissionEstimate{}, fmt.Errorf("LocalQueue %q does not exist in namespace %q", target.localQueue, target.namespace)
+	}
+
+	var cq etaClusterQueue
+	if err := g.getResource(ctx, &cq, clusterQueueGVR, "", cqName); err != nil {
+		return orchestrator.AdmissionEstimate{}, err
+	}
+
+	var nodeLimits resourceList
+	if !g.napEnabled {
+		nodeLimits = resourceList{
+			corev1.ResourceCPU.String():    int64(g.capacity.CPUs) * 1000,
+			corev1.ResourceMemory.String(): int64(g.capacity.MemoryGi) * 1024 * 1024 * 1024 * 1000,
+			"nvidia.com/gpu":               int64(g.capacity.GPUs) * 1000,
+			"google.com/tpu":               int64(g.capacity.TPUs) * 1000,
+		}
+	}
+
+	return estimateAdmission(target, cqName, cq, workloads.Items, lqToCQ, nodeLimits, g.now()), nil
+}
+
+func (g *GKEOrchestrator) etaTargetFromWorkload(ctx context.Context, name string, workloads []etaWorkload) (etaTarget, error) {
+	ns, err := g.getJobNamespace(ctx, name)
+	if err != nil {
+		return etaTarget{}, err
+	}
+	for _, wl := range workloads {
+		if wl.Metadata.Namespace != ns {
+			continue
+		}
+		for _, owner := range wl.Metadata.OwnerReferences {
+			if owner.Name == name {
+				return etaTarget{
+					name:       name,
+					namespace:  ns,
+					localQueue: wl.Spec.QueueName,
+					state:      wl.state(),
+					priority:   wl.priority(),
+					created:    wl.Metadata.CreationTimestamp,
+					requests:   podSetRequests(wl.Spec.PodSets),
+					uid:        wl.Metadata.Namespace + "/" + wl.Metadata.Name,
+				}, nil
+			}
+		}
+	}
+	return etaTarget{}, fmt.Errorf("no Kueue workload found for %s in namespace %s; it may not have been submitted to a Kueue queue", name, ns)
+}
+
+func (g *GKEOrchestrator) etaTargetFromSpec(ctx context.Context, path string) (etaTarget, error) {
+	m, err := readSpecManifest(path)
+	if err != nil {
+		return etaTarget{}, err
+	}
+
+	queue := m.Metadata.Labels["kueue.x-k8s.io/queue-name"]
+	if queue == "" {
+		return etaTarget{}, fmt.Errorf("%s %s in spec %s has no kueue.x-k8s.io/queue-name label", m.Kind, m.Metadata.Name, path)
+	}
+	ns := m.Metadata.Namespace
+	if ns == "" {
+		if ns, err = g.getCurrentNamespace(); err != nil {
+			return etaTarget{}, err
+		}
+	}
+	podSets := m.podSets()
+	var priority int32
+	if len(podSets) > 0 && podSets[0].Template.Spec.PriorityClassName != "" {
+		priority = g.priorityClassValue(ctx, podSets[0].Template.Spec.PriorityClassName)
+	}
+	return etaTarget{
+		name:       m.Metadata.Name,
+		namespace:  ns,
+		localQueue: queue,
+		state:      etaStateNotSubmitted,
+		priority:   priority,
+		requests:   podSetRequests(podSets),
+	}, nil
+}
+
+// readSpecManifest returns the first JobSet or Job of a manifest file, such as
+// one written by 'gcluster job submit --dry-run-out'.
+func readSpecManifest(path string) (etaManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return etaManifest{}, fmt.Errorf("failed to read spec %s: %w", path, err)
+	}
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return etaManifest{}, fmt.Errorf("spec %s contains no JobSet or Job", path)
+			}
+			return etaManifest{}, fmt.Errorf("failed to decode spec %s: %w", path, err)
+		}
+		if kind, _ := raw["kind"].(string); kind != "JobSet" && kind != "Job" {
+			continue
+		}
+
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return etaManifest{}, fmt.Errorf("failed to decode spec %s: %w", path, err)
+		}
+		var m etaManifest
+		if err := json.Unmarshal(b, &m); err != nil {
+			return etaManifest{}, fmt.Errorf("failed to decode spec %s: %w", path, err)
+		}
+		return m, nil
+	}
+}
+
+func (g *GKEOrchestrator) priorityClassValue(ctx context.Context, name string) int32 {
+	var pc struct {
+		Value int32 `json:"value"`
+	}
+	if err := g.getResource(ctx, &pc, priorityClassGVR, "", name); err != nil {
+		return 0
+	}
+	return pc.Value
+}
+
+// estimateAdmission compares the target's request with the free quota of its
+// ClusterQueue after the pending workloads ahead of it. When it does not fit,
+// admitted workloads are assumed to run for the median runtime of the queue's
+// finished workloads and the wait is the time until enough of them finish.
+func estimateAdmission(t etaTarget, cqName string, cq etaClusterQueue, workloads []etaWorkload, lqToCQ map[string]string, nodeLimits resourceList, now time.Time) orchestrator.AdmissionEstimate {
+	est := orchestrator.AdmissionEstimate{
+		Workload:     t.name,
+		State:        t.state,
+		LocalQueue:   t.localQueue,
+		ClusterQueue: cqName,
+	}
+
+	ahead := resourceList{}
+	type running struct {
+		admitted time.Time
+		requests resourceList
+	}
+	var admitted []running
+	var runtimes []time.Duration
+	for _, wl := range workloads {
+		if wl.Metadata.Namespace+"/"+wl.Metadata.Name == t.uid {
+			continue
+		}
+		wlCQ := lqToCQ[wl.Metadata.Namespace+"/"+wl.Spec.QueueName]
+		if wl.Status.Admission != nil {
+			wlCQ = wl.Status.Admission.ClusterQueue
+		}
+		if wlCQ != cqName {
+			continue
+		}
+
+		switch wl.state() {
+		case etaStatePending:
+			if wl.priority() > t.priority || (wl.priority() == t.priority && (t.created == "" || wl.Metadata.CreationTimestamp < t.created)) {
+				est.WorkloadsAhead++
+				ahead.add(podSetRequests(wl.Spec.PodSets))
+			}
+		case etaStateAdmitted:
+			if at, ok := wl.admittedAt(); ok {
+				admitted = append(admitted, running{admitted: at, requests: podSetRequests(wl.Spec.PodSets)})
+			}
+		case etaStateFinished:
+			at, okAdmitted := wl.admittedAt()
+			finished, okFinished := wl.conditionTime("Finished")
+			if okAdmitted && okFinished && finished.After(at) {
+				runtimes = append(runtimes, finished.Sub(at))
+			}
+		}
+	}
+
+	quota, usage := cq.quota(), cq.usage()
+	names := make([]string, 0, len(t.requests))
+	for name := range t.requests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var overQuota, overNodes []string
+	for _, name := range names {
+		h := orchestrator.ResourceHeadroom{
+			Name:      name,
+			Requested: formatQuantity(name, t.requests[name]),
+			Quota:     formatQuantity(name, quota[name]),
+			InUse:     formatQuantity(name, usage[name]),
+			Ahead:     formatQuantity(name, ahead[name]),
+		}
+		if limit, ok := nodeLimits[name]; ok {
+			h.NodeLimit = formatQuantity(name, limit)
+			if t.requests[name] > limit {
+				overNodes = append(overNodes, name)
+			}
+		}
+		if t.requests[name] > quota[name] {
+			overQuota = append(overQuota, name)
+		}
+		est.Resources = append(est.Resources, h)
+	}
+
+	if t.state == etaStateAdmitted {
+		est.WaitKnown = true
+		est.Summary = fmt.Sprintf("Admitted to ClusterQueue %s.", cqName)
+		return est
+	}
+	if t.state == etaStateFinished {
+		est.WaitKnown = true
+		est.Summary = "Finished; it no longer holds or waits for quota."
+		return est
+	}
+
+	if len(overNodes) > 0 {
+		est.Notes = append(est.Notes, fmt.Sprintf("The node pools can only scale to the NODE POOL MAX shown for %s, so admitted pods would stay Pending; raise the node pool autoscaling maximum or reduce the request.", strings.Join(overNodes, ", ")))
+	}
+	if len(overQuota) > 0 {
+		est.Summary = fmt.Sprintf("Will not be admitted as requested: %s exceed the nominal quota of ClusterQueue %s.", strings.Join(overQuota, ", "), cqName)
+		if cq.Spec.Cohort != "" {
+			est.Notes = append(est.Notes, fmt.Sprintf("ClusterQueue %s is in cohort %s; it may still be admitted by borrowing unused quota from the cohort.", cqName, cq.Spec.Cohort))
+		}
+		return est
+	}
+	if len(overNodes) > 0 {
+		est.Summary = "Fits the ClusterQueue quota but not the node pools' autoscaling limits."
+		return est
+	}
+
+	need := resourceList{}
+	need.add(t.requests)
+	need.add(ahead)
+	free := resourceList{}
+	for name, v := range quota {
+		free[name] = v - usage[name]
+	}
+	if free.fits(need) {
+		est.WaitKnown = true
+		est.Summary = "Fits the free quota; expect admission in the next Kueue scheduling cycle."
+		if est.WorkloadsAhead > 0 {
+			est.Summary = fmt.Sprintf("Fits the free quota together with the %d workload(s) ahead; expect admission in the next Kueue scheduling cycle.", est.WorkloadsAhead)
+		}
+		return est
+	}
+
+	if len(runtimes) == 0 {
+		est.Summary = fmt.Sprintf("Waits for admitted workloads to release quota; ClusterQueue %s has no finished workloads to estimate their runtime from.", cqName)
+		return est
+	}
+	sort.Slice(runtimes, func(i, j int) bool { return runtimes[i] < runtimes[j] })
+	median := runtimes[len(runtimes)/2]
+
+	sort.Slice(admitted, func(i, j int) bool { return admitted[i].admitted.Before(admitted[j].admitted) })
+	var doneAt time.Time
+	for _, r := range admitted {
+		free.add(r.requests)
+		doneAt = r.admitted.Add(median)
+		if free.fits(need) {
+			break
+		}
+	}
+	if !free.fits(need) {
+		// The workloads ahead need more than the admitted ones free up, so
+		// assume they run for one more typical runtime first.
+		doneAt = maxTime(doneAt, now).Add(median)
+		est.Notes = append(est.Notes, "The workloads ahead need more quota than the admitted workloads release, so one more typical runtime is added.")
+	}
+	est.Wait = max(doneAt.Sub(now), 0)
+	est.WaitKnown = true
+	est.Summary = fmt.Sprintf("Estimated admission in ~%s, based on the median runtime of %s over %d finished workload(s) in ClusterQueue %s.", formatWait(est.Wait), formatWait(median), len(runtimes), cqName)
+	if est.Wait == 0 {
+		est.Summary = fmt.Sprintf("Admitted workloads have run past the median runtime of %s; expect admission as soon as they finish.", formatWait(median))
+	}
+	return est
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
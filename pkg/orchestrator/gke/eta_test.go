@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var etaNow = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// etaTestWorkload builds a workload in namespace default, queue lq, requesting
+// count pods of cpu CPUs. admitted and finished are offsets from etaNow; zero
+// leaves the condition unset.
+func etaTestWorkload(t *testing.T, name string, count int, cpu string, priority int32, created, admitted, finished time.Duration) etaWorkload {
+	t.Helper()
+	cond := func(typ string, at time.Duration) string {
+		return `{"type":"` + typ + `","status":"True","lastTransitionTime":"` + etaNow.Add(at).Format(time.RFC3339) + `"}`
+	}
+	var conds []string
+	if admitted != 0 {
+		conds = append(conds, cond("QuotaReserved", admitted))
+	}
+	if finished != 0 {
+		conds = append(conds, cond("Finished", finished))
+	}
+	doc := `{"metadata":{"name":"` + name + `","namespace":"default","creationTimestamp":"` + etaNow.Add(created).Format(time.RFC3339) + `"},
+	"spec":{"queueName":"lq","priority":` + strconv.Itoa(int(priority)) + `,
+	"podSets":[{"count":` + strconv.Itoa(count) + `,"template":{"spec":{"containers":[{"name":"c","resources":{"requests":{"cpu":"` + cpu + `"}}}]}}}]},
+	"status":{"conditions":[` + strings.Join(conds, ",") + `]}}`
+	var wl etaWorkload
+	if err := json.Unmarshal([]byte(doc), &wl); err != nil {
+		t.Fatalf("bad test workload: %v", err)
+	}
+	return wl
+}
+
+func etaTestClusterQueue(t *testing.T, quotaCPU, usedCPU, cohort string) etaClusterQueue {
+	t.Helper()
+	doc := `{"spec":{"cohort":"` + cohort + `","resourceGroups":[{"flavors":[{"resources":[{"name":"cpu","nominalQuota":"` + quotaCPU + `"}]}]}]},
+	"status":{"flavorsUsage":[{"resources":[{"name":"cpu","total":"` + usedCPU + `"}]}]}}`
+	var cq etaClusterQueue
+	if err := json.Unmarshal([]byte(doc), &cq); err != nil {
+		t.Fatalf("bad test ClusterQueue: %v", err)
+	}
+	return cq
+}
+
+func etaTestTarget(cpu int64) etaTarget {
+	return etaTarget{name: "train", namespace: "default", localQueue: "lq", state: etaStatePending, created: etaNow.Format(time.RFC3339), requests: resourceList{"cpu": cpu * 1000}}
+}
+
+func TestEstimateAdmission_FitsFreeQuota(t *testing.T) {
+	est := estimateAdmission(etaTestTarget(4), "cq", etaTestClusterQueue(t, "16", "8", ""), nil, map[string]string{"default/lq": "cq"}, nil, etaNow)
+	if !est.WaitKnown || est.Wait != 0 || !strings.Contains(est.Summary, "next Kueue scheduling cycle") {
+		t.Errorf("unexpected estimate: %+v", est)
+	}
+	if len(est.Resources) != 1 || est.Resources[0].Requested != "4" || est.Resources[0].Quota != "16" || est.Resources[0].InUse != "8" {
+		t.Errorf("unexpected resources: %+v", est.Resources)
+	}
+}
+
+func TestEstimateAdmission_ExceedsQuota(t *testing.T) {
+	est := estimateAdmission(etaTestTarget(32), "cq", etaTestClusterQueue(t, "16", "0", "research"), nil, map[string]string{"default/lq": "cq"}, nil, etaNow)
+	if est.WaitKnown || !strings.Contains(est.Summary, "exceed the nominal quota") {
+		t.Errorf("unexpected summary: %+v", est)
+	}
+	if len(est.Notes) != 1 || !strings.Contains(est.Notes[0], "cohort research") {
+		t.Errorf("expected a cohort borrowing note, got %v", est.Notes)
+	}
+}
+
+func TestEstimateAdmission_ExceedsNodePools(t *testing.T) {
+	est := estimateAdmission(etaTestTarget(8), "cq", etaTestClusterQueue(t, "16", "0", ""), nil, map[string]string{"default/lq": "cq"}, resourceList{"cpu": 4000}, etaNow)
+	if est.WaitKnown || !strings.Contains(est.Summary, "autoscaling limits") || est.Resources[0].NodeLimit != "4" {
+		t.Errorf("unexpected estimate: %+v", est)
+	}
+}
+
+func TestEstimateAdmission_WaitsForRunningWorkloads(t *testing.T) {
+	lqToCQ := map[string]string{"default/lq": "cq"}
+	workloads := []etaWorkload{
+		// Two finished workloads that ran for 1h and 2h: median 2h.
+		etaTestWorkload(t, "done-1", 1, "1", 0, -10*time.Hour, -10*time.Hour, -9*time.Hour),
+		etaTestWorkload(t, "done-2", 1, "1", 0, -8*time.Hour, -8*time.Hour, -6*time.Hour),
+		// Running since 90m and 30m ago.
+		etaTestWorkload(t, "running-1", 4, "2", 0, -2*time.Hour, -90*time.Minute, 0),
+		etaTestWorkload(t, "running-2", 4, "2", 0, -time.Hour, -30*time.Minute, 0),
+		// Pending ahead (earlier, same priority) and behind (later).
+		etaTestWorkload(t, "ahead", 2, "2", 0, -time.Minute, 0, 0),
+		etaTestWorkload(t, "behind", 2, "2", 0, time.Minute, 0, 0),
+	}
+	// 16 CPUs in use, 4 ahead, 8 requested: the first running workload
+	// frees 8 CPUs at 30m, which is not enough; the second frees 8 more at 90m.
+	est := estimateAdmission(etaTestTarget(8), "cq", etaTestClusterQueue(t, "16", "16", ""), workloads, lqToCQ, nil, etaNow)
+	if est.WorkloadsAhead != 1 || est.Resources[0].Ahead != "4" {
+		t.Errorf("expected one workload ahead requesting 4 CPUs, got %d, %+v", est.WorkloadsAhead, est.Resources)
+	}
+	if !est.WaitKnown || est.Wait != 90*time.Minute {
+		t.Errorf("expected a 90m wait, got %+v", est)
+	}
+	if !strings.Contains(est.Summary, "~1h30m") || !strings.Contains(est.Summary, "2h") {
+		t.Errorf("unexpected summary: %s", est.Summary)
+	}
+}
+
+func TestEstimateAdmission_NoHistory(t *testing.T) {
+	workloads := []etaWorkload{etaTestWorkload(t, "running", 8, "2", 0, -time.Hour, -time.Hour, 0)}
+	est := estimateAdmission(etaTestTarget(8), "cq", etaTestClusterQueue(t, "16", "16", ""), workloads, map[string]string{"default/lq": "cq"}, nil, etaNow)
+	if est.WaitKnown || !strings.Contains(est.Summary, "no finished workloads") {
+		t.Errorf("unexpected estimate: %+v", est)
+	}
+}
+
+func TestEtaTargetFromSpec(t *testing.T) {
+	spec := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+---
+apiVersion: jobset.x-k8s.io/v1alpha2
+kind: JobSet
+metadata:
+  name: train
+  namespace: team-a
+  labels:
+    kueue.x-k8s.io/queue-name: lq
+spec:
+  replicatedJobs:
+  - name: workers
+    replicas: 2
+    template:
+      spec:
+        parallelism: 4
+        template:
+          spec:
+            priorityClassName: high
+            containers:
+            - name: c
+              resources:
+                requests:
+                  cpu: "2"
+                limits:
+                  cpu: "2"
+                  google.com/tpu: "4"
+`
+	path := filepath.Join(t.TempDir(), "train.yaml")
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	g.dynClient = newFakeDynamicClient(fakeObject("PriorityClass", "", "high", `{"value": 1000}`))
+
+	target, err := g.etaTargetFromSpec(context.Background(), path)
+	if err != nil {
+		t.Fatalf("etaTargetFromSpec() error = %v", err)
+	}
+	if target.name != "train" || target.namespace != "team-a" || target.localQueue != "lq" || target.priority != 1000 || target.state != etaStateNotSubmitted {
+		t.Errorf("unexpected target: %+v", target)
+	}
+	if target.requests["cpu"] != 16000 || target.requests["google.com/tpu"] != 32000 {
+		t.Errorf("unexpected requests: %v", target.requests)
+	}
+}
+
+func TestEtaTargetFromSpec_MissingQueueLabel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.yaml")
+	if err := os.WriteFile(path, []byte("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: single\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := newTestGKEOrchestrator(NewMockExecutor(nil)).etaTargetFromSpec(context.Background(), path)
+	if err == nil || !strings.Contains(err.Error(), "queue-name") {
+		t.Errorf("expected a missing queue label error, got %v", err)
+	}
+}
+
+func TestFormatWait(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		30 * time.Second:                "<1m",
+		5 * time.Minute:                 "5m",
+		90*time.Minute + 20*time.Second: "1h30m",
+		2 * time.Hour:                   "2h",
+	} {
+		if got := formatWait(d); got != want {
+			t.Errorf("formatWait(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
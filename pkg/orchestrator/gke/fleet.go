@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"k8s.io/client-go/rest"
+)
+
+// defaultFleetParallelism is the number of clusters a fleet status queries at
+// a time when no parallelism is set.
+const defaultFleetParallelism = 8
+
+// gkeFleetCluster is a cluster of `gcloud container clusters list`, with what
+// a client needs to reach its API server.
+type gkeFleetCluster struct {
+	Name       string `json:"name"`
+	Location   string `json:"location"`
+	Status     string `json:"status"`
+	Endpoint   string `json:"endpoint"`
+	MasterAuth struct {
+		ClusterCaCertificate string `json:"clusterCaCertificate"`
+	} `json:"masterAuth"`
+	projectID string
+}
+
+// newFleetKubeClient is replaced in tests to avoid reaching the clusters.
+var newFleetKubeClient = func(config *rest.Config) (KubeClient, error) {
+	return newDefaultKubeClient(config)
+}
+
+// FleetStatus lists the gcluster workloads of every running cluster of the
+// projects of opts, querying up to opts.Parallelism clusters at a time. The
+// clusters are reached directly at their endpoints with the access token of
+// the user, so the kubeconfig is neither read nor changed. A cluster that
+// cannot be queried does not stop the others; the errors are returned with
+// the workloads found.
+func (g *GKEOrchestrator) FleetStatus(ctx context.Context, opts orchestrator.FleetStatusOptions) ([]orchestrator.FleetWorkload, error) {
+	var clusters []gkeFleetCluster
+	for _, projectID := range opts.ProjectIDs {
+		res := g.executor.ExecuteCommand("gcloud", "container", "clusters", "list", "--project", projectID, "--format=json")
+		if res.ExitCode != 0 {
+			return nil, fmt.Errorf("gcloud container clusters list failed for project %s: %s", projectID, res.Stderr)
+		}
+		var found []gkeFleetCluster
+		if err := json.Unmarshal([]byte(res.Stdout), &found); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal clusters list of project %s: %w", projectID, err)
+		}
+		for _, c := range found {
+			if !strings.EqualFold(c.Status, "RUNNING") {
+				logging.Info("Skipping cluster '%s' of project %s, which is %s.", c.Name, projectID, c.Status)
+				continue
+			}
+			c.projectID = projectID
+			clusters = append(clusters, c)
+		}
+	}
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+
+	res := g.executor.ExecuteCommand("gcloud", "auth", "print-access-token")
+	token := strings.TrimSpace(res.Stdout)
+	if res.ExitCode != 0 || token == "" {
+		return nil, fmt.Errorf("failed to get an access token: %s", res.Stderr)
+	}
+
+	type clusterResult struct {
+		cluster   gkeFleetCluster
+		workloads []orchestrator.FleetWorkload
+		err       error
+	}
+	pending := make(chan gkeFleetCluster, len(clusters))
+	results := make(chan clusterResult, len(clusters))
+	var wg sync.WaitGroup
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultFleetParallelism
+	}
+	for range min(parallelism, len(clusters)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range pending {
+				workloads, err := g.fleetClusterWorkloads(ctx, c, token, opts)
+				results <- clusterResult{cluster: c, workloads: workloads, err: err}
+			}
+		}()
+	}
+	logging.Info("Querying %d cluster(s)...", len(clusters))
+	for _, c := range clusters {
+		pending <- c
+	}
+	close(pending)
+	wg.Wait()
+	close(results)
+
+	var workloads []orchestrator.FleetWorkload
+	var failures []string
+	for r := range results {
+		if r.err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s): %v", r.cluster.Name, r.cluster.projectID, r.err))
+			continue
+		}
+		workloads = append(workloads, r.workloads...)
+	}
+	slices.SortFunc(workloads, func(a, b orchestrator.FleetWorkload) int {
+		return cmp.Or(
+			cmp.Compare(a.ProjectID, b.ProjectID),
+			cmp.Compare(a.ClusterName, b.ClusterName),
+			cmp.Compare(a.ClusterLocation, b.ClusterLocation),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	if len(failures) > 0 {
+		slices.Sort(failures)
+		return workloads, fmt.Errorf("failed to query %d cluster(s):\n  %s", len(failures), strings.Join(failures, "\n  "))
+	}
+	return workloads, nil
+}
+
+// clusterRESTConfig returns the config of a client that reaches the API server
+// of cluster c with the access token token.
+func clusterRESTConfig(c gkeFleetCluster, token string) (*rest.Config, error) {
+	ca, err := base64.StdEncoding.DecodeString(c.MasterAuth.ClusterCaCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster CA certificate: %w", err)
+	}
+	return &rest.Config{
+		Host:            "https://" + c.Endpoint,
+		BearerToken:     token,
+		TLSClientConfig: rest.TLSClientConfig{CAData: ca},
+	}, nil
+}
+
+// fleetClusterWorkloads lists the gcluster workloads of cluster c that match
+// the filters of opts.
+func (g *GKEOrchestrator) fleetClusterWorkloads(ctx context.Context, c gkeFleetCluster, token string, opts orchestrator.FleetStatusOptions) ([]orchestrator.FleetWorkload, error) {
+	config, err := clusterRESTConfig(c, token)
+	if err != nil {
+		return nil, err
+	}
+	if g.impersonate != "" {
+		config.Impersonate = rest.ImpersonationConfig{UserName: g.impersonate}
+	}
+	client, err := newFleetKubeClient(config)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := client.ListJobSets(ctx, "gcluster.google.com/workload")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobsets across all namespaces: %w", err)
+	}
+
+	var workloads []orchestrator.FleetWorkload
+	for _, job := range jobs {
+		if opts.NameContains != "" && !strings.Contains(job.Name, opts.NameContains) {
+			continue
+		}
+		if opts.Status != "" && !strings.EqualFold(job.Status, opts.Status) {
+			continue
+		}
+		workloads = append(workloads, orchestrator.FleetWorkload{
+			ProjectID:       c.projectID,
+			ClusterName:     c.Name,
+			ClusterLocation: c.Location,
+			JobStatus:       job,
+		})
+	}
+	return workloads, nil
+}
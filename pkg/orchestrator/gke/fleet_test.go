@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestFleetStatus(t *testing.T) {
+	orig := newFleetKubeClient
+	defer func() { newFleetKubeClient = orig }()
+	clients := map[string]KubeClient{
+		"https://10.0.0.1": &MockKubeClient{JobSets: []orchestrator.JobStatus{
+			{Name: "train-b", Namespace: "team-a", Status: "Running"},
+			{Name: "train-a", Namespace: "team-a", Status: "Succeeded"},
+			{Name: "eval", Namespace: "team-b", Status: "Running"},
+		}},
+		"https://10.0.0.2": &MockKubeClient{JobSets: []orchestrator.JobStatus{{Name: "train-c", Namespace: "default", Status: "Running"}}},
+		"https://10.0.0.3": &MockKubeClient{Err: errors.New("forbidden")},
+	}
+	newFleetKubeClient = func(config *rest.Config) (KubeClient, error) {
+		if config.BearerToken != "token" || string(config.TLSClientConfig.CAData) != "ca" {
+			t.Errorf("unexpected client config %+v", config)
+		}
+		return clients[config.Host], nil
+	}
+
+	g := newTestGKEOrchestrator(NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud container clusters list --project dev": {{Stdout: `[
+  {"name": "a100", "location": "us-central1", "status": "RUNNING", "endpoint": "10.0.0.1", "masterAuth": {"clusterCaCertificate": "Y2E="}},
+  {"name": "old", "location": "us-east1", "status": "STOPPING", "endpoint": "10.0.0.9"}
+]`}},
+		"gcloud container clusters list --project prod": {{Stdout: `[
+  {"name": "h100", "location": "europe-west4", "status": "RUNNING", "endpoint": "10.0.0.2", "masterAuth": {"clusterCaCertificate": "Y2E="}},
+  {"name": "broken", "location": "us-west1", "status": "RUNNING", "endpoint": "10.0.0.3", "masterAuth": {"clusterCaCertificate": "Y2E="}}
+]`}},
+		"gcloud auth print-access-token": {{Stdout: "token\n"}},
+	}))
+
+	got, err := g.FleetStatus(context.Background(), orchestrator.FleetStatusOptions{ProjectIDs: []string{"prod", "dev"}, Parallelism: 2, Status: "running", NameContains: "train"})
+	if err == nil || !strings.Contains(err.Error(), "broken (prod): failed to list jobsets across all namespaces: forbidden") {
+		t.Errorf("expected the failure of cluster broken, got %v", err)
+	}
+	want := []orchestrator.FleetWorkload{
+		{ProjectID: "dev", ClusterName: "a100", ClusterLocation: "us-central1", JobStatus: orchestrator.JobStatus{Name: "train-b", Namespace: "team-a", Status: "Running"}},
+		{ProjectID: "prod", ClusterName: "h100", ClusterLocation: "europe-west4", JobStatus: orchestrator.JobStatus{Name: "train-c", Namespace: "default", Status: "Running"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FleetStatus() = %+v, want %+v", got, want)
+	}
+}
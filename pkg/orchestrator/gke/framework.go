@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+const (
+	pytorchMasterPort    = 29500
+	jaxCoordinatorPort   = 1234
+	jobIndexFieldPath    = "metadata.labels['jobset.sigs.k8s.io/job-index']"
+	frameworkJobIndexEnv = "JOB_INDEX"
+)
+
+// addFrameworkEnv sets the environment variables a distributed training
+// framework expects to find its peers. The coordinator is the first pod of
+// the first slice, reached through its DNS hostname (see addHeadlessService).
+// The rank of a pod depends on its slice and its index within the slice, so
+// it is computed by a shell prelude from JOB_INDEX, set through the downward
+// API, and JOB_COMPLETION_INDEX, set by Kubernetes for Indexed Jobs.
+// Variables set with --env take precedence.
+func addFrameworkEnv(opts *ManifestOptions, job orchestrator.JobDefinition) {
+	if job.Framework == "" {
+		return
+	}
+	nodes := job.NumSlices * job.NodesPerSlice
+	host := func(slice, pod int) string {
+		return fmt.Sprintf("%s-main-job-%d-%d.%s", opts.WorkloadName, slice, pod, opts.WorkloadName)
+	}
+	coordinator := host(0, 0)
+
+	env := map[string]string{"NNODES": strconv.Itoa(nodes)}
+	rankVars := []string{"NODE_RANK"}
+	switch job.Framework {
+	case orchestrator.FrameworkPyTorch:
+		env["MASTER_ADDR"] = coordinator
+		env["MASTER_PORT"] = strconv.Itoa(pytorchMasterPort)
+		env["WORLD_SIZE"] = strconv.Itoa(nodes)
+		rankVars = append(rankVars, "RANK")
+	case orchestrator.FrameworkJAX:
+		env["JAX_COORDINATOR_ADDRESS"] = fmt.Sprintf("%s:%d", coordinator, jaxCoordinatorPort)
+		env["JAX_NUM_PROCESSES"] = strconv.Itoa(nodes)
+		rankVars = append(rankVars, "JAX_PROCESS_ID")
+	case orchestrator.FrameworkMPI:
+		hosts := make([]string, 0, nodes)
+		for s := 0; s < job.NumSlices; s++ {
+			for p := 0; p < job.NodesPerSlice; p++ {
+				hosts = append(hosts, host(s, p))
+			}
+		}
+		env["MPI_HOSTS"] = strings.Join(hosts, ",")
+	}
+
+	if opts.Env == nil {
+		opts.Env = map[string]string{}
+	}
+	for k, v := range env {
+		if _, ok := job.Env[k]; !ok {
+			opts.Env[k] = v
+		}
+	}
+	if opts.FieldEnv == nil {
+		opts.FieldEnv = map[string]string{}
+	}
+	opts.FieldEnv[frameworkJobIndexEnv] = jobIndexFieldPath
+
+	rank := fmt.Sprintf("$((%s * %d + JOB_COMPLETION_INDEX))", frameworkJobIndexEnv, job.NodesPerSlice)
+	lines := make([]string, 0, len(rankVars))
+	for _, v := range rankVars {
+		if _, ok := job.Env[v]; ok {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("export %s=%s", v, rank))
+	}
+	if opts.CommandPrelude != "" {
+		lines = append([]string{opts.CommandPrelude}, lines...)
+	}
+	opts.CommandPrelude = strings.Join(lines, "\n")
+}
+
+// preludedCommand is containerCommand with a shell prelude run first. An
+// exec-form command is run by a shell that executes it after the prelude, so
+// that its arguments are passed through unchanged.
+func preludedCommand(commandToRun string, commandArgs []string, prelude string) (command, args []string) {
+	if len(commandArgs) > 0 {
+		return []string{"/bin/sh", "-c", prelude + "\nexec \"$@\"", "sh"}, slices.Clone(commandArgs)
+	}
+	command, args = containerCommand(commandToRun, nil)
+	if len(args) > 0 {
+		return command, []string{prelude + "\n" + args[0]}
+	}
+	return []string{"/bin/bash", "-c", prelude + "\n" + commandToRun}, nil
+}
+
+// sortedFieldEnvVars returns variables set from pod fields, sorted by name.
+func sortedFieldEnvVars(fieldEnv map[string]string) []EnvVar {
+	vars := sortedEnvVars(fieldEnv)
+	for i := range vars {
+		vars[i].FieldPath, vars[i].Value = vars[i].Value, ""
+	}
+	return vars
+}
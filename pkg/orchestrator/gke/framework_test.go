@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestAddFrameworkEnv(t *testing.T) {
+	opts := ManifestOptions{WorkloadName: "train", Env: map[string]string{}}
+	addFrameworkEnv(&opts, orchestrator.JobDefinition{Framework: orchestrator.FrameworkPyTorch, NumSlices: 2, NodesPerSlice: 4})
+	for k, want := range map[string]string{
+		"MASTER_ADDR": "train-main-job-0-0.train",
+		"MASTER_PORT": "29500",
+		"WORLD_SIZE":  "8",
+		"NNODES":      "8",
+	} {
+		if got := opts.Env[k]; got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+	if got := opts.FieldEnv["JOB_INDEX"]; got != jobIndexFieldPath {
+		t.Errorf("JOB_INDEX field path = %q", got)
+	}
+	wantPrelude := "export NODE_RANK=$((JOB_INDEX * 4 + JOB_COMPLETION_INDEX))\nexport RANK=$((JOB_INDEX * 4 + JOB_COMPLETION_INDEX))"
+	if opts.CommandPrelude != wantPrelude {
+		t.Errorf("prelude = %q, want %q", opts.CommandPrelude, wantPrelude)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train", Env: map[string]string{"WORLD_SIZE": "16", "RANK": "0"}}
+	addFrameworkEnv(&opts, orchestrator.JobDefinition{Framework: orchestrator.FrameworkPyTorch, NumSlices: 1, NodesPerSlice: 2, Env: map[string]string{"WORLD_SIZE": "16", "RANK": "0"}})
+	if opts.Env["WORLD_SIZE"] != "16" {
+		t.Errorf("expected --env to take precedence, got WORLD_SIZE=%q", opts.Env["WORLD_SIZE"])
+	}
+	if opts.CommandPrelude != "export NODE_RANK=$((JOB_INDEX * 2 + JOB_COMPLETION_INDEX))" {
+		t.Errorf("expected RANK to be left to --env, got prelude %q", opts.CommandPrelude)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	addFrameworkEnv(&opts, orchestrator.JobDefinition{Framework: orchestrator.FrameworkJAX, NumSlices: 1, NodesPerSlice: 2})
+	if opts.Env["JAX_COORDINATOR_ADDRESS"] != "train-main-job-0-0.train:1234" || opts.Env["JAX_NUM_PROCESSES"] != "2" {
+		t.Errorf("unexpected JAX env %v", opts.Env)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	addFrameworkEnv(&opts, orchestrator.JobDefinition{Framework: orchestrator.FrameworkMPI, NumSlices: 2, NodesPerSlice: 1})
+	if got := opts.Env["MPI_HOSTS"]; got != "train-main-job-0-0.train,train-main-job-1-0.train" {
+		t.Errorf("MPI_HOSTS = %q", got)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	addFrameworkEnv(&opts, orchestrator.JobDefinition{NumSlices: 1, NodesPerSlice: 2})
+	if opts.Env != nil || opts.FieldEnv != nil || opts.CommandPrelude != "" {
+		t.Errorf("expected no changes without --framework, got %+v", opts)
+	}
+}
+
+func TestPreludedCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		args        []string
+		wantCommand []string
+		wantArgs    []string
+	}{
+		{
+			name:        "shell command",
+			command:     "python train.py",
+			wantCommand: []string{"/bin/bash", "-c", "export RANK=1\npython train.py"},
+		},
+		{
+			name:        "multi-line script",
+			command:     "cd /app\npython train.py\n",
+			wantCommand: []string{"/bin/bash", "-e", "-c"},
+			wantArgs:    []string{"export RANK=1\ncd /app\npython train.py"},
+		},
+		{
+			name:        "exec form",
+			args:        []string{"python", "train.py", "--lr", "0.1"},
+			wantCommand: []string{"/bin/sh", "-c", "export RANK=1\nexec \"$@\"", "sh"},
+			wantArgs:    []string{"python", "train.py", "--lr", "0.1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			command, args := preludedCommand(tc.command, tc.args, "export RANK=1")
+			if !reflect.DeepEqual(command, tc.wantCommand) || !reflect.DeepEqual(args, tc.wantArgs) {
+				t.Errorf("preludedCommand() = %q, %q, want %q, %q", command, args, tc.wantCommand, tc.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// CollectGarbage sweeps every running cluster of the projects of opts for
+// finished gcluster workloads that completed or failed longer than opts.OlderThan ago,
+// and deletes them unless opts.DryRun is set. Running and suspended
+// workloads are never removed. A cluster that cannot be swept does not stop
+// the sweep of the others; the errors are returned with the workloads found.
+func (g *GKEOrchestrator) CollectGarbage(ctx context.Context, opts orchestrator.GCOptions) ([]orchestrator.ExpiredWorkload, error) {
+	clusters, err := g.gcClusters(ctx, opts.ProjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := g.now().Add(-opts.OlderThan)
+	var expired []orchestrator.ExpiredWorkload
+	var failures []string
+	for _, c := range clusters {
+		logging.Info("Sweeping cluster '%s' of project %s for workloads finished before %s...", c.Name, c.projectID, cutoff.Format(time.RFC3339))
+		found, err := g.expiredWorkloads(ctx, c.ClusterStatus, c.projectID, cutoff)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s): %v", c.Name, c.projectID, err))
+			continue
+		}
+		if !opts.DryRun {
+			for i := range found {
+				w := &found[i]
+				cancelOpts := orchestrator.CancelOptions{ProjectID: c.projectID, ClusterName: w.ClusterName, ClusterLocation: w.ClusterLocation}
+				if err := g.cancelWorkload(ctx, w.Name, w.Namespace, cancelOpts); err != nil {
+					w.Error = err.Error()
+					continue
+				}
+				w.Deleted = true
+			}
+		}
+		expired = append(expired, found...)
+	}
+
+	if len(failures) > 0 {
+		return expired, fmt.Errorf("failed to sweep %d cluster(s):\n  %s", len(failures), strings.Join(failures, "\n  "))
+	}
+	return expired, nil
+}
+
+// gcCluster is a cluster swept by a garbage collection, and its project.
+type gcCluster struct {
+	orchestrator.ClusterStatus
+	projectID string
+}
+
+// gcClusters returns the running clusters of projectIDs.
+func (g *GKEOrchestrator) gcClusters(ctx context.Context, projectIDs []string) ([]gcCluster, error) {
+	var clusters []gcCluster
+	for _, projectID := range projectIDs {
+		found, err := g.ListEnvironments(ctx, orchestrator.ListOptions{ProjectID: projectID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the clusters of project %s: %w", projectID, err)
+		}
+		for _, c := range found {
+			if !strings.EqualFold(c.Status, "RUNNING") {
+				logging.Info("Skipping cluster '%s' of project %s, which is %s.", c.Name, projectID, c.Status)
+				continue
+			}
+			clusters = append(clusters, gcCluster{ClusterStatus: c, projectID: projectID})
+		}
+	}
+	return clusters, nil
+}
+
+// expiredWorkloads lists the gcluster workloads of cluster c that finished
+// before cutoff.
+func (g *GKEOrchestrator) expiredWorkloads(ctx context.Context, c orchestrator.ClusterStatus, projectID string, cutoff time.Time) ([]orchestrator.ExpiredWorkload, error) {
+	if err := g.configureKubectl(ctx, c.Name, c.Location, projectID); err != nil {
+		return nil, err
+	}
+	client, err := g.getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := client.ListJobSets(ctx, "gcluster.google.com/workload")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobsets across all namespaces: %w", err)
+	}
+
+	var expired []orchestrator.ExpiredWorkload
+	for _, job := range jobs {
+		if job.Status != "Succeeded" && job.Status != "Failed" {
+			continue
+		}
+		finished := job.CompletionTime
+		if finished == "" {
+			finished = job.CreationTime
+		}
+		finishedAt, err := time.Parse(time.RFC3339, finished)
+		if err != nil {
+			logging.Warn("Skipping workload '%s' in cluster '%s': invalid finish time %q.", job.Name, c.Name, finished)
+			continue
+		}
+		if !finishedAt.Before(cutoff) {
+			continue
+		}
+		expired = append(expired, orchestrator.ExpiredWorkload{
+			ProjectID:       projectID,
+			ClusterName:     c.Name,
+			ClusterLocation: c.Location,
+			Namespace:       job.Namespace,
+			Name:            job.Name,
+			Status:          job.Status,
+			FinishedAt:      finishedAt,
+		})
+	}
+	return expired, nil
+}
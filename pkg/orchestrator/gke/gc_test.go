@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	var commands []string
+	exec := &mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		commands = append(commands, cmd)
+		switch {
+		case strings.HasPrefix(cmd, "gcloud container clusters list"):
+			return shell.CommandResult{Stdout: `[
+  {"name": "train", "location": "us-central1", "status": "RUNNING"},
+  {"name": "old", "location": "us-east1", "status": "STOPPING"}
+]`}
+		case strings.HasPrefix(cmd, "gcloud container clusters get-credentials train"):
+			return shell.CommandResult{}
+		}
+		t.Fatalf("unexpected command: %s", cmd)
+		return shell.CommandResult{}
+	}}
+	kube := &MockKubeClient{JobSets: []orchestrator.JobStatus{
+		{Name: "done", Namespace: "team-a", Status: "Succeeded", CreationTime: old, CompletionTime: old},
+		{Name: "failed", Namespace: "team-b", Status: "Failed", CreationTime: old},
+		{Name: "fresh", Namespace: "team-a", Status: "Succeeded", CreationTime: old, CompletionTime: recent},
+		{Name: "running", Namespace: "team-a", Status: "Running", CreationTime: old},
+	}}
+	orc := NewGKEOrchestrator()
+	orc.SetExecutor(exec)
+	orc.SetKubeClient(kube)
+	client := newFakeDynamicClient()
+	orc.dynClient = client
+	fakeGets(client, "JobSet", `{"status": {"conditions": [{"type": "Completed", "status": "True", "lastTransitionTime": "2026-01-01T00:00:00Z"}]}}`)
+
+	opts := orchestrator.GCOptions{ProjectIDs: []string{"p"}, OlderThan: 14 * 24 * time.Hour, DryRun: true}
+	expired, err := orc.CollectGarbage(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("CollectGarbage() error = %v", err)
+	}
+	var names []string
+	for _, w := range expired {
+		names = append(names, w.Namespace+"/"+w.Name)
+		if w.Deleted || w.ProjectID != "p" || w.ClusterName != "train" || w.ClusterLocation != "us-central1" {
+			t.Errorf("unexpected dry-run workload %+v", w)
+		}
+	}
+	if want := []string{"team-a/done", "team-b/failed"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expired workloads = %v, want %v", names, want)
+	}
+	if len(kube.DeletedJobSets) != 0 {
+		t.Errorf("dry run deleted %v", kube.DeletedJobSets)
+	}
+
+	opts.DryRun = false
+	expired, err = orc.CollectGarbage(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("CollectGarbage() error = %v", err)
+	}
+	for _, w := range expired {
+		if !w.Deleted {
+			t.Errorf("workload %s was not deleted: %s", w.Name, w.Error)
+		}
+	}
+	if want := []string{"team-a/done", "team-b/failed"}; !reflect.DeepEqual(kube.DeletedJobSets, want) {
+		t.Errorf("deleted jobsets = %v, want %v", kube.DeletedJobSets, want)
+	}
+	for _, cmd := range commands {
+		if strings.Contains(cmd, "get-credentials old") {
+			t.Errorf("cluster 'old' is not running and should be skipped, ran %s", cmd)
+		}
+	}
+}
+
+func TestCollectGarbage_ClusterErrors(t *testing.T) {
+	exec := &mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "gcloud container clusters list"):
+			return shell.CommandResult{Stdout: `[{"name": "a", "location": "l", "status": "RUNNING"}, {"name": "b", "location": "l", "status": "RUNNING"}]`}
+		case strings.HasPrefix(cmd, "gcloud container clusters get-credentials a"):
+			return shell.CommandResult{ExitCode: 1, Stderr: "permission denied"}
+		}
+		return shell.CommandResult{}
+	}}
+	old := time.Now().Add(-30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	kube := &MockKubeClient{JobSets: []orchestrator.JobStatus{{Name: "done", Namespace: "default", Status: "Failed", CompletionTime: old}}}
+	orc := NewGKEOrchestrator()
+	orc.SetExecutor(exec)
+	orc.SetKubeClient(kube)
+
+	expired, err := orc.CollectGarbage(context.Background(), orchestrator.GCOptions{ProjectIDs: []string{"p"}, OlderThan: time.Hour, DryRun: true})
+	if err == nil || !strings.Contains(err.Error(), "a (p): failed to get GKE cluster credentials: permission denied") {
+		t.Errorf("expected the error of cluster a, got %v", err)
+	}
+	if len(expired) != 1 || expired[0].ClusterName != "b" {
+		t.Errorf("cluster b should still be swept, got %+v", expired)
+	}
+}
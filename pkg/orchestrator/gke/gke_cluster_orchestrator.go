@@ -15,13 +15,16 @@
 package gke
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"hpc-toolkit/pkg/orchestrator"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ListEnvironments discovers all available target environments.
-func (g *GKEOrchestrator) ListEnvironments(opts orchestrator.ListOptions) ([]orchestrator.ClusterStatus, error) {
+func (g *GKEOrchestrator) ListEnvironments(ctx context.Context, opts orchestrator.ListOptions) ([]orchestrator.ClusterStatus, error) {
 	result := g.executor.ExecuteCommand("gcloud", "container", "clusters", "list", "--project", opts.ProjectID, "--format=json")
 	if result.ExitCode != 0 {
 		return nil, fmt.Errorf("gcloud container clusters list failed: %s", result.Stderr)
@@ -36,7 +39,7 @@ func (g *GKEOrchestrator) ListEnvironments(opts orchestrator.ListOptions) ([]orc
 }
 
 // GetClusterInfo shows summarized status of the current target cluster's resources.
-func (g *GKEOrchestrator) GetClusterInfo(name string, opts orchestrator.ListOptions) (string, error) {
+func (g *GKEOrchestrator) GetClusterInfo(ctx context.Context, name string, opts orchestrator.ListOptions) (string, error) {
 	result := g.executor.ExecuteCommand("gcloud", "container", "clusters", "describe", name, "--location="+opts.ClusterLocation, "--project", opts.ProjectID, "--format=json")
 	if result.ExitCode != 0 {
 		return "", fmt.Errorf("gcloud container clusters describe failed: %s", result.Stderr)
@@ -51,7 +54,7 @@ func (g *GKEOrchestrator) GetClusterInfo(name string, opts orchestrator.ListOpti
 }
 
 // DescribeEnvironment details the specific environment exhaustively.
-func (g *GKEOrchestrator) DescribeEnvironment(name string, opts orchestrator.ListOptions) (string, error) {
+func (g *GKEOrchestrator) DescribeEnvironment(ctx context.Context, name string, opts orchestrator.ListOptions) (string, error) {
 	result := g.executor.ExecuteCommand("gcloud", "container", "clusters", "describe", name, "--location="+opts.ClusterLocation, "--project", opts.ProjectID, "--format=yaml")
 	if result.ExitCode != 0 {
 		return "", fmt.Errorf("gcloud container clusters describe failed: %s", result.Stderr)
@@ -60,16 +63,11 @@ func (g *GKEOrchestrator) DescribeEnvironment(name string, opts orchestrator.Lis
 }
 
 // ListVolumes discovers and lists available storage options (PVCs labeled ghpc_role=file-system).
-func (g *GKEOrchestrator) ListVolumes(opts orchestrator.ListOptions) ([]orchestrator.VolumeStatus, error) {
+func (g *GKEOrchestrator) ListVolumes(ctx context.Context, opts orchestrator.ListOptions) ([]orchestrator.VolumeStatus, error) {
 	// Query PVCs with the managed role label
-	result := g.executor.ExecuteCommand("kubectl", "get", "pvc", "--all-namespaces", "-l", "ghpc_role=file-system", "-o", "json")
-	if result.ExitCode != 0 {
-		return nil, fmt.Errorf("kubectl get pvc failed: %s", result.Stderr)
-	}
-
 	var pvcList gkePVCList
-	if err := json.Unmarshal([]byte(result.Stdout), &pvcList); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal PVC list: %w", err)
+	if err := g.listResources(ctx, &pvcList, pvcGVR, "", metav1.ListOptions{LabelSelector: "ghpc_role=file-system"}); err != nil {
+		return nil, fmt.Errorf("failed to list PVCs: %w", err)
 	}
 
 	var volumes []orchestrator.VolumeStatus
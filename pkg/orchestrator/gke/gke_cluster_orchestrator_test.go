@@ -15,6 +15,8 @@
 package gke
 
 import (
+	"context"
+
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
 	"strings"
@@ -29,7 +31,7 @@ func TestListEnvironments(t *testing.T) {
 	}
 	orc := &GKEOrchestrator{executor: NewMockExecutor(mockResponses)}
 
-	envs, err := orc.ListEnvironments(orchestrator.ListOptions{ProjectID: "test-project"})
+	envs, err := orc.ListEnvironments(context.Background(), orchestrator.ListOptions{ProjectID: "test-project"})
 	if err != nil {
 		t.Fatalf("ListEnvironments failed: %v", err)
 	}
@@ -51,7 +53,7 @@ func TestGetClusterInfo(t *testing.T) {
 	}
 	orc := &GKEOrchestrator{executor: NewMockExecutor(mockResponses)}
 
-	info, err := orc.GetClusterInfo("cluster-1", orchestrator.ListOptions{ClusterLocation: "us-central1-a", ProjectID: "test-project"})
+	info, err := orc.GetClusterInfo(context.Background(), "cluster-1", orchestrator.ListOptions{ClusterLocation: "us-central1-a", ProjectID: "test-project"})
 	if err != nil {
 		t.Fatalf("GetClusterInfo failed: %v", err)
 	}
@@ -72,7 +74,7 @@ func TestDescribeEnvironment(t *testing.T) {
 	}
 	orc := &GKEOrchestrator{executor: NewMockExecutor(mockResponses)}
 
-	desc, err := orc.DescribeEnvironment("cluster-1", orchestrator.ListOptions{ClusterLocation: "us-central1-a", ProjectID: "test-project"})
+	desc, err := orc.DescribeEnvironment(context.Background(), "cluster-1", orchestrator.ListOptions{ClusterLocation: "us-central1-a", ProjectID: "test-project"})
 	if err != nil {
 		t.Fatalf("DescribeEnvironment failed: %v", err)
 	}
@@ -83,14 +85,13 @@ func TestDescribeEnvironment(t *testing.T) {
 }
 
 func TestListVolumes(t *testing.T) {
-	mockResponses := map[string][]shell.CommandResult{
-		"kubectl get pvc": {
-			{ExitCode: 0, Stdout: `{"items": [{"metadata": {"name": "pvc-1"}, "spec": {"storageClassName": "standard"}} ]}`},
-		},
-	}
-	orc := &GKEOrchestrator{executor: NewMockExecutor(mockResponses)}
+	// Only the claims labeled as file systems are listed.
+	orc := &GKEOrchestrator{executor: NewMockExecutor(nil), dynClient: newFakeDynamicClient(
+		fakeObject("PersistentVolumeClaim", "default", "pvc-1", `{"metadata": {"labels": {"ghpc_role": "file-system"}}, "spec": {"storageClassName": "standard"}}`),
+		fakeObject("PersistentVolumeClaim", "default", "scratch", `{"spec": {"storageClassName": "standard"}}`),
+	)}
 
-	vols, err := orc.ListVolumes(orchestrator.ListOptions{})
+	vols, err := orc.ListVolumes(context.Background(), orchestrator.ListOptions{})
 	if err != nil {
 		t.Fatalf("ListVolumes failed: %v", err)
 	}
@@ -18,12 +18,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/imagebuilder"
 	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/manifestsign"
 	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/retry"
 	"hpc-toolkit/pkg/shell"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
@@ -34,13 +39,14 @@ import (
 
 	"github.com/google/safetext/yamltemplate"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-
-	"gopkg.in/yaml.v2"
-	k8syaml "sigs.k8s.io/yaml"
 )
 
 const (
@@ -55,11 +61,22 @@ const (
 
 	// maxLogRequests is the maximum concurrent log streams allowed by the GKE logs CLI.
 	maxLogRequests = 10
+
+	// stdoutManifestPath as an output manifest path prints the manifest.
+	stdoutManifestPath = "-"
+
+	// experimentLabel groups the JobSets submitted under the same --experiment.
+	experimentLabel = "gcluster.google.com/experiment"
 )
 
+// dryRunOut receives the summary and manifest printed by a plain --dry-run.
+var dryRunOut io.Writer = os.Stdout
+
 func NewGKEOrchestrator() *GKEOrchestrator {
 	return &GKEOrchestrator{
 		executor:                 &DefaultExecutor{},
+		httpClient:               &http.Client{Timeout: 30 * time.Second},
+		clock:                    realClock{},
 		machineTypeClient:        &DefaultMachineTypeClient{},
 		acceleratorToMachineType: make(map[string]string),
 		machineCapCache:          make(map[string]MachineTypeCap),
@@ -81,63 +98,173 @@ func (g *GKEOrchestrator) SetKubeClient(c KubeClient) {
 	g.kubeClient = c
 }
 
+// SetHTTPClient sets the client the Kueue and JobSet release manifests are
+// downloaded with.
+func (g *GKEOrchestrator) SetHTTPClient(c *http.Client) {
+	g.httpClient = c
+}
+
+// SetClock sets the clock the orchestrator reads the time from and waits on
+// between polls.
+func (g *GKEOrchestrator) SetClock(c Clock) {
+	g.clock = c
+}
+
+// retryPolicy returns p waiting on the orchestrator's clock.
+func (g *GKEOrchestrator) retryPolicy(p retry.Policy) retry.Policy {
+	if g.clock != nil {
+		p.Clock = g.clock
+	}
+	return p
+}
+
+// now returns the current time of the orchestrator's clock.
+func (g *GKEOrchestrator) now() time.Time {
+	if g.clock == nil {
+		return time.Now()
+	}
+	return g.clock.Now()
+}
+
+// sleep waits for d on the orchestrator's clock.
+func (g *GKEOrchestrator) sleep(d time.Duration) {
+	if g.clock == nil {
+		time.Sleep(d)
+		return
+	}
+	g.clock.Sleep(d)
+}
+
 // SubmitJob submits a job to the GKE cluster. It processes the job definition,
 // creates the required Kubernetes manifests (JobSet), and applies them to the cluster.
-func (g *GKEOrchestrator) SubmitJob(job orchestrator.JobDefinition) error {
+// Commands, registry requests and downloads stop once ctx is done; objects
+// applied by an interrupted apply are deleted again.
+func (g *GKEOrchestrator) SubmitJob(ctx context.Context, job orchestrator.JobDefinition) (result orchestrator.SubmitResult, err error) {
 	logging.Info("Starting gcluster job submit workflow...")
+	defer func() {
+		if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+			err = fmt.Errorf("job submission canceled: %w (%v)", ctx.Err(), err)
+		}
+	}()
+	if g.impersonate != "" && job.BaseImage != "" && !job.IsDryRun() {
+		return orchestrator.SubmitResult{}, fmt.Errorf("image builds push with your own credentials and cannot be checked while impersonating %s; pass a pre-built --image instead", g.impersonate)
+	}
+	if err := orchestrator.AssignRunMetadata(&job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
 
 	sm := &StorageManager{orchestrator: g}
 	if err := sm.ValidateMounts(job.RawMounts); err != nil {
-		return err
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := sm.ValidateObjectMounts(job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := validateSecretEnv(job); err != nil {
+		return orchestrator.SubmitResult{}, err
 	}
 
-	var err error
-	err = g.initializeJobSubmission(&job)
-	if err != nil {
-		return err
+	if err := g.initializeJobSubmission(ctx, &job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := sm.CheckGCSFuseDriver(job); err != nil {
+		return orchestrator.SubmitResult{}, err
 	}
 
 	if err := g.fetchClusterState(&job); err != nil {
-		return err
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := g.selectComputeTypeByGPUMemory(&job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := g.discoverComputeType(&job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := g.selectComputeType(&job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := g.configureClusterEnvironment(ctx, &job); err != nil {
+		return orchestrator.SubmitResult{}, err
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := g.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := g.resolveHardwareRequirements(ctx, &job)
 	if err != nil {
-		return err
+		return orchestrator.SubmitResult{}, err
 	}
-	if err := g.validateJobConflicts(job.WorkloadName, job.ClusterName, job.ClusterLocation, job.ProjectID); err != nil {
-		return err
+	if err := g.validateImageArchitecture(job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := g.enforceManagedProfile(job, profile); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if !job.DryRun {
+		if err := g.validateJobConflicts(ctx, job); err != nil {
+			return orchestrator.SubmitResult{}, err
+		}
 	}
 
-	fullImageName, err := g.BuildContainerImage(job)
-	if err != nil {
+	if err := g.resolveSecretEnv(job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := g.checkBaseImagePolicy(&job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	var fullImageName string
+	err = g.runStep(ctx, stepBuild, job.StepTimeouts.Build, "", func(ctx context.Context) (err error) {
+		fullImageName, err = g.BuildContainerImage(ctx, job)
 		return err
+	})
+	var timeoutErr *StepTimeoutError
+	if errors.As(err, &timeoutErr) && timeoutErr.Image == "" {
+		// A build that pushed its image before it noticed the deadline names
+		// it, so a resubmission can skip the build.
+		timeoutErr.Image = builtImage(job, fullImageName)
+	}
+	if err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if !job.IsDryRun() {
+		if err := g.verifyWorkloadImage(job, fullImageName); err != nil {
+			return orchestrator.SubmitResult{}, err
+		}
+	}
+	if job.DryRun {
+		printDryRunSummary(job, fullImageName)
 	}
 
-	if err := g.generateAndSubmitManifests(job, fullImageName, profile, isDynamicSlicing, isStaticSlicing); err != nil {
-		return err
+	if err := g.generateAndSubmitManifests(ctx, job, fullImageName, profile, isDynamicSlicing, isStaticSlicing); err != nil {
+		return orchestrator.SubmitResult{}, err
 	}
 
-	if job.DryRunManifest == "" {
+	result = g.submitResult(job, fullImageName)
+	if g.impersonate != "" && !job.IsDryRun() {
+		logging.Info("The cluster accepted workload '%s' from %s in a server-side dry run. Nothing was created.", job.WorkloadName, g.impersonate)
+		return result, nil
+	}
+	if !job.IsDryRun() {
 		g.printConsoleLinks(job)
 	}
 
-	if job.AwaitJobCompletion && job.DryRunManifest == "" {
-		err = g.awaitJobCompletion(job.WorkloadName, job.ClusterName, job.ClusterLocation, job.ProjectID, job.Timeout)
+	if job.AwaitJobCompletion && !job.IsDryRun() {
+		err = g.awaitJobCompletion(ctx, job.WorkloadName, job.ClusterName, job.ClusterLocation, job.ClusterProjectID, job.Timeout, job.NoQueue)
+		if err != nil && ctx.Err() != nil {
+			// The workload was submitted; only the wait was interrupted.
+			return result, fmt.Errorf("stopped waiting for job '%s', which keeps running; follow it with 'gcluster job attach %s': %w", job.WorkloadName, job.WorkloadName, ctx.Err())
+		}
 		if err != nil {
-			return err
+			return orchestrator.SubmitResult{}, err
 		}
 	}
 	logging.Info("gcluster job submit workflow completed.")
 
-	return nil
+	return result, nil
 }
 
 // ListJobs retrieves a list of jobs in the GKE cluster.
 // It filters jobs based on the provided ListOptions.
-func (g *GKEOrchestrator) ListJobs(opts orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
+func (g *GKEOrchestrator) ListJobs(ctx context.Context, opts orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
 	logging.Info("Listing jobs in cluster '%s'...", opts.ClusterName)
-	if err := g.configureKubectl(opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
 		return nil, err
 	}
 
@@ -145,7 +272,7 @@ func (g *GKEOrchestrator) ListJobs(opts orchestrator.ListOptions) ([]orchestrato
 		return nil, err
 	}
 
-	list, err := g.kubeClient.ListJobSets("gcluster.google.com/workload")
+	list, err := g.kubeClient.ListJobSets(ctx, "gcluster.google.com/workload")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list jobsets across all namespaces: %w", err)
 	}
@@ -160,16 +287,21 @@ func (g *GKEOrchestrator) ListJobs(opts orchestrator.ListOptions) ([]orchestrato
 			continue
 		}
 
+		if opts.Experiment != "" && job.Experiment != opts.Experiment {
+			continue
+		}
+
 		filteredJobs = append(filteredJobs, job)
 	}
 
+	g.addKueueStates(ctx, filteredJobs)
 	return filteredJobs, nil
 }
 
 // CancelJob deletes a job from the GKE cluster by name.
 // Jobs are filtered via cluster name and location provided through CancelOptions.
-func (g *GKEOrchestrator) CancelJob(name string, opts orchestrator.CancelOptions) error {
-	if err := g.configureKubectl(opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+func (g *GKEOrchestrator) CancelJob(ctx context.Context, name string, opts orchestrator.CancelOptions) error {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
 		return err
 	}
 
@@ -178,12 +310,26 @@ func (g *GKEOrchestrator) CancelJob(name string, opts orchestrator.CancelOptions
 	}
 
 	// Find the job to get its namespace
-	foundNamespace, err := g.kubeClient.GetJobNamespace(name)
+	foundNamespace, err := g.kubeClient.GetJobNamespace(ctx, name)
 	if err != nil {
 		return err
 	}
 
-	status, err := g.getJobSetStatus(name, foundNamespace)
+	return g.cancelWorkload(ctx, name, foundNamespace, opts)
+}
+
+func (g *GKEOrchestrator) cancelWorkload(ctx context.Context, name, foundNamespace string, opts orchestrator.CancelOptions) error {
+	// Volume claims are read from the JobSet spec, so collect them before deleting it.
+	sm := &StorageManager{orchestrator: g}
+	var claims []string
+	if opts.DeleteVolumes {
+		var err error
+		if claims, err = sm.WorkloadClaims(ctx, foundNamespace, name); err != nil {
+			return err
+		}
+	}
+
+	status, err := g.getJobSetStatus(ctx, name, foundNamespace)
 	actionVerb := "Cancel"
 	if err == nil && (status == "Completed" || status == "Failed") {
 		actionVerb = "Cleanup"
@@ -192,27 +338,49 @@ func (g *GKEOrchestrator) CancelJob(name string, opts orchestrator.CancelOptions
 		logging.Info("Canceling job '%s' in cluster '%s'...", name, opts.ClusterName)
 	}
 
-	err = g.kubeClient.DeleteJobSet(foundNamespace, name)
+	err = g.kubeClient.DeleteJobSet(ctx, foundNamespace, name)
 	if err != nil {
 		return fmt.Errorf("%s operation failed for %s in namespace %s: %w", strings.ToLower(actionVerb), name, foundNamespace, err)
 	}
+
+	// The headless Service of --dns-hostnames is not owned by the JobSet.
+	if err := g.kubeClient.DeleteServices(ctx, foundNamespace, "gcluster.google.com/workload="+name); err != nil {
+		return err
+	}
+
+	// Pods are garbage collected with their own grace period after the JobSet
+	// is gone; delete them explicitly to shorten it.
+	if opts.Force || opts.GracePeriodSeconds != nil {
+		var grace int64
+		if !opts.Force {
+			grace = *opts.GracePeriodSeconds
+		}
+		selector := fmt.Sprintf("jobset.sigs.k8s.io/jobset-name=%s", name)
+		if err := g.kubeClient.DeletePods(ctx, foundNamespace, selector, grace); err != nil {
+			return fmt.Errorf("failed to delete pods of %s in namespace %s: %w", name, foundNamespace, err)
+		}
+	}
+
+	if err := sm.DeleteUnusedClaims(ctx, foundNamespace, claims); err != nil {
+		return err
+	}
 	logging.Info("%s operation on Job '%s' completed successfully.", actionVerb, name)
 	return nil
 }
 
 // GetJobLogs fetches the logs for a specific job in the GKE cluster.
-func (g *GKEOrchestrator) GetJobLogs(name string, opts orchestrator.LogsOptions) (string, error) {
+func (g *GKEOrchestrator) GetJobLogs(ctx context.Context, name string, opts orchestrator.LogsOptions) (string, error) {
 	logging.Info("Fetching logs for job '%s' in cluster '%s'...", name, opts.ClusterName)
-	if err := g.configureKubectl(opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
 		return "", err
 	}
 
-	foundNamespace, err := g.getJobNamespace(name)
+	foundNamespace, err := g.getJobNamespace(ctx, name)
 	if err != nil {
 		return "", err
 	}
 
-	selector, mainOnly, podCountForNotice := g.resolveLogsSelector(name, foundNamespace, opts.MainOnly)
+	selector, mainOnly, podCountForNotice := g.resolveLogsSelector(ctx, name, foundNamespace, opts.MainOnly)
 
 	if opts.MainOnly == nil && mainOnly {
 		logging.Info("Job has %d pods (> 5). Defaulting to --main-only logs. To fetch logs from all pods, run with --main-only=false.", podCountForNotice)
@@ -225,59 +393,83 @@ func (g *GKEOrchestrator) GetJobLogs(name string, opts orchestrator.LogsOptions)
 	}
 
 	if opts.Follow {
+		if !mainOnly {
+			return "", g.StreamLogs(ctx, name, true, opts.Since)
+		}
 		logging.Info("Streaming logs for job '%s'...", name)
-		err := g.executor.ExecuteCommandStream("kubectl", "logs", "-n", foundNamespace, "-l", selector, "--all-containers", "-f", fmt.Sprintf("--max-log-requests=%d", maxLogRequests))
-		return "", err
+		return "", g.writePodLogs(ctx, logsOutput, foundNamespace, selector, podLogsOptions{follow: true, since: opts.Since, prefix: true})
 	}
 
-	res, err := g.fetchLogsWithRetry(foundNamespace, selector)
+	logs, err := g.fetchLogsWithRetry(ctx, foundNamespace, selector, opts.Since)
 	if err != nil {
 		return "", err
 	}
 
-	if strings.TrimSpace(res.Stdout) == "" {
+	if strings.TrimSpace(logs) == "" {
 		return "Job exists but has no live logs available (it may have finished or failed to start pods)", nil
 	}
 
-	return res.Stdout, nil
+	return logs, nil
 }
 
-func (g *GKEOrchestrator) fetchLogsWithRetry(ns, selector string) (shell.CommandResult, error) {
-	maxRetries := 12 // 12 * 5s = 1 minute timeout
-	var res shell.CommandResult
-	for i := 0; i < maxRetries; i++ {
-		res = g.executor.ExecuteCommand("kubectl", "logs", "-n", ns, "-l", selector, "--all-containers", fmt.Sprintf("--max-log-requests=%d", maxLogRequests))
-		if res.ExitCode == 0 {
-			return res, nil
-		}
-
-		if strings.Contains(res.Stderr, "is waiting to start") {
-			if i == 0 {
-				logging.Info("Job containers are waiting to start (likely pulling images). Waiting...")
-			}
-			time.Sleep(5 * time.Second)
-			continue
-		}
+// logsOutput receives the logs that are streamed rather than returned.
+var logsOutput io.Writer = os.Stdout
 
-		return res, fmt.Errorf("failed to get logs: %s\n%s", res.Stderr, res.Stdout)
+// StreamLogs writes the logs of every container in the pods of a workload to
+// stdout, interleaved as they arrive and prefixed with the pod and container
+// name. With follow it keeps streaming until interrupted; since, if set, limits
+// output to a relative duration such as "10m". The kubeconfig must already
+// target the workload's cluster.
+func (g *GKEOrchestrator) StreamLogs(ctx context.Context, workloadName string, follow bool, since string) error {
+	ns, err := g.getJobNamespace(ctx, workloadName)
+	if err != nil {
+		return err
 	}
-
-	return res, fmt.Errorf("timed out waiting for job to start; latest error: %s\n%s", res.Stderr, res.Stdout)
+	logging.Info("Streaming logs for job '%s'...", workloadName)
+	selector := fmt.Sprintf("gcluster.google.com/workload=%s", workloadName)
+	return g.writePodLogs(ctx, logsOutput, ns, selector, podLogsOptions{follow: follow, since: since, prefix: true})
 }
 
-func (g *GKEOrchestrator) getJobPodCount(ns, selector string) (int, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "pods", "-n", ns, "-l", selector, "--no-headers")
-	if res.ExitCode != 0 {
-		return 0, fmt.Errorf("failed to query pods: %s", res.Stderr)
+// containerStartRetry polls for logs for up to a minute while the containers
+// of a job start.
+var containerStartRetry = retry.Policy{Attempts: 12, Initial: 5 * time.Second, Multiplier: 1}
+
+func (g *GKEOrchestrator) fetchLogsWithRetry(ctx context.Context, ns, selector, since string) (string, error) {
+	var logs strings.Builder
+	var lastErr error
+	attempt := 0
+	err := retry.Do(ctx, g.retryPolicy(containerStartRetry), func() error {
+		attempt++
+		logs.Reset()
+		lastErr = g.writePodLogs(ctx, &logs, ns, selector, podLogsOptions{since: since, prefix: true})
+		if lastErr == nil {
+			return nil
+		}
+		if !strings.Contains(lastErr.Error(), "is waiting to start") {
+			return retry.Permanent(fmt.Errorf("failed to get logs: %w", lastErr))
+		}
+		if attempt == 1 {
+			logging.Info("Job containers are waiting to start (likely pulling images). Waiting...")
+		}
+		return errContainersStarting
+	})
+	if errors.Is(err, errContainersStarting) {
+		return "", fmt.Errorf("timed out waiting for job to start; latest error: %w", lastErr)
 	}
-	stdout := strings.TrimSpace(res.Stdout)
-	if stdout == "" {
-		return 0, nil
+	return logs.String(), err
+}
+
+var errContainersStarting = errors.New("job containers are waiting to start")
+
+func (g *GKEOrchestrator) getJobPodCount(ctx context.Context, ns, selector string) (int, error) {
+	var pods logPodList
+	if err := g.listResources(ctx, &pods, podGVR, ns, metav1.ListOptions{LabelSelector: selector}); err != nil {
+		return 0, fmt.Errorf("failed to query pods: %w", err)
 	}
-	return len(strings.Split(stdout, "\n")), nil
+	return len(pods.Items), nil
 }
 
-func (g *GKEOrchestrator) resolveLogsSelector(name, ns string, optsMainOnly *bool) (string, bool, int) {
+func (g *GKEOrchestrator) resolveLogsSelector(ctx context.Context, name, ns string, optsMainOnly *bool) (string, bool, int) {
 	mainOnly := false
 	podCount := 0
 	selector := fmt.Sprintf("jobset.sigs.k8s.io/jobset-name=%s", name)
@@ -288,7 +480,7 @@ func (g *GKEOrchestrator) resolveLogsSelector(name, ns string, optsMainOnly *boo
 
 	if !mainOnly {
 		var err error
-		podCount, err = g.getJobPodCount(ns, selector)
+		podCount, err = g.getJobPodCount(ctx, ns, selector)
 
 		if optsMainOnly == nil && err == nil && podCount > 5 {
 			mainOnly = true
@@ -302,20 +494,104 @@ func (g *GKEOrchestrator) resolveLogsSelector(name, ns string, optsMainOnly *boo
 	return selector, mainOnly, podCount
 }
 
-func (g *GKEOrchestrator) generateAndSubmitManifests(job orchestrator.JobDefinition, fullImageName string, profile JobProfile, isDynamicSlicing bool, isStaticSlicing bool) error {
+func (g *GKEOrchestrator) generateAndSubmitManifests(ctx context.Context, job orchestrator.JobDefinition, fullImageName string, profile JobProfile, isDynamicSlicing bool, isStaticSlicing bool) error {
+	if job.WorkloadKind != "" && job.WorkloadKind != orchestrator.WorkloadKindJobSet && job.WorkloadKind != orchestrator.WorkloadKindMPI && !job.IsDryRun() {
+		if !isBatchJob(job) {
+			return fmt.Errorf("workload kind %q can only be generated with a dry run manifest path", job.WorkloadKind)
+		}
+		if job.NumSlices != 1 || job.NodesPerSlice != 1 {
+			return fmt.Errorf("a batch Job runs on a single node, but the workload resolved to %d slice(s) of %d node(s); use --workload-kind jobset for multi-node workloads", job.NumSlices, job.NodesPerSlice)
+		}
+	}
 	if job.IsPathwaysJob {
 		manifestContent, err := g.GeneratePathwaysManifest(job, fullImageName, profile, isDynamicSlicing, isStaticSlicing)
 		if err != nil {
 			return err
 		}
-		return g.ApplyManifest(manifestContent, job.DryRunManifest, job.WorkloadName)
+		retention, err := resolveRetention(job.Retention, nil)
+		if err != nil {
+			return err
+		}
+		if err := g.runStep(ctx, stepApply, job.StepTimeouts.Apply, builtImage(job, fullImageName), func(ctx context.Context) error {
+			return g.ApplyManifest(ctx, manifestContent, manifestOutputPath(job), job.WorkloadName)
+		}); err != nil {
+			return err
+		}
+		return g.submitRetention(ctx, job, retention)
 	}
 
 	manifestOpts, err := g.PrepareManifestOptions(job, fullImageName, profile, isDynamicSlicing, isStaticSlicing)
 	if err != nil {
 		return err
 	}
-	return g.generateAndApplyManifest(manifestOpts, profile, job.DryRunManifest)
+	if job.LogArchive != nil && job.LogArchive.Kind == orchestrator.LogArchiveLogging && !job.IsDryRun() {
+		if err := g.ensureLogArchiveSink(job); err != nil {
+			return err
+		}
+	}
+	if manifestOpts.StageInManifest != "" {
+		if job.IsDryRun() {
+			manifestOpts.AdditionalManifests = append(manifestOpts.AdditionalManifests, manifestOpts.StageInManifest)
+		} else if err := g.stageIn(ctx, manifestOpts); err != nil {
+			return err
+		}
+	}
+	if err := g.runStep(ctx, stepApply, job.StepTimeouts.Apply, builtImage(job, fullImageName), func(ctx context.Context) error {
+		return g.generateAndApplyManifest(ctx, manifestOpts, profile, manifestOutputPath(job))
+	}); err != nil {
+		return err
+	}
+	return g.submitRetention(ctx, job, manifestOpts.Retention)
+}
+
+// submitRetention records the retention rules of a submitted workload in its
+// namespace. A dry run records nothing.
+func (g *GKEOrchestrator) submitRetention(ctx context.Context, job orchestrator.JobDefinition, rules []orchestrator.RetentionRule) error {
+	if len(rules) == 0 || job.IsDryRun() {
+		return nil
+	}
+	ns, err := g.getCurrentNamespace()
+	if err != nil || ns == "" {
+		ns = "default"
+	}
+	return g.recordRetention(ctx, ns, rules)
+}
+
+// builtImage returns the image that the submission of job built and pushed, or
+// "" when it runs a pre-existing image.
+func builtImage(job orchestrator.JobDefinition, fullImageName string) string {
+	if job.BaseImage == "" && job.Dockerfile == "" {
+		return ""
+	}
+	return fullImageName
+}
+
+// manifestOutputPath returns where the manifest of a dry run is written: the
+// --dry-run-out path, or stdout for a plain --dry-run.
+func manifestOutputPath(job orchestrator.JobDefinition) string {
+	if job.DryRun && job.DryRunManifest == "" {
+		return stdoutManifestPath
+	}
+	return job.DryRunManifest
+}
+
+// printDryRunSummary prints what a dry run resolved as YAML comments, so that
+// the output of a plain --dry-run remains a valid manifest.
+func printDryRunSummary(job orchestrator.JobDefinition, fullImageName string) {
+	fmt.Fprintf(dryRunOut, "# Project: %s\n", job.ClusterProjectID)
+	fmt.Fprintf(dryRunOut, "# Cluster: %s (%s)\n", job.ClusterName, job.ClusterLocation)
+	if fullImageName != "" {
+		fmt.Fprintf(dryRunOut, "# Image: %s\n", fullImageName)
+	}
+	if job.BaseImage != "" {
+		fmt.Fprintf(dryRunOut, "# Base image: %s\n", job.BaseImage)
+	}
+	if job.WorkDir != "" {
+		fmt.Fprintf(dryRunOut, "# Workdir: %s\n", job.WorkDir)
+	}
+	if job.Dockerfile != "" {
+		fmt.Fprintf(dryRunOut, "# Dockerfile: %s\n", job.Dockerfile)
+	}
 }
 
 func getCloudConsoleLogsURL(projectID, location, clusterName, namespace, podNamePrefix string) string {
@@ -333,26 +609,44 @@ severity>=DEFAULT`, projectID, location, clusterName, namespace, podNamePrefix)
 }
 
 func (g *GKEOrchestrator) printConsoleLinks(job orchestrator.JobDefinition) {
+	gkeLink, logsLink := consoleLinks(job)
+	logging.Info("Follow your workload details here: %s", gkeLink)
+	logging.Info("View your workload logs in real-time here: %s or use gcluster job logs [job-name] to view logs using kubectl", logsLink)
+}
+
+// consoleLinks returns the Cloud Console links of the workload's first job
+// and of the logs of its containers.
+func consoleLinks(job orchestrator.JobDefinition) (gkeLink, logsLink string) {
 	jobName := job.WorkloadName + "-main-job-0"
 	if job.IsPathwaysJob {
 		jobName = job.WorkloadName + "-pathways-head-0"
+	} else if isBatchJob(job) {
+		jobName = job.WorkloadName
 	}
-	gkeLink := fmt.Sprintf("https://console.cloud.google.com/kubernetes/job/%s/%s/default/%s/details?project=%s",
-		job.ClusterLocation, job.ClusterName, jobName, job.ProjectID)
-
-	logging.Info("Follow your workload details here: %s", gkeLink)
-
-	logsLink := getCloudConsoleLogsURL(job.ProjectID, job.ClusterLocation, job.ClusterName, "default", jobName)
-	logging.Info("View your workload logs in real-time here: %s or use gcluster job logs [job-name] to view logs using kubectl", logsLink)
+	gkeLink = fmt.Sprintf("https://console.cloud.google.com/kubernetes/job/%s/%s/default/%s/details?project=%s",
+		job.ClusterLocation, job.ClusterName, jobName, job.ClusterProjectID)
+	logsLink = getCloudConsoleLogsURL(job.ClusterProjectID, job.ClusterLocation, job.ClusterName, "default", jobName)
+	return gkeLink, logsLink
 }
 
-func (g *GKEOrchestrator) validateJobConflicts(workloadName string, clusterName string, clusterLocation string, projectID string) error {
-	status, err := g.getJobStatus(workloadName)
+func (g *GKEOrchestrator) validateJobConflicts(ctx context.Context, job orchestrator.JobDefinition) error {
+	if isBatchJob(job) {
+		status, err := g.getBatchJobStatus(ctx, job.WorkloadName)
+		if err != nil {
+			return err
+		}
+		if status != "" {
+			return fmt.Errorf("batch Job with name '%s' already exists in state '%s'. Delete it with 'kubectl delete job %s' or resubmit this workload with a different name using '--name'", job.WorkloadName, status, job.WorkloadName)
+		}
+		return nil
+	}
+
+	status, err := g.getJobStatus(ctx, job.WorkloadName)
 	if err != nil {
 		return err
 	}
 	if status != "" {
-		return fmt.Errorf("job with name '%s' already exists in state '%s'. You can cancel the existing job using 'gcluster job cancel %s --cluster %s --location %s --project %s' or resubmit this workload with a different name using '--name'", workloadName, status, workloadName, clusterName, clusterLocation, projectID)
+		return fmt.Errorf("job with name '%s' already exists in state '%s'. You can cancel the existing job using 'gcluster job cancel %s --cluster %s --location %s --project %s' or resubmit this workload with a different name using '--name'", job.WorkloadName, status, job.WorkloadName, job.ClusterName, job.ClusterLocation, job.ClusterProjectID)
 	}
 	return nil
 }
@@ -373,11 +667,6 @@ func (g *GKEOrchestrator) GeneratePathwaysManifest(job orchestrator.JobDefinitio
 		job.Pathways.RamdiskDirectory = "/tmp/mtc_checkpoints"
 	}
 
-	tmpl, err := yamltemplate.New("pathways_jobset.tmpl").ParseFS(templatesFS, "templates/pathways_jobset.tmpl")
-	if err != nil {
-		return "", fmt.Errorf("failed to parse pathways jobset template: %w", err)
-	}
-
 	opts, err := g.PrepareManifestOptions(job, fullImageName, profile, isDynamicSlicing, isStaticSlicing)
 	if err != nil {
 		return "", err
@@ -385,10 +674,10 @@ func (g *GKEOrchestrator) GeneratePathwaysManifest(job orchestrator.JobDefinitio
 
 	opts.Pathways = job.Pathways
 
-	cpuLimit, memoryLimit, gpuLimit, tpuLimit, err := g.calculateResourceLimits(opts, profile)
-	var resStr string
+	spec, err := g.resourceSpec(opts, profile)
+	var resources corev1.ResourceRequirements
 	if err == nil {
-		resStr, err = g.buildResourcesString(cpuLimit, memoryLimit, gpuLimit, tpuLimit, 14)
+		resources, err = buildResources(spec)
 		if err != nil {
 			return "", err
 		}
@@ -396,20 +685,20 @@ func (g *GKEOrchestrator) GeneratePathwaysManifest(job orchestrator.JobDefinitio
 		logging.Warn("Warning: failed to calculate resource limits for Pathways job: %v", err)
 	}
 
-	cmdSlice := []string{"/bin/bash", "-c", opts.CommandToRun}
-	isTPU := tpuLimit != ""
-	isGPU := gpuLimit != ""
-	data := g.prepareJobSetTemplateData(opts, cmdSlice, resStr, isTPU, isGPU)
+	data := g.prepareJobSetTemplateData(opts, resources, spec.TPU != "", spec.GPU != "")
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute pathways jobset template: %w", err)
+	manifest, err := manifestRenderers[pathwaysRenderer].Render(data)
+	if err != nil {
+		return "", err
 	}
-
-	return assembleManifest(buf.String(), opts.AdditionalManifests), nil
+	return assembleManifest(manifest, opts.AdditionalManifests), nil
 }
 
-func (g *GKEOrchestrator) ApplyManifest(manifestContent, outputManifestPath, workloadName string) error {
+func (g *GKEOrchestrator) ApplyManifest(ctx context.Context, manifestContent, outputManifestPath, workloadName string) error {
+	if outputManifestPath == stdoutManifestPath {
+		_, err := io.WriteString(dryRunOut, manifestContent)
+		return err
+	}
 	if outputManifestPath != "" {
 		logging.Info("Saving GKE manifest to %s", outputManifestPath)
 		if err := os.WriteFile(outputManifestPath, []byte(manifestContent), 0644); err != nil {
@@ -419,7 +708,7 @@ func (g *GKEOrchestrator) ApplyManifest(manifestContent, outputManifestPath, wor
 	} else {
 		// Submit will fail if a job with the same name already exists.
 		logging.Info("Applying GKE manifest to cluster...")
-		err := g.applyManifests([]byte(manifestContent), workloadName+".yaml")
+		err := g.applyManifests(ctx, []byte(manifestContent), workloadName+".yaml")
 		if err != nil {
 			return fmt.Errorf("failed to apply GKE manifest: %w", err)
 		}
@@ -429,42 +718,24 @@ func (g *GKEOrchestrator) ApplyManifest(manifestContent, outputManifestPath, wor
 }
 
 func (g *GKEOrchestrator) populateClusterMetadata(job *orchestrator.JobDefinition) error {
-	projectID, err := g.getProjectID(job.ProjectID)
+	projectID, err := g.getProjectID(job.ClusterProjectID)
 	if err != nil {
 		return err
 	}
-	job.ProjectID = projectID
+	job.ClusterProjectID = projectID
 	g.projectID = projectID
+	if job.BuildProjectID == "" {
+		job.BuildProjectID = projectID
+	}
 
 	logging.Info("Fetching GKE cluster metadata for '%s'...", job.ClusterName)
-	res := g.executor.ExecuteCommand("gcloud", "container", "clusters", "describe", job.ClusterName,
-		"--location", job.ClusterLocation,
-		"--project", job.ProjectID,
-		"--format=json")
+	res := g.describeCluster(job.ClusterName, job.ClusterLocation, job.ClusterProjectID)
 	if res.ExitCode != 0 {
 		if strings.Contains(res.Stderr, "403") || strings.Contains(strings.ToLower(res.Stderr), "permission denied") {
-			return fmt.Errorf("your account lacks the required permission to access cluster '%s' in project '%s'. Please ask your project administrator to grant you the Kubernetes Engine Viewer role (roles/container.viewer)", job.ClusterName, job.ProjectID)
-		}
-		// If the user specified a zone (location with 3 components, e.g. us-central1-a), try to fallback to the region
-		if len(strings.Split(job.ClusterLocation, "-")) == 3 {
-			region := shell.ExtractRegion(job.ClusterLocation)
-			logging.Info("Failed to find cluster in zone %s. Trying fallback to region %s...", job.ClusterLocation, region)
-			fallbackRes := g.executor.ExecuteCommand("gcloud", "container", "clusters", "describe", job.ClusterName,
-				"--location", region,
-				"--project", job.ProjectID,
-				"--format=json")
-			if fallbackRes.ExitCode == 0 {
-				logging.Warn("Cluster '%s' is a regional cluster in '%s'. Found it by falling back from zone '%s'. "+
-					"Note: This does NOT restrict your job to '%s'. To run specifically in '%s', "+
-					"please use the '--node-constraint topology.kubernetes.io/zone=%s' flag.",
-					job.ClusterName, region, job.ClusterLocation, job.ClusterLocation, job.ClusterLocation, job.ClusterLocation)
-				job.ClusterLocation = region
-				res = fallbackRes
-			} else {
-				return fmt.Errorf("failed to describe GKE cluster %s in zone %s and fallback region %s: %s", job.ClusterName, job.ClusterLocation, region, res.Stderr)
-			}
-		} else {
-			return fmt.Errorf("failed to describe GKE cluster %s: %s", job.ClusterName, res.Stderr)
+			return fmt.Errorf("your account lacks the required permission to access cluster '%s' in project '%s'. Please ask your project administrator to grant you the Kubernetes Engine Viewer role (roles/container.viewer)", job.ClusterName, job.ClusterProjectID)
+		}
+		if res, err = g.describeClusterInSiblingLocation(job, res); err != nil {
+			return err
 		}
 	}
 
@@ -520,27 +791,33 @@ func (g *GKEOrchestrator) isSystemPool(np gkeJobNodePool) bool {
 	return false
 }
 
-func (g *GKEOrchestrator) initializeJobSubmission(job *orchestrator.JobDefinition) error {
+func (g *GKEOrchestrator) initializeJobSubmission(ctx context.Context, job *orchestrator.JobDefinition) error {
 	if err := g.populateClusterMetadata(job); err != nil {
 		return err
 	}
 
-	logging.Info("Configuring kubectl for GKE cluster '%s'...", job.ClusterName)
-	if err := g.configureKubectl(job.ClusterName, job.ClusterLocation, job.ProjectID); err != nil {
-		return err
+	if job.DryRun {
+		logging.Info("[Dry Run] Skipping cluster credentials for GKE cluster '%s'.", job.ClusterName)
+		// Without credentials the Kueue topologies cannot be read, so the
+		// workload is rendered as if dynamic slicing were not active.
+		g.slicingTopologiesChecked = true
+	} else {
+		logging.Info("Configuring kubectl for GKE cluster '%s'...", job.ClusterName)
+		err := g.runStep(ctx, stepCredentials, job.StepTimeouts.Credentials, "", func(ctx context.Context) error {
+			return g.configureKubectl(ctx, job.ClusterName, job.ClusterLocation, job.ClusterProjectID)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	// Centralized Cluster Validation (Skip for dry-runs to avoid cluster mutations)
-	if job.DryRunManifest == "" {
-		if err := g.ValidateClusterState(job); err != nil {
+	if !job.IsDryRun() {
+		if err := g.ValidateClusterState(ctx, job); err != nil {
 			return err
 		}
 	}
 
-	if err := g.configureClusterEnvironment(job); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -742,27 +1019,41 @@ func (g *GKEOrchestrator) processAccelerators(accelerators []gkeAccelerator, nod
 	return gpus, tpus, flavor, nodeLabels, nil
 }
 
-func (g *GKEOrchestrator) configureClusterEnvironment(job *orchestrator.JobDefinition) error {
-	localQueue, err := g.resolveKueueQueue(job.KueueQueueName)
+func (g *GKEOrchestrator) configureClusterEnvironment(ctx context.Context, job *orchestrator.JobDefinition) error {
+	if job.NoQueue {
+		job.KueueQueueName = ""
+		return nil
+	}
+	if job.DryRun {
+		if job.KueueQueueName == "" {
+			logging.Info("[Dry Run] Skipping Kueue LocalQueue discovery. Defaulting to '%s'.", defaultLocalQueue)
+			job.KueueQueueName = defaultLocalQueue
+		}
+		return nil
+	}
+	localQueue, err := g.resolveKueueQueue(ctx, job.KueueQueueName, job.ComputeType)
+	if errors.Is(err, errNoMatchingLocalQueue) {
+		return err
+	}
 	if err != nil {
 		logging.Info("Warning: Failed to auto-discover Kueue Queue Name: %v. Falling back to default-queue.", err)
 		localQueue = "default-queue"
 	}
 	job.KueueQueueName = localQueue
 
-	if job.DryRunManifest == "" {
-		if err := g.EnsureResourceFlavors(); err != nil {
+	if !job.IsDryRun() {
+		if err := g.EnsureResourceFlavors(ctx); err != nil {
 			logging.Info("Warning: Failed to ensure ResourceFlavors: %v", err)
 		}
 
-		exists, err := g.checkLocalQueueExists(localQueue)
+		exists, err := g.checkLocalQueueExists(ctx, localQueue)
 		if err != nil {
 			logging.Info("Warning: Failed to check if LocalQueue exists: %v", err)
 		}
 		if !exists {
 			promptMsg := fmt.Sprintf("LocalQueue '%s' does not exist. Do you want gcluster to create default Kueue resources (ClusterQueue and LocalQueue) with calculated cluster capacity?", localQueue)
 			if shell.PromptYesNo(promptMsg) {
-				if err := g.createDefaultQueues(localQueue); err != nil {
+				if err := g.createDefaultQueues(ctx, localQueue); err != nil {
 					logging.Info("Warning: Failed to create default queues: %v. Workload might remain suspended.", err)
 				}
 			} else {
@@ -771,7 +1062,7 @@ func (g *GKEOrchestrator) configureClusterEnvironment(job *orchestrator.JobDefin
 		}
 
 		if job.IsPathwaysJob {
-			if err := g.ensureClusterQueueCoverage(localQueue); err != nil {
+			if err := g.ensureClusterQueueCoverage(ctx, localQueue); err != nil {
 				logging.Info("Warning: Could not automatically update ClusterQueue: %v. Workload might remain suspended.", err)
 			}
 		}
@@ -780,18 +1071,18 @@ func (g *GKEOrchestrator) configureClusterEnvironment(job *orchestrator.JobDefin
 	return nil
 }
 
-func (g *GKEOrchestrator) checkLocalQueueExists(name string) (bool, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "localqueue", name, "-n", "default")
-	if res.ExitCode == 0 {
+func (g *GKEOrchestrator) checkLocalQueueExists(ctx context.Context, name string) (bool, error) {
+	_, err := g.getObject(ctx, localQueueGVR, "default", name)
+	if err == nil {
 		return true, nil
 	}
-	if strings.Contains(res.Stderr, "NotFound") || strings.Contains(res.Stderr, "not found") {
+	if apierrors.IsNotFound(err) {
 		return false, nil
 	}
-	return false, fmt.Errorf("failed to check localqueue status: %s", res.Stderr)
+	return false, fmt.Errorf("failed to check localqueue status: %w", err)
 }
 
-func (g *GKEOrchestrator) createDefaultQueues(localQueueName string) error {
+func (g *GKEOrchestrator) createDefaultQueues(ctx context.Context, localQueueName string) error {
 	logging.Info("Creating default ClusterQueue and LocalQueue...")
 
 	// Render and apply ClusterQueue
@@ -799,7 +1090,7 @@ func (g *GKEOrchestrator) createDefaultQueues(localQueueName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to render clusterqueue: %w", err)
 	}
-	if err := g.applyManifests(clusterQueueBytes, "cluster-queue.yaml"); err != nil {
+	if err := g.applyManifests(ctx, clusterQueueBytes, "cluster-queue.yaml"); err != nil {
 		return fmt.Errorf("failed to apply clusterqueue: %w", err)
 	}
 
@@ -817,7 +1108,7 @@ func (g *GKEOrchestrator) createDefaultQueues(localQueueName string) error {
 		return fmt.Errorf("failed to execute local_queue.tmpl template: %w", err)
 	}
 
-	if err := g.applyManifests(localQueueBuf.Bytes(), "local-queue.yaml"); err != nil {
+	if err := g.applyManifests(ctx, localQueueBuf.Bytes(), "local-queue.yaml"); err != nil {
 		return fmt.Errorf("failed to apply localqueue: %w", err)
 	}
 
@@ -825,13 +1116,13 @@ func (g *GKEOrchestrator) createDefaultQueues(localQueueName string) error {
 	return nil
 }
 
-func (g *GKEOrchestrator) ensureClusterQueueCoverage(localQueueName string) error {
-	cqName, err := g.getClusterQueueName(localQueueName)
+func (g *GKEOrchestrator) ensureClusterQueueCoverage(ctx context.Context, localQueueName string) error {
+	cqName, err := g.getClusterQueueName(ctx, localQueueName)
 	if err != nil {
 		return err
 	}
 
-	hasCoverage, isEmpty, err := g.checkClusterQueueCoverage(cqName)
+	hasCoverage, isEmpty, err := g.checkClusterQueueCoverage(ctx, cqName)
 	if err != nil {
 		return err
 	}
@@ -847,7 +1138,7 @@ func (g *GKEOrchestrator) ensureClusterQueueCoverage(localQueueName string) erro
 		if err != nil {
 			return fmt.Errorf("failed to render clusterqueue with new capacity: %w", err)
 		}
-		if err := g.applyManifests(clusterQueueBytes, "cluster-queue.yaml"); err != nil {
+		if err := g.applyManifests(ctx, clusterQueueBytes, "cluster-queue.yaml"); err != nil {
 			return fmt.Errorf("failed to apply clusterqueue with new capacity: %w", err)
 		}
 		return nil
@@ -856,30 +1147,29 @@ func (g *GKEOrchestrator) ensureClusterQueueCoverage(localQueueName string) erro
 	return fmt.Errorf("clusterQueue '%s' does not cover required resources (CPU and Memory). Please configure it manually to include quotas for 'cpu' and 'memory' resources.", cqName)
 }
 
-func (g *GKEOrchestrator) getClusterQueueName(localQueueName string) (string, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "localqueue", localQueueName, "-n", "default", "-o", "jsonpath={.spec.clusterQueue}")
-	if res.ExitCode != 0 {
-		return "", fmt.Errorf("failed to find clusterqueue for %s: %s", localQueueName, res.Stderr)
+func (g *GKEOrchestrator) getClusterQueueName(ctx context.Context, localQueueName string) (string, error) {
+	var lq struct {
+		Spec struct {
+			ClusterQueue string `json:"clusterQueue"`
+		} `json:"spec"`
+	}
+	if err := g.getResource(ctx, &lq, localQueueGVR, "default", localQueueName); err != nil {
+		return "", fmt.Errorf("failed to find clusterqueue for %s: %w", localQueueName, err)
 	}
-	cqName := strings.TrimSpace(res.Stdout)
+	cqName := lq.Spec.ClusterQueue
 	if cqName == "" {
 		cqName = localQueueName
 	}
 	return cqName, nil
 }
 
-func (g *GKEOrchestrator) checkClusterQueueCoverage(cqName string) (bool, bool, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "clusterqueue", cqName, "-o", "json")
-	if res.ExitCode != 0 {
-		return false, false, fmt.Errorf("failed to get clusterqueue %s: %s", cqName, res.Stderr)
-	}
-
-	var cq map[string]interface{}
-	if err := json.Unmarshal([]byte(res.Stdout), &cq); err != nil {
+func (g *GKEOrchestrator) checkClusterQueueCoverage(ctx context.Context, cqName string) (bool, bool, error) {
+	cq, err := g.getObject(ctx, clusterQueueGVR, "", cqName)
+	if err != nil {
 		return false, false, err
 	}
 
-	spec, ok := cq["spec"].(map[string]interface{})
+	spec, ok := cq.Object["spec"].(map[string]interface{})
 	if !ok {
 		return false, true, nil
 	}
@@ -933,24 +1223,38 @@ func (g *GKEOrchestrator) getProjectID(initialProjectID string) (string, error)
 	return projectID, nil
 }
 
-func (g *GKEOrchestrator) resolveKueueQueue(requestedQueueName string) (string, error) {
+// resolveKueueQueue returns the LocalQueue to submit to: the requested one,
+// or the one auto-discovered in the default namespace. When the compute type
+// runs on accelerators, only the LocalQueues whose ClusterQueue has a
+// ResourceFlavor for the accelerator are considered.
+func (g *GKEOrchestrator) resolveKueueQueue(ctx context.Context, requestedQueueName, computeType string) (string, error) {
 	if requestedQueueName != "" {
 		logging.Info("Using provided Kueue LocalQueue: %s", requestedQueueName)
 		return requestedQueueName, nil
 	}
 
-	res := g.executor.ExecuteCommand("kubectl", "get", "localqueue", "-n", "default", "-o", "jsonpath={.items[*].metadata.name}")
-	if res.ExitCode != 0 {
-		return "", fmt.Errorf("failed to query LocalQueues: %s", res.Stderr)
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
 	}
-
-	output := strings.TrimSpace(res.Stdout)
-	if output == "" {
+	if err := g.listResources(ctx, &list, localQueueGVR, "default", metav1.ListOptions{}); err != nil {
+		return "", fmt.Errorf("failed to query LocalQueues: %w", err)
+	}
+	if len(list.Items) == 0 {
 		logging.Info("No LocalQueues found. Defaulting to '%s'.", defaultLocalQueue)
 		return defaultLocalQueue, nil
 	}
 
-	queues := strings.Fields(output)
+	queues := make([]string, 0, len(list.Items))
+	for _, lq := range list.Items {
+		queues = append(queues, lq.Metadata.Name)
+	}
+	if label := g.queueAcceleratorLabel(computeType); label != "" {
+		return g.selectLocalQueue(ctx, queues, label)
+	}
 	if len(queues) == 1 {
 		logging.Info("Auto-discovered Kueue LocalQueue: %s", queues[0])
 		return queues[0], nil
@@ -973,7 +1277,7 @@ func (g *GKEOrchestrator) queryAllMachineTypes() ([]string, error) {
 	return unique, nil
 }
 
-func (g *GKEOrchestrator) resolveTopology(job *orchestrator.JobDefinition) (string, bool, error) {
+func (g *GKEOrchestrator) resolveTopology(ctx context.Context, job *orchestrator.JobDefinition) (string, bool, error) {
 
 	if g.topologyCache == nil {
 		g.topologyCache = make(map[string]string)
@@ -981,7 +1285,7 @@ func (g *GKEOrchestrator) resolveTopology(job *orchestrator.JobDefinition) (stri
 
 	cacheKey := job.Topology + ":" + job.MachineType
 	if val, ok := g.topologyCache[cacheKey]; ok {
-		isDyn, err := g.verifyDynamicSlicingActive(ManifestOptions{
+		isDyn, err := g.verifyDynamicSlicingActive(ctx, ManifestOptions{
 			ClusterName:     job.ClusterName,
 			ClusterLocation: job.ClusterLocation,
 			ComputeType:     job.ComputeType,
@@ -997,7 +1301,7 @@ func (g *GKEOrchestrator) resolveTopology(job *orchestrator.JobDefinition) (stri
 		return val, isDyn, nil
 	}
 
-	top, dynSlicing, err := g.resolveDynamicSlicingTopology(job)
+	top, dynSlicing, err := g.resolveDynamicSlicingTopology(ctx, job)
 	if err != nil {
 		return "", dynSlicing, err
 	}
@@ -1020,7 +1324,7 @@ func (g *GKEOrchestrator) resolveTopology(job *orchestrator.JobDefinition) (stri
 
 	logging.Info("Auto-discovering Topology for %s...", job.MachineType)
 	accelLabel := g.GenerateGKENodeSelectorLabel(job.MachineType)
-	output, err := g.queryDiscoveredTopologies(accelLabel, job.MachineType)
+	output, err := g.queryDiscoveredTopologies(ctx, accelLabel, job.MachineType)
 	if err != nil {
 		return "", false, err
 	}
@@ -1034,7 +1338,7 @@ func (g *GKEOrchestrator) resolveTopology(job *orchestrator.JobDefinition) (stri
 		// We ignore the error and fall back to resolving without size restriction.
 		requestedChips, _ = strconv.Atoi(parts[1])
 	}
-	res, err := g.selectTopology(job.Topology, topologies, job.MachineType, requestedChips)
+	res, err := g.selectTopology(ctx, job.Topology, topologies, job.MachineType, requestedChips)
 	if err == nil {
 		g.topologyCache[cacheKey] = res
 	}
@@ -1053,7 +1357,7 @@ func calculateChipsFromTopology(topology string) int {
 	return chips
 }
 
-func (g *GKEOrchestrator) selectTopology(requested string, topologies map[string]bool, accelType string, requestedChips int) (string, error) {
+func (g *GKEOrchestrator) selectTopology(ctx context.Context, requested string, topologies map[string]bool, accelType string, requestedChips int) (string, error) {
 	if len(topologies) == 0 {
 		if requested != "" {
 			logging.Info("Warning: No active topologies discovered from Kueue or Nodes. Fast-tracking provided topology: %s", requested)
@@ -1063,7 +1367,7 @@ func (g *GKEOrchestrator) selectTopology(requested string, topologies map[string
 	}
 
 	if requested != "" {
-		if err := g.validateRequestedTopology(requested, topologies, accelType); err != nil {
+		if err := g.validateRequestedTopology(ctx, requested, topologies, accelType); err != nil {
 			return "", err
 		}
 		return requested, nil
@@ -1094,7 +1398,7 @@ func (g *GKEOrchestrator) selectTopology(requested string, topologies map[string
 	return uniqueTops[0], nil
 }
 
-func (g *GKEOrchestrator) validateRequestedTopology(requested string, topologies map[string]bool, accelType string) error {
+func (g *GKEOrchestrator) validateRequestedTopology(ctx context.Context, requested string, topologies map[string]bool, accelType string) error {
 	if topologies[requested] {
 		logging.Info("Validated provided Topology: %s", requested)
 		return nil
@@ -1111,7 +1415,7 @@ func (g *GKEOrchestrator) validateRequestedTopology(requested string, topologies
 			return fmt.Errorf("failed to check topology containment: %w", err)
 		}
 		if fit {
-			if !g.hasSlicingTopologies() {
+			if !g.hasSlicingTopologies(ctx) {
 				var valid []string
 				for t := range topologies {
 					valid = append(valid, t)
@@ -1132,8 +1436,8 @@ func (g *GKEOrchestrator) validateRequestedTopology(requested string, topologies
 	return fmt.Errorf("requested topology %s is not valid for cluster. It must match or fit inside discovered limits: %v", requested, valid)
 }
 
-func (g *GKEOrchestrator) resolveDynamicSlicingTopology(job *orchestrator.JobDefinition) (string, bool, error) {
-	active, err := g.verifyDynamicSlicingActive(ManifestOptions{
+func (g *GKEOrchestrator) resolveDynamicSlicingTopology(ctx context.Context, job *orchestrator.JobDefinition) (string, bool, error) {
+	active, err := g.verifyDynamicSlicingActive(ctx, ManifestOptions{
 		ClusterName:     job.ClusterName,
 		ClusterLocation: job.ClusterLocation,
 		ComputeType:     job.ComputeType,
@@ -1171,7 +1475,7 @@ func (g *GKEOrchestrator) parseTopologies(output string) map[string]bool {
 	return topologies
 }
 
-func (g *GKEOrchestrator) queryDiscoveredTopologies(accelLabel string, machineType string) (string, error) {
+func (g *GKEOrchestrator) queryDiscoveredTopologies(ctx context.Context, accelLabel string, machineType string) (string, error) {
 	selector := fmt.Sprintf("cloud.google.com/gke-tpu-accelerator=%s", accelLabel)
 
 	var nodePoolTopologies []string
@@ -1183,15 +1487,37 @@ func (g *GKEOrchestrator) queryDiscoveredTopologies(accelLabel string, machineTy
 		}
 	}
 
-	res := g.executor.ExecuteCommand("kubectl", "get", "resourceflavors.kueue.x-k8s.io", "-o", "jsonpath={range .items[*]}{.spec.nodeLabels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end}", "-l", selector)
-	output := strings.TrimSpace(res.Stdout)
+	var flavors struct {
+		Items []struct {
+			Spec struct {
+				NodeLabels map[string]string `json:"nodeLabels"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	var topologies []string
+	if err := g.listResources(ctx, &flavors, resourceFlavorGVR, "", metav1.ListOptions{LabelSelector: selector}); err == nil {
+		for _, rf := range flavors.Items {
+			topologies = append(topologies, rf.Spec.NodeLabels[tpuTopologyLabel])
+		}
+	}
+	output := strings.TrimSpace(strings.Join(topologies, "\n"))
 
 	if output == "" {
-		res = g.executor.ExecuteCommand("kubectl", "get", "nodes", "-o", "jsonpath={range .items[*]}{.metadata.labels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end}", "-l", selector)
-		if res.ExitCode != 0 {
-			return "", fmt.Errorf("failed to query Nodes for topology: %s", res.Stderr)
+		var nodes struct {
+			Items []struct {
+				Metadata struct {
+					Labels map[string]string `json:"labels"`
+				} `json:"metadata"`
+			} `json:"items"`
 		}
-		output = strings.TrimSpace(res.Stdout)
+		if err := g.listResources(ctx, &nodes, nodeGVR, "", metav1.ListOptions{LabelSelector: selector}); err != nil {
+			return "", fmt.Errorf("failed to query Nodes for topology: %w", err)
+		}
+		topologies = topologies[:0]
+		for _, n := range nodes.Items {
+			topologies = append(topologies, n.Metadata.Labels[tpuTopologyLabel])
+		}
+		output = strings.TrimSpace(strings.Join(topologies, "\n"))
 	}
 
 	if len(nodePoolTopologies) > 0 {
@@ -1200,14 +1526,60 @@ func (g *GKEOrchestrator) queryDiscoveredTopologies(accelLabel string, machineTy
 	return strings.TrimSpace(output), nil
 }
 
-func (g *GKEOrchestrator) BuildContainerImage(job orchestrator.JobDefinition) (string, error) {
+// checkBaseImagePolicy warns about, or with the block policy rejects, a base
+// image that is older than allowed or whose tag moved since the last build. The
+// digest it resolved to is recorded in job for the workload's provenance.
+func (g *GKEOrchestrator) checkBaseImagePolicy(job *orchestrator.JobDefinition) error {
+	if job.BaseImage == "" || job.IsDryRun() || job.Pathways.Headless {
+		return nil
+	}
+	maxAge := time.Duration(job.BaseImageMaxAgeDays) * 24 * time.Hour
+	check, err := imagebuilder.CheckBaseImage(job.BaseImage, job.Platform, maxAge)
+	if err != nil {
+		return err
+	}
+	if len(check.Violations) > 0 {
+		if job.BaseImagePolicy == orchestrator.BaseImagePolicyBlock {
+			return fmt.Errorf("base image policy violated:\n  %s\nuse --base-image-policy=warn to submit anyway", strings.Join(check.Violations, "\n  "))
+		}
+		for _, v := range check.Violations {
+			logging.Warn("%s", v)
+		}
+	}
+	if err := imagebuilder.RecordBaseImage(job.BaseImage, job.Platform, check); err != nil {
+		logging.Warn("Failed to record the digest of base image %s: %v", job.BaseImage, err)
+	}
+	job.BaseImageDigest = check.Digest
+	return nil
+}
+
+// buildImageWithDocker is replaced in tests to avoid building and pushing an
+// image.
+var buildImageWithDocker = imagebuilder.BuildImageWithDocker
+
+func (g *GKEOrchestrator) BuildContainerImage(ctx context.Context, job orchestrator.JobDefinition) (string, error) {
 	if job.Pathways.Headless {
 		return "", nil
 	}
-	if job.DryRunManifest != "" {
+	if job.IsDryRun() {
+		if job.Dockerfile != "" {
+			logging.Info("[Dry Run] Skipping the build of %s, generating predicted URI...", job.Dockerfile)
+			repo, err := imagebuilder.ResolveImageRepo(job.ImageRepo, job.BuildProjectID, job.ClusterLocation)
+			if err != nil {
+				return "", err
+			}
+			return imagebuilder.PredictImageName(repo, builtImageTag(job), job.BuildContext)
+		}
 		if job.BaseImage != "" {
+			if job.Requirements != "" {
+				logging.Info("[Dry Run] Skipping dependency image build for %s.", job.Requirements)
+			}
 			logging.Info("[Dry Run] Skipping Crane build, generating predicted URI...")
-			return imagebuilder.GenerateImageName(job.ProjectID, job.ClusterLocation)
+			repo, err := imagebuilder.ResolveImageRepo(job.ImageRepo, job.BuildProjectID, job.ClusterLocation)
+			if err != nil {
+				return "", err
+			}
+			return imagebuilder.PredictImageName(repo, builtImageTag(job), job.BuildContext)
 		}
 		if job.ImageName != "" {
 			logging.Info("[Dry Run] Using pre-existing container image: %s", job.ImageName)
@@ -1215,25 +1587,86 @@ func (g *GKEOrchestrator) BuildContainerImage(job orchestrator.JobDefinition) (s
 		}
 	}
 
-	if job.BaseImage != "" {
-		logging.Info("Building container image using Crane (Go implementation) on top of %s...", job.BaseImage)
+	if job.Dockerfile != "" {
+		repo, err := imagebuilder.ResolveImageRepo(job.ImageRepo, job.BuildProjectID, job.ClusterLocation)
+		if err != nil {
+			return "", err
+		}
+		if err := imagebuilder.EnsureRepository(repo); err != nil {
+			return "", err
+		}
+		var fullImageName string
+		if job.BuildBackend == orchestrator.BuildBackendDocker {
+			fullImageName, err = buildImageWithDocker(ctx, repo, job.BuildContext, job.Dockerfile, job.Platform, builtImageTag(job))
+		} else {
+			fullImageName, err = imagebuilder.BuildImageWithCloudBuild(ctx, job.BuildProjectID, job.ClusterLocation, repo, job.BuildContext, job.Dockerfile, job.Platform, builtImageTag(job))
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to build %s: %w", job.Dockerfile, err)
+		}
+		return fullImageName, nil
+	}
 
-		ignorePatterns := []string{
-			".git", ".terraform", ".ghpc", ".ansible", "vendor", "bin", "pkg", "node_modules", "*.log", "tmp/", ".DS_Store", "__pycache__",
+	if job.BaseImage != "" {
+		repo, err := imagebuilder.ResolveImageRepo(job.ImageRepo, job.BuildProjectID, job.ClusterLocation)
+		if err != nil {
+			return "", err
+		}
+		if err := imagebuilder.EnsureRepository(repo); err != nil {
+			return "", err
 		}
 
-		ignoreMatcher, err := imagebuilder.ReadDockerignorePatterns(job.BuildContext, ignorePatterns)
+		baseImage := job.BaseImage
+		if job.BaseImageDigest != "" {
+			// Build on the digest that was checked, which also rebuilds the
+			// dependency image when the base image tag moves.
+			if baseImage, err = imagebuilder.PinnedBaseImage(job.BaseImage, job.BaseImageDigest); err != nil {
+				return "", err
+			}
+		}
+		if job.Requirements != "" {
+			depImage, err := imagebuilder.EnsureDependencyImage(ctx, job.BuildProjectID, job.ClusterLocation, repo, baseImage, job.Requirements, job.Platform)
+			if err != nil {
+				return "", fmt.Errorf("failed to prepare dependency image: %w", err)
+			}
+			baseImage = depImage
+		}
+		ignoreMatcher, err := imagebuilder.ReadDockerignorePatterns(job.BuildContext, imagebuilder.DefaultIgnorePatterns)
 		if err != nil {
 			return "", fmt.Errorf("failed to read .dockerignore patterns: %w", err)
 		}
 
+		if job.BuildBackend == orchestrator.BuildBackendRemote {
+			logging.Info("Building container image on a builder VM on top of %s...", baseImage)
+			fullImageName, err := imagebuilder.BuildContainerImageOnRemoteVM(
+				ctx,
+				imagebuilder.RemoteBuilder{Project: job.BuildProjectID, Zone: job.BuilderZone},
+				repo,
+				baseImage,
+				job.BuildContext,
+				job.WorkDir,
+				job.Platform,
+				ignoreMatcher,
+				imagebuilder.ImageConfig{Labels: job.ImageLabels},
+				builtImageTag(job),
+			)
+			if err != nil {
+				return "", fmt.Errorf("remote image build failed: %w", err)
+			}
+			logging.Info("Built image will be available at: %s", fullImageName)
+			return fullImageName, nil
+		}
+		logging.Info("Building container image using Crane (Go implementation) on top of %s...", baseImage)
 		fullImageName, err := imagebuilder.BuildContainerImageFromBaseImage(
-			job.ProjectID,
-			job.ClusterLocation,
-			job.BaseImage,
+			ctx,
+			repo,
+			baseImage,
 			job.BuildContext,
+			job.WorkDir,
 			job.Platform,
 			ignoreMatcher,
+			imagebuilder.ImageConfig{Labels: job.ImageLabels},
+			builtImageTag(job),
 		)
 		if err != nil {
 			return "", fmt.Errorf("crane-based image build failed: %w", err)
@@ -1244,28 +1677,48 @@ func (g *GKEOrchestrator) BuildContainerImage(job orchestrator.JobDefinition) (s
 		logging.Info("Using pre-existing container image: %s", job.ImageName)
 		return job.ImageName, nil
 	}
-	return "", fmt.Errorf("either --image or --base-image must be provided")
+	return "", fmt.Errorf("one of --image, --base-image or --dockerfile must be provided")
+}
+
+// builtImageTag returns the tag of the images built for job.
+func builtImageTag(job orchestrator.JobDefinition) imagebuilder.ImageTag {
+	return imagebuilder.ImageTag{Strategy: imagebuilder.TagStrategy(job.ImageTagStrategy), Value: job.ImageTag}
 }
 
-func (g *GKEOrchestrator) configureKubectl(clusterName, clusterLocation, projectID string) error {
-	credsRes := g.executor.ExecuteCommand("gcloud", "container", "clusters", "get-credentials", clusterName, "--location", clusterLocation, "--project", projectID)
+func (g *GKEOrchestrator) configureKubectl(ctx context.Context, clusterName, clusterLocation, projectID string) error {
+	if g.impersonate != "" {
+		return g.connectAsPrincipal(ctx, clusterName, clusterLocation, projectID)
+	}
+	credsRes := executorWithContext(g.executor, ctx).ExecuteCommand("gcloud", "container", "clusters", "get-credentials", clusterName, "--location", clusterLocation, "--project", projectID)
 	if credsRes.ExitCode != 0 {
 		if strings.Contains(strings.ToLower(credsRes.Stderr), "multiple") || strings.Contains(strings.ToLower(credsRes.Stderr), "ambiguous") {
 			return fmt.Errorf("found multiple GKE clusters named %s. Please specify the exact Zone using --location to disambiguate.", clusterName)
 		}
 		return fmt.Errorf("failed to get GKE cluster credentials: %s\n%s", credsRes.Stderr, credsRes.Stdout)
 	}
+	// get-credentials may switch the kubeconfig context; rebuild clients loaded from the old one.
+	if g.kubeClientFromConfig {
+		g.dynClient, g.kubeClient, g.kubeClientFromConfig = nil, nil, false
+	}
 	return nil
 }
 
-func (g *GKEOrchestrator) generateAndApplyManifest(opts ManifestOptions, profile JobProfile, outputManifestPath string) error {
+func (g *GKEOrchestrator) generateAndApplyManifest(ctx context.Context, opts ManifestOptions, profile JobProfile, outputManifestPath string) error {
 	logging.Info("Generating GKE manifest...")
 	gkeManifestContent, err := g.GenerateGKEManifest(opts, profile)
 	if err != nil {
 		return fmt.Errorf("failed to generate GKE manifest: %w", err)
 	}
+	if opts.SigningKey != "" {
+		logging.Info("Signing GKE manifest with %s...", opts.SigningKey)
+		signed, err := manifestsign.Sign([]byte(gkeManifestContent), opts.SigningKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign GKE manifest: %w", err)
+		}
+		gkeManifestContent = string(signed)
+	}
 
-	return g.ApplyManifest(gkeManifestContent, outputManifestPath, opts.WorkloadName)
+	return g.ApplyManifest(ctx, gkeManifestContent, outputManifestPath, opts.WorkloadName)
 }
 
 // TODO: Make this a dynamic lookup using cloud.google.com/gke-tpu-accelerator & cloud.google.com/gke-accelerator
@@ -1292,10 +1745,15 @@ func (g *GKEOrchestrator) GenerateGKENodeSelectorLabel(acceleratorType string) s
 	return acceleratorType
 }
 
-func (g *GKEOrchestrator) prepareJobSetTemplateData(opts ManifestOptions, command []string, resourcesYAML string, isTPU, isGPU bool) jobSetTemplateData {
+func (g *GKEOrchestrator) prepareJobSetTemplateData(opts ManifestOptions, resources corev1.ResourceRequirements, isTPU, isGPU bool) jobSetTemplateData {
+	command, args := containerCommand(opts.CommandToRun, opts.CommandArgs)
+	if opts.CommandPrelude != "" {
+		command, args = preludedCommand(opts.CommandToRun, opts.CommandArgs, opts.CommandPrelude)
+	}
+
 	exclusiveTopology := ""
 	if !opts.IsDynamicSlicing {
-		exclusiveTopology = "alpha.jobset.sigs.k8s.io/exclusive-topology: cloud.google.com/gke-nodepool"
+		exclusiveTopology = nodePoolLabel
 	}
 
 	workerBackoffLimit := 2048000
@@ -1317,17 +1775,22 @@ func (g *GKEOrchestrator) prepareJobSetTemplateData(opts ManifestOptions, comman
 	if opts.ParallelContainers > 1 {
 		for i := 0; i < opts.ParallelContainers; i++ {
 			containers = append(containers, ContainerData{
-				Name:          fmt.Sprintf("workload-container-%d", i+1),
-				ResourcesYAML: resourcesYAML,
+				Name:      fmt.Sprintf("workload-container-%d", i+1),
+				Resources: resources,
 			})
 		}
 	} else {
 		containers = append(containers, ContainerData{
-			Name:          "workload-container",
-			ResourcesYAML: resourcesYAML,
+			Name:      "workload-container",
+			Resources: resources,
 		})
 	}
 
+	restartPolicy := opts.RestartPolicy
+	if restartPolicy == "" {
+		restartPolicy = orchestrator.RestartPolicyNever
+	}
+
 	return jobSetTemplateData{
 		WorkloadName:                  opts.WorkloadName,
 		ClusterName:                   opts.ClusterName,
@@ -1337,6 +1800,8 @@ func (g *GKEOrchestrator) prepareJobSetTemplateData(opts ManifestOptions, comman
 		TtlSecondsAfterFinished:       opts.TtlSecondsAfterFinished,
 		TerminationGracePeriodSeconds: opts.TerminationGracePeriodSeconds,
 		MaxRestarts:                   opts.MaxRestarts,
+		RestartPolicy:                 restartPolicy,
+		PodBackoffLimit:               opts.PodBackoffLimit,
 		NumSlices:                     opts.NumSlices,
 		NodesPerSlice:                 opts.NodesPerSlice,
 		WorkerBackoffLimit:            workerBackoffLimit,
@@ -1344,30 +1809,45 @@ func (g *GKEOrchestrator) prepareJobSetTemplateData(opts ManifestOptions, comman
 		ServerArgsList:                serverArgsList,
 		WorkerArgsList:                workerArgsList,
 		PathwaysInstanceType:          opts.PathwaysInstanceType,
-		CommandToRun:                  opts.CommandToRun,
-		ResourcesString:               resourcesYAML,
+		CommandToRun:                  pathwaysCommand(opts.CommandToRun, opts.CommandArgs),
+		Resources:                     resources,
 		FullImageName:                 opts.FullImageName,
+		BaseImage:                     opts.BaseImage,
+		BaseImageDigest:               opts.BaseImageDigest,
+		CapturedEnv:                   opts.CapturedEnv,
+		CapturedEnvDigest:             opts.CapturedEnvDigest,
+		ManagedProfile:                opts.ManagedProfile,
+		Inputs:                        opts.Inputs,
+		Subdomain:                     opts.Subdomain,
+		MPI:                           opts.MPI,
 		Command:                       command,
-		ResourcesYAML:                 resourcesYAML,
+		Args:                          args,
+		Entrypoint:                    rayEntrypoint(opts.CommandToRun, opts.CommandArgs),
 		AcceleratorTypeLabel:          g.GenerateGKENodeSelectorLabel(opts.ComputeType),
 		NodeSelector:                  opts.NodeSelector,
 		Affinity:                      opts.Affinity,
 		PodFailurePolicy:              opts.PodFailurePolicy,
 		ImagePullSecrets:              opts.ImagePullSecrets,
 		ServiceAccountName:            opts.ServiceAccountName,
-		TopologyAnnotation:            opts.TopologyAnnotation,
+		TopologyAnnotations:           opts.TopologyAnnotations,
 		SchedulerName:                 opts.SchedulerName,
 		SchedulingGates:               opts.SchedulingGates,
 		Tolerations:                   opts.Tolerations,
 		PriorityClassName:             opts.PriorityClassName,
-		VolumesYAML:                   opts.VolumesYAML,
-		VolumeMountsYAML:              opts.VolumeMountsYAML,
+		Volumes:                       opts.Volumes,
+		VolumeMounts:                  opts.VolumeMounts,
 		GCSFuseEnabled:                opts.GCSFuseEnabled,
+		LogArchiver:                   opts.LogArchiver,
+		Sidecars:                      opts.Sidecars,
+		StartupProbe:                  opts.StartupProbe,
+		ReadinessProbe:                opts.ReadinessProbe,
 		HostNetworkEnabled:            isTPU || isGPU,
 		Pathways:                      opts.Pathways,
-		ExclusiveTopologyAnnotation:   exclusiveTopology,
+		ExclusiveTopology:             exclusiveTopology,
 		Verbose:                       opts.Verbose,
-		Env:                           sortedEnvVars(opts.Env),
+		Env:                           slices.Concat(sortedEnvVars(opts.Env), sortedFieldEnvVars(opts.FieldEnv), opts.SecretEnv),
+		CostLabels:                    opts.CostLabels,
+		ResourceLabels:                opts.ResourceLabels,
 		PathwaysProxyEnv:              sortedEnvVars(opts.Pathways.ProxyEnv),
 		PathwaysServerEnv:             sortedEnvVars(opts.Pathways.ServerEnv),
 		PathwaysWorkerEnv:             sortedEnvVars(opts.Pathways.WorkerEnv),
@@ -1454,7 +1934,7 @@ func parseConditions(conditions []interface{}, statusStr *string, completionTime
 		condStatus, _ := cond["status"].(string)
 		if condStatus == "True" {
 			switch condType {
-			case "Completed", "Succeeded":
+			case "Completed", "Complete", "Succeeded":
 				*statusStr = "Succeeded"
 				if *completionTime == "" {
 					if transitionTime, ok := cond["lastTransitionTime"].(string); ok {
@@ -1482,12 +1962,12 @@ func (g *GKEOrchestrator) getCurrentNamespace() (string, error) {
 	return g.kubeClient.GetCurrentNamespace()
 }
 
-func (g *GKEOrchestrator) getKueueWorkloadStatus(client dynamic.Interface, ns string, uid string) (string, error) {
+func (g *GKEOrchestrator) getKueueWorkloadStatus(ctx context.Context, client dynamic.Interface, ns string, uid string) (string, error) {
 	gvrWl := schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "workloads"}
 	listOptsWl := metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("kueue.x-k8s.io/job-uid=%s", uid),
 	}
-	wlList, err := client.Resource(gvrWl).Namespace(ns).List(context.TODO(), listOptsWl)
+	wlList, err := client.Resource(gvrWl).Namespace(ns).List(ctx, listOptsWl)
 	if err != nil {
 		return "", err
 	}
@@ -1498,12 +1978,12 @@ func (g *GKEOrchestrator) getKueueWorkloadStatus(client dynamic.Interface, ns st
 	return "", nil
 }
 
-func (g *GKEOrchestrator) getPodAggregatedStatus(client dynamic.Interface, ns string, workloadName string) (string, error) {
+func (g *GKEOrchestrator) getPodAggregatedStatus(ctx context.Context, client dynamic.Interface, ns string, workloadName string) (string, error) {
 	gvrPod := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
 	listOpts := metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("gcluster.google.com/workload=%s", workloadName),
 	}
-	podList, err := client.Resource(gvrPod).Namespace(ns).List(context.TODO(), listOpts)
+	podList, err := client.Resource(gvrPod).Namespace(ns).List(ctx, listOpts)
 	if err != nil {
 		return "", err
 	}
@@ -1540,7 +2020,7 @@ func (g *GKEOrchestrator) getPodAggregatedStatus(client dynamic.Interface, ns st
 	return "Running", nil
 }
 
-func (g *GKEOrchestrator) getJobStatus(name string) (string, error) {
+func (g *GKEOrchestrator) getJobStatus(ctx context.Context, name string) (string, error) {
 	client, err := g.getDynamicClient()
 	if err != nil {
 		return "", err
@@ -1550,7 +2030,7 @@ func (g *GKEOrchestrator) getJobStatus(name string) (string, error) {
 	optsSelector := metav1.ListOptions{
 		FieldSelector: fmt.Sprintf("metadata.name=%s", name),
 	}
-	list, err := client.Resource(gvr).Namespace("").List(context.TODO(), optsSelector)
+	list, err := client.Resource(gvr).Namespace("").List(ctx, optsSelector)
 	if err != nil {
 		return "", fmt.Errorf("failed to search for jobset %s across namespaces: %w", name, err)
 	}
@@ -1572,7 +2052,7 @@ func (g *GKEOrchestrator) getJobStatus(name string) (string, error) {
 	}
 	uid, _ := metadata["uid"].(string)
 
-	wlStatus, err := g.getKueueWorkloadStatus(client, ns, uid)
+	wlStatus, err := g.getKueueWorkloadStatus(ctx, client, ns, uid)
 	if err == nil && (wlStatus == "QuotaReserved" || wlStatus == "Evicted") {
 		return wlStatus, nil
 	}
@@ -1580,7 +2060,7 @@ func (g *GKEOrchestrator) getJobStatus(name string) (string, error) {
 	status, _ := parseJobStatus(obj.Object)
 
 	if status == "Running" {
-		podStatus, err := g.getPodAggregatedStatus(client, ns, name)
+		podStatus, err := g.getPodAggregatedStatus(ctx, client, ns, name)
 		if err != nil {
 			return status, nil // Fall back to JobSet status
 		}
@@ -1590,6 +2070,33 @@ func (g *GKEOrchestrator) getJobStatus(name string) (string, error) {
 	return status, nil
 }
 
+// isBatchJob reports whether the workload is submitted as a plain batch/v1 Job,
+// which runs without the JobSet CRD.
+func isBatchJob(job orchestrator.JobDefinition) bool {
+	return job.WorkloadKind == orchestrator.WorkloadKindJob
+}
+
+// getBatchJobStatus returns the state of the batch/v1 Job with the given name
+// in any namespace, or "" if there is none.
+func (g *GKEOrchestrator) getBatchJobStatus(ctx context.Context, name string) (string, error) {
+	client, err := g.getDynamicClient()
+	if err != nil {
+		return "", err
+	}
+	gvr := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	list, err := client.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("metadata.name=%s", name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search for job %s across namespaces: %w", name, err)
+	}
+	if len(list.Items) == 0 {
+		return "", nil
+	}
+	status, _ := parseJobStatus(list.Items[0].Object)
+	return status, nil
+}
+
 func (g *GKEOrchestrator) parseKueueWorkloadStatus(obj map[string]interface{}) string {
 	status, ok := obj["status"].(map[string]interface{})
 	if !ok {
@@ -1627,73 +2134,61 @@ func (g *GKEOrchestrator) parseKueueWorkloadStatus(obj map[string]interface{}) s
 	return "Unknown"
 }
 
-func (g *GKEOrchestrator) generatePodFailurePolicy(exitCodes []int) (string, error) {
+func (g *GKEOrchestrator) generatePodFailurePolicy(exitCodes []int) *batchv1.PodFailurePolicy {
 	if len(exitCodes) == 0 {
-		return "", nil
+		return nil
 	}
 
-	var validCodes []int
+	var validCodes []int32
 	for _, code := range exitCodes {
 		if code == 0 {
 			logging.Info("Warning: Exit code 0 (success) cannot be used in PodFailurePolicy. Ignoring it.")
 			continue
 		}
-		validCodes = append(validCodes, code)
+		validCodes = append(validCodes, int32(code))
 	}
 
 	if len(validCodes) == 0 {
-		return "", nil
+		return nil
 	}
 
-	policy := map[string]interface{}{
-		"rules": []map[string]interface{}{
-			{
-				"action": "FailJob",
-				"onExitCodes": map[string]interface{}{
-					"operator": "NotIn",
-					"values":   validCodes,
-				},
+	return &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{{
+			Action: batchv1.PodFailurePolicyActionFailJob,
+			OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+				Operator: batchv1.PodFailurePolicyOnExitCodesOpNotIn,
+				Values:   validCodes,
 			},
-		},
+		}},
 	}
-	b, err := yaml.Marshal(policy)
-	if err != nil {
-		return "", err
-	}
-	return string(b), nil
 }
 
-func (g *GKEOrchestrator) generateImagePullSecrets(secrets string) string {
-	if secrets == "" {
-		return ""
-	}
-	parts := strings.Split(secrets, ",")
-	var secretList []map[string]string
-	for _, s := range parts {
+func (g *GKEOrchestrator) generateImagePullSecrets(secrets string) []corev1.LocalObjectReference {
+	var refs []corev1.LocalObjectReference
+	for _, s := range strings.Split(secrets, ",") {
 		s = strings.TrimSpace(s)
 		if s != "" {
-			secretList = append(secretList, map[string]string{"name": s})
+			refs = append(refs, corev1.LocalObjectReference{Name: s})
 		}
 	}
-	if len(secretList) == 0 {
-		return ""
-	}
-	b, _ := yaml.Marshal(secretList)
-	return string(b)
+	return refs
 }
 
-func (g *GKEOrchestrator) getJobNamespace(name string) (string, error) {
+func (g *GKEOrchestrator) getJobNamespace(ctx context.Context, name string) (string, error) {
 	if g.kubeClient == nil {
 		_, err := g.getDynamicClient()
 		if err != nil {
 			return "", fmt.Errorf("failed to get dynamic client: %w", err)
 		}
 	}
-	return g.kubeClient.GetJobNamespace(name)
+	return g.kubeClient.GetJobNamespace(ctx, name)
 }
 
 func (g *GKEOrchestrator) getDynamicClient() (dynamic.Interface, error) {
 	if g.dynClient != nil {
+		if g.kubeClient == nil {
+			g.kubeClient = &DefaultKubeClient{dynClient: g.dynClient}
+		}
 		return g.dynClient, nil
 	}
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
@@ -1703,18 +2198,35 @@ func (g *GKEOrchestrator) getDynamicClient() (dynamic.Interface, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
 	}
-	g.dynClient, err = dynamic.NewForConfig(config)
+	if g.impersonate != "" {
+		config.Impersonate = rest.ImpersonationConfig{UserName: g.impersonate}
+	}
+	client, err := newDefaultKubeClient(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+		return nil, err
 	}
+	client.dryRun = g.impersonate != ""
+	g.dynClient = client.dynClient
 	if g.kubeClient == nil {
-		g.kubeClient = &DefaultKubeClient{dynClient: g.dynClient}
+		g.kubeClient = client
+		g.kubeClientFromConfig = true
 	}
 	return g.dynClient, nil
 }
 
-func (g *GKEOrchestrator) awaitJobCompletion(workloadName, clusterName, clusterLocation, projectID, timeout string) error {
-	logging.Info("Waiting for job '%s' to complete...", workloadName)
+// getKubeClient returns the configured KubeClient, building one from the
+// current kubeconfig when none was injected.
+func (g *GKEOrchestrator) getKubeClient() (KubeClient, error) {
+	if g.kubeClient == nil {
+		if _, err := g.getDynamicClient(); err != nil {
+			return nil, fmt.Errorf("failed to get dynamic client: %w", err)
+		}
+	}
+	return g.kubeClient, nil
+}
+
+func (g *GKEOrchestrator) awaitJobCompletion(ctx context.Context, workloadName, clusterName, clusterLocation, projectID, timeout string, noQueue bool) error {
+	logging.Info("Waiting for job '%s' to complete. If this is interrupted, follow it with 'gcluster job attach %s'.", workloadName, workloadName)
 
 	if g.kubeClient == nil {
 		_, err := g.getDynamicClient() // ensure kubeClient is initialized
@@ -1723,7 +2235,7 @@ func (g *GKEOrchestrator) awaitJobCompletion(workloadName, clusterName, clusterL
 		}
 	}
 
-	ns, err := g.kubeClient.GetJobNamespace(workloadName)
+	ns, err := g.kubeClient.GetJobNamespace(ctx, workloadName)
 	if err != nil {
 		return fmt.Errorf("failed to get job namespace: %w", err)
 	}
@@ -1731,19 +2243,26 @@ func (g *GKEOrchestrator) awaitJobCompletion(workloadName, clusterName, clusterL
 	jobConsoleLink := fmt.Sprintf("https://console.cloud.google.com/kubernetes/workload/gke/%s/%s/details/%s?project=%s",
 		clusterLocation, clusterName, workloadName, projectID)
 
-	targetWorkloadName, err := g.findTargetWorkload(ns, workloadName)
-	if err != nil {
-		return err
-	}
+	if noQueue {
+		// Without Kueue there is no workload object to wait on.
+		if err := g.waitJobSetFinished(ctx, ns, timeout, jobConsoleLink, workloadName); err != nil {
+			return err
+		}
+	} else {
+		targetWorkloadName, err := g.findTargetWorkload(ctx, ns, workloadName)
+		if err != nil {
+			return err
+		}
 
-	err = g.waitWorkloadFinished(targetWorkloadName, ns, timeout, jobConsoleLink, workloadName)
-	if err != nil {
-		return err
+		err = g.waitWorkloadFinished(ctx, targetWorkloadName, ns, timeout, jobConsoleLink, workloadName)
+		if err != nil {
+			return err
+		}
 	}
 
 	logging.Info("Job '%s' has finished. Checking final status...", workloadName)
 
-	status, err := g.getJobSetStatus(workloadName, ns)
+	status, err := g.getJobSetStatus(ctx, workloadName, ns)
 	if err != nil {
 		return err
 	}
@@ -1757,15 +2276,10 @@ func (g *GKEOrchestrator) awaitJobCompletion(workloadName, clusterName, clusterL
 	return nil
 }
 
-func (g *GKEOrchestrator) getJobSetStatus(workloadName, ns string) (string, error) {
-	statusRes := g.executor.ExecuteCommand("kubectl", "get", "jobset", workloadName, "-n", ns, "-o", "json")
-	if statusRes.ExitCode != 0 {
-		return "", fmt.Errorf("failed to get final job status: %s\n%s", statusRes.Stderr, statusRes.Stdout)
-	}
-
+func (g *GKEOrchestrator) getJobSetStatus(ctx context.Context, workloadName, ns string) (string, error) {
 	var jsStatus JobSetStatus
-	if err := json.Unmarshal([]byte(statusRes.Stdout), &jsStatus); err != nil {
-		return "", fmt.Errorf("failed to parse jobset status JSON: %w", err)
+	if err := g.getResource(ctx, &jsStatus, jobSetGVR, ns, workloadName); err != nil {
+		return "", fmt.Errorf("failed to get final job status: %w", err)
 	}
 
 	var latestCondition JobSetCondition
@@ -1792,8 +2306,8 @@ func (g *GKEOrchestrator) getJobSetStatus(workloadName, ns string) (string, erro
 	return latestCondition.Type, nil
 }
 
-func (g *GKEOrchestrator) findTargetWorkload(ns, workloadName string) (string, error) {
-	matchedWorkloads, err := g.kubeClient.ListWorkloads(ns, workloadName)
+func (g *GKEOrchestrator) findTargetWorkload(ctx context.Context, ns, workloadName string) (string, error) {
+	matchedWorkloads, err := g.kubeClient.ListWorkloads(ctx, ns, workloadName)
 	if err != nil {
 		return "", fmt.Errorf("failed to list workloads: %w", err)
 	}
@@ -1809,19 +2323,101 @@ func (g *GKEOrchestrator) findTargetWorkload(ns, workloadName string) (string, e
 	return targetWorkloadName, nil
 }
 
-func (g *GKEOrchestrator) waitWorkloadFinished(targetWorkloadName, ns, timeout, jobConsoleLink, workloadName string) error {
+func (g *GKEOrchestrator) waitWorkloadFinished(ctx context.Context, targetWorkloadName, ns, timeout, jobConsoleLink, workloadName string) error {
 	logging.Info("Waiting for Kueue workload '%s' to be Finished...", targetWorkloadName)
-	waitRes := g.executor.ExecuteCommand("kubectl", "wait", "--for=condition=Finished",
-		"workload", targetWorkloadName, "-n", ns, "--timeout="+timeout)
+	return g.pollJobFinished(ctx, timeout, jobConsoleLink, workloadName, func() (bool, error) {
+		var wl etaWorkload
+		if err := g.getResource(ctx, &wl, workloadGVR, ns, targetWorkloadName); err != nil {
+			return false, err
+		}
+		return wl.state() == etaStateFinished, nil
+	})
+}
+
+// jobPollInterval is how often a running job is checked for completion.
+var jobPollInterval = 30 * time.Second
+
+func (g *GKEOrchestrator) waitJobSetFinished(ctx context.Context, ns, timeout, jobConsoleLink, workloadName string) error {
+	logging.Info("Waiting for JobSet '%s' to be Completed or Failed...", workloadName)
+	var lastPodStates string
+	return g.pollJobFinished(ctx, timeout, jobConsoleLink, workloadName, func() (bool, error) {
+		var js struct {
+			Status struct {
+				TerminalState string `json:"terminalState"`
+			} `json:"status"`
+		}
+		if err := g.getResource(ctx, &js, jobSetGVR, ns, workloadName); err != nil {
+			return false, err
+		}
+		if js.Status.TerminalState != "" {
+			return true, nil
+		}
+		if states := g.podStatesSummary(ctx, ns, workloadName); states != lastPodStates {
+			if states != "" {
+				logging.Info("Job '%s': %s", workloadName, states)
+			}
+			lastPodStates = states
+		}
+		return false, nil
+	})
+}
 
-	if waitRes.ExitCode != 0 {
-		if strings.Contains(waitRes.Stderr, "timed out waiting") || strings.Contains(waitRes.Stdout, "timed out waiting") {
+// pollJobFinished calls finished every jobPollInterval until it reports true.
+// timeout is a duration or a number of seconds; zero waits without a limit.
+func (g *GKEOrchestrator) pollJobFinished(ctx context.Context, timeout, jobConsoleLink, workloadName string, finished func() (bool, error)) error {
+	limit, err := time.ParseDuration(timeout)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(timeout)
+		if convErr != nil {
+			return fmt.Errorf("invalid timeout %q: %w", timeout, err)
+		}
+		limit = time.Duration(seconds) * time.Second
+	}
+	deadline := g.now().Add(limit)
+	for {
+		done, err := finished()
+		if err != nil {
+			return fmt.Errorf("error waiting for job completion: %w", err)
+		}
+		if done {
+			return nil
+		}
+		if limit > 0 && !g.now().Before(deadline) {
 			logging.Error("Timed out waiting for job '%s' to finish. Check its status in the Cloud Console: %s", workloadName, jobConsoleLink)
 			return fmt.Errorf("job timed out")
 		}
-		return fmt.Errorf("error waiting for job completion: %s\n%s", waitRes.Stderr, waitRes.Stdout)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		g.sleep(jobPollInterval)
 	}
-	return nil
+}
+
+// podStatesSummary describes the pods of a workload that are still loading or
+// crash looping, so a wait can tell a slow start from a failing one. It is
+// empty when there are none or the pods cannot be listed.
+func (g *GKEOrchestrator) podStatesSummary(ctx context.Context, ns, workloadName string) string {
+	pods, err := g.listWorkloadPods(ctx, ns, workloadName)
+	if err != nil {
+		return ""
+	}
+	var loading, crashLooping int
+	for _, p := range pods {
+		switch p.State {
+		case orchestrator.PodStateLoading:
+			loading++
+		case orchestrator.PodStateCrashLooping:
+			crashLooping++
+		}
+	}
+	var parts []string
+	if crashLooping > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d pods are crash looping; see 'gcluster job logs %s'", crashLooping, len(pods), workloadName))
+	}
+	if loading > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d pods are still loading (startup probe not passed yet)", loading, len(pods)))
+	}
+	return strings.Join(parts, ", ")
 }
 
 func (g *GKEOrchestrator) addAcceleratorLabel(nodeSelector map[string]string, accelLabel string, isCPUMachine bool, machineType string) {
@@ -1873,11 +2469,11 @@ func injectProvisioningLabels(nodeSelector map[string]string, provisioning strin
 	}
 }
 
-func (g *GKEOrchestrator) buildNodeSelector(schedOpts SchedulingOptions, job orchestrator.JobDefinition, isCPUMachine bool) (string, error) {
+func (g *GKEOrchestrator) buildNodeSelector(schedOpts SchedulingOptions, job orchestrator.JobDefinition, isCPUMachine bool) (map[string]string, error) {
 	nodeSelector := make(map[string]string)
 	existing, err := getNodeSelector(schedOpts)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	for k, v := range existing {
 		nodeSelector[k] = v
@@ -1885,10 +2481,13 @@ func (g *GKEOrchestrator) buildNodeSelector(schedOpts SchedulingOptions, job orc
 
 	// Inject unified consumption options
 	injectProvisioningLabels(nodeSelector, job.GKENAPProvisioning, job.GKENAPReservation)
+	if job.Spot {
+		nodeSelector[spotNodeLabel] = "true"
+	}
 
 	cap, err := g.FetchMachineCapabilities(job.MachineType, job.ClusterLocation)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch machine capabilities: %w", err)
+		return nil, fmt.Errorf("failed to fetch machine capabilities: %w", err)
 	}
 
 	var accelLabel string
@@ -1901,58 +2500,28 @@ func (g *GKEOrchestrator) buildNodeSelector(schedOpts SchedulingOptions, job orc
 	g.addAcceleratorLabel(nodeSelector, accelLabel, isCPUMachine, job.MachineType)
 
 	if err := g.addTopologyLabel(nodeSelector, schedOpts, isGPU, isCPUMachine); err != nil {
-		return "", err
-	}
-
-	if len(nodeSelector) > 0 {
-		b, err := yaml.Marshal(nodeSelector)
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal nodeSelector: %w", err)
-		}
-		return indentYaml(string(b), 16), nil
+		return nil, err
 	}
-	return "", nil
-}
 
-func (g *GKEOrchestrator) buildAffinity(schedOpts SchedulingOptions) (string, error) {
-	affinity, err := GetAffinity(schedOpts)
-	if err != nil {
-		return "", err
-	}
-	if affinity != nil {
-		b, err := k8syaml.Marshal(affinity)
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal affinity: %w", err)
-		}
-		return indentYaml(string(b), 16), nil
-	}
-	return "", nil
+	return nilIfEmpty(nodeSelector), nil
 }
 
-func (g *GKEOrchestrator) buildTopologyAnnotation(topology string, machineType string, numSlices int, nodesPerSlice int, isSubSlicing bool) string {
-	var topologyAnnotation map[string]string
+func (g *GKEOrchestrator) buildTopologyAnnotations(topology string, machineType string, numSlices int, nodesPerSlice int, isSubSlicing bool) map[string]string {
 	if isSubSlicing {
-		topologyAnnotation = GetTopologyAnnotation(topology, machineType, numSlices, nodesPerSlice)
+		return nilIfEmpty(GetTopologyAnnotation(topology, machineType, numSlices, nodesPerSlice))
 	} else if topology != "" {
-		topologyAnnotation = map[string]string{
+		return map[string]string{
 			"cloud.google.com/gke-tpu-slice-topology": topology,
 		}
 	}
-	if len(topologyAnnotation) > 0 {
-		b, err := yaml.Marshal(topologyAnnotation)
-		if err == nil {
-			return indentYaml(string(b), 16)
-		}
-	}
-	return ""
+	return nil
 }
 
-func (d *DefaultKubeClient) GetJobNamespace(workloadName string) (string, error) {
-	gvr := schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
+func (d *DefaultKubeClient) GetJobNamespace(ctx context.Context, workloadName string) (string, error) {
 	optsSelector := metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("gcluster.google.com/workload=%s", workloadName),
 	}
-	list, err := d.dynClient.Resource(gvr).Namespace("").List(context.TODO(), optsSelector)
+	list, err := d.dynClient.Resource(jobSetGVR).Namespace("").List(ctx, optsSelector)
 	if err != nil {
 		return "", fmt.Errorf("failed to search for jobset %s across namespaces: %w", workloadName, err)
 	}
@@ -1965,15 +2534,19 @@ func (d *DefaultKubeClient) GetJobNamespace(workloadName string) (string, error)
 	return "", fmt.Errorf("jobset %s not found in any namespace", workloadName)
 }
 
-func (d *DefaultKubeClient) DeleteJobSet(namespace string, name string) error {
-	gvr := schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
-	return d.dynClient.Resource(gvr).Namespace(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+func (d *DefaultKubeClient) DeleteJobSet(ctx context.Context, namespace string, name string) error {
+	return d.dynClient.Resource(jobSetGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: d.dryRunOption()})
 }
 
-func (d *DefaultKubeClient) ListWorkloads(namespace string, workloadName string) ([]string, error) {
+func (d *DefaultKubeClient) DeletePods(ctx context.Context, namespace string, labelSelector string, gracePeriodSeconds int64) error {
+	return d.dynClient.Resource(podGVR).Namespace(namespace).DeleteCollection(ctx,
+		metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds, DryRun: d.dryRunOption()},
+		metav1.ListOptions{LabelSelector: labelSelector})
+}
+
+func (d *DefaultKubeClient) ListWorkloads(ctx context.Context, namespace string, workloadName string) ([]string, error) {
 	// First, retrieve the JobSet to get its UID
-	jobsetGVR := schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
-	jobset, err := d.dynClient.Resource(jobsetGVR).Namespace(namespace).Get(context.TODO(), workloadName, metav1.GetOptions{})
+	jobset, err := d.dynClient.Resource(jobSetGVR).Namespace(namespace).Get(ctx, workloadName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get jobset %s in namespace %s: %w", workloadName, namespace, err)
 	}
@@ -1984,11 +2557,10 @@ func (d *DefaultKubeClient) ListWorkloads(namespace string, workloadName string)
 	}
 
 	// Now list workloads using the UID label selector
-	workloadGVR := schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "workloads"}
 	listOpts := metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("kueue.x-k8s.io/job-uid=%s", uid),
 	}
-	workloadList, err := d.dynClient.Resource(workloadGVR).Namespace(namespace).List(context.TODO(), listOpts)
+	workloadList, err := d.dynClient.Resource(workloadGVR).Namespace(namespace).List(ctx, listOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list workloads in namespace %s: %w", namespace, err)
 	}
@@ -2000,9 +2572,8 @@ func (d *DefaultKubeClient) ListWorkloads(namespace string, workloadName string)
 	return matchedWorkloads, nil
 }
 
-func (d *DefaultKubeClient) ListJobSets(labelSelector string) ([]orchestrator.JobStatus, error) {
-	gvr := schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
-	list, err := d.dynClient.Resource(gvr).Namespace("").List(context.Background(), metav1.ListOptions{
+func (d *DefaultKubeClient) ListJobSets(ctx context.Context, labelSelector string) ([]orchestrator.JobStatus, error) {
+	list, err := d.dynClient.Resource(jobSetGVR).Namespace("").List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
 	})
 	if err != nil {
@@ -2019,9 +2590,12 @@ func (d *DefaultKubeClient) ListJobSets(labelSelector string) ([]orchestrator.Jo
 
 		jobs = append(jobs, orchestrator.JobStatus{
 			Name:           name,
+			Namespace:      item.GetNamespace(),
 			Status:         statusStr,
 			CreationTime:   creationTime,
 			CompletionTime: completionTime,
+			Queue:          item.GetLabels()["kueue.x-k8s.io/queue-name"],
+			Experiment:     item.GetLabels()[experimentLabel],
 		})
 	}
 
@@ -2029,11 +2603,18 @@ func (d *DefaultKubeClient) ListJobSets(labelSelector string) ([]orchestrator.Jo
 }
 
 func (d *DefaultExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	if d.ctx != nil {
+		return shell.ExecuteCommandContext(d.ctx, name, args...)
+	}
 	return shell.ExecuteCommand(name, args...)
 }
 
 func (d *DefaultExecutor) ExecuteCommandStream(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
+	ctx := d.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
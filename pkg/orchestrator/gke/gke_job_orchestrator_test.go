@@ -15,13 +15,32 @@
 package gke
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
+	"hpc-toolkit/pkg/testutil"
+	"io"
 	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func setupMockMachineConfig(t *testing.T) {
@@ -76,6 +95,7 @@ func NewMockExecutor(responses map[string][]shell.CommandResult) *MockExecutor {
 func newTestGKEOrchestrator(executor Executor) *GKEOrchestrator {
 	return &GKEOrchestrator{
 		executor:                 executor,
+		dynClient:                newFakeDynamicClient(),
 		kubeClient:               &MockKubeClient{Namespace: "default"},
 		machineTypeClient:        &MockMachineTypeClient{Executor: executor},
 		acceleratorToMachineType: make(map[string]string),
@@ -111,25 +131,62 @@ func (m *MockExecutor) ExecuteCommandStream(name string, args ...string) error {
 }
 
 type MockKubeClient struct {
-	Namespace string
-	Workloads []string
-	Err       error
+	Namespace   string
+	Workloads   []string
+	Err         error
+	DeletedPods []string
+	Applied     []string
+	// MissingCRDs lists the CRDs CRDInstalled reports as absent.
+	MissingCRDs []string
+	// JobSets is the result of ListJobSets; DeletedJobSets records
+	// DeleteJobSet calls as namespace/name.
+	JobSets        []orchestrator.JobStatus
+	DeletedJobSets []string
+	// DeletedServices records DeleteServices calls as namespace/selector.
+	DeletedServices []string
+	// Logs are the logs PodLogs returns, by pod or by pod/container.
+	Logs map[string]string
+	// LogRequests records PodLogs calls as namespace/pod/container, and
+	// LogOptions their options.
+	LogRequests []string
+	LogOptions  []corev1.PodLogOptions
+	// LogErrs are returned, in order, by the first PodLogs calls.
+	LogErrs []error
+	// Forwarded records PortForward calls as namespace/pod ports.
+	Forwarded []string
+	// Raw are the bodies RawGet returns, by path.
+	Raw map[string]string
+	// Denied lists the "verb resource" pairs CanI refuses.
+	Denied []string
+
+	mu sync.Mutex
 }
 
-func (m *MockKubeClient) GetJobNamespace(workloadName string) (string, error) {
+func (m *MockKubeClient) GetJobNamespace(_ context.Context, workloadName string) (string, error) {
 	return m.Namespace, m.Err
 }
 
-func (m *MockKubeClient) ListWorkloads(namespace string, workloadName string) ([]string, error) {
+func (m *MockKubeClient) ListWorkloads(_ context.Context, namespace string, workloadName string) ([]string, error) {
 	return m.Workloads, m.Err
 }
 
-func (m *MockKubeClient) DeleteJobSet(namespace string, name string) error {
+func (m *MockKubeClient) DeleteJobSet(_ context.Context, namespace string, name string) error {
+	m.DeletedJobSets = append(m.DeletedJobSets, namespace+"/"+name)
 	return m.Err
 }
 
-func (m *MockKubeClient) ListJobSets(labelSelector string) ([]orchestrator.JobStatus, error) {
-	return []orchestrator.JobStatus{}, m.Err
+func (m *MockKubeClient) DeletePods(_ context.Context, namespace string, labelSelector string, gracePeriodSeconds int64) error {
+	m.DeletedPods = append(m.DeletedPods, fmt.Sprintf("%s/%s grace=%d", namespace, labelSelector, gracePeriodSeconds))
+	return m.Err
+}
+
+func (m *MockKubeClient) DeleteServices(_ context.Context, namespace string, labelSelector string) error {
+	m.DeletedServices = append(m.DeletedServices, namespace+"/"+labelSelector)
+	return m.Err
+}
+
+func (m *MockKubeClient) ListJobSets(_ context.Context, labelSelector string) ([]orchestrator.JobStatus, error) {
+	return m.JobSets, m.Err
 }
 
 func (m *MockKubeClient) GetCurrentNamespace() (string, error) {
@@ -139,6 +196,55 @@ func (m *MockKubeClient) GetCurrentNamespace() (string, error) {
 	return "default", m.Err
 }
 
+func (m *MockKubeClient) ApplyManifests(_ context.Context, manifests []byte) error {
+	m.Applied = append(m.Applied, string(manifests))
+	return m.Err
+}
+
+func (m *MockKubeClient) CRDInstalled(_ context.Context, name string) (bool, error) {
+	return !slices.Contains(m.MissingCRDs, name), m.Err
+}
+
+func (m *MockKubeClient) PodLogs(_ context.Context, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.LogRequests = append(m.LogRequests, namespace+"/"+pod+"/"+opts.Container)
+	m.LogOptions = append(m.LogOptions, *opts)
+	if len(m.LogErrs) > 0 {
+		err := m.LogErrs[0]
+		m.LogErrs = m.LogErrs[1:]
+		return nil, err
+	}
+	logs, ok := m.Logs[pod+"/"+opts.Container]
+	if !ok {
+		logs, ok = m.Logs[pod]
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if !ok {
+		return nil, apierrors.NewNotFound(podGVR.GroupResource(), pod)
+	}
+	return io.NopCloser(strings.NewReader(logs)), nil
+}
+
+func (m *MockKubeClient) PortForward(_ context.Context, namespace, pod string, ports []string) error {
+	m.Forwarded = append(m.Forwarded, namespace+"/"+pod+" "+strings.Join(ports, " "))
+	return m.Err
+}
+
+func (m *MockKubeClient) RawGet(_ context.Context, path string) ([]byte, error) {
+	body, ok := m.Raw[path]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, path)
+	}
+	return []byte(body), nil
+}
+
+func (m *MockKubeClient) CanI(_ context.Context, verb, resource string) (bool, error) {
+	return !slices.Contains(m.Denied, verb+" "+resource), m.Err
+}
+
 func TestGenerateGKEManifest_Accelerators(t *testing.T) {
 	setupMockMachineConfig(t)
 
@@ -231,17 +337,16 @@ func TestGenerateGKEManifest_Accelerators(t *testing.T) {
 			}
 
 			mockResponses := map[string][]shell.CommandResult{
-				"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
-				"kubectl get nodes -o jsonpath={range .items[*]}{.metadata.labels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end}": {{ExitCode: 0, Stdout: "16x16"}},
-				"gcloud compute machine-types describe n2-standard-2 --zone=us-central1-a --format=json":                                {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
-				"gcloud compute machine-types describe nvidia-h100-mega-80gb --zone=us-central1-a --format=json":                        {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-h100-mega-80gb"}]}`}},
-				"gcloud compute machine-types describe nvidia-gb200 --zone=us-central1-a --format=json":                                 {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-gb200"}]}`}},
-				"gcloud compute machine-types describe nvidia-l4 --zone=us-central1-a --format=json":                                    {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-l4"}]}`}},
-				"gcloud compute machine-types describe tpu-v6e-slice --zone=us-central1-a --format=json":                                {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu-v6e-slice"}]}`}},
-				"gcloud compute machine-types describe nvidia-unknown-new-gpu --zone=us-central1-a --format=json":                       {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-unknown-new-gpu"}]}`}},
+				"gcloud compute machine-types describe n2-standard-2 --zone=us-central1-a --format=json":          {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
+				"gcloud compute machine-types describe nvidia-h100-mega-80gb --zone=us-central1-a --format=json":  {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-h100-mega-80gb"}]}`}},
+				"gcloud compute machine-types describe nvidia-gb200 --zone=us-central1-a --format=json":           {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-gb200"}]}`}},
+				"gcloud compute machine-types describe nvidia-l4 --zone=us-central1-a --format=json":              {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-l4"}]}`}},
+				"gcloud compute machine-types describe tpu-v6e-slice --zone=us-central1-a --format=json":          {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu-v6e-slice"}]}`}},
+				"gcloud compute machine-types describe nvidia-unknown-new-gpu --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-unknown-new-gpu"}]}`}},
 			}
 			mockExec := NewMockExecutor(mockResponses)
 			orc := newTestGKEOrchestrator(mockExec)
+			orc.dynClient = newFakeDynamicClient(fakeTPUNodes("tpu-v6e-slice", "16x16"))
 			orc.projectID = "mock-project"
 			orc.clusterDesc.NodePools = []gkeJobNodePool{
 				{Config: gkeNodePoolConfig{MachineType: "nvidia-h100-mega-80gb"}},
@@ -252,7 +357,7 @@ func TestGenerateGKEManifest_Accelerators(t *testing.T) {
 				{Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
 			}
 
-			profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+			profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 			var manifest string
 			if err == nil {
 				var opts ManifestOptions
@@ -314,9 +419,12 @@ func TestGenerateGKEManifest_Volumes(t *testing.T) {
 			"/host/path:/host",
 			"my-pvc:/pvc",
 		},
+		SecretMounts:    []string{"api-token:/etc/token"},
+		ConfigMapMounts: []string{"train-config:/etc/config"},
+		SharedVolumes:   []orchestrator.SharedVolume{{Server: "10.0.0.2", Path: "/share1", MountPath: "/mnt/nfs"}},
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -347,6 +455,18 @@ func TestGenerateGKEManifest_Volumes(t *testing.T) {
 		"path: /host/path",
 		"persistentVolumeClaim:",
 		"claimName: my-pvc",
+		"name: secret-0",
+		"mountPath: /etc/token",
+		"secretName: api-token",
+		"name: configmap-0",
+		"mountPath: /etc/config",
+		"name: train-config",
+		"name: shared-0",
+		"mountPath: /mnt/nfs",
+		"claimName: gcluster-nfs-10-0-0-2-share1",
+		"kind: PersistentVolume",
+		"server: 10.0.0.2",
+		"path: /share1",
 	}
 
 	for _, want := range expectedSubStrs {
@@ -384,13 +504,9 @@ func TestGenerateGKEManifest_CommandEscaping(t *testing.T) {
 		t.Fatalf("GenerateGKEManifest failed: %v", err)
 	}
 
-	// We expect the command to be properly rendered as a YAML list
-	expectedSubStr := `                command:
-                - "/bin/bash"
-                - "-c"
-                - "python -c \"print('hello')\" && echo \"world\""`
-	if !strings.Contains(manifest, expectedSubStr) {
-		t.Errorf("manifest command string is not properly rendered as a YAML list.\nExpected substring:\n%s\nActual manifest:\n%s", expectedSubStr, manifest)
+	want := []string{"/bin/bash", "-c", `python -c "print('hello')" && echo "world"`}
+	if got := decodePodSpec(t, manifest).Containers[0].Command; !reflect.DeepEqual(got, want) {
+		t.Errorf("container command = %q, want %q", got, want)
 	}
 }
 
@@ -437,6 +553,148 @@ spec:
 	}
 }
 
+func TestGenerateGKEManifest_ExecFormAndMultiLineCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		commandToRun string
+		commandArgs  []string
+		wantCommand  []string
+		wantArgs     []string
+	}{
+		{
+			name:        "exec form",
+			commandArgs: []string{"python", "train.py", "--msg", "it's \"done\""},
+			wantCommand: []string{"python"},
+			wantArgs:    []string{"train.py", "--msg", "it's \"done\""},
+		},
+		{
+			name:         "multi-line command",
+			commandToRun: "pip install -r requirements.txt\npython train.py\n",
+			wantCommand:  []string{"/bin/bash", "-e", "-c"},
+			wantArgs:     []string{"pip install -r requirements.txt\npython train.py"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setupMockMachineConfig(t)
+			mockExec := NewMockExecutor(map[string][]shell.CommandResult{
+				"gcloud compute machine-types describe nvidia-l4 --zone=us-central1-a --format=json": {
+					{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1}]}`},
+				},
+			})
+			orc := newTestGKEOrchestrator(mockExec)
+			orc.projectID = "mock-project"
+			orc.clusterDesc.NodePools = []gkeJobNodePool{
+				{Config: gkeNodePoolConfig{MachineType: "nvidia-l4"}},
+			}
+			opts := ManifestOptions{
+				WorkloadName:    "test-workload",
+				FullImageName:   "test-image:latest",
+				CommandToRun:    tc.commandToRun,
+				CommandArgs:     tc.commandArgs,
+				ComputeType:     "nvidia-l4",
+				MachineType:     "nvidia-l4",
+				ClusterLocation: "us-central1-a",
+			}
+
+			manifest, err := orc.GenerateGKEManifest(opts, JobProfile{})
+			if err != nil {
+				t.Fatalf("GenerateGKEManifest failed: %v", err)
+			}
+			c := decodePodSpec(t, manifest).Containers[0]
+			if !reflect.DeepEqual(c.Command, tc.wantCommand) || !reflect.DeepEqual(c.Args, tc.wantArgs) {
+				t.Errorf("container command = %q, args = %q, want %q, %q", c.Command, c.Args, tc.wantCommand, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestGenerateGKEManifest_CostLabels(t *testing.T) {
+	setupMockMachineConfig(t)
+	mockExec := NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud compute machine-types describe nvidia-l4 --zone=us-central1-a --format=json": {
+			{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1}]}`},
+		},
+	})
+	orc := newTestGKEOrchestrator(mockExec)
+	orc.projectID = "mock-project"
+	orc.clusterDesc.NodePools = []gkeJobNodePool{
+		{Config: gkeNodePoolConfig{MachineType: "nvidia-l4"}},
+	}
+	opts := ManifestOptions{
+		WorkloadName:    "test-workload",
+		FullImageName:   "test-image:latest",
+		CommandToRun:    "python train.py",
+		ComputeType:     "nvidia-l4",
+		MachineType:     "nvidia-l4",
+		ClusterLocation: "us-central1-a",
+		CostLabels:      map[string]string{"user": "jdoe", "team": "ml-infra"},
+	}
+
+	manifest, err := orc.GenerateGKEManifest(opts, JobProfile{})
+	if err != nil {
+		t.Fatalf("GenerateGKEManifest failed: %v", err)
+	}
+	want := map[string]string{"gcluster.google.com/workload": "test-workload", "team": "ml-infra", "user": "jdoe"}
+	if got := decodeJobSet(t, manifest).Spec.ReplicatedJobs[0].Template.Spec.Template.Labels; !reflect.DeepEqual(got, want) {
+		t.Errorf("pod labels = %v, want %v", got, want)
+	}
+}
+
+// pathwaysCommandPattern matches the line of the Pathways head script that
+// evaluates the base64-encoded user command.
+var pathwaysCommandPattern = regexp.MustCompile(`eval "\$\(echo ([A-Za-z0-9+/=]+) \| base64 -d\)"`)
+
+// decodePathwaysCommand returns the user command embedded in a rendered
+// Pathways manifest.
+func decodePathwaysCommand(t *testing.T, manifest string) string {
+	t.Helper()
+	m := pathwaysCommandPattern.FindStringSubmatch(manifest)
+	if m == nil {
+		t.Fatalf("manifest does not evaluate an encoded command:\n%s", manifest)
+	}
+	command, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		t.Fatalf("failed to decode the command %q: %v", m[1], err)
+	}
+	return string(command)
+}
+
+func TestPathwaysCommand(t *testing.T) {
+	if got, want := decodePathwaysCommand(t, pathwaysCommand("", []string{"python", "train.py", "--name", "it's a run"})), `python train.py --name 'it'"'"'s a run'`; got != want {
+		t.Errorf("pathwaysCommand(exec) = %q, want %q", got, want)
+	}
+}
+
+// TestPathwaysCommand_RunsUnchanged runs commands that a shell script or a
+// YAML block scalar would mangle both directly and as the Pathways head
+// script evaluates them, and compares their output.
+func TestPathwaysCommand_RunsUnchanged(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	for _, command := range []string{
+		`echo "double \"quoted\"" 'single' $(echo substituted) \\back\\slash`,
+		"cat <<EOF\n  indented heredoc: $((1 + 2))\nEOF",
+		"python3 -c 'pass' 2>/dev/null; printf '%s\\n' \"a  b\" '\ttab'",
+		"echo ')' && (echo nested) # trailing comment with {{ .Braces }}",
+		"x=1\nif [ $x = 1 ]; then\n    echo multi-line\nfi",
+	} {
+		want, err := exec.Command("bash", "-c", command).CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash -c %q failed: %v\n%s", command, err, want)
+		}
+		script := "(\n  " + pathwaysCommand(command, nil) + "\n)"
+		got, err := exec.Command("bash", "-c", script).CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash -c %q failed: %v\n%s", script, err, got)
+		}
+		if string(got) != string(want) {
+			t.Errorf("command %q printed %q through the Pathways script, want %q", command, got, want)
+		}
+	}
+}
+
 func TestGeneratePathwaysManifest(t *testing.T) {
 	setupMockMachineConfig(t)
 	job := orchestrator.JobDefinition{
@@ -464,7 +722,7 @@ func TestGeneratePathwaysManifest(t *testing.T) {
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
 	}
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -489,15 +747,15 @@ func TestGeneratePathwaysManifest(t *testing.T) {
 		"alpha.jobset.sigs.k8s.io/exclusive-topology: kubernetes.io/hostname",
 		"MEGASCALE_GRPC_ENABLE_XOR_TRACER",
 		`cpu: "16"`,
-		`memory: "100Gi"`,
+		"memory: 100Gi",
 		`cpu: "8"`,
-		`memory: "32Gi"`,
+		"memory: 32Gi",
 		"restartStrategy: BlockingRecreate",
 		"privileged: true",
 		"alpha.jobset.sigs.k8s.io/exclusive-topology: cloud.google.com/gke-nodepool",
 		`cpu: "24"`,
 		`cpu: "2"`,
-		`memory: "8Gi"`,
+		"memory: 8Gi",
 		"kill -SIGTERM $PID",
 		"echo \"Exit code: $EXIT_CODE\"",
 		"name: shared-tmp",
@@ -549,7 +807,7 @@ func TestGeneratePathwaysManifest_MTC(t *testing.T) {
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
 	}
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -596,62 +854,68 @@ func TestAwaitJobCompletion(t *testing.T) {
 	clusterLocation := "us-central1-a"
 	projectID := "test-project"
 
+	finished := fakeObject("Workload", "default", "jobset-test-workload-abc",
+		`{"status": {"conditions": [{"type": "Finished", "status": "True", "lastTransitionTime": "2026-04-12T12:00:00Z"}]}}`)
+	jobSet := func(condition, terminalState string) string {
+		return fakeObject("JobSet", "default", workloadName, fmt.Sprintf(
+			`{"status": {"terminalState": %q, "conditions": [{"type": %q, "status": "True", "lastTransitionTime": "2026-04-12T12:00:00Z"}]}}`,
+			terminalState, condition))
+	}
+
 	tests := []struct {
 		name          string
 		mockNamespace string
 		mockWorkloads []string
-		mockResponses map[string][]shell.CommandResult
+		manifests     []string
+		noQueue       bool
 		expectedError string
 	}{
 		{
 			name:          "Successful completion",
 			mockNamespace: "default",
 			mockWorkloads: []string{"jobset-test-workload-abc"},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl wait --for=condition=Finished workload jobset-test-workload-abc -n default --timeout=1h": {
-					{ExitCode: 0, Stdout: "workload condition met"},
-				},
-				"kubectl get jobset test-workload -n default -o json": {
-					{ExitCode: 0, Stdout: `{"status": {"conditions": [{"type": "Completed", "status": "True", "lastTransitionTime": "2026-04-12T12:00:00Z"}]}}`},
-				},
-			},
+			manifests:     []string{finished, jobSet("Completed", "Completed")},
 			expectedError: "",
 		},
 		{
 			name:          "Job timeout",
 			mockNamespace: "default",
 			mockWorkloads: []string{"jobset-test-workload-abc"},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl wait --for=condition=Finished workload jobset-test-workload-abc -n default --timeout=1h": {
-					{ExitCode: 1, Stderr: "timed out waiting for conditions to be met"},
-				},
-			},
+			manifests:     []string{fakeObject("Workload", "default", "jobset-test-workload-abc", `{}`)},
 			expectedError: "job timed out",
 		},
 		{
 			name:          "Job finished but not completed",
 			mockNamespace: "default",
 			mockWorkloads: []string{"jobset-test-workload-abc"},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl wait --for=condition=Finished workload jobset-test-workload-abc -n default --timeout=1h": {
-					{ExitCode: 0, Stdout: "workload condition met"},
-				},
-				"kubectl get jobset test-workload -n default -o json": {
-					{ExitCode: 0, Stdout: `{"status": {"conditions": [{"type": "Failed", "status": "True", "lastTransitionTime": "2026-04-12T12:00:00Z"}]}}`},
-				},
-			},
+			manifests:     []string{finished, jobSet("Failed", "Failed")},
 			expectedError: "job completed unsuccessfully with status: Failed",
 		},
+		{
+			name:          "Completion without a queue",
+			mockNamespace: "default",
+			manifests:     []string{jobSet("Completed", "Completed")},
+			noQueue:       true,
+			expectedError: "",
+		},
+		{
+			name:          "Timeout without a queue",
+			mockNamespace: "default",
+			manifests:     []string{fakeObject("JobSet", "default", workloadName, `{}`)},
+			noQueue:       true,
+			expectedError: "job timed out",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockExecutor := &MockExecutor{responses: tt.mockResponses, callCount: make(map[string]int)}
 			mockKube := &MockKubeClient{Namespace: tt.mockNamespace, Workloads: tt.mockWorkloads}
-			orc := newTestGKEOrchestrator(mockExecutor)
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
 			orc.kubeClient = mockKube
+			orc.dynClient = newFakeDynamicClient(tt.manifests...)
+			orc.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
 
-			err := orc.awaitJobCompletion(workloadName, clusterName, clusterLocation, projectID, "1h")
+			err := orc.awaitJobCompletion(context.Background(), workloadName, clusterName, clusterLocation, projectID, "1h", tt.noQueue)
 
 			if tt.expectedError == "" {
 				if err != nil {
@@ -1009,12 +1273,13 @@ func TestDetermineIfCPUMachine_Hyperthreading(t *testing.T) {
 
 func TestVerifyDynamicSlicingActive(t *testing.T) {
 	tests := []struct {
-		name          string
-		opts          ManifestOptions
-		nodePools     []gkeJobNodePool
-		mockResponses map[string][]shell.CommandResult
-		wantResult    bool
-		wantErr       bool
+		name       string
+		opts       ManifestOptions
+		nodePools  []gkeJobNodePool
+		manifests  []string
+		failList   string
+		wantResult bool
+		wantErr    bool
 	}{
 		{
 			name: "Success - TPU7x Dynamic-slicing active via PROVISION_ONLY",
@@ -1035,11 +1300,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"spec":{"controllerName":"accelerator.gke.io/slice"}}]}`},
-				},
-			},
+			manifests:  []string{fakeAdmissionCheck("accelerator.gke.io/slice")},
 			wantResult: true,
 		},
 		{
@@ -1061,8 +1322,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: nil,
-			wantResult:    false,
+			wantResult: false,
 		},
 		{
 			name: "Success - TPU7x Dynamic-slicing active via topology subset (static reservation)",
@@ -1083,11 +1343,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"spec":{"controllerName":"accelerator.gke.io/slice"}}]}`},
-				},
-			},
+			manifests:  []string{fakeAdmissionCheck("accelerator.gke.io/slice")},
 			wantResult: false,
 		},
 		{
@@ -1109,11 +1365,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"spec":{"controllerName":"accelerator.gke.io/slice"}}]}`},
-				},
-			},
+			manifests:  []string{fakeAdmissionCheck("accelerator.gke.io/slice")},
 			wantResult: true,
 			wantErr:    false,
 		},
@@ -1136,11 +1388,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"spec":{"controllerName":"accelerator.gke.io/slice"}}]}`},
-				},
-			},
+			manifests:  []string{fakeAdmissionCheck("accelerator.gke.io/slice")},
 			wantResult: true,
 			wantErr:    true,
 		},
@@ -1163,11 +1411,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"spec":{"controllerName":"accelerator.gke.io/slice"}}]}`},
-				},
-			},
+			manifests:  []string{fakeAdmissionCheck("accelerator.gke.io/slice")},
 			wantResult: true,
 			wantErr:    true,
 		},
@@ -1190,11 +1434,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"spec":{"controllerName":"accelerator.gke.io/slice"}}]}`},
-				},
-			},
+			manifests:  []string{fakeAdmissionCheck("accelerator.gke.io/slice")},
 			wantResult: true,
 			wantErr:    true,
 		},
@@ -1217,8 +1457,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: nil,
-			wantResult:    false,
+			wantResult: false,
 		},
 		{
 			name: "Failure - No TPU",
@@ -1227,9 +1466,8 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 				ClusterLocation: "us-central1-a",
 				ComputeType:     "nvidia-l4",
 			},
-			nodePools:     nil,
-			mockResponses: nil,
-			wantResult:    false,
+			nodePools:  nil,
+			wantResult: false,
 		},
 		{
 			name: "Failure - No matching node pool",
@@ -1246,9 +1484,8 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: nil,
-			wantResult:    false,
-			wantErr:       true,
+			wantResult: false,
+			wantErr:    true,
 		},
 		{
 			name: "Failure - CRD not found",
@@ -1265,11 +1502,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io -o json": {
-					{ExitCode: 1},
-				},
-			},
+			failList:   "topologies",
 			wantResult: false,
 		},
 		{
@@ -1290,11 +1523,7 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"spec":{"controllerName":"other-controller"}}]}`},
-				},
-			},
+			manifests:  []string{fakeAdmissionCheck("other-controller")},
 			wantResult: false,
 		},
 		{
@@ -1315,29 +1544,29 @@ func TestVerifyDynamicSlicingActive(t *testing.T) {
 					},
 				},
 			},
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get admissioncheck -o json": {
-					{ExitCode: 1, Stderr: "error"},
-				},
-			},
+			failList:   "admissionchecks",
 			wantResult: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.mockResponses != nil {
-				if _, ok := tt.mockResponses["kubectl get topologies.kueue.x-k8s.io -o json"]; !ok {
-					tt.mockResponses["kubectl get topologies.kueue.x-k8s.io -o json"] = []shell.CommandResult{
-						{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x2-id"}]}}]}`},
-					}
-				}
+			// Clusters with slicing set up also have a slice Kueue Topology.
+			manifests := tt.manifests
+			if manifests != nil || tt.failList != "" {
+				manifests = append(manifests, fakeKueueTopology("cloud.google.com/gke-tpu-slice-2x2-id"))
 			}
-			mockExecutor := NewMockExecutor(tt.mockResponses)
-			orc := newTestGKEOrchestrator(mockExecutor)
+			client := newFakeDynamicClient(manifests...)
+			if tt.failList != "" {
+				client.PrependReactor("list", tt.failList, func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, fmt.Errorf("failed to list %s", tt.failList)
+				})
+			}
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			orc.dynClient = client
 			orc.clusterDesc.NodePools = tt.nodePools
 
-			got, err := orc.verifyDynamicSlicingActive(tt.opts)
+			got, err := orc.verifyDynamicSlicingActive(context.Background(), tt.opts)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("verifyDynamicSlicingActive() error = %v, wantErr %v", err, tt.wantErr)
@@ -1377,7 +1606,7 @@ func TestGenerateGKEManifest_Verbose_GPU(t *testing.T) {
 		t.Fatalf("GenerateGKEManifest failed: %v", err)
 	}
 
-	if !strings.Contains(manifest, "name: NCCL_DEBUG") || !strings.Contains(manifest, "value: \"INFO\"") {
+	if !slices.Contains(decodePodSpec(t, manifest).Containers[0].Env, corev1.EnvVar{Name: "NCCL_DEBUG", Value: "INFO"}) {
 		t.Errorf("manifest missing expected GPU verbose env var.\nManifest: %s", manifest)
 	}
 }
@@ -1627,13 +1856,12 @@ func TestGenerateGKEManifest_DynamicVmsPerSlice(t *testing.T) {
 
 	orc := NewGKEOrchestrator()
 	orc.projectID = "mock-project"
+	orc.dynClient = newFakeDynamicClient()
 	mockExec := NewMockExecutor(map[string][]shell.CommandResult{
 		"gcloud compute machine-types describe ct6e-standard-8t --zone=us-central1-a --format=json": {
 			{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 8, "guestAcceleratorType": "tpu-v6e-slice"}]}`},
 			{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 8, "guestAcceleratorType": "tpu-v6e-slice"}]}`},
 		},
-		"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
-		"kubectl get nodes":           {{ExitCode: 0, Stdout: ""}},
 	})
 	orc.SetExecutor(mockExec)
 	orc.machineTypeClient = &MockMachineTypeClient{Executor: mockExec}
@@ -1647,7 +1875,7 @@ func TestGenerateGKEManifest_DynamicVmsPerSlice(t *testing.T) {
 		NodesPerSlice:   0,
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -1679,9 +1907,8 @@ func TestGenerateGKEManifest_RespectUserNumNodes(t *testing.T) {
 
 	orc := NewGKEOrchestrator()
 	orc.projectID = "mock-project"
+	orc.dynClient = newFakeDynamicClient()
 	mockExec := NewMockExecutor(map[string][]shell.CommandResult{
-		"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
-		"kubectl get nodes":           {{ExitCode: 0, Stdout: ""}},
 		"gcloud compute machine-types describe g2-standard-12 --zone=us-central1-a --format=json": {
 			{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-l4"}]}`},
 			{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 1, "guestAcceleratorType": "nvidia-l4"}]}`},
@@ -1698,7 +1925,7 @@ func TestGenerateGKEManifest_RespectUserNumNodes(t *testing.T) {
 		NodesPerSlice:   32,     // Explicitly set to 32 (representing --num-nodes)
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -1729,9 +1956,8 @@ func TestGenerateGKEManifest_ParallelContainers(t *testing.T) {
 
 	orc := NewGKEOrchestrator()
 	orc.projectID = "mock-project"
+	orc.dynClient = newFakeDynamicClient()
 	mockExec := NewMockExecutor(map[string][]shell.CommandResult{
-		"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
-		"kubectl get nodes":           {{ExitCode: 0, Stdout: ""}},
 		"gcloud compute machine-types describe tpu7x-standard-4t --zone=us-central1-a --format=json": {
 			{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu-v7x-slice"}]}`},
 		},
@@ -1748,7 +1974,7 @@ func TestGenerateGKEManifest_ParallelContainers(t *testing.T) {
 		UseParallelContainers: true,    // Enable parallel containers
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -1795,28 +2021,10 @@ func TestConfigureClusterEnvironment_AutoCreateQueues(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	responses := map[string][]shell.CommandResult{
-		"kubectl get localqueue default-queue -n default": {
-			{ExitCode: 1, Stderr: "Error from server (NotFound): localqueues.kueue.x-k8s.io \"default-queue\" not found"},
-		},
-		"kubectl apply -f": {
-			{ExitCode: 0, Stdout: "resourceflavor.kueue.x-k8s.io/flavor-default created"},
-			{ExitCode: 0, Stdout: "clusterqueue.kueue.x-k8s.io/cluster-queue created"},
-			{ExitCode: 0, Stdout: "localqueue.kueue.x-k8s.io/default-queue created"},
-		},
-		"kubectl get localqueue default-queue -n default -o jsonpath={.spec.clusterQueue}": {
-			{ExitCode: 0, Stdout: "cluster-queue"},
-		},
-		"kubectl get clusterqueue cluster-queue -o json": {
-			{ExitCode: 0, Stdout: "{\"apiVersion\":\"kueue.x-k8s.io/v1beta2\",\"kind\":\"ClusterQueue\",\"spec\":{\"resourceGroups\":[{\"coveredResources\":[\"cpu\"]}]}}"},
-		},
-		"kubectl patch clusterqueue cluster-queue": {
-			{ExitCode: 0, Stdout: "clusterqueue.kueue.x-k8s.io/cluster-queue patched"},
-		},
-	}
-
-	mockExec := NewMockExecutor(responses)
-	orc := newTestGKEOrchestrator(mockExec)
+	// The cluster has no LocalQueue default-queue, so it is created.
+	orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+	kube := &MockKubeClient{Namespace: "default"}
+	orc.kubeClient = kube
 	orc.capacity = ClusterCapacity{
 		Flavors: map[string]FlavorCapacity{
 			"flavor-default": {CPUs: 30},
@@ -1827,14 +2035,14 @@ func TestConfigureClusterEnvironment_AutoCreateQueues(t *testing.T) {
 		KueueQueueName: "default-queue",
 	}
 
-	err = orc.configureClusterEnvironment(job)
+	err = orc.configureClusterEnvironment(context.Background(), job)
 	if err != nil {
 		t.Fatalf("configureClusterEnvironment failed: %v", err)
 	}
 
 	// Verify calls
-	if mockExec.callCount["kubectl apply -f"] != 3 {
-		t.Errorf("Expected 3 calls to kubectl apply -f, got %d", mockExec.callCount["kubectl apply -f"])
+	if len(kube.Applied) != 3 {
+		t.Errorf("Expected 3 manifests to be applied, got %d", len(kube.Applied))
 	}
 }
 
@@ -1842,35 +2050,33 @@ func TestResolveKueueQueue(t *testing.T) {
 	tests := []struct {
 		name          string
 		requestedName string
-		kubectlOutput string
+		queues        []string
 		wantName      string
 		wantErr       bool
 	}{
 		{
 			name:          "User requested name",
 			requestedName: "custom-q",
-			kubectlOutput: "",
 			wantName:      "custom-q",
 			wantErr:       false,
 		},
 		{
 			name:          "No queues found, fallback to multislice-queue",
 			requestedName: "",
-			kubectlOutput: "",
 			wantName:      "multislice-queue",
 			wantErr:       false,
 		},
 		{
 			name:          "Single queue found, auto-discover",
 			requestedName: "",
-			kubectlOutput: "queue-1",
+			queues:        []string{"queue-1"},
 			wantName:      "queue-1",
 			wantErr:       false,
 		},
 		{
 			name:          "Multiple queues found, hard fail",
 			requestedName: "",
-			kubectlOutput: "queue-1 queue-2",
+			queues:        []string{"queue-1", "queue-2"},
 			wantName:      "",
 			wantErr:       true,
 		},
@@ -1878,15 +2084,14 @@ func TestResolveKueueQueue(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			responses := map[string][]shell.CommandResult{
-				"kubectl get localqueue -n default -o jsonpath={.items[*].metadata.name}": {
-					{ExitCode: 0, Stdout: tt.kubectlOutput},
-				},
+			var manifests []string
+			for _, q := range tt.queues {
+				manifests = append(manifests, fakeObject("LocalQueue", "default", q, `{"spec": {"clusterQueue": "cluster-queue"}}`))
 			}
-			mockExec := NewMockExecutor(responses)
-			orc := newTestGKEOrchestrator(mockExec)
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			orc.dynClient = newFakeDynamicClient(manifests...)
 
-			got, err := orc.resolveKueueQueue(tt.requestedName)
+			got, err := orc.resolveKueueQueue(context.Background(), tt.requestedName, "")
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("resolveKueueQueue() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -1919,7 +2124,7 @@ func TestGPUTopologyAwareScheduling(t *testing.T) {
 		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "nvidia-tesla-a100"}},
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -1928,8 +2133,8 @@ func TestGPUTopologyAwareScheduling(t *testing.T) {
 		t.Fatalf("PrepareManifestOptions failed: %v", err)
 	}
 
-	if opts.SchedulingGates == "" {
-		t.Errorf("Expected SchedulingGates to be populated, got empty string")
+	if len(opts.SchedulingGates) == 0 {
+		t.Errorf("Expected SchedulingGates to be populated, got none")
 	}
 
 	if opts.SchedulerName != "" {
@@ -2034,15 +2239,16 @@ func TestGenerateGKEManifest_DynamicSlicingActive_TPU7x(t *testing.T) {
 	}
 
 	mockResponses := map[string][]shell.CommandResult{
-		"kubectl get resourceflavors":                   {{ExitCode: 0, Stdout: ""}},
-		"kubectl get topologies.kueue.x-k8s.io -o json": {{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-partition-4x4x4-id"}]}}]}`}},
-		"kubectl get admissioncheck":                    {{ExitCode: 0, Stdout: `{"items": [{"spec": {"controllerName": "accelerator.gke.io/slice"}}]}`}},
-		"kubectl get nodes -o jsonpath={range .items[*]}{.metadata.labels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end}": {{ExitCode: 0, Stdout: "8x8x8"}},
-		"gcloud compute machine-types describe tpu7x-standard-4t --zone=us-central1-a --format=json":                            {{ExitCode: 0, Stdout: `{"guestCpus": 8, "memoryMb": 32768, "accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu7x-standard-4t"}]}`}},
+		"gcloud compute machine-types describe tpu7x-standard-4t --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 8, "memoryMb": 32768, "accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu7x-standard-4t"}]}`}},
 	}
 
 	mockExec := NewMockExecutor(mockResponses)
 	orc := newTestGKEOrchestrator(mockExec)
+	orc.dynClient = newFakeDynamicClient(
+		fakeKueueTopology("cloud.google.com/gke-tpu-partition-4x4x4-id"),
+		fakeAdmissionCheck("accelerator.gke.io/slice"),
+		fakeTPUNodes("tpu7x", "8x8x8"),
+	)
 	orc.projectID = "mock-project"
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{
@@ -2056,7 +2262,7 @@ func TestGenerateGKEManifest_DynamicSlicingActive_TPU7x(t *testing.T) {
 		},
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -2115,14 +2321,15 @@ func TestGeneratePathwaysManifest_DynamicSlicing(t *testing.T) {
 	}
 
 	mockResponses := map[string][]shell.CommandResult{
-		"kubectl get resourceflavors":                   {{ExitCode: 0, Stdout: ""}},
-		"kubectl get topologies.kueue.x-k8s.io -o json": {{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-partition-4x4x4-id"}]}}]}`}},
-		"kubectl get admissioncheck":                    {{ExitCode: 0, Stdout: `{"items": [{"spec": {"controllerName": "accelerator.gke.io/slice"}}]}`}},
-		"kubectl get nodes -o jsonpath={range .items[*]}{.metadata.labels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end} -l cloud.google.com/gke-tpu-accelerator=tpu7x": {{ExitCode: 0, Stdout: "8x8x8"}},
-		"gcloud compute machine-types describe tpu7x-standard-4t --zone=us-central1-a --format=json":                                                                          {{ExitCode: 0, Stdout: `{"guestCpus": 8, "memoryMb": 32768, "accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu7x-standard-4t"}]}`}},
+		"gcloud compute machine-types describe tpu7x-standard-4t --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 8, "memoryMb": 32768, "accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu7x-standard-4t"}]}`}},
 	}
 	mockExec := NewMockExecutor(mockResponses)
 	orc := newTestGKEOrchestrator(mockExec)
+	orc.dynClient = newFakeDynamicClient(
+		fakeKueueTopology("cloud.google.com/gke-tpu-partition-4x4x4-id"),
+		fakeAdmissionCheck("accelerator.gke.io/slice"),
+		fakeTPUNodes("tpu7x", "8x8x8"),
+	)
 	orc.projectID = "mock-project"
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{
@@ -2136,7 +2343,7 @@ func TestGeneratePathwaysManifest_DynamicSlicing(t *testing.T) {
 		},
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -2178,14 +2385,15 @@ func TestGenerateGKEManifest_StaticSlicingActive_v6e(t *testing.T) {
 	}
 
 	mockResponses := map[string][]shell.CommandResult{
-		"kubectl get resourceflavors":                   {{ExitCode: 0, Stdout: ""}, {ExitCode: 0, Stdout: ""}},
-		"kubectl get topologies.kueue.x-k8s.io -o json": {{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x2-id"}]}}]}`}, {ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x2-id"}]}}]}`}},
-		"kubectl get nodes":                             {{ExitCode: 0, Stdout: "4x4"}, {ExitCode: 0, Stdout: "4x4"}},
 		"gcloud compute machine-types describe ct6e-standard-8t --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 8, "memoryMb": 32768, "accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu-v6e-slice"}]}`}},
 	}
 
 	mockExec := NewMockExecutor(mockResponses)
 	orc := newTestGKEOrchestrator(mockExec)
+	orc.dynClient = newFakeDynamicClient(
+		fakeKueueTopology("cloud.google.com/gke-tpu-slice-2x2-id"),
+		fakeTPUNodes("tpu-v6e-slice", "4x4"),
+	)
 	orc.projectID = "mock-project"
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{
@@ -2196,7 +2404,7 @@ func TestGenerateGKEManifest_StaticSlicingActive_v6e(t *testing.T) {
 		},
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -2267,9 +2475,9 @@ func TestPopulateClusterMetadata_NAPLimitsLoopOrder(t *testing.T) {
 
 	orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
 	job := &orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterName:     "my-cluster",
-		ClusterLocation: "us-central1-a",
+		ClusterProjectID: "my-project",
+		ClusterName:      "my-cluster",
+		ClusterLocation:  "us-central1-a",
 	}
 
 	err := orc.populateClusterMetadata(job)
@@ -2310,9 +2518,9 @@ func TestPopulateClusterMetadata_LocationFallback(t *testing.T) {
 
 	orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
 	job := &orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterName:     "my-cluster",
-		ClusterLocation: "us-central1-a",
+		ClusterProjectID: "my-project",
+		ClusterName:      "my-cluster",
+		ClusterLocation:  "us-central1-a",
 	}
 
 	err := orc.populateClusterMetadata(job)
@@ -2325,6 +2533,80 @@ func TestPopulateClusterMetadata_LocationFallback(t *testing.T) {
 	}
 }
 
+func TestInitializeJobSubmission_DryRun(t *testing.T) {
+	setupMockMachineConfig(t)
+
+	// Only the cluster description is mocked: fetching credentials fails the
+	// test.
+	mockResponses := map[string][]shell.CommandResult{
+		"gcloud container clusters describe my-cluster --location us-central1 --project my-project --format=json": {
+			{ExitCode: 0, Stdout: `{"locations": ["us-central1-a"], "nodePools": [], "autoscaling": {}}`},
+		},
+	}
+
+	orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
+	job := &orchestrator.JobDefinition{
+		ClusterProjectID: "my-project",
+		ClusterName:      "my-cluster",
+		ClusterLocation:  "us-central1",
+		DryRun:           true,
+	}
+
+	if err := orc.initializeJobSubmission(context.Background(), job); err != nil {
+		t.Fatalf("initializeJobSubmission failed: %v", err)
+	}
+	if err := orc.configureClusterEnvironment(context.Background(), job); err != nil {
+		t.Fatalf("configureClusterEnvironment failed: %v", err)
+	}
+	if job.KueueQueueName != defaultLocalQueue {
+		t.Errorf("expected the queue to default to %q, got %q", defaultLocalQueue, job.KueueQueueName)
+	}
+	if orc.hasSlicingTopologies(context.Background()) {
+		t.Error("expected dynamic slicing to be inactive without cluster credentials")
+	}
+}
+
+func TestDryRunOutput(t *testing.T) {
+	var out bytes.Buffer
+	oldOut := dryRunOut
+	dryRunOut = &out
+	defer func() { dryRunOut = oldOut }()
+
+	job := orchestrator.JobDefinition{
+		ClusterProjectID: "my-project",
+		ClusterName:      "my-cluster",
+		ClusterLocation:  "us-central1",
+		WorkloadName:     "my-job",
+		BaseImage:        "python:3.11",
+		DryRun:           true,
+	}
+	if got := manifestOutputPath(job); got != stdoutManifestPath {
+		t.Errorf("expected a plain dry run to print the manifest, got output path %q", got)
+	}
+	if got := manifestOutputPath(orchestrator.JobDefinition{DryRun: true, DryRunManifest: "out.yaml"}); got != "out.yaml" {
+		t.Errorf("expected --dry-run-out to take precedence, got output path %q", got)
+	}
+
+	orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+	printDryRunSummary(job, "us-docker.pkg.dev/my-project/gcluster/img:tag")
+	if err := orc.ApplyManifest(context.Background(), "apiVersion: jobset.x-k8s.io/v1alpha2\n", manifestOutputPath(job), job.WorkloadName); err != nil {
+		t.Fatalf("ApplyManifest failed: %v", err)
+	}
+
+	want := `# Project: my-project
+# Cluster: my-cluster (us-central1)
+# Image: us-docker.pkg.dev/my-project/gcluster/img:tag
+# Base image: python:3.11
+apiVersion: jobset.x-k8s.io/v1alpha2
+`
+	if out.String() != want {
+		t.Errorf("unexpected dry run output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if applied := orc.kubeClient.(*MockKubeClient).Applied; len(applied) != 0 {
+		t.Errorf("expected nothing to be applied, got %v", applied)
+	}
+}
+
 func TestPopulateNAPFlavors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -2422,6 +2704,7 @@ func TestGenerateGKENodeSelectorLabel(t *testing.T) {
 		{"g2-standard-48", "nvidia-l4"},
 		{"a3-highgpu-8g", "nvidia-h100-80gb"},
 		{"a2-highgpu-1g", "nvidia-tesla-a100"},
+		{"a2-ultragpu-8g", "nvidia-a100-80gb"},
 		{"g4-standard-4", "nvidia-rtx-pro-6000"},
 		{"ct6e-standard-8t", "tpu-v6e-slice"},
 
@@ -2471,7 +2754,6 @@ func TestGenerateGKEManifest_CustomEnv(t *testing.T) {
 	}
 
 	mockResponses := map[string][]shell.CommandResult{
-		"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
 		"gcloud compute machine-types describe n2-standard-2 --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
 	}
 	mockExec := NewMockExecutor(mockResponses)
@@ -2481,7 +2763,7 @@ func TestGenerateGKEManifest_CustomEnv(t *testing.T) {
 		{Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -2496,15 +2778,13 @@ func TestGenerateGKEManifest_CustomEnv(t *testing.T) {
 		t.Fatalf("GenerateGKEManifest failed: %v", err)
 	}
 
-	expectedLines := []string{
-		`- name: MY_CUSTOM_VAR`,
-		`  value: "some-value"`,
-		`- name: ANOTHER_VAR`,
-		`  value: "another=value"`,
-	}
-	for _, line := range expectedLines {
-		if !strings.Contains(manifest, line) {
-			t.Errorf("manifest does not contain expected line %q. Got manifest:\n%s", line, manifest)
+	env := decodePodSpec(t, manifest).Containers[0].Env
+	for _, want := range []corev1.EnvVar{
+		{Name: "MY_CUSTOM_VAR", Value: "some-value"},
+		{Name: "ANOTHER_VAR", Value: "another=value"},
+	} {
+		if !slices.Contains(env, want) {
+			t.Errorf("container env %+v does not contain %+v", env, want)
 		}
 	}
 }
@@ -2529,18 +2809,17 @@ func TestGeneratePathwaysManifest_CustomEnv(t *testing.T) {
 	}
 
 	mockResponses := map[string][]shell.CommandResult{
-		"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
-		"kubectl get nodes -o jsonpath={range .items[*]}{.metadata.labels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end}": {{ExitCode: 0, Stdout: "16x16"}},
-		"gcloud compute machine-types describe tpu-v5-lite-podslice --zone=us-central1-a --format=json":                         {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu-v5-lite-podslice"}]}`}},
+		"gcloud compute machine-types describe tpu-v5-lite-podslice --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "tpu-v5-lite-podslice"}]}`}},
 	}
 	mockExec := NewMockExecutor(mockResponses)
 	orc := newTestGKEOrchestrator(mockExec)
+	orc.dynClient = newFakeDynamicClient(fakeTPUNodes(orc.GenerateGKENodeSelectorLabel("tpu-v5-lite-podslice"), "16x16"))
 	orc.projectID = "mock-project"
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{Config: gkeNodePoolConfig{MachineType: "tpu-v5-lite-podslice"}},
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -2553,7 +2832,7 @@ func TestGeneratePathwaysManifest_CustomEnv(t *testing.T) {
 	// The custom environment variables must be present only in the workload-container container spec
 	expectedLines := []string{
 		`- name: PATHWAYS_UNSAFE_UNSAFE_OVERRIDE_GRPC_CREDENTIALS`,
-		`  value: "grpc_insecure_override"`,
+		`  value: grpc_insecure_override`,
 	}
 	for _, line := range expectedLines {
 		count := strings.Count(manifest, line)
@@ -2615,19 +2894,18 @@ func TestPrepareManifestOptions_PathwaysPlatform(t *testing.T) {
 			}
 
 			mockResponses := map[string][]shell.CommandResult{
-				"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
-				"kubectl get nodes -o jsonpath={range .items[*]}{.metadata.labels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end}": {{ExitCode: 0, Stdout: tc.topology}},
-				"gcloud compute machine-types describe " + tc.computeType + " --zone=us-central1-a --format=json":                       {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "` + tc.computeType + `"}]}`}},
+				"gcloud compute machine-types describe " + tc.computeType + " --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"accelerators": [{"guestAcceleratorCount": 4, "guestAcceleratorType": "` + tc.computeType + `"}]}`}},
 			}
 
 			mockExec := NewMockExecutor(mockResponses)
 			orc := newTestGKEOrchestrator(mockExec)
+			orc.dynClient = newFakeDynamicClient(fakeTPUNodes(orc.GenerateGKENodeSelectorLabel(tc.computeType), tc.topology))
 			orc.projectID = "mock-project"
 			orc.clusterDesc.NodePools = []gkeJobNodePool{
 				{Config: gkeNodePoolConfig{MachineType: tc.computeType}},
 			}
 
-			profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+			profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 			if err != nil {
 				t.Fatalf("resolveHardwareRequirements failed: %v", err)
 			}
@@ -2677,7 +2955,7 @@ func TestSharedReservationManifestGeneration(t *testing.T) {
 		GKENAPReservation:  "projects/my-owner-project/reservations/my-shared-res",
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -2739,7 +3017,7 @@ func TestSharedReservationSubblockManifestGeneration(t *testing.T) {
 		GKENAPReservation:  "projects/my-owner-project/reservations/my-shared-res/reservationBlocks/block-1/reservationSubBlocks/subblock-2",
 	}
 
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -2769,6 +3047,30 @@ func TestSharedReservationSubblockManifestGeneration(t *testing.T) {
 		}
 	}
 }
+
+// fakeJobSetPods returns n pods <jobSet>-<i> of the JobSet jobSet in namespace
+// ns, one per job of the JobSet, each with a "main" container.
+func fakeJobSetPods(ns, jobSet string, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, `---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %s-%d
+  namespace: %s
+  labels:
+    jobset.sigs.k8s.io/jobset-name: %s
+    jobset.sigs.k8s.io/job-index: "%d"
+    batch.kubernetes.io/job-completion-index: "0"
+    gcluster.google.com/workload: %s
+spec:
+  containers: [{name: main}]
+`, jobSet, i, ns, jobSet, i, jobSet)
+	}
+	return sb.String()
+}
+
 func TestGetJobLogs(t *testing.T) {
 	jobName := "test-job"
 	trueVal := true
@@ -2777,70 +3079,52 @@ func TestGetJobLogs(t *testing.T) {
 	tests := []struct {
 		desc               string
 		mainOnly           *bool
-		mockGetPods1Stdout string // response for total count check
-		mockGetPods2Stdout string // response for filtered count check
-		expectedCmdLogsKey string
+		pods               int
+		wantPods           []string
 		expectErrorContain string
 	}{
 		{
-			desc:               "explicit MainOnly=true uses coordinator-only selector (1 pod, succeeds)",
-			mainOnly:           &trueVal,
-			mockGetPods2Stdout: "pod-main-0-0\n",
-			expectedCmdLogsKey: "kubectl logs -n default -l jobset.sigs.k8s.io/jobset-name=test-job,jobset.sigs.k8s.io/job-index=0,batch.kubernetes.io/job-completion-index=0 --all-containers --max-log-requests=10",
+			desc:     "explicit MainOnly=true uses coordinator-only selector",
+			mainOnly: &trueVal,
+			pods:     8,
+			wantPods: []string{"test-job-0"},
 		},
 		{
-			desc:               "explicit MainOnly=false with pods <= 10 uses all-job selector (succeeds)",
-			mainOnly:           &falseVal,
-			mockGetPods2Stdout: "pod-1\npod-2\npod-3\npod-4\npod-5\npod-6\npod-7\npod-8\n", // 8 pods
-			expectedCmdLogsKey: "kubectl logs -n default -l jobset.sigs.k8s.io/jobset-name=test-job --all-containers --max-log-requests=10",
+			desc:     "explicit MainOnly=false with pods <= 10 uses all-job selector",
+			mainOnly: &falseVal,
+			pods:     8,
+			wantPods: []string{"test-job-0", "test-job-1", "test-job-2", "test-job-3", "test-job-4", "test-job-5", "test-job-6", "test-job-7"},
 		},
 		{
 			desc:               "explicit MainOnly=false with pods > 10 fails proactively with Console URL",
 			mainOnly:           &falseVal,
-			mockGetPods2Stdout: "pod-1\npod-2\npod-3\npod-4\npod-5\npod-6\npod-7\npod-8\npod-9\npod-10\npod-11\npod-12\n", // 12 pods
+			pods:               12,
 			expectErrorContain: "exceeds the max fetch limit (10). Please view logs directly in the Google Cloud Console",
 		},
 		{
-			desc:               "implicit MainOnly (nil) with pods <= 5 defaults to all pods (succeeds)",
-			mainOnly:           nil,
-			mockGetPods1Stdout: "pod-1\npod-2\n", // 2 pods (total)
-			mockGetPods2Stdout: "pod-1\npod-2\n", // 2 pods (filtered)
-			expectedCmdLogsKey: "kubectl logs -n default -l jobset.sigs.k8s.io/jobset-name=test-job --all-containers --max-log-requests=10",
+			desc:     "implicit MainOnly (nil) with pods <= 5 defaults to all pods",
+			pods:     2,
+			wantPods: []string{"test-job-0", "test-job-1"},
 		},
 		{
-			desc:               "implicit MainOnly (nil) with pods > 5 defaults to coordinator-only (succeeds)",
-			mainOnly:           nil,
-			mockGetPods1Stdout: "pod-1\npod-2\npod-3\npod-4\npod-5\npod-6\npod-7\npod-8\n", // 8 pods total
-			mockGetPods2Stdout: "pod-main-0-0\n",                                           // 1 pod coordinator
-			expectedCmdLogsKey: "kubectl logs -n default -l jobset.sigs.k8s.io/jobset-name=test-job,jobset.sigs.k8s.io/job-index=0,batch.kubernetes.io/job-completion-index=0 --all-containers --max-log-requests=10",
+			desc:     "implicit MainOnly (nil) with pods > 5 defaults to coordinator-only",
+			pods:     8,
+			wantPods: []string{"test-job-0"},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.desc, func(t *testing.T) {
-			totalQuery := "kubectl get pods -n default -l jobset.sigs.k8s.io/jobset-name=test-job --no-headers"
-			filteredQuery := "kubectl get pods -n default -l jobset.sigs.k8s.io/jobset-name=test-job,jobset.sigs.k8s.io/job-index=0,batch.kubernetes.io/job-completion-index=0 --no-headers"
-			if tc.mainOnly != nil && !*tc.mainOnly {
-				filteredQuery = "kubectl get pods -n default -l jobset.sigs.k8s.io/jobset-name=test-job --no-headers"
-			}
-
-			mockResponses := map[string][]shell.CommandResult{
+			mockExec := NewMockExecutor(map[string][]shell.CommandResult{
 				"gcloud container clusters get-credentials test-cluster --location us-central1-a --project test-project": {{ExitCode: 0, Stdout: ""}},
-				"gcloud container clusters describe": {{ExitCode: 0, Stdout: "description"}},
-			}
-			if totalQuery == filteredQuery {
-				mockResponses[totalQuery] = []shell.CommandResult{{ExitCode: 0, Stdout: tc.mockGetPods2Stdout}}
-			} else {
-				mockResponses[totalQuery] = []shell.CommandResult{{ExitCode: 0, Stdout: tc.mockGetPods1Stdout}, {ExitCode: 0, Stdout: tc.mockGetPods1Stdout}}
-				mockResponses[filteredQuery] = []shell.CommandResult{{ExitCode: 0, Stdout: tc.mockGetPods2Stdout}}
-			}
-			if tc.expectedCmdLogsKey != "" {
-				mockResponses[tc.expectedCmdLogsKey] = []shell.CommandResult{{ExitCode: 0, Stdout: "mock-logs-content"}}
-			}
-
-			mockExec := NewMockExecutor(mockResponses)
+			})
 			orc := newTestGKEOrchestrator(mockExec)
-			orc.kubeClient = &MockKubeClient{Namespace: "default"}
+			orc.dynClient = newFakeDynamicClient(fakeJobSetPods("default", jobName, tc.pods))
+			kube := &MockKubeClient{Namespace: "default", Logs: map[string]string{}}
+			for i := 0; i < tc.pods; i++ {
+				kube.Logs[fmt.Sprintf("test-job-%d", i)] = "mock-logs-content\n"
+			}
+			orc.kubeClient = kube
 
 			opts := orchestrator.LogsOptions{
 				ClusterName:     "test-cluster",
@@ -2850,7 +3134,7 @@ func TestGetJobLogs(t *testing.T) {
 				MainOnly:        tc.mainOnly,
 			}
 
-			logs, err := orc.GetJobLogs(jobName, opts)
+			logs, err := orc.GetJobLogs(context.Background(), jobName, opts)
 
 			if tc.expectErrorContain != "" {
 				if err == nil {
@@ -2866,12 +3150,16 @@ func TestGetJobLogs(t *testing.T) {
 				t.Fatalf("GetJobLogs failed: %v", err)
 			}
 
-			if logs != "mock-logs-content" {
-				t.Errorf("expected logs %q, got %q", "mock-logs-content", logs)
+			var requested []string
+			for _, r := range kube.LogRequests {
+				requested = append(requested, strings.Split(r, "/")[1])
 			}
-
-			if mockExec.callCount[tc.expectedCmdLogsKey] != 1 {
-				t.Errorf("expected command %q to be called exactly once, call count: %d", tc.expectedCmdLogsKey, mockExec.callCount[tc.expectedCmdLogsKey])
+			slices.Sort(requested)
+			if !slices.Equal(requested, tc.wantPods) {
+				t.Errorf("fetched the logs of %v, want %v", requested, tc.wantPods)
+			}
+			if !strings.Contains(logs, "[pod/test-job-0/main] mock-logs-content") {
+				t.Errorf("expected prefixed logs of the coordinator, got %q", logs)
 			}
 		})
 
@@ -2905,7 +3193,7 @@ func TestGeneratePathwaysManifest_Headless(t *testing.T) {
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
 	}
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -3116,7 +3404,94 @@ func TestGeneratePathwaysManifest_CommandWithQuotes(t *testing.T) {
 	orc.clusterDesc.NodePools = []gkeJobNodePool{
 		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
 	}
-	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(&job)
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
+	if err != nil {
+		t.Fatalf("resolveHardwareRequirements failed: %v", err)
+	}
+	manifest, err := orc.GeneratePathwaysManifest(job, "test-image:latest", profile, isDynamicSlicing, isStaticSlicing)
+	if err != nil {
+		t.Fatalf("generatePathwaysManifest failed: %v", err)
+	}
+
+	assertValidYAML(t, manifest)
+	if got := decodePathwaysCommand(t, manifest); got != job.CommandToRun {
+		t.Errorf("Pathways command = %q, want %q", got, job.CommandToRun)
+	}
+}
+
+func TestGeneratePathwaysManifest_MultiLineCommand(t *testing.T) {
+	setupMockMachineConfig(t)
+	job := orchestrator.JobDefinition{
+		WorkloadName:    "pathways-test",
+		CommandToRun:    "cd /app\npython train.py --steps=10\n",
+		NumSlices:       1,
+		ClusterLocation: "us-central1",
+		ComputeType:     "n2-standard-2",
+		Pathways: orchestrator.PathwaysJobDefinition{
+			ProxyServerImage: "proxy:latest",
+			ServerImage:      "server:latest",
+			WorkerImage:      "worker:latest",
+			GCSLocation:      "gs://my-bucket",
+			HeadNodePool:     "pathways-np",
+		},
+	}
+
+	mockResponses := map[string][]shell.CommandResult{
+		"gcloud compute machine-types describe n2-standard-2 --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
+	}
+	mockExec := NewMockExecutor(mockResponses)
+	orc := newTestGKEOrchestrator(mockExec)
+	orc.projectID = "mock-project"
+	orc.clusterZones = []string{"us-central1-a"}
+	orc.clusterDesc.NodePools = []gkeJobNodePool{
+		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
+	}
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
+	if err != nil {
+		t.Fatalf("resolveHardwareRequirements failed: %v", err)
+	}
+	manifest, err := orc.GeneratePathwaysManifest(job, "test-image:latest", profile, isDynamicSlicing, isStaticSlicing)
+	if err != nil {
+		t.Fatalf("generatePathwaysManifest failed: %v", err)
+	}
+
+	if got, want := decodePathwaysCommand(t, manifest), "cd /app\npython train.py --steps=10"; got != want {
+		t.Errorf("Pathways command = %q, want %q", got, want)
+	}
+	if !strings.Contains(manifest, "(\n                  eval ") {
+		t.Errorf("expected the command to run in the subshell of the head script, got:\n%s", manifest)
+	}
+}
+
+func TestGeneratePathwaysManifest_CostLabels(t *testing.T) {
+	setupMockMachineConfig(t)
+	job := orchestrator.JobDefinition{
+		WorkloadName:    "pathways-test",
+		CommandToRun:    "python train.py",
+		CostLabels:      map[string]string{"team": "ml-infra"},
+		NumSlices:       1,
+		ClusterLocation: "us-central1",
+		ComputeType:     "n2-standard-2",
+		Pathways: orchestrator.PathwaysJobDefinition{
+			ProxyServerImage: "proxy:latest",
+			ServerImage:      "server:latest",
+			WorkerImage:      "worker:latest",
+			GCSLocation:      "gs://my-bucket",
+			HeadNodePool:     "pathways-np",
+		},
+	}
+
+	mockResponses := map[string][]shell.CommandResult{
+		"gcloud compute machine-types describe n2-standard-2 --zone=us-central1-a --format=json": {{ExitCode: 0, Stdout: `{"guestCpus": 2}`}},
+	}
+	mockExec := NewMockExecutor(mockResponses)
+	orc := newTestGKEOrchestrator(mockExec)
+	orc.projectID = "mock-project"
+	orc.clusterZones = []string{"us-central1-a"}
+	orc.clusterDesc.NodePools = []gkeJobNodePool{
+		{Name: "default-pool", Config: gkeNodePoolConfig{MachineType: "n2-standard-2"}},
+	}
+	profile, isDynamicSlicing, isStaticSlicing, err := orc.resolveHardwareRequirements(context.Background(), &job)
 	if err != nil {
 		t.Fatalf("resolveHardwareRequirements failed: %v", err)
 	}
@@ -3125,8 +3500,186 @@ func TestGeneratePathwaysManifest_CommandWithQuotes(t *testing.T) {
 		t.Fatalf("generatePathwaysManifest failed: %v", err)
 	}
 
-	expectedCommand := `pip install pathwaysutils && python -c 'import pathwaysutils; pathwaysutils.initialize(); import jax; print("JAX Device count:", jax.device_count())'`
-	if !strings.Contains(manifest, expectedCommand) {
-		t.Errorf("manifest does not contain expected command exactly.\nExpected to find: %q\nManifest: %s", expectedCommand, manifest)
+	// Both the head and the worker pods carry the label.
+	if n := strings.Count(manifest, "team: ml-infra"); n != 2 {
+		t.Errorf("expected cost allocation label on head and worker pods, found %d occurrences.\nManifest: %s", n, manifest)
+	}
+}
+
+func TestFetchLogsWithRetry(t *testing.T) {
+	waiting := apierrors.NewBadRequest(`container "main" in pod "train-0" is waiting to start: ContainerCreating`)
+
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	g.dynClient = newFakeDynamicClient(fakeJobSetPods("default", "train", 1))
+	g.kubeClient = &MockKubeClient{Logs: map[string]string{"train-0": "step 1\n"}, LogErrs: []error{waiting, waiting}}
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	g.SetClock(clock)
+	logs, err := g.fetchLogsWithRetry(context.Background(), "default", "gcluster.google.com/workload=train", "")
+	if err != nil || logs != "[pod/train-0/main] step 1\n" {
+		t.Fatalf("fetchLogsWithRetry() = %q, %v", logs, err)
+	}
+	if got, want := clock.Sleeps(), []time.Duration{5 * time.Second, 5 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("waits = %v, want %v", got, want)
+	}
+
+	g.kubeClient = &MockKubeClient{LogErrs: slices.Repeat([]error{waiting}, containerStartRetry.Attempts)}
+	g.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if _, err := g.fetchLogsWithRetry(context.Background(), "default", "gcluster.google.com/workload=train", ""); err == nil || !strings.Contains(err.Error(), "timed out waiting for job to start") {
+		t.Errorf("expected a timeout once the containers never start, got %v", err)
+	}
+
+	g.kubeClient = &MockKubeClient{LogErrs: []error{apierrors.NewForbidden(podGVR.GroupResource(), "train-0", errors.New("denied"))}}
+	if _, err := g.fetchLogsWithRetry(context.Background(), "default", "gcluster.google.com/workload=train", ""); err == nil || !strings.Contains(err.Error(), "failed to get logs:") || !apierrors.IsForbidden(err) {
+		t.Errorf("expected other errors to fail at once, got %v", err)
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	origOutput := logsOutput
+	defer func() { logsOutput = origOutput }()
+
+	tests := []struct {
+		desc   string
+		follow bool
+		since  string
+	}{
+		{desc: "follow", follow: true},
+		{desc: "since without follow", since: "10m0s"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			logsOutput = &out
+			kube := &MockKubeClient{Namespace: "team-a", Logs: map[string]string{"test-job-0": "step 1\n", "test-job-1": "step 1\n"}}
+			orc := NewGKEOrchestrator()
+			orc.SetExecutor(&mockExecutor{})
+			orc.SetKubeClient(kube)
+			orc.dynClient = newFakeDynamicClient(fakeJobSetPods("team-a", "test-job", 2), fakeJobSetPods("team-a", "other-job", 1))
+
+			if err := orc.StreamLogs(context.Background(), "test-job", tc.follow, tc.since); err != nil {
+				t.Fatalf("StreamLogs failed: %v", err)
+			}
+			if len(kube.LogRequests) != 2 {
+				t.Fatalf("expected the logs of the two pods of the workload, got %v", kube.LogRequests)
+			}
+			for _, opts := range kube.LogOptions {
+				if opts.Follow != tc.follow || (tc.since != "" && (opts.SinceSeconds == nil || *opts.SinceSeconds != 600)) {
+					t.Errorf("unexpected log options %+v", opts)
+				}
+			}
+			if !strings.Contains(out.String(), "[pod/test-job-1/main] step 1") {
+				t.Errorf("expected prefixed logs, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestCancelWorkload(t *testing.T) {
+	const jobSetJSON = `{
+  "metadata": {"name": "train"},
+  "spec": {"replicatedJobs": [{"template": {"spec": {"template": {"spec": {"volumes": [
+    {"name": "vol-0", "persistentVolumeClaim": {"claimName": "gcluster-filestore-a-share"}},
+    {"name": "vol-1", "persistentVolumeClaim": {"claimName": "gcluster-filestore-b-share"}},
+    {"name": "vol-2", "persistentVolumeClaim": {"claimName": "data"}}
+  ]}}}}}]},
+  "status": {"conditions": [{"type": "Suspended", "status": "False", "lastTransitionTime": "2026-01-01T00:00:00Z"}]}
+}`
+	// The JobSet eval still mounts the second volume. The JobSet train is
+	// only answered to gets, as the mock deletes it without the cluster.
+	client := newFakeDynamicClient(
+		fakeObject("JobSet", "team-a", "eval", `{"spec": {"replicatedJobs": [{"template": {"spec": {"template": {"spec": {"volumes": [
+			{"name": "vol-0", "persistentVolumeClaim": {"claimName": "gcluster-filestore-b-share"}}
+		]}}}}}]}}`),
+		fakeObject("PersistentVolumeClaim", "team-a", "gcluster-filestore-a-share", `{"spec": {"volumeName": "gcluster-filestore-a-share-team-a"}}`),
+		fakeObject("PersistentVolumeClaim", "team-a", "gcluster-filestore-b-share", `{"spec": {"volumeName": "gcluster-filestore-b-share-team-a"}}`),
+		fakeObject("PersistentVolume", "", "gcluster-filestore-a-share-team-a", `{}`),
+		fakeObject("PersistentVolume", "", "gcluster-filestore-b-share-team-a", `{}`),
+	)
+	fakeGets(client, "JobSet", jobSetJSON)
+	deleted := func() []string {
+		var names []string
+		for _, a := range client.Actions() {
+			if d, ok := a.(k8stesting.DeleteAction); ok {
+				names = append(names, d.GetResource().Resource+"/"+d.GetName())
+			}
+		}
+		return names
+	}
+	kube := &MockKubeClient{Namespace: "team-a"}
+	orc := NewGKEOrchestrator()
+	orc.SetExecutor(NewMockExecutor(nil))
+	orc.SetKubeClient(kube)
+	orc.dynClient = client
+
+	grace := int64(5)
+	if err := orc.cancelWorkload(context.Background(), "train", "team-a", orchestrator.CancelOptions{GracePeriodSeconds: &grace, DeleteVolumes: true}); err != nil {
+		t.Fatalf("cancelWorkload() error = %v", err)
+	}
+
+	if want := []string{"team-a/jobset.sigs.k8s.io/jobset-name=train grace=5"}; !reflect.DeepEqual(kube.DeletedPods, want) {
+		t.Errorf("deleted pods = %v, want %v", kube.DeletedPods, want)
+	}
+	if want := []string{"team-a/gcluster.google.com/workload=train"}; !reflect.DeepEqual(kube.DeletedServices, want) {
+		t.Errorf("deleted services = %v, want %v", kube.DeletedServices, want)
+	}
+	wantDeleted := []string{
+		"persistentvolumeclaims/gcluster-filestore-a-share",
+		"persistentvolumes/gcluster-filestore-a-share-team-a",
+	}
+	if !reflect.DeepEqual(deleted(), wantDeleted) {
+		t.Errorf("deleted resources = %v, want %v", deleted(), wantDeleted)
+	}
+
+	kube.DeletedPods = nil
+	client.ClearActions()
+	if err := orc.cancelWorkload(context.Background(), "train", "team-a", orchestrator.CancelOptions{Force: true}); err != nil {
+		t.Fatalf("cancelWorkload() with force error = %v", err)
+	}
+	if want := []string{"team-a/jobset.sigs.k8s.io/jobset-name=train grace=0"}; !reflect.DeepEqual(kube.DeletedPods, want) {
+		t.Errorf("forced deleted pods = %v, want %v", kube.DeletedPods, want)
+	}
+	if len(deleted()) != 0 {
+		t.Errorf("volumes should be kept without DeleteVolumes, deleted %v", deleted())
+	}
+}
+
+func TestValidateJobConflicts_BatchJob(t *testing.T) {
+	dyn := &fakeDynamic{items: []unstructured.Unstructured{{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "simple", "namespace": "default"},
+		"spec":     map[string]interface{}{"suspend": false},
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Complete", "status": "True"},
+		}},
+	}}}}
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	g.SetDynamicClient(dyn)
+
+	job := orchestrator.JobDefinition{WorkloadName: "simple", WorkloadKind: orchestrator.WorkloadKindJob}
+	err := g.validateJobConflicts(context.Background(), job)
+	if err == nil || !strings.Contains(err.Error(), "batch Job with name 'simple' already exists in state 'Succeeded'") {
+		t.Fatalf("expected a conflict with the existing batch Job, got %v", err)
+	}
+	if want := []string{"list jobs metadata.name=simple"}; !reflect.DeepEqual(dyn.calls, want) {
+		t.Errorf("calls = %q, want %q", dyn.calls, want)
+	}
+
+	dyn.items, dyn.calls = nil, nil
+	if err := g.validateJobConflicts(context.Background(), job); err != nil {
+		t.Errorf("expected no conflict without an existing Job, got %v", err)
+	}
+}
+
+func TestGenerateAndSubmitManifests_BatchJobRequiresSingleNode(t *testing.T) {
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	job := orchestrator.JobDefinition{WorkloadName: "simple", WorkloadKind: orchestrator.WorkloadKindJob, NumSlices: 1, NodesPerSlice: 4}
+
+	err := g.generateAndSubmitManifests(context.Background(), job, "busybox", JobProfile{}, false, false)
+	if err == nil || !strings.Contains(err.Error(), "resolved to 1 slice(s) of 4 node(s)") {
+		t.Fatalf("expected a single-node error, got %v", err)
+	}
+
+	job.WorkloadKind = orchestrator.WorkloadKindDeployment
+	if err := g.generateAndSubmitManifests(context.Background(), job, "busybox", JobProfile{}, false, false); err == nil || !strings.Contains(err.Error(), "dry run manifest path") {
+		t.Fatalf("expected deployments to require a dry run, got %v", err)
 	}
 }
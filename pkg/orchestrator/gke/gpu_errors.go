@@ -0,0 +1,358 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// xidKind groups NVIDIA XID errors by their probable root cause.
+type xidKind int
+
+const (
+	xidUnknown xidKind = iota
+	xidApplication
+	xidSecondary
+	xidDriver
+	xidHardware
+)
+
+type xidInfo struct {
+	description string
+	kind        xidKind
+}
+
+// knownXIDs describes the XID errors seen on data center GPUs, from NVIDIA's
+// XID catalog.
+var knownXIDs = map[int]xidInfo{
+	13:  {"graphics engine exception", xidApplication},
+	31:  {"GPU memory page fault", xidApplication},
+	43:  {"GPU stopped processing", xidApplication},
+	45:  {"preemptive cleanup after an earlier error", xidSecondary},
+	48:  {"double bit ECC error", xidHardware},
+	61:  {"internal micro-controller breakpoint", xidDriver},
+	62:  {"internal micro-controller halt", xidDriver},
+	63:  {"ECC page retirement or row remapping event", xidHardware},
+	64:  {"ECC page retirement or row remapping failure", xidHardware},
+	74:  {"NVLink error", xidHardware},
+	79:  {"GPU has fallen off the bus", xidHardware},
+	92:  {"high single bit ECC error rate", xidHardware},
+	94:  {"contained ECC error", xidApplication},
+	95:  {"uncontained ECC error", xidHardware},
+	119: {"GSP RPC timeout", xidDriver},
+	120: {"GSP error", xidDriver},
+}
+
+var xidCauses = map[xidKind]string{
+	xidUnknown:     "unknown",
+	xidApplication: "an error in the GPU code of the job, such as an illegal memory access",
+	xidSecondary:   "a side effect of the job being stopped or of an earlier GPU error",
+	xidDriver:      "a fault of the GPU driver or firmware",
+	xidHardware:    "a hardware failure of the GPU or its host",
+}
+
+// xidPattern matches the kernel log line of the NVIDIA driver for an XID,
+// e.g. "NVRM: Xid (PCI:0000:00:04): 79, pid=1234, GPU has fallen off the bus."
+var xidPattern = regexp.MustCompile(`NVRM: Xid \(PCI:([^)]+)\): (\d+)`)
+
+// dcgmExporterSelectors select the DCGM exporter pods of the GKE managed
+// DCGM metrics, the NVIDIA GPU operator and the upstream Helm chart.
+var dcgmExporterSelectors = []string{
+	"app.kubernetes.io/name in (gke-managed-dcgm-exporter,dcgm-exporter)",
+	"app=nvidia-dcgm-exporter",
+}
+
+const (
+	dcgmExporterPort = 9400
+	dcgmXIDMetric    = "DCGM_FI_DEV_XID_ERRORS"
+)
+
+var metricLabelPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// workloadGPUErrors returns the XID errors of the GPUs on the nodes that run
+// pods, the pods of a workload in namespace ns. They are read from the node
+// events node-problem-detector raises from the kernel log and from the DCGM
+// exporter, where either is installed. Both sources are optional, so they are
+// skipped when they cannot be read.
+func (g *GKEOrchestrator) workloadGPUErrors(ctx context.Context, ns string, pods []orchestrator.PodStatus) []orchestrator.GPUError {
+	nodePods := map[string][]string{}
+	for _, p := range pods {
+		if p.Node != "" {
+			nodePods[p.Node] = append(nodePods[p.Node], p.Name)
+		}
+	}
+	if len(nodePods) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var errs []orchestrator.GPUError
+	for _, e := range append(g.nodeEventXIDs(ctx, nodePods), g.dcgmXIDs(ctx, ns, nodePods)...) {
+		key := fmt.Sprintf("%s\x00%s\x00%d", e.Node, e.GPU, e.XID)
+		if !seen[key] {
+			seen[key] = true
+			errs = append(errs, e)
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Node != errs[j].Node {
+			return errs[i].Node < errs[j].Node
+		}
+		return errs[i].GPU < errs[j].GPU
+	})
+	return errs
+}
+
+// nodeEventXIDs parses the XIDs out of the kernel log lines that
+// node-problem-detector reports as events of the nodes.
+func (g *GKEOrchestrator) nodeEventXIDs(ctx context.Context, nodePods map[string][]string) []orchestrator.GPUError {
+	var list eventList
+	if err := g.listResources(ctx, &list, eventGVR, "", metav1.ListOptions{FieldSelector: "involvedObject.kind=Node"}); err != nil {
+		return nil
+	}
+	var errs []orchestrator.GPUError
+	for _, e := range list.Items {
+		node := e.InvolvedObject.Name
+		if _, ok := nodePods[node]; !ok {
+			continue
+		}
+		for _, m := range xidPattern.FindAllStringSubmatch(e.Message, -1) {
+			xid, _ := strconv.Atoi(m[2])
+			errs = append(errs, newGPUError(node, m[1], onlyPod(nodePods[node]), xid, orchestrator.GPUErrorSourceDmesg))
+		}
+	}
+	return errs
+}
+
+// dcgmXIDs reads the last XID of each GPU from the metrics of the DCGM
+// exporters on the nodes. GPUs the exporter attributes to pods of other
+// workloads are left out.
+func (g *GKEOrchestrator) dcgmXIDs(ctx context.Context, ns string, nodePods map[string][]string) []orchestrator.GPUError {
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return nil
+	}
+	workloadPods := map[string]bool{}
+	for _, pods := range nodePods {
+		for _, p := range pods {
+			workloadPods[p] = true
+		}
+	}
+
+	var errs []orchestrator.GPUError
+	for _, selector := range dcgmExporterSelectors {
+		var exporters podList
+		if err := g.listResources(ctx, &exporters, podGVR, "", metav1.ListOptions{LabelSelector: selector}); err != nil {
+			continue
+		}
+		for _, exp := range exporters.Items {
+			node := exp.Spec.NodeName
+			if _, ok := nodePods[node]; !ok {
+				continue
+			}
+			path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s:%d/proxy/metrics", exp.Metadata.Namespace, exp.Metadata.Name, dcgmExporterPort)
+			metrics, err := kube.RawGet(ctx, path)
+			if err != nil {
+				continue
+			}
+			for _, m := range parseDCGMXIDs(string(metrics)) {
+				pod := m.labels["pod"]
+				switch {
+				case pod == "":
+					pod = onlyPod(nodePods[node])
+				case m.labels["namespace"] != ns || !workloadPods[pod]:
+					continue
+				}
+				errs = append(errs, newGPUError(node, m.labels["gpu"], pod, m.xid, orchestrator.GPUErrorSourceDCGM))
+			}
+		}
+	}
+	return errs
+}
+
+type dcgmXID struct {
+	labels map[string]string
+	xid    int
+}
+
+// parseDCGMXIDs returns the GPUs whose last XID, as reported by the
+// DCGM_FI_DEV_XID_ERRORS gauge of a DCGM exporter, is set.
+func parseDCGMXIDs(metrics string) []dcgmXID {
+	var out []dcgmXID
+	for _, line := range strings.Split(metrics, "\n") {
+		if !strings.HasPrefix(line, dcgmXIDMetric+"{") {
+			continue
+		}
+		end := strings.LastIndex(line, "}")
+		if end < 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.Fields(line[end+1:] + " 0")[0], 64)
+		if err != nil || value == 0 {
+			continue
+		}
+		labels := map[string]string{}
+		for _, m := range metricLabelPattern.FindAllStringSubmatch(line[:end], -1) {
+			labels[m[1]] = m[2]
+		}
+		out = append(out, dcgmXID{labels: labels, xid: int(value)})
+	}
+	return out
+}
+
+func newGPUError(node, gpu, pod string, xid int, source string) orchestrator.GPUError {
+	info, ok := knownXIDs[xid]
+	if !ok {
+		info = xidInfo{description: "unknown error", kind: xidUnknown}
+	}
+	return orchestrator.GPUError{
+		Node: node, GPU: gpu, Pod: pod, XID: xid, Source: source,
+		Description: info.description, Cause: xidCauses[info.kind],
+	}
+}
+
+// onlyPod returns the pod of a node that runs a single workload pod, which
+// must be the one using its GPUs.
+func onlyPod(pods []string) string {
+	if len(pods) == 1 {
+		return pods[0]
+	}
+	return ""
+}
+
+func xidKindOf(xid int) xidKind {
+	return knownXIDs[xid].kind
+}
+
+// xidAdvice tells what to do about an XID of a given kind on node.
+func xidAdvice(kind xidKind, node string) string {
+	switch kind {
+	case xidHardware:
+		return fmt.Sprintf("Cordon the node with 'kubectl cordon %s' and resubmit the job so that it runs on healthy GPUs.", node)
+	case xidDriver:
+		return fmt.Sprintf("Reset the GPU by recreating node %s, then resubmit the job.", node)
+	case xidApplication:
+		return "Check the job for out-of-bounds memory accesses, e.g. with compute-sanitizer."
+	case xidSecondary:
+		return "Look for the first failure in the other GPU errors and the logs of the job."
+	}
+	return "Look the XID up in NVIDIA's XID catalog."
+}
+
+// gpuErrorCauses explains the GPU errors of a workload, one line per XID and
+// node, hardware failures first.
+func gpuErrorCauses(errs []orchestrator.GPUError) []string {
+	type group struct {
+		err  orchestrator.GPUError
+		gpus []string
+		pods []string
+	}
+	byKey := map[string]*group{}
+	var groups []*group
+	for _, e := range errs {
+		key := fmt.Sprintf("%s\x00%d", e.Node, e.XID)
+		gr, ok := byKey[key]
+		if !ok {
+			gr = &group{err: e}
+			byKey[key] = gr
+			groups = append(groups, gr)
+		}
+		if e.GPU != "" {
+			gr.gpus = append(gr.gpus, e.GPU)
+		}
+		if e.Pod != "" && !slices.Contains(gr.pods, e.Pod) {
+			gr.pods = append(gr.pods, e.Pod)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return xidKindOf(groups[i].err.XID) > xidKindOf(groups[j].err.XID) })
+
+	var out []string
+	for _, gr := range groups {
+		e := gr.err
+		line := fmt.Sprintf("GPU XID %d (%s) on node %s", e.XID, e.Description, e.Node)
+		switch len(gr.gpus) {
+		case 0:
+		case 1:
+			line += ", GPU " + gr.gpus[0]
+		default:
+			line += ", GPUs " + strings.Join(gr.gpus, ", ")
+		}
+		if len(gr.pods) > 0 {
+			line += ", used by " + podNames(gr.pods)
+		}
+		if e.Cause != xidCauses[xidUnknown] {
+			line += ": probably " + e.Cause
+		}
+		out = append(out, line+". "+xidAdvice(xidKindOf(e.XID), e.Node))
+	}
+	return out
+}
+
+// oomDiagnosis explains the OOMKilled containers of pods. limit is their
+// common memory limit, if known.
+func oomDiagnosis(pods []string, limit string) string {
+	at := ""
+	if limit != "" {
+		at = " at their memory limit of " + limit
+	}
+	return fmt.Sprintf("Containers ran out of memory and were OOMKilled%s in %s. Lower the memory use of the job or request more memory per pod.", at, podNames(pods))
+}
+
+// oomMemoryLimit returns the memory limit shared by the OOMKilled containers
+// of pods, or "" when they have none or different ones.
+func oomMemoryLimit(pods []orchestrator.PodStatus) string {
+	limit := ""
+	for _, p := range pods {
+		for _, t := range p.Terminations {
+			if t.Reason != "OOMKilled" {
+				continue
+			}
+			if t.MemoryHighWater == "" || (limit != "" && limit != t.MemoryHighWater) {
+				return ""
+			}
+			limit = t.MemoryHighWater
+		}
+	}
+	return limit
+}
+
+// probableCauses summarizes the likely root causes of the OOMKilled
+// containers and GPU errors of a workload, most severe first.
+func probableCauses(detail orchestrator.JobStatusDetail) []string {
+	causes := gpuErrorCauses(detail.GPUErrors)
+	var oomKilled []string
+	for _, p := range detail.Pods {
+		for _, t := range p.Terminations {
+			if t.Reason == "OOMKilled" {
+				oomKilled = append(oomKilled, p.Name)
+				break
+			}
+		}
+	}
+	if len(oomKilled) > 0 {
+		causes = append(causes, oomDiagnosis(oomKilled, oomMemoryLimit(detail.Pods)))
+	}
+	return causes
+}
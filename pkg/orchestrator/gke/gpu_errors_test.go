@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const nodeEventsJSON = `{"items": [
+  {"involvedObject": {"kind": "Node", "name": "node-a"}, "type": "Warning", "reason": "KernelOops",
+   "message": "kernel: NVRM: Xid (PCI:0000:00:04): 79, pid=1234, GPU has fallen off the bus."},
+  {"involvedObject": {"kind": "Node", "name": "node-z"}, "type": "Warning", "reason": "KernelOops",
+   "message": "kernel: NVRM: Xid (PCI:0000:00:05): 48, pid=99, DBE (double bit error) ECC error."},
+  {"involvedObject": {"kind": "Node", "name": "node-b"}, "type": "Normal", "reason": "NodeReady", "message": "Node node-b status is now: NodeReady"}
+]}`
+
+const dcgmExportersJSON = `{"items": [
+  {"metadata": {"name": "dcgm-exporter-b", "namespace": "gke-managed-system"}, "spec": {"nodeName": "node-b"}},
+  {"metadata": {"name": "dcgm-exporter-z", "namespace": "gke-managed-system"}, "spec": {"nodeName": "node-z"}}
+]}`
+
+const dcgmMetrics = `# HELP DCGM_FI_DEV_XID_ERRORS Value of the last XID error encountered.
+# TYPE DCGM_FI_DEV_XID_ERRORS gauge
+DCGM_FI_DEV_XID_ERRORS{gpu="0",UUID="GPU-1",Hostname="node-b",namespace="team-a",pod="train-main-0-1-b"} 13
+DCGM_FI_DEV_XID_ERRORS{gpu="1",UUID="GPU-2",Hostname="node-b",namespace="team-a",pod="other-job-0"} 79
+DCGM_FI_DEV_XID_ERRORS{gpu="2",UUID="GPU-3",Hostname="node-b"} 0
+DCGM_FI_DEV_GPU_TEMP{gpu="0",UUID="GPU-1",Hostname="node-b"} 61
+`
+
+func TestWorkloadGPUErrors(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{})
+	g.dynClient = newFakeDynamicClient(
+		fakeList("Event", "default", nil, nodeEventsJSON),
+		fakeList("Pod", "", map[string]string{"app.kubernetes.io/name": "gke-managed-dcgm-exporter"}, dcgmExportersJSON),
+	)
+	g.kubeClient = &MockKubeClient{Raw: map[string]string{
+		"/api/v1/namespaces/gke-managed-system/pods/dcgm-exporter-b:9400/proxy/metrics": dcgmMetrics,
+	}}
+
+	pods := []orchestrator.PodStatus{
+		{Name: "train-main-0-0-a", Node: "node-a"},
+		{Name: "train-main-0-1-b", Node: "node-b"},
+		{Name: "train-main-0-2-c"},
+	}
+	got := g.workloadGPUErrors(context.Background(), "team-a", pods)
+	want := []orchestrator.GPUError{
+		{Node: "node-a", GPU: "0000:00:04", Pod: "train-main-0-0-a", XID: 79, Source: orchestrator.GPUErrorSourceDmesg,
+			Description: "GPU has fallen off the bus", Cause: "a hardware failure of the GPU or its host"},
+		{Node: "node-b", GPU: "0", Pod: "train-main-0-1-b", XID: 13, Source: orchestrator.GPUErrorSourceDCGM,
+			Description: "graphics engine exception", Cause: "an error in the GPU code of the job, such as an illegal memory access"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("workloadGPUErrors() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestWorkloadGPUErrors_NoScheduledPods(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{})
+	g.dynClient = newFakeDynamicClient(fakeList("Event", "default", nil, nodeEventsJSON))
+	if got := g.workloadGPUErrors(context.Background(), "default", []orchestrator.PodStatus{{Name: "train-0", Phase: "Pending"}}); got != nil {
+		t.Errorf("expected no GPU errors for unscheduled pods, got %+v", got)
+	}
+}
+
+func TestParseDCGMXIDs(t *testing.T) {
+	got := parseDCGMXIDs(dcgmMetrics)
+	if len(got) != 2 || got[0].xid != 13 || got[0].labels["pod"] != "train-main-0-1-b" || got[1].xid != 79 || got[1].labels["gpu"] != "1" {
+		t.Errorf("parseDCGMXIDs() = %+v", got)
+	}
+}
+
+func TestProbableCauses(t *testing.T) {
+	detail := orchestrator.JobStatusDetail{
+		Pods: []orchestrator.PodStatus{
+			{Name: "train-0", Terminations: []orchestrator.ContainerTermination{{Container: "workload", Reason: "OOMKilled", ExitCode: 137, MemoryHighWater: "16Gi"}}},
+			{Name: "train-1", Terminations: []orchestrator.ContainerTermination{{Container: "workload", Reason: "Error", ExitCode: 1}}},
+		},
+		GPUErrors: []orchestrator.GPUError{
+			newGPUError("node-a", "0", "train-1", 13, orchestrator.GPUErrorSourceDCGM),
+			newGPUError("node-b", "0", "train-0", 79, orchestrator.GPUErrorSourceDmesg),
+			newGPUError("node-b", "1", "train-0", 79, orchestrator.GPUErrorSourceDmesg),
+			newGPUError("node-c", "0", "", 999, orchestrator.GPUErrorSourceDCGM),
+		},
+	}
+	want := []string{
+		"GPU XID 79 (GPU has fallen off the bus) on node node-b, GPUs 0, 1, used by 1 pod (train-0): probably a hardware failure of the GPU or its host. Cordon the node with 'kubectl cordon node-b' and resubmit the job so that it runs on healthy GPUs.",
+		"GPU XID 13 (graphics engine exception) on node node-a, GPU 0, used by 1 pod (train-1): probably an error in the GPU code of the job, such as an illegal memory access. Check the job for out-of-bounds memory accesses, e.g. with compute-sanitizer.",
+		"GPU XID 999 (unknown error) on node node-c, GPU 0. Look the XID up in NVIDIA's XID catalog.",
+		"Containers ran out of memory and were OOMKilled at their memory limit of 16Gi in 1 pod (train-0). Lower the memory use of the job or request more memory per pod.",
+	}
+	if got := probableCauses(detail); !reflect.DeepEqual(got, want) {
+		t.Errorf("probableCauses() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestOOMMemoryLimit_Differs(t *testing.T) {
+	pods := []orchestrator.PodStatus{
+		{Name: "a", Terminations: []orchestrator.ContainerTermination{{Reason: "OOMKilled", MemoryHighWater: "16Gi"}}},
+		{Name: "b", Terminations: []orchestrator.ContainerTermination{{Reason: "OOMKilled", MemoryHighWater: "32Gi"}}},
+	}
+	if got := oomMemoryLimit(pods); got != "" {
+		t.Errorf("oomMemoryLimit() = %q, want no common limit", got)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// selectComputeTypeByGPUMemory sets the compute type of a job submitted with
+// a minimum GPU memory to the machine type of the cluster's GPU node pools
+// whose GPUs have enough memory and are the cheapest per hour. When several
+// node pools carry the cheapest GPU, the first one's machine type is used.
+func (g *GKEOrchestrator) selectComputeTypeByGPUMemory(job *orchestrator.JobDefinition) error {
+	if job.MinGPUMemoryGB == 0 {
+		return nil
+	}
+
+	var best, bestLabel string
+	var bestSpec config.GPUSpec
+	var seen []string
+	for _, np := range g.clusterDesc.NodePools {
+		machineType := np.Config.MachineType
+		if machineType == "" || g.isSystemPool(np) {
+			continue
+		}
+		label := g.nodePoolGPULabel(np)
+		spec, ok := config.GPUCatalog[label]
+		if !ok {
+			continue
+		}
+		seen = append(seen, fmt.Sprintf("%s (%s, %d GB)", machineType, label, spec.MemoryGB))
+		if spec.MemoryGB < job.MinGPUMemoryGB {
+			continue
+		}
+		if best == "" || spec.HourlyPrice < bestSpec.HourlyPrice {
+			best, bestLabel, bestSpec = machineType, label, spec
+		}
+	}
+
+	if best == "" {
+		have := "has no GPU node pools"
+		if len(seen) > 0 {
+			have = "has GPU node pools of " + strings.Join(seen, ", ")
+		}
+		fits := "no known GPU has that much memory"
+		if gpus := config.GPUsWithMemory(job.MinGPUMemoryGB); len(gpus) > 0 {
+			fits = "GPUs with enough memory are " + strings.Join(gpus, ", ")
+		}
+		return fmt.Errorf("--gpu-memory %dGB: cluster %s %s; %s. Add a node pool of one of them, or set --compute-type", job.MinGPUMemoryGB, job.ClusterName, have, fits)
+	}
+	logging.Info("Selected compute type %s (%s, %d GB per GPU), the cheapest GPU in cluster %s with at least %d GB of memory.", best, bestLabel, bestSpec.MemoryGB, job.ClusterName, job.MinGPUMemoryGB)
+	job.ComputeType = best
+	return nil
+}
+
+// nodePoolGPULabel returns the GKE accelerator label of the GPUs of a node
+// pool: the type of the accelerators attached to it, or the label of its
+// machine family.
+func (g *GKEOrchestrator) nodePoolGPULabel(np gkeJobNodePool) string {
+	if len(np.Config.Accelerators) > 0 && np.Config.Accelerators[0].AcceleratorType != "" {
+		return np.Config.Accelerators[0].AcceleratorType
+	}
+	return g.GenerateGKENodeSelectorLabel(np.Config.MachineType)
+}
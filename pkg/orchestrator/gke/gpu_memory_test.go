@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestSelectComputeTypeByGPUMemory(t *testing.T) {
+	l4 := gkeJobNodePool{Config: gkeNodePoolConfig{MachineType: "g2-standard-12"}}
+	a100 := gkeJobNodePool{Config: gkeNodePoolConfig{MachineType: "a2-ultragpu-1g"}}
+	h100 := gkeJobNodePool{Config: gkeNodePoolConfig{MachineType: "a3-highgpu-8g"}}
+	h200 := gkeJobNodePool{Config: gkeNodePoolConfig{MachineType: "a3-ultragpu-8g"}}
+	t4 := gkeJobNodePool{Config: gkeNodePoolConfig{MachineType: "n1-standard-8", Accelerators: []gkeAccelerator{{AcceleratorType: "nvidia-tesla-t4", AcceleratorCount: "1"}}}}
+	tests := []struct {
+		name      string
+		minMemory int
+		job       orchestrator.JobDefinition
+		nodePools []gkeJobNodePool
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "unset keeps the compute type",
+			job:       orchestrator.JobDefinition{ComputeType: "n2-standard-8"},
+			nodePools: []gkeJobNodePool{l4},
+			want:      "n2-standard-8",
+		},
+		{
+			name:      "cheapest with enough memory",
+			minMemory: 80,
+			nodePools: []gkeJobNodePool{l4, h200, h100, a100},
+			want:      "a2-ultragpu-1g",
+		},
+		{
+			name:      "attached accelerators",
+			minMemory: 16,
+			nodePools: []gkeJobNodePool{l4, t4},
+			want:      "n1-standard-8",
+		},
+		{
+			name:      "only larger GPUs",
+			minMemory: 100,
+			nodePools: []gkeJobNodePool{a100, h200},
+			want:      "a3-ultragpu-8g",
+		},
+		{
+			name:      "not enough memory",
+			minMemory: 80,
+			job:       orchestrator.JobDefinition{ClusterName: "c"},
+			nodePools: []gkeJobNodePool{l4, {Config: gkeNodePoolConfig{MachineType: "n2-standard-8"}}},
+			wantErr:   "cluster c has GPU node pools of g2-standard-12 (nvidia-l4, 24 GB); GPUs with enough memory are nvidia-a100-80gb,",
+		},
+		{
+			name:      "no GPU pools",
+			minMemory: 40,
+			job:       orchestrator.JobDefinition{ClusterName: "c"},
+			nodePools: []gkeJobNodePool{{Config: gkeNodePoolConfig{MachineType: "n2-standard-8"}}},
+			wantErr:   "cluster c has no GPU node pools",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newTestGKEOrchestrator(NewMockExecutor(nil))
+			g.clusterDesc.NodePools = tc.nodePools
+			job := tc.job
+			job.MinGPUMemoryGB = tc.minMemory
+			err := g.selectComputeTypeByGPUMemory(&job)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.ComputeType != tc.want {
+				t.Errorf("ComputeType = %q, want %q", job.ComputeType, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// verifyWorkloadImage checks, before the workload is applied, that its image
+// exists in the registry and that the cluster's nodes can pull it, so that a
+// missing image fails the submission instead of leaving the pods in
+// ImagePullBackOff. The image is looked up with the credentials of the user;
+// registry errors other than the image not existing only warn, since the
+// nodes may still be able to pull it.
+func (g *GKEOrchestrator) verifyWorkloadImage(job orchestrator.JobDefinition, image string) error {
+	if image == "" {
+		return nil
+	}
+	if _, err := resolveImageDigest(image); err != nil {
+		if imageNotFound(err) {
+			return fmt.Errorf("image %s does not exist in its registry; check that it was pushed and that the name and tag are correct: %w", image, err)
+		}
+		logging.Warn("Could not verify that image %s exists: %v", image, err)
+	}
+	g.checkRegistryAccess(job)
+	return nil
+}
+
+// imageNotFound reports whether err is the answer of a registry that the
+// image, or its repository, does not exist.
+func imageNotFound(err error) bool {
+	var terr *transport.Error
+	if !errors.As(err, &terr) {
+		return false
+	}
+	if terr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	return slices.ContainsFunc(terr.Errors, func(d transport.Diagnostic) bool {
+		return d.Code == transport.ManifestUnknownErrorCode || d.Code == transport.NameUnknownErrorCode
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+func TestVerifyWorkloadImage(t *testing.T) {
+	origResolve := resolveImageDigest
+	defer func() { resolveImageDigest = origResolve }()
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr string
+	}{
+		{name: "exists"},
+		{name: "unauthorized", err: &transport.Error{StatusCode: http.StatusUnauthorized}},
+		{name: "network error", err: errors.New("dial tcp: no such host")},
+		{name: "not found", err: fmt.Errorf("failed to resolve: %w", &transport.Error{StatusCode: http.StatusNotFound}), wantErr: "does not exist"},
+		{name: "manifest unknown", err: &transport.Error{StatusCode: http.StatusBadRequest, Errors: []transport.Diagnostic{{Code: transport.ManifestUnknownErrorCode}}}, wantErr: "does not exist"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolveImageDigest = func(image string) (string, error) { return "sha256:feed", tc.err }
+			g := newTestGKEOrchestrator(NewMockExecutor(nil))
+			job := orchestrator.JobDefinition{ClusterProjectID: "p", ImageName: "us-docker.pkg.dev/p/r/img:v1"}
+			err := g.verifyWorkloadImage(job, job.ImageName)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyWorkloadImage() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
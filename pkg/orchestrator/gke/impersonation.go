@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/shell"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/impersonate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// readOnlyGcloudCommands are the gcloud commands, given as their command
+// groups and verb, that are run in read-only mode. Any other gcloud command
+// is refused. get-credentials is not among them since it writes the
+// kubeconfig; see connectAsPrincipal.
+var readOnlyGcloudCommands = [][]string{
+	{"artifacts", "repositories", "get-iam-policy"},
+	{"auth", "print-access-token"},
+	{"compute", "machine-types", "describe"},
+	{"compute", "zones", "list"},
+	{"config", "get-value"},
+	{"config", "list"},
+	{"container", "clusters", "describe"},
+	{"container", "clusters", "list"},
+	{"container", "node-pools", "list"},
+	{"logging", "read"},
+	{"logging", "sinks", "describe"},
+	{"projects", "describe"},
+	{"projects", "get-iam-policy"},
+	{"secrets", "get-iam-policy"},
+	{"storage", "ls"},
+	{"version"},
+}
+
+// Impersonate makes the orchestrator act as principal in read-only mode, to
+// check what the principal is allowed to do. Kubernetes requests are made as
+// the principal, and their writes are validated by the API server with a
+// server-side dry run but not persisted. gcloud commands impersonate the
+// principal when it is a service account, and commands that would change a
+// Google Cloud resource are refused.
+func (g *GKEOrchestrator) Impersonate(principal string) {
+	g.impersonate = principal
+	g.executor = &impersonatingExecutor{Executor: g.executor, principal: principal}
+	if g.kubeClientFromConfig {
+		g.dynClient, g.kubeClient, g.kubeClientFromConfig = nil, nil, false
+	}
+}
+
+// IsServiceAccount reports whether principal is a service account email,
+// which is the only kind of principal Google Cloud credentials can
+// impersonate.
+func IsServiceAccount(principal string) bool {
+	return strings.HasSuffix(principal, ".gserviceaccount.com")
+}
+
+// impersonatingExecutor runs gcloud commands as principal in read-only mode.
+type impersonatingExecutor struct {
+	Executor
+	principal string
+}
+
+func (e *impersonatingExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	args, err := e.impersonationArgs(name, args)
+	if err != nil {
+		return shell.CommandResult{ExitCode: 1, Stderr: err.Error()}
+	}
+	return e.Executor.ExecuteCommand(name, args...)
+}
+
+func (e *impersonatingExecutor) ExecuteCommandStream(name string, args ...string) error {
+	args, err := e.impersonationArgs(name, args)
+	if err != nil {
+		return err
+	}
+	return e.Executor.ExecuteCommandStream(name, args...)
+}
+
+// impersonationArgs returns args with the flags that run command name as the
+// principal, or an error if the command would make a change.
+func (e *impersonatingExecutor) impersonationArgs(name string, args []string) ([]string, error) {
+	command := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	switch name {
+	case "gcloud":
+		if !isReadOnlyGcloudCommand(args) {
+			return nil, fmt.Errorf("refusing to run '%s' while impersonating %s: it could change Google Cloud resources", command, e.principal)
+		}
+		if IsServiceAccount(e.principal) {
+			return append(args, "--impersonate-service-account="+e.principal), nil
+		}
+		return args, nil
+	}
+	return nil, fmt.Errorf("refusing to run '%s' while impersonating %s", command, e.principal)
+}
+
+// isReadOnlyGcloudCommand reports whether the gcloud args start with one of
+// readOnlyGcloudCommands. Only the positional arguments are matched, so that
+// a flag value or a resource named like a read-only verb does not count.
+func isReadOnlyGcloudCommand(args []string) bool {
+	var positional []string
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") {
+			break
+		}
+		positional = append(positional, a)
+	}
+	return slices.ContainsFunc(readOnlyGcloudCommands, func(command []string) bool {
+		return len(positional) >= len(command) && slices.Equal(positional[:len(command)], command)
+	})
+}
+
+// connectAsPrincipal builds the Kubernetes clients of an impersonated run from
+// the endpoint of the cluster, since get-credentials would write the
+// kubeconfig. A service account principal is reached with its own access
+// tokens, minted by impersonating it. Google Cloud cannot impersonate other
+// principals, so for them the caller's access token is fetched and the API
+// server impersonates the principal.
+func (g *GKEOrchestrator) connectAsPrincipal(ctx context.Context, clusterName, clusterLocation, projectID string) error {
+	exec := executorWithContext(g.executor, ctx)
+	res := exec.ExecuteCommand("gcloud", "container", "clusters", "describe", clusterName, "--location", clusterLocation, "--project", projectID, "--format=json")
+	if res.ExitCode != 0 {
+		return fmt.Errorf("failed to describe GKE cluster %s: %s", clusterName, res.Stderr)
+	}
+	var cluster gkeFleetCluster
+	if err := json.Unmarshal([]byte(res.Stdout), &cluster); err != nil {
+		return fmt.Errorf("failed to parse GKE cluster description: %w", err)
+	}
+
+	var config *rest.Config
+	if IsServiceAccount(g.impersonate) {
+		// The clients outlive this step, so the tokens are refreshed
+		// without its deadline.
+		ts, err := impersonatedTokenSource(context.WithoutCancel(ctx), g.impersonate)
+		if err != nil {
+			return fmt.Errorf("failed to impersonate %s: %w", g.impersonate, err)
+		}
+		if config, err = clusterRESTConfig(cluster, ""); err != nil {
+			return err
+		}
+		config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			return &oauth2.Transport{Source: ts, Base: rt}
+		}
+	} else {
+		res = exec.ExecuteCommand("gcloud", "auth", "print-access-token")
+		token := strings.TrimSpace(res.Stdout)
+		if res.ExitCode != 0 || token == "" {
+			return fmt.Errorf("failed to get an access token: %s", res.Stderr)
+		}
+		var err error
+		if config, err = clusterRESTConfig(cluster, token); err != nil {
+			return err
+		}
+		config.Impersonate = rest.ImpersonationConfig{UserName: g.impersonate}
+	}
+	client, err := newImpersonatedKubeClient(config)
+	if err != nil {
+		return err
+	}
+	client.dryRun = true
+	g.dynClient, g.kubeClient, g.kubeClientFromConfig = client.dynClient, client, true
+	return nil
+}
+
+// newImpersonatedKubeClient is replaced in tests to avoid reaching the cluster.
+var newImpersonatedKubeClient = newDefaultKubeClient
+
+// impersonatedTokenSource returns the access tokens of the service account
+// serviceAccount, minted with the caller's credentials. It is replaced in
+// tests to avoid reaching the IAM Credentials API.
+var impersonatedTokenSource = func(ctx context.Context, serviceAccount string) (oauth2.TokenSource, error) {
+	return impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: serviceAccount,
+		Scopes:          []string{"https://www.googleapis.com/auth/cloud-platform"},
+	})
+}
+
+// dryRunOption returns the DryRun option of the writes of the client.
+func (d *DefaultKubeClient) dryRunOption() []string {
+	if d.dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
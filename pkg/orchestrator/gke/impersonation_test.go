@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	"golang.org/x/oauth2"
+	"k8s.io/client-go/rest"
+)
+
+func TestImpersonatingExecutor(t *testing.T) {
+	var ran []string
+	inner := &mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		ran = append(ran, name+" "+strings.Join(args, " "))
+		return shell.CommandResult{}
+	}}
+
+	tests := []struct {
+		principal string
+		command   []string
+		want      string // The command run, or "" if it is refused.
+	}{
+		{"alice@example.com", []string{"gcloud", "container", "clusters", "describe", "c"}, "gcloud container clusters describe c"},
+		{"ci@p.iam.gserviceaccount.com", []string{"gcloud", "container", "clusters", "describe", "c"}, "gcloud container clusters describe c --impersonate-service-account=ci@p.iam.gserviceaccount.com"},
+		{"ci@p.iam.gserviceaccount.com", []string{"gcloud", "logging", "sinks", "create", "s"}, ""},
+		{"alice@example.com", []string{"gcloud", "builds", "submit", "--tag", "list"}, ""},
+		{"alice@example.com", []string{"gcloud", "compute", "instances", "delete", "describe"}, ""},
+		{"alice@example.com", []string{"gcloud", "container", "clusters", "get-credentials", "c"}, ""},
+		{"alice@example.com", []string{"kubectl", "get", "jobsets", "-A"}, ""},
+		{"alice@example.com", []string{"docker", "push", "image"}, ""},
+	}
+	for _, tc := range tests {
+		ran = nil
+		e := &impersonatingExecutor{Executor: inner, principal: tc.principal}
+		res := e.ExecuteCommand(tc.command[0], tc.command[1:]...)
+		if tc.want == "" {
+			if res.ExitCode == 0 || len(ran) != 0 {
+				t.Errorf("%v as %s: expected the command to be refused, ran %v", tc.command, tc.principal, ran)
+			}
+			continue
+		}
+		if res.ExitCode != 0 || !reflect.DeepEqual(ran, []string{tc.want}) {
+			t.Errorf("%v as %s: ran %v (%s), want %q", tc.command, tc.principal, ran, res.Stderr, tc.want)
+		}
+	}
+}
+
+func TestImpersonate_RefusesImageBuilds(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		t.Fatalf("unexpected command: %s %v", name, args)
+		return shell.CommandResult{}
+	}})
+	g.Impersonate("alice@example.com")
+	if _, ok := g.executor.(*impersonatingExecutor); !ok {
+		t.Errorf("expected an impersonating executor, got %T", g.executor)
+	}
+	_, err := g.SubmitJob(context.Background(), orchestrator.JobDefinition{WorkloadName: "w", BaseImage: "python:3.11", BuildContext: "."})
+	if err == nil || !strings.Contains(err.Error(), "cannot be checked while impersonating alice@example.com") {
+		t.Errorf("expected the image build to be refused, got %v", err)
+	}
+}
+
+func TestImpersonate_ConnectsWithoutKubeconfig(t *testing.T) {
+	var ran []string
+	g := newTestGKEOrchestrator(&mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		ran = append(ran, cmd)
+		switch {
+		case strings.HasPrefix(cmd, "gcloud container clusters describe c"):
+			return shell.CommandResult{Stdout: `{"endpoint": "10.0.0.1", "masterAuth": {"clusterCaCertificate": "Y2E="}}`}
+		case cmd == "gcloud auth print-access-token":
+			return shell.CommandResult{Stdout: "token\n"}
+		}
+		return shell.CommandResult{ExitCode: 1, Stderr: "unexpected command"}
+	}})
+	var config *rest.Config
+	defer func(orig func(*rest.Config) (*DefaultKubeClient, error)) { newImpersonatedKubeClient = orig }(newImpersonatedKubeClient)
+	newImpersonatedKubeClient = func(c *rest.Config) (*DefaultKubeClient, error) {
+		config = c
+		return &DefaultKubeClient{dynClient: newFakeDynamicClient()}, nil
+	}
+
+	g.Impersonate("alice@example.com")
+	if err := g.configureKubectl(context.Background(), "c", "us-central1", "p"); err != nil {
+		t.Fatalf("configureKubectl() error = %v", err)
+	}
+	for _, cmd := range ran {
+		if strings.Contains(cmd, "get-credentials") {
+			t.Errorf("expected the kubeconfig to be left alone, ran %s", cmd)
+		}
+	}
+	if config == nil || config.Host != "https://10.0.0.1" || config.BearerToken != "token" || string(config.CAData) != "ca" || config.Impersonate.UserName != "alice@example.com" {
+		t.Fatalf("unexpected client config %+v", config)
+	}
+	if client, ok := g.kubeClient.(*DefaultKubeClient); !ok || !client.dryRun {
+		t.Errorf("expected a dry-run client, got %#v", g.kubeClient)
+	}
+}
+
+func TestImpersonate_ServiceAccountConnectsWithItsOwnToken(t *testing.T) {
+	var ran []string
+	g := newTestGKEOrchestrator(&mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		ran = append(ran, cmd)
+		if strings.HasPrefix(cmd, "gcloud container clusters describe c") {
+			return shell.CommandResult{Stdout: `{"endpoint": "10.0.0.1", "masterAuth": {"clusterCaCertificate": "Y2E="}}`}
+		}
+		return shell.CommandResult{ExitCode: 1, Stderr: "unexpected command"}
+	}})
+	var impersonated string
+	defer func(orig func(context.Context, string) (oauth2.TokenSource, error)) { impersonatedTokenSource = orig }(impersonatedTokenSource)
+	impersonatedTokenSource = func(_ context.Context, serviceAccount string) (oauth2.TokenSource, error) {
+		impersonated = serviceAccount
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "sa-token"}), nil
+	}
+	var config *rest.Config
+	defer func(orig func(*rest.Config) (*DefaultKubeClient, error)) { newImpersonatedKubeClient = orig }(newImpersonatedKubeClient)
+	newImpersonatedKubeClient = func(c *rest.Config) (*DefaultKubeClient, error) {
+		config = c
+		return &DefaultKubeClient{dynClient: newFakeDynamicClient()}, nil
+	}
+
+	const sa = "ci@p.iam.gserviceaccount.com"
+	g.Impersonate(sa)
+	if err := g.configureKubectl(context.Background(), "c", "us-central1", "p"); err != nil {
+		t.Fatalf("configureKubectl() error = %v", err)
+	}
+	if want := []string{"gcloud container clusters describe c --location us-central1 --project p --format=json --impersonate-service-account=" + sa}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+	if impersonated != sa {
+		t.Errorf("expected tokens minted for %s, got %q", sa, impersonated)
+	}
+	if config == nil || config.BearerToken != "" || config.Impersonate.UserName != "" || config.WrapTransport == nil {
+		t.Fatalf("unexpected client config %+v", config)
+	}
+
+	var auth string
+	rt := config.WrapTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		auth = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}))
+	if _, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://10.0.0.1/api", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer sa-token" {
+		t.Errorf("expected requests to carry the service account token, got %q", auth)
+	}
+}
+
+func TestDefaultKubeClientDryRunOption(t *testing.T) {
+	if got := (&DefaultKubeClient{}).dryRunOption(); got != nil {
+		t.Errorf("dryRunOption() = %v, want nil", got)
+	}
+	if got := (&DefaultKubeClient{dryRun: true}).dryRunOption(); !reflect.DeepEqual(got, []string{"All"}) {
+		t.Errorf("dryRunOption() = %v, want [All]", got)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper that answers requests with itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
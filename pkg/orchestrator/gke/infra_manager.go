@@ -15,12 +15,16 @@
 package gke
 
 import (
+	"context"
+
 	"bytes"
 	"embed"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/retry"
 	"hpc-toolkit/pkg/shell"
+	"hpc-toolkit/pkg/versioncheck"
 	"io"
 	"net/http"
 	"os"
@@ -36,6 +40,9 @@ import (
 	"hpc-toolkit/pkg/orchestrator"
 
 	"gopkg.in/yaml.v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 //go:embed templates/*
@@ -47,27 +54,29 @@ var templatesFS embed.FS
 const defaultKueueVersion = "v0.15.2"
 const defaultJobSetVersion = "v0.10.1"
 
-func (g *GKEOrchestrator) checkAndInstallJobSetCRD() error {
-	if installed, err := g.isJobSetCRDInstalled(); err != nil {
+func (g *GKEOrchestrator) checkAndInstallJobSetCRD(ctx context.Context) error {
+	if installed, err := g.isJobSetCRDInstalled(ctx); err != nil {
 		return err
 	} else if installed {
 		logging.Info("JobSet CRD found. Verifying Webhook health...")
-		cmdEndpoints := g.executor.ExecuteCommand("kubectl", "get", "endpoints", "jobset-webhook-service", "-n", "jobset-system", "-o", "jsonpath={.subsets[*].addresses[*].ip}")
-		if cmdEndpoints.ExitCode == 0 && strings.TrimSpace(cmdEndpoints.Stdout) != "" {
+		if ready, _ := g.serviceEndpointsReady(ctx, "jobset-system", "jobset-webhook-service", false); ready {
 			logging.Info("JobSet Webhook is healthy.")
+			if jobSetVersion, err := g.GetJobSetVersion(ctx); err == nil {
+				warnIncompatibleComponent("jobset", jobSetVersion)
+			}
 			return nil
 		}
 		logging.Info("JobSet Webhook endpoints not found. Proceeding with re-installation/fix...")
 	}
 
-	jobSetManifestsURL := fmt.Sprintf("https://github.com/kubernetes-sigs/jobset/releases/download/%s/manifests.yaml", defaultJobSetVersion)
-	return g.installJobSetCRD(jobSetManifestsURL)
+	return g.installJobSetCRD(ctx, jobSetManifestsURL(defaultJobSetVersion))
 }
 
-func (g *GKEOrchestrator) CheckAndInstallKueue(version string, clusterName string, clusterLocation string) error {
-	kueueCRDInstalled, _ := g.isKueueInstalled()
-	kueueDeploymentInstalled, _ := g.isKueueDeploymentInstalled()
-	currentVersion, _ := g.GetKueueVersion()
+func (g *GKEOrchestrator) CheckAndInstallKueue(ctx context.Context, version string, clusterName string, clusterLocation string) error {
+	kueueCRDInstalled, _ := g.isKueueInstalled(ctx)
+	kueueDeploymentInstalled, _ := g.isKueueDeploymentInstalled(ctx)
+	currentVersion, _ := g.GetKueueVersion(ctx)
+	warnIncompatibleComponent("kueue", currentVersion)
 
 	if version == "" {
 		version = defaultKueueVersion
@@ -89,7 +98,7 @@ func (g *GKEOrchestrator) CheckAndInstallKueue(version string, clusterName strin
 	}
 
 	// 3. Check if webhook is healthy (only if not already deciding to reinstall)
-	if !needReinstall && g.waitForKueueWebhook() != nil {
+	if !needReinstall && g.waitForKueueWebhook(ctx) != nil {
 		needReinstall = true
 		reinstallReason = "Kueue webhook health check failed. Treating as broken."
 	}
@@ -99,11 +108,11 @@ func (g *GKEOrchestrator) CheckAndInstallKueue(version string, clusterName strin
 			return fmt.Errorf("automatic Kueue installation blocked: we detected that cluster %s is set up for Dynamic-slicing (found 'PROVISION_ONLY' in the node pool's placementPolicy). Wiping Kueue would corrupt your custom topology configurations. Please install Kueue and the required custom CRDs manually", clusterName)
 		}
 
-		if err := g.checkKueueInstallPermissions(version); err != nil {
+		if err := g.checkKueueInstallPermissions(ctx, version); err != nil {
 			return err
 		}
 
-		if err := g.handleKueueReinstallation(version, reinstallReason); err != nil {
+		if err := g.handleKueueReinstallation(ctx, version, reinstallReason); err != nil {
 			return err
 		}
 	}
@@ -112,8 +121,8 @@ func (g *GKEOrchestrator) CheckAndInstallKueue(version string, clusterName strin
 	return nil
 }
 
-func (g *GKEOrchestrator) ensurePriorityClassesInstalled() error {
-	hasUserClasses, err := g.hasUserPriorityClasses()
+func (g *GKEOrchestrator) ensurePriorityClassesInstalled(ctx context.Context) error {
+	hasUserClasses, err := g.hasUserPriorityClasses(ctx)
 	if err != nil {
 		return err
 	}
@@ -123,21 +132,21 @@ func (g *GKEOrchestrator) ensurePriorityClassesInstalled() error {
 	}
 
 	logging.Info("No user-defined PriorityClasses found. Installing defaults...")
-	return g.installPriorityClasses()
+	return g.installPriorityClasses(ctx)
 }
 
-func (g *GKEOrchestrator) handleKueueReinstallation(targetVersion string, reason string) error {
+func (g *GKEOrchestrator) handleKueueReinstallation(ctx context.Context, targetVersion string, reason string) error {
 	promptMsg := fmt.Sprintf("%s\nKueue requires re-installation using %s.\nWARNING: This deletes all queued and suspended workloads in this cluster before proceeding.\nReplying 'no' will cause an immediate exit and you will have to do the re-installation manually. Proceed?", reason, targetVersion)
 	if !shell.PromptYesNo(promptMsg) {
 		return fmt.Errorf("user declined to re-install Kueue. Exiting.")
 	}
 
 	logging.Info("Proceeding with clean re-installation of Kueue...")
-	if err := g.DeleteAllKueueResources(); err != nil {
+	if err := g.DeleteAllKueueResources(ctx); err != nil {
 		return fmt.Errorf("failed to delete Kueue resources: %w", err)
 	}
 
-	return g.installKueue(targetVersion)
+	return g.installKueue(ctx, targetVersion)
 }
 
 func (g *GKEOrchestrator) isVersionBelow(current, target string) bool {
@@ -147,16 +156,15 @@ func (g *GKEOrchestrator) isVersionBelow(current, target string) bool {
 	return curMajor < defMajor || (curMajor == defMajor && curMinor < defMinor) || (curMajor == defMajor && curMinor == defMinor && curPatch < defPatch)
 }
 
-func (g *GKEOrchestrator) DeleteKueueDeployment() error {
+func (g *GKEOrchestrator) DeleteKueueDeployment(ctx context.Context) error {
 	logging.Info("Deleting Kueue deployment...")
-	res := g.executor.ExecuteCommand("kubectl", "delete", "deployment", "kueue-controller-manager", "-n", "kueue-system", "--ignore-not-found")
-	if res.ExitCode != 0 {
-		return fmt.Errorf("failed to delete Kueue deployment: %s\n%s", res.Stderr, res.Stdout)
+	if err := g.deleteResource(ctx, deploymentGVR, "kueue-system", "kueue-controller-manager"); err != nil {
+		return fmt.Errorf("failed to delete Kueue deployment: %w", err)
 	}
 	return nil
 }
 
-func (g *GKEOrchestrator) DeleteAllKueueResources() error {
+func (g *GKEOrchestrator) DeleteAllKueueResources(ctx context.Context) error {
 	crds := []string{
 		"admissionchecks.kueue.x-k8s.io",
 		"clusterqueues.kueue.x-k8s.io",
@@ -171,72 +179,180 @@ func (g *GKEOrchestrator) DeleteAllKueueResources() error {
 		"workloads.kueue.x-k8s.io",
 	}
 
-	resourceList := strings.Join(crds, ",")
 	logging.Info("Deleting all Kueue resources...")
-	g.executor.ExecuteCommand("kubectl", "delete", resourceList, "--all", "--ignore-not-found", "--wait=false")
+	for _, crd := range crds {
+		// The objects are deleted while the controller still runs to remove
+		// their finalizers; the CRDs below are deleted even if this fails.
+		if err := g.deleteCustomResources(ctx, crd); err != nil {
+			logging.Warn("Failed to delete the %s of Kueue: %v", crd, err)
+		}
+	}
 
 	logging.Info("Deleting Kueue CRDs...")
-	args := append([]string{"delete", "crd", "--ignore-not-found"}, crds...)
-	res := g.executor.ExecuteCommand("kubectl", args...)
-	if res.ExitCode != 0 {
-		return fmt.Errorf("failed to delete Kueue CRDs: %s\n%s", res.Stderr, res.Stdout)
+	for _, crd := range crds {
+		if err := g.deleteResource(ctx, crdGVR, "", crd); err != nil {
+			return fmt.Errorf("failed to delete Kueue CRDs: %w", err)
+		}
 	}
 
-	return g.DeleteKueueDeployment()
+	return g.DeleteKueueDeployment(ctx)
 }
 
-func (g *GKEOrchestrator) isKueueInstalled() (bool, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "crd", "clusterqueues.kueue.x-k8s.io")
-	if res.ExitCode == 0 {
-		logging.Info("Kueue CRD found.")
-		return true, nil
+// deleteCustomResources deletes every object of the CustomResourceDefinition
+// crd, in every namespace, without waiting for their finalizers. A CRD that
+// does not exist has no objects to delete.
+func (g *GKEOrchestrator) deleteCustomResources(ctx context.Context, crd string) error {
+	var def struct {
+		Spec struct {
+			Group string `json:"group"`
+			Names struct {
+				Plural string `json:"plural"`
+			} `json:"names"`
+			Versions []struct {
+				Name    string `json:"name"`
+				Storage bool   `json:"storage"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	err := g.getResource(ctx, &def, crdGVR, "", crd)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	gvr := schema.GroupVersionResource{Group: def.Spec.Group, Resource: def.Spec.Names.Plural}
+	for _, v := range def.Spec.Versions {
+		if v.Storage {
+			gvr.Version = v.Name
+		}
+	}
+
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := g.listResources(ctx, &list, gvr, "", metav1.ListOptions{}); err != nil {
+		return err
+	}
+	for _, item := range list.Items {
+		if err := g.deleteResource(ctx, gvr, item.Metadata.Namespace, item.Metadata.Name); err != nil {
+			return err
+		}
 	}
-	if strings.Contains(res.Stderr, "not found") || strings.Contains(res.Stdout, "NotFound") {
+	return nil
+}
+
+func (g *GKEOrchestrator) isKueueInstalled(ctx context.Context) (bool, error) {
+	installed, err := g.crdInstalled(ctx, "clusterqueues.kueue.x-k8s.io")
+	if err != nil {
+		return false, fmt.Errorf("failed to check for Kueue CRD: %w", err)
+	}
+	if installed {
+		logging.Info("Kueue CRD found.")
+	} else {
 		logging.Info("Kueue CRD not found.")
-		return false, nil
 	}
-	return false, fmt.Errorf("failed to check for Kueue CRD: %s\n%s", res.Stderr, res.Stdout)
+	return installed, nil
+}
+
+func (g *GKEOrchestrator) crdInstalled(ctx context.Context, name string) (bool, error) {
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return false, err
+	}
+	return kube.CRDInstalled(ctx, name)
 }
 
-func (g *GKEOrchestrator) isKueueDeploymentInstalled() (bool, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "deployment", "kueue-controller-manager", "-n", "kueue-system")
-	if res.ExitCode == 0 {
+func (g *GKEOrchestrator) isKueueDeploymentInstalled(ctx context.Context) (bool, error) {
+	_, err := g.getObject(ctx, deploymentGVR, "kueue-system", "kueue-controller-manager")
+	if err == nil {
 		logging.Info("Kueue deployment found.")
 		return true, nil
 	}
-	if strings.Contains(res.Stderr, "not found") || strings.Contains(res.Stdout, "NotFound") {
+	if apierrors.IsNotFound(err) {
 		logging.Info("Kueue deployment not found.")
 		return false, nil
 	}
-	return false, fmt.Errorf("failed to check for Kueue deployment: %s\n%s", res.Stderr, res.Stdout)
+	return false, fmt.Errorf("failed to check for Kueue deployment: %w", err)
 }
 
-func (g *GKEOrchestrator) GetKueueVersion() (string, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "deployment", "kueue-controller-manager", "-n", "kueue-system", "-o", "jsonpath={.spec.template.spec.containers[0].image}")
-	if res.ExitCode != 0 {
-		return "", fmt.Errorf("failed to get Kueue version: %s\n%s", res.Stderr, res.Stdout)
+// GetKueueVersion returns the version tag of the Kueue controller image running in the cluster.
+func (g *GKEOrchestrator) GetKueueVersion(ctx context.Context) (string, error) {
+	version, err := g.controllerVersion(ctx, "kueue-system", "kueue-controller-manager")
+	if err != nil {
+		return "", fmt.Errorf("failed to get Kueue version: %w", err)
 	}
-	image := strings.TrimSpace(res.Stdout)
-	// The image string might be "registry.k8s.io/kueue/kueue:v0.6.3"
-	// We want to extract "v0.6.3"
-	parts := strings.Split(image, ":")
-	if len(parts) < 2 {
-		return "", fmt.Errorf("unexpected image format for Kueue: %s", image)
+	return version, nil
+}
+
+// GetJobSetVersion returns the version tag of the JobSet controller image running in the cluster.
+func (g *GKEOrchestrator) GetJobSetVersion(ctx context.Context) (string, error) {
+	version, err := g.controllerVersion(ctx, "jobset-system", "jobset-controller-manager")
+	if err != nil {
+		return "", fmt.Errorf("failed to get JobSet version: %w", err)
 	}
-	version := parts[len(parts)-1]
 	return version, nil
 }
 
-func (g *GKEOrchestrator) getClusterPriorityClasses() ([]string, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "priorityclass", "-o", "jsonpath={.items[*].metadata.name}")
-	if res.ExitCode != 0 {
-		return nil, fmt.Errorf("failed to list priority classes: %s", res.Stderr)
+// controllerVersion returns the tag of the image of the first container of
+// the Deployment deployment in namespace ns, such as "v0.6.3" for
+// "registry.k8s.io/kueue/kueue:v0.6.3" or for the same image pinned by digest.
+func (g *GKEOrchestrator) controllerVersion(ctx context.Context, ns, deployment string) (string, error) {
+	var d struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Image string `json:"image"`
+					} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := g.getResource(ctx, &d, deploymentGVR, ns, deployment); err != nil {
+		return "", err
+	}
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return "", fmt.Errorf("deployment %s has no containers", deployment)
+	}
+	tag, err := parseImageTag(d.Spec.Template.Spec.Containers[0].Image)
+	if err != nil {
+		return "", fmt.Errorf("unexpected image of %s: %w", deployment, err)
+	}
+	return tag, nil
+}
+
+// warnIncompatibleComponent logs any compatibility-matrix warnings for an in-cluster component version.
+func warnIncompatibleComponent(component, version string) {
+	for _, w := range versioncheck.CheckComponent(component, version) {
+		logging.Warn("%s", w)
+	}
+}
+
+func (g *GKEOrchestrator) getClusterPriorityClasses(ctx context.Context) ([]string, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := g.listResources(ctx, &list, priorityClassGVR, "", metav1.ListOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to list priority classes: %w", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Metadata.Name)
 	}
-	return strings.Fields(res.Stdout), nil
+	return names, nil
 }
 
-func (g *GKEOrchestrator) hasUserPriorityClasses() (bool, error) {
-	existing, err := g.getClusterPriorityClasses()
+func (g *GKEOrchestrator) hasUserPriorityClasses(ctx context.Context) (bool, error) {
+	existing, err := g.getClusterPriorityClasses(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -251,10 +367,19 @@ func (g *GKEOrchestrator) hasUserPriorityClasses() (bool, error) {
 	return false, nil
 }
 
-func (g *GKEOrchestrator) installKueue(version string) error {
+func (g *GKEOrchestrator) installKueue(ctx context.Context, version string) error {
+	if err := g.applyKueueRelease(ctx, version); err != nil {
+		return err
+	}
+	return g.installKueueResources(ctx, defaultClusterQueue, defaultLocalQueue)
+}
+
+// applyKueueRelease applies the manifests of a Kueue release, which installs
+// it or upgrades it in place, and waits for its webhook to serve.
+func (g *GKEOrchestrator) applyKueueRelease(ctx context.Context, version string) error {
 	logging.Info("Installing Kueue version %s...", version)
 	kueueManifestsURL := fmt.Sprintf("https://github.com/kubernetes-sigs/kueue/releases/download/%s/manifests.yaml", version)
-	manifestBytes, err := g.downloadManifests(kueueManifestsURL)
+	manifestBytes, err := g.downloadManifests(ctx, kueueManifestsURL)
 	if err != nil {
 		return err
 	}
@@ -264,20 +389,16 @@ func (g *GKEOrchestrator) installKueue(version string) error {
 		return err
 	}
 
-	if err := g.applyManifests(cleanedManifests, "kueue.yaml"); err != nil {
+	if err := g.applyManifests(ctx, cleanedManifests, "kueue.yaml"); err != nil {
 		return err
 	}
 
 	logging.Info("Kueue components applied successfully.")
 
-	if err := g.waitForKueueWebhook(); err != nil {
-		return err
-	}
-
-	return g.installKueueResources(defaultClusterQueue, defaultLocalQueue)
+	return g.waitForKueueWebhook(ctx)
 }
 
-func (g *GKEOrchestrator) installPriorityClasses() error {
+func (g *GKEOrchestrator) installPriorityClasses(ctx context.Context) error {
 	logging.Info("Installing Kueue PriorityClasses...")
 	priorityClassesTmpl, err := yamltemplate.New("priority_classes.tmpl").ParseFS(templatesFS, "templates/priority_classes.tmpl")
 	if err != nil {
@@ -287,18 +408,18 @@ func (g *GKEOrchestrator) installPriorityClasses() error {
 	if err := priorityClassesTmpl.Execute(&priorityClassesBuf, nil); err != nil {
 		return fmt.Errorf("failed to execute priority_classes.tmpl template: %w", err)
 	}
-	return g.applyManifests(priorityClassesBuf.Bytes(), "priority-classes.yaml")
+	return g.applyManifests(ctx, priorityClassesBuf.Bytes(), "priority-classes.yaml")
 }
 
-func (g *GKEOrchestrator) installKueueResources(cqName string, lqName string) error {
+func (g *GKEOrchestrator) installKueueResources(ctx context.Context, cqName string, lqName string) error {
 	logging.Info("Installing Kueue resources (ClusterQueue, LocalQueue)...")
 
-	hasUserClasses, err := g.hasUserPriorityClasses()
+	hasUserClasses, err := g.hasUserPriorityClasses(ctx)
 	if err != nil {
 		return err
 	}
 	if !hasUserClasses {
-		if err := g.installPriorityClasses(); err != nil {
+		if err := g.installPriorityClasses(ctx); err != nil {
 			return err
 		}
 	}
@@ -308,7 +429,7 @@ func (g *GKEOrchestrator) installKueueResources(cqName string, lqName string) er
 	if err != nil {
 		return err
 	}
-	if err := g.applyManifests(clusterQueueBytes, "cluster-queue.yaml"); err != nil {
+	if err := g.applyManifests(ctx, clusterQueueBytes, "cluster-queue.yaml"); err != nil {
 		return err
 	}
 
@@ -325,7 +446,7 @@ func (g *GKEOrchestrator) installKueueResources(cqName string, lqName string) er
 	}{"default", lqName, cqName}); err != nil {
 		return fmt.Errorf("failed to execute local_queue.tmpl template: %w", err)
 	}
-	if err := g.applyManifests(localQueueBuf.Bytes(), "local-queue.yaml"); err != nil {
+	if err := g.applyManifests(ctx, localQueueBuf.Bytes(), "local-queue.yaml"); err != nil {
 		return err
 	}
 
@@ -565,7 +686,7 @@ func (g *GKEOrchestrator) renderResourceFlavor(name string, nodeLabels map[strin
 	return yaml.Marshal(rfMap)
 }
 
-func (g *GKEOrchestrator) EnsureResourceFlavors() error {
+func (g *GKEOrchestrator) EnsureResourceFlavors(ctx context.Context) error {
 	logging.Info("Ensuring Kueue ResourceFlavors exist...")
 	for name, fc := range g.capacity.Flavors {
 		logging.Info("Ensuring ResourceFlavor '%s'...", name)
@@ -573,17 +694,23 @@ func (g *GKEOrchestrator) EnsureResourceFlavors() error {
 		if err != nil {
 			return fmt.Errorf("failed to render ResourceFlavor %s: %w", name, err)
 		}
-		if err := g.applyManifests(rfBytes, "resource-flavor.yaml"); err != nil {
+		if err := g.applyManifests(ctx, rfBytes, "resource-flavor.yaml"); err != nil {
 			return fmt.Errorf("failed to apply ResourceFlavor %s: %w", name, err)
 		}
 	}
 	return nil
 }
 
-func (g *GKEOrchestrator) installJobSetCRD(jobSetManifestsURL string) error {
+// jobSetManifestsURL returns where the manifests of a JobSet release are
+// published.
+func jobSetManifestsURL(version string) string {
+	return fmt.Sprintf("https://github.com/kubernetes-sigs/jobset/releases/download/%s/manifests.yaml", version)
+}
+
+func (g *GKEOrchestrator) installJobSetCRD(ctx context.Context, jobSetManifestsURL string) error {
 	logging.Info("Installing/Fixing JobSet CRD and Webhook...")
 
-	manifestBytes, err := g.downloadManifests(jobSetManifestsURL)
+	manifestBytes, err := g.downloadManifests(ctx, jobSetManifestsURL)
 	if err != nil {
 		return err
 	}
@@ -593,53 +720,56 @@ func (g *GKEOrchestrator) installJobSetCRD(jobSetManifestsURL string) error {
 		return err
 	}
 
-	if err := g.applyManifests(cleanedManifests, "jobset.yaml"); err != nil {
+	if err := g.applyManifests(ctx, cleanedManifests, "jobset.yaml"); err != nil {
 		return err
 	}
 
 	logging.Info("JobSet components applied successfully.")
 
-	return g.waitForJobSetWebhook()
+	return g.waitForJobSetWebhook(ctx)
 }
 
-type k8sEndpointSliceList struct {
-	Items []struct {
-		Endpoints []struct {
-			Addresses  []string `json:"addresses"`
-			Conditions struct {
-				Ready bool `json:"ready"`
-			} `json:"conditions"`
-		} `json:"endpoints"`
-	} `json:"items"`
+// webhookEndpointsRetry polls the endpoints of a webhook Service for up to two
+// minutes while its controller starts.
+var webhookEndpointsRetry = retry.Policy{Attempts: 40, Initial: 3 * time.Second, Multiplier: 1}
+
+// webhookProbeRetry retries a request to a webhook for up to 100 seconds
+// while it starts to serve.
+var webhookProbeRetry = retry.Policy{Attempts: 20, Initial: 5 * time.Second, Multiplier: 1}
+
+var errEndpointsNotReady = errors.New("no ready endpoints")
+
+// waitForWebhookEndpoints waits until the webhook Service service in
+// namespace ns has a ready endpoint.
+func (g *GKEOrchestrator) waitForWebhookEndpoints(ctx context.Context, ns, service string, useEndpointSlice bool) error {
+	err := retry.Do(ctx, g.retryPolicy(webhookEndpointsRetry), func() error {
+		ready, err := g.serviceEndpointsReady(ctx, ns, service, useEndpointSlice)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		if !ready {
+			return fmt.Errorf("%s: %w", service, errEndpointsNotReady)
+		}
+		return nil
+	})
+	if errors.Is(err, errEndpointsNotReady) {
+		return fmt.Errorf("timed out waiting for %s endpoints to be available", service)
+	}
+	return err
 }
 
-func (g *GKEOrchestrator) waitForJobSetWebhook() error {
+func (g *GKEOrchestrator) waitForJobSetWebhook(ctx context.Context) error {
 	logging.Info("Waiting for JobSet webhook service to be ready...")
-	res := g.executor.ExecuteCommand("kubectl", "rollout", "status", "deployment/jobset-controller-manager", "-n", "jobset-system", "--timeout=600s")
-	if res.ExitCode != 0 {
-		return fmt.Errorf("jobset controller manager failed to become ready: %s\n%s", res.Stderr, res.Stdout)
+	if err := g.waitForRollout(ctx, "jobset-system", "jobset-controller-manager", deploymentRolloutRetry); err != nil {
+		return fmt.Errorf("jobset controller manager failed to become ready: %w", err)
 	}
 
 	logging.Info("Verifying JobSet webhook service endpoints...")
-	for i := 0; i < 40; i++ {
-		cmdEndpoints := g.executor.ExecuteCommand("kubectl", "get", "endpointslice", "-l", "kubernetes.io/service-name=jobset-webhook-service", "-n", "jobset-system", "-o", "json")
-		if cmdEndpoints.ExitCode == 0 {
-			var eps k8sEndpointSliceList
-			if err := json.Unmarshal([]byte(cmdEndpoints.Stdout), &eps); err == nil {
-				for _, item := range eps.Items {
-					for _, ep := range item.Endpoints {
-						if ep.Conditions.Ready && len(ep.Addresses) > 0 {
-							logging.Info("JobSet webhook service endpoints are available.")
-							return nil
-						}
-					}
-				}
-			}
-		}
-		time.Sleep(3 * time.Second)
+	if err := g.waitForWebhookEndpoints(ctx, "jobset-system", "jobset-webhook-service", true); err != nil {
+		return err
 	}
-
-	return fmt.Errorf("timed out waiting for jobset-webhook-service endpoints to be available")
+	logging.Info("JobSet webhook service endpoints are available.")
+	return nil
 }
 
 func parseVersion(v string) (int, int, int) {
@@ -661,14 +791,12 @@ func parseVersion(v string) (int, int, int) {
 	return major, minor, patch
 }
 
-func (g *GKEOrchestrator) waitForKueueWebhook() error {
-	res := g.executor.ExecuteCommand("kubectl", "rollout", "status", "deployment/kueue-controller-manager", "-n", "kueue-system", "--timeout=600s")
-	if res.ExitCode != 0 {
-		podDetails := g.getKueuePodDetails()
-		return fmt.Errorf("kueue controller manager failed to become ready: %s\n%s%s", res.Stderr, res.Stdout, podDetails)
+func (g *GKEOrchestrator) waitForKueueWebhook(ctx context.Context) error {
+	if err := g.waitForRollout(ctx, "kueue-system", "kueue-controller-manager", deploymentRolloutRetry); err != nil {
+		return fmt.Errorf("kueue controller manager failed to become ready: %w%s", err, g.getKueuePodDetails(ctx))
 	}
 
-	version, err := g.GetKueueVersion()
+	version, err := g.GetKueueVersion(ctx)
 	if err != nil {
 		logging.Warn("Failed to get Kueue version, defaulting to Endpoints check: %v", err)
 		version = defaultKueueVersion // Fallback to older version behavior
@@ -677,84 +805,60 @@ func (g *GKEOrchestrator) waitForKueueWebhook() error {
 	major, minor, _ := parseVersion(version)
 	useEndpointSlice := major > 0 || (major == 0 && minor > 13)
 
-	endpointsReady := false
-	for i := 0; i < 40; i++ {
-		ready, err := g.checkKueueEndpoints(useEndpointSlice)
-		if err != nil {
-			return err
-		}
-		if ready {
-			logging.Info("Kueue webhook service endpoints are available.")
-			endpointsReady = true
-			break
-		}
-		time.Sleep(3 * time.Second)
-	}
-
-	if !endpointsReady {
-		return fmt.Errorf("timed out waiting for kueue-webhook-service endpoints to be available")
+	if err := g.waitForWebhookEndpoints(ctx, "kueue-system", "kueue-webhook-service", useEndpointSlice); err != nil {
+		return err
 	}
+	logging.Info("Kueue webhook service endpoints are available.")
 
 	// Active probe to ensure webhook is processing requests
 	logging.Info("Probing Kueue webhook readiness...")
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return err
+	}
 	probeManifest := `apiVersion: kueue.x-k8s.io/` + kueueAPIVersion + `
 kind: ResourceFlavor
 metadata:
   name: gcluster-webhook-probe
 `
-	f, err := os.CreateTemp("", "gcluster-webhook-probe-*.yaml")
+	err = retry.Do(ctx, g.retryPolicy(webhookProbeRetry), func() error {
+		return kube.ApplyManifests(ctx, []byte(probeManifest))
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create probe manifest file: %w", err)
+		return fmt.Errorf("timed out waiting for Kueue webhook to become operational: %w", err)
 	}
-	probeFile := f.Name()
-	if _, err := f.Write([]byte(probeManifest)); err != nil {
-		f.Close()
-		return fmt.Errorf("failed to write probe manifest: %w", err)
+	logging.Info("Kueue webhook is fully operational.")
+	if err := g.deleteResource(ctx, resourceFlavorGVR, "", "gcluster-webhook-probe"); err != nil {
+		logging.Warn("Failed to delete the Kueue webhook probe: %v", err)
 	}
-	f.Close()
-	defer os.Remove(probeFile)
-
-	for i := 0; i < 20; i++ {
-		res := g.executor.ExecuteCommand("kubectl", "apply", "-f", probeFile)
-		if res.ExitCode == 0 {
-			logging.Info("Kueue webhook is fully operational.")
-			g.executor.ExecuteCommand("kubectl", "delete", "-f", probeFile, "--ignore-not-found")
-			return nil
-		}
-		time.Sleep(5 * time.Second)
-	}
-
-	return fmt.Errorf("timed out waiting for Kueue webhook to become operational")
+	return nil
 }
 
-func (g *GKEOrchestrator) getKueuePodDetails() string {
-	podRes := g.executor.ExecuteCommand("kubectl", "get", "pods", "-n", "kueue-system", "-l", "control-plane=controller-manager", "-o", "json")
+func (g *GKEOrchestrator) getKueuePodDetails(ctx context.Context) string {
+	var podList struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Status struct {
+				ContainerStatuses []struct {
+					Name  string `json:"name"`
+					State struct {
+						Waiting struct {
+							Reason  string `json:"reason"`
+							Message string `json:"message"`
+						} `json:"waiting"`
+					} `json:"state"`
+				} `json:"containerStatuses"`
+			} `json:"status"`
+		} `json:"items"`
+	}
 	var podDetails string
-	if podRes.ExitCode == 0 {
-		var podList struct {
-			Items []struct {
-				Metadata struct {
-					Name string `json:"name"`
-				} `json:"metadata"`
-				Status struct {
-					ContainerStatuses []struct {
-						Name  string `json:"name"`
-						State struct {
-							Waiting struct {
-								Reason  string `json:"reason"`
-								Message string `json:"message"`
-							} `json:"waiting"`
-						} `json:"state"`
-					} `json:"containerStatuses"`
-				} `json:"status"`
-			} `json:"items"`
-		}
-		if err := json.Unmarshal([]byte(podRes.Stdout), &podList); err == nil {
-			for _, item := range podList.Items {
-				for _, cs := range item.Status.ContainerStatuses {
-					if cs.State.Waiting.Reason != "" {
-						podDetails += fmt.Sprintf("\n  - Pod %s: %s (%s)", item.Metadata.Name, cs.State.Waiting.Reason, cs.State.Waiting.Message)
-					}
+	if err := g.listResources(ctx, &podList, podGVR, "kueue-system", metav1.ListOptions{LabelSelector: "control-plane=controller-manager"}); err == nil {
+		for _, item := range podList.Items {
+			for _, cs := range item.Status.ContainerStatuses {
+				if cs.State.Waiting.Reason != "" {
+					podDetails += fmt.Sprintf("\n  - Pod %s: %s (%s)", item.Metadata.Name, cs.State.Waiting.Reason, cs.State.Waiting.Message)
 				}
 			}
 		}
@@ -762,78 +866,50 @@ func (g *GKEOrchestrator) getKueuePodDetails() string {
 	return podDetails
 }
 
-func (g *GKEOrchestrator) checkKueueEndpoints(useEndpointSlice bool) (bool, error) {
-	var cmdEndpoints shell.CommandResult
-	if useEndpointSlice {
-		cmdEndpoints = g.executor.ExecuteCommand("kubectl", "get", "endpointslice", "-l", "kubernetes.io/service-name=kueue-webhook-service", "-n", "kueue-system", "-o", "json")
-	} else {
-		cmdEndpoints = g.executor.ExecuteCommand("kubectl", "get", "endpoints", "kueue-webhook-service", "-n", "kueue-system", "-o", "json")
-	}
-
-	if cmdEndpoints.ExitCode != 0 {
-		return false, nil
-	}
-
-	if useEndpointSlice {
-		var eps k8sEndpointSliceList
-		if err := json.Unmarshal([]byte(cmdEndpoints.Stdout), &eps); err == nil {
-			for _, item := range eps.Items {
-				for _, ep := range item.Endpoints {
-					if ep.Conditions.Ready && len(ep.Addresses) > 0 {
-						return true, nil
-					}
-				}
-			}
-		}
-	} else {
-		var eps struct {
-			Subsets []struct {
-				Addresses []struct {
-					Ip string `json:"ip"`
-				} `json:"addresses"`
-			} `json:"subsets"`
-		}
-		if err := json.Unmarshal([]byte(cmdEndpoints.Stdout), &eps); err == nil {
-			for _, subset := range eps.Subsets {
-				if len(subset.Addresses) > 0 {
-					return true, nil
-				}
-			}
-		}
-	}
-	return false, nil
-}
-
-func (g *GKEOrchestrator) isJobSetCRDInstalled() (bool, error) {
-	res := g.executor.ExecuteCommand("kubectl", "get", "crd", "jobsets.jobset.x-k8s.io")
-	if res.ExitCode == 0 {
-		return true, nil
+func (g *GKEOrchestrator) isJobSetCRDInstalled(ctx context.Context) (bool, error) {
+	installed, err := g.crdInstalled(ctx, "jobsets.jobset.x-k8s.io")
+	if err != nil {
+		return false, fmt.Errorf("failed to check for JobSet CRD: %w", err)
 	}
-	if strings.Contains(res.Stderr, "not found") || strings.Contains(res.Stdout, "NotFound") {
+	if !installed {
 		logging.Info("JobSet CRD not found.")
-		return false, nil
 	}
-	return false, fmt.Errorf("failed to check for JobSet CRD: %s\n%s", res.Stderr, res.Stdout)
+	return installed, nil
 }
 
-func (g *GKEOrchestrator) downloadManifests(url string) ([]byte, error) {
+func (g *GKEOrchestrator) downloadManifests(ctx context.Context, url string) ([]byte, error) {
 	logging.Info("Downloading manifests from %s", url)
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download manifests: %w", err)
+	client := g.httpClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
 	}
-	defer resp.Body.Close()
+	var manifestBytes []byte
+	err := retry.Do(ctx, g.retryPolicy(retry.Default), func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to download manifests: %w", err))
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to download manifests: %w", err)
+		}
+		defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download manifests: received status code %d", resp.StatusCode)
-	}
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("failed to download manifests: received status code %d", resp.StatusCode)
+			if !retry.TransientStatus(resp.StatusCode) {
+				return retry.Permanent(err)
+			}
+			return err
+		}
 
-	manifestBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read manifests: %w", err)
-	}
-	return manifestBytes, nil
+		manifestBytes, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read manifests: %w", err)
+		}
+		return nil
+	})
+	return manifestBytes, err
 }
 
 func (g *GKEOrchestrator) cleanJobSetManifests(manifestBytes []byte) ([]byte, error) {
@@ -976,7 +1052,7 @@ func replaceDeprecatedRbacProxyImage(podSpec map[interface{}]interface{}) {
 	replaceInContainerList("initContainers")
 }
 
-func (g *GKEOrchestrator) applyManifests(manifests []byte, filename string) error {
+func (g *GKEOrchestrator) applyManifests(ctx context.Context, manifests []byte, filename string) error {
 	logging.Info("Applying manifests for %s...", filename)
 
 	homeDir, err := os.UserHomeDir()
@@ -995,9 +1071,12 @@ func (g *GKEOrchestrator) applyManifests(manifests []byte, filename string) erro
 	}
 	logging.Info("Manifests saved to %s", filePath)
 
-	res := g.executor.ExecuteCommand("kubectl", "apply", "-f", filePath)
-	if res.ExitCode != 0 {
-		return fmt.Errorf("kubectl apply failed with exit code %d: %s\n%s", res.ExitCode, res.Stderr, res.Stdout)
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return err
+	}
+	if err := kube.ApplyManifests(ctx, manifests); err != nil {
+		return fmt.Errorf("failed to apply %s: %w", filePath, err)
 	}
 	logging.Info("Manifests applied successfully.")
 	return nil
@@ -1022,17 +1101,20 @@ func (g *GKEOrchestrator) removeDescriptionFields(data map[interface{}]interface
 }
 
 // ValidateClusterState runs all cluster-specific validations to fail early on invalid state.
-func (g *GKEOrchestrator) ValidateClusterState(job *orchestrator.JobDefinition) error {
+func (g *GKEOrchestrator) ValidateClusterState(ctx context.Context, job *orchestrator.JobDefinition) error {
 	validators := []func() error{
-		g.checkClusterConnectivity,
-		func() error { return g.CheckAndInstallKueue("", job.ClusterName, job.ClusterLocation) },
-		g.checkAndInstallJobSetCRD,
+		func() error { return g.checkClusterConnectivity(ctx) },
+		func() error { return g.ensureKueueOrNoQueue(ctx, job) },
+	}
+	// Batch Jobs are a core API, so clusters without JobSet can still run them.
+	if !isBatchJob(*job) {
+		validators = append(validators, func() error { return g.checkAndInstallJobSetCRD(ctx) })
 	}
 
 	if job.PriorityClassName != "" {
 		validators = append(validators,
-			func() error { return g.ensurePriorityClassesInstalled() },
-			func() error { return g.validatePriorityClass(job.PriorityClassName) },
+			func() error { return g.ensurePriorityClassesInstalled(ctx) },
+			func() error { return g.validatePriorityClass(ctx, job.PriorityClassName) },
 		)
 	}
 
@@ -1044,13 +1126,41 @@ func (g *GKEOrchestrator) ValidateClusterState(job *orchestrator.JobDefinition)
 	return nil
 }
 
+// ensureKueueOrNoQueue checks the Kueue installation of the cluster, and
+// installs or repairs it when needed. On a cluster without Kueue, the user may
+// instead submit the workload without a queue.
+func (g *GKEOrchestrator) ensureKueueOrNoQueue(ctx context.Context, job *orchestrator.JobDefinition) error {
+	if job.NoQueue {
+		logging.Info("Submitting without a Kueue queue (--no-queue).")
+		return nil
+	}
+	installed, err := g.crdInstalled(ctx, "clusterqueues.kueue.x-k8s.io")
+	if err == nil && !installed && job.KueueQueueName == "" {
+		promptMsg := fmt.Sprintf("Kueue is not installed on cluster %s. Do you want gcluster to install it?\nReplying 'no' submits the workload without a queue, scheduled by pod priority only.", job.ClusterName)
+		if !shell.PromptYesNo(promptMsg) {
+			logging.Warn("Submitting without a Kueue queue: the workload is not admitted as a gang, and its pods are scheduled as soon as nodes are available. Pass --no-queue to skip this prompt.")
+			job.NoQueue = true
+			return nil
+		}
+	}
+	return g.CheckAndInstallKueue(ctx, "", job.ClusterName, job.ClusterLocation)
+}
+
+// clusterConnectivityTimeout bounds the connectivity check of a cluster.
+const clusterConnectivityTimeout = 5 * time.Second
+
 // checkClusterConnectivity verifies that we can connect to the cluster.
 // It uses a short timeout to fail fast if IP is blocked by authorized networks.
-func (g *GKEOrchestrator) checkClusterConnectivity() error {
+func (g *GKEOrchestrator) checkClusterConnectivity(ctx context.Context) error {
 	logging.Info("Checking cluster connectivity...")
-	res := g.executor.ExecuteCommand("kubectl", "get", "namespace", "default", "--request-timeout=5s")
-	if res.ExitCode != 0 {
-		return fmt.Errorf("failed to connect to GKE cluster. Please verify your IP is allowed in the cluster's authorized networks or that you have correct network access. Error: %s", res.Stderr)
+	client, err := g.getDynamicClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, clusterConnectivityTimeout)
+	defer cancel()
+	if _, err := client.Resource(namespaceGVR).Get(ctx, "default", metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("failed to connect to GKE cluster. Please verify your IP is allowed in the cluster's authorized networks or that you have correct network access. Error: %w", err)
 	}
 	logging.Info("Cluster connectivity verified.")
 	return nil
@@ -1068,7 +1178,7 @@ func (g *GKEOrchestrator) checkDynamicSlicingViaGKE() bool {
 	return false
 }
 
-func (g *GKEOrchestrator) checkKueueInstallPermissions(version string) error {
+func (g *GKEOrchestrator) checkKueueInstallPermissions(ctx context.Context, version string) error {
 	logging.Info("Verifying cluster permissions for Kueue installation...")
 	checks := []struct {
 		verb     string
@@ -1080,21 +1190,24 @@ func (g *GKEOrchestrator) checkKueueInstallPermissions(version string) error {
 		{"create", "clusterrolebindings.rbac.authorization.k8s.io"},
 	}
 
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return err
+	}
 	for _, c := range checks {
-		res := g.executor.ExecuteCommand("kubectl", "auth", "can-i", c.verb, c.resource)
-		if res.ExitCode != 0 || strings.TrimSpace(res.Stdout) != "yes" {
+		if allowed, err := kube.CanI(ctx, c.verb, c.resource); err != nil || !allowed {
 			return fmt.Errorf("unable to re-install kueue to version %s, this could be a permission issue. Please contact your cluster administrator for updating KUEUE settings", version)
 		}
 	}
 	return nil
 }
 
-func (g *GKEOrchestrator) validatePriorityClass(requestedPriority string) error {
+func (g *GKEOrchestrator) validatePriorityClass(ctx context.Context, requestedPriority string) error {
 	if requestedPriority == "" {
 		return nil
 	}
 
-	existing, err := g.getClusterPriorityClasses()
+	existing, err := g.getClusterPriorityClasses(ctx)
 	if err != nil {
 		return err
 	}
@@ -15,13 +15,26 @@
 package gke
 
 import (
+	"context"
+
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
+	"hpc-toolkit/pkg/testutil"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestRenderClusterQueue(t *testing.T) {
 	orc := &GKEOrchestrator{
+		kubeClient: &MockKubeClient{},
 		capacity: ClusterCapacity{
 			Flavors: map[string]FlavorCapacity{
 				"flavor-default": {
@@ -63,6 +76,7 @@ func TestRenderClusterQueue(t *testing.T) {
 
 func TestRenderClusterQueue_Pathways(t *testing.T) {
 	orc := &GKEOrchestrator{
+		kubeClient: &MockKubeClient{},
 		capacity: ClusterCapacity{
 			Flavors: map[string]FlavorCapacity{
 				"flavor-tpu": { // TPU flavor (name contains 'tpu')
@@ -128,7 +142,8 @@ func TestRenderClusterQueue_Pathways(t *testing.T) {
 
 func TestRenderClusterQueue_Empty(t *testing.T) {
 	orc := &GKEOrchestrator{
-		capacity: ClusterCapacity{}, // Empty
+		kubeClient: &MockKubeClient{},
+		capacity:   ClusterCapacity{}, // Empty
 	}
 
 	bytes, err := orc.renderClusterQueue("cluster-queue")
@@ -190,69 +205,82 @@ func (m *mockExecutor) ExecuteCommandStream(name string, args ...string) error {
 	return nil
 }
 
-func TestWaitForKueueWebhook_Success(t *testing.T) {
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			if name == "kubectl" && args[0] == "get" && args[1] == "deployment" {
-				return shell.CommandResult{
-					ExitCode: 0,
-					Stdout:   "registry.k8s.io/kueue/kueue:v0.15.2",
-				}
-			}
-			if name == "kubectl" && args[0] == "get" && args[1] == "endpointslice" {
-				return shell.CommandResult{
-					ExitCode: 0,
-					Stdout:   `{"items":[{"endpoints":[{"addresses":["10.4.1.3"],"conditions":{"ready":true}}]}]}`,
-				}
-			}
-			// Handle rollout status
-			if name == "kubectl" && args[0] == "rollout" {
-				return shell.CommandResult{ExitCode: 0}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
-	}
+// fakeDeployment returns a ready Deployment running the given image.
+func fakeDeployment(ns, name, image string) string {
+	return fakeObject("Deployment", ns, name, fmt.Sprintf(`{
+		"metadata": {"generation": 1},
+		"spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "manager", "image": %q}]}}},
+		"status": {"observedGeneration": 1, "replicas": 1, "updatedReplicas": 1, "readyReplicas": 1, "availableReplicas": 1}
+	}`, image))
+}
 
-	orc := &GKEOrchestrator{
-		executor: mock,
+// fakeKueueCluster returns a client of a cluster that runs Kueue version with
+// a serving webhook, and serves the objects of manifests too. The controller
+// Deployment survives deletes, as re-installing Kueue creates it again.
+func fakeKueueCluster(version string, manifests ...string) *dynamicfake.FakeDynamicClient {
+	client := newFakeDynamicClient(append(manifests,
+		fakeDeployment("kueue-system", "kueue-controller-manager", "registry.k8s.io/kueue/kueue:"+version),
+		fakeList("EndpointSlice", "kueue-system", map[string]string{"kubernetes.io/service-name": "kueue-webhook-service"},
+			`{"items": [{"endpoints": [{"addresses": ["10.4.1.3"], "conditions": {"ready": true}}]}]}`),
+		fakeObject("Endpoints", "kueue-system", "kueue-webhook-service", `{"subsets": [{"addresses": [{"ip": "10.4.1.3"}]}]}`),
+	)...)
+	client.PrependReactor("delete", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	return client
+}
+
+// deletedCRDs returns the names of the CRDs deleted through client.
+func deletedCRDs(client *dynamicfake.FakeDynamicClient) []string {
+	var names []string
+	for _, action := range client.Actions() {
+		if del, ok := action.(k8stesting.DeleteAction); ok && action.GetResource() == crdGVR {
+			names = append(names, del.GetName())
+		}
 	}
+	return names
+}
 
-	err := orc.waitForKueueWebhook()
+func TestWaitForKueueWebhook_Success(t *testing.T) {
+	kube := &MockKubeClient{}
+	orc := &GKEOrchestrator{kubeClient: kube}
+	orc.dynClient = fakeKueueCluster("v0.15.2")
+
+	err := orc.waitForKueueWebhook(context.Background())
 	if err != nil {
 		t.Fatalf("waitForKueueWebhook failed: %v", err)
 	}
+	if len(kube.Applied) != 1 || !strings.Contains(kube.Applied[0], "name: gcluster-webhook-probe") {
+		t.Errorf("expected the webhook to be probed with a ResourceFlavor, got %q", kube.Applied)
+	}
 }
 
 func TestWaitForKueueWebhook_Success_OlderVersion(t *testing.T) {
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			if name == "kubectl" && args[0] == "get" && args[1] == "deployment" {
-				return shell.CommandResult{
-					ExitCode: 0,
-					Stdout:   "registry.k8s.io/kueue/kueue:v0.11.1",
-				}
-			}
-			if name == "kubectl" && args[0] == "get" && args[1] == "endpoints" {
-				return shell.CommandResult{
-					ExitCode: 0,
-					Stdout:   `{"subsets":[{"addresses":[{"ip":"10.4.1.3"}]}]}`,
-				}
-			}
-			// Handle rollout status
-			if name == "kubectl" && args[0] == "rollout" {
-				return shell.CommandResult{ExitCode: 0}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
+	orc := &GKEOrchestrator{kubeClient: &MockKubeClient{}}
+	// Kueue before v0.14 is checked through the Endpoints of its webhook.
+	orc.dynClient = newFakeDynamicClient(
+		fakeDeployment("kueue-system", "kueue-controller-manager", "registry.k8s.io/kueue/kueue:v0.11.1"),
+		fakeObject("Endpoints", "kueue-system", "kueue-webhook-service", `{"subsets": [{"addresses": [{"ip": "10.4.1.3"}]}]}`),
+	)
+
+	err := orc.waitForKueueWebhook(context.Background())
+	if err != nil {
+		t.Fatalf("waitForKueueWebhook failed: %v", err)
 	}
+}
 
-	orc := &GKEOrchestrator{
-		executor: mock,
-	}
+func TestWaitForKueueWebhook_NotReady(t *testing.T) {
+	orc := &GKEOrchestrator{kubeClient: &MockKubeClient{}}
+	orc.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	orc.dynClient = newFakeDynamicClient(
+		fakeDeployment("kueue-system", "kueue-controller-manager", "registry.k8s.io/kueue/kueue:v0.15.2"),
+		fakeList("EndpointSlice", "kueue-system", map[string]string{"kubernetes.io/service-name": "kueue-webhook-service"},
+			`{"items": [{"endpoints": [{"addresses": ["10.4.1.3"], "conditions": {"ready": false}}]}]}`),
+	)
 
-	err := orc.waitForKueueWebhook()
-	if err != nil {
-		t.Fatalf("waitForKueueWebhook failed: %v", err)
+	err := orc.waitForKueueWebhook(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out waiting for kueue-webhook-service endpoints") {
+		t.Errorf("expected the webhook wait to time out, got %v", err)
 	}
 }
 
@@ -282,117 +310,80 @@ func TestParseVersion(t *testing.T) {
 	}
 }
 
+// fakeKueueRelease serves a minimal Kueue release manifest to orc and makes
+// its waits instant, so installing Kueue needs no network access.
+func fakeKueueRelease(t *testing.T, orc *GKEOrchestrator) *testutil.FakeTransport {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	url := fmt.Sprintf("https://github.com/kubernetes-sigs/kueue/releases/download/%s/manifests.yaml", defaultKueueVersion)
+	transport := testutil.NewFakeTransport(map[string]testutil.HTTPResponse{
+		url: {Body: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: kueue-system\n"},
+	})
+	orc.SetHTTPClient(transport.Client())
+	orc.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	return transport
+}
+
 func TestCheckAndInstallKueue_ReinstallNeeded_LowVersion(t *testing.T) {
 	origPrompt := shell.PromptYesNo
 	defer func() { shell.PromptYesNo = origPrompt }()
 	shell.PromptYesNo = func(prompt string) bool { return true }
 
-	deleteCalled := false
+	orc := &GKEOrchestrator{kubeClient: &MockKubeClient{}}
+	client := fakeKueueCluster("v0.12.0")
+	orc.dynClient = client
+	release := fakeKueueRelease(t, orc)
 
-	matchers := []struct {
-		pattern string
-		res     shell.CommandResult
-		action  func()
-	}{
-		{pattern: "kubectl delete crd", action: func() { deleteCalled = true }, res: shell.CommandResult{ExitCode: 0}},
-		{pattern: "auth can-i", res: shell.CommandResult{ExitCode: 0, Stdout: "yes"}},
-		{pattern: "kubectl get crd", res: shell.CommandResult{ExitCode: 0, Stdout: "clusterqueues.kueue.x-k8s.io found"}},
-		{pattern: "jsonpath", res: shell.CommandResult{ExitCode: 0, Stdout: "registry.k8s.io/kueue/kueue:v0.12.0"}},
-		{pattern: "kubectl get deployment", res: shell.CommandResult{ExitCode: 0, Stdout: "kueue-controller-manager found"}},
-		{pattern: "kubectl get endpoints", res: shell.CommandResult{ExitCode: 0, Stdout: `{"subsets": [{"addresses": [{"ip": "10.0.0.1"}]}]}`}},
-		{pattern: "kubectl get endpointslice", res: shell.CommandResult{ExitCode: 0, Stdout: `{"subsets": [{"addresses": [{"ip": "10.0.0.1"}]}]}`}},
-		{pattern: "apply", res: shell.CommandResult{ExitCode: 0}},
-		{pattern: "priorityclass", res: shell.CommandResult{ExitCode: 0}},
-		{pattern: "rollout", res: shell.CommandResult{ExitCode: 0}},
-	}
-
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			fullCmd := name + " " + strings.Join(args, " ")
-			for _, m := range matchers {
-				if strings.Contains(fullCmd, m.pattern) {
-					if m.action != nil {
-						m.action()
-					}
-					return m.res
-				}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
-	}
-
-	orc := &GKEOrchestrator{
-		executor: mock,
-	}
-
-	err := orc.CheckAndInstallKueue("", "test-cluster", "us-central1-a")
+	err := orc.CheckAndInstallKueue(context.Background(), "", "test-cluster", "us-central1-a")
 	if err != nil {
 		t.Fatalf("CheckAndInstallKueue failed: %v", err)
 	}
 
-	if !deleteCalled {
-		t.Errorf("expected DeleteAllKueueResources to be called, but it wasn't")
+	if deleted := deletedCRDs(client); !slices.Contains(deleted, "workloads.kueue.x-k8s.io") {
+		t.Errorf("expected DeleteAllKueueResources to delete the Kueue CRDs, deleted %v", deleted)
+	}
+	if got := release.Requests(); len(got) != 1 {
+		t.Errorf("expected the Kueue release manifest to be downloaded once, got %v", got)
 	}
 }
 
-func TestEnsurePriorityClassesInstalled_Missing(t *testing.T) {
-	applyCalled := false
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			fullCmd := name + " " + strings.Join(args, " ")
-			if strings.Contains(fullCmd, "kubectl get priorityclass") {
-				// Return only system priority classes to simulate no user priority classes
-				return shell.CommandResult{ExitCode: 0, Stdout: "system-cluster-critical system-node-critical"}
-			}
-			if strings.Contains(fullCmd, "kubectl apply") && strings.Contains(fullCmd, "priority-classes.yaml") {
-				applyCalled = true
-				return shell.CommandResult{ExitCode: 0}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
+// fakePriorityClasses returns a list of the PriorityClasses names.
+func fakePriorityClasses(names ...string) string {
+	items := make([]string, 0, len(names))
+	for _, name := range names {
+		items = append(items, fmt.Sprintf(`{"metadata": {"name": %q}}`, name))
 	}
+	return fakeList("PriorityClass", "", nil, `{"items": [`+strings.Join(items, ",")+`]}`)
+}
 
-	orc := &GKEOrchestrator{
-		executor: mock,
-	}
+func TestEnsurePriorityClassesInstalled_Missing(t *testing.T) {
+	kube := &MockKubeClient{}
+	orc := &GKEOrchestrator{kubeClient: kube}
+	// Only system priority classes, so no user priority classes.
+	orc.dynClient = newFakeDynamicClient(fakePriorityClasses("system-cluster-critical", "system-node-critical"))
 
-	err := orc.ensurePriorityClassesInstalled()
+	err := orc.ensurePriorityClassesInstalled(context.Background())
 	if err != nil {
 		t.Fatalf("ensurePriorityClassesInstalled failed: %v", err)
 	}
 
-	if !applyCalled {
+	if len(kube.Applied) == 0 || !strings.Contains(kube.Applied[0], "kind: PriorityClass") {
 		t.Errorf("expected priority classes to be installed, but they weren't")
 	}
 }
 
 func TestEnsurePriorityClassesInstalled_Present(t *testing.T) {
-	applyCalled := false
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			fullCmd := name + " " + strings.Join(args, " ")
-			if strings.Contains(fullCmd, "kubectl get priorityclass") {
-				// Return system classes and at least one user class (e.g. 'low') to simulate pre-existing classes
-				return shell.CommandResult{ExitCode: 0, Stdout: "system-cluster-critical system-node-critical low"}
-			}
-			if strings.Contains(fullCmd, "kubectl apply") && strings.Contains(fullCmd, "priority-classes.yaml") {
-				applyCalled = true
-				return shell.CommandResult{ExitCode: 0}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
-	}
+	kube := &MockKubeClient{}
+	orc := &GKEOrchestrator{kubeClient: kube}
+	// System classes and at least one user class (e.g. 'low').
+	orc.dynClient = newFakeDynamicClient(fakePriorityClasses("system-cluster-critical", "system-node-critical", "low"))
 
-	orc := &GKEOrchestrator{
-		executor: mock,
-	}
-
-	err := orc.ensurePriorityClassesInstalled()
+	err := orc.ensurePriorityClassesInstalled(context.Background())
 	if err != nil {
 		t.Fatalf("ensurePriorityClassesInstalled failed: %v", err)
 	}
 
-	if applyCalled {
+	if len(kube.Applied) > 0 {
 		t.Errorf("expected priority classes to be skipped, but they were installed")
 	}
 }
@@ -404,7 +395,7 @@ func TestHandleKueueReinstallation_UserDeclines(t *testing.T) {
 
 	orc := &GKEOrchestrator{}
 
-	err := orc.handleKueueReinstallation("v0.15.2", "Test reason")
+	err := orc.handleKueueReinstallation(context.Background(), "v0.15.2", "Test reason")
 	if err == nil {
 		t.Fatal("expected error when user declines, got nil")
 	}
@@ -416,6 +407,7 @@ func TestHandleKueueReinstallation_UserDeclines(t *testing.T) {
 
 func TestRenderClusterQueue_NAP(t *testing.T) {
 	orc := &GKEOrchestrator{
+		kubeClient: &MockKubeClient{},
 		napEnabled: true,
 		napLimits: map[string]int64{
 			"cpu":            1000,
@@ -455,33 +447,13 @@ func TestRenderClusterQueue_NAP(t *testing.T) {
 }
 
 func TestCheckAndInstallKueue_PermissionDenied(t *testing.T) {
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			fullCmd := name + " " + strings.Join(args, " ")
-			if strings.Contains(fullCmd, "auth can-i") {
-				if strings.Contains(fullCmd, "clusterroles") {
-					return shell.CommandResult{ExitCode: 0, Stdout: "no"}
-				}
-				return shell.CommandResult{ExitCode: 0, Stdout: "yes"}
-			}
-			if strings.Contains(fullCmd, "kubectl get crd") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "clusterqueues.kueue.x-k8s.io found"}
-			}
-			if strings.Contains(fullCmd, "jsonpath") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "registry.k8s.io/kueue/kueue:v0.12.0"}
-			}
-			if strings.Contains(fullCmd, "kubectl get deployment") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "kueue-controller-manager found"}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
-	}
-
 	orc := &GKEOrchestrator{
-		executor: mock,
+		kubeClient: &MockKubeClient{Denied: []string{"create clusterroles.rbac.authorization.k8s.io"}},
 	}
+	client := fakeKueueCluster("v0.12.0")
+	orc.dynClient = client
 
-	err := orc.CheckAndInstallKueue("", "test-cluster", "us-central1-a")
+	err := orc.CheckAndInstallKueue(context.Background(), "", "test-cluster", "us-central1-a")
 	if err == nil {
 		t.Fatal("expected error due to insufficient permissions, got nil")
 	}
@@ -490,89 +462,53 @@ func TestCheckAndInstallKueue_PermissionDenied(t *testing.T) {
 	if !strings.Contains(err.Error(), expectedErr) {
 		t.Errorf("expected error containing %q, got: %v", expectedErr, err)
 	}
+	if deleted := deletedCRDs(client); len(deleted) > 0 {
+		t.Errorf("expected nothing to be deleted without permissions, deleted %v", deleted)
+	}
 }
 
 func TestCheckAndInstallKueue_PermissionGranted(t *testing.T) {
-	deleteCalled := false
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			fullCmd := name + " " + strings.Join(args, " ")
-			if strings.Contains(fullCmd, "auth can-i") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "yes"}
-			}
-			if strings.Contains(fullCmd, "kubectl delete crd") {
-				deleteCalled = true
-				return shell.CommandResult{ExitCode: 0}
-			}
-			if strings.Contains(fullCmd, "kubectl get crd") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "clusterqueues.kueue.x-k8s.io found"}
-			}
-			if strings.Contains(fullCmd, "jsonpath") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "registry.k8s.io/kueue/kueue:v0.12.0"}
-			}
-			if strings.Contains(fullCmd, "kubectl get deployment") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "kueue-controller-manager found"}
-			}
-			if strings.Contains(fullCmd, "kubectl get endpoints") || strings.Contains(fullCmd, "kubectl get endpointslice") {
-				return shell.CommandResult{ExitCode: 0, Stdout: `{"subsets": [{"addresses": [{"ip": "10.0.0.1"}]}]}`}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
-	}
-
 	origPrompt := shell.PromptYesNo
 	defer func() { shell.PromptYesNo = origPrompt }()
 	shell.PromptYesNo = func(prompt string) bool { return true }
 
-	orc := &GKEOrchestrator{
-		executor: mock,
-	}
+	orc := &GKEOrchestrator{kubeClient: &MockKubeClient{}}
+	client := fakeKueueCluster("v0.12.0")
+	orc.dynClient = client
+	release := fakeKueueRelease(t, orc)
 
-	err := orc.CheckAndInstallKueue("", "test-cluster", "us-central1-a")
+	err := orc.CheckAndInstallKueue(context.Background(), "", "test-cluster", "us-central1-a")
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
 
-	if !deleteCalled {
+	if deleted := deletedCRDs(client); len(deleted) == 0 {
 		t.Errorf("expected DeleteAllKueueResources to be called, but it wasn't")
 	}
+	if got := release.Requests(); len(got) != 1 {
+		t.Errorf("expected the Kueue release manifest to be downloaded once, got %v", got)
+	}
 }
 
 func TestValidatePriorityClass_Empty(t *testing.T) {
 	orc := &GKEOrchestrator{}
-	err := orc.validatePriorityClass("")
+	err := orc.validatePriorityClass(context.Background(), "")
 	if err != nil {
 		t.Fatalf("expected no error for empty priority, got %v", err)
 	}
 }
 
 func TestValidatePriorityClass_Exists(t *testing.T) {
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			if strings.Contains(name+" "+strings.Join(args, " "), "kubectl get priorityclass") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "system-cluster-critical low medium high"}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
-	}
-	orc := &GKEOrchestrator{executor: mock}
-	err := orc.validatePriorityClass("medium")
+	orc := &GKEOrchestrator{dynClient: newFakeDynamicClient(fakePriorityClasses("system-cluster-critical", "low", "medium", "high"))}
+	err := orc.validatePriorityClass(context.Background(), "medium")
 	if err != nil {
 		t.Fatalf("expected no error for existing priority, got %v", err)
 	}
 }
 
 func TestValidatePriorityClass_NotExist(t *testing.T) {
-	mock := &mockExecutor{
-		executeCommandFunc: func(name string, args ...string) shell.CommandResult {
-			if strings.Contains(name+" "+strings.Join(args, " "), "kubectl get priorityclass") {
-				return shell.CommandResult{ExitCode: 0, Stdout: "system-cluster-critical low high"}
-			}
-			return shell.CommandResult{ExitCode: 0}
-		},
-	}
-	orc := &GKEOrchestrator{executor: mock}
-	err := orc.validatePriorityClass("medium")
+	orc := &GKEOrchestrator{dynClient: newFakeDynamicClient(fakePriorityClasses("system-cluster-critical", "low", "high"))}
+	err := orc.validatePriorityClass(context.Background(), "medium")
 	if err == nil {
 		t.Fatal("expected error for non-existing priority, got nil")
 	}
@@ -704,3 +640,135 @@ func TestReplaceDeprecatedRbacProxyImage(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureKueueOrNoQueue(t *testing.T) {
+	origPrompt := shell.PromptYesNo
+	defer func() { shell.PromptYesNo = origPrompt }()
+
+	tests := []struct {
+		name        string
+		job         orchestrator.JobDefinition
+		missingCRD  bool
+		wantNoQueue bool
+		wantPrompt  bool
+		wantErr     bool
+	}{
+		{name: "no-queue requested", job: orchestrator.JobDefinition{NoQueue: true}, missingCRD: true, wantNoQueue: true},
+		{name: "Kueue absent and install declined", missingCRD: true, wantNoQueue: true, wantPrompt: true},
+		{name: "Kueue absent with an explicit queue", job: orchestrator.JobDefinition{KueueQueueName: "my-queue"}, missingCRD: true, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			prompted := false
+			shell.PromptYesNo = func(prompt string) bool { prompted = true; return false }
+
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			kube := &MockKubeClient{}
+			if tc.missingCRD {
+				kube.MissingCRDs = []string{"clusterqueues.kueue.x-k8s.io"}
+			}
+			orc.kubeClient = kube
+
+			job := tc.job
+			job.ClusterName = "my-cluster"
+			err := orc.ensureKueueOrNoQueue(context.Background(), &job)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ensureKueueOrNoQueue() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if job.NoQueue != tc.wantNoQueue {
+				t.Errorf("NoQueue = %v, want %v", job.NoQueue, tc.wantNoQueue)
+			}
+			if tc.wantPrompt != prompted && !tc.wantErr {
+				t.Errorf("prompted = %v, want %v", prompted, tc.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestDownloadManifests_Retry(t *testing.T) {
+	const url = "https://github.com/kubernetes-sigs/jobset/releases/download/v0.8.0/manifests.yaml"
+	transport := testutil.NewFakeTransport(map[string]testutil.HTTPResponse{url: {Body: "kind: Namespace\n"}})
+	transport.Queue(url, testutil.HTTPResponse{StatusCode: 503}, testutil.HTTPResponse{Err: errors.New("connection reset by peer")})
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	orc := &GKEOrchestrator{}
+	orc.SetHTTPClient(transport.Client())
+	orc.SetClock(clock)
+
+	got, err := orc.downloadManifests(context.Background(), url)
+	if err != nil || string(got) != "kind: Namespace\n" {
+		t.Fatalf("downloadManifests() = %q, %v", got, err)
+	}
+	if n := len(transport.Requests()); n != 3 {
+		t.Errorf("expected 2 retries of transient failures, got %d requests", n)
+	}
+	if n := len(clock.Sleeps()); n != 2 {
+		t.Errorf("expected 2 backoff waits, got %d", n)
+	}
+
+	transport.Queue(url, testutil.HTTPResponse{StatusCode: 404})
+	if _, err := orc.downloadManifests(context.Background(), url); err == nil || !strings.Contains(err.Error(), "status code 404") {
+		t.Errorf("expected a 404 to fail at once, got %v", err)
+	}
+	if n := len(transport.Requests()); n != 4 {
+		t.Errorf("expected a 404 not to be retried, got %d requests in total", n)
+	}
+}
+
+func TestGetKueueVersion(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	for image, want := range map[string]string{
+		"registry.k8s.io/kueue/kueue:v0.15.2":            "v0.15.2",
+		"localhost:5000/kueue:v0.14.0@" + digest:         "v0.14.0",
+		"registry.k8s.io/kueue/kueue@" + digest:          "",
+		"us-docker.pkg.dev/p/r/kueue-controller-manager": "",
+	} {
+		orc := newTestGKEOrchestrator(&mockExecutor{})
+		orc.dynClient = newFakeDynamicClient(fakeDeployment("kueue-system", "kueue-controller-manager", image))
+		got, err := orc.GetKueueVersion(context.Background())
+		if want == "" {
+			if err == nil {
+				t.Errorf("GetKueueVersion() with image %q = %q, want an error", image, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("GetKueueVersion() with image %q = %q, %v, want %q", image, got, err, want)
+		}
+	}
+
+	orc := newTestGKEOrchestrator(&mockExecutor{})
+	if _, err := orc.GetKueueVersion(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to get Kueue version") {
+		t.Errorf("expected a missing deployment to fail, got %v", err)
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	rolling := `{"metadata": {"generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "replicas": 3, "updatedReplicas": 2, "availableReplicas": 1}}`
+	done := `{"metadata": {"generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "replicas": 2, "updatedReplicas": 2, "availableReplicas": 2}}`
+	stalled := `{"metadata": {"generation": 2}, "status": {"observedGeneration": 2, "conditions": [{"type": "Progressing", "reason": "ProgressDeadlineExceeded"}]}}`
+
+	newOrchestrator := func(objects ...string) (*GKEOrchestrator, *int) {
+		orc := &GKEOrchestrator{}
+		orc.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+		client := newFakeDynamicClient()
+		orc.dynClient = client
+		return orc, fakeGets(client, "Deployment", objects...)
+	}
+
+	orc, gets := newOrchestrator(rolling, rolling, done)
+	if err := orc.waitForRollout(context.Background(), "jobset-system", "jobset-controller-manager", deploymentRolloutRetry); err != nil || *gets != 3 {
+		t.Errorf("waitForRollout() = %v after %d gets, want nil after 3", err, *gets)
+	}
+
+	orc, _ = newOrchestrator(rolling)
+	err := orc.waitForRollout(context.Background(), "jobset-system", "jobset-controller-manager", componentHealthRetry)
+	if err == nil || !strings.Contains(err.Error(), "1 old replicas are pending termination") {
+		t.Errorf("expected the rollout to time out pending old replicas, got %v", err)
+	}
+
+	orc, gets = newOrchestrator(stalled)
+	err = orc.waitForRollout(context.Background(), "jobset-system", "jobset-controller-manager", deploymentRolloutRetry)
+	if err == nil || !strings.Contains(err.Error(), "exceeded its progress deadline") || *gets != 1 {
+		t.Errorf("expected a stalled rollout to fail at once, got %v after %d gets", err, *gets)
+	}
+}
@@ -15,7 +15,7 @@
 package gke
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/orchestrator"
@@ -25,7 +25,9 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
-	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const spacer = "========================================================"
@@ -33,6 +35,7 @@ const spacer = "========================================================"
 type inspectWriter struct {
 	writer   io.Writer
 	executor Executor
+	g        *GKEOrchestrator
 }
 
 func (w *inspectWriter) runAndLog(description string, command string, args ...string) {
@@ -51,17 +54,49 @@ func (w *inspectWriter) runAndLog(description string, command string, args ...st
 	_, _ = fmt.Fprint(w.writer, divider)
 }
 
+// logObject writes the object name of gvr in namespace ns as YAML, or every
+// object of gvr when name is empty.
+func (w *inspectWriter) logObject(ctx context.Context, description string, gvr schema.GroupVersionResource, ns, name string) {
+	var out string
+	var err error
+	if name == "" {
+		out, err = w.g.listYAML(ctx, gvr, ns, metav1.ListOptions{})
+	} else {
+		out, err = w.g.objectYAML(ctx, gvr, ns, name)
+	}
+	w.logOutput(description, out, err)
+}
+
+// logControllerLogs writes the last lines of the logs of the manager
+// container of a controller Deployment.
+func (w *inspectWriter) logControllerLogs(ctx context.Context, description, ns, name string) {
+	out, err := w.g.deploymentLogs(ctx, ns, name, "manager", 100)
+	w.logOutput(description, out, err)
+}
+
+// logOutput writes the output of a diagnostic read from the cluster, or the
+// error that stopped it from being read.
+func (w *inspectWriter) logOutput(description string, output string, err error) {
+	_, _ = fmt.Fprintf(w.writer, "Description: %s\n", description)
+	if err != nil {
+		_, _ = fmt.Fprintf(w.writer, "Error:\n%v\n", err)
+	} else {
+		_, _ = fmt.Fprintf(w.writer, "Output:\n%s\n", output)
+	}
+	_, _ = fmt.Fprintf(w.writer, "\n%s\n\n", spacer)
+}
+
 // InspectCluster runs diagnostic checks on the GKE cluster and writes them to a log file.
-func (g *GKEOrchestrator) InspectCluster(opts orchestrator.InspectOptions) error {
+func (g *GKEOrchestrator) InspectCluster(ctx context.Context, opts orchestrator.InspectOptions) error {
 	// 1. Setup Kubectl (Critical, fail fast)
-	if err := g.configureKubectl(opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
 		return fmt.Errorf("failed to configure kubectl: %w", err)
 	}
 
 	// 2. Create log file (Critical, fail fast)
 	filePath := opts.OutputPath
 	if filePath == "" {
-		timestamp := time.Now().UTC().Format("20060102-150405")
+		timestamp := g.now().UTC().Format("20060102-150405")
 		fileName := fmt.Sprintf("gcluster-inspect-%s-%s.log", opts.ClusterName, timestamp)
 		filePath = filepath.Join(".", fileName)
 	}
@@ -91,6 +126,7 @@ func (g *GKEOrchestrator) InspectCluster(opts orchestrator.InspectOptions) error
 	writer := &inspectWriter{
 		writer:   outputTarget,
 		executor: g.executor,
+		g:        g,
 	}
 
 	// --- 1. Local Setup ---
@@ -104,38 +140,39 @@ func (g *GKEOrchestrator) InspectCluster(opts orchestrator.InspectOptions) error
 	// ConfigMaps (graceful handle if not present)
 	metadataCM := fmt.Sprintf("%s-metadata", opts.ClusterName)
 	resourcesCM := fmt.Sprintf("%s-resources", opts.ClusterName)
-	writer.runAndLog("GKE: Cluster Metadata ConfigMap Details", "kubectl", "get", "configmap", metadataCM, "-n", targetNamespace, "-o", "yaml")
-	writer.runAndLog("GKE: Cluster Resources ConfigMap Details", "kubectl", "get", "configmap", resourcesCM, "-n", targetNamespace, "-o", "yaml")
+	writer.logObject(ctx, "GKE: Cluster Metadata ConfigMap Details", configMapGVR, targetNamespace, metadataCM)
+	writer.logObject(ctx, "GKE: Cluster Resources ConfigMap Details", configMapGVR, targetNamespace, resourcesCM)
 
 	// --- 3. Node Status ---
-	writer.runAndLog("Kubectl: All Nodes", "kubectl", "get", "nodes", "-o", "wide")
+	var nodes kubernetesNodeList
+	nodesErr := g.listResources(ctx, &nodes, nodeGVR, "", metav1.ListOptions{})
+	writer.logOutput("Nodes: All Nodes", nodeTable(nodes), nodesErr)
 
 	// Count nodes per pool (healthy and total)
-	logNodeCounts(outputTarget, g.executor)
+	writer.logOutput("Nodes: Node count analysis", nodeCounts(nodes), nodesErr)
 
 	// --- 4. Kueue & JobSet Resources ---
-	writer.runAndLog("Kueue: ClusterQueue Details", "kubectl", "describe", "ClusterQueue")
-	writer.runAndLog("Kueue: LocalQueue Details", "kubectl", "describe", "LocalQueue", "-A")
-	writer.runAndLog("Kueue: ResourceFlavor Details", "kubectl", "describe", "ResourceFlavor")
-	writer.runAndLog("Kueue: Kueue Deployment Details", "kubectl", "describe", "Deployment", "kueue-controller-manager", "-n", "kueue-system")
-	writer.runAndLog("Kueue: Kueue Controller Manager Logs (tail 100)", "kubectl", "logs", "deployment/kueue-controller-manager", "-n", "kueue-system", "-c", "manager", "--tail=100")
+	writer.logObject(ctx, "Kueue: ClusterQueue Details", clusterQueueGVR, "", "")
+	writer.logObject(ctx, "Kueue: LocalQueue Details", localQueueGVR, "", "")
+	writer.logObject(ctx, "Kueue: ResourceFlavor Details", resourceFlavorGVR, "", "")
+	writer.logObject(ctx, "Kueue: Kueue Deployment Details", deploymentGVR, "kueue-system", "kueue-controller-manager")
+	writer.logControllerLogs(ctx, "Kueue: Kueue Controller Manager Logs (tail 100)", "kueue-system", "kueue-controller-manager")
 
-	writer.runAndLog("JobSet: Deployment Details", "kubectl", "describe", "Deployment", "jobset-controller-manager", "-n", "jobset-system")
-	writer.runAndLog("JobSet: JobSet Controller Manager Logs (tail 100)", "kubectl", "logs", "deployment/jobset-controller-manager", "-n", "jobset-system", "-c", "manager", "--tail=100")
+	writer.logObject(ctx, "JobSet: Deployment Details", deploymentGVR, "jobset-system", "jobset-controller-manager")
+	writer.logControllerLogs(ctx, "JobSet: JobSet Controller Manager Logs (tail 100)", "jobset-system", "jobset-controller-manager")
 
 	// --- 5. Slice Controller (Dynamic Slicing) ---
-	cResult := g.executor.ExecuteCommand("kubectl", "get", "crd", "topologies.kueue.x-k8s.io")
-	if cResult.ExitCode == 0 {
-		writer.runAndLog("Slice Controller: Deployment Details", "kubectl", "describe", "deployment", "slice-controller-controller-manager", "-n", "slice-controller-system")
-		writer.runAndLog("Slice Controller: Logs (tail 100)", "kubectl", "logs", "deployment/slice-controller-controller-manager", "-n", "slice-controller-system", "-c", "manager", "--tail=100")
+	if installed, err := g.crdInstalled(ctx, "topologies.kueue.x-k8s.io"); err == nil && installed {
+		writer.logObject(ctx, "Slice Controller: Deployment Details", deploymentGVR, "slice-controller-system", "slice-controller-controller-manager")
+		writer.logControllerLogs(ctx, "Slice Controller: Logs (tail 100)", "slice-controller-system", "slice-controller-controller-manager")
 	}
 
 	// --- 6. Workloads ---
-	logWorkloadList(outputTarget, g.executor, "EVERYTHING", "", targetNamespace)
-	logWorkloadList(outputTarget, g.executor, "QUEUED", "", targetNamespace)
-	logWorkloadList(outputTarget, g.executor, "RUNNING", "", targetNamespace)
+	g.logWorkloadList(ctx, outputTarget, "EVERYTHING", "", targetNamespace)
+	g.logWorkloadList(ctx, outputTarget, "QUEUED", "", targetNamespace)
+	g.logWorkloadList(ctx, outputTarget, "RUNNING", "", targetNamespace)
 
-	workloadNamespace := g.inspectWorkload(writer, opts.WorkloadName)
+	workloadNamespace := g.inspectWorkload(ctx, writer, opts.WorkloadName)
 
 	// --- 7. Console Links ---
 	logConsoleLinks(outputTarget, opts, workloadNamespace)
@@ -144,47 +181,57 @@ func (g *GKEOrchestrator) InspectCluster(opts orchestrator.InspectOptions) error
 	return nil
 }
 
-func (g *GKEOrchestrator) inspectWorkload(writer *inspectWriter, workloadName string) string {
+func (g *GKEOrchestrator) inspectWorkload(ctx context.Context, writer *inspectWriter, workloadName string) string {
 	workloadNamespace := "default"
 	if workloadName == "" {
 		return workloadNamespace
 	}
 
-	ns, err := g.getJobNamespace(workloadName)
+	ns, err := g.getJobNamespace(ctx, workloadName)
 	if err == nil {
 		workloadNamespace = ns
 	} else {
 		logging.Warn("Failed to auto-discover namespace for workload %s, defaulting to 'default': %v", workloadName, err)
 	}
 
-	logWorkloadList(writer.writer, g.executor, "EVERYTHING", workloadName, workloadNamespace)
+	g.logWorkloadList(ctx, writer.writer, "EVERYTHING", workloadName, workloadNamespace)
 
-	writer.runAndLog(fmt.Sprintf("JobSet: Config for %s", workloadName), "kubectl", "describe", "jobsets", workloadName, "-n", workloadNamespace)
+	writer.logObject(ctx, fmt.Sprintf("JobSet: Config for %s", workloadName), jobSetGVR, workloadNamespace, workloadName)
 
 	targetWorkload := fmt.Sprintf("jobset-%s", workloadName)
 	if g.kubeClient != nil {
-		if tw, err := g.findTargetWorkload(workloadNamespace, workloadName); err == nil {
+		if tw, err := g.findTargetWorkload(ctx, workloadNamespace, workloadName); err == nil {
 			targetWorkload = tw
 		}
 	}
-	writer.runAndLog(fmt.Sprintf("Kueue: Workload config for %s", workloadName), "kubectl", "describe", "workloads", targetWorkload, "-n", workloadNamespace)
+	writer.logObject(ctx, fmt.Sprintf("Kueue: Workload config for %s", workloadName), workloadGVR, workloadNamespace, targetWorkload)
+	g.logAutoscalerEvents(ctx, writer.writer, workloadName, workloadNamespace)
 
 	return workloadNamespace
 }
 
-func logNodeCounts(w io.Writer, exec Executor) {
-	desc := "Kubectl: Node count analysis"
-	_, _ = fmt.Fprintf(w, "Description: %s\n", desc)
-
-	nodeList, err := fetchNodeList(exec)
-	if err != nil {
-		errStr := fmt.Sprintf("Error fetching nodes for analysis: %v\n", err)
-		_, _ = fmt.Fprint(w, errStr)
-		_, _ = fmt.Fprintf(w, "\n%s\n\n", spacer)
-		return
+// nodeTable lists the nodes with their readiness, node pool, zone and
+// machine type.
+func nodeTable(nodes kubernetesNodeList) string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tSTATUS\tNODE POOL\tZONE\tMACHINE TYPE")
+	for _, node := range nodes.Items {
+		status := "NotReady"
+		if node.ready() {
+			status = "Ready"
+		}
+		labels := node.Metadata.Labels
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", node.Metadata.Name, status,
+			labels["cloud.google.com/gke-nodepool"], labels["topology.kubernetes.io/zone"], labels["node.kubernetes.io/instance-type"])
 	}
+	_ = tw.Flush()
+	return sb.String()
+}
 
-	totalNodesPerPool, healthyNodesPerPool := countNodes(nodeList)
+// nodeCounts reports the number of nodes and of healthy nodes per node pool.
+func nodeCounts(nodes kubernetesNodeList) string {
+	totalNodesPerPool, healthyNodesPerPool := countNodes(&nodes)
 
 	// Sort keys for deterministic output
 	var pools []string
@@ -193,8 +240,7 @@ func logNodeCounts(w io.Writer, exec Executor) {
 	}
 	sort.Strings(pools)
 
-	outputStr := "Output:\n"
-	outputStr += "Node Pool Node Counts:\n"
+	outputStr := "Node Pool Node Counts:\n"
 	for _, pool := range pools {
 		outputStr += fmt.Sprintf("  - %s: %d total\n", pool, totalNodesPerPool[pool])
 	}
@@ -202,22 +248,7 @@ func logNodeCounts(w io.Writer, exec Executor) {
 	for _, pool := range pools {
 		outputStr += fmt.Sprintf("  - %s: %d healthy\n", pool, healthyNodesPerPool[pool])
 	}
-
-	_, _ = fmt.Fprint(w, outputStr)
-	_, _ = fmt.Fprintf(w, "\n%s\n\n", spacer)
-}
-
-func fetchNodeList(exec Executor) (*kubernetesNodeList, error) {
-	res := exec.ExecuteCommand("kubectl", "get", "nodes", "-o", "json")
-	if res.ExitCode != 0 {
-		return nil, fmt.Errorf("kubectl get nodes failed (%d): %s", res.ExitCode, res.Stderr)
-	}
-
-	var nodeList kubernetesNodeList
-	if err := json.Unmarshal([]byte(res.Stdout), &nodeList); err != nil {
-		return nil, fmt.Errorf("failed to parse node JSON: %w", err)
-	}
-	return &nodeList, nil
+	return outputStr
 }
 
 func countNodes(nodeList *kubernetesNodeList) (map[string]int, map[string]int) {
@@ -231,20 +262,22 @@ func countNodes(nodeList *kubernetesNodeList) (map[string]int, map[string]int) {
 		}
 		totalNodesPerPool[nodepool]++
 
-		isReady := false
-		for _, cond := range node.Status.Conditions {
-			if cond.Type == "Ready" && cond.Status == "True" {
-				isReady = true
-				break
-			}
-		}
-		if isReady {
+		if node.ready() {
 			healthyNodesPerPool[nodepool]++
 		}
 	}
 	return totalNodesPerPool, healthyNodesPerPool
 }
 
+func (node kubernetesNode) ready() bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == "Ready" && cond.Status == "True" {
+			return true
+		}
+	}
+	return false
+}
+
 func logConsoleLinks(w io.Writer, opts orchestrator.InspectOptions, workloadNamespace string) {
 	desc := "Cloud Console Links"
 	_, _ = fmt.Fprintf(w, "Description: %s\n", desc)
@@ -291,33 +324,17 @@ func logConsoleLinks(w io.Writer, opts orchestrator.InspectOptions, workloadName
 	_, _ = fmt.Fprintf(w, "\n%s\n\n", spacer)
 }
 
-func logWorkloadList(w io.Writer, exec Executor, filterStatus string, filterWorkload string, namespace string) {
-	desc := fmt.Sprintf("Kubectl: List Jobs with filter-by-status=%s", filterStatus)
+func (g *GKEOrchestrator) logWorkloadList(ctx context.Context, w io.Writer, filterStatus string, filterWorkload string, namespace string) {
+	desc := fmt.Sprintf("Kueue: List Jobs with filter-by-status=%s", filterStatus)
 	if filterWorkload != "" {
 		desc += fmt.Sprintf(" with filter-by-job=%s", filterWorkload)
 	}
 
 	_, _ = fmt.Fprintf(w, "Description: %s\n", desc)
 
-	args := []string{"get", "workloads"}
-	if namespace != "" {
-		args = append(args, "-n", namespace)
-	} else {
-		args = append(args, "-A")
-	}
-	args = append(args, "-o", "json")
-	res := exec.ExecuteCommand("kubectl", args...)
-	if res.ExitCode != 0 {
-		errStr := fmt.Sprintf("Error listing workloads (%d):\n%s\n", res.ExitCode, res.Stderr)
-		_, _ = fmt.Fprint(w, errStr)
-		_, _ = fmt.Fprintf(w, "\n%s\n\n", spacer)
-		return
-	}
-
 	var wlList kueueWorkloadList
-	if err := json.Unmarshal([]byte(res.Stdout), &wlList); err != nil {
-		errStr := fmt.Sprintf("Error parsing workloads JSON: %v\n", err)
-		_, _ = fmt.Fprint(w, errStr)
+	if err := g.listResources(ctx, &wlList, workloadGVR, namespace, metav1.ListOptions{}); err != nil {
+		_, _ = fmt.Fprintf(w, "Error listing workloads:\n%v\n", err)
 		_, _ = fmt.Fprintf(w, "\n%s\n\n", spacer)
 		return
 	}
@@ -15,6 +15,8 @@
 package gke
 
 import (
+	"context"
+	"fmt"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
 	"os"
@@ -24,34 +26,79 @@ import (
 )
 
 func defaultMockResponses(clusterName, location, project string) map[string][]shell.CommandResult {
-	workloadsJSON := `{"items": [{"metadata":{"name":"jobset-test-workload","namespace":"custom-namespace","creationTimestamp":"2026-07-10T12:00:00Z","ownerReferences":[{"kind":"JobSet","name":"test-workload"}]},"spec":{"priorityClassName":"high-priority","podSets":[{"count":4}]},"status":{"admission":{"podSetAssignments":[{"count":4}]},"reclaimablePods":[{"count":0}],"conditions":[{"type":"Admitted","status":"True","message":"Admitted by ClusterQueue","lastTransitionTime":"2026-07-10T12:01:00Z"}]}}]}`
-	workloadsResult := shell.CommandResult{ExitCode: 0, Stdout: workloadsJSON}
-
 	return map[string][]shell.CommandResult{
 		"gcloud container clusters get-credentials": {{ExitCode: 0}},
-		"gcloud version":                                                  {{ExitCode: 0, Stdout: "Google Cloud SDK 400.0.0"}},
-		"gcloud config list":                                              {{ExitCode: 0, Stdout: "project = test-project"}},
-		"gcloud container clusters describe":                              {{ExitCode: 0, Stdout: "cluster-description"}},
-		"gcloud container node-pools list":                                {{ExitCode: 0, Stdout: "node-pools"}},
-		"kubectl get configmap":                                           {{ExitCode: 0, Stdout: "configmap-data"}},
-		"kubectl get nodes -o wide":                                       {{ExitCode: 0, Stdout: "nodes-list"}},
-		"kubectl get nodes -o json":                                       {{ExitCode: 0, Stdout: `{"items": [{"metadata":{"name":"node-1","labels":{"cloud.google.com/gke-nodepool":"pool-1"}},"status":{"conditions":[{"type":"Ready","status":"True"}]}}]}`}},
-		"kubectl describe ClusterQueue":                                   {{ExitCode: 0, Stdout: "clusterqueue-details"}},
-		"kubectl describe LocalQueue":                                     {{ExitCode: 0, Stdout: "localqueue-details"}},
-		"kubectl describe ResourceFlavor":                                 {{ExitCode: 0, Stdout: "resourceflavor-details"}},
-		"kubectl describe Deployment kueue-controller-manager":            {{ExitCode: 0, Stdout: "kueue-dep-details"}},
-		"kubectl logs deployment/kueue-controller-manager":                {{ExitCode: 0, Stdout: "kueue-logs"}},
-		"kubectl describe Deployment jobset-controller-manager":           {{ExitCode: 0, Stdout: "jobset-dep-details"}},
-		"kubectl logs deployment/jobset-controller-manager":               {{ExitCode: 0, Stdout: "jobset-logs"}},
-		"kubectl get crd topologies.kueue.x-k8s.io":                       {{ExitCode: 0, Stdout: "topologies-crd"}},
-		"kubectl describe deployment slice-controller-controller-manager": {{ExitCode: 0, Stdout: "slice-dep-details"}},
-		"kubectl logs deployment/slice-controller-controller-manager":     {{ExitCode: 0, Stdout: "slice-logs"}},
-		"kubectl get workloads":                                           {workloadsResult, workloadsResult, workloadsResult, workloadsResult},
-		"kubectl describe jobsets":                                        {{ExitCode: 0, Stdout: "jobset-config"}},
-		"kubectl describe workloads":                                      {{ExitCode: 0, Stdout: "workload-config"}},
+		"gcloud version":                     {{ExitCode: 0, Stdout: "Google Cloud SDK 400.0.0"}},
+		"gcloud config list":                 {{ExitCode: 0, Stdout: "project = test-project"}},
+		"gcloud container clusters describe": {{ExitCode: 0, Stdout: "cluster-description"}},
+		"gcloud container node-pools list":   {{ExitCode: 0, Stdout: "node-pools"}},
+	}
+}
+
+// inspectObjects are the objects of a cluster running the workload
+// test-workload in namespace ns.
+func inspectObjects(clusterName, ns string) []string {
+	controller := func(name, ns string) string {
+		return fmt.Sprintf(`
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: %[1]s, namespace: %[2]s}
+spec:
+  selector: {matchLabels: {control-plane: %[1]s}}
+---
+apiVersion: v1
+kind: Pod
+metadata: {name: %[1]s-abc, namespace: %[2]s, labels: {control-plane: %[1]s}}
+spec: {containers: [{name: manager}, {name: kube-rbac-proxy}]}
+`, name, ns)
+	}
+	return []string{
+		fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: %s-metadata, namespace: %s}
+data: {gke_version: configmap-data}
+---
+apiVersion: v1
+kind: Node
+metadata: {name: node-1, labels: {cloud.google.com/gke-nodepool: pool-1}}
+status: {conditions: [{type: Ready, status: "True"}]}
+---
+apiVersion: kueue.x-k8s.io/v1beta1
+kind: ClusterQueue
+metadata: {name: cluster-queue}
+---
+apiVersion: kueue.x-k8s.io/v1beta1
+kind: LocalQueue
+metadata: {name: multislice-queue, namespace: %s}
+---
+apiVersion: kueue.x-k8s.io/v1beta1
+kind: ResourceFlavor
+metadata: {name: v5p-flavor}
+---
+apiVersion: jobset.x-k8s.io/v1alpha2
+kind: JobSet
+metadata: {name: test-workload, namespace: %s}
+spec: {replicatedJobs: [{name: main-job, replicas: 4}]}
+`, clusterName, ns, ns, ns),
+		controller("kueue-controller-manager", "kueue-system"),
+		controller("jobset-controller-manager", "jobset-system"),
+		controller("slice-controller-controller-manager", "slice-controller-system"),
+		fakeList("Workload", ns, nil, `{"items": [{"metadata":{"name":"jobset-test-workload-abcde","creationTimestamp":"2026-07-10T12:00:00Z","ownerReferences":[{"apiVersion":"jobset.x-k8s.io/v1alpha2","kind":"JobSet","name":"test-workload","uid":"uid-1"}]},"spec":{"priorityClassName":"high-priority","podSets":[{"count":4}]},"status":{"admission":{"podSetAssignments":[{"count":4}]},"reclaimablePods":[{"count":0}],"conditions":[{"type":"Admitted","status":"True","message":"Admitted by ClusterQueue","lastTransitionTime":"2026-07-10T12:01:00Z"}]}}]}`),
+		fakeList("Pod", ns, nil, `{"items": [{"metadata":{"name":"test-workload-main-job-0-0-abcde","labels":{"gcluster.google.com/workload":"test-workload","gcluster.google.com/accelerator":"v5p-32","gcluster.google.com/topology":"2x4x4","gcluster.google.com/provisioning":"spot"}}}]}`),
+		fakeList("Event", ns, nil, `{"items": [`+
+			`{"involvedObject":{"kind":"Pod","name":"test-workload-main-job-0-0-abcde"},"source":{"component":"cluster-autoscaler"},"reason":"NotTriggerScaleUp","message":"pod didn't trigger scale-up: 1 max node group size reached","lastTimestamp":"2026-07-10T12:02:00Z"},`+
+			`{"involvedObject":{"kind":"Pod","name":"other-pod"},"source":{"component":"cluster-autoscaler"},"reason":"TriggeredScaleUp","message":"other-scale-up","lastTimestamp":"2026-07-10T12:03:00Z"}]}`),
 	}
 }
 
+// inspectLogs are the logs of the controllers of inspectObjects.
+var inspectLogs = map[string]string{
+	"kueue-controller-manager-abc/manager":            "kueue-logs\n",
+	"jobset-controller-manager-abc/manager":           "jobset-logs\n",
+	"slice-controller-controller-manager-abc/manager": "slice-logs\n",
+}
+
 func TestInspectCluster_Success(t *testing.T) {
 	clusterName := "test-cluster-success"
 	location := "us-central1-a"
@@ -68,9 +115,11 @@ func TestInspectCluster_Success(t *testing.T) {
 	mockExec := NewMockExecutor(responses)
 	orc := newTestGKEOrchestrator(mockExec)
 	orc.projectID = project
+	orc.dynClient = newFakeDynamicClient(inspectObjects(clusterName, "custom-namespace")...)
 	orc.kubeClient = &MockKubeClient{
 		Namespace: "custom-namespace",
 		Workloads: []string{"jobset-test-workload-abcde"},
+		Logs:      inspectLogs,
 	}
 
 	opts := orchestrator.InspectOptions{
@@ -81,7 +130,7 @@ func TestInspectCluster_Success(t *testing.T) {
 		Show:            false,
 	}
 
-	err := orc.InspectCluster(opts)
+	err := orc.InspectCluster(context.Background(), opts)
 	if err != nil {
 		t.Fatalf("InspectCluster failed: %v", err)
 	}
@@ -112,12 +161,25 @@ func TestInspectCluster_Success(t *testing.T) {
 		"pool-1: 1 total",
 		"Healthy Node Counts Per Node Pool:",
 		"pool-1: 1 healthy",
+		"GKE: Cluster Metadata ConfigMap Details",
+		"gke_version: configmap-data",
+		"Nodes: All Nodes",
+		"node-1  Ready   pool-1",
 		"Kueue: ClusterQueue Details",
-		"clusterqueue-details",
-		"Kubectl: List Jobs with filter-by-status=EVERYTHING",
-		"Kubectl: List Jobs with filter-by-status=QUEUED",
-		"Kubectl: List Jobs with filter-by-status=RUNNING",
-		"Kubectl: List Jobs with filter-by-status=EVERYTHING with filter-by-job=test-workload",
+		"name: cluster-queue",
+		"Kueue: LocalQueue Details",
+		"name: multislice-queue",
+		"Kueue: ResourceFlavor Details",
+		"name: v5p-flavor",
+		"Kueue: Kueue Controller Manager Logs (tail 100)",
+		"[pod/kueue-controller-manager-abc/manager] kueue-logs",
+		"[pod/jobset-controller-manager-abc/manager] jobset-logs",
+		"Slice Controller: Logs (tail 100)",
+		"[pod/slice-controller-controller-manager-abc/manager] slice-logs",
+		"Kueue: List Jobs with filter-by-status=EVERYTHING",
+		"Kueue: List Jobs with filter-by-status=QUEUED",
+		"Kueue: List Jobs with filter-by-status=RUNNING",
+		"Kueue: List Jobs with filter-by-status=EVERYTHING with filter-by-job=test-workload",
 		"Jobset Name",
 		"Created Time",
 		"Priority",
@@ -132,9 +194,12 @@ func TestInspectCluster_Success(t *testing.T) {
 		"high-priority",
 		"Admitted by ClusterQueue",
 		"JobSet: Config for test-workload",
-		"jobset-config",
+		"name: main-job",
 		"Kueue: Workload config for test-workload",
-		"workload-config",
+		"priorityClassName: high-priority",
+		"Autoscaler: Scale-up events for test-workload",
+		"Requested: accelerator=v5p-32, topology=2x4x4, provisioning=spot",
+		"NotTriggerScaleUp  pod didn't trigger scale-up: 1 max node group size reached",
 		"Cloud Console Links",
 		"https://console.cloud.google.com/kubernetes/clusters/details/us-central1-a/test-cluster-success/details?project=test-project",
 		"https://console.cloud.google.com/kubernetes/workload/details/us-central1-a/test-cluster-success/custom-namespace/test-workload?project=test-project",
@@ -145,6 +210,12 @@ func TestInspectCluster_Success(t *testing.T) {
 			t.Errorf("expected log file to contain %q, but it did not.", sub)
 		}
 	}
+	if strings.Contains(content, "other-scale-up") {
+		t.Error("expected autoscaler events of other pods to be filtered out")
+	}
+	if strings.Contains(content, "kube-rbac-proxy") {
+		t.Error("expected only the logs of the manager containers of the controllers")
+	}
 }
 
 func TestInspectCluster_CommandFailure(t *testing.T) {
@@ -177,7 +248,7 @@ func TestInspectCluster_CommandFailure(t *testing.T) {
 		Show:            false,
 	}
 
-	err := orc.InspectCluster(opts)
+	err := orc.InspectCluster(context.Background(), opts)
 	if err != nil {
 		t.Fatalf("InspectCluster failed: %v", err)
 	}
@@ -220,7 +291,7 @@ func TestInspectCluster_CustomOutputPath(t *testing.T) {
 		Show:            false,
 	}
 
-	err := orc.InspectCluster(opts)
+	err := orc.InspectCluster(context.Background(), opts)
 	if err != nil {
 		t.Fatalf("InspectCluster failed: %v", err)
 	}
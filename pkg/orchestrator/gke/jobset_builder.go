@@ -0,0 +1,384 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// The jobset.x-k8s.io/v1alpha2 API objects that gcluster creates. They mirror
+// the fields of the upstream JobSet API that gcluster sets, so that the
+// manifest can be built without depending on the JobSet controller module.
+
+type jobSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              jobSetSpec `json:"spec"`
+}
+
+type jobSetSpec struct {
+	Suspend                 *bool                `json:"suspend,omitempty"`
+	TTLSecondsAfterFinished *int32               `json:"ttlSecondsAfterFinished,omitempty"`
+	Network                 *jobSetNetwork       `json:"network,omitempty"`
+	Coordinator             *jobSetCoordinator   `json:"coordinator,omitempty"`
+	StartupPolicy           *jobSetStartupPolicy `json:"startupPolicy,omitempty"`
+	SuccessPolicy           *jobSetSuccessPolicy `json:"successPolicy,omitempty"`
+	FailurePolicy           *jobSetFailurePolicy `json:"failurePolicy,omitempty"`
+	ReplicatedJobs          []replicatedJob      `json:"replicatedJobs"`
+}
+
+type jobSetNetwork struct {
+	EnableDNSHostnames       *bool  `json:"enableDNSHostnames,omitempty"`
+	Subdomain                string `json:"subdomain,omitempty"`
+	PublishNotReadyAddresses *bool  `json:"publishNotReadyAddresses,omitempty"`
+}
+
+type jobSetCoordinator struct {
+	ReplicatedJob string `json:"replicatedJob"`
+}
+
+type jobSetStartupPolicy struct {
+	StartupPolicyOrder string `json:"startupPolicyOrder"`
+}
+
+type jobSetSuccessPolicy struct {
+	Operator             string   `json:"operator"`
+	TargetReplicatedJobs []string `json:"targetReplicatedJobs,omitempty"`
+}
+
+type jobSetFailurePolicy struct {
+	MaxRestarts     int32                     `json:"maxRestarts"`
+	RestartStrategy string                    `json:"restartStrategy,omitempty"`
+	Rules           []jobSetFailurePolicyRule `json:"rules,omitempty"`
+}
+
+type jobSetFailurePolicyRule struct {
+	Action              string   `json:"action"`
+	OnJobFailureReasons []string `json:"onJobFailureReasons,omitempty"`
+}
+
+type replicatedJob struct {
+	Name     string                  `json:"name"`
+	Replicas int32                   `json:"replicas"`
+	Template batchv1.JobTemplateSpec `json:"template"`
+}
+
+// typedJobSetRenderer renders a JobSet by building its API object and
+// marshalling it, so that user input such as the command, environment and
+// labels is encoded by the YAML marshaller rather than spliced into a template.
+type typedJobSetRenderer struct {
+	build func(jobSetTemplateData) (*jobSet, error)
+}
+
+func (r typedJobSetRenderer) Render(data jobSetTemplateData) (string, error) {
+	js, err := r.build(data)
+	if err != nil {
+		return "", err
+	}
+	return marshalManifest(js)
+}
+
+// buildJobSet returns the JobSet of a workload with one replicated job of
+// NumSlices jobs, each running NodesPerSlice pods.
+func buildJobSet(data jobSetTemplateData) (*jobSet, error) {
+	jobSpec := batchv1.JobSpec{
+		Parallelism:      ptr(int32(data.NodesPerSlice)),
+		Completions:      ptr(int32(data.NodesPerSlice)),
+		BackoffLimit:     ptr(int32(data.PodBackoffLimit)),
+		PodFailurePolicy: data.PodFailurePolicy,
+		Template:         buildPodTemplate(data),
+	}
+
+	var annotations map[string]string
+	if data.ExclusiveTopology != "" {
+		annotations = map[string]string{exclusiveTopologyAnnotation: data.ExclusiveTopology}
+	}
+
+	js := newJobSet(data, annotations)
+	js.Spec.ReplicatedJobs = []replicatedJob{{
+		Name:     "main-job",
+		Replicas: int32(data.NumSlices),
+		Template: batchv1.JobTemplateSpec{Spec: jobSpec},
+	}}
+	return js, nil
+}
+
+// exclusiveTopologyAnnotation places each job of a replicated job on its own
+// domain of a node label.
+const exclusiveTopologyAnnotation = "alpha.jobset.sigs.k8s.io/exclusive-topology"
+
+// newJobSet returns the JobSet of a workload without its replicated jobs. Its
+// annotations are annotations followed by those recording the provenance of
+// the workload.
+func newJobSet(data jobSetTemplateData, annotations map[string]string) *jobSet {
+	labels := map[string]string{"gcluster.google.com/workload": data.WorkloadName}
+	if data.KueueQueueName != "" {
+		labels["kueue.x-k8s.io/queue-name"] = data.KueueQueueName
+	}
+	if v := data.CostLabels["experiment"]; v != "" {
+		labels[experimentLabel] = v
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if data.BaseImageDigest != "" {
+		annotations["gcluster.google.com/base-image"] = data.BaseImage
+		annotations["gcluster.google.com/base-image-digest"] = data.BaseImageDigest
+	}
+	if data.CapturedEnv != "" {
+		annotations["gcluster.google.com/captured-env"] = data.CapturedEnv
+		annotations["gcluster.google.com/captured-env-digest"] = data.CapturedEnvDigest
+	}
+	if data.ManagedProfile != "" {
+		annotations["gcluster.google.com/profile"] = data.ManagedProfile
+	}
+	if data.Inputs != "" {
+		annotations["gcluster.google.com/inputs"] = data.Inputs
+	}
+
+	js := &jobSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "jobset.x-k8s.io/v1alpha2", Kind: "JobSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        data.WorkloadName,
+			Labels:      labels,
+			Annotations: nilIfEmpty(annotations),
+		},
+		Spec: jobSetSpec{
+			TTLSecondsAfterFinished: ptr(int32(data.TtlSecondsAfterFinished)),
+			FailurePolicy: &jobSetFailurePolicy{
+				MaxRestarts: int32(data.MaxRestarts),
+				Rules: []jobSetFailurePolicyRule{{
+					Action:              "FailJobSet",
+					OnJobFailureReasons: []string{batchv1.JobReasonPodFailurePolicy},
+				}},
+			},
+		},
+	}
+	if data.Subdomain != "" {
+		js.Spec.Network = &jobSetNetwork{EnableDNSHostnames: ptr(true), Subdomain: data.Subdomain}
+	}
+	return js
+}
+
+// buildPodTemplate returns the pod template of the workload.
+func buildPodTemplate(data jobSetTemplateData) corev1.PodTemplateSpec {
+	spec := workerPodSpec(data)
+	if data.LogArchiver != nil {
+		spec.InitContainers = append(spec.InitContainers, *data.LogArchiver)
+	}
+	spec.InitContainers = append(spec.InitContainers, sidecarContainers(data)...)
+	spec.Volumes = data.Volumes
+
+	env := workloadEnv(data)
+	for _, cd := range data.Containers {
+		spec.Containers = append(spec.Containers, corev1.Container{
+			Name:           cd.Name,
+			Image:          data.FullImageName,
+			Command:        data.Command,
+			Args:           data.Args,
+			Env:            env,
+			Resources:      cd.Resources,
+			VolumeMounts:   data.VolumeMounts,
+			StartupProbe:   data.StartupProbe,
+			ReadinessProbe: data.ReadinessProbe,
+		})
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: podLabels(data), Annotations: workerPodAnnotations(data)},
+		Spec:       spec,
+	}
+}
+
+// podLabels returns the labels of the workload's pods, which attribute their
+// cost and resources.
+func podLabels(data jobSetTemplateData) map[string]string {
+	labels := map[string]string{"gcluster.google.com/workload": data.WorkloadName}
+	for _, key := range []string{"team", "experiment", "user"} {
+		if v := data.CostLabels[key]; v != "" {
+			labels[key] = v
+		}
+	}
+	for _, key := range []string{"accelerator", "topology", "provisioning"} {
+		if v := data.ResourceLabels[key]; v != "" {
+			labels["gcluster.google.com/"+key] = v
+		}
+	}
+	return labels
+}
+
+// workerPodAnnotations returns the annotations of the pods running the
+// workload on its accelerator nodes.
+func workerPodAnnotations(data jobSetTemplateData) map[string]string {
+	annotations := maps.Clone(data.TopologyAnnotations)
+	if data.GCSFuseEnabled {
+		annotations = withGCSFuseAnnotation(annotations)
+	}
+	return nilIfEmpty(annotations)
+}
+
+// workerPodSpec returns the pod spec of the pods running the workload on its
+// accelerator nodes, without their containers and volumes.
+func workerPodSpec(data jobSetTemplateData) corev1.PodSpec {
+	spec := corev1.PodSpec{
+		TerminationGracePeriodSeconds: ptr(int64(data.TerminationGracePeriodSeconds)),
+		SchedulerName:                 data.SchedulerName,
+		SchedulingGates:               data.SchedulingGates,
+		PriorityClassName:             data.PriorityClassName,
+		RestartPolicy:                 corev1.RestartPolicy(data.RestartPolicy),
+		NodeSelector:                  data.NodeSelector,
+		Affinity:                      data.Affinity,
+		Tolerations:                   data.Tolerations,
+		ImagePullSecrets:              data.ImagePullSecrets,
+		ServiceAccountName:            data.ServiceAccountName,
+	}
+	if data.HostNetworkEnabled {
+		spec.HostNetwork = true
+		spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	}
+	return spec
+}
+
+// workloadEnv returns the environment of the workload containers.
+func workloadEnv(data jobSetTemplateData) []corev1.EnvVar {
+	env := podEnv(data.Env)
+	if data.Verbose {
+		env = append(env, verboseEnv(data.IsTPU, data.IsGPU)...)
+	}
+	return env
+}
+
+// sidecarContainers returns the --sidecar containers of the workload as
+// native sidecars sharing its environment and volume mounts.
+func sidecarContainers(data jobSetTemplateData) []corev1.Container {
+	var containers []corev1.Container
+	for _, s := range data.Sidecars {
+		c := corev1.Container{
+			Name:          s.Name,
+			Image:         s.Image,
+			RestartPolicy: ptr(corev1.ContainerRestartPolicyAlways),
+			Env:           podEnv(data.Env),
+			VolumeMounts:  data.VolumeMounts,
+		}
+		if s.Command != "" {
+			c.Command = []string{"/bin/sh", "-c", s.Command}
+		}
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+// withGCSFuseAnnotation returns annotations with the annotation that injects
+// the Cloud Storage FUSE sidecar.
+func withGCSFuseAnnotation(annotations map[string]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["gke-gcsfuse/volumes"] = "true"
+	return annotations
+}
+
+// podEnv returns the container environment of env, in its order.
+func podEnv(env []EnvVar) []corev1.EnvVar {
+	var res []corev1.EnvVar
+	for _, e := range env {
+		v := corev1.EnvVar{Name: e.Name, Value: e.Value}
+		switch {
+		case e.FieldPath != "":
+			v = corev1.EnvVar{Name: e.Name, ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: e.FieldPath}}}
+		case e.SecretName != "":
+			v = corev1.EnvVar{Name: e.Name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: e.SecretName}, Key: e.SecretKey}}}
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+// verboseEnv returns the environment that raises the log level of the
+// accelerator runtime.
+func verboseEnv(isTPU, isGPU bool) []corev1.EnvVar {
+	switch {
+	case isTPU:
+		return []corev1.EnvVar{
+			{Name: "TPU_STDERR_LOG_LEVEL", Value: "0"},
+			{Name: "TPU_MIN_LOG_LEVEL", Value: "0"},
+			{Name: "TF_CPP_MIN_LOG_LEVEL", Value: "0"},
+			{Name: "TPU_VMODULE", Value: "real_program_continuator=1"},
+		}
+	case isGPU:
+		return []corev1.EnvVar{{Name: "NCCL_DEBUG", Value: "INFO"}}
+	}
+	return nil
+}
+
+// marshalManifest marshals a Kubernetes API object to YAML. Null fields, such
+// as the creationTimestamp of an object that was never stored, are left out.
+func marshalManifest(obj any) (string, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if b, err = json.Marshal(dropNulls(v)); err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	y, err := k8syaml.JSONToYAML(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return string(y), nil
+}
+
+func dropNulls(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			if e == nil {
+				delete(t, k)
+				continue
+			}
+			t[k] = dropNulls(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = dropNulls(e)
+		}
+	}
+	return v
+}
+
+func nilIfEmpty(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	corev1 "k8s.io/api/core/v1"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// decodeJobSet decodes a rendered JobSet manifest, failing the test on fields
+// the JobSet types do not know.
+func decodeJobSet(t *testing.T, manifest string) *jobSet {
+	t.Helper()
+	var js jobSet
+	if err := k8syaml.UnmarshalStrict([]byte(manifest), &js); err != nil {
+		t.Fatalf("failed to decode JobSet manifest: %v\n%s", err, manifest)
+	}
+	if len(js.Spec.ReplicatedJobs) == 0 {
+		t.Fatalf("JobSet manifest has no replicated jobs:\n%s", manifest)
+	}
+	return &js
+}
+
+// decodePodSpec returns the pod spec of the first replicated job of a
+// rendered JobSet manifest.
+func decodePodSpec(t *testing.T, manifest string) corev1.PodSpec {
+	t.Helper()
+	return decodeJobSet(t, manifest).Spec.ReplicatedJobs[0].Template.Spec.Template.Spec
+}
+
+func TestTypedJobSetRenderer_RoundTripsUserInput(t *testing.T) {
+	data := goldenTemplateData(t)
+	hostile := "echo \"a\" 'b' $HOME `id`\n- name: injected\n  image: evil:latest\n#}}{{ .WorkloadName }}: x"
+	data.Command = []string{"/bin/bash", "-c", hostile}
+	data.Args = nil
+	data.Env = []EnvVar{{Name: "MSG", Value: "line1\nline2: \"quoted\" # not a comment"}}
+	data.CostLabels = map[string]string{"team": "yes"}
+	data.Inputs = `[{"name":"x","uri":"gs://b/o: y"}]`
+
+	got, err := typedJobSetRenderer{build: buildJobSet}.Render(data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	assertValidYAML(t, got)
+
+	js := decodeJobSet(t, got)
+	pod := js.Spec.ReplicatedJobs[0].Template.Spec.Template
+	if len(pod.Spec.Containers) != len(data.Containers) {
+		t.Fatalf("got %d containers, want %d", len(pod.Spec.Containers), len(data.Containers))
+	}
+	c := pod.Spec.Containers[0]
+	if !reflect.DeepEqual(c.Command, data.Command) {
+		t.Errorf("command = %q, want %q", c.Command, data.Command)
+	}
+	if len(c.Env) == 0 || c.Env[0].Value != data.Env[0].Value {
+		t.Errorf("env = %+v, want first value %q", c.Env, data.Env[0].Value)
+	}
+	if got := pod.Labels["team"]; got != "yes" {
+		t.Errorf("team label = %q, want %q", got, "yes")
+	}
+	if got := js.Annotations["gcluster.google.com/inputs"]; got != data.Inputs {
+		t.Errorf("inputs annotation = %q, want %q", got, data.Inputs)
+	}
+}
+
+func TestTypedJobSetRenderer_Minimal(t *testing.T) {
+	data := jobSetTemplateData{
+		WorkloadName:    "minimal",
+		Containers:      []ContainerData{{Name: "workload-container"}},
+		FullImageName:   "busybox",
+		Command:         []string{"true"},
+		RestartPolicy:   "Never",
+		NumSlices:       1,
+		NodesPerSlice:   1,
+		MaxRestarts:     0,
+		PodBackoffLimit: 0,
+	}
+	got, err := typedJobSetRenderer{build: buildJobSet}.Render(data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, unwanted := range []string{"null", "annotations", "network", "creationTimestamp"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("minimal manifest contains %q:\n%s", unwanted, got)
+		}
+	}
+	pod := decodePodSpec(t, got)
+	if pod.RestartPolicy != corev1.RestartPolicyNever || pod.Containers[0].Image != "busybox" {
+		t.Errorf("unexpected pod spec %+v", pod)
+	}
+}
+
+func TestTypedJobSetRenderers_ReplicatedJobs(t *testing.T) {
+	pathways := goldenPathwaysTemplateData(t)
+	headless := goldenPathwaysTemplateData(t)
+	headless.Pathways.Headless = true
+	for _, tc := range []struct {
+		name     string
+		kind     string
+		data     jobSetTemplateData
+		jobs     []string
+		headPods []string // Containers of the first replicated job.
+	}{
+		{"mpi", orchestrator.WorkloadKindMPI, goldenTemplateData(t), []string{"launcher", "worker"}, []string{"launcher"}},
+		{"pathways", pathwaysRenderer, pathways, []string{"pathways-head", "worker"}, []string{"workload-container"}},
+		{"headless pathways", pathwaysRenderer, headless, []string{"pathways-head", "worker"}, []string{"pathways-proxy", "pathways-rm"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := manifestRenderers[tc.kind].Render(tc.data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			js := decodeJobSet(t, got)
+			var jobs []string
+			for _, j := range js.Spec.ReplicatedJobs {
+				jobs = append(jobs, j.Name)
+			}
+			if !reflect.DeepEqual(jobs, tc.jobs) {
+				t.Errorf("replicated jobs = %v, want %v", jobs, tc.jobs)
+			}
+			var containers []string
+			for _, c := range js.Spec.ReplicatedJobs[0].Template.Spec.Template.Spec.Containers {
+				containers = append(containers, c.Name)
+			}
+			if !reflect.DeepEqual(containers, tc.headPods) {
+				t.Errorf("containers of %s = %v, want %v", jobs[0], containers, tc.headPods)
+			}
+			worker := js.Spec.ReplicatedJobs[1].Template.Spec.Template.Spec
+			if !reflect.DeepEqual(worker.Tolerations, tc.data.Tolerations) || !reflect.DeepEqual(worker.NodeSelector, tc.data.NodeSelector) {
+				t.Errorf("workers are not scheduled as the workload: %+v", worker)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/retry"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// fieldManager identifies gcluster as the owner of the fields it applies.
+const fieldManager = "gcluster"
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// ManifestApplyError reports the object of a manifest that could not be applied.
+// The underlying API error is available through errors.As / apierrors helpers.
+type ManifestApplyError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Err       error
+}
+
+func (e *ManifestApplyError) Error() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + e.Name
+	}
+	return fmt.Sprintf("failed to apply %s %s: %v", e.Kind, name, e.Err)
+}
+
+func (e *ManifestApplyError) Unwrap() error {
+	return e.Err
+}
+
+// newDefaultKubeClient builds the dynamic client and the API resource lookup
+// used to resolve the kinds of applied manifests.
+func newDefaultKubeClient(config *rest.Config) (*DefaultKubeClient, error) {
+	dynClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	restClient, err := rest.UnversionedRESTClientFor(dynamic.ConfigFor(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REST client: %w", err)
+	}
+	return &DefaultKubeClient{
+		dynClient:  dynClient,
+		restClient: restClient,
+		config:     config,
+		resources: &apiResourceCache{lookup: func(ctx context.Context, gv schema.GroupVersion) (*metav1.APIResourceList, error) {
+			path := "/apis/" + gv.String()
+			if gv.Group == "" {
+				path = "/api/" + gv.Version
+			}
+			raw, err := restClient.Get().AbsPath(path).Do(ctx).Raw()
+			if err != nil {
+				return nil, err
+			}
+			var list metav1.APIResourceList
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, fmt.Errorf("failed to parse API resources for %s: %w", gv, err)
+			}
+			return &list, nil
+		}},
+	}, nil
+}
+
+// apiResourceCache resolves kinds to API resources from the server's discovery
+// documents, fetching each group version once.
+type apiResourceCache struct {
+	lookup func(ctx context.Context, gv schema.GroupVersion) (*metav1.APIResourceList, error)
+	cache  map[schema.GroupVersion]*metav1.APIResourceList
+}
+
+// discoveryRetry covers the few seconds a freshly applied CRD takes to be served.
+var discoveryRetry = retry.Policy{Attempts: 5, Initial: 500 * time.Millisecond, Multiplier: 2, Retryable: apierrors.IsNotFound}
+
+// apiRetry retries API calls that failed for a transient reason.
+var apiRetry = retry.Policy{Attempts: 4, Initial: 10 * time.Millisecond, Multiplier: 5, Jitter: 0.1, Retryable: isRetryableAPIError}
+
+// resolve returns the resource for a kind and whether it is namespaced.
+func (c *apiResourceCache) resolve(ctx context.Context, gvk schema.GroupVersionKind) (schema.GroupVersionResource, bool, error) {
+	gv := gvk.GroupVersion()
+	var found *metav1.APIResource
+	err := retry.Do(ctx, discoveryRetry, func() error {
+		list, ok := c.cache[gv]
+		if !ok || findAPIResource(list, gvk.Kind) == nil {
+			fetched, err := c.lookup(ctx, gv)
+			if err != nil {
+				return err
+			}
+			if c.cache == nil {
+				c.cache = map[schema.GroupVersion]*metav1.APIResourceList{}
+			}
+			c.cache[gv], list = fetched, fetched
+		}
+		if found = findAPIResource(list, gvk.Kind); found == nil {
+			return apierrors.NewNotFound(gv.WithResource("").GroupResource(), gvk.Kind)
+		}
+		return nil
+	})
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("no API resource serves %s: %w", gvk, err)
+	}
+	return gv.WithResource(found.Name), found.Namespaced, nil
+}
+
+func findAPIResource(list *metav1.APIResourceList, kind string) *metav1.APIResource {
+	for i, r := range list.APIResources {
+		// Subresources such as "jobsets/status" share the kind of their parent.
+		if r.Kind == kind && !strings.Contains(r.Name, "/") {
+			return &list.APIResources[i]
+		}
+	}
+	return nil
+}
+
+// isRetryableAPIError reports whether an API call failed for a transient
+// reason, such as a busy API server or a webhook that is still starting.
+func isRetryableAPIError(err error) bool {
+	return apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsConflict(err)
+}
+
+// ApplyManifests server-side applies every object of a multi-document YAML
+// manifest, in order. Objects without a namespace are placed in the current
+// kubeconfig namespace when their resource is namespaced. When ctx is done
+// before every object is applied, the objects applied so far are deleted
+// again, so a canceled submission does not leave half a workload behind.
+func (d *DefaultKubeClient) ApplyManifests(ctx context.Context, manifests []byte) error {
+	if d.resources == nil {
+		return fmt.Errorf("kube client has no API resource lookup configured")
+	}
+	defaultNS, _ := d.GetCurrentNamespace()
+
+	var applied []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifests), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return d.rollBack(ctx, applied, err)
+		}
+		if err := d.applyObject(ctx, obj, defaultNS); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return d.rollBack(ctx, applied, ctxErr)
+			}
+			return &ManifestApplyError{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Err: err}
+		}
+		applied = append(applied, obj)
+	}
+}
+
+// rollBack deletes the applied objects of a manifest whose apply was
+// interrupted by cause, newest first, and returns an error wrapping cause.
+// The deletes outlive the cancellation of ctx.
+func (d *DefaultKubeClient) rollBack(ctx context.Context, applied []*unstructured.Unstructured, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	if len(applied) == 0 {
+		return fmt.Errorf("manifest apply canceled before any object was applied: %w", cause)
+	}
+	logging.Info("Manifest apply canceled; deleting the %d object(s) applied so far...", len(applied))
+	var failed []string
+	for i := len(applied) - 1; i >= 0; i-- {
+		obj := applied[i]
+		resource, err := d.resourceFor(ctx, obj, "")
+		if err == nil {
+			err = resource.Delete(ctx, obj.GetName(), metav1.DeleteOptions{DryRun: d.dryRunOption()})
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			failed = append(failed, fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName()))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("manifest apply canceled, and %s could not be deleted: %w", strings.Join(failed, ", "), cause)
+	}
+	return fmt.Errorf("manifest apply canceled; the %d object(s) applied so far were deleted: %w", len(applied), cause)
+}
+
+// resourceFor returns the client of the resource of obj. A namespaced object
+// without a namespace is placed in defaultNS.
+func (d *DefaultKubeClient) resourceFor(ctx context.Context, obj *unstructured.Unstructured, defaultNS string) (dynamic.ResourceInterface, error) {
+	gvr, namespaced, err := d.resources.resolve(ctx, obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if !namespaced {
+		return d.dynClient.Resource(gvr), nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(defaultNS)
+	}
+	return d.dynClient.Resource(gvr).Namespace(obj.GetNamespace()), nil
+}
+
+func (d *DefaultKubeClient) applyObject(ctx context.Context, obj *unstructured.Unstructured, defaultNS string) error {
+	resource, err := d.resourceFor(ctx, obj, defaultNS)
+	if err != nil {
+		return err
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode object: %w", err)
+	}
+	force := true
+	return retry.Do(ctx, apiRetry, func() error {
+		_, err := resource.Patch(ctx, obj.GetName(), types.ApplyPatchType, data,
+			metav1.PatchOptions{FieldManager: fieldManager, Force: &force, DryRun: d.dryRunOption()})
+		return err
+	})
+}
+
+// CRDInstalled reports whether the named CustomResourceDefinition exists.
+func (d *DefaultKubeClient) CRDInstalled(ctx context.Context, name string) (bool, error) {
+	var installed bool
+	err := retry.Do(ctx, apiRetry, func() error {
+		_, err := d.dynClient.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			installed = true
+		case apierrors.IsNotFound(err):
+			installed = false
+		default:
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to look up CRD %s: %w", name, err)
+	}
+	return installed, nil
+}
+
+// DeleteServices deletes the Services of namespace that match labelSelector.
+// Services do not support deleting a collection, so they are deleted one by
+// one; one deleted meanwhile is not an error.
+func (d *DefaultKubeClient) DeleteServices(ctx context.Context, namespace string, labelSelector string) error {
+	list, err := d.dynClient.Resource(serviceGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
+	}
+	for _, svc := range list.Items {
+		err := d.dynClient.Resource(serviceGVR).Namespace(namespace).Delete(ctx, svc.GetName(), metav1.DeleteOptions{DryRun: d.dryRunOption()})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete service %s in namespace %s: %w", svc.GetName(), namespace, err)
+		}
+	}
+	return nil
+}
+
+// PodLogs opens the log stream of a container of a pod.
+func (d *DefaultKubeClient) PodLogs(ctx context.Context, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	if d.restClient == nil {
+		return nil, errNoRESTClient
+	}
+	req := d.restClient.Get().AbsPath("/api/v1/namespaces", namespace, "pods", pod, "log")
+	if opts.Container != "" {
+		req = req.Param("container", opts.Container)
+	}
+	if opts.Follow {
+		req = req.Param("follow", "true")
+	}
+	if opts.Timestamps {
+		req = req.Param("timestamps", "true")
+	}
+	if opts.SinceSeconds != nil {
+		req = req.Param("sinceSeconds", strconv.FormatInt(*opts.SinceSeconds, 10))
+	}
+	if opts.SinceTime != nil {
+		req = req.Param("sinceTime", opts.SinceTime.UTC().Format(time.RFC3339))
+	}
+	if opts.TailLines != nil {
+		req = req.Param("tailLines", strconv.FormatInt(*opts.TailLines, 10))
+	}
+	return req.Stream(ctx)
+}
+
+// PortForward forwards local ports to a pod until ctx is done, reporting the
+// forwarded ports on stdout.
+func (d *DefaultKubeClient) PortForward(ctx context.Context, namespace, pod string, ports []string) error {
+	if d.restClient == nil || d.config == nil {
+		return errNoRESTClient
+	}
+	if d.dryRun {
+		return fmt.Errorf("port forwarding is not available in read-only mode")
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(d.config)
+	if err != nil {
+		return fmt.Errorf("failed to create the port-forward transport: %w", err)
+	}
+	url := d.restClient.Post().AbsPath("/api/v1/namespaces", namespace, "pods", pod, "portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+	fw, err := portforward.New(dialer, ports, ctx.Done(), nil, os.Stdout, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to forward ports to pod %s/%s: %w", namespace, pod, err)
+	}
+	return fw.ForwardPorts()
+}
+
+// RawGet returns the body of a GET of an API server path.
+func (d *DefaultKubeClient) RawGet(ctx context.Context, path string) ([]byte, error) {
+	if d.restClient == nil {
+		return nil, errNoRESTClient
+	}
+	return d.restClient.Get().AbsPath(path).DoRaw(ctx)
+}
+
+var selfSubjectAccessReviewGVR = schema.GroupVersionResource{Group: "authorization.k8s.io", Version: "v1", Resource: "selfsubjectaccessreviews"}
+
+// CanI reports whether the current user may perform verb on resource, given
+// as "<resource>[.<group>]", in the current namespace.
+func (d *DefaultKubeClient) CanI(ctx context.Context, verb, resource string) (bool, error) {
+	ns, _ := d.GetCurrentNamespace()
+	name, group, _ := strings.Cut(resource, ".")
+	review := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "authorization.k8s.io/v1",
+		"kind":       "SelfSubjectAccessReview",
+		"spec": map[string]interface{}{"resourceAttributes": map[string]interface{}{
+			"namespace": ns, "verb": verb, "group": group, "resource": name,
+		}},
+	}}
+	res, err := d.dynClient.Resource(selfSubjectAccessReviewGVR).Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %s %s is allowed: %w", verb, resource, err)
+	}
+	allowed, _, _ := unstructured.NestedBool(res.Object, "status", "allowed")
+	return allowed, nil
+}
+
+// errNoRESTClient is returned by the calls that need a REST client when the
+// kube client was built around an injected dynamic client only.
+var errNoRESTClient = errors.New("kube client has no REST client configured")
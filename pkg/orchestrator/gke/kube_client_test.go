@@ -0,0 +1,259 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// fakeDynamic records the calls ApplyManifests and CRDInstalled make, and
+// runs afterPatch after every successful patch. Methods
+// it does not override panic through the nil embedded interfaces.
+type fakeDynamic struct {
+	dynamic.Interface
+	calls      []string
+	getErr     error
+	patchErr   []error
+	items      []unstructured.Unstructured
+	afterPatch func()
+}
+
+func (f *fakeDynamic) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &fakeResource{f: f, gvr: gvr}
+}
+
+type fakeResource struct {
+	dynamic.NamespaceableResourceInterface
+	f   *fakeDynamic
+	gvr schema.GroupVersionResource
+	ns  string
+}
+
+func (r *fakeResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &fakeResource{f: r.f, gvr: r.gvr, ns: ns}
+}
+
+func (r *fakeResource) Patch(_ context.Context, name string, pt types.PatchType, _ []byte, opts metav1.PatchOptions, _ ...string) (*unstructured.Unstructured, error) {
+	r.f.calls = append(r.f.calls, fmt.Sprintf("%s %s %s/%s by %s", pt, r.gvr.Resource, r.ns, name, opts.FieldManager))
+	if len(r.f.patchErr) > 0 {
+		err := r.f.patchErr[0]
+		r.f.patchErr = r.f.patchErr[1:]
+		return nil, err
+	}
+	if r.f.afterPatch != nil {
+		r.f.afterPatch()
+	}
+	return &unstructured.Unstructured{}, nil
+}
+
+func (r *fakeResource) Delete(_ context.Context, name string, _ metav1.DeleteOptions, _ ...string) error {
+	r.f.calls = append(r.f.calls, fmt.Sprintf("delete %s %s/%s", r.gvr.Resource, r.ns, name))
+	return nil
+}
+
+func (r *fakeResource) Get(_ context.Context, name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	r.f.calls = append(r.f.calls, fmt.Sprintf("get %s %s", r.gvr.Resource, name))
+	return &unstructured.Unstructured{}, r.f.getErr
+}
+
+func (r *fakeResource) List(_ context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.f.calls = append(r.f.calls, fmt.Sprintf("list %s %s", r.gvr.Resource, opts.FieldSelector))
+	return &unstructured.UnstructuredList{Items: r.f.items}, nil
+}
+
+func testResourceCache(lookups *int) *apiResourceCache {
+	return &apiResourceCache{lookup: func(_ context.Context, gv schema.GroupVersion) (*metav1.APIResourceList, error) {
+		*lookups++
+		switch gv.String() {
+		case "v1":
+			return &metav1.APIResourceList{APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace"},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			}}, nil
+		case "jobset.x-k8s.io/v1alpha2":
+			return &metav1.APIResourceList{APIResources: []metav1.APIResource{
+				{Name: "jobsets/status", Kind: "JobSet", Namespaced: true},
+				{Name: "jobsets", Kind: "JobSet", Namespaced: true},
+			}}, nil
+		}
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, gv.String())
+	}}
+}
+
+func TestAPIResourceCacheResolve(t *testing.T) {
+	oldRetry := discoveryRetry
+	t.Cleanup(func() { discoveryRetry = oldRetry })
+	discoveryRetry.Initial = 0
+
+	lookups := 0
+	cache := testResourceCache(&lookups)
+
+	gvr, namespaced, err := cache.resolve(context.Background(), schema.GroupVersionKind{Group: "jobset.x-k8s.io", Version: "v1alpha2", Kind: "JobSet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gvr.Resource != "jobsets" || !namespaced {
+		t.Errorf("resolve(JobSet) = %v, %v; want jobsets, namespaced", gvr, namespaced)
+	}
+	if _, _, err := cache.resolve(context.Background(), schema.GroupVersionKind{Group: "jobset.x-k8s.io", Version: "v1alpha2", Kind: "JobSet"}); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 1 {
+		t.Errorf("expected discovery to be cached, got %d lookups", lookups)
+	}
+
+	if _, _, err := cache.resolve(context.Background(), schema.GroupVersionKind{Group: "kueue.x-k8s.io", Version: "v1beta2", Kind: "ClusterQueue"}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected a NotFound error for an unserved kind, got %v", err)
+	}
+}
+
+func TestDefaultKubeClientApplyManifests(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	manifests := []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: team-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: jobset.x-k8s.io/v1alpha2
+kind: JobSet
+metadata:
+  name: train
+  namespace: team-a
+`)
+
+	lookups := 0
+	dyn := &fakeDynamic{patchErr: []error{apierrors.NewTooManyRequests("busy", 0)}}
+	client := &DefaultKubeClient{dynClient: dyn, resources: testResourceCache(&lookups)}
+	if err := client.ApplyManifests(context.Background(), manifests); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"application/apply-patch+yaml namespaces /team-a by gcluster",
+		"application/apply-patch+yaml namespaces /team-a by gcluster",
+		"application/apply-patch+yaml configmaps default/settings by gcluster",
+		"application/apply-patch+yaml jobsets team-a/train by gcluster",
+	}
+	if !reflect.DeepEqual(dyn.calls, want) {
+		t.Errorf("calls = %q, want %q (first patch is retried after a throttling error)", dyn.calls, want)
+	}
+}
+
+func TestDefaultKubeClientApplyManifests_TypedError(t *testing.T) {
+	lookups := 0
+	dyn := &fakeDynamic{patchErr: []error{apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "team-a", errors.New("denied"))}}
+	client := &DefaultKubeClient{dynClient: dyn, resources: testResourceCache(&lookups)}
+
+	err := client.ApplyManifests(context.Background(), []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: team-a\n"))
+	var applyErr *ManifestApplyError
+	if !errors.As(err, &applyErr) || applyErr.Kind != "Namespace" || applyErr.Name != "team-a" {
+		t.Fatalf("expected a ManifestApplyError for Namespace team-a, got %v", err)
+	}
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("expected the API error to be preserved, got %v", err)
+	}
+	if len(dyn.calls) != 1 {
+		t.Errorf("expected a forbidden apply not to be retried, got %d calls", len(dyn.calls))
+	}
+}
+
+func TestDefaultKubeClientApplyManifests_RollsBackOnCancel(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	patches := 0
+	lookups := 0
+	dyn := &fakeDynamic{afterPatch: func() {
+		if patches++; patches == 2 {
+			cancel()
+		}
+	}}
+	client := &DefaultKubeClient{dynClient: dyn, resources: testResourceCache(&lookups)}
+	err := client.ApplyManifests(ctx, []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: team-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: team-a
+---
+apiVersion: jobset.x-k8s.io/v1alpha2
+kind: JobSet
+metadata:
+  name: train
+  namespace: team-a
+`))
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "2 object(s) applied so far were deleted") {
+		t.Fatalf("ApplyManifests() error = %v, want a cancellation after a rollback", err)
+	}
+
+	want := []string{
+		"application/apply-patch+yaml namespaces /team-a by gcluster",
+		"application/apply-patch+yaml configmaps team-a/settings by gcluster",
+		"delete configmaps team-a/settings",
+		"delete namespaces /team-a",
+	}
+	if !reflect.DeepEqual(dyn.calls, want) {
+		t.Errorf("calls = %q, want %q", dyn.calls, want)
+	}
+}
+
+func TestDefaultKubeClientCRDInstalled(t *testing.T) {
+	tests := []struct {
+		name    string
+		getErr  error
+		want    bool
+		wantErr bool
+	}{
+		{name: "installed", want: true},
+		{name: "missing", getErr: apierrors.NewNotFound(crdGVR.GroupResource(), "jobsets.jobset.x-k8s.io")},
+		{name: "forbidden", getErr: apierrors.NewForbidden(crdGVR.GroupResource(), "jobsets.jobset.x-k8s.io", errors.New("denied")), wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &DefaultKubeClient{dynClient: &fakeDynamic{getErr: tc.getErr}}
+			got, err := client.CRDInstalled(context.Background(), "jobsets.jobset.x-k8s.io")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("CRDInstalled() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("CRDInstalled() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"hpc-toolkit/pkg/retry"
+)
+
+// Resources the orchestrator reads and writes through the dynamic client.
+var (
+	podGVR            = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	eventGVR          = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+	nodeGVR           = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	namespaceGVR      = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	configMapGVR      = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	serviceGVR        = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	endpointsGVR      = schema.GroupVersionResource{Version: "v1", Resource: "endpoints"}
+	pvcGVR            = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	pvGVR             = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}
+	endpointSliceGVR  = schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"}
+	deploymentGVR     = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	daemonSetGVR      = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}
+	batchJobGVR       = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	priorityClassGVR  = schema.GroupVersionResource{Group: "scheduling.k8s.io", Version: "v1", Resource: "priorityclasses"}
+	jobSetGVR         = schema.GroupVersionResource{Group: "jobset.x-k8s.io", Version: "v1alpha2", Resource: "jobsets"}
+	workloadGVR       = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "workloads"}
+	localQueueGVR     = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "localqueues"}
+	clusterQueueGVR   = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "clusterqueues"}
+	resourceFlavorGVR = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "resourceflavors"}
+	admissionCheckGVR = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "admissionchecks"}
+	topologyGVR       = schema.GroupVersionResource{Group: "kueue.x-k8s.io", Version: kueueAPIVersion, Resource: "topologies"}
+)
+
+// getObject returns the object name of gvr in namespace ns. ns is empty for
+// cluster-scoped resources.
+func (g *GKEOrchestrator) getObject(ctx context.Context, gvr schema.GroupVersionResource, ns, name string) (*unstructured.Unstructured, error) {
+	client, err := g.getDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	var obj *unstructured.Unstructured
+	err = retry.Do(ctx, g.retryPolicy(apiRetry), func() error {
+		obj, err = client.Resource(gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", gvr.Resource, name, err)
+	}
+	return obj, nil
+}
+
+// getResource decodes the object name of gvr in namespace ns into out, which
+// is a struct with the JSON fields of the object that are read.
+func (g *GKEOrchestrator) getResource(ctx context.Context, out interface{}, gvr schema.GroupVersionResource, ns, name string) error {
+	obj, err := g.getObject(ctx, gvr, ns, name)
+	if err != nil {
+		return err
+	}
+	if err := decodeObject(obj.Object, out); err != nil {
+		return fmt.Errorf("failed to parse %s %s: %w", gvr.Resource, name, err)
+	}
+	return nil
+}
+
+// decodeObject decodes the fields of an unstructured object into out.
+func decodeObject(obj map[string]interface{}, out interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// listResources decodes the objects of gvr in namespace ns, or in all
+// namespaces when ns is empty, into out, which is a struct with an "items"
+// field.
+func (g *GKEOrchestrator) listResources(ctx context.Context, out interface{}, gvr schema.GroupVersionResource, ns string, opts metav1.ListOptions) error {
+	client, err := g.getDynamicClient()
+	if err != nil {
+		return err
+	}
+	var data []byte
+	err = retry.Do(ctx, g.retryPolicy(apiRetry), func() error {
+		list, err := client.Resource(gvr).Namespace(ns).List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		data, err = list.MarshalJSON()
+		return retry.Permanent(err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", gvr.Resource, err)
+	}
+	return nil
+}
+
+// objectYAML returns the object name of gvr in namespace ns as YAML; see
+// redactedYAML.
+func (g *GKEOrchestrator) objectYAML(ctx context.Context, gvr schema.GroupVersionResource, ns, name string) (string, error) {
+	obj, err := g.getObject(ctx, gvr, ns, name)
+	if err != nil {
+		return "", err
+	}
+	return redactedYAML(obj.Object), nil
+}
+
+// listYAML returns the objects of gvr in namespace ns, or in all namespaces
+// when ns is empty, as a YAML stream; see redactedYAML.
+func (g *GKEOrchestrator) listYAML(ctx context.Context, gvr schema.GroupVersionResource, ns string, opts metav1.ListOptions) (string, error) {
+	var list struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := g.listResources(ctx, &list, gvr, ns, opts); err != nil {
+		return "", err
+	}
+	if len(list.Items) == 0 {
+		return fmt.Sprintf("no %s found\n", gvr.Resource), nil
+	}
+	docs := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		docs = append(docs, redactedYAML(item))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// deleteResource deletes the object name of gvr in namespace ns. An object
+// that does not exist is not an error.
+func (g *GKEOrchestrator) deleteResource(ctx context.Context, gvr schema.GroupVersionResource, ns, name string) error {
+	client, err := g.getDynamicClient()
+	if err != nil {
+		return err
+	}
+	err = retry.Do(ctx, g.retryPolicy(apiRetry), func() error {
+		return client.Resource(gvr).Namespace(ns).Delete(ctx, name, metav1.DeleteOptions{DryRun: g.dryRunOption()})
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s %s: %w", gvr.Resource, name, err)
+	}
+	return nil
+}
+
+// createObject creates obj of gvr in namespace ns. An object that already
+// exists is not an error.
+func (g *GKEOrchestrator) createObject(ctx context.Context, gvr schema.GroupVersionResource, ns string, obj map[string]interface{}) error {
+	client, err := g.getDynamicClient()
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{Object: obj}
+	err = retry.Do(ctx, g.retryPolicy(apiRetry), func() error {
+		_, err := client.Resource(gvr).Namespace(ns).Create(ctx, u, metav1.CreateOptions{DryRun: g.dryRunOption()})
+		return err
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create %s %s: %w", gvr.Resource, u.GetName(), err)
+	}
+	return nil
+}
+
+// mergePatch applies the JSON merge patch to the object name of gvr in
+// namespace ns.
+func (g *GKEOrchestrator) mergePatch(ctx context.Context, gvr schema.GroupVersionResource, ns, name string, patch []byte) error {
+	client, err := g.getDynamicClient()
+	if err != nil {
+		return err
+	}
+	err = retry.Do(ctx, g.retryPolicy(apiRetry), func() error {
+		_, err := client.Resource(gvr).Namespace(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{DryRun: g.dryRunOption()})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to patch %s %s: %w", gvr.Resource, name, err)
+	}
+	return nil
+}
+
+// dryRunOption returns the DryRun option of the writes of the orchestrator,
+// which are only validated while impersonating; see Impersonate.
+func (g *GKEOrchestrator) dryRunOption() []string {
+	if g.impersonate != "" {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// deploymentRolloutRetry polls a Deployment for up to ten minutes while its
+// replicas roll out.
+var deploymentRolloutRetry = retry.Policy{Attempts: 120, Initial: 5 * time.Second, Multiplier: 1}
+
+// deploymentRollout holds the fields of a Deployment that tell whether its
+// rollout finished.
+type deploymentRollout struct {
+	Metadata struct {
+		Generation int64 `json:"generation"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int32 `json:"replicas"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration int64 `json:"observedGeneration"`
+		Replicas           int32 `json:"replicas"`
+		UpdatedReplicas    int32 `json:"updatedReplicas"`
+		AvailableReplicas  int32 `json:"availableReplicas"`
+		Conditions         []struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// pending returns why the rollout of the Deployment name is not finished, as
+// kubectl rollout status reports it, or nil once every replica runs the
+// latest template and is available.
+func (d deploymentRollout) pending(name string) error {
+	for _, c := range d.Status.Conditions {
+		if c.Type == "Progressing" && c.Reason == "ProgressDeadlineExceeded" {
+			return retry.Permanent(fmt.Errorf("deployment %q exceeded its progress deadline", name))
+		}
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	switch s := d.Status; {
+	case s.ObservedGeneration < d.Metadata.Generation:
+		return fmt.Errorf("waiting for deployment %q spec update to be observed", name)
+	case s.UpdatedReplicas < replicas:
+		return fmt.Errorf("waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated", name, s.UpdatedReplicas, replicas)
+	case s.Replicas > s.UpdatedReplicas:
+		return fmt.Errorf("waiting for deployment %q rollout to finish: %d old replicas are pending termination", name, s.Replicas-s.UpdatedReplicas)
+	case s.AvailableReplicas < s.UpdatedReplicas:
+		return fmt.Errorf("waiting for deployment %q rollout to finish: %d of %d updated replicas are available", name, s.AvailableReplicas, s.UpdatedReplicas)
+	}
+	return nil
+}
+
+// waitForRollout polls the Deployment name in namespace ns under p until its
+// rollout finishes.
+func (g *GKEOrchestrator) waitForRollout(ctx context.Context, ns, name string, p retry.Policy) error {
+	return retry.Do(ctx, g.retryPolicy(p), func() error {
+		var d deploymentRollout
+		if err := g.getResource(ctx, &d, deploymentGVR, ns, name); err != nil {
+			return retry.Permanent(err)
+		}
+		return d.pending(name)
+	})
+}
+
+// serviceEndpointsReady reports whether the Service service in namespace ns
+// has a ready endpoint. It reads the EndpointSlices of the Service, or its
+// Endpoints on controllers that predate them.
+func (g *GKEOrchestrator) serviceEndpointsReady(ctx context.Context, ns, service string, useEndpointSlice bool) (bool, error) {
+	if !useEndpointSlice {
+		var eps struct {
+			Subsets []struct {
+				Addresses []struct {
+					IP string `json:"ip"`
+				} `json:"addresses"`
+			} `json:"subsets"`
+		}
+		err := g.getResource(ctx, &eps, endpointsGVR, ns, service)
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		for _, subset := range eps.Subsets {
+			if len(subset.Addresses) > 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	var slices struct {
+		Items []struct {
+			Endpoints []struct {
+				Addresses  []string `json:"addresses"`
+				Conditions struct {
+					Ready *bool `json:"ready"`
+				} `json:"conditions"`
+			} `json:"endpoints"`
+		} `json:"items"`
+	}
+	if err := g.listResources(ctx, &slices, endpointSliceGVR, ns, metav1.ListOptions{LabelSelector: "kubernetes.io/service-name=" + service}); err != nil {
+		return false, err
+	}
+	for _, slice := range slices.Items {
+		for _, ep := range slice.Endpoints {
+			// A nil ready condition means ready.
+			if (ep.Conditions.Ready == nil || *ep.Conditions.Ready) && len(ep.Addresses) > 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
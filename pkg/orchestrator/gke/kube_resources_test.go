@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeKinds maps the kinds the tests seed to their resources and list kinds.
+var fakeKinds = map[string]schema.GroupVersionResource{
+	"Pod":                      podGVR,
+	"Event":                    eventGVR,
+	"Node":                     nodeGVR,
+	"Namespace":                namespaceGVR,
+	"ConfigMap":                configMapGVR,
+	"Service":                  serviceGVR,
+	"Endpoints":                endpointsGVR,
+	"PersistentVolumeClaim":    pvcGVR,
+	"PersistentVolume":         pvGVR,
+	"EndpointSlice":            endpointSliceGVR,
+	"Deployment":               deploymentGVR,
+	"DaemonSet":                daemonSetGVR,
+	"Job":                      batchJobGVR,
+	"PriorityClass":            priorityClassGVR,
+	"JobSet":                   jobSetGVR,
+	"Workload":                 workloadGVR,
+	"LocalQueue":               localQueueGVR,
+	"ClusterQueue":             clusterQueueGVR,
+	"ResourceFlavor":           resourceFlavorGVR,
+	"AdmissionCheck":           admissionCheckGVR,
+	"Topology":                 topologyGVR,
+	"CustomResourceDefinition": crdGVR,
+}
+
+// newFakeDynamicClient returns an in-memory dynamic client that serves the
+// objects of the given YAML or JSON manifests, which may also be lists of
+// objects. It honors label selectors but ignores field selectors.
+func newFakeDynamicClient(manifests ...string) *dynamicfake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for kind, gvr := range fakeKinds {
+		listKinds[gvr] = kind + "List"
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	for _, manifest := range manifests {
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			if err := decoder.Decode(&obj.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				panic(fmt.Sprintf("invalid fake object: %v", err))
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			if !obj.IsList() {
+				seedFakeObject(client, obj)
+				continue
+			}
+			list, err := obj.ToList()
+			if err != nil {
+				panic(fmt.Sprintf("invalid fake list: %v", err))
+			}
+			for i := range list.Items {
+				seedFakeObject(client, &list.Items[i])
+			}
+		}
+	}
+	return client
+}
+
+func seedFakeObject(client *dynamicfake.FakeDynamicClient, obj *unstructured.Unstructured) {
+	gvr, ok := fakeKinds[obj.GetKind()]
+	if !ok {
+		panic(fmt.Sprintf("no fake resource for kind %q", obj.GetKind()))
+	}
+	if _, err := client.Resource(gvr).Namespace(obj.GetNamespace()).Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+		panic(fmt.Sprintf("failed to seed %s %s: %v", obj.GetKind(), obj.GetName(), err))
+	}
+}
+
+// fakeList completes the items of a {"items": [...]} JSON list so that
+// newFakeDynamicClient can seed them: each item gets the kind and the
+// apiVersion of its resource, the namespace ns, the labels and, when it has
+// none, a generated name.
+func fakeList(kind, ns string, labels map[string]string, list string) string {
+	gvr, ok := fakeKinds[kind]
+	if !ok {
+		panic(fmt.Sprintf("no fake resource for kind %q", kind))
+	}
+	var items struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(list), &items); err != nil {
+		panic(fmt.Sprintf("invalid fake list: %v", err))
+	}
+	for i, item := range items.Items {
+		obj := &unstructured.Unstructured{Object: item}
+		obj.SetAPIVersion(gvr.GroupVersion().String())
+		obj.SetKind(kind)
+		if ns != "" {
+			obj.SetNamespace(ns)
+		}
+		if obj.GetName() == "" {
+			obj.SetName(fmt.Sprintf("%s-%d", strings.ToLower(kind), i))
+		}
+		if len(labels) > 0 {
+			merged := obj.GetLabels()
+			if merged == nil {
+				merged = map[string]string{}
+			}
+			maps.Copy(merged, labels)
+			obj.SetLabels(merged)
+		}
+	}
+	out, err := json.Marshal(items)
+	if err != nil {
+		panic(fmt.Sprintf("invalid fake list: %v", err))
+	}
+	return string(out)
+}
+
+// fakeObject completes a JSON object like fakeList, naming it name.
+func fakeObject(kind, ns, name, object string) string {
+	var items struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(fakeList(kind, ns, nil, `{"items": [`+object+`]}`)), &items); err != nil {
+		panic(fmt.Sprintf("invalid fake object: %v", err))
+	}
+	obj := &unstructured.Unstructured{Object: items.Items[0]}
+	obj.SetName(name)
+	out, err := obj.MarshalJSON()
+	if err != nil {
+		panic(fmt.Sprintf("invalid fake object: %v", err))
+	}
+	return string(out)
+}
+
+// fakeGets makes client answer every get of kind with the next of the given
+// JSON objects, repeating the last one, and returns the number of gets
+// answered so far.
+func fakeGets(client *dynamicfake.FakeDynamicClient, kind string, objects ...string) *int {
+	gets := new(int)
+	client.PrependReactor("get", fakeKinds[kind].Resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON([]byte(fakeObject(kind, "", "", objects[min(*gets, len(objects)-1)]))); err != nil {
+			return true, nil, err
+		}
+		*gets++
+		return true, obj, nil
+	})
+	return gets
+}
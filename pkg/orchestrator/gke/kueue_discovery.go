@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// errNoMatchingLocalQueue is returned when LocalQueues exist, but none of
+// them can admit the job's accelerator. Unlike other discovery failures, it
+// does not fall back to the default queue, where the job would never be
+// admitted.
+var errNoMatchingLocalQueue = errors.New("no Kueue LocalQueue can admit the job's accelerator")
+
+// Node labels of the ResourceFlavors of accelerator nodes.
+var acceleratorNodeLabels = []string{"cloud.google.com/gke-accelerator", "cloud.google.com/gke-tpu-accelerator"}
+
+// queueAcceleratorLabel returns the accelerator node label value of the
+// nodes computeType runs on, or "" for CPU machines and compute types that
+// cannot be resolved yet.
+func (g *GKEOrchestrator) queueAcceleratorLabel(computeType string) string {
+	if computeType == "" {
+		return ""
+	}
+	machineType, err := g.resolveJobMachineType(computeType)
+	if err != nil {
+		return ""
+	}
+	label := g.GenerateGKENodeSelectorLabel(machineType)
+	if strings.EqualFold(label, machineType) {
+		return ""
+	}
+	return label
+}
+
+// selectLocalQueue picks the one of queues whose ClusterQueue has a
+// ResourceFlavor for the accelerator label.
+func (g *GKEOrchestrator) selectLocalQueue(ctx context.Context, queues []string, label string) (string, error) {
+	flavorAccelerators, err := g.resourceFlavorAccelerators(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var matching, others []string
+	for _, q := range queues {
+		cq, err := g.getClusterQueueName(ctx, q)
+		if err != nil {
+			return "", err
+		}
+		flavors, err := g.clusterQueueFlavors(ctx, cq)
+		if err != nil {
+			return "", err
+		}
+		var accelerators []string
+		for _, f := range flavors {
+			if a := flavorAccelerators[f]; a != "" && !slices.Contains(accelerators, a) {
+				accelerators = append(accelerators, a)
+			}
+		}
+		if slices.Contains(accelerators, label) {
+			matching = append(matching, q)
+			continue
+		}
+		offered := "no accelerator flavors"
+		if len(accelerators) > 0 {
+			offered = "flavors for " + strings.Join(accelerators, ", ")
+		}
+		others = append(others, fmt.Sprintf("%s (ClusterQueue %s): %s", q, cq, offered))
+	}
+
+	switch len(matching) {
+	case 0:
+		return "", fmt.Errorf("%w %s. The LocalQueues in namespace default are:\n  %s\nSpecify a queue with the --queue flag, or add a ResourceFlavor with the node label %s to one of their ClusterQueues", errNoMatchingLocalQueue, label, strings.Join(others, "\n  "), label)
+	case 1:
+		logging.Info("Auto-discovered Kueue LocalQueue: %s, whose ClusterQueue has a ResourceFlavor for %s", matching[0], label)
+		return matching[0], nil
+	}
+	return "", fmt.Errorf("multiple LocalQueues have a ResourceFlavor for %s (%v). Please specify which one to use using --queue flag", label, matching)
+}
+
+// resourceFlavorAccelerators maps the names of the cluster's ResourceFlavors
+// to the accelerator of the nodes they select, if any.
+func (g *GKEOrchestrator) resourceFlavorAccelerators(ctx context.Context) (map[string]string, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				NodeLabels map[string]string `json:"nodeLabels"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := g.listResources(ctx, &list, resourceFlavorGVR, "", metav1.ListOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to list ResourceFlavors: %w", err)
+	}
+
+	accelerators := map[string]string{}
+	for _, rf := range list.Items {
+		for _, key := range acceleratorNodeLabels {
+			if v := rf.Spec.NodeLabels[key]; v != "" {
+				accelerators[rf.Metadata.Name] = v
+			}
+		}
+	}
+	return accelerators, nil
+}
+
+// clusterQueueFlavors returns the names of the ResourceFlavors of a
+// ClusterQueue's resource groups.
+func (g *GKEOrchestrator) clusterQueueFlavors(ctx context.Context, cqName string) ([]string, error) {
+	var cq struct {
+		Spec struct {
+			ResourceGroups []struct {
+				Flavors []struct {
+					Name string `json:"name"`
+				} `json:"flavors"`
+			} `json:"resourceGroups"`
+		} `json:"spec"`
+	}
+	if err := g.getResource(ctx, &cq, clusterQueueGVR, "", cqName); err != nil {
+		return nil, err
+	}
+
+	var flavors []string
+	for _, rg := range cq.Spec.ResourceGroups {
+		for _, f := range rg.Flavors {
+			flavors = append(flavors, f.Name)
+		}
+	}
+	return flavors, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveKueueQueue_MatchesAcceleratorFlavor(t *testing.T) {
+	setupMockMachineConfig(t)
+
+	const resourceFlavors = `{"items": [
+  {"metadata": {"name": "flavor-nvidia-h100-80gb"}, "spec": {"nodeLabels": {"cloud.google.com/gke-accelerator": "nvidia-h100-80gb"}}},
+  {"metadata": {"name": "flavor-tpu-v6e-slice"}, "spec": {"nodeLabels": {"cloud.google.com/gke-tpu-accelerator": "tpu-v6e-slice"}}},
+  {"metadata": {"name": "default-flavor"}, "spec": {}}
+]}`
+	clusterQueue := func(name string, flavors ...string) string {
+		var names []string
+		for _, f := range flavors {
+			names = append(names, `{"name": "`+f+`"}`)
+		}
+		return fakeObject("ClusterQueue", "", name, `{"spec": {"resourceGroups": [{"flavors": [`+strings.Join(names, ", ")+`]}]}}`)
+	}
+	newOrchestrator := func(queues ...string) *GKEOrchestrator {
+		manifests := []string{
+			fakeList("ResourceFlavor", "", nil, resourceFlavors),
+			clusterQueue("cpu-cq", "default-flavor"),
+			clusterQueue("gpu-cq", "default-flavor", "flavor-nvidia-h100-80gb"),
+			clusterQueue("tpu-cq", "flavor-tpu-v6e-slice"),
+		}
+		for _, q := range queues {
+			cq := strings.TrimSuffix(q, "-q") + "-cq"
+			manifests = append(manifests, fakeObject("LocalQueue", "default", q, `{"spec": {"clusterQueue": "`+cq+`"}}`))
+		}
+		orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+		orc.dynClient = newFakeDynamicClient(manifests...)
+		return orc
+	}
+
+	for computeType, want := range map[string]string{
+		"a3-highgpu-8g":      "gpu-q",
+		"ct6e-standard-4t":   "tpu-q",
+		"n2-standard-8":      "",
+		"unknown-compute-ty": "",
+	} {
+		orc := newOrchestrator("cpu-q", "gpu-q", "tpu-q")
+		got, err := orc.resolveKueueQueue(context.Background(), "", computeType)
+		if want == "" {
+			// CPU compute types keep the unfiltered discovery, which needs a
+			// single LocalQueue.
+			if err == nil || errors.Is(err, errNoMatchingLocalQueue) {
+				t.Errorf("resolveKueueQueue(%s) = %q, %v; want the multiple queues error", computeType, got, err)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("resolveKueueQueue(%s) = %q, %v; want %q", computeType, got, err, want)
+		}
+	}
+
+	orc := newOrchestrator("cpu-q", "tpu-q")
+	_, err := orc.resolveKueueQueue(context.Background(), "", "a3-highgpu-8g")
+	if !errors.Is(err, errNoMatchingLocalQueue) {
+		t.Fatalf("expected errNoMatchingLocalQueue, got %v", err)
+	}
+	for _, want := range []string{"nvidia-h100-80gb", "cpu-q (ClusterQueue cpu-cq): no accelerator flavors", "tpu-q (ClusterQueue tpu-cq): flavors for tpu-v6e-slice", "--queue"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	if got, err := newOrchestrator("cpu-q").resolveKueueQueue(context.Background(), "picked", "a3-highgpu-8g"); err != nil || got != "picked" {
+		t.Errorf("a requested queue must be used as is, got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func (g *GKEOrchestrator) describeCluster(clusterName, location, projectID string) shell.CommandResult {
+	return g.executor.ExecuteCommand("gcloud", "container", "clusters", "describe", clusterName,
+		"--location", location,
+		"--project", projectID,
+		"--format=json")
+}
+
+// describeClusterInSiblingLocation is called when the cluster of job could not
+// be described in job.ClusterLocation, with failed the result of that attempt.
+// A regional cluster addressed by one of its zones is found in its region.
+// Otherwise the location is checked against the zones and regions available to
+// the project, and the cluster is looked up in the other locations of the same
+// region, which job.ClusterLocation is updated to.
+func (g *GKEOrchestrator) describeClusterInSiblingLocation(job *orchestrator.JobDefinition, failed shell.CommandResult) (shell.CommandResult, error) {
+	if isZone(job.ClusterLocation) {
+		region := shell.ExtractRegion(job.ClusterLocation)
+		logging.Info("Failed to find cluster in zone %s. Trying fallback to region %s...", job.ClusterLocation, region)
+		if res := g.describeCluster(job.ClusterName, region, job.ClusterProjectID); res.ExitCode == 0 {
+			logging.Warn("Cluster '%s' is a regional cluster in '%s'. Found it by falling back from zone '%s'. "+
+				"Note: This does NOT restrict your job to '%s'. To run specifically in '%s', "+
+				"please use the '--node-constraint topology.kubernetes.io/zone=%s' flag.",
+				job.ClusterName, region, job.ClusterLocation, job.ClusterLocation, job.ClusterLocation, job.ClusterLocation)
+			job.ClusterLocation = region
+			return res, nil
+		}
+	}
+
+	if err := g.validateLocation(job.ClusterLocation, job.ClusterProjectID); err != nil {
+		return failed, err
+	}
+
+	locations, err := g.findClusterLocations(job.ClusterName, job.ClusterProjectID)
+	if err != nil {
+		logging.Warn("Failed to look up the location of cluster '%s': %v", job.ClusterName, err)
+	}
+	location := siblingLocation(job.ClusterLocation, locations)
+	if location == "" {
+		if len(locations) > 0 && !slices.Contains(locations, job.ClusterLocation) {
+			return failed, fmt.Errorf("GKE cluster %s is in %s, not in %s; use --location %s", job.ClusterName, strings.Join(locations, ", "), job.ClusterLocation, locations[0])
+		}
+		return failed, fmt.Errorf("failed to describe GKE cluster %s in %s: %s", job.ClusterName, job.ClusterLocation, failed.Stderr)
+	}
+
+	res := g.describeCluster(job.ClusterName, location, job.ClusterProjectID)
+	if res.ExitCode != 0 {
+		return res, fmt.Errorf("failed to describe GKE cluster %s in %s: %s", job.ClusterName, location, res.Stderr)
+	}
+	logging.Warn("Cluster '%s' was not found in '%s' but exists in '%s'; using '%s'. Pass '--location %s' to skip this lookup.",
+		job.ClusterName, job.ClusterLocation, location, location, location)
+	job.ClusterLocation = location
+	return res, nil
+}
+
+// validateLocation rejects a location that is neither a zone nor a region
+// available to projectID, suggesting the closest one. The location is accepted
+// if the available locations cannot be listed.
+func (g *GKEOrchestrator) validateLocation(location, projectID string) error {
+	locations, err := g.listLocations(projectID)
+	if err != nil {
+		logging.Warn("Failed to list the locations of project '%s': %v", projectID, err)
+		return nil
+	}
+	if slices.Contains(locations, location) {
+		return nil
+	}
+	return config.HintSpelling(location, locations,
+		fmt.Errorf("location %q is not a zone or region available to project %s", location, projectID))
+}
+
+// listLocations returns the zones available to projectID and the regions they
+// belong to, sorted.
+func (g *GKEOrchestrator) listLocations(projectID string) ([]string, error) {
+	res := g.executor.ExecuteCommand("gcloud", "compute", "zones", "list", "--project", projectID, "--format=value(name)")
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to list zones: %s", res.Stderr)
+	}
+	var locations []string
+	for _, zone := range strings.Fields(res.Stdout) {
+		locations = append(locations, zone, shell.ExtractRegion(zone))
+	}
+	slices.Sort(locations)
+	return slices.Compact(locations), nil
+}
+
+// findClusterLocations returns the locations of the clusters named clusterName
+// in projectID.
+func (g *GKEOrchestrator) findClusterLocations(clusterName, projectID string) ([]string, error) {
+	res := g.executor.ExecuteCommand("gcloud", "container", "clusters", "list", "--project", projectID,
+		"--filter", "name="+clusterName, "--format=value(location)")
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("failed to list clusters: %s", res.Stderr)
+	}
+	return strings.Fields(res.Stdout), nil
+}
+
+// siblingLocation returns the first of locations, other than location itself,
+// in the same region as location: the region of a zone, a zone of a region, or
+// another zone of the same region.
+func siblingLocation(location string, locations []string) string {
+	for _, l := range locations {
+		if l != location && shell.ExtractRegion(l) == shell.ExtractRegion(location) {
+			return l
+		}
+	}
+	return ""
+}
+
+// isZone reports whether location names a zone (e.g. us-central1-a) rather
+// than a region.
+func isZone(location string) bool {
+	return len(strings.Split(location, "-")) == 3
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestPopulateClusterMetadata_ResolveLocation(t *testing.T) {
+	setupMockMachineConfig(t)
+
+	const (
+		describe     = "gcloud container clusters describe my-cluster --location "
+		listZones    = "gcloud compute zones list --project my-project"
+		listClusters = "gcloud container clusters list --project my-project --filter name=my-cluster"
+		description  = `{"locations": ["us-central1-b"], "nodePools": [], "autoscaling": {}}`
+	)
+	notFound := shell.CommandResult{ExitCode: 1, Stderr: "ERROR: (gcloud.container.clusters.describe) ResponseError: code=404, message=Not found"}
+	zones := shell.CommandResult{Stdout: "us-central1-a\nus-central1-b\neurope-west4-a\n"}
+
+	tests := []struct {
+		name         string
+		location     string
+		responses    map[string][]shell.CommandResult
+		wantLocation string
+		wantErr      string
+	}{
+		{
+			name:     "typo suggests the closest location",
+			location: "us-centrl1",
+			responses: map[string][]shell.CommandResult{
+				describe + "us-centrl1 ": {notFound},
+				listZones:                {zones},
+			},
+			wantErr: `location "us-centrl1" is not a zone or region available to project my-project - did you mean "us-central1"?`,
+		},
+		{
+			name:     "region resolves to the zone of a zonal cluster",
+			location: "us-central1",
+			responses: map[string][]shell.CommandResult{
+				describe + "us-central1 ":   {notFound},
+				listZones:                   {zones},
+				listClusters:                {{Stdout: "us-central1-b\n"}},
+				describe + "us-central1-b ": {{Stdout: description}},
+			},
+			wantLocation: "us-central1-b",
+		},
+		{
+			name:     "zone resolves to another zone of the region",
+			location: "us-central1-a",
+			responses: map[string][]shell.CommandResult{
+				describe + "us-central1-a ": {notFound},
+				describe + "us-central1 ":   {notFound},
+				listZones:                   {zones},
+				listClusters:                {{Stdout: "us-central1-b\n"}},
+				describe + "us-central1-b ": {{Stdout: description}},
+			},
+			wantLocation: "us-central1-b",
+		},
+		{
+			name:     "cluster in another region is not resolved",
+			location: "europe-west4-a",
+			responses: map[string][]shell.CommandResult{
+				describe + "europe-west4-a ": {notFound},
+				describe + "europe-west4 ":   {notFound},
+				listZones:                    {zones},
+				listClusters:                 {{Stdout: "us-central1-b\n"}},
+			},
+			wantErr: "GKE cluster my-cluster is in us-central1-b, not in europe-west4-a; use --location us-central1-b",
+		},
+		{
+			name:     "unknown cluster reports the describe error",
+			location: "us-central1",
+			responses: map[string][]shell.CommandResult{
+				describe + "us-central1 ": {notFound},
+				listZones:                 {zones},
+				listClusters:              {{}},
+			},
+			wantErr: "failed to describe GKE cluster my-cluster in us-central1: ERROR: (gcloud.container.clusters.describe) ResponseError: code=404",
+		},
+		{
+			name:     "location is accepted when zones cannot be listed",
+			location: "us-central1",
+			responses: map[string][]shell.CommandResult{
+				describe + "us-central1 ":   {notFound},
+				listZones:                   {{ExitCode: 1, Stderr: "compute API disabled"}},
+				listClusters:                {{Stdout: "us-central1-b\n"}},
+				describe + "us-central1-b ": {{Stdout: description}},
+			},
+			wantLocation: "us-central1-b",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orc := newTestGKEOrchestrator(NewMockExecutor(tc.responses))
+			job := &orchestrator.JobDefinition{
+				ClusterProjectID: "my-project",
+				ClusterName:      "my-cluster",
+				ClusterLocation:  tc.location,
+			}
+
+			err := orc.populateClusterMetadata(job)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("populateClusterMetadata failed: %v", err)
+			}
+			if job.ClusterLocation != tc.wantLocation {
+				t.Errorf("expected location %q, got %q", tc.wantLocation, job.ClusterLocation)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// cloudSDKImage runs the gcloud helper containers: the log archiver and
+	// the stage-in Job.
+	cloudSDKImage            = "gcr.io/google.com/cloudsdktool/google-cloud-cli:slim"
+	logArchiverContainerName = "log-archiver"
+	// logArchiveIntervalSeconds is how often logs are uploaded while the pod
+	// runs, so a node that disappears still leaves most of its logs behind.
+	logArchiveIntervalSeconds = 300
+)
+
+// logArchiverScript collects the kubelet's log files and termination messages
+// of the pod's other containers. With a GCS destination it uploads them
+// periodically and once more when the sidecar is stopped after the workload
+// containers exit; with Cloud Logging, where GKE already ships the logs, it only
+// prints the termination messages so the log sink keeps them too.
+const logArchiverScript = `set -u
+OUT=/tmp/gcluster-archive
+collect() {
+  mkdir -p "$OUT"
+  for d in /gcluster/logs/*/; do
+    c=$(basename "$d")
+    [ "$c" = ` + logArchiverContainerName + ` ] && continue
+    ls -1v "$d" | grep '\.log$' | while read -r f; do cat "$d$f"; done > "$OUT/$c.log"
+  done
+  for d in /gcluster/termination/*/; do
+    c=$(basename "$d")
+    [ "$c" = ` + logArchiverContainerName + ` ] && continue
+    for f in "$d"*; do [ -s "$f" ] && cp "$f" "$OUT/$c.termination-log"; done
+  done
+}
+upload() {
+  collect
+  gcloud storage cp "$OUT"/* "$ARCHIVE_DEST/$POD_NAME/" --custom-metadata="gcluster-workload=$WORKLOAD" --quiet || echo "log upload to $ARCHIVE_DEST failed"
+}
+print_termination() {
+  for d in /gcluster/termination/*/; do
+    c=$(basename "$d")
+    [ "$c" = ` + logArchiverContainerName + ` ] && continue
+    for f in "$d"*; do [ -s "$f" ] && echo "termination message of $c: $(cat "$f")"; done
+  done
+}
+final() {
+  if [ "$ARCHIVE_KIND" = gcs ]; then upload; else print_termination; fi
+  exit 0
+}
+trap final TERM INT
+while true; do
+  sleep "$ARCHIVE_INTERVAL" &
+  wait $!
+  [ "$ARCHIVE_KIND" = gcs ] && upload
+done
+`
+
+// logArchiveURI is the GCS prefix a workload's pods are archived under.
+func logArchiveURI(archive *orchestrator.LogArchive, workload string) string {
+	uri := "gs://" + archive.Bucket
+	if archive.Prefix != "" {
+		uri += "/" + strings.Trim(archive.Prefix, "/")
+	}
+	return uri + "/" + workload
+}
+
+// addLogArchiver adds the archiver as a native sidecar (an init container that
+// restarts always), which the kubelet stops only after the workload containers
+// exit. It reads this pod's directories of the node's /var/log/pods and kubelet
+// pod state through read-only hostPath mounts scoped with subPathExpr.
+func addLogArchiver(opts *ManifestOptions, archive *orchestrator.LogArchive) error {
+	if archive == nil {
+		return nil
+	}
+
+	fieldEnv := func(name, path string) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: path}}}
+	}
+	env := []corev1.EnvVar{
+		fieldEnv("POD_NAME", "metadata.name"),
+		fieldEnv("POD_NAMESPACE", "metadata.namespace"),
+		fieldEnv("POD_UID", "metadata.uid"),
+		{Name: "WORKLOAD", Value: opts.WorkloadName},
+		{Name: "ARCHIVE_KIND", Value: archive.Kind},
+		{Name: "ARCHIVE_INTERVAL", Value: fmt.Sprint(logArchiveIntervalSeconds)},
+	}
+	mounts := []corev1.VolumeMount{
+		{Name: "gcluster-pod-termination", MountPath: "/gcluster/termination", SubPathExpr: "$(POD_UID)/containers", ReadOnly: true},
+	}
+	volumes := []corev1.Volume{
+		hostPathVolume("gcluster-pod-termination", "/var/lib/kubelet/pods"),
+	}
+	if archive.Kind == orchestrator.LogArchiveGCS {
+		env = append(env, corev1.EnvVar{Name: "ARCHIVE_DEST", Value: logArchiveURI(archive, opts.WorkloadName)})
+		mounts = append(mounts, corev1.VolumeMount{Name: "gcluster-pod-logs", MountPath: "/gcluster/logs", SubPathExpr: "$(POD_NAMESPACE)_$(POD_NAME)_$(POD_UID)", ReadOnly: true})
+		volumes = append(volumes, hostPathVolume("gcluster-pod-logs", "/var/log/pods"))
+	}
+
+	opts.LogArchiver = &corev1.Container{
+		Name:          logArchiverContainerName,
+		Image:         cloudSDKImage,
+		RestartPolicy: ptr(corev1.ContainerRestartPolicyAlways),
+		Command:       []string{"/bin/bash", "-c", logArchiverScript},
+		Env:           env,
+		VolumeMounts:  mounts,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		},
+	}
+	opts.Volumes = append(opts.Volumes, volumes...)
+	return nil
+}
+
+// hostPathVolume returns a volume of the node directory path.
+func hostPathVolume(name, path string) corev1.Volume {
+	return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{
+		HostPath: &corev1.HostPathVolumeSource{Path: path, Type: ptr(corev1.HostPathDirectory)},
+	}}
+}
+
+// logArchiveSinkName is the log sink routing every gcluster workload of a
+// cluster to its archive log bucket.
+func logArchiveSinkName(clusterName string) string {
+	return "gcluster-logs-" + clusterName
+}
+
+// ensureLogArchiveSink creates or updates the cluster's log sink so the logs of
+// pods labeled with a gcluster workload are routed to the archive log bucket,
+// where they are kept for the bucket's retention instead of the default one.
+func (g *GKEOrchestrator) ensureLogArchiveSink(job orchestrator.JobDefinition) error {
+	archive := job.LogArchive
+	name := logArchiveSinkName(job.ClusterName)
+	destination := fmt.Sprintf("logging.googleapis.com/projects/%s/locations/%s/buckets/%s", job.ClusterProjectID, archive.Location, archive.Bucket)
+	filter := fmt.Sprintf(`resource.type="k8s_container" AND resource.labels.cluster_name="%s" AND resource.labels.location="%s" AND labels."k8s-pod/gcluster_google_com/workload":*`, job.ClusterName, job.ClusterLocation)
+
+	verb := "update"
+	if res := g.executor.ExecuteCommand("gcloud", "logging", "sinks", "describe", name, "--project", job.ClusterProjectID); res.ExitCode != 0 {
+		verb = "create"
+	}
+	res := g.executor.ExecuteCommand("gcloud", "logging", "sinks", verb, name, destination, "--log-filter", filter, "--project", job.ClusterProjectID)
+	if res.ExitCode != 0 {
+		return fmt.Errorf("failed to %s log sink %s to %s: %s", verb, name, destination, res.Stderr)
+	}
+	logging.Info("Logs of workload %s are archived to log bucket %s through log sink %s", job.WorkloadName, archive.Bucket, name)
+	return nil
+}
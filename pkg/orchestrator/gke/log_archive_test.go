@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"slices"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAddLogArchiver_Logging(t *testing.T) {
+	opts := ManifestOptions{WorkloadName: "train"}
+	(&StorageManager{}).AddVolumeOptions(&opts, []MountInfo{{Name: "data", Source: "/mnt/data", MountPath: "/data", Type: "hostPath"}})
+
+	if err := addLogArchiver(&opts, &orchestrator.LogArchive{Kind: orchestrator.LogArchiveLogging, Bucket: "archive", Location: "global"}); err != nil {
+		t.Fatalf("addLogArchiver() error = %v", err)
+	}
+	c := opts.LogArchiver
+	if c == nil || c.Name != logArchiverContainerName || c.RestartPolicy == nil || *c.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+		t.Fatalf("expected the archiver as a native sidecar, got %+v", c)
+	}
+	if !slices.Contains(c.Env, corev1.EnvVar{Name: "ARCHIVE_KIND", Value: "logging"}) || c.VolumeMounts[0].SubPathExpr != "$(POD_UID)/containers" {
+		t.Errorf("unexpected archiver sidecar %+v", c)
+	}
+	// GKE already ships the logs to Cloud Logging, so only termination
+	// messages are read from the node.
+	var volumes []string
+	for _, v := range opts.Volumes {
+		volumes = append(volumes, v.Name)
+	}
+	if len(c.Env) != 6 || len(c.VolumeMounts) != 1 {
+		t.Errorf("expected no log file mount for a Cloud Logging archive, got %+v", c)
+	}
+	if want := []string{"data", "gcluster-pod-termination"}; !slices.Equal(volumes, want) || opts.Volumes[1].HostPath.Path != "/var/lib/kubelet/pods" {
+		t.Errorf("expected the archiver volume after the mounted volumes, got %v", volumes)
+	}
+}
+
+func TestAddLogArchiver_Disabled(t *testing.T) {
+	opts := ManifestOptions{WorkloadName: "train"}
+	if err := addLogArchiver(&opts, nil); err != nil || opts.LogArchiver != nil || opts.Volumes != nil {
+		t.Errorf("expected no changes without an archive, got %+v, %v", opts, err)
+	}
+}
+
+func TestLogArchiveURI(t *testing.T) {
+	if got, want := logArchiveURI(&orchestrator.LogArchive{Bucket: "b"}, "train"), "gs://b/train"; got != want {
+		t.Errorf("logArchiveURI() = %q, want %q", got, want)
+	}
+	if got, want := logArchiveURI(&orchestrator.LogArchive{Bucket: "b", Prefix: "/runs/"}, "train"), "gs://b/runs/train"; got != want {
+		t.Errorf("logArchiveURI() = %q, want %q", got, want)
+	}
+}
+
+func TestEnsureLogArchiveSink(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		ClusterProjectID: "my-project",
+		ClusterName:      "my-cluster",
+		ClusterLocation:  "us-central1",
+		WorkloadName:     "train",
+		LogArchive:       &orchestrator.LogArchive{Kind: orchestrator.LogArchiveLogging, Bucket: "archive", Location: "global"},
+	}
+	dest := "logging.googleapis.com/projects/my-project/locations/global/buckets/archive"
+
+	tests := []struct {
+		name     string
+		describe shell.CommandResult
+		verb     string
+	}{
+		{"creates a missing sink", shell.CommandResult{ExitCode: 1, Stderr: "NOT_FOUND"}, "create"},
+		{"updates an existing sink", shell.CommandResult{ExitCode: 0}, "update"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			exec := NewMockExecutor(map[string][]shell.CommandResult{
+				"gcloud logging sinks describe gcluster-logs-my-cluster": {tc.describe},
+				"gcloud logging sinks " + tc.verb + " gcluster-logs-my-cluster " + dest + " --log-filter resource.type=\"k8s_container\" AND resource.labels.cluster_name=\"my-cluster\"": {{ExitCode: 0}},
+			})
+			if err := newTestGKEOrchestrator(exec).ensureLogArchiveSink(job); err != nil {
+				t.Fatalf("ensureLogArchiveSink() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// enforceManagedProfile rejects a workload that exceeds the bounds of the
+// managed profile it is submitted under: the namespace and queue bounds of
+// cluster workloads, and those every backend applies. It runs once the queue
+// and hardware are resolved, and before any image is built.
+func (g *GKEOrchestrator) enforceManagedProfile(job orchestrator.JobDefinition, profile JobProfile) error {
+	mp := job.ManagedProfile
+	if mp == nil {
+		return nil
+	}
+	var violations []string
+
+	if len(mp.AllowedNamespaces) > 0 {
+		if job.DryRun {
+			logging.Info("[Dry Run] Skipping the namespace check of managed profile %q.", mp.Name)
+		} else {
+			ns, err := g.currentNamespace()
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(mp.AllowedNamespaces, ns) {
+				violations = append(violations, fmt.Sprintf("namespace %q is not allowed (allowed: %s); switch with 'kubectl config set-context --current --namespace <namespace>'", ns, strings.Join(mp.AllowedNamespaces, ", ")))
+			}
+		}
+	}
+
+	if len(mp.AllowedQueues) > 0 && !slices.Contains(mp.AllowedQueues, job.KueueQueueName) {
+		violations = append(violations, fmt.Sprintf("queue %q is not allowed (allowed: %s)", job.KueueQueueName, strings.Join(mp.AllowedQueues, ", ")))
+	}
+
+	gpus := 0
+	if mp.MaxGPUs > 0 {
+		var err error
+		if gpus, err = g.requestedGPUs(job, profile); err != nil {
+			return err
+		}
+	}
+	shared, err := orchestrator.ManagedProfileViolations(job, gpus)
+	if err != nil {
+		return err
+	}
+	return orchestrator.ManagedProfileError(job, append(violations, shared...))
+}
+
+// managedProfileName returns the name of the managed profile job is submitted
+// under, which is recorded on the workload.
+func managedProfileName(job orchestrator.JobDefinition) string {
+	if job.ManagedProfile == nil {
+		return ""
+	}
+	return job.ManagedProfile.Name
+}
+
+// requestedGPUs returns the number of GPUs of all the nodes of the workload.
+func (g *GKEOrchestrator) requestedGPUs(job orchestrator.JobDefinition, profile JobProfile) (int, error) {
+	if job.MachineType == "" || profile.IsCPUMachine || config.IsTPU(job.MachineType) {
+		return 0, nil
+	}
+	if job.GPUsPerPod > 0 {
+		return job.GPUsPerPod * max(job.NodesPerSlice, 1) * max(job.NumSlices, 1), nil
+	}
+	perNode, err := g.FetchMachineCapacity(job.MachineType, job.ClusterLocation)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve the GPUs of machine type %s: %w", job.MachineType, err)
+	}
+	return perNode * max(job.NodesPerSlice, 1) * max(job.NumSlices, 1), nil
+}
+
+// currentNamespace returns the namespace workloads are applied to.
+func (g *GKEOrchestrator) currentNamespace() (string, error) {
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return "", err
+	}
+	ns, err := kube.GetCurrentNamespace()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the current namespace: %w", err)
+	}
+	return ns, nil
+}
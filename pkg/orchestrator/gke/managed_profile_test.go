@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestEnforceManagedProfile(t *testing.T) {
+	mp := &orchestrator.ManagedProfile{
+		Name:              "ml-research",
+		AllowedNamespaces: []string{"ml-research"},
+		AllowedQueues:     []string{"ml-research-queue"},
+		MaxGPUs:           16,
+		AllowedRegistries: []string{"us-docker.pkg.dev/ml-research"},
+	}
+	allowed := orchestrator.JobDefinition{
+		ManagedProfile:  mp,
+		KueueQueueName:  "ml-research-queue",
+		MachineType:     "a3-highgpu-8g",
+		ClusterLocation: "us-central1-a",
+		NumSlices:       1,
+		NodesPerSlice:   2,
+		ImageName:       "us-docker.pkg.dev/ml-research/images/trainer:v1",
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		modify    func(job *orchestrator.JobDefinition)
+		wantErr   string
+	}{
+		{name: "within bounds", namespace: "ml-research"},
+		{name: "no profile", namespace: "default", modify: func(job *orchestrator.JobDefinition) { job.ManagedProfile = nil }},
+		{name: "namespace", namespace: "default", wantErr: `namespace "default" is not allowed (allowed: ml-research)`},
+		{name: "namespace skipped in dry run", namespace: "default", modify: func(job *orchestrator.JobDefinition) { job.DryRun = true }},
+		{
+			name:      "queue",
+			namespace: "ml-research",
+			modify:    func(job *orchestrator.JobDefinition) { job.KueueQueueName = "default-queue" },
+			wantErr:   `queue "default-queue" is not allowed (allowed: ml-research-queue)`,
+		},
+		{
+			name:      "GPUs",
+			namespace: "ml-research",
+			modify:    func(job *orchestrator.JobDefinition) { job.NumSlices = 2; job.NodesPerSlice = 2 },
+			wantErr:   "the workload requests 32 GPUs, more than the allowed 16",
+		},
+		{
+			name:      "GPUs are not counted for TPUs",
+			namespace: "ml-research",
+			modify:    func(job *orchestrator.JobDefinition) { job.MachineType = "ct6e-standard-4t"; job.NodesPerSlice = 64 },
+		},
+		{
+			name:      "image registry",
+			namespace: "ml-research",
+			modify:    func(job *orchestrator.JobDefinition) { job.ImageName = "python:3.11" },
+			wantErr:   "image repository index.docker.io/library/python is not allowed",
+		},
+		{
+			name:      "built image repository",
+			namespace: "ml-research",
+			modify: func(job *orchestrator.JobDefinition) {
+				job.ImageName = ""
+				job.BaseImage = "python:3.11"
+				job.ImageRepo = "us-central1-docker.pkg.dev/other-team/images"
+			},
+			wantErr: "image repository us-central1-docker.pkg.dev/other-team/images is not allowed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			orc.kubeClient = &MockKubeClient{Namespace: tc.namespace}
+			orc.machineCapCache = map[string]MachineTypeCap{
+				"a3-highgpu-8g:us-central1-a": {
+					Accelerators: []struct {
+						Count int    `json:"guestAcceleratorCount"`
+						Type  string `json:"guestAcceleratorType"`
+					}{{Count: 8, Type: "nvidia-h100-80gb"}},
+				},
+			}
+
+			job := allowed
+			if tc.modify != nil {
+				tc.modify(&job)
+			}
+			err := orc.enforceManagedProfile(job, JobProfile{})
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected the workload to be allowed, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if !strings.Contains(err.Error(), `managed profile "ml-research"`) {
+				t.Errorf("expected the error to name the profile, got %v", err)
+			}
+		})
+	}
+}
@@ -15,99 +15,138 @@
 package gke
 
 import (
-	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/orchestrator"
+	"slices"
 	"strings"
 
-	"github.com/google/safetext/yamltemplate"
-
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
-	k8syaml "sigs.k8s.io/yaml"
 )
 
 func (g *GKEOrchestrator) GenerateGKEManifest(opts ManifestOptions, profile JobProfile) (string, error) {
-	cpuLimit, memoryLimit, gpuLimit, tpuLimit, err := g.calculateResourceLimits(opts, profile)
+	spec, err := g.resourceSpec(opts, profile)
 	if err != nil {
 		return "", fmt.Errorf("failed to calculate resource limits: %w", err)
 	}
 
-	if opts.ComputeType != "" && gpuLimit == "" && tpuLimit == "" {
+	if opts.ComputeType != "" && spec.GPU == "" && spec.TPU == "" {
 		logging.Info("Suppressing nodeSelector label for deduced CPU machine %s", opts.ComputeType)
 		opts.ComputeType = ""
 	}
 
-	resourcesString, err := g.buildResourcesString(cpuLimit, memoryLimit, gpuLimit, tpuLimit, 16)
+	resources, err := buildResources(spec)
 	if err != nil {
 		return "", err
 	}
 
-	cmdSlice := []string{"/bin/bash", "-c", opts.CommandToRun}
+	renderer, err := rendererFor(opts.WorkloadKind)
+	if err != nil {
+		return "", err
+	}
 
-	tmpl, err := yamltemplate.New("jobset.tmpl").ParseFS(templatesFS, "templates/jobset.tmpl")
+	data := g.prepareJobSetTemplateData(opts, resources, spec.TPU != "", spec.GPU != "")
+
+	manifest, err := renderer.Render(data)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse jobset template: %w", err)
+		return "", err
 	}
+	return assembleManifest(manifest, opts.AdditionalManifests), nil
+}
 
-	isTPU := tpuLimit != ""
-	isGPU := gpuLimit != ""
-	data := g.prepareJobSetTemplateData(opts, cmdSlice, resourcesString, isTPU, isGPU)
+// ResourceSpec is the resources of a workload container. CPU, Memory, GPU and
+// TPU are its limits. CPURequest and MemoryRequest, when set, request less
+// than the limits so the pod is burstable; unset requests equal the limits.
+type ResourceSpec struct {
+	CPU           string
+	Memory        string
+	GPU           string
+	TPU           string
+	CPURequest    string
+	MemoryRequest string
+}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute jobset template: %w", err)
+// resourceSpec returns the resources of the containers of the workload.
+func (g *GKEOrchestrator) resourceSpec(opts ManifestOptions, profile JobProfile) (ResourceSpec, error) {
+	cpu, mem, gpu, tpu, err := g.calculateResourceLimits(opts, profile)
+	if err != nil {
+		return ResourceSpec{}, err
 	}
-	return assembleManifest(buf.String(), opts.AdditionalManifests), nil
+	return ResourceSpec{
+		CPU:           cpu,
+		Memory:        mem,
+		GPU:           gpu,
+		TPU:           tpu,
+		CPURequest:    opts.CPURequest,
+		MemoryRequest: opts.MemoryRequest,
+	}, nil
 }
 
-func (g *GKEOrchestrator) buildResourcesString(cpu, mem, gpu, tpu string, indent int) (string, error) {
+// buildResources returns the resource limits and requests of the workload
+// containers.
+func buildResources(spec ResourceSpec) (corev1.ResourceRequirements, error) {
 	limits := corev1.ResourceList{}
-	if cpu != "" {
-		q, err := resource.ParseQuantity(cpu)
-		if err != nil {
-			return "", fmt.Errorf("failed to parse CPU quantity %q: %w", cpu, err)
+	for _, r := range []struct {
+		name     corev1.ResourceName
+		label    string
+		quantity string
+	}{
+		{corev1.ResourceCPU, "CPU", spec.CPU},
+		{corev1.ResourceMemory, "memory", spec.Memory},
+		{corev1.ResourceName("nvidia.com/gpu"), "GPU", spec.GPU},
+		{corev1.ResourceName("google.com/tpu"), "TPU", spec.TPU},
+	} {
+		if r.quantity == "" {
+			continue
 		}
-		limits[corev1.ResourceCPU] = q
-	}
-	if mem != "" {
-		q, err := resource.ParseQuantity(mem)
+		q, err := resource.ParseQuantity(r.quantity)
 		if err != nil {
-			return "", fmt.Errorf("failed to parse memory quantity %q: %w", mem, err)
+			return corev1.ResourceRequirements{}, fmt.Errorf("failed to parse %s quantity %q: %w", r.label, r.quantity, err)
 		}
-		limits[corev1.ResourceMemory] = q
+		limits[r.name] = q
 	}
-	if gpu != "" {
-		q, err := resource.ParseQuantity(gpu)
-		if err != nil {
-			return "", fmt.Errorf("failed to parse GPU quantity %q: %w", gpu, err)
+
+	requests := corev1.ResourceList{}
+	for _, r := range []struct {
+		name     corev1.ResourceName
+		label    string
+		quantity string
+	}{
+		{corev1.ResourceCPU, "CPU", spec.CPURequest},
+		{corev1.ResourceMemory, "memory", spec.MemoryRequest},
+	} {
+		if r.quantity == "" {
+			continue
 		}
-		limits[corev1.ResourceName("nvidia.com/gpu")] = q
-	}
-	if tpu != "" {
-		q, err := resource.ParseQuantity(tpu)
+		q, err := resource.ParseQuantity(r.quantity)
 		if err != nil {
-			return "", fmt.Errorf("failed to parse TPU quantity %q: %w", tpu, err)
+			return corev1.ResourceRequirements{}, fmt.Errorf("failed to parse %s request %q: %w", r.label, r.quantity, err)
 		}
-		limits[corev1.ResourceName("google.com/tpu")] = q
-	}
-
-	if len(limits) == 0 {
-		return "", nil
+		if limit, ok := limits[r.name]; ok && q.Cmp(limit) > 0 {
+			return corev1.ResourceRequirements{}, fmt.Errorf("%s request %s is more than its limit %s", r.label, q.String(), limit.String())
+		}
+		requests[r.name] = q
 	}
 
-	resources := corev1.ResourceRequirements{
-		Limits: limits,
+	resources := corev1.ResourceRequirements{}
+	if len(limits) > 0 {
+		resources.Limits = limits
 	}
-
-	b, err := k8syaml.Marshal(resources)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal resources: %w", err)
+	if len(requests) > 0 {
+		// Requests given for some resources only default to the limits for
+		// the others, as they would without any requests.
+		for name, limit := range limits {
+			if _, ok := requests[name]; !ok {
+				requests[name] = limit
+			}
+		}
+		resources.Requests = requests
 	}
 
-	resourcesStr := "resources:\n" + indentYaml(string(b), 2)
-	return indentYaml(resourcesStr, indent), nil
+	return resources, nil
 }
 
 func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition, fullImageName string, profile JobProfile, isDynamicSlicing bool, isStaticSlicing bool) (ManifestOptions, error) {
@@ -116,11 +155,15 @@ func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition,
 	schedOpts := SchedulingOptions{
 		PlacementPolicy:    job.PlacementPolicy,
 		NodeAffinityLabels: job.NodeConstraint,
+		NodeAffinityExprs:  job.NodeAffinityExprs,
 		Topology:           job.Topology,
 		Scheduler:          job.GKEScheduler,
 		IsDynamicSlicing:   isDynamicSlicing,
 		IsStaticSlicing:    isStaticSlicing,
 	}
+	if err := g.applyCompactPlacement(&schedOpts, job); err != nil {
+		return ManifestOptions{}, err
+	}
 
 	// Reuse GCluster's existing GKE accelerator label mapping and algorithmically
 	// derive the Pathways short platform key to avoid duplicating mapping tables.
@@ -144,18 +187,35 @@ func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition,
 		IsDynamicSlicing:              isDynamicSlicing,
 		IsStaticSlicing:               isStaticSlicing,
 		WorkloadName:                  job.WorkloadName,
+		WorkloadKind:                  job.WorkloadKind,
 		FullImageName:                 fullImageName,
+		BaseImage:                     job.BaseImage,
+		BaseImageDigest:               job.BaseImageDigest,
+		CapturedEnv:                   job.CapturedEnv,
+		CapturedEnvDigest:             job.CapturedEnvDigest,
+		SigningKey:                    job.SigningKey,
+		ManagedProfile:                managedProfileName(job),
+		Inputs:                        inputsAnnotation(job.Inputs),
+		Sidecars:                      job.Sidecars,
 		CommandToRun:                  job.CommandToRun,
+		CommandArgs:                   job.CommandArgs,
 		ComputeType:                   job.ComputeType,
 		MachineType:                   job.MachineType,
+		CPU:                           job.CPU,
+		Memory:                        job.Memory,
+		GPUsPerPod:                    job.GPUsPerPod,
+		CPURequest:                    job.CPURequest,
+		MemoryRequest:                 job.MemoryRequest,
 		PathwaysInstanceType:          pathwaysInstanceType,
-		ProjectID:                     job.ProjectID,
+		ProjectID:                     job.ClusterProjectID,
 		ClusterName:                   job.ClusterName,
 		ClusterLocation:               job.ClusterLocation,
 		KueueQueueName:                job.KueueQueueName,
 		NumSlices:                     job.NumSlices,
 		NodesPerSlice:                 job.NodesPerSlice,
 		MaxRestarts:                   job.MaxRestarts,
+		RestartPolicy:                 job.RestartPolicy,
+		PodBackoffLimit:               job.PodBackoffLimit,
 		TtlSecondsAfterFinished:       job.TtlSecondsAfterFinished,
 		TerminationGracePeriodSeconds: job.TerminationGracePeriodSeconds,
 		ServiceAccountName:            job.ServiceAccountName,
@@ -164,10 +224,12 @@ func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition,
 		PriorityClassName:             job.PriorityClassName,
 		Topology:                      schedOpts.Topology,
 		Verbose:                       job.Verbose,
-		Env:                           job.Env,
+		Env:                           orchestrator.RunMetadataEnv(job),
+		CostLabels:                    job.CostLabels,
+		ResourceLabels:                resourceFingerprintLabels(job, schedOpts.Topology),
 	}
 
-	if err := g.fillManifestStrings(&opts, schedOpts, job, isDynamicSlicing, isStaticSlicing, profile.IsCPUMachine); err != nil {
+	if err := g.fillPodScheduling(&opts, schedOpts, job, isDynamicSlicing, isStaticSlicing, profile.IsCPUMachine); err != nil {
 		return ManifestOptions{}, err
 	}
 
@@ -177,8 +239,65 @@ func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition,
 		return ManifestOptions{}, err
 	}
 	opts.AdditionalManifests = manifests
+	opts.SharedVolumes = sm.SharedVolumeSpecs(job)
+	sharedMounts, err := sm.AddSharedVolumes(&opts)
+	if err != nil {
+		return ManifestOptions{}, err
+	}
+	mountInfos = append(mountInfos, sharedMounts...)
+	if opts.StageInManifest, err = buildStageInManifest(job, mountInfos); err != nil {
+		return ManifestOptions{}, err
+	}
+	if opts.Retention, err = resolveRetention(job.Retention, mountInfos); err != nil {
+		return ManifestOptions{}, err
+	}
 
+	objectMounts, err := sm.ProcessObjectMounts(job)
+	if err != nil {
+		return ManifestOptions{}, err
+	}
+	mountInfos = append(mountInfos, objectMounts...)
+	snippetMount, err := addSnippet(&opts, job)
+	if err != nil {
+		return ManifestOptions{}, err
+	}
+	if snippetMount != nil {
+		mountInfos = append(mountInfos, *snippetMount)
+	}
+	scriptMount, err := addCommandScript(&opts, job)
+	if err != nil {
+		return ManifestOptions{}, err
+	}
+	if scriptMount != nil {
+		mountInfos = append(mountInfos, *scriptMount)
+	}
+	if err := g.addPullSecret(&opts, job); err != nil {
+		return ManifestOptions{}, err
+	}
+	secretEnvMount, err := g.addSecretEnv(&opts, job)
+	if err != nil {
+		return ManifestOptions{}, err
+	}
+	if secretEnvMount != nil {
+		mountInfos = append(mountInfos, *secretEnvMount)
+	}
 	sm.AddVolumeOptions(&opts, mountInfos)
+	if err := addHeadlessService(&opts, job); err != nil {
+		return ManifestOptions{}, err
+	}
+	if err := expandCommandTemplate(&opts, job); err != nil {
+		return ManifestOptions{}, err
+	}
+	if err := addMPI(&opts, job); err != nil {
+		return ManifestOptions{}, err
+	}
+	addFrameworkEnv(&opts, job)
+	if err := addLogArchiver(&opts, job.LogArchive); err != nil {
+		return ManifestOptions{}, err
+	}
+	if err := addStartupProbe(&opts, job.StartupProbe); err != nil {
+		return ManifestOptions{}, err
+	}
 
 	_, err = g.resolveResourcesAndGates(&opts, profile.IsCPUMachine, profile.CapacityCount, job)
 	if err != nil {
@@ -188,38 +307,28 @@ func (g *GKEOrchestrator) PrepareManifestOptions(job orchestrator.JobDefinition,
 	return opts, nil
 }
 
-func (g *GKEOrchestrator) fillManifestStrings(opts *ManifestOptions, schedOpts SchedulingOptions, job orchestrator.JobDefinition, isDynamicSlicing bool, isStaticSlicing bool, isCPUMachine bool) error {
-	nodeSelectorStr, err := g.buildNodeSelector(schedOpts, job, isCPUMachine)
+func (g *GKEOrchestrator) fillPodScheduling(opts *ManifestOptions, schedOpts SchedulingOptions, job orchestrator.JobDefinition, isDynamicSlicing bool, isStaticSlicing bool, isCPUMachine bool) error {
+	nodeSelector, err := g.buildNodeSelector(schedOpts, job, isCPUMachine)
 	if err != nil {
 		return err
 	}
-	opts.NodeSelector = nodeSelectorStr
+	opts.NodeSelector = nodeSelector
 
-	affinityStr, err := g.buildAffinity(schedOpts)
-	if err != nil {
+	if opts.Affinity, err = GetAffinity(schedOpts); err != nil {
 		return err
 	}
-	opts.Affinity = affinityStr
 
-	podFailurePolicyStr, err := g.generatePodFailurePolicy(job.RestartOnExitCodes)
-	if err != nil {
-		return err
-	}
-	opts.PodFailurePolicy = indentYaml(podFailurePolicyStr, 12)
-
-	imagePullSecretsStr := g.generateImagePullSecrets(job.ImagePullSecrets)
-	if imagePullSecretsStr != "" {
-		opts.ImagePullSecrets = indentYaml(imagePullSecretsStr, 16)
-	}
+	opts.PodFailurePolicy = g.generatePodFailurePolicy(job.RestartOnExitCodes)
+	opts.ImagePullSecrets = g.generateImagePullSecrets(imagePullSecrets(job))
 
 	isSubSlicing := isDynamicSlicing || isStaticSlicing
-	opts.TopologyAnnotation = g.buildTopologyAnnotation(schedOpts.Topology, job.MachineType, job.NumSlices, job.NodesPerSlice, isSubSlicing)
+	opts.TopologyAnnotations = g.buildTopologyAnnotations(schedOpts.Topology, job.MachineType, job.NumSlices, job.NodesPerSlice, isSubSlicing)
 
-	tolerationsStr, err := g.resolveTolerations(job.MachineType, job.GKENAPProvisioning, job.GKENAPReservation, 16)
-	if err != nil {
-		return err
+	extraTolerations := userTolerations(job.Tolerations)
+	if job.Spot {
+		extraTolerations = append(extraTolerations, spotToleration)
 	}
-	opts.Tolerations = tolerationsStr
+	opts.Tolerations = g.resolveTolerations(job.MachineType, job.GKENAPProvisioning, job.GKENAPReservation, extraTolerations...)
 
 	return nil
 }
@@ -277,7 +386,55 @@ func (g *GKEOrchestrator) resolveReservationTolerations(machineType, reservation
 	return tolerations
 }
 
-func (g *GKEOrchestrator) resolveTolerations(acceleratorType string, consumptionModel string, reservationName string, indent int) (string, error) {
+// inputsAnnotation returns the JSON recorded in the gcluster.google.com/inputs
+// annotation of a workload, or "" without inputs.
+func inputsAnnotation(inputs []orchestrator.DataInput) string {
+	if len(inputs) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		logging.Warn("Failed to record the inputs on the workload: %v", err)
+		return ""
+	}
+	return string(b)
+}
+
+// spotNodeLabel is the label, and taint, of the nodes of Spot VM node pools.
+const spotNodeLabel = "cloud.google.com/gke-spot"
+
+// spotToleration tolerates the taint of Spot VM node pools created with
+// --node-taints cloud.google.com/gke-spot=true:NoSchedule.
+var spotToleration = corev1.Toleration{
+	Key:      spotNodeLabel,
+	Operator: corev1.TolerationOpEqual,
+	Value:    "true",
+	Effect:   corev1.TaintEffectNoSchedule,
+}
+
+// userTolerations converts the --toleration values of a job. A toleration
+// without a value tolerates the taint whatever its value.
+func userTolerations(tolerations []orchestrator.Toleration) []corev1.Toleration {
+	var res []corev1.Toleration
+	for _, t := range tolerations {
+		toleration := corev1.Toleration{
+			Key:      t.Key,
+			Operator: corev1.TolerationOpEqual,
+			Value:    t.Value,
+			Effect:   corev1.TaintEffect(t.Effect),
+		}
+		if t.Value == "" {
+			toleration.Operator = corev1.TolerationOpExists
+		}
+		res = append(res, toleration)
+	}
+	return res
+}
+
+// resolveTolerations returns the tolerations the workload needs to schedule
+// on nodes of acceleratorType with the given consumption model, followed by
+// the extra ones not already among them.
+func (g *GKEOrchestrator) resolveTolerations(acceleratorType string, consumptionModel string, reservationName string, extra ...corev1.Toleration) []corev1.Toleration {
 	// Copy the slice to avoid mutating any shared underlying array returned by GetTolerations
 	tolerations := append([]corev1.Toleration(nil), GetTolerations(acceleratorType)...)
 
@@ -292,15 +449,12 @@ func (g *GKEOrchestrator) resolveTolerations(acceleratorType string, consumption
 	case "reservation":
 		tolerations = append(tolerations, g.resolveReservationTolerations(acceleratorType, reservationName)...)
 	}
-
-	if len(tolerations) == 0 {
-		return "", nil
-	}
-	b, err := k8syaml.Marshal(tolerations)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal tolerations: %w", err)
+	for _, t := range extra {
+		if !slices.Contains(tolerations, t) {
+			tolerations = append(tolerations, t)
+		}
 	}
-	return indentYaml(string(b), indent), nil
+	return tolerations
 }
 
 func assembleManifest(mainManifest string, additionalManifests []string) string {
@@ -328,3 +482,53 @@ func indentYaml(s string, indent int) string {
 	}
 	return strings.Join(result, "\n")
 }
+
+// containerCommand returns the container command and args for a workload. An
+// exec-form command is used verbatim. A multi-line shell command is passed to
+// bash as a single argument with errexit set, so each line runs in order and
+// the first failing line fails the container.
+func containerCommand(commandToRun string, commandArgs []string) (command, args []string) {
+	if len(commandArgs) > 0 {
+		return commandArgs[:1], commandArgs[1:]
+	}
+	if strings.Contains(strings.TrimSpace(commandToRun), "\n") {
+		return []string{"/bin/bash", "-e", "-c"}, []string{strings.TrimSpace(commandToRun)}
+	}
+	return []string{"/bin/bash", "-c", commandToRun}, nil
+}
+
+// pathwaysCommand renders the user command for embedding in the Pathways head
+// container script, which runs exec-form commands shell-quoted. The command is
+// embedded base64-encoded and decoded and evaluated by the script, so its
+// quotes, backslashes, command substitutions and line breaks reach the shell
+// unchanged whatever the indentation of the block scalar around it.
+func pathwaysCommand(commandToRun string, commandArgs []string) string {
+	command := strings.TrimSpace(commandToRun)
+	if len(commandArgs) > 0 {
+		command = shellJoin(commandArgs)
+	}
+	return `eval "$(echo ` + base64.StdEncoding.EncodeToString([]byte(command)) + ` | base64 -d)"`
+}
+
+// rayEntrypoint renders the container command as the single shell string a
+// RayJob entrypoint takes.
+func rayEntrypoint(commandToRun string, commandArgs []string) string {
+	command, args := containerCommand(commandToRun, commandArgs)
+	return shellJoin(slices.Concat(command, args))
+}
+
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes s for bash unless it consists only of safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
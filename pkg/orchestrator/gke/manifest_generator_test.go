@@ -19,9 +19,7 @@ import (
 	"testing"
 )
 
-func TestBuildResourcesString(t *testing.T) {
-	g := &GKEOrchestrator{}
-
+func TestBuildResources(t *testing.T) {
 	tests := []struct {
 		name        string
 		cpu         string
@@ -68,18 +66,54 @@ func TestBuildResourcesString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := g.buildResourcesString(tt.cpu, tt.mem, tt.gpu, tt.tpu, 16)
+			resources, err := buildResources(ResourceSpec{CPU: tt.cpu, Memory: tt.mem, GPU: tt.gpu, TPU: tt.tpu})
 			if (err != nil) != tt.wantErr {
-				t.Errorf("buildResourcesString() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("buildResources() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			got, err := marshalManifest(resources)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if !tt.wantErr && tt.wantContain != "" && !strings.Contains(got, tt.wantContain) {
-				t.Errorf("buildResourcesString() = %v, want contain %v", got, tt.wantContain)
+				t.Errorf("buildResources() = %v, want contain %v", got, tt.wantContain)
 			}
 		})
 	}
 }
 
+func TestBuildResources_Requests(t *testing.T) {
+	resources, err := buildResources(ResourceSpec{CPU: "8", Memory: "32Gi", GPU: "1", CPURequest: "2", MemoryRequest: "16Gi"})
+	if err != nil {
+		t.Fatalf("buildResources() error = %v", err)
+	}
+	got, err := marshalManifest(resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `limits:
+  cpu: "8"
+  memory: 32Gi
+  nvidia.com/gpu: "1"
+requests:
+  cpu: "2"
+  memory: 16Gi
+  nvidia.com/gpu: "1"
+`
+	if got != want {
+		t.Errorf("buildResources() = %q, want %q", got, want)
+	}
+
+	resources, err = buildResources(ResourceSpec{MemoryRequest: "4Gi"})
+	if err != nil || resources.Limits != nil || resources.Requests.Memory().String() != "4Gi" {
+		t.Errorf("expected only a memory request, got %+v (%v)", resources, err)
+	}
+
+	if _, err := buildResources(ResourceSpec{CPU: "4", CPURequest: "8"}); err == nil || !strings.Contains(err.Error(), "CPU request 8 is more than its limit 4") {
+		t.Errorf("expected a request above the limit to fail, got %v", err)
+	}
+}
+
 func TestAssembleManifest(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -0,0 +1,233 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// mpiHostfilePath is where the launcher init container writes the
+	// hostfile mpirun reads.
+	mpiHostfilePath = "/etc/mpi/hostfile"
+	// mpiSSHKeyType is the OpenSSH name of the key type of the SSH keys.
+	mpiSSHKeyType = "ecdsa-sha2-nistp256"
+)
+
+// addMPI prepares an MPI workload: a launcher that runs the command through
+// mpirun on workers running sshd. The launcher and the workers share an SSH
+// key pair, generated for the workload and stored in a Secret. The launcher
+// waits for the DNS hostname of every worker (see addHeadlessService) before
+// writing the hostfile.
+func addMPI(opts *ManifestOptions, job orchestrator.JobDefinition) error {
+	if job.WorkloadKind != orchestrator.WorkloadKindMPI {
+		return nil
+	}
+	secret, err := mpiSSHSecret(opts.WorkloadName)
+	if err != nil {
+		return err
+	}
+	opts.AdditionalManifests = append(opts.AdditionalManifests, secret)
+	opts.MPI.SSHSecret = opts.WorkloadName + "-ssh"
+
+	slots := max(1, job.GPUsPerPod)
+	var hosts []string
+	for s := 0; s < job.NumSlices; s++ {
+		for p := 0; p < job.NodesPerSlice; p++ {
+			hosts = append(hosts, fmt.Sprintf("%s-worker-%d-%d.%s", opts.WorkloadName, s, p, opts.WorkloadName))
+		}
+	}
+	opts.MPI.HostfileScript = strings.Join([]string{
+		": > " + mpiHostfilePath,
+		"for host in " + strings.Join(hosts, " ") + "; do",
+		`  until getent hosts "$host" > /dev/null; do echo "Waiting for $host..."; sleep 2; done`,
+		fmt.Sprintf(`  echo "$host slots=%d" >> %s`, slots, mpiHostfilePath),
+		"done",
+	}, "\n")
+
+	command := shellJoin(opts.CommandArgs)
+	if len(opts.CommandArgs) == 0 {
+		command = "/bin/bash -c " + shellQuote(strings.TrimSpace(opts.CommandToRun))
+	}
+	// Processes started over SSH do not inherit the environment of the
+	// worker containers, so mpirun exports the workload's variables.
+	var exports []string
+	for _, v := range append(sortedEnvVars(opts.Env), opts.SecretEnv...) {
+		exports = append(exports, "-x", v.Name)
+	}
+	opts.MPI.LauncherCommand = strings.Join(append(append([]string{"mpirun", "--hostfile", mpiHostfilePath, "-np", strconv.Itoa(len(hosts) * slots)}, exports...), command), " ")
+	return nil
+}
+
+// mpiSSHSecret renders the Secret of a new SSH key pair, laid out to be
+// mounted as ~/.ssh.
+func mpiSSHSecret(name string) (string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the SSH key of the MPI workload: %w", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the SSH key of the MPI workload: %w", err)
+	}
+	pub, err := sshPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := k8syaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":   name + "-ssh",
+			"labels": map[string]string{"gcluster.google.com/workload": name},
+		},
+		"type": "Opaque",
+		"stringData": map[string]string{
+			"id_ecdsa":        string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})),
+			"id_ecdsa.pub":    pub,
+			"authorized_keys": pub,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render the SSH Secret: %w", err)
+	}
+	return string(manifest), nil
+}
+
+// sshPublicKey formats a P-256 public key as an authorized_keys line.
+func sshPublicKey(pub *ecdsa.PublicKey) (string, error) {
+	ecdhKey, err := pub.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the SSH public key: %w", err)
+	}
+	var blob []byte
+	for _, field := range [][]byte{[]byte(mpiSSHKeyType), []byte("nistp256"), ecdhKey.Bytes()} {
+		blob = binary.BigEndian.AppendUint32(blob, uint32(len(field)))
+		blob = append(blob, field...)
+	}
+	return mpiSSHKeyType + " " + base64.StdEncoding.EncodeToString(blob) + "\n", nil
+}
+
+// buildMPIJobSet returns the JobSet of an MPI workload: a launcher job, which
+// the JobSet succeeds with, and NumSlices jobs of NodesPerSlice workers.
+func buildMPIJobSet(data jobSetTemplateData) (*jobSet, error) {
+	sshVolume := corev1.Volume{Name: "mpi-ssh", VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: data.MPI.SSHSecret, DefaultMode: ptr(int32(0600))},
+	}}
+	sshMount := corev1.VolumeMount{Name: "mpi-ssh", MountPath: "/root/.ssh"}
+	hostfileMount := corev1.VolumeMount{Name: "mpi-hostfile", MountPath: path.Dir(mpiHostfilePath)}
+	var annotations map[string]string
+	if data.GCSFuseEnabled {
+		annotations = withGCSFuseAnnotation(nil)
+	}
+
+	launcher := corev1.PodSpec{
+		TerminationGracePeriodSeconds: ptr(int64(data.TerminationGracePeriodSeconds)),
+		PriorityClassName:             data.PriorityClassName,
+		RestartPolicy:                 corev1.RestartPolicy(data.RestartPolicy),
+		InitContainers: []corev1.Container{{
+			Name:         "hostfile",
+			Image:        data.FullImageName,
+			Command:      []string{"/bin/sh", "-c", data.MPI.HostfileScript},
+			VolumeMounts: []corev1.VolumeMount{hostfileMount},
+		}},
+		Containers: []corev1.Container{{
+			Name:    "launcher",
+			Image:   data.FullImageName,
+			Command: []string{"/bin/bash", "-c", data.MPI.LauncherCommand},
+			Env: append([]corev1.EnvVar{
+				{Name: "OMPI_ALLOW_RUN_AS_ROOT", Value: "1"},
+				{Name: "OMPI_ALLOW_RUN_AS_ROOT_CONFIRM", Value: "1"},
+				{Name: "OMPI_MCA_plm_rsh_args", Value: "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o ConnectionAttempts=10"},
+			}, podEnv(data.Env)...),
+			VolumeMounts: append([]corev1.VolumeMount{sshMount, hostfileMount}, data.VolumeMounts...),
+		}},
+		Volumes: append([]corev1.Volume{
+			sshVolume,
+			{Name: "mpi-hostfile", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		}, data.Volumes...),
+		Tolerations:        data.Tolerations,
+		ImagePullSecrets:   data.ImagePullSecrets,
+		ServiceAccountName: data.ServiceAccountName,
+	}
+	if data.LogArchiver != nil {
+		launcher.InitContainers = append(launcher.InitContainers, *data.LogArchiver)
+	}
+
+	worker := workerPodSpec(data)
+	worker.InitContainers = sidecarContainers(data)
+	worker.Containers = []corev1.Container{{
+		Name:         "worker",
+		Image:        data.FullImageName,
+		Command:      []string{"/bin/sh", "-c", "mkdir -p /run/sshd && ssh-keygen -A && exec /usr/sbin/sshd -De"},
+		Env:          workloadEnv(data),
+		VolumeMounts: append([]corev1.VolumeMount{sshMount}, data.VolumeMounts...),
+	}}
+	if len(data.Containers) > 0 {
+		worker.Containers[0].Resources = data.Containers[0].Resources
+	}
+	worker.Volumes = append([]corev1.Volume{sshVolume}, data.Volumes...)
+
+	js := newJobSet(data, nil)
+	js.Spec.SuccessPolicy = &jobSetSuccessPolicy{Operator: "All", TargetReplicatedJobs: []string{"launcher"}}
+	js.Spec.ReplicatedJobs = []replicatedJob{
+		{
+			Name:     "launcher",
+			Replicas: 1,
+			Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+				Parallelism:      ptr(int32(1)),
+				Completions:      ptr(int32(1)),
+				BackoffLimit:     ptr(int32(data.PodBackoffLimit)),
+				PodFailurePolicy: data.PodFailurePolicy,
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: podLabels(data), Annotations: annotations},
+					Spec:       launcher,
+				},
+			}},
+		},
+		{
+			Name:     "worker",
+			Replicas: int32(data.NumSlices),
+			Template: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+				Parallelism:  ptr(int32(data.NodesPerSlice)),
+				Completions:  ptr(int32(data.NodesPerSlice)),
+				BackoffLimit: ptr(int32(data.PodBackoffLimit)),
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: podLabels(data), Annotations: workerPodAnnotations(data)},
+					Spec:       worker,
+				},
+			}},
+		},
+	}
+	return js, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestAddMPI(t *testing.T) {
+	opts := ManifestOptions{
+		WorkloadName: "train",
+		CommandArgs:  []string{"python", "train.py"},
+		Env:          map[string]string{"LOG_LEVEL": "debug"},
+	}
+	job := orchestrator.JobDefinition{WorkloadKind: orchestrator.WorkloadKindMPI, NumSlices: 2, NodesPerSlice: 2, GPUsPerPod: 4}
+	if err := addMPI(&opts, job); err != nil {
+		t.Fatalf("addMPI() error = %v", err)
+	}
+	if opts.MPI.SSHSecret != "train-ssh" {
+		t.Errorf("expected SSH secret train-ssh, got %q", opts.MPI.SSHSecret)
+	}
+	if len(opts.AdditionalManifests) != 1 {
+		t.Fatalf("expected the Secret manifest, got %v", opts.AdditionalManifests)
+	}
+	for _, want := range []string{"kind: Secret", "name: train-ssh", "gcluster.google.com/workload: train", "BEGIN EC PRIVATE KEY", "authorized_keys: |\n    ecdsa-sha2-nistp256 "} {
+		if !strings.Contains(opts.AdditionalManifests[0], want) {
+			t.Errorf("expected Secret to contain %q, got:\n%s", want, opts.AdditionalManifests[0])
+		}
+	}
+	if want := "for host in train-worker-0-0.train train-worker-0-1.train train-worker-1-0.train train-worker-1-1.train; do"; !strings.Contains(opts.MPI.HostfileScript, want) {
+		t.Errorf("expected hostfile script to contain %q, got:\n%s", want, opts.MPI.HostfileScript)
+	}
+	if !strings.Contains(opts.MPI.HostfileScript, "slots=4") {
+		t.Errorf("expected 4 slots per worker, got:\n%s", opts.MPI.HostfileScript)
+	}
+	if want := "mpirun --hostfile /etc/mpi/hostfile -np 16 -x LOG_LEVEL python train.py"; opts.MPI.LauncherCommand != want {
+		t.Errorf("expected launcher command %q, got %q", want, opts.MPI.LauncherCommand)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train", CommandToRun: "python train.py --lr 0.1"}
+	job = orchestrator.JobDefinition{WorkloadKind: orchestrator.WorkloadKindMPI, NumSlices: 1, NodesPerSlice: 1}
+	if err := addMPI(&opts, job); err != nil {
+		t.Fatalf("addMPI() error = %v", err)
+	}
+	if want := "mpirun --hostfile /etc/mpi/hostfile -np 1 /bin/bash -c 'python train.py --lr 0.1'"; opts.MPI.LauncherCommand != want {
+		t.Errorf("expected launcher command %q, got %q", want, opts.MPI.LauncherCommand)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	if err := addMPI(&opts, orchestrator.JobDefinition{}); err != nil || opts.MPI != (MPIOptions{}) || len(opts.AdditionalManifests) != 0 {
+		t.Errorf("expected no changes for a JobSet, got %+v, %v", opts, err)
+	}
+}
@@ -15,9 +15,12 @@
 package gke
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
+	"hpc-toolkit/pkg/orchestrator"
+
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -303,7 +306,7 @@ func TestResolveTolerations(t *testing.T) {
 					NodePools: tt.nodePools,
 				},
 			}
-			got, err := g.resolveTolerations(tt.acceleratorType, tt.consumptionModel, tt.reservationName, 16)
+			got, err := marshalManifest(g.resolveTolerations(tt.acceleratorType, tt.consumptionModel, tt.reservationName))
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -321,20 +324,42 @@ func TestResolveTolerationsDoesNotMutateSharedArray(t *testing.T) {
 	g := &GKEOrchestrator{}
 
 	// Call resolveTolerations for a TPU with Spot (which appends "spot")
-	got1, err := g.resolveTolerations("v5p-8", "spot", "", 16)
-	if err != nil {
-		t.Fatalf("unexpected error on first call: %v", err)
-	}
+	got1 := g.resolveTolerations("v5p-8", "spot", "")
 
 	// Call resolveTolerations for a TPU with standard consumption model (no Spot/Reservation)
-	got2, err := g.resolveTolerations("v5p-8", "", "", 16)
-	if err != nil {
-		t.Fatalf("unexpected error on second call: %v", err)
-	}
+	got2 := g.resolveTolerations("v5p-8", "", "")
 
 	// The second result should ONLY have TPU toleration, NOT spot
-	if strings.Contains(got2, "spot") {
-		t.Errorf("second call unexpectedly contains 'spot'. got1: %q, got2: %q", got1, got2)
+	for _, tol := range got2 {
+		if tol.Value == "spot" {
+			t.Errorf("second call unexpectedly tolerates spot. got1: %v, got2: %v", got1, got2)
+		}
+	}
+}
+
+func TestResolveTolerations_UserTolerations(t *testing.T) {
+	g := &GKEOrchestrator{}
+	extra := userTolerations([]orchestrator.Toleration{
+		{Key: "dedicated", Value: "ml", Effect: "NoSchedule"},
+		{Key: "nvidia.com/gpu"},
+		{Key: "google.com/tpu", Effect: "NoSchedule"}, // Already tolerated for TPUs.
+	})
+	got, err := marshalManifest(g.resolveTolerations("v5p-8", "", "", extra...))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `- effect: NoSchedule
+  key: google.com/tpu
+  operator: Exists
+- effect: NoSchedule
+  key: dedicated
+  operator: Equal
+  value: ml
+- key: nvidia.com/gpu
+  operator: Exists
+`
+	if got != want {
+		t.Errorf("resolveTolerations() = %q, want %q", got, want)
 	}
 }
 
@@ -438,3 +463,22 @@ func TestParseReservationURI(t *testing.T) {
 		})
 	}
 }
+
+func TestFillPodScheduling_Spot(t *testing.T) {
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	g.machineCapCache["n2-standard-4:us-central1-a"] = MachineTypeCap{}
+	job := orchestrator.JobDefinition{MachineType: "n2-standard-4", ClusterLocation: "us-central1-a", Spot: true}
+	var opts ManifestOptions
+	if err := g.fillPodScheduling(&opts, SchedulingOptions{}, job, false, false, true); err != nil {
+		t.Fatalf("fillPodScheduling() error = %v", err)
+	}
+	if got := opts.NodeSelector[spotNodeLabel]; got != "true" {
+		t.Errorf("expected the Spot node selector, got %v", opts.NodeSelector)
+	}
+	if !slices.Contains(opts.Tolerations, spotToleration) {
+		t.Errorf("expected the Spot toleration, got %v", opts.Tolerations)
+	}
+	if got := resourceFingerprintLabels(job, "")["provisioning"]; got != "spot" {
+		t.Errorf("provisioning label = %q, want spot", got)
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pathwaysHeadScript runs the user command, passed as its only verb, in the
+// background so that SIGTERM can be forwarded to it, and reports its exit code.
+const pathwaysHeadScript = `echo "GCluster Start: $(date)"
+_sigterm() {
+  if [ -n "$PID" ]; then
+    kill -SIGTERM $PID 2>/dev/null
+    wait $PID
+  fi
+  exit 143
+}
+trap _sigterm SIGTERM
+(
+  %s
+) & PID=$!
+wait $PID
+EXIT_CODE=$?
+echo "GCluster End: $(date)"
+echo "Exit code: $EXIT_CODE"
+exit $EXIT_CODE
+`
+
+// pathwaysAbslFlags are the flags of every Pathways component.
+var pathwaysAbslFlags = corev1.EnvVar{Name: "ABSL_FLAGS", Value: "--pathways_pipe_unreachable_timeout=60s"}
+
+// buildPathwaysJobSet returns the JobSet of a Pathways workload: a head job
+// running the proxy, the resource manager and, unless the workload is
+// headless, the user command; and NumSlices jobs of NodesPerSlice workers.
+func buildPathwaysJobSet(data jobSetTemplateData) (*jobSet, error) {
+	p := data.Pathways
+	js := newJobSet(data, map[string]string{"jobset.sigs.k8s.io/hack": "true"})
+	js.Spec.Suspend = ptr(false)
+	js.Spec.Network = &jobSetNetwork{EnableDNSHostnames: ptr(true), PublishNotReadyAddresses: ptr(true)}
+	js.Spec.Coordinator = &jobSetCoordinator{ReplicatedJob: "pathways-head"}
+	js.Spec.StartupPolicy = &jobSetStartupPolicy{StartupPolicyOrder: "InOrder"}
+	if !p.Headless {
+		js.Spec.SuccessPolicy = &jobSetSuccessPolicy{Operator: "All", TargetReplicatedJobs: []string{"pathways-head"}}
+	}
+	js.Spec.FailurePolicy.RestartStrategy = "BlockingRecreate"
+
+	headAnnotations := map[string]string{"kueue.x-k8s.io/safe-to-forcefully-delete": "true"}
+	if data.GCSFuseEnabled {
+		headAnnotations = withGCSFuseAnnotation(headAnnotations)
+	}
+	var headLabels map[string]string
+	for _, key := range []string{"team", "experiment", "user"} {
+		if v := data.CostLabels[key]; v != "" {
+			if headLabels == nil {
+				headLabels = map[string]string{}
+			}
+			headLabels[key] = v
+		}
+	}
+	workerAnnotations := map[string]string{
+		"kueue.x-k8s.io/safe-to-forcefully-delete": "true",
+		"cloud.google.com/skip-tpu-webhook-check":  "true",
+	}
+	for k, v := range data.TopologyAnnotations {
+		workerAnnotations[k] = v
+	}
+	if data.GCSFuseEnabled {
+		workerAnnotations = withGCSFuseAnnotation(workerAnnotations)
+	}
+
+	js.Spec.ReplicatedJobs = []replicatedJob{
+		{
+			Name:     "pathways-head",
+			Replicas: 1,
+			Template: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{exclusiveTopologyAnnotation: "kubernetes.io/hostname"}},
+				Spec: batchv1.JobSpec{
+					CompletionMode:   ptr(batchv1.IndexedCompletion),
+					Parallelism:      ptr(int32(1)),
+					Completions:      ptr(int32(1)),
+					BackoffLimit:     ptr(int32(0)),
+					PodFailurePolicy: data.PodFailurePolicy,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: headLabels, Annotations: headAnnotations},
+						Spec:       pathwaysHeadPodSpec(data),
+					},
+				},
+			},
+		},
+		{
+			Name:     "worker",
+			Replicas: int32(data.NumSlices),
+			Template: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{exclusiveTopologyAnnotation: nodePoolLabel}},
+				Spec: batchv1.JobSpec{
+					CompletionMode:       ptr(batchv1.IndexedCompletion),
+					Parallelism:          ptr(int32(data.NodesPerSlice)),
+					Completions:          ptr(int32(data.NodesPerSlice)),
+					BackoffLimit:         ptr(int32(data.WorkerBackoffLimit)),
+					BackoffLimitPerIndex: ptr(int32(4000)),
+					PodReplacementPolicy: ptr(batchv1.Failed),
+					MaxFailedIndexes:     ptr(int32(0)),
+					PodFailurePolicy:     data.PodFailurePolicy,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: podLabels(data), Annotations: workerAnnotations},
+						Spec:       pathwaysWorkerPodSpec(data),
+					},
+				},
+			},
+		},
+	}
+	return js, nil
+}
+
+// pathwaysHeadPodSpec returns the pod spec of the Pathways head. The proxy and
+// the resource manager are sidecars of the user command, or the containers of
+// the pod when the workload is headless.
+func pathwaysHeadPodSpec(data jobSetTemplateData) corev1.PodSpec {
+	p := data.Pathways
+	var sidecarPolicy *corev1.ContainerRestartPolicy
+	if !p.Headless {
+		sidecarPolicy = ptr(corev1.ContainerRestartPolicyAlways)
+	}
+	coordinatorEnv := func(name string) corev1.EnvVar {
+		return fieldRefEnv(name, "metadata.labels['jobset.sigs.k8s.io/coordinator']")
+	}
+
+	proxyArgs := []string{
+		"--server_port=29000",
+		"--resource_manager_address=$(PATHWAYS_HEAD):29001",
+		"--gcs_scratch_location=" + p.GCSLocation,
+	}
+	if p.ElasticSlices > 0 {
+		proxyArgs = append(proxyArgs, fmt.Sprintf("--num_elastic_slices=%d", p.ElasticSlices))
+	}
+	serverArgs := []string{
+		"--server_port=29001",
+		"--gcs_scratch_location=" + p.GCSLocation,
+		"--node_type=resource_manager",
+		fmt.Sprintf("--instance_count=%d", data.NumSlices),
+		"--instance_type=" + data.PathwaysInstanceType,
+	}
+	components := []corev1.Container{
+		{
+			Name:            "pathways-proxy",
+			Image:           p.ProxyServerImage,
+			ImagePullPolicy: corev1.PullAlways,
+			Ports:           containerPorts(29000),
+			Args:            append(proxyArgs, data.ProxyArgsList...),
+			Env:             append([]corev1.EnvVar{coordinatorEnv("PATHWAYS_HEAD"), pathwaysAbslFlags}, podEnv(data.PathwaysProxyEnv)...),
+			RestartPolicy:   sidecarPolicy,
+			Resources:       resourceLimits("16", "100Gi"),
+		},
+		{
+			Name:            "pathways-rm",
+			Image:           p.ServerImage,
+			ImagePullPolicy: corev1.PullAlways,
+			Ports:           containerPorts(29001, 29002),
+			Args:            append(serverArgs, data.ServerArgsList...),
+			Env: append([]corev1.EnvVar{
+				fieldRefEnv("REPLICATED_JOB_NAME", "metadata.annotations['jobset.sigs.k8s.io/replicatedjob-name']"),
+				fieldRefEnv("JOBSET_NAME", "metadata.annotations['jobset.sigs.k8s.io/jobset-name']"),
+				coordinatorEnv("HOST_ADDRESS"),
+				{Name: "TPU_SKIP_MDS_QUERY", Value: "true"},
+				pathwaysAbslFlags,
+			}, podEnv(data.PathwaysServerEnv)...),
+			RestartPolicy: sidecarPolicy,
+			Resources:     resourceLimits("8", "32Gi"),
+		},
+	}
+
+	spec := corev1.PodSpec{
+		NodeSelector:       map[string]string{nodePoolLabel: p.HeadNodePool},
+		HostNetwork:        true,
+		DNSPolicy:          corev1.DNSClusterFirstWithHostNet,
+		RestartPolicy:      corev1.RestartPolicyNever,
+		PriorityClassName:  data.PriorityClassName,
+		ServiceAccountName: data.ServiceAccountName,
+		ImagePullSecrets:   data.ImagePullSecrets,
+		Volumes:            append([]corev1.Volume{pathwaysSharedTmpVolume()}, data.Volumes...),
+	}
+	if p.Headless {
+		spec.Containers = components
+		return spec
+	}
+	spec.InitContainers = components
+	workload := resourceLimits("24", "100Gi")
+	workload.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	spec.Containers = []corev1.Container{{
+		Name:            "workload-container",
+		Image:           data.FullImageName,
+		ImagePullPolicy: corev1.PullAlways,
+		SecurityContext: &corev1.SecurityContext{Privileged: ptr(true)},
+		Resources:       workload,
+		Env: append([]corev1.EnvVar{
+			coordinatorEnv("PATHWAYS_HEAD"),
+			{Name: "JAX_PLATFORMS", Value: "proxy"},
+			{Name: "XCLOUD_ENVIRONMENT", Value: "GCP"},
+			{Name: "JAX_BACKEND_TARGET", Value: "grpc://$(PATHWAYS_HEAD):29000"},
+			pathwaysAbslFlags,
+		}, podEnv(data.Env)...),
+		Command:      []string{"/bin/bash", "-c", fmt.Sprintf(pathwaysHeadScript, data.CommandToRun)},
+		VolumeMounts: append([]corev1.VolumeMount{{Name: "shared-tmp", MountPath: "/tmp"}}, data.VolumeMounts...),
+	}}
+	return spec
+}
+
+// pathwaysWorkerPodSpec returns the pod spec of the Pathways workers, with
+// the colocated Python sidecar when its image is set and the multi-tier
+// checkpointing volumes when it is enabled.
+func pathwaysWorkerPodSpec(data jobSetTemplateData) corev1.PodSpec {
+	p := data.Pathways
+	mounts := []corev1.VolumeMount{{Name: "shared-tmp", MountPath: "/tmp"}}
+	volumes := []corev1.Volume{pathwaysSharedTmpVolume()}
+	args := []string{
+		"--server_port=29005",
+		"--resource_manager_address=$(PATHWAYS_HEAD):29001",
+		"--gcs_scratch_location=" + p.GCSLocation,
+	}
+	if p.MTCEnabled {
+		mounts = append(mounts,
+			corev1.VolumeMount{Name: "cache", MountPath: p.RamdiskDirectory},
+			corev1.VolumeMount{Name: "sidecar-shared-memory", MountPath: "/tmp/sidecar"},
+		)
+		volumes = append(volumes,
+			corev1.Volume{Name: "cache", VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{Driver: "multitier-checkpoint.csi.storage.gke.io"}}},
+			corev1.Volume{Name: "sidecar-shared-memory", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}}},
+		)
+		args = append(args, "--cloud_pathways_sidecar_shm_directory=/tmp/sidecar")
+	}
+
+	var env []corev1.EnvVar
+	if data.Verbose {
+		env = append(env, corev1.EnvVar{Name: "TPU_MIN_LOG_LEVEL", Value: "0"}, corev1.EnvVar{Name: "TF_CPP_MIN_LOG_LEVEL", Value: "0"})
+	}
+	env = append(env,
+		corev1.EnvVar{Name: "XCLOUD_ENVIRONMENT", Value: "GCP"},
+		corev1.EnvVar{Name: "MEGASCALE_GRPC_ENABLE_XOR_TRACER", Value: "false"},
+		fieldRefEnv("MEGASCALE_NUM_SLICES", "metadata.labels['jobset.sigs.k8s.io/replicatedjob-replicas']"),
+		fieldRefEnv("JOBSET_NAME", "metadata.annotations['jobset.sigs.k8s.io/jobset-name']"),
+		fieldRefEnv("REPLICATED_JOB_NAME", "metadata.annotations['jobset.sigs.k8s.io/replicatedjob-name']"),
+		fieldRefEnv("MEGASCALE_SLICE_ID", "metadata.labels['jobset.sigs.k8s.io/job-index']"),
+		fieldRefEnv("PATHWAYS_HEAD", "metadata.labels['jobset.sigs.k8s.io/coordinator']"),
+		fieldRefEnv("MEGASCALE_COORDINATOR_ADDRESS", "metadata.labels['jobset.sigs.k8s.io/coordinator']"),
+		pathwaysAbslFlags,
+	)
+
+	spec := corev1.PodSpec{
+		HostNetwork:                   true,
+		DNSPolicy:                     corev1.DNSClusterFirstWithHostNet,
+		RestartPolicy:                 corev1.RestartPolicyOnFailure,
+		ServiceAccountName:            data.ServiceAccountName,
+		ImagePullSecrets:              data.ImagePullSecrets,
+		PriorityClassName:             data.PriorityClassName,
+		TerminationGracePeriodSeconds: ptr(int64(data.TerminationGracePeriodSeconds)),
+		Containers: []corev1.Container{{
+			Name:            "pathways-worker",
+			Image:           p.WorkerImage,
+			ImagePullPolicy: corev1.PullAlways,
+			Ports:           containerPorts(29005, 29006, 8471, 8080),
+			Args:            append(args, data.WorkerArgsList...),
+			Env:             append(env, podEnv(data.PathwaysWorkerEnv)...),
+			Resources:       data.Resources,
+			VolumeMounts:    append(mounts, data.VolumeMounts...),
+		}},
+		Volumes:      append(volumes, data.Volumes...),
+		NodeSelector: data.NodeSelector,
+		Affinity:     data.Affinity,
+		Tolerations:  data.Tolerations,
+	}
+	if p.ColocatedPythonSidecarImage != "" {
+		spec.InitContainers = []corev1.Container{{
+			Name:            "colocated-python-sidecar",
+			Image:           p.ColocatedPythonSidecarImage,
+			ImagePullPolicy: corev1.PullAlways,
+			RestartPolicy:   ptr(corev1.ContainerRestartPolicyAlways),
+			Ports:           []corev1.ContainerPort{{ContainerPort: 50051, Protocol: corev1.ProtocolTCP}},
+			Env: []corev1.EnvVar{
+				{Name: "TCMALLOC_RELEASE_RATE", Value: "10"},
+				{Name: "GRPC_SERVER_ADDRESS", Value: "0.0.0.0:50051"},
+				{Name: "CLOUD_PATHWAYS_SIDECAR_SHM_DIRECTORY", Value: "/tmp/sidecar"},
+				{Name: "PYTHONUNBUFFERED", Value: "1"},
+			},
+			VolumeMounts: mounts,
+		}}
+	}
+	return spec
+}
+
+// pathwaysSharedTmpVolume is the node's /tmp, which the Pathways components
+// of a node share.
+func pathwaysSharedTmpVolume() corev1.Volume {
+	return corev1.Volume{Name: "shared-tmp", VolumeSource: corev1.VolumeSource{
+		HostPath: &corev1.HostPathVolumeSource{Path: "/tmp", Type: ptr(corev1.HostPathDirectoryOrCreate)},
+	}}
+}
+
+func fieldRefEnv(name, fieldPath string) corev1.EnvVar {
+	return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: fieldPath}}}
+}
+
+func containerPorts(ports ...int32) []corev1.ContainerPort {
+	res := make([]corev1.ContainerPort, len(ports))
+	for i, p := range ports {
+		res[i] = corev1.ContainerPort{ContainerPort: p}
+	}
+	return res
+}
+
+// resourceLimits returns the CPU and memory limits of a Pathways component.
+func resourceLimits(cpu, memory string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{Limits: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}}
+}
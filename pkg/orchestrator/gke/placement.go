@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// applyCompactPlacement resolves --placement-policy compact into the node
+// selection of the job, so that its pods land on physically adjacent hosts:
+// the node pools of its machine type created with compact placement, or,
+// without any, a placement group Node Auto-Provisioning creates compact node
+// pools for. Since the JobSet is exclusive per node pool, each slice then
+// gets a compact pool of its own.
+func (g *GKEOrchestrator) applyCompactPlacement(schedOpts *SchedulingOptions, job orchestrator.JobDefinition) error {
+	if !strings.EqualFold(job.PlacementPolicy, orchestrator.PlacementPolicyCompact) {
+		return nil
+	}
+	schedOpts.PlacementPolicy = ""
+	if config.IsTPU(job.MachineType) {
+		logging.Info("TPU slices are placed compactly by their node pools; ignoring --placement-policy compact.")
+		return nil
+	}
+	if _, ok := job.NodeConstraint[nodePoolLabel]; ok {
+		return fmt.Errorf("--placement-policy compact selects the node pools itself and cannot be combined with a %s node constraint", nodePoolLabel)
+	}
+
+	pools := g.compactNodePools(job.MachineType)
+	if len(pools) == 0 {
+		if !g.clusterDesc.Autoscaling.EnableNodeAutoprovisioning {
+			return fmt.Errorf("--placement-policy compact: cluster %s has no node pool of machine type %s with compact placement, and Node Auto-Provisioning is disabled. Create one with 'gcloud container node-pools create --placement-type COMPACT'", job.ClusterName, job.MachineType)
+		}
+		// Node Auto-Provisioning creates compact node pools for the pods
+		// selecting a placement group.
+		logging.Info("No node pool of machine type %s has compact placement; Node Auto-Provisioning will create one for placement group %s.", job.MachineType, job.WorkloadName)
+		schedOpts.PlacementPolicy = job.WorkloadName
+		return nil
+	}
+
+	logging.Info("Placing the workload on the compact node pools %s.", strings.Join(pools, ", "))
+	labels := maps.Clone(job.NodeConstraint)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[nodePoolLabel] = strings.Join(pools, "|")
+	schedOpts.NodeAffinityLabels = labels
+	return nil
+}
+
+// compactNodePools returns the node pools of machineType created with
+// compact placement.
+func (g *GKEOrchestrator) compactNodePools(machineType string) []string {
+	var pools []string
+	for _, np := range g.clusterDesc.NodePools {
+		if g.isSystemPool(np) || !strings.EqualFold(np.Config.MachineType, machineType) {
+			continue
+		}
+		if np.PlacementPolicy != nil && strings.EqualFold(np.PlacementPolicy.Type, "COMPACT") {
+			pools = append(pools, np.Name)
+		}
+	}
+	return pools
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestApplyCompactPlacement(t *testing.T) {
+	compact := &gkePlacementPolicy{Type: "COMPACT"}
+	pools := []gkeJobNodePool{
+		{Name: "a3-compact-1", Config: gkeNodePoolConfig{MachineType: "a3-highgpu-8g"}, PlacementPolicy: compact},
+		{Name: "a3-spread", Config: gkeNodePoolConfig{MachineType: "a3-highgpu-8g"}},
+		{Name: "a3-compact-2", Config: gkeNodePoolConfig{MachineType: "a3-highgpu-8g"}, PlacementPolicy: compact},
+		{Name: "g2-compact", Config: gkeNodePoolConfig{MachineType: "g2-standard-24"}, PlacementPolicy: compact},
+	}
+
+	tests := []struct {
+		name          string
+		job           orchestrator.JobDefinition
+		nap           bool
+		wantLabels    map[string]string
+		wantPlacement string
+		wantErr       string
+	}{
+		{
+			name:       "compact node pools",
+			job:        orchestrator.JobDefinition{MachineType: "a3-highgpu-8g", NodeConstraint: map[string]string{"team": "ml"}},
+			wantLabels: map[string]string{"team": "ml", nodePoolLabel: "a3-compact-1|a3-compact-2"},
+		},
+		{
+			name:       "single compact node pool",
+			job:        orchestrator.JobDefinition{MachineType: "g2-standard-24"},
+			wantLabels: map[string]string{nodePoolLabel: "g2-compact"},
+		},
+		{
+			name:          "placement group for node auto-provisioning",
+			job:           orchestrator.JobDefinition{MachineType: "a2-highgpu-8g", WorkloadName: "train"},
+			nap:           true,
+			wantPlacement: "train",
+		},
+		{
+			name:    "no compact node pool",
+			job:     orchestrator.JobDefinition{MachineType: "a2-highgpu-8g", ClusterName: "c"},
+			wantErr: "has no node pool of machine type a2-highgpu-8g with compact placement",
+		},
+		{
+			name:    "node pool constraint",
+			job:     orchestrator.JobDefinition{MachineType: "a3-highgpu-8g", NodeConstraint: map[string]string{nodePoolLabel: "a3-spread"}},
+			wantErr: "cannot be combined",
+		},
+		{
+			name: "tpu",
+			job:  orchestrator.JobDefinition{MachineType: "ct5lp-hightpu-4t"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := &GKEOrchestrator{}
+			g.clusterDesc.NodePools = pools
+			g.clusterDesc.Autoscaling.EnableNodeAutoprovisioning = tc.nap
+			tc.job.PlacementPolicy = "Compact"
+			schedOpts := SchedulingOptions{PlacementPolicy: tc.job.PlacementPolicy, NodeAffinityLabels: tc.job.NodeConstraint}
+
+			err := g.applyCompactPlacement(&schedOpts, tc.job)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if schedOpts.PlacementPolicy != tc.wantPlacement {
+				t.Errorf("PlacementPolicy = %q, want %q", schedOpts.PlacementPolicy, tc.wantPlacement)
+			}
+			if tc.wantLabels != nil && !reflect.DeepEqual(schedOpts.NodeAffinityLabels, tc.wantLabels) {
+				t.Errorf("NodeAffinityLabels = %v, want %v", schedOpts.NodeAffinityLabels, tc.wantLabels)
+			}
+		})
+	}
+
+	g := &GKEOrchestrator{}
+	schedOpts := SchedulingOptions{PlacementPolicy: "my-placement-group"}
+	if err := g.applyCompactPlacement(&schedOpts, orchestrator.JobDefinition{PlacementPolicy: "my-placement-group"}); err != nil || schedOpts.PlacementPolicy != "my-placement-group" {
+		t.Errorf("expected a placement group name to be kept, got %q, %v", schedOpts.PlacementPolicy, err)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// planNode is a node of the cluster with the resources left for new pods.
+type planNode struct {
+	node corev1.Node
+	free resourceList
+}
+
+// PlanPlacement simulates the scheduling of the pods of a manifest on the
+// current nodes of the cluster. The pods are placed first-fit on the nodes
+// that match their node selector and affinity, tolerate their taints and have
+// the resources left by the pods already running; pods that fit nowhere are
+// reported with the reasons each node rejected them.
+func (g *GKEOrchestrator) PlanPlacement(ctx context.Context, opts orchestrator.PlanOptions) (orchestrator.PlacementPlan, error) {
+	m, err := readSpecManifest(opts.SpecPath)
+	if err != nil {
+		return orchestrator.PlacementPlan{}, err
+	}
+
+	job := orchestrator.JobDefinition{ClusterProjectID: opts.ProjectID, ClusterName: opts.ClusterName, ClusterLocation: opts.ClusterLocation}
+	if err := g.populateClusterMetadata(&job); err != nil {
+		return orchestrator.PlacementPlan{}, err
+	}
+	if err := g.configureKubectl(ctx, job.ClusterName, job.ClusterLocation, job.ClusterProjectID); err != nil {
+		return orchestrator.PlacementPlan{}, err
+	}
+
+	var nodes corev1.NodeList
+	if err := g.listResources(ctx, &nodes, nodeGVR, "", metav1.ListOptions{}); err != nil {
+		return orchestrator.PlacementPlan{}, err
+	}
+	var pods corev1.PodList
+	if err := g.listResources(ctx, &pods, podGVR, "", metav1.ListOptions{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"}); err != nil {
+		return orchestrator.PlacementPlan{}, err
+	}
+
+	plan := planPlacement(m.podSets(), planNodes(nodes.Items, pods.Items))
+	plan.Workload, plan.Kind = m.Metadata.Name, m.Kind
+	if note := g.autoscalingNote(plan); note != "" {
+		plan.Notes = append(plan.Notes, note)
+	}
+	return plan, nil
+}
+
+// planNodes returns the nodes sorted by node pool and name, with the
+// allocatable resources that the scheduled pods do not request.
+func planNodes(nodes []corev1.Node, pods []corev1.Pod) []*planNode {
+	used := map[string]resourceList{}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" {
+			continue
+		}
+		if used[p.Spec.NodeName] == nil {
+			used[p.Spec.NodeName] = resourceList{}
+		}
+		used[p.Spec.NodeName].add(podRequests(p.Spec))
+	}
+
+	res := make([]*planNode, 0, len(nodes))
+	for _, n := range nodes {
+		free := resourceList{}
+		for name, q := range n.Status.Allocatable {
+			free[name.String()] = q.MilliValue() - used[n.Name][name.String()]
+		}
+		res = append(res, &planNode{node: n, free: free})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		pi, pj := res[i].node.Labels[nodePoolLabel], res[j].node.Labels[nodePoolLabel]
+		if pi != pj {
+			return pi < pj
+		}
+		return res[i].node.Name < res[j].node.Name
+	})
+	return res
+}
+
+// podRequests is what the scheduler reserves on a node for a pod: the
+// requests of its containers and sidecars, falling back to limits for
+// resources without a request, or of its largest init container if more.
+func podRequests(spec corev1.PodSpec) resourceList {
+	containerRequests := func(c corev1.Container) resourceList {
+		r := resourceList{}
+		for name, q := range c.Resources.Limits {
+			r[name.String()] = q.MilliValue()
+		}
+		for name, q := range c.Resources.Requests {
+			r[name.String()] = q.MilliValue()
+		}
+		return r
+	}
+
+	total := resourceList{}
+	for _, c := range spec.Containers {
+		total.add(containerRequests(c))
+	}
+	sidecars := resourceList{}
+	initMax := resourceList{}
+	for _, c := range spec.InitContainers {
+		r := containerRequests(c)
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			total.add(r)
+			sidecars.add(r)
+			continue
+		}
+		r.add(sidecars)
+		for name, v := range r {
+			initMax[name] = max(initMax[name], v)
+		}
+	}
+	for name, v := range initMax {
+		total[name] = max(total[name], v)
+	}
+	total[corev1.ResourcePods.String()] = 1000
+	return total
+}
+
+// planPlacement places the pods of every pod set on the nodes, taking the
+// resources of each placed pod from its node.
+func planPlacement(podSets []etaPodSet, nodes []*planNode) orchestrator.PlacementPlan {
+	var plan orchestrator.PlacementPlan
+	for _, ps := range podSets {
+		req := podRequests(ps.Template.Spec)
+		psPlan := orchestrator.PodSetPlan{Name: ps.Name, Pods: ps.Count, PodRequests: formatRequests(req)}
+
+		placed := map[*planNode]int{}
+		for i := 0; i < ps.Count; i++ {
+			n := firstFit(ps.Template.Spec, req, nodes)
+			if n == nil {
+				// The remaining pods are identical and the nodes only
+				// have less room, so none of them fits either.
+				psPlan.Unplaced = ps.Count - i
+				psPlan.Reasons = unschedulableReasons(ps.Template.Spec, req, nodes)
+				break
+			}
+			for name, v := range req {
+				n.free[name] -= v
+			}
+			placed[n]++
+		}
+		for _, n := range nodes {
+			if placed[n] > 0 {
+				psPlan.Placements = append(psPlan.Placements, orchestrator.NodePlacement{Node: n.node.Name, NodePool: n.node.Labels[nodePoolLabel], Pods: placed[n]})
+			}
+		}
+		plan.PodSets = append(plan.PodSets, psPlan)
+	}
+	return plan
+}
+
+func firstFit(spec corev1.PodSpec, req resourceList, nodes []*planNode) *planNode {
+	for _, n := range nodes {
+		if len(nodeRejections(spec, req, n)) == 0 {
+			return n
+		}
+	}
+	return nil
+}
+
+// unschedulableReasons counts the nodes per reason they reject a pod for,
+// most frequent first, in the words of the Kubernetes scheduler.
+func unschedulableReasons(spec corev1.PodSpec, req resourceList, nodes []*planNode) []string {
+	if len(nodes) == 0 {
+		return []string{"the cluster has no nodes"}
+	}
+	counts := map[string]int{}
+	for _, n := range nodes {
+		for _, r := range nodeRejections(spec, req, n) {
+			counts[r]++
+		}
+	}
+	reasons := make([]string, 0, len(counts))
+	for r := range counts {
+		reasons = append(reasons, r)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	for i, r := range reasons {
+		reasons[i] = fmt.Sprintf("%d %s", counts[r], r)
+	}
+	return reasons
+}
+
+// nodeRejections returns why a pod cannot be placed on a node; none if it
+// can. Like the scheduler, a node that fails a filter is not checked further.
+func nodeRejections(spec corev1.PodSpec, req resourceList, n *planNode) []string {
+	if n.node.Spec.Unschedulable {
+		return []string{"node(s) were unschedulable"}
+	}
+	if !nodeReady(n.node) {
+		return []string{"node(s) were not ready"}
+	}
+	if !nodeMatchesPod(n.node, spec) {
+		return []string{"node(s) didn't match Pod's node affinity/selector"}
+	}
+	for _, taint := range n.node.Spec.Taints {
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		if !slices.ContainsFunc(spec.Tolerations, func(t corev1.Toleration) bool { return t.ToleratesTaint(&taint) }) {
+			return []string{fmt.Sprintf("node(s) had untolerated taint {%s: %s}", taint.Key, taint.Value)}
+		}
+	}
+	var insufficient []string
+	for name, v := range req {
+		if v > n.free[name] {
+			insufficient = append(insufficient, "Insufficient "+name)
+		}
+	}
+	sort.Strings(insufficient)
+	return insufficient
+}
+
+func nodeReady(n corev1.Node) bool {
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// nodeMatchesPod reports whether a node matches the node selector and the
+// required node affinity of a pod.
+func nodeMatchesPod(n corev1.Node, spec corev1.PodSpec) bool {
+	for k, v := range spec.NodeSelector {
+		if n.Labels[k] != v {
+			return false
+		}
+	}
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	fields := map[string]string{"metadata.name": n.Name}
+	// The terms are ORed; the expressions of a term are ANDed.
+	for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if matchesRequirements(n.Labels, term.MatchExpressions) && matchesRequirements(fields, term.MatchFields) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRequirements(labels map[string]string, reqs []corev1.NodeSelectorRequirement) bool {
+	for _, r := range reqs {
+		v, ok := labels[r.Key]
+		switch r.Operator {
+		case corev1.NodeSelectorOpIn:
+			if !ok || !slices.Contains(r.Values, v) {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if ok && slices.Contains(r.Values, v) {
+				return false
+			}
+		case corev1.NodeSelectorOpExists:
+			if !ok {
+				return false
+			}
+		case corev1.NodeSelectorOpDoesNotExist:
+			if ok {
+				return false
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if !ok || len(r.Values) != 1 {
+				return false
+			}
+			have, err1 := strconv.ParseInt(v, 10, 64)
+			want, err2 := strconv.ParseInt(r.Values[0], 10, 64)
+			if err1 != nil || err2 != nil {
+				return false
+			}
+			if (r.Operator == corev1.NodeSelectorOpGt && have <= want) || (r.Operator == corev1.NodeSelectorOpLt && have >= want) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// formatRequests renders the requests of a pod, e.g. "cpu=4, nvidia.com/gpu=1".
+func formatRequests(req resourceList) string {
+	names := make([]string, 0, len(req))
+	for name := range req {
+		if name != corev1.ResourcePods.String() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + formatQuantity(name, req[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// autoscalingNote tells, when pods do not fit, which node pools the cluster
+// autoscaler could grow to host them.
+func (g *GKEOrchestrator) autoscalingNote(plan orchestrator.PlacementPlan) string {
+	if !slices.ContainsFunc(plan.PodSets, func(ps orchestrator.PodSetPlan) bool { return ps.Unplaced > 0 }) {
+		return ""
+	}
+	if g.napEnabled {
+		return "Node auto-provisioning is enabled: the cluster autoscaler may create a node pool for the pods that do not fit."
+	}
+	var pools []string
+	for _, np := range g.clusterDesc.NodePools {
+		if np.Autoscaling.Enabled {
+			pools = append(pools, np.Name)
+		}
+	}
+	if len(pools) == 0 {
+		return "No node pool has autoscaling enabled: the pods that do not fit stay pending until other pods finish."
+	}
+	return fmt.Sprintf("The cluster autoscaler may add nodes for the pods that do not fit to the autoscaled node pools %s, if they match the pods.", strings.Join(pools, ", "))
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func planTestNode(name, pool, cpu, gpu string, taints ...corev1.Taint) corev1.Node {
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:  resource.MustParse(cpu),
+		corev1.ResourcePods: resource.MustParse("110"),
+	}
+	if gpu != "" {
+		allocatable["nvidia.com/gpu"] = resource.MustParse(gpu)
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{nodePoolLabel: pool}},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: allocatable,
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func planTestPodSpec(cpu, gpu string) corev1.PodSpec {
+	limits := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+	if gpu != "" {
+		limits["nvidia.com/gpu"] = resource.MustParse(gpu)
+	}
+	return corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Resources: corev1.ResourceRequirements{Limits: limits}}}}
+}
+
+var gpuTaint = corev1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule}
+
+func TestPlanPlacement_PacksPodsOntoNodes(t *testing.T) {
+	nodes := planNodes(
+		[]corev1.Node{
+			planTestNode("gpu-b", "gpu-pool", "8", "2", gpuTaint),
+			planTestNode("gpu-a", "gpu-pool", "8", "2", gpuTaint),
+			planTestNode("cpu-a", "default-pool", "4", ""),
+		},
+		// A running pod takes one GPU of gpu-a.
+		[]corev1.Pod{{Spec: func() corev1.PodSpec {
+			s := planTestPodSpec("1", "1")
+			s.NodeName = "gpu-a"
+			return s
+		}()}},
+	)
+	spec := planTestPodSpec("2", "1")
+	spec.Tolerations = []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+
+	plan := planPlacement([]etaPodSet{{Name: "workers", Count: 3, Template: corev1.PodTemplateSpec{Spec: spec}}}, nodes)
+
+	want := orchestrator.PodSetPlan{
+		Name:        "workers",
+		Pods:        3,
+		PodRequests: "cpu=2, nvidia.com/gpu=1",
+		Placements: []orchestrator.NodePlacement{
+			{Node: "gpu-a", NodePool: "gpu-pool", Pods: 1},
+			{Node: "gpu-b", NodePool: "gpu-pool", Pods: 2},
+		},
+	}
+	if len(plan.PodSets) != 1 || !reflect.DeepEqual(plan.PodSets[0], want) {
+		t.Errorf("planPlacement() = %+v, want %+v", plan.PodSets, want)
+	}
+}
+
+func TestPlanPlacement_ReportsWhyPodsDoNotFit(t *testing.T) {
+	cordoned := planTestNode("cordoned", "gpu-pool", "8", "8")
+	cordoned.Spec.Unschedulable = true
+	nodes := planNodes([]corev1.Node{
+		planTestNode("gpu-a", "gpu-pool", "8", "2", gpuTaint),
+		planTestNode("gpu-b", "gpu-pool", "8", "1"),
+		planTestNode("cpu-a", "default-pool", "4", ""),
+		cordoned,
+	}, nil)
+
+	plan := planPlacement([]etaPodSet{{Name: "main-job", Count: 2, Template: corev1.PodTemplateSpec{Spec: planTestPodSpec("2", "2")}}}, nodes)
+
+	ps := plan.PodSets[0]
+	if ps.Unplaced != 2 || len(ps.Placements) != 0 {
+		t.Fatalf("expected both pods to be unplaced, got %+v", ps)
+	}
+	want := []string{
+		"2 Insufficient nvidia.com/gpu",
+		"1 node(s) had untolerated taint {nvidia.com/gpu: present}",
+		"1 node(s) were unschedulable",
+	}
+	if !reflect.DeepEqual(ps.Reasons, want) {
+		t.Errorf("Reasons = %q, want %q", ps.Reasons, want)
+	}
+}
+
+func TestNodeMatchesPod(t *testing.T) {
+	node := planTestNode("n", "spot-pool", "4", "")
+	node.Labels["cloud.google.com/gke-spot"] = "true"
+	node.Labels["cores"] = "16"
+
+	affinity := func(reqs ...corev1.NodeSelectorRequirement) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: reqs}},
+		}}}
+	}
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want bool
+	}{
+		{"no constraints", corev1.PodSpec{}, true},
+		{"matching selector", corev1.PodSpec{NodeSelector: map[string]string{nodePoolLabel: "spot-pool"}}, true},
+		{"other pool", corev1.PodSpec{NodeSelector: map[string]string{nodePoolLabel: "gpu-pool"}}, false},
+		{"exists", corev1.PodSpec{Affinity: affinity(corev1.NodeSelectorRequirement{Key: "cloud.google.com/gke-spot", Operator: corev1.NodeSelectorOpExists})}, true},
+		{"does not exist", corev1.PodSpec{Affinity: affinity(corev1.NodeSelectorRequirement{Key: "cloud.google.com/gke-spot", Operator: corev1.NodeSelectorOpDoesNotExist})}, false},
+		{"not in", corev1.PodSpec{Affinity: affinity(corev1.NodeSelectorRequirement{Key: nodePoolLabel, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"a", "b"}})}, true},
+		{"gt", corev1.PodSpec{Affinity: affinity(corev1.NodeSelectorRequirement{Key: "cores", Operator: corev1.NodeSelectorOpGt, Values: []string{"8"}})}, true},
+		{"lt", corev1.PodSpec{Affinity: affinity(corev1.NodeSelectorRequirement{Key: "cores", Operator: corev1.NodeSelectorOpLt, Values: []string{"8"}})}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := nodeMatchesPod(node, tc.spec); got != tc.want {
+				t.Errorf("nodeMatchesPod() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPodRequests_CountsSidecarsAndInitContainers(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	spec := planTestPodSpec("2", "")
+	spec.InitContainers = []corev1.Container{
+		{Name: "sidecar", RestartPolicy: &always, Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}}},
+		{Name: "setup", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}}},
+	}
+	got := podRequests(spec)
+	// The setup container runs with the sidecar: 4 + 0.5 CPUs, more than
+	// the 2.5 CPUs of the running pod.
+	if got["cpu"] != 4500 || got["pods"] != 1000 {
+		t.Errorf("podRequests() = %v, want cpu 4500 and pods 1000", got)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podLogsOptions selects the container logs streamPodLogs reads.
+type podLogsOptions struct {
+	// container limits the logs to one container of each pod; all
+	// containers are read when it is empty.
+	container string
+	// follow keeps streaming until the containers exit or ctx is done.
+	follow bool
+	// since limits the logs to a relative duration such as "10m", and
+	// sinceTime to the lines logged after a time.
+	since     string
+	sinceTime time.Time
+	// tail limits the logs to the last lines of each container.
+	tail int64
+	// timestamps starts every line with its RFC 3339 timestamp.
+	timestamps bool
+	// prefix starts every line with "[pod/<pod>/<container>] ".
+	prefix bool
+}
+
+// logPodList is the subset of a pod list read by streamPodLogs.
+type logPodList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			Containers []struct {
+				Name string `json:"name"`
+			} `json:"containers"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// podContainer names a container of a pod.
+type podContainer struct {
+	pod, container string
+}
+
+// writePodLogs writes the logs of the pods matching selector in namespace ns
+// to w; see streamPodLogs.
+func (g *GKEOrchestrator) writePodLogs(ctx context.Context, w io.Writer, ns, selector string, opts podLogsOptions) error {
+	return g.streamPodLogs(ctx, ns, selector, opts, func(line string) {
+		fmt.Fprintln(w, line)
+	})
+}
+
+// deploymentLogs returns the last tail lines of the logs of container in the
+// pods of the Deployment name in namespace ns.
+func (g *GKEOrchestrator) deploymentLogs(ctx context.Context, ns, name, container string, tail int64) (string, error) {
+	var deployment struct {
+		Spec struct {
+			Selector metav1.LabelSelector `json:"selector"`
+		} `json:"spec"`
+	}
+	if err := g.getResource(ctx, &deployment, deploymentGVR, ns, name); err != nil {
+		return "", err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&deployment.Spec.Selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector of deployment %s: %w", name, err)
+	}
+	var sb strings.Builder
+	err = g.writePodLogs(ctx, &sb, ns, selector.String(), podLogsOptions{container: container, tail: tail, prefix: true})
+	return sb.String(), err
+}
+
+// jobLogs returns the logs of container in the pods of the Job name in
+// namespace ns.
+func (g *GKEOrchestrator) jobLogs(ctx context.Context, ns, name, container string) (string, error) {
+	var sb strings.Builder
+	err := g.writePodLogs(ctx, &sb, ns, "batch.kubernetes.io/job-name="+name, podLogsOptions{container: container})
+	return sb.String(), err
+}
+
+// streamPodLogs calls onLine with every line of the logs of the containers of
+// the pods matching selector in namespace ns. The containers are read one
+// after the other, or concurrently when following, in which case onLine is
+// still called for one line at a time.
+func (g *GKEOrchestrator) streamPodLogs(ctx context.Context, ns, selector string, opts podLogsOptions, onLine func(string)) error {
+	kube, err := g.getKubeClient()
+	if err != nil {
+		return err
+	}
+	var pods logPodList
+	if err := g.listResources(ctx, &pods, podGVR, ns, metav1.ListOptions{LabelSelector: selector}); err != nil {
+		return err
+	}
+	var streams []podContainer
+	for _, p := range pods.Items {
+		for _, c := range p.Spec.Containers {
+			if opts.container == "" || c.Name == opts.container {
+				streams = append(streams, podContainer{pod: p.Metadata.Name, container: c.Name})
+			}
+		}
+	}
+	if opts.follow && len(streams) > maxLogRequests {
+		return fmt.Errorf("cannot follow %d log streams at once; the maximum is %d", len(streams), maxLogRequests)
+	}
+
+	logOpts := corev1.PodLogOptions{Follow: opts.follow, Timestamps: opts.timestamps}
+	switch {
+	case !opts.sinceTime.IsZero():
+		logOpts.SinceTime = &metav1.Time{Time: opts.sinceTime}
+	case opts.since != "":
+		d, err := time.ParseDuration(opts.since)
+		if err != nil {
+			return fmt.Errorf("invalid log duration %q: %w", opts.since, err)
+		}
+		seconds := int64(d.Seconds())
+		logOpts.SinceSeconds = &seconds
+	}
+	if opts.tail > 0 {
+		logOpts.TailLines = &opts.tail
+	}
+
+	var mu sync.Mutex
+	copyStream := func(s podContainer) error {
+		pod, container := s.pod, s.container
+		containerOpts := logOpts
+		containerOpts.Container = container
+		body, err := kube.PodLogs(ctx, ns, pod, &containerOpts)
+		if err != nil {
+			return fmt.Errorf("failed to get the logs of container %s of pod %s: %w", container, pod, err)
+		}
+		defer body.Close()
+		prefix := ""
+		if opts.prefix {
+			prefix = fmt.Sprintf("[pod/%s/%s] ", pod, container)
+		}
+		r := bufio.NewReader(body)
+		for {
+			line, err := r.ReadString('\n')
+			if line != "" {
+				mu.Lock()
+				onLine(prefix + strings.TrimSuffix(line, "\n"))
+				mu.Unlock()
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read the logs of container %s of pod %s: %w", container, pod, err)
+			}
+		}
+	}
+
+	if !opts.follow {
+		for _, s := range streams {
+			if err := copyStream(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	errs := make([]error, len(streams))
+	var wg sync.WaitGroup
+	for i, s := range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = copyStream(s)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
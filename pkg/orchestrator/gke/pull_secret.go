@@ -0,0 +1,259 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// pullSecretUser is the user name registries of Google Cloud accept
+	// OAuth access tokens with.
+	pullSecretUser = "oauth2accesstoken"
+	// pullSecretRefreshSchedule refreshes the token well before the hour
+	// access tokens are valid for runs out.
+	pullSecretRefreshSchedule = "*/30 * * * *"
+	// dryRunPullToken stands for the token in dry runs, so that no
+	// credentials end up in a printed or saved manifest.
+	dryRunPullToken = "MINTED-AT-SUBMISSION"
+)
+
+// pullSecretRefreshScript mints a new access token with the Google service
+// account of its Kubernetes service account and writes it into the pull
+// secret. Once the workload is gone it deletes the secret and its own CronJob.
+// The gcloud image has no kubectl, so the API server is called with curl.
+const pullSecretRefreshScript = `set -eu
+sa=/var/run/secrets/kubernetes.io/serviceaccount
+ns=$(cat $sa/namespace)
+api() { curl -sS --cacert $sa/ca.crt -H "Authorization: Bearer $(cat $sa/token)" "$@"; }
+base=https://kubernetes.default.svc
+code=$(api -o /dev/null -w '%{http_code}' "$base/$WORKLOAD_PATH/namespaces/$ns/$WORKLOAD_RESOURCE/$WORKLOAD")
+if [ "$code" = 404 ]; then
+  echo "Workload $WORKLOAD is gone; deleting pull secret $SECRET."
+  api -X DELETE "$base/api/v1/namespaces/$ns/secrets/$SECRET" > /dev/null
+  api -X DELETE "$base/apis/batch/v1/namespaces/$ns/cronjobs/$CRONJOB" > /dev/null
+  exit 0
+fi
+token=$(gcloud auth print-access-token)
+config=$(printf '{"auths":{"%s":{"username":"` + pullSecretUser + `","password":"%s"}}}' "$REGISTRY" "$token" | sed 's/"/\\"/g')
+api --fail -X PATCH -H 'Content-Type: application/merge-patch+json' \
+  -d "{\"stringData\":{\".dockerconfigjson\":\"$config\"}}" "$base/api/v1/namespaces/$ns/secrets/$SECRET" > /dev/null
+echo "Refreshed pull secret $SECRET."
+`
+
+// workloadAPIs are the API group, version and resource of each workload kind,
+// which the refresh CronJob looks its workload up with.
+var workloadAPIs = map[string][3]string{
+	orchestrator.WorkloadKindJobSet:     {"jobset.x-k8s.io", "v1alpha2", "jobsets"},
+	orchestrator.WorkloadKindMPI:        {"jobset.x-k8s.io", "v1alpha2", "jobsets"},
+	orchestrator.WorkloadKindJob:        {"batch", "v1", "jobs"},
+	orchestrator.WorkloadKindDeployment: {"apps", "v1", "deployments"},
+	orchestrator.WorkloadKindRayJob:     {"ray.io", "v1", "rayjobs"},
+}
+
+// pullSecretName is the name of the pull secret minted for a workload.
+func pullSecretName(workload string) string {
+	return workload + "-pull"
+}
+
+// imagePullSecrets returns the comma-separated pull secrets of the workload's
+// pods: the ones passed with --image-pull-secret and the minted one.
+func imagePullSecrets(job orchestrator.JobDefinition) string {
+	if !job.MintPullSecret {
+		return job.ImagePullSecrets
+	}
+	return strings.TrimPrefix(job.ImagePullSecrets+","+pullSecretName(job.WorkloadName), ",")
+}
+
+// pullSecretRegistry returns the registry host of image if it accepts Google
+// Cloud access tokens: Artifact Registry and the Container Registry hosts.
+func pullSecretRegistry(image string) (string, error) {
+	if image == "" {
+		return "", fmt.Errorf("--mint-pull-secret requires a workload image")
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference %q: %w", image, err)
+	}
+	host := ref.Context().RegistryStr()
+	if _, ok := gcrRepoLocations[host]; !ok && !strings.HasSuffix(host, "-docker.pkg.dev") {
+		return "", fmt.Errorf("--mint-pull-secret mints Google Cloud access tokens, which registry %s of image %s does not accept", host, image)
+	}
+	return host, nil
+}
+
+// addPullSecret renders a pull secret for the workload image into the
+// additional manifests, holding an access token of the registry reader
+// PullSecretAccount. Nodes whose service account cannot read a registry of
+// another project pull with it instead. The submitter's own token is never
+// used, since anyone who can read the secret would then act as them. The
+// token is valid for about an hour; with RefreshPullSecret a CronJob keeps it
+// fresh for long runs.
+func (g *GKEOrchestrator) addPullSecret(opts *ManifestOptions, job orchestrator.JobDefinition) error {
+	if !job.MintPullSecret {
+		return nil
+	}
+	if !IsServiceAccount(job.PullSecretAccount) {
+		return fmt.Errorf("--mint-pull-secret requires --pull-secret-sa, a Google service account with read access to the registry (roles/artifactregistry.reader) to mint the token as")
+	}
+	registry, err := pullSecretRegistry(opts.FullImageName)
+	if err != nil {
+		return err
+	}
+
+	token := dryRunPullToken
+	if !job.IsDryRun() {
+		res := g.executor.ExecuteCommand("gcloud", "auth", "print-access-token", "--impersonate-service-account="+job.PullSecretAccount)
+		token = strings.TrimSpace(res.Stdout)
+		if res.ExitCode != 0 || token == "" {
+			return fmt.Errorf("failed to mint an access token as %s for the pull secret; it needs the Service Account Token Creator role for you: %s", job.PullSecretAccount, res.Stderr)
+		}
+	}
+	config, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			registry: map[string]string{"username": pullSecretUser, "password": token},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render the pull secret: %w", err)
+	}
+	secret := pullSecretName(opts.WorkloadName)
+	manifest, err := k8syaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":   secret,
+			"labels": map[string]string{"gcluster.google.com/workload": opts.WorkloadName},
+		},
+		"type":       "kubernetes.io/dockerconfigjson",
+		"stringData": map[string]string{".dockerconfigjson": string(config)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render the pull secret: %w", err)
+	}
+	opts.AdditionalManifests = append(opts.AdditionalManifests, string(manifest))
+
+	if !job.RefreshPullSecret {
+		logging.Info("Pull secret %s for %s expires in about an hour; pods started after that cannot pull the image. Pass --refresh-pull-secret to keep it fresh for long runs.", secret, registry)
+		return nil
+	}
+	refresh, err := pullSecretRefreshManifests(opts.WorkloadName, job.WorkloadKind, registry, job.PullSecretAccount)
+	if err != nil {
+		return err
+	}
+	opts.AdditionalManifests = append(opts.AdditionalManifests, refresh...)
+	logging.Info("CronJob %s-refresh refreshes pull secret %s as %s. The service account needs read access to %s and must let Kubernetes service account %s-refresh use it through Workload Identity:\n  gcloud iam service-accounts add-iam-policy-binding %s --role roles/iam.workloadIdentityUser --member \"serviceAccount:%s.svc.id.goog[<namespace>/%s-refresh]\"",
+		secret, secret, job.PullSecretAccount, registry, secret, job.PullSecretAccount, job.ClusterProjectID, secret)
+	return nil
+}
+
+// pullSecretRefreshManifests renders the CronJob that refreshes the pull
+// secret of a workload, and the Kubernetes service account and RBAC rules it
+// runs with. The service account acts as the Google service account gsa.
+func pullSecretRefreshManifests(workload, kind, registry, gsa string) ([]string, error) {
+	if kind == "" {
+		kind = orchestrator.WorkloadKindJobSet
+	}
+	api, ok := workloadAPIs[kind]
+	if !ok {
+		return nil, fmt.Errorf("pull secrets cannot be refreshed for workload kind %q", kind)
+	}
+	apiPath := "apis/" + api[0] + "/" + api[1]
+	secret := pullSecretName(workload)
+	refresher := secret + "-refresh"
+	labels := map[string]string{"gcluster.google.com/workload": workload}
+
+	objects := []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ServiceAccount",
+			"metadata": map[string]interface{}{
+				"name":        refresher,
+				"labels":      labels,
+				"annotations": map[string]string{"iam.gke.io/gcp-service-account": gsa},
+			},
+		},
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "Role",
+			"metadata":   map[string]interface{}{"name": refresher, "labels": labels},
+			"rules": []map[string]interface{}{
+				{"apiGroups": []string{""}, "resources": []string{"secrets"}, "resourceNames": []string{secret}, "verbs": []string{"get", "patch", "delete"}},
+				{"apiGroups": []string{"batch"}, "resources": []string{"cronjobs"}, "resourceNames": []string{refresher}, "verbs": []string{"delete"}},
+				{"apiGroups": []string{api[0]}, "resources": []string{api[2]}, "resourceNames": []string{workload}, "verbs": []string{"get"}},
+			},
+		},
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "RoleBinding",
+			"metadata":   map[string]interface{}{"name": refresher, "labels": labels},
+			"roleRef":    map[string]string{"apiGroup": "rbac.authorization.k8s.io", "kind": "Role", "name": refresher},
+			"subjects":   []map[string]string{{"kind": "ServiceAccount", "name": refresher}},
+		},
+		{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"metadata":   map[string]interface{}{"name": refresher, "labels": labels},
+			"spec": map[string]interface{}{
+				"schedule":                   pullSecretRefreshSchedule,
+				"concurrencyPolicy":          "Forbid",
+				"successfulJobsHistoryLimit": 1,
+				"failedJobsHistoryLimit":     1,
+				"jobTemplate": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"backoffLimit": 2,
+						"template": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"serviceAccountName": refresher,
+								"restartPolicy":      "Never",
+								"containers": []map[string]interface{}{{
+									"name":    "refresh",
+									"image":   cloudSDKImage,
+									"command": []string{"/bin/bash", "-c", pullSecretRefreshScript},
+									"env": []map[string]string{
+										{"name": "WORKLOAD", "value": workload},
+										{"name": "WORKLOAD_PATH", "value": apiPath},
+										{"name": "WORKLOAD_RESOURCE", "value": api[2]},
+										{"name": "SECRET", "value": secret},
+										{"name": "CRONJOB", "value": refresher},
+										{"name": "REGISTRY", "value": registry},
+									},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var manifests []string
+	for _, obj := range objects {
+		b, err := k8syaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the pull secret refresh %s: %w", obj["kind"], err)
+		}
+		manifests = append(manifests, string(b))
+	}
+	return manifests, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+func TestAddPullSecret(t *testing.T) {
+	image := "us-docker.pkg.dev/other-project/repo/app:v1"
+	exec := NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud auth print-access-token --impersonate-service-account=reader@other-project.iam.gserviceaccount.com": {{Stdout: "ya29.token\n"}},
+	})
+	g := newTestGKEOrchestrator(exec)
+	opts := &ManifestOptions{WorkloadName: "train", FullImageName: image}
+	job := orchestrator.JobDefinition{WorkloadName: "train", MintPullSecret: true, PullSecretAccount: "reader@other-project.iam.gserviceaccount.com", ImagePullSecrets: "existing"}
+
+	if err := g.addPullSecret(opts, job); err != nil {
+		t.Fatalf("addPullSecret: %v", err)
+	}
+	if len(opts.AdditionalManifests) != 1 {
+		t.Fatalf("expected only the pull secret without a refresh account, got %d manifests", len(opts.AdditionalManifests))
+	}
+	var secret struct {
+		Metadata   struct{ Name string }
+		Type       string
+		StringData map[string]string `json:"stringData"`
+	}
+	if err := k8syaml.Unmarshal([]byte(opts.AdditionalManifests[0]), &secret); err != nil {
+		t.Fatalf("failed to parse the secret: %v", err)
+	}
+	want := `{"auths":{"us-docker.pkg.dev":{"password":"ya29.token","username":"oauth2accesstoken"}}}`
+	if secret.Metadata.Name != "train-pull" || secret.Type != "kubernetes.io/dockerconfigjson" || secret.StringData[".dockerconfigjson"] != want {
+		t.Errorf("unexpected pull secret: %+v", secret)
+	}
+	if got := imagePullSecrets(job); got != "existing,train-pull" {
+		t.Errorf("imagePullSecrets() = %q, want existing,train-pull", got)
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		exec := NewMockExecutor(nil)
+		opts := &ManifestOptions{WorkloadName: "train", FullImageName: image}
+		job := job
+		job.DryRun = true
+		if err := newTestGKEOrchestrator(exec).addPullSecret(opts, job); err != nil {
+			t.Fatalf("addPullSecret: %v", err)
+		}
+		if len(exec.callCount) != 0 || !strings.Contains(opts.AdditionalManifests[0], dryRunPullToken) {
+			t.Errorf("expected a placeholder token in a dry run, got %s", opts.AdditionalManifests[0])
+		}
+	})
+
+	t.Run("other registry", func(t *testing.T) {
+		opts := &ManifestOptions{WorkloadName: "train", FullImageName: "docker.io/library/python:3.11"}
+		err := g.addPullSecret(opts, job)
+		if err == nil || !strings.Contains(err.Error(), "registry index.docker.io") {
+			t.Errorf("expected an unsupported registry error, got %v", err)
+		}
+	})
+
+	t.Run("without a registry reader", func(t *testing.T) {
+		exec := NewMockExecutor(nil)
+		opts := &ManifestOptions{WorkloadName: "train", FullImageName: image}
+		job := job
+		job.PullSecretAccount = ""
+		err := newTestGKEOrchestrator(exec).addPullSecret(opts, job)
+		if err == nil || !strings.Contains(err.Error(), "requires --pull-secret-sa") {
+			t.Errorf("expected the pull secret to be refused, got %v", err)
+		}
+		if len(exec.callCount) != 0 || len(opts.AdditionalManifests) != 0 {
+			t.Errorf("expected no token to be minted, calls: %v", exec.callCount)
+		}
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		exec := NewMockExecutor(map[string][]shell.CommandResult{
+			"gcloud auth print-access-token --impersonate-service-account=reader@other-project.iam.gserviceaccount.com": {{Stdout: "ya29.token"}},
+		})
+		opts := &ManifestOptions{WorkloadName: "train", FullImageName: "gcr.io/other-project/app"}
+		job := job
+		job.WorkloadKind = orchestrator.WorkloadKindJob
+		job.RefreshPullSecret = true
+		if err := newTestGKEOrchestrator(exec).addPullSecret(opts, job); err != nil {
+			t.Fatalf("addPullSecret: %v", err)
+		}
+		var kinds []string
+		for _, m := range opts.AdditionalManifests {
+			var obj struct{ Kind string }
+			if err := k8syaml.Unmarshal([]byte(m), &obj); err != nil {
+				t.Fatalf("failed to parse manifest: %v", err)
+			}
+			kinds = append(kinds, obj.Kind)
+		}
+		if strings.Join(kinds, ",") != "Secret,ServiceAccount,Role,RoleBinding,CronJob" {
+			t.Errorf("unexpected manifests: %v", kinds)
+		}
+		all := strings.Join(opts.AdditionalManifests, "---\n")
+		for _, want := range []string{
+			"iam.gke.io/gcp-service-account: reader@other-project.iam.gserviceaccount.com",
+			"value: apis/batch/v1",
+			"value: gcr.io",
+			"- jobs",
+		} {
+			if !strings.Contains(all, want) {
+				t.Errorf("expected %q in the refresh manifests:\n%s", want, all)
+			}
+		}
+	})
+}
+
+func TestPullSecretRefreshManifests_UnknownKind(t *testing.T) {
+	if _, err := pullSecretRefreshManifests("train", "notebook", "gcr.io", "sa@p.iam.gserviceaccount.com"); err == nil {
+		t.Error("expected an error for an unknown workload kind")
+	}
+}
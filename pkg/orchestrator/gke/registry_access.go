@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/imagebuilder"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// registryReaderRoles are the roles that allow pulling images from an
+// Artifact Registry repository.
+var registryReaderRoles = []string{
+	"roles/artifactregistry.reader",
+	"roles/artifactregistry.writer",
+	"roles/artifactregistry.repoAdmin",
+	"roles/artifactregistry.admin",
+}
+
+type iamPolicy struct {
+	Bindings []struct {
+		Role    string   `json:"role"`
+		Members []string `json:"members"`
+	} `json:"bindings"`
+}
+
+// grants reports whether the policy grants member one of roles.
+func (p iamPolicy) grants(member string, roles []string) bool {
+	for _, b := range p.Bindings {
+		if slices.Contains(roles, b.Role) && slices.Contains(b.Members, member) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRegistryAccess warns when the workload image is in an Artifact Registry
+// repository of another project than the cluster and the cluster's node
+// service accounts are not granted read access to it. The pods of such a
+// workload fail to pull the image, unless it mints a pull secret.
+func (g *GKEOrchestrator) checkRegistryAccess(job orchestrator.JobDefinition) {
+	if job.MintPullSecret {
+		return
+	}
+	repo, accounts := g.registryAccessGaps(job)
+	for _, sa := range accounts {
+		logging.Warn("Node service account %s of cluster %s (project %s) is not granted read access to %s (project %s), so pods may fail to pull the image. Grant it with:\n  gcloud artifacts repositories add-iam-policy-binding %s --location %s --project %s --member serviceAccount:%s --role roles/artifactregistry.reader\nor submit with --mint-pull-secret and --pull-secret-sa to pull with a token of a service account that can read it.",
+			sa, job.ClusterName, job.ClusterProjectID, repo, repo.Project, repo.Repository, repo.Location, repo.Project, sa)
+	}
+}
+
+// registryAccessGaps returns the cross-project repository of the workload
+// image and the node service accounts that neither it nor its project grant
+// read access to. Grants through groups or folders cannot be seen, and nothing
+// is returned when the policies cannot be read.
+func (g *GKEOrchestrator) registryAccessGaps(job orchestrator.JobDefinition) (imagebuilder.ImageRepo, []string) {
+	repo, ok := workloadRegistryRepo(job)
+	if !ok || repo.Project == job.ClusterProjectID {
+		return repo, nil
+	}
+
+	var policies []iamPolicy
+	for _, args := range [][]string{
+		{"artifacts", "repositories", "get-iam-policy", repo.Repository, "--location", repo.Location, "--project", repo.Project, "--format=json"},
+		{"projects", "get-iam-policy", repo.Project, "--format=json"},
+	} {
+		res := g.executor.ExecuteCommand("gcloud", args...)
+		var p iamPolicy
+		if res.ExitCode != 0 || json.Unmarshal([]byte(res.Stdout), &p) != nil {
+			logging.Info("Could not read the IAM policies of %s to check that cluster %s can pull images from it.", repo, job.ClusterName)
+			return repo, nil
+		}
+		policies = append(policies, p)
+	}
+
+	var missing []string
+	for _, sa := range g.nodeServiceAccounts(job.ClusterProjectID) {
+		member := "serviceAccount:" + sa
+		if !slices.ContainsFunc(policies, func(p iamPolicy) bool { return p.grants(member, registryReaderRoles) }) {
+			missing = append(missing, sa)
+		}
+	}
+	return repo, missing
+}
+
+// gcrRepoLocations are the Artifact Registry locations of the repositories
+// serving the Container Registry hosts, which are named after the host.
+var gcrRepoLocations = map[string]string{
+	"gcr.io":      "us",
+	"us.gcr.io":   "us",
+	"eu.gcr.io":   "europe",
+	"asia.gcr.io": "asia",
+}
+
+// workloadRegistryRepo returns the Artifact Registry repository the workload
+// image is pulled from, if any. Images on gcr.io hosts are pulled from the
+// repository of the host in their project.
+func workloadRegistryRepo(job orchestrator.JobDefinition) (imagebuilder.ImageRepo, bool) {
+	path, err := orchestrator.WorkloadImageRepository(job)
+	if err != nil || path == "" {
+		return imagebuilder.ImageRepo{}, false
+	}
+	parts := strings.Split(path, "/")
+	if location, ok := gcrRepoLocations[parts[0]]; ok && len(parts) >= 2 {
+		return imagebuilder.ImageRepo{Location: location, Project: parts[1], Repository: parts[0]}, true
+	}
+	if len(parts) < 3 {
+		return imagebuilder.ImageRepo{}, false
+	}
+	repo, err := imagebuilder.ParseImageRepo(strings.Join(parts[:3], "/"))
+	return repo, err == nil
+}
+
+// nodeServiceAccounts returns the service accounts of the cluster's nodes.
+// Nodes without one run as the Compute Engine default service account of
+// projectID.
+func (g *GKEOrchestrator) nodeServiceAccounts(projectID string) []string {
+	var configured []string
+	for _, np := range g.clusterDesc.NodePools {
+		configured = append(configured, np.Config.ServiceAccount)
+	}
+	if g.clusterDesc.Autoscaling.EnableNodeAutoprovisioning || g.clusterDesc.Autopilot.Enabled {
+		configured = append(configured, g.clusterDesc.Autoscaling.AutoprovisioningNodePoolDefaults.ServiceAccount)
+	}
+	for i, sa := range configured {
+		if sa == "" {
+			configured[i] = "default"
+		}
+	}
+	slices.Sort(configured)
+
+	var accounts []string
+	for _, sa := range slices.Compact(configured) {
+		if sa == "default" {
+			res := g.executor.ExecuteCommand("gcloud", "projects", "describe", projectID, "--format=value(projectNumber)")
+			number := strings.TrimSpace(res.Stdout)
+			if res.ExitCode != 0 || number == "" {
+				continue
+			}
+			sa = fmt.Sprintf("%s-compute@developer.gserviceaccount.com", number)
+		}
+		accounts = append(accounts, sa)
+	}
+	return accounts
+}
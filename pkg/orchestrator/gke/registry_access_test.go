@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestRegistryAccessGaps(t *testing.T) {
+	const (
+		repoPolicyCmd    = "gcloud artifacts repositories get-iam-policy images --location us --project build-project --format=json"
+		projectPolicyCmd = "gcloud projects get-iam-policy build-project --format=json"
+		projectNumberCmd = "gcloud projects describe cluster-project --format=value(projectNumber)"
+	)
+	job := orchestrator.JobDefinition{
+		ClusterName:      "my-cluster",
+		ClusterProjectID: "cluster-project",
+		ImageName:        "us-docker.pkg.dev/build-project/images/trainer:v1",
+	}
+	nodePools := []gkeJobNodePool{
+		{Config: gkeNodePoolConfig{ServiceAccount: "nodes@cluster-project.iam.gserviceaccount.com"}},
+		{Config: gkeNodePoolConfig{ServiceAccount: "default"}},
+		{Config: gkeNodePoolConfig{}},
+	}
+
+	tests := []struct {
+		name      string
+		job       orchestrator.JobDefinition
+		responses map[string][]shell.CommandResult
+		want      []string
+	}{
+		{
+			name: "same project",
+			job: orchestrator.JobDefinition{
+				ClusterProjectID: "build-project",
+				ImageName:        "us-docker.pkg.dev/build-project/images/trainer:v1",
+			},
+		},
+		{
+			name: "not an artifact registry image",
+			job:  orchestrator.JobDefinition{ClusterProjectID: "cluster-project", ImageName: "busybox"},
+		},
+		{
+			name: "policies cannot be read",
+			job:  job,
+		},
+		{
+			name: "granted on the repository and the project",
+			job:  job,
+			responses: map[string][]shell.CommandResult{
+				repoPolicyCmd:    {{ExitCode: 0, Stdout: `{"bindings":[{"role":"roles/artifactregistry.reader","members":["serviceAccount:nodes@cluster-project.iam.gserviceaccount.com"]}]}`}},
+				projectPolicyCmd: {{ExitCode: 0, Stdout: `{"bindings":[{"role":"roles/artifactregistry.admin","members":["serviceAccount:123-compute@developer.gserviceaccount.com"]}]}`}},
+				projectNumberCmd: {{ExitCode: 0, Stdout: "123\n"}},
+			},
+		},
+		{
+			name: "gcr.io image not granted",
+			job: orchestrator.JobDefinition{
+				ClusterName:      "my-cluster",
+				ClusterProjectID: "cluster-project",
+				ImageName:        "gcr.io/build-project/trainer:v1",
+			},
+			responses: map[string][]shell.CommandResult{
+				"gcloud artifacts repositories get-iam-policy gcr.io --location us --project build-project --format=json": {{ExitCode: 0, Stdout: `{}`}},
+				projectPolicyCmd: {{ExitCode: 0, Stdout: `{"bindings":[{"role":"roles/artifactregistry.reader","members":["serviceAccount:123-compute@developer.gserviceaccount.com"]}]}`}},
+				projectNumberCmd: {{ExitCode: 0, Stdout: "123\n"}},
+			},
+			want: []string{"nodes@cluster-project.iam.gserviceaccount.com"},
+		},
+		{
+			name: "not granted",
+			job:  job,
+			responses: map[string][]shell.CommandResult{
+				repoPolicyCmd:    {{ExitCode: 0, Stdout: `{"bindings":[{"role":"roles/viewer","members":["serviceAccount:nodes@cluster-project.iam.gserviceaccount.com"]}]}`}},
+				projectPolicyCmd: {{ExitCode: 0, Stdout: `{}`}},
+				projectNumberCmd: {{ExitCode: 0, Stdout: "123\n"}},
+			},
+			want: []string{"123-compute@developer.gserviceaccount.com", "nodes@cluster-project.iam.gserviceaccount.com"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := newTestGKEOrchestrator(NewMockExecutor(tc.responses))
+			g.clusterDesc.NodePools = nodePools
+			_, got := g.registryAccessGaps(tc.job)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("registryAccessGaps() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"strings"
+
+	"github.com/google/safetext/yamltemplate"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// pathwaysRenderer is the renderer key of Pathways JobSets, which are chosen
+// with --pathways rather than --workload-kind.
+const pathwaysRenderer = "pathways-jobset"
+
+// ManifestRenderer renders one Kubernetes workload kind from the template data
+// shared by every kind.
+type ManifestRenderer interface {
+	Render(data jobSetTemplateData) (string, error)
+}
+
+// templateRenderer renders an embedded template. The pod spec fields of
+// jobSetTemplateData are passed to it as YAML blocks indented for the pod spec
+// of a JobSet; podIndentShift moves them to the depth of the pod spec in this
+// template.
+type templateRenderer struct {
+	file           string
+	podIndentShift int
+}
+
+var manifestRenderers = map[string]ManifestRenderer{
+	orchestrator.WorkloadKindJobSet:     typedJobSetRenderer{build: buildJobSet},
+	orchestrator.WorkloadKindJob:        templateRenderer{file: "job.tmpl", podIndentShift: -8},
+	orchestrator.WorkloadKindDeployment: templateRenderer{file: "deployment.tmpl", podIndentShift: -8},
+	orchestrator.WorkloadKindRayJob:     templateRenderer{file: "rayjob.tmpl", podIndentShift: -4},
+	orchestrator.WorkloadKindMPI:        typedJobSetRenderer{build: buildMPIJobSet},
+	pathwaysRenderer:                    typedJobSetRenderer{build: buildPathwaysJobSet},
+}
+
+// rendererFor returns the renderer of a workload kind; an empty kind is a JobSet.
+func rendererFor(kind string) (ManifestRenderer, error) {
+	if kind == "" {
+		kind = orchestrator.WorkloadKindJobSet
+	}
+	r, ok := manifestRenderers[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+	return r, nil
+}
+
+func (r templateRenderer) Render(data jobSetTemplateData) (string, error) {
+	tmpl, err := yamltemplate.New(r.file).ParseFS(templatesFS, "templates/"+r.file)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %w", strings.TrimSuffix(r.file, ".tmpl"), err)
+	}
+	blocks, err := newTemplateBlocks(data, r.podIndentShift)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, blocks); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", strings.TrimSuffix(r.file, ".tmpl"), err)
+	}
+	return buf.String(), nil
+}
+
+// templateBlocks is the data of a text template: jobSetTemplateData with its
+// pod spec fields, which it shadows, marshalled to YAML blocks.
+type templateBlocks struct {
+	jobSetTemplateData
+	Containers         []templateContainer
+	PodFailurePolicy   string
+	TopologyAnnotation string
+	SchedulingGates    string // Carries its schedulingGates key.
+	NodeSelector       string
+	Affinity           string
+	Tolerations        string
+	ImagePullSecrets   string
+	VolumesYAML        string
+	VolumeMountsYAML   string
+	LogArchiverYAML    string
+	ProbesYAML         string // Carries its startupProbe and readinessProbe keys.
+}
+
+type templateContainer struct {
+	Name          string
+	ResourcesYAML string // Carries its resources key.
+}
+
+// newTemplateBlocks marshals the pod spec fields of data at their depth in the
+// pod spec of a JobSet, moved by podIndentShift.
+func newTemplateBlocks(data jobSetTemplateData, podIndentShift int) (templateBlocks, error) {
+	blocks := templateBlocks{jobSetTemplateData: data}
+	var probes map[string]*corev1.Probe
+	if data.StartupProbe != nil {
+		probes = map[string]*corev1.Probe{"startupProbe": data.StartupProbe, "readinessProbe": data.ReadinessProbe}
+	}
+	var schedulingGates map[string][]corev1.PodSchedulingGate
+	if len(data.SchedulingGates) > 0 {
+		schedulingGates = map[string][]corev1.PodSchedulingGate{"schedulingGates": data.SchedulingGates}
+	}
+	var logArchiver []corev1.Container
+	if data.LogArchiver != nil {
+		logArchiver = []corev1.Container{*data.LogArchiver}
+	}
+	for _, b := range []struct {
+		out    *string
+		value  any
+		empty  bool
+		indent int
+	}{
+		{&blocks.PodFailurePolicy, data.PodFailurePolicy, data.PodFailurePolicy == nil, 12},
+		{&blocks.TopologyAnnotation, data.TopologyAnnotations, len(data.TopologyAnnotations) == 0, 16},
+		{&blocks.SchedulingGates, schedulingGates, schedulingGates == nil, 14},
+		{&blocks.NodeSelector, data.NodeSelector, len(data.NodeSelector) == 0, 16},
+		{&blocks.Affinity, data.Affinity, data.Affinity == nil, 16},
+		{&blocks.Tolerations, data.Tolerations, len(data.Tolerations) == 0, 16},
+		{&blocks.ImagePullSecrets, data.ImagePullSecrets, len(data.ImagePullSecrets) == 0, 16},
+		{&blocks.VolumesYAML, data.Volumes, len(data.Volumes) == 0, 14},
+		{&blocks.VolumeMountsYAML, data.VolumeMounts, len(data.VolumeMounts) == 0, 16},
+		{&blocks.LogArchiverYAML, logArchiver, logArchiver == nil, 14},
+		{&blocks.ProbesYAML, probes, probes == nil, 16},
+	} {
+		if b.empty {
+			continue
+		}
+		var err error
+		if *b.out, err = yamlBlock(b.value, b.indent+podIndentShift); err != nil {
+			return templateBlocks{}, err
+		}
+	}
+	for _, c := range data.Containers {
+		tc := templateContainer{Name: c.Name}
+		if len(c.Resources.Limits) > 0 || len(c.Resources.Requests) > 0 {
+			resources, err := yamlBlock(map[string]corev1.ResourceRequirements{"resources": c.Resources}, 16+podIndentShift)
+			if err != nil {
+				return templateBlocks{}, err
+			}
+			tc.ResourcesYAML = resources
+		}
+		blocks.Containers = append(blocks.Containers, tc)
+	}
+	return blocks, nil
+}
+
+// yamlBlock marshals v to a YAML block indented by indent spaces.
+func yamlBlock(v any, indent int) (string, error) {
+	y, err := marshalManifest(v)
+	if err != nil {
+		return "", err
+	}
+	return indentYaml(y, indent), nil
+}
@@ -0,0 +1,326 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	corev1 "k8s.io/api/core/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden manifests in testdata/golden")
+
+// goldenTemplateData sets every field a renderer can emit, so a field that is
+// added to the template data but dropped by one of the renderers shows up as a
+// golden diff.
+func goldenTemplateData(t *testing.T) jobSetTemplateData {
+	t.Helper()
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+
+	opts := ManifestOptions{
+		WorkloadName:                  "golden-job",
+		FullImageName:                 "us-docker.pkg.dev/my-project/repo/trainer:v1",
+		BaseImage:                     "python:3.11",
+		BaseImageDigest:               "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		CapturedEnv:                   "conda:ml",
+		CapturedEnvDigest:             "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210",
+		ManagedProfile:                "ml-research",
+		Subdomain:                     "golden-job",
+		Inputs:                        inputsAnnotation([]orchestrator.DataInput{{Name: "TRAIN_DATA", URI: "gs://data/train.tfrecord", Generation: "1712345678901234", MD5: "XUFAKrxLKna5cZ2REBfFkg=="}}),
+		CommandArgs:                   []string{"python", "train.py", "--epochs", "3"},
+		ProjectID:                     "my-project",
+		ClusterName:                   "my-cluster",
+		KueueQueueName:                "lq",
+		NumSlices:                     1,
+		NodesPerSlice:                 2,
+		ParallelContainers:            2,
+		MaxRestarts:                   3,
+		TtlSecondsAfterFinished:       3600,
+		TerminationGracePeriodSeconds: 30,
+		ServiceAccountName:            "trainer",
+		SchedulerName:                 "gke.io/topology-aware-auto",
+		PriorityClassName:             "high",
+		Verbose:                       true,
+		Env:                           map[string]string{"LOG_LEVEL": "debug", "DATA_DIR": "/data"},
+		FieldEnv:                      map[string]string{"JOB_INDEX": jobIndexFieldPath},
+		SecretEnv:                     []EnvVar{{Name: "WANDB_API_KEY", SecretName: "golden-job-secret-env", SecretKey: "WANDB_API_KEY"}},
+		CostLabels:                    map[string]string{"team": "ml", "experiment": "golden", "user": "alice"},
+		ResourceLabels:                map[string]string{"accelerator": "nvidia-l4", "provisioning": "spot"},
+		NodeSelector:                  map[string]string{"cloud.google.com/gke-tpu-accelerator": "tpu-v5-lite-podslice"},
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "cloud.google.com/gke-spot", Operator: corev1.NodeSelectorOpDoesNotExist}}}},
+		}}},
+		SchedulingGates: []corev1.PodSchedulingGate{{Name: "gke.io/topology-aware-auto-golden-job"}},
+		MPI: MPIOptions{
+			SSHSecret:       "golden-job-ssh",
+			HostfileScript:  ": > /etc/mpi/hostfile\nfor host in golden-job-worker-0-0.golden-job; do\n  echo \"$host slots=1\" >> /etc/mpi/hostfile\ndone",
+			LauncherCommand: "mpirun --hostfile /etc/mpi/hostfile -np 2 -x LOG_LEVEL python train.py",
+		},
+		Sidecars: []orchestrator.ContainerSpec{{Name: "tensorboard", Image: "tensorflow/tensorflow:2.16.1", Command: "tensorboard --logdir /data/logs --bind_all"}},
+	}
+
+	opts.PodFailurePolicy = g.generatePodFailurePolicy([]int{42})
+	opts.ImagePullSecrets = g.generateImagePullSecrets("regcred")
+	opts.TopologyAnnotations = g.buildTopologyAnnotations("2x4", "ct5lp-hightpu-4t", 1, 2, false)
+	opts.Tolerations = g.resolveTolerations("tpu-v5-lite-podslice", "spot", "")
+	(&StorageManager{}).AddVolumeOptions(&opts, []MountInfo{{Name: "data", Source: "my-bucket", MountPath: "/data", Type: "gcsfuse"}})
+	if err := addLogArchiver(&opts, &orchestrator.LogArchive{Kind: orchestrator.LogArchiveGCS, Bucket: "my-logs", Prefix: "archive"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := buildResources(ResourceSpec{TPU: "4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g.prepareJobSetTemplateData(opts, resources, true, false)
+}
+
+// goldenPathwaysTemplateData is goldenTemplateData as GeneratePathwaysManifest
+// prepares it: Pathways images are set and resources are set for the Pathways
+// worker container.
+func goldenPathwaysTemplateData(t *testing.T) jobSetTemplateData {
+	t.Helper()
+	data := goldenTemplateData(t)
+	data.Pathways = orchestrator.PathwaysJobDefinition{
+		ProxyServerImage: defaultPathwaysProxyImage,
+		ServerImage:      defaultPathwaysServerImage,
+		WorkerImage:      defaultPathwaysServerImage,
+	}
+	data.Resources = data.Containers[0].Resources
+	return data
+}
+
+func TestManifestRenderersGolden(t *testing.T) {
+	data := goldenTemplateData(t)
+	pathwaysData := goldenPathwaysTemplateData(t)
+
+	kinds := make([]string, 0, len(manifestRenderers))
+	for kind := range manifestRenderers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		t.Run(kind, func(t *testing.T) {
+			d := data
+			if kind == pathwaysRenderer {
+				d = pathwaysData
+			}
+			got, err := manifestRenderers[kind].Render(d)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertValidYAML(t, got)
+
+			path := filepath.Join("testdata", "golden", kind+".yaml")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s manifest differs from %s (run with -update to accept):\n%s", kind, path, lineDiff(string(want), got))
+			}
+		})
+	}
+}
+
+func TestManifestRenderers_NoQueue(t *testing.T) {
+	data := goldenTemplateData(t)
+	data.KueueQueueName = ""
+	pathwaysData := goldenPathwaysTemplateData(t)
+	pathwaysData.KueueQueueName = ""
+	for kind, r := range manifestRenderers {
+		d := data
+		if kind == pathwaysRenderer {
+			d = pathwaysData
+		}
+		got, err := r.Render(d)
+		if err != nil {
+			t.Fatalf("%s: Render() error = %v", kind, err)
+		}
+		if strings.Contains(got, "kueue.x-k8s.io/queue-name") {
+			t.Errorf("%s: expected no queue label without a queue, got:\n%s", kind, got)
+		}
+	}
+}
+
+func TestManifestRenderers_RestartPolicy(t *testing.T) {
+	data := goldenTemplateData(t)
+	data.RestartPolicy = orchestrator.RestartPolicyOnFailure
+	data.PodBackoffLimit = 5
+	for kind, want := range map[string][]string{
+		orchestrator.WorkloadKindJobSet: {"restartPolicy: OnFailure", "backoffLimit: 5\n"},
+		orchestrator.WorkloadKindJob:    {"restartPolicy: OnFailure", "backoffLimit: 3\n"},
+	} {
+		got, err := manifestRenderers[kind].Render(data)
+		if err != nil {
+			t.Fatalf("%s: Render() error = %v", kind, err)
+		}
+		for _, w := range want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s: expected %q in:\n%s", kind, w, got)
+			}
+		}
+	}
+}
+
+// adversarialCommands are commands with characters that are special to YAML,
+// Go templates or the shell.
+var adversarialCommands = []string{
+	`echo "double \"quoted\"" 'single' $(date) ${HOME} \back\\slash`,
+	"python -c 'print(\"a\\nb\")'\ncat <<EOF\n  indented: {x: [1]}\nEOF",
+	"echo '{{ .WorkloadName }}' # not a comment: - [x] {a: b} ) (",
+	"echo tab\there \x07bell é 😀 \u2028separator",
+	"- name: injected\n  image: evil:latest",
+}
+
+// TestManifestRenderers_CommandsRoundTrip renders every workload kind with
+// adversarial commands and checks that the decoded manifest runs the command
+// unchanged.
+func TestManifestRenderers_CommandsRoundTrip(t *testing.T) {
+	for _, command := range adversarialCommands {
+		for kind, r := range manifestRenderers {
+			data := goldenTemplateData(t)
+			if kind == pathwaysRenderer {
+				data = goldenPathwaysTemplateData(t)
+			}
+			data.Command, data.Args = containerCommand(command, nil)
+			data.Entrypoint = rayEntrypoint(command, nil)
+			data.CommandToRun = pathwaysCommand(command, nil)
+			data.MPI.LauncherCommand = "mpirun -np 2 /bin/bash -c " + shellQuote(command)
+
+			got, err := r.Render(data)
+			if err != nil {
+				t.Fatalf("%s: Render(%q) error = %v", kind, command, err)
+			}
+			assertValidYAML(t, got)
+
+			want := slices.Concat(data.Command, data.Args)
+			var found []string
+			switch kind {
+			case orchestrator.WorkloadKindRayJob:
+				want = []string{data.Entrypoint}
+			case orchestrator.WorkloadKindMPI:
+				want = []string{data.MPI.LauncherCommand}
+			case pathwaysRenderer:
+				want = []string{command}
+				found = []string{decodePathwaysCommand(t, got)}
+			}
+			if found == nil {
+				found = manifestStrings(t, got)
+			}
+			if !slices.Contains(found, want[len(want)-1]) {
+				t.Errorf("%s: command %q does not round-trip; manifest:\n%s", kind, command, got)
+			}
+		}
+	}
+}
+
+// manifestStrings returns every string value of a multi-document manifest.
+func manifestStrings(t *testing.T, manifest string) []string {
+	t.Helper()
+	var out []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			out = append(out, v)
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+	for {
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("failed to decode manifest: %v", err)
+			}
+			return out
+		}
+		walk(obj)
+	}
+}
+
+func TestRendererFor(t *testing.T) {
+	for _, kind := range append([]string{""}, orchestrator.WorkloadKinds...) {
+		if _, err := rendererFor(kind); err != nil {
+			t.Errorf("rendererFor(%q) error = %v", kind, err)
+		}
+	}
+	if _, err := rendererFor("statefulset"); err == nil {
+		t.Error("expected an error for an unsupported workload kind")
+	}
+}
+
+func assertValidYAML(t *testing.T, manifest string) {
+	t.Helper()
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+	for {
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			t.Fatalf("rendered manifest is not valid YAML: %v\n%s", err, manifest)
+		}
+	}
+}
+
+// lineDiff lists the lines that differ between two manifests.
+func lineDiff(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var sb strings.Builder
+	for i := 0; i < max(len(w), len(g)); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			fmt.Fprintf(&sb, "line %d:\n  want: %s\n  got:  %s\n", i+1, wl, gl)
+		}
+	}
+	return sb.String()
+}
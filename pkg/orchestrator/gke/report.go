@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// defaultReportLogLines is the number of log lines per container read when
+	// ReportOptions.LogTailLines is not set.
+	defaultReportLogLines = 200
+	// maxReportErrorLines and reportLastLines bound the log excerpts of a report.
+	maxReportErrorLines = 50
+	reportLastLines     = 20
+	gclusterPrefix      = "gcluster.google.com/"
+)
+
+// reportErrorLine matches the log lines that are quoted as errors in a report.
+var reportErrorLine = regexp.MustCompile(`(?i)\b(error|exception|traceback|fatal|panic)\b`)
+
+// acceleratorResources are the resources whose chip-hours are reported.
+var acceleratorResources = map[corev1.ResourceName]bool{
+	"nvidia.com/gpu": true,
+	"google.com/tpu": true,
+}
+
+type reportJobSet struct {
+	jobSet
+	Status struct {
+		Conditions []kueueWorkloadCondition `json:"conditions"`
+	} `json:"status"`
+}
+
+// ReportJob assembles the run report of a workload from its JobSet, its
+// description and the tail of its logs. Logs that cannot be read are left out
+// of the report rather than failing it.
+func (g *GKEOrchestrator) ReportJob(ctx context.Context, name string, opts orchestrator.ReportOptions) (orchestrator.RunReport, error) {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return orchestrator.RunReport{}, err
+	}
+	ns, err := g.getJobNamespace(ctx, name)
+	if err != nil {
+		return orchestrator.RunReport{}, err
+	}
+	desc, err := g.collectDescription(ctx, name, ns)
+	if err != nil {
+		return orchestrator.RunReport{}, err
+	}
+
+	var js reportJobSet
+	if err := g.getResource(ctx, &js, jobSetGVR, ns, name); err != nil {
+		return orchestrator.RunReport{}, err
+	}
+
+	report := orchestrator.RunReport{
+		Name:        name,
+		Namespace:   ns,
+		ClusterName: opts.ClusterName,
+		Location:    opts.ClusterLocation,
+		ProjectID:   opts.ProjectID,
+		Status:      desc.Status.Status,
+		Labels:      map[string]string{},
+		Annotations: map[string]string{},
+		Created:     js.CreationTimestamp.UTC(),
+		Diagnosis:   desc.Diagnosis,
+	}
+	addPrefixed(report.Labels, js.Labels)
+	addPrefixed(report.Annotations, js.Annotations)
+	delete(report.Labels, "workload")
+	report.Started, report.Finished = runTimes(js, desc, report.Created)
+	report.Containers, report.Resources = reportContainers(js)
+	for _, rj := range js.Spec.ReplicatedJobs {
+		addPrefixed(report.Labels, rj.Template.Spec.Template.Labels)
+		delete(report.Labels, "workload")
+		for _, c := range rj.Template.Spec.Template.Spec.Containers {
+			for _, e := range c.Env {
+				if e.Name == orchestrator.EnvRunID && report.RunID == "" {
+					report.RunID = e.Value
+				}
+			}
+		}
+	}
+
+	tail := opts.LogTailLines
+	if tail <= 0 {
+		tail = defaultReportLogLines
+	}
+	var logs strings.Builder
+	err = g.writePodLogs(ctx, &logs, ns, "jobset.sigs.k8s.io/jobset-name="+name, podLogsOptions{tail: int64(tail), prefix: true})
+	if err != nil {
+		logging.Warn("Could not read the logs of %s, the report has no log excerpts: %v", name, err)
+	} else {
+		report.ErrorLines, report.LastLines = logExcerpts(logs.String())
+	}
+
+	_, isPathways := replicatedJobNamed(js, "pathways-head")
+	report.WorkloadURL, report.LogsURL = consoleLinks(orchestrator.JobDefinition{
+		WorkloadName:     name,
+		ClusterName:      opts.ClusterName,
+		ClusterLocation:  opts.ClusterLocation,
+		ClusterProjectID: opts.ProjectID,
+		IsPathwaysJob:    isPathways,
+	})
+	return report, nil
+}
+
+// addPrefixed copies the gcluster.google.com entries of from into to, without
+// their prefix.
+func addPrefixed(to, from map[string]string) {
+	for k, v := range from {
+		if key, ok := strings.CutPrefix(k, gclusterPrefix); ok {
+			to[key] = v
+		}
+	}
+}
+
+func replicatedJobNamed(js reportJobSet, name string) (replicatedJob, bool) {
+	for _, rj := range js.Spec.ReplicatedJobs {
+		if rj.Name == name {
+			return rj, true
+		}
+	}
+	return replicatedJob{}, false
+}
+
+// runTimes returns when the workload was admitted by Kueue, or created when it
+// was not queued, and when its JobSet completed or failed.
+func runTimes(js reportJobSet, desc orchestrator.WorkloadDescription, created time.Time) (started, finished time.Time) {
+	started = created
+	if len(desc.KueueConditions) > 0 {
+		started = time.Time{}
+		if c := findCondition(desc.KueueConditions, "Admitted"); c != nil && c.Status == "True" {
+			started = parseConditionTime(c.LastTransitionTime)
+		}
+	}
+	for _, c := range js.Status.Conditions {
+		if (c.Type == "Completed" || c.Type == "Failed") && c.Status == "True" {
+			finished = parseConditionTime(c.LastTransitionTime)
+		}
+	}
+	return started, finished
+}
+
+func parseConditionTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// reportContainers returns the main container of each replicated job and the
+// resources requested by all pods of the workload.
+func reportContainers(js reportJobSet) ([]orchestrator.ReportContainer, []orchestrator.ReportResource) {
+	var containers []orchestrator.ReportContainer
+	totals := map[corev1.ResourceName]*resource.Quantity{}
+	for _, rj := range js.Spec.ReplicatedJobs {
+		pods := int64(max(rj.Replicas, 1))
+		if p := rj.Template.Spec.Parallelism; p != nil {
+			pods *= int64(*p)
+		}
+		spec := rj.Template.Spec.Template.Spec
+		if len(spec.Containers) == 0 {
+			continue
+		}
+		main := spec.Containers[0]
+		containers = append(containers, orchestrator.ReportContainer{
+			ReplicatedJob: rj.Name,
+			Pods:          int(pods),
+			Image:         main.Image,
+			Command:       append(append([]string(nil), main.Command...), main.Args...),
+			Env:           reportEnv(main.Env),
+		})
+		for _, c := range spec.Containers {
+			requests := c.Resources.Requests
+			if len(requests) == 0 {
+				requests = c.Resources.Limits
+			}
+			for name, q := range requests {
+				total := totals[name]
+				if total == nil {
+					total = resource.NewQuantity(0, q.Format)
+					totals[name] = total
+				}
+				for range pods {
+					total.Add(q)
+				}
+			}
+		}
+	}
+
+	var resources []orchestrator.ReportResource
+	for name, q := range totals {
+		r := orchestrator.ReportResource{Name: string(name), Total: q.String()}
+		if acceleratorResources[name] {
+			r.Accelerator, r.Count = true, q.Value()
+		}
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	return containers, resources
+}
+
+// reportEnv returns the env of a container with secret-looking values
+// redacted and references to Secrets and fields named instead of resolved.
+func reportEnv(env []corev1.EnvVar) map[string]string {
+	if len(env) == 0 {
+		return nil
+	}
+	out := map[string]string{}
+	for _, e := range env {
+		v := e.Value
+		switch {
+		case e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil:
+			v = orchestrator.RedactedValue
+		case e.ValueFrom != nil && e.ValueFrom.FieldRef != nil:
+			v = "(field " + e.ValueFrom.FieldRef.FieldPath + ")"
+		case orchestrator.IsSensitiveName(e.Name):
+			v = orchestrator.RedactedValue
+		}
+		out[e.Name] = v
+	}
+	return out
+}
+
+// logExcerpts returns the first lines of logs that look like errors and the
+// last lines of logs.
+func logExcerpts(logs string) (errorLines, lastLines []string) {
+	lines := strings.Split(strings.TrimRight(logs, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	for _, l := range lines {
+		if len(errorLines) < maxReportErrorLines && reportErrorLine.MatchString(l) {
+			errorLines = append(errorLines, l)
+		}
+	}
+	return errorLines, lines[max(len(lines)-reportLastLines, 0):]
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const reportJobSetJSON = `{
+  "kind": "JobSet",
+  "metadata": {"name": "train", "uid": "uid-1", "creationTimestamp": "2026-01-01T00:00:00Z",
+    "labels": {"gcluster.google.com/workload": "train", "gcluster.google.com/experiment": "lr", "kueue.x-k8s.io/queue-name": "lq"},
+    "annotations": {"gcluster.google.com/base-image-digest": "sha256:abc", "other.io/note": "x"}},
+  "spec": {"replicatedJobs": [{"name": "main-job", "replicas": 2, "template": {"spec": {"parallelism": 2, "template": {
+    "metadata": {"labels": {"gcluster.google.com/workload": "train", "gcluster.google.com/team": "ml"}},
+    "spec": {"containers": [{"name": "workload-container", "image": "us-docker.pkg.dev/p/r/img:v1",
+      "command": ["bash", "-c"], "args": ["python train.py"],
+      "env": [{"name": "GCLUSTER_RUN_ID", "value": "run123"}, {"name": "HF_TOKEN", "value": "hf_abc"},
+        {"name": "DB_PASSWORD", "valueFrom": {"secretKeyRef": {"name": "train-secret-env", "key": "DB_PASSWORD"}}}],
+      "resources": {"requests": {"cpu": "4", "memory": "16Gi", "nvidia.com/gpu": "1"}}}]}}}}}]},
+  "status": {"conditions": [{"type": "Completed", "status": "True", "lastTransitionTime": "2026-01-01T02:30:00Z"}]}
+}`
+
+const reportWorkloadsJSON = `{"items": [{"metadata": {"name": "jobset-train-1"}, "status": {"conditions": [
+  {"type": "QuotaReserved", "status": "True", "lastTransitionTime": "2026-01-01T00:10:00Z"},
+  {"type": "Admitted", "status": "True", "lastTransitionTime": "2026-01-01T00:30:00Z"}]}}]}`
+
+func TestReportJob(t *testing.T) {
+	kube := &MockKubeClient{Namespace: "team-a", Logs: map[string]string{
+		"train-0/workload-container": "step 1\nRuntimeError: CUDA error\ndone\n",
+	}}
+	g := &GKEOrchestrator{executor: &mockExecutor{}, kubeClient: kube}
+	g.dynClient = newFakeDynamicClient(
+		fakeObject("JobSet", "team-a", "train", reportJobSetJSON),
+		fakeList("Workload", "team-a", map[string]string{"kueue.x-k8s.io/job-uid": "uid-1"}, reportWorkloadsJSON),
+		fakeList("Pod", "team-a", map[string]string{"jobset.sigs.k8s.io/jobset-name": "train"}, `{"items": [
+		  {"metadata": {"name": "train-0"}, "spec": {"containers": [{"name": "workload-container"}]}}]}`),
+	)
+
+	got, err := g.ReportJob(context.Background(), "train", orchestrator.ReportOptions{ProjectID: "p", ClusterName: "c", ClusterLocation: "us-central1", LogTailLines: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Namespace != "team-a" || got.RunID != "run123" {
+		t.Errorf("unexpected namespace or run ID: %+v", got)
+	}
+	wantLabels := map[string]string{"experiment": "lr", "team": "ml"}
+	if !reflect.DeepEqual(got.Labels, wantLabels) {
+		t.Errorf("Labels = %v, want %v", got.Labels, wantLabels)
+	}
+	if !reflect.DeepEqual(got.Annotations, map[string]string{"base-image-digest": "sha256:abc"}) {
+		t.Errorf("unexpected annotations %v", got.Annotations)
+	}
+	if want := time.Date(2026, 1, 1, 0, 30, 0, 0, time.UTC); !got.Started.Equal(want) {
+		t.Errorf("Started = %v, want the Kueue admission at %v", got.Started, want)
+	}
+	if d := got.Duration(time.Now()); d != 2*time.Hour {
+		t.Errorf("Duration() = %v, want 2h", d)
+	}
+
+	wantContainers := []orchestrator.ReportContainer{{
+		ReplicatedJob: "main-job",
+		Pods:          4,
+		Image:         "us-docker.pkg.dev/p/r/img:v1",
+		Command:       []string{"bash", "-c", "python train.py"},
+		Env:           map[string]string{"GCLUSTER_RUN_ID": "run123", "HF_TOKEN": orchestrator.RedactedValue, "DB_PASSWORD": orchestrator.RedactedValue},
+	}}
+	if !reflect.DeepEqual(got.Containers, wantContainers) {
+		t.Errorf("Containers = %+v, want %+v", got.Containers, wantContainers)
+	}
+	wantResources := []orchestrator.ReportResource{
+		{Name: "cpu", Total: "16"},
+		{Name: "memory", Total: "64Gi"},
+		{Name: "nvidia.com/gpu", Total: "4", Accelerator: true, Count: 4},
+	}
+	if !reflect.DeepEqual(got.Resources, wantResources) {
+		t.Errorf("Resources = %+v, want %+v", got.Resources, wantResources)
+	}
+
+	if len(kube.LogOptions) != 1 || kube.LogOptions[0].TailLines == nil || *kube.LogOptions[0].TailLines != 50 {
+		t.Errorf("expected the last 50 lines of the logs, got %+v", kube.LogOptions)
+	}
+	if len(got.ErrorLines) != 1 || !strings.Contains(got.ErrorLines[0], "CUDA error") || len(got.LastLines) != 3 {
+		t.Errorf("unexpected log excerpts: errors %q, last %q", got.ErrorLines, got.LastLines)
+	}
+	if !strings.Contains(got.WorkloadURL, "/us-central1/c/default/train-main-job-0/details?project=p") || got.LogsURL == "" {
+		t.Errorf("unexpected links %q, %q", got.WorkloadURL, got.LogsURL)
+	}
+}
+
+func TestLogExcerpts(t *testing.T) {
+	var lines []string
+	for i := range reportLastLines + 5 {
+		lines = append(lines, "step "+strings.Repeat("x", i))
+	}
+	lines[2] = "Traceback (most recent call last):"
+	errs, last := logExcerpts(strings.Join(lines, "\n") + "\n")
+	if !reflect.DeepEqual(errs, []string{"Traceback (most recent call last):"}) {
+		t.Errorf("error lines = %q", errs)
+	}
+	if len(last) != reportLastLines || last[len(last)-1] != lines[len(lines)-1] {
+		t.Errorf("last lines = %q", last)
+	}
+	if errs, last := logExcerpts(""); errs != nil || last != nil {
+		t.Errorf("logExcerpts(\"\") = %q, %q, want none", errs, last)
+	}
+}
@@ -15,13 +15,16 @@
 package gke
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/logging"
 	"hpc-toolkit/pkg/orchestrator"
 	"strconv"
 	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type MachineTypeCap struct {
@@ -29,11 +32,18 @@ type MachineTypeCap struct {
 		Count int    `json:"guestAcceleratorCount"`
 		Type  string `json:"guestAcceleratorType"`
 	} `json:"accelerators"`
-	GuestCpus int `json:"guestCpus"`
-	MemoryMb  int `json:"memoryMb"`
+	GuestCpus    int    `json:"guestCpus"`
+	MemoryMb     int    `json:"memoryMb"`
+	Architecture string `json:"architecture"`
 }
 
+// FetchMachineCapacity returns the accelerators per node of a machine type,
+// or its vCPUs if it has none. The accelerators of an accelerator preset take
+// precedence over the Compute Engine API.
 func (g *GKEOrchestrator) FetchMachineCapacity(machineType, zone string) (int, error) {
+	if preset, ok := config.AcceleratorPresetFor(machineType); ok && preset.Accelerators > 0 {
+		return preset.Accelerators, nil
+	}
 	cap, err := g.FetchMachineCapabilities(machineType, zone)
 	if err != nil {
 		return 0, err
@@ -112,8 +122,9 @@ func (g *GKEOrchestrator) FetchMachineCapabilities(machineType, zone string) (Ma
 		logging.Info("Discovered machine capabilities in zone %s", z)
 
 		cap := MachineTypeCap{
-			GuestCpus: int(mt.GuestCpus),
-			MemoryMb:  int(mt.MemoryMb),
+			GuestCpus:    int(mt.GuestCpus),
+			MemoryMb:     int(mt.MemoryMb),
+			Architecture: mt.Architecture,
 		}
 
 		count, accelType, isTPU := config.ResolveAcceleratorInfo(mt, machineType)
@@ -144,7 +155,7 @@ func (g *GKEOrchestrator) FetchMachineCapabilities(machineType, zone string) (Ma
 	return MachineTypeCap{}, fmt.Errorf("failed to fetch machine capabilities for %s in zone %s: %w", machineType, zone, lastErr)
 }
 
-func (g *GKEOrchestrator) verifyDynamicSlicingActive(opts ManifestOptions) (bool, error) {
+func (g *GKEOrchestrator) verifyDynamicSlicingActive(ctx context.Context, opts ManifestOptions) (bool, error) {
 	// Return false immediately if not using TPUs.
 	if !config.IsTPU(opts.ComputeType) {
 		return false, nil
@@ -170,7 +181,7 @@ func (g *GKEOrchestrator) verifyDynamicSlicingActive(opts ManifestOptions) (bool
 	}
 
 	isTPU7x := strings.Contains(strings.ToLower(requestedMachineName), "tpu7x")
-	if !isTPU7x || !g.hasSlicingTopologies() || !g.hasSliceAdmissionCheck() {
+	if !isTPU7x || !g.hasSlicingTopologies(ctx) || !g.hasSliceAdmissionCheck(ctx) {
 		g.dynamicSlicingCache[cacheKey] = false
 		return false, nil
 	}
@@ -183,7 +194,7 @@ func (g *GKEOrchestrator) verifyDynamicSlicingActive(opts ManifestOptions) (bool
 	return active, nil
 }
 
-func (g *GKEOrchestrator) verifyStaticSlicingActive(job *orchestrator.JobDefinition) (bool, error) {
+func (g *GKEOrchestrator) verifyStaticSlicingActive(ctx context.Context, job *orchestrator.JobDefinition) (bool, error) {
 	if !config.IsTPU(job.MachineType) {
 		return false, nil
 	}
@@ -198,13 +209,13 @@ func (g *GKEOrchestrator) verifyStaticSlicingActive(job *orchestrator.JobDefinit
 		return val, nil
 	}
 
-	if !g.hasSlicingTopologies() {
+	if !g.hasSlicingTopologies(ctx) {
 		g.staticSlicingCache[cacheKey] = false
 		return false, nil
 	}
 
 	accelLabel := g.GenerateGKENodeSelectorLabel(job.MachineType)
-	output, err := g.queryDiscoveredTopologies(accelLabel, job.MachineType)
+	output, err := g.queryDiscoveredTopologies(ctx, accelLabel, job.MachineType)
 	if err != nil {
 		return false, fmt.Errorf("failed to discover topologies for static sub-slicing check: %w", err)
 	}
@@ -257,13 +268,7 @@ func validateTPU7xTopology(topology string, machineType string) error {
 	return config.Validate3DTopology(topology, machineType, true)
 }
 
-func (g *GKEOrchestrator) hasSliceAdmissionCheck() bool {
-	acResult := g.executor.ExecuteCommand("kubectl", "get", "admissioncheck", "-o", "json")
-	if acResult.ExitCode != 0 {
-		logging.Warn("Failed to query AdmissionChecks. Assuming dynamic-slicing not active.")
-		return false
-	}
-
+func (g *GKEOrchestrator) hasSliceAdmissionCheck(ctx context.Context) bool {
 	var acList struct {
 		Items []struct {
 			Spec struct {
@@ -272,8 +277,8 @@ func (g *GKEOrchestrator) hasSliceAdmissionCheck() bool {
 		} `json:"items"`
 	}
 
-	if err := json.Unmarshal([]byte(acResult.Stdout), &acList); err != nil {
-		logging.Warn("Failed to parse AdmissionChecks JSON: %v. Assuming dynamic-slicing not active.", err)
+	if err := g.listResources(ctx, &acList, admissionCheckGVR, "", metav1.ListOptions{}); err != nil {
+		logging.Warn("Failed to query AdmissionChecks: %v. Assuming dynamic-slicing not active.", err)
 		return false
 	}
 
@@ -287,7 +292,7 @@ func (g *GKEOrchestrator) hasSliceAdmissionCheck() bool {
 	return false
 }
 
-func (g *GKEOrchestrator) hasSlicingTopologies() bool {
+func (g *GKEOrchestrator) hasSlicingTopologies(ctx context.Context) bool {
 	if g.slicingTopologiesChecked {
 		return g.slicingTopologiesDetected
 	}
@@ -296,12 +301,6 @@ func (g *GKEOrchestrator) hasSlicingTopologies() bool {
 		g.slicingTopologiesChecked = true
 	}()
 
-	tResult := g.executor.ExecuteCommand("kubectl", "get", "topologies.kueue.x-k8s.io", "-o", "json")
-	if tResult.ExitCode != 0 {
-		logging.Warn("Failed to query Kueue topologies. Assuming dynamic-slicing not active.")
-		return false
-	}
-
 	var tList struct {
 		Items []struct {
 			Spec struct {
@@ -312,8 +311,8 @@ func (g *GKEOrchestrator) hasSlicingTopologies() bool {
 		} `json:"items"`
 	}
 
-	if err := json.Unmarshal([]byte(tResult.Stdout), &tList); err != nil {
-		logging.Warn("Failed to parse Kueue topologies JSON: %v. Assuming dynamic-slicing not active.", err)
+	if err := g.listResources(ctx, &tList, topologyGVR, "", metav1.ListOptions{}); err != nil {
+		logging.Warn("Failed to query Kueue topologies: %v. Assuming dynamic-slicing not active.", err)
 		return false
 	}
 
@@ -335,11 +334,47 @@ func (g *GKEOrchestrator) hasSlicingTopologies() bool {
 	return false
 }
 
+// calculateResourceLimits returns the container limits of the workload: the
+// limits derived from its compute type, overridden by opts.CPU, opts.Memory
+// and opts.GPUsPerPod.
 func (g *GKEOrchestrator) calculateResourceLimits(opts ManifestOptions, profile JobProfile) (cpu, mem, gpu, tpu string, err error) {
+	cpu, mem, gpu, tpu, err = g.derivedResourceLimits(opts, profile)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	if opts.CPU != "" {
+		cpu = opts.CPU
+	}
+	if opts.Memory != "" {
+		mem = opts.Memory
+	}
+	if opts.GPUsPerPod > 0 {
+		if gpu == "" {
+			return "", "", "", "", fmt.Errorf("--gpus-per-pod requires a GPU compute type, %s has no GPUs", opts.ComputeType)
+		}
+		perNode, err := strconv.Atoi(gpu)
+		if err != nil {
+			return "", "", "", "", fmt.Errorf("failed to parse gpu limit %q: %w", gpu, err)
+		}
+		if opts.GPUsPerPod > perNode {
+			return "", "", "", "", fmt.Errorf("--gpus-per-pod %d is more than the %d GPUs of a %s node", opts.GPUsPerPod, perNode, opts.ComputeType)
+		}
+		gpu = strconv.Itoa(opts.GPUsPerPod)
+	}
+	return cpu, mem, gpu, tpu, nil
+}
+
+// derivedResourceLimits returns the container limits that fill the nodes of
+// the workload's compute type.
+func (g *GKEOrchestrator) derivedResourceLimits(opts ManifestOptions, profile JobProfile) (cpu, mem, gpu, tpu string, err error) {
 	if profile.IsCPUMachine {
 		logging.Info("Using cached capacity for CPU machine %s during limits calculation: %d", opts.ComputeType, profile.CapacityCount)
+		preset, _ := config.AcceleratorPresetFor(opts.MachineType)
+		if preset.CPU != "" {
+			return preset.CPU, preset.Memory, "", "", nil
+		}
 		offsetVCPUs := max(1, int(float64(profile.CapacityCount)*0.95))
-		return fmt.Sprintf("%d", offsetVCPUs), "", "", "", nil
+		return fmt.Sprintf("%d", offsetVCPUs), preset.Memory, "", "", nil
 	}
 
 	mapped := g.GenerateGKENodeSelectorLabel(opts.ComputeType)
@@ -369,13 +404,14 @@ func (g *GKEOrchestrator) calculateGCPMachineResourceLimits(opts ManifestOptions
 	if count > 0 {
 		logging.Info("Dynamically determined capacity for %s: %d", machineName, count)
 
-		if strings.Contains(strings.ToLower(mapped), "nvidia") {
-			return "", "", fmt.Sprintf("%d", count), "", nil
-		}
-		if strings.Contains(strings.ToLower(mapped), "tpu") {
-			return "", "", "", fmt.Sprintf("%d", count), nil
+		preset, _ := config.AcceleratorPresetFor(machineName)
+		switch config.AcceleratorLimitFor(mapped) {
+		case config.AcceleratorLimitGPU:
+			return preset.CPU, preset.Memory, fmt.Sprintf("%d", count), "", nil
+		case config.AcceleratorLimitTPU:
+			return preset.CPU, preset.Memory, "", fmt.Sprintf("%d", count), nil
 		}
-		return "", "", "", "", fmt.Errorf("machine type %s resolved to %d capacity but could not be classified as GPU or TPU (mapped label: %s)", machineName, count, mapped)
+		return "", "", "", "", fmt.Errorf("machine type %s resolved to %d capacity but could not be classified as GPU or TPU (mapped label: %s); add a kind matching it to the accelerator presets", machineName, count, mapped)
 	}
 	return "", "", "", "", fmt.Errorf("failed to determine capacity for machine type %s", machineName)
 }
@@ -433,7 +469,7 @@ func (g *GKEOrchestrator) resolveJobMachineType(computeType string) (string, err
 	return machineName, nil
 }
 
-func (g *GKEOrchestrator) resolveTPURequirements(job *orchestrator.JobDefinition) (isDynamicSlicing bool, isStaticSlicing bool, err error) {
+func (g *GKEOrchestrator) resolveTPURequirements(ctx context.Context, job *orchestrator.JobDefinition) (isDynamicSlicing bool, isStaticSlicing bool, err error) {
 	isTPU7x := strings.Contains(strings.ToLower(job.MachineType), "tpu7x")
 	if isTPU7x && job.Topology == "" {
 		return false, false, fmt.Errorf("topology must be specified explicitly via --topology flag for TPU 7x machine type %s", job.MachineType)
@@ -447,14 +483,14 @@ func (g *GKEOrchestrator) resolveTPURequirements(job *orchestrator.JobDefinition
 	}
 
 	var topology string
-	topology, isDynamicSlicing, err = g.resolveTopology(job)
+	topology, isDynamicSlicing, err = g.resolveTopology(ctx, job)
 	if err != nil {
 		return false, false, err
 	}
 	job.Topology = topology
 
 	if !isDynamicSlicing && job.Topology != "" {
-		isStaticSlicing, err = g.verifyStaticSlicingActive(job)
+		isStaticSlicing, err = g.verifyStaticSlicingActive(ctx, job)
 		if err != nil {
 			return false, false, err
 		}
@@ -469,7 +505,7 @@ func (g *GKEOrchestrator) resolveTPURequirements(job *orchestrator.JobDefinition
 	return isDynamicSlicing, isStaticSlicing, nil
 }
 
-func (g *GKEOrchestrator) resolveHardwareRequirements(job *orchestrator.JobDefinition) (profile JobProfile, isDynamicSlicing bool, isStaticSlicing bool, err error) {
+func (g *GKEOrchestrator) resolveHardwareRequirements(ctx context.Context, job *orchestrator.JobDefinition) (profile JobProfile, isDynamicSlicing bool, isStaticSlicing bool, err error) {
 	if job.ComputeType == "" {
 		return JobProfile{}, false, false, nil
 	}
@@ -480,7 +516,7 @@ func (g *GKEOrchestrator) resolveHardwareRequirements(job *orchestrator.JobDefin
 	}
 	job.MachineType = machineName
 	if config.IsTPU(machineName) {
-		isDynamicSlicing, isStaticSlicing, err = g.resolveTPURequirements(job)
+		isDynamicSlicing, isStaticSlicing, err = g.resolveTPURequirements(ctx, job)
 		if err != nil {
 			return JobProfile{}, false, false, err
 		}
@@ -590,7 +626,7 @@ func (g *GKEOrchestrator) fetchClusterState(job *orchestrator.JobDefinition) err
 func (g *GKEOrchestrator) resolveResourcesAndGates(opts *ManifestOptions, isCPUMachine bool, capacity int, job orchestrator.JobDefinition) (JobProfile, error) {
 	isGPU := !isCPUMachine && !config.IsTPU(job.MachineType)
 	if isGPU && job.GKEScheduler == "gke.io/topology-aware-auto" {
-		opts.SchedulingGates = indentYaml("schedulingGates:\n  - name: \"gke.io/topology-aware-auto-"+job.WorkloadName+"\"", 14)
+		opts.SchedulingGates = []corev1.PodSchedulingGate{{Name: "gke.io/topology-aware-auto-" + job.WorkloadName}}
 		opts.SchedulerName = ""
 	}
 
@@ -604,19 +640,19 @@ func (g *GKEOrchestrator) resolveResourcesAndGates(opts *ManifestOptions, isCPUM
 		opts.ParallelContainers = 2
 	}
 
-	cpuLimit, memoryLimit, gpuLimit, tpuLimit, err := g.calculateResourceLimits(*opts, profile)
+	spec, err := g.resourceSpec(*opts, profile)
 	if err != nil {
 		logging.Warn("Warning: failed to calculate resource limits: %v", err)
 	} else {
-		if opts.ComputeType != "" && gpuLimit == "" && tpuLimit == "" {
+		if opts.ComputeType != "" && spec.GPU == "" && spec.TPU == "" {
 			logging.Info("Suppressing nodeSelector label for deduced CPU machine %s", opts.ComputeType)
 			opts.ComputeType = ""
 		}
-		resStr, err := g.buildResourcesString(cpuLimit, memoryLimit, gpuLimit, tpuLimit, 16)
+		resources, err := buildResources(spec)
 		if err != nil {
 			return profile, err
 		}
-		opts.ResourcesString = resStr
+		opts.Resources = resources
 	}
 
 	return profile, nil
@@ -15,14 +15,19 @@
 package gke
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
+	"slices"
 	"strings"
 	"testing"
 
 	compute "google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestResolveMachineName(t *testing.T) {
@@ -225,25 +230,41 @@ func (m *MockMachineTypeClient) GetMachineType(project, zone, machineType string
 	return nil, fmt.Errorf("mock not configured")
 }
 
-func injectDefaultMocksForShorthand(mockResponses map[string][]shell.CommandResult) {
-	if _, ok := mockResponses["kubectl get resourceflavors.kueue.x-k8s.io"]; !ok {
-		mockResponses["kubectl get resourceflavors.kueue.x-k8s.io"] = []shell.CommandResult{
-			{ExitCode: 0, Stdout: ""},
-			{ExitCode: 0, Stdout: ""},
-		}
+// fakeTPUNodes returns Nodes of the TPU accelerator, one with each of the
+// given topologies.
+func fakeTPUNodes(accelerator string, topologies ...string) string {
+	items := make([]string, 0, len(topologies))
+	for i, topology := range topologies {
+		items = append(items, fmt.Sprintf(`{"metadata": {"name": "%s-%d", "labels": {"cloud.google.com/gke-tpu-accelerator": %q, %q: %q}}}`, accelerator, i, accelerator, tpuTopologyLabel, topology))
 	}
-	if _, ok := mockResponses["kubectl get nodes -o jsonpath"]; !ok {
-		mockResponses["kubectl get nodes -o jsonpath"] = []shell.CommandResult{
-			{ExitCode: 0, Stdout: "16x16\n"},
-			{ExitCode: 0, Stdout: "16x16\n"},
-		}
+	return fakeList("Node", "", nil, `{"items": [`+strings.Join(items, ", ")+`]}`)
+}
+
+// fakeTPUFlavors returns ResourceFlavors of the TPU accelerator, one with
+// each of the given topologies.
+func fakeTPUFlavors(accelerator string, topologies ...string) string {
+	items := make([]string, 0, len(topologies))
+	for i, topology := range topologies {
+		labels := fmt.Sprintf(`{"cloud.google.com/gke-tpu-accelerator": %q, %q: %q}`, accelerator, tpuTopologyLabel, topology)
+		items = append(items, fmt.Sprintf(`{"metadata": {"name": "%s-%d", "labels": %s}, "spec": {"nodeLabels": %s}}`, accelerator, i, labels, labels))
 	}
-	if _, ok := mockResponses["kubectl get topologies.kueue.x-k8s.io -o json"]; !ok {
-		mockResponses["kubectl get topologies.kueue.x-k8s.io -o json"] = []shell.CommandResult{
-			{ExitCode: 0, Stdout: `{"items":[{"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-4x8-id"}]}}]}`},
-			{ExitCode: 0, Stdout: `{"items":[{"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-4x8-id"}]}}]}`},
-		}
+	return fakeList("ResourceFlavor", "", nil, `{"items": [`+strings.Join(items, ", ")+`]}`)
+}
+
+// fakeKueueTopology returns a Kueue Topology with a level for each of the
+// given node labels.
+func fakeKueueTopology(nodeLabels ...string) string {
+	levels := make([]string, 0, len(nodeLabels))
+	for _, label := range nodeLabels {
+		levels = append(levels, fmt.Sprintf(`{"nodeLabel": %q}`, label))
 	}
+	return fakeObject("Topology", "", "tpu-topology", `{"spec": {"levels": [`+strings.Join(levels, ", ")+`]}}`)
+}
+
+// fakeAdmissionCheck returns a Kueue AdmissionCheck handled by the given
+// controller.
+func fakeAdmissionCheck(controllerName string) string {
+	return fakeObject("AdmissionCheck", "", "slice-check", fmt.Sprintf(`{"spec": {"controllerName": %q}}`, controllerName))
 }
 
 func TestResolveAcceleratorShorthand(t *testing.T) {
@@ -254,6 +275,7 @@ func TestResolveAcceleratorShorthand(t *testing.T) {
 		acceleratorType string
 		mockResponses   map[string][]shell.CommandResult
 		nodePools       []string
+		nodeTopology    string
 		wantType        string
 		wantTopology    string
 		wantErr         bool
@@ -287,20 +309,16 @@ func TestResolveAcceleratorShorthand(t *testing.T) {
 			nodePools:       []string{"ct4p-hightpu-4t"},
 			wantType:        "ct4p-hightpu-4t",
 			wantTopology:    "2x2x1",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get nodes -o jsonpath": {{ExitCode: 0, Stdout: "2x2x1\n"}},
-			},
-			wantErr: false,
+			nodeTopology:    "2x2x1",
+			wantErr:         false,
 		},
 		{
 			name:            "Valid shorthand in map fails with conflicting cluster topology",
 			acceleratorType: "v4-8",
 			nodePools:       []string{"ct4p-hightpu-4t"},
 			wantType:        "ct4p-hightpu-4t",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get nodes -o jsonpath": {{ExitCode: 0, Stdout: "16x16\n"}},
-			},
-			wantErr: true,
+			nodeTopology:    "16x16",
+			wantErr:         true,
 		},
 		{
 			name:            "Ambiguous shorthand resolved",
@@ -354,30 +372,35 @@ func TestResolveAcceleratorShorthand(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockResponses := tt.mockResponses
-			if mockResponses == nil {
-				mockResponses = make(map[string][]shell.CommandResult)
-			}
-			// Add default mocks for topology discovery if not provided
-			injectDefaultMocksForShorthand(mockResponses)
-
-			mockExecutor := NewMockExecutor(mockResponses)
+			mockExecutor := NewMockExecutor(tt.mockResponses)
 			orc := newTestGKEOrchestrator(mockExecutor)
 			orc.projectID = "mock-project"
 			orc.clusterZones = []string{"us-central1-a"}
-			if len(tt.nodePools) > 0 {
-				for _, mt := range tt.nodePools {
-					orc.clusterDesc.NodePools = append(orc.clusterDesc.NodePools, gkeJobNodePool{
-						Config: gkeNodePoolConfig{MachineType: mt},
-					})
-				}
+			// The nodes of each node pool report the node topology, and a
+			// Kueue Topology is set up for slicing.
+			nodeTopology := tt.nodeTopology
+			if nodeTopology == "" {
+				nodeTopology = "16x16"
+			}
+			manifests := []string{fakeKueueTopology("cloud.google.com/gke-tpu-slice-4x8-id")}
+			accelerators := []string{orc.GenerateGKENodeSelectorLabel(tt.acceleratorType)}
+			for _, mt := range tt.nodePools {
+				orc.clusterDesc.NodePools = append(orc.clusterDesc.NodePools, gkeJobNodePool{
+					Config: gkeNodePoolConfig{MachineType: mt},
+				})
+				accelerators = append(accelerators, orc.GenerateGKENodeSelectorLabel(mt))
 			}
+			slices.Sort(accelerators)
+			for _, accelerator := range slices.Compact(accelerators) {
+				manifests = append(manifests, fakeTPUNodes(accelerator, nodeTopology))
+			}
+			orc.dynClient = newFakeDynamicClient(manifests...)
 
 			job := &orchestrator.JobDefinition{
 				ComputeType: tt.acceleratorType,
 			}
 
-			_, _, _, err := orc.resolveHardwareRequirements(job)
+			_, _, _, err := orc.resolveHardwareRequirements(context.Background(), job)
 
 			if tt.wantErr {
 				if err == nil {
@@ -403,7 +426,8 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 		name           string
 		machineType    string
 		requestedTopo  string
-		mockResponses  map[string][]shell.CommandResult
+		manifests      []string
+		topologiesErr  bool
 		nodePools      []gkeJobNodePool
 		wantActive     bool
 		wantErr        bool
@@ -431,35 +455,22 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 			name:          "No Kueue topologies configured",
 			machineType:   "ct6e-standard-8t",
 			requestedTopo: "2x2",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io -o json": {
-					{ExitCode: 1, Stderr: "NotFound"},
-				},
-			},
-			wantActive: false,
+			topologiesErr: true,
+			wantActive:    false,
 		},
 		{
 			name:          "Empty topologies configured",
 			machineType:   "ct6e-standard-8t",
 			requestedTopo: "2x2",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io -o json": {
-					{ExitCode: 0, Stdout: `{"items":[]}`},
-				},
-			},
-			wantActive: false,
+			wantActive:    false,
 		},
 		{
 			name:          "Static sub-slicing active (requested shape fits physical)",
 			machineType:   "ct6e-standard-8t",
 			requestedTopo: "2x2",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x2-id"}]}}]}`},
-				},
-				"kubectl get resourceflavors.kueue.x-k8s.io -o jsonpath={range .items[*]}{.spec.nodeLabels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end} -l cloud.google.com/gke-tpu-accelerator=tpu-v6e-slice": {
-					{ExitCode: 0, Stdout: "4x4\n"},
-				},
+			manifests: []string{
+				fakeKueueTopology("cloud.google.com/gke-tpu-slice-2x2-id"),
+				fakeTPUFlavors("tpu-v6e-slice", "4x4"),
 			},
 			wantActive:     true,
 			verifyCacheHit: true,
@@ -468,13 +479,9 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 			name:          "Full-slice topology matches physical (Still TAS active)",
 			machineType:   "ct6e-standard-8t",
 			requestedTopo: "4x4",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-4x4-id"}]}}]}`},
-				},
-				"kubectl get resourceflavors.kueue.x-k8s.io -o jsonpath={range .items[*]}{.spec.nodeLabels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end} -l cloud.google.com/gke-tpu-accelerator=tpu-v6e-slice": {
-					{ExitCode: 0, Stdout: "4x4\n"},
-				},
+			manifests: []string{
+				fakeKueueTopology("cloud.google.com/gke-tpu-slice-4x4-id"),
+				fakeTPUFlavors("tpu-v6e-slice", "4x4"),
 			},
 			wantActive: true,
 		},
@@ -482,13 +489,9 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 			name:          "Requested shape too large for physical",
 			machineType:   "ct6e-standard-8t",
 			requestedTopo: "8x8",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-4x4-id"}]}}]}`},
-				},
-				"kubectl get resourceflavors.kueue.x-k8s.io -o jsonpath={range .items[*]}{.spec.nodeLabels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end} -l cloud.google.com/gke-tpu-accelerator=tpu-v6e-slice": {
-					{ExitCode: 0, Stdout: "4x4\n"},
-				},
+			manifests: []string{
+				fakeKueueTopology("cloud.google.com/gke-tpu-slice-4x4-id"),
+				fakeTPUFlavors("tpu-v6e-slice", "4x4"),
 			},
 			wantActive: false,
 		},
@@ -496,17 +499,7 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 			name:          "Static sub-slicing active (discovered from scaled-to-0 node pool)",
 			machineType:   "ct6e-standard-8t",
 			requestedTopo: "2x2",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io -o json": {
-					{ExitCode: 0, Stdout: `{"items":[{"metadata":{"name":"tpu-topology"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x2-id"}]}}]}`},
-				},
-				"kubectl get resourceflavors.kueue.x-k8s.io -o jsonpath={range .items[*]}{.spec.nodeLabels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end} -l cloud.google.com/gke-tpu-accelerator=tpu-v6e-slice": {
-					{ExitCode: 0, Stdout: ""},
-				},
-				"kubectl get nodes -o jsonpath={range .items[*]}{.metadata.labels.cloud\\.google\\.com/gke-tpu-topology}{\"\\n\"}{end} -l cloud.google.com/gke-tpu-accelerator=tpu-v6e-slice": {
-					{ExitCode: 0, Stdout: ""},
-				},
-			},
+			manifests:     []string{fakeKueueTopology("cloud.google.com/gke-tpu-slice-2x2-id")},
 			nodePools: []gkeJobNodePool{
 				{
 					Name: "tpu-pool-4x4",
@@ -524,8 +517,14 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockExec := NewMockExecutor(tt.mockResponses)
-			orc := newTestGKEOrchestrator(mockExec)
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			client := newFakeDynamicClient(tt.manifests...)
+			if tt.topologiesErr {
+				client.PrependReactor("list", "topologies", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, fmt.Errorf("the server could not find the requested resource")
+				})
+			}
+			orc.dynClient = client
 			if len(tt.nodePools) > 0 {
 				orc.clusterDesc.NodePools = tt.nodePools
 			}
@@ -535,7 +534,7 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 				Topology:    tt.requestedTopo,
 			}
 
-			got, err := orc.verifyStaticSlicingActive(job)
+			got, err := orc.verifyStaticSlicingActive(context.Background(), job)
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("verifyStaticSlicingActive() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -544,9 +543,9 @@ func TestVerifyStaticSlicingActive(t *testing.T) {
 			}
 
 			if tt.verifyCacheHit && err == nil && got == tt.wantActive {
-				// Clear mock executor to ensure subsequent call is satisfied entirely from cache
-				orc.executor = NewMockExecutor(nil)
-				got2, err2 := orc.verifyStaticSlicingActive(job)
+				// Clear the cluster to ensure subsequent call is satisfied entirely from cache
+				orc.dynClient = newFakeDynamicClient()
+				got2, err2 := orc.verifyStaticSlicingActive(context.Background(), job)
 				if err2 != nil || got2 != tt.wantActive {
 					t.Errorf("cache hit failed: got %v, err %v", got2, err2)
 				}
@@ -567,7 +566,7 @@ func TestResolveHardwareRequirements_NAPIncompatibilities(t *testing.T) {
 		computeType        string
 		machineType        string
 		dynamicSlicing     bool
-		mockResponses      map[string][]shell.CommandResult
+		manifests          []string
 		wantErr            bool
 		expectedErrMatch   string
 	}{
@@ -585,11 +584,9 @@ func TestResolveHardwareRequirements_NAPIncompatibilities(t *testing.T) {
 			machineType:        "tpu7x-standard-4t",
 			topology:           "4x4x8",
 			dynamicSlicing:     true,
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get nodes -o jsonpath": {{ExitCode: 0, Stdout: "4x4x8\n"}},
-			},
-			wantErr:          true,
-			expectedErrMatch: "TPU Dynamic Slicing is not supported on GKE Node Auto-Provisioning (NAP) workloads",
+			manifests:          []string{fakeTPUNodes("tpu7x", "4x4x8")},
+			wantErr:            true,
+			expectedErrMatch:   "TPU Dynamic Slicing is not supported on GKE Node Auto-Provisioning (NAP) workloads",
 		},
 		{
 			name:               "NAP Cluster - TPU Static Sub-slicing disallowed",
@@ -598,14 +595,9 @@ func TestResolveHardwareRequirements_NAPIncompatibilities(t *testing.T) {
 			computeType:        "v6e-8",
 			machineType:        "v6e-standard-8t",
 			topology:           "2x4",
-			mockResponses: map[string][]shell.CommandResult{
-				"kubectl get topologies.kueue.x-k8s.io": {
-					{ExitCode: 0, Stdout: `{"items": [{"metadata":{"name":"tpu-v6e-slice"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x4-id"}]}}]}`},
-					{ExitCode: 0, Stdout: `{"items": [{"metadata":{"name":"tpu-v6e-slice"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x4-id"}]}}]}`},
-					{ExitCode: 0, Stdout: `{"items": [{"metadata":{"name":"tpu-v6e-slice"},"spec":{"levels":[{"nodeLabel":"cloud.google.com/gke-tpu-slice-2x4-id"}]}}]}`},
-				},
-				"kubectl get resourceflavors": {{ExitCode: 0, Stdout: ""}},
-				"kubectl get nodes":           {{ExitCode: 0, Stdout: "4x8\n"}},
+			manifests: []string{
+				fakeKueueTopology("cloud.google.com/gke-tpu-slice-2x4-id"),
+				fakeTPUNodes("tpu-v6e-slice", "4x8"),
 			},
 			wantErr:          true,
 			expectedErrMatch: "TPU Static Sub-slicing is not supported on GKE Node Auto-Provisioning (NAP) workloads",
@@ -623,19 +615,14 @@ func TestResolveHardwareRequirements_NAPIncompatibilities(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockResponses := tt.mockResponses
-			if mockResponses == nil {
-				mockResponses = make(map[string][]shell.CommandResult)
-			}
-			// Add default mocks for topology discovery if not provided
-			if _, ok := mockResponses["kubectl get resourceflavors"]; !ok {
-				mockResponses["kubectl get resourceflavors"] = []shell.CommandResult{{ExitCode: 0, Stdout: ""}}
-			}
-			if _, ok := mockResponses["kubectl get nodes -o jsonpath"]; !ok {
-				mockResponses["kubectl get nodes -o jsonpath"] = []shell.CommandResult{{ExitCode: 0, Stdout: "4x8\n"}, {ExitCode: 0, Stdout: "4x8\n"}}
+			// Nodes report a 4x8 topology unless the test seeds its own.
+			manifests := tt.manifests
+			if manifests == nil {
+				manifests = []string{fakeTPUNodes("tpu-v6e-slice", "4x8")}
 			}
 
-			orc := newTestGKEOrchestrator(NewMockExecutor(mockResponses))
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			orc.dynClient = newFakeDynamicClient(manifests...)
 			orc.napEnabled = tt.napEnabled
 			orc.projectID = "mock-project"
 
@@ -664,7 +651,7 @@ func TestResolveHardwareRequirements_NAPIncompatibilities(t *testing.T) {
 				GKENAPProvisioning: tt.gkeNapProvisioning,
 			}
 
-			_, _, _, err := orc.resolveHardwareRequirements(job)
+			_, _, _, err := orc.resolveHardwareRequirements(context.Background(), job)
 			if tt.wantErr {
 				if err == nil {
 					t.Fatalf("expected error, got nil")
@@ -1020,3 +1007,72 @@ func TestFetchMachineCapabilities_NodePoolEmptyLocations_ClusterZonesFallback(t
 		t.Errorf("cap.GuestCpus = %d, want 4", cap.GuestCpus)
 	}
 }
+
+func TestCalculateResourceLimits_Overrides(t *testing.T) {
+	g := newTestGKEOrchestrator(nil)
+	g.machineTypeClient = &MockMachineTypeClient{
+		MT: &compute.MachineType{
+			GuestCpus:    24,
+			Accelerators: []*compute.MachineTypeAccelerators{{GuestAcceleratorCount: 2, GuestAcceleratorType: "nvidia-l4"}},
+		},
+	}
+	gpuOpts := ManifestOptions{ComputeType: "nvidia-l4", MachineType: "g2-standard-24", ClusterLocation: "us-central1-a"}
+
+	opts := gpuOpts
+	opts.CPU, opts.Memory, opts.GPUsPerPod = "8", "64Gi", 1
+	cpu, mem, gpu, tpu, err := g.calculateResourceLimits(opts, JobProfile{})
+	if err != nil {
+		t.Fatalf("calculateResourceLimits failed: %v", err)
+	}
+	if cpu != "8" || mem != "64Gi" || gpu != "1" || tpu != "" {
+		t.Errorf("limits = %q, %q, %q, %q; want 8, 64Gi, 1 and no TPUs", cpu, mem, gpu, tpu)
+	}
+
+	if _, _, gpu, _, err := g.calculateResourceLimits(gpuOpts, JobProfile{}); err != nil || gpu != "2" {
+		t.Errorf("expected the GPUs of the node without overrides, got %q (%v)", gpu, err)
+	}
+
+	opts = gpuOpts
+	opts.GPUsPerPod = 4
+	if _, _, _, _, err := g.calculateResourceLimits(opts, JobProfile{}); err == nil || !strings.Contains(err.Error(), "more than the 2 GPUs") {
+		t.Errorf("expected --gpus-per-pod above the node's GPUs to fail, got %v", err)
+	}
+
+	cpuOpts := ManifestOptions{ComputeType: "n2-standard-32", MachineType: "n2-standard-32", Memory: "100Gi", GPUsPerPod: 1}
+	if _, _, _, _, err := g.calculateResourceLimits(cpuOpts, JobProfile{IsCPUMachine: true, CapacityCount: 32}); err == nil || !strings.Contains(err.Error(), "requires a GPU compute type") {
+		t.Errorf("expected --gpus-per-pod on a CPU machine to fail, got %v", err)
+	}
+	cpuOpts.GPUsPerPod = 0
+	cpu, mem, _, _, err = g.calculateResourceLimits(cpuOpts, JobProfile{IsCPUMachine: true, CapacityCount: 32})
+	if err != nil || cpu != "30" || mem != "100Gi" {
+		t.Errorf("limits = %q, %q (%v); want the derived CPU and 100Gi", cpu, mem, err)
+	}
+}
+
+func TestCalculateResourceLimits_Presets(t *testing.T) {
+	t.Cleanup(config.ResetAcceleratorPresets)
+	config.RegisterAcceleratorPresets(&config.AcceleratorPresets{
+		Kinds: []config.AcceleratorKind{{Match: "acme", Limit: config.AcceleratorLimitGPU}},
+		Presets: []config.AcceleratorPreset{
+			{MachineType: "acme-highgpu-4g", Accelerators: 4, CPU: "90", Memory: "700Gi"},
+			{MachineType: "n2-standard-32", CPU: "28", Memory: "100Gi"},
+		},
+	})
+
+	g := newTestGKEOrchestrator(nil)
+	g.machineTypeClient = &MockMachineTypeClient{FailAll: true}
+	opts := ManifestOptions{ComputeType: "acme-highgpu-4g", MachineType: "acme-highgpu-4g", ClusterLocation: "us-central1-a"}
+	cpu, mem, gpu, tpu, err := g.derivedResourceLimits(opts, JobProfile{})
+	if err != nil {
+		t.Fatalf("derivedResourceLimits failed: %v", err)
+	}
+	if cpu != "90" || mem != "700Gi" || gpu != "4" || tpu != "" {
+		t.Errorf("limits = %q, %q, %q, %q; want the preset's 90, 700Gi and 4 GPUs", cpu, mem, gpu, tpu)
+	}
+
+	opts = ManifestOptions{ComputeType: "n2-standard-32", MachineType: "n2-standard-32"}
+	cpu, mem, _, _, err = g.derivedResourceLimits(opts, JobProfile{IsCPUMachine: true, CapacityCount: 32})
+	if err != nil || cpu != "28" || mem != "100Gi" {
+		t.Errorf("expected the preset's CPU and memory for a CPU machine, got %q, %q (%v)", cpu, mem, err)
+	}
+}
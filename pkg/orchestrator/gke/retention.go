@@ -0,0 +1,440 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// retentionConfigMap holds the retention rules of the workloads submitted
+	// to a namespace, so that they outlive the workloads that declared them.
+	retentionConfigMap     = "gcluster-retention"
+	retentionLabel         = "gcluster.google.com/retention"
+	retentionContainerName = "retention"
+	retentionLogPrefix     = "gcluster-retention:"
+)
+
+var retentionPollInterval = 10 * time.Second
+
+// retentionScript applies a retention rule to the directory given as argument
+// with the same selection as expiredEntries: entries are sorted newest first
+// by their newest file, and those past KEEP_LAST and older than
+// EXPIRE_SECONDS are reported and, unless DRY_RUN is true, deleted.
+const retentionScript = `set -uo pipefail
+dir=$1
+[ -d "$dir" ] || exit 0
+now=$(date +%s)
+find "$dir" -mindepth 1 -maxdepth 1 -print0 | while IFS= read -r -d '' entry; do
+  echo "$(find "$entry" -printf '%T@\n' | sort -n | tail -1 | cut -d. -f1) $entry"
+done | sort -rn | {
+  i=0
+  while read -r mtime entry; do
+    i=$((i + 1))
+    if [ "$i" -le "$KEEP_LAST" ]; then continue; fi
+    if [ "$EXPIRE_SECONDS" -gt 0 ] && [ $((now - mtime)) -le "$EXPIRE_SECONDS" ]; then continue; fi
+    if [ "$DRY_RUN" = true ]; then
+      echo "` + retentionLogPrefix + ` expired $mtime $entry"
+    elif rm -rf -- "$entry"; then
+      echo "` + retentionLogPrefix + ` deleted $mtime $entry"
+    else
+      echo "` + retentionLogPrefix + ` failed $mtime $entry"
+    fi
+  done
+}
+`
+
+// retentionEntry is a file or directory directly under the path of a
+// retention rule.
+type retentionEntry struct {
+	Name       string
+	ModifiedAt time.Time
+}
+
+// resolveRetention checks that each rule is on a gs:// prefix or under a
+// writable PVC mount, which covers filestore:// mounts and existing claims,
+// and sets the claim of the rules on mounts.
+func resolveRetention(rules []orchestrator.RetentionRule, mounts []MountInfo) ([]orchestrator.RetentionRule, error) {
+	var resolved []orchestrator.RetentionRule
+	for _, r := range rules {
+		if strings.HasPrefix(r.Path, "gs://") {
+			resolved = append(resolved, r)
+			continue
+		}
+		m, ok := pvcMountOf(r.Path, mounts)
+		if !ok {
+			return nil, fmt.Errorf("retention path %s must be a gs:// prefix or under the mount path of a filestore:// or PVC --mount", r.Path)
+		}
+		if m.ReadOnly {
+			return nil, fmt.Errorf("retention path %s is on the read-only mount %s", r.Path, m.MountPath)
+		}
+		r.ClaimName, r.MountPath = m.Source, m.MountPath
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
+// pvcMountOf returns the PVC mount that p is at or under.
+func pvcMountOf(p string, mounts []MountInfo) (MountInfo, bool) {
+	for _, m := range mounts {
+		if m.Type == "pvc" && (p == m.MountPath || strings.HasPrefix(p, strings.TrimRight(m.MountPath, "/")+"/")) {
+			return m, true
+		}
+	}
+	return MountInfo{}, false
+}
+
+// retentionKey is the ConfigMap key of the rule for path p. A rule replaces
+// the one a previous workload recorded for the same path.
+func retentionKey(p string) string {
+	sum := sha256.Sum256([]byte(p))
+	return "rule-" + hex.EncodeToString(sum[:8])
+}
+
+// recordRetention merges rules into the retention ConfigMap of namespace ns.
+func (g *GKEOrchestrator) recordRetention(ctx context.Context, ns string, rules []orchestrator.RetentionRule) error {
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": retentionConfigMap, "namespace": ns},
+	}
+	if err := g.createObject(ctx, configMapGVR, ns, configMap); err != nil {
+		return err
+	}
+	data := map[string]string{}
+	for _, r := range rules {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		data[retentionKey(r.Path)] = string(b)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]string{retentionLabel: "true"}},
+		"data":     data,
+	})
+	if err != nil {
+		return err
+	}
+	if err := g.mergePatch(ctx, configMapGVR, ns, retentionConfigMap, patch); err != nil {
+		return fmt.Errorf("failed to record retention rules: %w", err)
+	}
+	logging.Info("Recorded %d retention rule(s) in configmap %s/%s; 'gcluster fleet gc --outputs' enforces them.", len(rules), ns, retentionConfigMap)
+	return nil
+}
+
+// CollectExpiredOutputs sweeps every running cluster of the projects of opts
+// for the
+// retention rules recorded by submitted workloads, and deletes the output and
+// checkpoint entries past them unless opts.DryRun is set. Entries on gs://
+// paths are listed and deleted with the caller's credentials; entries on
+// volumes by a short-lived Job that mounts the volume. A cluster that cannot
+// be swept does not stop the sweep of the others.
+func (g *GKEOrchestrator) CollectExpiredOutputs(ctx context.Context, opts orchestrator.GCOptions) ([]orchestrator.ExpiredOutput, error) {
+	clusters, err := g.gcClusters(ctx, opts.ProjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []orchestrator.ExpiredOutput
+	var failures []string
+	for _, c := range clusters {
+		logging.Info("Applying the retention rules of cluster '%s' of project %s...", c.Name, c.projectID)
+		found, err := g.expiredOutputs(ctx, c, opts.DryRun)
+		expired = append(expired, found...)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s): %v", c.Name, c.projectID, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return expired, fmt.Errorf("failed to apply the retention rules of %d cluster(s):\n  %s", len(failures), strings.Join(failures, "\n  "))
+	}
+	return expired, nil
+}
+
+type retentionConfigMapList struct {
+	Items []struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Data map[string]string `json:"data"`
+	} `json:"items"`
+}
+
+// expiredOutputs applies the retention rules recorded in cluster c.
+func (g *GKEOrchestrator) expiredOutputs(ctx context.Context, c gcCluster, dryRun bool) ([]orchestrator.ExpiredOutput, error) {
+	if err := g.configureKubectl(ctx, c.Name, c.Location, c.projectID); err != nil {
+		return nil, err
+	}
+	var list retentionConfigMapList
+	if err := g.listResources(ctx, &list, configMapGVR, "", metav1.ListOptions{LabelSelector: retentionLabel}); err != nil {
+		return nil, fmt.Errorf("failed to list retention rules: %w", err)
+	}
+
+	now := g.now()
+	var expired []orchestrator.ExpiredOutput
+	var failures []string
+	for _, cm := range list.Items {
+		ns := cm.Metadata.Namespace
+		keys := make([]string, 0, len(cm.Data))
+		for k := range cm.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var rule orchestrator.RetentionRule
+			if err := json.Unmarshal([]byte(cm.Data[k]), &rule); err != nil {
+				logging.Warn("Skipping invalid retention rule %s in %s/%s: %v", k, ns, retentionConfigMap, err)
+				continue
+			}
+			var found []orchestrator.ExpiredOutput
+			var err error
+			if strings.HasPrefix(rule.Path, "gs://") {
+				found, err = g.expireGCSOutputs(rule, now, dryRun)
+			} else {
+				found, err = g.expireVolumeOutputs(ctx, ns, rule, dryRun)
+			}
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", rule.Path, err))
+			}
+			for i := range found {
+				found[i].ProjectID, found[i].ClusterName, found[i].ClusterLocation, found[i].Namespace = c.projectID, c.Name, c.Location, ns
+			}
+			expired = append(expired, found...)
+		}
+	}
+	if len(failures) > 0 {
+		return expired, fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return expired, nil
+}
+
+// expiredEntries returns the entries past rule at time now, newest first.
+func expiredEntries(entries []retentionEntry, rule orchestrator.RetentionRule, now time.Time) []retentionEntry {
+	sorted := append([]retentionEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].ModifiedAt.Equal(sorted[j].ModifiedAt) {
+			return sorted[i].ModifiedAt.After(sorted[j].ModifiedAt)
+		}
+		return sorted[i].Name > sorted[j].Name
+	})
+	var expired []retentionEntry
+	for i, e := range sorted {
+		if i < rule.KeepLast || (rule.ExpireAfter > 0 && now.Sub(e.ModifiedAt) <= rule.ExpireAfter) {
+			continue
+		}
+		expired = append(expired, e)
+	}
+	return expired
+}
+
+// gcsEntries lists the objects under prefix with 'gcloud storage ls --long'
+// and groups them into the entries directly under it. A directory entry ends
+// with "/" and is as old as its newest object.
+func gcsEntries(prefix, listing string) []retentionEntry {
+	newest := map[string]time.Time{}
+	for _, line := range strings.Split(listing, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		updated, err := time.Parse(time.RFC3339, fields[1])
+		uri := strings.Join(fields[2:], " ")
+		rest, ok := strings.CutPrefix(uri, prefix)
+		if err != nil || !ok || rest == "" {
+			continue
+		}
+		name := prefix + rest
+		if dir, _, isDir := strings.Cut(rest, "/"); isDir {
+			name = prefix + dir + "/"
+		}
+		if updated.After(newest[name]) {
+			newest[name] = updated
+		}
+	}
+	entries := make([]retentionEntry, 0, len(newest))
+	for name, t := range newest {
+		entries = append(entries, retentionEntry{Name: name, ModifiedAt: t})
+	}
+	return entries
+}
+
+func (g *GKEOrchestrator) expireGCSOutputs(rule orchestrator.RetentionRule, now time.Time, dryRun bool) ([]orchestrator.ExpiredOutput, error) {
+	prefix := strings.TrimRight(rule.Path, "/") + "/"
+	res := g.executor.ExecuteCommand("gcloud", "storage", "ls", "--long", "--recursive", prefix)
+	if res.ExitCode != 0 {
+		if strings.Contains(res.Stderr, "matched no objects") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list %s: %s", prefix, strings.TrimSpace(res.Stderr))
+	}
+
+	var expired []orchestrator.ExpiredOutput
+	for _, e := range expiredEntries(gcsEntries(prefix, res.Stdout), rule, now) {
+		out := orchestrator.ExpiredOutput{Rule: rule, Entry: e.Name, ModifiedAt: e.ModifiedAt}
+		if !dryRun {
+			args := []string{"storage", "rm", e.Name}
+			if strings.HasSuffix(e.Name, "/") {
+				args = []string{"storage", "rm", "--recursive", e.Name}
+			}
+			if rm := g.executor.ExecuteCommand("gcloud", args...); rm.ExitCode != 0 {
+				out.Error = strings.TrimSpace(rm.Stderr)
+			} else {
+				out.Deleted = true
+			}
+		}
+		expired = append(expired, out)
+	}
+	return expired, nil
+}
+
+// retentionJobName is the name of the Job that applies the rule for path p.
+func retentionJobName(p string) string {
+	return "gcluster-" + retentionKey(p)
+}
+
+// buildRetentionJob returns the Job that applies rule to the volume it is on.
+func buildRetentionJob(ns string, rule orchestrator.RetentionRule, dryRun bool) (string, error) {
+	podSpec := map[string]interface{}{
+		"restartPolicy": "Never",
+		"containers": []interface{}{map[string]interface{}{
+			"name":    retentionContainerName,
+			"image":   cloudSDKImage,
+			"command": []string{"/bin/bash", "-c", retentionScript, retentionContainerName, path.Clean(rule.Path)},
+			"env": []interface{}{
+				map[string]interface{}{"name": "KEEP_LAST", "value": strconv.Itoa(rule.KeepLast)},
+				map[string]interface{}{"name": "EXPIRE_SECONDS", "value": strconv.Itoa(int(rule.ExpireAfter.Seconds()))},
+				map[string]interface{}{"name": "DRY_RUN", "value": strconv.FormatBool(dryRun)},
+			},
+			"volumeMounts": []interface{}{map[string]interface{}{"name": "outputs", "mountPath": rule.MountPath}},
+		}},
+		"volumes": []interface{}{map[string]interface{}{
+			"name":                  "outputs",
+			"persistentVolumeClaim": map[string]interface{}{"claimName": rule.ClaimName},
+		}},
+	}
+	b, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":      retentionJobName(rule.Path),
+			"namespace": ns,
+			"labels":    map[string]interface{}{retentionLabel: "true"},
+		},
+		"spec": map[string]interface{}{
+			"backoffLimit": 0,
+			"template":     map[string]interface{}{"spec": podSpec},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal retention job: %w", err)
+	}
+	return string(b), nil
+}
+
+// expireVolumeOutputs runs the retention Job of rule and reports the entries
+// it expired. The Job is deleted once it has finished.
+func (g *GKEOrchestrator) expireVolumeOutputs(ctx context.Context, ns string, rule orchestrator.RetentionRule, dryRun bool) ([]orchestrator.ExpiredOutput, error) {
+	name := retentionJobName(rule.Path)
+	manifest, err := buildRetentionJob(ns, rule, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	// A Job left by an interrupted sweep would keep its old arguments.
+	if err := g.deleteResource(ctx, batchJobGVR, ns, name); err != nil {
+		return nil, err
+	}
+	if err := g.applyManifests(ctx, []byte(manifest), name+".yaml"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := g.deleteResource(context.WithoutCancel(ctx), batchJobGVR, ns, name); err != nil {
+			logging.Warn("Failed to delete retention job %s: %v", name, err)
+		}
+	}()
+
+	for {
+		// The retention Job reports its conditions like the stage-in Job.
+		var job stageInJob
+		if err := g.getResource(ctx, &job, batchJobGVR, ns, name); err != nil {
+			return nil, fmt.Errorf("failed to get retention job %s: %w", name, err)
+		}
+		state := ""
+		for _, c := range job.Status.Conditions {
+			if c.Status == "True" && (c.Type == "Complete" || c.Type == "Failed") {
+				state = c.Type
+			}
+		}
+		if state != "" {
+			logs, err := g.jobLogs(ctx, ns, name, retentionContainerName)
+			expired := parseRetentionLog(rule, logs)
+			if state == "Failed" {
+				return expired, fmt.Errorf("retention job %s failed: %s", name, strings.TrimSpace(logs))
+			}
+			if err != nil {
+				return expired, fmt.Errorf("failed to read the logs of retention job %s: %w", name, err)
+			}
+			return expired, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		g.sleep(retentionPollInterval)
+	}
+}
+
+// parseRetentionLog returns the entries reported by the retention script.
+func parseRetentionLog(rule orchestrator.RetentionRule, logs string) []orchestrator.ExpiredOutput {
+	var expired []orchestrator.ExpiredOutput
+	for _, line := range strings.Split(logs, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), retentionLogPrefix)
+		fields := strings.SplitN(strings.TrimSpace(rest), " ", 3)
+		if !ok || len(fields) != 3 {
+			continue
+		}
+		mtime, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		out := orchestrator.ExpiredOutput{Rule: rule, Entry: fields[2], ModifiedAt: time.Unix(mtime, 0).UTC()}
+		switch fields[0] {
+		case "deleted":
+			out.Deleted = true
+		case "failed":
+			out.Error = "rm failed"
+		case "expired":
+		default:
+			continue
+		}
+		expired = append(expired, out)
+	}
+	return expired
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestResolveRetention(t *testing.T) {
+	mounts := []MountInfo{
+		{Name: "vol-0", Source: "team-share-pvc", MountPath: "/mnt/nfs", Type: "pvc"},
+		{Name: "vol-1", Source: "ref-pvc", MountPath: "/ref", Type: "pvc", ReadOnly: true},
+		{Name: "vol-2", Source: "data", MountPath: "/data", Type: "gcsfuse"},
+	}
+	got, err := resolveRetention([]orchestrator.RetentionRule{
+		{Path: "gs://runs/ckpt", KeepLast: 3},
+		{Path: "/mnt/nfs/outputs", ExpireAfter: time.Hour},
+	}, mounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []orchestrator.RetentionRule{
+		{Path: "gs://runs/ckpt", KeepLast: 3},
+		{Path: "/mnt/nfs/outputs", ExpireAfter: time.Hour, ClaimName: "team-share-pvc", MountPath: "/mnt/nfs"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveRetention() = %+v, want %+v", got, want)
+	}
+
+	for p, wantErr := range map[string]string{
+		"/ref/old":  "read-only mount /ref",
+		"/data/out": "must be a gs:// prefix or under the mount path",
+		"/mnt/nfsx": "must be a gs:// prefix or under the mount path",
+	} {
+		if _, err := resolveRetention([]orchestrator.RetentionRule{{Path: p, KeepLast: 1}}, mounts); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("resolveRetention(%s) error = %v, want %q", p, err, wantErr)
+		}
+	}
+}
+
+func TestExpiredEntries(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	entries := []retentionEntry{
+		{Name: "step_100", ModifiedAt: now.Add(-40 * day)},
+		{Name: "step_400", ModifiedAt: now.Add(-1 * day)},
+		{Name: "step_200", ModifiedAt: now.Add(-35 * day)},
+		{Name: "step_300", ModifiedAt: now.Add(-2 * day)},
+	}
+	names := func(es []retentionEntry) []string {
+		var n []string
+		for _, e := range es {
+			n = append(n, e.Name)
+		}
+		return n
+	}
+
+	for _, tc := range []struct {
+		rule orchestrator.RetentionRule
+		want []string
+	}{
+		{orchestrator.RetentionRule{KeepLast: 2}, []string{"step_200", "step_100"}},
+		{orchestrator.RetentionRule{ExpireAfter: 30 * day}, []string{"step_200", "step_100"}},
+		{orchestrator.RetentionRule{KeepLast: 3, ExpireAfter: 30 * day}, []string{"step_100"}},
+		{orchestrator.RetentionRule{KeepLast: 1, ExpireAfter: 36 * time.Hour}, []string{"step_300", "step_200", "step_100"}},
+		{orchestrator.RetentionRule{KeepLast: 5}, nil},
+	} {
+		if got := names(expiredEntries(entries, tc.rule, now)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("expiredEntries(%+v) = %v, want %v", tc.rule, got, tc.want)
+		}
+	}
+}
+
+const retentionGCSListing = `      1024  2026-01-01T00:00:00Z  gs://runs/ckpt/step_100/model.bin
+      1024  2026-01-02T00:00:00Z  gs://runs/ckpt/step_100/opt state.bin
+      2048  2026-02-27T00:00:00Z  gs://runs/ckpt/step_200/model.bin
+       512  2026-01-03T00:00:00Z  gs://runs/ckpt/summary.json
+         0  2026-01-01T00:00:00Z  gs://runs/ckpt/
+TOTAL: 5 objects, 4608 bytes (4.5 KiB)
+`
+
+func TestExpireGCSOutputs(t *testing.T) {
+	exec := NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud storage ls --long --recursive gs://runs/ckpt/":        {{Stdout: retentionGCSListing}},
+		"gcloud storage rm --recursive gs://runs/ckpt/step_100/":      {{}},
+		"gcloud storage rm gs://runs/ckpt/summary.json":               {{ExitCode: 1, Stderr: "AccessDeniedException: 403"}},
+		"gcloud storage ls --long --recursive gs://runs/empty/":       {{ExitCode: 1, Stderr: "ERROR: One or more URLs matched no objects."}},
+		"gcloud storage ls --long --recursive gs://runs/unreachable/": {{ExitCode: 1, Stderr: "ERROR: 403 Forbidden"}},
+	})
+	g := newTestGKEOrchestrator(exec)
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rule := orchestrator.RetentionRule{Path: "gs://runs/ckpt", KeepLast: 1, ExpireAfter: 7 * 24 * time.Hour}
+
+	got, err := g.expireGCSOutputs(rule, now, false)
+	if err != nil {
+		t.Fatalf("expireGCSOutputs() error = %v", err)
+	}
+	want := []orchestrator.ExpiredOutput{
+		{Rule: rule, Entry: "gs://runs/ckpt/summary.json", ModifiedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), Error: "AccessDeniedException: 403"},
+		{Rule: rule, Entry: "gs://runs/ckpt/step_100/", ModifiedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Deleted: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expireGCSOutputs() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got, err := g.expireGCSOutputs(orchestrator.RetentionRule{Path: "gs://runs/empty/", KeepLast: 1}, now, false); err != nil || len(got) != 0 {
+		t.Errorf("expected nothing to expire under an empty prefix, got %v, %v", got, err)
+	}
+	if _, err := g.expireGCSOutputs(orchestrator.RetentionRule{Path: "gs://runs/unreachable", KeepLast: 1}, now, false); err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Errorf("expected a listing error, got %v", err)
+	}
+}
+
+func TestRecordRetention(t *testing.T) {
+	// The rules recorded by earlier workloads are kept.
+	client := newFakeDynamicClient(fakeObject("ConfigMap", "team-a", retentionConfigMap, `{"data": {"rule-earlier": "{}"}}`))
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	g.dynClient = client
+
+	rule := orchestrator.RetentionRule{Path: "/mnt/nfs/outputs", KeepLast: 3, ClaimName: "team-share-pvc", MountPath: "/mnt/nfs"}
+	if err := g.recordRetention(context.Background(), "team-a", []orchestrator.RetentionRule{rule}); err != nil {
+		t.Fatalf("recordRetention() error = %v", err)
+	}
+
+	var got struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Data map[string]string `json:"data"`
+	}
+	if err := g.getResource(context.Background(), &got, configMapGVR, "team-a", retentionConfigMap); err != nil {
+		t.Fatal(err)
+	}
+	if got.Metadata.Labels[retentionLabel] != "true" {
+		t.Errorf("expected the configmap to be labeled %s, got %v", retentionLabel, got.Metadata.Labels)
+	}
+	var recorded orchestrator.RetentionRule
+	if err := json.Unmarshal([]byte(got.Data[retentionKey(rule.Path)]), &recorded); err != nil || !reflect.DeepEqual(recorded, rule) {
+		t.Errorf("expected the rule under its path's key, got %v (%v)", got.Data, err)
+	}
+	if _, ok := got.Data["rule-earlier"]; !ok {
+		t.Errorf("expected the earlier rules to be kept, got %v", got.Data)
+	}
+
+	// The configmap is created in a namespace without rules.
+	if err := g.recordRetention(context.Background(), "team-b", []orchestrator.RetentionRule{rule}); err != nil {
+		t.Fatalf("recordRetention() error = %v", err)
+	}
+	if err := g.getResource(context.Background(), &got, configMapGVR, "team-b", retentionConfigMap); err != nil {
+		t.Fatalf("expected the configmap to be created: %v", err)
+	}
+}
+
+func TestExpireVolumeOutputs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldInterval := retentionPollInterval
+	retentionPollInterval = 0
+	defer func() { retentionPollInterval = oldInterval }()
+
+	rule := orchestrator.RetentionRule{Path: "/mnt/nfs/outputs", KeepLast: 2, ClaimName: "team-share-pvc", MountPath: "/mnt/nfs"}
+	job := retentionJobName(rule.Path)
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	client := newFakeDynamicClient(fakeJobPod("team-a", job, retentionContainerName))
+	fakeGets(client, "Job", stageInRunningJSON, stageInCompleteJSON)
+	g.dynClient = client
+	g.kubeClient.(*MockKubeClient).Logs = map[string]string{
+		job + "-abcde/" + retentionContainerName: strings.Join([]string{
+			"gcluster-retention: deleted 1767225600 /mnt/nfs/outputs/run 1",
+			"gcluster-retention: failed 1767139200 /mnt/nfs/outputs/run-0",
+			"rm: cannot remove '/mnt/nfs/outputs/run-0/x': Permission denied",
+		}, "\n"),
+	}
+
+	got, err := g.expireVolumeOutputs(context.Background(), "team-a", rule, false)
+	if err != nil {
+		t.Fatalf("expireVolumeOutputs() error = %v", err)
+	}
+	want := []orchestrator.ExpiredOutput{
+		{Rule: rule, Entry: "/mnt/nfs/outputs/run 1", ModifiedAt: time.Unix(1767225600, 0).UTC(), Deleted: true},
+		{Rule: rule, Entry: "/mnt/nfs/outputs/run-0", ModifiedAt: time.Unix(1767139200, 0).UTC(), Error: "rm failed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expireVolumeOutputs() =\n%+v\nwant\n%+v", got, want)
+	}
+	deletes := 0
+	for _, a := range client.Actions() {
+		if a.Matches("delete", "jobs") {
+			deletes++
+		}
+	}
+	if deletes != 2 {
+		t.Errorf("expected the retention job to be deleted before and after the sweep, got %d deletes", deletes)
+	}
+
+	applied := g.kubeClient.(*MockKubeClient).Applied
+	if len(applied) != 1 {
+		t.Fatalf("expected one retention job to be applied, got %v", applied)
+	}
+	for _, want := range []string{"claimName: team-share-pvc", "mountPath: /mnt/nfs", "namespace: team-a", "- /mnt/nfs/outputs", "value: \"2\"", "value: \"false\""} {
+		if !strings.Contains(applied[0], want) {
+			t.Errorf("expected %q in the retention job:\n%s", want, applied[0])
+		}
+	}
+}
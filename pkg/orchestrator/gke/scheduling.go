@@ -17,6 +17,7 @@ package gke
 import (
 	"fmt"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/orchestrator"
 	"slices"
 	"strconv"
 	"strings"
@@ -29,6 +30,7 @@ type SchedulingOptions struct {
 	Topology           string
 	Scheduler          string
 	NodeAffinityLabels map[string]string
+	NodeAffinityExprs  []orchestrator.NodeAffinityExpression
 	IsDynamicSlicing   bool
 	IsStaticSlicing    bool
 }
@@ -118,6 +120,14 @@ func GetAffinity(opts SchedulingOptions) (*corev1.Affinity, error) {
 		)
 	}
 
+	for _, expr := range opts.NodeAffinityExprs {
+		term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      expr.Key,
+			Operator: corev1.NodeSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+
 	return affinity, nil
 }
 
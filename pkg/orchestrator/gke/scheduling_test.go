@@ -15,6 +15,8 @@
 package gke
 
 import (
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
 	"slices"
 	"testing"
 
@@ -353,3 +355,26 @@ func TestGetAffinity_ConstraintsAndMerging(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAffinity_NodeAffinityExprs(t *testing.T) {
+	opts := SchedulingOptions{
+		NodeAffinityExprs: []orchestrator.NodeAffinityExpression{
+			{Key: "team-pool", Operator: "In", Values: []string{"research", "shared"}},
+			{Key: "capacity-tier", Operator: "DoesNotExist"},
+		},
+	}
+	affinity, err := GetAffinity(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exprs := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions
+	if len(exprs) != 3 {
+		t.Fatalf("expected default-pool exclusion plus 2 expressions, got %v", exprs)
+	}
+	if exprs[1].Key != "team-pool" || exprs[1].Operator != corev1.NodeSelectorOpIn || !reflect.DeepEqual(exprs[1].Values, []string{"research", "shared"}) {
+		t.Errorf("unexpected first expression: %v", exprs[1])
+	}
+	if exprs[2].Key != "capacity-tier" || exprs[2].Operator != corev1.NodeSelectorOpDoesNotExist || exprs[2].Values != nil {
+		t.Errorf("unexpected second expression: %v", exprs[2])
+	}
+}
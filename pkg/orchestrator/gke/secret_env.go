@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// secretManagerCSIDriver is the CSI driver of the Secret Manager add-on
+	// of GKE, and secretManagerProvider its SecretProviderClass provider.
+	secretManagerCSIDriver = "secrets-store-gke.csi.k8s.io"
+	secretManagerProvider  = "gke"
+	// mountTypeSecretProvider mounts the secrets of a SecretProviderClass.
+	mountTypeSecretProvider = "secretProvider"
+	// secretEnvMountPath is the directory the csi mode mounts the secrets
+	// of --secret-env at, one file per variable.
+	secretEnvMountPath = "/etc/gcluster/secrets"
+	// dryRunSecretValue stands for the secret values in dry runs, so that
+	// no secret ends up in a printed or saved manifest.
+	dryRunSecretValue = "READ-AT-SUBMISSION"
+)
+
+// secretAccessorRoles are the roles that allow reading the payload of a
+// Secret Manager secret.
+var secretAccessorRoles = []string{
+	"roles/secretmanager.secretAccessor",
+	"roles/secretmanager.admin",
+}
+
+// secretEnvName is the name of the Secret, or the SecretProviderClass, that
+// holds the --secret-env values of a workload.
+func secretEnvName(workload string) string {
+	return workload + "-secret-env"
+}
+
+// secretEnvRefs parses the --secret-env references of job, filling in the
+// project of the cluster for short references, and returns them with the
+// variable names in order.
+func secretEnvRefs(job orchestrator.JobDefinition) ([]string, map[string]orchestrator.SecretRef, error) {
+	refs := make(map[string]orchestrator.SecretRef, len(job.SecretEnv))
+	for name, ref := range job.SecretEnv {
+		r, err := orchestrator.ParseSecretRef(ref)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --secret-env %s: %w", name, err)
+		}
+		if r.Project == "" {
+			r.Project = job.ClusterProjectID
+		}
+		refs[name] = r
+	}
+	return slices.Sorted(maps.Keys(refs)), refs, nil
+}
+
+// validateSecretEnv checks the --secret-env references of job and that its
+// workload kind supports the chosen mode. The csi mode exports the mounted
+// files in a shell prelude of the command, which Ray, MPI and Pathways
+// workloads do not run.
+func validateSecretEnv(job orchestrator.JobDefinition) error {
+	if len(job.SecretEnv) == 0 {
+		return nil
+	}
+	if job.SecretEnvMode != "" && !slices.Contains(orchestrator.SecretEnvModes, job.SecretEnvMode) {
+		return fmt.Errorf("invalid --secret-env-mode %q: must be one of %s", job.SecretEnvMode, strings.Join(orchestrator.SecretEnvModes, ", "))
+	}
+	names, _, err := secretEnvRefs(job)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, ok := job.Env[name]; ok {
+			return fmt.Errorf("environment variable %s is set by both --env and --secret-env", name)
+		}
+	}
+	if job.SecretEnvMode == orchestrator.SecretEnvModeCSI {
+		switch {
+		case job.IsPathwaysJob:
+			return fmt.Errorf("--secret-env-mode %s is not supported for Pathways workloads; use --secret-env-mode %s", orchestrator.SecretEnvModeCSI, orchestrator.SecretEnvModeSecret)
+		case job.WorkloadKind == orchestrator.WorkloadKindRayJob, job.WorkloadKind == orchestrator.WorkloadKindMPI:
+			return fmt.Errorf("--secret-env-mode %s is not supported for %s workloads; use --secret-env-mode %s", orchestrator.SecretEnvModeCSI, job.WorkloadKind, orchestrator.SecretEnvModeSecret)
+		}
+	}
+	return nil
+}
+
+// resolveSecretEnv prepares the --secret-env values of job before anything
+// is built or applied. In the secret mode it reads them from Secret Manager
+// with the credentials of the user, so a secret the user cannot read fails
+// the submission; a dry run reads nothing. In the csi mode it checks that the
+// Secret Manager add-on of the cluster is enabled and warns about secrets the
+// pods may not be able to read.
+func (g *GKEOrchestrator) resolveSecretEnv(job orchestrator.JobDefinition) error {
+	if len(job.SecretEnv) == 0 {
+		return nil
+	}
+	names, refs, err := secretEnvRefs(job)
+	if err != nil {
+		return err
+	}
+	if job.SecretEnvMode == orchestrator.SecretEnvModeCSI {
+		if !g.clusterDesc.SecretManagerConfig.Enabled {
+			return fmt.Errorf("the Secret Manager add-on, which --secret-env-mode %s requires, is not enabled on cluster %s. Enable it with:\n  gcloud container clusters update %s --location %s --project %s --enable-secret-manager",
+				orchestrator.SecretEnvModeCSI, job.ClusterName, job.ClusterName, job.ClusterLocation, job.ClusterProjectID)
+		}
+		g.checkSecretEnvAccess(job, names, refs)
+		return nil
+	}
+	if job.IsDryRun() {
+		return nil
+	}
+
+	g.secretEnvValues = make(map[string]string, len(names))
+	for _, name := range names {
+		r := refs[name]
+		res := g.executor.ExecuteCommand("gcloud", "secrets", "versions", "access", r.Version, "--secret", r.Secret, "--project", r.Project)
+		if res.ExitCode != 0 {
+			return fmt.Errorf("failed to read %s for --secret-env %s: %s\nReading it requires roles/secretmanager.secretAccessor on the secret, which can be granted with:\n  gcloud secrets add-iam-policy-binding %s --project %s --role roles/secretmanager.secretAccessor --member user:<your account>",
+				r, name, strings.TrimSpace(res.Stderr), r.Secret, r.Project)
+		}
+		g.secretEnvValues[name] = res.Stdout
+	}
+	logging.Info("Read %d --secret-env value(s) from Secret Manager.", len(names))
+	return nil
+}
+
+// checkSecretEnvAccess warns about the --secret-env secrets that the
+// Kubernetes service account of the workload's pods may not be able to read.
+func (g *GKEOrchestrator) checkSecretEnvAccess(job orchestrator.JobDefinition, names []string, refs map[string]orchestrator.SecretRef) {
+	member, missing := g.secretEnvAccessGaps(job, names, refs)
+	for _, name := range missing {
+		r := refs[name]
+		logging.Warn("The pods of %s are not granted access to secret %s (project %s) of --secret-env %s, so they may fail to mount it. Grant their Kubernetes service account access with:\n  gcloud secrets add-iam-policy-binding %s --project %s --role roles/secretmanager.secretAccessor --member %s",
+			job.WorkloadName, r.Secret, r.Project, name, r.Secret, r.Project, member)
+	}
+}
+
+// secretEnvAccessGaps returns the Workload Identity principal of the
+// Kubernetes service account of the workload's pods, and the --secret-env
+// variables whose secrets neither their own policy nor the policy of their
+// project grant it access to. Grants through groups or Google service
+// accounts cannot be seen, and secrets whose policies cannot be read are not
+// returned.
+func (g *GKEOrchestrator) secretEnvAccessGaps(job orchestrator.JobDefinition, names []string, refs map[string]orchestrator.SecretRef) (string, []string) {
+	res := g.executor.ExecuteCommand("gcloud", "projects", "describe", job.ClusterProjectID, "--format=value(projectNumber)")
+	number := strings.TrimSpace(res.Stdout)
+	if res.ExitCode != 0 || number == "" {
+		logging.Info("Could not read the project number of %s to check that the pods of %s can read their --secret-env secrets.", job.ClusterProjectID, job.WorkloadName)
+		return "", nil
+	}
+	ns, err := g.getCurrentNamespace()
+	if err != nil || ns == "" {
+		ns = "default"
+	}
+	ksa := job.ServiceAccountName
+	if ksa == "" {
+		ksa = "default"
+	}
+	member := fmt.Sprintf("principal://iam.googleapis.com/projects/%s/locations/global/workloadIdentityPools/%s.svc.id.goog/subject/ns/%s/sa/%s", number, job.ClusterProjectID, ns, ksa)
+
+	var missing []string
+	projectPolicies := map[string]iamPolicy{}
+	for _, name := range names {
+		r := refs[name]
+		if _, ok := projectPolicies[r.Project]; !ok {
+			p, ok := g.readIAMPolicy("projects", "get-iam-policy", r.Project, "--format=json")
+			if !ok {
+				logging.Info("Could not read the IAM policy of project %s to check that the pods of %s can read %s.", r.Project, job.WorkloadName, r)
+				continue
+			}
+			projectPolicies[r.Project] = p
+		}
+		if projectPolicies[r.Project].grants(member, secretAccessorRoles) {
+			continue
+		}
+		p, ok := g.readIAMPolicy("secrets", "get-iam-policy", r.Secret, "--project", r.Project, "--format=json")
+		if !ok {
+			logging.Info("Could not read the IAM policy of secret %s to check that the pods of %s can read it.", r.Secret, job.WorkloadName)
+			continue
+		}
+		if !p.grants(member, secretAccessorRoles) {
+			missing = append(missing, name)
+		}
+	}
+	return member, missing
+}
+
+// readIAMPolicy runs the gcloud get-iam-policy command of args and parses its
+// output.
+func (g *GKEOrchestrator) readIAMPolicy(args ...string) (iamPolicy, bool) {
+	res := g.executor.ExecuteCommand("gcloud", args...)
+	var p iamPolicy
+	if res.ExitCode != 0 || json.Unmarshal([]byte(res.Stdout), &p) != nil {
+		return iamPolicy{}, false
+	}
+	return p, true
+}
+
+// addSecretEnv passes the --secret-env values of job to the workload. In the
+// secret mode it renders a Secret holding the values read by
+// resolveSecretEnv, and sets the variables from its keys. In the csi mode it
+// renders a SecretProviderClass of the secret versions, returns the mount of
+// its files, and exports the variables from them before the command runs.
+func (g *GKEOrchestrator) addSecretEnv(opts *ManifestOptions, job orchestrator.JobDefinition) (*MountInfo, error) {
+	if len(job.SecretEnv) == 0 {
+		return nil, nil
+	}
+	names, refs, err := secretEnvRefs(job)
+	if err != nil {
+		return nil, err
+	}
+	name := secretEnvName(opts.WorkloadName)
+	labels := map[string]string{"gcluster.google.com/workload": opts.WorkloadName}
+
+	if job.SecretEnvMode == orchestrator.SecretEnvModeCSI {
+		var secrets []map[string]string
+		var exports []string
+		for _, v := range names {
+			secrets = append(secrets, map[string]string{"resourceName": refs[v].String(), "path": v})
+			exports = append(exports, fmt.Sprintf(`export %s="$(cat %s/%s)"`, v, secretEnvMountPath, v))
+		}
+		params, err := k8syaml.Marshal(secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the SecretProviderClass of --secret-env: %w", err)
+		}
+		manifest, err := k8syaml.Marshal(map[string]interface{}{
+			"apiVersion": "secrets-store.csi.x-k8s.io/v1",
+			"kind":       "SecretProviderClass",
+			"metadata":   map[string]interface{}{"name": name, "labels": labels},
+			"spec": map[string]interface{}{
+				"provider":   secretManagerProvider,
+				"parameters": map[string]string{"secrets": string(params)},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the SecretProviderClass of --secret-env: %w", err)
+		}
+		opts.AdditionalManifests = append(opts.AdditionalManifests, string(manifest))
+		if opts.CommandPrelude != "" {
+			exports = append(exports, opts.CommandPrelude)
+		}
+		opts.CommandPrelude = strings.Join(exports, "\n")
+		return &MountInfo{Name: "secret-env", Source: name, MountPath: secretEnvMountPath, Type: mountTypeSecretProvider, ReadOnly: true}, nil
+	}
+
+	data := make(map[string]string, len(names))
+	for _, v := range names {
+		value, ok := g.secretEnvValues[v]
+		if job.IsDryRun() {
+			value, ok = dryRunSecretValue, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("--secret-env %s was not read from Secret Manager", v)
+		}
+		data[v] = value
+		opts.SecretEnv = append(opts.SecretEnv, EnvVar{Name: v, SecretName: name, SecretKey: v})
+	}
+	manifest, err := k8syaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": name, "labels": labels},
+		"type":       "Opaque",
+		"stringData": data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the Secret of --secret-env: %w", err)
+	}
+	opts.AdditionalManifests = append(opts.AdditionalManifests, string(manifest))
+	return nil, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+func TestValidateSecretEnv(t *testing.T) {
+	ref := "sm://projects/p/secrets/wandb"
+	tests := []struct {
+		name    string
+		job     orchestrator.JobDefinition
+		wantErr string
+	}{
+		{name: "none", job: orchestrator.JobDefinition{}},
+		{name: "secret mode", job: orchestrator.JobDefinition{SecretEnv: map[string]string{"WANDB_API_KEY": ref}}},
+		{name: "csi mode", job: orchestrator.JobDefinition{SecretEnv: map[string]string{"WANDB_API_KEY": ref}, SecretEnvMode: orchestrator.SecretEnvModeCSI}},
+		{
+			name:    "invalid reference",
+			job:     orchestrator.JobDefinition{SecretEnv: map[string]string{"WANDB_API_KEY": "projects/p/secrets/wandb"}},
+			wantErr: "invalid --secret-env WANDB_API_KEY",
+		},
+		{
+			name:    "also set with --env",
+			job:     orchestrator.JobDefinition{SecretEnv: map[string]string{"WANDB_API_KEY": ref}, Env: map[string]string{"WANDB_API_KEY": "x"}},
+			wantErr: "set by both --env and --secret-env",
+		},
+		{
+			name:    "unknown mode",
+			job:     orchestrator.JobDefinition{SecretEnv: map[string]string{"WANDB_API_KEY": ref}, SecretEnvMode: "vault"},
+			wantErr: `invalid --secret-env-mode "vault"`,
+		},
+		{
+			name:    "csi mode for a ray job",
+			job:     orchestrator.JobDefinition{SecretEnv: map[string]string{"WANDB_API_KEY": ref}, SecretEnvMode: orchestrator.SecretEnvModeCSI, WorkloadKind: orchestrator.WorkloadKindRayJob},
+			wantErr: "not supported for rayjob workloads",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSecretEnv(tc.job)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestResolveSecretEnv(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		WorkloadName:     "train",
+		ClusterProjectID: "cluster-project",
+		SecretEnv: map[string]string{
+			"WANDB_API_KEY": "sm://wandb",
+			"HF_TOKEN":      "sm://projects/shared/secrets/hf/versions/3",
+		},
+	}
+	exec := NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud secrets versions access latest --secret wandb --project cluster-project": {{Stdout: "wandb-key"}},
+		"gcloud secrets versions access 3 --secret hf --project shared":                  {{Stdout: "hf-token"}},
+	})
+	g := newTestGKEOrchestrator(exec)
+	if err := g.resolveSecretEnv(job); err != nil {
+		t.Fatalf("resolveSecretEnv: %v", err)
+	}
+	if want := map[string]string{"WANDB_API_KEY": "wandb-key", "HF_TOKEN": "hf-token"}; !reflect.DeepEqual(g.secretEnvValues, want) {
+		t.Errorf("secretEnvValues = %v, want %v", g.secretEnvValues, want)
+	}
+
+	t.Run("access denied", func(t *testing.T) {
+		exec := NewMockExecutor(map[string][]shell.CommandResult{
+			"gcloud secrets versions access": {{ExitCode: 1, Stderr: "PERMISSION_DENIED: Permission 'secretmanager.versions.access' denied"}},
+		})
+		err := newTestGKEOrchestrator(exec).resolveSecretEnv(job)
+		if err == nil || !strings.Contains(err.Error(), "PERMISSION_DENIED") || !strings.Contains(err.Error(), "gcloud secrets add-iam-policy-binding") {
+			t.Errorf("expected a permission error with the grant command, got %v", err)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		exec := NewMockExecutor(nil)
+		job := job
+		job.DryRun = true
+		if err := newTestGKEOrchestrator(exec).resolveSecretEnv(job); err != nil {
+			t.Fatalf("resolveSecretEnv: %v", err)
+		}
+		if len(exec.callCount) != 0 {
+			t.Errorf("expected no secret to be read in a dry run, got %v", exec.callCount)
+		}
+	})
+
+	t.Run("csi add-on disabled", func(t *testing.T) {
+		job := job
+		job.ClusterName = "my-cluster"
+		job.SecretEnvMode = orchestrator.SecretEnvModeCSI
+		err := newTestGKEOrchestrator(NewMockExecutor(nil)).resolveSecretEnv(job)
+		if err == nil || !strings.Contains(err.Error(), "--enable-secret-manager") {
+			t.Errorf("expected an add-on error, got %v", err)
+		}
+	})
+}
+
+func TestSecretEnvAccessGaps(t *testing.T) {
+	const principal = "principal://iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/cluster-project.svc.id.goog/subject/ns/default/sa/trainer"
+	job := orchestrator.JobDefinition{
+		WorkloadName:       "train",
+		ClusterProjectID:   "cluster-project",
+		ServiceAccountName: "trainer",
+		SecretEnv: map[string]string{
+			"WANDB_API_KEY": "sm://wandb",
+			"HF_TOKEN":      "sm://hf",
+		},
+	}
+	names, refs, err := secretEnvRefs(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud projects describe cluster-project --format=value(projectNumber)": {{Stdout: "123\n"}},
+		"gcloud projects get-iam-policy cluster-project --format=json":           {{Stdout: `{}`}},
+		"gcloud secrets get-iam-policy wandb --project cluster-project":          {{Stdout: `{"bindings":[{"role":"roles/secretmanager.secretAccessor","members":["` + principal + `"]}]}`}},
+		"gcloud secrets get-iam-policy hf --project cluster-project":             {{Stdout: `{"bindings":[{"role":"roles/secretmanager.viewer","members":["` + principal + `"]}]}`}},
+	})
+	member, missing := newTestGKEOrchestrator(exec).secretEnvAccessGaps(job, names, refs)
+	if member != principal {
+		t.Errorf("member = %q, want %q", member, principal)
+	}
+	if !reflect.DeepEqual(missing, []string{"HF_TOKEN"}) {
+		t.Errorf("missing = %v, want [HF_TOKEN]", missing)
+	}
+
+	t.Run("granted on the project", func(t *testing.T) {
+		exec := NewMockExecutor(map[string][]shell.CommandResult{
+			"gcloud projects describe cluster-project --format=value(projectNumber)": {{Stdout: "123\n"}},
+			"gcloud projects get-iam-policy cluster-project --format=json":           {{Stdout: `{"bindings":[{"role":"roles/secretmanager.secretAccessor","members":["` + principal + `"]}]}`}},
+		})
+		if _, missing := newTestGKEOrchestrator(exec).secretEnvAccessGaps(job, names, refs); len(missing) != 0 {
+			t.Errorf("expected the project grant to cover every secret, got %v", missing)
+		}
+	})
+
+	t.Run("policies cannot be read", func(t *testing.T) {
+		if _, missing := newTestGKEOrchestrator(NewMockExecutor(nil)).secretEnvAccessGaps(job, names, refs); len(missing) != 0 {
+			t.Errorf("expected nothing to be reported, got %v", missing)
+		}
+	})
+}
+
+func TestAddSecretEnv(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		WorkloadName:     "train",
+		ClusterProjectID: "cluster-project",
+		SecretEnv:        map[string]string{"WANDB_API_KEY": "sm://wandb", "HF_TOKEN": "sm://projects/shared/secrets/hf/versions/3"},
+	}
+
+	t.Run("secret", func(t *testing.T) {
+		g := newTestGKEOrchestrator(NewMockExecutor(nil))
+		g.secretEnvValues = map[string]string{"WANDB_API_KEY": "wandb-key", "HF_TOKEN": "hf-token"}
+		opts := &ManifestOptions{WorkloadName: "train"}
+		mount, err := g.addSecretEnv(opts, job)
+		if err != nil {
+			t.Fatalf("addSecretEnv: %v", err)
+		}
+		if mount != nil {
+			t.Errorf("expected no mount, got %+v", mount)
+		}
+		var secret struct {
+			Metadata   struct{ Name string }
+			Type       string
+			StringData map[string]string `json:"stringData"`
+		}
+		if err := k8syaml.Unmarshal([]byte(opts.AdditionalManifests[0]), &secret); err != nil {
+			t.Fatalf("failed to parse the secret: %v", err)
+		}
+		if secret.Metadata.Name != "train-secret-env" || secret.Type != "Opaque" || !reflect.DeepEqual(secret.StringData, g.secretEnvValues) {
+			t.Errorf("unexpected secret: %+v", secret)
+		}
+		want := []EnvVar{
+			{Name: "HF_TOKEN", SecretName: "train-secret-env", SecretKey: "HF_TOKEN"},
+			{Name: "WANDB_API_KEY", SecretName: "train-secret-env", SecretKey: "WANDB_API_KEY"},
+		}
+		if !reflect.DeepEqual(opts.SecretEnv, want) {
+			t.Errorf("SecretEnv = %+v, want %+v", opts.SecretEnv, want)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		job := job
+		job.DryRun = true
+		opts := &ManifestOptions{WorkloadName: "train"}
+		if _, err := newTestGKEOrchestrator(NewMockExecutor(nil)).addSecretEnv(opts, job); err != nil {
+			t.Fatalf("addSecretEnv: %v", err)
+		}
+		if !strings.Contains(opts.AdditionalManifests[0], dryRunSecretValue) {
+			t.Errorf("expected placeholder values in a dry run, got %s", opts.AdditionalManifests[0])
+		}
+	})
+
+	t.Run("csi", func(t *testing.T) {
+		job := job
+		job.SecretEnvMode = orchestrator.SecretEnvModeCSI
+		opts := &ManifestOptions{WorkloadName: "train"}
+		mount, err := newTestGKEOrchestrator(NewMockExecutor(nil)).addSecretEnv(opts, job)
+		if err != nil {
+			t.Fatalf("addSecretEnv: %v", err)
+		}
+		wantMount := &MountInfo{Name: "secret-env", Source: "train-secret-env", MountPath: secretEnvMountPath, Type: mountTypeSecretProvider, ReadOnly: true}
+		if !reflect.DeepEqual(mount, wantMount) {
+			t.Errorf("mount = %+v, want %+v", mount, wantMount)
+		}
+		var spc struct {
+			Kind string
+			Spec struct {
+				Provider   string
+				Parameters map[string]string
+			}
+		}
+		if err := k8syaml.Unmarshal([]byte(opts.AdditionalManifests[0]), &spc); err != nil {
+			t.Fatalf("failed to parse the SecretProviderClass: %v", err)
+		}
+		var secrets []map[string]string
+		if err := k8syaml.Unmarshal([]byte(spc.Spec.Parameters["secrets"]), &secrets); err != nil {
+			t.Fatalf("failed to parse the secrets parameter: %v", err)
+		}
+		wantSecrets := []map[string]string{
+			{"resourceName": "projects/shared/secrets/hf/versions/3", "path": "HF_TOKEN"},
+			{"resourceName": "projects/cluster-project/secrets/wandb/versions/latest", "path": "WANDB_API_KEY"},
+		}
+		if spc.Kind != "SecretProviderClass" || spc.Spec.Provider != secretManagerProvider || !reflect.DeepEqual(secrets, wantSecrets) {
+			t.Errorf("unexpected SecretProviderClass: %+v with secrets %v", spc, secrets)
+		}
+		wantPrelude := `export HF_TOKEN="$(cat /etc/gcluster/secrets/HF_TOKEN)"` + "\n" + `export WANDB_API_KEY="$(cat /etc/gcluster/secrets/WANDB_API_KEY)"`
+		if opts.CommandPrelude != wantPrelude {
+			t.Errorf("prelude = %q, want %q", opts.CommandPrelude, wantPrelude)
+		}
+		if len(opts.SecretEnv) != 0 {
+			t.Errorf("expected no variables from a Secret, got %+v", opts.SecretEnv)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+const (
+	// sharedVolumeClaimPrefix marks the claims gcluster creates for shared
+	// volumes. The claims are named after the export, so workloads sharing an
+	// export share the claim.
+	sharedVolumeClaimPrefix = "gcluster-nfs-"
+	// sharedVolumeCapacity is recorded on shared volumes because Kubernetes
+	// requires one; NFS does not enforce it.
+	sharedVolumeCapacity = "1Ti"
+)
+
+// SharedVolumeSpecs returns the volumes that the shared volumes of job are
+// mounted through.
+func (sm *StorageManager) SharedVolumeSpecs(job orchestrator.JobDefinition) []VolumeSpec {
+	var specs []VolumeSpec
+	for i, v := range job.SharedVolumes {
+		pvcName, pvName := sm.claimNames(sanitizePVCName(sharedVolumeClaimPrefix + v.Server + "-" + v.Path))
+		specs = append(specs, VolumeSpec{
+			Name:      fmt.Sprintf("shared-%d", i),
+			PVName:    pvName,
+			PVCName:   pvcName,
+			Server:    v.Server,
+			Path:      v.Path,
+			MountPath: v.MountPath,
+		})
+	}
+	return specs
+}
+
+// AddSharedVolumes renders the PersistentVolumes and claims of
+// opts.SharedVolumes into the additional manifests, and returns the mounts of
+// the claims.
+func (sm *StorageManager) AddSharedVolumes(opts *ManifestOptions) ([]MountInfo, error) {
+	var mounts []MountInfo
+	for _, v := range opts.SharedVolumes {
+		manifest, err := renderNFSVolume(v.PVName, v.PVCName, v.Server, v.Path, sharedVolumeCapacity)
+		if err != nil {
+			return nil, err
+		}
+		opts.AdditionalManifests = append(opts.AdditionalManifests, manifest)
+		mounts = append(mounts, MountInfo{
+			Name:      v.Name,
+			Source:    v.PVCName,
+			MountPath: v.MountPath,
+			Type:      "pvc",
+		})
+	}
+	return mounts, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestAddSharedVolumes(t *testing.T) {
+	sm := &StorageManager{}
+	job := orchestrator.JobDefinition{
+		SharedVolumes: []orchestrator.SharedVolume{
+			{Server: "10.0.0.2", Path: "/share1", MountPath: "/mnt/nfs"},
+			{Server: "nfs.example.com", Path: "/exports/data", MountPath: "/data"},
+		},
+	}
+
+	opts := ManifestOptions{AdditionalManifests: []string{"existing"}}
+	opts.SharedVolumes = sm.SharedVolumeSpecs(job)
+	wantSpecs := []VolumeSpec{
+		{Name: "shared-0", PVName: "gcluster-nfs-10-0-0-2-share1-default", PVCName: "gcluster-nfs-10-0-0-2-share1", Server: "10.0.0.2", Path: "/share1", MountPath: "/mnt/nfs"},
+		{Name: "shared-1", PVName: "gcluster-nfs-nfs-example-com-exports-data-default", PVCName: "gcluster-nfs-nfs-example-com-exports-data", Server: "nfs.example.com", Path: "/exports/data", MountPath: "/data"},
+	}
+	if !reflect.DeepEqual(opts.SharedVolumes, wantSpecs) {
+		t.Fatalf("SharedVolumeSpecs() = %+v, want %+v", opts.SharedVolumes, wantSpecs)
+	}
+
+	mounts, err := sm.AddSharedVolumes(&opts)
+	if err != nil {
+		t.Fatalf("AddSharedVolumes() error = %v", err)
+	}
+	wantMounts := []MountInfo{
+		{Name: "shared-0", Source: "gcluster-nfs-10-0-0-2-share1", MountPath: "/mnt/nfs", Type: "pvc"},
+		{Name: "shared-1", Source: "gcluster-nfs-nfs-example-com-exports-data", MountPath: "/data", Type: "pvc"},
+	}
+	if !reflect.DeepEqual(mounts, wantMounts) {
+		t.Errorf("AddSharedVolumes() = %+v, want %+v", mounts, wantMounts)
+	}
+
+	if len(opts.AdditionalManifests) != 3 || opts.AdditionalManifests[0] != "existing" {
+		t.Fatalf("expected two manifests to be appended, got %q", opts.AdditionalManifests)
+	}
+	for _, want := range []string{
+		"name: gcluster-nfs-10-0-0-2-share1-default",
+		"server: 10.0.0.2",
+		"path: /share1",
+		"storage: " + sharedVolumeCapacity,
+		"ReadWriteMany",
+	} {
+		if !strings.Contains(opts.AdditionalManifests[1], want) {
+			t.Errorf("manifest missing %q:\n%s", want, opts.AdditionalManifests[1])
+		}
+	}
+	if !strings.Contains(opts.AdditionalManifests[2], "path: /exports/data") {
+		t.Errorf("manifest missing the export path:\n%s", opts.AdditionalManifests[2])
+	}
+}
+
+func TestIsManagedClaim(t *testing.T) {
+	for name, want := range map[string]bool{
+		"gcluster-filestore-10-0-0-2-share1": true,
+		"gcluster-nfs-10-0-0-2-share1":       true,
+		"my-pvc":                             false,
+	} {
+		if got := isManagedClaim(name); got != want {
+			t.Errorf("isManagedClaim(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"path"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// snippetMountPath is the directory the ConfigMap of an inline snippet is
+// mounted at.
+const snippetMountPath = "/gcluster/snippet"
+
+// snippetRunners maps the languages of a snippet to its file name in the
+// ConfigMap and the interpreter it is run with.
+var snippetRunners = map[string]struct{ file, interpreter string }{
+	orchestrator.SnippetLanguagePython: {"snippet.py", "python3"},
+	orchestrator.SnippetLanguageBash:   {"snippet.sh", "bash"},
+}
+
+// addSnippet renders the ConfigMap holding the inline snippet of job into
+// the additional manifests, makes the container run it, and returns the
+// mount of the ConfigMap. It returns nil if the job has no snippet.
+func addSnippet(opts *ManifestOptions, job orchestrator.JobDefinition) (*MountInfo, error) {
+	if job.Snippet == "" {
+		return nil, nil
+	}
+	language := job.SnippetLanguage
+	if language == "" {
+		language = orchestrator.SnippetLanguagePython
+	}
+	runner, ok := snippetRunners[language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported snippet language %q, must be one of %v", language, orchestrator.SnippetLanguages)
+	}
+
+	name := opts.WorkloadName + "-snippet"
+	manifest, err := k8syaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]string{"gcluster.google.com/workload": opts.WorkloadName},
+		},
+		"data": map[string]string{runner.file: job.Snippet},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the snippet ConfigMap: %w", err)
+	}
+	opts.AdditionalManifests = append(opts.AdditionalManifests, string(manifest))
+	opts.CommandToRun = ""
+	opts.CommandArgs = []string{runner.interpreter, path.Join(snippetMountPath, runner.file)}
+	return &MountInfo{
+		Name:      "snippet",
+		Source:    name,
+		MountPath: snippetMountPath,
+		Type:      mountTypeConfigMap,
+		ReadOnly:  true,
+	}, nil
+}
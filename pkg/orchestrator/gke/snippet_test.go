@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestAddSnippet(t *testing.T) {
+	opts := ManifestOptions{WorkloadName: "train", CommandToRun: "ignored"}
+	mount, err := addSnippet(&opts, orchestrator.JobDefinition{Snippet: "import sys\nprint(sys.version)\n"})
+	if err != nil {
+		t.Fatalf("addSnippet() error = %v", err)
+	}
+	if want := []string{"python3", "/gcluster/snippet/snippet.py"}; !reflect.DeepEqual(opts.CommandArgs, want) || opts.CommandToRun != "" {
+		t.Errorf("expected command %v, got %v (%q)", want, opts.CommandArgs, opts.CommandToRun)
+	}
+	if len(opts.AdditionalManifests) != 1 {
+		t.Fatalf("expected the ConfigMap manifest, got %v", opts.AdditionalManifests)
+	}
+	for _, want := range []string{"kind: ConfigMap", "name: train-snippet", "gcluster.google.com/workload: train", "snippet.py: |", "print(sys.version)"} {
+		if !strings.Contains(opts.AdditionalManifests[0], want) {
+			t.Errorf("expected ConfigMap to contain %q, got:\n%s", want, opts.AdditionalManifests[0])
+		}
+	}
+	want := MountInfo{Name: "snippet", Source: "train-snippet", MountPath: "/gcluster/snippet", Type: mountTypeConfigMap, ReadOnly: true}
+	if mount == nil || *mount != want {
+		t.Errorf("addSnippet() mount = %+v, want %+v", mount, want)
+	}
+
+	opts = ManifestOptions{WorkloadName: "train"}
+	if _, err := addSnippet(&opts, orchestrator.JobDefinition{Snippet: "echo hi", SnippetLanguage: orchestrator.SnippetLanguageBash}); err != nil {
+		t.Fatalf("addSnippet() error = %v", err)
+	}
+	if want := []string{"bash", "/gcluster/snippet/snippet.sh"}; !reflect.DeepEqual(opts.CommandArgs, want) {
+		t.Errorf("expected command %v, got %v", want, opts.CommandArgs)
+	}
+
+	if _, err := addSnippet(&opts, orchestrator.JobDefinition{Snippet: "puts 1", SnippetLanguage: "ruby"}); err == nil {
+		t.Error("expected an error for an unsupported language")
+	}
+	opts = ManifestOptions{WorkloadName: "train"}
+	if mount, err := addSnippet(&opts, orchestrator.JobDefinition{}); mount != nil || err != nil || len(opts.AdditionalManifests) != 0 {
+		t.Errorf("expected no changes without a snippet, got %+v, %v", mount, err)
+	}
+}
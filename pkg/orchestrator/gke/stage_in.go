@@ -0,0 +1,248 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	stageInContainerName = "stage-in"
+	stageInLogPrefix     = "gcluster-stage-in:"
+	// stageInProgressSeconds is how often the stage-in container reports the
+	// number of bytes copied so far.
+	stageInProgressSeconds = 60
+)
+
+var stageInPollInterval = 30 * time.Second
+
+// stageInScript copies each source/destination pair given as arguments with
+// gcloud storage rsync, which downloads many objects in parallel and slices
+// large objects into parallel ranged downloads. Progress is the size of the
+// destination directory, so files already present there count as copied.
+const stageInScript = `set -uo pipefail
+while [ $# -gt 0 ]; do
+  src=$1 dest=$2
+  shift 2
+  mkdir -p "$dest"
+  total=$(gcloud storage du --summarize "$src" | awk '{print $1}')
+  gcloud storage rsync --recursive "$src" "$dest" &
+  pid=$!
+  while kill -0 "$pid" 2>/dev/null; do
+    echo "` + stageInLogPrefix + ` progress $src $(du -sb "$dest" | cut -f1) ${total:-0}"
+    sleep "$PROGRESS_INTERVAL"
+  done
+  if ! wait "$pid"; then
+    echo "` + stageInLogPrefix + ` failed $src"
+    exit 1
+  fi
+  echo "` + stageInLogPrefix + ` done $src ${total:-0} ${total:-0}"
+done
+`
+
+// stageInJobName is the name of the Job that stages in a workload's datasets.
+func stageInJobName(workload string) string {
+	return workload + "-stage-in"
+}
+
+// buildStageInManifest returns the Job that copies the job's stage-in sources
+// onto the workload volumes they land on, or "" if nothing is staged in. Each
+// destination must be under the mount path of a writable PVC mount, which
+// covers filestore:// mounts and existing Parallelstore or Lustre claims.
+func buildStageInManifest(job orchestrator.JobDefinition, mounts []MountInfo) (string, error) {
+	if len(job.StageIn) == 0 {
+		return "", nil
+	}
+
+	var args []string
+	var volumeMounts, volumes []interface{}
+	used := map[string]bool{}
+	for _, s := range job.StageIn {
+		m, err := stageInMount(s.Dest, mounts)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, s.Source, s.Dest)
+		if used[m.Name] {
+			continue
+		}
+		used[m.Name] = true
+		volumeMounts = append(volumeMounts, map[string]interface{}{"name": m.Name, "mountPath": m.MountPath})
+		volumes = append(volumes, map[string]interface{}{"name": m.Name, "persistentVolumeClaim": map[string]interface{}{"claimName": m.Source}})
+	}
+
+	podSpec := map[string]interface{}{
+		"restartPolicy": "Never",
+		"containers": []interface{}{map[string]interface{}{
+			"name":    stageInContainerName,
+			"image":   cloudSDKImage,
+			"command": append([]string{"/bin/bash", "-c", stageInScript, stageInContainerName}, args...),
+			"env": []interface{}{
+				map[string]interface{}{"name": "PROGRESS_INTERVAL", "value": strconv.Itoa(stageInProgressSeconds)},
+			},
+			"volumeMounts": volumeMounts,
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "4", "memory": "8Gi"},
+			},
+		}},
+		"volumes": volumes,
+	}
+	if job.ServiceAccountName != "" {
+		podSpec["serviceAccountName"] = job.ServiceAccountName
+	}
+
+	spec := map[string]interface{}{
+		// gcloud storage rsync skips files that were already copied, so a
+		// retry resumes the transfer.
+		"backoffLimit": 2,
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"gcluster.google.com/stage-in": job.WorkloadName}},
+			"spec":     podSpec,
+		},
+	}
+	if job.TtlSecondsAfterFinished > 0 {
+		spec["ttlSecondsAfterFinished"] = job.TtlSecondsAfterFinished
+	}
+
+	b, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":   stageInJobName(job.WorkloadName),
+			"labels": map[string]interface{}{"gcluster.google.com/stage-in": job.WorkloadName},
+		},
+		"spec": spec,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal stage-in job: %w", err)
+	}
+	return string(b), nil
+}
+
+// stageInMount returns the writable PVC mount that dest is under.
+func stageInMount(dest string, mounts []MountInfo) (MountInfo, error) {
+	if m, ok := pvcMountOf(dest, mounts); ok {
+		if m.ReadOnly {
+			return MountInfo{}, fmt.Errorf("--stage-in destination %s is on the read-only mount %s", dest, m.MountPath)
+		}
+		return m, nil
+	}
+	return MountInfo{}, fmt.Errorf("--stage-in destination %s must be under the mount path of a filestore:// or PVC --mount", dest)
+}
+
+// stageIn creates the workload's volumes and stage-in Job and waits for the
+// transfers to complete, so the workload only starts once its data is in place.
+func (g *GKEOrchestrator) stageIn(ctx context.Context, opts ManifestOptions) error {
+	logging.Info("Staging in datasets for %s before submitting it...", opts.WorkloadName)
+	manifest := assembleManifest(opts.StageInManifest, opts.AdditionalManifests)
+	if err := g.applyManifests(ctx, []byte(manifest), stageInJobName(opts.WorkloadName)+".yaml"); err != nil {
+		return fmt.Errorf("failed to create stage-in job: %w", err)
+	}
+
+	ns, err := g.getCurrentNamespace()
+	if err != nil || ns == "" {
+		ns = "default"
+	}
+	return g.waitForStageIn(ctx, ns, opts.WorkloadName)
+}
+
+func (g *GKEOrchestrator) waitForStageIn(ctx context.Context, ns, workload string) error {
+	reported := map[string]string{}
+	for {
+		status, err := g.stageInStatus(ctx, ns, workload)
+		if err != nil {
+			return err
+		}
+		for _, t := range status.Transfers {
+			if line := t.String(); reported[t.Source] != line {
+				reported[t.Source] = line
+				logging.Info("Stage-in %s", line)
+			}
+		}
+		switch status.State {
+		case "Complete":
+			logging.Info("Stage-in for %s completed.", workload)
+			return nil
+		case "Failed":
+			return fmt.Errorf("stage-in for %s failed; check 'kubectl logs -n %s job/%s'", workload, ns, stageInJobName(workload))
+		}
+		g.sleep(stageInPollInterval)
+	}
+}
+
+type stageInJob struct {
+	Status struct {
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// stageInStatus reports the state of a workload's stage-in Job and the last
+// progress of each transfer read from its logs.
+func (g *GKEOrchestrator) stageInStatus(ctx context.Context, ns, workload string) (*orchestrator.StageInStatus, error) {
+	name := stageInJobName(workload)
+	var job stageInJob
+	if err := g.getResource(ctx, &job, batchJobGVR, ns, name); err != nil {
+		return nil, fmt.Errorf("failed to get stage-in job %s: %w", name, err)
+	}
+
+	status := &orchestrator.StageInStatus{State: "Running"}
+	for _, c := range job.Status.Conditions {
+		if c.Status == "True" && (c.Type == "Complete" || c.Type == "Failed") {
+			status.State = c.Type
+		}
+	}
+	if logs, err := g.jobLogs(ctx, ns, name, stageInContainerName); err == nil {
+		status.Transfers = parseStageInProgress(logs)
+	}
+	return status, nil
+}
+
+// parseStageInProgress returns the last progress line of each transfer in the
+// stage-in container's logs, in the order the transfers started.
+func parseStageInProgress(logs string) []orchestrator.TransferProgress {
+	var transfers []orchestrator.TransferProgress
+	index := map[string]int{}
+	for _, line := range strings.Split(logs, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), stageInLogPrefix))
+		if !strings.HasPrefix(strings.TrimSpace(line), stageInLogPrefix) || len(fields) != 4 || (fields[0] != "progress" && fields[0] != "done") {
+			continue
+		}
+		copied, err1 := strconv.ParseInt(fields[2], 10, 64)
+		total, err2 := strconv.ParseInt(fields[3], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		t := orchestrator.TransferProgress{Source: fields[1], CopiedBytes: copied, TotalBytes: total, Done: fields[0] == "done"}
+		if i, ok := index[t.Source]; ok {
+			transfers[i] = t
+			continue
+		}
+		index[t.Source] = len(transfers)
+		transfers = append(transfers, t)
+	}
+	return transfers
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	stageInRunningJSON  = `{"status":{"active":1}}`
+	stageInCompleteJSON = `{"status":{"succeeded":1,"conditions":[{"type":"Complete","status":"True"}]}}`
+	stageInFailedJSON   = `{"status":{"failed":3,"conditions":[{"type":"Failed","status":"True"}]}}`
+)
+
+func stageInTestMounts() []MountInfo {
+	return []MountInfo{
+		{Name: "vol-0", Source: "gcluster-filestore-data-share", MountPath: "/data", Type: "pvc"},
+		{Name: "vol-1", Source: "gs://bucket", MountPath: "/gcs", Type: "gcsfuse"},
+		{Name: "vol-2", Source: "ro-pvc", MountPath: "/ro", Type: "pvc", ReadOnly: true},
+	}
+}
+
+func TestBuildStageInManifest(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		WorkloadName:            "train",
+		ServiceAccountName:      "trainer",
+		TtlSecondsAfterFinished: 3600,
+		StageIn: []orchestrator.StageIn{
+			{Source: "gs://datasets/imagenet", Dest: "/data/imagenet"},
+			{Source: "gs://weights", Dest: "/data"},
+		},
+	}
+	manifest, err := buildStageInManifest(job, stageInTestMounts())
+	if err != nil {
+		t.Fatalf("buildStageInManifest() error = %v", err)
+	}
+	for _, want := range []string{
+		"kind: Job",
+		"name: train-stage-in",
+		"ttlSecondsAfterFinished: 3600",
+		"serviceAccountName: trainer",
+		"claimName: gcluster-filestore-data-share",
+		"- stage-in\n        - gs://datasets/imagenet\n        - /data/imagenet\n        - gs://weights\n        - /data\n",
+	} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("expected manifest to contain %q, got:\n%s", want, manifest)
+		}
+	}
+	if strings.Count(manifest, "claimName:") != 1 || strings.Contains(manifest, "vol-1") {
+		t.Errorf("expected only the destination volume to be mounted once, got:\n%s", manifest)
+	}
+
+	if manifest, err := buildStageInManifest(orchestrator.JobDefinition{WorkloadName: "train"}, nil); err != nil || manifest != "" {
+		t.Errorf("expected no manifest without stage-in, got %q, %v", manifest, err)
+	}
+}
+
+func TestBuildStageInManifest_InvalidDestination(t *testing.T) {
+	for dest, wantErr := range map[string]string{
+		"/gcs/imagenet":  "must be under the mount path",
+		"/datasets":      "must be under the mount path",
+		"/ro/checkpoint": "read-only",
+	} {
+		job := orchestrator.JobDefinition{WorkloadName: "train", StageIn: []orchestrator.StageIn{{Source: "gs://datasets", Dest: dest}}}
+		if _, err := buildStageInManifest(job, stageInTestMounts()); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("buildStageInManifest(%s) error = %v, want %q", dest, err, wantErr)
+		}
+	}
+}
+
+func TestParseStageInProgress(t *testing.T) {
+	logs := strings.Join([]string{
+		"gcluster-stage-in: progress gs://weights 0 2048",
+		"Copying gs://weights/model.bin to file:///data/model.bin",
+		"gcluster-stage-in: done gs://weights 2048 2048",
+		"gcluster-stage-in: progress gs://datasets/imagenet 100 4000",
+		"gcluster-stage-in: progress gs://datasets/imagenet 3000 4000",
+		"gcluster-stage-in: failed gs://other",
+	}, "\n")
+	want := []orchestrator.TransferProgress{
+		{Source: "gs://weights", CopiedBytes: 2048, TotalBytes: 2048, Done: true},
+		{Source: "gs://datasets/imagenet", CopiedBytes: 3000, TotalBytes: 4000},
+	}
+	if got := parseStageInProgress(logs); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStageInProgress() = %+v, want %+v", got, want)
+	}
+}
+
+// fakeJobPod returns the pod of the Job job in namespace ns, running container.
+func fakeJobPod(ns, job, container string) string {
+	return fakeObject("Pod", ns, job+"-abcde", fmt.Sprintf(`{"metadata": {"labels": {"batch.kubernetes.io/job-name": %q}}, "spec": {"containers": [{"name": %q}]}}`, job, container))
+}
+
+func TestStageIn(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldInterval := stageInPollInterval
+	stageInPollInterval = 0
+	defer func() { stageInPollInterval = oldInterval }()
+
+	tests := []struct {
+		name    string
+		states  []string
+		wantErr string
+	}{
+		{"waits for completion", []string{stageInRunningJSON, stageInCompleteJSON}, ""},
+		{"reports failure", []string{stageInFailedJSON}, "kubectl logs -n default job/train-stage-in"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			client := newFakeDynamicClient(fakeJobPod("default", "train-stage-in", stageInContainerName))
+			fakeGets(client, "Job", tc.states...)
+			orc.dynClient = client
+			orc.kubeClient.(*MockKubeClient).Logs = map[string]string{
+				"train-stage-in-abcde/" + stageInContainerName: "gcluster-stage-in: done gs://datasets 100 100\n",
+			}
+			opts := ManifestOptions{WorkloadName: "train", StageInManifest: "kind: Job", AdditionalManifests: []string{"kind: PersistentVolumeClaim"}}
+
+			err := orc.stageIn(context.Background(), opts)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("stageIn() error = %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			applied := orc.kubeClient.(*MockKubeClient).Applied
+			if len(applied) != 1 || !strings.Contains(applied[0], "kind: PersistentVolumeClaim\n---\nkind: Job") {
+				t.Errorf("expected the volumes and stage-in job to be applied together, got %v", applied)
+			}
+		})
+	}
+}
+
+func TestGetJobStatus_PendingStageIn(t *testing.T) {
+	exec := NewMockExecutor(map[string][]shell.CommandResult{
+		"gcloud container clusters get-credentials": {{ExitCode: 0}},
+	})
+	orc := newTestGKEOrchestrator(exec)
+	client := newFakeDynamicClient(fakeJobPod("default", "train-stage-in", stageInContainerName))
+	fakeGets(client, "Job", stageInRunningJSON)
+	orc.dynClient = client
+	orc.kubeClient = noJobSetKubeClient{&MockKubeClient{Logs: map[string]string{
+		"train-stage-in-abcde/" + stageInContainerName: "gcluster-stage-in: progress gs://datasets 10 100\n",
+	}}}
+
+	detail, err := orc.GetJobStatus(context.Background(), "train", orchestrator.StatusOptions{ClusterName: "c", ClusterLocation: "us-central1", ProjectID: "p"})
+	if err != nil {
+		t.Fatalf("GetJobStatus() error = %v", err)
+	}
+	if detail.Status != "StagingIn" || detail.StageIn == nil || len(detail.StageIn.Transfers) != 1 || detail.StageIn.Transfers[0].CopiedBytes != 10 {
+		t.Errorf("unexpected status for a workload being staged in: %+v", detail)
+	}
+}
+
+// noJobSetKubeClient is a MockKubeClient in which no JobSet exists yet.
+type noJobSetKubeClient struct {
+	*MockKubeClient
+}
+
+func (noJobSetKubeClient) GetJobNamespace(context.Context, string) (string, error) {
+	return "", errors.New("jobset train not found")
+}
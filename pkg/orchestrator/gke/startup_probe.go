@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// startupProbePeriod is how often the startup probe of a workload runs.
+const startupProbePeriod = 10 * time.Second
+
+// addStartupProbe sets probe as the startupProbe of the workload's
+// containers, which defers restarts until it succeeds or probe.Timeout
+// passes, and as their readinessProbe, so pods only count as ready once
+// started.
+func addStartupProbe(opts *ManifestOptions, probe *orchestrator.StartupProbe) error {
+	if probe == nil {
+		return nil
+	}
+	var handler corev1.ProbeHandler
+	switch probe.Kind {
+	case orchestrator.StartupProbeHTTP:
+		handler.HTTPGet = &corev1.HTTPGetAction{Path: probe.Path, Port: intstr.FromInt(probe.Port)}
+	case orchestrator.StartupProbeTCP:
+		handler.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt(probe.Port)}
+	case orchestrator.StartupProbeExec:
+		handler.Exec = &corev1.ExecAction{Command: []string{"/bin/sh", "-c", probe.Command}}
+	default:
+		return fmt.Errorf("unknown startup probe kind %q", probe.Kind)
+	}
+
+	period := int32(startupProbePeriod.Seconds())
+	opts.StartupProbe = &corev1.Probe{
+		ProbeHandler:     handler,
+		PeriodSeconds:    period,
+		FailureThreshold: int32(max((probe.Timeout+startupProbePeriod-1)/startupProbePeriod, 1)),
+	}
+	opts.ReadinessProbe = &corev1.Probe{ProbeHandler: handler, PeriodSeconds: period}
+	return nil
+}
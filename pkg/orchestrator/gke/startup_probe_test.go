@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// containerProbes returns the startupProbe and readinessProbe of every
+// container in a rendered manifest.
+func containerProbes(t *testing.T, manifest string) []map[string]interface{} {
+	t.Helper()
+	var obj map[string]interface{}
+	if err := k8syaml.Unmarshal([]byte(manifest), &obj); err != nil {
+		t.Fatalf("failed to parse manifest: %v\n%s", err, manifest)
+	}
+	var probes []map[string]interface{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if containers, ok := v["containers"].([]interface{}); ok {
+				for _, c := range containers {
+					c := c.(map[string]interface{})
+					probes = append(probes, map[string]interface{}{"startupProbe": c["startupProbe"], "readinessProbe": c["readinessProbe"]})
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(obj)
+	return probes
+}
+
+func TestAddStartupProbe(t *testing.T) {
+	tests := []struct {
+		probe   orchestrator.StartupProbe
+		handler map[string]interface{}
+		failure float64
+	}{
+		{
+			probe:   orchestrator.StartupProbe{Kind: orchestrator.StartupProbeHTTP, Port: 8080, Path: "/healthz", Timeout: 20 * time.Minute},
+			handler: map[string]interface{}{"httpGet": map[string]interface{}{"path": "/healthz", "port": float64(8080)}},
+			failure: 120,
+		},
+		{
+			probe:   orchestrator.StartupProbe{Kind: orchestrator.StartupProbeTCP, Port: 9000, Timeout: 95 * time.Second},
+			handler: map[string]interface{}{"tcpSocket": map[string]interface{}{"port": float64(9000)}},
+			failure: 10,
+		},
+		{
+			probe:   orchestrator.StartupProbe{Kind: orchestrator.StartupProbeExec, Command: "test -f /tmp/ready", Timeout: time.Second},
+			handler: map[string]interface{}{"exec": map[string]interface{}{"command": []interface{}{"/bin/sh", "-c", "test -f /tmp/ready"}}},
+			failure: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.probe.Kind, func(t *testing.T) {
+			data := goldenTemplateData(t)
+			opts := ManifestOptions{}
+			if err := addStartupProbe(&opts, &tc.probe); err != nil {
+				t.Fatalf("addStartupProbe() error = %v", err)
+			}
+			data.StartupProbe, data.ReadinessProbe = opts.StartupProbe, opts.ReadinessProbe
+
+			for _, kind := range []string{orchestrator.WorkloadKindJobSet, orchestrator.WorkloadKindJob, orchestrator.WorkloadKindDeployment} {
+				manifest, err := manifestRenderers[kind].Render(data)
+				if err != nil {
+					t.Fatalf("%s: Render() error = %v", kind, err)
+				}
+				probes := containerProbes(t, manifest)
+				if len(probes) == 0 {
+					t.Fatalf("%s: no containers found", kind)
+				}
+				for _, p := range probes {
+					startup, _ := p["startupProbe"].(map[string]interface{})
+					readiness, _ := p["readinessProbe"].(map[string]interface{})
+					if startup == nil || readiness == nil {
+						t.Fatalf("%s: expected startup and readiness probes, got %v", kind, p)
+					}
+					if startup["failureThreshold"] != tc.failure || startup["periodSeconds"] != float64(10) {
+						t.Errorf("%s: unexpected startup probe timing %v", kind, startup)
+					}
+					for k, v := range tc.handler {
+						if !reflect.DeepEqual(startup[k], v) || !reflect.DeepEqual(readiness[k], v) {
+							t.Errorf("%s: expected %s %v in both probes, got %v and %v", kind, k, v, startup[k], readiness[k])
+						}
+					}
+				}
+			}
+		})
+	}
+
+	opts := ManifestOptions{}
+	if err := addStartupProbe(&opts, nil); err != nil || opts.StartupProbe != nil || opts.ReadinessProbe != nil {
+		t.Errorf("expected no probes without a startup probe, got %v, %v, %v", opts.StartupProbe, opts.ReadinessProbe, err)
+	}
+}
+
+func TestPodState(t *testing.T) {
+	started, notStarted := true, false
+	running := containerStatus{}
+	running.State.Running = &struct{}{}
+	loading := running
+	loading.Started = &notStarted
+	ready := running
+	ready.Started = &started
+	crashLooping := containerStatus{}
+	crashLooping.State.Waiting = &struct {
+		Reason string `json:"reason"`
+	}{Reason: "CrashLoopBackOff"}
+
+	for name, tc := range map[string]struct {
+		statuses []containerStatus
+		want     string
+	}{
+		"no containers":       {nil, ""},
+		"started":             {[]containerStatus{ready}, ""},
+		"no startup probe":    {[]containerStatus{running}, ""},
+		"loading":             {[]containerStatus{ready, loading}, orchestrator.PodStateLoading},
+		"crash looping":       {[]containerStatus{crashLooping}, orchestrator.PodStateCrashLooping},
+		"crash looping first": {[]containerStatus{loading, crashLooping}, orchestrator.PodStateCrashLooping},
+	} {
+		if got := podState(tc.statuses); got != tc.want {
+			t.Errorf("%s: podState() = %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+func TestPodStatesSummary(t *testing.T) {
+	pods := fakeList("Pod", "default", map[string]string{"jobset.sigs.k8s.io/jobset-name": "my-job"}, `{"items": [
+		{"metadata": {"name": "a"}, "status": {"phase": "Running", "containerStatuses": [{"started": false, "state": {"running": {}}}]}},
+		{"metadata": {"name": "b"}, "status": {"phase": "Running", "containerStatuses": [{"started": false, "state": {"running": {}}}]}},
+		{"metadata": {"name": "c"}, "status": {"phase": "Running", "containerStatuses": [{"restartCount": 3, "state": {"waiting": {"reason": "CrashLoopBackOff"}}}]}}
+	]}`)
+	g := newTestGKEOrchestrator(NewMockExecutor(nil))
+	client := newFakeDynamicClient(pods)
+	g.dynClient = client
+	got := g.podStatesSummary(context.Background(), "default", "my-job")
+	for _, want := range []string{"1 of 3 pods are crash looping", "2 of 3 pods are still loading"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("podStatesSummary() = %q, want it to contain %q", got, want)
+		}
+	}
+	client.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(podGVR.GroupResource(), "", errors.New("denied"))
+	})
+	if got := g.podStatesSummary(context.Background(), "default", "my-job"); got != "" {
+		t.Errorf("expected no summary when the pods cannot be listed, got %q", got)
+	}
+}
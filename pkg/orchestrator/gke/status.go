@@ -0,0 +1,314 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// jobSetDetail is the subset of a JobSet object read by GetJobStatus.
+type jobSetDetail struct {
+	Metadata struct {
+		UID    string            `json:"uid"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		ReplicatedJobs []struct {
+			Name     string `json:"name"`
+			Replicas int    `json:"replicas"`
+		} `json:"replicatedJobs"`
+		FailurePolicy struct {
+			MaxRestarts int `json:"maxRestarts"`
+		} `json:"failurePolicy"`
+	} `json:"spec"`
+	Status struct {
+		Restarts             int `json:"restarts"`
+		ReplicatedJobsStatus []struct {
+			Name      string `json:"name"`
+			Active    int    `json:"active"`
+			Ready     int    `json:"ready"`
+			Succeeded int    `json:"succeeded"`
+			Failed    int    `json:"failed"`
+			Suspended int    `json:"suspended"`
+		} `json:"replicatedJobsStatus"`
+	} `json:"status"`
+}
+
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string            `json:"name"`
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			NodeName   string `json:"nodeName"`
+			Containers []struct {
+				Name      string `json:"name"`
+				Resources struct {
+					Limits map[string]string `json:"limits"`
+				} `json:"resources"`
+			} `json:"containers"`
+		} `json:"spec"`
+		Status struct {
+			Phase             string            `json:"phase"`
+			ContainerStatuses []containerStatus `json:"containerStatuses"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type containerStatus struct {
+	Name         string `json:"name"`
+	RestartCount int    `json:"restartCount"`
+	Started      *bool  `json:"started"`
+	State        struct {
+		Waiting *struct {
+			Reason string `json:"reason"`
+		} `json:"waiting"`
+		Running    *struct{}             `json:"running"`
+		Terminated *containerTermination `json:"terminated"`
+	} `json:"state"`
+	LastState struct {
+		Terminated *containerTermination `json:"terminated"`
+	} `json:"lastState"`
+}
+
+// podState returns the PodStatus.State of a pod from its container statuses.
+// A crash looping container takes precedence over one that is still loading.
+func podState(statuses []containerStatus) string {
+	state := ""
+	for _, cs := range statuses {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff":
+			return orchestrator.PodStateCrashLooping
+		case cs.State.Running != nil && cs.Started != nil && !*cs.Started:
+			state = orchestrator.PodStateLoading
+		}
+	}
+	return state
+}
+
+// containerTerminations returns why the containers of a pod last stopped
+// abnormally, preferring the current state over the previous one. The memory
+// high-water mark of an OOMKilled container is its memory limit.
+func containerTerminations(statuses []containerStatus, memoryLimits map[string]string) []orchestrator.ContainerTermination {
+	var out []orchestrator.ContainerTermination
+	for _, cs := range statuses {
+		t := cs.State.Terminated
+		if t == nil || (t.ExitCode == 0 && t.Reason != "OOMKilled") {
+			t = cs.LastState.Terminated
+		}
+		if t == nil || (t.ExitCode == 0 && t.Reason != "OOMKilled") {
+			continue
+		}
+		ct := orchestrator.ContainerTermination{Container: cs.Name, Reason: t.Reason, ExitCode: t.ExitCode}
+		if t.Reason == "OOMKilled" {
+			ct.MemoryHighWater = memoryLimits[cs.Name]
+		}
+		out = append(out, ct)
+	}
+	return out
+}
+
+// GetJobStatus reports the state of a workload: the JobSet condition and
+// restart budget, per-replicated-job counts, per-pod phases and Kueue
+// admission, together with the GPU errors on the nodes of its pods and the
+// probable causes of its failures.
+func (g *GKEOrchestrator) GetJobStatus(ctx context.Context, name string, opts orchestrator.StatusOptions) (orchestrator.JobStatusDetail, error) {
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return orchestrator.JobStatusDetail{}, err
+	}
+	detail, err := g.jobStatusDetail(ctx, name)
+	if err != nil {
+		return detail, err
+	}
+	detail.GPUErrors = g.workloadGPUErrors(ctx, detail.Namespace, detail.Pods)
+	detail.ProbableCauses = probableCauses(detail)
+	return detail, nil
+}
+
+// jobStatusDetail returns the state of workload name in the cluster the
+// kubeconfig points at.
+func (g *GKEOrchestrator) jobStatusDetail(ctx context.Context, name string) (orchestrator.JobStatusDetail, error) {
+	ns, err := g.getJobNamespace(ctx, name)
+	if err != nil {
+		// The JobSet is only created once its stage-in has completed.
+		if detail, ok := g.pendingStageInStatus(ctx, name); ok {
+			return detail, nil
+		}
+		return orchestrator.JobStatusDetail{}, err
+	}
+	detail, err := g.collectJobStatus(ctx, name, ns)
+	if err == nil {
+		detail.StageIn, _ = g.stageInStatus(ctx, ns, name)
+	}
+	return detail, err
+}
+
+// pendingStageInStatus reports a workload whose stage-in Job is still running
+// or has failed in the current namespace, before its JobSet exists.
+func (g *GKEOrchestrator) pendingStageInStatus(ctx context.Context, name string) (orchestrator.JobStatusDetail, bool) {
+	ns, err := g.getCurrentNamespace()
+	if err != nil || ns == "" {
+		ns = "default"
+	}
+	stageIn, err := g.stageInStatus(ctx, ns, name)
+	if err != nil {
+		return orchestrator.JobStatusDetail{}, false
+	}
+	status := "StagingIn"
+	if stageIn.State == "Failed" {
+		status = "StageInFailed"
+	}
+	return orchestrator.JobStatusDetail{Name: name, Namespace: ns, Status: status, StageIn: stageIn}, true
+}
+
+func (g *GKEOrchestrator) collectJobStatus(ctx context.Context, name, ns string) (orchestrator.JobStatusDetail, error) {
+	detail := orchestrator.JobStatusDetail{Name: name, Namespace: ns}
+
+	obj, err := g.getObject(ctx, jobSetGVR, ns, name)
+	if err != nil {
+		return detail, err
+	}
+	var js jobSetDetail
+	if err := decodeObject(obj.Object, &js); err != nil {
+		return detail, fmt.Errorf("failed to parse jobset %s: %w", name, err)
+	}
+
+	detail.Status, _ = parseJobStatus(obj.Object)
+	detail.Restarts = js.Status.Restarts
+	detail.MaxRestarts = js.Spec.FailurePolicy.MaxRestarts
+	detail.RetriesRemaining = max(detail.MaxRestarts-detail.Restarts, 0)
+	detail.KueueQueue = js.Metadata.Labels["kueue.x-k8s.io/queue-name"]
+
+	counts := map[string]orchestrator.ReplicatedJobStatus{}
+	for _, rs := range js.Status.ReplicatedJobsStatus {
+		counts[rs.Name] = orchestrator.ReplicatedJobStatus{
+			Name: rs.Name, Active: rs.Active, Ready: rs.Ready, Succeeded: rs.Succeeded, Failed: rs.Failed, Suspended: rs.Suspended,
+		}
+	}
+	for _, rj := range js.Spec.ReplicatedJobs {
+		rs := counts[rj.Name]
+		rs.Name = rj.Name
+		rs.Replicas = rj.Replicas
+		detail.ReplicatedJobs = append(detail.ReplicatedJobs, rs)
+	}
+
+	detail.KueueState, detail.ClusterQueue = g.kueueAdmission(ctx, ns, js.Metadata.UID)
+
+	pods, err := g.listWorkloadPods(ctx, ns, name)
+	if err != nil {
+		return detail, err
+	}
+	detail.Pods = pods
+	return detail, nil
+}
+
+func (g *GKEOrchestrator) kueueAdmission(ctx context.Context, ns, uid string) (state string, clusterQueue string) {
+	wl := g.jobKueueWorkload(ctx, ns, uid)
+	if wl == nil {
+		return "", ""
+	}
+	state = wl.latestCondition()
+	if wl.Status.Admission != nil {
+		clusterQueue = wl.Status.Admission.ClusterQueue
+	}
+	return state, clusterQueue
+}
+
+// jobKueueWorkload returns the Kueue Workload of the JobSet with the given
+// UID, or nil when it has none or Kueue is not installed.
+func (g *GKEOrchestrator) jobKueueWorkload(ctx context.Context, ns, uid string) *kueueWorkload {
+	if uid == "" {
+		return nil
+	}
+	var list kueueWorkloadList
+	err := g.listResources(ctx, &list, workloadGVR, ns, metav1.ListOptions{LabelSelector: "kueue.x-k8s.io/job-uid=" + uid})
+	if err != nil || len(list.Items) == 0 {
+		return nil
+	}
+	return &list.Items[len(list.Items)-1]
+}
+
+// addKueueStates sets the Kueue state of each JobSet in jobs from the Kueue
+// Workload that owns it. The states are left empty when the Workloads cannot
+// be listed, for example because Kueue is not installed.
+func (g *GKEOrchestrator) addKueueStates(ctx context.Context, jobs []orchestrator.JobStatus) {
+	if len(jobs) == 0 {
+		return
+	}
+	var list kueueWorkloadList
+	if err := g.listResources(ctx, &list, workloadGVR, "", metav1.ListOptions{}); err != nil {
+		logging.Warn("Could not list Kueue workloads, so their admission state is not shown: %v", err)
+		return
+	}
+
+	// A JobSet that was suspended and resumed can own several Workloads;
+	// the newest one is current.
+	states := map[string]string{}
+	created := map[string]string{}
+	for _, wl := range list.Items {
+		for _, ref := range wl.Metadata.OwnerReferences {
+			if ref.Kind != "JobSet" {
+				continue
+			}
+			key := wl.Metadata.Namespace + "/" + ref.Name
+			if wl.Metadata.CreationTimestamp >= created[key] {
+				created[key], states[key] = wl.Metadata.CreationTimestamp, wl.latestCondition()
+			}
+		}
+	}
+	for i := range jobs {
+		jobs[i].KueueState = states[jobs[i].Namespace+"/"+jobs[i].Name]
+	}
+}
+
+func (g *GKEOrchestrator) listWorkloadPods(ctx context.Context, ns, name string) ([]orchestrator.PodStatus, error) {
+	var list podList
+	if err := g.listResources(ctx, &list, podGVR, ns, metav1.ListOptions{LabelSelector: "jobset.sigs.k8s.io/jobset-name=" + name}); err != nil {
+		return nil, fmt.Errorf("failed to list pods for %s: %w", name, err)
+	}
+
+	var pods []orchestrator.PodStatus
+	for _, p := range list.Items {
+		restarts := 0
+		for _, cs := range p.Status.ContainerStatuses {
+			restarts += cs.RestartCount
+		}
+		memoryLimits := map[string]string{}
+		for _, c := range p.Spec.Containers {
+			if limit := c.Resources.Limits["memory"]; limit != "" {
+				memoryLimits[c.Name] = limit
+			}
+		}
+		pods = append(pods, orchestrator.PodStatus{
+			Name:          p.Metadata.Name,
+			ReplicatedJob: p.Metadata.Labels["jobset.sigs.k8s.io/replicatedjob-name"],
+			Phase:         p.Status.Phase,
+			Node:          p.Spec.NodeName,
+			Restarts:      restarts,
+			State:         podState(p.Status.ContainerStatuses),
+			Terminations:  containerTerminations(p.Status.ContainerStatuses, memoryLimits),
+		})
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
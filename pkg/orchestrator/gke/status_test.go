@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"hpc-toolkit/pkg/orchestrator"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const statusJobSetJSON = `{
+  "apiVersion": "jobset.x-k8s.io/v1alpha2", "kind": "JobSet",
+  "metadata": {"name": "train", "namespace": "team-a", "uid": "uid-123", "labels": {"kueue.x-k8s.io/queue-name": "multislice-queue"}},
+  "spec": {
+    "suspend": false,
+    "failurePolicy": {"maxRestarts": 3},
+    "replicatedJobs": [{"name": "main-job", "replicas": 2}]
+  },
+  "status": {
+    "restarts": 1,
+    "replicatedJobsStatus": [{"name": "main-job", "active": 1, "ready": 1, "failed": 1}]
+  }
+}`
+
+const statusPodsJSON = `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "Pod",
+   "metadata": {"name": "train-main-job-1-0-b", "namespace": "team-a", "labels": {"jobset.sigs.k8s.io/jobset-name": "train", "jobset.sigs.k8s.io/replicatedjob-name": "main-job"}},
+   "spec": {"nodeName": "node-b"},
+   "status": {"phase": "Pending"}},
+  {"apiVersion": "v1", "kind": "Pod",
+   "metadata": {"name": "train-main-job-0-0-a", "namespace": "team-a", "labels": {"jobset.sigs.k8s.io/jobset-name": "train", "jobset.sigs.k8s.io/replicatedjob-name": "main-job"}},
+   "spec": {"nodeName": "node-a", "containers": [{"name": "workload", "resources": {"limits": {"memory": "16Gi"}}}, {"name": "sidecar"}]},
+   "status": {"phase": "Running", "containerStatuses": [
+     {"name": "workload", "restartCount": 2, "state": {"running": {}}, "lastState": {"terminated": {"reason": "OOMKilled", "exitCode": 137}}},
+     {"name": "sidecar", "restartCount": 1, "lastState": {"terminated": {"reason": "Completed", "exitCode": 0}}}]}}
+]}`
+
+const statusWorkloadsJSON = `{"apiVersion": "v1", "kind": "List", "items": [{
+  "apiVersion": "kueue.x-k8s.io/v1beta1", "kind": "Workload",
+  "metadata": {"name": "jobset-train-1a2b3", "namespace": "team-a", "labels": {"kueue.x-k8s.io/job-uid": "uid-123"}},
+  "status": {
+    "admission": {"clusterQueue": "default-queue"},
+    "conditions": [
+      {"type": "QuotaReserved", "status": "True", "lastTransitionTime": "2026-01-01T00:00:00Z"},
+      {"type": "Admitted", "status": "True", "lastTransitionTime": "2026-01-01T00:00:05Z"}
+    ]
+  }
+}]}`
+
+func TestCollectJobStatus(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{})
+	// The Workload of another JobSet is not the one admitting train.
+	g.dynClient = newFakeDynamicClient(statusJobSetJSON, statusPodsJSON, statusWorkloadsJSON, `
+apiVersion: kueue.x-k8s.io/v1beta1
+kind: Workload
+metadata: {name: jobset-eval-4c5d6, namespace: team-a, labels: {kueue.x-k8s.io/job-uid: uid-456}}
+status: {admission: {clusterQueue: other-queue}}
+`)
+
+	got, err := g.collectJobStatus(context.Background(), "train", "team-a")
+	if err != nil {
+		t.Fatalf("collectJobStatus() error = %v", err)
+	}
+
+	want := orchestrator.JobStatusDetail{
+		Name:             "train",
+		Namespace:        "team-a",
+		Status:           "Running",
+		Restarts:         1,
+		MaxRestarts:      3,
+		RetriesRemaining: 2,
+		KueueQueue:       "multislice-queue",
+		KueueState:       "Admitted",
+		ClusterQueue:     "default-queue",
+		ReplicatedJobs: []orchestrator.ReplicatedJobStatus{
+			{Name: "main-job", Replicas: 2, Active: 1, Ready: 1, Failed: 1},
+		},
+		Pods: []orchestrator.PodStatus{
+			{Name: "train-main-job-0-0-a", ReplicatedJob: "main-job", Phase: "Running", Node: "node-a", Restarts: 3, Terminations: []orchestrator.ContainerTermination{
+				{Container: "workload", Reason: "OOMKilled", ExitCode: 137, MemoryHighWater: "16Gi"},
+			}},
+			{Name: "train-main-job-1-0-b", ReplicatedJob: "main-job", Phase: "Pending", Node: "node-b"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectJobStatus() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCollectJobStatus_MissingJobSet(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{})
+	if _, err := g.collectJobStatus(context.Background(), "train", "default"); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected NotFound error, got %v", err)
+	}
+}
+
+const listWorkloadsJSON = `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "kueue.x-k8s.io/v1beta1", "kind": "Workload",
+   "metadata": {"name": "jobset-train-1", "namespace": "default", "creationTimestamp": "2026-01-01T00:00:00Z", "ownerReferences": [{"kind": "JobSet", "name": "train"}]},
+   "status": {"conditions": [{"type": "Evicted", "status": "True", "lastTransitionTime": "2026-01-01T00:10:00Z"}]}},
+  {"apiVersion": "kueue.x-k8s.io/v1beta1", "kind": "Workload",
+   "metadata": {"name": "jobset-train-2", "namespace": "default", "creationTimestamp": "2026-01-01T01:00:00Z", "ownerReferences": [{"kind": "JobSet", "name": "train"}]},
+   "status": {"conditions": [
+     {"type": "QuotaReserved", "status": "True", "lastTransitionTime": "2026-01-01T01:00:00Z"},
+     {"type": "Admitted", "status": "True", "lastTransitionTime": "2026-01-01T01:00:05Z"}
+   ]}},
+  {"apiVersion": "kueue.x-k8s.io/v1beta1", "kind": "Workload",
+   "metadata": {"name": "jobset-eval-1", "namespace": "research", "creationTimestamp": "2026-01-01T00:00:00Z", "ownerReferences": [{"kind": "JobSet", "name": "eval"}]},
+   "status": {"conditions": [{"type": "QuotaReserved", "status": "False", "lastTransitionTime": "2026-01-01T00:00:00Z"}]}}
+]}`
+
+func TestAddKueueStates(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{})
+	g.dynClient = newFakeDynamicClient(listWorkloadsJSON)
+	jobs := []orchestrator.JobStatus{
+		{Name: "train", Namespace: "default"},
+		{Name: "eval", Namespace: "research"},
+		{Name: "train", Namespace: "research"},
+	}
+
+	g.addKueueStates(context.Background(), jobs)
+
+	var got []string
+	for _, job := range jobs {
+		got = append(got, job.KueueState)
+	}
+	// The newest Workload of default/train is admitted; research/eval has
+	// no true condition and research/train has no Workload.
+	if want := []string{"Admitted", "", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("KueueStates = %q, want %q", got, want)
+	}
+}
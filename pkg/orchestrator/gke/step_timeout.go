@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"hpc-toolkit/pkg/shell"
+)
+
+// Steps of the submission workflow that can be given a timeout.
+const (
+	stepCredentials = "credentials"
+	stepBuild       = "build"
+	stepApply       = "apply"
+)
+
+// stepTimeoutFlags are the submit flags that set the timeout of each step.
+var stepTimeoutFlags = map[string]string{
+	stepCredentials: "--credentials-timeout",
+	stepBuild:       "--build-timeout",
+	stepApply:       "--apply-timeout",
+}
+
+// StepTimeoutError reports a submission step that did not finish in time.
+// Image is the image pushed by an earlier step, if any, which a resubmission
+// can reuse instead of building it again.
+type StepTimeoutError struct {
+	Step    string
+	Timeout time.Duration
+	Image   string
+}
+
+func (e *StepTimeoutError) Error() string {
+	msg := fmt.Sprintf("the %s step did not finish within %s; raise %s to allow it more time", e.Step, e.Timeout, stepTimeoutFlags[e.Step])
+	if e.Image != "" {
+		msg += fmt.Sprintf(". The image %s was pushed; resubmit with --image %s to skip the build", e.Image, e.Image)
+	}
+	return msg
+}
+
+// runStep runs fn, one step of the submission workflow, with a context that
+// is canceled once timeout passes, and then fails with a *StepTimeoutError.
+// Commands fn runs through executorWithContext(g.executor, ctx) are killed
+// at the deadline, and fn is waited for so that nothing it started outlives the
+// step. A zero timeout runs fn with ctx as is.
+//
+// The step also ends when ctx is canceled; the error of fn, which deletes the
+// objects it applied, is then reported as a cancellation.
+func (g *GKEOrchestrator) runStep(ctx context.Context, step string, timeout time.Duration, image string, fn func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, &StepTimeoutError{Step: step, Timeout: timeout, Image: image})
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return stepCanceled(step, context.Cause(ctx))
+	}
+
+	err := fn(ctx)
+	if ctx.Err() == nil {
+		return err
+	}
+	var timeoutErr *StepTimeoutError
+	if errors.As(context.Cause(ctx), &timeoutErr) {
+		return timeoutErr
+	}
+	if err == nil {
+		// The step finished before it noticed the cancellation.
+		return nil
+	}
+	return stepCanceled(step, err)
+}
+
+// stepCanceled reports that step was interrupted because the submission was
+// canceled. err wraps the cancellation cause.
+func stepCanceled(step string, err error) error {
+	return fmt.Errorf("job submission canceled during the %s step: %w", step, err)
+}
+
+// executorWithContext returns an executor that runs the commands of e until
+// ctx is done. Local commands are killed at that point; the commands of other
+// executors, such as test mocks, are refused from then on.
+func executorWithContext(e Executor, ctx context.Context) Executor {
+	switch e := e.(type) {
+	case *DefaultExecutor:
+		return &DefaultExecutor{ctx: ctx}
+	case *impersonatingExecutor:
+		return &impersonatingExecutor{Executor: executorWithContext(e.Executor, ctx), principal: e.principal}
+	default:
+		return &contextExecutor{Executor: e, ctx: ctx}
+	}
+}
+
+// contextExecutor refuses the commands of its executor once ctx is done.
+type contextExecutor struct {
+	Executor
+	ctx context.Context
+}
+
+func (e *contextExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	if err := e.ctx.Err(); err != nil {
+		return shell.CommandResult{ExitCode: 1, Stderr: err.Error()}
+	}
+	return e.Executor.ExecuteCommand(name, args...)
+}
+
+func (e *contextExecutor) ExecuteCommandStream(name string, args ...string) error {
+	if err := e.ctx.Err(); err != nil {
+		return err
+	}
+	return e.Executor.ExecuteCommandStream(name, args...)
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/imagebuilder"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+func TestRunStep_TimesOut(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{"gcloud storage": {{ExitCode: 0}}})
+	g := newTestGKEOrchestrator(mock)
+	var res shell.CommandResult
+	returned := false
+
+	err := g.runStep(context.Background(), stepApply, 10*time.Millisecond, "us-docker.pkg.dev/p/r/img:abc", func(ctx context.Context) error {
+		<-ctx.Done()
+		res = executorWithContext(g.executor, ctx).ExecuteCommand("gcloud", "storage", "ls")
+		returned = true
+		return ctx.Err()
+	})
+
+	var timeoutErr *StepTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("runStep() error = %v, want a *StepTimeoutError", err)
+	}
+	for _, want := range []string{"apply step did not finish within 10ms", "--apply-timeout", "resubmit with --image us-docker.pkg.dev/p/r/img:abc"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if !returned {
+		t.Fatal("expected runStep to wait for the step to return")
+	}
+	// Commands the step runs after the deadline are refused.
+	if res.ExitCode == 0 || mock.callCount["gcloud storage"] != 0 {
+		t.Errorf("expected the command run after the timeout to be refused, got %+v, calls: %v", res, mock.callCount)
+	}
+}
+
+func TestRunStep_FinishesInTime(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{"gcloud version": {{ExitCode: 0}}})
+	g := newTestGKEOrchestrator(mock)
+	stepErr := errors.New("build failed")
+
+	err := g.runStep(context.Background(), stepBuild, time.Minute, "", func(ctx context.Context) error {
+		if res := executorWithContext(g.executor, ctx).ExecuteCommand("gcloud", "version"); res.ExitCode != 0 {
+			t.Errorf("expected the command to run during the step, got %+v", res)
+		}
+		return stepErr
+	})
+	if err != stepErr {
+		t.Errorf("runStep() error = %v, want %v", err, stepErr)
+	}
+}
+
+func TestRunStep_Canceled(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{"gcloud storage": {{ExitCode: 0}}})
+	g := newTestGKEOrchestrator(mock)
+	ctx, cancel := context.WithCancel(context.Background())
+	applyErr := errors.New("rolled back")
+
+	err := g.runStep(ctx, stepApply, time.Minute, "", func(stepCtx context.Context) error {
+		cancel()
+		<-stepCtx.Done()
+		if res := executorWithContext(g.executor, stepCtx).ExecuteCommand("gcloud", "storage", "ls"); res.ExitCode == 0 {
+			t.Errorf("expected the command run after the cancellation to be refused, got %+v", res)
+		}
+		return applyErr
+	})
+	if !errors.Is(err, applyErr) || !strings.Contains(err.Error(), "canceled during the apply step") {
+		t.Errorf("runStep() error = %v, want the error of the canceled step", err)
+	}
+	var timeoutErr *StepTimeoutError
+	if errors.As(err, &timeoutErr) {
+		t.Errorf("expected a cancellation, not a timeout: %v", err)
+	}
+
+	ran := false
+	err = g.runStep(ctx, stepBuild, 0, "", func(context.Context) error {
+		ran = true
+		return nil
+	})
+	if ran || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected no step to start after the cancellation, ran = %v, error = %v", ran, err)
+	}
+}
+
+func TestSubmitJob_Canceled(t *testing.T) {
+	mock := NewMockExecutor(map[string][]shell.CommandResult{})
+	g := newTestGKEOrchestrator(mock)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := g.SubmitJob(ctx, orchestrator.JobDefinition{WorkloadName: "w", ImageName: "busybox", ClusterName: "c", ClusterLocation: "us-central1"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SubmitJob() error = %v, want a cancellation", err)
+	}
+	if len(mock.callCount) != 0 {
+		t.Errorf("expected no command to run, calls: %v", mock.callCount)
+	}
+}
+
+func TestStepTimeoutError_WithoutImage(t *testing.T) {
+	err := &StepTimeoutError{Step: stepCredentials, Timeout: 2 * time.Minute}
+	if got, want := err.Error(), "the credentials step did not finish within 2m0s; raise --credentials-timeout to allow it more time"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestDefaultExecutor_KillsCommandAtDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	e := executorWithContext(&impersonatingExecutor{Executor: &DefaultExecutor{}, principal: "sa@p.iam.gserviceaccount.com"}, ctx)
+	inner, ok := e.(*impersonatingExecutor).Executor.(*DefaultExecutor)
+	if !ok || inner.ctx != ctx {
+		t.Fatalf("expected the impersonated executor to run with the step context, got %#v", e)
+	}
+
+	start := time.Now()
+	if res := inner.ExecuteCommand("sleep", "10"); res.ExitCode == 0 {
+		t.Errorf("expected the command to be killed, got %+v", res)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command ran for %s after the deadline", elapsed)
+	}
+}
+
+func TestSubmitJob_BuildTimeoutCancelsBuild(t *testing.T) {
+	g := newTestGKEOrchestrator(&mockExecutor{executeCommandFunc: func(name string, args ...string) shell.CommandResult {
+		cmd := name + " " + strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(cmd, "gcloud container clusters describe c"):
+			return shell.CommandResult{Stdout: `{"locations": ["us-central1-a"], "nodePools": [{"name": "default-pool", "config": {"machineType": "n2-standard-2"}}], "autoscaling": {}}`}
+		case strings.HasPrefix(cmd, "gcloud container clusters get-credentials c"):
+			return shell.CommandResult{}
+		case strings.HasPrefix(cmd, "gcloud compute machine-types describe n2-standard-2"):
+			return shell.CommandResult{Stdout: `{"guestCpus": 2}`}
+		}
+		return shell.CommandResult{ExitCode: 1, Stderr: "unexpected command"}
+	}})
+	g.dynClient = newFakeDynamicClient(fakeObject("Namespace", "", "default", `{}`))
+
+	defer func(orig func(string, ...string) shell.CommandResult) { shell.ExecuteCommand = orig }(shell.ExecuteCommand)
+	shell.ExecuteCommand = func(name string, args ...string) shell.CommandResult {
+		// The Artifact Registry repository exists.
+		return shell.CommandResult{Stdout: "DOCKER"}
+	}
+	canceled := make(chan error, 1)
+	defer func(orig func(context.Context, imagebuilder.ImageRepo, string, string, string, imagebuilder.ImageTag) (string, error)) {
+		buildImageWithDocker = orig
+	}(buildImageWithDocker)
+	buildImageWithDocker = func(ctx context.Context, repo imagebuilder.ImageRepo, buildContext, dockerfile, platform string, tag imagebuilder.ImageTag) (string, error) {
+		select {
+		case <-ctx.Done():
+			canceled <- ctx.Err()
+			return "", ctx.Err()
+		case <-time.After(5 * time.Second):
+			canceled <- nil
+			return "us-central1-docker.pkg.dev/my-project/r/img:abc", nil
+		}
+	}
+
+	_, err := g.SubmitJob(context.Background(), orchestrator.JobDefinition{
+		WorkloadName:     "w",
+		WorkloadKind:     orchestrator.WorkloadKindJob,
+		NoQueue:          true,
+		ClusterProjectID: "p",
+		ClusterName:      "c",
+		ClusterLocation:  "us-central1",
+		ComputeType:      "n2-standard-2",
+		Dockerfile:       "Dockerfile",
+		BuildContext:     t.TempDir(),
+		BuildBackend:     orchestrator.BuildBackendDocker,
+		ImageRepo:        "us-central1-docker.pkg.dev/my-project/r",
+		StepTimeouts:     orchestrator.StepTimeouts{Build: 10 * time.Millisecond},
+	})
+
+	var timeoutErr *StepTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Step != stepBuild {
+		t.Fatalf("SubmitJob() error = %v, want a build *StepTimeoutError", err)
+	}
+	if buildErr := <-canceled; !errors.Is(buildErr, context.DeadlineExceeded) {
+		t.Errorf("expected the build context to be canceled at the deadline, got %v", buildErr)
+	}
+	if timeoutErr.Image != "" {
+		t.Errorf("expected no image for a canceled build, got %q", timeoutErr.Image)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -30,7 +31,10 @@ import (
 	"cloud.google.com/go/filestore/apiv1/filestorepb"
 	"google.golang.org/api/iterator"
 
-	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // filestoreTmpl is the pre-parsed template for Filestore configuration.
@@ -101,6 +105,96 @@ func (sm *StorageManager) ValidateMounts(mounts []string) error {
 	return nil
 }
 
+// CheckGCSFuseDriver fails Cloud Storage mounts on a cluster without the GCS
+// FUSE CSI driver, where their pods would stay pending on a mount failure. It
+// needs the cluster description, so it runs after the cluster is described.
+func (sm *StorageManager) CheckGCSFuseDriver(job orchestrator.JobDefinition) error {
+	if !slices.ContainsFunc(job.RawMounts, func(m string) bool { return strings.HasPrefix(m, "gs://") }) {
+		return nil
+	}
+	desc := sm.orchestrator.clusterDesc
+	if desc.Autopilot.Enabled || desc.AddonsConfig.GcsFuseCsiDriverConfig.Enabled {
+		return nil
+	}
+	return fmt.Errorf("the GCS FUSE CSI driver, which Cloud Storage mounts require, is not enabled on cluster %s. Enable it with:\n  gcloud container clusters update %s --location %s --project %s --update-addons GcsFuseCsiDriver=ENABLED",
+		job.ClusterName, job.ClusterName, job.ClusterLocation, job.ClusterProjectID)
+}
+
+// Types of the mounts that project a Kubernetes object into the containers.
+const (
+	mountTypeSecret    = "secret"
+	mountTypeConfigMap = "configMap"
+)
+
+// ProcessObjectMounts parses the --mount-secret and --mount-configmap values of
+// job. The objects must already exist in the target namespace and are always
+// mounted read-only.
+func (sm *StorageManager) ProcessObjectMounts(job orchestrator.JobDefinition) ([]MountInfo, error) {
+	var mountInfos []MountInfo
+	for _, m := range []struct {
+		volType string
+		prefix  string
+		mounts  []string
+	}{
+		{mountTypeSecret, "secret", job.SecretMounts},
+		{mountTypeConfigMap, "configmap", job.ConfigMapMounts},
+	} {
+		for i, vStr := range m.mounts {
+			name, dest, err := parseObjectMount(vStr)
+			if err != nil {
+				return nil, err
+			}
+			mountInfos = append(mountInfos, MountInfo{
+				Name:      fmt.Sprintf("%s-%d", m.prefix, i),
+				Source:    name,
+				MountPath: dest,
+				Type:      m.volType,
+				ReadOnly:  true,
+			})
+		}
+	}
+	return mountInfos, nil
+}
+
+// ValidateObjectMounts checks the --mount-secret and --mount-configmap values of
+// job, and that no two mounts of the job share a destination.
+func (sm *StorageManager) ValidateObjectMounts(job orchestrator.JobDefinition) error {
+	seenDestinations := make(map[string]bool)
+	for _, vStr := range job.RawMounts {
+		if _, dest, _, err := sm.parseSingleVolume(vStr); err == nil {
+			seenDestinations[dest] = true
+		}
+	}
+	for _, v := range job.SharedVolumes {
+		if seenDestinations[v.MountPath] {
+			return fmt.Errorf("duplicate volume destination: %s", v.MountPath)
+		}
+		seenDestinations[v.MountPath] = true
+	}
+	for _, vStr := range slices.Concat(job.SecretMounts, job.ConfigMapMounts) {
+		_, dest, err := parseObjectMount(vStr)
+		if err != nil {
+			return err
+		}
+		if seenDestinations[dest] {
+			return fmt.Errorf("duplicate volume destination: %s", dest)
+		}
+		seenDestinations[dest] = true
+	}
+	return nil
+}
+
+func parseObjectMount(vStr string) (name, dest string, err error) {
+	name, dest, ok := strings.Cut(vStr, ":")
+	if !ok || name == "" || !strings.HasPrefix(dest, "/") {
+		return "", "", fmt.Errorf("invalid object mount format: %s. Expected format: <name>:<dest>", vStr)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", "", fmt.Errorf("invalid object name %q in mount %s: %s", name, vStr, strings.Join(errs, "; "))
+	}
+	return name, dest, nil
+}
+
 func (sm *StorageManager) parseSingleVolume(vStr string) (src, dest string, readOnly bool, err error) {
 	src, dest, readOnly, err = parseSrcDest(vStr)
 	if err != nil {
@@ -216,7 +310,7 @@ func (sm *StorageManager) handleFilestoreMount(src, dest string, readOnly bool,
 	cleanHost := strings.TrimPrefix(strings.TrimRight(instanceOrIP, "]"), "[")
 	isIP := net.ParseIP(cleanHost) != nil
 
-	ip, resolvedName, capacityGb, err := sm.resolveFilestoreIP(job.ProjectID, job.ClusterLocation, cleanHost, isIP)
+	ip, resolvedName, capacityGb, err := sm.resolveFilestoreIP(job.ClusterProjectID, job.ClusterLocation, cleanHost, isIP)
 	if err != nil {
 		return MountInfo{}, "", err
 	}
@@ -224,6 +318,27 @@ func (sm *StorageManager) handleFilestoreMount(src, dest string, readOnly bool,
 
 	pvcName := fmt.Sprintf("gcluster-filestore-%s-%s", resolvedName, share)
 	pvcName = sanitizePVCName(pvcName)
+	pvcName, pvName := sm.claimNames(pvcName)
+
+	pvYAML, err := renderNFSVolume(pvName, pvcName, ip, share, capacityStr)
+	if err != nil {
+		return MountInfo{}, "", err
+	}
+
+	info := MountInfo{
+		Name:      fmt.Sprintf("vol-%d", idx),
+		Source:    pvcName,
+		MountPath: dest,
+		Type:      "pvc",
+		ReadOnly:  readOnly,
+	}
+
+	return info, pvYAML, nil
+}
+
+// claimNames returns pvcName, truncated, and the name of the PersistentVolume
+// bound to it in the current namespace.
+func (sm *StorageManager) claimNames(pvcName string) (string, string) {
 	// Truncate pvcName to avoid PV name collisions when the namespace is appended.
 	// A PV name is derived from <pvc-name>-<namespace>. A namespace can be up to 63
 	// characters. By limiting the PVC name to 189, we ensure the combined name does not
@@ -243,30 +358,24 @@ func (sm *StorageManager) handleFilestoreMount(src, dest string, readOnly bool,
 	if ns == "" {
 		ns = "default"
 	}
-	pvName := sanitizePVCName(fmt.Sprintf("%s-%s", pvcName, ns))
+	return pvcName, sanitizePVCName(fmt.Sprintf("%s-%s", pvcName, ns))
+}
 
+// renderNFSVolume renders a ReadWriteMany PersistentVolume for the NFS export
+// server:/share and the claim bound to it.
+func renderNFSVolume(pvName, pvcName, server, share, capacity string) (string, error) {
 	var buf bytes.Buffer
-	err = filestoreTmpl.Execute(&buf, map[string]string{
+	err := filestoreTmpl.Execute(&buf, map[string]string{
 		"PVName":   pvName,
 		"PVCName":  pvcName,
-		"Share":    share,
-		"IP":       ip,
-		"Capacity": capacityStr,
+		"Share":    strings.TrimPrefix(share, "/"),
+		"IP":       server,
+		"Capacity": capacity,
 	})
 	if err != nil {
-		return MountInfo{}, "", fmt.Errorf("failed to execute filestore template: %w", err)
+		return "", fmt.Errorf("failed to execute filestore template: %w", err)
 	}
-	pvYAML := buf.String()
-
-	info := MountInfo{
-		Name:      fmt.Sprintf("vol-%d", idx),
-		Source:    pvcName,
-		MountPath: dest,
-		Type:      "pvc",
-		ReadOnly:  readOnly,
-	}
-
-	return info, pvYAML, nil
+	return buf.String(), nil
 }
 
 func sanitizePVCName(name string) string {
@@ -287,68 +396,46 @@ func sanitizePVCName(name string) string {
 	return name
 }
 
-// AddVolumeOptions marshals and indents the volume and volume mount specifications into the manifest options.
+// AddVolumeOptions adds the volume and volume mount specifications to the manifest options.
 func (sm *StorageManager) AddVolumeOptions(opts *ManifestOptions, vols []MountInfo) {
-	if len(vols) == 0 {
-		return
-	}
-
-	var volSpecs []map[string]interface{}
-	var mountSpecs []map[string]interface{}
-	gcsFuseEnabled := false
-
 	for _, v := range vols {
-		mountSpecs = append(mountSpecs, buildVolumeMountSpec(v))
-		volSpecs = append(volSpecs, buildVolumeSpec(v))
+		opts.VolumeMounts = append(opts.VolumeMounts, buildVolumeMount(v))
+		opts.Volumes = append(opts.Volumes, buildVolume(v))
 		if v.Type == "gcsfuse" {
-			gcsFuseEnabled = true
+			opts.GCSFuseEnabled = true
 		}
 	}
-
-	opts.GCSFuseEnabled = gcsFuseEnabled
-
-	if b, err := yaml.Marshal(mountSpecs); err == nil {
-		opts.VolumeMountsYAML = indentYaml(string(b), 16)
-	}
-	if b, err := yaml.Marshal(volSpecs); err == nil {
-		opts.VolumesYAML = indentYaml(string(b), 14)
-	}
 }
 
-func buildVolumeMountSpec(v MountInfo) map[string]interface{} {
-	mountSpec := map[string]interface{}{
-		"name":      v.Name,
-		"mountPath": v.MountPath,
-	}
-	if v.ReadOnly {
-		mountSpec["readOnly"] = true
-	}
-	return mountSpec
+func buildVolumeMount(v MountInfo) corev1.VolumeMount {
+	return corev1.VolumeMount{Name: v.Name, MountPath: v.MountPath, ReadOnly: v.ReadOnly}
 }
 
-func buildVolumeSpec(v MountInfo) map[string]interface{} {
-	spec := map[string]interface{}{
-		"name": v.Name,
-	}
+func buildVolume(v MountInfo) corev1.Volume {
+	vol := corev1.Volume{Name: v.Name}
 	switch v.Type {
 	case "gcsfuse":
-		spec["csi"] = map[string]interface{}{
-			"driver":   "gcsfuse.csi.storage.gke.io",
-			"readOnly": v.ReadOnly,
-			"volumeAttributes": map[string]interface{}{
-				"bucketName": strings.TrimPrefix(v.Source, "gs://"),
-			},
+		vol.CSI = &corev1.CSIVolumeSource{
+			Driver:           "gcsfuse.csi.storage.gke.io",
+			ReadOnly:         ptr(v.ReadOnly),
+			VolumeAttributes: map[string]string{"bucketName": strings.TrimPrefix(v.Source, "gs://")},
 		}
 	case "hostPath":
-		spec["hostPath"] = map[string]interface{}{
-			"path": v.Source,
-		}
+		vol.HostPath = &corev1.HostPathVolumeSource{Path: v.Source}
 	case "pvc":
-		spec["persistentVolumeClaim"] = map[string]interface{}{
-			"claimName": v.Source,
+		vol.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: v.Source}
+	case mountTypeSecret:
+		vol.Secret = &corev1.SecretVolumeSource{SecretName: v.Source}
+	case mountTypeConfigMap:
+		vol.ConfigMap = &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: v.Source}}
+	case mountTypeSecretProvider:
+		vol.CSI = &corev1.CSIVolumeSource{
+			Driver:           secretManagerCSIDriver,
+			ReadOnly:         ptr(true),
+			VolumeAttributes: map[string]string{"secretProviderClass": v.Source},
 		}
 	}
-	return spec
+	return vol
 }
 
 func (sm *StorageManager) resolveFilestoreIP(projectID, location, nameOrIP string, isIP bool) (string, string, int64, error) {
@@ -533,3 +620,97 @@ func extractInstanceInfo(matches []*filestorepb.Instance, nameOrIP string, isIP
 	}
 	return ip, resolvedName, capacity, nil
 }
+
+// managedClaimPrefixes mark the PersistentVolumeClaims created by gcluster for
+// Filestore mounts and shared NFS volumes.
+var managedClaimPrefixes = []string{"gcluster-filestore-", sharedVolumeClaimPrefix}
+
+type jobSetClaims struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		ReplicatedJobs []struct {
+			Template struct {
+				Spec struct {
+					Template struct {
+						Spec struct {
+							Volumes []struct {
+								PersistentVolumeClaim *struct {
+									ClaimName string `json:"claimName"`
+								} `json:"persistentVolumeClaim"`
+							} `json:"volumes"`
+						} `json:"spec"`
+					} `json:"template"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"replicatedJobs"`
+	} `json:"spec"`
+}
+
+func (js jobSetClaims) managedClaims() []string {
+	var claims []string
+	for _, rj := range js.Spec.ReplicatedJobs {
+		for _, v := range rj.Template.Spec.Template.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil && isManagedClaim(v.PersistentVolumeClaim.ClaimName) && !slices.Contains(claims, v.PersistentVolumeClaim.ClaimName) {
+				claims = append(claims, v.PersistentVolumeClaim.ClaimName)
+			}
+		}
+	}
+	return claims
+}
+
+func isManagedClaim(name string) bool {
+	return slices.ContainsFunc(managedClaimPrefixes, func(p string) bool { return strings.HasPrefix(name, p) })
+}
+
+// WorkloadClaims returns the gcluster-managed volume claims mounted by a workload.
+func (sm *StorageManager) WorkloadClaims(ctx context.Context, ns, workloadName string) ([]string, error) {
+	var js jobSetClaims
+	if err := sm.orchestrator.getResource(ctx, &js, jobSetGVR, ns, workloadName); err != nil {
+		return nil, fmt.Errorf("failed to get jobset %s: %w", workloadName, err)
+	}
+	return js.managedClaims(), nil
+}
+
+// DeleteUnusedClaims deletes the given claims, and the volumes bound to them,
+// unless another JobSet in the namespace still mounts them. The backing
+// Filestore instances are not touched.
+func (sm *StorageManager) DeleteUnusedClaims(ctx context.Context, ns string, claims []string) error {
+	if len(claims) == 0 {
+		return nil
+	}
+	var list struct {
+		Items []jobSetClaims `json:"items"`
+	}
+	if err := sm.orchestrator.listResources(ctx, &list, jobSetGVR, ns, metav1.ListOptions{}); err != nil {
+		return fmt.Errorf("failed to list jobsets in namespace %s: %w", ns, err)
+	}
+	inUse := map[string]string{}
+	for _, js := range list.Items {
+		for _, c := range js.managedClaims() {
+			inUse[c] = js.Metadata.Name
+		}
+	}
+
+	for _, claim := range claims {
+		if user, ok := inUse[claim]; ok {
+			logging.Info("Keeping volume claim '%s', it is still mounted by '%s'.", claim, user)
+			continue
+		}
+		var pvc corev1.PersistentVolumeClaim
+		if err := sm.orchestrator.getResource(ctx, &pvc, pvcGVR, ns, claim); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get volume claim %s: %w", claim, err)
+		}
+		if err := sm.orchestrator.deleteResource(ctx, pvcGVR, ns, claim); err != nil {
+			return fmt.Errorf("failed to delete volume claim %s: %w", claim, err)
+		}
+		if pv := pvc.Spec.VolumeName; pv != "" {
+			if err := sm.orchestrator.deleteResource(ctx, pvGVR, "", pv); err != nil {
+				return fmt.Errorf("failed to delete volume %s: %w", pv, err)
+			}
+		}
+		logging.Info("Deleted volume claim '%s'.", claim)
+	}
+	return nil
+}
@@ -16,7 +16,9 @@ package gke
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -225,6 +227,137 @@ func TestValidateMounts(t *testing.T) {
 	}
 }
 
+func TestCheckGCSFuseDriver(t *testing.T) {
+	gcsJob := orchestrator.JobDefinition{
+		ClusterName:      "my-cluster",
+		ClusterLocation:  "us-central1",
+		ClusterProjectID: "my-project",
+		RawMounts:        []string{"my-pvc:/pvc", "gs://datasets:/mnt/gcs"},
+	}
+	tests := []struct {
+		name    string
+		job     orchestrator.JobDefinition
+		desc    string
+		wantErr bool
+	}{
+		{name: "driver enabled", job: gcsJob, desc: `{"addonsConfig": {"gcsFuseCsiDriverConfig": {"enabled": true}}}`},
+		{name: "autopilot", job: gcsJob, desc: `{"autopilot": {"enabled": true}}`},
+		{name: "driver disabled", job: gcsJob, desc: `{}`, wantErr: true},
+		{name: "no bucket mounts", job: orchestrator.JobDefinition{RawMounts: []string{"my-pvc:/pvc"}}, desc: `{}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			if err := json.Unmarshal([]byte(tc.desc), &orc.clusterDesc); err != nil {
+				t.Fatal(err)
+			}
+			sm := &StorageManager{orchestrator: orc}
+			err := sm.CheckGCSFuseDriver(tc.job)
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "gcloud container clusters update my-cluster --location us-central1 --project my-project --update-addons GcsFuseCsiDriver=ENABLED") {
+				t.Fatalf("expected an error with the command enabling the driver, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateObjectMounts(t *testing.T) {
+	sm := &StorageManager{}
+	tests := []struct {
+		name    string
+		job     orchestrator.JobDefinition
+		wantErr string
+	}{
+		{
+			name: "valid",
+			job: orchestrator.JobDefinition{
+				RawMounts:       []string{"gs://my-bucket:/data"},
+				SecretMounts:    []string{"api-token:/etc/token"},
+				ConfigMapMounts: []string{"train-config:/etc/config"},
+			},
+		},
+		{
+			name:    "missing destination",
+			job:     orchestrator.JobDefinition{SecretMounts: []string{"api-token"}},
+			wantErr: "Expected format: <name>:<dest>",
+		},
+		{
+			name:    "relative destination",
+			job:     orchestrator.JobDefinition{ConfigMapMounts: []string{"train-config:etc/config"}},
+			wantErr: "Expected format: <name>:<dest>",
+		},
+		{
+			name:    "invalid name",
+			job:     orchestrator.JobDefinition{SecretMounts: []string{"API_Token:/etc/token"}},
+			wantErr: `invalid object name "API_Token"`,
+		},
+		{
+			name: "destination shared with a volume",
+			job: orchestrator.JobDefinition{
+				RawMounts:    []string{"gs://my-bucket:/data"},
+				SecretMounts: []string{"api-token:/data"},
+			},
+			wantErr: "duplicate volume destination: /data",
+		},
+		{
+			name: "destination shared between objects",
+			job: orchestrator.JobDefinition{
+				SecretMounts:    []string{"api-token:/etc/app"},
+				ConfigMapMounts: []string{"train-config:/etc/app"},
+			},
+			wantErr: "duplicate volume destination: /etc/app",
+		},
+		{
+			name: "destination shared with a shared volume",
+			job: orchestrator.JobDefinition{
+				SharedVolumes:   []orchestrator.SharedVolume{{Server: "10.0.0.2", Path: "/share1", MountPath: "/etc/config"}},
+				ConfigMapMounts: []string{"train-config:/etc/config"},
+			},
+			wantErr: "duplicate volume destination: /etc/config",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := sm.ValidateObjectMounts(tc.job)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestProcessObjectMounts(t *testing.T) {
+	sm := &StorageManager{}
+	job := orchestrator.JobDefinition{
+		SecretMounts:    []string{"api-token:/etc/token", "tls:/etc/tls"},
+		ConfigMapMounts: []string{"train-config:/etc/config"},
+	}
+
+	infos, err := sm.ProcessObjectMounts(job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []MountInfo{
+		{Name: "secret-0", Source: "api-token", MountPath: "/etc/token", Type: "secret", ReadOnly: true},
+		{Name: "secret-1", Source: "tls", MountPath: "/etc/tls", Type: "secret", ReadOnly: true},
+		{Name: "configmap-0", Source: "train-config", MountPath: "/etc/config", Type: "configMap", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Errorf("ProcessObjectMounts() = %+v, want %+v", infos, want)
+	}
+}
+
 func TestProcessMounts_Basic(t *testing.T) {
 	sm := &StorageManager{}
 	job := orchestrator.JobDefinition{}
@@ -367,8 +500,8 @@ func TestProcessMounts_Filestore_Mock(t *testing.T) {
 		},
 	}
 	jobMock := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "us-central1-a",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-central1-a",
 	}
 	mountsMock := []string{
 		"filestore://my-filestore-instance/my_share:/data",
@@ -514,37 +647,25 @@ func TestAddVolumeOptions(t *testing.T) {
 
 	sm.AddVolumeOptions(opts, vols)
 
-	// Verify volumeMounts YAML contains readOnly for read-only mounts
-	actualVolumeMounts := opts.VolumeMountsYAML
-	if !strings.Contains(actualVolumeMounts, "readOnly: true") {
-		t.Errorf("expected readOnly: true in volume mounts, got:\n%s", actualVolumeMounts)
+	// Verify only the read-only mounts are marked readOnly
+	if len(opts.VolumeMounts) != len(vols) {
+		t.Fatalf("expected %d volume mounts, got %v", len(vols), opts.VolumeMounts)
 	}
-
-	// Verify vol-1 (readOnly = false) does NOT contain readOnly: true
-	lines := strings.Split(actualVolumeMounts, "\n")
-	var vol1LineIdx = -1
-	for idx, line := range lines {
-		if strings.Contains(line, "name: vol-1") {
-			vol1LineIdx = idx
-			break
-		}
-	}
-	if vol1LineIdx == -1 {
-		t.Fatalf("could not find vol-1 in volume mounts, got:\n%s", actualVolumeMounts)
-	}
-	// Verify next few lines don't contain readOnly: true
-	for i := vol1LineIdx + 1; i < len(lines) && i < vol1LineIdx+3; i++ {
-		if strings.Contains(lines[i], "readOnly:") {
-			t.Errorf("expected vol-1 to not have readOnly field, but found: %s", lines[i])
+	for i, m := range opts.VolumeMounts {
+		if m.Name != vols[i].Name || m.ReadOnly != vols[i].ReadOnly {
+			t.Errorf("expected mount %s with readOnly %v, got %+v", vols[i].Name, vols[i].ReadOnly, m)
 		}
 	}
 
-	// Verify volumes YAML
-	if !strings.Contains(opts.VolumesYAML, "persistentVolumeClaim") {
-		t.Errorf("expected persistentVolumeClaim in volumes, got:\n%s", opts.VolumesYAML)
+	// Verify volumes
+	if len(opts.Volumes) != len(vols) {
+		t.Fatalf("expected %d volumes, got %v", len(vols), opts.Volumes)
+	}
+	if csi := opts.Volumes[0].CSI; csi == nil || csi.Driver != "gcsfuse.csi.storage.gke.io" {
+		t.Errorf("expected gcsfuse CSI driver in volumes, got %+v", opts.Volumes[0])
 	}
-	if !strings.Contains(opts.VolumesYAML, "gcsfuse.csi.storage.gke.io") {
-		t.Errorf("expected gcsfuse CSI driver in volumes, got:\n%s", opts.VolumesYAML)
+	if pvc := opts.Volumes[2].PersistentVolumeClaim; pvc == nil || pvc.ClaimName != "my-pvc" {
+		t.Errorf("expected persistentVolumeClaim my-pvc in volumes, got %+v", opts.Volumes[2])
 	}
 }
 
@@ -606,8 +727,8 @@ func TestProcessMounts_Filestore_Ambiguous(t *testing.T) {
 
 	// Case 1: Cluster location is us-central1-a. Should resolve to inst1.
 	job1 := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "us-central1-a",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-central1-a",
 	}
 	mounts1 := []string{
 		"filestore://my-filestore/share:/data",
@@ -627,8 +748,8 @@ func TestProcessMounts_Filestore_Ambiguous(t *testing.T) {
 
 	// Case 2: Cluster location is us-east1-b. Should resolve to inst2.
 	job2 := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "us-east1-b",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-east1-b",
 	}
 	infos2, manifests2, err := sm.ProcessMounts(mounts1, job2) // reuse mounts1 as it only contains name
 	if err != nil {
@@ -641,8 +762,8 @@ func TestProcessMounts_Filestore_Ambiguous(t *testing.T) {
 
 	// Case 3: Cluster location is us-west1-a (no match). Should fail due to ambiguity.
 	job3 := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "us-west1-a",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-west1-a",
 	}
 	_, _, err = sm.ProcessMounts(mounts1, job3)
 	if err == nil {
@@ -653,8 +774,8 @@ func TestProcessMounts_Filestore_Ambiguous(t *testing.T) {
 
 	// Case 4: Cluster location is empty. Should fail due to ambiguity.
 	job4 := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "",
 	}
 	_, _, err = sm.ProcessMounts(mounts1, job4)
 	if err == nil {
@@ -682,7 +803,7 @@ func TestProcessMounts_Filestore_Fallback(t *testing.T) {
 		},
 	}
 	job := orchestrator.JobDefinition{
-		ProjectID: "my-project",
+		ClusterProjectID: "my-project",
 	}
 	mountsIP := []string{
 		"filestore://10.0.0.3/share:/data",
@@ -770,8 +891,8 @@ func TestProcessMounts_Filestore_LocationOverlap(t *testing.T) {
 	}
 	// Cluster in europe-west10
 	job := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "europe-west10",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "europe-west10",
 	}
 	mounts := []string{
 		"filestore://my-filestore/share:/data",
@@ -814,8 +935,8 @@ func TestProcessMounts_Filestore_PVNameLengthLimit(t *testing.T) {
 		},
 	}
 	job := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "us-central1",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-central1",
 	}
 	mounts := []string{
 		"filestore://a-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-long-name/a-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-very-long-share:/data",
@@ -879,8 +1000,8 @@ func TestProcessMounts_Filestore_Caching(t *testing.T) {
 		},
 	}
 	job := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "us-central1",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-central1",
 	}
 	mounts := []string{
 		"filestore://inst1/share1:/data1",
@@ -923,8 +1044,8 @@ func TestProcessMounts_Filestore_IPv6(t *testing.T) {
 		},
 	}
 	job := orchestrator.JobDefinition{
-		ProjectID:       "my-project",
-		ClusterLocation: "us-central1",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-central1",
 	}
 	mounts := []string{
 		"filestore://[2001:db8::1]/share:/data",
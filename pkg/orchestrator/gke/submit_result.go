@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"hpc-toolkit/pkg/imagebuilder"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// resolveImageDigest is replaced in tests to avoid reaching a registry.
+var resolveImageDigest = imagebuilder.ImageDigest
+
+// submitResult describes the workload SubmitJob applied. The namespace and
+// image digest are looked up on a best-effort basis, and like the console
+// links not for dry runs.
+func (g *GKEOrchestrator) submitResult(job orchestrator.JobDefinition, image string) orchestrator.SubmitResult {
+	result := orchestrator.SubmitResult{
+		WorkloadName: job.WorkloadName,
+		ClusterName:  job.ClusterName,
+		Location:     job.ClusterLocation,
+		ProjectID:    job.ClusterProjectID,
+		RunID:        job.RunID,
+		ComputeType:  job.ComputeType,
+		Image:        image,
+		Manifest:     job.DryRunManifest,
+	}
+	if job.IsDryRun() {
+		return result
+	}
+	result.WorkloadURL, result.LogsURL = consoleLinks(job)
+
+	if ns, err := g.currentNamespace(); err != nil {
+		logging.Warn("Failed to get the namespace of %s: %v", job.WorkloadName, err)
+	} else {
+		result.Namespace = ns
+	}
+	if image != "" {
+		if digest, err := resolveImageDigest(image); err != nil {
+			logging.Warn("Failed to resolve the digest of image %s: %v", image, err)
+		} else {
+			result.ImageDigest = digest
+		}
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"errors"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestSubmitResult(t *testing.T) {
+	origResolve := resolveImageDigest
+	defer func() { resolveImageDigest = origResolve }()
+
+	job := orchestrator.JobDefinition{
+		WorkloadName:     "my-job",
+		ClusterName:      "my-cluster",
+		ClusterLocation:  "us-central1",
+		ClusterProjectID: "my-project",
+		RunID:            "0123456789ab",
+	}
+	base := orchestrator.SubmitResult{
+		WorkloadName: "my-job",
+		ClusterName:  "my-cluster",
+		Location:     "us-central1",
+		ProjectID:    "my-project",
+		RunID:        "0123456789ab",
+		Image:        "us-docker.pkg.dev/p/r/img:v1",
+	}
+	workloadURL := "https://console.cloud.google.com/kubernetes/job/us-central1/my-cluster/default/my-job-main-job-0/details?project=my-project"
+	links := func(r *orchestrator.SubmitResult) {
+		r.WorkloadURL, r.LogsURL = workloadURL, getCloudConsoleLogsURL("my-project", "us-central1", "my-cluster", "default", "my-job-main-job-0")
+	}
+
+	tests := []struct {
+		name      string
+		dryRun    bool
+		digestErr error
+		want      func(r *orchestrator.SubmitResult)
+	}{
+		{name: "submitted", want: func(r *orchestrator.SubmitResult) { r.Namespace = "team-a"; r.ImageDigest = "sha256:feed"; links(r) }},
+		{name: "digest unavailable", digestErr: errors.New("unauthorized"), want: func(r *orchestrator.SubmitResult) { r.Namespace = "team-a"; links(r) }},
+		{name: "dry run", dryRun: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolveImageDigest = func(image string) (string, error) {
+				if tc.dryRun {
+					t.Error("expected a dry run not to resolve the image digest")
+				}
+				return "sha256:feed", tc.digestErr
+			}
+			orc := newTestGKEOrchestrator(NewMockExecutor(nil))
+			orc.kubeClient = &MockKubeClient{Namespace: "team-a"}
+
+			j := job
+			j.DryRun = tc.dryRun
+			want := base
+			if tc.want != nil {
+				tc.want(&want)
+			}
+			if got := orc.submitResult(j, base.Image); got != want {
+				t.Errorf("submitResult() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
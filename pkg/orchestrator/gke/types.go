@@ -21,11 +21,17 @@ import (
 	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/orchestrator"
 	"hpc-toolkit/pkg/shell"
+	"io"
+	"net/http"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/filestore/apiv1/filestorepb"
 	compute "google.golang.org/api/compute/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 )
 
 const (
@@ -41,12 +47,29 @@ type Executor interface {
 }
 
 // KubeClient defines the interface for specific Kubernetes API operations needed by the orchestrator.
+// Resources are otherwise read and written through the dynamic client; see
+// getResource.
 type KubeClient interface {
-	GetJobNamespace(workloadName string) (string, error)
-	ListWorkloads(namespace string, workloadName string) ([]string, error)
-	DeleteJobSet(namespace string, name string) error
-	ListJobSets(labelSelector string) ([]orchestrator.JobStatus, error)
+	GetJobNamespace(ctx context.Context, workloadName string) (string, error)
+	ListWorkloads(ctx context.Context, namespace string, workloadName string) ([]string, error)
+	DeleteJobSet(ctx context.Context, namespace string, name string) error
+	DeletePods(ctx context.Context, namespace string, labelSelector string, gracePeriodSeconds int64) error
+	DeleteServices(ctx context.Context, namespace string, labelSelector string) error
+	ListJobSets(ctx context.Context, labelSelector string) ([]orchestrator.JobStatus, error)
 	GetCurrentNamespace() (string, error)
+	ApplyManifests(ctx context.Context, manifests []byte) error
+	CRDInstalled(ctx context.Context, name string) (bool, error)
+	// PodLogs opens the log stream of a container of a pod.
+	PodLogs(ctx context.Context, namespace, pod string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+	// PortForward forwards local ports to a pod until ctx is done. ports
+	// are "<local>:<remote>" pairs.
+	PortForward(ctx context.Context, namespace, pod string, ports []string) error
+	// RawGet returns the body of a GET of an API server path, such as a
+	// node proxy path.
+	RawGet(ctx context.Context, path string) ([]byte, error)
+	// CanI reports whether the current user may perform verb on resource
+	// in the default namespace.
+	CanI(ctx context.Context, verb, resource string) (bool, error)
 }
 
 type MachineTypeClient interface {
@@ -62,12 +85,38 @@ func (c DefaultMachineTypeClient) GetMachineType(project, zone, machineType stri
 // DefaultKubeClient implements KubeClient using the actual dynamic client.
 type DefaultKubeClient struct {
 	dynClient dynamic.Interface
+	// restClient serves the subresources the dynamic client cannot, such as
+	// pod logs; config is what it was built from.
+	restClient rest.Interface
+	config     *rest.Config
+	resources  *apiResourceCache
+	// dryRun sends writes as server-side dry runs, which are validated by
+	// the API server but not persisted.
+	dryRun bool
 }
 
-type DefaultExecutor struct{}
+// DefaultExecutor runs commands on the local machine. When ctx is set, its
+// commands are killed once ctx is done.
+type DefaultExecutor struct {
+	ctx context.Context
+}
+
+// Clock tells the time and waits between polls. Tests set a fake clock so
+// timeouts and retries run instantly; see SetClock.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
 
 type GKEOrchestrator struct {
 	executor                    Executor
+	httpClient                  *http.Client
+	clock                       Clock
 	projectID                   string
 	clusterZones                []string
 	nodePoolSAs                 []string
@@ -75,6 +124,7 @@ type GKEOrchestrator struct {
 	clusterDesc                 gkeCluster
 	dynClient                   dynamic.Interface
 	kubeClient                  KubeClient
+	kubeClientFromConfig        bool
 	machineTypeClient           MachineTypeClient
 	acceleratorToMachineType    map[string]string
 	machineCapCache             map[string]MachineTypeCap
@@ -87,6 +137,12 @@ type GKEOrchestrator struct {
 	topologyCache               map[string]string
 	slicingTopologiesChecked    bool
 	slicingTopologiesDetected   bool
+	// impersonate is the principal the orchestrator acts as in read-only
+	// mode; see Impersonate.
+	impersonate string
+	// secretEnvValues are the --secret-env values read from Secret Manager
+	// by resolveSecretEnv, by variable name.
+	secretEnvValues map[string]string
 }
 
 // Types for GetClusterInfo unmarshaling
@@ -137,6 +193,13 @@ type gkePVCList struct {
 	Items []gkePVC `json:"items"`
 }
 
+// MPIOptions are the settings of the launcher and workers of an MPI workload.
+type MPIOptions struct {
+	SSHSecret       string // Secret holding the SSH keys of the launcher and workers.
+	HostfileScript  string // Script of the launcher init container writing the hostfile.
+	LauncherCommand string // mpirun running the command on the workers.
+}
+
 type JobProfile struct {
 	IsCPUMachine  bool
 	CapacityCount int
@@ -144,11 +207,27 @@ type JobProfile struct {
 
 type ManifestOptions struct {
 	WorkloadName                  string
+	WorkloadKind                  string
 	FullImageName                 string
+	BaseImage                     string
+	BaseImageDigest               string
+	CapturedEnv                   string
+	CapturedEnvDigest             string
+	SigningKey                    string
+	ManagedProfile                string
+	Inputs                        string // JSON of the verified inputs.
+	Subdomain                     string // Headless Service giving the pods DNS hostnames.
+	MPI                           MPIOptions
 	CommandToRun                  string
+	CommandArgs                   []string
 	ComputeType                   string
 	MachineType                   string
-	ResourcesString               string
+	Resources                     corev1.ResourceRequirements
+	CPU                           string
+	Memory                        string
+	GPUsPerPod                    int
+	CPURequest                    string
+	MemoryRequest                 string
 	ProjectID                     string
 	ClusterName                   string
 	ClusterLocation               string
@@ -157,31 +236,56 @@ type ManifestOptions struct {
 	NodesPerSlice                 int
 	ParallelContainers            int
 	MaxRestarts                   int
+	RestartPolicy                 string
+	PodBackoffLimit               int
 	TtlSecondsAfterFinished       int
 	TerminationGracePeriodSeconds int
-	NodeSelector                  string
-	Affinity                      string
-	PodFailurePolicy              string
-	ImagePullSecrets              string
+	NodeSelector                  map[string]string
+	Affinity                      *corev1.Affinity
+	PodFailurePolicy              *batchv1.PodFailurePolicy
+	ImagePullSecrets              []corev1.LocalObjectReference
 	ServiceAccountName            string
-	TopologyAnnotation            string
+	TopologyAnnotations           map[string]string
 	Topology                      string
 	PathwaysInstanceType          string
 	SchedulerName                 string
-	SchedulingGates               string
-	Tolerations                   string
+	SchedulingGates               []corev1.PodSchedulingGate
+	Tolerations                   []corev1.Toleration
 	AwaitJobCompletion            bool
 	PriorityClassName             string
-	VolumesYAML                   string
-	VolumeMountsYAML              string
+	Volumes                       []corev1.Volume
+	VolumeMounts                  []corev1.VolumeMount
 	GCSFuseEnabled                bool
+	LogArchiver                   *corev1.Container // Native sidecar archiving the pod logs.
+	Sidecars                      []orchestrator.ContainerSpec
+	StartupProbe                  *corev1.Probe
+	ReadinessProbe                *corev1.Probe
 	IsDynamicSlicing              bool
 	IsStaticSlicing               bool
 	IsCPUMachine                  bool
 	Pathways                      orchestrator.PathwaysJobDefinition
 	Verbose                       bool
 	Env                           map[string]string
+	FieldEnv                      map[string]string // Variables set from pod fields through the downward API.
+	SecretEnv                     []EnvVar          // Variables set from the keys of a Secret, in order.
+	CommandPrelude                string            // Shell run before the command, e.g. to derive ranks.
+	CostLabels                    map[string]string
+	ResourceLabels                map[string]string
+	SharedVolumes                 []VolumeSpec
 	AdditionalManifests           []string
+	StageInManifest               string
+	Retention                     []orchestrator.RetentionRule // Resolved onto the workload's mounts.
+}
+
+// VolumeSpec is a shared volume of a workload: a PersistentVolume for an NFS
+// export and the claim every pod mounts it through.
+type VolumeSpec struct {
+	Name      string // Name of the volume in the pod spec.
+	PVName    string
+	PVCName   string
+	Server    string
+	Path      string
+	MountPath string
 }
 
 // StorageManager handles parsing and validation of storage mounts.
@@ -271,14 +375,33 @@ type gkeResourceLimit struct {
 }
 
 type gkeClusterAutoscaling struct {
-	EnableNodeAutoprovisioning bool               `json:"enableNodeAutoprovisioning"`
-	ResourceLimits             []gkeResourceLimit `json:"resourceLimits"`
+	EnableNodeAutoprovisioning       bool               `json:"enableNodeAutoprovisioning"`
+	ResourceLimits                   []gkeResourceLimit `json:"resourceLimits"`
+	AutoprovisioningNodePoolDefaults struct {
+		ServiceAccount string `json:"serviceAccount"`
+	} `json:"autoprovisioningNodePoolDefaults"`
 }
 
 type gkeCluster struct {
-	Locations   []string              `json:"locations"`
-	NodePools   []gkeJobNodePool      `json:"nodePools"`
-	Autoscaling gkeClusterAutoscaling `json:"autoscaling"`
+	Locations    []string              `json:"locations"`
+	NodePools    []gkeJobNodePool      `json:"nodePools"`
+	Autoscaling  gkeClusterAutoscaling `json:"autoscaling"`
+	AddonsConfig gkeAddonsConfig       `json:"addonsConfig"`
+	Autopilot    struct {
+		Enabled bool `json:"enabled"`
+	} `json:"autopilot"`
+	SecretManagerConfig struct {
+		Enabled bool `json:"enabled"`
+	} `json:"secretManagerConfig"`
+}
+
+type gkeAddonsConfig struct {
+	GcsFuseCsiDriverConfig struct {
+		Enabled bool `json:"enabled"`
+	} `json:"gcsFuseCsiDriverConfig"`
+	GcpFilestoreCsiDriverConfig struct {
+		Enabled bool `json:"enabled"`
+	} `json:"gcpFilestoreCsiDriverConfig"`
 }
 
 // Types for JobSet status unmarshaling
@@ -296,8 +419,8 @@ type JobSetStatus struct {
 }
 
 type ContainerData struct {
-	Name          string
-	ResourcesYAML string
+	Name      string
+	Resources corev1.ResourceRequirements
 }
 
 // EnvVar represents a custom environment variable key-value pair.
@@ -306,6 +429,13 @@ type EnvVar struct {
 	Name string
 	// Value is the environment variable value.
 	Value string
+	// FieldPath, when set, takes the value from a field of the pod through
+	// the downward API instead.
+	FieldPath string
+	// SecretName and SecretKey, when set, take the value from a key of a
+	// Secret instead.
+	SecretName string
+	SecretKey  string
 }
 
 type jobSetTemplateData struct {
@@ -317,37 +447,54 @@ type jobSetTemplateData struct {
 	TtlSecondsAfterFinished       int
 	TerminationGracePeriodSeconds int
 	MaxRestarts                   int
+	RestartPolicy                 string
+	PodBackoffLimit               int
 	NumSlices                     int
 	NodesPerSlice                 int
 	WorkerBackoffLimit            int
 	PathwaysInstanceType          string
 	CommandToRun                  string
-	ResourcesString               string
+	Resources                     corev1.ResourceRequirements
 	ProxyArgsList                 []string
 	ServerArgsList                []string
 	WorkerArgsList                []string
 	FullImageName                 string
+	BaseImage                     string
+	BaseImageDigest               string
+	CapturedEnv                   string
+	CapturedEnvDigest             string
+	ManagedProfile                string
+	Inputs                        string // JSON of the verified inputs.
+	Subdomain                     string
+	MPI                           MPIOptions
 	Command                       []string
-	ResourcesYAML                 string
+	Args                          []string
+	Entrypoint                    string
 	AcceleratorTypeLabel          string
-	NodeSelector                  string
-	Affinity                      string
-	PodFailurePolicy              string
-	ImagePullSecrets              string
+	NodeSelector                  map[string]string
+	Affinity                      *corev1.Affinity
+	PodFailurePolicy              *batchv1.PodFailurePolicy
+	ImagePullSecrets              []corev1.LocalObjectReference
 	ServiceAccountName            string
-	TopologyAnnotation            string
+	TopologyAnnotations           map[string]string
 	SchedulerName                 string
-	SchedulingGates               string
-	Tolerations                   string
+	SchedulingGates               []corev1.PodSchedulingGate
+	Tolerations                   []corev1.Toleration
 	PriorityClassName             string
-	VolumesYAML                   string
-	VolumeMountsYAML              string
+	Volumes                       []corev1.Volume
+	VolumeMounts                  []corev1.VolumeMount
 	GCSFuseEnabled                bool
+	LogArchiver                   *corev1.Container
+	Sidecars                      []orchestrator.ContainerSpec
+	StartupProbe                  *corev1.Probe
+	ReadinessProbe                *corev1.Probe
 	HostNetworkEnabled            bool
 	Pathways                      orchestrator.PathwaysJobDefinition
-	ExclusiveTopologyAnnotation   string
+	ExclusiveTopology             string // Node label each replicated job is placed exclusively by.
 	Verbose                       bool
 	Env                           []EnvVar
+	CostLabels                    map[string]string
+	ResourceLabels                map[string]string
 	PathwaysProxyEnv              []EnvVar
 	PathwaysServerEnv             []EnvVar
 	PathwaysWorkerEnv             []EnvVar
@@ -383,6 +530,7 @@ type kubernetesNodeCondition struct {
 type kueueWorkloadCondition struct {
 	Type               string `json:"type"`
 	Status             string `json:"status"`
+	Reason             string `json:"reason"`
 	Message            string `json:"message"`
 	LastTransitionTime string `json:"lastTransitionTime"`
 }
@@ -409,6 +557,7 @@ type kueueWorkload struct {
 	} `json:"spec"`
 	Status struct {
 		Admission *struct {
+			ClusterQueue      string                `json:"clusterQueue"`
 			PodSetAssignments []kueueWorkloadPodSet `json:"podSetAssignments"`
 		} `json:"admission"`
 		ReclaimablePods []kueueWorkloadPodSet    `json:"reclaimablePods"`
@@ -416,6 +565,18 @@ type kueueWorkload struct {
 	} `json:"status"`
 }
 
+// latestCondition returns the type of the most recent condition of the
+// workload that is currently true.
+func (w kueueWorkload) latestCondition() string {
+	var latest, state string
+	for _, c := range w.Status.Conditions {
+		if c.Status == "True" && c.LastTransitionTime >= latest {
+			latest, state = c.LastTransitionTime, c.Type
+		}
+	}
+	return state
+}
+
 type kueueWorkloadList struct {
 	Items []kueueWorkload `json:"items"`
 }
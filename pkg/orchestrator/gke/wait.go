@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// waitPollInterval is how often 'gcluster job wait' checks the workload.
+var waitPollInterval = 10 * time.Second
+
+// WaitJob polls the status of a workload until it reaches opts.Until. It
+// stops early when the workload fails, or succeeds while failed is awaited,
+// since the awaited condition can then no longer be reached.
+func (g *GKEOrchestrator) WaitJob(ctx context.Context, name string, opts orchestrator.WaitOptions) (orchestrator.WaitResult, error) {
+	if !slices.Contains(orchestrator.WaitConditions, opts.Until) {
+		return orchestrator.WaitResult{}, fmt.Errorf("invalid wait condition %q: must be one of %v", opts.Until, orchestrator.WaitConditions)
+	}
+	if err := g.configureKubectl(ctx, opts.ClusterName, opts.ClusterLocation, opts.ProjectID); err != nil {
+		return orchestrator.WaitResult{}, err
+	}
+
+	logging.Info("Waiting for job '%s' to be %s...", name, opts.Until)
+	deadline := g.now().Add(opts.Timeout)
+	var result orchestrator.WaitResult
+	for {
+		detail, err := g.jobStatusDetail(ctx, name)
+		if err != nil {
+			return result, err
+		}
+		if condition := waitCondition(detail); condition != result.Condition {
+			if condition != "" {
+				logging.Info("Job '%s' is %s.", name, condition)
+			}
+			result.Condition = condition
+		}
+		result.Status = detail.Status
+		result.Reached = conditionReached(result.Condition, opts.Until)
+		if result.Reached || result.Condition == orchestrator.WaitUntilFailed || result.Condition == orchestrator.WaitUntilSucceeded {
+			return result, nil
+		}
+		if opts.Timeout > 0 && !g.now().Before(deadline) {
+			logging.Warn("Timed out after %s waiting for job '%s' to be %s; its status is %s.", opts.Timeout, name, opts.Until, detail.Status)
+			return result, nil
+		}
+		g.sleep(waitPollInterval)
+	}
+}
+
+// waitCondition returns the latest lifecycle milestone detail shows, or
+// empty while the workload waits for admission.
+func waitCondition(detail orchestrator.JobStatusDetail) string {
+	switch detail.Status {
+	case "Failed", "StageInFailed":
+		return orchestrator.WaitUntilFailed
+	case "Succeeded":
+		return orchestrator.WaitUntilSucceeded
+	}
+	if slices.ContainsFunc(detail.Pods, func(p orchestrator.PodStatus) bool { return p.Phase == "Running" }) {
+		return orchestrator.WaitUntilRunning
+	}
+	// Kueue admits a workload by unsuspending it; without Kueue it is never
+	// suspended and its Jobs start right away.
+	active := slices.ContainsFunc(detail.ReplicatedJobs, func(r orchestrator.ReplicatedJobStatus) bool { return r.Active > 0 })
+	if detail.Status == "Running" || detail.KueueState == "Admitted" || active {
+		return orchestrator.WaitUntilAdmitted
+	}
+	return ""
+}
+
+// conditionReached reports whether a workload at milestone condition has
+// reached until. Admitted, running and succeeded are passed in that order.
+func conditionReached(condition, until string) bool {
+	if condition == until {
+		return true
+	}
+	progress := []string{orchestrator.WaitUntilAdmitted, orchestrator.WaitUntilRunning, orchestrator.WaitUntilSucceeded}
+	at, want := slices.Index(progress, condition), slices.Index(progress, until)
+	return at >= 0 && want >= 0 && at >= want
+}
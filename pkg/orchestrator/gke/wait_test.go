@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/testutil"
+)
+
+func TestWaitCondition(t *testing.T) {
+	running := []orchestrator.PodStatus{{Name: "p", Phase: "Running"}}
+	tests := []struct {
+		name   string
+		detail orchestrator.JobStatusDetail
+		want   string
+	}{
+		{name: "queued", detail: orchestrator.JobStatusDetail{Status: "Suspended", KueueState: "QuotaReserved"}},
+		{name: "admitted", detail: orchestrator.JobStatusDetail{Status: "Running", KueueState: "Admitted"}, want: "admitted"},
+		{name: "without queue", detail: orchestrator.JobStatusDetail{Status: "Unknown", ReplicatedJobs: []orchestrator.ReplicatedJobStatus{{Active: 1}}}, want: "admitted"},
+		{name: "running", detail: orchestrator.JobStatusDetail{Status: "Running", Pods: running}, want: "running"},
+		{name: "succeeded", detail: orchestrator.JobStatusDetail{Status: "Succeeded"}, want: "succeeded"},
+		{name: "failed", detail: orchestrator.JobStatusDetail{Status: "Failed", Pods: running}, want: "failed"},
+		{name: "stage-in failed", detail: orchestrator.JobStatusDetail{Status: "StageInFailed"}, want: "failed"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := waitCondition(tc.detail); got != tc.want {
+				t.Errorf("waitCondition() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		condition, until string
+		want             bool
+	}{
+		{"succeeded", "running", true},
+		{"running", "admitted", true},
+		{"admitted", "running", false},
+		{"failed", "failed", true},
+		{"failed", "admitted", false},
+		{"succeeded", "failed", false},
+		{"", "admitted", false},
+	} {
+		if got := conditionReached(tc.condition, tc.until); got != tc.want {
+			t.Errorf("conditionReached(%q, %q) = %t, want %t", tc.condition, tc.until, got, tc.want)
+		}
+	}
+}
+
+func TestWaitJob(t *testing.T) {
+	suspended := `{"metadata": {"uid": "uid-1"}, "spec": {"suspend": true}}`
+	admitted := `{"metadata": {"uid": "uid-1"}, "spec": {"suspend": false}}`
+	failed := `{"metadata": {"uid": "uid-1"}, "spec": {"suspend": false}, "status": {"conditions": [{"type": "Failed", "status": "True"}]}}`
+
+	run := func(t *testing.T, until string, timeout time.Duration, states ...string) (orchestrator.WaitResult, int) {
+		g := newTestGKEOrchestrator(&mockExecutor{})
+		client := newFakeDynamicClient()
+		polls := fakeGets(client, "JobSet", states...)
+		g.dynClient = client
+		g.kubeClient = &MockKubeClient{Namespace: "default", Workloads: []string{"train"}}
+		g.SetClock(testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+		result, err := g.WaitJob(context.Background(), "train", orchestrator.WaitOptions{ClusterName: "c", ClusterLocation: "l", ProjectID: "p", Until: until, Timeout: timeout})
+		if err != nil {
+			t.Fatalf("WaitJob: %v", err)
+		}
+		return result, *polls
+	}
+
+	t.Run("reached", func(t *testing.T) {
+		result, polls := run(t, "admitted", 0, suspended, suspended, admitted)
+		if !result.Reached || result.Condition != "admitted" || polls != 3 {
+			t.Errorf("expected admission after 3 polls, got %+v after %d", result, polls)
+		}
+	})
+	t.Run("failed first", func(t *testing.T) {
+		result, _ := run(t, "succeeded", 0, admitted, failed)
+		if result.Reached || result.Condition != "failed" || result.Status != "Failed" {
+			t.Errorf("expected the wait to stop at the failure, got %+v", result)
+		}
+	})
+	t.Run("timeout", func(t *testing.T) {
+		result, polls := run(t, "admitted", time.Minute, suspended)
+		if result.Reached || result.Condition != "" || result.Status != "Suspended" {
+			t.Errorf("expected a timeout while suspended, got %+v", result)
+		}
+		if want := int(time.Minute/waitPollInterval) + 1; polls != want {
+			t.Errorf("expected %d polls within the timeout, got %d", want, polls)
+		}
+	})
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hosted holds what the backends that run workloads on managed Google
+// Cloud services, rather than on a GKE cluster, have in common.
+package hosted
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/imagebuilder"
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+// BuildImage returns the image of job: the pre-built --image, the image built
+// from --base-image and the build context with crane, or the image built from
+// the --dockerfile with Cloud Build or, with the docker build backend, the
+// local Docker daemon. A dry run only predicts the name of the
+// built image.
+func BuildImage(ctx context.Context, job orchestrator.JobDefinition) (string, error) {
+	if job.Dockerfile != "" {
+		return buildDockerfile(ctx, job)
+	}
+	if job.BaseImage == "" {
+		if job.ImageName == "" {
+			return "", fmt.Errorf("one of --image, --base-image or --dockerfile must be provided")
+		}
+		logging.Info("Using pre-existing container image: %s", job.ImageName)
+		return job.ImageName, nil
+	}
+
+	repo, err := imagebuilder.ResolveImageRepo(job.ImageRepo, job.BuildProjectID, job.ClusterLocation)
+	if err != nil {
+		return "", err
+	}
+	if job.IsDryRun() {
+		logging.Info("[Dry Run] Skipping Crane build, generating predicted URI...")
+		return imagebuilder.PredictImageName(repo, imageTag(job), job.BuildContext)
+	}
+	if err := imagebuilder.EnsureRepository(repo); err != nil {
+		return "", err
+	}
+	baseImage := job.BaseImage
+	if job.Requirements != "" {
+		if baseImage, err = imagebuilder.EnsureDependencyImage(ctx, job.BuildProjectID, job.ClusterLocation, repo, baseImage, job.Requirements, job.Platform); err != nil {
+			return "", fmt.Errorf("failed to prepare dependency image: %w", err)
+		}
+	}
+	ignoreMatcher, err := imagebuilder.ReadDockerignorePatterns(job.BuildContext, imagebuilder.DefaultIgnorePatterns)
+	if err != nil {
+		return "", fmt.Errorf("failed to read .dockerignore patterns: %w", err)
+	}
+	logging.Info("Building container image using Crane (Go implementation) on top of %s...", baseImage)
+	image, err := imagebuilder.BuildContainerImageFromBaseImage(ctx, repo, baseImage, job.BuildContext, job.WorkDir, job.Platform, ignoreMatcher, imagebuilder.ImageConfig{Labels: job.ImageLabels}, imageTag(job))
+	if err != nil {
+		return "", fmt.Errorf("crane-based image build failed: %w", err)
+	}
+	logging.Info("Built image will be available at: %s", image)
+	return image, nil
+}
+
+func buildDockerfile(ctx context.Context, job orchestrator.JobDefinition) (string, error) {
+	repo, err := imagebuilder.ResolveImageRepo(job.ImageRepo, job.BuildProjectID, job.ClusterLocation)
+	if err != nil {
+		return "", err
+	}
+	if job.IsDryRun() {
+		logging.Info("[Dry Run] Skipping the build of %s, generating predicted URI...", job.Dockerfile)
+		return imagebuilder.PredictImageName(repo, imageTag(job), job.BuildContext)
+	}
+	if err := imagebuilder.EnsureRepository(repo); err != nil {
+		return "", err
+	}
+	var image string
+	if job.BuildBackend == orchestrator.BuildBackendDocker {
+		image, err = imagebuilder.BuildImageWithDocker(ctx, repo, job.BuildContext, job.Dockerfile, job.Platform, imageTag(job))
+	} else {
+		image, err = imagebuilder.BuildImageWithCloudBuild(ctx, job.BuildProjectID, job.ClusterLocation, repo, job.BuildContext, job.Dockerfile, job.Platform, imageTag(job))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to build %s: %w", job.Dockerfile, err)
+	}
+	logging.Info("Built image will be available at: %s", image)
+	return image, nil
+}
+
+// imageTag returns the tag of the images built for job.
+func imageTag(job orchestrator.JobDefinition) imagebuilder.ImageTag {
+	return imagebuilder.ImageTag{Strategy: imagebuilder.TagStrategy(job.ImageTagStrategy), Value: job.ImageTag}
+}
+
+// ValidateJob checks that job sets the fields backend requires and rejects
+// the settings that only GKE workloads support. service names what backend runs the workload as, e.g. "Cloud Batch job".
+func ValidateJob(job orchestrator.JobDefinition, backend, service string) error {
+	if err := orchestrator.ValidateJobDefinition(backend, job); err != nil {
+		return err
+	}
+	unsupported := []struct {
+		set  bool
+		flag string
+	}{
+		{job.IsPathwaysJob, "--pathways"},
+		{job.Snippet != "", "--snippet"},
+		{job.CommandScript != "", "--command-script"},
+		{len(job.RawMounts) > 0, "--mount and --gcs-bucket"},
+		{len(job.SecretMounts) > 0, "--mount-secret"},
+		{len(job.ConfigMapMounts) > 0, "--mount-configmap"},
+		{len(job.SecretEnv) > 0, "--secret-env"},
+		{len(job.SharedVolumes) > 0, "--nfs-server"},
+		{len(job.StageIn) > 0, "--stage-in"},
+		{len(job.Retention) > 0, "--retention"},
+		{len(job.Sidecars) > 0, "--sidecar"},
+		{job.LogArchive != nil, "--archive-logs"},
+		{job.StartupProbe != nil, "--startup-probe"},
+		{job.MintPullSecret, "--mint-pull-secret"},
+		{job.ImagePullSecrets != "", "--image-pull-secret"},
+		{job.AwaitJobCompletion, "--await-job-completion and --timeout"},
+		{job.BuildBackend == orchestrator.BuildBackendRemote, "--build-backend remote"},
+		{job.WorkloadKind != "" && job.WorkloadKind != orchestrator.WorkloadKindJobSet, "--workload-kind " + job.WorkloadKind},
+		{job.SigningKey != "", "--sign-key"},
+	}
+	var flags []string
+	for _, u := range unsupported {
+		if u.set {
+			flags = append(flags, u.flag)
+		}
+	}
+	if len(flags) > 0 {
+		return fmt.Errorf("%s cannot be used with the %s backend, which runs the workload as a %s", strings.Join(flags, ", "), backend, service)
+	}
+	return nil
+}
+
+// EnforceManagedProfile rejects job if it exceeds the bounds of the managed
+// profile it is submitted under. A hosted workload runs in no namespace or
+// Kueue queue, so the bounds every backend applies are checked: the image
+// registry and the GPUs of all its tasks.
+func EnforceManagedProfile(job orchestrator.JobDefinition) error {
+	if job.ManagedProfile == nil {
+		return nil
+	}
+	gpus := 0
+	if job.ManagedProfile.MaxGPUs > 0 && job.ComputeType != "" {
+		_, perTask, err := GPUs(config.ResolveMachineType(job.ComputeType), job.GPUsPerPod)
+		if err != nil {
+			return err
+		}
+		gpus = perTask * max(job.NumSlices, 1) * max(job.NodesPerSlice, 1)
+	}
+	violations, err := orchestrator.ManagedProfileViolations(job, gpus)
+	if err != nil {
+		return err
+	}
+	return orchestrator.ManagedProfileError(job, violations)
+}
+
+// gpuCountSuffix matches the GPU count at the end of machine types such as
+// a3-highgpu-8g.
+var gpuCountSuffix = regexp.MustCompile(`-(\d+)g$`)
+
+// GPUs returns the Compute Engine accelerator type of the GPUs attached to
+// machineType, e.g. nvidia-h100-80gb for a3-highgpu-8g, and how many of them
+// a task uses: gpusPerPod, or all of them when it is 0. The accelerator type
+// is empty for machine types without GPUs.
+func GPUs(machineType string, gpusPerPod int) (accelerator string, count int, err error) {
+	label, ok := acceleratorLabel(machineType)
+	if !ok || !strings.HasPrefix(label, "nvidia-") {
+		return "", 0, nil
+	}
+	if gpusPerPod > 0 {
+		return label, gpusPerPod, nil
+	}
+	if count, ok = gpuCount(machineType); !ok {
+		return "", 0, fmt.Errorf("cannot tell the number of GPUs of machine type %s; set it with --gpus-per-pod", machineType)
+	}
+	return label, count, nil
+}
+
+// acceleratorLabel returns the GKE accelerator label of the family of
+// machineType, which is also its Compute Engine accelerator type.
+func acceleratorLabel(machineType string) (string, bool) {
+	families := config.GetMachineMappings().MachineFamilyToLabelMap
+	parts := strings.Split(strings.ToLower(machineType), "-")
+	if len(parts) >= 2 {
+		if label, ok := families[parts[0]+"-"+parts[1]]; ok {
+			return label, true
+		}
+	}
+	label, ok := families[parts[0]]
+	return label, ok
+}
+
+// gpuCount returns the GPUs attached to machineType: the size of the
+// accelerator shorthand it is known by, or the count in its name.
+func gpuCount(machineType string) (int, bool) {
+	var shorthands []string
+	for shorthand, mt := range config.AcceleratorShorthandMap {
+		if mt == machineType {
+			shorthands = append(shorthands, shorthand)
+		}
+	}
+	sort.Strings(shorthands)
+	for _, shorthand := range shorthands {
+		if n, err := strconv.Atoi(shorthand[strings.LastIndex(shorthand, "-")+1:]); err == nil && n > 0 {
+			return n, true
+		}
+	}
+	if m := gpuCountSuffix.FindStringSubmatch(machineType); m != nil {
+		n, err := strconv.Atoi(m[1])
+		return n, err == nil && n > 0
+	}
+	return 0, false
+}
+
+// ContainerCommand runs an exec-form command as is, and a command string with
+// bash, like the containers of GKE workloads. Both are empty to run the
+// entrypoint of the image.
+func ContainerCommand(commandToRun string, commandArgs []string) (command, args []string) {
+	if len(commandArgs) > 0 {
+		return commandArgs[:1], commandArgs[1:]
+	}
+	if commandToRun == "" {
+		return nil, nil
+	}
+	if strings.Contains(strings.TrimSpace(commandToRun), "\n") {
+		return []string{"/bin/bash", "-e", "-c"}, []string{strings.TrimSpace(commandToRun)}
+	}
+	return []string{"/bin/bash", "-c", commandToRun}, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hosted
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestGPUs(t *testing.T) {
+	tests := []struct {
+		machineType string
+		gpusPerPod  int
+		wantType    string
+		wantCount   int
+	}{
+		{"a3-highgpu-8g", 0, "nvidia-h100-80gb", 8},
+		{"g2-standard-24", 0, "nvidia-l4", 2},
+		{"a2-highgpu-8g", 4, "nvidia-tesla-a100", 4},
+		{"n2-standard-32", 0, "", 0},
+		{"ct6e-standard-4t", 0, "", 0},
+	}
+	for _, tc := range tests {
+		gotType, gotCount, err := GPUs(tc.machineType, tc.gpusPerPod)
+		if err != nil {
+			t.Errorf("GPUs(%q) failed: %v", tc.machineType, err)
+			continue
+		}
+		if gotType != tc.wantType || gotCount != tc.wantCount {
+			t.Errorf("GPUs(%q, %d) = %q, %d; want %q, %d", tc.machineType, tc.gpusPerPod, gotType, gotCount, tc.wantType, tc.wantCount)
+		}
+	}
+}
+
+func TestContainerCommand(t *testing.T) {
+	tests := []struct {
+		command     string
+		args        []string
+		wantCommand []string
+		wantArgs    []string
+	}{
+		{"python train.py", nil, []string{"/bin/bash", "-c", "python train.py"}, nil},
+		{"set -x\npython train.py\n", nil, []string{"/bin/bash", "-e", "-c"}, []string{"set -x\npython train.py"}},
+		{"ignored", []string{"python", "main.py"}, []string{"python"}, []string{"main.py"}},
+		{"", nil, nil, nil},
+	}
+	for _, tc := range tests {
+		command, args := ContainerCommand(tc.command, tc.args)
+		if !reflect.DeepEqual(command, tc.wantCommand) || !reflect.DeepEqual(args, tc.wantArgs) {
+			t.Errorf("ContainerCommand(%q, %q) = %q, %q; want %q, %q", tc.command, tc.args, command, args, tc.wantCommand, tc.wantArgs)
+		}
+	}
+}
+
+func TestValidateJob(t *testing.T) {
+	job := orchestrator.JobDefinition{WorkloadName: "train", ImageName: "img", ClusterProjectID: "p", ClusterLocation: "us-central1"}
+	if err := ValidateJob(job, "vertex", "Vertex AI custom job"); err != nil {
+		t.Errorf("ValidateJob() failed: %v", err)
+	}
+
+	job.Snippet = "print(1)"
+	job.RawMounts = []string{"gs://bucket:/data"}
+	err := ValidateJob(job, "batch", "Cloud Batch job")
+	if err == nil || !strings.Contains(err.Error(), "--snippet, --mount and --gcs-bucket cannot be used with the batch backend") {
+		t.Errorf("expected the unsupported flags to be listed, got %v", err)
+	}
+
+	if err := ValidateJob(orchestrator.JobDefinition{}, "batch", "Cloud Batch job"); err == nil || !strings.Contains(err.Error(), "--name") {
+		t.Errorf("expected a missing name error, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"hpc-toolkit/pkg/imagebuilder"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// ManagedProfileViolations returns the bounds of the managed profile of job
+// that apply on every backend and that job exceeds: its image registry and,
+// given gpus, the number of GPUs of all its nodes.
+func ManagedProfileViolations(job JobDefinition, gpus int) ([]string, error) {
+	mp := job.ManagedProfile
+	if mp == nil {
+		return nil, nil
+	}
+	var violations []string
+	if mp.MaxGPUs > 0 && gpus > mp.MaxGPUs {
+		violations = append(violations, fmt.Sprintf("the workload requests %d GPUs, more than the allowed %d", gpus, mp.MaxGPUs))
+	}
+	if len(mp.AllowedRegistries) > 0 {
+		repository, err := WorkloadImageRepository(job)
+		if err != nil {
+			return nil, err
+		}
+		if repository != "" && !registryAllowed(repository, mp.AllowedRegistries) {
+			violations = append(violations, fmt.Sprintf("image repository %s is not allowed (allowed: %s)", repository, strings.Join(mp.AllowedRegistries, ", ")))
+		}
+	}
+	return violations, nil
+}
+
+// ManagedProfileError reports the violations of the managed profile of job,
+// or returns nil if there are none.
+func ManagedProfileError(job JobDefinition, violations []string) error {
+	if job.ManagedProfile == nil || len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the workload violates managed profile %q:\n  %s", job.ManagedProfile.Name, strings.Join(violations, "\n  "))
+}
+
+// WorkloadImageRepository returns the repository of the image the workload
+// runs: the --image repository, or the repository built images are pushed to.
+func WorkloadImageRepository(job JobDefinition) (string, error) {
+	switch {
+	case job.Pathways.Headless:
+		return "", nil
+	case job.ImageName != "":
+		ref, err := name.ParseReference(job.ImageName)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse image reference %q: %w", job.ImageName, err)
+		}
+		return ref.Context().Name(), nil
+	case job.BaseImage != "" || job.Dockerfile != "":
+		repo, err := imagebuilder.ResolveImageRepo(job.ImageRepo, job.BuildProjectID, job.ClusterLocation)
+		if err != nil {
+			return "", err
+		}
+		return repo.String(), nil
+	}
+	return "", nil
+}
+
+// registryAllowed reports whether repository is one of allowed, or under one
+// of them: a registry host or a repository prefix.
+func registryAllowed(repository string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.TrimSuffix(a, "/")
+		if repository == a || strings.HasPrefix(repository, a+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManagedProfileViolations(t *testing.T) {
+	job := JobDefinition{
+		ImageName:      "docker.io/library/python:3.11",
+		ManagedProfile: &ManagedProfile{Name: "ml-research", MaxGPUs: 8, AllowedRegistries: []string{"us-docker.pkg.dev/ml-research"}},
+	}
+	violations, err := ManagedProfileViolations(job, 16)
+	if err != nil {
+		t.Fatalf("ManagedProfileViolations() error = %v", err)
+	}
+	want := []string{
+		"the workload requests 16 GPUs, more than the allowed 8",
+		"image repository index.docker.io/library/python is not allowed (allowed: us-docker.pkg.dev/ml-research)",
+	}
+	if !reflect.DeepEqual(violations, want) {
+		t.Errorf("ManagedProfileViolations() = %q, want %q", violations, want)
+	}
+	if err := ManagedProfileError(job, nil); err != nil {
+		t.Errorf("expected no error without violations, got %v", err)
+	}
+
+	job.ManagedProfile = nil
+	if violations, err := ManagedProfileViolations(job, 16); err != nil || violations != nil {
+		t.Errorf("expected no violations without a profile, got %v, %v", violations, err)
+	}
+}
+
+func TestRegistryAllowed(t *testing.T) {
+	allowed := []string{"us-docker.pkg.dev/ml-research/", "gcr.io"}
+	tests := []struct {
+		repository string
+		want       bool
+	}{
+		{"us-docker.pkg.dev/ml-research/images", true},
+		{"us-docker.pkg.dev/ml-research", true},
+		{"us-docker.pkg.dev/ml-research-other/images", false},
+		{"gcr.io/some-project/image", true},
+		{"index.docker.io/library/python", false},
+	}
+	for _, tc := range tests {
+		if got := registryAllowed(tc.repository, allowed); got != tc.want {
+			t.Errorf("registryAllowed(%q) = %v, want %v", tc.repository, got, tc.want)
+		}
+	}
+}
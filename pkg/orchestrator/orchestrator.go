@@ -14,6 +14,14 @@
 
 package orchestrator
 
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
 var ValidPriorityClasses = []string{"very-low", "low", "medium", "high", "very-high"}
 
 type PathwaysJobDefinition struct {
@@ -52,34 +60,339 @@ type VolumeDefinition struct {
 	Name      string
 	Source    string // The raw <src>
 	MountPath string // The <dest>
-	Type      string // "gcsfuse", "hostPath", "pvc"
+	Type      string // "gcsfuse", "hostPath", "pvc", "secret", "configMap"
 	ReadOnly  bool
 }
 
+// Kinds of StartupProbe checks.
+const (
+	StartupProbeHTTP = "http"
+	StartupProbeTCP  = "tcp"
+	StartupProbeExec = "exec"
+)
+
+// StartupProbe checks whether a workload has finished starting, for example
+// loading a large model, before it is considered ready.
+type StartupProbe struct {
+	Kind    string // One of StartupProbeHTTP, StartupProbeTCP and StartupProbeExec.
+	Port    int    // Port of HTTP and TCP checks.
+	Path    string // Path of HTTP checks.
+	Command string // Shell command of exec checks; it succeeds with exit code 0.
+	// Timeout is how long the workload may take to start before its
+	// containers are restarted.
+	Timeout time.Duration
+}
+
+// StepTimeouts limit how long the steps of a submission may take. A zero
+// timeout does not limit the step.
+type StepTimeouts struct {
+	Credentials time.Duration // Fetching the cluster credentials.
+	Build       time.Duration // Building and pushing the container image.
+	Apply       time.Duration // Applying the workload manifests.
+}
+
+// SharedVolume is an NFS export, such as a Filestore share, that all the pods
+// of a workload mount to share a POSIX filesystem.
+type SharedVolume struct {
+	Server    string // Host name or IP address of the NFS server.
+	Path      string // Exported path on the server.
+	MountPath string // Path in the containers.
+}
+
+// NodeAffinityExpression is a required node affinity match expression, e.g. "team-pool In a,b".
+type NodeAffinityExpression struct {
+	Key      string
+	Operator string // In, NotIn, Exists, DoesNotExist, Gt or Lt.
+	Values   []string
+}
+
+// ContainerSpec is an auxiliary container run in every pod alongside the
+// workload, e.g. TensorBoard or a metrics exporter.
+type ContainerSpec struct {
+	Name    string
+	Image   string
+	Command string // Run with /bin/sh -c; empty runs the entrypoint of the image.
+}
+
+// Toleration lets the pods of a workload schedule on nodes with a matching
+// taint, e.g. "nvidia.com/gpu=present:NoSchedule".
+type Toleration struct {
+	Key    string
+	Value  string // Empty to tolerate the taint whatever its value.
+	Effect string // NoSchedule, PreferNoSchedule or NoExecute; empty for all effects.
+}
+
+// Workload kinds a job manifest can be rendered as. JobSets, MPI JobSets and
+// single-node batch Jobs are applied to the cluster; the other kinds are
+// written out with --dry-run-out.
+const (
+	WorkloadKindJobSet     = "jobset"
+	WorkloadKindJob        = "job"
+	WorkloadKindDeployment = "deployment"
+	WorkloadKindRayJob     = "rayjob"
+	// WorkloadKindMPI is a JobSet of an mpirun launcher and SSH workers.
+	WorkloadKindMPI = "mpi"
+)
+
+var WorkloadKinds = []string{WorkloadKindJobSet, WorkloadKindJob, WorkloadKindDeployment, WorkloadKindRayJob, WorkloadKindMPI}
+
+// PlacementPolicyCompact is the --placement-policy placing the pods of a
+// workload on physically adjacent hosts, instead of naming a placement group.
+const PlacementPolicyCompact = "compact"
+
+// Container restart policies of the pods of a batch workload. Jobs do not
+// accept Always.
+const (
+	RestartPolicyNever     = "Never"
+	RestartPolicyOnFailure = "OnFailure"
+)
+
+var RestartPolicies = []string{RestartPolicyNever, RestartPolicyOnFailure}
+
+// Distributed training frameworks whose environment variables --framework
+// injects into the workload containers.
+const (
+	FrameworkPyTorch = "pytorch"
+	FrameworkJAX     = "jax"
+	FrameworkMPI     = "mpi"
+)
+
+var Frameworks = []string{FrameworkPyTorch, FrameworkJAX, FrameworkMPI}
+
+// Languages of an inline --snippet.
+const (
+	SnippetLanguagePython = "python"
+	SnippetLanguageBash   = "bash"
+)
+
+var SnippetLanguages = []string{SnippetLanguagePython, SnippetLanguageBash}
+
+// Destinations container logs can be archived to before ttlSecondsAfterFinished
+// deletes a workload's pods.
+const (
+	LogArchiveGCS     = "gcs"     // Objects under gs://<bucket>/<prefix>/<workload>/<pod>/.
+	LogArchiveLogging = "logging" // A Cloud Logging log bucket, routed by a log sink.
+)
+
+// LogArchive is where an archiver sidecar keeps a workload's container logs and
+// termination messages after its pods are deleted.
+type LogArchive struct {
+	Kind     string // LogArchiveGCS or LogArchiveLogging.
+	Bucket   string // GCS bucket name, or Cloud Logging log bucket ID.
+	Prefix   string // Object prefix in the GCS bucket; may be empty.
+	Location string // Location of the Cloud Logging log bucket.
+}
+
+// Base image policies, applied to base images that are older than the allowed
+// age or whose tag moved since the last build.
+const (
+	BaseImagePolicyWarn  = "warn"
+	BaseImagePolicyBlock = "block"
+)
+
+var BaseImagePolicies = []string{BaseImagePolicyWarn, BaseImagePolicyBlock}
+
+// Build backends, which build the image of a --base-image or --dockerfile.
+// The crane backend builds a --base-image on the local machine; the remote
+// backend builds it on a short-lived Compute Engine VM near the registry. The
+// cloudbuild backend builds a --dockerfile with Cloud Build; the docker
+// backend builds it with the local Docker daemon.
+const (
+	BuildBackendCrane      = "crane"
+	BuildBackendRemote     = "remote"
+	BuildBackendDocker     = "docker"
+	BuildBackendCloudBuild = "cloudbuild"
+)
+
+var BuildBackends = []string{BuildBackendCrane, BuildBackendRemote, BuildBackendDocker, BuildBackendCloudBuild}
+
+// Secret env modes, which pass the Secret Manager secrets of --secret-env to
+// the workload. The secret mode reads them at submission, with the
+// credentials of the user, into a Kubernetes Secret of the workload. The csi
+// mode mounts them with the Secret Manager CSI driver of GKE, so the values
+// are never read by gcluster, but the Kubernetes service account of the pods
+// must be granted access to them.
+const (
+	SecretEnvModeSecret = "secret"
+	SecretEnvModeCSI    = "csi"
+)
+
+var SecretEnvModes = []string{SecretEnvModeSecret, SecretEnvModeCSI}
+
+// SecretRefScheme prefixes the references to Secret Manager secrets.
+const SecretRefScheme = "sm://"
+
+// SecretRef is a version of a Secret Manager secret. An empty Project is the
+// project of the cluster.
+type SecretRef struct {
+	Project string
+	Secret  string
+	Version string
+}
+
+var (
+	secretIDPattern      = regexp.MustCompile(`^[A-Za-z0-9_-]{1,255}$`)
+	secretVersionPattern = regexp.MustCompile(`^(latest|[1-9][0-9]*)$`)
+)
+
+// ParseSecretRef parses sm://projects/<project>/secrets/<secret>[/versions/<version>]
+// or the short form sm://<secret>[/versions/<version>], which refers to a
+// secret in the project of the cluster. The version defaults to latest.
+func ParseSecretRef(ref string) (SecretRef, error) {
+	path, ok := strings.CutPrefix(ref, SecretRefScheme)
+	if !ok {
+		return SecretRef{}, fmt.Errorf("invalid Secret Manager reference %q: must start with %s", ref, SecretRefScheme)
+	}
+	var r SecretRef
+	if rest, ok := strings.CutPrefix(path, "projects/"); ok {
+		project, secret, ok := strings.Cut(rest, "/secrets/")
+		if !ok || project == "" {
+			return SecretRef{}, fmt.Errorf("invalid Secret Manager reference %q: expected %sprojects/<project>/secrets/<secret>[/versions/<version>]", ref, SecretRefScheme)
+		}
+		r.Project, path = project, secret
+	}
+	r.Secret, r.Version, _ = strings.Cut(path, "/versions/")
+	if r.Version == "" {
+		r.Version = "latest"
+	}
+	if !secretIDPattern.MatchString(r.Secret) {
+		return SecretRef{}, fmt.Errorf("invalid Secret Manager reference %q: %q is not a valid secret name", ref, r.Secret)
+	}
+	if !secretVersionPattern.MatchString(r.Version) {
+		return SecretRef{}, fmt.Errorf("invalid Secret Manager reference %q: version must be latest or a version number", ref)
+	}
+	return r, nil
+}
+
+// String returns the resource name of the secret version.
+func (r SecretRef) String() string {
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/%s", r.Project, r.Secret, r.Version)
+}
+
+// StageIn copies a Cloud Storage prefix onto a Filestore or PVC mount of the
+// workload before the workload is submitted.
+type StageIn struct {
+	Source string // gs://bucket[/prefix]
+	Dest   string // Path under the mount path of a filestore:// or PVC --mount.
+}
+
+// DataInput is a Cloud Storage object, or prefix ending in '/', that a
+// workload reads. It is verified before the workload is submitted, its URI is
+// passed to the workload in the environment variable Name, and it is recorded
+// on the workload.
+type DataInput struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	Generation string `json:"generation,omitempty"` // Expected generation of an object; set to the actual one once verified.
+	MD5        string `json:"md5,omitempty"`        // Expected base64 MD5 hash of an object; set to the actual one once verified.
+}
+
+// RetentionRule limits what is kept of the outputs or checkpoints written
+// under Path. Each file or directory directly under Path is one entry, aged by
+// the newest file it holds. An entry is deleted when it is not one of the
+// KeepLast newest entries and is older than ExpireAfter.
+type RetentionRule struct {
+	// Path is a gs:// prefix, or a directory under a filestore:// or PVC
+	// mount of the workload.
+	Path string `json:"path"`
+	// KeepLast is the number of newest entries that are never deleted.
+	KeepLast int `json:"keep_last,omitempty"`
+	// ExpireAfter is the age past which entries are deleted; zero deletes
+	// every entry but the KeepLast newest.
+	ExpireAfter time.Duration `json:"expire_after,omitempty"`
+	// ClaimName and MountPath locate a Path on a mount: the PVC holding it
+	// and where the workload mounted it. They are empty for gs:// paths.
+	ClaimName string `json:"claim_name,omitempty"`
+	MountPath string `json:"mount_path,omitempty"`
+}
+
+// ManagedProfile is a read-only set of bounds distributed by cluster admins
+// to a team. Empty fields leave the corresponding setting unrestricted.
+type ManagedProfile struct {
+	// Name identifies the profile on the workloads submitted under it.
+	Name              string   `json:"name"`
+	AllowedNamespaces []string `json:"allowed_namespaces,omitempty"`
+	// AllowedQueues lists the Kueue LocalQueues workloads may be submitted
+	// to; the first one is the default queue.
+	AllowedQueues []string `json:"allowed_queues,omitempty"`
+	MaxGPUs       int      `json:"max_gpus,omitempty"`
+	// AllowedRegistries lists the registry hosts or repository prefixes the
+	// workload's image may come from, e.g. us-docker.pkg.dev/my-project.
+	AllowedRegistries []string `json:"allowed_registries,omitempty"`
+	// PresetsFile is an accelerator presets file extending the built-in
+	// presets, relative to the directory of the profile.
+	PresetsFile string `json:"presets_file,omitempty"`
+}
+
 type JobDefinition struct {
 	ImageName       string
 	BaseImage       string
+	BaseImageDigest string // Digest BaseImage resolved to when it was checked against the base image policy.
+	ImageRepo       string // Artifact Registry repository built images are pushed to; defaults to GCLUSTER_IMAGE_REPO.
 	BuildContext    string
-	Platform        string
-	CommandToRun    string
-	ComputeType     string
-	MachineType     string
-	DryRunManifest  string
-	ProjectID       string
-	ClusterName     string
-	ClusterLocation string
+	// WorkDir is the directory of the image BuildContext is placed under and
+	// the working directory of the image built from BaseImage; empty places
+	// BuildContext at the image root.
+	WorkDir string
+	// ImageLabels are set in the config of the image built from BaseImage,
+	// besides the labels that record its provenance.
+	ImageLabels map[string]string
+	// ImageTagStrategy selects the tag of built images: "content-hash"
+	// (default), "git-sha" or "user", which tags them with ImageTag.
+	ImageTagStrategy string
+	ImageTag         string
+	// Dockerfile is built on BuildContext with Cloud Build or the local
+	// Docker daemon, the third way to get the image of a workload besides
+	// ImageName and BaseImage.
+	Dockerfile   string
+	Requirements string
+	Platform     string
+	CommandToRun string
+	CommandArgs  []string // Exec-form command; takes precedence over CommandToRun.
+	// Snippet is a short inline script run in place of a command. It is
+	// mounted into the container from a ConfigMap, so no image is built.
+	Snippet         string
+	SnippetLanguage string // One of SnippetLanguages.
+	// CommandScript is the content of a script file run in place of a
+	// command, and CommandScriptName the name of the file. Like a snippet,
+	// it is mounted into the container from a ConfigMap.
+	CommandScript     string
+	CommandScriptName string
+	ComputeType       string
+	MachineType       string
+	DryRunManifest    string
+	// DryRun resolves and renders the workload without fetching cluster
+	// credentials, pushing images or applying manifests. The manifest is
+	// printed unless DryRunManifest is set.
+	DryRun bool
+	// ClusterProjectID is the project of the cluster the workload runs on.
+	// BuildProjectID is the project images are built and pushed in; it
+	// defaults to ClusterProjectID.
+	ClusterProjectID string
+	BuildProjectID   string
+	ClusterName      string
+	ClusterLocation  string
 
 	WorkloadName                  string
+	WorkloadKind                  string // One of WorkloadKinds; empty means WorkloadKindJobSet.
 	KueueQueueName                string
+	NoQueue                       bool // Submit without a Kueue queue; pods are scheduled by priority only.
 	NumSlices                     int
 	NodesPerSlice                 int
 	MaxRestarts                   int
 	TtlSecondsAfterFinished       int
 	TerminationGracePeriodSeconds int
+	RestartPolicy                 string // One of RestartPolicies; empty means RestartPolicyNever.
+	PodBackoffLimit               int    // Pod failures, or container restarts with OnFailure, each Job tolerates before failing.
+	DNSHostnames                  bool   // Give the pods DNS hostnames under a headless Service of the workload.
+	Framework                     string // One of Frameworks; empty injects no distributed training variables.
 
 	PlacementPolicy    string
 	NodeConstraint     map[string]string
 	Affinity           map[string]string
+	NodeAffinityExprs  []NodeAffinityExpression
+	Tolerations        []Toleration
+	Spot               bool // Schedule on Spot VM node pools.
 	PodFailurePolicy   map[string]interface{}
 	RestartOnExitCodes []int
 
@@ -90,10 +403,34 @@ type JobDefinition struct {
 	AwaitJobCompletion    bool
 	UseParallelContainers bool
 	Timeout               string
+	StepTimeouts          StepTimeouts
 	PriorityClassName     string
 	GKENAPProvisioning    string
 	GKENAPReservation     string
 
+	// MintPullSecret adds a pull secret for the image holding an access token
+	// of PullSecretAccount, a Google service account that can read the
+	// registry. With RefreshPullSecret a CronJob acting as that account keeps
+	// the token fresh.
+	MintPullSecret    bool
+	PullSecretAccount string
+	RefreshPullSecret bool
+
+	// CPU and Memory are Kubernetes quantities, and GPUsPerPod a count, that
+	// override the container limits derived from the compute type. Empty
+	// and 0 keep the derived limits.
+	CPU        string
+	Memory     string
+	GPUsPerPod int
+	// CPURequest and MemoryRequest request less than the limits, for
+	// burstable pods. Empty requests equal the limits.
+	CPURequest    string
+	MemoryRequest string
+
+	// StartupProbe, when set, keeps the workload's pods unready, and their
+	// containers from being restarted, until it succeeds or times out.
+	StartupProbe *StartupProbe
+
 	// Pathways-specific fields
 	IsPathwaysJob bool
 	Pathways      PathwaysJobDefinition // Embedded struct for Pathways-specific args
@@ -101,14 +438,99 @@ type JobDefinition struct {
 	RawMounts []string
 	Env       map[string]string
 
+	// SecretMounts and ConfigMapMounts mount existing Secrets and ConfigMaps
+	// read-only, in <name>:<dest> format.
+	SecretMounts    []string
+	ConfigMapMounts []string
+	// SecretEnv sets environment variables, by name, from Secret Manager
+	// secrets given as ParseSecretRef references. SecretEnvMode is one of
+	// SecretEnvModes; empty means SecretEnvModeSecret.
+	SecretEnv     map[string]string
+	SecretEnvMode string
+	// SharedVolumes are mounted read-write into every pod of the workload.
+	SharedVolumes []SharedVolume
+
+	// BaseImageMaxAgeDays reports base images created more than this many
+	// days ago; 0 disables the check.
+	BaseImageMaxAgeDays int
+	// BaseImagePolicy is one of BaseImagePolicies; empty means BaseImagePolicyWarn.
+	BaseImagePolicy string
+	// BuildBackend is one of BuildBackends; empty means BuildBackendCrane
+	// for a BaseImage and BuildBackendCloudBuild for a Dockerfile.
+	// BuilderZone is the zone of the VM of BuildBackendRemote; it defaults
+	// to a zone in the region of the registry.
+	BuildBackend string
+	BuilderZone  string
+
+	// ComputeTypeFallbacks are used, in order, when the cluster cannot run
+	// the job on ComputeType. The first available one replaces ComputeType.
+	ComputeTypeFallbacks []string
+	// MinGPUMemoryGB, when set instead of ComputeType, selects the cheapest
+	// GPU machine type of the cluster's node pools whose GPUs have at least
+	// this much memory.
+	MinGPUMemoryGB int
+
+	// CapturedEnv is the local environment, as conda:<name> or venv:<path>,
+	// that Requirements was exported from, and CapturedEnvDigest the digest
+	// of the export. Both are recorded on the workload.
+	CapturedEnv       string
+	CapturedEnvDigest string
+
+	// SigningKey is the Cloud KMS key version the rendered manifest is signed
+	// with, as projects/.../cryptoKeyVersions/N. Empty leaves it unsigned.
+	SigningKey string
+
+	// StageIn lists the datasets copied onto the workload's volumes before it
+	// is submitted.
+	StageIn []StageIn
+
+	// Inputs are the datasets the workload reads.
+	Inputs []DataInput
+
+	// Retention declares how long the outputs and checkpoints the workload
+	// writes are kept. The rules are recorded on the cluster and enforced by
+	// 'gcluster fleet gc --outputs'.
+	Retention []RetentionRule
+
+	// LogArchive keeps container logs after the workload's pods are deleted;
+	// nil disables archiving.
+	LogArchive *LogArchive
+
+	// Sidecars run as native sidecar containers: they are started before the
+	// workload containers, restarted when they exit, and stopped once the
+	// workload containers finish, so they neither complete nor fail the pod.
+	Sidecars []ContainerSpec
+
+	// CostLabels are pod labels picked up by GKE cost allocation. Only the
+	// "team", "experiment" and "user" keys are rendered.
+	CostLabels map[string]string
+
+	// ManagedProfile bounds the workload; nil if no profile is installed.
+	ManagedProfile *ManagedProfile
+
+	// RunID identifies one submission of the workload and SubmitTime records
+	// when it was made. Both are set on submit when empty, and are passed to
+	// the containers as GCLUSTER_RUN_ID and GCLUSTER_SUBMIT_TIME.
+	RunID      string
+	SubmitTime time.Time
+
 	Verbose bool
 }
 
+// IsDryRun reports whether the workload is only rendered, not submitted.
+func (j JobDefinition) IsDryRun() bool {
+	return j.DryRun || j.DryRunManifest != ""
+}
+
 type JobStatus struct {
 	Name           string
+	Namespace      string
 	Status         string
 	CreationTime   string
 	CompletionTime string
+	Queue          string // Kueue LocalQueue the workload was submitted to.
+	KueueState     string // Latest true Kueue Workload condition, e.g. QuotaReserved, Admitted or Evicted.
+	Experiment     string // Experiment the workload was submitted under with --experiment.
 }
 
 type ListOptions struct {
@@ -118,12 +540,22 @@ type ListOptions struct {
 	// Filters
 	Status       string
 	NameContains string
+	Experiment   string
 }
 
 type CancelOptions struct {
 	ProjectID       string
 	ClusterName     string
 	ClusterLocation string
+
+	// GracePeriodSeconds overrides the termination grace period of the
+	// workload's pods; nil keeps the period from the pod spec.
+	GracePeriodSeconds *int64
+	// Force removes the pods immediately without waiting for them to terminate.
+	Force bool
+	// DeleteVolumes also deletes the volume claims gcluster created for the
+	// workload's mounts when no other workload uses them.
+	DeleteVolumes bool
 }
 
 type LogsOptions struct {
@@ -131,9 +563,238 @@ type LogsOptions struct {
 	ClusterName     string
 	ClusterLocation string
 	Follow          bool
+	Since           string
 	MainOnly        *bool
 }
 
+// Lifecycle milestones of a workload that 'gcluster job wait --until' waits
+// for. Admitted, running and succeeded follow each other, so a workload that
+// has succeeded has also been admitted and running; failed ends a workload
+// before it can succeed.
+const (
+	WaitUntilAdmitted  = "admitted"
+	WaitUntilRunning   = "running"
+	WaitUntilSucceeded = "succeeded"
+	WaitUntilFailed    = "failed"
+)
+
+// WaitConditions lists the values of WaitOptions.Until.
+var WaitConditions = []string{WaitUntilAdmitted, WaitUntilRunning, WaitUntilSucceeded, WaitUntilFailed}
+
+// WaitOptions configures 'gcluster job wait'.
+type WaitOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	Until           string        // One of WaitConditions.
+	Timeout         time.Duration // 0 waits without a limit.
+}
+
+// WaitResult is where a wait for a workload stopped.
+type WaitResult struct {
+	// Reached reports whether the workload reached the awaited condition.
+	Reached bool
+	// Condition is the last milestone the workload reached, or empty while
+	// it is pending. A terminal Condition other than the awaited one means
+	// the awaited one cannot be reached anymore; a wait that stopped without
+	// either timed out.
+	Condition string
+	Status    string // Status of the workload when the wait stopped.
+}
+
+// AttachOptions configures 'gcluster job attach', which streams the logs of a
+// workload and waits until it finishes.
+type AttachOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	// Since, a relative duration such as "10m", limits the logs of a session
+	// that does not resume.
+	Since string
+	// Resume is where an earlier session stopped; nil starts a new one.
+	Resume *AttachCheckpoint
+	// OnCheckpoint is called as the session progresses, so that it can be
+	// resumed if the process dies.
+	OnCheckpoint func(AttachCheckpoint)
+}
+
+// AttachCheckpoint is how far a session watching a workload got.
+type AttachCheckpoint struct {
+	// LogCursors holds the time of the last log line printed for each
+	// "pod/<pod>/<container>" stream.
+	LogCursors map[string]time.Time `json:"log_cursors,omitempty"`
+	// ResourceVersion and Status are those of the JobSet when it was last seen.
+	ResourceVersion string `json:"resource_version,omitempty"`
+	Status          string `json:"status,omitempty"`
+}
+
+type StatusOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+}
+
+// ReplicatedJobStatus summarizes the child Jobs of one JobSet replicated job.
+type ReplicatedJobStatus struct {
+	Name      string
+	Replicas  int
+	Active    int
+	Ready     int
+	Succeeded int
+	Failed    int
+	Suspended int
+}
+
+// PodStatus is the phase of a single workload pod.
+type PodStatus struct {
+	Name          string
+	ReplicatedJob string
+	Phase         string
+	Node          string
+	Restarts      int
+	// State refines a running pod's phase: PodStateLoading while a container
+	// has not passed its startup probe, PodStateCrashLooping while one is
+	// backing off from repeated crashes. It is empty otherwise.
+	State string
+	// Terminations lists why containers of the pod last stopped abnormally.
+	Terminations []ContainerTermination
+}
+
+// ContainerTermination is the last abnormal stop of a container of a workload pod.
+type ContainerTermination struct {
+	Container string
+	Reason    string // e.g. OOMKilled or Error.
+	ExitCode  int
+	// MemoryHighWater is the memory use at which an OOMKilled container was
+	// killed: its memory limit, which the kernel enforces. It is empty when
+	// unknown, e.g. for a container without a limit.
+	MemoryHighWater string
+}
+
+// GPUError is an NVIDIA XID error reported for a GPU of a node that runs
+// workload pods.
+type GPUError struct {
+	Node        string
+	GPU         string // GPU index or PCI address.
+	Pod         string // Workload pod using the GPU, if known.
+	XID         int
+	Source      string // GPUErrorSourceDmesg or GPUErrorSourceDCGM.
+	Description string // What the XID reports, e.g. "GPU has fallen off the bus".
+	Cause       string // Probable root cause.
+}
+
+// Values of GPUError.Source.
+const (
+	// GPUErrorSourceDmesg marks errors from node events that
+	// node-problem-detector raises from the kernel log of the node.
+	GPUErrorSourceDmesg = "dmesg"
+	// GPUErrorSourceDCGM marks errors from the metrics of the DCGM exporter.
+	GPUErrorSourceDCGM = "dcgm"
+)
+
+// Values of PodStatus.State.
+const (
+	PodStateLoading      = "Loading"
+	PodStateCrashLooping = "CrashLoopBackOff"
+)
+
+// JobStatusDetail is the detailed state of a single workload reported by 'gcluster job status'.
+type JobStatusDetail struct {
+	Name             string
+	Namespace        string
+	Status           string
+	Restarts         int
+	MaxRestarts      int
+	RetriesRemaining int
+	KueueQueue       string
+	KueueState       string // Latest true Kueue Workload condition, e.g. QuotaReserved, Admitted or Evicted.
+	ClusterQueue     string // ClusterQueue the workload was admitted to, if any.
+	ReplicatedJobs   []ReplicatedJobStatus
+	Pods             []PodStatus
+	StageIn          *StageInStatus // Set when the workload was submitted with --stage-in.
+	GPUErrors        []GPUError     // XID errors of GPUs on the nodes of the workload's pods.
+	// ProbableCauses summarizes, most severe first, the likely root causes of
+	// OOMKilled containers and GPU errors. It is empty when there are none.
+	ProbableCauses []string
+}
+
+// StageInStatus is the state of the stage-in Job of a workload.
+type StageInStatus struct {
+	State     string // Running, Complete or Failed.
+	Transfers []TransferProgress
+}
+
+// TransferProgress is the last reported progress of one stage-in transfer.
+type TransferProgress struct {
+	Source      string
+	CopiedBytes int64
+	TotalBytes  int64
+	Done        bool
+}
+
+func (t TransferProgress) String() string {
+	if t.Done {
+		return fmt.Sprintf("%s: done (%s)", t.Source, formatBytes(t.TotalBytes))
+	}
+	if t.TotalBytes <= 0 {
+		return fmt.Sprintf("%s: %s copied", t.Source, formatBytes(t.CopiedBytes))
+	}
+	return fmt.Sprintf("%s: %s / %s (%d%%)", t.Source, formatBytes(t.CopiedBytes), formatBytes(t.TotalBytes), min(t.CopiedBytes*100/t.TotalBytes, 100))
+}
+
+// formatBytes renders n with a binary unit, e.g. "1.5 TiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// DescribeOptions selects the cluster of the workload reported by 'gcluster job describe'.
+type DescribeOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+}
+
+// Condition is a status condition of a JobSet or Kueue Workload.
+type Condition struct {
+	Type               string
+	Status             string
+	Reason             string
+	Message            string
+	LastTransitionTime string
+}
+
+// Event aggregates the Kubernetes events of a workload's objects that share
+// a reason and message.
+type Event struct {
+	Type     string // Normal or Warning.
+	Reason   string
+	Message  string
+	Objects  []string // Kind/name of the objects the event was reported for.
+	Count    int
+	LastSeen string
+}
+
+// WorkloadDescription merges the state of a workload with the conditions and
+// events explaining it, as reported by 'gcluster job describe'.
+type WorkloadDescription struct {
+	Status          JobStatusDetail
+	Conditions      []Condition // Of the JobSet.
+	KueueConditions []Condition // Of the newest Kueue Workload.
+	Events          []Event
+	// Diagnosis lists, most severe first, human-readable reasons why the
+	// workload is pending or failing. It is empty when nothing is wrong.
+	Diagnosis []string
+}
+
 // InspectOptions defines configuration for GKE cluster diagnostic sweeps.
 type InspectOptions struct {
 	ProjectID       string
@@ -144,13 +805,365 @@ type InspectOptions struct {
 	Show            bool
 }
 
+// BundleOptions configures the support bundle written by 'gcluster job bundle'.
+type BundleOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	OutputPath      string
+	LogTailLines    int    // Log lines kept per container.
+	ClientInfo      string // gcluster version and invocation, already redacted by the caller.
+}
+
+// ReportOptions configures the run report of 'gcluster report'.
+type ReportOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	LogTailLines    int // Log lines per container that excerpts are taken from.
+}
+
+// RunReport summarizes a run of a workload for sharing, as assembled by
+// 'gcluster report'. Env values whose names look like secrets are redacted.
+type RunReport struct {
+	Name        string
+	Namespace   string
+	ClusterName string
+	Location    string
+	ProjectID   string
+	RunID       string
+	Status      string
+	// Labels and Annotations are the gcluster.google.com labels, such as the
+	// team and experiment, and annotations, such as the base image digest and
+	// inputs, of the workload, without their prefix.
+	Labels      map[string]string
+	Annotations map[string]string
+	// Created is when the workload was submitted, Started when it was
+	// admitted, and Finished when it completed or failed; Finished is zero
+	// while it runs.
+	Created    time.Time
+	Started    time.Time
+	Finished   time.Time
+	Containers []ReportContainer
+	Resources  []ReportResource
+	// Diagnosis lists why a failed or pending workload is in that state.
+	Diagnosis []string
+	// ErrorLines are the log lines that look like errors, and LastLines the
+	// last lines of the logs of the workload, prefixed with their pod and
+	// container.
+	ErrorLines  []string
+	LastLines   []string
+	WorkloadURL string
+	LogsURL     string
+}
+
+// Duration is how long the run has been running at now, or ran.
+func (r RunReport) Duration(now time.Time) time.Duration {
+	if r.Started.IsZero() {
+		return 0
+	}
+	if !r.Finished.IsZero() {
+		return r.Finished.Sub(r.Started)
+	}
+	return now.Sub(r.Started)
+}
+
+// ReportContainer is the main container of a replicated job of a workload.
+type ReportContainer struct {
+	ReplicatedJob string
+	Pods          int
+	Image         string
+	Command       []string
+	Env           map[string]string
+}
+
+// ReportResource is a resource the pods of a workload request, such as cpu,
+// memory or nvidia.com/gpu, summed over its pods.
+type ReportResource struct {
+	Name  string
+	Total string
+	// Accelerator is set for GPUs and TPUs, whose chip-hours are reported.
+	Accelerator bool
+	Count       int64
+}
+
+// EtaOptions configures the admission estimate of 'gcluster job eta'.
+type EtaOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	// SpecPath is a manifest written by 'gcluster job submit --dry-run-out'
+	// to estimate before submitting it, instead of a submitted workload.
+	SpecPath string
+}
+
+// ResourceHeadroom compares the request of one resource with the ClusterQueue
+// it is queued in. Quantities are Kubernetes quantity strings.
+type ResourceHeadroom struct {
+	Name      string
+	Requested string
+	Quota     string // Nominal quota summed over the ClusterQueue's flavors.
+	InUse     string // Used by workloads admitted to the ClusterQueue.
+	Ahead     string // Requested by pending workloads queued ahead.
+	NodeLimit string // Capacity the node pools can scale to; empty when unknown.
+}
+
+// AdmissionEstimate is the rough admission time reported by 'gcluster job eta'.
+type AdmissionEstimate struct {
+	Workload       string
+	State          string // Admitted, Pending, Finished or NotSubmitted.
+	LocalQueue     string
+	ClusterQueue   string
+	WorkloadsAhead int
+	Resources      []ResourceHeadroom
+	// Wait is the estimated time until admission; it is only meaningful when
+	// WaitKnown is set.
+	Wait      time.Duration
+	WaitKnown bool
+	Summary   string
+	Notes     []string
+}
+
+// PlanOptions configures the placement preview of 'gcluster job plan'.
+type PlanOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	// SpecPath is a manifest written by 'gcluster job submit --dry-run-out'.
+	SpecPath string
+}
+
+// NodePlacement is the number of pods of a pod set placed on one node.
+type NodePlacement struct {
+	Node     string
+	NodePool string
+	Pods     int
+}
+
+// PodSetPlan is the placement of a group of identical pods, such as a
+// replicated job of a JobSet.
+type PodSetPlan struct {
+	Name        string
+	Pods        int
+	PodRequests string // Requests of each pod, e.g. "cpu=4, nvidia.com/gpu=1".
+	Placements  []NodePlacement
+	// Unplaced is the number of pods that fit on no node, and Reasons why,
+	// as counts of nodes per reason.
+	Unplaced int
+	Reasons  []string
+}
+
+// PlacementPlan is the simulated placement of a workload on the current nodes
+// of a cluster, reported by 'gcluster job plan'.
+type PlacementPlan struct {
+	Workload string
+	Kind     string
+	PodSets  []PodSetPlan
+	Notes    []string
+}
+
+// RedactedValue replaces secret values in support bundles.
+const RedactedValue = "<redacted>"
+
+var sensitiveNamePattern = regexp.MustCompile(`(?i)(token|secret|passw(or)?d|credential|api_?key|private_?key)`)
+
+// IsSensitiveName reports whether an env var or flag name suggests its value is a secret.
+func IsSensitiveName(name string) bool {
+	return sensitiveNamePattern.MatchString(name)
+}
+
+// Interactive development session modes.
+const (
+	DevModeJupyter = "jupyter"
+	DevModeSSH     = "ssh"
+)
+
+// DevSessionDefinition describes a long-lived interactive pod started by 'gcluster job dev'.
+type DevSessionDefinition struct {
+	Job                JobDefinition // Image, compute and placement of the session pod.
+	Mode               string        // DevModeJupyter or DevModeSSH.
+	IdleTimeoutSeconds int           // The pod exits after this long without activity.
+	LocalPort          int           // Local port forwarded to the session; 0 uses the server port.
+	SSHPublicKey       string        // Authorized key for DevModeSSH.
+}
+
+// SubmitResult describes a workload applied by SubmitJob.
+type SubmitResult struct {
+	WorkloadName string `json:"workload_name"`
+	Namespace    string `json:"namespace,omitempty"`
+	ClusterName  string `json:"cluster"`
+	Location     string `json:"location"`
+	ProjectID    string `json:"project"`
+	RunID        string `json:"run_id"`
+	ComputeType  string `json:"compute_type,omitempty"`
+	Image        string `json:"image,omitempty"`
+	ImageDigest  string `json:"image_digest,omitempty"` // Empty if the registry could not be reached.
+	WorkloadURL  string `json:"workload_url,omitempty"` // Cloud Console page of the workload.
+	LogsURL      string `json:"logs_url,omitempty"`     // Cloud Console Logs Explorer query of its containers.
+	Manifest     string `json:"manifest,omitempty"`     // Path the manifest was written to instead of being applied.
+}
+
 // JobOrchestrator defines the interface to interact with job orchestrators like GKE.
+// Every call runs under the ctx of the command that made it and stops when
+// that ctx is canceled.
 type JobOrchestrator interface {
-	SubmitJob(job JobDefinition) error
-	ListJobs(opts ListOptions) ([]JobStatus, error)
-	CancelJob(name string, opts CancelOptions) error
-	GetJobLogs(name string, opts LogsOptions) (string, error)
-	InspectCluster(opts InspectOptions) error
+	// SubmitJob builds the image of job and submits its workload. A
+	// submission that is canceled through ctx stops and cleans up what it
+	// partially applied.
+	SubmitJob(ctx context.Context, job JobDefinition) (SubmitResult, error)
+	ListJobs(ctx context.Context, opts ListOptions) ([]JobStatus, error)
+	CancelJob(ctx context.Context, name string, opts CancelOptions) error
+	GetJobLogs(ctx context.Context, name string, opts LogsOptions) (string, error)
+	// AttachJob streams the logs of a workload until it finishes, and
+	// returns its final status.
+	AttachJob(ctx context.Context, name string, opts AttachOptions) (string, error)
+	GetJobStatus(ctx context.Context, name string, opts StatusOptions) (JobStatusDetail, error)
+	// WaitJob polls a workload until it reaches opts.Until, cannot reach it
+	// anymore, or opts.Timeout passes.
+	WaitJob(ctx context.Context, name string, opts WaitOptions) (WaitResult, error)
+	InspectCluster(ctx context.Context, opts InspectOptions) error
+	CreateBundle(ctx context.Context, name string, opts BundleOptions) (string, error)
+	// ReportJob assembles the metadata, configuration, duration, requested
+	// resources, log excerpts and links of a workload into a RunReport.
+	ReportJob(ctx context.Context, name string, opts ReportOptions) (RunReport, error)
+	// DescribeJob explains why a workload is pending or failing from its
+	// JobSet and Kueue conditions and the events of its pods.
+	DescribeJob(ctx context.Context, name string, opts DescribeOptions) (WorkloadDescription, error)
+	EstimateAdmission(ctx context.Context, name string, opts EtaOptions) (AdmissionEstimate, error)
+	// PlanPlacement simulates the scheduling of a workload manifest on the
+	// current nodes of the cluster.
+	PlanPlacement(ctx context.Context, opts PlanOptions) (PlacementPlan, error)
+	StartDevSession(ctx context.Context, def DevSessionDefinition) error
+	// Impersonate makes the following calls act as principal in read-only
+	// mode: they report what the principal is allowed to do without making
+	// changes.
+	Impersonate(principal string)
+}
+
+// BootstrapOptions selects the cluster whose queueing components are
+// installed by a bootstrap.
+type BootstrapOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	// KueueVersion is the Kueue release to install; empty for the default.
+	KueueVersion string
+	// LocalQueue is the LocalQueue in the default namespace that workloads
+	// are submitted to.
+	LocalQueue string
+}
+
+// BootstrapResult describes the queueing setup of a bootstrapped cluster.
+type BootstrapResult struct {
+	KueueVersion    string
+	ClusterQueue    string
+	LocalQueue      string
+	ResourceFlavors []string
+	// CreatedQueues is set when the queues were created by the bootstrap
+	// rather than found on the cluster.
+	CreatedQueues bool
+}
+
+// Actions an upgrade of the cluster components takes for a component.
+const (
+	// ComponentActionNone leaves a component that is at or above the
+	// version gcluster is tested with, or that GKE manages.
+	ComponentActionNone = "none"
+	// ComponentActionUpgrade upgrades a component in place.
+	ComponentActionUpgrade = "upgrade"
+	// ComponentActionManual marks a component that needs a change gcluster
+	// does not make on its own; the note of the component explains it.
+	ComponentActionManual = "manual"
+)
+
+// ComponentsUpgradeOptions selects the cluster whose components are
+// upgraded.
+type ComponentsUpgradeOptions struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	// DryRun only inventories the components and plans their upgrades.
+	DryRun bool
+}
+
+// ComponentStatus describes a cluster component gcluster depends on.
+type ComponentStatus struct {
+	Name string
+	// Version is the installed version, "enabled" or "disabled" for GKE
+	// add-ons, or empty when the component is missing.
+	Version string
+	// Tested is the version gcluster is tested with, or empty for
+	// components whose version GKE manages.
+	Tested  string
+	Healthy bool
+	Action  string
+	Note    string
+}
+
+// ComponentsUpgradeResult describes the components of a cluster, in the order
+// they are upgraded, and the ones an upgrade changed.
+type ComponentsUpgradeResult struct {
+	Components []ComponentStatus
+	Upgraded   []string
+}
+
+// GCOptions selects the workloads removed by a retention sweep of the
+// clusters of a project.
+type GCOptions struct {
+	ProjectIDs []string
+	// OlderThan is the retention of finished workloads, counted from the time
+	// they completed or failed.
+	OlderThan time.Duration
+	// DryRun reports the expired workloads or outputs without deleting them.
+	DryRun bool
+}
+
+// ExpiredOutput is an output or checkpoint entry past the retention rule
+// recorded for its location.
+type ExpiredOutput struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	Namespace       string
+	Rule            RetentionRule
+	// Entry is the gs:// URI, or the path on the volume, of the entry.
+	Entry      string
+	ModifiedAt time.Time
+	Deleted    bool
+	// Error is why the entry could not be deleted.
+	Error string
+}
+
+// ExpiredWorkload is a finished workload past the retention of a sweep.
+type ExpiredWorkload struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	Namespace       string
+	Name            string
+	Status          string
+	FinishedAt      time.Time
+	Deleted         bool
+	// Error is why the workload could not be deleted.
+	Error string
+}
+
+// FleetStatusOptions selects the clusters and workloads of a fleet status.
+type FleetStatusOptions struct {
+	ProjectIDs []string
+	// Parallelism is the number of clusters queried at a time.
+	Parallelism int
+	// Filters
+	Status       string
+	NameContains string
+}
+
+// FleetWorkload is a gcluster workload of one of the clusters of a fleet.
+type FleetWorkload struct {
+	ProjectID       string
+	ClusterName     string
+	ClusterLocation string
+	JobStatus
 }
 
 type ClusterStatus struct {
@@ -166,8 +1179,8 @@ type VolumeStatus struct {
 }
 
 type ClusterOrchestrator interface {
-	ListEnvironments(opts ListOptions) ([]ClusterStatus, error)
-	GetClusterInfo(name string, opts ListOptions) (string, error)
-	DescribeEnvironment(name string, opts ListOptions) (string, error)
-	ListVolumes(opts ListOptions) ([]VolumeStatus, error)
+	ListEnvironments(ctx context.Context, opts ListOptions) ([]ClusterStatus, error)
+	GetClusterInfo(ctx context.Context, name string, opts ListOptions) (string, error)
+	DescribeEnvironment(ctx context.Context, name string, opts ListOptions) (string, error)
+	ListVolumes(ctx context.Context, opts ListOptions) ([]VolumeStatus, error)
 }
@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    SecretRef
+		wantErr string
+	}{
+		{ref: "sm://projects/p/secrets/wandb/versions/latest", want: SecretRef{Project: "p", Secret: "wandb", Version: "latest"}},
+		{ref: "sm://projects/p/secrets/wandb/versions/3", want: SecretRef{Project: "p", Secret: "wandb", Version: "3"}},
+		{ref: "sm://projects/p/secrets/wandb", want: SecretRef{Project: "p", Secret: "wandb", Version: "latest"}},
+		{ref: "sm://wandb", want: SecretRef{Secret: "wandb", Version: "latest"}},
+		{ref: "sm://wandb/versions/2", want: SecretRef{Secret: "wandb", Version: "2"}},
+		{ref: "projects/p/secrets/wandb", wantErr: "must start with sm://"},
+		{ref: "sm://projects/p/wandb", wantErr: "expected sm://projects/<project>/secrets/<secret>"},
+		{ref: "sm://projects/p/secrets/", wantErr: "not a valid secret name"},
+		{ref: "sm://wandb/versions/v1", wantErr: "version must be latest or a version number"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.ref, func(t *testing.T) {
+			got, err := ParseSecretRef(tc.ref)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("ParseSecretRef() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+	if got := (SecretRef{Project: "p", Secret: "wandb", Version: "latest"}).String(); got != "projects/p/secrets/wandb/versions/latest" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"strconv"
+	"time"
+
+	"hpc-toolkit/pkg/shell"
+)
+
+// Environment variables describing the run, set in every workload container so
+// that training scripts can tag their logs and artifacts.
+const (
+	EnvWorkloadName = "GCLUSTER_WORKLOAD_NAME"
+	EnvRunID        = "GCLUSTER_RUN_ID"
+	EnvNumSlices    = "GCLUSTER_NUM_SLICES"
+	EnvVMsPerSlice  = "GCLUSTER_VMS_PER_SLICE"
+	EnvSubmitTime   = "GCLUSTER_SUBMIT_TIME"
+)
+
+// AssignRunMetadata sets the run ID and submission time of job, unless the
+// caller already did.
+func AssignRunMetadata(job *JobDefinition) error {
+	if job.RunID == "" {
+		id, err := shell.RandomString(12)
+		if err != nil {
+			return err
+		}
+		job.RunID = id
+	}
+	if job.SubmitTime.IsZero() {
+		job.SubmitTime = time.Now().UTC()
+	}
+	return nil
+}
+
+// RunMetadataEnv returns the environment of the workload containers: the run
+// metadata, overridden by the variables set with --env.
+func RunMetadataEnv(job JobDefinition) map[string]string {
+	env := map[string]string{
+		EnvWorkloadName: job.WorkloadName,
+		EnvNumSlices:    strconv.Itoa(job.NumSlices),
+		EnvVMsPerSlice:  strconv.Itoa(job.NodesPerSlice),
+	}
+	if job.RunID != "" {
+		env[EnvRunID] = job.RunID
+	}
+	if !job.SubmitTime.IsZero() {
+		env[EnvSubmitTime] = job.SubmitTime.UTC().Format(time.RFC3339)
+	}
+	for k, v := range job.Env {
+		env[k] = v
+	}
+	return env
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAssignRunMetadata(t *testing.T) {
+	job := JobDefinition{}
+	if err := AssignRunMetadata(&job); err != nil {
+		t.Fatalf("AssignRunMetadata failed: %v", err)
+	}
+	if len(job.RunID) != 12 {
+		t.Errorf("expected a 12 character run ID, got %q", job.RunID)
+	}
+	if job.SubmitTime.IsZero() || job.SubmitTime.Location() != time.UTC {
+		t.Errorf("expected the submit time to be set in UTC, got %v", job.SubmitTime)
+	}
+
+	submitted := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	job = JobDefinition{RunID: "preset", SubmitTime: submitted}
+	if err := AssignRunMetadata(&job); err != nil {
+		t.Fatalf("AssignRunMetadata failed: %v", err)
+	}
+	if job.RunID != "preset" || !job.SubmitTime.Equal(submitted) {
+		t.Errorf("expected the run metadata to be kept, got %q at %v", job.RunID, job.SubmitTime)
+	}
+}
+
+func TestRunMetadataEnv(t *testing.T) {
+	job := JobDefinition{
+		WorkloadName:  "my-job",
+		RunID:         "0123456789ab",
+		SubmitTime:    time.Date(2026, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600)),
+		NumSlices:     2,
+		NodesPerSlice: 4,
+		Env:           map[string]string{"FOO": "bar", EnvWorkloadName: "custom"},
+	}
+	want := map[string]string{
+		"GCLUSTER_WORKLOAD_NAME": "custom",
+		"GCLUSTER_RUN_ID":        "0123456789ab",
+		"GCLUSTER_NUM_SLICES":    "2",
+		"GCLUSTER_VMS_PER_SLICE": "4",
+		"GCLUSTER_SUBMIT_TIME":   "2026-03-01T11:30:00Z",
+		"FOO":                    "bar",
+	}
+	if got := RunMetadataEnv(job); !reflect.DeepEqual(got, want) {
+		t.Errorf("RunMetadataEnv() = %v, want %v", got, want)
+	}
+	if _, ok := job.Env[EnvRunID]; ok {
+		t.Error("expected the job's --env map to be left unchanged")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vertex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/orchestrator/hosted"
+)
+
+// customJobSpec is the CustomJobSpec of a Vertex AI custom training job, in
+// the form 'gcloud ai custom-jobs create --config' reads it.
+type customJobSpec struct {
+	WorkerPoolSpecs []workerPoolSpec `json:"workerPoolSpecs"`
+	Scheduling      *scheduling      `json:"scheduling,omitempty"`
+	ServiceAccount  string           `json:"serviceAccount,omitempty"`
+}
+
+type workerPoolSpec struct {
+	MachineSpec   machineSpec   `json:"machineSpec"`
+	ReplicaCount  int           `json:"replicaCount"`
+	ContainerSpec containerSpec `json:"containerSpec"`
+}
+
+type machineSpec struct {
+	MachineType      string `json:"machineType"`
+	AcceleratorType  string `json:"acceleratorType,omitempty"`
+	AcceleratorCount int    `json:"acceleratorCount,omitempty"`
+	TPUTopology      string `json:"tpuTopology,omitempty"`
+}
+
+type containerSpec struct {
+	ImageURI string   `json:"imageUri"`
+	Command  []string `json:"command,omitempty"`
+	Args     []string `json:"args,omitempty"`
+	Env      []envVar `json:"env,omitempty"`
+}
+
+type envVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type scheduling struct {
+	Strategy                  string `json:"strategy,omitempty"`
+	RestartJobOnWorkerRestart bool   `json:"restartJobOnWorkerRestart,omitempty"`
+}
+
+// acceleratorTypes maps Compute Engine GPU accelerator types to those of
+// Vertex AI machine specs.
+var acceleratorTypes = map[string]string{
+	"nvidia-l4":             "NVIDIA_L4",
+	"nvidia-tesla-a100":     "NVIDIA_TESLA_A100",
+	"nvidia-a100-80gb":      "NVIDIA_A100_80GB",
+	"nvidia-h100-80gb":      "NVIDIA_H100_80GB",
+	"nvidia-h100-mega-80gb": "NVIDIA_H100_MEGA_80GB",
+	"nvidia-h200-141gb":     "NVIDIA_H200_141GB",
+	"nvidia-b200":           "NVIDIA_B200",
+	"nvidia-gb200":          "NVIDIA_GB200",
+	"nvidia-rtx-pro-6000":   "NVIDIA_RTX_PRO_6000",
+}
+
+// resolveMachineSpec maps the compute type of job, an accelerator shorthand
+// such as h100-80gb-8 or a machine type, to a Vertex AI machine spec.
+func resolveMachineSpec(job orchestrator.JobDefinition) (machineSpec, error) {
+	if job.ComputeType == "" {
+		return machineSpec{}, fmt.Errorf("--compute-type is required with the vertex backend, e.g. h100-80gb-8 or n2-standard-32")
+	}
+	spec := machineSpec{MachineType: config.ResolveMachineType(job.ComputeType)}
+	if config.IsTPU(spec.MachineType) {
+		spec.TPUTopology = job.Topology
+		return spec, nil
+	}
+
+	label, count, err := hosted.GPUs(spec.MachineType, job.GPUsPerPod)
+	if err != nil {
+		return machineSpec{}, err
+	}
+	if label == "" {
+		return spec, nil
+	}
+	accelType, ok := acceleratorTypes[label]
+	if !ok {
+		return machineSpec{}, fmt.Errorf("accelerator %s of machine type %s is not supported by the vertex backend", label, spec.MachineType)
+	}
+	spec.AcceleratorType, spec.AcceleratorCount = accelType, count
+	return spec, nil
+}
+
+// buildCustomJobSpec translates job into the spec of a custom training job.
+// The first replica runs in a pool of its own, the chief of Vertex AI
+// distributed training; the other NumSlices*NodesPerSlice-1 replicas run in a
+// second pool with the same machine and container. A TPU job runs as one
+// replica on a slice of its topology.
+func buildCustomJobSpec(job orchestrator.JobDefinition, image string) (customJobSpec, error) {
+	machine, err := resolveMachineSpec(job)
+	if err != nil {
+		return customJobSpec{}, err
+	}
+	replicas := max(job.NumSlices, 1) * max(job.NodesPerSlice, 1)
+	if config.IsTPU(machine.MachineType) {
+		if job.NumSlices > 1 {
+			return customJobSpec{}, fmt.Errorf("--num-slices %d is not supported with TPUs by the vertex backend, which runs a single slice", job.NumSlices)
+		}
+		replicas = 1
+	}
+
+	container := containerSpec{ImageURI: image, Env: containerEnv(job)}
+	container.Command, container.Args = hosted.ContainerCommand(job.CommandToRun, job.CommandArgs)
+
+	spec := customJobSpec{
+		WorkerPoolSpecs: []workerPoolSpec{{MachineSpec: machine, ReplicaCount: 1, ContainerSpec: container}},
+	}
+	if replicas > 1 {
+		spec.WorkerPoolSpecs = append(spec.WorkerPoolSpecs, workerPoolSpec{MachineSpec: machine, ReplicaCount: replicas - 1, ContainerSpec: container})
+	}
+	if job.Spot || job.MaxRestarts > 0 {
+		spec.Scheduling = &scheduling{RestartJobOnWorkerRestart: job.MaxRestarts > 0}
+		if job.Spot {
+			spec.Scheduling.Strategy = "SPOT"
+		}
+	}
+	if strings.Contains(job.ServiceAccountName, "@") {
+		spec.ServiceAccount = job.ServiceAccountName
+	}
+	return spec, nil
+}
+
+// containerEnv returns the run metadata and --env variables in a stable order.
+func containerEnv(job orchestrator.JobDefinition) []envVar {
+	env := orchestrator.RunMetadataEnv(job)
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	vars := make([]envVar, 0, len(names))
+	for _, name := range names {
+		vars = append(vars, envVar{Name: name, Value: env[name]})
+	}
+	return vars
+}
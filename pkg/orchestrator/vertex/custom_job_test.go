@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vertex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/orchestrator"
+)
+
+func TestResolveMachineSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     orchestrator.JobDefinition
+		want    machineSpec
+		wantErr string
+	}{
+		{
+			name: "GPU shorthand",
+			job:  orchestrator.JobDefinition{ComputeType: "h100-80gb-8"},
+			want: machineSpec{MachineType: "a3-highgpu-8g", AcceleratorType: "NVIDIA_H100_80GB", AcceleratorCount: 8},
+		},
+		{
+			name: "GPU machine type",
+			job:  orchestrator.JobDefinition{ComputeType: "g2-standard-24"},
+			want: machineSpec{MachineType: "g2-standard-24", AcceleratorType: "NVIDIA_L4", AcceleratorCount: 2},
+		},
+		{
+			name: "GPUs per pod",
+			job:  orchestrator.JobDefinition{ComputeType: "a2-highgpu-8g", GPUsPerPod: 4},
+			want: machineSpec{MachineType: "a2-highgpu-8g", AcceleratorType: "NVIDIA_TESLA_A100", AcceleratorCount: 4},
+		},
+		{
+			name: "CPU machine type",
+			job:  orchestrator.JobDefinition{ComputeType: "n2-standard-32"},
+			want: machineSpec{MachineType: "n2-standard-32"},
+		},
+		{
+			name: "TPU",
+			job:  orchestrator.JobDefinition{ComputeType: "v5litepod-8", Topology: "2x4"},
+			want: machineSpec{MachineType: "ct5lp-hightpu-8t", TPUTopology: "2x4"},
+		},
+		{
+			name:    "no compute type",
+			job:     orchestrator.JobDefinition{},
+			wantErr: "--compute-type is required",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveMachineSpec(tc.job)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("resolveMachineSpec() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveMachineSpec() failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("resolveMachineSpec() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildCustomJobSpec(t *testing.T) {
+	job := orchestrator.JobDefinition{
+		WorkloadName:       "train",
+		ComputeType:        "h100-80gb-8",
+		NumSlices:          2,
+		NodesPerSlice:      2,
+		MaxRestarts:        1,
+		Spot:               true,
+		CommandToRun:       "python train.py",
+		ServiceAccountName: "trainer@my-project.iam.gserviceaccount.com",
+		RunID:              "0123456789ab",
+		SubmitTime:         time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC),
+		Env:                map[string]string{"FOO": "bar"},
+	}
+	spec, err := buildCustomJobSpec(job, "us-docker.pkg.dev/p/r/train:v1")
+	if err != nil {
+		t.Fatalf("buildCustomJobSpec() failed: %v", err)
+	}
+
+	if len(spec.WorkerPoolSpecs) != 2 {
+		t.Fatalf("expected a chief and a worker pool, got %d pools", len(spec.WorkerPoolSpecs))
+	}
+	if chief, workers := spec.WorkerPoolSpecs[0].ReplicaCount, spec.WorkerPoolSpecs[1].ReplicaCount; chief != 1 || workers != 3 {
+		t.Errorf("expected 1 chief and 3 workers, got %d and %d", chief, workers)
+	}
+	container := spec.WorkerPoolSpecs[1].ContainerSpec
+	if container.ImageURI != "us-docker.pkg.dev/p/r/train:v1" {
+		t.Errorf("unexpected image %q", container.ImageURI)
+	}
+	if want := []string{"/bin/bash", "-c", "python train.py"}; !reflect.DeepEqual(container.Command, want) {
+		t.Errorf("command = %q, want %q", container.Command, want)
+	}
+	wantEnv := []envVar{
+		{"FOO", "bar"},
+		{"GCLUSTER_NUM_SLICES", "2"},
+		{"GCLUSTER_RUN_ID", "0123456789ab"},
+		{"GCLUSTER_SUBMIT_TIME", "2026-03-01T12:30:00Z"},
+		{"GCLUSTER_VMS_PER_SLICE", "2"},
+		{"GCLUSTER_WORKLOAD_NAME", "train"},
+	}
+	if !reflect.DeepEqual(container.Env, wantEnv) {
+		t.Errorf("env = %v, want %v", container.Env, wantEnv)
+	}
+	if want := (&scheduling{Strategy: "SPOT", RestartJobOnWorkerRestart: true}); !reflect.DeepEqual(spec.Scheduling, want) {
+		t.Errorf("scheduling = %+v, want %+v", spec.Scheduling, want)
+	}
+	if spec.ServiceAccount != job.ServiceAccountName {
+		t.Errorf("service account = %q, want %q", spec.ServiceAccount, job.ServiceAccountName)
+	}
+}
+
+func TestBuildCustomJobSpecSingleReplica(t *testing.T) {
+	job := orchestrator.JobDefinition{ComputeType: "n2-standard-8", NumSlices: 1, NodesPerSlice: 1, CommandArgs: []string{"python", "main.py"}, ServiceAccountName: "default"}
+	spec, err := buildCustomJobSpec(job, "img")
+	if err != nil {
+		t.Fatalf("buildCustomJobSpec() failed: %v", err)
+	}
+	if len(spec.WorkerPoolSpecs) != 1 {
+		t.Fatalf("expected only the chief pool, got %d pools", len(spec.WorkerPoolSpecs))
+	}
+	if c := spec.WorkerPoolSpecs[0].ContainerSpec; !reflect.DeepEqual(c.Command, []string{"python"}) || !reflect.DeepEqual(c.Args, []string{"main.py"}) {
+		t.Errorf("expected the exec-form command to be kept, got %q %q", c.Command, c.Args)
+	}
+	if spec.Scheduling != nil || spec.ServiceAccount != "" {
+		t.Errorf("expected no scheduling or service account, got %+v and %q", spec.Scheduling, spec.ServiceAccount)
+	}
+}
+
+func TestBuildCustomJobSpecRejectsTPUMultislice(t *testing.T) {
+	job := orchestrator.JobDefinition{ComputeType: "v5litepod-8", NumSlices: 2}
+	if _, err := buildCustomJobSpec(job, "img"); err == nil || !strings.Contains(err.Error(), "--num-slices") {
+		t.Errorf("expected a --num-slices error, got %v", err)
+	}
+}
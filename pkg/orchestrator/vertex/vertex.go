@@ -0,0 +1,250 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vertex runs gcluster workloads as Vertex AI custom training jobs,
+// for users without a GKE cluster.
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/orchestrator/hosted"
+	"hpc-toolkit/pkg/shell"
+
+	"sigs.k8s.io/yaml"
+)
+
+// stdoutManifestPath as a DryRunManifest prints the spec.
+const stdoutManifestPath = "-"
+
+// dryRunOut receives the spec printed by a plain --dry-run.
+var dryRunOut io.Writer = os.Stdout
+
+// errUnsupported is returned by the operations of the JobOrchestrator
+// interface that only the GKE backend implements.
+var errUnsupported = errors.New("not supported by the vertex backend; manage Vertex AI custom jobs with 'gcloud ai custom-jobs' or in the Cloud Console")
+
+// Executor runs the gcloud commands of the orchestrator.
+type Executor interface {
+	ExecuteCommand(name string, args ...string) shell.CommandResult
+}
+
+type defaultExecutor struct{}
+
+func (defaultExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	return shell.ExecuteCommand(name, args...)
+}
+
+// VertexOrchestrator submits workloads as Vertex AI custom training jobs in
+// the region of the job's location.
+type VertexOrchestrator struct {
+	executor Executor
+}
+
+var _ orchestrator.JobOrchestrator = (*VertexOrchestrator)(nil)
+
+func NewVertexOrchestrator() *VertexOrchestrator {
+	return &VertexOrchestrator{executor: defaultExecutor{}}
+}
+
+func (v *VertexOrchestrator) SetExecutor(e Executor) {
+	v.executor = e
+}
+
+// SubmitJob builds the image of job, unless it names a pre-built one, and
+// creates a custom job running it. A dry run prints or writes the spec of the
+// custom job instead.
+func (v *VertexOrchestrator) SubmitJob(ctx context.Context, job orchestrator.JobDefinition) (orchestrator.SubmitResult, error) {
+	logging.Info("Starting gcluster job submit workflow for Vertex AI...")
+	if err := hosted.ValidateJob(job, "vertex", "Vertex AI custom job"); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if err := orchestrator.AssignRunMetadata(&job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	if job.BuildProjectID == "" {
+		job.BuildProjectID = job.ClusterProjectID
+	}
+	if err := hosted.EnforceManagedProfile(job); err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	region := shell.ExtractRegion(job.ClusterLocation)
+
+	image, err := hosted.BuildImage(ctx, job)
+	if err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	spec, err := buildCustomJobSpec(job, image)
+	if err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return orchestrator.SubmitResult{}, fmt.Errorf("failed to render the custom job spec: %w", err)
+	}
+
+	result := orchestrator.SubmitResult{
+		WorkloadName: job.WorkloadName,
+		Location:     region,
+		ProjectID:    job.ClusterProjectID,
+		RunID:        job.RunID,
+		ComputeType:  job.ComputeType,
+		Image:        image,
+		Manifest:     job.DryRunManifest,
+	}
+	if job.IsDryRun() {
+		return result, writeDryRunSpec(job.DryRunManifest, data)
+	}
+	if err := ctx.Err(); err != nil {
+		return orchestrator.SubmitResult{}, fmt.Errorf("job submission canceled: %w", err)
+	}
+
+	id, err := v.createCustomJob(job, region, data)
+	if err != nil {
+		return orchestrator.SubmitResult{}, err
+	}
+	result.WorkloadURL, result.LogsURL = consoleLinks(job.ClusterProjectID, region, id)
+	logging.Info("Follow your custom job here: %s", result.WorkloadURL)
+	logging.Info("View its logs here: %s", result.LogsURL)
+	logging.Info("gcluster job submit workflow completed.")
+	return result, nil
+}
+
+// createCustomJob creates the custom job of spec and returns its ID.
+func (v *VertexOrchestrator) createCustomJob(job orchestrator.JobDefinition, region string, spec []byte) (string, error) {
+	dir, err := os.MkdirTemp("", "gcluster-vertex-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a directory for the custom job spec: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "custom_job.yaml")
+	if err := os.WriteFile(configPath, spec, 0644); err != nil {
+		return "", fmt.Errorf("failed to write the custom job spec: %w", err)
+	}
+
+	args := []string{"ai", "custom-jobs", "create",
+		"--project", job.ClusterProjectID,
+		"--region", region,
+		"--display-name", job.WorkloadName,
+		"--config", configPath,
+		"--format=value(name)"}
+	if labels := jobLabels(job); labels != "" {
+		args = append(args, "--labels", labels)
+	}
+	logging.Info("Creating Vertex AI custom job '%s' in %s...", job.WorkloadName, region)
+	res := v.executor.ExecuteCommand("gcloud", args...)
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("failed to create Vertex AI custom job: %s\n%s", res.Stderr, res.Stdout)
+	}
+	name := strings.TrimSpace(res.Stdout)
+	return name[strings.LastIndex(name, "/")+1:], nil
+}
+
+// jobLabels renders the cost allocation labels of job for --labels.
+func jobLabels(job orchestrator.JobDefinition) string {
+	var labels []string
+	for k, v := range job.CostLabels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ",")
+}
+
+// consoleLinks returns the Cloud Console links of a custom job and of its logs.
+func consoleLinks(project, region, id string) (jobLink, logsLink string) {
+	jobLink = fmt.Sprintf("https://console.cloud.google.com/vertex-ai/locations/%s/training/%s/cpu?project=%s", region, id, project)
+	query := fmt.Sprintf(`resource.type="ml_job"
+resource.labels.job_id="%s"`, id)
+	logsLink = fmt.Sprintf("https://console.cloud.google.com/logs/query;query=%s;storageScope=project;duration=P1D?project=%s",
+		url.QueryEscape(query), project)
+	return jobLink, logsLink
+}
+
+func writeDryRunSpec(path string, data []byte) error {
+	if path == "" || path == stdoutManifestPath {
+		_, err := dryRunOut.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write the custom job spec to %s: %w", path, err)
+	}
+	logging.Info("[Dry Run] Custom job spec written to %s.", path)
+	return nil
+}
+
+func (v *VertexOrchestrator) ListJobs(context.Context, orchestrator.ListOptions) ([]orchestrator.JobStatus, error) {
+	return nil, fmt.Errorf("listing workloads is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) CancelJob(context.Context, string, orchestrator.CancelOptions) error {
+	return fmt.Errorf("canceling workloads is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) GetJobLogs(context.Context, string, orchestrator.LogsOptions) (string, error) {
+	return "", fmt.Errorf("reading logs is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) AttachJob(context.Context, string, orchestrator.AttachOptions) (string, error) {
+	return "", fmt.Errorf("attaching to workloads is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) GetJobStatus(context.Context, string, orchestrator.StatusOptions) (orchestrator.JobStatusDetail, error) {
+	return orchestrator.JobStatusDetail{}, fmt.Errorf("reading the status of workloads is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) WaitJob(context.Context, string, orchestrator.WaitOptions) (orchestrator.WaitResult, error) {
+	return orchestrator.WaitResult{}, fmt.Errorf("waiting for workloads is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) InspectCluster(context.Context, orchestrator.InspectOptions) error {
+	return fmt.Errorf("inspecting clusters is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) CreateBundle(context.Context, string, orchestrator.BundleOptions) (string, error) {
+	return "", fmt.Errorf("support bundles are %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) ReportJob(context.Context, string, orchestrator.ReportOptions) (orchestrator.RunReport, error) {
+	return orchestrator.RunReport{}, fmt.Errorf("run reports are %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) DescribeJob(context.Context, string, orchestrator.DescribeOptions) (orchestrator.WorkloadDescription, error) {
+	return orchestrator.WorkloadDescription{}, fmt.Errorf("describing workloads is %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) EstimateAdmission(context.Context, string, orchestrator.EtaOptions) (orchestrator.AdmissionEstimate, error) {
+	return orchestrator.AdmissionEstimate{}, fmt.Errorf("admission estimates are %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) PlanPlacement(context.Context, orchestrator.PlanOptions) (orchestrator.PlacementPlan, error) {
+	return orchestrator.PlacementPlan{}, fmt.Errorf("placement plans are %w", errUnsupported)
+}
+
+func (v *VertexOrchestrator) StartDevSession(context.Context, orchestrator.DevSessionDefinition) error {
+	return fmt.Errorf("dev sessions are %w", errUnsupported)
+}
+
+// Impersonate is a no-op: the vertex backend makes no read-only checks, so
+// callers reject --as with it.
+func (v *VertexOrchestrator) Impersonate(string) {}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vertex
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"hpc-toolkit/pkg/orchestrator"
+	"hpc-toolkit/pkg/shell"
+)
+
+type mockExecutor struct {
+	calls  [][]string
+	config string
+	result shell.CommandResult
+}
+
+func (m *mockExecutor) ExecuteCommand(name string, args ...string) shell.CommandResult {
+	m.calls = append(m.calls, append([]string{name}, args...))
+	for i, arg := range args {
+		if arg == "--config" && i+1 < len(args) {
+			data, _ := os.ReadFile(args[i+1])
+			m.config = string(data)
+		}
+	}
+	return m.result
+}
+
+func testJob() orchestrator.JobDefinition {
+	return orchestrator.JobDefinition{
+		ImageName:        "us-docker.pkg.dev/p/r/train:v1",
+		ComputeType:      "l4-1",
+		CommandToRun:     "python train.py",
+		ClusterProjectID: "my-project",
+		ClusterLocation:  "us-central1-a",
+		WorkloadName:     "train",
+		NumSlices:        1,
+		NodesPerSlice:    1,
+		CostLabels:       map[string]string{"team": "ml"},
+	}
+}
+
+func TestSubmitJob(t *testing.T) {
+	exec := &mockExecutor{result: shell.CommandResult{Stdout: "projects/123/locations/us-central1/customJobs/456\n"}}
+	v := NewVertexOrchestrator()
+	v.SetExecutor(exec)
+
+	result, err := v.SubmitJob(context.Background(), testJob())
+	if err != nil {
+		t.Fatalf("SubmitJob() failed: %v", err)
+	}
+
+	if len(exec.calls) != 1 {
+		t.Fatalf("expected one gcloud call, got %v", exec.calls)
+	}
+	cmd := strings.Join(exec.calls[0], " ")
+	for _, want := range []string{"gcloud ai custom-jobs create", "--project my-project", "--region us-central1", "--display-name train", "--labels team=ml"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("expected %q in %q", want, cmd)
+		}
+	}
+	for _, want := range []string{"machineType: g2-standard-12", "acceleratorType: NVIDIA_L4", "imageUri: us-docker.pkg.dev/p/r/train:v1"} {
+		if !strings.Contains(exec.config, want) {
+			t.Errorf("expected %q in the custom job spec:\n%s", want, exec.config)
+		}
+	}
+	if result.Location != "us-central1" || result.RunID == "" || result.ClusterName != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.Contains(result.WorkloadURL, "/vertex-ai/locations/us-central1/training/456/") {
+		t.Errorf("unexpected workload URL %q", result.WorkloadURL)
+	}
+}
+
+func TestSubmitJobDryRun(t *testing.T) {
+	var out bytes.Buffer
+	prev := dryRunOut
+	dryRunOut = &out
+	defer func() { dryRunOut = prev }()
+
+	exec := &mockExecutor{}
+	v := NewVertexOrchestrator()
+	v.SetExecutor(exec)
+	job := testJob()
+	job.DryRun = true
+
+	if _, err := v.SubmitJob(context.Background(), job); err != nil {
+		t.Fatalf("SubmitJob() failed: %v", err)
+	}
+	if len(exec.calls) != 0 {
+		t.Errorf("expected no gcloud calls in a dry run, got %v", exec.calls)
+	}
+	if !strings.Contains(out.String(), "workerPoolSpecs:") {
+		t.Errorf("expected the custom job spec to be printed, got:\n%s", out.String())
+	}
+}
+
+func TestSubmitJobFailure(t *testing.T) {
+	exec := &mockExecutor{result: shell.CommandResult{ExitCode: 1, Stderr: "PERMISSION_DENIED"}}
+	v := NewVertexOrchestrator()
+	v.SetExecutor(exec)
+	if _, err := v.SubmitJob(context.Background(), testJob()); err == nil || !strings.Contains(err.Error(), "PERMISSION_DENIED") {
+		t.Errorf("expected the gcloud error, got %v", err)
+	}
+}
+
+func TestSubmitJobEnforcesManagedProfile(t *testing.T) {
+	exec := &mockExecutor{}
+	v := NewVertexOrchestrator()
+	v.SetExecutor(exec)
+	job := testJob()
+	job.NodesPerSlice = 4
+	job.ManagedProfile = &orchestrator.ManagedProfile{Name: "ml-research", MaxGPUs: 2, AllowedRegistries: []string{"us-docker.pkg.dev/ml-research"}}
+
+	_, err := v.SubmitJob(context.Background(), job)
+	if err == nil || !strings.Contains(err.Error(), `managed profile "ml-research"`) {
+		t.Fatalf("expected the managed profile to be enforced, got %v", err)
+	}
+	for _, want := range []string{"requests 4 GPUs, more than the allowed 2", "image repository us-docker.pkg.dev/p/r/train is not allowed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if len(exec.calls) != 0 {
+		t.Errorf("expected nothing to be submitted, got %v", exec.calls)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retry calls external services, such as container registries, the
+// Kubernetes API and gcloud, again when they fail with a transient error. Waits
+// between calls grow exponentially and are jittered, so clients that failed
+// together do not retry together.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/logging"
+)
+
+// Policy describes how often and how fast a call is retried.
+type Policy struct {
+	// Attempts is the number of calls made, including the first one.
+	Attempts int
+	// Initial is the wait after the first failure. Each later wait is
+	// Multiplier times longer, up to Max.
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction of it.
+	Jitter float64
+	// Retryable reports whether an error is worth another call. Nil retries
+	// every error not marked with Permanent.
+	Retryable func(error) bool
+	// Clock waits between calls. Nil waits in real time and stops waiting as
+	// soon as the context is done.
+	Clock Clock
+}
+
+// Clock waits between calls; testutil.FakeClock returns immediately.
+type Clock interface {
+	Sleep(d time.Duration)
+}
+
+// Default is the policy for requests to Google Cloud, Kubernetes and
+// container registry APIs: five calls over about 15 seconds.
+var Default = Policy{
+	Attempts:   5,
+	Initial:    time.Second,
+	Max:        8 * time.Second,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// Backoff returns the wait after the given failed attempt, counted from 1,
+// before jitter.
+func (p Policy) Backoff(attempt int) time.Duration {
+	d := float64(p.Initial)
+	for i := 1; i < attempt; i++ {
+		d *= max(p.Multiplier, 1)
+		if p.Max > 0 && d >= float64(p.Max) {
+			return p.Max
+		}
+	}
+	return time.Duration(d)
+}
+
+func (p Policy) wait(attempt int) time.Duration {
+	d := p.Backoff(attempt)
+	if p.Jitter <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
+
+func (p Policy) sleep(ctx context.Context, d time.Duration) error {
+	if p.Clock != nil {
+		p.Clock.Sleep(d)
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; Do returns it at once.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, fails with an error that is permanent or not
+// retryable under p, uses up the attempts of p, or ctx is done. It returns the
+// last error of fn, without the Permanent mark.
+func Do(ctx context.Context, p Policy, fn func() error) error {
+	attempts := max(p.Attempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt >= attempts || (p.Retryable != nil && !p.Retryable(err)) {
+			return err
+		}
+		d := p.wait(attempt)
+		logging.Info("%v; retrying in %s (attempt %d of %d)", err, d.Round(time.Millisecond), attempt+1, attempts)
+		if werr := p.sleep(ctx, d); werr != nil {
+			return fmt.Errorf("%w (stopped retrying: %w)", err, werr)
+		}
+	}
+}
+
+// transientMessages are fragments of the errors printed by kubectl, gcloud and
+// Go HTTP clients when a request failed on the way and may succeed if sent
+// again.
+var transientMessages = []string{
+	"connection reset by peer",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"tls handshake timeout",
+	"timeout awaiting response headers",
+	"http2: client connection lost",
+	"unexpected eof",
+	"context deadline exceeded",
+	"etcdserver: request timed out",
+	"the server is currently unable to handle the request",
+	"service unavailable",
+	"bad gateway",
+	"gateway timeout",
+	"too many requests",
+	"ratelimitexceeded",
+	"rate limit exceeded",
+	"resource_exhausted",
+	"backenderror",
+	"internal error occurred",
+}
+
+// TransientMessage reports whether the error output of a failed command or
+// request points to a transient failure, such as a dropped connection, an
+// overloaded server or a rate limit.
+func TransientMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// TransientStatusCodes are the HTTP status codes of responses that may succeed
+// if the request is sent again.
+var TransientStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// TransientStatus reports whether code is one of TransientStatusCodes.
+func TransientStatus(code int) bool {
+	return slices.Contains(TransientStatusCodes, code)
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"hpc-toolkit/pkg/testutil"
+)
+
+func fakePolicy() (Policy, *testutil.FakeClock) {
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := Default
+	p.Jitter = 0
+	p.Clock = clock
+	return p, clock
+}
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	p, clock := fakePolicy()
+	calls := 0
+	err := Do(context.Background(), p, func() error {
+		calls++
+		if calls < 4 {
+			return errors.New("connection reset by peer")
+		}
+		return nil
+	})
+	if err != nil || calls != 4 {
+		t.Fatalf("Do() = %v after %d calls, want success after 4", err, calls)
+	}
+	if got, want := clock.Sleeps(), []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("waits = %v, want %v", got, want)
+	}
+}
+
+func TestDo_GivesUp(t *testing.T) {
+	p, clock := fakePolicy()
+	calls := 0
+	err := Do(context.Background(), p, func() error {
+		calls++
+		return errors.New("503 Service Unavailable")
+	})
+	if err == nil || err.Error() != "503 Service Unavailable" || calls != 5 {
+		t.Fatalf("Do() = %v after %d calls, want the last error after 5", err, calls)
+	}
+	if got, want := clock.Sleeps(), []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("waits = %v, want %v", got, want)
+	}
+}
+
+func TestDo_Permanent(t *testing.T) {
+	p, clock := fakePolicy()
+	notFound := errors.New("not found")
+	calls := 0
+	err := Do(context.Background(), p, func() error {
+		calls++
+		return Permanent(notFound)
+	})
+	if err != notFound || calls != 1 || len(clock.Sleeps()) != 0 {
+		t.Errorf("Do() = %v after %d calls, want the unwrapped error after 1", err, calls)
+	}
+
+	p.Retryable = func(err error) bool { return TransientMessage(err.Error()) }
+	calls = 0
+	if err := Do(context.Background(), p, func() error { calls++; return notFound }); err != notFound || calls != 1 {
+		t.Errorf("expected an error that is not retryable to be returned at once, got %v after %d calls", err, calls)
+	}
+}
+
+func TestDo_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Default
+	p.Initial = time.Hour
+	calls := 0
+	err := Do(ctx, p, func() error {
+		calls++
+		cancel()
+		return errors.New("i/o timeout")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("Do() = %v after %d calls, want to stop waiting once the context is canceled", err, calls)
+	}
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	p := Policy{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 3, Jitter: 0.5}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 300 * time.Millisecond, 3: 900 * time.Millisecond, 4: time.Second, 10: time.Second} {
+		if got := p.Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+		for i := 0; i < 20; i++ {
+			if d := p.wait(attempt); d < want/2 || d > want*3/2 {
+				t.Fatalf("wait(%d) = %s, want within 50%% of %s", attempt, d, want)
+			}
+		}
+	}
+}
+
+func TestTransient(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"Unable to connect to the server: dial tcp 10.0.0.1:443: i/o timeout":                          true,
+		"ERROR: (gcloud.container.clusters.describe) HTTPError 429: Too Many Requests":                 true,
+		"Error from server (ServiceUnavailable): the server is currently unable to handle the request": true,
+		`Error from server (NotFound): jobsets.jobset.x-k8s.io "train" not found`:                      false,
+		"ERROR: (gcloud.compute.instances.describe) PERMISSION_DENIED":                                 false,
+	} {
+		if got := TransientMessage(msg); got != want {
+			t.Errorf("TransientMessage(%q) = %v, want %v", msg, got, want)
+		}
+	}
+	if !TransientStatus(503) || !TransientStatus(429) || TransientStatus(404) || TransientStatus(200) {
+		t.Error("expected 429 and 5xx statuses to be transient and others not")
+	}
+}
@@ -19,13 +19,16 @@ package shell
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/rand"
 	"fmt"
 	"hpc-toolkit/pkg/config"
 	"hpc-toolkit/pkg/logging"
+	"hpc-toolkit/pkg/retry"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"golang.org/x/sys/unix"
@@ -59,6 +62,13 @@ func NewCommand(name string, args ...string) *Command {
 	return &Command{cmd: cmd}
 }
 
+// NewCommandContext creates a new Command instance that is killed when ctx is
+// done.
+func NewCommandContext(ctx context.Context, name string, args ...string) *Command {
+	cmd := exec.CommandContext(ctx, name, args...)
+	return &Command{cmd: cmd}
+}
+
 // SetInput sets the standard input for the command.
 func (c *Command) SetInput(input string) {
 	c.stdin.WriteString(input)
@@ -95,9 +105,66 @@ func (c *Command) Execute() CommandResult {
 
 // ExecuteCommand executes a shell command and returns its output and exit code.
 // It takes the command name as the first argument, followed by its arguments.
+// kubectl and gcloud commands that only read state are retried while they fail
+// with a transient error.
 var ExecuteCommand = func(name string, args ...string) CommandResult {
-	cmd := NewCommand(name, args...)
-	return cmd.Execute()
+	return ExecuteCommandContext(context.Background(), name, args...)
+}
+
+// ExecuteCommandContext is ExecuteCommand for a command that is killed, and
+// not retried any more, once ctx is done.
+func ExecuteCommandContext(ctx context.Context, name string, args ...string) CommandResult {
+	return executeWithRetry(ctx, name, args, func() CommandResult {
+		return NewCommandContext(ctx, name, args...).Execute()
+	})
+}
+
+// commandRetry is the retry policy of read-only commands.
+var commandRetry = retry.Default
+
+func executeWithRetry(ctx context.Context, name string, args []string, run func() CommandResult) CommandResult {
+	if !readOnlyCommand(name, args) {
+		return run()
+	}
+	var res CommandResult
+	_ = retry.Do(ctx, commandRetry, func() error {
+		res = run()
+		if res.ExitCode != 0 && retry.TransientMessage(res.Stderr) {
+			return fmt.Errorf("%s %s failed: %s", name, strings.Join(args[:min(len(args), 3)], " "), strings.TrimSpace(res.Stderr))
+		}
+		return nil
+	})
+	return res
+}
+
+// readOnlyVerbs are the kubectl and gcloud subcommands that only read state,
+// so running them again after a failure has no side effects.
+var readOnlyVerbs = map[string]map[string]bool{
+	"kubectl": {"get": true, "describe": true, "logs": true, "top": true, "auth": true, "version": true, "api-resources": true},
+	"gcloud":  {"describe": true, "list": true, "get-credentials": true, "get-value": true, "get-iam-policy": true, "ls": true, "cat": true},
+}
+
+// readOnlyCommand reports whether a kubectl or gcloud command only reads
+// state. kubectl names its verb first; gcloud names it after its command
+// groups, before the flags. gcloud ssh and scp run arbitrary commands, so
+// their arguments are never taken for a verb.
+func readOnlyCommand(name string, args []string) bool {
+	verbs := readOnlyVerbs[name]
+	if verbs == nil || len(args) == 0 {
+		return false
+	}
+	if name == "kubectl" {
+		return verbs[args[0]] && !slices.Contains(args, "-f") && !slices.Contains(args, "--follow")
+	}
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") || a == "ssh" || a == "scp" {
+			break
+		}
+		if verbs[a] {
+			return true
+		}
+	}
+	return false
 }
 
 // RandomString generates a random string of a given length.
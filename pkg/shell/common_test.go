@@ -17,9 +17,13 @@ limitations under the License.
 package shell
 
 import (
+	"context"
 	"hpc-toolkit/pkg/config"
+	"hpc-toolkit/pkg/testutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 )
@@ -166,3 +170,52 @@ func (s *MySuite) TestAskForConfirmation_No(c *C) {
 	got := PromptYesNo("Test prompt")
 	c.Assert(got, Equals, false)
 }
+
+func (s *MySuite) TestReadOnlyCommand(c *C) {
+	for cmd, want := range map[string]bool{
+		"kubectl get jobsets -A -o json":                              true,
+		"kubectl logs -n default -l app=train":                        true,
+		"kubectl logs -f -l app=train":                                false,
+		"kubectl apply -f jobset.yaml":                                false,
+		"gcloud container clusters describe c --location us-central1": true,
+		"gcloud storage ls --long gs://bucket/out":                    true,
+		"gcloud compute instances create vm --zone z":                 false,
+		"gcloud compute ssh list --command ls":                        false,
+		"bash -c ls":                                                  false,
+	} {
+		parts := strings.Fields(cmd)
+		c.Check(readOnlyCommand(parts[0], parts[1:]), Equals, want, Commentf("%s", cmd))
+	}
+}
+
+func (s *MySuite) TestExecuteWithRetry(c *C) {
+	orig := commandRetry
+	defer func() { commandRetry = orig }()
+	commandRetry.Clock = testutil.NewFakeClock(time.Now())
+
+	runs := 0
+	flaky := func() CommandResult {
+		runs++
+		if runs < 3 {
+			return CommandResult{ExitCode: 1, Stderr: "Unable to connect to the server: net/http: TLS handshake timeout"}
+		}
+		return CommandResult{Stdout: "ok"}
+	}
+	res := executeWithRetry(context.Background(), "kubectl", []string{"get", "pods"}, flaky)
+	c.Check(res, DeepEquals, CommandResult{Stdout: "ok"})
+	c.Check(runs, Equals, 3)
+
+	runs = 0
+	res = executeWithRetry(context.Background(), "kubectl", []string{"apply", "-f", "x.yaml"}, flaky)
+	c.Check(res.ExitCode, Equals, 1)
+	c.Check(runs, Equals, 1)
+
+	runs = 0
+	notFound := func() CommandResult {
+		runs++
+		return CommandResult{ExitCode: 1, Stderr: "Error from server (NotFound): pods \"x\" not found"}
+	}
+	res = executeWithRetry(context.Background(), "kubectl", []string{"get", "pod", "x"}, notFound)
+	c.Check(res.ExitCode, Equals, 1)
+	c.Check(runs, Equals, 1)
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// HTTPResponse is the canned answer to a request. A zero StatusCode means
+// 200 OK; a non-nil Err fails the request as a network error would.
+type HTTPResponse struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+// FakeTransport is an http.RoundTripper that answers requests from canned
+// responses keyed by URL. Requests for any other URL fail, so code under test
+// never reaches the network.
+type FakeTransport struct {
+	mu        sync.Mutex
+	responses map[string]HTTPResponse
+	queued    map[string][]HTTPResponse
+	requests  []string
+}
+
+// NewFakeTransport returns a transport answering from responses.
+func NewFakeTransport(responses map[string]HTTPResponse) *FakeTransport {
+	return &FakeTransport{responses: responses}
+}
+
+// Queue makes the next requests for url get responses, one each, before the
+// transport falls back to the response it was created with. It simulates
+// failures that go away, such as a rate limit.
+func (t *FakeTransport) Queue(url string, responses ...HTTPResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.queued == nil {
+		t.queued = map[string][]HTTPResponse{}
+	}
+	t.queued[url] = append(t.queued[url], responses...)
+}
+
+// Client returns an http.Client that sends its requests through t.
+func (t *FakeTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Requests lists the requests made so far, e.g. "GET https://example.com/a".
+func (t *FakeTransport) Requests() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.requests...)
+}
+
+func (t *FakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	t.mu.Lock()
+	t.requests = append(t.requests, req.Method+" "+url)
+	res, ok := t.responses[url]
+	if q := t.queued[url]; len(q) > 0 {
+		res, ok, t.queued[url] = q[0], true, q[1:]
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no fake response for %s", url)
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	status := res.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(res.Body)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFakeTransport(t *testing.T) {
+	tr := NewFakeTransport(map[string]HTTPResponse{
+		"https://example.com/ok":      {Body: "hello"},
+		"https://example.com/missing": {StatusCode: http.StatusNotFound},
+		"https://example.com/down":    {Err: errors.New("connection refused")},
+	})
+	client := tr.Client()
+
+	resp, err := client.Get("https://example.com/ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("got %d %q, want 200 \"hello\"", resp.StatusCode, body)
+	}
+
+	resp, err = client.Get("https://example.com/missing")
+	if err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404, got %v, %v", resp, err)
+	}
+	if _, err := client.Get("https://example.com/down"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the injected error, got %v", err)
+	}
+	if _, err := client.Get("https://github.com/"); err == nil || !strings.Contains(err.Error(), "no fake response") {
+		t.Errorf("expected unknown URLs to fail, got %v", err)
+	}
+	if got := tr.Requests(); len(got) != 4 || got[0] != "GET https://example.com/ok" {
+		t.Errorf("unexpected requests %v", got)
+	}
+}
+
+func TestFakeTransport_Queue(t *testing.T) {
+	tr := NewFakeTransport(map[string]HTTPResponse{"https://example.com/a": {Body: "ok"}})
+	tr.Queue("https://example.com/a", HTTPResponse{StatusCode: http.StatusServiceUnavailable}, HTTPResponse{StatusCode: http.StatusTooManyRequests})
+
+	var got []int
+	for i := 0; i < 3; i++ {
+		resp, err := tr.Client().Get("https://example.com/a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		got = append(got, resp.StatusCode)
+	}
+	if want := []int{503, 429, 200}; !reflect.DeepEqual(got, want) {
+		t.Errorf("status codes = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,405 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testutil holds fakes for the registry, HTTP and clock dependencies
+// of gcluster's packages, so they can be tested without network access or
+// real waits. The fakes satisfy the interfaces accepted by the package
+// setters, such as imagebuilder.SetRegistry and GKEOrchestrator.SetClock.
+package testutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// FakeRegistry is an in-memory container registry. Images are stored under
+// the reference they are added or pushed with; tags and digests of the same
+// repository resolve to them like in a real registry. Lookups of unknown
+// references fail with a MANIFEST_UNKNOWN error.
+type FakeRegistry struct {
+	mu     sync.Mutex
+	images map[string]*fakeImage
+	blobs  map[string][]byte
+	errs   map[string]error
+	calls  []string
+}
+
+type fakeImage struct {
+	img      v1.Image
+	index    v1.ImageIndex
+	digest   string
+	manifest []byte
+	config   []byte
+	layers   [][]byte
+}
+
+// NewFakeRegistry returns an empty registry.
+func NewFakeRegistry() *FakeRegistry {
+	return &FakeRegistry{images: map[string]*fakeImage{}, blobs: map[string][]byte{}, errs: map[string]error{}}
+}
+
+// AddImage stores img under ref.
+func (r *FakeRegistry) AddImage(ref string, img v1.Image) error {
+	d, err := img.Digest()
+	if err != nil {
+		return fmt.Errorf("failed to compute the digest of %s: %w", ref, err)
+	}
+	return r.put(ref, &fakeImage{img: img, digest: d.String()})
+}
+
+// AddManifest stores a raw manifest, and optionally a raw config, under ref.
+// It stands for images whose content does not matter, such as multi-platform
+// indexes that are only inspected.
+func (r *FakeRegistry) AddManifest(ref string, manifest, config []byte) error {
+	sum := sha256.Sum256(manifest)
+	return r.put(ref, &fakeImage{manifest: manifest, config: config, digest: "sha256:" + hex.EncodeToString(sum[:])})
+}
+
+// AddIndex stores the multi-platform index idx under ref, and each of its
+// images under its digest. Requests for ref with a platform resolve to the
+// image of that platform, like in a real registry.
+func (r *FakeRegistry) AddIndex(ref string, idx v1.ImageIndex) error {
+	return r.putIndex(ref, idx, func(img v1.Image, ref string) error {
+		d, err := img.Digest()
+		if err != nil {
+			return err
+		}
+		return r.put(ref, &fakeImage{img: img, digest: d.String()})
+	})
+}
+
+// SetError makes every request for ref fail with err, e.g. to simulate a
+// registry that denies access. A nil err clears it.
+func (r *FakeRegistry) SetError(ref string, err error) {
+	key := normalize(ref)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		delete(r.errs, key)
+		return
+	}
+	r.errs[key] = err
+}
+
+// Calls lists the requests made so far, e.g. "Digest index.docker.io/library/python:3.11".
+func (r *FakeRegistry) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// PushedLayers returns the compressed layers of the image pushed to ref, by
+// tag or digest, in order. Layers are read as they are pushed, so streamed
+// layers can be inspected after the push.
+func (r *FakeRegistry) PushedLayers(ref string) [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.images[normalize(ref)]
+	if !ok {
+		e = r.findDigest(ref)
+	}
+	if e != nil {
+		return e.layers
+	}
+	return nil
+}
+
+func (r *FakeRegistry) Digest(ref string, opts ...crane.Option) (string, error) {
+	e, err := r.lookup("Digest", ref, opts)
+	if err != nil {
+		return "", err
+	}
+	return e.digest, nil
+}
+
+func (r *FakeRegistry) Head(ref string, _ ...crane.Option) (*v1.Descriptor, error) {
+	e, err := r.lookup("Head", ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	h, err := v1.NewHash(e.digest)
+	if err != nil {
+		return nil, err
+	}
+	desc := &v1.Descriptor{Digest: h, Size: int64(len(e.manifest)), MediaType: types.OCIManifestSchema1}
+	if e.img != nil {
+		if desc.MediaType, err = e.img.MediaType(); err != nil {
+			return nil, err
+		}
+		if desc.Size, err = e.img.Size(); err != nil {
+			return nil, err
+		}
+	}
+	return desc, nil
+}
+
+// Pull returns the image stored under ref. Images that were pushed with
+// streamed layers have already been read and cannot be read again.
+func (r *FakeRegistry) Pull(ref string, opts ...crane.Option) (v1.Image, error) {
+	e, err := r.lookup("Pull", ref, opts)
+	if err != nil {
+		return nil, err
+	}
+	if e.img == nil {
+		return nil, fmt.Errorf("%s holds a manifest without image content", ref)
+	}
+	return e.img, nil
+}
+
+// Push stores img under ref. Layers whose blob was pushed before are not read
+// again, like a registry client skips blobs the registry already has, so
+// streamed layers can be pushed once and then referenced by other images.
+func (r *FakeRegistry) Push(img v1.Image, ref string, _ ...crane.Option) error {
+	if err := r.record("Push", ref); err != nil {
+		return err
+	}
+	return r.pushImage(img, ref)
+}
+
+// PushLayer stores the blob of l in repo.
+func (r *FakeRegistry) PushLayer(l v1.Layer, repo string, _ ...crane.Option) error {
+	if err := r.record("PushLayer", repo); err != nil {
+		return err
+	}
+	_, err := r.readLayer(l)
+	return err
+}
+
+// PushIndex stores idx under ref, and each of its images under its digest in
+// the repository of ref.
+func (r *FakeRegistry) PushIndex(idx v1.ImageIndex, ref string, _ ...crane.Option) error {
+	if err := r.record("PushIndex", ref); err != nil {
+		return err
+	}
+	return r.putIndex(ref, idx, r.pushImage)
+}
+
+// Tag stores the image or index of ref under tag in the repository of ref.
+func (r *FakeRegistry) Tag(ref, tag string, _ ...crane.Option) error {
+	e, err := r.lookup("Tag", ref, nil)
+	if err != nil {
+		return err
+	}
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	return r.put(parsed.Context().Tag(tag).String(), e)
+}
+
+// putIndex stores each image of idx with put under its digest in the
+// repository of ref, and then idx under ref.
+func (r *FakeRegistry) putIndex(ref string, idx v1.ImageIndex, put func(img v1.Image, ref string) error) error {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range manifest.Manifests {
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if err := put(img, parsed.Context().Digest(desc.Digest.String()).String()); err != nil {
+			return err
+		}
+	}
+	raw, err := idx.RawManifest()
+	if err != nil {
+		return err
+	}
+	d, err := idx.Digest()
+	if err != nil {
+		return err
+	}
+	return r.put(ref, &fakeImage{index: idx, manifest: raw, digest: d.String()})
+}
+
+// record logs a push request for ref and returns the error set for it.
+func (r *FakeRegistry) record(op, ref string) error {
+	key := normalize(ref)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, op+" "+key)
+	return r.errs[key]
+}
+
+func (r *FakeRegistry) pushImage(img v1.Image, ref string) error {
+	layers, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	e := &fakeImage{img: img}
+	for _, l := range layers {
+		b, err := r.readLayer(l)
+		if err != nil {
+			return err
+		}
+		e.layers = append(e.layers, b)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	e.digest = d.String()
+	return r.put(ref, e)
+}
+
+// readLayer returns the compressed content of l, from the blobs pushed
+// before when its digest is known.
+func (r *FakeRegistry) readLayer(l v1.Layer) ([]byte, error) {
+	if d, err := l.Digest(); err == nil {
+		r.mu.Lock()
+		b, ok := r.blobs[d.String()]
+		r.mu.Unlock()
+		if ok {
+			return b, nil
+		}
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	d, err := l.Digest()
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.blobs[d.String()] = b
+	r.mu.Unlock()
+	return b, nil
+}
+
+func (r *FakeRegistry) Manifest(ref string, opts ...crane.Option) ([]byte, error) {
+	e, err := r.lookup("Manifest", ref, opts)
+	if err != nil {
+		return nil, err
+	}
+	if e.img != nil {
+		return e.img.RawManifest()
+	}
+	return e.manifest, nil
+}
+
+func (r *FakeRegistry) Config(ref string, opts ...crane.Option) ([]byte, error) {
+	e, err := r.lookup("Config", ref, opts)
+	if err != nil {
+		return nil, err
+	}
+	if e.img != nil {
+		return e.img.RawConfigFile()
+	}
+	if e.config == nil {
+		return nil, fmt.Errorf("%s has no config", ref)
+	}
+	return e.config, nil
+}
+
+func (r *FakeRegistry) put(ref string, e *fakeImage) error {
+	if _, err := name.ParseReference(ref); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.images[normalize(ref)] = e
+	return nil
+}
+
+// lookup finds ref by tag or, for digest references, by any image of the
+// repository with that digest. An index resolves to its image for the
+// platform in opts, if any.
+func (r *FakeRegistry) lookup(op, ref string, opts []crane.Option) (*fakeImage, error) {
+	key := normalize(ref)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, op+" "+key)
+	if err := r.errs[key]; err != nil {
+		return nil, err
+	}
+	e, ok := r.images[key]
+	if !ok {
+		e = r.findDigest(ref)
+	}
+	if e == nil {
+		return nil, fmt.Errorf("MANIFEST_UNKNOWN: manifest unknown: %s", ref)
+	}
+	if p := crane.GetOptions(opts...).Platform; e.index != nil && p != nil {
+		return r.platformImage(ref, e, *p)
+	}
+	return e, nil
+}
+
+func (r *FakeRegistry) findDigest(ref string) *fakeImage {
+	d, err := name.NewDigest(ref)
+	if err != nil {
+		return nil
+	}
+	for k, e := range r.images {
+		if e.digest != d.DigestStr() {
+			continue
+		}
+		if parsed, err := name.ParseReference(k); err == nil && parsed.Context() == d.Context() {
+			return e
+		}
+	}
+	return nil
+}
+
+// platformImage returns the image of the index e stored under ref for
+// platform.
+func (r *FakeRegistry) platformImage(ref string, e *fakeImage, platform v1.Platform) (*fakeImage, error) {
+	manifest, err := e.index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range manifest.Manifests {
+		if desc.Platform == nil || desc.Platform.OS != platform.OS || desc.Platform.Architecture != platform.Architecture {
+			continue
+		}
+		if child := r.findDigest(parsed.Context().Digest(desc.Digest.String()).String()); child != nil {
+			return child, nil
+		}
+	}
+	return nil, fmt.Errorf("no child with platform %s in index %s", platform, ref)
+}
+
+// normalize spells ref out in full, so "python:3.11" and
+// "index.docker.io/library/python:3.11" are the same image.
+func normalize(ref string) string {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return ref
+	}
+	return parsed.Name()
+}
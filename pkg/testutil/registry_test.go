@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/stream"
+)
+
+func TestFakeRegistry_Lookup(t *testing.T) {
+	reg := NewFakeRegistry()
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.AddImage("python:3.11", img); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := img.Digest()
+
+	digest, err := reg.Digest("index.docker.io/library/python:3.11")
+	if err != nil || digest != want.String() {
+		t.Errorf("Digest() = %q, %v; want %s", digest, err, want)
+	}
+	if desc, err := reg.Head("python@" + digest); err != nil || desc.Digest != want {
+		t.Errorf("Head() by digest = %+v, %v; want %s", desc, err, want)
+	}
+	if pulled, err := reg.Pull("python:3.11"); err != nil || pulled != img {
+		t.Errorf("Pull() = %v, %v; want the added image", pulled, err)
+	}
+	if _, err := reg.Head("ubuntu@" + digest); err == nil || !strings.Contains(err.Error(), "MANIFEST_UNKNOWN") {
+		t.Errorf("expected a digest of another repository to be unknown, got %v", err)
+	}
+	if _, err := reg.Digest("python:3.12"); err == nil || !strings.Contains(err.Error(), "MANIFEST_UNKNOWN") {
+		t.Errorf("expected an unknown tag to fail with MANIFEST_UNKNOWN, got %v", err)
+	}
+
+	reg.SetError("python:3.11", errors.New("DENIED"))
+	if _, err := reg.Digest("python:3.11"); err == nil || err.Error() != "DENIED" {
+		t.Errorf("expected the injected error, got %v", err)
+	}
+
+	calls := reg.Calls()
+	if len(calls) != 6 || calls[0] != "Digest index.docker.io/library/python:3.11" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+}
+
+func TestFakeRegistry_AddManifest(t *testing.T) {
+	reg := NewFakeRegistry()
+	if err := reg.AddManifest("example.com/app:v1", []byte(`{"manifests": []}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := reg.Manifest("example.com/app:v1"); err != nil || string(raw) != `{"manifests": []}` {
+		t.Errorf("Manifest() = %s, %v", raw, err)
+	}
+	if _, err := reg.Config("example.com/app:v1"); err == nil {
+		t.Error("expected an error for a manifest without config")
+	}
+	if _, err := reg.Pull("example.com/app:v1"); err == nil {
+		t.Error("expected an error pulling a manifest without image content")
+	}
+}
+
+func TestFakeRegistry_Push(t *testing.T) {
+	reg := NewFakeRegistry()
+	layer := stream.NewLayer(io.NopCloser(strings.NewReader("context")))
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Push(img, "example.com/app:ctx"); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	layers := reg.PushedLayers("example.com/app:ctx")
+	if len(layers) != 1 || !bytes.HasPrefix(layers[0], []byte{0x1f, 0x8b}) {
+		t.Errorf("expected one gzipped layer to be recorded, got %d", len(layers))
+	}
+	if _, err := reg.Head("example.com/app:ctx"); err != nil {
+		t.Errorf("expected the pushed image to be found, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versioncheck
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/localstate"
+)
+
+const (
+	binaryName       = "gcluster"
+	downloadTimeout  = 5 * time.Minute
+	checksumSuffix   = ".sha256"
+	bundleNamePrefix = "gcluster_bundle_"
+)
+
+var downloadClient = &http.Client{Timeout: downloadTimeout}
+
+// bundleOSName maps GOOS onto the OS name used in release bundle asset names.
+func bundleOSName(goos string) string {
+	if goos == "darwin" {
+		return "mac"
+	}
+	return goos
+}
+
+// bundleFormats lists the archive formats a release may publish, in order of
+// preference. Tarballs are published from v1.89.0, zips from v1.85.0.
+var bundleFormats = []string{".tgz", ".zip"}
+
+// bundleBaseName returns the asset name of the release bundle for the given
+// platform without its archive extension, e.g. "gcluster_bundle_linux_amd64".
+func bundleBaseName(goos, goarch string) string {
+	return bundleNamePrefix + bundleOSName(goos) + "_" + goarch
+}
+
+// FindBundleAsset returns the release bundle for the given OS and
+// architecture together with its checksum asset. A release that does not
+// publish a checksum for the bundle is rejected so that an unverified binary
+// is never installed.
+func FindBundleAsset(rel Release, goos, goarch string) (bundle Asset, checksum Asset, err error) {
+	base := bundleBaseName(goos, goarch)
+	assets := make(map[string]Asset, len(rel.Assets))
+	for _, a := range rel.Assets {
+		assets[a.Name] = a
+	}
+	for _, ext := range bundleFormats {
+		b, ok := assets[base+ext]
+		if !ok {
+			continue
+		}
+		sum, ok := assets[b.Name+checksumSuffix]
+		if !ok {
+			return Asset{}, Asset{}, fmt.Errorf("release %s publishes %s without a %s checksum; refusing to install an unverified binary", rel.TagName, b.Name, checksumSuffix)
+		}
+		return b, sum, nil
+	}
+	return Asset{}, Asset{}, fmt.Errorf("release %s has no %s bundle (%s) for %s/%s", rel.TagName, base, strings.Join(bundleFormats, ", "), goos, goarch)
+}
+
+// SelfUpdate replaces the binary at targetPath with the gcluster binary from
+// the release bundle matching the running OS and architecture.
+func SelfUpdate(rel Release, targetPath string) error {
+	bundle, checksum, err := FindBundleAsset(rel, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	body, err := download(bundle.BrowserDownloadURL)
+	if err != nil {
+		return err
+	}
+
+	sum, err := download(checksum.BrowserDownloadURL)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksum(body, string(sum)); err != nil {
+		return fmt.Errorf("%s: %w", bundle.Name, err)
+	}
+
+	bin, err := extractBinary(bundle.Name, body)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s from %s: %w", binaryName, bundle.Name, err)
+	}
+
+	return localstate.WriteFileAtomic(targetPath, bin, 0755)
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := downloadClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: HTTP %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifyChecksum compares body against a "sha256sum"-style checksum file.
+func verifyChecksum(body []byte, checksumFile string) error {
+	fields := strings.Fields(checksumFile)
+	if len(fields) == 0 {
+		return fmt.Errorf("checksum file is empty")
+	}
+	sum := sha256.Sum256(body)
+	actual := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(fields[0], actual) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", fields[0], actual)
+	}
+	return nil
+}
+
+// extractBinary returns the gcluster binary from a bundle archive, picking the
+// archive reader from the bundle's file name.
+func extractBinary(name string, body []byte) ([]byte, error) {
+	if strings.HasSuffix(name, ".tgz") {
+		return extractTarGz(body)
+	}
+	return extractZip(body)
+}
+
+func extractZip(body []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || path.Base(f.Name) != binaryName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("archive does not contain a %s binary", binaryName)
+}
+
+func extractTarGz(body []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tgz archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tgz archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || path.Base(hdr.Name) != binaryName {
+			continue
+		}
+		return io.ReadAll(tr)
+	}
+	return nil, fmt.Errorf("archive does not contain a %s binary", binaryName)
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package versioncheck compares the running gcluster binary against published
+// releases and against the cluster components it is known to work with.
+package versioncheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-toolkit/pkg/localstate"
+)
+
+const (
+	// UpdateCheckEnvVar opts into a once-a-day release check on startup.
+	UpdateCheckEnvVar = "GCLUSTER_UPDATE_CHECK"
+
+	stateFileName  = "version_check.json"
+	checkFrequency = 24 * time.Hour
+	apiTimeout     = 5 * time.Second
+)
+
+var (
+	// ReleasesURL is the GitHub API endpoint describing the latest release.
+	ReleasesURL = "https://api.github.com/repos/GoogleCloudPlatform/cluster-toolkit/releases/latest"
+
+	httpClient = &http.Client{Timeout: apiTimeout}
+	now        = time.Now
+)
+
+// Asset is a downloadable file attached to a release.
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Release describes a published gcluster release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Incompatibility records a range of cluster component versions that the
+// current gcluster release is known not to work with.
+type Incompatibility struct {
+	Component string // "kueue" or "jobset"
+	Below     string // Versions strictly below this one are affected.
+	Reason    string
+}
+
+// CompatibilityMatrix lists the known-incompatible in-cluster component versions.
+var CompatibilityMatrix = []Incompatibility{
+	{
+		Component: "kueue",
+		Below:     "v0.15.0",
+		Reason:    "gcluster manages Kueue objects through the kueue.x-k8s.io/v1beta2 API, which is not served by older Kueue releases",
+	},
+	{
+		Component: "jobset",
+		Below:     "v0.6.0",
+		Reason:    "generated JobSets use failurePolicy.rules, which older JobSet controllers reject",
+	},
+}
+
+type checkState struct {
+	LastChecked   time.Time `json:"last_checked"`
+	LatestVersion string    `json:"latest_version"`
+}
+
+var stateFile = localstate.File{Name: stateFileName, Version: 1}
+
+// LatestRelease fetches the latest published release.
+func LatestRelease() (Release, error) {
+	req, err := http.NewRequest(http.MethodGet, ReleasesURL, nil)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to create release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to query latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("failed to query latest release: HTTP %d", resp.StatusCode)
+	}
+
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return Release{}, fmt.Errorf("failed to parse latest release: %w", err)
+	}
+	if rel.TagName == "" {
+		return Release{}, fmt.Errorf("latest release has no tag")
+	}
+	return rel, nil
+}
+
+// IsNewer reports whether candidate is a strictly higher semantic version than current.
+func IsNewer(candidate, current string) bool {
+	return Compare(candidate, current) > 0
+}
+
+// Compare returns -1, 0 or 1 depending on whether a is lower, equal or higher than b.
+// Versions may carry a leading "v" and pre-release/build suffixes, which are ignored.
+func Compare(a, b string) int {
+	pa, pb := parse(a), parse(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parse(v string) [3]int {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v = strings.SplitN(v, "-", 2)[0]
+	v = strings.SplitN(v, "+", 2)[0]
+	for i, p := range strings.SplitN(v, ".", 3) {
+		out[i], _ = strconv.Atoi(p)
+	}
+	return out
+}
+
+// CheckComponent returns human-readable warnings for a cluster component
+// version that appears in the compatibility matrix.
+func CheckComponent(component, version string) []string {
+	if version == "" {
+		return nil
+	}
+	var warnings []string
+	for _, inc := range CompatibilityMatrix {
+		if !strings.EqualFold(inc.Component, component) {
+			continue
+		}
+		if Compare(version, inc.Below) < 0 {
+			warnings = append(warnings, fmt.Sprintf("%s %s is not compatible with this version of gcluster (requires %s or newer): %s", component, version, inc.Below, inc.Reason))
+		}
+	}
+	return warnings
+}
+
+// StartupCheckEnabled reports whether the user opted into the startup release check.
+func StartupCheckEnabled() bool {
+	switch strings.ToLower(os.Getenv(UpdateCheckEnvVar)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// CachedLatestVersion returns the latest release tag, querying the release
+// endpoint at most once per day and caching the result in the local state
+// directory.
+func CachedLatestVersion() (string, error) {
+	var state checkState
+	if err := stateFile.Load(&state); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if state.LatestVersion != "" && now().Sub(state.LastChecked) < checkFrequency {
+		return state.LatestVersion, nil
+	}
+
+	rel, err := LatestRelease()
+	if err != nil {
+		return "", err
+	}
+	state = checkState{LastChecked: now(), LatestVersion: rel.TagName}
+	if err := stateFile.Save(state); err != nil {
+		return "", err
+	}
+	return rel.TagName, nil
+}
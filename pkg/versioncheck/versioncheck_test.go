@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versioncheck
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.98.0", "v1.98.0", 0},
+		{"v1.99.0", "v1.98.0", 1},
+		{"v1.98.0", "v1.98.1", -1},
+		{"1.100.0", "v1.99.9", 1},
+		{"v0.15.2-rc.1", "v0.15.2", 0},
+		{"v2", "v1.99.99", 1},
+	}
+	for _, tc := range tests {
+		if got := Compare(tc.a, tc.b); got != tc.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestCheckComponent(t *testing.T) {
+	if w := CheckComponent("kueue", "v0.14.3"); len(w) != 1 || !strings.Contains(w[0], "v0.15.0") {
+		t.Errorf("expected one kueue warning, got %v", w)
+	}
+	if w := CheckComponent("kueue", "v0.15.2"); len(w) != 0 {
+		t.Errorf("expected no warnings for supported kueue, got %v", w)
+	}
+	if w := CheckComponent("JobSet", "v0.5.1"); len(w) != 1 {
+		t.Errorf("expected one jobset warning, got %v", w)
+	}
+	if w := CheckComponent("kueue", ""); w != nil {
+		t.Errorf("expected no warnings for unknown version, got %v", w)
+	}
+}
+
+func TestStartupCheckEnabled(t *testing.T) {
+	t.Setenv(UpdateCheckEnvVar, "")
+	if StartupCheckEnabled() {
+		t.Error("expected startup check to be disabled by default")
+	}
+	t.Setenv(UpdateCheckEnvVar, "TRUE")
+	if !StartupCheckEnabled() {
+		t.Error("expected startup check to be enabled")
+	}
+}
+
+func serveRelease(t *testing.T, tag string, hits *int32) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits != nil {
+			atomic.AddInt32(hits, 1)
+		}
+		fmt.Fprintf(w, `{"tag_name": %q, "html_url": "https://example.com/%s"}`, tag, tag)
+	}))
+	t.Cleanup(srv.Close)
+
+	orig := ReleasesURL
+	ReleasesURL = srv.URL
+	t.Cleanup(func() { ReleasesURL = orig })
+}
+
+func TestLatestRelease(t *testing.T) {
+	serveRelease(t, "v1.99.0", nil)
+
+	rel, err := LatestRelease()
+	if err != nil {
+		t.Fatalf("LatestRelease() error = %v", err)
+	}
+	if rel.TagName != "v1.99.0" {
+		t.Errorf("TagName = %q, want v1.99.0", rel.TagName)
+	}
+}
+
+func TestLatestRelease_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	orig := ReleasesURL
+	ReleasesURL = srv.URL
+	defer func() { ReleasesURL = orig }()
+
+	if _, err := LatestRelease(); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected HTTP 403 error, got %v", err)
+	}
+}
+
+func TestCachedLatestVersion_QueriesOncePerDay(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var hits int32
+	serveRelease(t, "v1.99.0", &hits)
+
+	fixed := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	defer func() { now = time.Now }()
+
+	for i := 0; i < 3; i++ {
+		v, err := CachedLatestVersion()
+		if err != nil {
+			t.Fatalf("CachedLatestVersion() error = %v", err)
+		}
+		if v != "v1.99.0" {
+			t.Errorf("version = %q, want v1.99.0", v)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected 1 release query, got %d", hits)
+	}
+
+	now = func() time.Time { return fixed.Add(25 * time.Hour) }
+	if _, err := CachedLatestVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 2 {
+		t.Errorf("expected cache to expire after a day, got %d queries", hits)
+	}
+}
+
+func zipWithBinary(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("gcluster-bundle/gcluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tgzWithBinary(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "gcluster-bundle/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "gcluster-bundle/gcluster", Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFindBundleAsset(t *testing.T) {
+	rel := Release{
+		TagName: "v1.99.0",
+		Assets: []Asset{
+			{Name: "gcluster_bundle_linux.zip"},
+			{Name: "gcluster_bundle_linux.zip.sha256"},
+			{Name: "gcluster_bundle_linux_amd64.zip"},
+			{Name: "gcluster_bundle_linux_amd64.zip.sha256"},
+			{Name: "gcluster_bundle_linux_amd64.tgz"},
+			{Name: "gcluster_bundle_linux_amd64.tgz.sha256"},
+			{Name: "gcluster_bundle_linux_arm64.zip"},
+			{Name: "gcluster_bundle_linux_arm64.zip.sha256"},
+			{Name: "gcluster_bundle_mac_arm64.zip"},
+		},
+	}
+	for _, tc := range []struct {
+		goos, goarch string
+		want         string
+		wantErr      string
+	}{
+		{goos: "linux", goarch: "amd64", want: "gcluster_bundle_linux_amd64.tgz"},
+		{goos: "linux", goarch: "arm64", want: "gcluster_bundle_linux_arm64.zip"},
+		{goos: "darwin", goarch: "arm64", wantErr: "without a .sha256 checksum"},
+		{goos: "darwin", goarch: "amd64", wantErr: "no gcluster_bundle_mac_amd64 bundle"},
+		{goos: "windows", goarch: "amd64", wantErr: "no gcluster_bundle_windows_amd64 bundle"},
+	} {
+		t.Run(tc.goos+"/"+tc.goarch, func(t *testing.T) {
+			bundle, sum, err := FindBundleAsset(rel, tc.goos, tc.goarch)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindBundleAsset() error = %v", err)
+			}
+			if bundle.Name != tc.want || sum.Name != tc.want+checksumSuffix {
+				t.Errorf("got %q, %q; want %q with its checksum", bundle.Name, sum.Name, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelfUpdate(t *testing.T) {
+	zipPayload := zipWithBinary(t, "new-binary")
+	tgzPayload := tgzWithBinary(t, "new-binary")
+	sumOf := func(b []byte) string {
+		sum := sha256.Sum256(b)
+		return hex.EncodeToString(sum[:])
+	}
+	base := bundleBaseName(runtime.GOOS, runtime.GOARCH)
+
+	for _, tc := range []struct {
+		name       string
+		bundleName string
+		payload    []byte
+		checksum   string
+		wantErr    string
+	}{
+		{name: "zip valid checksum", bundleName: base + ".zip", payload: zipPayload, checksum: sumOf(zipPayload) + "  " + base + ".zip"},
+		{name: "tgz valid checksum", bundleName: base + ".tgz", payload: tgzPayload, checksum: sumOf(tgzPayload) + "  " + base + ".tgz"},
+		{name: "bad checksum", bundleName: base + ".zip", payload: zipPayload, checksum: "deadbeef", wantErr: "checksum mismatch"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/bundle", func(w http.ResponseWriter, r *http.Request) { w.Write(tc.payload) })
+			mux.HandleFunc("/sum", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(tc.checksum)) })
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			rel := Release{TagName: "v1.99.0", Assets: []Asset{
+				{Name: tc.bundleName, BrowserDownloadURL: srv.URL + "/bundle"},
+				{Name: tc.bundleName + checksumSuffix, BrowserDownloadURL: srv.URL + "/sum"},
+			}}
+
+			target := filepath.Join(t.TempDir(), "gcluster")
+			if err := os.WriteFile(target, []byte("old-binary"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := SelfUpdate(rel, target)
+			got, _ := os.ReadFile(target)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				if string(got) != "old-binary" {
+					t.Errorf("binary should be untouched on failure, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelfUpdate() error = %v", err)
+			}
+			if string(got) != "new-binary" {
+				t.Errorf("binary content = %q, want new-binary", got)
+			}
+		})
+	}
+}